@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
@@ -24,6 +26,7 @@ import (
 	// Internal packages
 	"github.com/seu-repo/sigec-ve/internal/adapter/ai/gemini"
 	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
+	notificationAdapter "github.com/seu-repo/sigec-ve/internal/adapter/external/notification"
 	payment "github.com/seu-repo/sigec-ve/internal/adapter/external/payment"
 	"github.com/seu-repo/sigec-ve/internal/adapter/grpc/server"
 	"github.com/seu-repo/sigec-ve/internal/ports"
@@ -31,13 +34,23 @@ import (
 	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
 	v201 "github.com/seu-repo/sigec-ve/internal/adapter/ocpp/v201"
 	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
-	nzdb "github.com/seu-repo/sigec-ve/internal/adapter/storage/nietzsche"
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage"
 	wsAdapter "github.com/seu-repo/sigec-ve/internal/adapter/websocket"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/circuitbreaker"
 	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
+	"github.com/seu-repo/sigec-ve/internal/service/admin"
 	"github.com/seu-repo/sigec-ve/internal/service/auth"
 	"github.com/seu-repo/sigec-ve/internal/service/device"
+	"github.com/seu-repo/sigec-ve/internal/service/email"
+	"github.com/seu-repo/sigec-ve/internal/service/invoice"
+	"github.com/seu-repo/sigec-ve/internal/service/notification"
+	paymentService "github.com/seu-repo/sigec-ve/internal/service/payment"
+	"github.com/seu-repo/sigec-ve/internal/service/reservation"
 	"github.com/seu-repo/sigec-ve/internal/service/transaction"
+	"github.com/seu-repo/sigec-ve/internal/service/v2g"
 	"github.com/seu-repo/sigec-ve/internal/service/voice"
+	"github.com/seu-repo/sigec-ve/internal/service/webhook"
 	"github.com/seu-repo/sigec-ve/pkg/config"
 
 	// Import metrics to register them
@@ -79,16 +92,35 @@ func main() {
 		}
 	}()
 
-	// 4. Initialize NietzscheDB Connection
+	// 4. Initialize Storage Backend
 	nietzscheAddr := os.Getenv("NIETZSCHE_ADDR")
+	if nietzscheAddr == "" {
+		nietzscheAddr = cfg.Database.GRPCAddress
+	}
 	if nietzscheAddr == "" {
 		nietzscheAddr = "136.111.0.47:50051"
 	}
-	db, err := nzdb.NewConnection(nietzscheAddr, logger)
+	// NietzscheDB updates that read then write, reservation confirmations and
+	// session stops are locked through Redis when REDIS_URL is set
+	var storageLocker ports.Locker
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisLocker, err := cache.NewRedisLocker(redisURL, logger)
+		if err != nil {
+			logger.Warn("Redis not available, storage updates are locked per instance", zap.Error(err))
+		} else {
+			storageLocker = redisLocker
+		}
+	}
+	repos, err := storage.Open(storage.Config{
+		Backend:     storage.Backend(cfg.Database.Backend),
+		URL:         cfg.Database.URL,
+		GRPCAddress: nietzscheAddr,
+		Locker:      storageLocker,
+	}, logger)
 	if err != nil {
-		logger.Fatal("Failed to connect to NietzscheDB", zap.Error(err))
+		logger.Fatal("Failed to open storage", zap.String("backend", cfg.Database.Backend), zap.Error(err))
 	}
-	defer db.Close()
+	defer repos.Close()
 
 	// 5. Initialize Local Cache
 	localCache := cache.NewLocalCache(time.Minute, logger)
@@ -103,10 +135,17 @@ func main() {
 		defer messageQueue.Close()
 	}
 
-	// 7. Initialize Repositories (NietzscheDB-backed)
-	chargePointRepo := nzdb.NewChargePointRepository(db, logger)
-	transactionRepo := nzdb.NewTransactionRepository(db, logger)
-	userRepo := nzdb.NewUserRepository(db, logger)
+	// 7. Initialize Repositories
+	chargePointRepo := repos.ChargePoints
+	transactionRepo := repos.Transactions
+	userRepo := repos.Users
+	inventoryRepo := repos.Inventory
+	voiceCommandRepo := repos.VoiceCommands
+	eventRepo := repos.Events
+	logRequestRepo := repos.LogRequests
+
+	// Domain events are appended to the event log before they reach NATS
+	eventStore := queue.NewEventStore(messageQueue, eventRepo, logger)
 
 	// 8. Initialize Payment Gateway (Stripe)
 	stripeGateway := payment.NewStripeService(cfg.Payment.Stripe.SecretKey, logger)
@@ -114,23 +153,280 @@ func main() {
 	// 9. Initialize Services (Business Logic Layer)
 	authService := auth.NewService(userRepo, localCache, cfg.JWT.Secret, logger)
 	deviceService := device.NewService(chargePointRepo, localCache, messageQueue, logger)
-	transactionService := transaction.NewService(transactionRepo, deviceService, messageQueue, logger)
-	billingService := transaction.NewBillingService(transactionRepo, messageQueue, transaction.DefaultPricingConfig(), logger)
+	deviceService.(*device.Service).StartGeoIndexRefresh(context.Background(), device.DefaultGeoRefreshInterval)
+	transactionService := transaction.NewService(transactionRepo, deviceService, eventStore, logger)
+	if strategy := cfg.SmartCharging.StationSelection; strategy != "" {
+		if err := transactionService.(*transaction.Service).SetStationSelection(domain.StationSelectionStrategy(strategy)); err != nil {
+			logger.Fatal("Invalid station selection strategy", zap.Error(err))
+		}
+	}
+	if err := transactionService.(*transaction.Service).LoadActiveSessions(context.Background()); err != nil {
+		// Not fatal: the index loads again on first use
+		logger.Warn("Failed to load active sessions", zap.Error(err))
+	}
+	billingService := transaction.NewBillingService(transactionRepo, eventStore, transaction.DefaultPricingConfig(), logger)
+	billingService.SetStationRepository(chargePointRepo)
+	if cfg.Jobs.Reconciliation.Enabled {
+		reconciliationService := transaction.NewReconciliationService(transactionRepo, repos.Reconciliations, repos.Alerts, transaction.ReconciliationConfig{
+			TolerancePercent: cfg.Jobs.Reconciliation.TolerancePercent,
+			ToleranceWh:      cfg.Jobs.Reconciliation.ToleranceWh,
+			Lookback:         cfg.Jobs.Reconciliation.Lookback,
+		}, logger)
+		interval := cfg.Jobs.Reconciliation.Interval
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		reconciliationService.Start(context.Background(), interval)
+	}
+	if cfg.Jobs.MeterRetention.Enabled {
+		meterRetention := transaction.NewMeterRetentionService(transactionRepo, repos.MeterValues, transaction.MeterRetentionConfig{
+			FullResolution:     cfg.Jobs.MeterRetention.FullResolution,
+			DownsampleInterval: cfg.Jobs.MeterRetention.DownsampleInterval,
+			Retention:          cfg.Jobs.MeterRetention.Retention,
+		}, logger)
+		interval := cfg.Jobs.MeterRetention.Interval
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		meterRetention.Start(context.Background(), interval)
+	}
+	invoiceService := invoice.NewService(transactionRepo, userRepo, billingService, invoice.Issuer{
+		Name:  cfg.Payment.Invoice.IssuerName,
+		TaxID: cfg.Payment.Invoice.IssuerTaxID,
+	}, logger)
 
 
 	// 9. Initialize Gemini Live API Client (Voice)
 	geminiClient := gemini.NewLiveClient(cfg.Gemini.APIKey, logger)
-	voiceAssistant := voice.NewVoiceAssistant(geminiClient, deviceService, transactionService, logger)
+	voiceAssistant := voice.NewVoiceAssistant(geminiClient, deviceService, transactionService, voiceCommandRepo, logger)
 
 	// 10. Initialize OCPP 2.0.1 Server
 	ocppServer := v201.NewServer(deviceService, transactionService, logger)
+	transactionService.(*transaction.Service).SetSessionStarter(ocppServer)
+	transactionService.(*transaction.Service).SetMeterValueRepository(repos.MeterValues)
+	ocppServer.SetInventoryRepository(inventoryRepo)
+	ocppServer.SetLogRequestRepository(logRequestRepo)
+	ocppServer.SetConnectionEventRepository(repos.ConnectionEvents)
+	ocppServer.SetMeterValueRepository(repos.MeterValues)
+	ocppServer.SetChargingProfileReportRepository(repos.ChargingProfiles)
+	ocppServer.SetFirmwarePublicationRepository(repos.FirmwarePublications)
+	ocppServer.SetAlertRepository(repos.Alerts)
+	if cfg.OCPP.Security.CACert != "" {
+		stationCA, err := v2g.LoadCertificateAuthority(cfg.OCPP.Security.CACert, cfg.OCPP.Security.CAKey)
+		if err != nil {
+			logger.Warn("Station CA not available, SignCertificate requests will be rejected", zap.Error(err))
+		} else {
+			certService := v2g.NewStationCertificateService(repos.StationCertificates, repos.Alerts, stationCA, v2g.StationCertificateConfig{
+				Validity:      cfg.OCPP.Security.CertValidity,
+				ExpiryWarning: cfg.OCPP.Security.CertExpiryWarning,
+			}, logger)
+			certService.StartExpiryChecks(context.Background(), 12*time.Hour)
+			ocppServer.SetStationCertificateService(certService)
+		}
+	}
+	ocppServer.SetKeepalive(v201.KeepaliveConfig{
+		PingInterval: cfg.OCPP.WebsocketPingInterval,
+		PongTimeout:  cfg.OCPP.WebsocketPongTimeout,
+	})
+	ocppServer.SetConnectionLimits(v201.ConnectionLimits{
+		MaxConnections:       cfg.OCPP.Security.MaxConnections,
+		MaxConnectionsPerIP:  cfg.OCPP.Security.MaxConnectionsPerIP,
+		ConnectionRate:       cfg.OCPP.Security.ConnectionRate,
+		ConnectionRateWindow: cfg.OCPP.Security.ConnectionRateWindow,
+	})
+	ocppServer.SetCompression(v201.CompressionConfig{
+		Enabled: cfg.OCPP.WebsocketCompression,
+		Level:   cfg.OCPP.CompressionLevel,
+	})
+	ocppServer.SetSchemaValidation(cfg.OCPP.SchemaValidation)
+	ocppServer.SetHeartbeatPolicy(domain.HeartbeatPolicy{
+		Default:       cfg.OCPP.HeartbeatInterval,
+		ByVendor:      cfg.OCPP.HeartbeatByVendor,
+		ByChargePoint: cfg.OCPP.HeartbeatByChargePoint,
+	})
+	ocppServer.SetSiteCapacity(v201.SiteCapacityConfig{
+		ByChargePoint: cfg.OCPP.SiteCapacityKW,
+		Policy:        v201.SiteCapacityPolicy(cfg.OCPP.SiteCapacityPolicy),
+	})
+	ocppServer.SetChargeRateConfig(v201.ChargeRateConfig{
+		DefaultMinW: cfg.OCPP.MinChargeRateW,
+		Policy:      v201.ChargeRatePolicy(cfg.OCPP.ChargeRatePolicy),
+	})
+	ocppServer.SetFaultService(device.NewFaultService(repos.Alerts, messageQueue, logger))
+	gridPrices := v2g.NewGridPriceService(logger, nil)
+	ocppServer.SetGridPriceService(gridPrices)
+	ocppServer.StartCostUpdates(billingService, cfg.OCPP.CostUpdateInterval)
+	// REST device commands and the services driving stations go through the command port
+	ocppCommands := v201.NewCommandService(ocppServer)
+	firmwareService := device.NewFirmwarePort(device.NewFirmwareService(ocppCommands, queue.NewJSONQueue(eventStore), logger))
+	ocppServer.SetFirmwareService(firmwareService)
+	v2gService := v2g.NewService(repos.V2G, deviceService, transactionService, gridPrices, ocppCommands, queue.NewJSONQueue(eventStore), logger, nil)
+	if cfg.Jobs.SmartCharging.Enabled {
+		v2gService.SetChargingWindowScheduler(ocppServer)
+		v2gService.StartSmartChargeScheduling(context.Background(), cfg.Jobs.SmartCharging.Interval)
+	}
+	if cfg.Jobs.PrepaidMonitor.Enabled {
+		prepaidMonitor := transaction.NewPrepaidMonitor(transactionService, repos.Wallets, ocppServer, eventStore, transaction.PrepaidMonitorConfig{
+			Interval: cfg.Jobs.PrepaidMonitor.Interval,
+			Buffer:   cfg.Jobs.PrepaidMonitor.Buffer,
+		}, logger)
+		prepaidMonitor.SetStationRepository(chargePointRepo)
+		prepaidMonitor.Start(context.Background())
+	}
+	if cfg.Jobs.SessionLimit.Enabled {
+		sessionLimit := transaction.NewSessionLimitMonitor(transactionService, ocppServer, eventStore, transaction.SessionLimitConfig{
+			Interval:      cfg.Jobs.SessionLimit.Interval,
+			MaxDuration:   cfg.Limits.MaxTransactionDuration,
+			ByChargePoint: cfg.Jobs.SessionLimit.ByChargePoint,
+			WarnBefore:    cfg.Jobs.SessionLimit.WarnBefore,
+			StopGrace:     cfg.Jobs.SessionLimit.StopGrace,
+		}, logger)
+		// V2G sessions run for as long as the grid needs them
+		sessionLimit.SetV2GRepository(repos.V2G)
+		sessionLimit.SetStationRepository(chargePointRepo)
+		sessionLimit.Start(context.Background())
+	}
+	// Answers to commands sent before a restart are still matched
+	ocppServer.SetPendingCommandRepository(repos.PendingCommands)
+	if _, err := ocppServer.RestorePendingRequests(context.Background()); err != nil {
+		logger.Warn("Failed to restore pending OCPP commands", zap.Error(err))
+	}
 	go func() {
 		logger.Info("Starting OCPP WebSocket Server", zap.Int("port", cfg.OCPP.Port))
-		if err := ocppServer.Start(cfg.OCPP.Port); err != nil {
+		if err := ocppServer.Start(cfg.OCPP.Port); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Fatal("OCPP Server failed", zap.Error(err))
 		}
 	}()
 
+	if cfg.FeatureFlags.SmartCharging {
+		smartChargingConfig := transaction.DefaultSmartChargingConfig()
+		smartChargingConfig.StationPowerKW = cfg.SmartCharging.StationPowerKW
+		if cfg.SmartCharging.ReservationWeight > 0 {
+			smartChargingConfig.ReservationWeight = cfg.SmartCharging.ReservationWeight
+		}
+		smartChargingService := transaction.NewSmartChargingService(chargePointRepo, transactionRepo, eventStore, smartChargingConfig, logger)
+		smartChargingService.SetChargingLimiter(ocppServer)
+		smartChargingService.SetReservationRepository(repos.Reservations)
+		if messageQueue != nil {
+			if err := smartChargingService.StartLoadBalancing(eventStore); err != nil {
+				logger.Warn("Load balancing not started", zap.Error(err))
+			}
+		}
+	}
+
+	var webhookService *webhook.Service
+	if cfg.Webhooks.Enabled {
+		webhookService = webhook.NewService(repos.Webhooks, userRepo, webhook.Config{
+			EventTypes:         cfg.Webhooks.EventTypes,
+			MaxRetries:         cfg.Webhooks.MaxRetries,
+			RetryBaseDelay:     cfg.Webhooks.RetryBaseDelay,
+			Timeout:            cfg.Webhooks.Timeout,
+			AllowInternalHosts: cfg.Webhooks.AllowInternalHosts,
+		}, logger)
+		if messageQueue != nil {
+			if err := webhookService.Start(eventStore); err != nil {
+				logger.Warn("Webhook delivery not started", zap.Error(err))
+			}
+		}
+	}
+
+	// Notification events go out by email, push and SMS as each user opted in
+	notificationService := notification.NewService(userRepo, logger)
+	var receiptMailer ports.EmailService
+	notificationService.SetPreferenceRepository(repos.NotificationPrefs)
+	if emailAdapter, err := notificationAdapter.NewEmailAdapter(email.ConfigFromSettings(cfg.Notification.Email), logger); err != nil {
+		logger.Warn("Email notifications disabled", zap.Error(err))
+	} else {
+		emailAdapter.SetPreferenceRepository(repos.NotificationPrefs)
+		notificationService.SetEmailService(emailAdapter)
+		receiptMailer = emailAdapter
+	}
+	notificationService.SetPushSender(notificationAdapter.NewPushAdapter(cfg.Notification.Push.ServerKey, cfg.Notification.Push.ProjectID, logger))
+	notificationService.SetSMSSender(notificationAdapter.NewSMSAdapter(cfg.Notification.SMS.AccountSID, cfg.Notification.SMS.AuthToken, cfg.Notification.SMS.From, logger))
+	if messageQueue != nil {
+		if err := notificationService.Start(messageQueue); err != nil {
+			logger.Warn("Notification delivery not started", zap.Error(err))
+		}
+	}
+
+	// Circuit breakers, one per downstream dependency, all listed for admins
+	breakers := circuitbreaker.NewManager(logger)
+
+	// Payments through the configured providers; their webhooks update them
+	walletService := paymentService.NewWalletService(repos.Wallets, logger)
+	if len(cfg.Payment.FXRates) > 0 {
+		walletService.SetFXRateSource(paymentService.NewStaticFXRates(cfg.Payment.Stripe.Currency, cfg.Payment.FXRates))
+	}
+	payments, err := paymentService.NewService(&paymentService.Config{
+		DefaultProvider:     domain.PaymentProviderStripe,
+		DefaultCurrency:     cfg.Payment.Stripe.Currency,
+		FXRates:             cfg.Payment.FXRates,
+		StripeSecretKey:     cfg.Payment.Stripe.SecretKey,
+		StripeWebhookSecret: cfg.Payment.Stripe.WebhookSecret,
+		Breakers:            breakers,
+	}, repos.Payments, walletService, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize payments", zap.Error(err))
+	}
+	payments.SetCardRepository(repos.Cards)
+	// Refunds are recorded in the audit log, whoever issues them
+	auditedPayments := admin.NewAuditedPaymentService(payments, repos.Audit, logger)
+
+	// Stopped sessions are charged from the wallet, or else the card
+	var settlement *transaction.BillingOrchestrator
+	if cfg.Payment.Settlement.Enabled {
+		settlement = transaction.NewBillingOrchestrator(transactionRepo, billingService, auditedPayments, userRepo, transaction.BillingOrchestratorConfig{
+			MaxAttempts:   cfg.Payment.Settlement.MaxAttempts,
+			RetryDelay:    cfg.Payment.Settlement.RetryDelay,
+			SweepInterval: cfg.Payment.Settlement.SweepInterval,
+		}, logger)
+		if receiptMailer != nil {
+			settlement.SetEmailService(receiptMailer)
+		}
+		settlement.SetAlertRepository(repos.Alerts)
+		settlement.StartRetrySweep(context.Background())
+		if cfg.Payment.Settlement.HoldMaxEnergyKWh > 0 {
+			transactionService.(*transaction.Service).SetPaymentHolds(auditedPayments, repos.Wallets, repos.Users, transaction.PaymentHoldConfig{
+				MaxEnergyKWh: cfg.Payment.Settlement.HoldMaxEnergyKWh,
+			})
+		}
+	}
+
+	// Reservations hold the connector on the station and follow the session
+	// started on it until it stops
+	reservationService := reservation.NewService(repos.Reservations, chargePointRepo, walletService, nil, logger)
+	reservationService.SetOCPPService(ocppCommands)
+	reservationService.SetTransactionService(transactionService)
+	reservationService.SetMaintenanceRepository(repos.MaintenanceWindows)
+	reservationService.SetMessageQueue(eventStore)
+	if storageLocker != nil {
+		reservationService.SetLocker(storageLocker)
+		transactionService.(*transaction.Service).SetLocker(storageLocker)
+	}
+	ocppServer.SetReservationSync(reservationService)
+	if messageQueue != nil {
+		if err := reservationService.StartTransactionSync(eventStore); err != nil {
+			logger.Warn("Reservation completion not started", zap.Error(err))
+		}
+	}
+
+	// Admin console over the same repositories; station views read the live OCPP state
+	adminService := admin.NewService(userRepo, chargePointRepo, transactionRepo, repos.Payments, repos.Reservations, repos.Alerts, logger)
+	adminService.SetTransactionService(transactionService)
+	adminService.SetWalletRepository(repos.Wallets)
+	adminService.SetV2GRepository(repos.V2G)
+	adminService.SetV2GCompensationCalculator(v2gService)
+	adminService.SetVoiceCommandRepository(voiceCommandRepo)
+	adminService.SetCardRepository(repos.Cards)
+	adminService.SetCustomerDataEraser(ocppServer)
+	adminService.SetMessageQueue(eventStore)
+	adminService.SetConnectionEventRepository(repos.ConnectionEvents)
+	adminService.SetMeterValueRepository(repos.MeterValues)
+	adminService.SetChargingLimitSource(ocppServer)
+	adminService.SetAuditRepository(repos.Audit)
+	// Admin mutations are recorded in the audit log with the values they replaced
+	auditedAdmin := admin.NewAuditedService(adminService, repos.Audit, userRepo, chargePointRepo, repos.Alerts, logger)
+
 	// 11. Initialize WebSocket Hub (for real-time updates)
 	wsHub := wsAdapter.NewHub()
 	go wsHub.Run()
@@ -154,8 +450,58 @@ func main() {
 		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
 		AllowMethods: "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 	}))
-	app.Use(middleware.RateLimit())
-	app.Use(middleware.CircuitBreakerWithLogger(logger))
+	// Rate limiting: per user (with role limits) on protected routes, per IP otherwise.
+	// Counters are shared through Redis when REDIS_URL is set.
+	var rateLimitStore ports.RateLimitStore = cache.NewLocalRateLimitStore()
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisStore, err := cache.NewRedisRateLimitStore(redisURL, logger)
+		if err != nil {
+			logger.Warn("Redis not available, using in-memory rate limits", zap.Error(err))
+		} else {
+			rateLimitStore = redisStore
+		}
+	}
+	rateLimitCfg := middleware.DefaultUserRateLimitConfig()
+	rateLimitCfg.Logger = logger
+	if cfg.RateLimiting.Window > 0 {
+		rateLimitCfg.Window = cfg.RateLimiting.Window
+	}
+	if cfg.RateLimiting.MaxRequests > 0 {
+		rateLimitCfg.UserMax = cfg.RateLimiting.MaxRequests
+	}
+	rateLimiter := middleware.UserRateLimit(rateLimitStore, rateLimitCfg)
+
+	// Replay protection for sensitive POSTs; nonces are shared through Redis when REDIS_URL is set
+	var nonceStore ports.NonceStore = cache.NewLocalNonceStore()
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisNonces, err := cache.NewRedisNonceStore(redisURL, logger)
+		if err != nil {
+			logger.Warn("Redis not available, using in-memory nonces", zap.Error(err))
+		} else {
+			nonceStore = redisNonces
+		}
+	}
+	replayCfg := middleware.DefaultReplayProtectionConfig()
+	replayCfg.Logger = logger
+	if cfg.Security.ReplayWindow > 0 {
+		replayCfg.Window = cfg.Security.ReplayWindow
+	}
+	replayGuard := middleware.ReplayProtection(nonceStore, replayCfg)
+
+	// Payment providers cannot send X-Timestamp/X-Nonce; their signed
+	// timestamp and signature are checked instead
+	webhookReplayCfg := replayCfg
+	webhookReplayCfg.Window = 72 * time.Hour
+	if cfg.Security.WebhookReplayWindow > 0 {
+		webhookReplayCfg.Window = cfg.Security.WebhookReplayWindow
+	}
+	webhookReplayCfg.Source = paymentService.SignedReplaySource
+	webhookReplayCfg.Scope = paymentService.WebhookReplayScope
+	webhookReplayGuard := middleware.ReplayProtection(nonceStore, webhookReplayCfg)
+
+	// Circuit breakers are scoped per downstream dependency (see route groups below)
+	dbBreaker := middleware.DownstreamCircuitBreaker(breakers, circuitbreaker.DownstreamDatabase, logger)
+	ocppBreaker := middleware.DownstreamCircuitBreaker(breakers, circuitbreaker.DownstreamOCPP, logger)
 	// app.Use(middleware.RequestID()) // Assuming this exists or uses fiber's
 	// app.Use(telemetry.HTTPMiddleware()) // Assuming this exists
 
@@ -164,8 +510,7 @@ func main() {
 		return c.SendString("OK")
 	})
 	app.Get("/health/ready", func(c *fiber.Ctx) error {
-		// Check NietzscheDB
-		if err := db.Client.HealthCheck(context.Background()); err != nil {
+		if err := repos.Healthy(context.Background()); err != nil {
 			return c.Status(503).SendString("Database not ready")
 		}
 		if err := localCache.Ping(); err != nil {
@@ -187,34 +532,86 @@ func main() {
 
 	// Auth routes (public)
 	authHandler := handlers.NewAuthHandler(authService, logger)
-	v1.Post("/auth/login", authHandler.Login)
-	v1.Post("/auth/register", authHandler.Register)
-	v1.Post("/auth/refresh", authHandler.RefreshToken)
+	v1.Post("/auth/login", rateLimiter, authHandler.Login)
+	v1.Post("/auth/register", rateLimiter, authHandler.Register)
+	v1.Post("/auth/refresh", rateLimiter, authHandler.RefreshToken)
+
+	// Payment provider webhooks (public, authenticated by the provider signature)
+	paymentService.NewHandler(auditedPayments, logger).RegisterRoutes(app, webhookReplayGuard)
+
+	// Admin routes (admins only)
+	adminHandler := admin.NewHandler(auditedAdmin, breakers)
+	adminHandler.SetConnectionTester(ocppServer)
+	adminHandler.RegisterRoutes(app, middleware.AuthRequired(authService), middleware.RoleRequired(domain.UserRoleAdmin))
+
+	// Reservation routes
+	reservation.NewHandler(reservationService).RegisterRoutes(app, middleware.AuthRequired(authService))
 
-	// Protected routes
-	protected := v1.Group("", middleware.AuthRequired(authService))
+	// Protected routes (rate limited per authenticated user)
+	protected := v1.Group("", middleware.AuthRequired(authService), rateLimiter)
 
 	// Auth protected routes
 	protected.Get("/auth/me", authHandler.Me)
 
 	// Device routes (nearby MUST come before :id to avoid matching "nearby" as id param)
 	deviceHandler := handlers.NewDeviceHandler(deviceService, logger)
-	protected.Get("/devices", deviceHandler.List)
-	protected.Get("/devices/nearby", deviceHandler.GetNearby)
-	protected.Get("/devices/:id", deviceHandler.Get)
-	protected.Patch("/devices/:id/status", deviceHandler.UpdateStatus)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryRepo, logger)
+	commandHandler := handlers.NewDeviceCommandHandler(ocppCommands, firmwareService, deviceService, logger)
+	commandHandler.SetLogRequestRepository(logRequestRepo)
+	commandHandler.SetFirmwarePublicationRepository(repos.FirmwarePublications)
+	commandHandler.SetTransactionService(transactionService)
+	// Device reads are guarded by the database breaker and OCPP commands by
+	// their own, so a station outage does not reject plain reads. Group
+	// middleware applies to every route under the prefix, hence per route.
+	// Commands to stations are for operators only.
+	devices := protected.Group("/devices")
+	devices.Get("", dbBreaker, deviceHandler.List)
+	devices.Get("/nearby", dbBreaker, deviceHandler.GetNearby)
+	commandHandler.RegisterRoutes(devices, middleware.RoleRequired(domain.UserRoleAdmin, domain.UserRoleOperator), ocppBreaker, dbBreaker)
+	devices.Get("/:id", dbBreaker, deviceHandler.Get)
+	devices.Get("/:id/inventory", dbBreaker, inventoryHandler.Get)
+	devices.Get("/:id/connections", dbBreaker, handlers.NewConnectionHandler(repos.ConnectionEvents, logger).List)
+	devices.Get("/:id/charging-profiles", dbBreaker, handlers.NewChargingProfileHandler(repos.ChargingProfiles, logger).Get)
+	devices.Patch("/:id/status", dbBreaker, deviceHandler.UpdateStatus)
 
 	// Transaction routes (specific paths MUST come before :id to avoid matching as param)
 	txHandler := handlers.NewTransactionHandler(transactionService, logger)
-	protected.Post("/transactions/start", txHandler.Start)
-	protected.Get("/transactions/history", txHandler.GetHistory)
-	protected.Get("/transactions/active", txHandler.GetActive)
-	protected.Post("/transactions/:id/stop", txHandler.Stop)
-	protected.Get("/transactions/:id", txHandler.Get)
+	transactions := protected.Group("/transactions", dbBreaker)
+	transactions.Post("/start", txHandler.Start)
+	transactions.Get("/history", txHandler.GetHistory)
+	transactions.Get("/active", txHandler.GetActive)
+	transactions.Get("/active/projection", txHandler.GetProjection)
+	transactions.Post("/:id/stop", txHandler.Stop)
+	transactions.Get("/:id", txHandler.Get)
+
+	// Scan to charge: walk-up users start on the connector whose QR they scanned
+	chargeHandler := handlers.NewChargeHandler(transactionService.(*transaction.Service), logger)
+	protected.Post("/charge/scan", dbBreaker, chargeHandler.Scan)
+
+	// Webhook routes
+	if webhookService != nil {
+		webhookHandler := handlers.NewWebhookHandler(webhookService, logger)
+		webhooks := protected.Group("/webhooks", dbBreaker)
+		webhooks.Post("", webhookHandler.Create)
+		webhooks.Get("", webhookHandler.List)
+		webhooks.Get("/:id", webhookHandler.Get)
+		webhooks.Patch("/:id", webhookHandler.Update)
+		webhooks.Delete("/:id", webhookHandler.Delete)
+		webhooks.Get("/:id/deliveries", webhookHandler.Deliveries)
+	}
+
+	// Invoice routes
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService, logger)
+	protected.Get("/invoices/:id.pdf", dbBreaker, invoiceHandler.GetPDF)
+
+	// Notification preferences of the caller
+	notificationHandler := handlers.NewNotificationHandler(notificationService, logger)
+	protected.Get("/users/me/notifications", dbBreaker, notificationHandler.GetPreferences)
+	protected.Put("/users/me/notifications", dbBreaker, notificationHandler.UpdatePreferences)
 
 	// Voice routes
 	voiceHandler := handlers.NewVoiceHandler(voiceAssistant, logger)
-	protected.Post("/voice/command", voiceHandler.ProcessCommand)
+	protected.Post("/voice/command", replayGuard, voiceHandler.ProcessCommand)
 	protected.Get("/voice/history", voiceHandler.GetHistory)
 
 	// WebSocket routes
@@ -252,7 +649,7 @@ func main() {
 
 	// 15. Start Background Workers (only if NATS available)
 	if messageQueue != nil {
-		go startBackgroundWorkers(messageQueue, billingService, stripeGateway, transactionRepo, logger)
+		go startBackgroundWorkers(messageQueue, billingService, settlement, stripeGateway, transactionRepo, logger)
 	}
 
 	// 16. Start HTTP Server
@@ -273,17 +670,27 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Stations finish what they are sending before HTTP goes away; the drain
+	// timeout stays inside the overall shutdown deadline
+	drainTimeout := cfg.OCPP.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = 20 * time.Second
+	}
+	drainCtx, cancelDrain := context.WithTimeout(ctx, drainTimeout)
+	if err := ocppServer.Drain(drainCtx); err != nil {
+		logger.Warn("OCPP drain incomplete, connections closed", zap.Error(err))
+	}
+	cancelDrain()
+
 	if err := app.ShutdownWithContext(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
-	ocppServer.Stop()
-
 	logger.Info("Server exited gracefully")
 }
 
 // startBackgroundWorkers starts async jobs like billing, analytics, etc.
-func startBackgroundWorkers(mq queue.MessageQueue, billing *transaction.BillingService, pg ports.PaymentGateway, txRepo ports.TransactionRepository, logger *zap.Logger) {
+func startBackgroundWorkers(mq queue.MessageQueue, billing *transaction.BillingService, settlement *transaction.BillingOrchestrator, pg ports.PaymentGateway, txRepo ports.TransactionRepository, logger *zap.Logger) {
 	logger.Info("Starting background workers")
 
 	// Worker 1: Process billing payment events
@@ -314,19 +721,19 @@ func startBackgroundWorkers(mq queue.MessageQueue, billing *transaction.BillingS
 		return nil
 	})
 
-	// Worker 2: Process general billing events
-	mq.Subscribe("billing.events", func(msg []byte) error {
-		logger.Info("Processing billing event", zap.ByteString("msg", msg))
-		return nil
-	})
-
-	// Worker 3: Send notifications
-	mq.Subscribe("notifications.events", func(msg []byte) error {
-		logger.Info("Sending notification", zap.ByteString("msg", msg))
-		return nil
-	})
+	// Worker 2: Charge stopped sessions, or just log them when settlement is off
+	if settlement != nil {
+		if err := settlement.Start(mq); err != nil {
+			logger.Error("Session settlement not started", zap.Error(err))
+		}
+	} else {
+		mq.Subscribe(transaction.BillingSubject, func(msg []byte) error {
+			logger.Info("Processing billing event", zap.ByteString("msg", msg))
+			return nil
+		})
+	}
 
-	// Worker 4: Analytics aggregation
+	// Worker 3: Analytics aggregation
 	ticker := time.NewTicker(5 * time.Minute)
 	for range ticker.C {
 		logger.Info("Running analytics aggregation")
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+
+	"go.uber.org/zap"
+)
+
+// cacheAuthorization records the CSMS verdict on idToken in the simulated
+// Authorization Cache
+func (s *Simulator) cacheAuthorization(idToken, status string) {
+	if idToken == "" || status == "" {
+		return
+	}
+	s.authMu.Lock()
+	s.authCache[idToken] = status
+	s.authMu.Unlock()
+}
+
+// cachedAuthorization returns the cached status of idToken, or ""
+func (s *Simulator) cachedAuthorization(idToken string) string {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	return s.authCache[idToken]
+}
+
+// handleClearCache empties the Authorization Cache
+func (s *Simulator) handleClearCache(payload json.RawMessage) map[string]interface{} {
+	s.authMu.Lock()
+	cleared := len(s.authCache)
+	s.authCache = make(map[string]string)
+	s.authMu.Unlock()
+
+	s.log.Info("Authorization cache cleared", zap.Int("entries", cleared))
+
+	return map[string]interface{}{
+		"status": "Accepted",
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSimulator_ClearCacheEmptiesAuthorizationCache(t *testing.T) {
+	// Arrange
+	srv, url := startOCPPServer(t)
+	sim := NewSimulator(&SimulatorConfig{ServerURL: url, ChargePointID: "SIM-001", ConnectorCount: 1}, zap.NewNop())
+	deadline := time.Now().Add(2 * time.Second)
+	for sim.Connect() != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the simulator to connect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer sim.Stop()
+	sim.cacheAuthorization("RFID-1", "Accepted")
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := srv.ClearAuthorizationCache(ctx, "SIM-001")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected the cache to be cleared, got %v", err)
+	}
+	if status := sim.cachedAuthorization("RFID-1"); status != "" {
+		t.Errorf("expected RFID-1 to be dropped from the cache, got %q", status)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// CertificateHashData identifies an installed certificate the way OCPP does
+type CertificateHashData struct {
+	HashAlgorithm  string `json:"hashAlgorithm"`
+	IssuerNameHash string `json:"issuerNameHash"`
+	IssuerKeyHash  string `json:"issuerKeyHash"`
+	SerialNumber   string `json:"serialNumber"`
+}
+
+// InstalledCertificate is an entry of the simulated trust store
+type InstalledCertificate struct {
+	Type     string
+	HashData CertificateHashData
+}
+
+// certificateHashData computes SHA256 hash data for a root certificate, whose
+// issuer key is its own public key
+func certificateHashData(cert *x509.Certificate) (CertificateHashData, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return CertificateHashData{}, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	nameHash := sha256.Sum256(cert.RawIssuer)
+	keyHash := sha256.Sum256(spki.PublicKey.Bytes)
+	return CertificateHashData{
+		HashAlgorithm:  "SHA256",
+		IssuerNameHash: hex.EncodeToString(nameHash[:]),
+		IssuerKeyHash:  hex.EncodeToString(keyHash[:]),
+		SerialNumber:   cert.SerialNumber.Text(16),
+	}, nil
+}
+
+func (s *Simulator) handleInstallCertificate(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		CertificateType string `json:"certificateType"`
+		Certificate     string `json:"certificate"`
+	}
+	json.Unmarshal(payload, &req)
+
+	block, _ := pem.Decode([]byte(req.Certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return map[string]interface{}{"status": "Rejected", "statusInfo": map[string]interface{}{"reasonCode": "InvalidCertificate"}}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return map[string]interface{}{"status": "Rejected", "statusInfo": map[string]interface{}{"reasonCode": "InvalidCertificate"}}
+	}
+	hashData, err := certificateHashData(cert)
+	if err != nil {
+		return map[string]interface{}{"status": "Failed"}
+	}
+
+	s.certMu.Lock()
+	s.certificates[hashData.SerialNumber] = InstalledCertificate{Type: req.CertificateType, HashData: hashData}
+	s.certMu.Unlock()
+
+	s.log.Info("Certificate installed",
+		zap.String("type", req.CertificateType),
+		zap.String("serial", hashData.SerialNumber),
+	)
+	return map[string]interface{}{"status": "Accepted"}
+}
+
+func (s *Simulator) handleDeleteCertificate(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		CertificateHashData CertificateHashData `json:"certificateHashData"`
+	}
+	json.Unmarshal(payload, &req)
+
+	s.certMu.Lock()
+	defer s.certMu.Unlock()
+
+	installed, ok := s.certificates[req.CertificateHashData.SerialNumber]
+	if !ok || installed.HashData != req.CertificateHashData {
+		return map[string]interface{}{"status": "NotFound"}
+	}
+	delete(s.certificates, req.CertificateHashData.SerialNumber)
+
+	s.log.Info("Certificate deleted", zap.String("serial", req.CertificateHashData.SerialNumber))
+	return map[string]interface{}{"status": "Accepted"}
+}
+
+func (s *Simulator) handleGetInstalledCertificateIds(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		CertificateType []string `json:"certificateType"`
+	}
+	json.Unmarshal(payload, &req)
+
+	wanted := make(map[string]bool, len(req.CertificateType))
+	for _, t := range req.CertificateType {
+		wanted[t] = true
+	}
+
+	s.certMu.Lock()
+	var chain []map[string]interface{}
+	for _, c := range s.certificates {
+		if len(wanted) > 0 && !wanted[c.Type] {
+			continue
+		}
+		chain = append(chain, map[string]interface{}{
+			"certificateType":     c.Type,
+			"certificateHashData": c.HashData,
+		})
+	}
+	s.certMu.Unlock()
+
+	if len(chain) == 0 {
+		return map[string]interface{}{"status": "NotFound"}
+	}
+	sort.Slice(chain, func(i, j int) bool {
+		return chain[i]["certificateHashData"].(CertificateHashData).SerialNumber <
+			chain[j]["certificateHashData"].(CertificateHashData).SerialNumber
+	})
+	return map[string]interface{}{"status": "Accepted", "certificateHashDataChain": chain}
+}
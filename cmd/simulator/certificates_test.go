@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rootCertificatePEM creates a self-signed root certificate
+func rootCertificatePEM(t *testing.T, serial int64) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func mustJSON(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	return data
+}
+
+func TestSimulatorCertificates_InstallListDelete(t *testing.T) {
+	// Arrange
+	sim := NewSimulator(&SimulatorConfig{ChargePointID: "SIM-001"}, zap.NewNop())
+
+	// Act: install two roots of different types
+	for i, certType := range []string{"CSMSRootCertificate", "V2GRootCertificate"} {
+		resp := sim.handleInstallCertificate(mustJSON(t, map[string]interface{}{
+			"certificateType": certType,
+			"certificate":     rootCertificatePEM(t, int64(0x10+i)),
+		}))
+		if resp["status"] != "Accepted" {
+			t.Fatalf("expected install of %s to be Accepted, got %v", certType, resp["status"])
+		}
+	}
+
+	// Assert: listing returns both, filtering returns one
+	all := sim.handleGetInstalledCertificateIds(mustJSON(t, map[string]interface{}{}))
+	chain, _ := all["certificateHashDataChain"].([]map[string]interface{})
+	if all["status"] != "Accepted" || len(chain) != 2 {
+		t.Fatalf("expected 2 installed certificates, got %v", all)
+	}
+
+	filtered := sim.handleGetInstalledCertificateIds(mustJSON(t, map[string]interface{}{
+		"certificateType": []string{"V2GRootCertificate"},
+	}))
+	v2gChain, _ := filtered["certificateHashDataChain"].([]map[string]interface{})
+	if len(v2gChain) != 1 || v2gChain[0]["certificateType"] != "V2GRootCertificate" {
+		t.Fatalf("expected only the V2G root, got %v", filtered)
+	}
+	hashData := v2gChain[0]["certificateHashData"].(CertificateHashData)
+	if hashData.SerialNumber != "11" || hashData.HashAlgorithm != "SHA256" {
+		t.Errorf("expected SHA256 hash data for serial 11, got %+v", hashData)
+	}
+
+	// Act: delete it
+	deleted := sim.handleDeleteCertificate(mustJSON(t, map[string]interface{}{"certificateHashData": hashData}))
+
+	// Assert
+	if deleted["status"] != "Accepted" {
+		t.Errorf("expected delete to be Accepted, got %v", deleted["status"])
+	}
+	again := sim.handleDeleteCertificate(mustJSON(t, map[string]interface{}{"certificateHashData": hashData}))
+	if again["status"] != "NotFound" {
+		t.Errorf("expected second delete to be NotFound, got %v", again["status"])
+	}
+	remaining := sim.handleGetInstalledCertificateIds(mustJSON(t, map[string]interface{}{
+		"certificateType": []string{"V2GRootCertificate"},
+	}))
+	if remaining["status"] != "NotFound" {
+		t.Errorf("expected no V2G roots left, got %v", remaining)
+	}
+}
+
+func TestSimulatorCertificates_RejectsInvalidCertificate(t *testing.T) {
+	// Arrange
+	sim := NewSimulator(&SimulatorConfig{ChargePointID: "SIM-001"}, zap.NewNop())
+
+	// Act
+	resp := sim.handleInstallCertificate(mustJSON(t, map[string]interface{}{
+		"certificateType": "CSMSRootCertificate",
+		"certificate":     "not a certificate",
+	}))
+
+	// Assert
+	if resp["status"] != "Rejected" {
+		t.Errorf("expected Rejected, got %v", resp["status"])
+	}
+}
+
+func TestSimulatorCertificates_DeleteRequiresMatchingIssuer(t *testing.T) {
+	// Arrange
+	sim := NewSimulator(&SimulatorConfig{ChargePointID: "SIM-001"}, zap.NewNop())
+	sim.handleInstallCertificate(mustJSON(t, map[string]interface{}{
+		"certificateType": "CSMSRootCertificate",
+		"certificate":     rootCertificatePEM(t, 7),
+	}))
+
+	// Act
+	resp := sim.handleDeleteCertificate(mustJSON(t, map[string]interface{}{
+		"certificateHashData": CertificateHashData{HashAlgorithm: "SHA256", IssuerNameHash: "00", IssuerKeyHash: "00", SerialNumber: "7"},
+	}))
+
+	// Assert
+	if resp["status"] != "NotFound" {
+		t.Errorf("expected NotFound for a different issuer, got %v", resp["status"])
+	}
+}
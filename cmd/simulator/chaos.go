@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultReconnectDelay is how long a dropped connection stays down when
+// chaos disconnect names no delay
+const defaultReconnectDelay = 5 * time.Second
+
+// ChaosConfig holds the faults the simulator injects to exercise the CSMS's
+// reconnection, timeout cleanup and fault-alert paths
+type ChaosConfig struct {
+	ResponseDelay time.Duration // Added before answering each CSMS request
+	FaultRate     float64       // Chance, 0 to 1, that a random connector reports Faulted on each heartbeat
+}
+
+// malformedMessages are the broken frames chaos malformed can send
+var malformedMessages = map[string]string{
+	"json":    `[2,"chaos-1","Heartbeat",{`,
+	"frame":   `[2,"chaos-2"]`,
+	"payload": `[2,"chaos-3","StatusNotification",{"timestamp":42,"connectorStatus":true,"evseId":"one"}]`,
+}
+
+func (s *Simulator) responseDelay() time.Duration {
+	s.chaosMu.Lock()
+	defer s.chaosMu.Unlock()
+	return s.chaos.ResponseDelay
+}
+
+// injectRandomFault reports a random connector Faulted with the configured
+// fault rate
+func (s *Simulator) injectRandomFault() {
+	s.chaosMu.Lock()
+	rate := s.chaos.FaultRate
+	s.chaosMu.Unlock()
+
+	if rate <= 0 || len(s.connectors) == 0 || rand.Float64() >= rate {
+		return
+	}
+	connectorID := rand.Intn(len(s.connectors)) + 1
+	s.log.Info("Injecting connector fault", zap.Int("connectorID", connectorID))
+	s.sendStatusNotification(connectorID, "Faulted")
+}
+
+// dropConnection closes the socket under the WebSocket without a close
+// frame, the way a lost network link looks to the CSMS
+func (s *Simulator) dropConnection() error {
+	s.writeMu.Lock()
+	conn := s.conn
+	s.writeMu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	s.log.Info("Dropping connection", zap.Bool("charging", s.isCharging))
+	return conn.UnderlyingConn().Close()
+}
+
+// reconnect dials the OCPP server again after a dropped connection. A
+// station that kept its power does not boot again, so no BootNotification
+// is sent.
+func (s *Simulator) reconnect() error {
+	select {
+	case <-s.stopChan:
+		return fmt.Errorf("simulator stopped")
+	default:
+	}
+	return s.dial()
+}
+
+// sendMalformed sends one of the malformedMessages
+func (s *Simulator) sendMalformed(kind string) error {
+	message, ok := malformedMessages[kind]
+	if !ok {
+		return fmt.Errorf("unknown malformed message %q (json, frame or payload)", kind)
+	}
+	s.log.Info("Sending malformed message", zap.String("kind", kind))
+	return s.write([]byte(message))
+}
+
+// runChaosCommand handles the interactive chaos command
+func (s *Simulator) runChaosCommand(args []string) {
+	if len(args) == 0 || args[0] == "status" {
+		s.chaosMu.Lock()
+		chaos := s.chaos
+		s.chaosMu.Unlock()
+		fmt.Printf("Response delay: %s, fault rate: %g\n", chaos.ResponseDelay, chaos.FaultRate)
+		return
+	}
+
+	switch args[0] {
+	case "disconnect":
+		delay := defaultReconnectDelay
+		if len(args) > 1 {
+			seconds, err := strconv.Atoi(args[1])
+			if err != nil || seconds < 0 {
+				fmt.Printf("Invalid delay: %s\n", args[1])
+				return
+			}
+			delay = time.Duration(seconds) * time.Second
+		}
+		if err := s.dropConnection(); err != nil {
+			fmt.Printf("Failed to drop connection: %v\n", err)
+			return
+		}
+		fmt.Printf("Connection dropped, reconnecting in %s\n", delay)
+		go func() {
+			time.Sleep(delay)
+			if err := s.reconnect(); err != nil {
+				s.log.Error("Reconnect failed", zap.Error(err))
+				return
+			}
+			fmt.Println("Reconnected")
+		}()
+
+	case "malformed":
+		kind := "json"
+		if len(args) > 1 {
+			kind = args[1]
+		}
+		if err := s.sendMalformed(kind); err != nil {
+			fmt.Printf("Failed to send malformed message: %v\n", err)
+			return
+		}
+		fmt.Printf("Sent malformed %s message\n", kind)
+
+	case "delay":
+		if len(args) < 2 {
+			fmt.Println("Usage: chaos delay <ms>")
+			return
+		}
+		ms, err := strconv.Atoi(args[1])
+		if err != nil || ms < 0 {
+			fmt.Printf("Invalid delay: %s\n", args[1])
+			return
+		}
+		s.chaosMu.Lock()
+		s.chaos.ResponseDelay = time.Duration(ms) * time.Millisecond
+		s.chaosMu.Unlock()
+		fmt.Printf("Responses delayed by %dms\n", ms)
+
+	case "faults":
+		if len(args) < 2 {
+			fmt.Println("Usage: chaos faults <rate 0-1>")
+			return
+		}
+		rate, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || rate < 0 || rate > 1 {
+			fmt.Printf("Invalid fault rate: %s\n", args[1])
+			return
+		}
+		s.chaosMu.Lock()
+		s.chaos.FaultRate = rate
+		s.chaosMu.Unlock()
+		fmt.Printf("Connector fault rate set to %g per heartbeat\n", rate)
+
+	case "off":
+		s.chaosMu.Lock()
+		s.chaos = ChaosConfig{}
+		s.chaosMu.Unlock()
+		fmt.Println("Chaos disabled")
+
+	default:
+		fmt.Println("Usage: chaos [status] | disconnect [seconds] | malformed [json|frame|payload] | delay <ms> | faults <rate> | off")
+	}
+}
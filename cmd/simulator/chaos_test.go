@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// waitFor polls cond until it holds or the timeout passes
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+func TestChaos_CSMSRecoversAfterDisconnect(t *testing.T) {
+	// Arrange: the simulator is slow to answer, so a CSMS request is
+	// pending when the connection drops
+	srv, url := startOCPPServer(t)
+	sim := NewSimulator(&SimulatorConfig{
+		ServerURL: url, ChargePointID: "SIM-001", ConnectorCount: 1,
+		Chaos: ChaosConfig{ResponseDelay: time.Second},
+	}, zap.NewNop())
+	if !waitFor(t, 2*time.Second, func() bool { return sim.Connect() == nil }) {
+		t.Fatal("expected the simulator to connect")
+	}
+	defer sim.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	interrupted := make(chan bool, 1)
+	go func() {
+		result, err := srv.TestConnection(ctx, "SIM-001", "Heartbeat")
+		interrupted <- err == nil && !result.Success
+	}()
+	if !waitFor(t, 2*time.Second, func() bool { return srv.GetPendingRequestCount() > 0 }) {
+		t.Fatal("expected a pending request")
+	}
+
+	// Act
+	if err := sim.dropConnection(); err != nil {
+		t.Fatalf("failed to drop connection: %v", err)
+	}
+
+	// Assert: the request is given up and the station seen as gone
+	select {
+	case ok := <-interrupted:
+		if !ok {
+			t.Error("expected the connection test to fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the pending request to be cancelled")
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return !srv.IsConnected("SIM-001") }) {
+		t.Fatal("expected the CSMS to notice the disconnect")
+	}
+	if pending := srv.GetPendingRequestCount(); pending != 0 {
+		t.Errorf("expected no pending requests, got %d", pending)
+	}
+
+	// Act: the station comes back answering promptly
+	sim.runChaosCommand([]string{"off"})
+	if err := sim.reconnect(); err != nil {
+		t.Fatalf("failed to reconnect: %v", err)
+	}
+
+	// Assert
+	if !waitFor(t, 2*time.Second, func() bool { return srv.IsConnected("SIM-001") }) {
+		t.Fatal("expected the CSMS to accept the reconnection")
+	}
+	result, err := srv.TestConnection(ctx, "SIM-001", "Heartbeat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected a successful test after reconnecting, got %+v", result)
+	}
+}
+
+func TestChaos_MalformedMessages(t *testing.T) {
+	// Arrange
+	srv, url := startOCPPServer(t)
+	sim := NewSimulator(&SimulatorConfig{ServerURL: url, ChargePointID: "SIM-002", ConnectorCount: 1}, zap.NewNop())
+	if !waitFor(t, 2*time.Second, func() bool { return sim.Connect() == nil }) {
+		t.Fatal("expected the simulator to connect")
+	}
+	defer sim.Stop()
+
+	// Act
+	for kind := range malformedMessages {
+		if err := sim.sendMalformed(kind); err != nil {
+			t.Fatalf("failed to send malformed %s message: %v", kind, err)
+		}
+	}
+
+	// Assert: the CSMS keeps the station and still talks to it
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := srv.TestConnection(ctx, "SIM-002", "Heartbeat")
+	if err != nil || !result.Success {
+		t.Errorf("expected a successful test after malformed messages, got %+v, %v", result, err)
+	}
+}
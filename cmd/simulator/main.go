@@ -25,6 +25,8 @@ var (
 	connectorCount = flag.Int("connectors", 2, "Number of connectors")
 	interactive = flag.Bool("interactive", false, "Enable interactive mode")
 	verbose     = flag.Bool("verbose", false, "Enable verbose logging")
+	chaosDelay  = flag.Duration("chaos-delay", 0, "Delay before answering each CSMS request (fault injection)")
+	chaosFaultRate = flag.Float64("chaos-fault-rate", 0, "Chance (0-1) of a random connector fault on each heartbeat")
 )
 
 func main() {
@@ -58,6 +60,10 @@ func main() {
 		MaxChargePowerKW:  *maxChargePower,
 		MaxDischargePowerKW: *maxDischargePower,
 		ConnectorCount:    *connectorCount,
+		Chaos: ChaosConfig{
+			ResponseDelay: *chaosDelay,
+			FaultRate:     *chaosFaultRate,
+		},
 	}
 
 	// Create and start simulator
@@ -110,6 +116,13 @@ func runInteractiveMode(sim *Simulator, logger *zap.Logger) {
 	fmt.Println("  fault <connector>       - Simulate fault on connector")
 	fmt.Println("  reset                   - Simulate device reset")
 	fmt.Println("  firmware accept|reject  - Respond to firmware update")
+	fmt.Println("  monitor <comp> <var> <value> - Set a monitored variable (sends NotifyEvent on threshold)")
+	fmt.Println("  monitor list            - List monitors configured by the CSMS")
+	fmt.Println("  chaos disconnect [sec]  - Drop the connection mid-session, reconnect after sec (default 5)")
+	fmt.Println("  chaos malformed [kind]  - Send a malformed message (json, frame or payload)")
+	fmt.Println("  chaos delay <ms>        - Delay answers to CSMS requests")
+	fmt.Println("  chaos faults <rate>     - Report random connector faults on heartbeats (0-1)")
+	fmt.Println("  chaos off|status        - Disable or show injected faults")
 	fmt.Println("  quit                    - Exit simulator")
 	fmt.Println("")
 
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// VariableMonitor is a monitor configured by the CSMS via SetVariableMonitoring
+type VariableMonitor struct {
+	ID        int
+	Component string
+	EvseID    int
+	Variable  string
+	Type      string // UpperThreshold, LowerThreshold, Delta
+	Value     float64
+	Severity  int
+	tripped   bool
+	lastValue *float64
+}
+
+func monitorKey(component, variable string) string {
+	return component + "." + variable
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func (s *Simulator) handleSetVariableMonitoring(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		SetMonitoringData []struct {
+			Id        *int    `json:"id"`
+			Value     float64 `json:"value"`
+			Type      string  `json:"type"`
+			Severity  int     `json:"severity"`
+			Component struct {
+				Name string `json:"name"`
+				Evse *struct {
+					Id int `json:"id"`
+				} `json:"evse,omitempty"`
+			} `json:"component"`
+			Variable struct {
+				Name string `json:"name"`
+			} `json:"variable"`
+		} `json:"setMonitoringData"`
+	}
+	json.Unmarshal(payload, &req)
+
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+
+	results := make([]map[string]interface{}, len(req.SetMonitoringData))
+	for i, m := range req.SetMonitoringData {
+		result := map[string]interface{}{
+			"type":      m.Type,
+			"severity":  m.Severity,
+			"component": m.Component,
+			"variable":  m.Variable,
+		}
+
+		switch m.Type {
+		case "UpperThreshold", "LowerThreshold", "Delta":
+		default:
+			result["status"] = "UnsupportedMonitorType"
+			results[i] = result
+			continue
+		}
+
+		id := s.nextMonitorID
+		if m.Id != nil {
+			id = *m.Id
+		} else {
+			s.nextMonitorID++
+		}
+
+		monitor := &VariableMonitor{
+			ID:        id,
+			Component: m.Component.Name,
+			Variable:  m.Variable.Name,
+			Type:      m.Type,
+			Value:     m.Value,
+			Severity:  m.Severity,
+		}
+		if m.Component.Evse != nil {
+			monitor.EvseID = m.Component.Evse.Id
+		}
+		s.monitors[id] = monitor
+
+		s.log.Info("Variable monitor set",
+			zap.Int("id", id),
+			zap.String("variable", monitorKey(monitor.Component, monitor.Variable)),
+			zap.String("type", monitor.Type),
+			zap.Float64("value", monitor.Value),
+		)
+
+		result["id"] = id
+		result["status"] = "Accepted"
+		results[i] = result
+	}
+
+	return map[string]interface{}{
+		"setMonitoringResult": results,
+	}
+}
+
+func (s *Simulator) handleSetMonitoringBase(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		MonitoringBase string `json:"monitoringBase"`
+	}
+	json.Unmarshal(payload, &req)
+
+	s.log.Info("Set monitoring base", zap.String("base", req.MonitoringBase))
+
+	// FactoryDefault and HardWiredOnly remove custom monitors
+	if req.MonitoringBase != "All" {
+		s.monitorMu.Lock()
+		s.monitors = make(map[int]*VariableMonitor)
+		s.monitorMu.Unlock()
+	}
+
+	return map[string]interface{}{
+		"status": "Accepted",
+	}
+}
+
+// setMonitoredVariable updates a simulated variable and sends a NotifyEvent
+// for every monitor whose limit was crossed. It returns the number of events sent.
+func (s *Simulator) setMonitoredVariable(component, variable string, value float64) int {
+	s.monitorMu.Lock()
+	s.variables[monitorKey(component, variable)] = value
+
+	now := time.Now().Format(time.RFC3339)
+	var events []map[string]interface{}
+	for _, m := range s.monitors {
+		if m.Component != component || m.Variable != variable {
+			continue
+		}
+
+		trigger := "Alerting"
+		fire, cleared := false, false
+		switch m.Type {
+		case "UpperThreshold", "LowerThreshold":
+			exceeded := value > m.Value
+			if m.Type == "LowerThreshold" {
+				exceeded = value < m.Value
+			}
+			fire = exceeded != m.tripped
+			cleared = !exceeded
+			m.tripped = exceeded
+		case "Delta":
+			trigger = "Delta"
+			fire = m.lastValue == nil || math.Abs(value-*m.lastValue) >= m.Value
+			if fire {
+				v := value
+				m.lastValue = &v
+			}
+		}
+		if !fire {
+			continue
+		}
+
+		event := map[string]interface{}{
+			"eventId":               len(events) + 1,
+			"timestamp":             now,
+			"trigger":               trigger,
+			"actualValue":           formatFloat(value),
+			"cleared":               cleared,
+			"variableMonitoringId":  m.ID,
+			"eventNotificationType": "CustomMonitor",
+			"component":             map[string]interface{}{"name": component},
+			"variable":              map[string]interface{}{"name": variable},
+		}
+		if m.EvseID > 0 {
+			event["component"].(map[string]interface{})["evse"] = map[string]interface{}{"id": m.EvseID}
+		}
+		events = append(events, event)
+	}
+
+	seqNo := s.eventSeqNo
+	if len(events) > 0 {
+		s.eventSeqNo++
+	}
+	s.monitorMu.Unlock()
+
+	if len(events) == 0 {
+		return 0
+	}
+
+	s.sendCall("NotifyEvent", map[string]interface{}{
+		"generatedAt": now,
+		"seqNo":       seqNo,
+		"eventData":   events,
+	})
+	return len(events)
+}
+
+func (s *Simulator) printMonitors() {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+
+	if len(s.monitors) == 0 {
+		fmt.Println("No monitors configured")
+		return
+	}
+	for _, m := range s.monitors {
+		current := "unset"
+		if v, ok := s.variables[monitorKey(m.Component, m.Variable)]; ok {
+			current = formatFloat(v)
+		}
+		fmt.Printf("  #%d %s %s %g (severity %d, current %s)\n",
+			m.ID, monitorKey(m.Component, m.Variable), m.Type, m.Value, m.Severity, current)
+	}
+}
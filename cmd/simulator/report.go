@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// reportPartSize is the number of variables sent per NotifyReport message
+const reportPartSize = 4
+
+func (s *Simulator) handleGetBaseReport(payload json.RawMessage) (map[string]interface{}, func()) {
+	var req struct {
+		RequestId  int    `json:"requestId"`
+		ReportBase string `json:"reportBase"`
+	}
+	json.Unmarshal(payload, &req)
+
+	s.log.Info("Get base report", zap.Int("requestId", req.RequestId), zap.String("reportBase", req.ReportBase))
+
+	switch req.ReportBase {
+	case "FullInventory", "ConfigurationInventory", "SummaryInventory":
+	default:
+		return map[string]interface{}{"status": "NotSupported"}, nil
+	}
+
+	return map[string]interface{}{"status": "Accepted"}, func() {
+		s.sendNotifyReport(req.RequestId, s.reportData())
+	}
+}
+
+// reportData describes every variable the simulator knows about
+func (s *Simulator) reportData() []map[string]interface{} {
+	readOnly := func(component map[string]interface{}, variable, value, dataType string) map[string]interface{} {
+		return map[string]interface{}{
+			"component": component,
+			"variable":  map[string]interface{}{"name": variable},
+			"variableAttribute": []map[string]interface{}{
+				{"type": "Actual", "value": value, "mutability": "ReadOnly"},
+			},
+			"variableCharacteristics": map[string]interface{}{
+				"dataType":           dataType,
+				"supportsMonitoring": false,
+			},
+		}
+	}
+
+	station := map[string]interface{}{"name": "ChargingStation"}
+	data := []map[string]interface{}{
+		readOnly(station, "Model", s.config.Model, "string"),
+		readOnly(station, "VendorName", s.config.Vendor, "string"),
+		readOnly(station, "SerialNumber", s.config.SerialNumber, "string"),
+		readOnly(station, "FirmwareVersion", s.config.FirmwareVersion, "string"),
+	}
+
+	for _, c := range s.connectors {
+		evse := map[string]interface{}{
+			"name": "EVSE",
+			"evse": map[string]interface{}{"id": c.ID},
+		}
+		data = append(data, readOnly(evse, "AvailabilityState", c.Status, "OptionList"))
+	}
+
+	if s.config.V2GCapable {
+		data = append(data, readOnly(map[string]interface{}{"name": "V2XChargingCtrlr"}, "Enabled", "true", "boolean"))
+	}
+
+	// Variables set through the monitor command can be monitored by the CSMS
+	s.monitorMu.Lock()
+	for key, value := range s.variables {
+		parts := strings.SplitN(key, ".", 2)
+		item := readOnly(map[string]interface{}{"name": parts[0]}, parts[1], formatFloat(value), "decimal")
+		item["variableCharacteristics"].(map[string]interface{})["supportsMonitoring"] = true
+		data = append(data, item)
+	}
+	s.monitorMu.Unlock()
+
+	return data
+}
+
+// sendNotifyReport sends the report in parts of reportPartSize, flagging all
+// but the last one with tbc
+func (s *Simulator) sendNotifyReport(requestID int, data []map[string]interface{}) {
+	generatedAt := time.Now().Format(time.RFC3339)
+
+	for seqNo := 0; ; seqNo++ {
+		start := seqNo * reportPartSize
+		end := start + reportPartSize
+		if end > len(data) {
+			end = len(data)
+		}
+		tbc := end < len(data)
+
+		payload := map[string]interface{}{
+			"requestId":   requestID,
+			"generatedAt": generatedAt,
+			"tbc":         tbc,
+			"seqNo":       seqNo,
+			"reportData":  data[start:end],
+		}
+		if _, err := s.sendCall("NotifyReport", payload); err != nil {
+			s.log.Error("NotifyReport failed", zap.Int("seqNo", seqNo), zap.Error(err))
+			return
+		}
+
+		if !tbc {
+			s.log.Info("Base report sent", zap.Int("requestId", requestID), zap.Int("parts", seqNo+1))
+			return
+		}
+	}
+}
@@ -28,6 +28,7 @@ type SimulatorConfig struct {
 	MaxChargePowerKW    float64
 	MaxDischargePowerKW float64
 	ConnectorCount      int
+	Chaos               ChaosConfig // Faults injected from the start
 }
 
 // ConnectorState represents a connector's state
@@ -56,6 +57,26 @@ type Simulator struct {
 	pendingMsgs map[string]chan []byte
 	mu          sync.RWMutex
 
+	// Variable monitoring
+	monitors      map[int]*VariableMonitor
+	variables     map[string]float64 // "Component.Variable" → simulated value
+	nextMonitorID int
+	eventSeqNo    int
+	monitorMu     sync.Mutex
+
+	// Trust store managed by InstallCertificate/DeleteCertificate
+	certificates map[string]InstalledCertificate // serial number → certificate
+	certMu       sync.Mutex
+
+	// Authorization Cache, emptied by ClearCache
+	authCache map[string]string // idToken → status the CSMS returned
+	authMu    sync.Mutex
+
+	// Faults injected for resilience testing, see chaos.go
+	chaos   ChaosConfig
+	chaosMu sync.Mutex
+
+	writeMu     sync.Mutex // The connection allows one writer at a time
 	stopChan    chan struct{}
 	wg          sync.WaitGroup
 }
@@ -77,11 +98,41 @@ func NewSimulator(config *SimulatorConfig, log *zap.Logger) *Simulator {
 		pendingMsgs: make(map[string]chan []byte),
 		stopChan:    make(chan struct{}),
 		heartbeatInterval: 300,
+		monitors:      make(map[int]*VariableMonitor),
+		variables:     make(map[string]float64),
+		nextMonitorID: 1,
+		certificates:  make(map[string]InstalledCertificate),
+		authCache:     make(map[string]string),
+		chaos:         config.Chaos,
 	}
 }
 
 // Connect connects to the OCPP server
 func (s *Simulator) Connect() error {
+	if err := s.dial(); err != nil {
+		return err
+	}
+
+	// Send BootNotification
+	resp, err := s.sendBootNotification()
+	if err != nil {
+		s.log.Error("BootNotification failed", zap.Error(err))
+	} else {
+		s.log.Info("BootNotification response", zap.Any("response", resp))
+		if interval, ok := resp["interval"].(float64); ok {
+			s.heartbeatInterval = int(interval)
+		}
+	}
+
+	// Start heartbeat goroutine
+	s.wg.Add(1)
+	go s.heartbeatLoop()
+
+	return nil
+}
+
+// dial opens the WebSocket to the OCPP server and starts reading from it
+func (s *Simulator) dial() error {
 	url := fmt.Sprintf("%s/%s", s.config.ServerURL, s.config.ChargePointID)
 
 	dialer := websocket.Dialer{
@@ -93,7 +144,9 @@ func (s *Simulator) Connect() error {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 
+	s.writeMu.Lock()
 	s.conn = conn
+	s.writeMu.Unlock()
 	s.log.Info("Connected to OCPP server",
 		zap.String("url", url),
 		zap.String("chargePointID", s.config.ChargePointID),
@@ -101,22 +154,7 @@ func (s *Simulator) Connect() error {
 
 	// Start message reader
 	s.wg.Add(1)
-	go s.readMessages()
-
-	// Send BootNotification
-	resp, err := s.sendBootNotification()
-	if err != nil {
-		s.log.Error("BootNotification failed", zap.Error(err))
-	} else {
-		s.log.Info("BootNotification response", zap.Any("response", resp))
-		if interval, ok := resp["interval"].(float64); ok {
-			s.heartbeatInterval = int(interval)
-		}
-	}
-
-	// Start heartbeat goroutine
-	s.wg.Add(1)
-	go s.heartbeatLoop()
+	go s.readMessages(conn)
 
 	return nil
 }
@@ -124,14 +162,17 @@ func (s *Simulator) Connect() error {
 // Stop stops the simulator
 func (s *Simulator) Stop() {
 	close(s.stopChan)
-	if s.conn != nil {
-		s.conn.Close()
+	s.writeMu.Lock()
+	conn := s.conn
+	s.writeMu.Unlock()
+	if conn != nil {
+		conn.Close()
 	}
 	s.wg.Wait()
 }
 
-// readMessages reads and processes incoming messages
-func (s *Simulator) readMessages() {
+// readMessages reads and processes incoming messages on conn until it closes
+func (s *Simulator) readMessages(conn *websocket.Conn) {
 	defer s.wg.Done()
 
 	for {
@@ -139,7 +180,7 @@ func (s *Simulator) readMessages() {
 		case <-s.stopChan:
 			return
 		default:
-			_, message, err := s.conn.ReadMessage()
+			_, message, err := conn.ReadMessage()
 			if err != nil {
 				s.log.Error("Read error", zap.Error(err))
 				return
@@ -196,6 +237,7 @@ func (s *Simulator) handleServerRequest(msgID, action string, payload json.RawMe
 	s.log.Info("Received server request", zap.String("action", action))
 
 	var response interface{}
+	var followUp func() // runs after the CallResult has been sent
 
 	switch action {
 	case "RequestStartTransaction":
@@ -218,14 +260,45 @@ func (s *Simulator) handleServerRequest(msgID, action string, payload json.RawMe
 		response = s.handleSetVariables(payload)
 	case "UnlockConnector":
 		response = s.handleUnlockConnector(payload)
+	case "ClearCache":
+		response = s.handleClearCache(payload)
 	case "ChangeAvailability":
 		response = s.handleChangeAvailability(payload)
+	case "CostUpdated":
+		response = s.handleCostUpdated(payload)
+	case "GetTransactionStatus":
+		response = s.handleGetTransactionStatus(payload)
+	case "ReserveNow":
+		response = s.handleReserveNow(payload)
+	case "CancelReservation":
+		response = s.handleCancelReservation(payload)
+	case "SetVariableMonitoring":
+		response = s.handleSetVariableMonitoring(payload)
+	case "SetMonitoringBase":
+		response = s.handleSetMonitoringBase(payload)
+	case "GetBaseReport":
+		response, followUp = s.handleGetBaseReport(payload)
+	case "DataTransfer":
+		response = s.handleDataTransfer(payload)
+	case "InstallCertificate":
+		response = s.handleInstallCertificate(payload)
+	case "DeleteCertificate":
+		response = s.handleDeleteCertificate(payload)
+	case "GetInstalledCertificateIds":
+		response = s.handleGetInstalledCertificateIds(payload)
 	default:
 		s.sendCallError(msgID, "NotImplemented", fmt.Sprintf("Action %s not implemented", action))
 		return
 	}
 
+	if delay := s.responseDelay(); delay > 0 {
+		time.Sleep(delay)
+	}
 	s.sendCallResult(msgID, response)
+
+	if followUp != nil {
+		go followUp()
+	}
 }
 
 // --- Request Handlers ---
@@ -513,6 +586,99 @@ func (s *Simulator) handleUnlockConnector(payload json.RawMessage) map[string]in
 	}
 }
 
+func (s *Simulator) handleReserveNow(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		Id             int    `json:"id"`
+		ExpiryDateTime string `json:"expiryDateTime"`
+		EvseId         *int   `json:"evseId"`
+	}
+	json.Unmarshal(payload, &req)
+
+	s.log.Info("Reservation received",
+		zap.Int("reservationId", req.Id),
+		zap.String("expiry", req.ExpiryDateTime),
+	)
+
+	return map[string]interface{}{
+		"status": "Accepted",
+	}
+}
+
+func (s *Simulator) handleCancelReservation(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		ReservationId int `json:"reservationId"`
+	}
+	json.Unmarshal(payload, &req)
+
+	s.log.Info("Reservation cancelled", zap.Int("reservationId", req.ReservationId))
+
+	return map[string]interface{}{
+		"status": "Accepted",
+	}
+}
+
+// handleDataTransfer echoes the vendor data back so CSMS-side extensions
+// can be exercised without real hardware
+func (s *Simulator) handleDataTransfer(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		VendorId  string          `json:"vendorId"`
+		MessageId string          `json:"messageId"`
+		Data      json.RawMessage `json:"data"`
+	}
+	json.Unmarshal(payload, &req)
+
+	s.log.Info("DataTransfer received",
+		zap.String("vendorId", req.VendorId),
+		zap.String("messageId", req.MessageId),
+	)
+
+	response := map[string]interface{}{
+		"status": "Accepted",
+	}
+	if len(req.Data) > 0 {
+		response["data"] = req.Data
+	}
+	return response
+}
+
+func (s *Simulator) handleCostUpdated(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		TotalCost     float64 `json:"totalCost"`
+		TransactionId string  `json:"transactionId"`
+	}
+	json.Unmarshal(payload, &req)
+
+	s.log.Info("Running cost updated",
+		zap.String("transactionID", req.TransactionId),
+		zap.Float64("totalCost", req.TotalCost),
+	)
+
+	return map[string]interface{}{}
+}
+
+// handleGetTransactionStatus reports whether the asked transaction is the
+// one charging. The simulator sends its messages right away, so none are
+// ever queued.
+func (s *Simulator) handleGetTransactionStatus(payload json.RawMessage) map[string]interface{} {
+	var req struct {
+		TransactionId string `json:"transactionId"`
+	}
+	json.Unmarshal(payload, &req)
+
+	response := map[string]interface{}{
+		"messagesInQueue": false,
+	}
+	if req.TransactionId != "" {
+		response["ongoingIndicator"] = s.isCharging && req.TransactionId == s.currentTxID
+	}
+
+	s.log.Info("Transaction status requested",
+		zap.String("transactionID", req.TransactionId),
+		zap.String("currentTransactionID", s.currentTxID),
+	)
+	return response
+}
+
 func (s *Simulator) handleChangeAvailability(payload json.RawMessage) map[string]interface{} {
 	var req struct {
 		OperationalStatus string `json:"operationalStatus"`
@@ -581,7 +747,7 @@ func (s *Simulator) sendCall(action string, payload interface{}) (map[string]int
 	msg := []interface{}{2, msgID, action, payload}
 	data, _ := json.Marshal(msg)
 
-	if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+	if err := s.write(data); err != nil {
 		return nil, err
 	}
 
@@ -598,13 +764,21 @@ func (s *Simulator) sendCall(action string, payload interface{}) (map[string]int
 func (s *Simulator) sendCallResult(msgID string, payload interface{}) {
 	msg := []interface{}{3, msgID, payload}
 	data, _ := json.Marshal(msg)
-	s.conn.WriteMessage(websocket.TextMessage, data)
+	s.write(data)
 }
 
 func (s *Simulator) sendCallError(msgID, code, desc string) {
 	msg := []interface{}{4, msgID, code, desc, nil}
 	data, _ := json.Marshal(msg)
-	s.conn.WriteMessage(websocket.TextMessage, data)
+	s.write(data)
+}
+
+// write sends one message; calls, results and triggered messages are sent
+// from different goroutines
+func (s *Simulator) write(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteMessage(websocket.TextMessage, data)
 }
 
 func (s *Simulator) sendBootNotification() (map[string]interface{}, error) {
@@ -656,7 +830,14 @@ func (s *Simulator) sendTransactionEvent(eventType, txID string, connectorID int
 		}
 	}
 
-	s.sendCall("TransactionEvent", payload)
+	resp, err := s.sendCall("TransactionEvent", payload)
+	if err != nil || idToken == "" {
+		return
+	}
+	if info, ok := resp["idTokenInfo"].(map[string]interface{}); ok {
+		status, _ := info["status"].(string)
+		s.cacheAuthorization(idToken, status)
+	}
 }
 
 func (s *Simulator) sendMeterValues(evseID, valueWh int) {
@@ -724,6 +905,7 @@ func (s *Simulator) heartbeatLoop() {
 			return
 		case <-ticker.C:
 			s.sendHeartbeat()
+			s.injectRandomFault()
 		}
 	}
 }
@@ -841,6 +1023,24 @@ func (s *Simulator) RunInteractive() {
 				}
 			}
 
+		case "monitor":
+			if len(args) == 1 && args[0] == "list" {
+				s.printMonitors()
+			} else if len(args) < 3 {
+				fmt.Println("Usage: monitor <component> <variable> <value> | monitor list")
+			} else {
+				value, err := strconv.ParseFloat(args[2], 64)
+				if err != nil {
+					fmt.Printf("Invalid value: %s\n", args[2])
+					break
+				}
+				events := s.setMonitoredVariable(args[0], args[1], value)
+				fmt.Printf("Set %s.%s = %g (%d event(s) sent)\n", args[0], args[1], value, events)
+			}
+
+		case "chaos":
+			s.runChaosCommand(args)
+
 		case "quit", "exit":
 			fmt.Println("Goodbye!")
 			return
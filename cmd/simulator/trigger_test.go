@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/ocpp/v201"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// startOCPPServer runs a CSMS on a free local port and returns its URL
+func startOCPPServer(t *testing.T) (*v201.Server, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	srv := v201.NewServer(nil, nil, zap.NewNop())
+	go srv.Start(port)
+	t.Cleanup(srv.Stop)
+	return srv, fmt.Sprintf("ws://127.0.0.1:%d/ocpp", port)
+}
+
+func TestSimulator_AnswersConnectionTest(t *testing.T) {
+	for _, message := range []string{"Heartbeat", "BootNotification"} {
+		t.Run(message, func(t *testing.T) {
+			// Arrange
+			srv, url := startOCPPServer(t)
+			sim := NewSimulator(&SimulatorConfig{ServerURL: url, ChargePointID: "SIM-001", ConnectorCount: 1}, zap.NewNop())
+			deadline := time.Now().Add(2 * time.Second)
+			for sim.Connect() != nil {
+				if time.Now().After(deadline) {
+					t.Fatal("expected the simulator to connect")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			defer sim.Stop()
+
+			// Act
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			result, err := srv.TestConnection(ctx, "SIM-001", message)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Success || result.TriggerStatus != "Accepted" {
+				t.Fatalf("expected a successful test, got %+v", result)
+			}
+			if result.LatencyMs < 100 || result.LatencyMs > 2000 {
+				t.Errorf("expected the simulator's ~100ms delay as latency, got %dms", result.LatencyMs)
+			}
+		})
+	}
+}
+
+func TestConnectionTest_StationNotConnected(t *testing.T) {
+	srv, _ := startOCPPServer(t)
+
+	_, err := srv.TestConnection(context.Background(), "SIM-404", "")
+
+	if !errors.Is(err, domain.ErrChargePointNotConnected) {
+		t.Errorf("expected ErrChargePointNotConnected, got %v", err)
+	}
+}
@@ -4,17 +4,39 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sync"
 
 	"go.uber.org/zap"
 	"nhooyr.io/websocket"
 )
 
+const liveEndpoint = "wss://generativelanguage.googleapis.com/ws/google.ai.generativelanguage.v1alpha.GenerativeService.BidiGenerateContent"
+
 type LiveClient struct {
-	apiKey  string
-	modelID string
-	logger  *zap.Logger
-	conn    *websocket.Conn
+	apiKey    string
+	modelID   string
+	endpoint  string
+	reconnect ReconnectConfig
+	logger    *zap.Logger
+
+	mu          sync.Mutex
+	conn        *websocket.Conn
+	state       ConnectionState
+	reconnectCh chan struct{} // closed when the running reconnect finishes
+	buffer      []bufferedChunk
+	subscribers map[int]chan ConnectionState
+	nextSubID   int
+
+	incoming chan readResult // filled by the read pump of each connection
+}
+
+// readResult is one message (or the terminal error) read from a connection
+type readResult struct {
+	conn *websocket.Conn
+	data []byte
+	err  error
 }
 
 type VoiceConfig struct {
@@ -26,29 +48,50 @@ type VoiceConfig struct {
 
 func NewLiveClient(apiKey string, logger *zap.Logger) *LiveClient {
 	return &LiveClient{
-		apiKey:  apiKey,
-		modelID: "gemini-2.0-flash-exp",
-		logger:  logger,
+		apiKey:      apiKey,
+		modelID:     "gemini-2.0-flash-exp",
+		endpoint:    liveEndpoint,
+		reconnect:   DefaultReconnectConfig(),
+		logger:      logger,
+		state:       StateDisconnected,
+		subscribers: make(map[int]chan ConnectionState),
+		incoming:    make(chan readResult, 16),
 	}
 }
 
 // ConnectVoiceStream estabelece conexão bidirecional com Gemini Live API
 func (c *LiveClient) ConnectVoiceStream(ctx context.Context) error {
-	url := "wss://generativelanguage.googleapis.com/ws/google.ai.generativelanguage.v1alpha.GenerativeService.BidiGenerateContent"
+	c.mu.Lock()
+	c.setStateLocked(StateConnecting)
+	c.mu.Unlock()
+
+	conn, err := c.dial(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.setStateLocked(StateDisconnected)
+		return err
+	}
+	c.conn = conn
+	go c.readPump(conn)
+	c.setStateLocked(StateConnected)
+	return nil
+}
 
+// dial opens a new Live API connection and sends the session setup
+func (c *LiveClient) dial(ctx context.Context) (*websocket.Conn, error) {
 	headers := http.Header{
 		"Content-Type": []string{"application/json"},
 	}
 
-	conn, _, err := websocket.Dial(ctx, url+"?key="+c.apiKey, &websocket.DialOptions{
+	conn, _, err := websocket.Dial(ctx, c.endpoint+"?key="+c.apiKey, &websocket.DialOptions{
 		HTTPHeader: headers,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	c.conn = conn
-
 	// Enviar setup inicial
 	setup := map[string]interface{}{
 		"setup": map[string]interface{}{
@@ -82,47 +125,173 @@ func (c *LiveClient) ConnectVoiceStream(ctx context.Context) error {
 		},
 	}
 
-	return c.send(setup)
+	if err := send(ctx, conn, setup); err != nil {
+		conn.Close(websocket.StatusInternalError, "setup failed")
+		return nil, err
+	}
+	return conn, nil
 }
 
-// SendAudioChunk envia áudio PCM16 para o Gemini
+// SendAudioChunk envia áudio PCM16 para o Gemini. Durante uma reconexão o
+// áudio é mantido em buffer e enviado assim que a conexão volta.
 func (c *LiveClient) SendAudioChunk(audioData []byte) error {
+	conn, err := c.activeConn(context.Background(), audioData)
+	if err != nil || conn == nil {
+		return err
+	}
+
+	if err := send(context.Background(), conn, audioMessage(audioData)); err != nil {
+		c.logger.Warn("Gemini connection dropped while sending audio", zap.Error(err))
+		c.mu.Lock()
+		c.bufferLocked(audioData)
+		c.mu.Unlock()
+		go c.reconnectWithBackoff(context.Background(), conn)
+	}
+	return nil
+}
+
+// ReceiveResponse recebe resposta de voz do Gemini
+func (c *LiveClient) ReceiveResponse(ctx context.Context) (*VoiceResponse, error) {
+	for {
+		conn, err := c.activeConn(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if conn == nil {
+			if err := c.waitReconnect(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var r readResult
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case r = <-c.incoming:
+		}
+
+		if r.conn != conn {
+			// Left over from a connection that was already replaced
+			continue
+		}
+		if r.err != nil {
+			c.logger.Warn("Gemini connection dropped while reading", zap.Error(r.err))
+			if err := c.reconnectWithBackoff(ctx, conn); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var response VoiceResponse
+		if err := json.Unmarshal(r.data, &response); err != nil {
+			return nil, err
+		}
+
+		return &response, nil
+	}
+}
+
+// Interrupt cancela a resposta em andamento quando o usuário começa a falar
+// (barge-in). Mensagens da resposta interrompida que já chegaram são
+// descartadas para não vazarem para o próximo turno.
+func (c *LiveClient) Interrupt(ctx context.Context) error {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		// Nothing is being streamed while reconnecting
+		return nil
+	}
+
 	msg := map[string]interface{}{
 		"realtime_input": map[string]interface{}{
-			"media_chunks": []map[string]string{
-				{
-					"mime_type": "audio/pcm",
-					"data":      base64.StdEncoding.EncodeToString(audioData),
-				},
-			},
+			"activity_start": map[string]interface{}{},
 		},
 	}
+	if err := send(ctx, conn, msg); err != nil {
+		return err
+	}
 
-	return c.send(msg)
+	for {
+		select {
+		case r := <-c.incoming:
+			if r.err != nil && r.conn == conn {
+				c.logger.Warn("Gemini connection dropped while interrupting", zap.Error(r.err))
+				go c.reconnectWithBackoff(context.Background(), conn)
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
 }
 
-// ReceiveResponse recebe resposta de voz do Gemini
-func (c *LiveClient) ReceiveResponse(ctx context.Context) (*VoiceResponse, error) {
-	_, data, err := c.conn.Read(ctx)
-	if err != nil {
-		return nil, err
+// readPump reads a connection until it fails and hands every message to
+// ReceiveResponse. Reads never use a caller's context: cancelling a read
+// closes the websocket, and a cancelled turn must not cost the connection.
+func (c *LiveClient) readPump(conn *websocket.Conn) {
+	for {
+		_, data, err := conn.Read(context.Background())
+		c.incoming <- readResult{conn: conn, data: data, err: err}
+		if err != nil {
+			return
+		}
 	}
+}
+
+// activeConn returns the current connection, connecting first if the client
+// has never connected or a previous reconnect gave up. While a reconnect is
+// running it returns a nil connection and buffers audio, if any.
+func (c *LiveClient) activeConn(ctx context.Context, audio []byte) (*websocket.Conn, error) {
+	c.mu.Lock()
+	switch {
+	case c.conn != nil:
+		conn := c.conn
+		c.mu.Unlock()
+		return conn, nil
+	case c.reconnectCh != nil:
+		if audio != nil {
+			c.bufferLocked(audio)
+		}
+		c.mu.Unlock()
+		return nil, nil
+	}
+	c.mu.Unlock()
 
-	var response VoiceResponse
-	if err := json.Unmarshal(data, &response); err != nil {
+	if err := c.ConnectVoiceStream(ctx); err != nil {
 		return nil, err
 	}
 
-	return &response, nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn, nil
+}
+
+func audioMessage(audioData []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"realtime_input": map[string]interface{}{
+			"media_chunks": []map[string]string{
+				{
+					"mime_type": "audio/pcm",
+					"data":      base64.StdEncoding.EncodeToString(audioData),
+				},
+			},
+		},
+	}
 }
 
-func (c *LiveClient) send(msg interface{}) error {
+func send(ctx context.Context, conn *websocket.Conn, msg interface{}) error {
+	if conn == nil {
+		return errors.New("gemini: not connected")
+	}
+
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
-	return c.conn.Write(context.Background(), websocket.MessageText, data)
+	return conn.Write(ctx, websocket.MessageText, data)
 }
 
 type VoiceResponse struct {
@@ -137,5 +306,6 @@ type VoiceResponse struct {
 			} `json:"parts"`
 		} `json:"modelTurn"`
 		TurnComplete bool `json:"turnComplete"`
+		Interrupted  bool `json:"interrupted"`
 	} `json:"serverContent"`
 }
@@ -0,0 +1,228 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+	"nhooyr.io/websocket"
+)
+
+// ErrReconnectFailed is returned once the reconnect attempts are exhausted
+var ErrReconnectFailed = errors.New("gemini: reconnect failed")
+
+// ConnectionState is the state of the Live API connection
+type ConnectionState string
+
+const (
+	StateDisconnected ConnectionState = "disconnected"
+	StateConnecting   ConnectionState = "connecting"
+	StateConnected    ConnectionState = "connected"
+	StateReconnecting ConnectionState = "reconnecting"
+	StateFailed       ConnectionState = "failed"
+)
+
+// ReconnectConfig controls how a dropped connection is re-established
+type ReconnectConfig struct {
+	MaxAttempts  int           // Attempts before giving up with ErrReconnectFailed
+	BaseDelay    time.Duration // Delay before the first attempt, doubled after each one
+	MaxDelay     time.Duration // Upper bound for the delay
+	BufferWindow time.Duration // Audio older than this is dropped while reconnecting
+}
+
+// DefaultReconnectConfig returns the reconnect settings used by NewLiveClient
+func DefaultReconnectConfig() ReconnectConfig {
+	return ReconnectConfig{
+		MaxAttempts:  5,
+		BaseDelay:    500 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+		BufferWindow: 5 * time.Second,
+	}
+}
+
+// backoff returns the delay before the given attempt (1-based): exponential
+// growth capped at MaxDelay, with "equal jitter" so that clients dropped at
+// the same time do not reconnect in lockstep
+func (rc ReconnectConfig) backoff(attempt int) time.Duration {
+	d := rc.BaseDelay << (attempt - 1)
+	if d <= 0 || d > rc.MaxDelay {
+		d = rc.MaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// bufferedChunk is audio received while the connection was down
+type bufferedChunk struct {
+	data []byte
+	at   time.Time
+}
+
+// State returns the current connection state
+func (c *LiveClient) State() ConnectionState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Subscribe returns a channel receiving connection state changes and a
+// function to unsubscribe. Slow subscribers miss intermediate states rather
+// than block the client.
+func (c *LiveClient) Subscribe() (<-chan ConnectionState, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.nextSubID
+	c.nextSubID++
+	ch := make(chan ConnectionState, 8)
+	c.subscribers[id] = ch
+
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, ok := c.subscribers[id]; ok {
+			delete(c.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
+func (c *LiveClient) setStateLocked(state ConnectionState) {
+	if c.state == state {
+		return
+	}
+	c.state = state
+	for _, ch := range c.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+// bufferLocked keeps audio for replay after reconnecting, dropping anything
+// older than the buffer window
+func (c *LiveClient) bufferLocked(audio []byte) {
+	now := time.Now()
+	c.buffer = append(c.buffer, bufferedChunk{data: append([]byte(nil), audio...), at: now})
+
+	cutoff := now.Add(-c.reconnect.BufferWindow)
+	drop := 0
+	for drop < len(c.buffer) && c.buffer[drop].at.Before(cutoff) {
+		drop++
+	}
+	c.buffer = c.buffer[drop:]
+}
+
+// reconnectWithBackoff replaces a dropped connection. Concurrent callers
+// share a single reconnect loop; if the connection was already replaced it
+// returns immediately.
+func (c *LiveClient) reconnectWithBackoff(ctx context.Context, failed *websocket.Conn) error {
+	c.mu.Lock()
+	if c.conn != nil && c.conn != failed {
+		c.mu.Unlock()
+		return nil
+	}
+	if c.reconnectCh != nil {
+		c.mu.Unlock()
+		return c.waitReconnect(ctx)
+	}
+
+	c.reconnectCh = make(chan struct{})
+	if c.conn != nil {
+		go c.conn.Close(websocket.StatusGoingAway, "reconnecting")
+		c.conn = nil
+	}
+	c.setStateLocked(StateReconnecting)
+	c.mu.Unlock()
+
+	var lastErr error
+	for attempt := 1; attempt <= c.reconnect.MaxAttempts; attempt++ {
+		delay := c.reconnect.backoff(attempt)
+		c.logger.Info("Reconnecting to Gemini Live API",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			c.finishReconnect(nil, StateDisconnected)
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		conn, err := c.dial(ctx)
+		if err != nil {
+			lastErr = err
+			c.logger.Warn("Gemini reconnect attempt failed", zap.Int("attempt", attempt), zap.Error(err))
+			continue
+		}
+
+		c.finishReconnect(conn, StateConnected)
+		c.logger.Info("Reconnected to Gemini Live API", zap.Int("attempt", attempt))
+		return nil
+	}
+
+	c.finishReconnect(nil, StateFailed)
+	c.logger.Error("Giving up on Gemini Live API", zap.Int("attempts", c.reconnect.MaxAttempts), zap.Error(lastErr))
+	return fmt.Errorf("%w after %d attempts: %v", ErrReconnectFailed, c.reconnect.MaxAttempts, lastErr)
+}
+
+// finishReconnect installs the new connection, replays buffered audio and
+// wakes up callers waiting on the reconnect
+func (c *LiveClient) finishReconnect(conn *websocket.Conn, state ConnectionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conn = conn
+	if conn != nil {
+		go c.readPump(conn)
+		cutoff := time.Now().Add(-c.reconnect.BufferWindow)
+		for _, chunk := range c.buffer {
+			if chunk.at.Before(cutoff) {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err := send(ctx, conn, audioMessage(chunk.data))
+			cancel()
+			if err != nil {
+				c.logger.Warn("Failed to replay buffered audio", zap.Error(err))
+				break
+			}
+		}
+	}
+	c.buffer = nil
+
+	c.setStateLocked(state)
+	close(c.reconnectCh)
+	c.reconnectCh = nil
+}
+
+// waitReconnect blocks until the running reconnect, if any, finishes
+func (c *LiveClient) waitReconnect(ctx context.Context) error {
+	c.mu.Lock()
+	ch := c.reconnectCh
+	c.mu.Unlock()
+
+	if ch != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.conn != nil:
+		return nil
+	case c.state == StateFailed:
+		return ErrReconnectFailed
+	default:
+		return errors.New("gemini: not connected")
+	}
+}
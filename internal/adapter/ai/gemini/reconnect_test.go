@@ -0,0 +1,202 @@
+package gemini
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"nhooyr.io/websocket"
+)
+
+// flakyGemini is a fake Live API endpoint. The first connection is dropped
+// after one audio chunk, the next `rejects` dials fail with 503, and later
+// connections wait for one audio chunk and answer with a text turn.
+type flakyGemini struct {
+	rejects  int32
+	dials    int32
+	received chan string
+}
+
+func (f *flakyGemini) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&f.dials, 1)
+	if n > 1 && n <= 1+f.rejects {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	ctx := r.Context()
+
+	// Session setup
+	if _, _, err := conn.Read(ctx); err != nil {
+		return
+	}
+
+	chunk, err := readAudio(ctx, conn)
+	if err != nil {
+		return
+	}
+	f.received <- chunk
+
+	if n == 1 {
+		conn.Close(websocket.StatusInternalError, "dropped")
+		return
+	}
+
+	resp := `{"serverContent":{"modelTurn":{"parts":[{"text":"ok"}]},"turnComplete":true}}`
+	conn.Write(ctx, websocket.MessageText, []byte(resp))
+	conn.Read(ctx) // wait for the client to go away
+}
+
+func readAudio(ctx context.Context, conn *websocket.Conn) (string, error) {
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		return "", err
+	}
+	var msg struct {
+		RealtimeInput struct {
+			MediaChunks []struct {
+				Data string `json:"data"`
+			} `json:"media_chunks"`
+		} `json:"realtime_input"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil || len(msg.RealtimeInput.MediaChunks) == 0 {
+		return "", errors.New("not an audio message")
+	}
+	audio, err := base64.StdEncoding.DecodeString(msg.RealtimeInput.MediaChunks[0].Data)
+	return string(audio), err
+}
+
+func newFlakyClient(t *testing.T, fake *flakyGemini, maxAttempts int) *LiveClient {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	c := NewLiveClient("test-key", zap.NewNop())
+	c.endpoint = "ws" + strings.TrimPrefix(srv.URL, "http")
+	c.reconnect = ReconnectConfig{
+		MaxAttempts:  maxAttempts,
+		BaseDelay:    20 * time.Millisecond,
+		MaxDelay:     40 * time.Millisecond,
+		BufferWindow: 5 * time.Second,
+	}
+	return c
+}
+
+func TestLiveClient_ReconnectsAndReplaysBufferedAudio(t *testing.T) {
+	// Arrange
+	fake := &flakyGemini{rejects: 2, received: make(chan string, 4)}
+	c := newFlakyClient(t, fake, 5)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	states, unsubscribe := c.Subscribe()
+	defer unsubscribe()
+
+	if err := c.ConnectVoiceStream(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := c.SendAudioChunk([]byte("first")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Act: the read hits the dropped connection and triggers the reconnect
+	type result struct {
+		resp *VoiceResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := c.ReceiveResponse(ctx)
+		done <- result{resp, err}
+	}()
+
+	for c.State() != StateReconnecting {
+		select {
+		case <-ctx.Done():
+			t.Fatal("client never started reconnecting")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if err := c.SendAudioChunk([]byte("during-outage")); err != nil {
+		t.Fatalf("expected audio to be buffered while reconnecting, got %v", err)
+	}
+
+	res := <-done
+
+	// Assert
+	if res.err != nil {
+		t.Fatalf("expected response after reconnect, got %v", res.err)
+	}
+	if text := res.resp.ServerContent.ModelTurn.Parts[0].Text; text != "ok" {
+		t.Errorf("expected text 'ok', got %q", text)
+	}
+	if first, replayed := <-fake.received, <-fake.received; first != "first" || replayed != "during-outage" {
+		t.Errorf("expected [first during-outage], got [%s %s]", first, replayed)
+	}
+	if dials := atomic.LoadInt32(&fake.dials); dials != 4 {
+		t.Errorf("expected 4 dials (1 initial, 2 rejected, 1 accepted), got %d", dials)
+	}
+
+	var seen []string
+	for len(states) > 0 {
+		seen = append(seen, string(<-states))
+	}
+	if got := strings.Join(seen, ","); got != "connecting,connected,reconnecting,connected" {
+		t.Errorf("unexpected state transitions: %s", got)
+	}
+}
+
+func TestLiveClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	fake := &flakyGemini{rejects: 100, received: make(chan string, 4)}
+	c := newFlakyClient(t, fake, 3)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.ConnectVoiceStream(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	c.SendAudioChunk([]byte("first"))
+
+	// Act
+	_, err := c.ReceiveResponse(ctx)
+
+	// Assert
+	if !errors.Is(err, ErrReconnectFailed) {
+		t.Fatalf("expected ErrReconnectFailed, got %v", err)
+	}
+	if c.State() != StateFailed {
+		t.Errorf("expected state failed, got %s", c.State())
+	}
+	if dials := atomic.LoadInt32(&fake.dials); dials != 4 {
+		t.Errorf("expected 1 initial dial and 3 reconnect attempts, got %d", dials)
+	}
+}
+
+func TestReconnectConfig_BackoffIsCappedWithJitter(t *testing.T) {
+	rc := ReconnectConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		want := rc.BaseDelay << (attempt - 1)
+		if want > rc.MaxDelay {
+			want = rc.MaxDelay
+		}
+		for i := 0; i < 50; i++ {
+			if d := rc.backoff(attempt); d < want/2 || d > want {
+				t.Fatalf("attempt %d: expected delay in [%v, %v], got %v", attempt, want/2, want, d)
+			}
+		}
+	}
+}
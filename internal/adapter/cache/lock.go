@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// lockKeyPrefix namespaces lock keys in Redis
+const lockKeyPrefix = "lock:"
+
+// Lua scripts compare the holder token so an instance never extends or
+// deletes a lock that expired and was taken by another instance
+var (
+	refreshLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisLocker implements ports.Locker with SET NX PX, so a lock is exclusive
+// across every instance sharing the Redis server
+type RedisLocker struct {
+	client *redis.Client
+	log    *zap.Logger
+}
+
+// NewRedisLocker connects to Redis and returns a distributed locker
+func NewRedisLocker(url string, log *zap.Logger) (ports.Locker, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	log.Info("Redis locker initialized")
+	return &RedisLocker{
+		client: client,
+		log:    log,
+	}, nil
+}
+
+// TryLock sets key to a random token if it does not exist yet
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (ports.Lock, error) {
+	token := uuid.New().String()
+	ok, err := l.client.SetNX(ctx, lockKeyPrefix+key, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !ok {
+		return nil, ports.ErrLockHeld
+	}
+	return &redisLock{client: l.client, key: lockKeyPrefix + key, token: token}, nil
+}
+
+// Close closes the underlying Redis client
+func (l *RedisLocker) Close() error {
+	return l.client.Close()
+}
+
+type redisLock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+func (l *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	n, err := refreshLockScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to refresh lock %s: %w", l.key, err)
+	}
+	if n == 0 {
+		return ports.ErrLockLost
+	}
+	return nil
+}
+
+func (l *redisLock) Release(ctx context.Context) error {
+	if err := releaseLockScript.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+	return nil
+}
+
+// LocalLocker implements ports.Locker in memory.
+// Used as a fallback when Redis is unavailable; locks are per instance.
+type LocalLocker struct {
+	locks map[string]*localLock
+	mu    sync.Mutex
+}
+
+// NewLocalLocker creates an in-memory locker
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{
+		locks: make(map[string]*localLock),
+	}
+}
+
+type localLock struct {
+	locker    *LocalLocker
+	key       string
+	expiresAt time.Time
+}
+
+// TryLock takes key unless an unexpired lock holds it
+func (l *LocalLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (ports.Lock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if held, ok := l.locks[key]; ok && now.Before(held.expiresAt) {
+		return nil, ports.ErrLockHeld
+	}
+
+	lock := &localLock{locker: l, key: key, expiresAt: now.Add(ttl)}
+	l.locks[key] = lock
+	return lock, nil
+}
+
+func (k *localLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	k.locker.mu.Lock()
+	defer k.locker.mu.Unlock()
+
+	now := time.Now()
+	if k.locker.locks[k.key] != k || !now.Before(k.expiresAt) {
+		return ports.ErrLockLost
+	}
+	k.expiresAt = now.Add(ttl)
+	return nil
+}
+
+func (k *localLock) Release(ctx context.Context) error {
+	k.locker.mu.Lock()
+	defer k.locker.mu.Unlock()
+
+	if k.locker.locks[k.key] == k {
+		delete(k.locker.locks, k.key)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// nonceKeyPrefix namespaces nonce keys in Redis
+const nonceKeyPrefix = "nonce:"
+
+// RedisNonceStore implements ports.NonceStore with SET NX, so a nonce used
+// on one API instance is rejected by every other
+type RedisNonceStore struct {
+	client *redis.Client
+	log    *zap.Logger
+}
+
+// NewRedisNonceStore connects to Redis and returns a nonce store
+func NewRedisNonceStore(url string, log *zap.Logger) (ports.NonceStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	log.Info("Redis nonce store initialized")
+	return &RedisNonceStore{
+		client: client,
+		log:    log,
+	}, nil
+}
+
+// Claim stores nonce unless it is already there
+func (s *RedisNonceStore) Claim(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	claimed, err := s.client.SetNX(ctx, nonceKeyPrefix+nonce, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim nonce: %w", err)
+	}
+	return claimed, nil
+}
+
+// Release deletes nonce
+func (s *RedisNonceStore) Release(ctx context.Context, nonce string) error {
+	if err := s.client.Del(ctx, nonceKeyPrefix+nonce).Err(); err != nil {
+		return fmt.Errorf("failed to release nonce: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying Redis client
+func (s *RedisNonceStore) Close() error {
+	return s.client.Close()
+}
+
+// LocalNonceStore implements ports.NonceStore in memory.
+// Used as a fallback when Redis is unavailable; replays are caught per instance.
+type LocalNonceStore struct {
+	expiries map[string]time.Time
+	mu       sync.Mutex
+}
+
+// NewLocalNonceStore creates an in-memory nonce store
+func NewLocalNonceStore() *LocalNonceStore {
+	return &LocalNonceStore{
+		expiries: make(map[string]time.Time),
+	}
+}
+
+// Claim stores nonce unless it is already there and unexpired
+func (s *LocalNonceStore) Claim(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := s.expiries[nonce]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	// Drop expired nonces opportunistically to bound memory
+	for n, expiresAt := range s.expiries {
+		if !now.Before(expiresAt) {
+			delete(s.expiries, n)
+		}
+	}
+	s.expiries[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// Release forgets nonce
+func (s *LocalNonceStore) Release(ctx context.Context, nonce string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expiries, nonce)
+	return nil
+}
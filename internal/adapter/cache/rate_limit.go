@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// RedisRateLimitStore implements ports.RateLimitStore with fixed windows kept
+// in Redis, so every API instance shares the same counters
+type RedisRateLimitStore struct {
+	client *redis.Client
+	log    *zap.Logger
+}
+
+// NewRedisRateLimitStore connects to Redis and returns a rate limit store
+func NewRedisRateLimitStore(url string, log *zap.Logger) (ports.RateLimitStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	log.Info("Redis rate limit store initialized")
+	return &RedisRateLimitStore{
+		client: client,
+		log:    log,
+	}, nil
+}
+
+// Increment atomically bumps the counter for key and starts the window on the first hit
+func (s *RedisRateLimitStore) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	ttl := pipe.PTTL(ctx, key)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to increment rate limit counter: %w", err)
+	}
+
+	reset := ttl.Val()
+	if reset < 0 {
+		reset = window
+	}
+
+	return incr.Val(), reset, nil
+}
+
+// Close closes the underlying Redis client
+func (s *RedisRateLimitStore) Close() error {
+	return s.client.Close()
+}
+
+type rateLimitWindow struct {
+	count   int64
+	resetAt time.Time
+}
+
+// LocalRateLimitStore implements ports.RateLimitStore in memory.
+// Used as a fallback when Redis is unavailable; limits are per instance.
+type LocalRateLimitStore struct {
+	windows map[string]*rateLimitWindow
+	mu      sync.Mutex
+}
+
+// NewLocalRateLimitStore creates an in-memory rate limit store
+func NewLocalRateLimitStore() *LocalRateLimitStore {
+	return &LocalRateLimitStore{
+		windows: make(map[string]*rateLimitWindow),
+	}
+}
+
+// Increment records one hit for key in the current window
+func (s *LocalRateLimitStore) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		// Drop expired windows opportunistically to bound memory
+		for k, existing := range s.windows {
+			if !now.Before(existing.resetAt) {
+				delete(s.windows, k)
+			}
+		}
+		w = &rateLimitWindow{resetAt: now.Add(window)}
+		s.windows[key] = w
+	}
+
+	w.count++
+	return w.count, w.resetAt.Sub(now), nil
+}
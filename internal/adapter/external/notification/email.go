@@ -25,6 +25,11 @@ func NewEmailAdapter(cfg *email.Config, log *zap.Logger) (*EmailAdapter, error)
 	return &EmailAdapter{svc: svc, log: log}, nil
 }
 
+// SetPreferenceRepository makes notification emails honor user preferences
+func (a *EmailAdapter) SetPreferenceRepository(repo ports.NotificationPreferenceRepository) {
+	a.svc.SetPreferenceRepository(repo)
+}
+
 // Ensure EmailAdapter implements ports.EmailService
 var _ ports.EmailService = (*EmailAdapter)(nil)
 
@@ -8,6 +8,8 @@ import (
 	"net/http"
 
 	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // PushAdapter sends push notifications via Firebase Cloud Messaging (FCM) HTTP v1 API
@@ -18,6 +20,9 @@ type PushAdapter struct {
 	log        *zap.Logger
 }
 
+// Ensure PushAdapter implements ports.PushSender
+var _ ports.PushSender = (*PushAdapter)(nil)
+
 // NewPushAdapter creates a new Firebase push notification adapter
 func NewPushAdapter(serverKey, projectID string, log *zap.Logger) *PushAdapter {
 	return &PushAdapter{
@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // SMSAdapter sends SMS messages via Twilio REST API
@@ -20,6 +22,9 @@ type SMSAdapter struct {
 	log        *zap.Logger
 }
 
+// Ensure SMSAdapter implements ports.SMSSender
+var _ ports.SMSSender = (*SMSAdapter)(nil)
+
 // NewSMSAdapter creates a new Twilio SMS adapter
 func NewSMSAdapter(accountSID, authToken, fromNumber string, log *zap.Logger) *SMSAdapter {
 	return &SMSAdapter{
@@ -5,6 +5,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -21,31 +22,28 @@ func NewAuthHandler(service ports.AuthService, log *zap.Logger) *AuthHandler {
 }
 
 type LoginRequest struct {
-	CPF      string `json:"cpf"`
-	Password string `json:"password"`
+	CPF      string `json:"cpf" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type RegisterRequest struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
-	CPF      string `json:"cpf"`
+	CPF      string `json:"cpf" validate:"required"`
+	Language string `json:"language"` // pt-BR (default) or en
 }
 
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
-	}
-
-	if req.CPF == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "CPF and password are required"})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	token, refreshToken, err := h.service.Login(c.Context(), req.CPF, req.Password)
 	if err != nil {
 		h.log.Warn("Login failed", zap.String("cpf", req.CPF), zap.Error(err))
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	user, err2 := h.service.ValidateToken(c.Context(), token)
@@ -63,12 +61,14 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
-
-	if req.CPF == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "CPF is required"})
+	language, ok := domain.NormalizeLanguage(req.Language)
+	if !ok {
+		return &validation.Errors{Fields: map[string]string{
+			"language": "must be one of: " + domain.LanguagePortugueseBR + ", " + domain.LanguageEnglish,
+		}}
 	}
 
 	user := domain.User{
@@ -76,14 +76,12 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		Email:    req.Email,
 		Password: req.Password,
 		Document: req.CPF,
+		Language: language,
 	}
 	plainPassword := req.Password
 
 	if err := h.service.Register(c.Context(), &user); err != nil {
-		if err.Error() == "email already registered" || err.Error() == "cpf already registered" {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	// Auto-login after registration using CPF
@@ -110,12 +108,13 @@ type RefreshRequest struct {
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	var req RefreshRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		return badRequest("Invalid request body")
 	}
 
 	token, err := h.service.RefreshToken(c.Context(), req.RefreshToken)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		h.log.Debug("Refresh token rejected", zap.Error(err))
+		return domain.ErrInvalidToken
 	}
 
 	return c.JSON(fiber.Map{
@@ -127,7 +126,7 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 func (h *AuthHandler) Me(c *fiber.Ctx) error {
 	user := c.Locals("user")
 	if user == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not authenticated"})
+		return domain.ErrUnauthorized
 	}
 	return c.JSON(user)
 }
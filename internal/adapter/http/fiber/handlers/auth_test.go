@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// recordingAuthService keeps the registered user and rejects logins
+type recordingAuthService struct {
+	registered *domain.User
+}
+
+func (s *recordingAuthService) Login(ctx context.Context, cpf, password string) (string, string, error) {
+	return "", "", errors.New("not needed")
+}
+
+func (s *recordingAuthService) Register(ctx context.Context, user *domain.User) error {
+	s.registered = user
+	return nil
+}
+
+func (s *recordingAuthService) RefreshToken(ctx context.Context, token string) (string, error) {
+	return "", errors.New("not needed")
+}
+
+func (s *recordingAuthService) ValidateToken(ctx context.Context, token string) (*domain.User, error) {
+	return nil, errors.New("not needed")
+}
+
+func doRegister(t *testing.T, auth *recordingAuthService, body string) int {
+	t.Helper()
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+	app.Post("/auth/register", NewAuthHandler(auth, zap.NewNop()).Register)
+
+	req := httptest.NewRequest("POST", "/auth/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestRegister_NormalizesLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		expected string
+	}{
+		{"", domain.LanguagePortugueseBR},
+		{"pt_br", domain.LanguagePortugueseBR},
+		{"EN", domain.LanguageEnglish},
+		{"en-US", domain.LanguageEnglish},
+	}
+
+	for _, tt := range tests {
+		// Arrange
+		auth := &recordingAuthService{}
+
+		// Act
+		status := doRegister(t, auth, `{"cpf":"12345678909","password":"secret","language":"`+tt.language+`"}`)
+
+		// Assert
+		if status != fiber.StatusCreated {
+			t.Fatalf("%q: expected status 201, got %d", tt.language, status)
+		}
+		if auth.registered.Language != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.language, tt.expected, auth.registered.Language)
+		}
+	}
+}
+
+func TestRegister_RejectsUnsupportedLanguage(t *testing.T) {
+	// Arrange
+	auth := &recordingAuthService{}
+
+	// Act
+	status := doRegister(t, auth, `{"cpf":"12345678909","password":"secret","language":"klingon-tlh-Latn"}`)
+
+	// Assert
+	if status != fiber.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", status)
+	}
+	if auth.registered != nil {
+		t.Error("expected no user to be registered")
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ChargeHandler starts sessions for walk-up users
+type ChargeHandler struct {
+	starter ports.QRChargeStarter
+	log     *zap.Logger
+}
+
+func NewChargeHandler(starter ports.QRChargeStarter, log *zap.Logger) *ChargeHandler {
+	return &ChargeHandler{
+		starter: starter,
+		log:     log,
+	}
+}
+
+// ScanRequest is the decoded QR code printed on a connector
+type ScanRequest struct {
+	QR string `json:"qr" validate:"required"` // stationID:connectorID
+}
+
+// Scan handles POST /api/v1/charge/scan, starting a session for the caller
+// on the scanned connector. A QR code naming no known connector gives 404,
+// a connector that is not available 409.
+func (h *ChargeHandler) Scan(c *fiber.Ctx) error {
+	var req ScanRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	userID := c.Locals("user_id").(string)
+
+	tx, err := h.starter.StartFromQR(c.Context(), userID, req.QR)
+	if err != nil {
+		h.log.Info("QR charge start refused",
+			zap.String("user_id", userID),
+			zap.String("qr", req.QR),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(tx)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/service/transaction"
+)
+
+// acceptingStarter accepts every remote start
+type acceptingStarter struct {
+	started []string
+}
+
+func (a *acceptingStarter) StartSession(ctx context.Context, chargePointID string, evseID int, userID string) error {
+	a.started = append(a.started, chargePointID)
+	return nil
+}
+
+func newScanTestApp(starter *acceptingStarter) *fiber.App {
+	devices := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			if id != "CP-001" {
+				return nil, nil
+			}
+			return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable, Connectors: []domain.Connector{
+				{ConnectorID: 1, Status: domain.ChargePointStatusAvailable},
+			}}, nil
+		},
+	}
+	sessions := transaction.NewService(memory.NewTransactionRepository(), devices, nil, zap.NewNop()).(*transaction.Service)
+	sessions.SetSessionStarter(starter)
+	h := NewChargeHandler(sessions, zap.NewNop())
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+	app.Post("/charge/scan", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "user-1")
+		return c.Next()
+	}, h.Scan)
+	return app
+}
+
+func doScanRequest(t *testing.T, app *fiber.App, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/charge/scan", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestScan_StartsSession(t *testing.T) {
+	// Arrange
+	starter := &acceptingStarter{}
+	app := newScanTestApp(starter)
+
+	// Act
+	status, resp := doScanRequest(t, app, `{"qr":"CP-001:1"}`)
+
+	// Assert
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %v", status, resp)
+	}
+	if resp["charge_point_id"] != "CP-001" || resp["user_id"] != "user-1" || resp["status"] != "Started" {
+		t.Errorf("expected a Started session for user-1 on CP-001, got %v", resp)
+	}
+	if len(starter.started) != 1 {
+		t.Errorf("expected the station to be asked to start, got %v", starter.started)
+	}
+}
+
+func TestScan_UnknownConnector(t *testing.T) {
+	tests := []struct {
+		name string
+		qr   string
+	}{
+		{"unknown station", "CP-999:1"},
+		{"unknown connector", "CP-001:2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			starter := &acceptingStarter{}
+			app := newScanTestApp(starter)
+
+			// Act
+			status, resp := doScanRequest(t, app, `{"qr":"`+tt.qr+`"}`)
+
+			// Assert
+			if status != fiber.StatusNotFound {
+				t.Fatalf("expected status 404, got %d: %v", status, resp)
+			}
+			apiErr, _ := resp["error"].(map[string]interface{})
+			if apiErr["code"] != middleware.CodeConnectorNotFound {
+				t.Errorf("expected code %s, got %v", middleware.CodeConnectorNotFound, resp["error"])
+			}
+			if len(starter.started) != 0 {
+				t.Errorf("expected no remote start, got %v", starter.started)
+			}
+		})
+	}
+}
+
+func TestScan_MalformedQR(t *testing.T) {
+	// Arrange
+	app := newScanTestApp(&acceptingStarter{})
+
+	// Act
+	status, resp := doScanRequest(t, app, `{"qr":"not-a-connector"}`)
+
+	// Assert
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected status 400, got %d: %v", status, resp)
+	}
+}
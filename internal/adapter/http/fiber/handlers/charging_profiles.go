@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ChargingProfileHandler exposes the charging profiles reported by charge points
+type ChargingProfileHandler struct {
+	repo ports.ChargingProfileReportRepository
+	log  *zap.Logger
+}
+
+func NewChargingProfileHandler(repo ports.ChargingProfileReportRepository, log *zap.Logger) *ChargingProfileHandler {
+	return &ChargingProfileHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Get handles GET /api/v1/devices/:id/charging-profiles
+func (h *ChargingProfileHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+	report, err := h.repo.GetByChargePointID(c.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to get charging profiles", zap.String("device_id", id), zap.Error(err))
+		return err
+	}
+	if report == nil {
+		return domain.ErrChargingProfilesNotFound
+	}
+	return c.JSON(report)
+}
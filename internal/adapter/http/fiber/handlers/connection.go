@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ConnectionHandler exposes when charge points connected and disconnected
+type ConnectionHandler struct {
+	repo ports.ConnectionEventRepository
+	log  *zap.Logger
+}
+
+func NewConnectionHandler(repo ports.ConnectionEventRepository, log *zap.Logger) *ConnectionHandler {
+	return &ConnectionHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// ConnectionHistoryResponse is a charge point's connection events in a
+// period and the share of it the charge point was connected
+type ConnectionHistoryResponse struct {
+	DeviceID      string                   `json:"device_id"`
+	From          time.Time                `json:"from"`
+	To            time.Time                `json:"to"`
+	UptimePercent float64                  `json:"uptime_percent"`
+	Events        []domain.ConnectionEvent `json:"events"`
+}
+
+// List handles GET /api/v1/devices/:id/connections. The period defaults to
+// the last domain.DefaultUptimeWindow and is set with RFC 3339 from and to.
+func (h *ConnectionHandler) List(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return badRequest("to must be an RFC 3339 time")
+		}
+		to = t
+	}
+	from := to.Add(-domain.DefaultUptimeWindow)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return badRequest("from must be an RFC 3339 time")
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		return badRequest("from must be before to")
+	}
+
+	last, err := h.repo.FindLastBefore(c.Context(), id, from)
+	if err != nil {
+		h.log.Error("Failed to get connection history", zap.String("device_id", id), zap.Error(err))
+		return err
+	}
+	events, err := h.repo.FindByChargePointID(c.Context(), id, from, to)
+	if err != nil {
+		h.log.Error("Failed to get connection history", zap.String("device_id", id), zap.Error(err))
+		return err
+	}
+	if events == nil {
+		events = []domain.ConnectionEvent{}
+	}
+
+	return c.JSON(ConnectionHistoryResponse{
+		DeviceID:      id,
+		From:          from,
+		To:            to,
+		UptimePercent: domain.UptimePercent(last, events, from, to),
+		Events:        events,
+	})
+}
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -28,10 +30,17 @@ func (h *DeviceHandler) List(c *fiber.Ctx) error {
 	if status := c.Query("status"); status != "" {
 		filter["status"] = status
 	}
+	connectors, err := connectorFilter(c)
+	if err != nil {
+		return err
+	}
+	if !connectors.IsZero() {
+		filter[ports.ConnectorFilterKey] = connectors
+	}
 
 	devices, err := h.service.ListDevices(c.Context(), filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(devices)
 }
@@ -40,10 +49,10 @@ func (h *DeviceHandler) Get(c *fiber.Ctx) error {
 	id := c.Params("id")
 	device, err := h.service.GetDevice(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	if device == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Device not found"})
+		return domain.ErrDeviceNotFound
 	}
 	return c.JSON(device)
 }
@@ -52,25 +61,50 @@ func (h *DeviceHandler) GetNearby(c *fiber.Ctx) error {
 	lat, _ := strconv.ParseFloat(c.Query("lat"), 64)
 	lon, _ := strconv.ParseFloat(c.Query("lon"), 64)
 	radius, _ := strconv.ParseFloat(c.Query("radius"), 64)
+	connectors, err := connectorFilter(c)
+	if err != nil {
+		return err
+	}
 
-	devices, err := h.service.GetNearby(c.Context(), lat, lon, radius)
+	devices, err := h.service.GetNearby(c.Context(), lat, lon, radius, connectors)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(devices)
 }
 
+// connectorFilter reads the connector_type, min_power_kw and available
+// query params, e.g. ?connector_type=CCS2&min_power_kw=150&available=true
+func connectorFilter(c *fiber.Ctx) (domain.ConnectorFilter, error) {
+	f := domain.ConnectorFilter{Standard: c.Query("connector_type")}
+	if v := c.Query("min_power_kw"); v != "" {
+		minPower, err := strconv.ParseFloat(v, 64)
+		if err != nil || minPower < 0 {
+			return f, fmt.Errorf("%w: min_power_kw must be a non-negative number", domain.ErrValidation)
+		}
+		f.MinPowerKW = minPower
+	}
+	if v := c.Query("available"); v != "" {
+		available, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("%w: available must be true or false", domain.ErrValidation)
+		}
+		f.AvailableNow = available
+	}
+	return f, nil
+}
+
 func (h *DeviceHandler) UpdateStatus(c *fiber.Ctx) error {
 	id := c.Params("id")
 	var req struct {
-		Status domain.ChargePointStatus `json:"status"`
+		Status domain.ChargePointStatus `json:"status" validate:"required"`
 	}
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	if err := h.service.UpdateStatus(c.Context(), id, req.Status); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.SendStatus(fiber.StatusOK)
 }
@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -13,6 +20,10 @@ import (
 type DeviceCommandHandler struct {
 	ocppService     ports.OCPPCommandService
 	firmwareService ports.FirmwareService
+	deviceService   ports.DeviceService
+	logRequests     ports.LogRequestRepository
+	firmwarePubs    ports.FirmwarePublicationRepository // optional, see SetFirmwarePublicationRepository
+	sessions        ports.TransactionService // optional, see SetTransactionService
 	log             *zap.Logger
 }
 
@@ -20,20 +31,74 @@ type DeviceCommandHandler struct {
 func NewDeviceCommandHandler(
 	ocppService ports.OCPPCommandService,
 	firmwareService ports.FirmwareService,
+	deviceService ports.DeviceService,
 	log *zap.Logger,
 ) *DeviceCommandHandler {
 	return &DeviceCommandHandler{
 		ocppService:     ocppService,
 		firmwareService: firmwareService,
+		deviceService:   deviceService,
 		log:             log,
 	}
 }
 
+// SetLogRequestRepository enables listing diagnostics log requests
+func (h *DeviceCommandHandler) SetLogRequestRepository(repo ports.LogRequestRepository) {
+	h.logRequests = repo
+}
+
+// SetFirmwarePublicationRepository enables listing firmware publications
+func (h *DeviceCommandHandler) SetFirmwarePublicationRepository(repo ports.FirmwarePublicationRepository) {
+	h.firmwarePubs = repo
+}
+
+// SetTransactionService enables refusing Immediate resets that would cut off
+// a charging session, and finalizing the sessions a forced reset ends
+func (h *DeviceCommandHandler) SetTransactionService(sessions ports.TransactionService) {
+	h.sessions = sessions
+}
+
+// RegisterRoutes registers the command routes on the devices router. staff
+// restricts every route to operators; ocppBreaker guards those that reach a
+// station and dbBreaker those that read storage. Register before any "/:id"
+// GET route so "/connected" is not taken for a device ID.
+func (h *DeviceCommandHandler) RegisterRoutes(devices fiber.Router, staff, ocppBreaker, dbBreaker fiber.Handler) {
+	devices.Get("/connected", staff, ocppBreaker, h.GetConnectedDevices)
+	devices.Post("/bulk/remote-stop", staff, ocppBreaker, h.BulkRemoteStop)
+	devices.Get("/:id/firmware/status", staff, dbBreaker, h.GetFirmwareStatus)
+	devices.Get("/:id/firmware/publications", staff, dbBreaker, h.ListFirmwarePublications)
+	devices.Get("/:id/logs", staff, dbBreaker, h.ListLogs)
+
+	// OCPP commands to a device
+	devices.Post("/:id/remote-start", staff, ocppBreaker, h.RemoteStart)
+	devices.Post("/:id/remote-stop", staff, ocppBreaker, h.RemoteStop)
+	devices.Post("/:id/reset", staff, ocppBreaker, h.Reset)
+	devices.Post("/:id/trigger/:message", staff, ocppBreaker, h.TriggerMessage)
+	devices.Post("/:id/charging-profile", staff, ocppBreaker, h.SetChargingProfile)
+	devices.Delete("/:id/charging-profile", staff, ocppBreaker, h.ClearChargingProfile)
+	devices.Post("/:id/unlock", staff, ocppBreaker, h.UnlockConnector)
+	devices.Post("/:id/clear-cache", staff, ocppBreaker, h.ClearCache)
+	devices.Post("/:id/availability", staff, ocppBreaker, h.ChangeAvailability)
+	devices.Post("/:id/firmware/update", staff, ocppBreaker, h.UpdateFirmware)
+	devices.Delete("/:id/firmware/update", staff, ocppBreaker, h.CancelFirmwareUpdate)
+	devices.Post("/:id/firmware/publish", staff, ocppBreaker, h.PublishFirmware)
+	devices.Post("/:id/data-transfer", staff, ocppBreaker, h.DataTransfer)
+	devices.Get("/:id/certificates", staff, ocppBreaker, h.ListCertificates)
+	devices.Post("/:id/certificates", staff, ocppBreaker, h.InstallCertificate)
+	devices.Delete("/:id/certificates/:serial", staff, ocppBreaker, h.DeleteCertificate)
+	devices.Get("/:id/connection", staff, ocppBreaker, h.GetConnectionStatus)
+	devices.Get("/:id/config", staff, ocppBreaker, h.GetConfig)
+	devices.Put("/:id/config", staff, ocppBreaker, h.UpdateConfig)
+	devices.Post("/:id/monitoring", staff, ocppBreaker, h.SetMonitoring)
+	devices.Put("/:id/monitoring/base", staff, ocppBreaker, h.SetMonitoringBase)
+}
+
 // --- Remote Start/Stop ---
 
 // RemoteStartRequest represents a remote start request
 type RemoteStartRequest struct {
-	IdToken     string `json:"id_token"`
+	IdToken     string `json:"id_token" validate:"required"`
+	IdTokenType string `json:"id_token_type,omitempty" validate:"omitempty,oneof=Central eMAID ISO14443 ISO15693 KeyCode Local MacAddress NoAuthorization"` // ISO14443 when empty
 	EvseID      *int   `json:"evse_id,omitempty"`
 	ConnectorID *int   `json:"connector_id,omitempty"`
 }
@@ -43,34 +108,27 @@ func (h *DeviceCommandHandler) RemoteStart(c *fiber.Ctx) error {
 	deviceID := c.Params("id")
 
 	var req RemoteStartRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
-	if req.IdToken == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "id_token is required",
-		})
+	tokenType, err := domain.ParseIdTokenType(req.IdTokenType)
+	if err != nil {
+		return badRequest("id_token_type must be one of Central, eMAID, ISO14443, ISO15693, KeyCode, Local, MacAddress, NoAuthorization")
 	}
 
 	// Check if device is connected
 	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+		return deviceNotConnected()
 	}
 
-	err := h.ocppService.RemoteStartTransaction(c.Context(), deviceID, req.IdToken, req.EvseID)
+	err = h.ocppService.RemoteStartTransaction(c.Context(), deviceID, req.IdToken, tokenType, req.EvseID)
 	if err != nil {
 		h.log.Error("Remote start failed",
 			zap.String("deviceID", deviceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -81,7 +139,7 @@ func (h *DeviceCommandHandler) RemoteStart(c *fiber.Ctx) error {
 
 // RemoteStopRequest represents a remote stop request
 type RemoteStopRequest struct {
-	TransactionID string `json:"transaction_id"`
+	TransactionID string `json:"transaction_id" validate:"required"`
 }
 
 // RemoteStop handles POST /api/v1/devices/:id/remote-stop
@@ -89,22 +147,12 @@ func (h *DeviceCommandHandler) RemoteStop(c *fiber.Ctx) error {
 	deviceID := c.Params("id")
 
 	var req RemoteStopRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	if req.TransactionID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "transaction_id is required",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+		return deviceNotConnected()
 	}
 
 	err := h.ocppService.RemoteStopTransaction(c.Context(), deviceID, req.TransactionID)
@@ -113,9 +161,7 @@ func (h *DeviceCommandHandler) RemoteStop(c *fiber.Ctx) error {
 			zap.String("deviceID", deviceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -124,12 +170,104 @@ func (h *DeviceCommandHandler) RemoteStop(c *fiber.Ctx) error {
 	})
 }
 
+// --- Bulk Remote Stop ---
+
+// maxBulkTargets caps the number of devices a single bulk request can address
+const maxBulkTargets = 500
+
+// BulkRemoteStopRequest represents a bulk remote stop request. Devices are
+// selected by explicit IDs, by filter, or both.
+type BulkRemoteStopRequest struct {
+	DeviceIDs    []string          `json:"device_ids"`
+	Transactions map[string]string `json:"transactions,omitempty"` // device_id -> transaction_id
+	Filter       *BulkDeviceFilter `json:"filter,omitempty"`
+}
+
+// BulkDeviceFilter selects devices for a bulk command
+type BulkDeviceFilter struct {
+	Status string `json:"status"`
+}
+
+// BulkRemoteStop handles POST /api/v1/devices/bulk/remote-stop
+func (h *DeviceCommandHandler) BulkRemoteStop(c *fiber.Ctx) error {
+	var req BulkRemoteStopRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest("Invalid request body")
+	}
+
+	if len(req.DeviceIDs) == 0 && req.Filter == nil {
+		return badRequest("device_ids or filter is required")
+	}
+
+	deviceIDs := req.DeviceIDs
+	if req.Filter != nil {
+		if h.deviceService == nil {
+			return notImplemented("Device filter is not supported")
+		}
+
+		filter := make(map[string]interface{})
+		if req.Filter.Status != "" {
+			filter["status"] = req.Filter.Status
+		}
+
+		devices, err := h.deviceService.ListDevices(c.Context(), filter)
+		if err != nil {
+			return err
+		}
+		for _, d := range devices {
+			deviceIDs = append(deviceIDs, d.ID)
+		}
+	}
+
+	// Deduplicate while keeping request order
+	seen := make(map[string]bool, len(deviceIDs))
+	targets := make([]ports.RemoteStopTarget, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		targets = append(targets, ports.RemoteStopTarget{
+			ChargePointID: id,
+			TransactionID: req.Transactions[id],
+		})
+	}
+
+	if len(targets) == 0 {
+		return badRequest("No devices matched the request")
+	}
+
+	if len(targets) > maxBulkTargets {
+		return middleware.NewAPIError(fiber.StatusBadRequest, middleware.CodeValidation, "Too many devices in a single request").
+			WithDetails(map[string]interface{}{"max": maxBulkTargets})
+	}
+
+	results := h.ocppService.BulkRemoteStop(c.Context(), targets)
+
+	summary := make(map[string]int)
+	for _, r := range results {
+		summary[r.Status]++
+	}
+
+	h.log.Info("Bulk remote stop processed",
+		zap.Int("targets", len(targets)),
+		zap.Any("summary", summary),
+	)
+
+	return c.JSON(fiber.Map{
+		"total":   len(results),
+		"summary": summary,
+		"results": results,
+	})
+}
+
 // --- Reset ---
 
 // ResetRequest represents a reset request
 type ResetRequest struct {
-	Type   string `json:"type"` // Immediate, OnIdle
+	Type   string `json:"type" validate:"omitempty,oneof=Immediate OnIdle"` // Immediate when empty
 	EvseID *int   `json:"evse_id,omitempty"`
+	Force  bool   `json:"force,omitempty"` // Reset Immediate even while charging; the sessions are billed first
 }
 
 // Reset handles POST /api/v1/devices/:id/reset
@@ -137,43 +275,118 @@ func (h *DeviceCommandHandler) Reset(c *fiber.Ctx) error {
 	deviceID := c.Params("id")
 
 	var req ResetRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	if req.Type == "" {
 		req.Type = "Immediate"
 	}
 
-	if req.Type != "Immediate" && req.Type != "OnIdle" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "type must be 'Immediate' or 'OnIdle'",
-		})
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
 	}
 
-	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+	// An Immediate reset ends any session abruptly, without a final meter
+	// reading; OnIdle waits for the sessions to end
+	var active []domain.Transaction
+	if req.Type == "Immediate" && h.sessions != nil {
+		var err error
+		active, err = h.activeTransactions(c.Context(), deviceID, req.EvseID)
+		if err != nil {
+			h.log.Error("Failed to list active sessions", zap.String("deviceID", deviceID), zap.Error(err))
+			return err
+		}
+		if len(active) > 0 && !req.Force {
+			ids := make([]string, 0, len(active))
+			for _, tx := range active {
+				ids = append(ids, tx.ID)
+			}
+			return middleware.NewAPIError(fiber.StatusConflict, middleware.CodeConflict,
+				"A transaction is active; use type OnIdle to reset once it ends, or set force to end it now").
+				WithDetails(map[string]interface{}{
+					"suggested_type":      "OnIdle",
+					"active_transactions": ids,
+				})
+		}
 	}
 
-	err := h.ocppService.Reset(c.Context(), deviceID, req.Type, req.EvseID)
+	status, err := h.ocppService.Reset(c.Context(), deviceID, req.Type, req.EvseID)
 	if err != nil {
 		h.log.Error("Reset failed",
 			zap.String("deviceID", deviceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
+		return err
+	}
+
+	switch status {
+	case "Accepted":
+	case "Scheduled":
+		// The station resets once its sessions end, which it reports itself
+		return c.JSON(fiber.Map{
+			"status":  status,
+			"message": "Reset scheduled for when the device is idle",
 		})
+	default:
+		return commandRejected("Device rejected the reset", status)
 	}
 
-	return c.JSON(fiber.Map{
-		"status":  "Accepted",
+	// The sessions a forced reset cut off are finalized only once the station
+	// has accepted it, so a refused reset leaves them running
+	var unfinalized []string
+	for _, tx := range active {
+		if _, err := h.sessions.StopTransaction(c.Context(), tx.ID); err != nil {
+			h.log.Error("Failed to finalize session after forced reset",
+				zap.String("deviceID", deviceID),
+				zap.String("txID", tx.ID),
+				zap.Error(err),
+			)
+			unfinalized = append(unfinalized, tx.ID)
+			continue
+		}
+		h.log.Warn("Session finalized for forced reset",
+			zap.String("deviceID", deviceID),
+			zap.String("txID", tx.ID),
+		)
+	}
+
+	resp := fiber.Map{
+		"status":  status,
 		"message": "Reset command sent successfully",
-	})
+	}
+	if len(unfinalized) > 0 {
+		resp["unfinalized_transactions"] = unfinalized
+	}
+	return c.JSON(resp)
+}
+
+// activeTransactions returns the sessions in progress on a device, or on one
+// of its EVSEs when evseID is set and not 0. Sessions whose EVSE is not known
+// count as on every EVSE.
+func (h *DeviceCommandHandler) activeTransactions(ctx context.Context, deviceID string, evseID *int) ([]domain.Transaction, error) {
+	var sessions []domain.ActiveSession
+	var err error
+	if index, ok := h.sessions.(ports.ActiveSessionIndex); ok {
+		sessions, err = index.ActiveSessionsByChargePoint(ctx, deviceID)
+	} else {
+		sessions, err = h.sessions.ListActiveSessions(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var active []domain.Transaction
+	for _, session := range sessions {
+		tx := session.Transaction
+		if tx.ChargePointID != deviceID {
+			continue
+		}
+		if evseID != nil && *evseID != 0 && tx.EvseID != 0 && tx.EvseID != *evseID {
+			continue
+		}
+		active = append(active, tx)
+	}
+	return active, nil
 }
 
 // --- Trigger Message ---
@@ -184,25 +397,23 @@ func (h *DeviceCommandHandler) TriggerMessage(c *fiber.Ctx) error {
 	message := c.Params("message")
 
 	validMessages := map[string]bool{
-		"BootNotification":           true,
-		"Heartbeat":                  true,
-		"StatusNotification":         true,
-		"MeterValues":                true,
-		"FirmwareStatusNotification": true,
-		"LogStatusNotification":      true,
+		"BootNotification":                  true,
+		"Heartbeat":                         true,
+		"StatusNotification":                true,
+		"MeterValues":                       true,
+		"FirmwareStatusNotification":        true,
+		"PublishFirmwareStatusNotification": true,
+		"LogStatusNotification":             true,
 	}
 
 	if !validMessages[message] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":          "Invalid message type",
-			"valid_messages": []string{"BootNotification", "Heartbeat", "StatusNotification", "MeterValues", "FirmwareStatusNotification", "LogStatusNotification"},
+		return middleware.NewAPIError(fiber.StatusBadRequest, middleware.CodeValidation, "Invalid message type").WithDetails(map[string]interface{}{
+			"valid_messages": []string{"BootNotification", "Heartbeat", "StatusNotification", "MeterValues", "FirmwareStatusNotification", "PublishFirmwareStatusNotification", "LogStatusNotification"},
 		})
 	}
 
 	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+		return deviceNotConnected()
 	}
 
 	var evseID *int
@@ -217,9 +428,7 @@ func (h *DeviceCommandHandler) TriggerMessage(c *fiber.Ctx) error {
 			zap.String("message", message),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -233,7 +442,8 @@ func (h *DeviceCommandHandler) TriggerMessage(c *fiber.Ctx) error {
 // SetChargingProfileRequest represents a charging profile request
 type SetChargingProfileRequest struct {
 	EvseID          int              `json:"evse_id"`
-	ChargingProfile *ChargingProfile `json:"charging_profile"`
+	ChargingProfile *ChargingProfile `json:"charging_profile" validate:"required"`
+	DryRun          bool             `json:"dry_run"` // Validate and preview only; nothing is sent
 }
 
 // ChargingProfile for REST API
@@ -242,6 +452,7 @@ type ChargingProfile struct {
 	StackLevel             int                `json:"stack_level"`
 	ChargingProfilePurpose string             `json:"purpose"` // ChargePointMaxProfile, TxDefaultProfile, TxProfile
 	ChargingProfileKind    string             `json:"kind"`    // Absolute, Recurring, Relative
+	RecurrencyKind         string             `json:"recurrency_kind,omitempty"` // Daily, Weekly; Recurring profiles only
 	ValidFrom              *string            `json:"valid_from,omitempty"`
 	ValidTo                *string            `json:"valid_to,omitempty"`
 	ChargingSchedule       []ChargingSchedule `json:"charging_schedule"`
@@ -249,9 +460,11 @@ type ChargingProfile struct {
 
 // ChargingSchedule for REST API
 type ChargingSchedule struct {
+	ID               int                       `json:"id,omitempty"` // Numbered in order from 1 when left out
 	Duration         *int                      `json:"duration,omitempty"`
 	StartSchedule    *string                   `json:"start_schedule,omitempty"`
 	ChargingRateUnit string                    `json:"charging_rate_unit"` // W, A
+	MinChargingRate  *float64                  `json:"min_charging_rate,omitempty"`
 	Periods          []ChargingSchedulePeriod  `json:"periods"`
 }
 
@@ -262,38 +475,67 @@ type ChargingSchedulePeriod struct {
 	NumberPhases *int    `json:"number_phases,omitempty"`
 }
 
+// ToDomain maps the REST charging profile to the domain one
+func (p *ChargingProfile) ToDomain() domain.ChargingProfile {
+	profile := domain.ChargingProfile{
+		ID:                     p.ID,
+		StackLevel:             p.StackLevel,
+		ChargingProfilePurpose: p.ChargingProfilePurpose,
+		ChargingProfileKind:    p.ChargingProfileKind,
+		RecurrencyKind:         p.RecurrencyKind,
+		ValidFrom:              p.ValidFrom,
+		ValidTo:                p.ValidTo,
+		ChargingSchedule:       make([]domain.ChargingSchedule, 0, len(p.ChargingSchedule)),
+	}
+	for i, schedule := range p.ChargingSchedule {
+		id := schedule.ID
+		if id == 0 {
+			id = i + 1
+		}
+		periods := make([]domain.ChargingSchedulePeriod, 0, len(schedule.Periods))
+		for _, period := range schedule.Periods {
+			periods = append(periods, domain.ChargingSchedulePeriod{
+				StartPeriod:  period.StartPeriod,
+				Limit:        period.Limit,
+				NumberPhases: period.NumberPhases,
+			})
+		}
+		profile.ChargingSchedule = append(profile.ChargingSchedule, domain.ChargingSchedule{
+			ID:                     id,
+			StartSchedule:          schedule.StartSchedule,
+			Duration:               schedule.Duration,
+			ChargingRateUnit:       schedule.ChargingRateUnit,
+			MinChargingRate:        schedule.MinChargingRate,
+			ChargingSchedulePeriod: periods,
+		})
+	}
+	return profile
+}
+
 // SetChargingProfile handles POST /api/v1/devices/:id/charging-profile
 func (h *DeviceCommandHandler) SetChargingProfile(c *fiber.Ctx) error {
 	deviceID := c.Params("id")
 
 	var req SetChargingProfileRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
-	if req.ChargingProfile == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "charging_profile is required",
-		})
+	if req.DryRun || c.QueryBool("dryRun") {
+		return h.previewChargingProfile(c, deviceID, req)
 	}
 
 	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+		return deviceNotConnected()
 	}
 
-	err := h.ocppService.SetChargingProfile(c.Context(), deviceID, req.EvseID, req.ChargingProfile)
+	err := h.ocppService.SetChargingProfile(c.Context(), deviceID, req.EvseID, req.ChargingProfile.ToDomain())
 	if err != nil {
 		h.log.Error("Set charging profile failed",
 			zap.String("deviceID", deviceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -302,6 +544,24 @@ func (h *DeviceCommandHandler) SetChargingProfile(c *fiber.Ctx) error {
 	})
 }
 
+// previewChargingProfile answers a dry run with the validation result and
+// the composite schedule, without sending anything to the device
+func (h *DeviceCommandHandler) previewChargingProfile(c *fiber.Ctx, deviceID string, req SetChargingProfileRequest) error {
+	preview, err := h.ocppService.PreviewChargingProfile(c.Context(), deviceID, req.EvseID, req.ChargingProfile.ToDomain())
+	if err != nil {
+		h.log.Error("Charging profile dry run failed",
+			zap.String("deviceID", deviceID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	if !preview.Valid {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(preview)
+	}
+	return c.JSON(preview)
+}
+
 // ClearChargingProfile handles DELETE /api/v1/devices/:id/charging-profile
 func (h *DeviceCommandHandler) ClearChargingProfile(c *fiber.Ctx) error {
 	deviceID := c.Params("id")
@@ -317,9 +577,7 @@ func (h *DeviceCommandHandler) ClearChargingProfile(c *fiber.Ctx) error {
 	}
 
 	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+		return deviceNotConnected()
 	}
 
 	err := h.ocppService.ClearChargingProfile(c.Context(), deviceID, profileID, evseID)
@@ -328,9 +586,7 @@ func (h *DeviceCommandHandler) ClearChargingProfile(c *fiber.Ctx) error {
 			zap.String("deviceID", deviceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -343,8 +599,8 @@ func (h *DeviceCommandHandler) ClearChargingProfile(c *fiber.Ctx) error {
 
 // UnlockConnectorRequest represents an unlock request
 type UnlockConnectorRequest struct {
-	EvseID      int `json:"evse_id"`
-	ConnectorID int `json:"connector_id"`
+	EvseID      int `json:"evse_id" validate:"required,min=1"`
+	ConnectorID int `json:"connector_id" validate:"required,min=1"`
 }
 
 // UnlockConnector handles POST /api/v1/devices/:id/unlock
@@ -352,22 +608,12 @@ func (h *DeviceCommandHandler) UnlockConnector(c *fiber.Ctx) error {
 	deviceID := c.Params("id")
 
 	var req UnlockConnectorRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	if req.EvseID == 0 || req.ConnectorID == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "evse_id and connector_id are required",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+		return deviceNotConnected()
 	}
 
 	err := h.ocppService.UnlockConnector(c.Context(), deviceID, req.EvseID, req.ConnectorID)
@@ -376,9 +622,7 @@ func (h *DeviceCommandHandler) UnlockConnector(c *fiber.Ctx) error {
 			zap.String("deviceID", deviceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -387,11 +631,40 @@ func (h *DeviceCommandHandler) UnlockConnector(c *fiber.Ctx) error {
 	})
 }
 
+// --- Clear Cache ---
+
+// ClearCache handles POST /api/v1/devices/:id/clear-cache
+func (h *DeviceCommandHandler) ClearCache(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
+	}
+
+	status, err := h.ocppService.ClearCache(c.Context(), deviceID)
+	if err != nil {
+		h.log.Error("Clear cache failed",
+			zap.String("deviceID", deviceID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	if status != "Accepted" {
+		return commandRejected("Device did not clear its authorization cache", status)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  status,
+		"message": "Authorization cache cleared",
+	})
+}
+
 // --- Change Availability ---
 
 // ChangeAvailabilityRequest represents availability change request
 type ChangeAvailabilityRequest struct {
-	OperationalStatus string `json:"operational_status"` // Operative, Inoperative
+	OperationalStatus string `json:"operational_status" validate:"required,oneof=Operative Inoperative"`
 	EvseID            *int   `json:"evse_id,omitempty"`
 }
 
@@ -400,22 +673,12 @@ func (h *DeviceCommandHandler) ChangeAvailability(c *fiber.Ctx) error {
 	deviceID := c.Params("id")
 
 	var req ChangeAvailabilityRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	if req.OperationalStatus != "Operative" && req.OperationalStatus != "Inoperative" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "operational_status must be 'Operative' or 'Inoperative'",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+		return deviceNotConnected()
 	}
 
 	err := h.ocppService.ChangeAvailability(c.Context(), deviceID, req.OperationalStatus, req.EvseID)
@@ -424,9 +687,7 @@ func (h *DeviceCommandHandler) ChangeAvailability(c *fiber.Ctx) error {
 			zap.String("deviceID", deviceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -439,7 +700,7 @@ func (h *DeviceCommandHandler) ChangeAvailability(c *fiber.Ctx) error {
 
 // UpdateFirmwareRequest represents a firmware update request
 type UpdateFirmwareRequest struct {
-	FirmwareURL      string     `json:"firmware_url"`
+	FirmwareURL      string     `json:"firmware_url" validate:"required"`
 	Version          string     `json:"version"`
 	RetrieveDateTime *time.Time `json:"retrieve_datetime,omitempty"`
 	InstallDateTime  *time.Time `json:"install_datetime,omitempty"`
@@ -452,22 +713,12 @@ func (h *DeviceCommandHandler) UpdateFirmware(c *fiber.Ctx) error {
 	deviceID := c.Params("id")
 
 	var req UpdateFirmwareRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	if req.FirmwareURL == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "firmware_url is required",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	if !h.ocppService.IsConnected(deviceID) {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Device is not connected",
-		})
+		return deviceNotConnected()
 	}
 
 	fwReq := &ports.FirmwareUpdateRequest{
@@ -486,9 +737,7 @@ func (h *DeviceCommandHandler) UpdateFirmware(c *fiber.Ctx) error {
 			zap.String("deviceID", deviceID),
 			zap.Error(err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusAccepted).JSON(status)
@@ -500,15 +749,11 @@ func (h *DeviceCommandHandler) GetFirmwareStatus(c *fiber.Ctx) error {
 
 	status, err := h.firmwareService.GetFirmwareStatus(c.Context(), deviceID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	if status == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "No firmware update in progress",
-		})
+		return middleware.NewAPIError(fiber.StatusNotFound, middleware.CodeNotFound, "No firmware update in progress")
 	}
 
 	return c.JSON(status)
@@ -520,9 +765,7 @@ func (h *DeviceCommandHandler) CancelFirmwareUpdate(c *fiber.Ctx) error {
 
 	err := h.firmwareService.CancelFirmwareUpdate(c.Context(), deviceID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -531,6 +774,278 @@ func (h *DeviceCommandHandler) CancelFirmwareUpdate(c *fiber.Ctx) error {
 	})
 }
 
+// PublishFirmwareRequest represents a request to publish firmware on a local controller
+type PublishFirmwareRequest struct {
+	Location      string `json:"location" validate:"required,url,max=512"`
+	Checksum      string `json:"checksum" validate:"required,len=32,hexadecimal"` // MD5 of the firmware file
+	Retries       *int   `json:"retries,omitempty"`
+	RetryInterval *int   `json:"retry_interval,omitempty"`
+}
+
+// PublishFirmware handles POST /api/v1/devices/:id/firmware/publish
+func (h *DeviceCommandHandler) PublishFirmware(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	var req PublishFirmwareRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
+	}
+
+	err := h.ocppService.PublishFirmware(c.Context(), deviceID, req.Location, strings.ToLower(req.Checksum), req.Retries, req.RetryInterval)
+	if errors.Is(err, domain.ErrFirmwareOperationInProgress) {
+		return err
+	}
+	if err != nil {
+		h.log.Error("Firmware publish failed",
+			zap.String("deviceID", deviceID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status":  "Accepted",
+		"message": "Firmware publish command sent successfully",
+	})
+}
+
+// maxFirmwarePublications caps the publications listed per device
+const maxFirmwarePublications = 20
+
+// ListFirmwarePublications handles GET /api/v1/devices/:id/firmware/publications
+func (h *DeviceCommandHandler) ListFirmwarePublications(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	if h.firmwarePubs == nil {
+		return notImplemented("Firmware publication tracking is not configured")
+	}
+
+	pubs, err := h.firmwarePubs.FindByChargePointID(c.Context(), deviceID, maxFirmwarePublications)
+	if err != nil {
+		h.log.Error("Failed to list firmware publications", zap.String("deviceID", deviceID), zap.Error(err))
+		return err
+	}
+	if pubs == nil {
+		pubs = []domain.FirmwarePublication{}
+	}
+
+	return c.JSON(fiber.Map{
+		"device_id":    deviceID,
+		"publications": pubs,
+	})
+}
+
+// --- Data Transfer ---
+
+// DataTransferRequest represents a vendor-specific DataTransfer request
+type DataTransferRequest struct {
+	VendorID  string          `json:"vendor_id" validate:"required,max=255"` // OCPP 2.0.1 limit
+	MessageID string          `json:"message_id,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// DataTransfer handles POST /api/v1/devices/:id/data-transfer
+func (h *DeviceCommandHandler) DataTransfer(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	var req DataTransferRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
+	}
+
+	result, err := h.ocppService.DataTransfer(c.Context(), deviceID, req.VendorID, req.MessageID, req.Data)
+	if err != nil {
+		h.log.Error("Data transfer failed",
+			zap.String("deviceID", deviceID),
+			zap.String("vendorID", req.VendorID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return c.JSON(result)
+}
+
+// --- Diagnostics Logs ---
+
+// maxLogRequests caps the log requests listed per device
+const maxLogRequests = 50
+
+// LogRequestResponse is a GetLog request with its upload status
+type LogRequestResponse struct {
+	ID          string    `json:"id"`
+	RequestID   int       `json:"request_id"`
+	LogType     string    `json:"log_type"`
+	Status      string    `json:"status"`
+	Filename    string    `json:"filename,omitempty"`
+	DownloadURL string    `json:"download_url,omitempty"` // Set once the upload completed
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListLogs handles GET /api/v1/devices/:id/logs
+func (h *DeviceCommandHandler) ListLogs(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	if h.logRequests == nil {
+		return notImplemented("Log request tracking is not configured")
+	}
+
+	requests, err := h.logRequests.FindByChargePointID(c.Context(), deviceID, maxLogRequests)
+	if err != nil {
+		h.log.Error("Failed to list log requests", zap.String("deviceID", deviceID), zap.Error(err))
+		return err
+	}
+
+	logs := make([]LogRequestResponse, 0, len(requests))
+	for _, r := range requests {
+		item := LogRequestResponse{
+			ID:        r.ID,
+			RequestID: r.RequestID,
+			LogType:   r.LogType,
+			Status:    string(r.Status),
+			Filename:  r.Filename,
+			CreatedAt: r.CreatedAt,
+			UpdatedAt: r.UpdatedAt,
+		}
+		if r.Status == domain.LogUploadStatusUploaded {
+			item.DownloadURL = r.FileURL
+		}
+		logs = append(logs, item)
+	}
+
+	return c.JSON(fiber.Map{
+		"device_id": deviceID,
+		"logs":      logs,
+	})
+}
+
+// --- Certificates ---
+
+// InstallCertificateRequest represents a certificate installation request
+type InstallCertificateRequest struct {
+	CertificateType string `json:"certificate_type" validate:"required,oneof=V2GRootCertificate MORootCertificate CSMSRootCertificate ManufacturerRootCertificate"`
+	Certificate     string `json:"certificate" validate:"required"` // PEM
+}
+
+// ListCertificates handles GET /api/v1/devices/:id/certificates
+func (h *DeviceCommandHandler) ListCertificates(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	var certificateTypes []string
+	if types := c.Query("type"); types != "" {
+		certificateTypes = strings.Split(types, ",")
+	}
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
+	}
+
+	certificates, err := h.ocppService.GetInstalledCertificateIds(c.Context(), deviceID, certificateTypes)
+	if err != nil {
+		h.log.Error("Get installed certificates failed",
+			zap.String("deviceID", deviceID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if certificates == nil {
+		certificates = []ports.InstalledCertificate{}
+	}
+
+	return c.JSON(fiber.Map{
+		"device_id":    deviceID,
+		"certificates": certificates,
+	})
+}
+
+// InstallCertificate handles POST /api/v1/devices/:id/certificates
+func (h *DeviceCommandHandler) InstallCertificate(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	var req InstallCertificateRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+	if !strings.Contains(req.Certificate, "-----BEGIN CERTIFICATE-----") {
+		return badRequest("certificate must be PEM encoded")
+	}
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
+	}
+
+	status, err := h.ocppService.InstallCertificate(c.Context(), deviceID, req.CertificateType, req.Certificate)
+	if err != nil {
+		h.log.Error("Install certificate failed",
+			zap.String("deviceID", deviceID),
+			zap.String("certificateType", req.CertificateType),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	if status != "Accepted" {
+		return commandRejected("Device did not install the certificate", status)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  status,
+		"message": "Certificate installed",
+	})
+}
+
+// DeleteCertificate handles DELETE /api/v1/devices/:id/certificates/:serial.
+// The issuer hashes identify the certificate together with the serial number.
+func (h *DeviceCommandHandler) DeleteCertificate(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	hashData := ports.CertificateHashData{
+		HashAlgorithm:  c.Query("hash_algorithm", "SHA256"),
+		IssuerNameHash: c.Query("issuer_name_hash"),
+		IssuerKeyHash:  c.Query("issuer_key_hash"),
+		SerialNumber:   c.Params("serial"),
+	}
+	if hashData.IssuerNameHash == "" || hashData.IssuerKeyHash == "" {
+		return badRequest("issuer_name_hash and issuer_key_hash are required")
+	}
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
+	}
+
+	status, err := h.ocppService.DeleteCertificate(c.Context(), deviceID, hashData)
+	if err != nil {
+		h.log.Error("Delete certificate failed",
+			zap.String("deviceID", deviceID),
+			zap.String("serialNumber", hashData.SerialNumber),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	switch status {
+	case "Accepted":
+		return c.JSON(fiber.Map{
+			"status":  status,
+			"message": "Certificate deleted",
+		})
+	case "NotFound":
+		return middleware.NewAPIError(fiber.StatusNotFound, middleware.CodeNotFound, "Certificate is not installed on the device").
+			WithDetails(map[string]interface{}{"status": status})
+	default:
+		return commandRejected("Device did not delete the certificate", status)
+	}
+}
+
 // --- Connection Status ---
 
 // GetConnectionStatus handles GET /api/v1/devices/:id/connection
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+func TestReset_ActiveTransactionGuard(t *testing.T) {
+	charging := []domain.ActiveSession{
+		{Transaction: domain.Transaction{ID: "tx-1", ChargePointID: "CP-001", ConnectorID: 2, EvseID: 1}},
+		{Transaction: domain.Transaction{ID: "tx-9", ChargePointID: "CP-009", ConnectorID: 1, EvseID: 1}},
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		resetStatus string
+		stopErr     error
+		wantStatus  int
+		wantCalls   []string
+	}{
+		{"immediate while charging", `{"type":"Immediate"}`, "Accepted", nil, fiber.StatusConflict, nil},
+		{"default type while charging", `{}`, "Accepted", nil, fiber.StatusConflict, nil},
+		{"immediate on the charging EVSE", `{"type":"Immediate","evse_id":1}`, "Accepted", nil, fiber.StatusConflict, nil},
+		{"on idle while charging", `{"type":"OnIdle"}`, "Scheduled", nil, fiber.StatusOK, []string{"reset OnIdle"}},
+		{"immediate on an idle EVSE", `{"type":"Immediate","evse_id":2}`, "Accepted", nil, fiber.StatusOK, []string{"reset Immediate"}},
+		{"forced finalizes once accepted", `{"type":"Immediate","force":true}`, "Accepted", nil, fiber.StatusOK, []string{"reset Immediate", "stop tx-1"}},
+		{"forced but rejected", `{"type":"Immediate","force":true}`, "Rejected", nil, fiber.StatusUnprocessableEntity, []string{"reset Immediate"}},
+		{"forced but finalizing fails", `{"type":"Immediate","force":true}`, "Accepted", errors.New("database unavailable"), fiber.StatusOK, []string{"reset Immediate"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var calls []string
+			ocpp := &mocks.MockOCPPCommandService{
+				IsConnectedFunc: func(string) bool { return true },
+				ResetFunc: func(ctx context.Context, chargePointID string, resetType string, evseID *int) (string, error) {
+					calls = append(calls, "reset "+resetType)
+					return tt.resetStatus, nil
+				},
+			}
+			sessions := &mocks.MockTransactionService{
+				ListActiveSessionsFunc: func(ctx context.Context) ([]domain.ActiveSession, error) {
+					return charging, nil
+				},
+				StopTransactionFunc: func(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+					if tt.stopErr != nil {
+						return nil, tt.stopErr
+					}
+					calls = append(calls, "stop "+transactionID)
+					return &domain.Transaction{ID: transactionID, Status: domain.TransactionStatusStopped}, nil
+				},
+			}
+			h := NewDeviceCommandHandler(ocpp, nil, nil, zap.NewNop())
+			h.SetTransactionService(sessions)
+			app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+			app.Post("/devices/:id/reset", h.Reset)
+
+			req := httptest.NewRequest("POST", "/devices/CP-001/reset", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Act
+			resp, err := app.Test(req)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if strings.Join(calls, ", ") != strings.Join(tt.wantCalls, ", ") {
+				t.Errorf("expected calls %v, got %v", tt.wantCalls, calls)
+			}
+			if tt.wantStatus == fiber.StatusConflict {
+				var body struct {
+					Error middleware.APIError `json:"error"`
+				}
+				json.NewDecoder(resp.Body).Decode(&body)
+				details := body.Error.Details
+				if details["suggested_type"] != "OnIdle" {
+					t.Errorf("expected OnIdle to be suggested, got %v", details["suggested_type"])
+				}
+				if ids, _ := details["active_transactions"].([]interface{}); len(ids) != 1 || ids[0] != "tx-1" {
+					t.Errorf("expected tx-1 to be reported, got %v", details["active_transactions"])
+				}
+			}
+		})
+	}
+}
+
+func TestSetChargingProfile_MapsRESTFields(t *testing.T) {
+	// Arrange
+	var sent domain.ChargingProfile
+	ocpp := &mocks.MockOCPPCommandService{
+		IsConnectedFunc: func(string) bool { return true },
+		SetChargingProfileFunc: func(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) error {
+			sent = profile
+			return nil
+		},
+	}
+	h := NewDeviceCommandHandler(ocpp, nil, nil, zap.NewNop())
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+	app.Post("/devices/:id/charging-profile", h.SetChargingProfile)
+
+	body := `{"evse_id":1,"charging_profile":{"id":7,"stack_level":1,"purpose":"TxDefaultProfile","kind":"Recurring",
+		"recurrency_kind":"Daily","valid_from":"2026-10-16T12:00:00Z","charging_schedule":[{"duration":3600,
+		"charging_rate_unit":"W","min_charging_rate":1400,"periods":[{"start_period":0,"limit":7400,"number_phases":3}]}]}}`
+	req := httptest.NewRequest("POST", "/devices/CP-001/charging-profile", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+	if sent.ID != 7 || sent.StackLevel != 1 || sent.ChargingProfilePurpose != "TxDefaultProfile" ||
+		sent.ChargingProfileKind != "Recurring" || sent.RecurrencyKind != "Daily" {
+		t.Errorf("expected recurring daily TxDefaultProfile 7 at stack level 1, got %+v", sent)
+	}
+	if sent.ValidFrom == nil || *sent.ValidFrom != "2026-10-16T12:00:00Z" {
+		t.Errorf("expected valid_from to be kept, got %v", sent.ValidFrom)
+	}
+	if len(sent.ChargingSchedule) != 1 {
+		t.Fatalf("expected one schedule, got %+v", sent.ChargingSchedule)
+	}
+	schedule := sent.ChargingSchedule[0]
+	if schedule.ID != 1 || schedule.ChargingRateUnit != "W" || schedule.Duration == nil || *schedule.Duration != 3600 {
+		t.Errorf("expected schedule 1 in W for 3600 s, got %+v", schedule)
+	}
+	if schedule.MinChargingRate == nil || *schedule.MinChargingRate != 1400 {
+		t.Errorf("expected a minimum charging rate of 1400, got %v", schedule.MinChargingRate)
+	}
+	if len(schedule.ChargingSchedulePeriod) != 1 {
+		t.Fatalf("expected one period, got %+v", schedule.ChargingSchedulePeriod)
+	}
+	period := schedule.ChargingSchedulePeriod[0]
+	if period.StartPeriod != 0 || period.Limit != 7400 || period.NumberPhases == nil || *period.NumberPhases != 3 {
+		t.Errorf("expected 7400 W on 3 phases from 0 s, got %+v", period)
+	}
+}
+
+func TestRegisterRoutes_CommandsRequireStaff(t *testing.T) {
+	tests := []struct {
+		role       domain.UserRole
+		wantStatus int
+	}{
+		{domain.UserRoleUser, fiber.StatusForbidden},
+		{domain.UserRoleOperator, fiber.StatusOK},
+		{domain.UserRoleAdmin, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			// Arrange
+			reset := false
+			ocpp := &mocks.MockOCPPCommandService{
+				IsConnectedFunc: func(string) bool { return true },
+				ResetFunc: func(ctx context.Context, chargePointID string, resetType string, evseID *int) (string, error) {
+					reset = true
+					return "Accepted", nil
+				},
+			}
+			h := NewDeviceCommandHandler(ocpp, nil, nil, zap.NewNop())
+			pass := func(c *fiber.Ctx) error { return c.Next() }
+
+			app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+			devices := app.Group("/devices", func(c *fiber.Ctx) error {
+				c.Locals("user_role", tt.role)
+				return c.Next()
+			})
+			h.RegisterRoutes(devices, middleware.RoleRequired(domain.UserRoleAdmin, domain.UserRoleOperator), pass, pass)
+
+			req := httptest.NewRequest("POST", "/devices/CP-001/reset", strings.NewReader(`{"type":"OnIdle"}`))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Act
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			// Assert
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if reset != (tt.wantStatus == fiber.StatusOK) {
+				t.Errorf("expected reset sent only to staff, sent=%v", reset)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Data types of configuration variables (OCPP 2.0.1 DataEnumType)
+const (
+	configTypeString     = "string"
+	configTypeInteger    = "integer"
+	configTypeBoolean    = "boolean"
+	configTypeMemberList = "MemberList"
+)
+
+// ConfigVariable describes a station configuration variable the editor
+// knows about
+type ConfigVariable struct {
+	Component   string `json:"component"`
+	Name        string `json:"name"`
+	Instance    string `json:"instance,omitempty"`
+	Type        string `json:"type"`
+	ReadOnly    bool   `json:"read_only"`
+	Description string `json:"description"`
+}
+
+// configVariables are the variables shown by the configuration editor,
+// grouped by component in display order
+var configVariables = []ConfigVariable{
+	{"OCPPCommCtrlr", "HeartbeatInterval", "", configTypeInteger, false, "Seconds between heartbeats"},
+	{"OCPPCommCtrlr", "OfflineThreshold", "", configTypeInteger, false, "Seconds offline before StatusNotifications are resent"},
+	{"OCPPCommCtrlr", "MessageTimeout", "Default", configTypeInteger, false, "Seconds the station waits for a CSMS answer"},
+	{"OCPPCommCtrlr", "WebSocketPingInterval", "", configTypeInteger, false, "Seconds between WebSocket pings; 0 disables them"},
+	{"OCPPCommCtrlr", "ResetRetries", "", configTypeInteger, false, "Attempts to reset before giving up"},
+	{"TxCtrlr", "EVConnectionTimeOut", "", configTypeInteger, false, "Seconds to plug in after authorization"},
+	{"TxCtrlr", "StopTxOnEVSideDisconnect", "", configTypeBoolean, false, "Stop the transaction when the cable is unplugged at the EV"},
+	{"TxCtrlr", "TxStartPoint", "", configTypeMemberList, false, "Events that start a transaction"},
+	{"TxCtrlr", "TxStopPoint", "", configTypeMemberList, false, "Events that stop a transaction"},
+	{"AuthCtrlr", "AuthorizeRemoteStart", "", configTypeBoolean, false, "Authorize idTokens of remote starts"},
+	{"AuthCtrlr", "LocalAuthorizeOffline", "", configTypeBoolean, false, "Authorize from the local cache while offline"},
+	{"AuthCtrlr", "LocalPreAuthorize", "", configTypeBoolean, false, "Start before the CSMS confirms a cached idToken"},
+	{"SampledDataCtrlr", "TxUpdatedInterval", "", configTypeInteger, false, "Seconds between meter values during a transaction"},
+	{"SampledDataCtrlr", "TxUpdatedMeasurands", "", configTypeMemberList, false, "Measurands sent during a transaction"},
+	{"SecurityCtrlr", "SecurityProfile", "", configTypeInteger, true, "Security profile in use"},
+	{"SecurityCtrlr", "OrganizationName", "", configTypeString, false, "Organization name in station certificates"},
+	{"DeviceDataCtrlr", "ItemsPerMessage", "GetVariables", configTypeInteger, true, "Variables per GetVariables request"},
+}
+
+// findConfigVariable returns the known variable, or nil
+func findConfigVariable(component, name, instance string) *ConfigVariable {
+	for i := range configVariables {
+		v := &configVariables[i]
+		if v.Component == component && v.Name == name && v.Instance == instance {
+			return v
+		}
+	}
+	return nil
+}
+
+// checkConfigValue reports why value does not fit the variable's type, or ""
+func checkConfigValue(v *ConfigVariable, value string) string {
+	switch v.Type {
+	case configTypeInteger:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "must be an integer"
+		}
+	case configTypeBoolean:
+		if value != "true" && value != "false" {
+			return "must be true or false"
+		}
+	}
+	if len(value) > 1000 {
+		return "must be at most 1000 characters"
+	}
+	return ""
+}
+
+// ConfigVariableValue is a known variable with the station's current value
+type ConfigVariableValue struct {
+	ConfigVariable
+	Value  string `json:"value,omitempty"`
+	Status string `json:"status"` // Accepted when Value was read; otherwise the station's reason
+}
+
+// ConfigGroup holds the variables of one component
+type ConfigGroup struct {
+	Component string                `json:"component"`
+	Variables []ConfigVariableValue `json:"variables"`
+}
+
+// GetConfig handles GET /api/v1/devices/:id/config
+func (h *DeviceCommandHandler) GetConfig(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Device is not connected",
+		})
+	}
+
+	requests := make([]ports.GetVariableRequest, len(configVariables))
+	for i, v := range configVariables {
+		requests[i] = ports.GetVariableRequest{ComponentName: v.Component, VariableName: v.Name, Instance: v.Instance}
+	}
+
+	results, err := h.ocppService.GetVariables(c.Context(), deviceID, requests)
+	if err != nil {
+		h.log.Error("Get config failed",
+			zap.String("deviceID", deviceID),
+			zap.Error(err),
+		)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	values := make(map[string]ports.GetVariableResponse, len(results))
+	for _, r := range results {
+		values[configKey(r.ComponentName, r.VariableName, r.Instance)] = r
+	}
+
+	var groups []ConfigGroup
+	for _, v := range configVariables {
+		value := ConfigVariableValue{ConfigVariable: v, Status: "NoResponse"}
+		if r, ok := values[configKey(v.Component, v.Name, v.Instance)]; ok {
+			value.Value = r.Value
+			value.Status = r.Status
+		}
+
+		if len(groups) == 0 || groups[len(groups)-1].Component != v.Component {
+			groups = append(groups, ConfigGroup{Component: v.Component})
+		}
+		last := &groups[len(groups)-1]
+		last.Variables = append(last.Variables, value)
+	}
+
+	return c.JSON(fiber.Map{
+		"device_id": deviceID,
+		"groups":    groups,
+	})
+}
+
+// UpdateConfigRequest sets station configuration variables
+type UpdateConfigRequest struct {
+	Variables []ConfigChange `json:"variables" validate:"required,min=1"`
+}
+
+// ConfigChange is one variable to set
+type ConfigChange struct {
+	Component string `json:"component"`
+	Variable  string `json:"variable"`
+	Instance  string `json:"instance,omitempty"`
+	Value     string `json:"value"`
+}
+
+// ConfigChangeResult is the station's verdict on one change
+type ConfigChangeResult struct {
+	ConfigChange
+	Status     string `json:"status"` // Accepted, Rejected, RebootRequired, UnknownComponent, UnknownVariable, NotSupportedAttributeType
+	StatusInfo string `json:"status_info,omitempty"`
+}
+
+// UpdateConfig handles PUT /api/v1/devices/:id/config
+// Only known, writable variables with values of the right type are sent;
+// anything else fails the whole request with field-level errors.
+func (h *DeviceCommandHandler) UpdateConfig(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	var req UpdateConfigRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	fields := map[string]string{}
+	requests := make([]ports.SetVariableRequest, len(req.Variables))
+	for i, change := range req.Variables {
+		field := fmt.Sprintf("variables[%d]", i)
+		v := findConfigVariable(change.Component, change.Variable, change.Instance)
+		switch {
+		case v == nil:
+			fields[field] = "is not a known variable"
+		case v.ReadOnly:
+			fields[field] = "is read-only"
+		default:
+			if msg := checkConfigValue(v, change.Value); msg != "" {
+				fields[field+".value"] = msg
+			}
+		}
+		requests[i] = ports.SetVariableRequest{
+			ComponentName: change.Component,
+			VariableName:  change.Variable,
+			Instance:      change.Instance,
+			Value:         change.Value,
+		}
+	}
+	if len(fields) > 0 {
+		return &validation.Errors{Fields: fields}
+	}
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Device is not connected",
+		})
+	}
+
+	results, err := h.ocppService.SetVariables(c.Context(), deviceID, requests)
+	if err != nil {
+		h.log.Error("Update config failed",
+			zap.String("deviceID", deviceID),
+			zap.Error(err),
+		)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	statuses := make(map[string]ports.SetVariableResult, len(results))
+	for _, r := range results {
+		statuses[configKey(r.ComponentName, r.VariableName, r.Instance)] = r
+	}
+
+	response := make([]ConfigChangeResult, len(req.Variables))
+	accepted := 0
+	rebootRequired := false
+	for i, change := range req.Variables {
+		result := ConfigChangeResult{ConfigChange: change, Status: "NoResponse"}
+		if r, ok := statuses[configKey(change.Component, change.Variable, change.Instance)]; ok {
+			result.Status = r.Status
+			result.StatusInfo = r.StatusInfo
+		}
+		switch result.Status {
+		case "Accepted":
+			accepted++
+		case "RebootRequired":
+			accepted++
+			rebootRequired = true
+		}
+		response[i] = result
+	}
+
+	return c.JSON(fiber.Map{
+		"device_id":       deviceID,
+		"results":         response,
+		"accepted":        accepted,
+		"rejected":        len(response) - accepted,
+		"reboot_required": rebootRequired,
+	})
+}
+
+// configKey identifies a variable in GetVariables and SetVariables results
+func configKey(component, variable, instance string) string {
+	return strings.Join([]string{component, variable, instance}, "/")
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+func newConfigTestApp(ocpp *mocks.MockOCPPCommandService) *fiber.App {
+	if ocpp.IsConnectedFunc == nil {
+		ocpp.IsConnectedFunc = func(string) bool { return true }
+	}
+	h := NewDeviceCommandHandler(ocpp, nil, nil, zap.NewNop())
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+	app.Get("/devices/:id/config", h.GetConfig)
+	app.Put("/devices/:id/config", h.UpdateConfig)
+	return app
+}
+
+func doConfigRequest(t *testing.T, app *fiber.App, method, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, "/devices/CP-001/config", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestUpdateConfig_PartialSuccess(t *testing.T) {
+	// Arrange - the station accepts one change, needs a reboot for another
+	// and rejects the third
+	var sent []ports.SetVariableRequest
+	ocpp := &mocks.MockOCPPCommandService{
+		SetVariablesFunc: func(ctx context.Context, cpID string, variables []ports.SetVariableRequest) ([]ports.SetVariableResult, error) {
+			sent = variables
+			return []ports.SetVariableResult{
+				{ComponentName: "OCPPCommCtrlr", VariableName: "HeartbeatInterval", Status: "Accepted"},
+				{ComponentName: "OCPPCommCtrlr", VariableName: "WebSocketPingInterval", Status: "RebootRequired"},
+				{ComponentName: "TxCtrlr", VariableName: "EVConnectionTimeOut", Status: "Rejected", StatusInfo: "ValueOutOfRange"},
+			}, nil
+		},
+	}
+	app := newConfigTestApp(ocpp)
+	body := `{"variables":[
+		{"component":"OCPPCommCtrlr","variable":"HeartbeatInterval","value":"300"},
+		{"component":"OCPPCommCtrlr","variable":"WebSocketPingInterval","value":"30"},
+		{"component":"TxCtrlr","variable":"EVConnectionTimeOut","value":"99999"}
+	]}`
+
+	// Act
+	status, resp := doConfigRequest(t, app, "PUT", body)
+
+	// Assert
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status 200, got %d: %v", status, resp)
+	}
+	if len(sent) != 3 || sent[0].Value != "300" {
+		t.Fatalf("expected the three changes to be sent, got %+v", sent)
+	}
+	if resp["accepted"] != float64(2) || resp["rejected"] != float64(1) || resp["reboot_required"] != true {
+		t.Errorf("expected 2 accepted, 1 rejected and a reboot, got %v", resp)
+	}
+	results := resp["results"].([]interface{})
+	expected := []string{"Accepted", "RebootRequired", "Rejected"}
+	for i, r := range results {
+		if got := r.(map[string]interface{})["status"]; got != expected[i] {
+			t.Errorf("expected result %d to be %s, got %v", i, expected[i], got)
+		}
+	}
+	if info := results[2].(map[string]interface{})["status_info"]; info != "ValueOutOfRange" {
+		t.Errorf("expected the rejection reason, got %v", info)
+	}
+}
+
+func TestUpdateConfig_RejectsInvalidChangesLocally(t *testing.T) {
+	// Arrange
+	called := false
+	ocpp := &mocks.MockOCPPCommandService{
+		SetVariablesFunc: func(ctx context.Context, cpID string, variables []ports.SetVariableRequest) ([]ports.SetVariableResult, error) {
+			called = true
+			return nil, nil
+		},
+	}
+	app := newConfigTestApp(ocpp)
+	body := `{"variables":[
+		{"component":"SecurityCtrlr","variable":"SecurityProfile","value":"3"},
+		{"component":"OCPPCommCtrlr","variable":"HeartbeatInterval","value":"often"},
+		{"component":"VendorCtrlr","variable":"Secret","value":"x"}
+	]}`
+
+	// Act
+	status, resp := doConfigRequest(t, app, "PUT", body)
+
+	// Assert
+	if status != fiber.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", status)
+	}
+	if called {
+		t.Error("expected nothing to be sent to the station")
+	}
+	fields := resp["error"].(map[string]interface{})["details"].(map[string]interface{})["fields"].(map[string]interface{})
+	if fields["variables[0]"] != "is read-only" {
+		t.Errorf("expected variables[0] to be read-only, got %v", fields["variables[0]"])
+	}
+	if fields["variables[1].value"] != "must be an integer" {
+		t.Errorf("expected variables[1].value to need an integer, got %v", fields["variables[1].value"])
+	}
+	if fields["variables[2]"] != "is not a known variable" {
+		t.Errorf("expected variables[2] to be unknown, got %v", fields["variables[2]"])
+	}
+}
+
+func TestGetConfig_GroupsKnownVariables(t *testing.T) {
+	// Arrange
+	var requested []ports.GetVariableRequest
+	ocpp := &mocks.MockOCPPCommandService{
+		GetVariablesFunc: func(ctx context.Context, cpID string, variables []ports.GetVariableRequest) ([]ports.GetVariableResponse, error) {
+			requested = variables
+			return []ports.GetVariableResponse{
+				{ComponentName: "OCPPCommCtrlr", VariableName: "HeartbeatInterval", Value: "300", Status: "Accepted"},
+				{ComponentName: "SecurityCtrlr", VariableName: "SecurityProfile", Value: "2", Status: "Accepted"},
+				{ComponentName: "TxCtrlr", VariableName: "TxStartPoint", Status: "UnknownVariable"},
+			}, nil
+		},
+	}
+	app := newConfigTestApp(ocpp)
+
+	// Act
+	status, resp := doConfigRequest(t, app, "GET", "")
+
+	// Assert
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status 200, got %d", status)
+	}
+	if len(requested) != len(configVariables) {
+		t.Errorf("expected %d variables requested, got %d", len(configVariables), len(requested))
+	}
+	groups := resp["groups"].([]interface{})
+	first := groups[0].(map[string]interface{})
+	if first["component"] != "OCPPCommCtrlr" {
+		t.Fatalf("expected OCPPCommCtrlr first, got %v", first["component"])
+	}
+	heartbeat := first["variables"].([]interface{})[0].(map[string]interface{})
+	if heartbeat["value"] != "300" || heartbeat["type"] != "integer" || heartbeat["read_only"] != false {
+		t.Errorf("expected HeartbeatInterval 300 with metadata, got %v", heartbeat)
+	}
+
+	for _, g := range groups {
+		group := g.(map[string]interface{})
+		if group["component"] != "SecurityCtrlr" {
+			continue
+		}
+		profile := group["variables"].([]interface{})[0].(map[string]interface{})
+		if profile["read_only"] != true || profile["value"] != "2" {
+			t.Errorf("expected a read-only SecurityProfile of 2, got %v", profile)
+		}
+	}
+}
+
+func TestGetConfig_DeviceNotConnected(t *testing.T) {
+	// Arrange
+	app := newConfigTestApp(&mocks.MockOCPPCommandService{
+		IsConnectedFunc: func(string) bool { return false },
+	})
+
+	// Act
+	status, _ := doConfigRequest(t, app, "GET", "")
+
+	// Assert
+	if status != fiber.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", status)
+	}
+}
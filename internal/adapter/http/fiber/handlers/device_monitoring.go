@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetMonitoringRequest configures variable monitors on a station
+type SetMonitoringRequest struct {
+	Monitors []MonitorConfig `json:"monitors" validate:"required,min=1"`
+}
+
+// MonitorConfig is one variable monitor. Events it raises become alerts.
+type MonitorConfig struct {
+	ID          *int    `json:"id,omitempty"` // Set to replace an existing monitor
+	Component   string  `json:"component" validate:"required"`
+	Variable    string  `json:"variable" validate:"required"`
+	Instance    string  `json:"instance,omitempty"`
+	Type        string  `json:"type" validate:"required,oneof=UpperThreshold LowerThreshold Delta Periodic PeriodicClockAligned"`
+	Value       float64 `json:"value"`
+	Severity    int     `json:"severity" validate:"min=0,max=9"` // 0 (Danger) .. 9 (Debug)
+	Transaction bool    `json:"transaction,omitempty"`           // Only monitor during transactions
+}
+
+// MonitorResult is the station's verdict on one monitor
+type MonitorResult struct {
+	ID         *int   `json:"id,omitempty"`
+	Component  string `json:"component"`
+	Variable   string `json:"variable"`
+	Instance   string `json:"instance,omitempty"`
+	Status     string `json:"status"` // Accepted, UnknownComponent, UnknownVariable, UnsupportedMonitorType, Rejected, Duplicate
+	StatusInfo string `json:"status_info,omitempty"`
+}
+
+// SetMonitoring handles POST /api/v1/devices/:id/monitoring
+// Every monitor is validated first; the station then answers for each.
+func (h *DeviceCommandHandler) SetMonitoring(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	var req SetMonitoringRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	fields := map[string]string{}
+	monitors := make([]ports.VariableMonitor, len(req.Monitors))
+	for i, m := range req.Monitors {
+		var invalid *validation.Errors
+		if err := validation.Struct(m); errors.As(err, &invalid) {
+			for name, msg := range invalid.Fields {
+				fields[fmt.Sprintf("monitors[%d].%s", i, name)] = msg
+			}
+		}
+		monitors[i] = ports.VariableMonitor{
+			ID:            m.ID,
+			ComponentName: m.Component,
+			VariableName:  m.Variable,
+			Instance:      m.Instance,
+			Type:          m.Type,
+			Value:         m.Value,
+			Severity:      m.Severity,
+			Transaction:   m.Transaction,
+		}
+	}
+	if len(fields) > 0 {
+		return &validation.Errors{Fields: fields}
+	}
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
+	}
+
+	results, err := h.ocppService.SetVariableMonitoring(c.Context(), deviceID, monitors)
+	if err != nil {
+		h.log.Error("Set variable monitoring failed",
+			zap.String("deviceID", deviceID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	accepted := 0
+	response := make([]MonitorResult, len(results))
+	for i, r := range results {
+		if r.Status == "Accepted" {
+			accepted++
+		}
+		response[i] = MonitorResult{
+			ID:         r.ID,
+			Component:  r.ComponentName,
+			Variable:   r.VariableName,
+			Instance:   r.Instance,
+			Status:     r.Status,
+			StatusInfo: r.StatusInfo,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"device_id": deviceID,
+		"results":   response,
+		"accepted":  accepted,
+		"rejected":  len(results) - accepted,
+	})
+}
+
+// SetMonitoringBaseRequest selects a predefined set of monitors
+type SetMonitoringBaseRequest struct {
+	MonitoringBase string `json:"monitoring_base" validate:"required,oneof=All FactoryDefault HardWiredOnly"`
+}
+
+// SetMonitoringBase handles PUT /api/v1/devices/:id/monitoring/base
+// FactoryDefault and HardWiredOnly remove the monitors set through the API.
+func (h *DeviceCommandHandler) SetMonitoringBase(c *fiber.Ctx) error {
+	deviceID := c.Params("id")
+
+	var req SetMonitoringBaseRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if !h.ocppService.IsConnected(deviceID) {
+		return deviceNotConnected()
+	}
+
+	status, err := h.ocppService.SetMonitoringBase(c.Context(), deviceID, req.MonitoringBase)
+	if err != nil {
+		h.log.Error("Set monitoring base failed",
+			zap.String("deviceID", deviceID),
+			zap.String("monitoringBase", req.MonitoringBase),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	if status != "Accepted" {
+		return commandRejected("Device did not activate the monitoring base", status)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  status,
+		"message": "Monitoring base activated",
+	})
+}
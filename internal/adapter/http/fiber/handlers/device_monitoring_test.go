@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+func newMonitoringTestApp(ocpp *mocks.MockOCPPCommandService) *fiber.App {
+	if ocpp.IsConnectedFunc == nil {
+		ocpp.IsConnectedFunc = func(string) bool { return true }
+	}
+	h := NewDeviceCommandHandler(ocpp, nil, nil, zap.NewNop())
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+	app.Post("/devices/:id/monitoring", h.SetMonitoring)
+	app.Put("/devices/:id/monitoring/base", h.SetMonitoringBase)
+	return app
+}
+
+func doMonitoringRequest(t *testing.T, app *fiber.App, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestSetMonitoring_ReportsEachMonitor(t *testing.T) {
+	// Arrange - the station accepts one monitor and does not know the other
+	var sent []ports.VariableMonitor
+	ocpp := &mocks.MockOCPPCommandService{
+		SetVariableMonitoringFunc: func(ctx context.Context, cpID string, monitors []ports.VariableMonitor) ([]ports.VariableMonitorResult, error) {
+			sent = monitors
+			id := 7
+			return []ports.VariableMonitorResult{
+				{ID: &id, ComponentName: "EVSE", VariableName: "Power", Status: "Accepted"},
+				{ComponentName: "Connector", VariableName: "Temperature", Status: "UnknownVariable", StatusInfo: "NotSupported"},
+			}, nil
+		},
+	}
+	app := newMonitoringTestApp(ocpp)
+	body := `{"monitors":[
+		{"component":"EVSE","variable":"Power","type":"UpperThreshold","value":22000,"severity":2},
+		{"component":"Connector","variable":"Temperature","type":"Delta","value":5,"severity":4,"transaction":true}
+	]}`
+
+	// Act
+	status, resp := doMonitoringRequest(t, app, "POST", "/devices/CP-001/monitoring", body)
+
+	// Assert
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status 200, got %d: %v", status, resp)
+	}
+	if len(sent) != 2 || sent[0].Value != 22000 || sent[0].Severity != 2 || !sent[1].Transaction {
+		t.Fatalf("expected both monitors to be sent, got %+v", sent)
+	}
+	if resp["accepted"] != float64(1) || resp["rejected"] != float64(1) {
+		t.Errorf("expected 1 accepted and 1 rejected, got %v", resp)
+	}
+	results := resp["results"].([]interface{})
+	if first := results[0].(map[string]interface{}); first["id"] != float64(7) {
+		t.Errorf("expected the station's monitor ID, got %v", first["id"])
+	}
+	if second := results[1].(map[string]interface{}); second["status_info"] != "NotSupported" {
+		t.Errorf("expected the rejection reason, got %v", second["status_info"])
+	}
+}
+
+func TestSetMonitoring_RejectsInvalidMonitorsLocally(t *testing.T) {
+	// Arrange
+	called := false
+	ocpp := &mocks.MockOCPPCommandService{
+		SetVariableMonitoringFunc: func(ctx context.Context, cpID string, monitors []ports.VariableMonitor) ([]ports.VariableMonitorResult, error) {
+			called = true
+			return nil, nil
+		},
+	}
+	app := newMonitoringTestApp(ocpp)
+	body := `{"monitors":[
+		{"component":"EVSE","variable":"Power","type":"Above","value":1},
+		{"component":"EVSE","variable":"Power","type":"Delta","value":1,"severity":12}
+	]}`
+
+	// Act
+	status, resp := doMonitoringRequest(t, app, "POST", "/devices/CP-001/monitoring", body)
+
+	// Assert
+	if status != fiber.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", status)
+	}
+	if called {
+		t.Error("expected nothing to be sent to the station")
+	}
+	fields := resp["error"].(map[string]interface{})["details"].(map[string]interface{})["fields"].(map[string]interface{})
+	if _, ok := fields["monitors[0].type"]; !ok {
+		t.Errorf("expected monitors[0].type to be rejected, got %v", fields)
+	}
+	if _, ok := fields["monitors[1].severity"]; !ok {
+		t.Errorf("expected monitors[1].severity to be rejected, got %v", fields)
+	}
+}
+
+func TestSetMonitoringBase_Rejected(t *testing.T) {
+	// Arrange
+	var requested string
+	ocpp := &mocks.MockOCPPCommandService{
+		SetMonitoringBaseFunc: func(ctx context.Context, cpID string, monitoringBase string) (string, error) {
+			requested = monitoringBase
+			return "NotSupported", nil
+		},
+	}
+	app := newMonitoringTestApp(ocpp)
+
+	// Act
+	status, _ := doMonitoringRequest(t, app, "PUT", "/devices/CP-001/monitoring/base", `{"monitoring_base":"FactoryDefault"}`)
+
+	// Assert
+	if status != fiber.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", status)
+	}
+	if requested != "FactoryDefault" {
+		t.Errorf("expected FactoryDefault to be requested, got %q", requested)
+	}
+}
+
+func TestSetMonitoringBase_DeviceNotConnected(t *testing.T) {
+	// Arrange
+	app := newMonitoringTestApp(&mocks.MockOCPPCommandService{
+		IsConnectedFunc: func(string) bool { return false },
+	})
+
+	// Act
+	status, _ := doMonitoringRequest(t, app, "PUT", "/devices/CP-001/monitoring/base", `{"monitoring_base":"All"}`)
+
+	// Assert
+	if status != fiber.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", status)
+	}
+}
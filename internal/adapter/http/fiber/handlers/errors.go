@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+)
+
+// badRequest reports invalid input as VALIDATION_ERROR
+func badRequest(message string) error {
+	return middleware.NewAPIError(fiber.StatusBadRequest, middleware.CodeValidation, message)
+}
+
+// notImplemented reports a feature this deployment does not provide
+func notImplemented(message string) error {
+	return middleware.NewAPIError(fiber.StatusNotImplemented, middleware.CodeNotImplemented, message)
+}
+
+// deviceNotConnected reports a command for a device with no open OCPP
+// connection
+func deviceNotConnected() error {
+	return middleware.NewAPIError(fiber.StatusServiceUnavailable, middleware.CodeDeviceNotConnected, "Device is not connected")
+}
+
+// commandRejected reports a command the device answered with status instead
+// of accepting it
+func commandRejected(message, status string) error {
+	return middleware.NewAPIError(fiber.StatusUnprocessableEntity, middleware.CodeCommandRejected, message).
+		WithDetails(map[string]interface{}{"status": status})
+}
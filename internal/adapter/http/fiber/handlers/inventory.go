@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// InventoryHandler exposes the device model reported by charge points
+type InventoryHandler struct {
+	repo ports.DeviceInventoryRepository
+	log  *zap.Logger
+}
+
+func NewInventoryHandler(repo ports.DeviceInventoryRepository, log *zap.Logger) *InventoryHandler {
+	return &InventoryHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Get handles GET /api/v1/devices/:id/inventory
+func (h *InventoryHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+	inventory, err := h.repo.GetByChargePointID(c.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to get device inventory", zap.String("device_id", id), zap.Error(err))
+		return err
+	}
+	if inventory == nil {
+		return domain.ErrInventoryNotFound
+	}
+	return c.JSON(inventory)
+}
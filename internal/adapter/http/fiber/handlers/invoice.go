@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// InvoiceHandler serves invoices for the user's charging sessions
+type InvoiceHandler struct {
+	service ports.InvoiceService
+	log     *zap.Logger
+}
+
+func NewInvoiceHandler(service ports.InvoiceService, log *zap.Logger) *InvoiceHandler {
+	return &InvoiceHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// GetPDF handles GET /api/v1/invoices/:id.pdf
+func (h *InvoiceHandler) GetPDF(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+
+	invoice, err := h.service.GetInvoice(c.Context(), userID, id)
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvoiceNotFound) {
+			h.log.Error("Failed to get invoice", zap.String("invoice_id", id), zap.Error(err))
+		}
+		return err
+	}
+
+	pdf, err := h.service.RenderPDF(c.Context(), invoice)
+	if err != nil {
+		h.log.Error("Failed to render invoice", zap.String("invoice_id", id), zap.Error(err))
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", invoice.ID+".pdf"))
+	return c.Send(pdf)
+}
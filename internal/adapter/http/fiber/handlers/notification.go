@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// NotificationHandler manages the caller's notification preferences
+type NotificationHandler struct {
+	service ports.NotificationPreferenceService
+	log     *zap.Logger
+}
+
+func NewNotificationHandler(service ports.NotificationPreferenceService, log *zap.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// GetPreferences handles GET /api/v1/users/me/notifications
+func (h *NotificationHandler) GetPreferences(c *fiber.Ctx) error {
+	prefs, err := h.service.GetPreferences(c.Context(), c.Locals("user_id").(string))
+	if err != nil {
+		h.log.Error("Failed to get notification preferences", zap.Error(err))
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"preferences": prefs,
+		"event_types": domain.NotificationTypes(),
+	})
+}
+
+// UpdatePreferences handles PUT /api/v1/users/me/notifications
+func (h *NotificationHandler) UpdatePreferences(c *fiber.Ctx) error {
+	var req ports.NotificationPreferencesRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	prefs, err := h.service.UpdatePreferences(c.Context(), c.Locals("user_id").(string), &req)
+	if err != nil {
+		if !errors.Is(err, domain.ErrValidation) {
+			h.log.Error("Failed to update notification preferences", zap.Error(err))
+		}
+		return err
+	}
+	return c.JSON(fiber.Map{"preferences": prefs})
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -20,22 +22,22 @@ func NewTransactionHandler(service ports.TransactionService, log *zap.Logger) *T
 }
 
 type StartTransactionRequest struct {
-	DeviceID    string `json:"device_id"`
+	DeviceID    string `json:"device_id" validate:"required"`
 	ConnectorID int    `json:"connector_id"`
 	IdTag       string `json:"rfid_tag"` // Optional
 }
 
 func (h *TransactionHandler) Start(c *fiber.Ctx) error {
 	var req StartTransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	userID := c.Locals("user_id").(string) // Assumes middleware sets this
 
 	tx, err := h.service.StartTransaction(c.Context(), req.DeviceID, req.ConnectorID, userID, req.IdTag)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(tx)
@@ -45,7 +47,7 @@ func (h *TransactionHandler) Stop(c *fiber.Ctx) error {
 	id := c.Params("id")
 	tx, err := h.service.StopTransaction(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(tx)
 }
@@ -54,10 +56,10 @@ func (h *TransactionHandler) Get(c *fiber.Ctx) error {
 	id := c.Params("id")
 	tx, err := h.service.GetTransaction(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	if tx == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Transaction not found"})
+		return domain.ErrTransactionNotFound
 	}
 	return c.JSON(tx)
 }
@@ -66,7 +68,7 @@ func (h *TransactionHandler) GetHistory(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	txs, err := h.service.GetTransactionHistory(c.Context(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(txs)
 }
@@ -75,10 +77,37 @@ func (h *TransactionHandler) GetActive(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	tx, err := h.service.GetActiveTransaction(c.Context(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	if tx == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No active transaction"})
+		return domain.ErrNoActiveSession
 	}
 	return c.JSON(tx)
 }
+
+// GetProjection handles GET /api/v1/transactions/active/projection
+// Optional query: target_kwh, or target_soc with current_soc and battery_kwh
+func (h *TransactionHandler) GetProjection(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	target := domain.ChargeTarget{
+		EnergyKWh:          c.QueryFloat("target_kwh", 0),
+		TargetSOC:          c.QueryFloat("target_soc", 0),
+		CurrentSOC:         c.QueryFloat("current_soc", 0),
+		BatteryCapacityKWh: c.QueryFloat("battery_kwh", 0),
+	}
+	if target.EnergyKWh < 0 || target.BatteryCapacityKWh < 0 ||
+		target.TargetSOC < 0 || target.TargetSOC > 100 ||
+		target.CurrentSOC < 0 || target.CurrentSOC > 100 {
+		return badRequest("Invalid charge target")
+	}
+	if target.TargetSOC > 0 && target.BatteryCapacityKWh == 0 {
+		return badRequest("battery_kwh is required with target_soc")
+	}
+
+	projection, err := h.service.GetProjectedSessionCost(c.Context(), userID, target)
+	if err != nil {
+		return err
+	}
+	return c.JSON(projection)
+}
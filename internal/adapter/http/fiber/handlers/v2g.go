@@ -228,6 +228,9 @@ type SetPreferencesRequest struct {
 	PreserveSOC     int     `json:"preserve_soc"`
 	NotifyOnStart   bool    `json:"notify_on_start"`
 	NotifyOnEnd     bool    `json:"notify_on_end"`
+	SmartCharge     bool    `json:"smart_charge"`
+	DepartureTime   string  `json:"departure_time"` // local "15:04"
+	DepartureSOC    int     `json:"departure_soc"`
 }
 
 // GetPreferences handles GET /api/v1/v2g/preferences
@@ -263,6 +266,9 @@ func (h *V2GHandler) SetPreferences(c *fiber.Ctx) error {
 		PreserveSOC:     req.PreserveSOC,
 		NotifyOnStart:   req.NotifyOnStart,
 		NotifyOnEnd:     req.NotifyOnEnd,
+		SmartCharge:     req.SmartCharge,
+		DepartureTime:   req.DepartureTime,
+		DepartureSOC:    req.DepartureSOC,
 	}
 
 	err := h.v2gService.SetUserPreferences(c.Context(), userID, prefs)
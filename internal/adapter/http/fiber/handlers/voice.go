@@ -29,26 +29,40 @@ type AudioCommandRequest struct {
 func (h *VoiceHandler) ProcessCommand(c *fiber.Ctx) error {
 	var req AudioCommandRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+		return badRequest("Invalid body")
 	}
 
 	userID := c.Locals("user_id").(string)
 
 	audioBytes, err := base64.StdEncoding.DecodeString(req.Audio)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid base64 audio"})
+		return badRequest("Invalid base64 audio")
 	}
 
 	resp, err := h.assistant.ProcessVoiceCommand(c.Context(), userID, audioBytes)
 	if err != nil {
 		h.log.Error("Failed to process voice command", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to process voice command"})
+		return err
 	}
 
 	return c.JSON(resp)
 }
 
+// GetHistory handles GET /api/v1/voice/history
 func (h *VoiceHandler) GetHistory(c *fiber.Ctx) error {
-	// Not implemented in this snippet
-	return c.JSON([]string{})
+	userID := c.Locals("user_id").(string)
+	limit := c.QueryInt("limit", 20)
+	offset := c.QueryInt("offset", 0)
+
+	commands, err := h.assistant.GetHistory(c.Context(), userID, limit, offset)
+	if err != nil {
+		h.log.Error("Failed to get voice history", zap.Error(err))
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"commands": commands,
+		"limit":    limit,
+		"offset":   offset,
+	})
 }
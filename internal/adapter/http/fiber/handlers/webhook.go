@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// WebhookHandler manages the caller's webhook endpoints
+type WebhookHandler struct {
+	service ports.WebhookService
+	log     *zap.Logger
+}
+
+func NewWebhookHandler(service ports.WebhookService, log *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// registeredWebhook is the one response that carries the signing secret
+type registeredWebhook struct {
+	*domain.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// Create handles POST /api/v1/webhooks
+func (h *WebhookHandler) Create(c *fiber.Ctx) error {
+	var req ports.WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest("Invalid body")
+	}
+
+	endpoint, err := h.service.Register(c.Context(), c.Locals("user_id").(string), &req)
+	if err != nil {
+		return h.fail("Failed to register webhook", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(registeredWebhook{endpoint, endpoint.Secret})
+}
+
+// List handles GET /api/v1/webhooks
+func (h *WebhookHandler) List(c *fiber.Ctx) error {
+	endpoints, err := h.service.List(c.Context(), c.Locals("user_id").(string))
+	if err != nil {
+		return h.fail("Failed to list webhooks", err)
+	}
+	return c.JSON(fiber.Map{"webhooks": endpoints})
+}
+
+// Get handles GET /api/v1/webhooks/:id
+func (h *WebhookHandler) Get(c *fiber.Ctx) error {
+	endpoint, err := h.service.Get(c.Context(), c.Locals("user_id").(string), c.Params("id"))
+	if err != nil {
+		return h.fail("Failed to get webhook", err)
+	}
+	return c.JSON(endpoint)
+}
+
+// Update handles PATCH /api/v1/webhooks/:id
+func (h *WebhookHandler) Update(c *fiber.Ctx) error {
+	var req ports.WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest("Invalid body")
+	}
+
+	endpoint, err := h.service.Update(c.Context(), c.Locals("user_id").(string), c.Params("id"), &req)
+	if err != nil {
+		return h.fail("Failed to update webhook", err)
+	}
+	return c.JSON(endpoint)
+}
+
+// Delete handles DELETE /api/v1/webhooks/:id
+func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
+	if err := h.service.Delete(c.Context(), c.Locals("user_id").(string), c.Params("id")); err != nil {
+		return h.fail("Failed to delete webhook", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Deliveries handles GET /api/v1/webhooks/:id/deliveries
+func (h *WebhookHandler) Deliveries(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 20)
+	offset := c.QueryInt("offset", 0)
+
+	deliveries, err := h.service.Deliveries(c.Context(), c.Locals("user_id").(string), c.Params("id"), limit, offset)
+	if err != nil {
+		return h.fail("Failed to get webhook deliveries", err)
+	}
+	return c.JSON(fiber.Map{
+		"deliveries": deliveries,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
+
+// fail logs unexpected errors; the error handler maps them all to a response
+func (h *WebhookHandler) fail(msg string, err error) error {
+	if !errors.Is(err, domain.ErrWebhookNotFound) && !errors.Is(err, domain.ErrValidation) {
+		h.log.Error(msg, zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
+)
+
+// Stable error codes returned to API clients
+const (
+	CodeValidation          = "VALIDATION_ERROR"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeForbidden           = "FORBIDDEN"
+	CodeNotFound            = "NOT_FOUND"
+	CodeConflict            = "CONFLICT"
+	CodeRateLimited         = "RATE_LIMITED"
+	CodeRequestFailed       = "REQUEST_FAILED"
+	CodeInternal            = "INTERNAL_ERROR"
+	CodeDeviceNotFound      = "DEVICE_NOT_FOUND"
+	CodeTransactionNotFound = "TRANSACTION_NOT_FOUND"
+	CodeInventoryNotFound   = "INVENTORY_NOT_FOUND"
+	CodeProfilesNotFound    = "CHARGING_PROFILES_NOT_FOUND"
+	CodeInvoiceNotFound     = "INVOICE_NOT_FOUND"
+	CodeNoActiveSession     = "NO_ACTIVE_SESSION"
+	CodeInsufficientBalance = "INSUFFICIENT_BALANCE"
+	CodeInvalidCredentials  = "INVALID_CREDENTIALS"
+	CodeInvalidToken        = "INVALID_TOKEN"
+	CodeEmailRegistered     = "EMAIL_ALREADY_REGISTERED"
+	CodeDocumentRegistered  = "DOCUMENT_ALREADY_REGISTERED"
+	CodeInvalidTransition   = "INVALID_TRANSITION"
+	CodeWebhookNotFound     = "WEBHOOK_NOT_FOUND"
+	CodeConnectorBusy       = "CONNECTOR_BUSY"
+	CodeOutsideGeofence     = "OUTSIDE_GEOFENCE"
+	CodeFirmwareBusy        = "FIRMWARE_OPERATION_IN_PROGRESS"
+	CodePaymentDeclined     = "PAYMENT_DECLINED"
+	CodeConnectorNotFound   = "CONNECTOR_NOT_FOUND"
+	CodeReplayedRequest     = "REPLAYED_REQUEST"
+	CodeDeviceNotConnected  = "DEVICE_NOT_CONNECTED"
+	CodeCommandRejected     = "COMMAND_REJECTED"
+	CodeNotImplemented      = "NOT_IMPLEMENTED"
+)
+
+// APIError is the body of every error response:
+// {"error": {"code": "...", "message": "...", "details": {...}}}
+type APIError struct {
+	Status  int                    `json:"-"`
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// NewAPIError creates an error with an explicit status and code
+func NewAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// WithDetails attaches extra context, e.g. the offending field
+func (e *APIError) WithDetails(details map[string]interface{}) *APIError {
+	e.Details = details
+	return e
+}
+
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings maps domain and service errors to a status and stable code.
+// Specific sentinels come before the generic kinds they may wrap.
+var errorMappings = []errorMapping{
+	{domain.ErrDeviceNotFound, fiber.StatusNotFound, CodeDeviceNotFound},
+	{domain.ErrTransactionNotFound, fiber.StatusNotFound, CodeTransactionNotFound},
+	{domain.ErrInventoryNotFound, fiber.StatusNotFound, CodeInventoryNotFound},
+	{domain.ErrChargingProfilesNotFound, fiber.StatusNotFound, CodeProfilesNotFound},
+	{domain.ErrInvoiceNotFound, fiber.StatusNotFound, CodeInvoiceNotFound},
+	{domain.ErrNoActiveSession, fiber.StatusNotFound, CodeNoActiveSession},
+	{domain.ErrInsufficientBalance, fiber.StatusConflict, CodeInsufficientBalance},
+	{domain.ErrInvalidCredentials, fiber.StatusUnauthorized, CodeInvalidCredentials},
+	{domain.ErrInvalidToken, fiber.StatusUnauthorized, CodeInvalidToken},
+	{domain.ErrEmailAlreadyRegistered, fiber.StatusConflict, CodeEmailRegistered},
+	{domain.ErrDocumentAlreadyRegistered, fiber.StatusConflict, CodeDocumentRegistered},
+	{domain.ErrInvalidTransition, fiber.StatusConflict, CodeInvalidTransition},
+	{domain.ErrWebhookNotFound, fiber.StatusNotFound, CodeWebhookNotFound},
+	{domain.ErrConnectorUnavailable, fiber.StatusConflict, CodeConnectorBusy},
+	{domain.ErrUnknownConnector, fiber.StatusNotFound, CodeConnectorNotFound},
+	{domain.ErrOutsideGeofence, fiber.StatusForbidden, CodeOutsideGeofence},
+	{domain.ErrFirmwareOperationInProgress, fiber.StatusConflict, CodeFirmwareBusy},
+	{domain.ErrPaymentHoldDeclined, fiber.StatusPaymentRequired, CodePaymentDeclined},
+	{domain.ErrChargePointNotConnected, fiber.StatusServiceUnavailable, CodeDeviceNotConnected},
+	{domain.ErrCommandRejected, fiber.StatusUnprocessableEntity, CodeCommandRejected},
+	{domain.ErrNotFound, fiber.StatusNotFound, CodeNotFound},
+	{domain.ErrConflict, fiber.StatusConflict, CodeConflict},
+	{domain.ErrUnauthorized, fiber.StatusUnauthorized, CodeUnauthorized},
+	{domain.ErrValidation, fiber.StatusBadRequest, CodeValidation},
+}
+
+// ToAPIError converts any handler error into an APIError. Unknown errors
+// become INTERNAL_ERROR with a generic message so internals never leak.
+func ToAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var fieldErrs *validation.Errors
+	if errors.As(err, &fieldErrs) {
+		return NewAPIError(fiber.StatusUnprocessableEntity, CodeValidation, "Validation failed").
+			WithDetails(map[string]interface{}{"fields": fieldErrs.Fields})
+	}
+
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return NewAPIError(m.status, m.code, err.Error())
+		}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return NewAPIError(fiberErr.Code, codeForStatus(fiberErr.Code), fiberErr.Message)
+	}
+
+	return NewAPIError(fiber.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// codeForStatus derives a code for errors raised by fiber itself
+func codeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest, fiber.StatusUnprocessableEntity:
+		return CodeValidation
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	}
+	if status < fiber.StatusInternalServerError {
+		return CodeRequestFailed
+	}
+	return CodeInternal
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -33,3 +34,17 @@ func AuthRequired(service ports.AuthService) fiber.Handler {
 		return c.Next()
 	}
 }
+
+// RoleRequired lets through only users with one of roles; mount it after
+// AuthRequired, which sets the caller's role
+func RoleRequired(roles ...domain.UserRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("user_role").(domain.UserRole)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+		return NewAPIError(fiber.StatusForbidden, CodeForbidden, "Insufficient permissions")
+	}
+}
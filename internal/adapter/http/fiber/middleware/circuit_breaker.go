@@ -1,11 +1,15 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sony/gobreaker"
 	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/circuitbreaker"
 )
 
 // CircuitBreakerConfig holds configuration for the circuit breaker middleware
@@ -99,3 +103,50 @@ func CircuitBreakerWithConfig(cfg CircuitBreakerConfig) fiber.Handler {
 		return err
 	}
 }
+
+// DownstreamCircuitBreaker creates a circuit breaker middleware scoped to a
+// single downstream dependency (payment, db, ocpp). Mount it on the route
+// group that depends on it so a failing dependency only rejects its own routes.
+// Handler errors and 5xx responses count as failures.
+func DownstreamCircuitBreaker(manager *circuitbreaker.Manager, name string, log *zap.Logger) fiber.Handler {
+	if log == nil {
+		log = zap.NewNop()
+	}
+
+	cb := manager.Get(name, circuitbreaker.DownstreamSettings(name))
+
+	return func(c *fiber.Ctx) error {
+		var handlerErr error
+
+		_, err := cb.Execute(func() (interface{}, error) {
+			handlerErr = c.Next()
+			if handlerErr != nil {
+				var fiberErr *fiber.Error
+				if errors.As(handlerErr, &fiberErr) && fiberErr.Code < fiber.StatusInternalServerError {
+					return nil, nil
+				}
+				return nil, handlerErr
+			}
+
+			if status := c.Response().StatusCode(); status >= fiber.StatusInternalServerError {
+				return nil, fmt.Errorf("%s responded with status %d", name, status)
+			}
+			return nil, nil
+		})
+
+		if circuitbreaker.IsCircuitOpen(err) || circuitbreaker.IsTooManyRequests(err) {
+			log.Warn("Circuit breaker rejecting request",
+				zap.String("breaker", name),
+				zap.String("path", c.Path()),
+				zap.String("method", c.Method()),
+				zap.String("state", cb.State().String()),
+			)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error":      "Service temporarily unavailable",
+				"dependency": name,
+			})
+		}
+
+		return handlerErr
+	}
+}
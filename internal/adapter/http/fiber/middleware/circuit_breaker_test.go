@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/circuitbreaker"
+)
+
+func newBreakerTestApp(manager *circuitbreaker.Manager) *fiber.App {
+	app := fiber.New()
+	log := zap.NewNop()
+
+	payments := app.Group("/payments", DownstreamCircuitBreaker(manager, circuitbreaker.DownstreamPayment, log))
+	payments.Get("/", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "provider down"})
+	})
+
+	devices := app.Group("/devices", DownstreamCircuitBreaker(manager, circuitbreaker.DownstreamDatabase, log))
+	devices.Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"devices": []string{}})
+	})
+
+	return app
+}
+
+func TestDownstreamCircuitBreaker_TripsOnlyFailingDependency(t *testing.T) {
+	// Arrange
+	manager := circuitbreaker.NewManager(zap.NewNop())
+	app := newBreakerTestApp(manager)
+	threshold := int(circuitbreaker.DownstreamSettings(circuitbreaker.DownstreamPayment).FailureThreshold)
+
+	// Act - fail the payment dependency until it trips
+	for i := 0; i < threshold; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/payments/", nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusBadGateway {
+			t.Fatalf("expected status 502 before trip, got %d", resp.StatusCode)
+		}
+	}
+
+	// Assert - payment routes are rejected
+	resp, err := app.Test(httptest.NewRequest("GET", "/payments/", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("expected status 503 once payment breaker is open, got %d", resp.StatusCode)
+	}
+
+	// Assert - unrelated routes still go through
+	resp, err = app.Test(httptest.NewRequest("GET", "/devices/", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected status 200 for devices, got %d", resp.StatusCode)
+	}
+
+	status := manager.Status()
+	if got := status[circuitbreaker.DownstreamPayment].State; got != circuitbreaker.StateOpen.String() {
+		t.Errorf("expected payment breaker open, got %s", got)
+	}
+	if got := status[circuitbreaker.DownstreamDatabase].State; got != circuitbreaker.StateClosed.String() {
+		t.Errorf("expected db breaker closed, got %s", got)
+	}
+}
+
+func TestDownstreamCircuitBreaker_ClientErrorsDoNotTrip(t *testing.T) {
+	// Arrange
+	manager := circuitbreaker.NewManager(zap.NewNop())
+	app := fiber.New()
+	app.Get("/ocpp", DownstreamCircuitBreaker(manager, circuitbreaker.DownstreamOCPP, zap.NewNop()), func(c *fiber.Ctx) error {
+		return fiber.ErrNotFound
+	})
+
+	// Act
+	for i := 0; i < 20; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/ocpp", nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Fatalf("expected status 404, got %d", resp.StatusCode)
+		}
+	}
+
+	// Assert
+	if got := manager.Status()[circuitbreaker.DownstreamOCPP].State; got != circuitbreaker.StateClosed.String() {
+		t.Errorf("expected ocpp breaker closed, got %s", got)
+	}
+}
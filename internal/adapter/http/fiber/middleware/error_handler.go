@@ -5,20 +5,18 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrorHandler turns errors returned by handlers into an APIError response.
+// Handlers return sentinel errors and let ToAPIError pick status and code.
 func ErrorHandler(log *zap.Logger) fiber.ErrorHandler {
 	return func(c *fiber.Ctx, err error) error {
-		code := fiber.StatusInternalServerError
+		apiErr := ToAPIError(err)
 
-		if e, ok := err.(*fiber.Error); ok {
-			code = e.Code
-		}
-
-		if code == fiber.StatusInternalServerError {
+		if apiErr.Status >= fiber.StatusInternalServerError {
 			log.Error("Internal Server Error", zap.Error(err), zap.String("path", c.Path()))
 		}
 
-		return c.Status(code).JSON(fiber.Map{
-			"error": err.Error(),
+		return c.Status(apiErr.Status).JSON(fiber.Map{
+			"error": apiErr,
 		})
 	}
 }
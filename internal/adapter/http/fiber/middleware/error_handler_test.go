@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
+)
+
+type errorResponse struct {
+	Error APIError `json:"error"`
+}
+
+func doErrorRequest(t *testing.T, handlerErr error) (int, APIError) {
+	t.Helper()
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(zap.NewNop())})
+	app.Get("/", func(c *fiber.Ctx) error {
+		return handlerErr
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode error body: %v", err)
+	}
+	return resp.StatusCode, body.Error
+}
+
+func TestErrorHandler_DeviceNotFound(t *testing.T) {
+	// Arrange - a service wrapping the sentinel with context
+	serviceErr := fmt.Errorf("get device CP-404: %w", domain.ErrDeviceNotFound)
+
+	// Act
+	status, apiErr := doErrorRequest(t, serviceErr)
+
+	// Assert
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected status 404, got %d", status)
+	}
+	if apiErr.Code != CodeDeviceNotFound {
+		t.Errorf("expected code %s, got %s", CodeDeviceNotFound, apiErr.Code)
+	}
+}
+
+func TestErrorHandler_Mapping(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"generic not found", fmt.Errorf("tariff: %w", domain.ErrNotFound), fiber.StatusNotFound, CodeNotFound},
+		{"conflict", domain.ErrEmailAlreadyRegistered, fiber.StatusConflict, CodeEmailRegistered},
+		{"unauthorized", domain.ErrInvalidCredentials, fiber.StatusUnauthorized, CodeInvalidCredentials},
+		{"not connected", fmt.Errorf("failed to send command: %w", domain.ErrChargePointNotConnected), fiber.StatusServiceUnavailable, CodeDeviceNotConnected},
+		{"command rejected", fmt.Errorf("reset Rejected: %w", domain.ErrCommandRejected), fiber.StatusUnprocessableEntity, CodeCommandRejected},
+		{"validation", NewAPIError(fiber.StatusBadRequest, CodeValidation, "CPF is required"), fiber.StatusBadRequest, CodeValidation},
+		{"fiber error", fiber.ErrTooManyRequests, fiber.StatusTooManyRequests, CodeRateLimited},
+		{"unknown", errors.New("pq: connection refused"), fiber.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, apiErr := doErrorRequest(t, tt.err)
+
+			if status != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, status)
+			}
+			if apiErr.Code != tt.expectedCode {
+				t.Errorf("expected code %s, got %s", tt.expectedCode, apiErr.Code)
+			}
+		})
+	}
+}
+
+func TestErrorHandler_HidesInternalMessages(t *testing.T) {
+	_, apiErr := doErrorRequest(t, errors.New("pq: password authentication failed for user sigec"))
+
+	if apiErr.Message != "Internal server error" {
+		t.Errorf("expected a generic message, got %q", apiErr.Message)
+	}
+}
+
+func TestErrorHandler_FieldErrors(t *testing.T) {
+	// Arrange - a request body that failed its validate tags
+	var body struct {
+		IdToken  string `json:"id_token" validate:"required"`
+		Duration int    `json:"duration" validate:"min=30"`
+	}
+	body.Duration = 10
+	fieldErr := validation.Struct(&body)
+
+	// Act
+	status, apiErr := doErrorRequest(t, fieldErr)
+
+	// Assert
+	if status != fiber.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", status)
+	}
+	if apiErr.Code != CodeValidation {
+		t.Errorf("expected code %s, got %s", CodeValidation, apiErr.Code)
+	}
+	fields, ok := apiErr.Details["fields"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected details.fields, got %v", apiErr.Details)
+	}
+	if fields["id_token"] != "is required" {
+		t.Errorf("expected id_token is required, got %v", fields["id_token"])
+	}
+	if fields["duration"] != "must be at least 30" {
+		t.Errorf("expected duration must be at least 30, got %v", fields["duration"])
+	}
+}
@@ -1,10 +1,16 @@
 package middleware
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 func RateLimit() fiber.Handler {
@@ -27,3 +33,99 @@ func RateLimit() fiber.Handler {
 		},
 	})
 }
+
+// UserRateLimitConfig holds configuration for the user-aware rate limiter
+type UserRateLimitConfig struct {
+	Logger *zap.Logger
+
+	// Window is the fixed window length shared by all buckets
+	Window time.Duration
+
+	// AnonymousMax is the per-IP limit for requests without an authenticated user
+	AnonymousMax int
+
+	// UserMax is the per-user limit for roles not listed in RoleMax
+	UserMax int
+
+	// RoleMax overrides UserMax for specific roles
+	RoleMax map[domain.UserRole]int
+}
+
+// DefaultUserRateLimitConfig returns sensible defaults
+func DefaultUserRateLimitConfig() UserRateLimitConfig {
+	return UserRateLimitConfig{
+		Logger:       zap.NewNop(),
+		Window:       time.Minute,
+		AnonymousMax: 60,
+		UserMax:      120,
+		RoleMax: map[domain.UserRole]int{
+			domain.UserRoleOperator: 300,
+			domain.UserRoleAdmin:    600,
+		},
+	}
+}
+
+// UserRateLimit creates a rate limiter that keys on the authenticated user
+// (set by AuthRequired) with per-role limits, and falls back to the client IP
+// for anonymous requests. Mount it after AuthRequired on protected groups.
+// Counters live in store, so a shared store (Redis) enforces limits across
+// instances. If the store fails the request is allowed through.
+func UserRateLimit(store ports.RateLimitStore, cfg UserRateLimitConfig) fiber.Handler {
+	log := cfg.Logger
+	if log == nil {
+		log = zap.NewNop()
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+
+	return func(c *fiber.Ctx) error {
+		key, max := rateLimitBucket(c, cfg)
+
+		count, reset, err := store.Increment(c.Context(), key, cfg.Window)
+		if err != nil {
+			log.Warn("Rate limit store unavailable, allowing request",
+				zap.String("key", key),
+				zap.Error(err),
+			)
+			return c.Next()
+		}
+
+		remaining := int64(max) - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		retryAfter := int(reset.Round(time.Second).Seconds())
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(max))
+		c.Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		c.Set("X-RateLimit-Reset", strconv.Itoa(retryAfter))
+
+		if count > int64(max) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error":       "Rate limit exceeded",
+				"retry_after": fmt.Sprintf("%ds", retryAfter),
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// rateLimitBucket returns the counter key and limit for the current request
+func rateLimitBucket(c *fiber.Ctx, cfg UserRateLimitConfig) (string, int) {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return "ratelimit:ip:" + c.IP(), cfg.AnonymousMax
+	}
+
+	max := cfg.UserMax
+	if role, ok := c.Locals("user_role").(domain.UserRole); ok {
+		if roleMax, ok := cfg.RoleMax[role]; ok {
+			max = roleMax
+		}
+	}
+
+	return "ratelimit:user:" + userID, max
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// fakeAuth stands in for AuthRequired, taking the user from test headers
+func fakeAuth(c *fiber.Ctx) error {
+	if userID := c.Get("X-Test-User"); userID != "" {
+		c.Locals("user_id", userID)
+		c.Locals("user_role", domain.UserRole(c.Get("X-Test-Role", string(domain.UserRoleUser))))
+	}
+	return c.Next()
+}
+
+func newRateLimitTestApp() *fiber.App {
+	cfg := DefaultUserRateLimitConfig()
+	cfg.Window = time.Minute
+	cfg.AnonymousMax = 2
+	cfg.UserMax = 3
+	cfg.RoleMax = map[domain.UserRole]int{domain.UserRoleAdmin: 5}
+
+	app := fiber.New()
+	app.Use(fakeAuth, UserRateLimit(cache.NewLocalRateLimitStore(), cfg))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func doRateLimitedRequest(t *testing.T, app *fiber.App, userID, role string) int {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/", nil)
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+	if role != "" {
+		req.Header.Set("X-Test-Role", role)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestUserRateLimit_PerUserBucket(t *testing.T) {
+	// Arrange
+	app := newRateLimitTestApp()
+
+	// Act - exhaust user-1's bucket
+	for i := 0; i < 3; i++ {
+		if status := doRateLimitedRequest(t, app, "user-1", ""); status != fiber.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i+1, status)
+		}
+	}
+
+	// Assert
+	if status := doRateLimitedRequest(t, app, "user-1", ""); status != fiber.StatusTooManyRequests {
+		t.Errorf("expected status 429 for user-1, got %d", status)
+	}
+	if status := doRateLimitedRequest(t, app, "user-2", ""); status != fiber.StatusOK {
+		t.Errorf("expected user-2 to be unaffected, got %d", status)
+	}
+}
+
+func TestUserRateLimit_RoleLimits(t *testing.T) {
+	// Arrange
+	app := newRateLimitTestApp()
+
+	// Act & Assert - admins get the higher role limit
+	for i := 0; i < 5; i++ {
+		if status := doRateLimitedRequest(t, app, "admin-1", string(domain.UserRoleAdmin)); status != fiber.StatusOK {
+			t.Fatalf("request %d: expected status 200 for admin, got %d", i+1, status)
+		}
+	}
+	if status := doRateLimitedRequest(t, app, "admin-1", string(domain.UserRoleAdmin)); status != fiber.StatusTooManyRequests {
+		t.Errorf("expected status 429 after admin limit, got %d", status)
+	}
+}
+
+func TestUserRateLimit_AnonymousFallsBackToIP(t *testing.T) {
+	// Arrange
+	app := newRateLimitTestApp()
+
+	// Act
+	for i := 0; i < 2; i++ {
+		if status := doRateLimitedRequest(t, app, "", ""); status != fiber.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i+1, status)
+		}
+	}
+
+	// Assert - anonymous bucket is exhausted, authenticated users on the same IP are not
+	if status := doRateLimitedRequest(t, app, "", ""); status != fiber.StatusTooManyRequests {
+		t.Errorf("expected status 429 for anonymous client, got %d", status)
+	}
+	if status := doRateLimitedRequest(t, app, "user-1", ""); status != fiber.StatusOK {
+		t.Errorf("expected authenticated user behind same IP to pass, got %d", status)
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
+)
+
+// newReplayTestApp protects POST /:user, which answers with failures
+// errors before succeeding. The path stands in for the authenticated user.
+func newReplayTestApp(failures int) *fiber.App {
+	cfg := DefaultReplayProtectionConfig()
+	cfg.Window = time.Minute
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(zap.NewNop())})
+	app.Post("/:user", func(c *fiber.Ctx) error {
+		c.Locals("user_id", c.Params("user"))
+		return c.Next()
+	}, ReplayProtection(cache.NewLocalNonceStore(), cfg), func(c *fiber.Ctx) error {
+		if failures > 0 {
+			failures--
+			return fiber.ErrServiceUnavailable
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func doReplayProtectedRequest(t *testing.T, app *fiber.App, sentAt time.Time, nonce string) int {
+	t.Helper()
+	return doReplayProtectedRequestAs(t, app, "user-1", sentAt, nonce)
+}
+
+func doReplayProtectedRequestAs(t *testing.T, app *fiber.App, user string, sentAt time.Time, nonce string) int {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/"+user, nil)
+	if !sentAt.IsZero() {
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(sentAt.Unix(), 10))
+	}
+	if nonce != "" {
+		req.Header.Set(HeaderNonce, nonce)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestReplayProtection_FreshRequestAccepted(t *testing.T) {
+	// Arrange
+	app := newReplayTestApp(0)
+
+	// Act
+	status := doReplayProtectedRequest(t, app, time.Now(), "nonce-1")
+
+	// Assert
+	if status != fiber.StatusOK {
+		t.Errorf("expected status %d, got %d", fiber.StatusOK, status)
+	}
+}
+
+func TestReplayProtection_ReplayedNonceRejected(t *testing.T) {
+	// Arrange
+	app := newReplayTestApp(0)
+	sentAt := time.Now()
+	if status := doReplayProtectedRequest(t, app, sentAt, "nonce-1"); status != fiber.StatusOK {
+		t.Fatalf("expected the first request to pass, got %d", status)
+	}
+
+	// Act
+	status := doReplayProtectedRequest(t, app, sentAt, "nonce-1")
+
+	// Assert
+	if status != fiber.StatusConflict {
+		t.Errorf("expected status %d, got %d", fiber.StatusConflict, status)
+	}
+}
+
+func TestReplayProtection_ExpiredTimestampRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		sentAt time.Time
+	}{
+		{"too old", time.Now().Add(-2 * time.Minute)},
+		{"too far ahead", time.Now().Add(2 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app := newReplayTestApp(0)
+
+			// Act
+			status := doReplayProtectedRequest(t, app, tt.sentAt, "nonce-1")
+
+			// Assert
+			if status != fiber.StatusUnauthorized {
+				t.Errorf("expected status %d, got %d", fiber.StatusUnauthorized, status)
+			}
+		})
+	}
+}
+
+func TestReplayProtection_MissingHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		sentAt time.Time
+		nonce  string
+		want   int
+	}{
+		{"without headers", time.Time{}, "", fiber.StatusBadRequest},
+		{"nonce without timestamp", time.Time{}, "nonce-1", fiber.StatusBadRequest},
+		{"timestamp without nonce", time.Now(), "", fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app := newReplayTestApp(0)
+
+			// Act
+			status := doReplayProtectedRequest(t, app, tt.sentAt, tt.nonce)
+
+			// Assert
+			if status != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, status)
+			}
+		})
+	}
+}
+
+func TestReplayProtection_NoncesAreScopedPerUser(t *testing.T) {
+	// Arrange
+	app := newReplayTestApp(0)
+	sentAt := time.Now()
+	if status := doReplayProtectedRequestAs(t, app, "user-1", sentAt, "nonce-1"); status != fiber.StatusOK {
+		t.Fatalf("expected the first request to pass, got %d", status)
+	}
+
+	// Act
+	status := doReplayProtectedRequestAs(t, app, "user-2", sentAt, "nonce-1")
+
+	// Assert
+	if status != fiber.StatusOK {
+		t.Errorf("expected another user's nonce not to count, got %d", status)
+	}
+}
+
+func TestReplayProtection_FailedRequestCanBeRetried(t *testing.T) {
+	// Arrange
+	app := newReplayTestApp(1)
+	sentAt := time.Now()
+	if status := doReplayProtectedRequest(t, app, sentAt, "nonce-1"); status != fiber.StatusServiceUnavailable {
+		t.Fatalf("expected the first request to fail, got %d", status)
+	}
+
+	// Act
+	status := doReplayProtectedRequest(t, app, sentAt, "nonce-1")
+
+	// Assert
+	if status != fiber.StatusOK {
+		t.Errorf("expected the retry to pass, got %d", status)
+	}
+}
@@ -16,6 +16,8 @@ import (
 type Handlers struct {
 	deviceService ports.DeviceService
 	txService     ports.TransactionService
+	faults        ports.FaultService
+	heartbeat     domain.HeartbeatPolicy
 	log           *zap.Logger
 }
 
@@ -84,10 +86,25 @@ func (h *Handlers) handleBootNotification(ctx context.Context, chargePointID str
 		h.log.Warn("Failed to update device status on boot", zap.Error(err))
 	}
 
+	interval := h.heartbeat.IntervalFor(chargePointID, req.ChargePointVendor)
+	if err := h.deviceService.SetHeartbeatInterval(ctx, chargePointID, interval); err != nil {
+		h.log.Warn("Failed to store heartbeat interval", zap.Error(err))
+	}
+
+	info := domain.BootInfo{
+		Vendor:          req.ChargePointVendor,
+		Model:           req.ChargePointModel,
+		SerialNumber:    req.ChargePointSerial,
+		FirmwareVersion: req.FirmwareVersion,
+	}
+	if err := h.deviceService.RecordBootInfo(ctx, chargePointID, info); err != nil {
+		h.log.Warn("Failed to store boot info", zap.Error(err))
+	}
+
 	return bootNotificationResp{
 		Status:      "Accepted",
 		CurrentTime: time.Now().UTC().Format(time.RFC3339),
-		Interval:    300,
+		Interval:    interval,
 	}, nil
 }
 
@@ -104,6 +121,7 @@ type statusNotificationReq struct {
 	ErrorCode       string `json:"errorCode"`
 	Status          string `json:"status"`
 	Timestamp       string `json:"timestamp,omitempty"`
+	Info            string `json:"info,omitempty"`
 	VendorErrorCode string `json:"vendorErrorCode,omitempty"`
 }
 
@@ -129,21 +147,56 @@ func (h *Handlers) handleStatusNotification(ctx context.Context, chargePointID s
 		internalStatus = domain.ChargePointStatusOccupied
 	case "Faulted":
 		internalStatus = domain.ChargePointStatusFaulted
-	case "Unavailable", "Reserved":
+	case "Reserved":
+		internalStatus = domain.ChargePointStatusReserved
+	case "Unavailable":
 		internalStatus = domain.ChargePointStatusUnavailable
 	default:
 		internalStatus = domain.ChargePointStatusAvailable
 	}
 
+	// Connector 0 is the charge point as a whole
 	if req.ConnectorId == 0 {
 		if err := h.deviceService.UpdateStatus(ctx, chargePointID, internalStatus); err != nil {
 			h.log.Warn("Failed to update status", zap.Error(err))
 		}
+	} else if err := h.deviceService.UpdateConnectorStatus(ctx, chargePointID, req.ConnectorId, internalStatus); err != nil {
+		h.log.Warn("Failed to update connector status", zap.Int("connector_id", req.ConnectorId), zap.Error(err))
 	}
+	h.trackFault(ctx, chargePointID, req, internalStatus)
 
 	return map[string]interface{}{}, nil
 }
 
+// trackFault raises a fault alert with the reported error code when a
+// connector becomes Faulted and resolves it once it is Available again
+func (h *Handlers) trackFault(ctx context.Context, chargePointID string, req statusNotificationReq, status domain.ChargePointStatus) {
+	if h.faults == nil {
+		return
+	}
+
+	switch status {
+	case domain.ChargePointStatusFaulted:
+		fault := domain.ConnectorFault{
+			ChargePointID:   chargePointID,
+			ConnectorID:     req.ConnectorId,
+			ErrorCode:       req.ErrorCode,
+			VendorErrorCode: req.VendorErrorCode,
+			Info:            req.Info,
+		}
+		if ts, err := time.Parse(time.RFC3339, req.Timestamp); err == nil {
+			fault.Timestamp = ts
+		}
+		if _, err := h.faults.ReportFault(ctx, fault); err != nil {
+			h.log.Error("Failed to report fault", zap.String("charge_point_id", chargePointID), zap.Error(err))
+		}
+	case domain.ChargePointStatusAvailable:
+		if err := h.faults.ResolveFaults(ctx, chargePointID, req.ConnectorId); err != nil {
+			h.log.Warn("Failed to resolve fault", zap.String("charge_point_id", chargePointID), zap.Error(err))
+		}
+	}
+}
+
 type startTransactionReq struct {
 	ConnectorId   int    `json:"connectorId"`
 	IdTag         string `json:"idTag"`
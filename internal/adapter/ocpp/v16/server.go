@@ -9,6 +9,7 @@ import (
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -45,6 +46,16 @@ func NewServer(deviceService ports.DeviceService, txService ports.TransactionSer
 	return s
 }
 
+// SetHeartbeatPolicy sets how heartbeat intervals are chosen at boot
+func (s *Server) SetHeartbeatPolicy(policy domain.HeartbeatPolicy) {
+	s.handlers.heartbeat = policy
+}
+
+// SetFaultService enables fault alerts for Faulted status notifications
+func (s *Server) SetFaultService(faults ports.FaultService) {
+	s.handlers.faults = faults
+}
+
 // Start starts the OCPP 1.6 WebSocket server on the given port
 func (s *Server) Start(port int) error {
 	mux := http.NewServeMux()
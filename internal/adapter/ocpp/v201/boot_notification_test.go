@@ -0,0 +1,153 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+func bootPayload(t *testing.T, vendor string) []byte {
+	return firmwareBootPayload(t, vendor, "")
+}
+
+func firmwareBootPayload(t *testing.T, vendor, firmwareVersion string) []byte {
+	t.Helper()
+	payload, err := json.Marshal(BootNotificationRequest{
+		Reason:          "PowerUp",
+		ChargingStation: ChargingStation{Model: "M1", VendorName: vendor, FirmwareVersion: firmwareVersion},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal BootNotification: %v", err)
+	}
+	return payload
+}
+
+func TestHandleBootNotification_HeartbeatIntervalByVendor(t *testing.T) {
+	// Arrange
+	stored := make(map[string]int)
+	devices := &mocks.MockDeviceService{
+		SetHeartbeatIntervalFunc: func(ctx context.Context, id string, interval int) error {
+			stored[id] = interval
+			return nil
+		},
+	}
+	srv := NewServer(devices, nil, zap.NewNop())
+	srv.SetHeartbeatPolicy(domain.HeartbeatPolicy{
+		Default:       300,
+		ByVendor:      map[string]int{"cellco": 1800},
+		// Keys as loaded from configuration, which lowercases them
+		ByChargePoint: map[string]int{"cp-pinned": 60},
+	})
+	defer srv.Stop()
+
+	tests := []struct {
+		name     string
+		cpID     string
+		vendor   string
+		expected int
+	}{
+		{"default vendor", "CP-FIBER", "Acme", 300},
+		{"cellular vendor", "CP-CELL", "CellCo", 1800},
+		{"device override", "CP-PINNED", "CellCo", 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			resp, err := srv.handleBootNotification(tt.cpID, bootPayload(t, tt.vendor))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Interval != tt.expected {
+				t.Errorf("expected interval %d, got %d", tt.expected, resp.Interval)
+			}
+			if stored[tt.cpID] != tt.expected {
+				t.Errorf("expected stored interval %d, got %d", tt.expected, stored[tt.cpID])
+			}
+		})
+	}
+}
+
+func TestHandleBootNotification_DefaultPolicy(t *testing.T) {
+	// Arrange
+	srv := NewServer(&mocks.MockDeviceService{}, nil, zap.NewNop())
+	defer srv.Stop()
+
+	// Act
+	resp, err := srv.handleBootNotification("CP001", bootPayload(t, "Acme"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Interval != domain.DefaultHeartbeatInterval {
+		t.Errorf("expected interval %d, got %d", domain.DefaultHeartbeatInterval, resp.Interval)
+	}
+}
+
+func TestHandleBootNotification_StoresFirmwareVersionOnEveryBoot(t *testing.T) {
+	// Arrange
+	stored := make(map[string]domain.BootInfo)
+	devices := &mocks.MockDeviceService{
+		RecordBootInfoFunc: func(ctx context.Context, id string, info domain.BootInfo) error {
+			stored[id] = info
+			return nil
+		},
+	}
+	srv := NewServer(devices, nil, zap.NewNop())
+	defer srv.Stop()
+
+	if _, err := srv.handleBootNotification("CP001", firmwareBootPayload(t, "Acme", "1.0.0")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored["CP001"].FirmwareVersion != "1.0.0" {
+		t.Fatalf("expected firmware version 1.0.0, got %q", stored["CP001"].FirmwareVersion)
+	}
+
+	// Act: the station reboots after a firmware update
+	_, err := srv.handleBootNotification("CP001", firmwareBootPayload(t, "Acme", "1.1.0"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := domain.BootInfo{Vendor: "Acme", Model: "M1", FirmwareVersion: "1.1.0"}
+	if stored["CP001"] != expected {
+		t.Errorf("expected boot info %+v, got %+v", expected, stored["CP001"])
+	}
+}
+
+func TestHandleBootNotification_ReadsMissingFirmwareVersionAfterResponding(t *testing.T) {
+	// Arrange
+	recorded := 0
+	devices := &mocks.MockDeviceService{
+		RecordBootInfoFunc: func(ctx context.Context, id string, info domain.BootInfo) error {
+			recorded++
+			return nil
+		},
+	}
+	srv := NewServer(devices, nil, zap.NewNop())
+	defer srv.Stop()
+
+	// Act
+	resp, err := srv.handleBootNotification("CP001", firmwareBootPayload(t, "Acme", ""))
+
+	// Assert: nothing may be asked of the station before it has its answer
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recorded != 0 {
+		t.Errorf("expected the boot info to wait for the firmware version, got %d writes", recorded)
+	}
+	expected := domain.BootInfo{Vendor: "Acme", Model: "M1"}
+	if resp.unversioned == nil || *resp.unversioned != expected {
+		t.Errorf("expected %+v to be synced after the response, got %+v", expected, resp.unversioned)
+	}
+}
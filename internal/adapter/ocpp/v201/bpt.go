@@ -0,0 +1,227 @@
+package v201
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// BPTScheduleConfig controls the schedules offered to bidirectional
+// (ISO 15118-20 BPT) EVs
+type BPTScheduleConfig struct {
+	// DischargePriceThreshold is the grid price (R$/kWh) above which the EV
+	// discharges. Zero uses the average price of the forecast window.
+	DischargePriceThreshold float64
+	MinSOC                  int // Discharge never takes the battery below this SOC (%)
+	HorizonHours            int // Schedule length when the EV gives no departure time
+}
+
+// DefaultBPTScheduleConfig returns the settings used by NewServer
+func DefaultBPTScheduleConfig() BPTScheduleConfig {
+	return BPTScheduleConfig{
+		MinSOC:       20,
+		HorizonHours: 12,
+	}
+}
+
+// maxBPTHorizonHours caps the schedule length for far-away departures
+const maxBPTHorizonHours = 24
+
+// SetGridPriceService enables price-based schedules for BPT charging needs
+func (s *Server) SetGridPriceService(svc ports.GridPriceService) {
+	s.gridPrices = svc
+}
+
+// isBPT reports whether the EV requested bidirectional power transfer
+func isBPT(needs ChargingNeeds) bool {
+	return needs.RequestedEnergyTransfer == "AC_BPT" || needs.RequestedEnergyTransfer == "DC_BPT"
+}
+
+// bptHorizonHours returns how many hourly periods the schedule covers
+func (c BPTScheduleConfig) bptHorizonHours(needs ChargingNeeds, now time.Time) int {
+	hours := c.HorizonHours
+	if needs.DepartureTime != nil {
+		if departure, err := time.Parse(time.RFC3339, *needs.DepartureTime); err == nil {
+			hours = int(math.Ceil(departure.Sub(now).Hours()))
+		}
+	}
+	if hours < 1 {
+		hours = 1
+	}
+	if hours > maxBPTHorizonHours {
+		hours = maxBPTHorizonHours
+	}
+	return hours
+}
+
+// BuildBPTSchedule turns a price forecast into a charging schedule for a
+// bidirectional EV: it discharges while the price is above the threshold and
+// charges otherwise. Limits are in W (negative = discharge) and follow the
+// simulated state of charge, so discharge stops at minSOC, never exceeds
+// evMaxDischargePower, and charging stops once the battery is full.
+func BuildBPTSchedule(needs ChargingNeeds, prices []domain.GridPricePoint, threshold float64, minSOC int) ChargingSchedule {
+	schedule := ChargingSchedule{
+		Id:               1,
+		ChargingRateUnit: "W",
+	}
+	if len(prices) == 0 {
+		return schedule
+	}
+
+	start := prices[0].Timestamp
+	startSchedule := start.Format(time.RFC3339)
+	schedule.StartSchedule = &startSchedule
+
+	var (
+		maxChargeW    float64
+		maxDischargeW float64
+		capacityWh    float64
+		soc           float64
+		targetSOC     = 100.0
+	)
+	if dc := needs.DCChargingParameters; dc != nil {
+		maxChargeW = float64(dc.EVMaxCurrent * dc.EVMaxVoltage)
+		soc = float64(dc.StateOfCharge)
+		if dc.EVMaxDischargePower != nil {
+			maxDischargeW = float64(*dc.EVMaxDischargePower)
+		}
+		if dc.EVEnergyCapacity != nil {
+			capacityWh = float64(*dc.EVEnergyCapacity) * 1000
+		}
+		if dc.FullSOC != nil {
+			targetSOC = float64(*dc.FullSOC)
+		}
+	} else if ac := needs.ACChargingParameters; ac != nil {
+		maxChargeW = float64(ac.EVMaxCurrent * ac.EVMaxVoltage)
+	}
+
+	for i, point := range prices {
+		// Length of this period, taken from the next forecast point
+		hours := 1.0
+		if i+1 < len(prices) {
+			hours = prices[i+1].Timestamp.Sub(point.Timestamp).Hours()
+		}
+
+		var limit float64
+		switch {
+		case point.Price > threshold:
+			limit = -maxDischargeW
+			if capacityWh <= 0 {
+				// Without the battery size the SOC floor cannot be enforced
+				limit = 0
+			} else if available := (soc - float64(minSOC)) / 100 * capacityWh / hours; available < maxDischargeW {
+				limit = -math.Max(available, 0)
+			}
+		default:
+			limit = maxChargeW
+			if capacityWh > 0 {
+				if room := (targetSOC - soc) / 100 * capacityWh / hours; room < maxChargeW {
+					limit = math.Max(room, 0)
+				}
+			}
+		}
+		limit = math.Round(limit)
+		if limit == 0 {
+			limit = 0 // no negative zero on the wire
+		}
+
+		if capacityWh > 0 {
+			soc += limit * hours / capacityWh * 100
+		}
+
+		period := ChargingSchedulePeriod{
+			StartPeriod: int(point.Timestamp.Sub(start).Seconds()),
+			Limit:       limit,
+		}
+		// Consecutive periods with the same limit are merged
+		if n := len(schedule.ChargingSchedulePeriod); n > 0 && schedule.ChargingSchedulePeriod[n-1].Limit == limit {
+			continue
+		}
+		schedule.ChargingSchedulePeriod = append(schedule.ChargingSchedulePeriod, period)
+	}
+
+	last := prices[len(prices)-1].Timestamp
+	duration := int(last.Sub(start).Seconds()) + 3600
+	schedule.Duration = &duration
+
+	return schedule
+}
+
+// averagePrice returns the mean price of the forecast
+func averagePrice(prices []domain.GridPricePoint) float64 {
+	if len(prices) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, p := range prices {
+		sum += p.Price
+	}
+	return sum / float64(len(prices))
+}
+
+// planBPTSchedule builds the schedule for a BPT charging needs notification.
+// It returns nil when no price forecast is available.
+func (s *Server) planBPTSchedule(ctx context.Context, req *NotifyEVChargingNeedsRequest) (*ChargingSchedule, error) {
+	if s.gridPrices == nil {
+		return nil, nil
+	}
+
+	hours := s.bptConfig.bptHorizonHours(req.ChargingNeeds, time.Now())
+	prices, err := s.gridPrices.GetPriceForecast(ctx, hours)
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) > hours {
+		prices = prices[:hours]
+	}
+	if len(prices) == 0 {
+		return nil, nil
+	}
+
+	threshold := s.bptConfig.DischargePriceThreshold
+	if threshold <= 0 {
+		threshold = averagePrice(prices)
+	}
+
+	schedule := BuildBPTSchedule(req.ChargingNeeds, prices, threshold, s.bptConfig.MinSOC)
+	if req.MaxScheduleTuples != nil && *req.MaxScheduleTuples > 0 && len(schedule.ChargingSchedulePeriod) > *req.MaxScheduleTuples {
+		schedule.ChargingSchedulePeriod = schedule.ChargingSchedulePeriod[:*req.MaxScheduleTuples]
+	}
+	return &schedule, nil
+}
+
+// bptScheduleProfileID is the TxProfile pushBPTSchedule keeps on an EVSE;
+// a new schedule replaces the previous one
+func bptScheduleProfileID(evseID int) int {
+	return 4000000 + evseID
+}
+
+// pushBPTSchedule installs the schedule on the EVSE as a TxProfile
+func (s *Server) pushBPTSchedule(ctx context.Context, cpID string, evseID int, schedule ChargingSchedule) {
+	validFrom := time.Now().Format(time.RFC3339)
+	profile := ChargingProfile{
+		Id:                     bptScheduleProfileID(evseID),
+		StackLevel:             0,
+		ChargingProfilePurpose: "TxProfile",
+		ChargingProfileKind:    "Absolute",
+		ValidFrom:              &validFrom,
+		ChargingSchedule:       []ChargingSchedule{schedule},
+	}
+
+	resp, err := s.SetChargingProfile(ctx, cpID, evseID, profile)
+	if err != nil {
+		s.log.Error("Failed to push BPT schedule", zap.String("cpID", cpID), zap.Int("evseId", evseID), zap.Error(err))
+		return
+	}
+	s.log.Info("BPT schedule pushed",
+		zap.String("cpID", cpID),
+		zap.Int("evseId", evseID),
+		zap.Int("periods", len(schedule.ChargingSchedulePeriod)),
+		zap.String("status", resp.Status),
+	)
+}
@@ -0,0 +1,209 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// fixedGridPrices serves a fixed forecast
+type fixedGridPrices struct {
+	prices []domain.GridPricePoint
+}
+
+func (f *fixedGridPrices) GetCurrentPrice(ctx context.Context) (float64, error) {
+	return f.prices[0].Price, nil
+}
+
+func (f *fixedGridPrices) GetPriceForecast(ctx context.Context, hours int) ([]domain.GridPricePoint, error) {
+	return f.prices, nil
+}
+
+func (f *fixedGridPrices) IsPeakHour(ctx context.Context) (bool, error) {
+	return false, nil
+}
+
+func (f *fixedGridPrices) CalculateV2GCompensation(ctx context.Context, energyKWh float64, startTime, endTime time.Time) (float64, error) {
+	return 0, nil
+}
+
+func hourlyPrices(prices ...float64) []domain.GridPricePoint {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	points := make([]domain.GridPricePoint, len(prices))
+	for i, price := range prices {
+		points[i] = domain.GridPricePoint{Timestamp: start.Add(time.Duration(i) * time.Hour), Price: price}
+	}
+	return points
+}
+
+func bptNeeds(soc, capacityKWh, maxDischargeW int) ChargingNeeds {
+	return ChargingNeeds{
+		RequestedEnergyTransfer: "DC_BPT",
+		DCChargingParameters: &DCChargingParameters{
+			EVMaxCurrent:        100,
+			EVMaxVoltage:        400, // 40 kW
+			StateOfCharge:       soc,
+			EVEnergyCapacity:    &capacityKWh,
+			EVMaxDischargePower: &maxDischargeW,
+		},
+	}
+}
+
+// limitAt returns the limit in force at the given hour of the schedule
+func limitAt(schedule ChargingSchedule, hour int) float64 {
+	var limit float64
+	for _, p := range schedule.ChargingSchedulePeriod {
+		if p.StartPeriod <= hour*3600 {
+			limit = p.Limit
+		}
+	}
+	return limit
+}
+
+func TestBuildBPTSchedule_DischargesOnlyAbovePriceThreshold(t *testing.T) {
+	// Arrange
+	prices := hourlyPrices(0.40, 0.45, 1.20, 1.50, 0.90, 0.35, 1.10, 0.50)
+	threshold := 0.95
+
+	// Act
+	schedule := BuildBPTSchedule(bptNeeds(80, 100, 10000), prices, threshold, 20)
+
+	// Assert
+	if len(schedule.ChargingSchedulePeriod) == 0 {
+		t.Fatal("expected schedule periods")
+	}
+	discharging := 0
+	for hour, point := range prices {
+		limit := limitAt(schedule, hour)
+		if limit < 0 {
+			discharging++
+			if point.Price <= threshold {
+				t.Errorf("hour %d: expected no discharge at price %.2f, got limit %.0f", hour, point.Price, limit)
+			}
+		}
+		if point.Price > threshold && limit >= 0 {
+			t.Errorf("hour %d: expected discharge at price %.2f, got limit %.0f", hour, point.Price, limit)
+		}
+	}
+	if discharging != 3 {
+		t.Errorf("expected 3 discharge hours, got %d", discharging)
+	}
+	if schedule.ChargingRateUnit != "W" || schedule.Duration == nil || *schedule.Duration != 8*3600 {
+		t.Errorf("expected an 8 hour schedule in W, got %+v", schedule)
+	}
+}
+
+func TestBuildBPTSchedule_RespectsDischargePowerAndSOC(t *testing.T) {
+	// Arrange: 60 kWh battery at 25% may give 3 kWh before reaching 20%
+	prices := hourlyPrices(1.0, 1.0, 1.0)
+
+	// Act
+	schedule := BuildBPTSchedule(bptNeeds(25, 60, 10000), prices, 0.5, 20)
+
+	// Assert
+	if got := limitAt(schedule, 0); got != -3000 {
+		t.Errorf("expected first hour limited by SOC to -3000 W, got %.0f", got)
+	}
+	if got := limitAt(schedule, 1); got != 0 {
+		t.Errorf("expected no discharge at the SOC floor, got %.0f", got)
+	}
+
+	// A full battery is limited by evMaxDischargePower
+	schedule = BuildBPTSchedule(bptNeeds(90, 60, 7000), prices, 0.5, 20)
+	for hour := range prices {
+		if got := limitAt(schedule, hour); got != -7000 {
+			t.Errorf("hour %d: expected -7000 W, got %.0f", hour, got)
+		}
+	}
+}
+
+func TestBuildBPTSchedule_StopsChargingWhenFull(t *testing.T) {
+	// Arrange: 10 kWh of room in a 50 kWh battery at 80%
+	prices := hourlyPrices(0.2, 0.2)
+
+	// Act
+	schedule := BuildBPTSchedule(bptNeeds(80, 50, 10000), prices, 0.5, 20)
+
+	// Assert
+	if got := limitAt(schedule, 0); got != 10000 {
+		t.Errorf("expected first hour limited to 10000 W, got %.0f", got)
+	}
+	if got := limitAt(schedule, 1); got != 0 {
+		t.Errorf("expected no charging once full, got %.0f", got)
+	}
+}
+
+func TestNotifyEVChargingNeeds_PushesBPTSchedule(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetGridPriceService(&fixedGridPrices{prices: hourlyPrices(0.4, 1.5, 0.4)})
+
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/CP-BPT", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	needs := NotifyEVChargingNeedsRequest{EvseId: 1, ChargingNeeds: bptNeeds(60, 80, 11000)}
+	call, _ := json.Marshal([]interface{}{Call, "msg-1", "NotifyEVChargingNeeds", needs})
+
+	// Act
+	if err := conn.WriteMessage(websocket.TextMessage, call); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	// Assert: the CallResult and the SetChargingProfile call, in any order
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var accepted bool
+	var profile *SetChargingProfileRequest
+	for !accepted || profile == nil {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("expected response and schedule, got %v", err)
+		}
+		var raw []json.RawMessage
+		json.Unmarshal(data, &raw)
+
+		var msgType MessageType
+		json.Unmarshal(raw[0], &msgType)
+		switch msgType {
+		case CallResult:
+			var resp NotifyEVChargingNeedsResponse
+			json.Unmarshal(raw[2], &resp)
+			accepted = resp.Status == "Accepted"
+		case Call:
+			var action string
+			json.Unmarshal(raw[2], &action)
+			if action != "SetChargingProfile" {
+				t.Fatalf("expected SetChargingProfile, got %s", action)
+			}
+			profile = &SetChargingProfileRequest{}
+			json.Unmarshal(raw[3], profile)
+		}
+	}
+
+	if profile.EvseId != 1 || profile.ChargingProfile.ChargingProfilePurpose != "TxProfile" {
+		t.Errorf("expected TxProfile for EVSE 1, got %+v", profile)
+	}
+	schedule := profile.ChargingProfile.ChargingSchedule[0]
+	if got := limitAt(schedule, 1); got != -11000 {
+		t.Errorf("expected discharge at the price peak, got %.0f", got)
+	}
+	if got := limitAt(schedule, 0); got <= 0 {
+		t.Errorf("expected charging at low price, got %.0f", got)
+	}
+}
@@ -0,0 +1,230 @@
+package v201
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// DefaultBulkWorkers bounds how many commands a bulk operation sends at once
+const DefaultBulkWorkers = 10
+
+// BulkRemoteStop sends RequestStopTransaction to every target using a bounded
+// worker pool. A target without a transaction stops every transaction running
+// on the charge point's EVSEs, each with its own result. Disconnected charge
+// points and charge points without a known transaction are skipped and
+// reported with their own status.
+func (s *Server) BulkRemoteStop(ctx context.Context, targets []ports.RemoteStopTarget) []ports.BulkCommandResult {
+	results := runBulk(ctx, s.remoteStopJobs(targets), DefaultBulkWorkers, func(ctx context.Context, result ports.BulkCommandResult) ports.BulkCommandResult {
+		if result.Status != "" {
+			return result
+		}
+
+		resp, err := s.RemoteStopTransaction(ctx, result.ChargePointID, result.TransactionID)
+		if err != nil {
+			result.Status = ports.BulkStatusFailed
+			result.Error = err.Error()
+			return result
+		}
+
+		result.Status = resp.Status
+		return result
+	})
+
+	s.log.Info("Bulk remote stop completed",
+		zap.Int("targets", len(targets)),
+		zap.Int("accepted", countBulkStatus(results, ports.BulkStatusAccepted)),
+	)
+
+	return results
+}
+
+// remoteStopJobs resolves targets into one result per transaction to stop.
+// Targets that cannot be stopped come back with their status already set.
+func (s *Server) remoteStopJobs(targets []ports.RemoteStopTarget) []ports.BulkCommandResult {
+	jobs := make([]ports.BulkCommandResult, 0, len(targets))
+	for _, target := range targets {
+		result := ports.BulkCommandResult{
+			ChargePointID: target.ChargePointID,
+			TransactionID: target.TransactionID,
+		}
+
+		if !s.IsConnected(target.ChargePointID) {
+			result.Status = ports.BulkStatusNotConnected
+			jobs = append(jobs, result)
+			continue
+		}
+		if result.TransactionID != "" {
+			jobs = append(jobs, result)
+			continue
+		}
+
+		txIDs := s.ActiveTransactionIDs(target.ChargePointID)
+		if len(txIDs) == 0 {
+			result.Status = ports.BulkStatusNoActiveTransaction
+			jobs = append(jobs, result)
+			continue
+		}
+		for _, txID := range txIDs {
+			result.TransactionID = txID
+			jobs = append(jobs, result)
+		}
+	}
+	return jobs
+}
+
+// ActiveTransactionIDs returns the OCPP transactionIds last reported as
+// started on the charge point's EVSEs, in EVSE order
+func (s *Server) ActiveTransactionIDs(chargePointID string) []string {
+	s.txMu.RLock()
+	defer s.txMu.RUnlock()
+
+	var evseIDs []int
+	for key := range s.evseTxIDs {
+		if key.chargePointID == chargePointID {
+			evseIDs = append(evseIDs, key.evseID)
+		}
+	}
+	sort.Ints(evseIDs)
+
+	txIDs := make([]string, 0, len(evseIDs))
+	for _, evseID := range evseIDs {
+		txIDs = append(txIDs, s.evseTxIDs[evseKey{chargePointID, evseID}])
+	}
+	return txIDs
+}
+
+// meterTransactionID returns the OCPP transactionId that meter values reported
+// for an EVSE belong to: the one running on it, or the station's only
+// transaction when the EVSE has none of its own
+func (s *Server) meterTransactionID(chargePointID string, evseID int) (string, bool) {
+	if txID := s.evseTransactionID(chargePointID, evseID); txID != nil {
+		return *txID, true
+	}
+	if txIDs := s.ActiveTransactionIDs(chargePointID); len(txIDs) == 1 {
+		return txIDs[0], true
+	}
+	return "", false
+}
+
+func (s *Server) untrackTransaction(chargePointID, transactionID string) {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+	for key, current := range s.evseTxIDs {
+		if key.chargePointID == chargePointID && (transactionID == "" || current == transactionID) {
+			delete(s.evseTxIDs, key)
+		}
+	}
+}
+
+// evseKey identifies an EVSE of a charge point
+type evseKey struct {
+	chargePointID string
+	evseID        int
+}
+
+// trackEVSETransaction notes the transaction running on an EVSE, which its
+// TxProfiles must name
+func (s *Server) trackEVSETransaction(chargePointID string, evseID int, transactionID string) {
+	if transactionID == "" {
+		return
+	}
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+	// A transaction runs on one EVSE; drop where it was last seen
+	for key, current := range s.evseTxIDs {
+		if key.chargePointID == chargePointID && current == transactionID {
+			delete(s.evseTxIDs, key)
+		}
+	}
+	s.evseTxIDs[evseKey{chargePointID, evseID}] = transactionID
+}
+
+// evseTransactionID returns the OCPP transactionId running on an EVSE, or
+// nil when none is known
+func (s *Server) evseTransactionID(chargePointID string, evseID int) *string {
+	s.txMu.RLock()
+	defer s.txMu.RUnlock()
+	txID, ok := s.evseTxIDs[evseKey{chargePointID, evseID}]
+	if !ok {
+		return nil
+	}
+	return &txID
+}
+
+// runBulk runs fn on each job with at most workers goroutines and returns the
+// results in job order. Once ctx is done no further jobs are dispatched; those
+// still waiting fail with the context's error.
+func runBulk(ctx context.Context, jobs []ports.BulkCommandResult, workers int, fn func(ctx context.Context, job ports.BulkCommandResult) ports.BulkCommandResult) []ports.BulkCommandResult {
+	results := make([]ports.BulkCommandResult, len(jobs))
+	copy(results, jobs)
+	if len(jobs) == 0 {
+		return results
+	}
+	if workers <= 0 || workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if err := ctx.Err(); err != nil {
+					results[i] = abandonedJob(jobs[i], err)
+					continue
+				}
+				results[i] = fn(ctx, jobs[i])
+			}
+		}()
+	}
+
+	dispatched := 0
+dispatch:
+	for dispatched < len(jobs) {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case queue <- dispatched:
+			dispatched++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	for i := dispatched; i < len(jobs); i++ {
+		results[i] = abandonedJob(jobs[i], ctx.Err())
+	}
+
+	return results
+}
+
+// abandonedJob is the result of a job given up on with err, unless it already
+// had one
+func abandonedJob(job ports.BulkCommandResult, err error) ports.BulkCommandResult {
+	if job.Status == "" {
+		job.Status = ports.BulkStatusFailed
+		job.Error = err.Error()
+	}
+	return job
+}
+
+func countBulkStatus(results []ports.BulkCommandResult, status string) int {
+	count := 0
+	for _, r := range results {
+		if r.Status == status {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,238 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// connectFakeChargePoint dials the server as chargePointID and answers every
+// RequestStopTransaction with either a CallResult or a CallError
+func connectFakeChargePoint(t *testing.T, srv *Server, baseURL, chargePointID string, reject bool) {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial(baseURL+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		t.Fatalf("failed to connect %s: %v", chargePointID, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var raw []json.RawMessage
+			if err := json.Unmarshal(data, &raw); err != nil || len(raw) < 3 {
+				continue
+			}
+			var msgID string
+			json.Unmarshal(raw[1], &msgID)
+
+			var reply []interface{}
+			if reject {
+				reply = []interface{}{CallError, msgID, "InternalError", "stop failed", map[string]interface{}{}}
+			} else {
+				reply = []interface{}{CallResult, msgID, RequestStopTransactionResponse{Status: "Accepted"}}
+			}
+			out, _ := json.Marshal(reply)
+			if err := conn.WriteMessage(websocket.TextMessage, out); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Wait until the server has registered the connection
+	deadline := time.Now().Add(2 * time.Second)
+	for !srv.IsConnected(chargePointID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("charge point %s was not registered", chargePointID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_BulkRemoteStop_PartialFailure(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+	baseURL := "ws" + strings.TrimPrefix(httpSrv.URL, "http")
+
+	connectFakeChargePoint(t, srv, baseURL, "CP-OK", false)
+	connectFakeChargePoint(t, srv, baseURL, "CP-TRACKED", false)
+	connectFakeChargePoint(t, srv, baseURL, "CP-REJECT", true)
+	connectFakeChargePoint(t, srv, baseURL, "CP-IDLE", false)
+	srv.trackEVSETransaction("CP-TRACKED", 1, "tx-tracked")
+
+	targets := []ports.RemoteStopTarget{
+		{ChargePointID: "CP-OK", TransactionID: "tx-ok"},
+		{ChargePointID: "CP-TRACKED"},
+		{ChargePointID: "CP-REJECT", TransactionID: "tx-reject"},
+		{ChargePointID: "CP-OFFLINE", TransactionID: "tx-offline"},
+		{ChargePointID: "CP-IDLE"},
+	}
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := srv.BulkRemoteStop(ctx, targets)
+
+	// Assert
+	if len(results) != len(targets) {
+		t.Fatalf("expected %d results, got %d", len(targets), len(results))
+	}
+
+	expected := []struct {
+		id     string
+		txID   string
+		status string
+	}{
+		{"CP-OK", "tx-ok", ports.BulkStatusAccepted},
+		{"CP-TRACKED", "tx-tracked", ports.BulkStatusAccepted},
+		{"CP-REJECT", "tx-reject", ports.BulkStatusFailed},
+		{"CP-OFFLINE", "tx-offline", ports.BulkStatusNotConnected},
+		{"CP-IDLE", "", ports.BulkStatusNoActiveTransaction},
+	}
+
+	for i, want := range expected {
+		got := results[i]
+		if got.ChargePointID != want.id {
+			t.Errorf("result %d: expected device %s, got %s", i, want.id, got.ChargePointID)
+		}
+		if got.TransactionID != want.txID {
+			t.Errorf("result %d: expected transaction %q, got %q", i, want.txID, got.TransactionID)
+		}
+		if got.Status != want.status {
+			t.Errorf("result %d: expected status %s, got %s (error: %s)", i, want.status, got.Status, got.Error)
+		}
+	}
+
+	if results[2].Error == "" {
+		t.Error("expected error message for rejected stop")
+	}
+}
+
+func TestServer_BulkRemoteStop_StopsEveryEVSE(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+	baseURL := "ws" + strings.TrimPrefix(httpSrv.URL, "http")
+
+	connectFakeChargePoint(t, srv, baseURL, "CP-DUAL", false)
+	srv.trackEVSETransaction("CP-DUAL", 2, "tx-evse-2")
+	srv.trackEVSETransaction("CP-DUAL", 1, "tx-evse-1")
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := srv.BulkRemoteStop(ctx, []ports.RemoteStopTarget{{ChargePointID: "CP-DUAL"}})
+
+	// Assert
+	if len(results) != 2 {
+		t.Fatalf("expected one result per EVSE, got %+v", results)
+	}
+	for i, want := range []string{"tx-evse-1", "tx-evse-2"} {
+		if results[i].TransactionID != want || results[i].Status != ports.BulkStatusAccepted {
+			t.Errorf("result %d: expected %s accepted, got %+v", i, want, results[i])
+		}
+	}
+}
+
+func TestRunBulk_BoundsConcurrencyAndKeepsOrder(t *testing.T) {
+	// Arrange
+	const n, workers = 50, 4
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	jobs := make([]ports.BulkCommandResult, n)
+	for i := range jobs {
+		jobs[i] = ports.BulkCommandResult{ChargePointID: string(rune('A' + i%26)), TransactionID: strconv.Itoa(i)}
+	}
+
+	// Act
+	results := runBulk(context.Background(), jobs, workers, func(ctx context.Context, job ports.BulkCommandResult) ports.BulkCommandResult {
+		current := atomic.AddInt32(&inFlight, 1)
+		mu.Lock()
+		if current > maxInFlight {
+			maxInFlight = current
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+
+		job.Status = ports.BulkStatusAccepted
+		if i, _ := strconv.Atoi(job.TransactionID); i%3 == 0 {
+			job.Status = ports.BulkStatusFailed
+		}
+		return job
+	})
+
+	// Assert
+	if maxInFlight > workers {
+		t.Errorf("expected at most %d concurrent calls, got %d", workers, maxInFlight)
+	}
+	if len(results) != n {
+		t.Fatalf("expected %d results, got %d", n, len(results))
+	}
+	for i, r := range results {
+		if r.ChargePointID != string(rune('A'+i%26)) {
+			t.Errorf("result %d out of order: got %s", i, r.ChargePointID)
+		}
+	}
+	if failed := countBulkStatus(results, ports.BulkStatusFailed); failed != 17 {
+		t.Errorf("expected 17 failed results, got %d", failed)
+	}
+}
+
+func TestRunBulk_StopsDispatchingWhenContextIsDone(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs := make([]ports.BulkCommandResult, 10)
+	for i := range jobs {
+		jobs[i] = ports.BulkCommandResult{ChargePointID: "CP-" + strconv.Itoa(i)}
+	}
+	var calls int32
+
+	// Act: the first command outlives the request
+	results := runBulk(ctx, jobs, 1, func(ctx context.Context, job ports.BulkCommandResult) ports.BulkCommandResult {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		job.Status = ports.BulkStatusAccepted
+		return job
+	})
+
+	// Assert
+	if calls != 1 {
+		t.Errorf("expected dispatching to stop after the first command, got %d calls", calls)
+	}
+	if results[0].Status != ports.BulkStatusAccepted {
+		t.Errorf("expected the dispatched command's result, got %+v", results[0])
+	}
+	for i, r := range results[1:] {
+		if r.ChargePointID != jobs[i+1].ChargePointID || r.Status != ports.BulkStatusFailed || r.Error != context.Canceled.Error() {
+			t.Errorf("result %d: expected %s to fail with the context error, got %+v", i+1, jobs[i+1].ChargePointID, r)
+		}
+	}
+}
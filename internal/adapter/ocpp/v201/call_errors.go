@@ -0,0 +1,30 @@
+package v201
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// callErrorFor maps the error of an inbound action handler to the CallError
+// code and description sent back to the charge point. Internal failures are
+// only described in the log.
+func callErrorFor(err error) (code, description string) {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return "FormatViolation", "Payload is not valid JSON"
+	case errors.As(err, &typeErr):
+		return "TypeConstraintViolation", fmt.Sprintf("Field %s must be %s", typeErr.Field, typeErr.Type)
+	case errors.Is(err, domain.ErrValidation):
+		return "PropertyConstraintViolation", err.Error()
+	case errors.Is(err, domain.ErrUnauthorized):
+		return "SecurityError", "Not authorized"
+	default:
+		return "InternalError", "An internal error occurred"
+	}
+}
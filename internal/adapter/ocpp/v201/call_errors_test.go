@@ -0,0 +1,141 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// callError decodes a CallError reply, failing the test for anything else
+func callError(t *testing.T, reply []json.RawMessage) (msgID, code string) {
+	t.Helper()
+	var msgType MessageType
+	json.Unmarshal(reply[0], &msgType)
+	if msgType != CallError || len(reply) != 5 {
+		t.Fatalf("expected a CallError, got %s", reply)
+	}
+	json.Unmarshal(reply[1], &msgID)
+	json.Unmarshal(reply[2], &code)
+	var details map[string]interface{}
+	if err := json.Unmarshal(reply[4], &details); err != nil || details == nil {
+		t.Errorf("expected errorDetails to be an object, got %s", reply[4])
+	}
+	return msgID, code
+}
+
+func startedEvent(msgID string) string {
+	data, _ := json.Marshal([]interface{}{Call, msgID, "TransactionEvent", TransactionEventRequest{
+		EventType:       "Started",
+		Timestamp:       "2026-10-16T12:00:00Z",
+		TriggerReason:   "Authorized",
+		SeqNo:           0,
+		TransactionInfo: TransactionInfo{TransactionId: "ocpp-tx-1"},
+		IdToken:         &IdToken{IdToken: "user-1", Type: "ISO14443"},
+		Evse:            &Evse{Id: 1, ConnectorId: 1},
+	}})
+	return string(data)
+}
+
+func TestTransactionEvent_RepositoryErrorSendsCallError(t *testing.T) {
+	// Arrange
+	var failing atomic.Bool
+	failing.Store(true)
+	var recorded atomic.Int32
+	tx := &domain.Transaction{ID: "tx-domain", ChargePointID: "CP-001", UserID: "user-1", Status: domain.TransactionStatusStarted}
+	txService := &mocks.MockTransactionService{
+		StartTransactionFunc: func(ctx context.Context, deviceID string, connectorID int, userID string, idTag string) (*domain.Transaction, error) {
+			return tx, nil // a retried start gets the session it already started
+		},
+		RecordTransactionEventFunc: func(ctx context.Context, transactionID string, seqNo int, meterWh int) error {
+			if failing.Load() {
+				return errors.New("failed to save transaction: connection refused")
+			}
+			recorded.Add(1)
+			return nil
+		},
+	}
+	srv := NewServer(nil, txService, zap.NewNop())
+	defer srv.Stop()
+	conn := dialSchemaStation(t, srv, "CP-001")
+
+	// Act
+	reply := roundTrip(t, conn, startedEvent("te-1"))
+
+	// Assert
+	msgID, code := callError(t, reply)
+	if msgID != "te-1" {
+		t.Errorf("expected the error to answer te-1, got %s", msgID)
+	}
+	if code != "InternalError" {
+		t.Errorf("expected InternalError, got %s", code)
+	}
+
+	// The station retries once the database is back
+	failing.Store(false)
+	reply = roundTrip(t, conn, startedEvent("te-2"))
+	var msgType MessageType
+	json.Unmarshal(reply[0], &msgType)
+	if msgType != CallResult {
+		t.Fatalf("expected the retry to get a CallResult, got %s", reply)
+	}
+	if recorded.Load() != 1 {
+		t.Errorf("expected the retried event to be recorded once, got %d", recorded.Load())
+	}
+}
+
+func TestHandleAction_PanicSendsInternalError(t *testing.T) {
+	// Arrange: no device service to store the status in
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	conn := dialSchemaStation(t, srv, "CP-001")
+	call := `[2, "sn-1", "StatusNotification", {"timestamp":"2026-10-16T12:00:00Z","connectorStatus":"Available","evseId":1,"connectorId":1}]`
+
+	// Act
+	reply := roundTrip(t, conn, call)
+
+	// Assert
+	msgID, code := callError(t, reply)
+	if msgID != "sn-1" || code != "InternalError" {
+		t.Errorf("expected InternalError for sn-1, got %s for %s", code, msgID)
+	}
+}
+
+func TestCallErrorFor(t *testing.T) {
+	var value struct{ SeqNo int }
+	typeErr := json.Unmarshal([]byte(`{"SeqNo":"one"}`), &value)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"malformed JSON", json.Unmarshal([]byte(`{`), &value), "FormatViolation"},
+		{"wrong field type", typeErr, "TypeConstraintViolation"},
+		{"validation", fmt.Errorf("%w: evseId must be positive", domain.ErrValidation), "PropertyConstraintViolation"},
+		{"unauthorized", fmt.Errorf("station: %w", domain.ErrUnauthorized), "SecurityError"},
+		{"repository failure", errors.New("connection refused"), "InternalError"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, desc := callErrorFor(tt.err)
+
+			// Assert
+			if code != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, code)
+			}
+			if desc == "" {
+				t.Error("expected a description")
+			}
+		})
+	}
+}
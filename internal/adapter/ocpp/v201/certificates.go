@@ -0,0 +1,83 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetStationCertificateService enables signing station CSRs (SignCertificate).
+// Without it every CSR is rejected.
+func (s *Server) SetStationCertificateService(certificates ports.StationCertificateService) {
+	s.certificates = certificates
+}
+
+// handleSignCertificate signs the CSR right away so an invalid request can be
+// rejected in the response. The certificate itself follows in CertificateSigned.
+func (s *Server) handleSignCertificate(cpID string, payload []byte) (*SignCertificateResponse, error) {
+	var req SignCertificateRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	if s.certificates == nil {
+		return &SignCertificateResponse{
+			Status:     "Rejected",
+			StatusInfo: &StatusInfo{ReasonCode: "NotSupported"},
+		}, nil
+	}
+
+	certType := domain.StationCertificateTypeChargingStation
+	if req.CertificateType != nil && *req.CertificateType != "" {
+		certType = domain.StationCertificateType(*req.CertificateType)
+	}
+
+	issued, err := s.certificates.SignCertificate(context.Background(), cpID, req.Csr, certType)
+	if err != nil {
+		s.log.Warn("SignCertificate rejected",
+			zap.String("cpID", cpID),
+			zap.String("certificateType", string(certType)),
+			zap.Error(err),
+		)
+		return &SignCertificateResponse{
+			Status:     "Rejected",
+			StatusInfo: &StatusInfo{ReasonCode: "InvalidCSR", AdditionalInfo: err.Error()},
+		}, nil
+	}
+
+	return &SignCertificateResponse{Status: "Accepted", issued: issued}, nil
+}
+
+// deliverCertificate sends an issued certificate to the charge point
+func (s *Server) deliverCertificate(cpID string, cert *domain.StationCertificate) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCommandTimeout)
+	defer cancel()
+
+	resp, err := s.CertificateSigned(ctx, cpID, cert.CertificateChain, string(cert.CertificateType))
+	if err != nil {
+		s.log.Error("Failed to deliver signed certificate",
+			zap.String("cpID", cpID),
+			zap.String("serial", cert.SerialNumber),
+			zap.Error(err),
+		)
+		return
+	}
+	if resp.Status != "Accepted" {
+		s.log.Warn("Charge point rejected signed certificate",
+			zap.String("cpID", cpID),
+			zap.String("serial", cert.SerialNumber),
+			zap.String("status", resp.Status),
+		)
+		return
+	}
+
+	s.log.Info("Signed certificate installed",
+		zap.String("cpID", cpID),
+		zap.String("serial", cert.SerialNumber),
+		zap.Time("notAfter", cert.NotAfter),
+	)
+}
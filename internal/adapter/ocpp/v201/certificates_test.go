@@ -0,0 +1,129 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// fakeCertificateService signs every CSR with a fixed chain, or fails with err
+type fakeCertificateService struct {
+	err error
+}
+
+func (f *fakeCertificateService) SignCertificate(ctx context.Context, chargePointID string, csrPEM string, certType domain.StationCertificateType) (*domain.StationCertificate, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.StationCertificate{
+		ChargePointID:    chargePointID,
+		CertificateType:  certType,
+		SerialNumber:     "abc",
+		CertificateChain: "CHAIN:" + csrPEM,
+		NotAfter:         time.Now().Add(time.Hour),
+	}, nil
+}
+
+func (f *fakeCertificateService) CheckExpiring(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func TestSignCertificate_SendsCertificateSignedAfterResponse(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetStationCertificateService(&fakeCertificateService{})
+	defer srv.Stop()
+
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/CP-001", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	// Act
+	call := []interface{}{2, "msg-1", "SignCertificate", map[string]interface{}{"csr": "CSR-PEM"}}
+	if err := conn.WriteJSON(call); err != nil {
+		t.Fatalf("failed to send SignCertificate: %v", err)
+	}
+
+	// Assert
+	var result []json.RawMessage
+	if err := conn.ReadJSON(&result); err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	var resp SignCertificateResponse
+	json.Unmarshal(result[2], &resp)
+	if string(result[0]) != "3" || resp.Status != "Accepted" {
+		t.Fatalf("expected an Accepted CallResult first, got %s", result)
+	}
+
+	var next []json.RawMessage
+	if err := conn.ReadJSON(&next); err != nil {
+		t.Fatalf("failed to read CertificateSigned: %v", err)
+	}
+	var action string
+	json.Unmarshal(next[2], &action)
+	if action != "CertificateSigned" {
+		t.Fatalf("expected CertificateSigned, got %s", action)
+	}
+	var req CertificateSignedRequest
+	json.Unmarshal(next[3], &req)
+	if req.CertificateChain != "CHAIN:CSR-PEM" {
+		t.Errorf("expected the signed chain, got %q", req.CertificateChain)
+	}
+	if req.CertificateType == nil || *req.CertificateType != "ChargingStationCertificate" {
+		t.Errorf("expected ChargingStationCertificate, got %v", req.CertificateType)
+	}
+}
+
+func TestHandleSignCertificate_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		service    *fakeCertificateService
+		wantReason string
+	}{
+		{name: "no certificate service", service: nil, wantReason: "NotSupported"},
+		{name: "invalid CSR", service: &fakeCertificateService{err: errors.New("bad csr")}, wantReason: "InvalidCSR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := NewServer(nil, nil, zap.NewNop())
+			if tt.service != nil {
+				srv.SetStationCertificateService(tt.service)
+			}
+
+			// Act
+			resp, err := srv.handleSignCertificate("CP-001", []byte(`{"csr":"x","certificateType":"V2GCertificate"}`))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if resp.Status != "Rejected" {
+				t.Errorf("expected Rejected, got %s", resp.Status)
+			}
+			if resp.StatusInfo == nil || resp.StatusInfo.ReasonCode != tt.wantReason {
+				t.Errorf("expected reason %s, got %+v", tt.wantReason, resp.StatusInfo)
+			}
+			if resp.issued != nil {
+				t.Error("expected no certificate to be delivered")
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package v201
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ErrChargeRateOutOfRange is returned when a charging profile asks an EVSE
+// for a rate it cannot charge at
+var ErrChargeRateOutOfRange = errors.New("charge rate out of range")
+
+// ChargeRatePolicy decides what happens to periods above an EVSE's maximum
+// charge rate. Periods below its minimum are always rejected: the station
+// would not charge at them, and raising them would exceed the limit asked for.
+type ChargeRatePolicy string
+
+const (
+	ChargeRateClamp  ChargeRatePolicy = "clamp"  // The limit is lowered to the EVSE's maximum
+	ChargeRateReject ChargeRatePolicy = "reject" // The profile is refused
+)
+
+// ChargeRateConfig holds the charge rate checks of charging profiles
+type ChargeRateConfig struct {
+	DefaultMinW float64 // Minimum charge rate of EVSEs that report none; 0 for no minimum
+	Policy      ChargeRatePolicy
+}
+
+// SetChargeRateConfig sets how charging profiles are checked against the
+// charge rate range of their EVSE. An unknown policy falls back to clamping.
+func (s *Server) SetChargeRateConfig(cfg ChargeRateConfig) {
+	if cfg.Policy != ChargeRateReject {
+		cfg.Policy = ChargeRateClamp
+	}
+	if cfg.DefaultMinW < 0 {
+		cfg.DefaultMinW = 0
+	}
+	s.chargeRates = cfg
+}
+
+// chargeRateRange is the range of rates in W an EVSE can charge at. Zero
+// bounds are unknown.
+type chargeRateRange struct {
+	minW float64
+	maxW float64
+}
+
+// evseChargeRate returns the charge rate range of an EVSE from the Power
+// variable of its EVSE component in the stored inventory: the MinSet and
+// MaxSet attributes, else the variable's minLimit and maxLimit
+func (s *Server) evseChargeRate(ctx context.Context, chargePointID string, evseID int) chargeRateRange {
+	rates := chargeRateRange{minW: s.chargeRates.DefaultMinW}
+	if s.inventoryRepo == nil || evseID == 0 {
+		return rates
+	}
+	inventory, err := s.inventoryRepo.GetByChargePointID(ctx, chargePointID)
+	if err != nil || inventory == nil {
+		return rates
+	}
+
+	for _, v := range inventory.Variables {
+		if v.Component != "EVSE" || v.Variable != "Power" || v.EvseID == nil || *v.EvseID != evseID {
+			continue
+		}
+		minW, maxW := v.MinLimit, v.MaxLimit
+		for _, attr := range v.Attributes {
+			value, err := strconv.ParseFloat(attr.Value, 64)
+			if err != nil {
+				continue
+			}
+			switch attr.Type {
+			case "MinSet":
+				minW = &value
+			case "MaxSet":
+				maxW = &value
+			}
+		}
+		if minW != nil && *minW > 0 {
+			rates.minW = *minW
+		}
+		if maxW != nil && *maxW > 0 {
+			rates.maxW = *maxW
+		}
+		break
+	}
+	return rates
+}
+
+// checkChargeRates applies the EVSE's charge rate range to a profile about
+// to be sent, returning the profile to send
+func (s *Server) checkChargeRates(ctx context.Context, chargePointID string, evseID int, profile ChargingProfile) (ChargingProfile, error) {
+	rates := s.evseChargeRate(ctx, chargePointID, evseID)
+	checked, errs := applyChargeRates(profile, rates, s.chargeRates.Policy)
+	if len(errs) > 0 {
+		return profile, fmt.Errorf("%w: profile %d for EVSE %d on %s: %s",
+			ErrChargeRateOutOfRange, profile.Id, evseID, chargePointID, strings.Join(errs, "; "))
+	}
+	if rates.maxW > 0 && profileLimitsChanged(profile, checked) {
+		s.log.Info("Clamped charging profile to the EVSE's maximum charge rate",
+			zap.String("chargePointID", chargePointID),
+			zap.Int("evseID", evseID),
+			zap.Int("profileID", profile.Id),
+			zap.Float64("maxW", rates.maxW),
+		)
+	}
+	return checked, nil
+}
+
+// applyChargeRates checks every period against the charge rate range and
+// the schedule's minChargingRate, whichever minimum is higher. Periods
+// above the maximum are clamped to it, or rejected with ChargeRateReject.
+// A limit of 0 pauses charging and negative limits discharge, so neither
+// is checked. The profile passed in is left untouched.
+func applyChargeRates(profile ChargingProfile, rates chargeRateRange, policy ChargeRatePolicy) (ChargingProfile, []string) {
+	var errs []string
+
+	schedules := make([]ChargingSchedule, len(profile.ChargingSchedule))
+	for i, schedule := range profile.ChargingSchedule {
+		periods := make([]ChargingSchedulePeriod, len(schedule.ChargingSchedulePeriod))
+		for j, period := range schedule.ChargingSchedulePeriod {
+			periods[j] = period
+			limitW := periodLimitW(schedule.ChargingRateUnit, period)
+			if limitW <= 0 {
+				continue
+			}
+
+			minW := rates.minW
+			if schedule.MinChargingRate != nil {
+				scheduleMin := ChargingSchedulePeriod{Limit: *schedule.MinChargingRate, NumberPhases: period.NumberPhases}
+				minW = math.Max(minW, periodLimitW(schedule.ChargingRateUnit, scheduleMin))
+			}
+			if limitW < minW {
+				errs = append(errs, fmt.Sprintf("schedule %d: period %d limit %.0f W is below the minimum charge rate of %.0f W",
+					schedule.Id, j, limitW, minW))
+				continue
+			}
+
+			if rates.maxW > 0 && limitW > rates.maxW {
+				if policy == ChargeRateReject {
+					errs = append(errs, fmt.Sprintf("schedule %d: period %d limit %.0f W exceeds the maximum charge rate of %.0f W",
+						schedule.Id, j, limitW, rates.maxW))
+					continue
+				}
+				periods[j].Limit = limitInUnit(schedule.ChargingRateUnit, period, rates.maxW)
+			}
+		}
+		schedule.ChargingSchedulePeriod = periods
+		schedules[i] = schedule
+	}
+
+	profile.ChargingSchedule = schedules
+	return profile, errs
+}
+
+// limitInUnit converts a limit in W back into the schedule's unit, rounding
+// amperes down to a tenth so the result stays within limitW
+func limitInUnit(unit string, period ChargingSchedulePeriod, limitW float64) float64 {
+	if unit != "A" {
+		return limitW
+	}
+	perAmpere := periodLimitW(unit, ChargingSchedulePeriod{Limit: 1, NumberPhases: period.NumberPhases})
+	if perAmpere <= 0 {
+		return limitW
+	}
+	return math.Floor(limitW/perAmpere*10) / 10
+}
+
+// profileLimitsChanged reports whether any period limit differs between two
+// copies of a profile
+func profileLimitsChanged(before, after ChargingProfile) bool {
+	for i, schedule := range before.ChargingSchedule {
+		for j, period := range schedule.ChargingSchedulePeriod {
+			if after.ChargingSchedule[i].ChargingSchedulePeriod[j].Limit != period.Limit {
+				return true
+			}
+		}
+	}
+	return false
+}
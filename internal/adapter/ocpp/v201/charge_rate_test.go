@@ -0,0 +1,130 @@
+package v201
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// newChargeRateTestServer returns a server whose inventory has EVSE 1 of
+// CP-001 charging between 1380 W (MinSet) and 11 kW (maxLimit)
+func newChargeRateTestServer(policy ChargeRatePolicy) *Server {
+	evseID := 1
+	maxLimit := 11000.0
+	repo := newMockInventoryRepository()
+	repo.Save(context.Background(), &domain.DeviceInventory{
+		ChargePointID: "CP-001",
+		Variables: []domain.InventoryVariable{{
+			Component:  "EVSE",
+			EvseID:     &evseID,
+			Variable:   "Power",
+			Attributes: []domain.InventoryAttribute{{Type: "Actual", Value: "0"}, {Type: "MinSet", Value: "1380"}},
+			MaxLimit:   &maxLimit,
+		}},
+	})
+
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetInventoryRepository(repo)
+	srv.SetChargeRateConfig(ChargeRateConfig{Policy: policy})
+	return srv
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestSetChargingProfile_RejectsPeriodBelowMinimumRate(t *testing.T) {
+	// Arrange
+	srv := newChargeRateTestServer(ChargeRateClamp)
+	defer srv.Stop()
+	received := connectProfileStation(t, srv, "CP-001")
+
+	// Act
+	_, err := srv.SetChargingProfile(context.Background(), "CP-001", 1, txProfile(1, 0,
+		ChargingSchedulePeriod{StartPeriod: 0, Limit: 7400},
+		ChargingSchedulePeriod{StartPeriod: 1800, Limit: 1000},
+	))
+
+	// Assert
+	if !errors.Is(err, ErrChargeRateOutOfRange) {
+		t.Fatalf("expected ErrChargeRateOutOfRange, got %v", err)
+	}
+	if len(received) != 0 {
+		t.Errorf("expected nothing sent to the station, got %d profiles", len(received))
+	}
+	if installed := srv.installedProfiles("CP-001"); len(installed) != 0 {
+		t.Errorf("expected no installed profile, got %d", len(installed))
+	}
+}
+
+func TestSetChargingProfile_ClampsPeriodAboveMaximumRate(t *testing.T) {
+	// Arrange
+	srv := newChargeRateTestServer(ChargeRateClamp)
+	defer srv.Stop()
+	received := connectProfileStation(t, srv, "CP-001")
+	profile := txProfile(1, 0,
+		ChargingSchedulePeriod{StartPeriod: 0, Limit: 22000},
+		ChargingSchedulePeriod{StartPeriod: 1800, Limit: 7400},
+	)
+
+	// Act
+	resp, err := srv.SetChargingProfile(context.Background(), "CP-001", 1, profile)
+
+	// Assert
+	if err != nil || resp.Status != "Accepted" {
+		t.Fatalf("expected the profile to be accepted, got %+v, %v", resp, err)
+	}
+	sent := <-received
+	periods := sent.ChargingProfile.ChargingSchedule[0].ChargingSchedulePeriod
+	if periods[0].Limit != 11000 || periods[1].Limit != 7400 {
+		t.Errorf("expected limits of 11000 W and 7400 W, got %.0f W and %.0f W", periods[0].Limit, periods[1].Limit)
+	}
+	if limit := profile.ChargingSchedule[0].ChargingSchedulePeriod[0].Limit; limit != 22000 {
+		t.Errorf("expected the caller's profile untouched, got %.0f W", limit)
+	}
+}
+
+func TestApplyChargeRates(t *testing.T) {
+	rates := chargeRateRange{minW: 1380, maxW: 11000}
+	tests := []struct {
+		name       string
+		unit       string
+		minRate    *float64
+		limit      float64
+		policy     ChargeRatePolicy
+		wantLimit  float64
+		wantErrors int
+	}{
+		{"within range", "W", nil, 7400, ChargeRateClamp, 7400, 0},
+		{"pause", "W", nil, 0, ChargeRateClamp, 0, 0},
+		{"discharge", "W", nil, -5000, ChargeRateClamp, -5000, 0},
+		{"below station minimum", "W", nil, 1000, ChargeRateClamp, 1000, 1},
+		{"below schedule minChargingRate", "W", floatPtr(4000), 3000, ChargeRateClamp, 3000, 1},
+		{"schedule minChargingRate in amperes", "A", floatPtr(6), 5, ChargeRateClamp, 5, 1},
+		{"above maximum clamped", "W", nil, 22000, ChargeRateClamp, 11000, 0},
+		{"above maximum in amperes clamped", "A", nil, 32, ChargeRateClamp, 15.9, 0},
+		{"above maximum rejected", "W", nil, 22000, ChargeRateReject, 22000, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			profile := txProfile(1, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: tt.limit})
+			profile.ChargingSchedule[0].ChargingRateUnit = tt.unit
+			profile.ChargingSchedule[0].MinChargingRate = tt.minRate
+
+			// Act
+			checked, errs := applyChargeRates(profile, rates, tt.policy)
+
+			// Assert
+			if len(errs) != tt.wantErrors {
+				t.Errorf("expected %d errors, got %v", tt.wantErrors, errs)
+			}
+			if limit := checked.ChargingSchedule[0].ChargingSchedulePeriod[0].Limit; limit != tt.wantLimit {
+				t.Errorf("expected limit %v, got %v", tt.wantLimit, limit)
+			}
+		})
+	}
+}
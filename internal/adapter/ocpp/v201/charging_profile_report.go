@@ -0,0 +1,221 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// profileReportAssembly collects the parts of a multi-part
+// ReportChargingProfiles. The parts carry no sequence number; a charge point
+// sends one call at a time, so they arrive in order.
+type profileReportAssembly struct {
+	profiles  []domain.ReportedChargingProfile
+	startedAt time.Time
+	complete  bool // Answers an unfiltered GetChargingProfiles, so lists every profile
+}
+
+// SetChargingProfileReportRepository enables storing the charging profiles
+// charge points report via ReportChargingProfiles
+func (s *Server) SetChargingProfileReportRepository(repo ports.ChargingProfileReportRepository) {
+	s.profileReportRepo = repo
+}
+
+// expectChargingProfileReport notes a GetChargingProfiles request, so its
+// report is known to list every profile of the charge point when complete
+func (s *Server) expectChargingProfileReport(cpID string, requestID int, complete bool) {
+	s.profileReportMu.Lock()
+	defer s.profileReportMu.Unlock()
+	s.profileReports[fmt.Sprintf("%s:%d", cpID, requestID)] = &profileReportAssembly{
+		startedAt: time.Now(),
+		complete:  complete,
+	}
+}
+
+// forgetChargingProfileReport drops a request no report will answer
+func (s *Server) forgetChargingProfileReport(cpID string, requestID int) {
+	s.profileReportMu.Lock()
+	defer s.profileReportMu.Unlock()
+	delete(s.profileReports, fmt.Sprintf("%s:%d", cpID, requestID))
+}
+
+// handleReportChargingProfiles buffers report parts per requestId until the
+// final one (tbc=false), then stores the profiles: as the charge point's
+// active ones when it answers an unfiltered request, else merged into those
+// already stored
+func (s *Server) handleReportChargingProfiles(cpID string, payload []byte) (*ReportChargingProfilesResponse, error) {
+	var req ReportChargingProfilesRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Charging Profiles Report",
+		zap.String("cpID", cpID),
+		zap.Int("requestId", req.RequestId),
+		zap.Int("evseId", req.EvseId),
+		zap.Int("profileCount", len(req.ChargingProfile)),
+		zap.Bool("toBeContinued", req.Tbc),
+	)
+
+	key := fmt.Sprintf("%s:%d", cpID, req.RequestId)
+	now := time.Now()
+
+	s.profileReportMu.Lock()
+	for k, a := range s.profileReports {
+		if now.Sub(a.startedAt) > ReportAssemblyTimeout {
+			s.log.Warn("Discarding incomplete charging profiles report", zap.String("report", k))
+			delete(s.profileReports, k)
+		}
+	}
+
+	assembly, ok := s.profileReports[key]
+	if !ok {
+		assembly = &profileReportAssembly{startedAt: now}
+		s.profileReports[key] = assembly
+	}
+	for _, profile := range req.ChargingProfile {
+		assembly.profiles = append(assembly.profiles, reportedChargingProfile(req.EvseId, req.ChargingLimitSource, profile))
+	}
+
+	profiles, complete := assembly.profiles, assembly.complete
+	if !req.Tbc {
+		delete(s.profileReports, key)
+	}
+	s.profileReportMu.Unlock()
+
+	if req.Tbc {
+		return &ReportChargingProfilesResponse{}, nil
+	}
+	ctx := context.Background()
+	if !complete {
+		merged, err := s.mergeChargingProfileReport(ctx, cpID, profiles)
+		if err != nil {
+			return nil, err
+		}
+		profiles = merged
+	}
+	if err := s.storeChargingProfileReport(ctx, cpID, req.RequestId, profiles); err != nil {
+		return nil, err
+	}
+	return &ReportChargingProfilesResponse{}, nil
+}
+
+// mergeChargingProfileReport adds the profiles of a filtered report to those
+// stored for the charge point, replacing stored ones with the same ID. The
+// profiles the filter left out are kept.
+func (s *Server) mergeChargingProfileReport(ctx context.Context, cpID string, profiles []domain.ReportedChargingProfile) ([]domain.ReportedChargingProfile, error) {
+	if s.profileReportRepo == nil {
+		return profiles, nil
+	}
+	stored, err := s.profileReportRepo.GetByChargePointID(ctx, cpID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get charging profile report: %w", err)
+	}
+	if stored == nil {
+		return profiles, nil
+	}
+
+	reported := make(map[int]bool, len(profiles))
+	for _, profile := range profiles {
+		reported[profile.ID] = true
+	}
+	var merged []domain.ReportedChargingProfile
+	for _, profile := range stored.Profiles {
+		if !reported[profile.ID] {
+			merged = append(merged, profile)
+		}
+	}
+	return append(merged, profiles...), nil
+}
+
+// storeChargingProfileReport replaces the stored profiles of a charge point
+func (s *Server) storeChargingProfileReport(ctx context.Context, cpID string, requestID int, profiles []domain.ReportedChargingProfile) error {
+	if s.profileReportRepo == nil {
+		return nil
+	}
+
+	report := &domain.ChargingProfileReport{
+		ChargePointID: cpID,
+		RequestID:     requestID,
+		Profiles:      profiles,
+		ReportedAt:    time.Now(),
+	}
+	if report.Profiles == nil {
+		report.Profiles = []domain.ReportedChargingProfile{}
+	}
+	if err := s.profileReportRepo.Save(ctx, report); err != nil {
+		return fmt.Errorf("failed to save charging profile report: %w", err)
+	}
+
+	s.log.Info("Charging profiles stored",
+		zap.String("cpID", cpID),
+		zap.Int("requestId", requestID),
+		zap.Int("profileCount", len(report.Profiles)),
+	)
+	return nil
+}
+
+// clearChargingProfileReport stores an empty report for a charge point that
+// answered an unfiltered GetChargingProfiles with NoProfiles; no
+// ReportChargingProfiles follows such an answer
+func (s *Server) clearChargingProfileReport(ctx context.Context, cpID string, requestID int) {
+	if err := s.storeChargingProfileReport(ctx, cpID, requestID, nil); err != nil {
+		s.log.Warn("Failed to store empty charging profile report",
+			zap.String("cpID", cpID),
+			zap.Error(err),
+		)
+	}
+}
+
+// reportedChargingProfile converts an OCPP charging profile to its domain form
+func reportedChargingProfile(evseID int, source string, profile ChargingProfile) domain.ReportedChargingProfile {
+	reported := domain.ReportedChargingProfile{
+		ID:                  profile.Id,
+		EvseID:              evseID,
+		ChargingLimitSource: source,
+		StackLevel:          profile.StackLevel,
+		Purpose:             profile.ChargingProfilePurpose,
+		Kind:                profile.ChargingProfileKind,
+		RecurrencyKind:      profile.RecurrencyKind,
+		ValidFrom:           parseTimestamp(profile.ValidFrom),
+		ValidTo:             parseTimestamp(profile.ValidTo),
+		Schedules:           make([]domain.ReportedChargingSchedule, 0, len(profile.ChargingSchedule)),
+	}
+	for _, schedule := range profile.ChargingSchedule {
+		rs := domain.ReportedChargingSchedule{
+			ID:               schedule.Id,
+			StartSchedule:    parseTimestamp(schedule.StartSchedule),
+			Duration:         schedule.Duration,
+			ChargingRateUnit: schedule.ChargingRateUnit,
+			MinChargingRate:  schedule.MinChargingRate,
+			Periods:          make([]domain.ReportedSchedulePeriod, 0, len(schedule.ChargingSchedulePeriod)),
+		}
+		for _, period := range schedule.ChargingSchedulePeriod {
+			rs.Periods = append(rs.Periods, domain.ReportedSchedulePeriod{
+				StartPeriod:  period.StartPeriod,
+				Limit:        period.Limit,
+				NumberPhases: period.NumberPhases,
+			})
+		}
+		reported.Schedules = append(reported.Schedules, rs)
+	}
+	return reported
+}
+
+// parseTimestamp parses an optional RFC 3339 timestamp; invalid ones are dropped
+func parseTimestamp(ts *string) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *ts)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
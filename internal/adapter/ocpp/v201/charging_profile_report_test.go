@@ -0,0 +1,120 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+)
+
+// profileReportCall is a ReportChargingProfiles call for requestId 7
+func profileReportCall(t *testing.T, msgID string, evseID int, tbc bool, profileIDs ...int) string {
+	t.Helper()
+	req := ReportChargingProfilesRequest{
+		RequestId:           7,
+		ChargingLimitSource: "CSO",
+		EvseId:              evseID,
+		Tbc:                 tbc,
+	}
+	for _, id := range profileIDs {
+		req.ChargingProfile = append(req.ChargingProfile, ChargingProfile{
+			Id:                     id,
+			StackLevel:             1,
+			ChargingProfilePurpose: "TxDefaultProfile",
+			ChargingProfileKind:    "Relative",
+			ChargingSchedule: []ChargingSchedule{{
+				Id:                     id,
+				ChargingRateUnit:       "W",
+				ChargingSchedulePeriod: []ChargingSchedulePeriod{{StartPeriod: 0, Limit: 11000}},
+			}},
+		})
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to marshal report: %v", err)
+	}
+	return fmt.Sprintf(`[2,%q,"ReportChargingProfiles",%s]`, msgID, payload)
+}
+
+func TestServer_ReportChargingProfilesReassemblesParts(t *testing.T) {
+	// Arrange
+	repo := memory.NewChargingProfileReportRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetChargingProfileReportRepository(repo)
+	defer srv.Stop()
+	conn := dialSchemaStation(t, srv, "CP-PROFILES")
+	ctx := context.Background()
+
+	// Act: the station-wide profiles come first, then those of EVSE 1
+	first := roundTrip(t, conn, profileReportCall(t, "r1", 0, true, 1, 2))
+	afterFirst, _ := repo.GetByChargePointID(ctx, "CP-PROFILES")
+	second := roundTrip(t, conn, profileReportCall(t, "r2", 1, false, 3))
+
+	// Assert
+	for _, reply := range [][]json.RawMessage{first, second} {
+		var msgType MessageType
+		json.Unmarshal(reply[0], &msgType)
+		if msgType != CallResult {
+			t.Fatalf("expected a CallResult, got %s", reply)
+		}
+	}
+	if afterFirst != nil {
+		t.Errorf("expected nothing stored before the last part, got %+v", afterFirst)
+	}
+	report, err := repo.GetByChargePointID(ctx, "CP-PROFILES")
+	if err != nil || report == nil {
+		t.Fatalf("expected a stored report, got %+v, %v", report, err)
+	}
+	if report.RequestID != 7 || len(report.Profiles) != 3 {
+		t.Fatalf("expected 3 profiles of request 7, got %+v", report)
+	}
+	wantEvse := map[int]int{1: 0, 2: 0, 3: 1}
+	for _, profile := range report.Profiles {
+		if profile.EvseID != wantEvse[profile.ID] {
+			t.Errorf("expected profile %d on EVSE %d, got %d", profile.ID, wantEvse[profile.ID], profile.EvseID)
+		}
+		if profile.ChargingLimitSource != "CSO" || len(profile.Schedules) != 1 || profile.Schedules[0].Periods[0].Limit != 11000 {
+			t.Errorf("expected profile %d to keep its source and schedule, got %+v", profile.ID, profile)
+		}
+	}
+}
+
+func TestServer_ReportChargingProfilesMergesFilteredReports(t *testing.T) {
+	// Arrange: profiles 1 and 2 stored from an earlier full report
+	repo := memory.NewChargingProfileReportRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetChargingProfileReportRepository(repo)
+	defer srv.Stop()
+	conn := dialSchemaStation(t, srv, "CP-PROFILES")
+	ctx := context.Background()
+	srv.expectChargingProfileReport("CP-PROFILES", 7, true)
+	roundTrip(t, conn, profileReportCall(t, "r1", 0, false, 1, 2))
+
+	// Act: a request filtered on EVSE 1 reports profile 2 and a new one
+	srv.expectChargingProfileReport("CP-PROFILES", 7, false)
+	roundTrip(t, conn, profileReportCall(t, "r2", 1, false, 2, 3))
+	filtered, _ := repo.GetByChargePointID(ctx, "CP-PROFILES")
+
+	// ...then an unfiltered one reports only profile 3
+	srv.expectChargingProfileReport("CP-PROFILES", 7, true)
+	roundTrip(t, conn, profileReportCall(t, "r3", 1, false, 3))
+	full, _ := repo.GetByChargePointID(ctx, "CP-PROFILES")
+
+	// Assert
+	if filtered == nil || len(filtered.Profiles) != 3 {
+		t.Fatalf("expected profiles 1, 2 and 3 after the filtered report, got %+v", filtered)
+	}
+	wantEvse := map[int]int{1: 0, 2: 1, 3: 1}
+	for _, profile := range filtered.Profiles {
+		if evse, ok := wantEvse[profile.ID]; !ok || profile.EvseID != evse {
+			t.Errorf("expected profile %d on EVSE %d, got %d", profile.ID, wantEvse[profile.ID], profile.EvseID)
+		}
+	}
+	if full == nil || len(full.Profiles) != 1 || full.Profiles[0].ID != 3 {
+		t.Errorf("expected the unfiltered report to replace the stored profiles, got %+v", full)
+	}
+}
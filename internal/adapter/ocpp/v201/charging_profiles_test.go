@@ -0,0 +1,334 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// newProfileTestServer returns a server for a station with two 22 kW connectors
+func newProfileTestServer() *Server {
+	devices := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Connectors: []domain.Connector{
+				{ConnectorID: 1, MaxPowerKW: 22},
+				{ConnectorID: 2, MaxPowerKW: 22},
+			}}, nil
+		},
+	}
+	return NewServer(devices, nil, zap.NewNop())
+}
+
+func txProfile(id, stackLevel int, periods ...ChargingSchedulePeriod) ChargingProfile {
+	return ChargingProfile{
+		Id:                     id,
+		StackLevel:             stackLevel,
+		ChargingProfilePurpose: "TxProfile",
+		ChargingProfileKind:    "Relative",
+		ChargingSchedule: []ChargingSchedule{{
+			Id:                     id,
+			ChargingRateUnit:       "W",
+			ChargingSchedulePeriod: periods,
+		}},
+	}
+}
+
+func TestPreviewChargingProfile_RejectsOverlappingStackLevel(t *testing.T) {
+	// Arrange: profile 1 is already active on EVSE 1 at stack level 2
+	srv := newProfileTestServer()
+	defer srv.Stop()
+	srv.recordChargingProfile("CP-001", 1, txProfile(1, 2, ChargingSchedulePeriod{StartPeriod: 0, Limit: 11000}))
+
+	// Act
+	preview, err := srv.PreviewChargingProfile(context.Background(), "CP-001", 1,
+		txProfile(2, 2, ChargingSchedulePeriod{StartPeriod: 0, Limit: 7400}))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if preview.Valid {
+		t.Fatal("expected the profile to be rejected")
+	}
+	if len(preview.Errors) != 1 || !strings.Contains(preview.Errors[0], "overlaps profile 1 at stack level 2") {
+		t.Errorf("expected an overlap with profile 1, got %v", preview.Errors)
+	}
+	if preview.Composite != nil {
+		t.Errorf("expected no composite for an invalid profile, got %v", preview.Composite)
+	}
+	if len(srv.installedProfiles("CP-001")) != 1 {
+		t.Error("expected the dry run to leave the installed profiles alone")
+	}
+}
+
+func TestPreviewChargingProfile_ValidProfileComposite(t *testing.T) {
+	// Arrange: the station is capped at 11 kW; the new TxProfile asks for
+	// 16 kW for the first half hour and 3.7 kW afterwards
+	srv := newProfileTestServer()
+	defer srv.Stop()
+	srv.recordChargingProfile("CP-001", 0, ChargingProfile{
+		Id:                     10,
+		StackLevel:             0,
+		ChargingProfilePurpose: "ChargePointMaxProfile",
+		ChargingProfileKind:    "Relative",
+		ChargingSchedule: []ChargingSchedule{{
+			ChargingRateUnit:       "A",
+			ChargingSchedulePeriod: []ChargingSchedulePeriod{{StartPeriod: 0, Limit: 16}}, // 16 A x 3 phases = 11040 W
+		}},
+	})
+	// The same id at the same stack level is replaced, not a conflict
+	srv.recordChargingProfile("CP-001", 1, txProfile(2, 1, ChargingSchedulePeriod{StartPeriod: 0, Limit: 5000}))
+
+	// Act
+	preview, err := srv.PreviewChargingProfile(context.Background(), "CP-001", 1, txProfile(2, 1,
+		ChargingSchedulePeriod{StartPeriod: 0, Limit: 16000},
+		ChargingSchedulePeriod{StartPeriod: 1800, Limit: 3700},
+	))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !preview.Valid {
+		t.Fatalf("expected a valid profile, got errors %v", preview.Errors)
+	}
+	if len(preview.Composite) != 2 {
+		t.Fatalf("expected 2 composite periods, got %+v", preview.Composite)
+	}
+	if preview.Composite[0].StartPeriod != 0 || preview.Composite[0].LimitW != 11040 {
+		t.Errorf("expected the station cap of 11040 W first, got %+v", preview.Composite[0])
+	}
+	if preview.Composite[1].StartPeriod != 1800 || preview.Composite[1].LimitW != 3700 {
+		t.Errorf("expected 3700 W after 1800 s, got %+v", preview.Composite[1])
+	}
+	for _, installed := range srv.installedProfiles("CP-001") {
+		if installed.Profile.Id == 2 && installed.Profile.ChargingSchedule[0].ChargingSchedulePeriod[0].Limit != 5000 {
+			t.Error("expected the dry run not to replace the installed profile")
+		}
+	}
+}
+
+func TestValidateChargingProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		evseID  int
+		profile ChargingProfile
+		want    string
+	}{
+		{
+			name:    "limit above station rating",
+			evseID:  1,
+			profile: txProfile(1, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 30000}),
+			want:    "exceeds the station rating",
+		},
+		{
+			name:    "periods out of order",
+			evseID:  1,
+			profile: txProfile(1, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 1000}, ChargingSchedulePeriod{StartPeriod: 0, Limit: 2000}),
+			want:    "does not start after the previous one",
+		},
+		{
+			name:    "TxProfile without EVSE",
+			evseID:  0,
+			profile: txProfile(1, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 1000}),
+			want:    "TxProfile requires an evseId",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateChargingProfile(tt.evseID, tt.profile, 22000)
+			if len(errs) != 1 || !strings.Contains(errs[0], tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, errs)
+			}
+		})
+	}
+}
+
+func TestScheduleOffset_RecurringFollowsStationTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	manaus, _ := time.LoadLocation("America/Manaus")
+
+	tests := []struct {
+		name       string
+		recurrency string
+		start      string
+		now        time.Time
+		want       int
+	}{
+		// Daily at 18:00 local; DST began on 8 March, so 18:00 moved from 23:00 to 22:00 UTC
+		{"daily across DST", "Daily", "2026-03-01T18:00:00-05:00", time.Date(2026, 3, 10, 18, 30, 0, 0, newYork), -1800},
+		{"daily before today's start", "Daily", "2026-03-01T18:00:00-05:00", time.Date(2026, 3, 10, 17, 0, 0, 0, newYork), -23 * 3600},
+		{"weekly", "Weekly", "2026-03-02T07:00:00-04:00", time.Date(2026, 3, 17, 8, 0, 0, 0, manaus), -(24 + 1) * 3600},
+		{"not started yet", "Daily", "2026-03-20T07:00:00-04:00", time.Date(2026, 3, 17, 8, 0, 0, 0, manaus), (3*24 - 1) * 3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			profile := txProfile(1, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 7400})
+			profile.ChargingProfileKind = "Recurring"
+			profile.RecurrencyKind = tt.recurrency
+			profile.ChargingSchedule[0].StartSchedule = &tt.start
+
+			// Act
+			offset := scheduleOffset(profile, profile.ChargingSchedule[0], tt.now)
+
+			// Assert
+			if offset != tt.want {
+				t.Errorf("expected offset %d s, got %d s", tt.want, offset)
+			}
+		})
+	}
+}
+
+func TestSetChargingLimit_ReplacesTheEVSELimit(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	received := connectProfileStation(t, srv, "CP-001")
+	ctx := context.Background()
+
+	// Act
+	if err := srv.SetChargingLimit(ctx, "CP-001", 1, 11000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := srv.SetChargingLimit(ctx, "CP-001", 1, 22000)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first, second := <-received, <-received
+	if first.ChargingProfile.Id != second.ChargingProfile.Id || second.EvseId != 1 {
+		t.Errorf("expected the same TxProfile on EVSE 1 to be replaced, got %d and %d", first.ChargingProfile.Id, second.ChargingProfile.Id)
+	}
+	if limits := installedLimits(srv, "CP-001"); len(limits) != 1 || limits[1] != 22000 {
+		t.Errorf("expected a single 22000 W limit, got %v", limits)
+	}
+}
+
+func TestSetChargingLimit_NamesTheEVSETransaction(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	received := connectProfileStation(t, srv, "CP-001")
+	srv.trackEVSETransaction("CP-001", 1, "ocpp-tx-1")
+	ctx := context.Background()
+
+	// Act
+	errTx := srv.SetChargingLimit(ctx, "CP-001", 1, 11000)
+	srv.untrackTransaction("CP-001", "ocpp-tx-1")
+	errEnded := srv.SetChargingLimit(ctx, "CP-001", 1, 11000)
+
+	// Assert
+	if errTx != nil || errEnded != nil {
+		t.Fatalf("unexpected errors: %v, %v", errTx, errEnded)
+	}
+	withTx, afterEnd := (<-received).ChargingProfile, (<-received).ChargingProfile
+	if withTx.TransactionId == nil || *withTx.TransactionId != "ocpp-tx-1" {
+		t.Errorf("expected the TxProfile to name ocpp-tx-1, got %v", withTx.TransactionId)
+	}
+	if afterEnd.TransactionId != nil {
+		t.Errorf("expected no transactionId once the transaction ended, got %s", *afterEnd.TransactionId)
+	}
+}
+
+func TestScheduleChargingWindow_HoldsSessionUntilWindow(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	received := connectProfileStation(t, srv, "CP-001")
+	start := time.Now().Add(2 * time.Hour)
+	end := start.Add(3 * time.Hour)
+
+	// Act
+	err := srv.ScheduleChargingWindow(context.Background(), "CP-001", 1, start, end, 11000)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	profile := (<-received).ChargingProfile
+	if profile.ChargingProfilePurpose != "TxProfile" || profile.ChargingProfileKind != "Absolute" {
+		t.Errorf("expected an Absolute TxProfile, got %s %s", profile.ChargingProfileKind, profile.ChargingProfilePurpose)
+	}
+	periods := profile.ChargingSchedule[0].ChargingSchedulePeriod
+	if len(periods) != 2 || periods[0].Limit != 0 || periods[1].Limit != 11000 {
+		t.Fatalf("expected 0 W then 11000 W, got %+v", periods)
+	}
+	if offset := time.Duration(periods[1].StartPeriod) * time.Second; offset < 2*time.Hour-time.Minute || offset > 2*time.Hour {
+		t.Errorf("expected charging to start in 2h, got %v", offset)
+	}
+	if d := *profile.ChargingSchedule[0].Duration; d < 5*3600-60 || d > 5*3600 {
+		t.Errorf("expected the schedule to last until the window ends, got %ds", d)
+	}
+}
+
+func TestScheduleChargingWindow_RejectsPastWindow(t *testing.T) {
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+
+	err := srv.ScheduleChargingWindow(context.Background(), "CP-001", 1, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour), 11000)
+
+	if err == nil {
+		t.Fatal("expected an error for a window that is over")
+	}
+}
+
+func TestChargingProfileFromDomain_UsesWireFieldNames(t *testing.T) {
+	// Arrange
+	phases := 3
+	minRate := 1400.0
+	profile := domain.ChargingProfile{
+		ID: 7, StackLevel: 1, ChargingProfilePurpose: "TxDefaultProfile", ChargingProfileKind: "Recurring", RecurrencyKind: "Daily",
+		ChargingSchedule: []domain.ChargingSchedule{{
+			ID: 1, ChargingRateUnit: "W", MinChargingRate: &minRate,
+			ChargingSchedulePeriod: []domain.ChargingSchedulePeriod{{StartPeriod: 0, Limit: 7400, NumberPhases: &phases}},
+		}},
+	}
+
+	// Act
+	data, err := json.Marshal(chargingProfileFromDomain(profile))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var wire map[string]interface{}
+	json.Unmarshal(data, &wire)
+	for field, want := range map[string]interface{}{
+		"id": 7.0, "stackLevel": 1.0, "chargingProfilePurpose": "TxDefaultProfile",
+		"chargingProfileKind": "Recurring", "recurrencyKind": "Daily",
+	} {
+		if wire[field] != want {
+			t.Errorf("expected %s %v, got %v", field, want, wire[field])
+		}
+	}
+	schedules, _ := wire["chargingSchedule"].([]interface{})
+	if len(schedules) != 1 {
+		t.Fatalf("expected one chargingSchedule, got %v", wire["chargingSchedule"])
+	}
+	schedule := schedules[0].(map[string]interface{})
+	if schedule["id"] != 1.0 || schedule["chargingRateUnit"] != "W" || schedule["minChargingRate"] != 1400.0 {
+		t.Errorf("expected schedule 1 in W with a 1400 minimum, got %v", schedule)
+	}
+	periods, _ := schedule["chargingSchedulePeriod"].([]interface{})
+	if len(periods) != 1 {
+		t.Fatalf("expected one chargingSchedulePeriod, got %v", schedule)
+	}
+	period := periods[0].(map[string]interface{})
+	if period["startPeriod"] != 0.0 || period["limit"] != 7400.0 || period["numberPhases"] != 3.0 {
+		t.Errorf("expected 7400 W on 3 phases from 0 s, got %v", period)
+	}
+}
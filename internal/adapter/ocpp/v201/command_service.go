@@ -0,0 +1,437 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Ensure CommandService implements ports.OCPPCommandService
+var _ ports.OCPPCommandService = (*CommandService)(nil)
+
+// CommandService exposes the server's commands through the
+// ports.OCPPCommandService port. Commands the charge point answers with a
+// status other than the one asked for fail with domain.ErrCommandRejected,
+// except where the port hands the status back to the caller.
+type CommandService struct {
+	server *Server
+}
+
+// NewCommandService creates the command port over server
+func NewCommandService(server *Server) *CommandService {
+	return &CommandService{server: server}
+}
+
+// rejected reports a command the charge point did not accept
+func rejected(action, status string) error {
+	return fmt.Errorf("%s %s: %w", action, status, domain.ErrCommandRejected)
+}
+
+// evse addresses a whole EVSE, or the charge point when evseID is nil
+func evse(evseID *int) *Evse {
+	if evseID == nil {
+		return nil
+	}
+	return &Evse{Id: *evseID}
+}
+
+func (c *CommandService) RemoteStartTransaction(ctx context.Context, chargePointID, idToken string, tokenType domain.IdTokenType, evseID *int) error {
+	resp, err := c.server.RemoteStartTransaction(ctx, chargePointID, idToken, tokenType, evseID, nil)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return rejected("RequestStartTransaction", resp.Status)
+	}
+	return nil
+}
+
+// RemoteStopTransaction stops a domain transaction, see Server.StopSession
+func (c *CommandService) RemoteStopTransaction(ctx context.Context, chargePointID, transactionID string) error {
+	resp, err := c.server.RemoteStopTransaction(ctx, chargePointID, c.server.ocppTransactionID(ctx, transactionID))
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return rejected("RequestStopTransaction", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) Reset(ctx context.Context, chargePointID string, resetType string, evseID *int) (string, error) {
+	resp, err := c.server.Reset(ctx, chargePointID, resetType, evseID)
+	if err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+func (c *CommandService) TriggerMessage(ctx context.Context, chargePointID, requestedMessage string, evseID *int) error {
+	resp, err := c.server.TriggerMessage(ctx, chargePointID, requestedMessage, evse(evseID))
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return rejected("TriggerMessage", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) SetChargingProfile(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) error {
+	resp, err := c.server.SetChargingProfile(ctx, chargePointID, evseID, chargingProfileFromDomain(profile))
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return rejected("SetChargingProfile", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) PreviewChargingProfile(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) (*ports.ChargingProfilePreview, error) {
+	return c.server.PreviewChargingProfile(ctx, chargePointID, evseID, chargingProfileFromDomain(profile))
+}
+
+// ClearChargingProfile clears profileID, or every profile on the EVSE when
+// only evseID is set. A charge point with no matching profile gives
+// domain.ErrNotFound.
+func (c *CommandService) ClearChargingProfile(ctx context.Context, chargePointID string, profileID *int, evseID *int) error {
+	var criteria *ClearChargingProfileCriteria
+	if evseID != nil {
+		criteria = &ClearChargingProfileCriteria{EvseId: evseID}
+	}
+	resp, err := c.server.ClearChargingProfile(ctx, chargePointID, profileID, criteria)
+	if err != nil {
+		return err
+	}
+	switch resp.Status {
+	case "Accepted":
+		return nil
+	case "Unknown":
+		return fmt.Errorf("no matching charging profile: %w", domain.ErrNotFound)
+	default:
+		return rejected("ClearChargingProfile", resp.Status)
+	}
+}
+
+func (c *CommandService) UpdateFirmware(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime string, installDateTime *time.Time, retries, retryInterval *int) error {
+	var install *string
+	if installDateTime != nil {
+		formatted := installDateTime.UTC().Format(time.RFC3339)
+		install = &formatted
+	}
+	resp, err := c.server.UpdateFirmware(ctx, chargePointID, firmwareURL, retrieveDateTime, install, retries, retryInterval)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" && resp.Status != "AcceptedCanceled" {
+		return rejected("UpdateFirmware", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) UpdateFirmwareSigned(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime, signingCert, signature string, retries, retryInterval *int) error {
+	resp, err := c.server.UpdateFirmwareSigned(ctx, chargePointID, firmwareURL, retrieveDateTime, signingCert, signature, retries, retryInterval)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" && resp.Status != "AcceptedCanceled" {
+		return rejected("UpdateFirmware", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) PublishFirmware(ctx context.Context, chargePointID, location, checksum string, retries, retryInterval *int) error {
+	resp, err := c.server.PublishFirmware(ctx, chargePointID, location, checksum, retries, retryInterval)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return rejected("PublishFirmware", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) UnlockConnector(ctx context.Context, chargePointID string, evseID, connectorID int) error {
+	resp, err := c.server.UnlockConnector(ctx, chargePointID, evseID, connectorID)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Unlocked" {
+		return rejected("UnlockConnector", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) ClearCache(ctx context.Context, chargePointID string) (string, error) {
+	resp, err := c.server.ClearCache(ctx, chargePointID)
+	if err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+func (c *CommandService) ChangeAvailability(ctx context.Context, chargePointID string, operationalStatus string, evseID *int) error {
+	resp, err := c.server.ChangeAvailability(ctx, chargePointID, operationalStatus, evse(evseID))
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" && resp.Status != "Scheduled" {
+		return rejected("ChangeAvailability", resp.Status)
+	}
+	return nil
+}
+
+// GetVariables reads through the server's variable cache
+func (c *CommandService) GetVariables(ctx context.Context, chargePointID string, variables []ports.GetVariableRequest) ([]ports.GetVariableResponse, error) {
+	data := make([]GetVariableData, len(variables))
+	for i, v := range variables {
+		data[i] = GetVariableData{
+			Component: Component{Name: v.ComponentName},
+			Variable:  Variable{Name: v.VariableName, Instance: v.Instance},
+		}
+	}
+	results, err := c.server.ReadVariables(ctx, chargePointID, data, false)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]ports.GetVariableResponse, len(results))
+	for i, r := range results {
+		responses[i] = ports.GetVariableResponse{
+			ComponentName: r.Component.Name,
+			VariableName:  r.Variable.Name,
+			Instance:      r.Variable.Instance,
+			Value:         r.AttributeValue,
+			Status:        r.AttributeStatus,
+		}
+	}
+	return responses, nil
+}
+
+func (c *CommandService) SetVariables(ctx context.Context, chargePointID string, variables []ports.SetVariableRequest) ([]ports.SetVariableResult, error) {
+	data := make([]SetVariableData, len(variables))
+	for i, v := range variables {
+		data[i] = SetVariableData{
+			AttributeValue: v.Value,
+			Component:      Component{Name: v.ComponentName},
+			Variable:       Variable{Name: v.VariableName, Instance: v.Instance},
+		}
+	}
+	resp, err := c.server.SetVariables(ctx, chargePointID, data)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ports.SetVariableResult, len(resp.SetVariableResult))
+	for i, r := range resp.SetVariableResult {
+		results[i] = ports.SetVariableResult{
+			ComponentName: r.Component.Name,
+			VariableName:  r.Variable.Name,
+			Instance:      r.Variable.Instance,
+			Status:        r.AttributeStatus,
+		}
+		if r.StatusInfo != nil {
+			results[i].StatusInfo = r.StatusInfo.ReasonCode
+		}
+	}
+	return results, nil
+}
+
+// SetVariableMonitoring returns the results in the station's order, which
+// OCPP gives as the request order
+func (c *CommandService) SetVariableMonitoring(ctx context.Context, chargePointID string, monitors []ports.VariableMonitor) ([]ports.VariableMonitorResult, error) {
+	data := make([]SetMonitoringData, len(monitors))
+	for i, m := range monitors {
+		data[i] = SetMonitoringData{
+			Id:          m.ID,
+			Transaction: m.Transaction,
+			Value:       m.Value,
+			Type:        m.Type,
+			Severity:    m.Severity,
+			Component:   Component{Name: m.ComponentName},
+			Variable:    Variable{Name: m.VariableName, Instance: m.Instance},
+		}
+	}
+	resp, err := c.server.SetVariableMonitoring(ctx, chargePointID, data)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ports.VariableMonitorResult, len(resp.SetMonitoringResult))
+	for i, r := range resp.SetMonitoringResult {
+		results[i] = ports.VariableMonitorResult{
+			ID:            r.Id,
+			ComponentName: r.Component.Name,
+			VariableName:  r.Variable.Name,
+			Instance:      r.Variable.Instance,
+			Status:        r.Status,
+		}
+		if r.StatusInfo != nil {
+			results[i].StatusInfo = r.StatusInfo.ReasonCode
+		}
+	}
+	return results, nil
+}
+
+func (c *CommandService) SetMonitoringBase(ctx context.Context, chargePointID, monitoringBase string) (string, error) {
+	resp, err := c.server.SetMonitoringBase(ctx, chargePointID, monitoringBase)
+	if err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+func (c *CommandService) GetLog(ctx context.Context, chargePointID, logType, uploadURL string) error {
+	resp, err := c.server.GetLog(ctx, chargePointID, logType, uploadURL, nil, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" && resp.Status != "AcceptedCanceled" {
+		return rejected("GetLog", resp.Status)
+	}
+	return nil
+}
+
+// ReserveNow reserves the EVSE numbered connectorID, or any EVSE when it is
+// 0. The idToken is one the CSMS issued, so it is sent as a Central token.
+func (c *CommandService) ReserveNow(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error {
+	var evseID *int
+	if connectorID > 0 {
+		evseID = &connectorID
+	}
+	token := IdToken{IdToken: idToken, Type: string(domain.IdTokenTypeCentral)}
+	resp, err := c.server.ReserveNow(ctx, chargePointID, reservationID, expiry, token, evseID)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return rejected("ReserveNow", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) CancelReservation(ctx context.Context, chargePointID string, reservationID int) error {
+	resp, err := c.server.CancelReservation(ctx, chargePointID, reservationID)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return rejected("CancelReservation", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) DataTransfer(ctx context.Context, chargePointID, vendorID, messageID string, data json.RawMessage) (*ports.DataTransferResult, error) {
+	resp, err := c.server.DataTransfer(ctx, chargePointID, vendorID, messageID, data)
+	if err != nil {
+		return nil, err
+	}
+	return &ports.DataTransferResult{Status: resp.Status, Data: resp.Data}, nil
+}
+
+func (c *CommandService) InstallCertificate(ctx context.Context, chargePointID, certificateType, certificate string) (string, error) {
+	resp, err := c.server.InstallCertificate(ctx, chargePointID, certificateType, certificate)
+	if err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+func (c *CommandService) DeleteCertificate(ctx context.Context, chargePointID string, hashData ports.CertificateHashData) (string, error) {
+	resp, err := c.server.DeleteCertificate(ctx, chargePointID, CertificateHashData(hashData))
+	if err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+// GetInstalledCertificateIds lists the trust store; a charge point with no
+// matching certificate answers NotFound, which is an empty list
+func (c *CommandService) GetInstalledCertificateIds(ctx context.Context, chargePointID string, certificateTypes []string) ([]ports.InstalledCertificate, error) {
+	resp, err := c.server.GetInstalledCertificateIds(ctx, chargePointID, certificateTypes)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.Status {
+	case "Accepted":
+	case "NotFound":
+		return []ports.InstalledCertificate{}, nil
+	default:
+		return nil, rejected("GetInstalledCertificateIds", resp.Status)
+	}
+	certificates := make([]ports.InstalledCertificate, len(resp.CertificateHashDataChain))
+	for i, chain := range resp.CertificateHashDataChain {
+		certificates[i] = ports.InstalledCertificate{
+			CertificateType:     chain.CertificateType,
+			CertificateHashData: ports.CertificateHashData(chain.CertificateHashData),
+		}
+		for _, child := range chain.ChildCertificateHashData {
+			certificates[i].ChildCertificates = append(certificates[i].ChildCertificates, ports.CertificateHashData(child))
+		}
+	}
+	return certificates, nil
+}
+
+func (c *CommandService) SetV2GChargingProfile(ctx context.Context, chargePointID string, evseID int, dischargePowerKW float64, durationSeconds int) error {
+	resp, err := c.server.SetV2GChargingProfile(ctx, chargePointID, evseID, dischargePowerKW, durationSeconds, 0)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return rejected("SetChargingProfile", resp.Status)
+	}
+	return nil
+}
+
+func (c *CommandService) ClearV2GChargingProfile(ctx context.Context, chargePointID string, evseID int) error {
+	resp, err := c.server.CancelV2GDischarge(ctx, chargePointID, evseID)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" && resp.Status != "Unknown" {
+		return rejected("ClearChargingProfile", resp.Status)
+	}
+	return nil
+}
+
+// GetV2GCapability returns what the EV last plugged into the charge point
+// reported in NotifyEVChargingNeeds, or domain.ErrNotFound if none has
+func (c *CommandService) GetV2GCapability(ctx context.Context, chargePointID string) (*domain.V2GCapability, error) {
+	var latest *EVCapability
+	for _, capability := range c.server.v2g.GetAllCapabilities() {
+		if capability.ChargePointID == chargePointID && (latest == nil || capability.DetectedAt.After(latest.DetectedAt)) {
+			latest = capability
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no EV charging needs reported by %s: %w", chargePointID, domain.ErrNotFound)
+	}
+
+	bidirectional := latest.RequestedTransfer == "AC_BPT" || latest.RequestedTransfer == "DC_BPT"
+	return &domain.V2GCapability{
+		ChargePointID:         chargePointID,
+		ConnectorID:           latest.EvseID,
+		Supported:             bidirectional,
+		MaxDischargePowerKW:   float64(latest.MaxDischargePowerW) / 1000,
+		MaxDischargeCurrent:   float64(latest.MaxDischargeCurrent),
+		BidirectionalCharging: bidirectional,
+		ISO15118Support:       true, // Charging needs only arrive over ISO 15118
+		CurrentSOC:            latest.StateOfCharge,
+		BatteryCapacityKWh:    float64(latest.BatteryCapacityKWh),
+		LastUpdated:           latest.DetectedAt,
+	}, nil
+}
+
+func (c *CommandService) BulkRemoteStop(ctx context.Context, targets []ports.RemoteStopTarget) []ports.BulkCommandResult {
+	return c.server.BulkRemoteStop(ctx, targets)
+}
+
+func (c *CommandService) IsConnected(chargePointID string) bool {
+	return c.server.IsConnected(chargePointID)
+}
+
+func (c *CommandService) GetConnectedClients() []string {
+	return c.server.GetConnectedClients()
+}
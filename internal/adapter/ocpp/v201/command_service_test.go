@@ -0,0 +1,90 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+func TestCommandService_RemoteStopUsesStoredOCPPTransaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		wantErr error
+	}{
+		{"accepted", "Accepted", nil},
+		{"rejected", "Rejected", domain.ErrCommandRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange - a session started before a restart, so only the
+			// database knows its OCPP transactionId
+			txService := &mocks.MockTransactionService{
+				GetTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+					return &domain.Transaction{ID: id, OCPPTransactionID: "ocpp-tx-7"}, nil
+				},
+			}
+			srv := NewServer(nil, txService, zap.NewNop())
+			defer srv.Stop()
+			requested := answerStopRequests(dialSchemaStation(t, srv, "CP-001"), tt.status)
+			commands := NewCommandService(srv)
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			// Act
+			err := commands.RemoteStopTransaction(ctx, "CP-001", "tx-domain")
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			select {
+			case got := <-requested:
+				if got != "ocpp-tx-7" {
+					t.Errorf("expected a stop for ocpp-tx-7, got %s", got)
+				}
+			default:
+				t.Error("expected a RequestStopTransaction")
+			}
+		})
+	}
+}
+
+func TestCommandService_GetV2GCapability(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	commands := NewCommandService(srv)
+
+	_, errBefore := commands.GetV2GCapability(context.Background(), "CP-V2G")
+
+	payload, _ := json.Marshal(NotifyEVChargingNeedsRequest{EvseId: 2, ChargingNeeds: bptNeeds(60, 75, 11000)})
+	if _, err := srv.handleNotifyEVChargingNeeds("CP-V2G", payload); err != nil {
+		t.Fatalf("charging needs failed: %v", err)
+	}
+
+	// Act
+	capability, err := commands.GetV2GCapability(context.Background(), "CP-V2G")
+
+	// Assert
+	if !errors.Is(errBefore, domain.ErrNotFound) {
+		t.Errorf("expected not found before the EV reported its needs, got %v", errBefore)
+	}
+	if err != nil {
+		t.Fatalf("expected a capability, got %v", err)
+	}
+	if !capability.Supported || capability.ConnectorID != 2 || capability.CurrentSOC != 60 {
+		t.Errorf("expected a bidirectional EV at 60%% on EVSE 2, got %+v", capability)
+	}
+	if capability.MaxDischargePowerKW != 11 || capability.BatteryCapacityKWh != 75 {
+		t.Errorf("expected 11 kW discharge from a 75 kWh battery, got %+v", capability)
+	}
+}
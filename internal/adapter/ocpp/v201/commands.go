@@ -5,16 +5,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // --- Remote Start/Stop Transaction ---
 
-// RemoteStartTransaction requests a charge point to start a transaction
-func (s *Server) RemoteStartTransaction(ctx context.Context, chargePointID string, idToken string, evseID *int, chargingProfile *ChargingProfile) (*RequestStartTransactionResponse, error) {
+// RemoteStartTransaction requests a charge point to start a transaction. An
+// empty tokenType means domain.DefaultIdTokenType.
+func (s *Server) RemoteStartTransaction(ctx context.Context, chargePointID string, idToken string, tokenType domain.IdTokenType, evseID *int, chargingProfile *ChargingProfile) (*RequestStartTransactionResponse, error) {
+	tokenType, err := domain.ParseIdTokenType(string(tokenType))
+	if err != nil {
+		return nil, err
+	}
+
 	req := RequestStartTransactionRequest{
 		IdToken: IdToken{
 			IdToken: idToken,
-			Type:    "ISO14443",
+			Type:    string(tokenType),
 		},
 		RemoteStartId: int(time.Now().UnixNano() % 1000000),
 		EvseId:        evseID,
@@ -115,8 +124,30 @@ func (s *Server) TriggerMessage(ctx context.Context, chargePointID, requestedMes
 
 // --- Charging Profile Management ---
 
-// SetChargingProfile sets a charging profile on an EVSE
+// SetChargingProfile sets a charging profile on an EVSE. Its limits are
+// checked against the EVSE's charge rate range (see SetChargeRateConfig) and
+// TxProfiles of stations with a site capacity are kept within it (see
+// SetSiteCapacity).
 func (s *Server) SetChargingProfile(ctx context.Context, chargePointID string, evseID int, profile ChargingProfile) (*SetChargingProfileResponse, error) {
+	profile, err := s.checkChargeRates(ctx, chargePointID, evseID, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.ChargingProfilePurpose == "TxProfile" {
+		if profile.TransactionId == nil {
+			profile.TransactionId = s.evseTransactionID(chargePointID, evseID)
+		}
+		if capW, ok := s.siteCapacityW(chargePointID); ok {
+			return s.setCappedTxProfile(ctx, chargePointID, evseID, profile, capW)
+		}
+	}
+	return s.sendChargingProfile(ctx, chargePointID, evseID, profile, profile)
+}
+
+// sendChargingProfile sends profile and, once accepted, remembers it together
+// with the request it was derived from
+func (s *Server) sendChargingProfile(ctx context.Context, chargePointID string, evseID int, profile, requested ChargingProfile) (*SetChargingProfileResponse, error) {
 	req := SetChargingProfileRequest{
 		EvseId:          evseID,
 		ChargingProfile: profile,
@@ -136,6 +167,10 @@ func (s *Server) SetChargingProfile(ctx context.Context, chargePointID string, e
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	if response.Status == "Accepted" {
+		s.recordScaledChargingProfile(chargePointID, evseID, profile, requested)
+	}
+
 	return &response, nil
 }
 
@@ -160,6 +195,11 @@ func (s *Server) ClearChargingProfile(ctx context.Context, chargePointID string,
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	if response.Status == "Accepted" {
+		s.forgetChargingProfiles(chargePointID, profileID, criteria)
+		s.rebalanceSiteCapacity(ctx, chargePointID)
+	}
+
 	return &response, nil
 }
 
@@ -171,20 +211,34 @@ func (s *Server) GetChargingProfiles(ctx context.Context, chargePointID string,
 		ChargingProfile: criteria,
 	}
 
+	// The report may arrive before the response
+	unfiltered := evseID == nil && criteria == nil
+	s.expectChargingProfileReport(chargePointID, req.RequestId, unfiltered)
+
 	resp, err := s.SendCommand(ctx, chargePointID, "GetChargingProfiles", req)
 	if err != nil {
+		s.forgetChargingProfileReport(chargePointID, req.RequestId)
 		return nil, fmt.Errorf("get charging profiles failed: %w", err)
 	}
 
 	if !resp.Success {
+		s.forgetChargingProfileReport(chargePointID, req.RequestId)
 		return nil, fmt.Errorf("get charging profiles rejected: %s - %s", resp.Error.Code, resp.Error.Description)
 	}
 
 	var response GetChargingProfilesResponse
 	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		s.forgetChargingProfileReport(chargePointID, req.RequestId)
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	if response.Status != "Accepted" {
+		s.forgetChargingProfileReport(chargePointID, req.RequestId)
+	}
+	if response.Status == "NoProfiles" && unfiltered {
+		s.clearChargingProfileReport(ctx, chargePointID, req.RequestId)
+	}
+
 	return &response, nil
 }
 
@@ -192,6 +246,10 @@ func (s *Server) GetChargingProfiles(ctx context.Context, chargePointID string,
 
 // UpdateFirmware requests a charge point to update its firmware
 func (s *Server) UpdateFirmware(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime string, installDateTime *string, retries, retryInterval *int) (*UpdateFirmwareResponse, error) {
+	if err := s.checkNotPublishing(ctx, chargePointID); err != nil {
+		return nil, err
+	}
+
 	req := UpdateFirmwareRequest{
 		RequestId: int(time.Now().UnixNano() % 1000000),
 		Firmware: Firmware{
@@ -216,12 +274,19 @@ func (s *Server) UpdateFirmware(ctx context.Context, chargePointID, firmwareURL,
 	if err := json.Unmarshal(resp.Payload, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	if response.Status == "Accepted" || response.Status == "AcceptedCanceled" {
+		s.trackFirmwareStatus(chargePointID, firmwareUpdateAccepted)
+	}
 
 	return &response, nil
 }
 
 // UpdateFirmwareSigned requests a signed firmware update (with certificate validation)
-func (s *Server) UpdateFirmwareSigned(ctx context.Context, chargePointID string, firmwareURL, retrieveDateTime string, signingCert, signature string) (*UpdateFirmwareResponse, error) {
+func (s *Server) UpdateFirmwareSigned(ctx context.Context, chargePointID string, firmwareURL, retrieveDateTime string, signingCert, signature string, retries, retryInterval *int) (*UpdateFirmwareResponse, error) {
+	if err := s.checkNotPublishing(ctx, chargePointID); err != nil {
+		return nil, err
+	}
+
 	req := UpdateFirmwareRequest{
 		RequestId: int(time.Now().UnixNano() % 1000000),
 		Firmware: Firmware{
@@ -230,6 +295,8 @@ func (s *Server) UpdateFirmwareSigned(ctx context.Context, chargePointID string,
 			SigningCertificate: &signingCert,
 			Signature:          &signature,
 		},
+		Retries:       retries,
+		RetryInterval: retryInterval,
 	}
 
 	resp, err := s.SendCommand(ctx, chargePointID, "UpdateFirmware", req)
@@ -245,6 +312,9 @@ func (s *Server) UpdateFirmwareSigned(ctx context.Context, chargePointID string,
 	if err := json.Unmarshal(resp.Payload, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	if response.Status == "Accepted" || response.Status == "AcceptedCanceled" {
+		s.trackFirmwareStatus(chargePointID, firmwareUpdateAccepted)
+	}
 
 	return &response, nil
 }
@@ -294,6 +364,15 @@ func (s *Server) SetVariables(ctx context.Context, chargePointID string, variabl
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	// Drop cached values of the variables the station accepted
+	var changed []SetVariableData
+	for _, result := range response.SetVariableResult {
+		if result.AttributeStatus == "Accepted" || result.AttributeStatus == "RebootRequired" {
+			changed = append(changed, SetVariableData{Component: result.Component, Variable: result.Variable})
+		}
+	}
+	s.variables.invalidate(chargePointID, changed)
+
 	return &response, nil
 }
 
@@ -323,6 +402,44 @@ func (s *Server) UnlockConnector(ctx context.Context, chargePointID string, evse
 	return &response, nil
 }
 
+// --- Clear Cache ---
+
+// ClearCache asks the charge point to drop its Authorization Cache, so
+// idTokens are authorized against the CSMS again
+func (s *Server) ClearCache(ctx context.Context, chargePointID string) (*ClearCacheResponse, error) {
+	resp, err := s.SendCommand(ctx, chargePointID, "ClearCache", ClearCacheRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("clear cache failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("clear cache rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response ClearCacheResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// Ensure Server implements ports.AuthorizationCacheClearer
+var _ ports.AuthorizationCacheClearer = (*Server)(nil)
+
+// ClearAuthorizationCache clears the charge point's Authorization Cache and
+// fails unless the charge point accepted
+func (s *Server) ClearAuthorizationCache(ctx context.Context, chargePointID string) error {
+	resp, err := s.ClearCache(ctx, chargePointID)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return fmt.Errorf("charge point %s did not clear its authorization cache: %s", chargePointID, resp.Status)
+	}
+	return nil
+}
+
 // --- Change Availability ---
 
 // ChangeAvailability changes the availability of a charge point or EVSE
@@ -349,6 +466,79 @@ func (s *Server) ChangeAvailability(ctx context.Context, chargePointID string, o
 	return &response, nil
 }
 
+// --- Reservations ---
+
+// ReserveNow reserves an EVSE (or any EVSE when evseID is nil) for idToken
+// until expiry
+func (s *Server) ReserveNow(ctx context.Context, chargePointID string, reservationID int, expiry time.Time, idToken IdToken, evseID *int) (*ReserveNowResponse, error) {
+	req := ReserveNowRequest{
+		Id:             reservationID,
+		ExpiryDateTime: expiry.UTC().Format(time.RFC3339),
+		IdToken:        idToken,
+		EvseId:         evseID,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "ReserveNow", req)
+	if err != nil {
+		return nil, fmt.Errorf("reserve now failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("reserve now rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response ReserveNowResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// CancelReservation cancels a reservation made with ReserveNow
+func (s *Server) CancelReservation(ctx context.Context, chargePointID string, reservationID int) (*CancelReservationResponse, error) {
+	req := CancelReservationRequest{
+		ReservationId: reservationID,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "CancelReservation", req)
+	if err != nil {
+		return nil, fmt.Errorf("cancel reservation failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("cancel reservation rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response CancelReservationResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// --- Tariff and Cost ---
+
+// CostUpdated shows the running cost of a transaction on the station display
+func (s *Server) CostUpdated(ctx context.Context, chargePointID, transactionID string, totalCost float64) error {
+	req := CostUpdatedRequest{
+		TotalCost:     totalCost,
+		TransactionId: transactionID,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "CostUpdated", req)
+	if err != nil {
+		return fmt.Errorf("cost updated failed: %w", err)
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("cost updated rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	return nil
+}
+
 // --- Diagnostics ---
 
 // GetLog requests diagnostic logs from a charge point
@@ -379,18 +569,127 @@ func (s *Server) GetLog(ctx context.Context, chargePointID, logType, uploadURL s
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	if response.Status != "Rejected" {
+		s.recordLogRequest(ctx, chargePointID, req, response)
+	}
+
+	return &response, nil
+}
+
+// --- Security ---
+
+// CertificateSigned sends a certificate chain signed by the CSMS
+func (s *Server) CertificateSigned(ctx context.Context, chargePointID, certificateChain, certificateType string) (*CertificateSignedResponse, error) {
+	req := CertificateSignedRequest{
+		CertificateChain: certificateChain,
+	}
+	if certificateType != "" {
+		req.CertificateType = stringPtr(certificateType)
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "CertificateSigned", req)
+	if err != nil {
+		return nil, fmt.Errorf("certificate signed failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("certificate signed rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response CertificateSignedResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// InstallCertificate adds a root certificate to the charge point's trust store
+func (s *Server) InstallCertificate(ctx context.Context, chargePointID, certificateType, certificate string) (*InstallCertificateResponse, error) {
+	req := InstallCertificateRequest{
+		CertificateType: certificateType,
+		Certificate:     certificate,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "InstallCertificate", req)
+	if err != nil {
+		return nil, fmt.Errorf("install certificate failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("install certificate rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response InstallCertificateResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// DeleteCertificate removes an installed certificate from the charge point
+func (s *Server) DeleteCertificate(ctx context.Context, chargePointID string, hashData CertificateHashData) (*DeleteCertificateResponse, error) {
+	req := DeleteCertificateRequest{
+		CertificateHashData: hashData,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "DeleteCertificate", req)
+	if err != nil {
+		return nil, fmt.Errorf("delete certificate failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("delete certificate rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response DeleteCertificateResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetInstalledCertificateIds lists the certificates installed on the charge
+// point, optionally restricted to certificateTypes
+func (s *Server) GetInstalledCertificateIds(ctx context.Context, chargePointID string, certificateTypes []string) (*GetInstalledCertificateIdsResponse, error) {
+	req := GetInstalledCertificateIdsRequest{
+		CertificateType: certificateTypes,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "GetInstalledCertificateIds", req)
+	if err != nil {
+		return nil, fmt.Errorf("get installed certificate ids failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("get installed certificate ids rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response GetInstalledCertificateIdsResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
 	return &response, nil
 }
 
 // --- V2G Specific Commands ---
 
+// v2gDischargeProfileID is the TxProfile SetV2GChargingProfile keeps on an
+// EVSE; a new discharge replaces the previous one
+func v2gDischargeProfileID(evseID int) int {
+	return 5000000 + evseID
+}
+
 // SetV2GChargingProfile sets a bidirectional charging profile for V2G
 func (s *Server) SetV2GChargingProfile(ctx context.Context, chargePointID string, evseID int, dischargePowerKW float64, duration int, minSOC int) (*SetChargingProfileResponse, error) {
 	now := time.Now().Format(time.RFC3339)
 
 	// Create a V2G charging profile with negative limits for discharge
 	profile := ChargingProfile{
-		Id:                     int(time.Now().UnixNano() % 1000000),
+		Id:                     v2gDischargeProfileID(evseID),
 		StackLevel:             0, // Highest priority
 		ChargingProfilePurpose: "TxProfile",
 		ChargingProfileKind:    "Absolute",
@@ -0,0 +1,112 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// connectStartStation dials the server as chargePointID and accepts every
+// RequestStartTransaction
+func connectStartStation(t *testing.T, srv *Server, chargePointID string) <-chan RequestStartTransactionRequest {
+	t.Helper()
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	t.Cleanup(httpSrv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	received := make(chan RequestStartTransactionRequest, 4)
+	go func() {
+		for {
+			var raw []json.RawMessage
+			if err := conn.ReadJSON(&raw); err != nil {
+				return
+			}
+			var msgID, action string
+			json.Unmarshal(raw[1], &msgID)
+			json.Unmarshal(raw[2], &action)
+			if action == "RequestStartTransaction" {
+				var req RequestStartTransactionRequest
+				json.Unmarshal(raw[3], &req)
+				received <- req
+			}
+			conn.WriteJSON([]interface{}{CallResult, msgID, RequestStartTransactionResponse{Status: "Accepted"}})
+		}
+	}()
+
+	waitConnected(t, srv, chargePointID)
+	return received
+}
+
+func TestRemoteStartTransaction_TokenType(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokenType domain.IdTokenType
+		wantType  string
+	}{
+		{"eMAID contract", domain.IdTokenTypeEMAID, "eMAID"},
+		{"keypad code", domain.IdTokenTypeKeyCode, "KeyCode"},
+		{"defaults to ISO14443", "", "ISO14443"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := NewServer(nil, nil, zap.NewNop())
+			defer srv.Stop()
+			received := connectStartStation(t, srv, "CP-001")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// Act
+			resp, err := srv.RemoteStartTransaction(ctx, "CP-001", "BR-SGC-C12345678-9", tt.tokenType, nil, nil)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Status != "Accepted" {
+				t.Errorf("expected Accepted, got %s", resp.Status)
+			}
+			req := <-received
+			if req.IdToken.Type != tt.wantType || req.IdToken.IdToken != "BR-SGC-C12345678-9" {
+				t.Errorf("expected idToken BR-SGC-C12345678-9 of type %s, got %+v", tt.wantType, req.IdToken)
+			}
+		})
+	}
+}
+
+func TestRemoteStartTransaction_RejectsUnknownTokenType(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	received := connectStartStation(t, srv, "CP-001")
+
+	// Act
+	_, err := srv.RemoteStartTransaction(context.Background(), "CP-001", "04A2B3C4", "Barcode", nil, nil)
+
+	// Assert
+	if !errors.Is(err, domain.ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+	select {
+	case req := <-received:
+		t.Errorf("expected nothing sent to the station, got %+v", req)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
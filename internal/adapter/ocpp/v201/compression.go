@@ -0,0 +1,151 @@
+package v201
+
+import (
+	"compress/flate"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
+)
+
+// CompressionConfig controls permessage-deflate (RFC 7692) on charge point
+// connections. It is only used when the station offers it in its handshake;
+// stations that don't keep talking uncompressed.
+type CompressionConfig struct {
+	Enabled bool
+	Level   int // flate level, from flate.BestSpeed (1) to flate.BestCompression (9)
+}
+
+// DefaultCompressionConfig favours CPU over ratio; OCPP JSON compresses well
+// even at the fastest level
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Enabled: true,
+		Level:   flate.BestSpeed,
+	}
+}
+
+// SetCompression sets whether compression is offered to new connections and
+// at which level. An out-of-range level falls back to the default.
+func (s *Server) SetCompression(cfg CompressionConfig) {
+	if cfg.Level < flate.BestSpeed || cfg.Level > flate.BestCompression {
+		cfg.Level = DefaultCompressionConfig().Level
+	}
+	s.compression = cfg
+	s.upgrader.EnableCompression = cfg.Enabled
+}
+
+// offersCompression reports whether the handshake offered permessage-deflate,
+// which the upgrader then accepts when compression is enabled
+func offersCompression(r *http.Request) bool {
+	for _, header := range r.Header.Values("Sec-WebSocket-Extensions") {
+		for _, ext := range strings.Split(header, ",") {
+			name, _, _ := strings.Cut(ext, ";")
+			if strings.EqualFold(strings.TrimSpace(name), "permessage-deflate") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// countingConn counts the bytes that actually cross the network
+type countingConn struct {
+	net.Conn
+	read    atomic.Int64
+	written atomic.Int64
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.written.Add(int64(n))
+	return n, err
+}
+
+// countingListener wraps accepted connections in a countingConn
+type countingListener struct {
+	net.Listener
+}
+
+func (l countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn}, nil
+}
+
+// compressionStats compares the payload a compressed connection carried with
+// its wire bytes and reports the difference as bytes saved. Framing (and TLS)
+// overhead counts against the savings.
+type compressionStats struct {
+	wire                  *countingConn
+	baseRead, baseWritten int64 // Wire bytes before the upgrade (handshake)
+	payloadIn, payloadOut atomic.Int64
+	savedIn, savedOut     atomic.Int64 // Already reported
+}
+
+// newCompressionStats returns nil when the connection is not compressed or
+// was not accepted through a countingListener (e.g. Start was bypassed)
+func newCompressionStats(conn *websocket.Conn, compressed bool) *compressionStats {
+	if !compressed {
+		return nil
+	}
+	netConn := conn.NetConn()
+	if tlsConn, ok := netConn.(*tls.Conn); ok {
+		netConn = tlsConn.NetConn()
+	}
+	wire, ok := netConn.(*countingConn)
+	if !ok {
+		return nil
+	}
+	return &compressionStats{
+		wire:        wire,
+		baseRead:    wire.read.Load(),
+		baseWritten: wire.written.Load(),
+	}
+}
+
+// received records an inbound message of n payload bytes
+func (c *compressionStats) received(n int) {
+	if c == nil {
+		return
+	}
+	payload := c.payloadIn.Add(int64(n))
+	c.report(payload-(c.wire.read.Load()-c.baseRead), &c.savedIn, true)
+}
+
+// sent records an outbound message of n payload bytes
+func (c *compressionStats) sent(n int) {
+	if c == nil {
+		return
+	}
+	payload := c.payloadOut.Add(int64(n))
+	c.report(payload-(c.wire.written.Load()-c.baseWritten), &c.savedOut, false)
+}
+
+// report adds whatever the running saving grew by since the last report;
+// the counter only moves forward
+func (c *compressionStats) report(saved int64, reported *atomic.Int64, inbound bool) {
+	for {
+		prev := reported.Load()
+		if saved <= prev {
+			return
+		}
+		if reported.CompareAndSwap(prev, saved) {
+			telemetry.RecordOCPPCompressionSaved(saved-prev, inbound)
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package v201
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
+)
+
+// startCountingServer serves srv through a countingListener, as Start does
+func startCountingServer(t *testing.T, srv *Server) *httptest.Server {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	httpSrv := httptest.NewUnstartedServer(http.HandlerFunc(srv.handleConnection))
+	httpSrv.Listener.Close()
+	httpSrv.Listener = countingListener{ln}
+	httpSrv.Start()
+	return httpSrv
+}
+
+// heartbeatRoundTrip sends a Heartbeat padded with compressible data and
+// waits for its CallResult
+func heartbeatRoundTrip(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	padding := strings.Repeat(`{"measurand":"Energy.Active.Import.Register","unit":"Wh"}`, 200)
+	call := []interface{}{Call, "hb-1", "Heartbeat", map[string]string{"padding": padding}}
+	if err := conn.WriteJSON(call); err != nil {
+		t.Fatalf("failed to send heartbeat: %v", err)
+	}
+
+	var raw []json.RawMessage
+	if err := conn.ReadJSON(&raw); err != nil {
+		t.Fatalf("failed to read heartbeat response: %v", err)
+	}
+	var msgType MessageType
+	json.Unmarshal(raw[0], &msgType)
+	if msgType != CallResult {
+		t.Fatalf("expected a CallResult, got %s", raw[0])
+	}
+}
+
+func TestServer_CompressionNegotiation(t *testing.T) {
+	tests := []struct {
+		name           string
+		serverEnabled  bool
+		clientOffers   bool
+		wantCompressed bool
+	}{
+		{"both support it", true, true, true},
+		{"station without compression", true, false, false},
+		{"compression disabled on the server", false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := NewServer(nil, nil, zap.NewNop())
+			defer srv.Stop()
+			srv.SetCompression(CompressionConfig{Enabled: tt.serverEnabled, Level: 6})
+			httpSrv := startCountingServer(t, srv)
+			defer httpSrv.Close()
+			savedBefore := testutil.ToFloat64(telemetry.OCPPCompressionBytesSaved.WithLabelValues("inbound"))
+
+			// Act
+			dialer := websocket.Dialer{
+				Subprotocols:      []string{"ocpp2.0.1"},
+				EnableCompression: tt.clientOffers,
+			}
+			conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/CP-001", nil)
+			if err != nil {
+				t.Fatalf("failed to connect: %v", err)
+			}
+			defer conn.Close()
+			heartbeatRoundTrip(t, conn)
+
+			// Assert
+			negotiated := strings.Contains(resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+			if negotiated != tt.wantCompressed {
+				t.Errorf("expected compression negotiated %v, got %v", tt.wantCompressed, negotiated)
+			}
+			saved := testutil.ToFloat64(telemetry.OCPPCompressionBytesSaved.WithLabelValues("inbound")) - savedBefore
+			if tt.wantCompressed && saved <= 0 {
+				t.Error("expected the compressed heartbeat to record bytes saved")
+			}
+			if !tt.wantCompressed && saved != 0 {
+				t.Errorf("expected no bytes saved without compression, got %.0f", saved)
+			}
+		})
+	}
+}
+
+func TestSetCompression_InvalidLevelFallsBack(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+
+	// Act
+	srv.SetCompression(CompressionConfig{Enabled: true, Level: 42})
+
+	// Assert
+	if srv.compression.Level != DefaultCompressionConfig().Level {
+		t.Errorf("expected level %d, got %d", DefaultCompressionConfig().Level, srv.compression.Level)
+	}
+	if !srv.upgrader.EnableCompression {
+		t.Error("expected the upgrader to offer compression")
+	}
+}
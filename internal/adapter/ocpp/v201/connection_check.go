@@ -0,0 +1,118 @@
+package v201
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ConnectionTestWindow is how long a connection test waits for the
+// triggered message once the station accepted the TriggerMessage. Kept short
+// so an unrelated message is unlikely to be taken for the triggered one.
+const ConnectionTestWindow = 10 * time.Second
+
+// inboundWaiter is a connection test awaiting a message from a station
+type inboundWaiter struct {
+	action   string
+	received chan time.Time
+}
+
+// TestConnection triggers requestedMessage, Heartbeat when empty, and
+// reports how long the station took to send it
+func (s *Server) TestConnection(ctx context.Context, chargePointID, requestedMessage string) (*ports.ConnectionTestResult, error) {
+	if requestedMessage == "" {
+		requestedMessage = "Heartbeat"
+	}
+	if requestedMessage != "Heartbeat" && requestedMessage != "BootNotification" {
+		return nil, fmt.Errorf("%w: can only test with Heartbeat or BootNotification, not %q", domain.ErrValidation, requestedMessage)
+	}
+	if !s.IsConnected(chargePointID) {
+		return nil, domain.ErrChargePointNotConnected
+	}
+
+	result := &ports.ConnectionTestResult{
+		ChargePointID:    chargePointID,
+		RequestedMessage: requestedMessage,
+	}
+
+	// Registered before the trigger goes out, as the station may answer first
+	waiter := s.awaitInbound(chargePointID, requestedMessage)
+	defer s.stopAwaiting(chargePointID, waiter)
+
+	sentAt := time.Now()
+	resp, err := s.TriggerMessage(ctx, chargePointID, requestedMessage, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.TriggerStatus = resp.Status
+	if resp.Status != "Accepted" {
+		result.Error = fmt.Sprintf("station answered TriggerMessage with %s", resp.Status)
+		return result, nil
+	}
+
+	timer := time.NewTimer(ConnectionTestWindow)
+	defer timer.Stop()
+	select {
+	case receivedAt := <-waiter.received:
+		result.Success = true
+		result.LatencyMs = receivedAt.Sub(sentAt).Milliseconds()
+	case <-timer.C:
+		result.Error = fmt.Sprintf("no %s within %s", requestedMessage, ConnectionTestWindow)
+	case <-ctx.Done():
+		result.Error = ctx.Err().Error()
+	}
+
+	s.log.Info("Connection test finished",
+		zap.String("chargePointID", chargePointID),
+		zap.String("requestedMessage", requestedMessage),
+		zap.Bool("success", result.Success),
+		zap.Int64("latencyMs", result.LatencyMs),
+	)
+	return result, nil
+}
+
+// awaitInbound registers interest in the next action message from a station
+func (s *Server) awaitInbound(chargePointID, action string) *inboundWaiter {
+	waiter := &inboundWaiter{action: action, received: make(chan time.Time, 1)}
+	s.waiterMu.Lock()
+	s.inboundWaiters[chargePointID] = append(s.inboundWaiters[chargePointID], waiter)
+	s.waiterMu.Unlock()
+	return waiter
+}
+
+func (s *Server) stopAwaiting(chargePointID string, waiter *inboundWaiter) {
+	s.waiterMu.Lock()
+	defer s.waiterMu.Unlock()
+	waiters := s.inboundWaiters[chargePointID]
+	for i, w := range waiters {
+		if w == waiter {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(s.inboundWaiters, chargePointID)
+	} else {
+		s.inboundWaiters[chargePointID] = waiters
+	}
+}
+
+// notifyInbound tells the connection tests waiting on a station that action arrived
+func (s *Server) notifyInbound(chargePointID, action string) {
+	s.waiterMu.Lock()
+	defer s.waiterMu.Unlock()
+	for _, w := range s.inboundWaiters[chargePointID] {
+		if w.action == action {
+			select {
+			case w.received <- time.Now():
+			default: // Already received one
+			}
+		}
+	}
+}
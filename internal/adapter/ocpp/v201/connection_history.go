@@ -0,0 +1,64 @@
+package v201
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// connectionEventTimeout bounds storing a connection event, which happens on
+// the connection's own goroutine
+const connectionEventTimeout = 5 * time.Second
+
+// SetConnectionEventRepository enables recording when charge points connect
+// and disconnect
+func (s *Server) SetConnectionEventRepository(repo ports.ConnectionEventRepository) {
+	s.connEvents = repo
+}
+
+// recordConnectionEvent stores a connect or disconnect of a charge point
+func (s *Server) recordConnectionEvent(chargePointID string, eventType domain.ConnectionEventType, remoteAddr, reason string, at time.Time) {
+	if s.connEvents == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectionEventTimeout)
+	defer cancel()
+	event := &domain.ConnectionEvent{
+		ID:            uuid.New().String(),
+		ChargePointID: chargePointID,
+		Type:          eventType,
+		RemoteAddr:    remoteAddr,
+		Reason:        reason,
+		Timestamp:     at,
+	}
+	if err := s.connEvents.Save(ctx, event); err != nil {
+		s.log.Warn("Failed to record connection event",
+			zap.String("chargePointID", chargePointID),
+			zap.String("type", string(eventType)),
+			zap.Error(err),
+		)
+	}
+}
+
+// disconnectReason classifies the error that ended a connection's read loop
+func disconnectReason(err error) string {
+	var netErr net.Error
+	var closeErr *websocket.CloseError
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return domain.DisconnectReasonPingTimeout
+	case errors.As(err, &closeErr):
+		return domain.DisconnectReasonClosed
+	default:
+		return domain.DisconnectReasonError
+	}
+}
@@ -0,0 +1,79 @@
+package v201
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// waitConnectionEvents polls until the charge point has n connection events
+func waitConnectionEvents(t *testing.T, repo ports.ConnectionEventRepository, chargePointID string, n int) []domain.ConnectionEvent {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		events, err := repo.FindByChargePointID(context.Background(), chargePointID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("failed to list connection events: %v", err)
+		}
+		if len(events) >= n {
+			return events
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d connection events, got %+v", n, events)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConnectionHistory_RecordsConnectAndClose(t *testing.T) {
+	// Arrange
+	history := memory.NewConnectionEventRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetConnectionEventRepository(history)
+
+	// Act
+	conn := dialSchemaStation(t, srv, "CP-001")
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "maintenance"), time.Now().Add(time.Second))
+
+	// Assert
+	events := waitConnectionEvents(t, history, "CP-001", 2)
+	if events[0].Type != domain.ConnectionEventConnected || events[0].RemoteAddr == "" {
+		t.Errorf("expected a connect with the remote address, got %+v", events[0])
+	}
+	if events[1].Type != domain.ConnectionEventDisconnected || events[1].Reason != domain.DisconnectReasonClosed {
+		t.Errorf("expected a disconnect closed by the station, got %+v", events[1])
+	}
+}
+
+func TestConnectionHistory_RecordsReplacedConnection(t *testing.T) {
+	// Arrange
+	history := memory.NewConnectionEventRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetConnectionEventRepository(history)
+	dialSchemaStation(t, srv, "CP-001")
+
+	// Act: the station reconnects before its old connection is closed
+	dialSchemaStation(t, srv, "CP-001")
+
+	// Assert
+	events := waitConnectionEvents(t, history, "CP-001", 3)
+	if len(events) != 3 {
+		t.Fatalf("expected connect, disconnect and connect, got %+v", events)
+	}
+	if events[1].Type != domain.ConnectionEventDisconnected || events[1].Reason != domain.DisconnectReasonReplaced {
+		t.Errorf("expected the old connection to be replaced, got %+v", events[1])
+	}
+	if events[2].Type != domain.ConnectionEventConnected {
+		t.Errorf("expected the new connection last, got %+v", events[2])
+	}
+}
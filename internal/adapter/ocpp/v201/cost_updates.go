@@ -0,0 +1,138 @@
+package v201
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// DefaultCostUpdateInterval is how often the running cost is pushed to
+// charge points with an active transaction
+const DefaultCostUpdateInterval = time.Minute
+
+// StartCostUpdates periodically computes the running cost of every active
+// transaction with billing and shows it on the station display through
+// CostUpdated. It stops with the server.
+func (s *Server) StartCostUpdates(billing ports.BillingService, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCostUpdateInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopCleanup:
+				return
+			case <-ticker.C:
+				s.pushRunningCosts(billing, interval)
+			}
+		}
+	}()
+}
+
+// pushRunningCosts sends CostUpdated for all active transactions. Each push
+// may take at most one interval so a slow station does not delay the next
+// round.
+func (s *Server) pushRunningCosts(billing ports.BillingService, interval time.Duration) {
+	s.txMu.RLock()
+	active := make(map[evseKey]string, len(s.evseTxIDs))
+	for key, txID := range s.evseTxIDs {
+		active[key] = txID
+	}
+	s.txMu.RUnlock()
+
+	var wg sync.WaitGroup
+	for key, ocppTxID := range active {
+		wg.Add(1)
+		go func(cpID, ocppTxID string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			defer cancel()
+			s.pushRunningCost(ctx, billing, cpID, ocppTxID)
+		}(key.chargePointID, ocppTxID)
+	}
+	wg.Wait()
+}
+
+func (s *Server) pushRunningCost(ctx context.Context, billing ports.BillingService, cpID, ocppTxID string) {
+	if s.txService == nil {
+		return
+	}
+
+	txID := s.domainTransactionID(ctx, cpID, ocppTxID)
+	if txID == "" {
+		return
+	}
+	tx, err := s.txService.GetTransaction(ctx, txID)
+	if err != nil || tx == nil || tx.Status != domain.TransactionStatusStarted {
+		return
+	}
+
+	cost, err := billing.CalculateCost(ctx, runningTransaction(tx))
+	if err != nil {
+		s.log.Warn("Failed to calculate running cost", zap.String("txID", tx.ID), zap.Error(err))
+		return
+	}
+
+	if err := s.CostUpdated(ctx, cpID, ocppTxID, cost); err != nil {
+		s.log.Warn("Failed to push running cost",
+			zap.String("chargePointID", cpID),
+			zap.String("transactionId", ocppTxID),
+			zap.Error(err),
+		)
+	}
+}
+
+// domainTransactionID maps an OCPP transactionId to the transaction created
+// when it started, looking it up by the transactionId stored with it when the
+// sequence is not in memory, e.g. after a restart. It returns "" when the
+// transaction is unknown.
+func (s *Server) domainTransactionID(ctx context.Context, cpID, ocppTxID string) string {
+	s.seqMu.Lock()
+	seq, ok := s.txSequences[sequenceKey(cpID, ocppTxID)]
+	s.seqMu.Unlock()
+	if ok {
+		seq.mu.Lock()
+		txID := seq.txID
+		seq.mu.Unlock()
+		if txID != "" {
+			return txID
+		}
+	}
+
+	linker, ok := s.txService.(ports.OCPPTransactionLinker)
+	if !ok {
+		return ""
+	}
+	tx, err := linker.FindByOCPPTransaction(ctx, cpID, ocppTxID)
+	if err != nil {
+		s.log.Warn("Failed to look up transaction",
+			zap.String("chargePointID", cpID),
+			zap.String("transactionId", ocppTxID),
+			zap.Error(err),
+		)
+		return ""
+	}
+	if tx == nil {
+		return ""
+	}
+	return tx.ID
+}
+
+// runningTransaction returns a copy of an active transaction with the energy
+// delivered so far, which is only totalled when the transaction stops
+func runningTransaction(tx *domain.Transaction) *domain.Transaction {
+	running := *tx
+	if running.TotalEnergy == 0 && running.MeterStop > running.MeterStart {
+		running.TotalEnergy = running.MeterStop - running.MeterStart
+	}
+	return &running
+}
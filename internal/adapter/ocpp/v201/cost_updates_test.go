@@ -0,0 +1,136 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// flatRateBilling charges a fixed price per kWh
+type flatRateBilling struct {
+	pricePerKWh float64
+}
+
+func (b *flatRateBilling) CalculateCost(ctx context.Context, tx *domain.Transaction) (float64, error) {
+	return float64(tx.TotalEnergy) / 1000 * b.pricePerKWh, nil
+}
+
+func (b *flatRateBilling) ProcessPayment(ctx context.Context, tx *domain.Transaction) error {
+	return nil
+}
+
+func (b *flatRateBilling) GetPricePerKWh(ctx context.Context) float64 {
+	return b.pricePerKWh
+}
+
+func TestStartCostUpdates_PushesRunningCost(t *testing.T) {
+	// Arrange: 5 kWh delivered so far at R$ 2.00/kWh
+	txService := &mocks.MockTransactionService{
+		GetTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			if id != "tx-domain-1" {
+				return nil, nil
+			}
+			return &domain.Transaction{ID: id, Status: domain.TransactionStatusStarted, MeterStart: 1000, MeterStop: 6000}, nil
+		},
+	}
+	srv := NewServer(nil, txService, zap.NewNop())
+	defer srv.Stop()
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/CP-COST", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	var mu sync.Mutex
+	var received []CostUpdatedRequest
+	var times []time.Time
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var raw []json.RawMessage
+			json.Unmarshal(data, &raw)
+			var msgID, action string
+			json.Unmarshal(raw[1], &msgID)
+			json.Unmarshal(raw[2], &action)
+			if action == "CostUpdated" {
+				var req CostUpdatedRequest
+				json.Unmarshal(raw[3], &req)
+				mu.Lock()
+				received = append(received, req)
+				times = append(times, time.Now())
+				mu.Unlock()
+			}
+			out, _ := json.Marshal([]interface{}{CallResult, msgID, CostUpdatedResponse{}})
+			conn.WriteMessage(websocket.TextMessage, out)
+		}
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !srv.IsConnected("CP-COST") {
+		if time.Now().After(deadline) {
+			t.Fatal("charge point did not register")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	seq := srv.transactionSequence("CP-COST", "OCPP-TX-1")
+	seq.txID = "tx-domain-1"
+	srv.trackEVSETransaction("CP-COST", 1, "OCPP-TX-1")
+
+	// Act
+	interval := 100 * time.Millisecond
+	started := time.Now()
+	srv.StartCostUpdates(&flatRateBilling{pricePerKWh: 2.0}, interval)
+	time.Sleep(350 * time.Millisecond)
+
+	// Assert
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) < 2 || len(received) > 4 {
+		t.Fatalf("expected about 3 pushes in 350ms at a 100ms interval, got %d", len(received))
+	}
+	if first := times[0].Sub(started); first < interval/2 {
+		t.Errorf("expected the first push after one interval, got %v", first)
+	}
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < interval/2 {
+			t.Errorf("expected pushes about %v apart, got %v", interval, gap)
+		}
+	}
+	for _, req := range received {
+		if req.TransactionId != "OCPP-TX-1" || req.TotalCost != 10.0 {
+			t.Errorf("expected cost 10.00 for OCPP-TX-1, got %+v", req)
+		}
+	}
+}
+
+func TestRunningTransaction(t *testing.T) {
+	tx := &domain.Transaction{MeterStart: 1200, MeterStop: 4700}
+
+	running := runningTransaction(tx)
+
+	if running.TotalEnergy != 3500 {
+		t.Errorf("expected 3500 Wh delivered so far, got %d", running.TotalEnergy)
+	}
+	if tx.TotalEnergy != 0 {
+		t.Error("expected the original transaction to be left untouched")
+	}
+}
@@ -0,0 +1,161 @@
+package v201
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Ensure Server implements ports.ChargingLimitSource
+var _ ports.ChargingLimitSource = (*Server)(nil)
+
+// chargingLimitSourceCSO is the ChargingLimitSource of profiles we set
+const chargingLimitSourceCSO = "CSO"
+
+// CurrentChargingLimits works out the limit each EVSE of a charge point is
+// held to now, combining its profiles the way the station does. Profiles we
+// set are taken as installed; the last ReportChargingProfiles adds those set
+// by others, e.g. an EMS. EVSE 0 is reported only when the station's EVSEs
+// are unknown.
+func (s *Server) CurrentChargingLimits(ctx context.Context, chargePointID string) ([]domain.ChargingLimit, error) {
+	profiles := s.installedProfiles(chargePointID)
+	ours := make(map[int]bool, len(profiles))
+	for _, installed := range profiles {
+		ours[installed.Profile.Id] = true
+	}
+	if s.profileReportRepo != nil {
+		report, err := s.profileReportRepo.GetByChargePointID(ctx, chargePointID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get charging profile report: %w", err)
+		}
+		if report != nil {
+			for _, reported := range report.Profiles {
+				if !ours[reported.ID] {
+					profiles = append(profiles, reportedInstalledProfile(reported))
+				}
+			}
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+
+	var cp *domain.ChargePoint
+	if s.deviceService != nil {
+		cp, _ = s.deviceService.GetDevice(ctx, chargePointID)
+	}
+
+	evses := make(map[int]bool)
+	for _, installed := range profiles {
+		if installed.EvseID > 0 {
+			evses[installed.EvseID] = true
+		}
+	}
+	if cp != nil {
+		for _, c := range cp.Connectors {
+			evses[c.ConnectorID] = true
+		}
+	}
+	if len(evses) == 0 {
+		evses[0] = true
+	}
+	evseIDs := make([]int, 0, len(evses))
+	for evseID := range evses {
+		evseIDs = append(evseIDs, evseID)
+	}
+	sort.Ints(evseIDs)
+
+	now := time.Now()
+	if cp != nil {
+		now = now.In(cp.TimeZone())
+	}
+	var limits []domain.ChargingLimit
+	for _, evseID := range evseIDs {
+		var applicable []installedProfile
+		for _, installed := range profiles {
+			if installed.EvseID == evseID || installed.EvseID == 0 {
+				applicable = append(applicable, installed)
+			}
+		}
+		limitW, winner, ok := compositeLimitAt(applicable, now, 0)
+		if !ok {
+			continue
+		}
+
+		limit := domain.ChargingLimit{
+			EvseID:     evseID,
+			LimitW:     limitW,
+			RatedW:     ratedPowerW(cp, evseID),
+			ProfileID:  winner.Profile.Id,
+			Purpose:    winner.Profile.ChargingProfilePurpose,
+			StackLevel: winner.Profile.StackLevel,
+			Source:     winner.Source,
+		}
+		if limit.Source == "" {
+			limit.Source = chargingLimitSourceCSO
+		}
+		limit.Throttled = limit.RatedW == 0 || limit.LimitW < limit.RatedW
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// ratedPowerW returns the rated power of an EVSE, or of the whole station
+// for EVSE 0, in W. It is 0 when unknown.
+func ratedPowerW(cp *domain.ChargePoint, evseID int) float64 {
+	if cp == nil {
+		return 0
+	}
+	total := 0.0
+	for _, c := range cp.Connectors {
+		if evseID == 0 || c.ConnectorID == evseID {
+			total += c.MaxPowerKW * 1000
+		}
+	}
+	return total
+}
+
+// reportedInstalledProfile turns a profile a charge point reported back
+// into the profile it has installed
+func reportedInstalledProfile(reported domain.ReportedChargingProfile) installedProfile {
+	profile := ChargingProfile{
+		Id:                     reported.ID,
+		StackLevel:             reported.StackLevel,
+		ChargingProfilePurpose: reported.Purpose,
+		ChargingProfileKind:    reported.Kind,
+		RecurrencyKind:         reported.RecurrencyKind,
+		ValidFrom:              formatTimestamp(reported.ValidFrom),
+		ValidTo:                formatTimestamp(reported.ValidTo),
+	}
+	for _, schedule := range reported.Schedules {
+		cs := ChargingSchedule{
+			Id:               schedule.ID,
+			StartSchedule:    formatTimestamp(schedule.StartSchedule),
+			Duration:         schedule.Duration,
+			ChargingRateUnit: schedule.ChargingRateUnit,
+			MinChargingRate:  schedule.MinChargingRate,
+		}
+		for _, period := range schedule.Periods {
+			cs.ChargingSchedulePeriod = append(cs.ChargingSchedulePeriod, ChargingSchedulePeriod{
+				StartPeriod:  period.StartPeriod,
+				Limit:        period.Limit,
+				NumberPhases: period.NumberPhases,
+			})
+		}
+		profile.ChargingSchedule = append(profile.ChargingSchedule, cs)
+	}
+	return installedProfile{EvseID: reported.EvseID, Profile: profile, Requested: profile, Source: reported.ChargingLimitSource}
+}
+
+// formatTimestamp formats an optional timestamp as RFC 3339
+func formatTimestamp(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	ts := t.Format(time.RFC3339)
+	return &ts
+}
@@ -0,0 +1,87 @@
+package v201
+
+import (
+	"context"
+	"testing"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+func TestCurrentChargingLimits_ReflectsSetTxProfile(t *testing.T) {
+	// Arrange: a 7.4 kW TxProfile set on EVSE 1 of two 22 kW EVSEs
+	srv := newProfileTestServer()
+	defer srv.Stop()
+	srv.recordChargingProfile("CP-001", 1, txProfile(7, 1, ChargingSchedulePeriod{StartPeriod: 0, Limit: 7400}))
+
+	// Act
+	limits, err := srv.CurrentChargingLimits(context.Background(), "CP-001")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(limits) != 1 {
+		t.Fatalf("expected only EVSE 1 to be limited, got %+v", limits)
+	}
+	want := domain.ChargingLimit{
+		EvseID: 1, LimitW: 7400, RatedW: 22000, Throttled: true,
+		ProfileID: 7, Purpose: "TxProfile", StackLevel: 1, Source: "CSO",
+	}
+	if limits[0] != want {
+		t.Errorf("expected %+v, got %+v", want, limits[0])
+	}
+}
+
+func TestCurrentChargingLimits_CombinesReportedProfiles(t *testing.T) {
+	// Arrange: an EMS caps the whole station at 11 kW; our TxProfile holds
+	// EVSE 1 lower still
+	srv := newProfileTestServer()
+	defer srv.Stop()
+	reports := memory.NewChargingProfileReportRepository()
+	srv.SetChargingProfileReportRepository(reports)
+	reports.Save(context.Background(), &domain.ChargingProfileReport{
+		ChargePointID: "CP-001",
+		Profiles: []domain.ReportedChargingProfile{{
+			ID: 90, EvseID: 0, ChargingLimitSource: "EMS", StackLevel: 0,
+			Purpose: "ChargingStationExternalConstraints", Kind: "Relative",
+			Schedules: []domain.ReportedChargingSchedule{{
+				ID: 90, ChargingRateUnit: "A",
+				Periods: []domain.ReportedSchedulePeriod{{StartPeriod: 0, Limit: 16}},
+			}},
+		}},
+	})
+	srv.recordChargingProfile("CP-001", 1, txProfile(7, 1, ChargingSchedulePeriod{StartPeriod: 0, Limit: 7400}))
+
+	// Act
+	limits, err := srv.CurrentChargingLimits(context.Background(), "CP-001")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(limits) != 2 {
+		t.Fatalf("expected both EVSEs to be limited, got %+v", limits)
+	}
+	if limits[0].EvseID != 1 || limits[0].LimitW != 7400 || limits[0].Purpose != "TxProfile" || limits[0].Source != "CSO" {
+		t.Errorf("expected EVSE 1 held to 7400 W by our TxProfile, got %+v", limits[0])
+	}
+	// 16 A over three phases at 230 V
+	if limits[1].EvseID != 2 || limits[1].LimitW != 11040 || limits[1].ProfileID != 90 || limits[1].Source != "EMS" || !limits[1].Throttled {
+		t.Errorf("expected EVSE 2 throttled to 11040 W by the EMS, got %+v", limits[1])
+	}
+}
+
+func TestCurrentChargingLimits_NoProfiles(t *testing.T) {
+	// Arrange
+	srv := newProfileTestServer()
+	defer srv.Stop()
+
+	// Act
+	limits, err := srv.CurrentChargingLimits(context.Background(), "CP-001")
+
+	// Assert
+	if err != nil || len(limits) != 0 {
+		t.Errorf("expected no limits, got %+v, %v", limits, err)
+	}
+}
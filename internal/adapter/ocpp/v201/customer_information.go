@@ -0,0 +1,212 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// CustomerReportRetention is how long assembled NotifyCustomerInformation
+// data is kept for CustomerInformationReport. It holds personal data, so it
+// is not kept longer than needed to answer the request.
+const CustomerReportRetention = time.Hour
+
+// CustomerInformationReport is the customer data a charge point reported
+type CustomerInformationReport struct {
+	ChargePointID string
+	RequestID     int
+	Data          string
+	GeneratedAt   time.Time
+}
+
+// customerInfoAssembly collects the parts of a multi-part NotifyCustomerInformation
+type customerInfoAssembly struct {
+	parts     map[int]string // seqNo → data
+	lastSeqNo int            // seqNo of the part with tbc=false, -1 until received
+	startedAt time.Time
+	report    *CustomerInformationReport // Set once every part arrived
+}
+
+// Ensure Server implements ports.CustomerDataEraser
+var _ ports.CustomerDataEraser = (*Server)(nil)
+
+// CustomerInformation asks a charge point to report and/or clear the data it
+// keeps about the holder of idToken. A report arrives asynchronously with one
+// or more NotifyCustomerInformation messages.
+func (s *Server) CustomerInformation(ctx context.Context, chargePointID string, requestID int, idToken string, report, clear bool) (*CustomerInformationResponse, error) {
+	if !report && !clear {
+		return nil, errors.New("customer information requires report or clear")
+	}
+
+	req := CustomerInformationRequest{
+		RequestId: requestID,
+		Report:    report,
+		Clear:     clear,
+		IdToken: &IdToken{
+			IdToken: idToken,
+			Type:    "ISO14443",
+		},
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "CustomerInformation", req)
+	if err != nil {
+		return nil, fmt.Errorf("customer information failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("customer information rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response CustomerInformationResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	s.log.Info("Customer information requested",
+		zap.String("chargePointID", chargePointID),
+		zap.Int("requestId", requestID),
+		zap.Bool("report", report),
+		zap.Bool("clear", clear),
+		zap.String("status", response.Status),
+	)
+
+	return &response, nil
+}
+
+// ClearCustomerData removes what a charge point keeps about idToken, such as
+// its authorization cache entry and local transaction history
+func (s *Server) ClearCustomerData(ctx context.Context, chargePointID, idToken string) error {
+	requestID := int(time.Now().UnixNano() % 1000000)
+	resp, err := s.CustomerInformation(ctx, chargePointID, requestID, idToken, false, true)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return fmt.Errorf("clear customer data not accepted: %s", resp.Status)
+	}
+	return nil
+}
+
+// CustomerInformationReport returns the assembled customer data of a request
+// once every NotifyCustomerInformation part has arrived
+func (s *Server) CustomerInformationReport(chargePointID string, requestID int) (*CustomerInformationReport, bool) {
+	s.customerInfoMu.Lock()
+	defer s.customerInfoMu.Unlock()
+
+	assembly, ok := s.customerInfo[fmt.Sprintf("%s:%d", chargePointID, requestID)]
+	if !ok || assembly.report == nil {
+		return nil, false
+	}
+	report := *assembly.report
+	return &report, true
+}
+
+// handleNotifyCustomerInformation buffers data parts until the final one
+// (tbc=false) and all preceding sequence numbers have arrived
+func (s *Server) handleNotifyCustomerInformation(cpID string, payload []byte) (*NotifyCustomerInformationResponse, error) {
+	var req NotifyCustomerInformationRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	// The data itself is personal and never logged
+	s.log.Info("Notify Customer Information",
+		zap.String("cpID", cpID),
+		zap.Int("requestId", req.RequestId),
+		zap.Int("seqNo", req.SeqNo),
+		zap.Bool("toBeContinued", req.Tbc),
+	)
+
+	key := fmt.Sprintf("%s:%d", cpID, req.RequestId)
+	now := time.Now()
+
+	s.customerInfoMu.Lock()
+	defer s.customerInfoMu.Unlock()
+
+	for k, a := range s.customerInfo {
+		if a.report == nil && now.Sub(a.startedAt) > ReportAssemblyTimeout {
+			s.log.Warn("Discarding incomplete customer information", zap.String("report", k))
+			delete(s.customerInfo, k)
+		} else if a.report != nil && now.Sub(a.startedAt) > CustomerReportRetention {
+			delete(s.customerInfo, k)
+		}
+	}
+
+	assembly, ok := s.customerInfo[key]
+	if !ok {
+		assembly = &customerInfoAssembly{
+			parts:     make(map[int]string),
+			lastSeqNo: -1,
+			startedAt: now,
+		}
+		s.customerInfo[key] = assembly
+	}
+	if assembly.report != nil {
+		// A late duplicate of an already assembled report
+		return &NotifyCustomerInformationResponse{}, nil
+	}
+
+	assembly.parts[req.SeqNo] = req.Data
+	if !req.Tbc {
+		assembly.lastSeqNo = req.SeqNo
+	}
+
+	data, complete := assembly.assemble()
+	if !complete {
+		return &NotifyCustomerInformationResponse{}, nil
+	}
+
+	report := &CustomerInformationReport{
+		ChargePointID: cpID,
+		RequestID:     req.RequestId,
+		Data:          data,
+		GeneratedAt:   now,
+	}
+	if ts, err := time.Parse(time.RFC3339, req.GeneratedAt); err == nil {
+		report.GeneratedAt = ts
+	}
+	assembly.report = report
+	assembly.parts = nil
+
+	s.log.Info("Customer information received",
+		zap.String("cpID", cpID),
+		zap.Int("requestId", req.RequestId),
+		zap.Int("bytes", len(data)),
+	)
+
+	return &NotifyCustomerInformationResponse{}, nil
+}
+
+// assemble concatenates the data ordered by seqNo once every part from 0 up
+// to the final one has been received
+func (a *customerInfoAssembly) assemble() (string, bool) {
+	if a.lastSeqNo < 0 || len(a.parts) < a.lastSeqNo+1 {
+		return "", false
+	}
+
+	seqNos := make([]int, 0, len(a.parts))
+	for seq := range a.parts {
+		if seq > a.lastSeqNo {
+			continue
+		}
+		seqNos = append(seqNos, seq)
+	}
+	if len(seqNos) != a.lastSeqNo+1 {
+		return "", false
+	}
+	sort.Ints(seqNos)
+
+	var data strings.Builder
+	for _, seq := range seqNos {
+		data.WriteString(a.parts[seq])
+	}
+	return data.String(), true
+}
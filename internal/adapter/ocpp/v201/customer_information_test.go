@@ -0,0 +1,151 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+func customerInfoPart(t *testing.T, requestID, seqNo int, tbc bool, data string) []byte {
+	t.Helper()
+	payload, err := json.Marshal(NotifyCustomerInformationRequest{
+		Data:        data,
+		Tbc:         tbc,
+		SeqNo:       seqNo,
+		GeneratedAt: "2026-10-16T12:00:00Z",
+		RequestId:   requestID,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal part: %v", err)
+	}
+	return payload
+}
+
+func TestServer_CustomerInformationReportsAndAssembles(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/CP-001", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	// The charge point accepts, then reports its data in two parts sent out of order
+	requests := make(chan CustomerInformationRequest, 1)
+	go func() {
+		for {
+			var raw []json.RawMessage
+			if err := conn.ReadJSON(&raw); err != nil {
+				return
+			}
+			var msgType MessageType
+			var msgID string
+			json.Unmarshal(raw[0], &msgType)
+			json.Unmarshal(raw[1], &msgID)
+			if msgType != Call {
+				continue
+			}
+			var req CustomerInformationRequest
+			json.Unmarshal(raw[3], &req)
+			conn.WriteJSON([]interface{}{CallResult, msgID, CustomerInformationResponse{Status: "Accepted"}})
+			requests <- req
+
+			conn.WriteJSON([]interface{}{Call, "n-2", "NotifyCustomerInformation", json.RawMessage(customerInfoPart(t, req.RequestId, 1, false, "tx=42;"))})
+			conn.WriteJSON([]interface{}{Call, "n-1", "NotifyCustomerInformation", json.RawMessage(customerInfoPart(t, req.RequestId, 0, true, "idToken=ABC;"))})
+		}
+	}()
+
+	// Act
+	resp, err := srv.CustomerInformation(context.Background(), "CP-001", 7, "ABC", true, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Status != "Accepted" {
+		t.Errorf("expected Accepted, got %s", resp.Status)
+	}
+	req := <-requests
+	if req.RequestId != 7 || !req.Report || req.Clear {
+		t.Errorf("expected a report-only request 7, got %+v", req)
+	}
+	if req.IdToken == nil || req.IdToken.IdToken != "ABC" {
+		t.Errorf("expected idToken ABC, got %+v", req.IdToken)
+	}
+
+	var report *CustomerInformationReport
+	deadline := time.Now().Add(2 * time.Second)
+	for report == nil && time.Now().Before(deadline) {
+		report, _ = srv.CustomerInformationReport("CP-001", 7)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if report == nil {
+		t.Fatal("expected the report to be assembled")
+	}
+	if report.Data != "idToken=ABC;tx=42;" {
+		t.Errorf("expected the parts in seqNo order, got %q", report.Data)
+	}
+}
+
+func TestServer_NotifyCustomerInformationWaitsForEveryPart(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+
+	// Act: request 1 misses seqNo 1; request 2 is complete in one part
+	steps := [][]byte{
+		customerInfoPart(t, 1, 0, true, "a"),
+		customerInfoPart(t, 1, 2, false, "c"),
+		customerInfoPart(t, 2, 0, false, "single"),
+	}
+	for i, step := range steps {
+		if _, err := srv.handleNotifyCustomerInformation("CP-001", step); err != nil {
+			t.Fatalf("part %d: unexpected error: %v", i, err)
+		}
+	}
+
+	// Assert
+	if _, ok := srv.CustomerInformationReport("CP-001", 1); ok {
+		t.Fatal("expected no report while seqNo 1 is missing")
+	}
+	if report, ok := srv.CustomerInformationReport("CP-001", 2); !ok || report.Data != "single" {
+		t.Errorf("expected request 2 to be complete, got %+v", report)
+	}
+
+	if _, err := srv.handleNotifyCustomerInformation("CP-001", customerInfoPart(t, 1, 1, true, "b")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, ok := srv.CustomerInformationReport("CP-001", 1)
+	if !ok || report.Data != "abc" {
+		t.Errorf("expected abc once the gap is filled, got %+v", report)
+	}
+	if !report.GeneratedAt.Equal(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the station's generatedAt, got %v", report.GeneratedAt)
+	}
+}
+
+func TestServer_CustomerInformationRequiresAnAction(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+
+	// Act
+	_, err := srv.CustomerInformation(context.Background(), "CP-001", 1, "ABC", false, false)
+
+	// Assert
+	if err == nil {
+		t.Error("expected an error without report or clear")
+	}
+}
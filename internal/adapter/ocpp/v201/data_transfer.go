@@ -0,0 +1,95 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// DataTransfer statuses
+const (
+	DataTransferAccepted         = "Accepted"
+	DataTransferRejected         = "Rejected"
+	DataTransferUnknownMessageID = "UnknownMessageId"
+	DataTransferUnknownVendorID  = "UnknownVendorId"
+)
+
+// DataTransferHandler handles inbound DataTransfer messages for one vendor.
+// Returning an error answers Rejected; handlers that do not know messageID
+// should answer UnknownMessageId themselves.
+type DataTransferHandler func(ctx context.Context, chargePointID, messageID string, data json.RawMessage) (*DataTransferResponse, error)
+
+// RegisterDataTransferHandler routes DataTransfer messages with vendorID to
+// handler, replacing any handler registered for the same vendor
+func (s *Server) RegisterDataTransferHandler(vendorID string, handler DataTransferHandler) {
+	s.dataTransferMu.Lock()
+	s.dataTransferHandlers[vendorID] = handler
+	s.dataTransferMu.Unlock()
+}
+
+// --- Data Transfer Command (CSMS → Charge Point) ---
+
+// DataTransfer sends a vendor-specific message to a charge point
+func (s *Server) DataTransfer(ctx context.Context, chargePointID, vendorID, messageID string, data json.RawMessage) (*DataTransferResponse, error) {
+	req := DataTransferRequest{
+		MessageId: messageID,
+		Data:      data,
+		VendorId:  vendorID,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "DataTransfer", req)
+	if err != nil {
+		return nil, fmt.Errorf("data transfer failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("data transfer rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response DataTransferResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// --- Data Transfer Handler (Charge Point → CSMS) ---
+
+func (s *Server) handleDataTransfer(chargePointID string, payload []byte) (*DataTransferResponse, error) {
+	var req DataTransferRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	s.dataTransferMu.RLock()
+	handler, ok := s.dataTransferHandlers[req.VendorId]
+	s.dataTransferMu.RUnlock()
+
+	if !ok {
+		s.log.Info("DataTransfer from unknown vendor",
+			zap.String("chargePointID", chargePointID),
+			zap.String("vendorId", req.VendorId),
+			zap.String("messageId", req.MessageId),
+		)
+		return &DataTransferResponse{Status: DataTransferUnknownVendorID}, nil
+	}
+
+	resp, err := handler(context.Background(), chargePointID, req.MessageId, req.Data)
+	if err != nil {
+		s.log.Warn("DataTransfer handler failed",
+			zap.String("chargePointID", chargePointID),
+			zap.String("vendorId", req.VendorId),
+			zap.String("messageId", req.MessageId),
+			zap.Error(err),
+		)
+		return &DataTransferResponse{Status: DataTransferRejected}, nil
+	}
+	if resp == nil {
+		resp = &DataTransferResponse{Status: DataTransferAccepted}
+	}
+
+	return resp, nil
+}
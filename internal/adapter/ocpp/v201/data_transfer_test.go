@@ -0,0 +1,95 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func dataTransferPayload(t *testing.T, vendorID, messageID, data string) []byte {
+	t.Helper()
+
+	payload, err := json.Marshal(DataTransferRequest{
+		VendorId:  vendorID,
+		MessageId: messageID,
+		Data:      json.RawMessage(data),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal DataTransfer: %v", err)
+	}
+	return payload
+}
+
+func TestHandleDataTransfer_RegisteredVendor(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+
+	var gotChargePoint, gotMessage string
+	var gotData json.RawMessage
+	srv.RegisterDataTransferHandler("com.acme", func(ctx context.Context, chargePointID, messageID string, data json.RawMessage) (*DataTransferResponse, error) {
+		gotChargePoint, gotMessage, gotData = chargePointID, messageID, data
+		return &DataTransferResponse{Status: DataTransferAccepted, Data: json.RawMessage(`{"ack":true}`)}, nil
+	})
+
+	// Act
+	resp, err := srv.handleDataTransfer("CP001", dataTransferPayload(t, "com.acme", "LedColor", `{"color":"blue"}`))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Status != DataTransferAccepted {
+		t.Errorf("expected status Accepted, got %s", resp.Status)
+	}
+	if string(resp.Data) != `{"ack":true}` {
+		t.Errorf("expected handler data in response, got %s", resp.Data)
+	}
+	if gotChargePoint != "CP001" || gotMessage != "LedColor" || string(gotData) != `{"color":"blue"}` {
+		t.Errorf("expected handler to receive CP001/LedColor/{\"color\":\"blue\"}, got %s/%s/%s", gotChargePoint, gotMessage, gotData)
+	}
+}
+
+func TestHandleDataTransfer_UnknownVendor(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.RegisterDataTransferHandler("com.acme", func(ctx context.Context, chargePointID, messageID string, data json.RawMessage) (*DataTransferResponse, error) {
+		t.Error("expected the acme handler not to be called")
+		return nil, nil
+	})
+
+	// Act
+	resp, err := srv.handleDataTransfer("CP001", dataTransferPayload(t, "org.other", "Ping", ""))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Status != DataTransferUnknownVendorID {
+		t.Errorf("expected status UnknownVendorId, got %s", resp.Status)
+	}
+}
+
+func TestHandleDataTransfer_HandlerError(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.RegisterDataTransferHandler("com.acme", func(ctx context.Context, chargePointID, messageID string, data json.RawMessage) (*DataTransferResponse, error) {
+		return nil, errors.New("malformed data")
+	})
+
+	// Act
+	resp, err := srv.handleDataTransfer("CP001", dataTransferPayload(t, "com.acme", "LedColor", `"oops"`))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Status != DataTransferRejected {
+		t.Errorf("expected status Rejected, got %s", resp.Status)
+	}
+}
@@ -0,0 +1,79 @@
+package v201
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrServerDraining is returned for commands issued while the server drains
+var ErrServerDraining = errors.New("OCPP server is draining")
+
+// drainPollInterval is how often Drain checks for outstanding work
+const drainPollInterval = 20 * time.Millisecond
+
+// Drain shuts the server down gracefully. New connections are refused and no
+// new commands are sent, while connected stations keep being served so the
+// messages already on their way (a final TransactionEvent, the CallResult of
+// a command) are handled. Once nothing is in flight, or ctx is done, every
+// connection is closed as Stop does. It returns ctx.Err() when it gave up
+// waiting.
+func (s *Server) Drain(ctx context.Context) error {
+	if !s.draining.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	s.mu.RLock()
+	httpServer := s.httpServer
+	s.mu.RUnlock()
+	if httpServer != nil {
+		// Closes the listener; upgraded connections are not tracked by
+		// http.Server and stay open
+		if err := httpServer.Shutdown(ctx); err != nil {
+			s.log.Warn("Failed to stop accepting OCPP connections", zap.Error(err))
+		}
+	}
+
+	s.log.Info("Draining OCPP server",
+		zap.Int("connections", len(s.GetConnectedClients())),
+		zap.Int("pending_requests", s.GetPendingRequestCount()),
+	)
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for s.inflight.Load() > 0 || s.GetPendingRequestCount() > 0 {
+		select {
+		case <-ctx.Done():
+			s.log.Warn("OCPP drain timed out, closing connections",
+				zap.Int64("inflight_messages", s.inflight.Load()),
+				zap.Int("pending_requests", s.GetPendingRequestCount()),
+			)
+			s.Stop()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	s.Stop()
+	return nil
+}
+
+// IsDraining reports whether Drain has been called
+func (s *Server) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// rejectWhileDraining answers a handshake with 503 so the station retries
+// against another instance
+func (s *Server) rejectWhileDraining(w http.ResponseWriter) bool {
+	if !s.draining.Load() {
+		return false
+	}
+	w.Header().Set("Retry-After", "30")
+	http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+	return true
+}
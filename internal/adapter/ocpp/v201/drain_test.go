@@ -0,0 +1,157 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// readCall waits for the next Call sent to the charge point and returns its message ID
+func readCall(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	for {
+		var raw []json.RawMessage
+		if err := conn.ReadJSON(&raw); err != nil {
+			t.Fatalf("failed to read call: %v", err)
+		}
+		var msgType MessageType
+		var msgID string
+		json.Unmarshal(raw[0], &msgType)
+		json.Unmarshal(raw[1], &msgID)
+		if msgType == Call {
+			return msgID
+		}
+	}
+}
+
+// waitUntil polls cond for up to two seconds
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestServer_DrainCompletesInFlightRequests(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+	url := "ws" + strings.TrimPrefix(httpSrv.URL, "http") + "/ocpp/"
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial(url+"CP-001", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	waitUntil(t, "CP-001 is registered", func() bool { return srv.IsConnected("CP-001") })
+
+	type result struct {
+		resp *CommandResponse
+		err  error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		resp, err := srv.SendCommand(context.Background(), "CP-001", "Reset", map[string]string{"type": "OnIdle"})
+		inFlight <- result{resp, err}
+	}()
+	msgID := readCall(t, conn)
+
+	// Act
+	drained := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		drained <- srv.Drain(ctx)
+	}()
+	waitUntil(t, "the server drains", srv.IsDraining)
+
+	// Assert: nothing new gets in while draining
+	if _, err := srv.SendCommand(context.Background(), "CP-001", "Reset", nil); !errors.Is(err, ErrServerDraining) {
+		t.Errorf("expected ErrServerDraining for a new command, got %v", err)
+	}
+	if _, resp, err := dialer.Dial(url+"CP-002", nil); err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected a new connection to be refused with 503, got %v", err)
+	}
+
+	// The station still talks to the draining server
+	conn.WriteJSON([]interface{}{Call, "hb-1", "Heartbeat", map[string]interface{}{}})
+	var raw []json.RawMessage
+	if err := conn.ReadJSON(&raw); err != nil {
+		t.Fatalf("expected the heartbeat to be answered while draining, got %v", err)
+	}
+	select {
+	case err := <-drained:
+		t.Fatalf("expected drain to wait for the pending command, returned %v", err)
+	default:
+	}
+
+	conn.WriteJSON([]interface{}{CallResult, msgID, map[string]string{"status": "Accepted"}})
+
+	r := <-inFlight
+	if r.err != nil || r.resp == nil || !r.resp.Success {
+		t.Errorf("expected the in-flight command to complete, got %+v, %v", r.resp, r.err)
+	}
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Errorf("expected a clean drain, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected drain to finish once nothing was in flight")
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("expected the connection to be closed after draining")
+	}
+}
+
+func TestServer_DrainTimesOut(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/CP-001", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	waitUntil(t, "CP-001 is registered", func() bool { return srv.IsConnected("CP-001") })
+
+	// The station never answers
+	inFlight := make(chan *CommandResponse, 1)
+	go func() {
+		resp, _ := srv.SendCommand(context.Background(), "CP-001", "Reset", nil)
+		inFlight <- resp
+	}()
+	readCall(t, conn)
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = srv.Drain(ctx)
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+	resp := <-inFlight
+	if resp == nil || resp.Success || resp.Error == nil || resp.Error.Code != "ServerShutdown" {
+		t.Errorf("expected the abandoned command to fail with ServerShutdown, got %+v", resp)
+	}
+}
@@ -0,0 +1,39 @@
+package v201
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// syncFirmwareVersion records the boot info of a station that left its
+// firmware version out of the BootNotification, reading the version from the
+// ChargingStation component with GetVariables. If the station does not
+// report it the rest of the boot info is still stored.
+func (s *Server) syncFirmwareVersion(chargePointID string, info domain.BootInfo) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCommandTimeout)
+	defer cancel()
+
+	results, err := s.ReadVariables(ctx, chargePointID, []GetVariableData{{
+		Component:     Component{Name: "ChargingStation"},
+		Variable:      Variable{Name: "FirmwareVersion"},
+		AttributeType: "Actual",
+	}}, true)
+	if err != nil {
+		s.log.Warn("Failed to read firmware version",
+			zap.String("chargePointID", chargePointID),
+			zap.Error(err),
+		)
+	} else if len(results) == 1 && results[0].AttributeStatus == "Accepted" {
+		info.FirmwareVersion = results[0].AttributeValue
+	}
+
+	if err := s.deviceService.RecordBootInfo(ctx, chargePointID, info); err != nil {
+		s.log.Warn("Failed to store boot info",
+			zap.String("chargePointID", chargePointID),
+			zap.Error(err),
+		)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // handleAction routes the message to the appropriate handler based on the Action field
@@ -18,9 +19,33 @@ func (s *Server) handleAction(chargePointID string, msgID string, action string,
 
 	s.log.Info("Handling OCPP Action", zap.String("action", action), zap.String("chargePointID", chargePointID))
 
+	// The station waits for an answer to every call, even if a handler panics
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("Panic handling action",
+				zap.String("action", action),
+				zap.String("chargePointID", chargePointID),
+				zap.Any("panic", r),
+			)
+			s.sendError(chargePointID, msgID, "InternalError", "An internal error occurred", nil)
+		}
+	}()
+
+	if s.validateSchemas {
+		if violation := validatePayload(action, payload); violation != nil {
+			s.log.Warn("OCPP payload rejected by schema",
+				zap.String("action", action),
+				zap.String("chargePointID", chargePointID),
+				zap.String("violation", violation.Error()),
+			)
+			s.sendError(chargePointID, msgID, violation.Code, violation.Error(), violation.details())
+			return
+		}
+	}
+
 	switch action {
 	case "BootNotification":
-		responsePayload, err = s.handleBootNotification(payload)
+		responsePayload, err = s.handleBootNotification(chargePointID, payload)
 	case "Heartbeat":
 		responsePayload, err = s.handleHeartbeat(payload)
 	case "StatusNotification":
@@ -31,6 +56,8 @@ func (s *Server) handleAction(chargePointID string, msgID string, action string,
 		responsePayload, err = s.handleMeterValues(chargePointID, payload)
 	case "FirmwareStatusNotification":
 		responsePayload, err = s.handleFirmwareStatusNotification(chargePointID, payload)
+	case "PublishFirmwareStatusNotification":
+		responsePayload, err = s.handlePublishFirmwareStatusNotification(chargePointID, payload)
 	case "LogStatusNotification":
 		responsePayload, err = s.handleLogStatusNotification(chargePointID, payload)
 	case "NotifyEVChargingNeeds":
@@ -41,21 +68,52 @@ func (s *Server) handleAction(chargePointID string, msgID string, action string,
 		responsePayload, err = s.handleReportChargingProfiles(chargePointID, payload)
 	case "Authorize":
 		responsePayload, err = s.handleAuthorize(chargePointID, payload)
+	case "NotifyEvent":
+		responsePayload, err = s.handleNotifyEvent(chargePointID, payload)
+	case "NotifyReport":
+		responsePayload, err = s.handleNotifyReport(chargePointID, payload)
+	case "NotifyCustomerInformation":
+		responsePayload, err = s.handleNotifyCustomerInformation(chargePointID, payload)
+	case "ReservationStatusUpdate":
+		responsePayload, err = s.handleReservationStatusUpdate(chargePointID, payload)
+	case "DataTransfer":
+		responsePayload, err = s.handleDataTransfer(chargePointID, payload)
+	case "SignCertificate":
+		responsePayload, err = s.handleSignCertificate(chargePointID, payload)
 	default:
 		s.sendError(chargePointID, msgID, "NotImplemented", fmt.Sprintf("Action %s not implemented", action), nil)
 		return
 	}
 
 	if err != nil {
-		s.log.Error("Error handling action", zap.String("action", action), zap.Error(err))
-		s.sendError(chargePointID, msgID, "InternalError", "An internal error occurred", nil)
+		code, desc := callErrorFor(err)
+		s.log.Error("Error handling action", zap.String("action", action), zap.String("code", code), zap.Error(err))
+		s.sendError(chargePointID, msgID, code, desc, nil)
 		return
 	}
 
 	s.sendCallResult(chargePointID, msgID, responsePayload)
+
+	// Discover the device model once the charge point has been accepted
+	if action == "BootNotification" {
+		go s.requestInventoryIfMissing(chargePointID)
+	}
+	if resp, ok := responsePayload.(*BootNotificationResponse); ok && resp.unversioned != nil {
+		go s.syncFirmwareVersion(chargePointID, *resp.unversioned)
+	}
+
+	// CertificateSigned may only follow the SignCertificate response
+	if resp, ok := responsePayload.(*SignCertificateResponse); ok && resp.issued != nil {
+		go s.deliverCertificate(chargePointID, resp.issued)
+	}
 }
 
-func (s *Server) handleBootNotification(payload []byte) (*BootNotificationResponse, error) {
+// SetHeartbeatPolicy sets how heartbeat intervals are chosen at boot
+func (s *Server) SetHeartbeatPolicy(policy domain.HeartbeatPolicy) {
+	s.heartbeat = policy
+}
+
+func (s *Server) handleBootNotification(cpID string, payload []byte) (*BootNotificationResponse, error) {
 	var req BootNotificationRequest
 	if err := json.Unmarshal(payload, &req); err != nil {
 		return nil, err
@@ -66,10 +124,47 @@ func (s *Server) handleBootNotification(payload []byte) (*BootNotificationRespon
 
 	// In a real scenario, we would validate credentials here.
 
+	interval := s.heartbeat.IntervalFor(cpID, req.ChargingStation.VendorName)
+	var unversioned *domain.BootInfo
+	if s.deviceService != nil {
+		if err := s.deviceService.SetHeartbeatInterval(context.Background(), cpID, interval); err != nil {
+			s.log.Warn("Failed to store heartbeat interval",
+				zap.String("cpID", cpID),
+				zap.Int("interval", interval),
+				zap.Error(err),
+			)
+		}
+
+		info := domain.BootInfo{
+			Vendor:          req.ChargingStation.VendorName,
+			Model:           req.ChargingStation.Model,
+			SerialNumber:    req.ChargingStation.SerialNumber,
+			FirmwareVersion: req.ChargingStation.FirmwareVersion,
+		}
+		if info.FirmwareVersion == "" {
+			// Read from the device model instead, once the station has
+			// its answer and may take requests
+			unversioned = &info
+		} else if err := s.deviceService.RecordBootInfo(context.Background(), cpID, info); err != nil {
+			s.log.Warn("Failed to store boot info", zap.String("cpID", cpID), zap.Error(err))
+		}
+	}
+
+	if _, ok := s.siteCapacityW(cpID); ok {
+		// A rebooted station may have lost its max profile. Sent outside the
+		// read loop, which carries the station's answer.
+		go func() {
+			if err := s.ApplySiteCapacity(context.Background(), cpID); err != nil {
+				s.log.Warn("Failed to apply site capacity", zap.String("cpID", cpID), zap.Error(err))
+			}
+		}()
+	}
+
 	return &BootNotificationResponse{
 		CurrentTime: time.Now().Format(time.RFC3339),
-		Interval:    300,        // 5 minutes heartbeat
+		Interval:    interval,
 		Status:      "Accepted", // Accepted, Pending, Rejected
+		unversioned: unversioned,
 	}, nil
 }
 
@@ -88,113 +183,73 @@ func (s *Server) handleStatusNotification(cpID string, payload []byte) (*StatusN
 		return nil, err
 	}
 
-	s.log.Info("Status Notification", zap.String("cpID", cpID), zap.String("status", req.ConnectorStatus))
+	s.log.Info("Status Notification",
+		zap.String("cpID", cpID),
+		zap.Int("evseId", req.EvseId),
+		zap.Int("connectorId", req.ConnectorId),
+		zap.String("status", req.ConnectorStatus),
+	)
 
-	// Map OCPP status to Domain status
-	status := domain.ChargePointStatus(req.ConnectorStatus) // Simplified mapping
+	// OCPP 2.0.1 connector statuses share their names with domain statuses
+	status := domain.ChargePointStatus(req.ConnectorStatus)
+
+	// The device status is the rollup of its connectors
+	connectorID := connectorKey(req.EvseId, req.ConnectorId)
 
-	// Update device status in DB via Service
 	ctx := context.Background()
-	_ = s.deviceService.UpdateStatus(ctx, cpID, status)
+	if err := s.deviceService.UpdateConnectorStatus(ctx, cpID, connectorID, status); err != nil {
+		return nil, fmt.Errorf("failed to update connector status: %w", err)
+	}
+	s.trackConnectorFault(ctx, cpID, connectorID, status, req.Timestamp)
 
 	return &StatusNotificationResponse{}, nil
 }
 
-func (s *Server) handleTransactionEvent(cpID string, payload []byte) (*TransactionEventResponse, error) {
-	var req TransactionEventRequest
-	if err := json.Unmarshal(payload, &req); err != nil {
-		return nil, err
+// connectorKey is the connector ID an EVSE is stored under. Each EVSE is
+// modelled as one connector, so status notifications and transactions both
+// use the EVSE ID, falling back to the connector ID when no EVSE is given.
+func connectorKey(evseID, connectorID int) int {
+	if evseID != 0 {
+		return evseID
 	}
+	return connectorID
+}
 
-	ctx := context.Background()
-
-	switch req.EventType {
-	case "Started":
-		// User ID from IdToken
-		uID := "unknown"
-		idTag := ""
-		if req.IdToken != nil {
-			idTag = req.IdToken.IdToken
-			// In production, verify auth cache or service to get real UserUUID
-			uID = idTag // simplified
-		}
+// SetFaultService enables fault alerts for Faulted status notifications
+func (s *Server) SetFaultService(faults ports.FaultService) {
+	s.faults = faults
+}
 
-		connID := 1
-		if req.Evse != nil {
-			connID = req.Evse.ConnectorId
-		}
+// trackConnectorFault raises a fault alert when a connector becomes Faulted
+// and resolves it once the connector is Available again. OCPP 2.0.1 sends no
+// error code with the status; details arrive separately as NotifyEvent.
+func (s *Server) trackConnectorFault(ctx context.Context, cpID string, connectorID int, status domain.ChargePointStatus, timestamp string) {
+	if s.faults == nil {
+		return
+	}
 
-		tx, err := s.txService.StartTransaction(ctx, cpID, connID, uID, idTag)
-		if err != nil {
-			s.log.Error("Failed to start transaction", zap.Error(err))
-			return &TransactionEventResponse{
-				IdTokenInfo: &IdTokenInfo{Status: "Blocked"},
-			}, nil
+	switch status {
+	case domain.ChargePointStatusFaulted:
+		fault := domain.ConnectorFault{ChargePointID: cpID, ConnectorID: connectorID}
+		if ts, err := time.Parse(time.RFC3339, timestamp); err == nil {
+			fault.Timestamp = ts
 		}
-
-		s.log.Info("Transaction Started via OCPP",
-			zap.String("txID", tx.ID),
-			zap.String("chargePointID", cpID),
-			zap.String("userID", uID),
-		)
-
-	case "Updated":
-		// Handle meter values update during charging
-		if req.TransactionInfo.TransactionId != "" && len(req.MeterValue) > 0 {
-			s.log.Info("Transaction Updated - Meter Values",
-				zap.String("txID", req.TransactionInfo.TransactionId),
-				zap.Any("meterValues", req.MeterValue),
+		if _, err := s.faults.ReportFault(ctx, fault); err != nil {
+			s.log.Error("Failed to report connector fault",
+				zap.String("cpID", cpID),
+				zap.Int("connectorId", connectorID),
+				zap.Error(err),
 			)
-			// In production, update meter values in the transaction record
 		}
-
-	case "Ended":
-		txID := req.TransactionInfo.TransactionId
-		s.log.Info("Processing Transaction End", zap.String("txID", txID), zap.String("chargePointID", cpID))
-
-		// Try to find the transaction by the OCPP transaction ID
-		// If not found, try to find the active transaction for this charge point
-		tx, err := s.txService.GetTransaction(ctx, txID)
-		if err != nil || tx == nil {
-			// Fallback: find active transaction for this user/device
-			s.log.Warn("Transaction not found by ID, attempting to find active transaction",
-				zap.String("txID", txID),
-				zap.String("chargePointID", cpID),
+	case domain.ChargePointStatusAvailable:
+		if err := s.faults.ResolveFaults(ctx, cpID, connectorID); err != nil {
+			s.log.Warn("Failed to resolve connector fault",
+				zap.String("cpID", cpID),
+				zap.Int("connectorId", connectorID),
+				zap.Error(err),
 			)
-
-			// Get user ID from IdToken if available
-			userID := "unknown"
-			if req.IdToken != nil {
-				userID = req.IdToken.IdToken
-			}
-
-			// Try to stop any active charging for this user
-			if err := s.txService.StopActiveCharging(ctx, userID); err != nil {
-				s.log.Error("Failed to stop active charging", zap.Error(err))
-			} else {
-				s.log.Info("Transaction Ended via StopActiveCharging",
-					zap.String("userID", userID),
-					zap.String("chargePointID", cpID),
-				)
-			}
-		} else {
-			// Stop the specific transaction
-			stoppedTx, err := s.txService.StopTransaction(ctx, txID)
-			if err != nil {
-				s.log.Error("Failed to stop transaction", zap.Error(err), zap.String("txID", txID))
-			} else {
-				s.log.Info("Transaction Ended via OCPP",
-					zap.String("txID", stoppedTx.ID),
-					zap.Int("totalEnergy", stoppedTx.TotalEnergy),
-					zap.Float64("cost", stoppedTx.Cost),
-				)
-			}
 		}
 	}
-
-	return &TransactionEventResponse{
-		IdTokenInfo: &IdTokenInfo{Status: "Accepted"},
-	}, nil
 }
 
 func (s *Server) sendCallResult(id string, msgID string, payload interface{}) {
@@ -204,6 +259,9 @@ func (s *Server) sendCallResult(id string, msgID string, payload interface{}) {
 }
 
 func (s *Server) sendError(id string, msgID string, code string, desc string, details interface{}) {
+	if details == nil {
+		details = struct{}{} // errorDetails is an object, never null
+	}
 	response := []interface{}{CallError, msgID, code, desc, details}
 	data, _ := json.Marshal(response)
 	s.Send(id, data)
@@ -224,7 +282,13 @@ func (s *Server) handleMeterValues(cpID string, payload []byte) (*MeterValuesRes
 		zap.Int("meterValueCount", len(req.MeterValue)),
 	)
 
-	// Process meter values - in production, store these
+	// Live power and samples of the session in progress on this EVSE
+	if ocppTxID, ok := s.meterTransactionID(cpID, req.EvseId); ok {
+		txID := s.domainTransactionID(context.Background(), cpID, ocppTxID)
+		s.recordPower(context.Background(), txID, req.MeterValue)
+		s.storeMeterValues(context.Background(), cpID, txID, req.EvseId, req.MeterValue)
+	}
+
 	for _, mv := range req.MeterValue {
 		for _, sv := range mv.SampledValue {
 			s.log.Debug("Meter sample",
@@ -253,8 +317,12 @@ func (s *Server) handleFirmwareStatusNotification(cpID string, payload []byte) (
 		zap.Intp("requestId", req.RequestId),
 	)
 
-	// TODO: Update firmware service with status
-	// Could publish to NATS for real-time updates
+	s.trackFirmwareStatus(cpID, req.Status)
+	if s.firmware != nil {
+		if err := s.firmware.HandleStatusNotification(cpID, req.Status, req.RequestId); err != nil {
+			s.log.Warn("Failed to record firmware status", zap.String("cpID", cpID), zap.Error(err))
+		}
+	}
 
 	return &FirmwareStatusNotificationResponse{}, nil
 }
@@ -272,9 +340,66 @@ func (s *Server) handleLogStatusNotification(cpID string, payload []byte) (*LogS
 		zap.Intp("requestId", req.RequestId),
 	)
 
+	if req.RequestId != nil {
+		s.updateLogRequest(context.Background(), cpID, *req.RequestId, req.Status)
+	}
+
 	return &LogStatusNotificationResponse{}, nil
 }
 
+// handleReservationStatusUpdate processes a reservation that expired or was
+// removed on the charge point
+func (s *Server) handleReservationStatusUpdate(cpID string, payload []byte) (*ReservationStatusUpdateResponse, error) {
+	var req ReservationStatusUpdateRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Reservation Status Update",
+		zap.String("cpID", cpID),
+		zap.Int("reservationId", req.ReservationId),
+		zap.String("status", req.ReservationUpdateStatus),
+	)
+
+	if s.reservations != nil {
+		reservation, err := s.reservations.ApplyStationUpdate(context.Background(), cpID, req.ReservationId, req.ReservationUpdateStatus)
+		if err != nil {
+			s.log.Warn("Failed to reconcile reservation",
+				zap.String("cpID", cpID),
+				zap.Int("reservationId", req.ReservationId),
+				zap.Error(err),
+			)
+		} else if reservation == nil {
+			s.log.Warn("Status update for unknown reservation",
+				zap.String("cpID", cpID),
+				zap.Int("reservationId", req.ReservationId),
+			)
+		}
+	}
+
+	return &ReservationStatusUpdateResponse{}, nil
+}
+
+// SetReservationSync keeps reservations in line with station updates
+func (s *Server) SetReservationSync(reservations ports.ReservationSync) {
+	s.reservations = reservations
+}
+
+// linkReservation attaches a started transaction to a reservation the
+// station reported as used
+func (s *Server) linkReservation(ctx context.Context, cpID, idToken, txID string) {
+	if s.reservations == nil || idToken == "" {
+		return
+	}
+	if err := s.reservations.LinkTransaction(ctx, cpID, idToken, txID); err != nil {
+		s.log.Warn("Failed to link reservation",
+			zap.String("cpID", cpID),
+			zap.String("txID", txID),
+			zap.Error(err),
+		)
+	}
+}
+
 // handleNotifyEVChargingNeeds processes V2G charging needs from EV
 func (s *Server) handleNotifyEVChargingNeeds(cpID string, payload []byte) (*NotifyEVChargingNeedsResponse, error) {
 	var req NotifyEVChargingNeedsRequest
@@ -288,22 +413,39 @@ func (s *Server) handleNotifyEVChargingNeeds(cpID string, payload []byte) (*Noti
 		zap.String("energyTransfer", req.ChargingNeeds.RequestedEnergyTransfer),
 	)
 
+	s.v2g.ProcessChargingNeeds(cpID, &req)
+
 	// Check if this is a V2G capable EV (bidirectional)
-	isV2GCapable := req.ChargingNeeds.RequestedEnergyTransfer == "AC_BPT" ||
-		req.ChargingNeeds.RequestedEnergyTransfer == "DC_BPT"
+	if !isBPT(req.ChargingNeeds) {
+		return &NotifyEVChargingNeedsResponse{
+			Status: "Accepted",
+		}, nil
+	}
 
-	if isV2GCapable && req.ChargingNeeds.DCChargingParameters != nil {
-		dc := req.ChargingNeeds.DCChargingParameters
+	if dc := req.ChargingNeeds.DCChargingParameters; dc != nil {
 		s.log.Info("V2G Capable EV detected",
 			zap.String("cpID", cpID),
 			zap.Int("stateOfCharge", dc.StateOfCharge),
 			zap.Intp("evEnergyCapacity", dc.EVEnergyCapacity),
 			zap.Intp("evMaxDischargePower", dc.EVMaxDischargePower),
 		)
+	}
 
-		// TODO: Notify V2G service about available discharge capacity
+	schedule, err := s.planBPTSchedule(context.Background(), &req)
+	if err != nil {
+		s.log.Warn("Failed to plan BPT schedule", zap.String("cpID", cpID), zap.Error(err))
+	}
+	if schedule == nil {
+		// Without prices the EV keeps its own schedule
+		return &NotifyEVChargingNeedsResponse{
+			Status: "Accepted",
+		}, nil
 	}
 
+	// Pushed outside the read loop: SetChargingProfile waits for the
+	// charge point's reply, which arrives on this same connection
+	go s.pushBPTSchedule(context.Background(), cpID, req.EvseId, *schedule)
+
 	return &NotifyEVChargingNeedsResponse{
 		Status: "Accepted",
 	}, nil
@@ -327,26 +469,6 @@ func (s *Server) handleNotifyEVChargingSchedule(cpID string, payload []byte) (*N
 	}, nil
 }
 
-// handleReportChargingProfiles processes charging profiles report
-func (s *Server) handleReportChargingProfiles(cpID string, payload []byte) (*ReportChargingProfilesResponse, error) {
-	var req ReportChargingProfilesRequest
-	if err := json.Unmarshal(payload, &req); err != nil {
-		return nil, err
-	}
-
-	s.log.Info("Charging Profiles Report",
-		zap.String("cpID", cpID),
-		zap.Int("requestId", req.RequestId),
-		zap.Int("evseId", req.EvseId),
-		zap.Int("profileCount", len(req.ChargingProfile)),
-		zap.Bool("toBeContinued", req.Tbc),
-	)
-
-	// TODO: Store reported profiles
-
-	return &ReportChargingProfilesResponse{}, nil
-}
-
 // handleAuthorize processes authorization requests
 func (s *Server) handleAuthorize(cpID string, payload []byte) (*AuthorizeResponse, error) {
 	var req struct {
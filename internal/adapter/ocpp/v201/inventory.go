@@ -0,0 +1,234 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ReportAssemblyTimeout is how long partial NotifyReport messages are kept
+// while waiting for the remaining parts
+const ReportAssemblyTimeout = 5 * time.Minute
+
+// reportAssembly collects the parts of a multi-part NotifyReport
+type reportAssembly struct {
+	parts     map[int][]ReportData // seqNo → report data
+	lastSeqNo int                  // seqNo of the part with tbc=false, -1 until received
+	startedAt time.Time
+}
+
+// SetInventoryRepository enables storing NotifyReport inventories
+func (s *Server) SetInventoryRepository(repo ports.DeviceInventoryRepository) {
+	s.inventoryRepo = repo
+}
+
+// GetBaseReport requests a device model report; the charge point answers
+// asynchronously with one or more NotifyReport messages
+func (s *Server) GetBaseReport(ctx context.Context, chargePointID string, requestID int, reportBase string) (*GetBaseReportResponse, error) {
+	req := GetBaseReportRequest{
+		RequestId:  requestID,
+		ReportBase: reportBase, // ConfigurationInventory, FullInventory, SummaryInventory
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "GetBaseReport", req)
+	if err != nil {
+		return nil, fmt.Errorf("get base report failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("get base report rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response GetBaseReportResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// requestInventoryIfMissing asks a freshly booted charge point for its full
+// inventory unless one is already stored
+func (s *Server) requestInventoryIfMissing(chargePointID string) {
+	if s.inventoryRepo == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCommandTimeout)
+	defer cancel()
+
+	existing, err := s.inventoryRepo.GetByChargePointID(ctx, chargePointID)
+	if err != nil {
+		s.log.Warn("Failed to look up device inventory",
+			zap.String("chargePointID", chargePointID),
+			zap.Error(err),
+		)
+		return
+	}
+	if existing != nil {
+		return
+	}
+
+	requestID := int(time.Now().UnixNano() % 1000000)
+	resp, err := s.GetBaseReport(ctx, chargePointID, requestID, "FullInventory")
+	if err != nil {
+		s.log.Warn("Failed to request device inventory",
+			zap.String("chargePointID", chargePointID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.log.Info("Requested device inventory",
+		zap.String("chargePointID", chargePointID),
+		zap.Int("requestId", requestID),
+		zap.String("status", resp.Status),
+	)
+}
+
+// handleNotifyReport buffers report parts until the final one (tbc=false)
+// and all preceding sequence numbers have arrived, then stores the inventory
+func (s *Server) handleNotifyReport(cpID string, payload []byte) (*NotifyReportResponse, error) {
+	var req NotifyReportRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Notify Report",
+		zap.String("cpID", cpID),
+		zap.Int("requestId", req.RequestId),
+		zap.Int("seqNo", req.SeqNo),
+		zap.Int("itemCount", len(req.ReportData)),
+		zap.Bool("toBeContinued", req.Tbc),
+	)
+
+	key := fmt.Sprintf("%s:%d", cpID, req.RequestId)
+	now := time.Now()
+
+	s.reportMu.Lock()
+	for k, a := range s.reports {
+		if now.Sub(a.startedAt) > ReportAssemblyTimeout {
+			s.log.Warn("Discarding incomplete report", zap.String("report", k))
+			delete(s.reports, k)
+		}
+	}
+
+	assembly, ok := s.reports[key]
+	if !ok {
+		assembly = &reportAssembly{
+			parts:     make(map[int][]ReportData),
+			lastSeqNo: -1,
+			startedAt: now,
+		}
+		s.reports[key] = assembly
+	}
+	assembly.parts[req.SeqNo] = req.ReportData
+	if !req.Tbc {
+		assembly.lastSeqNo = req.SeqNo
+	}
+
+	data, complete := assembly.assemble()
+	if complete {
+		delete(s.reports, key)
+	}
+	s.reportMu.Unlock()
+
+	if !complete || s.inventoryRepo == nil {
+		return &NotifyReportResponse{}, nil
+	}
+
+	inventory := &domain.DeviceInventory{
+		ChargePointID: cpID,
+		RequestID:     req.RequestId,
+		Variables:     reportDataToInventory(data),
+		ReportedAt:    now,
+	}
+	if ts, err := time.Parse(time.RFC3339, req.GeneratedAt); err == nil {
+		inventory.ReportedAt = ts
+	}
+
+	if err := s.inventoryRepo.Save(context.Background(), inventory); err != nil {
+		return nil, fmt.Errorf("failed to save device inventory: %w", err)
+	}
+
+	s.log.Info("Device inventory stored",
+		zap.String("cpID", cpID),
+		zap.Int("requestId", req.RequestId),
+		zap.Int("variableCount", len(inventory.Variables)),
+	)
+
+	return &NotifyReportResponse{}, nil
+}
+
+// assemble returns the report data ordered by seqNo once every part from 0
+// up to the final one has been received
+func (a *reportAssembly) assemble() ([]ReportData, bool) {
+	if a.lastSeqNo < 0 || len(a.parts) < a.lastSeqNo+1 {
+		return nil, false
+	}
+
+	seqNos := make([]int, 0, len(a.parts))
+	for seq := range a.parts {
+		if seq > a.lastSeqNo {
+			continue
+		}
+		seqNos = append(seqNos, seq)
+	}
+	if len(seqNos) != a.lastSeqNo+1 {
+		return nil, false
+	}
+	sort.Ints(seqNos)
+
+	var data []ReportData
+	for _, seq := range seqNos {
+		data = append(data, a.parts[seq]...)
+	}
+	return data, true
+}
+
+// reportDataToInventory converts OCPP report data to domain inventory variables
+func reportDataToInventory(data []ReportData) []domain.InventoryVariable {
+	variables := make([]domain.InventoryVariable, 0, len(data))
+	for _, rd := range data {
+		v := domain.InventoryVariable{
+			Component:         rd.Component.Name,
+			ComponentInstance: rd.Component.Instance,
+			Variable:          rd.Variable.Name,
+			VariableInstance:  rd.Variable.Instance,
+			Attributes:        make([]domain.InventoryAttribute, 0, len(rd.VariableAttribute)),
+		}
+		if rd.Component.Evse != nil {
+			v.EvseID = intPtr(rd.Component.Evse.Id)
+			if rd.Component.Evse.ConnectorId > 0 {
+				v.ConnectorID = intPtr(rd.Component.Evse.ConnectorId)
+			}
+		}
+		for _, attr := range rd.VariableAttribute {
+			attrType := attr.Type
+			if attrType == "" {
+				attrType = "Actual"
+			}
+			v.Attributes = append(v.Attributes, domain.InventoryAttribute{
+				Type:       attrType,
+				Value:      attr.Value,
+				Mutability: attr.Mutability,
+			})
+		}
+		if rd.VariableCharacteristics != nil {
+			v.Unit = rd.VariableCharacteristics.Unit
+			v.DataType = rd.VariableCharacteristics.DataType
+			v.MinLimit = rd.VariableCharacteristics.MinLimit
+			v.MaxLimit = rd.VariableCharacteristics.MaxLimit
+			v.SupportsMonitoring = rd.VariableCharacteristics.SupportsMonitoring
+		}
+		variables = append(variables, v)
+	}
+	return variables
+}
@@ -0,0 +1,138 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// mockInventoryRepository records saved inventories
+type mockInventoryRepository struct {
+	mu          sync.Mutex
+	inventories map[string]*domain.DeviceInventory
+	saves       int
+}
+
+func newMockInventoryRepository() *mockInventoryRepository {
+	return &mockInventoryRepository{inventories: make(map[string]*domain.DeviceInventory)}
+}
+
+func (m *mockInventoryRepository) Save(ctx context.Context, inventory *domain.DeviceInventory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inventories[inventory.ChargePointID] = inventory
+	m.saves++
+	return nil
+}
+
+func (m *mockInventoryRepository) GetByChargePointID(ctx context.Context, chargePointID string) (*domain.DeviceInventory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inventories[chargePointID], nil
+}
+
+func reportPart(t *testing.T, requestID, seqNo int, tbc bool, variables ...string) []byte {
+	t.Helper()
+	req := NotifyReportRequest{
+		RequestId:   requestID,
+		GeneratedAt: "2026-10-16T12:00:00Z",
+		Tbc:         tbc,
+		SeqNo:       seqNo,
+	}
+	for _, name := range variables {
+		req.ReportData = append(req.ReportData, ReportData{
+			Component: Component{Name: "ChargingStation"},
+			Variable:  Variable{Name: name},
+			VariableAttribute: []VariableAttribute{
+				{Type: "Actual", Value: name + "-value", Mutability: "ReadOnly"},
+			},
+			VariableCharacteristics: &VariableCharacteristics{DataType: "string"},
+		})
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to marshal report: %v", err)
+	}
+	return data
+}
+
+func TestServer_NotifyReportReassemblesParts(t *testing.T) {
+	// Arrange
+	repo := newMockInventoryRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetInventoryRepository(repo)
+	defer srv.Stop()
+
+	// Parts arrive out of order; the final part is not the last to arrive
+	parts := [][]byte{
+		reportPart(t, 42, 1, true, "SerialNumber", "FirmwareVersion"),
+		reportPart(t, 42, 2, false, "AvailabilityState"),
+		reportPart(t, 42, 0, true, "Model", "VendorName"),
+	}
+
+	// Act
+	for i, part := range parts {
+		if _, err := srv.handleNotifyReport("CP-INV", part); err != nil {
+			t.Fatalf("part %d: unexpected error: %v", i, err)
+		}
+		if i < len(parts)-1 && repo.saves != 0 {
+			t.Fatalf("expected no inventory before all parts arrived, saved after part %d", i)
+		}
+	}
+
+	// Assert
+	if repo.saves != 1 {
+		t.Fatalf("expected 1 save, got %d", repo.saves)
+	}
+	inventory, _ := repo.GetByChargePointID(context.Background(), "CP-INV")
+	if inventory.RequestID != 42 {
+		t.Errorf("expected request 42, got %d", inventory.RequestID)
+	}
+
+	expected := []string{"Model", "VendorName", "SerialNumber", "FirmwareVersion", "AvailabilityState"}
+	if len(inventory.Variables) != len(expected) {
+		t.Fatalf("expected %d variables, got %d", len(expected), len(inventory.Variables))
+	}
+	for i, name := range expected {
+		v := inventory.Variables[i]
+		if v.Variable != name {
+			t.Errorf("variable %d: expected %s, got %s", i, name, v.Variable)
+		}
+		if len(v.Attributes) != 1 || v.Attributes[0].Value != name+"-value" {
+			t.Errorf("variable %d: unexpected attributes %+v", i, v.Attributes)
+		}
+	}
+
+	if len(srv.reports) != 0 {
+		t.Errorf("expected assembly buffer to be released, got %d pending", len(srv.reports))
+	}
+}
+
+func TestServer_NotifyReportKeepsRequestsSeparate(t *testing.T) {
+	// Arrange
+	repo := newMockInventoryRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetInventoryRepository(repo)
+	defer srv.Stop()
+
+	// Act: an unfinished report from another request must not be merged in
+	srv.handleNotifyReport("CP-INV", reportPart(t, 1, 0, true, "Stale"))
+	srv.handleNotifyReport("CP-INV", reportPart(t, 2, 0, false, "Model"))
+
+	// Assert
+	inventory, _ := repo.GetByChargePointID(context.Background(), "CP-INV")
+	if inventory == nil {
+		t.Fatal("expected inventory to be stored")
+	}
+	if len(inventory.Variables) != 1 || inventory.Variables[0].Variable != "Model" {
+		t.Errorf("expected only Model from request 2, got %+v", inventory.Variables)
+	}
+	if len(srv.reports) != 1 {
+		t.Errorf("expected request 1 to remain pending, got %d pending", len(srv.reports))
+	}
+}
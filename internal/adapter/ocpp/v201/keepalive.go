@@ -0,0 +1,103 @@
+package v201
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// KeepaliveConfig controls WebSocket pings used to detect dead connections
+// (e.g. half-open TCP after a NAT timeout)
+type KeepaliveConfig struct {
+	PingInterval time.Duration // How often a ping is sent
+	PongTimeout  time.Duration // How long after a ping the pong may arrive
+}
+
+// DefaultKeepaliveConfig returns the keepalive used when none is set
+func DefaultKeepaliveConfig() KeepaliveConfig {
+	return KeepaliveConfig{
+		PingInterval: 30 * time.Second,
+		PongTimeout:  10 * time.Second,
+	}
+}
+
+// SetKeepalive sets the ping interval and pong timeout for new connections.
+// Zero fields keep their defaults.
+func (s *Server) SetKeepalive(cfg KeepaliveConfig) {
+	defaults := DefaultKeepaliveConfig()
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaults.PingInterval
+	}
+	if cfg.PongTimeout <= 0 {
+		cfg.PongTimeout = defaults.PongTimeout
+	}
+	s.keepalive = cfg
+}
+
+// readWindow is how long a connection may stay silent, pongs included,
+// before the read loop gives up on it
+func (c KeepaliveConfig) readWindow() time.Duration {
+	return c.PingInterval + c.PongTimeout
+}
+
+// startKeepalive arms the read deadline, extends it on every pong and pings
+// the charge point until the returned stop function is called. A missing
+// pong makes the next read fail, which ends the connection.
+func (s *Server) startKeepalive(chargePointID string, conn *websocket.Conn) (stop func()) {
+	cfg := s.keepalive
+
+	conn.SetReadDeadline(time.Now().Add(cfg.readWindow()))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(cfg.readWindow()))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// WriteControl may run concurrently with Send
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(cfg.PongTimeout)); err != nil {
+					s.log.Warn("Failed to ping charge point",
+						zap.String("chargePointID", chargePointID),
+						zap.Error(err),
+					)
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// cancelPendingRequests fails every command still waiting on a charge point
+// whose connection was lost
+func (s *Server) cancelPendingRequests(chargePointID string) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	for msgID, req := range s.pendingRequests {
+		if req.ChargePointID != chargePointID {
+			continue
+		}
+		if req.ResponseChan != nil {
+			req.ResponseChan <- &CommandResponse{
+				Success: false,
+				Error: &OCPPError{
+					Code:        "ConnectionLost",
+					Description: "Charge point disconnected",
+				},
+			}
+			close(req.ResponseChan)
+		}
+		delete(s.pendingRequests, msgID)
+	}
+}
@@ -0,0 +1,116 @@
+package v201
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// dialKeepaliveServer connects chargePointID to srv. With answerPings false
+// the client keeps reading but never answers pings, like a half-open peer.
+func dialKeepaliveServer(t *testing.T, srv *Server, chargePointID string, answerPings bool) func() {
+	t.Helper()
+
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		httpSrv.Close()
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if !answerPings {
+		conn.SetPingHandler(func(string) error { return nil })
+	}
+
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	return func() {
+		conn.Close()
+		httpSrv.Close()
+	}
+}
+
+func waitConnected(t *testing.T, srv *Server, chargePointID string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !srv.IsConnected(chargePointID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s to connect", chargePointID)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestKeepalive_ReapsUnresponsiveClient(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetKeepalive(KeepaliveConfig{PingInterval: 50 * time.Millisecond, PongTimeout: 50 * time.Millisecond})
+	defer srv.Stop()
+
+	closeConn := dialKeepaliveServer(t, srv, "CP-SILENT", false)
+	defer closeConn()
+	waitConnected(t, srv, "CP-SILENT")
+
+	// A command the charge point will never answer
+	responses := make(chan *CommandResponse, 1)
+	go func() {
+		resp, _ := srv.SendCommandWithTimeout(context.Background(), "CP-SILENT", "GetBaseReport", map[string]interface{}{}, time.Minute)
+		responses <- resp
+	}()
+
+	// Act
+	start := time.Now()
+	for srv.IsConnected("CP-SILENT") {
+		if time.Since(start) > time.Second {
+			t.Fatal("expected the unresponsive charge point to be reaped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Assert
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected reaping within the pong timeout, took %v", elapsed)
+	}
+	select {
+	case resp := <-responses:
+		if resp == nil || resp.Success || resp.Error.Code != "ConnectionLost" {
+			t.Errorf("expected pending command to fail with ConnectionLost, got %+v", resp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the pending command to be cancelled")
+	}
+	if n := srv.GetPendingRequestCount(); n != 0 {
+		t.Errorf("expected no pending requests, got %d", n)
+	}
+}
+
+func TestKeepalive_ResponsiveClientStaysConnected(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetKeepalive(KeepaliveConfig{PingInterval: 50 * time.Millisecond, PongTimeout: 50 * time.Millisecond})
+	defer srv.Stop()
+
+	closeConn := dialKeepaliveServer(t, srv, "CP-ALIVE", true)
+	defer closeConn()
+	waitConnected(t, srv, "CP-ALIVE")
+
+	// Act: several read windows pass with only pings and pongs on the wire
+	time.Sleep(400 * time.Millisecond)
+
+	// Assert
+	if !srv.IsConnected("CP-ALIVE") {
+		t.Error("expected a charge point answering pings to stay connected")
+	}
+}
@@ -0,0 +1,77 @@
+package v201
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetLogRequestRepository enables tracking GetLog requests and their uploads
+func (s *Server) SetLogRequestRepository(repo ports.LogRequestRepository) {
+	s.logRequests = repo
+}
+
+// recordLogRequest stores a GetLog request the charge point accepted
+func (s *Server) recordLogRequest(ctx context.Context, cpID string, req GetLogRequest, resp GetLogResponse) {
+	if s.logRequests == nil {
+		return
+	}
+
+	now := time.Now()
+	record := &domain.LogRequest{
+		ID:             uuid.New().String(),
+		ChargePointID:  cpID,
+		RequestID:      req.RequestId,
+		LogType:        req.LogType,
+		RemoteLocation: req.Log.RemoteLocation,
+		Filename:       resp.Filename,
+		Status:         domain.LogUploadStatusAccepted,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.logRequests.Save(ctx, record); err != nil {
+		s.log.Error("Failed to save log request",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", req.RequestId),
+			zap.Error(err),
+		)
+	}
+}
+
+// updateLogRequest applies a LogStatusNotification to the tracked request
+func (s *Server) updateLogRequest(ctx context.Context, cpID string, requestID int, status string) {
+	if s.logRequests == nil {
+		return
+	}
+
+	record, err := s.logRequests.FindByRequestID(ctx, cpID, requestID)
+	if err != nil {
+		s.log.Error("Failed to load log request",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", requestID),
+			zap.Error(err),
+		)
+		return
+	}
+	if record == nil {
+		s.log.Warn("Log status for unknown request",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", requestID),
+		)
+		return
+	}
+
+	record.MarkStatus(domain.LogUploadStatus(status), time.Now())
+	if err := s.logRequests.Update(ctx, record); err != nil {
+		s.log.Error("Failed to update log request",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", requestID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,137 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// mockLogRequestRepository keeps log requests in memory
+type mockLogRequestRepository struct {
+	mu       sync.Mutex
+	requests map[string]*domain.LogRequest
+}
+
+func newMockLogRequestRepository() *mockLogRequestRepository {
+	return &mockLogRequestRepository{requests: make(map[string]*domain.LogRequest)}
+}
+
+func (m *mockLogRequestRepository) Save(ctx context.Context, req *domain.LogRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *req
+	m.requests[req.ID] = &copied
+	return nil
+}
+
+func (m *mockLogRequestRepository) Update(ctx context.Context, req *domain.LogRequest) error {
+	return m.Save(ctx, req)
+}
+
+func (m *mockLogRequestRepository) FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.LogRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, req := range m.requests {
+		if req.ChargePointID == chargePointID && req.RequestID == requestID {
+			copied := *req
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockLogRequestRepository) FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.LogRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []domain.LogRequest
+	for _, req := range m.requests {
+		if req.ChargePointID == chargePointID {
+			result = append(result, *req)
+		}
+	}
+	return result, nil
+}
+
+func logStatusPayload(t *testing.T, status string, requestID int) []byte {
+	t.Helper()
+	payload, err := json.Marshal(LogStatusNotificationRequest{Status: status, RequestId: &requestID})
+	if err != nil {
+		t.Fatalf("failed to marshal LogStatusNotification: %v", err)
+	}
+	return payload
+}
+
+func TestServer_LogStatusNotificationTracksUpload(t *testing.T) {
+	// Arrange
+	repo := newMockLogRequestRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetLogRequestRepository(repo)
+	defer srv.Stop()
+
+	ctx := context.Background()
+	srv.recordLogRequest(ctx, "CP001", GetLogRequest{
+		LogType:   "DiagnosticsLog",
+		RequestId: 42,
+		Log:       LogParams{RemoteLocation: "https://logs.example.com/upload"},
+	}, GetLogResponse{Status: "Accepted", Filename: "diag.zip"})
+
+	// Act & Assert: Uploading keeps the link hidden
+	if _, err := srv.handleLogStatusNotification("CP001", logStatusPayload(t, "Uploading", 42)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	record, _ := repo.FindByRequestID(ctx, "CP001", 42)
+	if record == nil {
+		t.Fatal("expected log request to be stored")
+	}
+	if record.Status != domain.LogUploadStatusUploading {
+		t.Errorf("expected status Uploading, got %s", record.Status)
+	}
+	if record.FileURL != "" {
+		t.Errorf("expected no file URL while uploading, got %s", record.FileURL)
+	}
+
+	// Act & Assert: Uploaded records where the file landed
+	if _, err := srv.handleLogStatusNotification("CP001", logStatusPayload(t, "Uploaded", 42)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	record, _ = repo.FindByRequestID(ctx, "CP001", 42)
+	if record.Status != domain.LogUploadStatusUploaded {
+		t.Errorf("expected status Uploaded, got %s", record.Status)
+	}
+	if record.FileURL != "https://logs.example.com/upload/diag.zip" {
+		t.Errorf("expected file URL https://logs.example.com/upload/diag.zip, got %s", record.FileURL)
+	}
+}
+
+func TestServer_LogStatusNotificationUploadFailure(t *testing.T) {
+	// Arrange
+	repo := newMockLogRequestRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetLogRequestRepository(repo)
+	defer srv.Stop()
+
+	ctx := context.Background()
+	srv.recordLogRequest(ctx, "CP001", GetLogRequest{
+		LogType:   "SecurityLog",
+		RequestId: 7,
+		Log:       LogParams{RemoteLocation: "ftp://logs.example.com"},
+	}, GetLogResponse{Status: "Accepted", Filename: "security.log"})
+
+	// Act
+	srv.handleLogStatusNotification("CP001", logStatusPayload(t, "UploadFailure", 7))
+	srv.handleLogStatusNotification("CP002", logStatusPayload(t, "Uploaded", 7))
+
+	// Assert
+	record, _ := repo.FindByRequestID(ctx, "CP001", 7)
+	if record.Status != domain.LogUploadStatusUploadFailure {
+		t.Errorf("expected status UploadFailure, got %s", record.Status)
+	}
+	if record.FileURL != "" {
+		t.Errorf("expected no file URL after a failed upload, got %s", record.FileURL)
+	}
+}
@@ -0,0 +1,91 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetMeterValueRepository enables storing the meter values of transactions
+func (s *Server) SetMeterValueRepository(repo ports.MeterValueRepository) {
+	s.meterValues = repo
+}
+
+// storeMeterValues stores the sampled values a charge point reported for a
+// transaction. Values that are not numbers are skipped.
+func (s *Server) storeMeterValues(ctx context.Context, cpID, txID string, connectorID int, meterValues []MeterValue) {
+	if s.meterValues == nil || txID == "" || len(meterValues) == 0 {
+		return
+	}
+
+	var samples []domain.MeterSample
+	for _, mv := range meterValues {
+		ts, err := time.Parse(time.RFC3339, mv.Timestamp)
+		if err != nil {
+			ts = time.Now()
+		}
+		for _, sv := range mv.SampledValue {
+			value, err := strconv.ParseFloat(sv.Value, 64)
+			if err != nil {
+				continue
+			}
+			measurand := sv.Measurand
+			if measurand == "" {
+				measurand = domain.MeasurandEnergyActiveImportRegister
+			}
+			samples = append(samples, domain.MeterSample{
+				ID:            uuid.New().String(),
+				TransactionID: txID,
+				ChargePointID: cpID,
+				ConnectorID:   connectorID,
+				Timestamp:     ts,
+				Measurand:     measurand,
+				Value:         value,
+				Unit:          sv.Unit,
+				Phase:         sv.Phase,
+				Location:      sv.Location,
+				Context:       sv.Context,
+			})
+		}
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	if err := s.meterValues.Save(ctx, samples); err != nil {
+		s.log.Warn("Failed to store meter values",
+			zap.String("txID", txID),
+			zap.Int("count", len(samples)),
+			zap.Error(err),
+		)
+	}
+}
+
+// UnmarshalJSON accepts value as the number OCPP 2.0.1 specifies as well as
+// the string some stations send
+func (v *SampledValue) UnmarshalJSON(data []byte) error {
+	type plain SampledValue
+	var raw struct {
+		plain
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*v = SampledValue(raw.plain)
+	if len(raw.Value) > 0 && raw.Value[0] == '"' {
+		return json.Unmarshal(raw.Value, &v.Value)
+	}
+	if string(raw.Value) != "null" {
+		v.Value = string(raw.Value)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+)
+
+// multiMeasurandMeterValues is one MeterValues of a three-phase AC session,
+// values as the numbers OCPP 2.0.1 specifies
+const multiMeasurandMeterValues = `{
+	"evseId": 1,
+	"meterValue": [{
+		"timestamp": "2026-10-16T12:05:00Z",
+		"sampledValue": [
+			{"value": 5400, "measurand": "Energy.Active.Import.Register", "unit": "Wh", "location": "Outlet"},
+			{"value": 7360.5, "measurand": "Power.Active.Import", "unit": "W", "location": "Outlet"},
+			{"value": 10.7, "measurand": "Current.Import", "phase": "L1", "unit": "A"},
+			{"value": 10.6, "measurand": "Current.Import", "phase": "L2", "unit": "A"},
+			{"value": 230.1, "measurand": "Voltage", "phase": "L1-N", "unit": "V"},
+			{"value": 64, "measurand": "SoC", "unit": "Percent", "location": "EV"},
+			{"value": "41.5", "measurand": "Temperature", "unit": "Celsius", "location": "Body"}
+		]
+	}]
+}`
+
+func TestMeterValues_StoresEveryMeasurand(t *testing.T) {
+	// Arrange
+	srv, _ := newSequenceTestServer(t)
+	repo := memory.NewMeterValueRepository()
+	srv.SetMeterValueRepository(repo)
+	feed(t, srv, [][]byte{transactionEvent(t, "Started", 0, 1000)})
+
+	// Act
+	_, err := srv.handleMeterValues("CP-001", []byte(multiMeasurandMeterValues))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	samples, _ := repo.FindByTransactionID(context.Background(), "tx-domain")
+	// The first sample is the reading the Started event carried
+	reported := samples[1:]
+	want := []struct {
+		measurand, phase, location, unit string
+		value                            float64
+	}{
+		{"Energy.Active.Import.Register", "", "Outlet", "Wh", 5400},
+		{"Power.Active.Import", "", "Outlet", "W", 7360.5},
+		{"Current.Import", "L1", "", "A", 10.7},
+		{"Current.Import", "L2", "", "A", 10.6},
+		{"Voltage", "L1-N", "", "V", 230.1},
+		{"SoC", "", "EV", "Percent", 64},
+		{"Temperature", "", "Body", "Celsius", 41.5},
+	}
+	if len(reported) != len(want) {
+		t.Fatalf("expected %d samples, got %d: %+v", len(want), len(reported), reported)
+	}
+	for i, w := range want {
+		got := reported[i]
+		if got.Measurand != w.measurand || got.Phase != w.phase || got.Location != w.location || got.Unit != w.unit || got.Value != w.value {
+			t.Errorf("sample %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+}
+
+func TestSampledValue_UnmarshalValue(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"number", `{"value": 7360.5}`, "7360.5"},
+		{"string", `{"value": "7360.5"}`, "7360.5"},
+		{"missing", `{"measurand": "SoC"}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sv SampledValue
+			err := json.Unmarshal([]byte(tt.json), &sv)
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if sv.Value != tt.want {
+				t.Errorf("expected value %q, got %q", tt.want, sv.Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// AlertTypeVariableMonitor is the alert type used for NotifyEvent-triggered alerts
+const AlertTypeVariableMonitor = "variable_monitor"
+
+// SetAlertRepository enables persisting NotifyEvent messages as alerts
+func (s *Server) SetAlertRepository(repo ports.AlertRepository) {
+	s.alertRepo = repo
+}
+
+// --- Monitoring Commands (CSMS → Charge Point) ---
+
+// SetMonitoringBase activates a predefined monitoring base on a charge point
+func (s *Server) SetMonitoringBase(ctx context.Context, chargePointID, monitoringBase string) (*SetMonitoringBaseResponse, error) {
+	req := SetMonitoringBaseRequest{
+		MonitoringBase: monitoringBase, // All, FactoryDefault, HardWiredOnly
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "SetMonitoringBase", req)
+	if err != nil {
+		return nil, fmt.Errorf("set monitoring base failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("set monitoring base rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response SetMonitoringBaseResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// FactoryDefault and HardWiredOnly remove all custom monitors
+	if response.Status == "Accepted" && monitoringBase != "All" {
+		s.monitorMu.Lock()
+		delete(s.monitors, chargePointID)
+		s.monitorMu.Unlock()
+	}
+
+	return &response, nil
+}
+
+// SetVariableMonitoring configures monitors on charge point variables. Accepted
+// monitors are remembered so that later NotifyEvent messages can be mapped
+// back to their severity and threshold.
+func (s *Server) SetVariableMonitoring(ctx context.Context, chargePointID string, monitors []SetMonitoringData) (*SetVariableMonitoringResponse, error) {
+	req := SetVariableMonitoringRequest{
+		SetMonitoringData: monitors,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "SetVariableMonitoring", req)
+	if err != nil {
+		return nil, fmt.Errorf("set variable monitoring failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("set variable monitoring rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response SetVariableMonitoringResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	for i, result := range response.SetMonitoringResult {
+		if result.Status != "Accepted" || result.Id == nil || i >= len(monitors) {
+			continue
+		}
+		monitor := monitors[i]
+		monitor.Id = intPtr(*result.Id)
+		if s.monitors[chargePointID] == nil {
+			s.monitors[chargePointID] = make(map[int]SetMonitoringData)
+		}
+		s.monitors[chargePointID][*result.Id] = monitor
+	}
+
+	return &response, nil
+}
+
+// GetMonitor returns a monitor previously accepted by a charge point
+func (s *Server) GetMonitor(chargePointID string, monitorID int) (SetMonitoringData, bool) {
+	s.monitorMu.RLock()
+	defer s.monitorMu.RUnlock()
+	monitor, ok := s.monitors[chargePointID][monitorID]
+	return monitor, ok
+}
+
+// --- NotifyEvent (Charge Point → CSMS) ---
+
+// handleNotifyEvent turns alerting monitor events into alerts
+func (s *Server) handleNotifyEvent(cpID string, payload []byte) (*NotifyEventResponse, error) {
+	var req NotifyEventRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Notify Event",
+		zap.String("cpID", cpID),
+		zap.Int("seqNo", req.SeqNo),
+		zap.Int("eventCount", len(req.EventData)),
+		zap.Bool("toBeContinued", req.Tbc),
+	)
+
+	ctx := context.Background()
+	for _, event := range req.EventData {
+		s.log.Info("Monitor event",
+			zap.String("cpID", cpID),
+			zap.Int("eventId", event.EventId),
+			zap.String("trigger", event.Trigger),
+			zap.String("component", event.Component.Name),
+			zap.String("variable", event.Variable.Name),
+			zap.String("actualValue", event.ActualValue),
+			zap.Bool("cleared", event.Cleared),
+		)
+
+		// Periodic reports and cleared conditions are informational only
+		if event.Trigger == "Periodic" || event.Cleared || s.alertRepo == nil {
+			continue
+		}
+
+		alert := s.eventToAlert(cpID, event)
+		if err := s.alertRepo.Save(ctx, alert); err != nil {
+			s.log.Error("Failed to save monitor alert",
+				zap.String("cpID", cpID),
+				zap.Int("eventId", event.EventId),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return &NotifyEventResponse{}, nil
+}
+
+// eventToAlert builds an alert from a monitor event, enriching it with the
+// configured monitor when the event references one
+func (s *Server) eventToAlert(cpID string, event EventData) *ports.Alert {
+	variable := event.Component.Name + "." + event.Variable.Name
+	severity := "warning"
+	title := fmt.Sprintf("%s reported an event", variable)
+
+	if event.VariableMonitoringId != nil {
+		if monitor, ok := s.GetMonitor(cpID, *event.VariableMonitoringId); ok {
+			severity = monitorSeverityToAlert(monitor.Severity)
+			switch monitor.Type {
+			case "UpperThreshold":
+				title = fmt.Sprintf("%s exceeded %g", variable, monitor.Value)
+			case "LowerThreshold":
+				title = fmt.Sprintf("%s dropped below %g", variable, monitor.Value)
+			case "Delta":
+				title = fmt.Sprintf("%s changed by more than %g", variable, monitor.Value)
+			}
+		}
+	}
+
+	message := fmt.Sprintf("Charge point %s reported %s = %s (trigger: %s)",
+		cpID, variable, event.ActualValue, event.Trigger)
+	if event.TechInfo != "" {
+		message += ": " + event.TechInfo
+	}
+
+	createdAt := time.Now()
+	if ts, err := time.Parse(time.RFC3339, event.Timestamp); err == nil {
+		createdAt = ts
+	}
+
+	return &ports.Alert{
+		ID:        uuid.New().String(),
+		Type:      AlertTypeVariableMonitor,
+		Severity:  severity,
+		Title:     title,
+		Message:   message,
+		Source:    "charge_point",
+		SourceID:  cpID,
+		CreatedAt: createdAt,
+	}
+}
+
+// monitorSeverityToAlert maps OCPP monitor severity (0 Danger .. 9 Debug)
+// onto the alert severities used by the admin dashboard
+func monitorSeverityToAlert(severity int) string {
+	switch {
+	case severity <= 3: // Danger, HardwareFailure, SystemFailure, Critical
+		return "critical"
+	case severity == 4: // Error
+		return "error"
+	case severity <= 6: // Alert, Warning
+		return "warning"
+	default: // Notice, Informational, Debug
+		return "info"
+	}
+}
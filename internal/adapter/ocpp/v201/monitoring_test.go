@@ -0,0 +1,261 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// mockAlertRepository records saved alerts
+type mockAlertRepository struct {
+	mu     sync.Mutex
+	alerts []ports.Alert
+}
+
+func (m *mockAlertRepository) Save(ctx context.Context, alert *ports.Alert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alerts = append(m.alerts, *alert)
+	return nil
+}
+
+func (m *mockAlertRepository) GetByID(ctx context.Context, id string) (*ports.Alert, error) {
+	return nil, nil
+}
+
+func (m *mockAlertRepository) GetAll(ctx context.Context, acknowledged bool, limit, offset int) ([]ports.Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ports.Alert(nil), m.alerts...), nil
+}
+
+func (m *mockAlertRepository) Acknowledge(ctx context.Context, id string) error { return nil }
+
+func (m *mockAlertRepository) Delete(ctx context.Context, id string) error { return nil }
+
+func (m *mockAlertRepository) CountUnacknowledged(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.alerts), nil
+}
+
+func (m *mockAlertRepository) FindOpen(ctx context.Context, alertType, sourceID string) ([]ports.Alert, error) {
+	return nil, nil
+}
+
+func (m *mockAlertRepository) Resolve(ctx context.Context, id string, resolvedAt time.Time) error {
+	return nil
+}
+
+// monitoringChargePoint is a fake charge point that accepts monitors and can
+// push NotifyEvent calls to the server
+type monitoringChargePoint struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	results chan string // message IDs of CallResults received from the server
+}
+
+func connectMonitoringChargePoint(t *testing.T, srv *Server, baseURL, chargePointID string) *monitoringChargePoint {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial(baseURL+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		t.Fatalf("failed to connect %s: %v", chargePointID, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	cp := &monitoringChargePoint{conn: conn, results: make(chan string, 10)}
+
+	go func() {
+		nextMonitorID := 100
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var raw []json.RawMessage
+			if err := json.Unmarshal(data, &raw); err != nil || len(raw) < 3 {
+				continue
+			}
+			var msgType MessageType
+			var msgID string
+			json.Unmarshal(raw[0], &msgType)
+			json.Unmarshal(raw[1], &msgID)
+
+			if msgType == CallResult {
+				cp.results <- msgID
+				continue
+			}
+
+			var req SetVariableMonitoringRequest
+			json.Unmarshal(raw[3], &req)
+
+			results := make([]SetMonitoringResult, len(req.SetMonitoringData))
+			for i, m := range req.SetMonitoringData {
+				results[i] = SetMonitoringResult{
+					Id:        intPtr(nextMonitorID),
+					Status:    "Accepted",
+					Type:      m.Type,
+					Severity:  m.Severity,
+					Component: m.Component,
+					Variable:  m.Variable,
+				}
+				nextMonitorID++
+			}
+			cp.send(t, []interface{}{CallResult, msgID, SetVariableMonitoringResponse{SetMonitoringResult: results}})
+		}
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !srv.IsConnected(chargePointID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("charge point %s was not registered", chargePointID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return cp
+}
+
+func (cp *monitoringChargePoint) send(t *testing.T, msg []interface{}) {
+	data, _ := json.Marshal(msg)
+	cp.writeMu.Lock()
+	defer cp.writeMu.Unlock()
+	if err := cp.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		t.Errorf("failed to write message: %v", err)
+	}
+}
+
+// notifyEvent sends a NotifyEvent call and waits for the server to acknowledge it
+func (cp *monitoringChargePoint) notifyEvent(t *testing.T, msgID string, req NotifyEventRequest) {
+	t.Helper()
+	cp.send(t, []interface{}{Call, msgID, "NotifyEvent", req})
+
+	select {
+	case got := <-cp.results:
+		if got != msgID {
+			t.Fatalf("expected CallResult for %s, got %s", msgID, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for NotifyEvent response")
+	}
+}
+
+func TestServer_VariableMonitoringEventCreatesAlert(t *testing.T) {
+	// Arrange
+	alerts := &mockAlertRepository{}
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetAlertRepository(alerts)
+	defer srv.Stop()
+
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+	baseURL := "ws" + strings.TrimPrefix(httpSrv.URL, "http")
+	cp := connectMonitoringChargePoint(t, srv, baseURL, "CP-MON")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Act: configure a temperature monitor, then report it tripping
+	resp, err := srv.SetVariableMonitoring(ctx, "CP-MON", []SetMonitoringData{{
+		Value:     60,
+		Type:      "UpperThreshold",
+		Severity:  2,
+		Component: Component{Name: "EVSE", Evse: &Evse{Id: 1}},
+		Variable:  Variable{Name: "Temperature"},
+	}})
+	if err != nil {
+		t.Fatalf("SetVariableMonitoring failed: %v", err)
+	}
+	if len(resp.SetMonitoringResult) != 1 || resp.SetMonitoringResult[0].Status != "Accepted" {
+		t.Fatalf("expected one accepted monitor, got %+v", resp.SetMonitoringResult)
+	}
+	monitorID := *resp.SetMonitoringResult[0].Id
+
+	cp.notifyEvent(t, "evt-1", NotifyEventRequest{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		SeqNo:       0,
+		EventData: []EventData{{
+			EventId:               1,
+			Timestamp:             time.Now().Format(time.RFC3339),
+			Trigger:               "Alerting",
+			ActualValue:           "72.5",
+			VariableMonitoringId:  intPtr(monitorID),
+			EventNotificationType: "CustomMonitor",
+			Component:             Component{Name: "EVSE", Evse: &Evse{Id: 1}},
+			Variable:              Variable{Name: "Temperature"},
+		}},
+	})
+
+	// A cleared event must not raise a second alert
+	cp.notifyEvent(t, "evt-2", NotifyEventRequest{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		SeqNo:       1,
+		EventData: []EventData{{
+			EventId:               2,
+			Timestamp:             time.Now().Format(time.RFC3339),
+			Trigger:               "Alerting",
+			ActualValue:           "55",
+			Cleared:               true,
+			VariableMonitoringId:  intPtr(monitorID),
+			EventNotificationType: "CustomMonitor",
+			Component:             Component{Name: "EVSE", Evse: &Evse{Id: 1}},
+			Variable:              Variable{Name: "Temperature"},
+		}},
+	})
+
+	// Assert
+	saved, _ := alerts.GetAll(ctx, false, 10, 0)
+	if len(saved) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(saved))
+	}
+	alert := saved[0]
+	if alert.Type != AlertTypeVariableMonitor {
+		t.Errorf("expected type %s, got %s", AlertTypeVariableMonitor, alert.Type)
+	}
+	if alert.Severity != "critical" {
+		t.Errorf("expected severity critical, got %s", alert.Severity)
+	}
+	if alert.SourceID != "CP-MON" {
+		t.Errorf("expected source CP-MON, got %s", alert.SourceID)
+	}
+	if !strings.Contains(alert.Title, "EVSE.Temperature exceeded 60") {
+		t.Errorf("expected title to mention threshold, got %q", alert.Title)
+	}
+	if !strings.Contains(alert.Message, "72.5") {
+		t.Errorf("expected message to include actual value, got %q", alert.Message)
+	}
+}
+
+func TestMonitorSeverityToAlert(t *testing.T) {
+	tests := []struct {
+		severity int
+		expected string
+	}{
+		{0, "critical"},
+		{3, "critical"},
+		{4, "error"},
+		{5, "warning"},
+		{6, "warning"},
+		{7, "info"},
+		{9, "info"},
+	}
+
+	for _, tt := range tests {
+		if got := monitorSeverityToAlert(tt.severity); got != tt.expected {
+			t.Errorf("severity %d: expected %s, got %s", tt.severity, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// pendingStoreTimeout bounds each write to the pending command store, so a
+// slow database does not hold up a command for long
+const pendingStoreTimeout = 5 * time.Second
+
+// RestoredResultHandler is called with the answer to a command sent before
+// the CSMS restarted. Nobody waits on such a command any more, so this is
+// the only place its outcome can be acted upon.
+type RestoredResultHandler func(req *PendingRequest, resp *CommandResponse)
+
+// SetPendingCommandRepository persists the commands awaiting an answer so
+// they survive a restart (see RestorePendingRequests). A stored command
+// stays until it is answered or expires: a station cut off by the restart
+// answers on its next connection.
+func (s *Server) SetPendingCommandRepository(repo ports.PendingCommandRepository) {
+	s.pendingStore = repo
+}
+
+// SetRestoredResultHandler sets what is done with answers to commands sent
+// before a restart. They are logged either way.
+func (s *Server) SetRestoredResultHandler(handler RestoredResultHandler) {
+	s.restoredResults = handler
+}
+
+// RestorePendingRequests reloads the commands still awaiting an answer when
+// the previous process stopped, so late CallResults and CallErrors are
+// matched until the commands expire. Call it once at startup.
+func (s *Server) RestorePendingRequests(ctx context.Context) (int, error) {
+	if s.pendingStore == nil {
+		return 0, nil
+	}
+
+	commands, err := s.pendingStore.FindUnexpired(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to load pending commands: %w", err)
+	}
+
+	s.pendingMu.Lock()
+	for _, cmd := range commands {
+		if _, ok := s.pendingRequests[cmd.MessageID]; ok {
+			continue
+		}
+		s.pendingRequests[cmd.MessageID] = &PendingRequest{
+			MessageID:     cmd.MessageID,
+			Action:        cmd.Action,
+			ChargePointID: cmd.ChargePointID,
+			Payload:       json.RawMessage(cmd.Payload),
+			Timeout:       cmd.ExpiresAt,
+			CreatedAt:     cmd.CreatedAt,
+			Restored:      true,
+		}
+	}
+	s.pendingMu.Unlock()
+
+	if len(commands) > 0 {
+		s.log.Info("Restored pending OCPP commands", zap.Int("count", len(commands)))
+	}
+	return len(commands), nil
+}
+
+// persistPending stores a command before it is sent. A failure is logged and
+// the command still goes out; only its survival across a restart is lost.
+func (s *Server) persistPending(req *PendingRequest) {
+	if s.pendingStore == nil {
+		return
+	}
+
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		s.log.Warn("Failed to encode pending command", zap.String("messageID", req.MessageID), zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pendingStoreTimeout)
+	defer cancel()
+	err = s.pendingStore.Save(ctx, &domain.PendingCommand{
+		MessageID:     req.MessageID,
+		ChargePointID: req.ChargePointID,
+		Action:        req.Action,
+		Payload:       string(payload),
+		ExpiresAt:     req.Timeout,
+		CreatedAt:     req.CreatedAt,
+	})
+	if err != nil {
+		s.log.Warn("Failed to persist pending command",
+			zap.String("messageID", req.MessageID),
+			zap.String("chargePointID", req.ChargePointID),
+			zap.Error(err),
+		)
+	}
+}
+
+// forgetPending removes a command that was answered or abandoned
+func (s *Server) forgetPending(messageID string) {
+	if s.pendingStore == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pendingStoreTimeout)
+	defer cancel()
+	if err := s.pendingStore.Delete(ctx, messageID); err != nil {
+		s.log.Warn("Failed to delete pending command", zap.String("messageID", messageID), zap.Error(err))
+	}
+}
+
+// forgetExpiredPending removes the stored commands that can no longer be answered
+func (s *Server) forgetExpiredPending(now time.Time) {
+	if s.pendingStore == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pendingStoreTimeout)
+	defer cancel()
+	if err := s.pendingStore.DeleteExpired(ctx, now); err != nil {
+		s.log.Warn("Failed to delete expired pending commands", zap.Error(err))
+	}
+}
+
+// handleRestoredResponse deals with the answer to a command sent before a restart
+func (s *Server) handleRestoredResponse(req *PendingRequest, resp *CommandResponse) {
+	fields := []zap.Field{
+		zap.String("action", req.Action),
+		zap.String("chargePointID", req.ChargePointID),
+		zap.String("messageID", req.MessageID),
+		zap.Time("sentAt", req.CreatedAt),
+	}
+	if resp.Success {
+		s.log.Info("Received answer to a command sent before restart", append(fields, zap.ByteString("payload", resp.Payload))...)
+	} else {
+		s.log.Warn("Received error for a command sent before restart",
+			append(fields, zap.String("errorCode", resp.Error.Code), zap.String("errorDesc", resp.Error.Description))...)
+	}
+
+	if s.restoredResults != nil {
+		s.restoredResults(req, resp)
+	}
+}
@@ -0,0 +1,125 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// dialStation connects to srv as chargePointID
+func dialStation(t *testing.T, srv *Server, chargePointID string) *websocket.Conn {
+	t.Helper()
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	t.Cleanup(httpSrv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	waitConnected(t, srv, chargePointID)
+	return conn
+}
+
+func TestPendingRequests_AnswerAfterRestartIsMatched(t *testing.T) {
+	// Arrange: the command is sent, then the CSMS restarts before the answer
+	store := memory.NewPendingCommandRepository()
+	before := NewServer(nil, nil, zap.NewNop())
+	before.SetPendingCommandRepository(store)
+	conn := dialStation(t, before, "CP-001")
+
+	messageID, err := before.SendCommandAsync("CP-001", "Reset", ResetRequest{Type: "OnIdle"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readCall(t, conn); got != messageID {
+		t.Fatalf("expected call %s, got %s", messageID, got)
+	}
+	conn.Close()
+	before.Stop()
+
+	after := NewServer(nil, nil, zap.NewNop())
+	defer after.Stop()
+	after.SetPendingCommandRepository(store)
+	answered := make(chan *PendingRequest, 1)
+	var answer *CommandResponse
+	after.SetRestoredResultHandler(func(req *PendingRequest, resp *CommandResponse) {
+		answer = resp
+		answered <- req
+	})
+	restored, err := after.RestorePendingRequests(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Act: the station reconnects and answers
+	conn = dialStation(t, after, "CP-001")
+	conn.WriteJSON([]interface{}{CallResult, messageID, ResetResponse{Status: "Scheduled"}})
+
+	// Assert
+	if restored != 1 {
+		t.Fatalf("expected 1 restored command, got %d", restored)
+	}
+	var req *PendingRequest
+	select {
+	case req = <-answered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the answer to be matched to the command")
+	}
+	if req.Action != "Reset" || req.ChargePointID != "CP-001" {
+		t.Errorf("expected Reset on CP-001, got %s on %s", req.Action, req.ChargePointID)
+	}
+	var sent ResetRequest
+	json.Unmarshal(req.Payload.(json.RawMessage), &sent)
+	if sent.Type != "OnIdle" {
+		t.Errorf("expected the original payload, got %+v", sent)
+	}
+	var resp ResetResponse
+	json.Unmarshal(answer.Payload, &resp)
+	if !answer.Success || resp.Status != "Scheduled" {
+		t.Errorf("expected a Scheduled answer, got %+v", answer)
+	}
+
+	waitUntil(t, "the answered command is forgotten", func() bool { return after.GetPendingRequestCount() == 0 })
+	if left, _ := store.FindUnexpired(context.Background(), time.Now()); len(left) != 0 {
+		t.Errorf("expected the stored command to be deleted, got %d", len(left))
+	}
+}
+
+func TestPendingRequests_ExpiredCommandsAreNotRestored(t *testing.T) {
+	// Arrange: the previous process stopped longer ago than the command timeout
+	store := memory.NewPendingCommandRepository()
+	store.Save(context.Background(), &domain.PendingCommand{
+		MessageID:     "msg-1",
+		ChargePointID: "CP-001",
+		Action:        "Reset",
+		Payload:       `{"type":"OnIdle"}`,
+		ExpiresAt:     time.Now().Add(-time.Second),
+		CreatedAt:     time.Now().Add(-DefaultCommandTimeout),
+	})
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetPendingCommandRepository(store)
+
+	// Act
+	restored, err := srv.RestorePendingRequests(context.Background())
+
+	// Assert
+	if err != nil || restored != 0 {
+		t.Errorf("expected nothing restored, got %d, %v", restored, err)
+	}
+	if srv.GetPendingRequestCount() != 0 {
+		t.Error("expected the expired command not to be pending")
+	}
+}
@@ -0,0 +1,213 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// firmwareUpdateAccepted is tracked for a charge point that accepted
+// UpdateFirmware and has not sent a FirmwareStatusNotification yet
+const firmwareUpdateAccepted = "Accepted"
+
+// publicationStaleAfter is how long a publication may go without a status
+// notification before it no longer blocks firmware updates, e.g. because the
+// controller was replaced
+const publicationStaleAfter = 24 * time.Hour
+
+// SetFirmwarePublicationRepository enables tracking PublishFirmware requests
+// and their status
+func (s *Server) SetFirmwarePublicationRepository(repo ports.FirmwarePublicationRepository) {
+	s.firmwarePubs = repo
+}
+
+// SetFirmwareService passes FirmwareStatusNotifications on to the service
+// tracking firmware updates
+func (s *Server) SetFirmwareService(firmware ports.FirmwareService) {
+	s.firmware = firmware
+}
+
+// PublishFirmware asks a local controller to download firmware and serve it
+// to the charge points behind it, so they fetch it over the local network.
+// It fails with domain.ErrFirmwareOperationInProgress while the controller
+// is updating its own firmware or still publishing another file.
+func (s *Server) PublishFirmware(ctx context.Context, chargePointID, location, checksum string, retries, retryInterval *int) (*PublishFirmwareResponse, error) {
+	if s.firmwareUpdating(chargePointID) {
+		return nil, fmt.Errorf("%s is updating its firmware: %w", chargePointID, domain.ErrFirmwareOperationInProgress)
+	}
+	if err := s.checkNotPublishing(ctx, chargePointID); err != nil {
+		return nil, err
+	}
+
+	req := PublishFirmwareRequest{
+		Location:      location,
+		Retries:       retries,
+		Checksum:      checksum,
+		RequestId:     int(time.Now().UnixNano() % 1000000),
+		RetryInterval: retryInterval,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "PublishFirmware", req)
+	if err != nil {
+		return nil, fmt.Errorf("publish firmware failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("publish firmware rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response PublishFirmwareResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if response.Status == "Accepted" {
+		s.recordPublication(ctx, chargePointID, req)
+	}
+
+	return &response, nil
+}
+
+// checkNotPublishing fails while the charge point's latest publication has
+// not finished
+func (s *Server) checkNotPublishing(ctx context.Context, chargePointID string) error {
+	if s.firmwarePubs == nil {
+		return nil
+	}
+
+	latest, err := s.firmwarePubs.FindByChargePointID(ctx, chargePointID, 1)
+	if err != nil {
+		return fmt.Errorf("failed to load firmware publications: %w", err)
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+	pub := latest[0]
+	if pub.Status.Finished() || time.Since(pub.UpdatedAt) > publicationStaleAfter {
+		return nil
+	}
+	return fmt.Errorf("%s is publishing firmware (request %d, %s): %w",
+		chargePointID, pub.RequestID, pub.Status, domain.ErrFirmwareOperationInProgress)
+}
+
+// trackFirmwareStatus remembers a charge point's latest firmware update status
+func (s *Server) trackFirmwareStatus(chargePointID, status string) {
+	s.firmwareMu.Lock()
+	defer s.firmwareMu.Unlock()
+	s.firmwareStatus[chargePointID] = status
+}
+
+// firmwareUpdating reports whether a charge point is downloading or
+// installing firmware for itself
+func (s *Server) firmwareUpdating(chargePointID string) bool {
+	s.firmwareMu.Lock()
+	status, ok := s.firmwareStatus[chargePointID]
+	s.firmwareMu.Unlock()
+	if !ok {
+		return false
+	}
+
+	switch status {
+	case "Idle", "Installed", "DownloadFailed", "InstallationFailed",
+		"InstallVerificationFailed", "InvalidSignature":
+		return false
+	default:
+		return true
+	}
+}
+
+// recordPublication stores a PublishFirmware request the controller accepted
+func (s *Server) recordPublication(ctx context.Context, cpID string, req PublishFirmwareRequest) {
+	if s.firmwarePubs == nil {
+		return
+	}
+
+	now := time.Now()
+	pub := &domain.FirmwarePublication{
+		ID:            uuid.New().String(),
+		ChargePointID: cpID,
+		RequestID:     req.RequestId,
+		Location:      req.Location,
+		Checksum:      req.Checksum,
+		Status:        domain.FirmwarePublishStatusAccepted,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.firmwarePubs.Save(ctx, pub); err != nil {
+		s.log.Error("Failed to save firmware publication",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", req.RequestId),
+			zap.Error(err),
+		)
+	}
+}
+
+// handlePublishFirmwareStatusNotification processes firmware publish status
+func (s *Server) handlePublishFirmwareStatusNotification(cpID string, payload []byte) (*PublishFirmwareStatusNotificationResponse, error) {
+	var req PublishFirmwareStatusNotificationRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Publish Firmware Status Notification",
+		zap.String("cpID", cpID),
+		zap.String("status", req.Status),
+		zap.Strings("location", req.Location),
+		zap.Intp("requestId", req.RequestId),
+	)
+
+	if req.RequestId != nil {
+		s.updatePublication(context.Background(), cpID, *req.RequestId, req)
+	}
+
+	return &PublishFirmwareStatusNotificationResponse{}, nil
+}
+
+// updatePublication applies a PublishFirmwareStatusNotification to the
+// tracked publication
+func (s *Server) updatePublication(ctx context.Context, cpID string, requestID int, req PublishFirmwareStatusNotificationRequest) {
+	if s.firmwarePubs == nil {
+		return
+	}
+
+	pub, err := s.firmwarePubs.FindByRequestID(ctx, cpID, requestID)
+	if err != nil {
+		s.log.Error("Failed to load firmware publication",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", requestID),
+			zap.Error(err),
+		)
+		return
+	}
+	if pub == nil {
+		s.log.Warn("Publish status for unknown request",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", requestID),
+		)
+		return
+	}
+
+	if !pub.MarkStatus(domain.FirmwarePublishStatus(req.Status), req.Location, time.Now()) {
+		s.log.Warn("Ignoring out of order publish status",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", requestID),
+			zap.String("current", string(pub.Status)),
+			zap.String("reported", req.Status),
+		)
+		return
+	}
+	if err := s.firmwarePubs.Update(ctx, pub); err != nil {
+		s.log.Error("Failed to update firmware publication",
+			zap.String("cpID", cpID),
+			zap.Int("requestId", requestID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,185 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// publishStatusCall is a PublishFirmwareStatusNotification call
+func publishStatusCall(t *testing.T, msgID string, requestID int, status string, location ...string) string {
+	t.Helper()
+	payload, err := json.Marshal(PublishFirmwareStatusNotificationRequest{
+		Status:    status,
+		Location:  location,
+		RequestId: &requestID,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal notification: %v", err)
+	}
+	return fmt.Sprintf(`[2,%q,"PublishFirmwareStatusNotification",%s]`, msgID, payload)
+}
+
+// seedPublication stores publication 5 of chargePointID in status
+func seedPublication(t *testing.T, repo ports.FirmwarePublicationRepository, chargePointID string, status domain.FirmwarePublishStatus) {
+	t.Helper()
+	err := repo.Save(context.Background(), &domain.FirmwarePublication{
+		ID:            "pub-1",
+		ChargePointID: chargePointID,
+		RequestID:     5,
+		Location:      "https://fw.example.com/v2.bin",
+		Checksum:      "9e107d9d372bb6826bd81d3542a419d6",
+		Status:        status,
+	})
+	if err != nil {
+		t.Fatalf("failed to seed publication: %v", err)
+	}
+}
+
+func TestPublishFirmwareStatusNotification_Transitions(t *testing.T) {
+	uris := []string{"http://10.0.0.2/fw.bin", "ftp://10.0.0.2/fw.bin"}
+
+	tests := []struct {
+		name       string
+		requestID  int
+		statuses   []string
+		wantStatus domain.FirmwarePublishStatus
+		wantURIs   int
+	}{
+		{"published", 5, []string{"DownloadScheduled", "Downloading", "DownloadPaused", "Downloading", "Downloaded", "ChecksumVerified", "Published"}, domain.FirmwarePublishStatusPublished, 2},
+		{"late download status ignored", 5, []string{"Downloading", "Published", "Downloading"}, domain.FirmwarePublishStatusPublished, 2},
+		{"invalid checksum is final", 5, []string{"Downloaded", "InvalidChecksum", "ChecksumVerified"}, domain.FirmwarePublishStatusInvalidChecksum, 0},
+		{"earlier stage ignored", 5, []string{"Downloaded", "Downloading"}, domain.FirmwarePublishStatusDownloaded, 0},
+		{"idle ends the publication", 5, []string{"Downloading", "Idle"}, domain.FirmwarePublishStatusIdle, 0},
+		{"unknown request ignored", 99, []string{"Downloading"}, domain.FirmwarePublishStatusAccepted, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := memory.NewFirmwarePublicationRepository()
+			seedPublication(t, repo, "CP-LC", domain.FirmwarePublishStatusAccepted)
+			srv := NewServer(nil, nil, zap.NewNop())
+			srv.SetFirmwarePublicationRepository(repo)
+			defer srv.Stop()
+			conn := dialSchemaStation(t, srv, "CP-LC")
+
+			// Act
+			for i, status := range tt.statuses {
+				var location []string
+				if status == "Published" {
+					location = uris
+				}
+				reply := roundTrip(t, conn, publishStatusCall(t, fmt.Sprintf("p%d", i), tt.requestID, status, location...))
+				var msgType MessageType
+				json.Unmarshal(reply[0], &msgType)
+				if msgType != CallResult {
+					t.Fatalf("expected a CallResult for %s, got %s", status, reply[0])
+				}
+			}
+
+			// Assert
+			pub, err := repo.FindByRequestID(context.Background(), "CP-LC", 5)
+			if err != nil || pub == nil {
+				t.Fatalf("expected the publication, got %v", err)
+			}
+			if pub.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, pub.Status)
+			}
+			if len(pub.PublishedURIs) != tt.wantURIs {
+				t.Errorf("expected %d published URIs, got %v", tt.wantURIs, pub.PublishedURIs)
+			}
+		})
+	}
+}
+
+func TestUpdateFirmware_RefusedWhilePublishing(t *testing.T) {
+	// Arrange
+	repo := memory.NewFirmwarePublicationRepository()
+	seedPublication(t, repo, "CP-LC", domain.FirmwarePublishStatusDownloading)
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetFirmwarePublicationRepository(repo)
+	defer srv.Stop()
+	ctx := context.Background()
+
+	// Act
+	_, updateErr := srv.UpdateFirmware(ctx, "CP-LC", "https://fw.example.com/v3.bin", "2026-10-16T12:00:00Z", nil, nil, nil)
+	_, publishErr := srv.PublishFirmware(ctx, "CP-LC", "https://fw.example.com/v3.bin", "9e107d9d372bb6826bd81d3542a419d6", nil, nil)
+
+	// Assert
+	if !errors.Is(updateErr, domain.ErrFirmwareOperationInProgress) {
+		t.Errorf("expected UpdateFirmware to be refused, got %v", updateErr)
+	}
+	if !errors.Is(publishErr, domain.ErrFirmwareOperationInProgress) {
+		t.Errorf("expected a second publication to be refused, got %v", publishErr)
+	}
+}
+
+// answerPublishRequests accepts every PublishFirmware and sends the request
+// on the returned channel
+func answerPublishRequests(conn *websocket.Conn) <-chan PublishFirmwareRequest {
+	requested := make(chan PublishFirmwareRequest, 1)
+	go func() {
+		for {
+			var raw []json.RawMessage
+			if err := conn.ReadJSON(&raw); err != nil {
+				return
+			}
+			if len(raw) < 4 {
+				continue
+			}
+			var msgID string
+			var req PublishFirmwareRequest
+			json.Unmarshal(raw[1], &msgID)
+			json.Unmarshal(raw[3], &req)
+			requested <- req
+
+			reply, _ := json.Marshal([]interface{}{CallResult, msgID, PublishFirmwareResponse{Status: "Accepted"}})
+			if err := conn.WriteMessage(websocket.TextMessage, reply); err != nil {
+				return
+			}
+		}
+	}()
+	return requested
+}
+
+func TestPublishFirmware_WaitsForFirmwareUpdate(t *testing.T) {
+	// Arrange: the controller is installing firmware for itself
+	repo := memory.NewFirmwarePublicationRepository()
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetFirmwarePublicationRepository(repo)
+	defer srv.Stop()
+	conn := dialSchemaStation(t, srv, "CP-LC")
+	roundTrip(t, conn, `[2,"f1","FirmwareStatusNotification",{"status":"Installing","requestId":3}]`)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// Act
+	_, busyErr := srv.PublishFirmware(ctx, "CP-LC", "https://fw.example.com/v2.bin", "9e107d9d372bb6826bd81d3542a419d6", nil, nil)
+	roundTrip(t, conn, `[2,"f2","FirmwareStatusNotification",{"status":"Installed","requestId":3}]`)
+	requested := answerPublishRequests(conn)
+	resp, err := srv.PublishFirmware(ctx, "CP-LC", "https://fw.example.com/v2.bin", "9e107d9d372bb6826bd81d3542a419d6", nil, nil)
+
+	// Assert
+	if !errors.Is(busyErr, domain.ErrFirmwareOperationInProgress) {
+		t.Errorf("expected the publication to wait for the update, got %v", busyErr)
+	}
+	if err != nil || resp.Status != "Accepted" {
+		t.Fatalf("expected the publication to be accepted, got %+v, %v", resp, err)
+	}
+	req := <-requested
+	pubs, _ := repo.FindByChargePointID(ctx, "CP-LC", 10)
+	if len(pubs) != 1 || pubs[0].RequestID != req.RequestId || pubs[0].Status != domain.FirmwarePublishStatusAccepted {
+		t.Errorf("expected publication %d to be tracked as Accepted, got %+v", req.RequestId, pubs)
+	}
+}
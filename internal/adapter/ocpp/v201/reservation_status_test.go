@@ -0,0 +1,45 @@
+package v201
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// recordingReservationSync records the updates and links it receives
+type recordingReservationSync struct {
+	updates []string
+	links   []string
+}
+
+func (r *recordingReservationSync) ApplyStationUpdate(ctx context.Context, chargePointID string, reservationID int, status string) (*domain.Reservation, error) {
+	r.updates = append(r.updates, chargePointID+"/"+status)
+	return &domain.Reservation{ID: "res-1"}, nil
+}
+
+func (r *recordingReservationSync) LinkTransaction(ctx context.Context, chargePointID, idToken, transactionID string) error {
+	r.links = append(r.links, chargePointID+"/"+idToken+"/"+transactionID)
+	return nil
+}
+
+func TestHandleReservationStatusUpdate_Reconciles(t *testing.T) {
+	// Arrange
+	sync := &recordingReservationSync{}
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetReservationSync(sync)
+	defer srv.Stop()
+
+	// Act
+	_, err := srv.handleReservationStatusUpdate("CP001", []byte(`{"reservationId":77,"reservationUpdateStatus":"Expired"}`))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sync.updates) != 1 || sync.updates[0] != "CP001/Expired" {
+		t.Errorf("expected one Expired update for CP001, got %v", sync.updates)
+	}
+}
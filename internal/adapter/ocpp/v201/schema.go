@@ -0,0 +1,272 @@
+package v201
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// schemaFiles holds the OCPP 2.0.1 JSON schemas of the requests charge
+// points send, one <Action>Request.json per action
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// requestSchemas maps an action to the schema of its request payload
+var requestSchemas = mustLoadRequestSchemas()
+
+// jsonSchema is the subset of JSON schema (draft 06) the OCPP schemas use
+type jsonSchema struct {
+	Ref         string                 `json:"$ref"`
+	Type        schemaTypes            `json:"type"`
+	Properties  map[string]*jsonSchema `json:"properties"`
+	Required    []string               `json:"required"`
+	Items       *jsonSchema            `json:"items"`
+	Enum        []string               `json:"enum"`
+	MaxLength   *int                   `json:"maxLength"`
+	Minimum     *float64               `json:"minimum"`
+	MinItems    *int                   `json:"minItems"`
+	MaxItems    *int                   `json:"maxItems"`
+	Format      string                 `json:"format"`
+	Definitions map[string]*jsonSchema `json:"definitions"`
+
+	ref *jsonSchema // Ref, resolved at load
+}
+
+// schemaTypes is the "type" keyword, a single type or a list of types
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// schemaViolation describes why a payload does not match its schema
+type schemaViolation struct {
+	Code   string // OCPP CallError code
+	Field  string // Path of the offending field, empty for the payload itself
+	Reason string
+}
+
+func (v *schemaViolation) Error() string {
+	if v.Field == "" {
+		return v.Reason
+	}
+	return fmt.Sprintf("%s: %s", v.Field, v.Reason)
+}
+
+// details is sent as the errorDetails of the CallError
+func (v *schemaViolation) details() map[string]string {
+	return map[string]string{"field": v.Field, "reason": v.Reason}
+}
+
+func mustLoadRequestSchemas() map[string]*jsonSchema {
+	schemas, err := loadRequestSchemas()
+	if err != nil {
+		panic(err)
+	}
+	return schemas
+}
+
+func loadRequestSchemas() (map[string]*jsonSchema, error) {
+	files, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]*jsonSchema, len(files))
+	for _, f := range files {
+		data, err := schemaFiles.ReadFile(path.Join("schemas", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var schema jsonSchema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", f.Name(), err)
+		}
+		if err := schema.resolve(&schema); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", f.Name(), err)
+		}
+		schemas[strings.TrimSuffix(f.Name(), "Request.json")] = &schema
+	}
+	return schemas, nil
+}
+
+// resolve links every $ref below s to its definition in root
+func (s *jsonSchema) resolve(root *jsonSchema) error {
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/definitions/")
+		if !ok || root.Definitions[name] == nil {
+			return fmt.Errorf("unresolved $ref %s", s.Ref)
+		}
+		s.ref = root.Definitions[name]
+	}
+	children := make([]*jsonSchema, 0, len(s.Properties)+len(s.Definitions)+1)
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	for _, d := range s.Definitions {
+		children = append(children, d)
+	}
+	if s.Items != nil {
+		children = append(children, s.Items)
+	}
+	for _, child := range children {
+		if err := child.resolve(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePayload checks the payload of an incoming call against the schema
+// of its action. Actions without a schema are not checked.
+func validatePayload(action string, payload []byte) *schemaViolation {
+	schema, ok := requestSchemas[action]
+	if !ok {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber() // Tells integers from decimals
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return &schemaViolation{Code: "FormatViolation", Reason: "payload is not valid JSON"}
+	}
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return &schemaViolation{Code: "FormatViolation", Reason: "payload must be a JSON object"}
+	}
+	return schema.validate(doc, "")
+}
+
+func (s *jsonSchema) validate(value interface{}, field string) *schemaViolation {
+	if s.ref != nil {
+		return s.ref.validate(value, field)
+	}
+
+	if len(s.Type) > 0 && !s.Type.match(value) {
+		return &schemaViolation{
+			Code:   "TypeConstraintViolation",
+			Field:  field,
+			Reason: fmt.Sprintf("expected %s", strings.Join(s.Type, " or ")),
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &schemaViolation{Code: "OccurrenceConstraintViolation", Field: joinField(field, name), Reason: "required"}
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+			if child, ok := v[name]; ok {
+				if violation := s.Properties[name].validate(child, joinField(field, name)); violation != nil {
+					return violation
+				}
+			}
+		}
+
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return &schemaViolation{Code: "OccurrenceConstraintViolation", Field: field, Reason: fmt.Sprintf("at least %d items required", *s.MinItems)}
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return &schemaViolation{Code: "OccurrenceConstraintViolation", Field: field, Reason: fmt.Sprintf("at most %d items allowed", *s.MaxItems)}
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if violation := s.Items.validate(item, fmt.Sprintf("%s[%d]", field, i)); violation != nil {
+					return violation
+				}
+			}
+		}
+
+	case string:
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+			return &schemaViolation{Code: "PropertyConstraintViolation", Field: field, Reason: fmt.Sprintf("%q is not one of %s", v, strings.Join(s.Enum, ", "))}
+		}
+		if s.MaxLength != nil && utf8.RuneCountInString(v) > *s.MaxLength {
+			return &schemaViolation{Code: "PropertyConstraintViolation", Field: field, Reason: fmt.Sprintf("longer than %d characters", *s.MaxLength)}
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				return &schemaViolation{Code: "PropertyConstraintViolation", Field: field, Reason: "expected an RFC 3339 date-time"}
+			}
+		}
+
+	case json.Number:
+		if s.Minimum != nil {
+			if n, err := v.Float64(); err == nil && n < *s.Minimum {
+				return &schemaViolation{Code: "PropertyConstraintViolation", Field: field, Reason: fmt.Sprintf("below minimum %v", *s.Minimum)}
+			}
+		}
+	}
+	return nil
+}
+
+// match reports whether value is of one of the types
+func (t schemaTypes) match(value interface{}) bool {
+	for _, typ := range t {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if typ == "object" {
+				return true
+			}
+		case []interface{}:
+			if typ == "array" {
+				return true
+			}
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case nil:
+			if typ == "null" {
+				return true
+			}
+		case json.Number:
+			if typ == "number" {
+				return true
+			}
+			if _, err := v.Int64(); typ == "integer" && err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// SetSchemaValidation sets whether incoming calls are checked against the
+// OCPP 2.0.1 schemas before they are handled. It is on by default; turning it
+// off saves a decode per message for trusted fleets.
+func (s *Server) SetSchemaValidation(enabled bool) {
+	s.validateSchemas = enabled
+}
@@ -0,0 +1,246 @@
+package v201
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// dialSchemaStation connects a charge point that only sends calls and reads
+// the replies
+func dialSchemaStation(t *testing.T, srv *Server, chargePointID string) *websocket.Conn {
+	t.Helper()
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	t.Cleanup(httpSrv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	waitConnected(t, srv, chargePointID)
+	return conn
+}
+
+// roundTrip sends msg and returns the server's reply
+func roundTrip(t *testing.T, conn *websocket.Conn, msg string) []json.RawMessage {
+	t.Helper()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var reply []json.RawMessage
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatalf("expected a reply, got %v", err)
+	}
+	return reply
+}
+
+// schemaTransactionEvent is an Updated event of a transaction whose start
+// was not seen, so the server only buffers it
+func schemaTransactionEvent(overrides map[string]interface{}) string {
+	payload := map[string]interface{}{
+		"eventType":       "Updated",
+		"timestamp":       "2026-10-16T12:00:00Z",
+		"triggerReason":   "MeterValuePeriodic",
+		"seqNo":           5,
+		"transactionInfo": map[string]interface{}{"transactionId": "TX-1"},
+		"meterValue": []interface{}{map[string]interface{}{
+			"timestamp":    "2026-10-16T12:00:00Z",
+			"sampledValue": []interface{}{map[string]interface{}{"value": "1200", "measurand": "Energy.Active.Import.Register"}},
+		}},
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(payload, k)
+			continue
+		}
+		payload[k] = v
+	}
+	data, _ := json.Marshal([]interface{}{Call, "te-1", "TransactionEvent", payload})
+	return string(data)
+}
+
+func TestRequestSchemas_LoadForHandledActions(t *testing.T) {
+	// Arrange
+	actions := []string{
+		"BootNotification", "Heartbeat", "StatusNotification", "TransactionEvent", "MeterValues",
+		"FirmwareStatusNotification", "LogStatusNotification", "NotifyEVChargingNeeds",
+		"NotifyEVChargingSchedule", "ReportChargingProfiles", "Authorize", "NotifyEvent",
+		"NotifyReport", "NotifyCustomerInformation", "ReservationStatusUpdate", "DataTransfer",
+		"SignCertificate", "PublishFirmwareStatusNotification",
+	}
+
+	// Act
+	schemas, err := loadRequestSchemas()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected the embedded schemas to load, got %v", err)
+	}
+	for _, action := range actions {
+		if schemas[action] == nil {
+			t.Errorf("expected a schema for %s", action)
+		}
+	}
+}
+
+func TestValidatePayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    string
+		payload   string
+		wantCode  string
+		wantField string
+	}{
+		{"valid", "StatusNotification", `{"timestamp":"2026-10-16T12:00:00Z","connectorStatus":"Available","evseId":1,"connectorId":1}`, "", ""},
+		{"action without schema", "VendorSpecific", `[]`, "", ""},
+		{"not an object", "Heartbeat", `[]`, "FormatViolation", ""},
+		{"missing field", "StatusNotification", `{"timestamp":"2026-10-16T12:00:00Z","connectorStatus":"Available","evseId":1}`, "OccurrenceConstraintViolation", "connectorId"},
+		{"wrong type", "StatusNotification", `{"timestamp":"2026-10-16T12:00:00Z","connectorStatus":"Available","evseId":"1","connectorId":1}`, "TypeConstraintViolation", "evseId"},
+		{"decimal for integer", "StatusNotification", `{"timestamp":"2026-10-16T12:00:00Z","connectorStatus":"Available","evseId":1.5,"connectorId":1}`, "TypeConstraintViolation", "evseId"},
+		{"unknown enum value", "StatusNotification", `{"timestamp":"2026-10-16T12:00:00Z","connectorStatus":"Sleeping","evseId":1,"connectorId":1}`, "PropertyConstraintViolation", "connectorStatus"},
+		{"bad date-time", "StatusNotification", `{"timestamp":"yesterday","connectorStatus":"Available","evseId":1,"connectorId":1}`, "PropertyConstraintViolation", "timestamp"},
+		{"too long", "DataTransfer", `{"vendorId":"` + strings.Repeat("v", 256) + `"}`, "PropertyConstraintViolation", "vendorId"},
+		{"empty array", "MeterValues", `{"evseId":1,"meterValue":[]}`, "OccurrenceConstraintViolation", "meterValue"},
+		{"nested field", "MeterValues", `{"evseId":1,"meterValue":[{"timestamp":"2026-10-16T12:00:00Z","sampledValue":[{"measurand":"SoC"}]}]}`, "OccurrenceConstraintViolation", "meterValue[0].sampledValue[0].value"},
+		{"below minimum", "MeterValues", `{"evseId":-1,"meterValue":[{"timestamp":"2026-10-16T12:00:00Z","sampledValue":[{"value":1}]}]}`, "PropertyConstraintViolation", "evseId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			violation := validatePayload(tt.action, []byte(tt.payload))
+
+			// Assert
+			if tt.wantCode == "" {
+				if violation != nil {
+					t.Fatalf("expected no violation, got %v", violation)
+				}
+				return
+			}
+			if violation == nil {
+				t.Fatalf("expected %s, got none", tt.wantCode)
+			}
+			if violation.Code != tt.wantCode {
+				t.Errorf("expected code %s, got %s (%v)", tt.wantCode, violation.Code, violation)
+			}
+			if violation.Field != tt.wantField {
+				t.Errorf("expected field %q, got %q", tt.wantField, violation.Field)
+			}
+		})
+	}
+}
+
+func TestHandleAction_RejectsSchemaInvalidTransactionEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]interface{}
+		wantCode  string
+		wantField string
+	}{
+		{"missing transaction id", map[string]interface{}{"transactionInfo": map[string]interface{}{}}, "OccurrenceConstraintViolation", "transactionInfo.transactionId"},
+		{"unknown event type", map[string]interface{}{"eventType": "Paused"}, "PropertyConstraintViolation", "eventType"},
+		{"seqNo as string", map[string]interface{}{"seqNo": "5"}, "TypeConstraintViolation", "seqNo"},
+		{"missing trigger reason", map[string]interface{}{"triggerReason": nil}, "OccurrenceConstraintViolation", "triggerReason"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := NewServer(nil, nil, zap.NewNop())
+			defer srv.Stop()
+			conn := dialSchemaStation(t, srv, "CP-SCHEMA")
+
+			// Act
+			reply := roundTrip(t, conn, schemaTransactionEvent(tt.overrides))
+
+			// Assert
+			var msgType MessageType
+			var msgID, code string
+			var details map[string]string
+			json.Unmarshal(reply[0], &msgType)
+			json.Unmarshal(reply[1], &msgID)
+			if msgType != CallError || len(reply) != 5 {
+				t.Fatalf("expected a CallError, got %s", reply)
+			}
+			json.Unmarshal(reply[2], &code)
+			json.Unmarshal(reply[4], &details)
+			if msgID != "te-1" {
+				t.Errorf("expected the error to answer te-1, got %s", msgID)
+			}
+			if code != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, code)
+			}
+			if details["field"] != tt.wantField {
+				t.Errorf("expected field %s in the details, got %v", tt.wantField, details)
+			}
+			srv.seqMu.Lock()
+			_, handled := srv.txSequences[sequenceKey("CP-SCHEMA", "TX-1")]
+			srv.seqMu.Unlock()
+			if handled {
+				t.Error("expected the rejected event not to reach the transaction handler")
+			}
+		})
+	}
+}
+
+func TestHandleAction_SchemaValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		validate  bool
+		overrides map[string]interface{}
+		wantType  MessageType
+	}{
+		{"valid event passes", true, nil, CallResult},
+		{"invalid event rejected", true, map[string]interface{}{"triggerReason": "Whenever"}, CallError},
+		{"invalid event handled when disabled", false, map[string]interface{}{"triggerReason": "Whenever"}, CallResult},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := NewServer(nil, nil, zap.NewNop())
+			srv.seqGapTimeout = time.Hour
+			srv.SetSchemaValidation(tt.validate)
+			defer srv.Stop()
+			conn := dialSchemaStation(t, srv, "CP-SCHEMA")
+
+			// Act
+			reply := roundTrip(t, conn, schemaTransactionEvent(tt.overrides))
+
+			// Assert
+			var msgType MessageType
+			json.Unmarshal(reply[0], &msgType)
+			if msgType != tt.wantType {
+				t.Errorf("expected message type %d, got %s", tt.wantType, reply)
+			}
+		})
+	}
+}
+
+func TestHandleMessage_CallWithoutPayloadIsProtocolError(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	conn := dialSchemaStation(t, srv, "CP-SCHEMA")
+
+	// Act
+	reply := roundTrip(t, conn, `[2, "hb-1", "Heartbeat"]`)
+
+	// Assert
+	var msgType MessageType
+	var code string
+	json.Unmarshal(reply[0], &msgType)
+	json.Unmarshal(reply[2], &code)
+	if msgType != CallError || code != "ProtocolError" {
+		t.Fatalf("expected a ProtocolError CallError, got %s", reply)
+	}
+}
@@ -3,13 +3,20 @@ package v201
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
 )
 
 // SecurityConfig holds OCPP security configuration
@@ -36,10 +43,29 @@ type SecurityConfig struct {
 	RequireClientCert bool   // Enable mTLS
 
 	// Rate limiting
-	MaxConnectionsPerIP  int
+	ConnectionLimits
 	MaxMessagesPerMinute int
 }
 
+// ConnectionLimits caps WebSocket connections. A limit of zero or less is
+// not enforced.
+type ConnectionLimits struct {
+	MaxConnections       int           // Open connections across all IPs
+	MaxConnectionsPerIP  int           // Open connections from one IP
+	ConnectionRate       int           // New connections one IP may open per ConnectionRateWindow
+	ConnectionRateWindow time.Duration // Sliding window for ConnectionRate
+}
+
+// DefaultConnectionLimits returns the limits used when none are configured
+func DefaultConnectionLimits() ConnectionLimits {
+	return ConnectionLimits{
+		MaxConnections:       10000,
+		MaxConnectionsPerIP:  10,
+		ConnectionRate:       60,
+		ConnectionRateWindow: time.Minute,
+	}
+}
+
 // DefaultSecurityConfig returns a secure default configuration
 func DefaultSecurityConfig() *SecurityConfig {
 	return &SecurityConfig{
@@ -49,7 +75,7 @@ func DefaultSecurityConfig() *SecurityConfig {
 		RequireSubprotocol:   true,
 		TLSEnabled:           false,
 		RequireClientCert:    false,
-		MaxConnectionsPerIP:  10,
+		ConnectionLimits:     DefaultConnectionLimits(),
 		MaxMessagesPerMinute: 1000,
 	}
 }
@@ -61,6 +87,9 @@ type SecurityManager struct {
 	allowedOrigins      map[string]bool
 	allowedChargePoints map[string]bool
 	connectionCount     map[string]int
+	connectionTimes     map[string][]time.Time // Admissions per IP within the rate window
+	totalConnections    int
+	now                 func() time.Time
 	mu                  sync.RWMutex
 }
 
@@ -76,6 +105,8 @@ func NewSecurityManager(config *SecurityConfig, log *zap.Logger) *SecurityManage
 		allowedOrigins:      make(map[string]bool),
 		allowedChargePoints: make(map[string]bool),
 		connectionCount:     make(map[string]int),
+		connectionTimes:     make(map[string][]time.Time),
+		now:                 time.Now,
 	}
 
 	// Pre-populate allowed origins map for fast lookup
@@ -153,6 +184,49 @@ func (sm *SecurityManager) CheckOrigin(r *http.Request) bool {
 	return false
 }
 
+// MaxChargePointIDLength is the longest identity OCPP-J allows for a station
+const MaxChargePointIDLength = 48
+
+// chargePointIDPattern restricts IDs to characters that are safe in paths,
+// logs and cache keys
+var chargePointIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ErrInvalidChargePointID is returned when the ID in the connection URL is
+// empty, too long or contains characters outside [A-Za-z0-9_-]
+var ErrInvalidChargePointID = errors.New("invalid charge point ID")
+
+// ChargePointIDFromRequest extracts the charge point ID that follows prefix
+// in the request path. The escaped path is decoded exactly once, so encoded
+// slashes and double-encoded sequences are rejected rather than normalized
+// into something that passes validation.
+func (sm *SecurityManager) ChargePointIDFromRequest(r *http.Request, prefix string) (string, error) {
+	rawPath := r.URL.EscapedPath()
+	if !strings.HasPrefix(rawPath, prefix) {
+		return "", ErrInvalidChargePointID
+	}
+
+	chargePointID, err := url.PathUnescape(rawPath[len(prefix):])
+	if err != nil {
+		return "", ErrInvalidChargePointID
+	}
+	if err := ValidateChargePointID(chargePointID); err != nil {
+		return "", err
+	}
+	return chargePointID, nil
+}
+
+// ValidateChargePointID checks the format of a charge point ID. Unlike the
+// allow list this applies even when security checks are disabled.
+func ValidateChargePointID(chargePointID string) error {
+	if len(chargePointID) == 0 || len(chargePointID) > MaxChargePointIDLength {
+		return ErrInvalidChargePointID
+	}
+	if !chargePointIDPattern.MatchString(chargePointID) {
+		return ErrInvalidChargePointID
+	}
+	return nil
+}
+
 // ValidateChargePoint validates if a charge point ID is allowed to connect
 func (sm *SecurityManager) ValidateChargePoint(chargePointID string, r *http.Request) error {
 	if !sm.config.Enabled {
@@ -208,48 +282,144 @@ func (sm *SecurityManager) ValidateSubprotocol(r *http.Request) bool {
 	return false
 }
 
-// CheckRateLimit checks if the IP has exceeded connection limits
-func (sm *SecurityManager) CheckRateLimit(r *http.Request) bool {
-	if !sm.config.Enabled || sm.config.MaxConnectionsPerIP <= 0 {
-		return true
-	}
+// ConnectionRejection is why a connection was refused by a connection limit
+type ConnectionRejection string
 
-	ip := getClientIP(r)
+const (
+	RejectMaxConnections      ConnectionRejection = "max_connections"
+	RejectMaxConnectionsPerIP ConnectionRejection = "max_connections_per_ip"
+	RejectConnectionRate      ConnectionRejection = "connection_rate"
+)
 
-	sm.mu.RLock()
-	count := sm.connectionCount[ip]
-	sm.mu.RUnlock()
-
-	if count >= sm.config.MaxConnectionsPerIP {
-		sm.log.Warn("Rate limit exceeded",
-			zap.String("ip", ip),
-			zap.Int("connections", count),
-			zap.Int("limit", sm.config.MaxConnectionsPerIP),
-		)
-		return false
+// ConnectionLimitError is returned by AcquireConnection when a limit is reached
+type ConnectionLimitError struct {
+	Reason     ConnectionRejection
+	IP         string
+	Limit      int
+	RetryAfter time.Duration // Set for RejectConnectionRate
+}
+
+func (e *ConnectionLimitError) Error() string {
+	switch e.Reason {
+	case RejectMaxConnections:
+		return fmt.Sprintf("server is at its limit of %d connections", e.Limit)
+	case RejectMaxConnectionsPerIP:
+		return fmt.Sprintf("too many connections from %s (limit %d)", e.IP, e.Limit)
+	default:
+		return fmt.Sprintf("too many new connections from %s (limit %d per window)", e.IP, e.Limit)
+	}
+}
+
+// SetConnectionLimits replaces the connection limits. Zero fields keep their
+// defaults; negative ones turn the limit off.
+func (sm *SecurityManager) SetConnectionLimits(limits ConnectionLimits) {
+	defaults := DefaultConnectionLimits()
+	if limits.MaxConnections == 0 {
+		limits.MaxConnections = defaults.MaxConnections
+	}
+	if limits.MaxConnectionsPerIP == 0 {
+		limits.MaxConnectionsPerIP = defaults.MaxConnectionsPerIP
+	}
+	if limits.ConnectionRate == 0 {
+		limits.ConnectionRate = defaults.ConnectionRate
+	}
+	if limits.ConnectionRateWindow <= 0 {
+		limits.ConnectionRateWindow = defaults.ConnectionRateWindow
 	}
 
-	return true
+	sm.mu.Lock()
+	sm.config.ConnectionLimits = limits
+	sm.mu.Unlock()
 }
 
-// RegisterConnection increments the connection count for an IP
-func (sm *SecurityManager) RegisterConnection(r *http.Request) {
+// AcquireConnection admits a connection from the request's IP, or returns a
+// *ConnectionLimitError saying which limit it hit. An admitted connection
+// holds its slot until UnregisterConnection, which must be called exactly
+// once however the connection ends.
+func (sm *SecurityManager) AcquireConnection(r *http.Request) error {
 	ip := getClientIP(r)
 
 	sm.mu.Lock()
-	sm.connectionCount[ip]++
+	err := sm.admit(ip)
+	if err == nil {
+		sm.connectionCount[ip]++
+		sm.totalConnections++
+	}
 	sm.mu.Unlock()
+
+	if err != nil {
+		telemetry.RecordOCPPConnectionRejected(string(err.Reason))
+		return err
+	}
+	return nil
+}
+
+// admit checks the limits for ip and records the admission in the rate
+// window. Callers hold sm.mu.
+func (sm *SecurityManager) admit(ip string) *ConnectionLimitError {
+	limits := sm.config.ConnectionLimits
+	if !sm.config.Enabled {
+		return nil
+	}
+
+	if limits.MaxConnections > 0 && sm.totalConnections >= limits.MaxConnections {
+		return &ConnectionLimitError{Reason: RejectMaxConnections, IP: ip, Limit: limits.MaxConnections}
+	}
+	if limits.MaxConnectionsPerIP > 0 && sm.connectionCount[ip] >= limits.MaxConnectionsPerIP {
+		return &ConnectionLimitError{Reason: RejectMaxConnectionsPerIP, IP: ip, Limit: limits.MaxConnectionsPerIP}
+	}
+
+	if limits.ConnectionRate <= 0 || limits.ConnectionRateWindow <= 0 {
+		return nil
+	}
+	now := sm.now()
+	times := sm.connectionTimes[ip]
+	cutoff := now.Add(-limits.ConnectionRateWindow)
+	for len(times) > 0 && !times[0].After(cutoff) {
+		times = times[1:]
+	}
+	if len(times) >= limits.ConnectionRate {
+		sm.connectionTimes[ip] = times
+		return &ConnectionLimitError{
+			Reason:     RejectConnectionRate,
+			IP:         ip,
+			Limit:      limits.ConnectionRate,
+			RetryAfter: times[0].Sub(cutoff),
+		}
+	}
+	sm.connectionTimes[ip] = append(times, now)
+	return nil
 }
 
-// UnregisterConnection decrements the connection count for an IP
+// UnregisterConnection releases the slot taken by AcquireConnection
 func (sm *SecurityManager) UnregisterConnection(r *http.Request) {
 	ip := getClientIP(r)
 
 	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.connectionCount[ip] == 0 {
+		return
+	}
+	sm.connectionCount[ip]--
+	sm.totalConnections--
 	if sm.connectionCount[ip] > 0 {
-		sm.connectionCount[ip]--
+		return
 	}
-	sm.mu.Unlock()
+	delete(sm.connectionCount, ip)
+
+	// Forget the rate window of an idle IP once it has expired
+	times := sm.connectionTimes[ip]
+	if len(times) == 0 || !times[len(times)-1].After(sm.now().Add(-sm.config.ConnectionRateWindow)) {
+		delete(sm.connectionTimes, ip)
+	}
+}
+
+// ConnectionCount returns the open connections, in total and from ip
+func (sm *SecurityManager) ConnectionCount(ip string) (total, fromIP int) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.totalConnections, sm.connectionCount[ip]
 }
 
 // AddAllowedChargePoint dynamically adds a charge point to the allowed list
@@ -301,8 +471,9 @@ func (sm *SecurityManager) GetTLSConfig() (*tls.Config, error) {
 		},
 	}
 
-	// Configure mTLS if required
-	if sm.config.RequireClientCert && sm.config.TLSClientCA != "" {
+	// Configure mTLS. With a client CA but no global requirement, certificates
+	// are verified when presented so devices can opt in to pinning.
+	if sm.config.TLSClientCA != "" {
 		caCert, err := os.ReadFile(sm.config.TLSClientCA)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read client CA certificate: %w", err)
@@ -314,12 +485,50 @@ func (sm *SecurityManager) GetTLSConfig() (*tls.Config, error) {
 		}
 
 		tlsConfig.ClientCAs = caCertPool
-		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if sm.config.RequireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
 	}
 
 	return tlsConfig, nil
 }
 
+// ErrClientCertificateMismatch is returned when a charge point that pinned a
+// client certificate connects without it or with a different one
+var ErrClientCertificateMismatch = errors.New("client certificate does not match charge point")
+
+// VerifyClientCertificate matches the certificate presented on the TLS
+// connection against the identity pinned for the charge point. Devices
+// without a pinned identity are accepted as-is.
+func (sm *SecurityManager) VerifyClientCertificate(cp *domain.ChargePoint, state *tls.ConnectionState) error {
+	if cp == nil || !cp.RequiresClientCertificate() {
+		return nil
+	}
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("%w: no client certificate presented", ErrClientCertificateMismatch)
+	}
+
+	cert := state.PeerCertificates[0]
+	if cp.CertCommonName != "" && cert.Subject.CommonName != cp.CertCommonName {
+		return fmt.Errorf("%w: common name %q", ErrClientCertificateMismatch, cert.Subject.CommonName)
+	}
+	if cp.CertSerialNumber != "" && cert.SerialNumber.Text(16) != normalizeSerial(cp.CertSerialNumber) {
+		return fmt.Errorf("%w: serial %s", ErrClientCertificateMismatch, cert.SerialNumber.Text(16))
+	}
+	return nil
+}
+
+// normalizeSerial turns "0A:1B:..." or "0a1b..." into big.Int.Text(16) form
+func normalizeSerial(serial string) string {
+	serial = strings.ToLower(strings.ReplaceAll(serial, ":", ""))
+	serial = strings.TrimLeft(serial, "0")
+	if serial == "" {
+		return "0"
+	}
+	return serial
+}
+
 // getClientIP extracts the client IP from the request
 func getClientIP(r *http.Request) string {
 	// Check X-Forwarded-For header (for reverse proxy)
@@ -0,0 +1,410 @@
+package v201
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+func TestChargePointIDFromRequest(t *testing.T) {
+	sm := NewSecurityManager(DefaultSecurityConfig(), zap.NewNop())
+
+	tests := []struct {
+		name      string
+		path      string
+		wantID    string
+		wantError bool
+	}{
+		{"valid", "/ocpp/CP-001_A", "CP-001_A", false},
+		{"max length", "/ocpp/" + strings.Repeat("A", MaxChargePointIDLength), strings.Repeat("A", MaxChargePointIDLength), false},
+		{"empty", "/ocpp/", "", true},
+		{"overlong", "/ocpp/" + strings.Repeat("A", MaxChargePointIDLength+1), "", true},
+		{"traversal", "/ocpp/../admin", "", true},
+		{"nested path", "/ocpp/CP001/extra", "", true},
+		{"encoded slash", "/ocpp/CP001%2F..%2Fadmin", "", true},
+		{"double encoded", "/ocpp/%252e%252e", "", true},
+		{"encoded space", "/ocpp/CP%20001", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://localhost"+tt.path, nil)
+
+			id, err := sm.ChargePointIDFromRequest(req, "/ocpp/")
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected %q to be rejected, got ID %q", tt.path, id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected %q to be accepted, got %v", tt.path, err)
+			}
+			if id != tt.wantID {
+				t.Errorf("expected ID %q, got %q", tt.wantID, id)
+			}
+		})
+	}
+}
+
+func TestHandleConnection_RejectsInvalidChargePointID(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+
+	paths := []string{
+		"/ocpp/..%2F..%2Fetc",
+		"/ocpp/" + strings.Repeat("X", 500),
+	}
+
+	for _, path := range paths {
+		req := httptest.NewRequest("GET", "http://localhost"+path, nil)
+		req.Header.Set("Sec-WebSocket-Protocol", "ocpp2.0.1")
+		rec := httptest.NewRecorder()
+
+		// Act
+		srv.handleConnection(rec, req)
+
+		// Assert
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %.40s, got %d", path, rec.Code)
+		}
+	}
+}
+
+// issueClientCert creates a self-signed client certificate for commonName
+func issueClientCert(t *testing.T, commonName string, serial int64) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// dialPinnedServer serves srv over TLS and connects as chargePointID with clientCert
+func dialPinnedServer(t *testing.T, srv *Server, chargePointID string, clientCert tls.Certificate) (*websocket.Conn, func()) {
+	t.Helper()
+
+	httpSrv := httptest.NewUnstartedServer(http.HandlerFunc(srv.handleConnection))
+	httpSrv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	httpSrv.StartTLS()
+
+	dialer := websocket.Dialer{
+		Subprotocols: []string{"ocpp2.0.1"},
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			Certificates:       []tls.Certificate{clientCert},
+		},
+	}
+	conn, _, err := dialer.Dial("wss"+strings.TrimPrefix(httpSrv.URL, "https")+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		httpSrv.Close()
+		t.Fatalf("failed to connect: %v", err)
+	}
+	return conn, func() {
+		conn.Close()
+		httpSrv.Close()
+	}
+}
+
+func pinnedDeviceService(cp *domain.ChargePoint) *mocks.MockDeviceService {
+	return &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			if id != cp.ID {
+				return nil, nil
+			}
+			return cp, nil
+		},
+	}
+}
+
+func TestHandleConnection_PinnedCertificateAccepted(t *testing.T) {
+	// Arrange
+	cp := &domain.ChargePoint{ID: "CP-PINNED", CertCommonName: "CP-PINNED", CertSerialNumber: "00:30:39"}
+	srv := NewServer(pinnedDeviceService(cp), nil, zap.NewNop())
+	defer srv.Stop()
+
+	// Act
+	_, closeConn := dialPinnedServer(t, srv, "CP-PINNED", issueClientCert(t, "CP-PINNED", 12345))
+	defer closeConn()
+
+	// Assert
+	deadline := time.Now().Add(2 * time.Second)
+	for !srv.IsConnected("CP-PINNED") {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the charge point with a matching certificate to register")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandleConnection_MismatchedCertificateRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		cert func(t *testing.T) tls.Certificate
+	}{
+		{"other common name", func(t *testing.T) tls.Certificate { return issueClientCert(t, "CP-OTHER", 12345) }},
+		{"other serial", func(t *testing.T) tls.Certificate { return issueClientCert(t, "CP-PINNED", 99) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cp := &domain.ChargePoint{ID: "CP-PINNED", CertCommonName: "CP-PINNED", CertSerialNumber: "3039"}
+			srv := NewServer(pinnedDeviceService(cp), nil, zap.NewNop())
+			defer srv.Stop()
+
+			// Act
+			conn, closeConn := dialPinnedServer(t, srv, "CP-PINNED", tt.cert(t))
+			defer closeConn()
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			_, _, err := conn.ReadMessage()
+
+			// Assert
+			if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+				t.Errorf("expected a policy violation close, got %v", err)
+			}
+			if srv.IsConnected("CP-PINNED") {
+				t.Error("expected the charge point not to be registered")
+			}
+		})
+	}
+}
+
+func TestVerifyClientCertificate_NotPinned(t *testing.T) {
+	sm := NewSecurityManager(DefaultSecurityConfig(), zap.NewNop())
+
+	if err := sm.VerifyClientCertificate(&domain.ChargePoint{ID: "CP-OPEN"}, nil); err != nil {
+		t.Errorf("expected devices without a pin to be accepted, got %v", err)
+	}
+	pinned := &domain.ChargePoint{ID: "CP-PINNED", CertCommonName: "CP-PINNED"}
+	if err := sm.VerifyClientCertificate(pinned, nil); !errors.Is(err, ErrClientCertificateMismatch) {
+		t.Errorf("expected a pinned device without certificate to be rejected, got %v", err)
+	}
+}
+
+func requestFrom(ip string) *http.Request {
+	req := httptest.NewRequest("GET", "http://localhost/ocpp/CP001", nil)
+	req.RemoteAddr = ip + ":40000"
+	return req
+}
+
+func newLimitedSecurityManager(limits ConnectionLimits) *SecurityManager {
+	cfg := DefaultSecurityConfig()
+	cfg.ConnectionLimits = limits
+	return NewSecurityManager(cfg, zap.NewNop())
+}
+
+func expectRejection(t *testing.T, err error, reason ConnectionRejection) {
+	t.Helper()
+	var limitErr *ConnectionLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected a %s rejection, got %v", reason, err)
+	}
+	if limitErr.Reason != reason {
+		t.Errorf("expected reason %s, got %s", reason, limitErr.Reason)
+	}
+}
+
+func TestAcquireConnection_PerIPCap(t *testing.T) {
+	// Arrange
+	sm := newLimitedSecurityManager(ConnectionLimits{MaxConnectionsPerIP: 2})
+	first := requestFrom("192.0.2.1")
+
+	// Act
+	errs := []error{
+		sm.AcquireConnection(first),
+		sm.AcquireConnection(requestFrom("192.0.2.1")),
+		sm.AcquireConnection(requestFrom("192.0.2.1")),
+	}
+	errOtherIP := sm.AcquireConnection(requestFrom("192.0.2.2"))
+
+	// Assert
+	if errs[0] != nil || errs[1] != nil {
+		t.Fatalf("expected the first two connections to be admitted, got %v, %v", errs[0], errs[1])
+	}
+	expectRejection(t, errs[2], RejectMaxConnectionsPerIP)
+	if errOtherIP != nil {
+		t.Errorf("expected another IP to be admitted, got %v", errOtherIP)
+	}
+
+	sm.UnregisterConnection(first)
+	if err := sm.AcquireConnection(requestFrom("192.0.2.1")); err != nil {
+		t.Errorf("expected a freed slot to be reusable, got %v", err)
+	}
+}
+
+func TestAcquireConnection_GlobalCap(t *testing.T) {
+	// Arrange
+	sm := newLimitedSecurityManager(ConnectionLimits{MaxConnections: 2, MaxConnectionsPerIP: 10})
+	first := requestFrom("192.0.2.1")
+	if err := sm.AcquireConnection(first); err != nil {
+		t.Fatalf("expected admission, got %v", err)
+	}
+	if err := sm.AcquireConnection(requestFrom("192.0.2.2")); err != nil {
+		t.Fatalf("expected admission, got %v", err)
+	}
+
+	// Act
+	err := sm.AcquireConnection(requestFrom("192.0.2.3"))
+
+	// Assert
+	expectRejection(t, err, RejectMaxConnections)
+	sm.UnregisterConnection(first)
+	if total, fromIP := sm.ConnectionCount("192.0.2.1"); total != 1 || fromIP != 0 {
+		t.Errorf("expected 1 connection in total and none from 192.0.2.1, got %d and %d", total, fromIP)
+	}
+	if err := sm.AcquireConnection(requestFrom("192.0.2.3")); err != nil {
+		t.Errorf("expected admission below the cap, got %v", err)
+	}
+}
+
+func TestAcquireConnection_SlidingWindowRate(t *testing.T) {
+	// Arrange
+	sm := newLimitedSecurityManager(ConnectionLimits{
+		MaxConnectionsPerIP:  10,
+		ConnectionRate:       2,
+		ConnectionRateWindow: time.Minute,
+	})
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	sm.now = func() time.Time { return now }
+
+	// Act - two connections 30s apart, then a third 40s after the first
+	for i := 0; i < 2; i++ {
+		req := requestFrom("192.0.2.1")
+		if err := sm.AcquireConnection(req); err != nil {
+			t.Fatalf("expected connection %d to be admitted, got %v", i, err)
+		}
+		sm.UnregisterConnection(req)
+		now = now.Add(30 * time.Second)
+	}
+	now = now.Add(-20 * time.Second)
+	errTooSoon := sm.AcquireConnection(requestFrom("192.0.2.1"))
+	now = now.Add(21 * time.Second)
+	errAfterWindow := sm.AcquireConnection(requestFrom("192.0.2.1"))
+
+	// Assert
+	expectRejection(t, errTooSoon, RejectConnectionRate)
+	var limitErr *ConnectionLimitError
+	if errors.As(errTooSoon, &limitErr) && limitErr.RetryAfter != 20*time.Second {
+		t.Errorf("expected to retry after 20s, got %v", limitErr.RetryAfter)
+	}
+	if errAfterWindow != nil {
+		t.Errorf("expected admission once the first connection left the window, got %v", errAfterWindow)
+	}
+}
+
+func TestHandleConnection_RejectsOverPerIPCap(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetConnectionLimits(ConnectionLimits{MaxConnectionsPerIP: 1})
+	if err := srv.GetSecurityManager().AcquireConnection(requestFrom("192.0.2.1")); err != nil {
+		t.Fatalf("expected admission, got %v", err)
+	}
+
+	req := requestFrom("192.0.2.1")
+	req.Header.Set("Sec-WebSocket-Protocol", "ocpp2.0.1")
+	rec := httptest.NewRecorder()
+
+	// Act
+	srv.handleConnection(rec, req)
+
+	// Assert
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", rec.Code)
+	}
+	if total, _ := srv.GetSecurityManager().ConnectionCount("192.0.2.1"); total != 1 {
+		t.Errorf("expected the rejected connection not to hold a slot, got %d connections", total)
+	}
+}
+
+func waitConnectionCount(t *testing.T, sm *SecurityManager, expected int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		total, _ := sm.ConnectionCount("127.0.0.1")
+		if total == expected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d open connections, got %d", expected, total)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandleConnection_ReleasesSlotOnTakeover(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	sm := srv.GetSecurityManager()
+
+	closeFirst := dialKeepaliveServer(t, srv, "CP-TAKEOVER", true)
+	defer closeFirst()
+	waitConnected(t, srv, "CP-TAKEOVER")
+
+	// Act - the station reconnects before its old connection is gone
+	closeSecond := dialKeepaliveServer(t, srv, "CP-TAKEOVER", true)
+	waitConnectionCount(t, sm, 1)
+
+	// Assert
+	if !srv.IsConnected("CP-TAKEOVER") {
+		t.Fatal("expected the new connection to survive the takeover")
+	}
+	closeSecond()
+	waitConnectionCount(t, sm, 0)
+}
+
+func TestHandleConnection_ReleasesSlotWhenReaped(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	srv.SetKeepalive(KeepaliveConfig{PingInterval: 50 * time.Millisecond, PongTimeout: 50 * time.Millisecond})
+	defer srv.Stop()
+
+	closeConn := dialKeepaliveServer(t, srv, "CP-SILENT", false)
+	defer closeConn()
+	waitConnected(t, srv, "CP-SILENT")
+
+	// Act & Assert
+	waitConnectionCount(t, srv.GetSecurityManager(), 0)
+	if srv.IsConnected("CP-SILENT") {
+		t.Error("expected the unresponsive charge point to be reaped")
+	}
+}
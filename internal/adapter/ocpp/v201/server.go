@@ -6,14 +6,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -40,6 +46,7 @@ type PendingRequest struct {
 	ResponseChan  chan *CommandResponse
 	Timeout       time.Time
 	CreatedAt     time.Time
+	Restored      bool // Reloaded from storage after a restart; nobody waits on it
 }
 
 // Server configuration constants
@@ -53,13 +60,61 @@ type Server struct {
 	txService       ports.TransactionService
 	log             *zap.Logger
 	clients         map[string]*websocket.Conn
-	clientRequests  map[string]*http.Request // Track request for unregister
 	pendingRequests map[string]*PendingRequest // Track pending CSMS → CP requests
+	pendingStore    ports.PendingCommandRepository // optional, see SetPendingCommandRepository
+	restoredResults RestoredResultHandler          // optional, see SetRestoredResultHandler
+	inboundWaiters  map[string][]*inboundWaiter    // chargePointID → connection tests awaiting a triggered message
+	evseTxIDs       map[evseKey]string         // EVSE → OCPP transactionId in progress
+	monitors        map[string]map[int]SetMonitoringData // chargePointID → monitorId → accepted monitor
+	profiles        map[string]map[int]installedProfile  // chargePointID → profileId → accepted charging profile
+	siteCapacity    SiteCapacityConfig
+	chargeRates     ChargeRateConfig // see SetChargeRateConfig
+	alertRepo       ports.AlertRepository
+	faults          ports.FaultService
+	reservations    ports.ReservationSync
+	reports         map[string]*reportAssembly // chargePointID:requestId → NotifyReport parts
+	customerInfo    map[string]*customerInfoAssembly // chargePointID:requestId → NotifyCustomerInformation parts
+	inventoryRepo   ports.DeviceInventoryRepository
+	meterValues     ports.MeterValueRepository // optional, see SetMeterValueRepository
+	profileReports  map[string]*profileReportAssembly // chargePointID:requestId → ReportChargingProfiles parts
+	profileReportRepo ports.ChargingProfileReportRepository // optional, see SetChargingProfileReportRepository
+	logRequests     ports.LogRequestRepository
+	firmwarePubs    ports.FirmwarePublicationRepository // optional, see SetFirmwarePublicationRepository
+	firmwareStatus  map[string]string // chargePointID → last firmware update status
+	firmware        ports.FirmwareService // optional, see SetFirmwareService
+	connEvents      ports.ConnectionEventRepository // optional, see SetConnectionEventRepository
+	certificates    ports.StationCertificateService
+	txSequences     map[string]*txSequence // chargePointID/transactionId → TransactionEvent ordering
+	seqGapTimeout   time.Duration
+	gridPrices      ports.GridPriceService
+	bptConfig       BPTScheduleConfig
+	v2g             *V2GManager // EV capabilities reported in NotifyEVChargingNeeds
+	heartbeat       domain.HeartbeatPolicy
+	keepalive       KeepaliveConfig
+	compression     CompressionConfig
+	compressionStats map[string]*compressionStats // chargePointID → bytes saved by compression, nil if uncompressed
+	validateSchemas bool // see SetSchemaValidation
+	variables       *variableCache // GetVariables read-through cache
+	dataTransferHandlers map[string]DataTransferHandler // vendorId → handler
 	mu              sync.RWMutex
 	pendingMu       sync.RWMutex // Separate mutex for pending requests
+	txMu            sync.RWMutex // Mutex for evseTxIDs
+	monitorMu       sync.RWMutex // Mutex for monitors
+	profileMu       sync.RWMutex // Mutex for profiles
+	capacityMu      sync.Mutex   // Serializes site capacity rescaling
+	reportMu        sync.Mutex   // Mutex for reports
+	profileReportMu sync.Mutex   // Mutex for profileReports
+	customerInfoMu  sync.Mutex   // Mutex for customerInfo
+	seqMu           sync.Mutex   // Mutex for txSequences
+	dataTransferMu  sync.RWMutex // Mutex for dataTransferHandlers
+	waiterMu        sync.Mutex   // Mutex for inboundWaiters
+	firmwareMu      sync.Mutex   // Mutex for firmwareStatus
 	upgrader        websocket.Upgrader
 	securityManager *SecurityManager
 	stopCleanup     chan struct{}
+	httpServer      *http.Server // Set by Start/StartTLS, shut down by Drain
+	draining        atomic.Bool
+	inflight        atomic.Int64 // Messages from charge points being handled
 }
 
 // NewServer creates a new OCPP 2.0.1 server with default security (disabled)
@@ -76,17 +131,35 @@ func NewServerWithSecurity(deviceService ports.DeviceService, txService ports.Tr
 		txService:       txService,
 		log:             log,
 		clients:         make(map[string]*websocket.Conn),
-		clientRequests:  make(map[string]*http.Request),
 		pendingRequests: make(map[string]*PendingRequest),
+		inboundWaiters:  make(map[string][]*inboundWaiter),
+		evseTxIDs:       make(map[evseKey]string),
+		monitors:        make(map[string]map[int]SetMonitoringData),
+		profiles:        make(map[string]map[int]installedProfile),
+		reports:         make(map[string]*reportAssembly),
+		profileReports:  make(map[string]*profileReportAssembly),
+		customerInfo:    make(map[string]*customerInfoAssembly),
+		txSequences:     make(map[string]*txSequence),
+		firmwareStatus:  make(map[string]string),
+		dataTransferHandlers: make(map[string]DataTransferHandler),
+		seqGapTimeout:   DefaultSeqGapTimeout,
+		bptConfig:       DefaultBPTScheduleConfig(),
+		keepalive:       DefaultKeepaliveConfig(),
+		compression:     DefaultCompressionConfig(),
+		compressionStats: make(map[string]*compressionStats),
+		validateSchemas: true,
 		securityManager: sm,
 		stopCleanup:     make(chan struct{}),
 	}
+	s.variables = newVariableCache(DefaultVariableCacheConfig(), s.GetVariables, log)
+	s.v2g = NewV2GManager(s, log)
 
 	s.upgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin:     sm.CheckOrigin,
 		Subprotocols:    []string{"ocpp2.0.1", "ocpp2.0"},
+		EnableCompression: s.compression.Enabled,
 	}
 
 	// Start background cleanup of expired pending requests
@@ -106,6 +179,9 @@ func (s *Server) Start(port int) error {
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	s.mu.Lock()
+	s.httpServer = server
+	s.mu.Unlock()
 
 	// Configure TLS if enabled
 	tlsConfig, err := s.securityManager.GetTLSConfig()
@@ -113,14 +189,21 @@ func (s *Server) Start(port int) error {
 		return fmt.Errorf("failed to configure TLS: %w", err)
 	}
 
+	// Wire bytes are counted to measure what compression saves
+	ln, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+	ln = countingListener{ln}
+
 	if tlsConfig != nil {
 		server.TLSConfig = tlsConfig
 		s.log.Info("Starting OCPP server with TLS", zap.Int("port", port))
-		return server.ListenAndServeTLS("", "") // Certs are in TLSConfig
+		return server.ServeTLS(ln, "", "") // Certs are in TLSConfig
 	}
 
 	s.log.Info("Starting OCPP server", zap.Int("port", port))
-	return server.ListenAndServe()
+	return server.Serve(ln)
 }
 
 // StartTLS starts the server with TLS using provided cert and key files
@@ -139,8 +222,17 @@ func (s *Server) StartTLS(port int, certFile, keyFile string) error {
 		},
 	}
 
+	s.mu.Lock()
+	s.httpServer = server
+	s.mu.Unlock()
+
+	ln, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+
 	s.log.Info("Starting OCPP server with TLS", zap.Int("port", port))
-	return server.ListenAndServeTLS(certFile, keyFile)
+	return server.ServeTLS(countingListener{ln}, certFile, keyFile)
 }
 
 func (s *Server) Stop() {
@@ -172,9 +264,19 @@ func (s *Server) Stop() {
 }
 
 func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
-	chargePointID := r.URL.Path[len("/ocpp/"):]
-	if chargePointID == "" {
-		http.Error(w, "ChargePointID required", http.StatusBadRequest)
+	// Security: Validate the charge point ID before anything else uses it
+	chargePointID, err := s.securityManager.ChargePointIDFromRequest(r, "/ocpp/")
+	if err != nil {
+		s.log.Warn("Invalid charge point ID",
+			zap.String("path", r.URL.EscapedPath()),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		http.Error(w, "Invalid ChargePointID", http.StatusBadRequest)
+		return
+	}
+
+	// Stations reconnect elsewhere while this instance shuts down
+	if s.rejectWhileDraining(w) {
 		return
 	}
 
@@ -198,15 +300,13 @@ func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Security: Check rate limit
-	if !s.securityManager.CheckRateLimit(r) {
-		s.log.Warn("Rate limit exceeded",
-			zap.String("chargePointID", chargePointID),
-			zap.String("remote_addr", r.RemoteAddr),
-		)
-		http.Error(w, "Too many connections", http.StatusTooManyRequests)
+	// Security: Connection limits. The slot is held until this handler
+	// returns, whichever way the connection ends.
+	if err := s.securityManager.AcquireConnection(r); err != nil {
+		s.rejectConnection(w, r, chargePointID, err)
 		return
 	}
+	defer s.securityManager.UnregisterConnection(r)
 
 	// Upgrade to WebSocket (CheckOrigin is handled by upgrader via SecurityManager)
 	conn, err := s.upgrader.Upgrade(w, r, nil)
@@ -215,52 +315,142 @@ func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Register connection for rate limiting
-	s.securityManager.RegisterConnection(r)
+	// Security: Match the client certificate for devices that pinned one
+	if err := s.verifyClientCertificate(r.Context(), chargePointID, r); err != nil {
+		s.log.Warn("Client certificate rejected",
+			zap.String("chargePointID", chargePointID),
+			zap.String("remote_addr", r.RemoteAddr),
+			zap.Error(err),
+		)
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "client certificate mismatch"),
+			time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
 
-	s.registerClient(chargePointID, conn, r)
-	defer s.unregisterClient(chargePointID)
+	// The upgrader accepted permessage-deflate if the station offered it
+	compressed := s.compression.Enabled && offersCompression(r)
+	if compressed {
+		conn.SetCompressionLevel(s.compression.Level)
+	}
+	telemetry.RecordOCPPCompressionNegotiated(compressed)
+	stats := newCompressionStats(conn, compressed)
+
+	s.registerClient(chargePointID, conn, r.RemoteAddr)
+	reason := domain.DisconnectReasonError
+	defer func() { s.unregisterClient(chargePointID, conn, reason) }()
+	s.mu.Lock()
+	s.compressionStats[chargePointID] = stats
+	s.mu.Unlock()
+
+	stopKeepalive := s.startKeepalive(chargePointID, conn)
+	defer stopKeepalive()
 
 	s.log.Info("New OCPP connection",
 		zap.String("chargePointID", chargePointID),
 		zap.String("remote_addr", r.RemoteAddr),
+		zap.Bool("compressed", compressed),
 	)
 
 	for {
 		// Read message (Call, CallResult, CallError)
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			reason = disconnectReason(err)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				s.log.Warn("Charge point stopped answering pings, closing connection",
+					zap.String("chargePointID", chargePointID),
+				)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				s.log.Error("WebSocket error", zap.Error(err))
 			}
 			break
 		}
+		conn.SetReadDeadline(time.Now().Add(s.keepalive.readWindow()))
+		stats.received(len(message))
 
+		s.inflight.Add(1)
 		s.handleMessage(chargePointID, message)
+		s.inflight.Add(-1)
 	}
 }
 
-func (s *Server) registerClient(id string, conn *websocket.Conn, r *http.Request) {
+// rejectConnection answers a connection refused by a connection limit. The
+// server-wide cap is reported as unavailable, per-IP caps as too many requests.
+func (s *Server) rejectConnection(w http.ResponseWriter, r *http.Request, chargePointID string, err error) {
+	status := http.StatusTooManyRequests
+	reason := ""
+	var limitErr *ConnectionLimitError
+	if errors.As(err, &limitErr) {
+		reason = string(limitErr.Reason)
+		if limitErr.Reason == RejectMaxConnections {
+			status = http.StatusServiceUnavailable
+		}
+		if limitErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+		}
+	}
+
+	s.log.Warn("Connection rejected by connection limits",
+		zap.String("chargePointID", chargePointID),
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.String("reason", reason),
+		zap.Error(err),
+	)
+	http.Error(w, err.Error(), status)
+}
+
+// registerClient makes conn the charge point's connection. A station that
+// reconnects while its old connection is still open takes over: the old
+// connection is closed and commands sent on it are failed.
+func (s *Server) registerClient(id string, conn *websocket.Conn, remoteAddr string) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	previous := s.clients[id]
 	s.clients[id] = conn
-	s.clientRequests[id] = r
+	s.mu.Unlock()
+
+	now := time.Now()
+	if previous != nil && previous != conn {
+		s.log.Info("Charge point reconnected, closing its previous connection",
+			zap.String("chargePointID", id),
+		)
+		previous.Close()
+		s.cancelPendingRequests(id)
+		s.variables.forget(id)
+		s.recordConnectionEvent(id, domain.ConnectionEventDisconnected, "", domain.DisconnectReasonReplaced, now)
+	}
+	s.recordConnectionEvent(id, domain.ConnectionEventConnected, remoteAddr, "", now)
+
+	// Answers are read once the read loop runs
+	go s.reconcileTransactions(id)
 }
 
-func (s *Server) unregisterClient(id string) {
+// unregisterClient removes conn when it is still the charge point's
+// connection. A connection that was taken over is only closed, so it
+// cannot tear down its replacement.
+func (s *Server) unregisterClient(id string, conn *websocket.Conn, reason string) {
 	s.mu.Lock()
-	r := s.clientRequests[id]
-	if conn, ok := s.clients[id]; ok {
-		conn.Close()
+	current := s.clients[id] == conn
+	if current {
 		delete(s.clients, id)
-		delete(s.clientRequests, id)
+		delete(s.compressionStats, id)
 	}
 	s.mu.Unlock()
 
-	// Unregister from rate limiter
-	if r != nil {
-		s.securityManager.UnregisterConnection(r)
+	conn.Close()
+	if !current {
+		return
 	}
+
+	s.recordConnectionEvent(id, domain.ConnectionEventDisconnected, "", reason, time.Now())
+
+	// Responses to commands sent on the lost connection can never arrive
+	s.cancelPendingRequests(id)
+
+	// The station may come back rebooted or with new firmware
+	s.variables.forget(id)
 }
 
 func (s *Server) handleMessage(chargePointID string, data []byte) {
@@ -292,9 +482,15 @@ func (s *Server) handleMessage(chargePointID string, data []byte) {
 		var action string
 		if err := json.Unmarshal(raw[2], &action); err != nil {
 			s.log.Error("Failed to parse action", zap.Error(err))
+			s.sendError(chargePointID, msgID, "ProtocolError", "Action must be a string", nil)
+			return
+		}
+		if len(raw) < 4 {
+			s.sendError(chargePointID, msgID, "ProtocolError", "Call must carry an action and a payload", nil)
 			return
 		}
 		// Payload is raw[3]
+		s.notifyInbound(chargePointID, action)
 		s.handleAction(chargePointID, msgID, action, []byte(raw[3]))
 
 	case CallResult:
@@ -320,13 +516,37 @@ func (s *Server) Send(chargePointID string, data []byte) error {
 	conn, ok := s.clients[chargePointID]
 	s.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("charge point not connected")
+		return domain.ErrChargePointNotConnected
 	}
 
 	s.mu.Lock() // Write concurrency
 	defer s.mu.Unlock()
 	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-	return conn.WriteMessage(websocket.TextMessage, data)
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+	s.compressionStats[chargePointID].sent(len(data))
+	return nil
+}
+
+// verifyClientCertificate loads the device and checks its pinned certificate.
+// A failed lookup rejects the connection, as the pin cannot be checked.
+func (s *Server) verifyClientCertificate(ctx context.Context, chargePointID string, r *http.Request) error {
+	if s.deviceService == nil {
+		return nil
+	}
+
+	cp, err := s.deviceService.GetDevice(ctx, chargePointID)
+	if err != nil {
+		return fmt.Errorf("failed to load charge point: %w", err)
+	}
+	return s.securityManager.VerifyClientCertificate(cp, r.TLS)
+}
+
+// SetConnectionLimits sets the connection limits for new connections.
+// Zero fields keep their defaults; negative ones turn the limit off.
+func (s *Server) SetConnectionLimits(limits ConnectionLimits) {
+	s.securityManager.SetConnectionLimits(limits)
 }
 
 // GetSecurityManager returns the security manager for external configuration
@@ -378,6 +598,9 @@ func (s *Server) SendCommand(ctx context.Context, chargePointID, action string,
 
 // SendCommandWithTimeout sends a command with custom timeout
 func (s *Server) SendCommandWithTimeout(ctx context.Context, chargePointID, action string, payload interface{}, timeout time.Duration) (*CommandResponse, error) {
+	if s.draining.Load() {
+		return nil, ErrServerDraining
+	}
 	messageID := uuid.New().String()
 
 	// Create pending request
@@ -396,6 +619,7 @@ func (s *Server) SendCommandWithTimeout(ctx context.Context, chargePointID, acti
 	s.pendingMu.Lock()
 	s.pendingRequests[messageID] = pendingReq
 	s.pendingMu.Unlock()
+	s.persistPending(pendingReq)
 
 	// Send the call message
 	callMsg := []interface{}{Call, messageID, action, payload}
@@ -431,6 +655,9 @@ func (s *Server) SendCommandWithTimeout(ctx context.Context, chargePointID, acti
 
 // SendCommandAsync sends a command without waiting for response
 func (s *Server) SendCommandAsync(chargePointID, action string, payload interface{}) (string, error) {
+	if s.draining.Load() {
+		return "", ErrServerDraining
+	}
 	messageID := uuid.New().String()
 
 	// Create pending request (without response channel for async)
@@ -446,6 +673,7 @@ func (s *Server) SendCommandAsync(chargePointID, action string, payload interfac
 	s.pendingMu.Lock()
 	s.pendingRequests[messageID] = pendingReq
 	s.pendingMu.Unlock()
+	s.persistPending(pendingReq)
 
 	// Send the call message
 	callMsg := []interface{}{Call, messageID, action, payload}
@@ -485,6 +713,12 @@ func (s *Server) handleCallResult(chargePointID, messageID string, payload json.
 		)
 		return
 	}
+	s.forgetPending(messageID)
+
+	if pendingReq.Restored {
+		s.handleRestoredResponse(pendingReq, &CommandResponse{Success: true, Payload: payload})
+		return
+	}
 
 	s.log.Info("Received CallResult",
 		zap.String("action", pendingReq.Action),
@@ -517,6 +751,15 @@ func (s *Server) handleCallError(chargePointID, messageID, errorCode, errorDesc
 		)
 		return
 	}
+	s.forgetPending(messageID)
+
+	if pendingReq.Restored {
+		s.handleRestoredResponse(pendingReq, &CommandResponse{
+			Success: false,
+			Error:   &OCPPError{Code: errorCode, Description: errorDesc, Details: details},
+		})
+		return
+	}
 
 	s.log.Warn("Received CallError",
 		zap.String("action", pendingReq.Action),
@@ -542,13 +785,18 @@ func (s *Server) handleCallError(chargePointID, messageID, errorCode, errorDesc
 // removePendingRequest removes a pending request by message ID
 func (s *Server) removePendingRequest(messageID string) {
 	s.pendingMu.Lock()
-	defer s.pendingMu.Unlock()
-	if req, ok := s.pendingRequests[messageID]; ok {
+	req, ok := s.pendingRequests[messageID]
+	if ok {
 		if req.ResponseChan != nil {
 			close(req.ResponseChan)
 		}
 		delete(s.pendingRequests, messageID)
 	}
+	s.pendingMu.Unlock()
+
+	if ok {
+		s.forgetPending(messageID)
+	}
 }
 
 // cleanupExpiredRequests periodically removes expired pending requests
@@ -584,6 +832,7 @@ func (s *Server) cleanupExpiredRequests() {
 				}
 			}
 			s.pendingMu.Unlock()
+			s.forgetExpiredPending(now)
 		}
 	}
 }
@@ -0,0 +1,26 @@
+package v201
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Ensure Server implements ports.SessionStarter
+var _ ports.SessionStarter = (*Server)(nil)
+
+// StartSession sends RequestStartTransaction for userID on an EVSE, with the
+// user ID as a Central idToken so the Started event names the user. It
+// fails unless the charge point accepts the request.
+func (s *Server) StartSession(ctx context.Context, chargePointID string, evseID int, userID string) error {
+	resp, err := s.RemoteStartTransaction(ctx, chargePointID, userID, domain.IdTokenTypeCentral, &evseID, nil)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return fmt.Errorf("remote start %s by charge point", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// answerStartRequests replies to every RequestStartTransaction with status
+// and sends the request on the returned channel
+func answerStartRequests(conn *websocket.Conn, status string) <-chan RequestStartTransactionRequest {
+	requested := make(chan RequestStartTransactionRequest, 1)
+	go func() {
+		for {
+			var raw []json.RawMessage
+			if err := conn.ReadJSON(&raw); err != nil {
+				return
+			}
+			if len(raw) < 4 {
+				continue
+			}
+			var msgID string
+			var req RequestStartTransactionRequest
+			json.Unmarshal(raw[1], &msgID)
+			json.Unmarshal(raw[3], &req)
+			requested <- req
+
+			reply, _ := json.Marshal([]interface{}{CallResult, msgID, RequestStartTransactionResponse{Status: status}})
+			if err := conn.WriteMessage(websocket.TextMessage, reply); err != nil {
+				return
+			}
+		}
+	}()
+	return requested
+}
+
+func TestStartSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		wantErr bool
+	}{
+		{"accepted by the station", "Accepted", false},
+		{"rejected by the station", "Rejected", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := NewServer(nil, nil, zap.NewNop())
+			defer srv.Stop()
+			requested := answerStartRequests(dialSchemaStation(t, srv, "CP-001"), tt.status)
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			// Act
+			err := srv.StartSession(ctx, "CP-001", 2, "user-1")
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			select {
+			case req := <-requested:
+				if req.IdToken.IdToken != "user-1" || req.IdToken.Type != "Central" {
+					t.Errorf("expected a Central idToken for user-1, got %+v", req.IdToken)
+				}
+				if req.EvseId == nil || *req.EvseId != 2 {
+					t.Errorf("expected a start on EVSE 2, got %v", req.EvseId)
+				}
+			default:
+				t.Error("expected a RequestStartTransaction")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package v201
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Ensure Server implements ports.SessionStopper
+var _ ports.SessionStopper = (*Server)(nil)
+
+// StopSession sends RequestStopTransaction for a domain transaction. It
+// fails unless the charge point accepts the request.
+func (s *Server) StopSession(ctx context.Context, chargePointID, transactionID string) error {
+	resp, err := s.RemoteStopTransaction(ctx, chargePointID, s.ocppTransactionID(ctx, transactionID))
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return fmt.Errorf("remote stop %s by charge point", resp.Status)
+	}
+	return nil
+}
+
+// ocppTransactionID maps a domain transaction ID to the OCPP transactionId
+// it was started with: the one being sequenced, else the one stored on the
+// transaction, falling back to the domain ID itself
+func (s *Server) ocppTransactionID(ctx context.Context, transactionID string) string {
+	// Sequences are locked while their events are applied; do not hold
+	// seqMu meanwhile
+	s.seqMu.Lock()
+	sequences := make([]*txSequence, 0, len(s.txSequences))
+	for _, seq := range s.txSequences {
+		sequences = append(sequences, seq)
+	}
+	s.seqMu.Unlock()
+
+	for _, seq := range sequences {
+		seq.mu.Lock()
+		match := seq.txID == transactionID
+		seq.mu.Unlock()
+		if match {
+			return seq.ocppTxID
+		}
+	}
+
+	if s.txService != nil {
+		if tx, err := s.txService.GetTransaction(ctx, transactionID); err == nil && tx != nil && tx.OCPPTransactionID != "" {
+			return tx.OCPPTransactionID
+		}
+	}
+	return transactionID
+}
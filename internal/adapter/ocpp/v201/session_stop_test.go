@@ -0,0 +1,83 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// answerStopRequests replies to every RequestStopTransaction with status and
+// sends the transactionId it was asked to stop on the returned channel
+func answerStopRequests(conn *websocket.Conn, status string) <-chan string {
+	requested := make(chan string, 1)
+	go func() {
+		for {
+			var raw []json.RawMessage
+			if err := conn.ReadJSON(&raw); err != nil {
+				return
+			}
+			if len(raw) < 4 {
+				continue
+			}
+			var msgID string
+			var req RequestStopTransactionRequest
+			json.Unmarshal(raw[1], &msgID)
+			json.Unmarshal(raw[3], &req)
+			requested <- req.TransactionId
+
+			reply, _ := json.Marshal([]interface{}{CallResult, msgID, RequestStopTransactionResponse{Status: status}})
+			if err := conn.WriteMessage(websocket.TextMessage, reply); err != nil {
+				return
+			}
+		}
+	}()
+	return requested
+}
+
+func TestStopSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		wantOCPPTx  string
+		knownToOCPP bool
+		wantErr     bool
+	}{
+		{"maps to the OCPP transactionId", "Accepted", "ocpp-tx-1", true, false},
+		{"falls back to the domain ID", "Accepted", "tx-domain", false, false},
+		{"rejected by the station", "Rejected", "ocpp-tx-1", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := NewServer(nil, nil, zap.NewNop())
+			defer srv.Stop()
+			if tt.knownToOCPP {
+				srv.transactionSequence("CP-001", "ocpp-tx-1").txID = "tx-domain"
+			}
+			requested := answerStopRequests(dialSchemaStation(t, srv, "CP-001"), tt.status)
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			// Act
+			err := srv.StopSession(ctx, "CP-001", "tx-domain")
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			select {
+			case got := <-requested:
+				if got != tt.wantOCPPTx {
+					t.Errorf("expected a stop for %s, got %s", tt.wantOCPPTx, got)
+				}
+			default:
+				t.Error("expected a RequestStopTransaction")
+			}
+		})
+	}
+}
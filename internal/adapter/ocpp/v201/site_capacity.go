@@ -0,0 +1,271 @@
+package v201
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// ErrSiteCapacityExceeded is returned when a TxProfile would take a station
+// over its grid connection limit and the policy is to reject it
+var ErrSiteCapacityExceeded = errors.New("site capacity exceeded")
+
+// siteCapacityProfileID is the ChargingStationMaxProfile the CSMS maintains
+// for stations with a site capacity
+const siteCapacityProfileID = 1000000
+
+// SiteCapacityPolicy decides what happens to TxProfiles that together ask
+// for more than a station's site capacity
+type SiteCapacityPolicy string
+
+const (
+	SiteCapacityScale  SiteCapacityPolicy = "scale"  // Every TxProfile is limited in proportion to its request
+	SiteCapacityReject SiteCapacityPolicy = "reject" // The profile that breaches the cap is refused
+)
+
+// SiteCapacityConfig holds the contracted grid capacity of stations
+type SiteCapacityConfig struct {
+	ByChargePoint map[string]float64 // Charge point ID -> capacity in kW
+	Policy        SiteCapacityPolicy
+}
+
+// SetSiteCapacity sets the site capacity of each station. Stations without
+// one are not capped by the CSMS. An unknown policy falls back to scaling.
+func (s *Server) SetSiteCapacity(cfg SiteCapacityConfig) {
+	capacity := make(map[string]float64, len(cfg.ByChargePoint))
+	for id, kw := range cfg.ByChargePoint {
+		if kw > 0 {
+			capacity[id] = kw
+		}
+	}
+	if cfg.Policy != SiteCapacityReject {
+		cfg.Policy = SiteCapacityScale
+	}
+	cfg.ByChargePoint = capacity
+	s.siteCapacity = cfg
+}
+
+// siteCapacityW returns a station's site capacity in W
+func (s *Server) siteCapacityW(chargePointID string) (float64, bool) {
+	kw, ok := domain.ChargePointSetting(s.siteCapacity.ByChargePoint, chargePointID)
+	return kw * 1000, ok
+}
+
+// ApplySiteCapacity installs the station's site capacity as its
+// ChargingStationMaxProfile, so the station enforces it even for sessions
+// the CSMS sends no profile for
+func (s *Server) ApplySiteCapacity(ctx context.Context, chargePointID string) error {
+	capW, ok := s.siteCapacityW(chargePointID)
+	if !ok {
+		return nil
+	}
+
+	profile := ChargingProfile{
+		Id:                     siteCapacityProfileID,
+		StackLevel:             0,
+		ChargingProfilePurpose: "ChargingStationMaxProfile",
+		ChargingProfileKind:    "Relative",
+		ChargingSchedule: []ChargingSchedule{{
+			Id:                     siteCapacityProfileID,
+			ChargingRateUnit:       "W",
+			ChargingSchedulePeriod: []ChargingSchedulePeriod{{StartPeriod: 0, Limit: capW}},
+		}},
+	}
+	resp, err := s.sendChargingProfile(ctx, chargePointID, 0, profile, profile)
+	if err != nil {
+		return err
+	}
+	if resp.Status != "Accepted" {
+		return fmt.Errorf("station max profile %s", resp.Status)
+	}
+
+	s.log.Info("Site capacity applied",
+		zap.String("chargePointID", chargePointID),
+		zap.Float64("capacityW", capW),
+	)
+	return nil
+}
+
+// setCappedTxProfile sends a TxProfile to a station with a site capacity.
+// When the TxProfiles of all EVSEs together would exceed the capacity they
+// are either scaled down by the same factor, the new one included, or the
+// new one is rejected.
+func (s *Server) setCappedTxProfile(ctx context.Context, chargePointID string, evseID int, profile ChargingProfile, capW float64) (*SetChargingProfileResponse, error) {
+	s.capacityMu.Lock()
+	defer s.capacityMu.Unlock()
+
+	requested := []installedProfile{{EvseID: evseID, Profile: profile}}
+	for _, installed := range s.requestedTxProfiles(chargePointID) {
+		if installed.Profile.Id != profile.Id {
+			requested = append(requested, installed)
+		}
+	}
+
+	factor := capacityFactor(requested, capW, time.Now().In(s.stationTimeZone(ctx, chargePointID)))
+	if factor < 1 && s.siteCapacity.Policy == SiteCapacityReject {
+		return nil, fmt.Errorf("%w: TxProfile %d on %s would exceed %.0f W", ErrSiteCapacityExceeded, profile.Id, chargePointID, capW)
+	}
+
+	resp, err := s.sendChargingProfile(ctx, chargePointID, evseID, scaleProfile(profile, factor), profile)
+	if err != nil || resp.Status != "Accepted" {
+		return resp, err
+	}
+
+	if factor < 1 {
+		s.log.Info("TxProfiles limited to the site capacity",
+			zap.String("chargePointID", chargePointID),
+			zap.Float64("capacityW", capW),
+			zap.Float64("factor", factor),
+		)
+	}
+	s.applyCapacityFactor(ctx, chargePointID, factor, profile.Id)
+	return resp, nil
+}
+
+// rebalanceSiteCapacity scales the remaining TxProfiles back towards what
+// was requested once capacity was freed
+func (s *Server) rebalanceSiteCapacity(ctx context.Context, chargePointID string) {
+	capW, ok := s.siteCapacityW(chargePointID)
+	if !ok {
+		return
+	}
+
+	s.capacityMu.Lock()
+	defer s.capacityMu.Unlock()
+
+	factor := capacityFactor(s.requestedTxProfiles(chargePointID), capW, time.Now().In(s.stationTimeZone(ctx, chargePointID)))
+	s.applyCapacityFactor(ctx, chargePointID, factor, 0)
+}
+
+// applyCapacityFactor re-sends every TxProfile of the station, except skipID,
+// whose installed limits differ from its request scaled by factor
+func (s *Server) applyCapacityFactor(ctx context.Context, chargePointID string, factor float64, skipID int) {
+	for _, installed := range s.installedProfiles(chargePointID) {
+		if installed.Profile.ChargingProfilePurpose != "TxProfile" || installed.Profile.Id == skipID {
+			continue
+		}
+		scaled := scaleProfile(installed.Requested, factor)
+		if sameLimits(scaled, installed.Profile) {
+			continue
+		}
+		resp, err := s.sendChargingProfile(ctx, chargePointID, installed.EvseID, scaled, installed.Requested)
+		if err == nil && resp.Status != "Accepted" {
+			err = fmt.Errorf("station answered %s", resp.Status)
+		}
+		if err != nil {
+			// The station's max profile still holds the total
+			s.log.Warn("Failed to rescale TxProfile",
+				zap.String("chargePointID", chargePointID),
+				zap.Int("evseID", installed.EvseID),
+				zap.Int("profileID", installed.Profile.Id),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// releaseTxProfiles forgets the TxProfiles of an EVSE whose transaction
+// ended, as the station discards them, and hands their share to the others
+func (s *Server) releaseTxProfiles(chargePointID string, evseID int) {
+	purpose := "TxProfile"
+	s.forgetChargingProfiles(chargePointID, nil, &ClearChargingProfileCriteria{
+		EvseId:                 &evseID,
+		ChargingProfilePurpose: &purpose,
+	})
+	if _, ok := s.siteCapacityW(chargePointID); ok {
+		// Sent outside the read loop, which carries the station's answers
+		go s.rebalanceSiteCapacity(context.Background(), chargePointID)
+	}
+}
+
+// requestedTxProfiles returns the TxProfiles of a station as requested,
+// before any scaling
+func (s *Server) requestedTxProfiles(chargePointID string) []installedProfile {
+	var requested []installedProfile
+	for _, installed := range s.installedProfiles(chargePointID) {
+		if installed.Profile.ChargingProfilePurpose == "TxProfile" {
+			requested = append(requested, installedProfile{EvseID: installed.EvseID, Profile: installed.Requested})
+		}
+	}
+	return requested
+}
+
+// capacityFactor returns how much the TxProfiles must be scaled so that
+// their combined limit never exceeds capW: 1 when they already fit
+func capacityFactor(profiles []installedProfile, capW float64, now time.Time) float64 {
+	byEVSE := make(map[int][]installedProfile)
+	for _, installed := range profiles {
+		byEVSE[installed.EvseID] = append(byEVSE[installed.EvseID], installed)
+	}
+
+	// The combined limit can only change where one of the EVSE composites does
+	points := map[int]bool{}
+	for _, group := range byEVSE {
+		for _, period := range compositeSchedule(group, 0, now) {
+			points[period.StartPeriod] = true
+		}
+	}
+
+	peak := 0.0
+	for t := range points {
+		total := 0.0
+		for _, group := range byEVSE {
+			if limit, _, ok := compositeLimitAt(group, now, t); ok && limit > 0 {
+				total += limit
+			}
+		}
+		peak = math.Max(peak, total)
+	}
+	if peak <= capW {
+		return 1
+	}
+	return capW / peak
+}
+
+// scaleProfile returns a copy of profile with every charging limit
+// multiplied by factor, rounded down to 0.1 so the sum stays under the cap.
+// Discharge (negative) limits are left alone.
+func scaleProfile(profile ChargingProfile, factor float64) ChargingProfile {
+	if factor >= 1 {
+		return profile
+	}
+	scaled := profile
+	scaled.ChargingSchedule = make([]ChargingSchedule, len(profile.ChargingSchedule))
+	for i, schedule := range profile.ChargingSchedule {
+		periods := make([]ChargingSchedulePeriod, len(schedule.ChargingSchedulePeriod))
+		for j, period := range schedule.ChargingSchedulePeriod {
+			if period.Limit > 0 {
+				period.Limit = math.Floor(period.Limit*factor*10) / 10
+			}
+			periods[j] = period
+		}
+		schedule.ChargingSchedulePeriod = periods
+		scaled.ChargingSchedule[i] = schedule
+	}
+	return scaled
+}
+
+// sameLimits reports whether two versions of a profile carry the same limits
+func sameLimits(a, b ChargingProfile) bool {
+	if len(a.ChargingSchedule) != len(b.ChargingSchedule) {
+		return false
+	}
+	for i := range a.ChargingSchedule {
+		pa, pb := a.ChargingSchedule[i].ChargingSchedulePeriod, b.ChargingSchedule[i].ChargingSchedulePeriod
+		if len(pa) != len(pb) {
+			return false
+		}
+		for j := range pa {
+			if pa[j].Limit != pb[j].Limit {
+				return false
+			}
+		}
+	}
+	return true
+}
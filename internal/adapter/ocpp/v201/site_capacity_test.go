@@ -0,0 +1,183 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// connectProfileStation dials the server as chargePointID, accepts every
+// command and passes on the SetChargingProfile requests it receives
+func connectProfileStation(t *testing.T, srv *Server, chargePointID string) <-chan SetChargingProfileRequest {
+	t.Helper()
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	t.Cleanup(httpSrv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	received := make(chan SetChargingProfileRequest, 16)
+	go func() {
+		for {
+			var raw []json.RawMessage
+			if err := conn.ReadJSON(&raw); err != nil {
+				return
+			}
+			var msgID, action string
+			json.Unmarshal(raw[1], &msgID)
+			json.Unmarshal(raw[2], &action)
+			if action == "SetChargingProfile" {
+				var req SetChargingProfileRequest
+				json.Unmarshal(raw[3], &req)
+				received <- req
+			}
+			conn.WriteJSON([]interface{}{CallResult, msgID, map[string]string{"status": "Accepted"}})
+		}
+	}()
+
+	waitConnected(t, srv, chargePointID)
+	return received
+}
+
+// installedLimits returns the first limit of every installed TxProfile by EVSE
+func installedLimits(srv *Server, chargePointID string) map[int]float64 {
+	limits := make(map[int]float64)
+	for _, installed := range srv.installedProfiles(chargePointID) {
+		if installed.Profile.ChargingProfilePurpose == "TxProfile" {
+			limits[installed.EvseID] = installed.Profile.ChargingSchedule[0].ChargingSchedulePeriod[0].Limit
+		}
+	}
+	return limits
+}
+
+func TestSetChargingProfile_ScalesSessionsToSiteCapacity(t *testing.T) {
+	// Arrange: an 18 kW site
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetSiteCapacity(SiteCapacityConfig{ByChargePoint: map[string]float64{"CP-001": 18}})
+	received := connectProfileStation(t, srv, "CP-001")
+	ctx := context.Background()
+
+	// Act: session 1 asks for 16 kW, which fits; session 2 then asks for 8 kW
+	if _, err := srv.SetChargingProfile(ctx, "CP-001", 1, txProfile(1, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 16000})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := srv.SetChargingProfile(ctx, "CP-001", 2, txProfile(2, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 8000}))
+
+	// Assert: both are limited by 18/24, keeping their 2:1 ratio
+	if err != nil || resp.Status != "Accepted" {
+		t.Fatalf("expected the profile to be accepted, got %+v, %v", resp, err)
+	}
+	limits := installedLimits(srv, "CP-001")
+	if limits[1] != 12000 || limits[2] != 6000 {
+		t.Errorf("expected 12000 W and 6000 W, got %v", limits)
+	}
+	if len(received) != 3 {
+		t.Errorf("expected session 1 to be sent again with its new limit, got %d profiles", len(received))
+	}
+	for _, installed := range srv.installedProfiles("CP-001") {
+		if installed.EvseID == 1 && installed.Requested.ChargingSchedule[0].ChargingSchedulePeriod[0].Limit != 16000 {
+			t.Error("expected the original 16 kW request to be remembered")
+		}
+	}
+
+	// Act: session 2 is cleared, so session 1 gets its full request back
+	profileID := 2
+	if _, err := srv.ClearChargingProfile(ctx, "CP-001", &profileID, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Assert
+	if limits := installedLimits(srv, "CP-001"); len(limits) != 1 || limits[1] != 16000 {
+		t.Errorf("expected session 1 restored to 16000 W, got %v", limits)
+	}
+}
+
+func TestSetChargingProfile_ScalesOnlyWhileSchedulesOverlap(t *testing.T) {
+	// Arrange: a 22 kW site; session 1 drops to 6 kW after an hour
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetSiteCapacity(SiteCapacityConfig{ByChargePoint: map[string]float64{"CP-001": 22}})
+	connectProfileStation(t, srv, "CP-001")
+	ctx := context.Background()
+
+	srv.SetChargingProfile(ctx, "CP-001", 1, txProfile(1, 0,
+		ChargingSchedulePeriod{StartPeriod: 0, Limit: 16000},
+		ChargingSchedulePeriod{StartPeriod: 3600, Limit: 6000},
+	))
+
+	// Act
+	srv.SetChargingProfile(ctx, "CP-001", 2, txProfile(2, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 16000}))
+
+	// Assert: the peak of 32 kW sets the factor for the whole schedule
+	for _, installed := range srv.installedProfiles("CP-001") {
+		periods := installed.Profile.ChargingSchedule[0].ChargingSchedulePeriod
+		if periods[0].Limit != 11000 {
+			t.Errorf("EVSE %d: expected 11000 W at the start, got %.0f", installed.EvseID, periods[0].Limit)
+		}
+		if installed.EvseID == 1 && periods[1].Limit != 4125 {
+			t.Errorf("expected the later period scaled to 4125 W, got %.0f", periods[1].Limit)
+		}
+	}
+}
+
+func TestSetChargingProfile_RejectsOverSiteCapacity(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetSiteCapacity(SiteCapacityConfig{
+		ByChargePoint: map[string]float64{"CP-001": 18},
+		Policy:        SiteCapacityReject,
+	})
+	received := connectProfileStation(t, srv, "CP-001")
+	ctx := context.Background()
+	srv.SetChargingProfile(ctx, "CP-001", 1, txProfile(1, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 16000}))
+
+	// Act
+	_, err := srv.SetChargingProfile(ctx, "CP-001", 2, txProfile(2, 0, ChargingSchedulePeriod{StartPeriod: 0, Limit: 8000}))
+
+	// Assert
+	if !errors.Is(err, ErrSiteCapacityExceeded) {
+		t.Errorf("expected ErrSiteCapacityExceeded, got %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("expected only the first profile to reach the station, got %d", len(received))
+	}
+	if limits := installedLimits(srv, "CP-001"); len(limits) != 1 || limits[1] != 16000 {
+		t.Errorf("expected session 1 untouched, got %v", limits)
+	}
+}
+
+func TestApplySiteCapacity_InstallsStationMaxProfile(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	srv.SetSiteCapacity(SiteCapacityConfig{ByChargePoint: map[string]float64{"CP-001": 50}})
+	received := connectProfileStation(t, srv, "CP-001")
+
+	// Act
+	err := srv.ApplySiteCapacity(context.Background(), "CP-001")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := <-received
+	if req.EvseId != 0 || req.ChargingProfile.ChargingProfilePurpose != "ChargingStationMaxProfile" {
+		t.Errorf("expected a station-wide max profile, got %+v", req)
+	}
+	if limit := req.ChargingProfile.ChargingSchedule[0].ChargingSchedulePeriod[0].Limit; limit != 50000 {
+		t.Errorf("expected 50000 W, got %.0f", limit)
+	}
+}
@@ -0,0 +1,484 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Transaction event sequencing defaults
+const (
+	// DefaultSeqGapTimeout is how long events after a missing seqNo are held
+	// back before they are applied without it
+	DefaultSeqGapTimeout = 30 * time.Second
+	// endedSequenceRetention keeps the state of ended transactions around so
+	// replayed events are still recognised as duplicates
+	endedSequenceRetention = 10 * time.Minute
+)
+
+// txSequence orders the TransactionEvents of one OCPP transaction by seqNo.
+// Events are applied in order; duplicates and events older than the last
+// applied one are dropped, and events after a gap wait for the gap to fill.
+type txSequence struct {
+	mu        sync.Mutex
+	ocppTxID  string // transactionId given by the station
+	txID      string // domain transaction ID, empty until Started is applied
+	started   bool
+	lastSeqNo int
+	pending   map[int]TransactionEventRequest
+	gapTimer  *time.Timer
+}
+
+// sequenceKey identifies an OCPP transaction across stations. The
+// transactionId is only unique per charge point, so two stations may well
+// both start transaction "1".
+func sequenceKey(cpID, transactionID string) string {
+	return cpID + "/" + transactionID
+}
+
+// transactionSequence returns the sequence state of an OCPP transaction on a
+// charge point, creating it on first use
+func (s *Server) transactionSequence(cpID, transactionID string) *txSequence {
+	s.seqMu.Lock()
+	defer s.seqMu.Unlock()
+
+	key := sequenceKey(cpID, transactionID)
+	seq, ok := s.txSequences[key]
+	if !ok {
+		seq = &txSequence{ocppTxID: transactionID, lastSeqNo: -1, pending: make(map[int]TransactionEventRequest)}
+		s.txSequences[key] = seq
+	}
+	return seq
+}
+
+// forgetTransactionSequence drops the state of an ended transaction after the
+// retention period
+func (s *Server) forgetTransactionSequence(cpID, transactionID string, seq *txSequence) {
+	key := sequenceKey(cpID, transactionID)
+	time.AfterFunc(endedSequenceRetention, func() {
+		s.seqMu.Lock()
+		defer s.seqMu.Unlock()
+		if s.txSequences[key] == seq {
+			delete(s.txSequences, key)
+		}
+	})
+}
+
+// handleTransactionEvent orders the event against the transaction's seqNo
+// and applies it together with any buffered events it unblocks
+func (s *Server) handleTransactionEvent(cpID string, payload []byte) (*TransactionEventResponse, error) {
+	var req TransactionEventRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	accepted := &TransactionEventResponse{IdTokenInfo: &IdTokenInfo{Status: "Accepted"}}
+
+	ocppTxID := req.TransactionInfo.TransactionId
+	seq := s.transactionSequence(cpID, ocppTxID)
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+
+	if !seq.started && req.EventType != "Started" {
+		s.restoreSequence(ctx, cpID, ocppTxID, seq)
+	}
+
+	switch {
+	case !seq.started && req.EventType == "Started":
+		resp, err := s.applyTransactionEvent(ctx, cpID, seq, req)
+		if err != nil {
+			return nil, err
+		}
+		s.drainSequence(ctx, cpID, seq)
+		return resp, nil
+
+	case !seq.started, req.SeqNo > seq.lastSeqNo+1:
+		// Started has not been seen yet or an earlier event is missing
+		s.log.Info("Holding back out-of-order TransactionEvent",
+			zap.String("txID", ocppTxID),
+			zap.Int("seqNo", req.SeqNo),
+			zap.Int("lastSeqNo", seq.lastSeqNo),
+		)
+		seq.pending[req.SeqNo] = req
+		if seq.gapTimer == nil {
+			seq.gapTimer = time.AfterFunc(s.seqGapTimeout, func() {
+				s.flushSequence(cpID, ocppTxID, seq)
+			})
+		}
+
+	case req.SeqNo <= seq.lastSeqNo:
+		s.log.Info("Ignoring duplicate or stale TransactionEvent",
+			zap.String("txID", ocppTxID),
+			zap.String("eventType", req.EventType),
+			zap.Int("seqNo", req.SeqNo),
+			zap.Int("lastSeqNo", seq.lastSeqNo),
+		)
+
+	default:
+		if _, err := s.applyTransactionEvent(ctx, cpID, seq, req); err != nil {
+			return nil, err
+		}
+		s.drainSequence(ctx, cpID, seq)
+	}
+
+	return accepted, nil
+}
+
+// restoreSequence picks up the last applied seqNo of a transaction that was
+// started before this server instance, e.g. events replayed after a restart.
+// The session is found by the transactionId the station gave it.
+func (s *Server) restoreSequence(ctx context.Context, cpID, ocppTxID string, seq *txSequence) {
+	linker, ok := s.txService.(ports.OCPPTransactionLinker)
+	if !ok || ocppTxID == "" {
+		return
+	}
+	tx, err := linker.FindByOCPPTransaction(ctx, cpID, ocppTxID)
+	if err != nil {
+		s.log.Warn("Failed to restore transaction sequence", zap.String("txID", ocppTxID), zap.Error(err))
+		return
+	}
+	if tx == nil {
+		return
+	}
+	seq.txID = tx.ID
+	seq.started = true
+	seq.lastSeqNo = tx.LastSeqNo
+}
+
+// drainSequence applies buffered events that directly follow the last
+// applied seqNo
+func (s *Server) drainSequence(ctx context.Context, cpID string, seq *txSequence) {
+	for {
+		next, ok := seq.pending[seq.lastSeqNo+1]
+		if !ok {
+			break
+		}
+		delete(seq.pending, next.SeqNo)
+		if _, err := s.applyTransactionEvent(ctx, cpID, seq, next); err != nil {
+			s.log.Warn("Failed to apply buffered TransactionEvent",
+				zap.String("txID", next.TransactionInfo.TransactionId),
+				zap.Int("seqNo", next.SeqNo),
+				zap.Error(err),
+			)
+		}
+	}
+
+	// Whatever is left is stale or still behind a gap
+	for seqNo := range seq.pending {
+		if seq.started && seqNo <= seq.lastSeqNo {
+			delete(seq.pending, seqNo)
+		}
+	}
+	if len(seq.pending) == 0 && seq.gapTimer != nil {
+		seq.gapTimer.Stop()
+		seq.gapTimer = nil
+	}
+}
+
+// flushSequence gives up on a gap: buffered events are applied in seqNo
+// order without the missing ones
+func (s *Server) flushSequence(cpID, ocppTxID string, seq *txSequence) {
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+
+	seq.gapTimer = nil
+	if len(seq.pending) == 0 {
+		return
+	}
+
+	seqNos := make([]int, 0, len(seq.pending))
+	for seqNo := range seq.pending {
+		seqNos = append(seqNos, seqNo)
+	}
+	sort.Ints(seqNos)
+
+	s.log.Warn("Applying TransactionEvents with missing seqNo",
+		zap.String("txID", ocppTxID),
+		zap.Int("lastSeqNo", seq.lastSeqNo),
+		zap.Ints("pending", seqNos),
+	)
+
+	ctx := context.Background()
+	for _, seqNo := range seqNos {
+		req := seq.pending[seqNo]
+		delete(seq.pending, seqNo)
+		if seq.started && seqNo <= seq.lastSeqNo {
+			continue
+		}
+		if _, err := s.applyTransactionEvent(ctx, cpID, seq, req); err != nil {
+			s.log.Warn("Failed to apply buffered TransactionEvent",
+				zap.String("txID", ocppTxID),
+				zap.Int("seqNo", seqNo),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// applyTransactionEvent updates the session for one event in sequence. The
+// event only counts as applied once it is stored, so a station retrying
+// after an error gets it applied again.
+func (s *Server) applyTransactionEvent(ctx context.Context, cpID string, seq *txSequence, req TransactionEventRequest) (*TransactionEventResponse, error) {
+	ocppTxID := req.TransactionInfo.TransactionId
+	meterWh := energyRegisterWh(req.MeterValue)
+
+	switch req.EventType {
+	case "Started":
+		// User ID from IdToken
+		uID := "unknown"
+		idTag := ""
+		if req.IdToken != nil {
+			idTag = req.IdToken.IdToken
+			// In production, verify auth cache or service to get real UserUUID
+			uID = idTag // simplified
+		}
+
+		connID, evseID := 1, 0
+		if req.Evse != nil {
+			connID, evseID = connectorKey(req.Evse.Id, req.Evse.ConnectorId), req.Evse.Id
+		}
+
+		tx, err := s.txService.StartTransaction(ctx, cpID, connID, uID, idTag)
+		if err != nil {
+			s.log.Error("Failed to start transaction", zap.Error(err))
+			seq.started = true
+			seq.lastSeqNo = req.SeqNo
+			return &TransactionEventResponse{
+				IdTokenInfo: &IdTokenInfo{Status: "Blocked"},
+			}, nil
+		}
+
+		seq.txID = tx.ID
+		if linker, ok := s.txService.(ports.OCPPTransactionLinker); ok && ocppTxID != "" {
+			if err := linker.LinkOCPPTransaction(ctx, tx.ID, ocppTxID, evseID); err != nil {
+				return nil, fmt.Errorf("failed to link transaction %s to %s: %w", tx.ID, ocppTxID, err)
+			}
+		}
+		if err := s.recordTransactionEvent(ctx, seq, req.SeqNo, meterWh); err != nil {
+			return nil, err
+		}
+		s.recordPower(ctx, seq.txID, req.MeterValue)
+		s.trackEVSETransaction(cpID, evseID, ocppTxID)
+		s.linkReservation(ctx, cpID, idTag, tx.ID)
+
+		s.log.Info("Transaction Started via OCPP",
+			zap.String("txID", tx.ID),
+			zap.String("chargePointID", cpID),
+			zap.String("userID", uID),
+		)
+
+	case "Updated":
+		// Handle meter values update during charging
+		if ocppTxID != "" && len(req.MeterValue) > 0 {
+			s.log.Info("Transaction Updated - Meter Values",
+				zap.String("txID", ocppTxID),
+				zap.Int("seqNo", req.SeqNo),
+				zap.Any("meterValues", req.MeterValue),
+			)
+		}
+		if err := s.recordTransactionEvent(ctx, seq, req.SeqNo, meterWh); err != nil {
+			return nil, err
+		}
+		s.recordPower(ctx, seq.txID, req.MeterValue)
+
+	case "Ended":
+		s.log.Info("Processing Transaction End", zap.String("txID", ocppTxID), zap.String("chargePointID", cpID))
+		if err := s.recordTransactionEvent(ctx, seq, req.SeqNo, meterWh); err != nil {
+			return nil, err
+		}
+		s.untrackTransaction(cpID, ocppTxID)
+		s.recordStationEnergy(ctx, seq.txID, req.MeterValue)
+		s.endTransaction(ctx, cpID, seq, req)
+		s.forgetTransactionSequence(cpID, ocppTxID, seq)
+		if req.Evse != nil {
+			s.releaseTxProfiles(cpID, req.Evse.Id)
+		}
+	}
+
+	connectorID := 1
+	if req.Evse != nil {
+		connectorID = connectorKey(req.Evse.Id, req.Evse.ConnectorId)
+	}
+	s.storeMeterValues(ctx, cpID, seq.txID, connectorID, req.MeterValue)
+
+	seq.started = true
+	seq.lastSeqNo = req.SeqNo
+	return &TransactionEventResponse{
+		IdTokenInfo: &IdTokenInfo{Status: "Accepted"},
+	}, nil
+}
+
+// recordTransactionEvent persists the applied seqNo and meter reading
+func (s *Server) recordTransactionEvent(ctx context.Context, seq *txSequence, seqNo, meterWh int) error {
+	if seq.txID == "" {
+		return nil
+	}
+	if err := s.txService.RecordTransactionEvent(ctx, seq.txID, seqNo, meterWh); err != nil {
+		return fmt.Errorf("failed to record transaction event %d of %s: %w", seqNo, seq.txID, err)
+	}
+	return nil
+}
+
+// recordPower passes the latest active power reading, if any, to the
+// transaction service for the live sessions view
+func (s *Server) recordPower(ctx context.Context, txID string, meterValues []MeterValue) {
+	if txID == "" {
+		return
+	}
+	powerKW, sampledAt, ok := activePowerKW(meterValues)
+	if !ok {
+		return
+	}
+	if err := s.txService.RecordPower(ctx, txID, powerKW, sampledAt); err != nil {
+		s.log.Warn("Failed to record power",
+			zap.String("txID", txID),
+			zap.Float64("powerKW", powerKW),
+			zap.Error(err),
+		)
+	}
+}
+
+// recordStationEnergy stores the session total the station reported in its
+// Ended event, used later to reconcile our billed energy
+func (s *Server) recordStationEnergy(ctx context.Context, txID string, meterValues []MeterValue) {
+	if txID == "" {
+		return
+	}
+	energyWh, ok := sessionEnergyWh(meterValues)
+	if !ok {
+		return
+	}
+	if err := s.txService.RecordStationEnergy(ctx, txID, energyWh); err != nil {
+		s.log.Warn("Failed to record station energy",
+			zap.String("txID", txID),
+			zap.Int("energyWh", energyWh),
+			zap.Error(err),
+		)
+	}
+}
+
+// endTransaction stops the session of an Ended event
+func (s *Server) endTransaction(ctx context.Context, cpID string, seq *txSequence, req TransactionEventRequest) {
+	txID := seq.txID
+	if txID == "" {
+		// Try to find the transaction by the OCPP transaction ID
+		txID = req.TransactionInfo.TransactionId
+	}
+
+	tx, err := s.txService.GetTransaction(ctx, txID)
+	if err != nil || tx == nil {
+		// Fallback: find active transaction for this user/device
+		s.log.Warn("Transaction not found by ID, attempting to find active transaction",
+			zap.String("txID", txID),
+			zap.String("chargePointID", cpID),
+		)
+
+		// Get user ID from IdToken if available
+		userID := "unknown"
+		if req.IdToken != nil {
+			userID = req.IdToken.IdToken
+		}
+
+		// Try to stop any active charging for this user
+		if err := s.txService.StopActiveCharging(ctx, userID); err != nil {
+			s.log.Error("Failed to stop active charging", zap.Error(err))
+		} else {
+			s.log.Info("Transaction Ended via StopActiveCharging",
+				zap.String("userID", userID),
+				zap.String("chargePointID", cpID),
+			)
+		}
+		return
+	}
+
+	// Stop the specific transaction
+	stoppedTx, err := s.txService.StopTransaction(ctx, tx.ID)
+	if err != nil {
+		s.log.Error("Failed to stop transaction", zap.Error(err), zap.String("txID", tx.ID))
+		return
+	}
+	s.log.Info("Transaction Ended via OCPP",
+		zap.String("txID", stoppedTx.ID),
+		zap.Int("totalEnergy", stoppedTx.TotalEnergy),
+		zap.Float64("cost", stoppedTx.Cost),
+	)
+}
+
+// activePowerKW returns the last Power.Active.Import reading in kW and when
+// it was sampled. ok is false if the meter values carry none.
+func activePowerKW(meterValues []MeterValue) (powerKW float64, sampledAt time.Time, ok bool) {
+	for _, mv := range meterValues {
+		for _, sv := range mv.SampledValue {
+			if sv.Measurand != "Power.Active.Import" {
+				continue
+			}
+			value, err := strconv.ParseFloat(sv.Value, 64)
+			if err != nil {
+				continue
+			}
+			if !strings.EqualFold(sv.Unit, "kW") {
+				value /= 1000 // W is the default unit
+			}
+			powerKW, ok = value, true
+			sampledAt, err = time.Parse(time.RFC3339, mv.Timestamp)
+			if err != nil {
+				sampledAt = time.Now()
+			}
+		}
+	}
+	return powerKW, sampledAt, ok
+}
+
+// energyRegisterWh returns the last Energy.Active.Import.Register reading in
+// Wh, or 0 if the meter values carry none
+func energyRegisterWh(meterValues []MeterValue) int {
+	wh := 0
+	for _, mv := range meterValues {
+		for _, sv := range mv.SampledValue {
+			if sv.Measurand != "" && sv.Measurand != "Energy.Active.Import.Register" {
+				continue
+			}
+			value, err := strconv.ParseFloat(sv.Value, 64)
+			if err != nil {
+				continue
+			}
+			if strings.EqualFold(sv.Unit, "kWh") {
+				value *= 1000
+			}
+			wh = int(value)
+		}
+	}
+	return wh
+}
+
+// sessionEnergyWh returns the last Energy.Active.Import.Interval reading in
+// Wh, which an Ended event uses for the energy delivered in the session. ok is
+// false if the meter values carry none.
+func sessionEnergyWh(meterValues []MeterValue) (energyWh int, ok bool) {
+	for _, mv := range meterValues {
+		for _, sv := range mv.SampledValue {
+			if sv.Measurand != "Energy.Active.Import.Interval" {
+				continue
+			}
+			value, err := strconv.ParseFloat(sv.Value, 64)
+			if err != nil {
+				continue
+			}
+			if strings.EqualFold(sv.Unit, "kWh") {
+				value *= 1000
+			}
+			energyWh, ok = int(value), true
+		}
+	}
+	return energyWh, ok
+}
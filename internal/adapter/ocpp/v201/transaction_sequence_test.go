@@ -0,0 +1,377 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// sessionRecorder is an in-memory transaction service for one session
+type sessionRecorder struct {
+	mu      sync.Mutex
+	tx      *domain.Transaction
+	starts  int
+	meters  []int // meter readings in the order they were recorded
+	stopped int
+}
+
+func newSequenceTestServer(t *testing.T) (*Server, *sessionRecorder) {
+	t.Helper()
+	rec := &sessionRecorder{}
+	return newSequenceTestServerFor(t, rec), rec
+}
+
+// newSequenceTestServerFor starts a server over an existing session, as
+// after a restart
+func newSequenceTestServerFor(t *testing.T, rec *sessionRecorder) *Server {
+	t.Helper()
+	txService := &mocks.MockTransactionService{
+		StartTransactionFunc: func(ctx context.Context, deviceID string, connectorID int, userID string, idTag string) (*domain.Transaction, error) {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			rec.starts++
+			rec.tx = &domain.Transaction{ID: "tx-domain", ChargePointID: deviceID, UserID: userID, Status: domain.TransactionStatusStarted}
+			return rec.tx, nil
+		},
+		GetTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			if rec.tx == nil || id != rec.tx.ID {
+				return nil, nil
+			}
+			return rec.tx, nil
+		},
+		LinkOCPPTransactionFunc: func(ctx context.Context, transactionID, ocppTransactionID string, evseID int) error {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			rec.tx.OCPPTransactionID = ocppTransactionID
+			rec.tx.EvseID = evseID
+			return nil
+		},
+		FindByOCPPTransactionFunc: func(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			if rec.tx == nil || rec.tx.ChargePointID != chargePointID || rec.tx.OCPPTransactionID != ocppTransactionID {
+				return nil, nil
+			}
+			copied := *rec.tx
+			return &copied, nil
+		},
+		RecordTransactionEventFunc: func(ctx context.Context, transactionID string, seqNo int, meterWh int) error {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			rec.tx.LastSeqNo = seqNo
+			if meterWh > 0 {
+				rec.tx.MeterStop = meterWh
+				rec.meters = append(rec.meters, meterWh)
+			}
+			return nil
+		},
+		StopTransactionFunc: func(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			if rec.tx.Status != domain.TransactionStatusStarted {
+				return nil, errors.New("transaction is not active")
+			}
+			rec.stopped++
+			rec.tx.Status = domain.TransactionStatusStopped
+			return rec.tx, nil
+		},
+	}
+
+	srv := NewServer(nil, txService, zap.NewNop())
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func transactionEvent(t *testing.T, eventType string, seqNo int, meterWh int) []byte {
+	t.Helper()
+	req := TransactionEventRequest{
+		EventType:       eventType,
+		Timestamp:       "2026-10-16T12:00:00Z",
+		SeqNo:           seqNo,
+		TransactionInfo: TransactionInfo{TransactionId: "ocpp-tx-1"},
+		IdToken:         &IdToken{IdToken: "user-1", Type: "ISO14443"},
+		MeterValue: []MeterValue{{
+			Timestamp: "2026-10-16T12:00:00Z",
+			SampledValue: []SampledValue{
+				{Value: strconv.Itoa(meterWh), Measurand: "Energy.Active.Import.Register", Unit: "Wh"},
+			},
+		}},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	return data
+}
+
+// sessionEvents is one session: Started, three Updated and Ended
+func sessionEvents(t *testing.T) [][]byte {
+	return [][]byte{
+		transactionEvent(t, "Started", 0, 1000),
+		transactionEvent(t, "Updated", 1, 2000),
+		transactionEvent(t, "Updated", 2, 3500),
+		transactionEvent(t, "Updated", 3, 5000),
+		transactionEvent(t, "Ended", 4, 6200),
+	}
+}
+
+func feed(t *testing.T, srv *Server, events [][]byte) {
+	t.Helper()
+	for _, event := range events {
+		if _, err := srv.handleTransactionEvent("CP-001", event); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
+
+func TestTransactionEvent_ShuffledMatchesInOrder(t *testing.T) {
+	// Arrange
+	inOrder, want := newSequenceTestServer(t)
+	feed(t, inOrder, sessionEvents(t))
+
+	for seed := int64(1); seed <= 20; seed++ {
+		t.Run(strconv.FormatInt(seed, 10), func(t *testing.T) {
+			srv, got := newSequenceTestServer(t)
+			events := sessionEvents(t)
+			// Replay a couple of events as a reconnecting station would
+			events = append(events, events[1], events[3])
+			rand.New(rand.NewSource(seed)).Shuffle(len(events), func(i, j int) {
+				events[i], events[j] = events[j], events[i]
+			})
+
+			// Act
+			feed(t, srv, events)
+
+			// Assert
+			if got.starts != 1 || got.stopped != 1 {
+				t.Fatalf("expected 1 start and 1 stop, got %d and %d", got.starts, got.stopped)
+			}
+			if got.tx.Status != want.tx.Status || got.tx.MeterStop != want.tx.MeterStop || got.tx.LastSeqNo != want.tx.LastSeqNo {
+				t.Errorf("expected status %s meter %d seqNo %d, got %s %d %d",
+					want.tx.Status, want.tx.MeterStop, want.tx.LastSeqNo,
+					got.tx.Status, got.tx.MeterStop, got.tx.LastSeqNo)
+			}
+			if len(got.meters) != len(want.meters) {
+				t.Fatalf("expected readings %v, got %v", want.meters, got.meters)
+			}
+			for i := range want.meters {
+				if got.meters[i] != want.meters[i] {
+					t.Fatalf("expected readings %v, got %v", want.meters, got.meters)
+				}
+			}
+		})
+	}
+}
+
+func TestTransactionEvent_IgnoresDuplicates(t *testing.T) {
+	// Arrange
+	srv, rec := newSequenceTestServer(t)
+	events := sessionEvents(t)
+
+	// Act
+	feed(t, srv, [][]byte{events[0], events[1], events[2], events[1], events[0], events[2]})
+
+	// Assert
+	if rec.starts != 1 {
+		t.Errorf("expected duplicate Started to be ignored, got %d starts", rec.starts)
+	}
+	if rec.tx.LastSeqNo != 2 || rec.tx.MeterStop != 3500 {
+		t.Errorf("expected seqNo 2 and meter 3500, got %d and %d", rec.tx.LastSeqNo, rec.tx.MeterStop)
+	}
+	if len(rec.meters) != 3 {
+		t.Errorf("expected 3 readings, got %v", rec.meters)
+	}
+}
+
+func TestTransactionEvent_GapIsFilledAfterTimeout(t *testing.T) {
+	// Arrange
+	srv, rec := newSequenceTestServer(t)
+	srv.seqGapTimeout = 20 * time.Millisecond
+	events := sessionEvents(t)
+
+	// Act: Updated seqNo 2 never arrives in time
+	feed(t, srv, [][]byte{events[0], events[1], events[3], events[4]})
+
+	rec.mu.Lock()
+	held := rec.stopped
+	rec.mu.Unlock()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rec.mu.Lock()
+		stopped := rec.stopped
+		rec.mu.Unlock()
+		if stopped == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected buffered events to be applied after the gap timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// The missing event shows up late and must not move the meter back
+	feed(t, srv, [][]byte{events[2]})
+
+	// Assert
+	if held != 0 {
+		t.Errorf("expected Ended to wait for the gap, got %d stops before the timeout", held)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.tx.MeterStop != 6200 || rec.tx.LastSeqNo != 4 {
+		t.Errorf("expected meter 6200 and seqNo 4, got %d and %d", rec.tx.MeterStop, rec.tx.LastSeqNo)
+	}
+	if got := rec.meters; len(got) != 4 || got[2] != 5000 || got[3] != 6200 {
+		t.Errorf("expected readings [1000 2000 5000 6200], got %v", got)
+	}
+}
+
+func TestTransactionEvent_ResumesSequenceAfterRestart(t *testing.T) {
+	// Arrange
+	before, rec := newSequenceTestServer(t)
+	events := sessionEvents(t)
+	feed(t, before, events[:2])
+	after := newSequenceTestServerFor(t, rec)
+
+	// Act
+	feed(t, after, [][]byte{events[1], events[2]})
+
+	// Assert
+	if rec.starts != 1 {
+		t.Fatalf("expected 1 start, got %d", rec.starts)
+	}
+	if rec.tx.LastSeqNo != 2 || rec.tx.MeterStop != 3500 {
+		t.Errorf("expected seqNo 2 applied at once, got seqNo %d meter %d", rec.tx.LastSeqNo, rec.tx.MeterStop)
+	}
+	if len(rec.meters) != 3 {
+		t.Errorf("expected the replayed event dropped, got meters %v", rec.meters)
+	}
+}
+
+func TestTransactionEvent_SameTransactionIDOnTwoStations(t *testing.T) {
+	// Arrange - both stations number their transactions from the same ID
+	var mu sync.Mutex
+	started := map[string]int{}
+	txService := &mocks.MockTransactionService{
+		StartTransactionFunc: func(ctx context.Context, deviceID string, connectorID int, userID string, idTag string) (*domain.Transaction, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			started[deviceID]++
+			return &domain.Transaction{ID: "tx-" + deviceID, ChargePointID: deviceID, Status: domain.TransactionStatusStarted}, nil
+		},
+	}
+	srv := NewServer(nil, txService, zap.NewNop())
+	t.Cleanup(srv.Stop)
+
+	// Act
+	for _, cpID := range []string{"CP-001", "CP-002"} {
+		if _, err := srv.handleTransactionEvent(cpID, transactionEvent(t, "Started", 0, 1000)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	// Assert
+	if started["CP-001"] != 1 || started["CP-002"] != 1 {
+		t.Fatalf("expected one session per station, got %v", started)
+	}
+	for _, cpID := range []string{"CP-001", "CP-002"} {
+		if txID := srv.domainTransactionID(context.Background(), cpID, "ocpp-tx-1"); txID != "tx-"+cpID {
+			t.Errorf("expected %s to map to tx-%s, got %q", cpID, cpID, txID)
+		}
+	}
+}
+
+func TestTransactionEvent_StartsOnEVSEConnector(t *testing.T) {
+	// Arrange - status notifications store EVSE 2 under connector 2
+	var connector int
+	txService := &mocks.MockTransactionService{
+		StartTransactionFunc: func(ctx context.Context, deviceID string, connectorID int, userID string, idTag string) (*domain.Transaction, error) {
+			connector = connectorID
+			return &domain.Transaction{ID: "tx-domain", ChargePointID: deviceID, Status: domain.TransactionStatusStarted}, nil
+		},
+	}
+	srv := NewServer(nil, txService, zap.NewNop())
+	t.Cleanup(srv.Stop)
+	req := TransactionEventRequest{
+		EventType:       "Started",
+		Timestamp:       "2026-10-16T12:00:00Z",
+		TransactionInfo: TransactionInfo{TransactionId: "ocpp-tx-1"},
+		Evse:            &Evse{Id: 2, ConnectorId: 1},
+	}
+	payload, _ := json.Marshal(req)
+
+	// Act
+	if _, err := srv.handleTransactionEvent("CP-001", payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Assert
+	if connector != 2 {
+		t.Errorf("expected the session on connector 2, got %d", connector)
+	}
+}
+
+func TestEnergyRegisterWh(t *testing.T) {
+	meterValues := []MeterValue{{
+		SampledValue: []SampledValue{
+			{Value: "7.4", Measurand: "Power.Active.Import", Unit: "kW"},
+			{Value: "12.5", Measurand: "Energy.Active.Import.Register", Unit: "kWh"},
+		},
+	}}
+
+	if got := energyRegisterWh(meterValues); got != 12500 {
+		t.Errorf("expected 12500 Wh, got %d", got)
+	}
+	if got := energyRegisterWh(nil); got != 0 {
+		t.Errorf("expected 0 without meter values, got %d", got)
+	}
+}
+
+func TestActivePowerKW(t *testing.T) {
+	meterValues := []MeterValue{
+		{Timestamp: "2026-10-16T12:00:00Z", SampledValue: []SampledValue{{Value: "7.4", Measurand: "Power.Active.Import", Unit: "kW"}}},
+		{Timestamp: "2026-10-16T12:01:00Z", SampledValue: []SampledValue{
+			{Value: "12.5", Measurand: "Energy.Active.Import.Register", Unit: "kWh"},
+			{Value: "11000", Measurand: "Power.Active.Import"},
+		}},
+	}
+
+	powerKW, sampledAt, ok := activePowerKW(meterValues)
+	if !ok || powerKW != 11 {
+		t.Errorf("expected the last reading of 11 kW, got %v (ok=%v)", powerKW, ok)
+	}
+	if sampledAt.Format(time.RFC3339) != "2026-10-16T12:01:00Z" {
+		t.Errorf("expected the reading's timestamp, got %v", sampledAt)
+	}
+	if _, _, ok := activePowerKW([]MeterValue{{SampledValue: []SampledValue{{Value: "12.5", Measurand: "Energy.Active.Import.Register"}}}}); ok {
+		t.Error("expected no power without a Power.Active.Import sample")
+	}
+}
+
+func TestSessionEnergyWh(t *testing.T) {
+	meterValues := []MeterValue{{SampledValue: []SampledValue{
+		{Value: "20500", Measurand: "Energy.Active.Import.Register"},
+		{Value: "8.25", Measurand: "Energy.Active.Import.Interval", Context: "Transaction.End", Unit: "kWh"},
+	}}}
+
+	energyWh, ok := sessionEnergyWh(meterValues)
+	if !ok || energyWh != 8250 {
+		t.Errorf("expected 8250 Wh, got %d (ok=%v)", energyWh, ok)
+	}
+	if _, ok := sessionEnergyWh([]MeterValue{{SampledValue: []SampledValue{{Value: "20500"}}}}); ok {
+		t.Error("expected no session energy without an Energy.Active.Import.Interval sample")
+	}
+}
@@ -0,0 +1,153 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// reconcileTimeout bounds the transaction check made when a station connects
+const reconcileTimeout = 30 * time.Second
+
+// GetTransactionStatus asks a charge point whether a transaction is still
+// ongoing and whether it holds queued messages for it. With an empty
+// transactionID only the queue is reported on.
+func (s *Server) GetTransactionStatus(ctx context.Context, chargePointID, transactionID string) (*GetTransactionStatusResponse, error) {
+	req := GetTransactionStatusRequest{
+		TransactionId: transactionID,
+	}
+
+	resp, err := s.SendCommand(ctx, chargePointID, "GetTransactionStatus", req)
+	if err != nil {
+		return nil, fmt.Errorf("get transaction status failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("get transaction status rejected: %s - %s", resp.Error.Code, resp.Error.Description)
+	}
+
+	var response GetTransactionStatusResponse
+	if err := json.Unmarshal(resp.Payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// reconcileTransactions runs when a station connects. Its transactions may
+// have changed on either side while it was away, so the station is asked
+// about each one it is known to run and our records follow its answers.
+func (s *Server) reconcileTransactions(chargePointID string) {
+	if s.txService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	defer cancel()
+
+	for _, ocppTxID := range s.knownTransactions(ctx, chargePointID) {
+		if err := s.reconcileTransaction(ctx, chargePointID, ocppTxID); err != nil {
+			s.log.Warn("Failed to reconcile transaction",
+				zap.String("chargePointID", chargePointID),
+				zap.String("transactionId", ocppTxID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// knownTransactions returns the OCPP transactionIds of the station's started
+// transactions in storage, which outlive a restart, and of the ones its EVSEs
+// last reported started, which we may have closed meanwhile
+func (s *Server) knownTransactions(ctx context.Context, chargePointID string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	if lister, ok := s.txService.(ports.StationTransactionLister); ok {
+		txs, err := lister.StartedTransactions(ctx, chargePointID)
+		if err != nil {
+			s.log.Warn("Failed to list started transactions",
+				zap.String("chargePointID", chargePointID),
+				zap.Error(err),
+			)
+		}
+		for _, tx := range txs {
+			if tx.OCPPTransactionID != "" && !seen[tx.OCPPTransactionID] {
+				seen[tx.OCPPTransactionID] = true
+				ids = append(ids, tx.OCPPTransactionID)
+			}
+		}
+	}
+	for _, ocppTxID := range s.ActiveTransactionIDs(chargePointID) {
+		if !seen[ocppTxID] {
+			seen[ocppTxID] = true
+			ids = append(ids, ocppTxID)
+		}
+	}
+	return ids
+}
+
+// reconcileTransaction syncs one transaction with what its station reports
+func (s *Server) reconcileTransaction(ctx context.Context, chargePointID, ocppTxID string) error {
+	status, err := s.GetTransactionStatus(ctx, chargePointID, ocppTxID)
+	if err != nil {
+		return err
+	}
+	if status.OngoingIndicator == nil {
+		return nil // Nothing said about the transaction itself
+	}
+	ongoing := *status.OngoingIndicator
+	if !ongoing {
+		s.untrackTransaction(chargePointID, ocppTxID)
+	}
+
+	txID := s.domainTransactionID(ctx, chargePointID, ocppTxID)
+	if txID == "" {
+		return nil // Not a transaction of ours
+	}
+	tx, err := s.txService.GetTransaction(ctx, txID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if tx == nil {
+		return nil
+	}
+	if ongoing {
+		s.trackEVSETransaction(chargePointID, tx.EvseID, ocppTxID)
+	}
+
+	switch {
+	case ongoing && tx.Status != domain.TransactionStatusStarted:
+		resumer, ok := s.txService.(ports.TransactionResumer)
+		if !ok {
+			return fmt.Errorf("station reports transaction %s ongoing but it is %s here", tx.ID, tx.Status)
+		}
+		if _, err := resumer.ResumeTransaction(ctx, tx.ID); err != nil {
+			return fmt.Errorf("failed to resume transaction: %w", err)
+		}
+		s.log.Info("Transaction resumed, station reports it ongoing",
+			zap.String("chargePointID", chargePointID),
+			zap.String("txID", tx.ID),
+			zap.String("previousStatus", string(tx.Status)),
+		)
+
+	case !ongoing && tx.Status == domain.TransactionStatusStarted:
+		if status.MessagesInQueue {
+			return nil // Its Ended event is on the way
+		}
+		if _, err := s.txService.StopTransaction(ctx, tx.ID); err != nil {
+			return fmt.Errorf("failed to stop transaction: %w", err)
+		}
+		s.log.Info("Transaction stopped, station reports it ended",
+			zap.String("chargePointID", chargePointID),
+			zap.String("txID", tx.ID),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// connectStatusStation dials the server as chargePointID and answers every
+// GetTransactionStatus with status
+func connectStatusStation(t *testing.T, srv *Server, chargePointID string, status GetTransactionStatusResponse) <-chan GetTransactionStatusRequest {
+	t.Helper()
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	t.Cleanup(httpSrv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/"+chargePointID, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	asked := make(chan GetTransactionStatusRequest, 4)
+	go func() {
+		for {
+			var raw []json.RawMessage
+			if err := conn.ReadJSON(&raw); err != nil {
+				return
+			}
+			var msgID, action string
+			json.Unmarshal(raw[1], &msgID)
+			json.Unmarshal(raw[2], &action)
+			if action != "GetTransactionStatus" {
+				continue
+			}
+			var req GetTransactionStatusRequest
+			json.Unmarshal(raw[3], &req)
+			asked <- req
+			conn.WriteJSON([]interface{}{CallResult, msgID, status})
+		}
+	}()
+	return asked
+}
+
+func TestReconcileTransactions_ResumesSessionStationStillRuns(t *testing.T) {
+	// Arrange: we closed tx-1, but the station kept charging while it was away
+	var mu sync.Mutex
+	tx := &domain.Transaction{ID: "tx-1", ChargePointID: "CP-001", Status: domain.TransactionStatusStopped}
+	txService := &mocks.MockTransactionService{
+		GetTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			copied := *tx
+			return &copied, nil
+		},
+		FindByOCPPTransactionFunc: func(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: "tx-1"}, nil
+		},
+		ResumeTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			tx.Status = domain.TransactionStatusStarted
+			return tx, nil
+		},
+	}
+	srv := NewServer(nil, txService, zap.NewNop())
+	defer srv.Stop()
+	srv.trackEVSETransaction("CP-001", 1, "tx-1")
+	ongoing := true
+
+	// Act
+	asked := connectStatusStation(t, srv, "CP-001", GetTransactionStatusResponse{OngoingIndicator: &ongoing})
+
+	// Assert
+	if req := <-asked; req.TransactionId != "tx-1" {
+		t.Errorf("expected the station to be asked about tx-1, got %q", req.TransactionId)
+	}
+	waitUntil(t, "tx-1 is resumed", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return tx.Status == domain.TransactionStatusStarted
+	})
+	if ids := srv.ActiveTransactionIDs("CP-001"); len(ids) != 1 || ids[0] != "tx-1" {
+		t.Errorf("expected tx-1 to stay tracked, got %v", ids)
+	}
+}
+
+func TestReconcileTransactions_StopsSessionStationEnded(t *testing.T) {
+	// Arrange
+	stopped := make(chan string, 1)
+	txService := &mocks.MockTransactionService{
+		GetTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: id, Status: domain.TransactionStatusStarted}, nil
+		},
+		FindByOCPPTransactionFunc: func(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: "tx-1"}, nil
+		},
+		StopTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			stopped <- id
+			return &domain.Transaction{ID: id, Status: domain.TransactionStatusStopped}, nil
+		},
+	}
+	srv := NewServer(nil, txService, zap.NewNop())
+	defer srv.Stop()
+	srv.trackEVSETransaction("CP-001", 1, "tx-1")
+	ongoing := false
+
+	// Act
+	connectStatusStation(t, srv, "CP-001", GetTransactionStatusResponse{OngoingIndicator: &ongoing})
+
+	// Assert
+	if id := <-stopped; id != "tx-1" {
+		t.Errorf("expected tx-1 to be stopped, got %s", id)
+	}
+	waitUntil(t, "tx-1 is untracked", func() bool {
+		return len(srv.ActiveTransactionIDs("CP-001")) == 0
+	})
+}
+
+func TestReconcileTransactions_ChecksStoredTransactionsAfterRestart(t *testing.T) {
+	// Arrange: nothing tracked in memory, but the database holds a started
+	// session on the station
+	stopped := make(chan string, 1)
+	txService := &mocks.MockTransactionService{
+		StartedTransactionsFunc: func(ctx context.Context, chargePointID string) ([]domain.Transaction, error) {
+			return []domain.Transaction{{ID: "tx-1", ChargePointID: chargePointID, OCPPTransactionID: "ocpp-1", Status: domain.TransactionStatusStarted}}, nil
+		},
+		GetTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: id, Status: domain.TransactionStatusStarted}, nil
+		},
+		FindByOCPPTransactionFunc: func(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: "tx-1"}, nil
+		},
+		StopTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			stopped <- id
+			return &domain.Transaction{ID: id, Status: domain.TransactionStatusStopped}, nil
+		},
+	}
+	srv := NewServer(nil, txService, zap.NewNop())
+	defer srv.Stop()
+	ongoing := false
+
+	// Act
+	asked := connectStatusStation(t, srv, "CP-001", GetTransactionStatusResponse{OngoingIndicator: &ongoing})
+
+	// Assert
+	if req := <-asked; req.TransactionId != "ocpp-1" {
+		t.Errorf("expected the station to be asked about ocpp-1, got %q", req.TransactionId)
+	}
+	if id := <-stopped; id != "tx-1" {
+		t.Errorf("expected tx-1 to be stopped, got %s", id)
+	}
+}
+
+func TestReconcileTransactions_WaitsForQueuedMessages(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, &mocks.MockTransactionService{
+		GetTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: id, Status: domain.TransactionStatusStarted}, nil
+		},
+		FindByOCPPTransactionFunc: func(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: "tx-1"}, nil
+		},
+		StopTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			t.Error("expected the station's own Ended event to stop the transaction")
+			return nil, nil
+		},
+	}, zap.NewNop())
+	defer srv.Stop()
+	srv.trackEVSETransaction("CP-001", 1, "tx-1")
+	ongoing := false
+
+	// Act
+	srv.reconcileTransactions("CP-002") // Not connected and nothing tracked: no-op
+	connectStatusStation(t, srv, "CP-001", GetTransactionStatusResponse{OngoingIndicator: &ongoing, MessagesInQueue: true})
+
+	// Assert
+	waitUntil(t, "tx-1 is untracked", func() bool {
+		return len(srv.ActiveTransactionIDs("CP-001")) == 0
+	})
+}
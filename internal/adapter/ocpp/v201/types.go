@@ -1,5 +1,11 @@
 package v201
 
+import (
+	"encoding/json"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
 // MessageType represents the type of OCPP message
 type MessageType int
 
@@ -51,6 +57,10 @@ type BootNotificationResponse struct {
 	CurrentTime string `json:"currentTime"`
 	Interval    int    `json:"interval"`
 	Status      string `json:"status"` // Accepted, Pending, Rejected
+
+	// unversioned is the boot info of a station that left its firmware
+	// version out, stored once the response went out and the version read
+	unversioned *domain.BootInfo
 }
 
 type HeartbeatRequest struct{}
@@ -99,9 +109,11 @@ type MeterValue struct {
 }
 
 type SampledValue struct {
-	Value     string `json:"value"`
+	Value     string `json:"value"` // Sent as a number or a string, see UnmarshalJSON
 	Context   string `json:"context,omitempty"`
 	Measurand string `json:"measurand,omitempty"` // Energy.Active.Import.Register
+	Phase     string `json:"phase,omitempty"`     // L1, L2, L3, N, L1-N, ...
+	Location  string `json:"location,omitempty"`  // Body, Cable, EV, Inlet, Outlet
 	Unit      string `json:"unit,omitempty"`      // Wh, kWh
 }
 
@@ -156,6 +168,7 @@ type ChargingProfile struct {
 	RecurrencyKind         string             `json:"recurrencyKind,omitempty"`
 	ValidFrom              *string            `json:"validFrom,omitempty"`
 	ValidTo                *string            `json:"validTo,omitempty"`
+	TransactionId          *string            `json:"transactionId,omitempty"` // TxProfile only
 	ChargingSchedule       []ChargingSchedule `json:"chargingSchedule"`
 }
 
@@ -319,6 +332,32 @@ type FirmwareStatusNotificationRequest struct {
 // FirmwareStatusNotificationResponse - CSMS acknowledges
 type FirmwareStatusNotificationResponse struct{}
 
+// PublishFirmwareRequest - CSMS asks a local controller to download firmware
+// and serve it to the charge points behind it
+type PublishFirmwareRequest struct {
+	Location      string `json:"location"` // URL to download firmware
+	Retries       *int   `json:"retries,omitempty"`
+	Checksum      string `json:"checksum"` // MD5 of the firmware file, hex
+	RequestId     int    `json:"requestId"`
+	RetryInterval *int   `json:"retryInterval,omitempty"`
+}
+
+// PublishFirmwareResponse - Response from the local controller
+type PublishFirmwareResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// PublishFirmwareStatusNotificationRequest - Local controller notifies publish status
+type PublishFirmwareStatusNotificationRequest struct {
+	Status    string   `json:"status"`             // Idle, DownloadScheduled, Downloading, Downloaded, Published, DownloadFailed, DownloadPaused, InvalidChecksum, ChecksumVerified, PublishFailed
+	Location  []string `json:"location,omitempty"` // URIs the firmware is served at, one per protocol; set when Published
+	RequestId *int     `json:"requestId,omitempty"`
+}
+
+// PublishFirmwareStatusNotificationResponse - CSMS acknowledges
+type PublishFirmwareStatusNotificationResponse struct{}
+
 // GetVariablesRequest - CSMS requests variable values
 type GetVariablesRequest struct {
 	GetVariableData []GetVariableData `json:"getVariableData"`
@@ -410,6 +449,26 @@ type UnlockConnectorResponse struct {
 	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
 }
 
+// ClearCacheRequest - CSMS asks the charge point to clear its Authorization Cache
+type ClearCacheRequest struct{}
+
+// ClearCacheResponse - Response from charge point
+type ClearCacheResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// GetTransactionStatusRequest - CSMS asks about a transaction and queued messages
+type GetTransactionStatusRequest struct {
+	TransactionId string `json:"transactionId,omitempty"`
+}
+
+// GetTransactionStatusResponse - Response from charge point
+type GetTransactionStatusResponse struct {
+	OngoingIndicator *bool `json:"ongoingIndicator,omitempty"` // Only set when a transactionId was asked about
+	MessagesInQueue  bool  `json:"messagesInQueue"`
+}
+
 // ChangeAvailabilityRequest - CSMS changes charge point/EVSE availability
 type ChangeAvailabilityRequest struct {
 	OperationalStatus string `json:"operationalStatus"` // Operative, Inoperative
@@ -516,6 +575,85 @@ type AuthorizeResponse struct {
 	CertificateStatus    *string       `json:"certificateStatus,omitempty"` // Accepted, SignatureError, CertificateExpired, CertificateRevoked, NoCertificateAvailable, CertChainError, ContractCancelled
 }
 
+// --- Security Messages ---
+
+// SignCertificateRequest - Charge point asks the CSMS to sign a CSR
+type SignCertificateRequest struct {
+	Csr             string  `json:"csr"`                       // PEM encoded PKCS#10 request
+	CertificateType *string `json:"certificateType,omitempty"` // ChargingStationCertificate (default) or V2GCertificate
+}
+
+// SignCertificateResponse - CSMS accepts or rejects the CSR
+type SignCertificateResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+
+	// issued is sent back in CertificateSigned once the response went out
+	issued *domain.StationCertificate
+}
+
+// CertificateSignedRequest - CSMS delivers the signed certificate chain
+type CertificateSignedRequest struct {
+	CertificateChain string  `json:"certificateChain"` // PEM, leaf first
+	CertificateType  *string `json:"certificateType,omitempty"`
+}
+
+// CertificateSignedResponse - Charge point confirms it installed the certificate
+type CertificateSignedResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// CertificateHashData identifies an installed certificate (OCPP 2.0.1 CertificateHashDataType)
+type CertificateHashData struct {
+	HashAlgorithm  string `json:"hashAlgorithm"` // SHA256, SHA384, SHA512
+	IssuerNameHash string `json:"issuerNameHash"`
+	IssuerKeyHash  string `json:"issuerKeyHash"`
+	SerialNumber   string `json:"serialNumber"`
+}
+
+// CertificateHashDataChain is an installed certificate with its sub CAs
+type CertificateHashDataChain struct {
+	CertificateType          string                `json:"certificateType"` // V2GRootCertificate, MORootCertificate, CSMSRootCertificate, V2GCertificateChain, ManufacturerRootCertificate
+	CertificateHashData      CertificateHashData   `json:"certificateHashData"`
+	ChildCertificateHashData []CertificateHashData `json:"childCertificateHashData,omitempty"`
+}
+
+// InstallCertificateRequest - CSMS adds a root certificate to the station trust store
+type InstallCertificateRequest struct {
+	CertificateType string `json:"certificateType"` // V2GRootCertificate, MORootCertificate, CSMSRootCertificate, ManufacturerRootCertificate
+	Certificate     string `json:"certificate"`     // PEM
+}
+
+// InstallCertificateResponse - Result of the installation
+type InstallCertificateResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected, Failed
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// DeleteCertificateRequest - CSMS removes an installed certificate
+type DeleteCertificateRequest struct {
+	CertificateHashData CertificateHashData `json:"certificateHashData"`
+}
+
+// DeleteCertificateResponse - Result of the deletion
+type DeleteCertificateResponse struct {
+	Status     string      `json:"status"` // Accepted, Failed, NotFound
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// GetInstalledCertificateIdsRequest - CSMS lists the station trust store
+type GetInstalledCertificateIdsRequest struct {
+	CertificateType []string `json:"certificateType,omitempty"` // Empty lists every type
+}
+
+// GetInstalledCertificateIdsResponse - Installed certificates
+type GetInstalledCertificateIdsResponse struct {
+	Status                   string                     `json:"status"` // Accepted, NotFound
+	StatusInfo               *StatusInfo                `json:"statusInfo,omitempty"`
+	CertificateHashDataChain []CertificateHashDataChain `json:"certificateHashDataChain,omitempty"`
+}
+
 // --- Metering Messages ---
 
 // MeterValuesRequest - Charge point sends meter values
@@ -527,6 +665,72 @@ type MeterValuesRequest struct {
 // MeterValuesResponse - CSMS acknowledges
 type MeterValuesResponse struct{}
 
+// --- Reservation Messages ---
+
+// ReserveNowRequest - CSMS reserves an EVSE for an idToken
+type ReserveNowRequest struct {
+	Id             int      `json:"id"`
+	ExpiryDateTime string   `json:"expiryDateTime"`
+	ConnectorType  string   `json:"connectorType,omitempty"`
+	IdToken        IdToken  `json:"idToken"`
+	EvseId         *int     `json:"evseId,omitempty"`
+	GroupIdToken   *IdToken `json:"groupIdToken,omitempty"`
+}
+
+// ReserveNowResponse - Response from charge point
+type ReserveNowResponse struct {
+	Status     string      `json:"status"` // Accepted, Faulted, Occupied, Rejected, Unavailable
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// CancelReservationRequest - CSMS cancels a reservation
+type CancelReservationRequest struct {
+	ReservationId int `json:"reservationId"`
+}
+
+// CancelReservationResponse - Response from charge point
+type CancelReservationResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// ReservationStatusUpdateRequest - Charge point reports a reservation ended
+// without being used
+type ReservationStatusUpdateRequest struct {
+	ReservationId           int    `json:"reservationId"`
+	ReservationUpdateStatus string `json:"reservationUpdateStatus"` // Expired, Removed
+}
+
+// ReservationStatusUpdateResponse - CSMS acknowledges
+type ReservationStatusUpdateResponse struct{}
+
+// --- Tariff and Cost Messages ---
+
+// CostUpdatedRequest - CSMS sends the running cost of a transaction
+type CostUpdatedRequest struct {
+	TotalCost     float64 `json:"totalCost"`
+	TransactionId string  `json:"transactionId"`
+}
+
+// CostUpdatedResponse - Charge point acknowledges
+type CostUpdatedResponse struct{}
+
+// --- Data Transfer Messages ---
+
+// DataTransferRequest - Vendor-specific message, sent in either direction
+type DataTransferRequest struct {
+	MessageId string          `json:"messageId,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	VendorId  string          `json:"vendorId"`
+}
+
+// DataTransferResponse - Response to a DataTransfer
+type DataTransferResponse struct {
+	Status     string          `json:"status"` // Accepted, Rejected, UnknownMessageId, UnknownVendorId
+	StatusInfo *StatusInfo     `json:"statusInfo,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
 // --- Diagnostics Messages ---
 
 // GetLogRequest - CSMS requests diagnostic log
@@ -559,3 +763,161 @@ type LogStatusNotificationRequest struct {
 
 // LogStatusNotificationResponse - CSMS acknowledges
 type LogStatusNotificationResponse struct{}
+
+// --- Monitoring Messages ---
+
+// SetMonitoringBaseRequest - CSMS activates a predefined set of monitors
+type SetMonitoringBaseRequest struct {
+	MonitoringBase string `json:"monitoringBase"` // All, FactoryDefault, HardWiredOnly
+}
+
+// SetMonitoringBaseResponse - Response from charge point
+type SetMonitoringBaseResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected, NotSupported, EmptyResultSet
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// SetVariableMonitoringRequest - CSMS configures monitors on variables
+type SetVariableMonitoringRequest struct {
+	SetMonitoringData []SetMonitoringData `json:"setMonitoringData"`
+}
+
+// SetMonitoringData describes a single variable monitor
+type SetMonitoringData struct {
+	Id          *int      `json:"id,omitempty"` // Omit to create, set to replace an existing monitor
+	Transaction bool      `json:"transaction,omitempty"`
+	Value       float64   `json:"value"`
+	Type        string    `json:"type"`     // UpperThreshold, LowerThreshold, Delta, Periodic, PeriodicClockAligned
+	Severity    int       `json:"severity"` // 0 (Danger) .. 9 (Debug)
+	Component   Component `json:"component"`
+	Variable    Variable  `json:"variable"`
+}
+
+// SetVariableMonitoringResponse - Response from charge point
+type SetVariableMonitoringResponse struct {
+	SetMonitoringResult []SetMonitoringResult `json:"setMonitoringResult"`
+}
+
+// SetMonitoringResult contains the result for each monitor
+type SetMonitoringResult struct {
+	Id         *int        `json:"id,omitempty"`
+	Status     string      `json:"status"` // Accepted, UnknownComponent, UnknownVariable, UnsupportedMonitorType, Rejected, Duplicate
+	Type       string      `json:"type"`
+	Severity   int         `json:"severity"`
+	Component  Component   `json:"component"`
+	Variable   Variable    `json:"variable"`
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// NotifyEventRequest - Charge point reports monitor events
+type NotifyEventRequest struct {
+	GeneratedAt string      `json:"generatedAt"`
+	Tbc         bool        `json:"tbc,omitempty"`
+	SeqNo       int         `json:"seqNo"`
+	EventData   []EventData `json:"eventData"`
+}
+
+// EventData describes a single monitor event
+type EventData struct {
+	EventId               int       `json:"eventId"`
+	Timestamp             string    `json:"timestamp"`
+	Trigger               string    `json:"trigger"` // Alerting, Delta, Periodic
+	Cause                 *int      `json:"cause,omitempty"`
+	ActualValue           string    `json:"actualValue"`
+	TechCode              string    `json:"techCode,omitempty"`
+	TechInfo              string    `json:"techInfo,omitempty"`
+	Cleared               bool      `json:"cleared,omitempty"`
+	TransactionId         string    `json:"transactionId,omitempty"`
+	VariableMonitoringId  *int      `json:"variableMonitoringId,omitempty"`
+	EventNotificationType string    `json:"eventNotificationType"` // HardWiredNotification, HardWiredMonitor, PreconfiguredMonitor, CustomMonitor
+	Component             Component `json:"component"`
+	Variable              Variable  `json:"variable"`
+}
+
+// NotifyEventResponse - CSMS acknowledges
+type NotifyEventResponse struct{}
+
+// --- Device Model Reporting ---
+
+// GetBaseReportRequest - CSMS requests a device model report
+type GetBaseReportRequest struct {
+	RequestId  int    `json:"requestId"`
+	ReportBase string `json:"reportBase"` // ConfigurationInventory, FullInventory, SummaryInventory
+}
+
+// GetBaseReportResponse - Response from charge point
+type GetBaseReportResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected, NotSupported, EmptyResultSet
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// NotifyReportRequest - Charge point sends (part of) a requested report
+type NotifyReportRequest struct {
+	RequestId   int          `json:"requestId"`
+	GeneratedAt string       `json:"generatedAt"`
+	Tbc         bool         `json:"tbc,omitempty"` // More parts follow
+	SeqNo       int          `json:"seqNo"`
+	ReportData  []ReportData `json:"reportData,omitempty"`
+}
+
+// ReportData describes one component/variable of the device model
+type ReportData struct {
+	Component               Component                `json:"component"`
+	Variable                Variable                 `json:"variable"`
+	VariableAttribute       []VariableAttribute      `json:"variableAttribute"`
+	VariableCharacteristics *VariableCharacteristics `json:"variableCharacteristics,omitempty"`
+}
+
+// VariableAttribute holds one attribute value of a variable
+type VariableAttribute struct {
+	Type       string `json:"type,omitempty"` // Actual, Target, MinSet, MaxSet
+	Value      string `json:"value,omitempty"`
+	Mutability string `json:"mutability,omitempty"` // ReadOnly, WriteOnly, ReadWrite
+	Persistent bool   `json:"persistent,omitempty"`
+	Constant   bool   `json:"constant,omitempty"`
+}
+
+// VariableCharacteristics describes the fixed properties of a variable
+type VariableCharacteristics struct {
+	Unit               string   `json:"unit,omitempty"`
+	DataType           string   `json:"dataType"` // string, decimal, integer, dateTime, boolean, OptionList, SequenceList, MemberList
+	MinLimit           *float64 `json:"minLimit,omitempty"`
+	MaxLimit           *float64 `json:"maxLimit,omitempty"`
+	ValuesList         string   `json:"valuesList,omitempty"`
+	SupportsMonitoring bool     `json:"supportsMonitoring"`
+}
+
+// NotifyReportResponse - CSMS acknowledges
+type NotifyReportResponse struct{}
+
+// --- Customer Information ---
+
+// CustomerInformationRequest - CSMS asks the charge point to report and/or
+// clear the data it keeps about a customer. Exactly one identifier is set.
+type CustomerInformationRequest struct {
+	RequestId           int                  `json:"requestId"`
+	Report              bool                 `json:"report"`
+	Clear               bool                 `json:"clear"`
+	CustomerIdentifier  string               `json:"customerIdentifier,omitempty"`
+	IdToken             *IdToken             `json:"idToken,omitempty"`
+	CustomerCertificate *CertificateHashData `json:"customerCertificate,omitempty"`
+}
+
+// CustomerInformationResponse - Response from charge point
+type CustomerInformationResponse struct {
+	Status     string      `json:"status"` // Accepted, Rejected, Invalid
+	StatusInfo *StatusInfo `json:"statusInfo,omitempty"`
+}
+
+// NotifyCustomerInformationRequest - Charge point sends (part of) the
+// customer data requested with CustomerInformation
+type NotifyCustomerInformationRequest struct {
+	Data        string `json:"data"`
+	Tbc         bool   `json:"tbc,omitempty"` // More parts follow
+	SeqNo       int    `json:"seqNo"`
+	GeneratedAt string `json:"generatedAt"`
+	RequestId   int    `json:"requestId"`
+}
+
+// NotifyCustomerInformationResponse - CSMS acknowledges
+type NotifyCustomerInformationResponse struct{}
@@ -0,0 +1,254 @@
+package v201
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// VariableCacheConfig controls how long variable values read from charge
+// points are reused and how reads are batched
+type VariableCacheConfig struct {
+	DefaultTTL  time.Duration
+	TTLs        map[string]time.Duration // Per variable name, overrides DefaultTTL
+	BatchWindow time.Duration            // Reads arriving within this window share one GetVariables call
+}
+
+// DefaultVariableCacheConfig returns the settings used by NewServer. Static
+// identification data lives long; operational limits are refreshed often.
+func DefaultVariableCacheConfig() VariableCacheConfig {
+	return VariableCacheConfig{
+		DefaultTTL: time.Minute,
+		TTLs: map[string]time.Duration{
+			"FirmwareVersion": time.Hour,
+			"SerialNumber":    24 * time.Hour,
+			"Model":           24 * time.Hour,
+			"VendorName":      24 * time.Hour,
+			"Power":           15 * time.Second,
+			"Available":       15 * time.Second,
+		},
+		BatchWindow: 20 * time.Millisecond,
+	}
+}
+
+// variableKey identifies one attribute of a component variable
+type variableKey struct {
+	component, componentInstance string
+	evse, connector              int
+	variable, variableInstance   string
+	attributeType                string
+}
+
+func keyOf(c Component, v Variable, attributeType string) variableKey {
+	if attributeType == "" {
+		attributeType = "Actual"
+	}
+	k := variableKey{
+		component:         c.Name,
+		componentInstance: c.Instance,
+		variable:          v.Name,
+		variableInstance:  v.Instance,
+		attributeType:     attributeType,
+	}
+	if c.Evse != nil {
+		k.evse = c.Evse.Id
+		k.connector = c.Evse.ConnectorId
+	}
+	return k
+}
+
+type cachedVariable struct {
+	result    GetVariableResult
+	expiresAt time.Time
+}
+
+// variableBatch collects the cache misses of one charge point until the
+// batch window closes
+type variableBatch struct {
+	variables map[variableKey]GetVariableData
+	done      chan struct{}
+	results   map[variableKey]GetVariableResult
+	err       error
+}
+
+type variableFetcher func(ctx context.Context, chargePointID string, variables []GetVariableData) (*GetVariablesResponse, error)
+
+// variableCache is a read-through cache of GetVariables results per charge
+// point
+type variableCache struct {
+	config VariableCacheConfig
+	fetch  variableFetcher
+	log    *zap.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	values  map[string]map[variableKey]cachedVariable // chargePointID → values
+	batches map[string]*variableBatch                 // chargePointID → open batch
+}
+
+func newVariableCache(config VariableCacheConfig, fetch variableFetcher, log *zap.Logger) *variableCache {
+	return &variableCache{
+		config:  config,
+		fetch:   fetch,
+		log:     log,
+		now:     time.Now,
+		values:  make(map[string]map[variableKey]cachedVariable),
+		batches: make(map[string]*variableBatch),
+	}
+}
+
+func (c *variableCache) ttl(variable string) time.Duration {
+	if ttl, ok := c.config.TTLs[variable]; ok {
+		return ttl
+	}
+	return c.config.DefaultTTL
+}
+
+// get returns the results for variables in request order. Fresh cached values
+// are served without contacting the charge point unless forceRefresh is set.
+func (c *variableCache) get(ctx context.Context, chargePointID string, variables []GetVariableData, forceRefresh bool) ([]GetVariableResult, error) {
+	results := make([]GetVariableResult, len(variables))
+	keys := make([]variableKey, len(variables))
+	var missing []int
+
+	c.mu.Lock()
+	now := c.now()
+	for i, v := range variables {
+		keys[i] = keyOf(v.Component, v.Variable, v.AttributeType)
+		if cached, ok := c.values[chargePointID][keys[i]]; ok && !forceRefresh && now.Before(cached.expiresAt) {
+			results[i] = cached.result
+			continue
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		c.mu.Unlock()
+		return results, nil
+	}
+
+	batch := c.batches[chargePointID]
+	if batch == nil {
+		batch = &variableBatch{
+			variables: make(map[variableKey]GetVariableData),
+			done:      make(chan struct{}),
+		}
+		c.batches[chargePointID] = batch
+		time.AfterFunc(c.config.BatchWindow, func() { c.flush(chargePointID, batch) })
+	}
+	for _, i := range missing {
+		batch.variables[keys[i]] = variables[i]
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-batch.done:
+	}
+	if batch.err != nil {
+		return nil, batch.err
+	}
+
+	for _, i := range missing {
+		result, ok := batch.results[keys[i]]
+		if !ok {
+			result = GetVariableResult{
+				AttributeStatus: "Rejected",
+				AttributeType:   keys[i].attributeType,
+				Component:       variables[i].Component,
+				Variable:        variables[i].Variable,
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// flush sends one GetVariables call for the whole batch and caches the
+// accepted values
+func (c *variableCache) flush(chargePointID string, batch *variableBatch) {
+	c.mu.Lock()
+	if c.batches[chargePointID] == batch {
+		delete(c.batches, chargePointID)
+	}
+	variables := make([]GetVariableData, 0, len(batch.variables))
+	for _, v := range batch.variables {
+		variables = append(variables, v)
+	}
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCommandTimeout)
+	defer cancel()
+	resp, err := c.fetch(ctx, chargePointID, variables)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer close(batch.done)
+
+	if err != nil {
+		batch.err = err
+		return
+	}
+
+	batch.results = make(map[variableKey]GetVariableResult, len(resp.GetVariableResult))
+	if c.values[chargePointID] == nil {
+		c.values[chargePointID] = make(map[variableKey]cachedVariable)
+	}
+	now := c.now()
+	for _, result := range resp.GetVariableResult {
+		key := keyOf(result.Component, result.Variable, result.AttributeType)
+		batch.results[key] = result
+		if result.AttributeStatus == "Accepted" {
+			c.values[chargePointID][key] = cachedVariable{
+				result:    result,
+				expiresAt: now.Add(c.ttl(result.Variable.Name)),
+			}
+		}
+	}
+
+	c.log.Debug("Variables fetched",
+		zap.String("chargePointID", chargePointID),
+		zap.Int("requested", len(variables)),
+		zap.Int("returned", len(resp.GetVariableResult)),
+	)
+}
+
+// invalidate drops every cached attribute of the given variables
+func (c *variableCache) invalidate(chargePointID string, variables []SetVariableData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := c.values[chargePointID]
+	for _, v := range variables {
+		target := keyOf(v.Component, v.Variable, "")
+		for key := range values {
+			if sameVariable(key, target) {
+				delete(values, key)
+			}
+		}
+	}
+}
+
+// sameVariable reports whether two keys name the same variable, whatever the
+// attribute type
+func sameVariable(a, b variableKey) bool {
+	a.attributeType, b.attributeType = "", ""
+	return a == b
+}
+
+// forget drops everything cached for a charge point when it disconnects
+func (c *variableCache) forget(chargePointID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, chargePointID)
+}
+
+// ReadVariables returns variable values from the cache, contacting the charge
+// point only for missing or expired values, or for all of them when
+// forceRefresh is set. Concurrent reads for the same charge point within the
+// batch window are combined into one GetVariables call.
+func (s *Server) ReadVariables(ctx context.Context, chargePointID string, variables []GetVariableData, forceRefresh bool) ([]GetVariableResult, error) {
+	return s.variables.get(ctx, chargePointID, variables, forceRefresh)
+}
@@ -0,0 +1,268 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// stationVariables answers GetVariables from a fixed set of values and counts
+// the calls
+type stationVariables struct {
+	mu     sync.Mutex
+	values map[string]string // variable name → value
+	calls  int32
+	asked  [][]GetVariableData
+}
+
+func (f *stationVariables) fetch(ctx context.Context, chargePointID string, variables []GetVariableData) (*GetVariablesResponse, error) {
+	atomic.AddInt32(&f.calls, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.asked = append(f.asked, variables)
+
+	resp := &GetVariablesResponse{}
+	for _, v := range variables {
+		result := GetVariableResult{Component: v.Component, Variable: v.Variable, AttributeStatus: "UnknownVariable"}
+		if value, ok := f.values[v.Variable.Name]; ok {
+			result.AttributeStatus = "Accepted"
+			result.AttributeValue = value
+		}
+		resp.GetVariableResult = append(resp.GetVariableResult, result)
+	}
+	return resp, nil
+}
+
+func variable(component, name string) GetVariableData {
+	return GetVariableData{Component: Component{Name: component}, Variable: Variable{Name: name}}
+}
+
+func testVariableConfig() VariableCacheConfig {
+	return VariableCacheConfig{
+		DefaultTTL:  time.Minute,
+		TTLs:        map[string]time.Duration{"FirmwareVersion": time.Hour},
+		BatchWindow: 10 * time.Millisecond,
+	}
+}
+
+func TestVariableCache_HitAndMiss(t *testing.T) {
+	// Arrange
+	station := &stationVariables{values: map[string]string{"FirmwareVersion": "1.2.3", "Power": "22000"}}
+	cache := newVariableCache(testVariableConfig(), station.fetch, zap.NewNop())
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+	vars := []GetVariableData{variable("ChargingStation", "FirmwareVersion"), variable("EVSE", "Power")}
+
+	// Act
+	first, err := cache.get(ctx, "CP-1", vars, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second, _ := cache.get(ctx, "CP-1", vars, false)
+
+	// Assert
+	if calls := atomic.LoadInt32(&station.calls); calls != 1 {
+		t.Errorf("expected the second read to be served from cache, got %d station calls", calls)
+	}
+	if first[0].AttributeValue != "1.2.3" || second[1].AttributeValue != "22000" {
+		t.Errorf("expected values in request order, got %+v", second)
+	}
+
+	// Power expires after the default TTL, FirmwareVersion is still fresh
+	now = now.Add(2 * time.Minute)
+	cache.get(ctx, "CP-1", vars, false)
+	if calls := atomic.LoadInt32(&station.calls); calls != 2 {
+		t.Fatalf("expected a miss after expiry, got %d station calls", calls)
+	}
+	if asked := station.asked[1]; len(asked) != 1 || asked[0].Variable.Name != "Power" {
+		t.Errorf("expected only the expired variable to be fetched, got %+v", asked)
+	}
+
+	// Other charge points have their own cache
+	cache.get(ctx, "CP-2", vars, false)
+	if calls := atomic.LoadInt32(&station.calls); calls != 3 {
+		t.Errorf("expected a miss for another charge point, got %d station calls", calls)
+	}
+}
+
+func TestVariableCache_ForceRefresh(t *testing.T) {
+	// Arrange
+	station := &stationVariables{values: map[string]string{"FirmwareVersion": "1.2.3"}}
+	cache := newVariableCache(testVariableConfig(), station.fetch, zap.NewNop())
+	ctx := context.Background()
+	vars := []GetVariableData{variable("ChargingStation", "FirmwareVersion")}
+	cache.get(ctx, "CP-1", vars, false)
+
+	station.mu.Lock()
+	station.values["FirmwareVersion"] = "2.0.0"
+	station.mu.Unlock()
+
+	// Act
+	results, _ := cache.get(ctx, "CP-1", vars, true)
+
+	// Assert
+	if results[0].AttributeValue != "2.0.0" {
+		t.Errorf("expected refreshed value 2.0.0, got %s", results[0].AttributeValue)
+	}
+	if calls := atomic.LoadInt32(&station.calls); calls != 2 {
+		t.Errorf("expected forceRefresh to contact the station, got %d calls", calls)
+	}
+}
+
+func TestVariableCache_DoesNotCacheRejectedResults(t *testing.T) {
+	// Arrange
+	station := &stationVariables{values: map[string]string{}}
+	cache := newVariableCache(testVariableConfig(), station.fetch, zap.NewNop())
+	ctx := context.Background()
+	vars := []GetVariableData{variable("ChargingStation", "Unknown")}
+
+	// Act
+	cache.get(ctx, "CP-1", vars, false)
+	results, _ := cache.get(ctx, "CP-1", vars, false)
+
+	// Assert
+	if results[0].AttributeStatus != "UnknownVariable" {
+		t.Errorf("expected UnknownVariable, got %s", results[0].AttributeStatus)
+	}
+	if calls := atomic.LoadInt32(&station.calls); calls != 2 {
+		t.Errorf("expected rejected results not to be cached, got %d calls", calls)
+	}
+}
+
+func TestVariableCache_BatchesConcurrentReads(t *testing.T) {
+	// Arrange
+	station := &stationVariables{values: map[string]string{"FirmwareVersion": "1.2.3", "Power": "22000"}}
+	cache := newVariableCache(testVariableConfig(), station.fetch, zap.NewNop())
+	ctx := context.Background()
+
+	// Act
+	var wg sync.WaitGroup
+	results := make([][]GetVariableResult, 2)
+	for i, name := range []string{"FirmwareVersion", "Power"} {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			res, err := cache.get(ctx, "CP-1", []GetVariableData{variable("ChargingStation", name)}, false)
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			results[i] = res
+		}(i, name)
+	}
+	wg.Wait()
+
+	// Assert
+	if calls := atomic.LoadInt32(&station.calls); calls != 1 {
+		t.Fatalf("expected one station call for both reads, got %d", calls)
+	}
+	if len(station.asked[0]) != 2 {
+		t.Errorf("expected the call to carry both variables, got %+v", station.asked[0])
+	}
+	if results[0][0].AttributeValue != "1.2.3" || results[1][0].AttributeValue != "22000" {
+		t.Errorf("expected each reader to get its own variable, got %+v and %+v", results[0], results[1])
+	}
+}
+
+func TestServer_SetVariablesInvalidatesCache(t *testing.T) {
+	// Arrange
+	srv := NewServer(nil, nil, zap.NewNop())
+	defer srv.Stop()
+	httpSrv := httptest.NewServer(http.HandlerFunc(srv.handleConnection))
+	defer httpSrv.Close()
+
+	var getCalls int32
+	power := "22000"
+	dialer := websocket.Dialer{Subprotocols: []string{"ocpp2.0.1"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(httpSrv.URL, "http")+"/ocpp/CP-VARS", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var raw []json.RawMessage
+			json.Unmarshal(data, &raw)
+			var msgID, action string
+			json.Unmarshal(raw[1], &msgID)
+			json.Unmarshal(raw[2], &action)
+
+			var payload interface{}
+			switch action {
+			case "GetVariables":
+				atomic.AddInt32(&getCalls, 1)
+				var req GetVariablesRequest
+				json.Unmarshal(raw[3], &req)
+				resp := GetVariablesResponse{}
+				for _, v := range req.GetVariableData {
+					value := power
+					resp.GetVariableResult = append(resp.GetVariableResult, GetVariableResult{
+						AttributeStatus: "Accepted", Component: v.Component, Variable: v.Variable, AttributeValue: value,
+					})
+				}
+				payload = resp
+			case "SetVariables":
+				var req SetVariablesRequest
+				json.Unmarshal(raw[3], &req)
+				resp := SetVariablesResponse{}
+				for _, v := range req.SetVariableData {
+					power = v.AttributeValue
+					resp.SetVariableResult = append(resp.SetVariableResult, SetVariableResult{
+						AttributeStatus: "Accepted", Component: v.Component, Variable: v.Variable,
+					})
+				}
+				payload = resp
+			}
+			out, _ := json.Marshal([]interface{}{CallResult, msgID, payload})
+			conn.WriteMessage(websocket.TextMessage, out)
+		}
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !srv.IsConnected("CP-VARS") {
+		if time.Now().After(deadline) {
+			t.Fatal("charge point did not register")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	vars := []GetVariableData{variable("EVSE", "Power")}
+	srv.ReadVariables(ctx, "CP-VARS", vars, false)
+	srv.ReadVariables(ctx, "CP-VARS", vars, false)
+
+	// Act
+	_, err = srv.SetVariables(ctx, "CP-VARS", []SetVariableData{{
+		Component: Component{Name: "EVSE"}, Variable: Variable{Name: "Power"}, AttributeValue: "11000",
+	}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	results, err := srv.ReadVariables(ctx, "CP-VARS", vars, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := atomic.LoadInt32(&getCalls); got != 2 {
+		t.Errorf("expected one cached read and one read after SetVariables, got %d station calls", got)
+	}
+	if results[0].AttributeValue != "11000" {
+		t.Errorf("expected updated value 11000, got %s", results[0].AttributeValue)
+	}
+}
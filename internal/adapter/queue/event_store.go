@@ -0,0 +1,191 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// DefaultEventSubjects are the subject prefixes of domain events kept in the
+// event log
+var DefaultEventSubjects = []string{"transaction.", "v2g.", "payment.", "billing."}
+
+// maxAppendAttempts bounds the retries of an append whose sequence number
+// another server took first
+const maxAppendAttempts = 5
+
+// aggregateIDKeys are the payload fields identifying the aggregate of an
+// event, in order of preference
+var aggregateIDKeys = []string{"aggregate_id", "transaction_id", "session_id", "payment_id", "invoice_id", "id"}
+
+// EventStore is a MessageQueue that appends domain events to a durable log
+// before publishing them. Events are numbered with a monotonic sequence and
+// published in sequence order, so the log can be replayed to rebuild state
+// after a crash. Servers sharing the log each take the next number free in
+// it. Subjects outside the configured prefixes are published without being
+// stored.
+type EventStore struct {
+	queue    MessageQueue // may be nil: events are then only stored
+	repo     ports.EventRepository
+	subjects []string
+	log      *zap.Logger
+
+	mu      sync.Mutex // serializes append+publish so sequence order is publish order
+	lastSeq int64
+	loaded  bool
+}
+
+// NewEventStore wraps queue so that domain events are persisted to repo
+func NewEventStore(queue MessageQueue, repo ports.EventRepository, log *zap.Logger) *EventStore {
+	return &EventStore{
+		queue:    queue,
+		repo:     repo,
+		subjects: DefaultEventSubjects,
+		log:      log,
+	}
+}
+
+// Publish persists a domain event and then publishes it. If the event cannot
+// be persisted it is not published.
+func (s *EventStore) Publish(subject string, data []byte) error {
+	if !s.stored(subject) {
+		return s.publish(subject, data)
+	}
+
+	ctx := context.Background()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event, err := s.append(ctx, subject, data)
+	if err != nil {
+		return err
+	}
+
+	if err := s.publish(subject, data); err != nil {
+		// The event is in the log; consumers can catch up with ReadEvents
+		s.log.Warn("Stored event could not be published",
+			zap.String("subject", subject),
+			zap.Int64("seq", event.Seq),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// append stores the event under the next sequence number. Other servers
+// append to the same log, so when one of them took the number first the
+// sequence is reloaded from the log and the append retried.
+func (s *EventStore) append(ctx context.Context, subject string, data []byte) (*domain.Event, error) {
+	for attempt := 1; ; attempt++ {
+		if !s.loaded {
+			last, err := s.repo.LastSeq(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("event store: load sequence: %w", err)
+			}
+			s.lastSeq = last
+			s.loaded = true
+		}
+
+		event := &domain.Event{
+			Seq:         s.lastSeq + 1,
+			AggregateID: aggregateID(data),
+			Subject:     subject,
+			Payload:     payload(data),
+			CreatedAt:   time.Now(),
+		}
+		err := s.repo.Append(ctx, event)
+		if err == nil {
+			s.lastSeq = event.Seq
+			return event, nil
+		}
+		s.loaded = false
+		if !errors.Is(err, domain.ErrConflict) || attempt == maxAppendAttempts {
+			return nil, fmt.Errorf("event store: append %s: %w", subject, err)
+		}
+	}
+}
+
+// ReadEvents returns the events of an aggregate after sinceSeq in sequence
+// order. Pass the Seq of the last event seen to resume reading.
+func (s *EventStore) ReadEvents(ctx context.Context, aggregateID string, sinceSeq int64) ([]domain.Event, error) {
+	events, err := s.repo.FindByAggregateID(ctx, aggregateID, sinceSeq)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+	return events, nil
+}
+
+func (s *EventStore) Subscribe(subject string, handler func(data []byte) error) error {
+	if s.queue == nil {
+		return fmt.Errorf("event store: no message queue to subscribe to %s", subject)
+	}
+	return s.queue.Subscribe(subject, handler)
+}
+
+func (s *EventStore) QueueSubscribe(subject, group string, handler func(data []byte) error) error {
+	if s.queue == nil {
+		return fmt.Errorf("event store: no message queue to subscribe to %s", subject)
+	}
+	return s.queue.QueueSubscribe(subject, group, handler)
+}
+
+func (s *EventStore) Close() error {
+	if s.queue == nil {
+		return nil
+	}
+	return s.queue.Close()
+}
+
+func (s *EventStore) publish(subject string, data []byte) error {
+	if s.queue == nil {
+		return nil
+	}
+	return s.queue.Publish(subject, data)
+}
+
+// stored reports whether events on subject go to the event log
+func (s *EventStore) stored(subject string) bool {
+	for _, prefix := range s.subjects {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregateID extracts the aggregate identifier from a JSON event payload
+func aggregateID(data []byte) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	for _, key := range aggregateIDKeys {
+		if id, ok := fields[key].(string); ok && id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// payload keeps JSON payloads as they are and wraps anything else as a JSON
+// string so the event remains valid JSON
+func payload(data []byte) json.RawMessage {
+	if json.Valid(data) {
+		return append(json.RawMessage(nil), data...)
+	}
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
@@ -0,0 +1,253 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// memoryEventLog is an in-memory event repository
+type memoryEventLog struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (l *memoryEventLog) repository() *mocks.MockEventRepository {
+	return &mocks.MockEventRepository{
+		AppendFunc: func(ctx context.Context, event *domain.Event) error {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for _, e := range l.events {
+				if e.Seq == event.Seq {
+					return domain.ErrConflict
+				}
+			}
+			l.events = append(l.events, *event)
+			return nil
+		},
+		FindByAggregateIDFunc: func(ctx context.Context, aggregateID string, sinceSeq int64) ([]domain.Event, error) {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			var events []domain.Event
+			// Newest first, to check that the store orders them
+			for i := len(l.events) - 1; i >= 0; i-- {
+				if e := l.events[i]; e.AggregateID == aggregateID && e.Seq > sinceSeq {
+					events = append(events, e)
+				}
+			}
+			return events, nil
+		},
+		LastSeqFunc: func(ctx context.Context) (int64, error) {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			var last int64
+			for _, e := range l.events {
+				if e.Seq > last {
+					last = e.Seq
+				}
+			}
+			return last, nil
+		},
+	}
+}
+
+// publishLog records the order in which messages reach the queue
+type publishLog struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (p *publishLog) queue() *mocks.MockMessageQueue {
+	mq := mocks.NewMockMessageQueue()
+	mq.PublishFunc = func(topic string, data []byte) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.messages = append(p.messages, string(data))
+		return nil
+	}
+	return mq
+}
+
+func event(txID string, n int) []byte {
+	data, _ := json.Marshal(map[string]interface{}{"transaction_id": txID, "n": n})
+	return data
+}
+
+func TestEventStore_PublishOrderMatchesSequence(t *testing.T) {
+	// Arrange
+	log := &memoryEventLog{}
+	published := &publishLog{}
+	store := NewEventStore(published.queue(), log.repository(), zap.NewNop())
+
+	// Act: concurrent publishers
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				if err := store.Publish("transaction.started", event(fmt.Sprintf("tx-%d", w), i)); err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Assert
+	if len(log.events) != 200 || len(published.messages) != 200 {
+		t.Fatalf("expected 200 stored and published events, got %d and %d", len(log.events), len(published.messages))
+	}
+	for i, e := range log.events {
+		if e.Seq != int64(i+1) {
+			t.Fatalf("expected gapless sequence, got seq %d at position %d", e.Seq, i)
+		}
+		if string(e.Payload) != published.messages[i] {
+			t.Fatalf("seq %d: stored %s but published %s", e.Seq, e.Payload, published.messages[i])
+		}
+	}
+}
+
+func TestEventStore_ReaderResumesFromSequence(t *testing.T) {
+	// Arrange
+	log := &memoryEventLog{}
+	store := NewEventStore(nil, log.repository(), zap.NewNop())
+	ctx := context.Background()
+
+	store.Publish("transaction.started", event("tx-1", 0))
+	store.Publish("transaction.started", event("tx-2", 0))
+	store.Publish("billing.events", event("tx-1", 1))
+	store.Publish("transaction.completed", event("tx-1", 2))
+
+	// Act
+	all, err := store.ReadEvents(ctx, "tx-1", 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	resumed, _ := store.ReadEvents(ctx, "tx-1", all[0].Seq)
+
+	// Assert
+	var seqs []int64
+	for _, e := range all {
+		seqs = append(seqs, e.Seq)
+	}
+	if fmt.Sprint(seqs) != "[1 3 4]" {
+		t.Errorf("expected tx-1 events [1 3 4], got %v", seqs)
+	}
+	if len(resumed) != 2 || resumed[0].Seq != 3 || resumed[1].Subject != "transaction.completed" {
+		t.Errorf("expected to resume at seq 3, got %+v", resumed)
+	}
+}
+
+func TestEventStore_ContinuesSequenceAfterRestart(t *testing.T) {
+	// Arrange
+	log := &memoryEventLog{}
+	NewEventStore(nil, log.repository(), zap.NewNop()).Publish("v2g.session.started", event("s-1", 0))
+
+	// Act
+	restarted := NewEventStore(nil, log.repository(), zap.NewNop())
+	restarted.Publish("v2g.session.completed", event("s-1", 1))
+
+	// Assert
+	if got := log.events[1].Seq; got != 2 {
+		t.Errorf("expected sequence to continue at 2, got %d", got)
+	}
+}
+
+func TestEventStore_SharedLogAcrossServers(t *testing.T) {
+	// Arrange: two servers appending to one log
+	log := &memoryEventLog{}
+	published := &publishLog{}
+	first := NewEventStore(published.queue(), log.repository(), zap.NewNop())
+	second := NewEventStore(published.queue(), log.repository(), zap.NewNop())
+
+	// Act: the first server's cached sequence goes stale
+	errs := []error{
+		first.Publish("transaction.started", event("tx-1", 0)),
+		second.Publish("transaction.started", event("tx-2", 0)),
+		first.Publish("transaction.completed", event("tx-1", 1)),
+		second.Publish("transaction.completed", event("tx-2", 1)),
+	}
+
+	// Assert
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("publish %d: expected no error, got %v", i+1, err)
+		}
+	}
+	var seqs []int64
+	for _, e := range log.events {
+		seqs = append(seqs, e.Seq)
+	}
+	if fmt.Sprint(seqs) != "[1 2 3 4]" {
+		t.Errorf("expected sequence [1 2 3 4], got %v", seqs)
+	}
+	if len(published.messages) != 4 {
+		t.Errorf("expected all 4 events published, got %d", len(published.messages))
+	}
+}
+
+func TestEventStore_DoesNotPublishUnstoredEvents(t *testing.T) {
+	// Arrange
+	published := &publishLog{}
+	repo := &mocks.MockEventRepository{
+		AppendFunc: func(ctx context.Context, event *domain.Event) error {
+			return errors.New("database unavailable")
+		},
+	}
+	store := NewEventStore(published.queue(), repo, zap.NewNop())
+
+	// Act
+	err := store.Publish("payment.succeeded", event("tx-1", 0))
+
+	// Assert
+	if err == nil {
+		t.Error("expected error when the event cannot be stored")
+	}
+	if len(published.messages) != 0 {
+		t.Errorf("expected nothing published, got %v", published.messages)
+	}
+}
+
+func TestEventStore_PassesThroughOtherSubjects(t *testing.T) {
+	// Arrange
+	log := &memoryEventLog{}
+	published := &publishLog{}
+	store := NewEventStore(published.queue(), log.repository(), zap.NewNop())
+
+	// Act
+	store.Publish("device.status.changed", []byte(`{"id":"CP-1"}`))
+
+	// Assert
+	if len(log.events) != 0 {
+		t.Errorf("expected device events not to be stored, got %d", len(log.events))
+	}
+	if len(published.messages) != 1 {
+		t.Errorf("expected the event to be published, got %d", len(published.messages))
+	}
+}
+
+func TestAggregateID(t *testing.T) {
+	tests := []struct {
+		data, want string
+	}{
+		{`{"transaction_id":"tx-1","user_id":"u-1"}`, "tx-1"},
+		{`{"session_id":"s-1"}`, "s-1"},
+		{`{"aggregate_id":"a-1","transaction_id":"tx-1"}`, "a-1"},
+		{`not json`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := aggregateID([]byte(tt.data)); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.data, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Ensure JSONQueue implements ports.MessageQueue
+var _ ports.MessageQueue = (*JSONQueue)(nil)
+
+// JSONQueue exposes a MessageQueue to services written against
+// ports.MessageQueue, encoding the messages they publish as JSON
+type JSONQueue struct {
+	queue MessageQueue
+}
+
+// NewJSONQueue wraps queue
+func NewJSONQueue(queue MessageQueue) *JSONQueue {
+	return &JSONQueue{queue: queue}
+}
+
+// Publish encodes message as JSON and publishes it on topic
+func (q *JSONQueue) Publish(topic string, message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message for %s: %w", topic, err)
+	}
+	return q.queue.Publish(topic, data)
+}
+
+// Subscribe calls handler with every message published on topic
+func (q *JSONQueue) Subscribe(topic string, handler func(message []byte)) error {
+	return q.queue.Subscribe(topic, func(data []byte) error {
+		handler(data)
+		return nil
+	})
+}
+
+func (q *JSONQueue) Close() error {
+	return q.queue.Close()
+}
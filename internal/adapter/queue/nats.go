@@ -38,6 +38,15 @@ func (q *NATSQueue) Subscribe(subject string, handler func(data []byte) error) e
 	return err
 }
 
+func (q *NATSQueue) QueueSubscribe(subject, group string, handler func(data []byte) error) error {
+	_, err := q.conn.QueueSubscribe(subject, group, func(msg *nats.Msg) {
+		if err := handler(msg.Data); err != nil {
+			q.log.Error("Error processing message", zap.String("subject", subject), zap.String("group", group), zap.Error(err))
+		}
+	})
+	return err
+}
+
 func (q *NATSQueue) Close() error {
 	q.conn.Close()
 	return nil
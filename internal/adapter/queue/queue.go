@@ -4,5 +4,8 @@ package queue
 type MessageQueue interface {
 	Publish(subject string, data []byte) error
 	Subscribe(subject string, handler func(data []byte) error) error
+	// QueueSubscribe delivers each message to only one of the subscribers
+	// sharing group, e.g. one replica of a worker
+	QueueSubscribe(subject, group string, handler func(data []byte) error) error
 	Close() error
 }
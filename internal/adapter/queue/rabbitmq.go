@@ -73,6 +73,18 @@ func (q *RabbitMQQueue) Publish(subject string, data []byte) error {
 }
 
 func (q *RabbitMQQueue) Subscribe(subject string, handler func(data []byte) error) error {
+	return q.consume(subject, "", handler)
+}
+
+// QueueSubscribe consumes from a durable queue named after subject and
+// group, which every subscriber of the group shares
+func (q *RabbitMQQueue) QueueSubscribe(subject, group string, handler func(data []byte) error) error {
+	return q.consume(subject, subject+"."+group, handler)
+}
+
+// consume binds a queue to the subject's exchange and handles its messages.
+// An empty name declares a private queue deleted with the subscriber.
+func (q *RabbitMQQueue) consume(subject, name string, handler func(data []byte) error) error {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
 
@@ -85,7 +97,8 @@ func (q *RabbitMQQueue) Subscribe(subject string, handler func(data []byte) erro
 		return fmt.Errorf("rabbitmq: declare exchange: %w", err)
 	}
 
-	queue, err := q.channel.QueueDeclare("", false, true, true, false, nil)
+	shared := name != ""
+	queue, err := q.channel.QueueDeclare(name, shared, !shared, !shared, false, nil)
 	if err != nil {
 		return fmt.Errorf("rabbitmq: declare queue: %w", err)
 	}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// AlertRepository implements alert persistence in memory
+type AlertRepository struct {
+	mu     sync.RWMutex
+	alerts map[string]ports.Alert
+}
+
+// NewAlertRepository creates an empty alert repository
+func NewAlertRepository() ports.AlertRepository {
+	return &AlertRepository{
+		alerts: make(map[string]ports.Alert),
+	}
+}
+
+// Save creates or fully overwrites an alert
+func (r *AlertRepository) Save(ctx context.Context, alert *ports.Alert) error {
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts[alert.ID] = *alert
+	return nil
+}
+
+// GetByID returns an alert, or nil when it does not exist
+func (r *AlertRepository) GetByID(ctx context.Context, id string) (*ports.Alert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alert, ok := r.alerts[id]
+	if !ok {
+		return nil, nil
+	}
+	return &alert, nil
+}
+
+// GetAll returns the alerts with the given acknowledgement, newest first
+func (r *AlertRepository) GetAll(ctx context.Context, acknowledged bool, limit, offset int) ([]ports.Alert, error) {
+	alerts := r.find(func(a *ports.Alert) bool { return a.Acknowledged == acknowledged })
+	return paginate(alerts, limit, offset), nil
+}
+
+// Acknowledge marks an alert as seen by an operator
+func (r *AlertRepository) Acknowledge(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if alert, ok := r.alerts[id]; ok {
+		alert.Acknowledged = true
+		r.alerts[id] = alert
+	}
+	return nil
+}
+
+// Delete removes an alert
+func (r *AlertRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.alerts, id)
+	return nil
+}
+
+// CountUnacknowledged counts the alerts no operator has seen
+func (r *AlertRepository) CountUnacknowledged(ctx context.Context) (int, error) {
+	return len(r.find(func(a *ports.Alert) bool { return !a.Acknowledged })), nil
+}
+
+// FindOpen returns the unresolved alerts of alertType raised by sourceID
+func (r *AlertRepository) FindOpen(ctx context.Context, alertType, sourceID string) ([]ports.Alert, error) {
+	return r.find(func(a *ports.Alert) bool {
+		return a.Type == alertType && a.SourceID == sourceID && !a.Resolved
+	}), nil
+}
+
+// Resolve marks an alert as resolved because its condition cleared
+func (r *AlertRepository) Resolve(ctx context.Context, id string, resolvedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if alert, ok := r.alerts[id]; ok {
+		alert.Resolved = true
+		alert.ResolvedAt = &resolvedAt
+		r.alerts[id] = alert
+	}
+	return nil
+}
+
+// find returns the matching alerts, newest first
+func (r *AlertRepository) find(match func(a *ports.Alert) bool) []ports.Alert {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var alerts []ports.Alert
+	for _, a := range r.alerts {
+		if match(&a) {
+			alerts = append(alerts, a)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].CreatedAt.After(alerts[j].CreatedAt) })
+	return alerts
+}
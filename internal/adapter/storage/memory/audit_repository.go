@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// AuditRepository implements the audit log in memory
+type AuditRepository struct {
+	mu      sync.RWMutex
+	entries []domain.AuditEntry
+}
+
+// NewAuditRepository creates an empty audit log
+func NewAuditRepository() ports.AuditRepository {
+	return &AuditRepository{}
+}
+
+// Save appends an entry
+func (r *AuditRepository) Save(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+// Find returns the entries matching filter, newest first, and the total
+func (r *AuditRepository) Find(ctx context.Context, filter ports.AuditFilter, limit, offset int) ([]domain.AuditEntry, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []domain.AuditEntry
+	for _, entry := range r.entries {
+		if (filter.ActorID == "" || entry.ActorID == filter.ActorID) &&
+			(filter.Action == "" || entry.Action == filter.Action) &&
+			(filter.EntityType == "" || entry.EntityType == filter.EntityType) &&
+			(filter.EntityID == "" || entry.EntityID == filter.EntityID) &&
+			(filter.From.IsZero() || !entry.CreatedAt.Before(filter.From)) &&
+			(filter.To.IsZero() || entry.CreatedAt.Before(filter.To)) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	return paginate(entries, limit, offset), len(entries), nil
+}
@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// nearbyLimit caps FindNearby like the SQL backends do
+const nearbyLimit = 50
+
+// ChargePointRepository implements charge point persistence in memory
+type ChargePointRepository struct {
+	mu           sync.RWMutex
+	chargePoints map[string]domain.ChargePoint
+}
+
+// NewChargePointRepository creates an empty charge point repository
+func NewChargePointRepository() ports.ChargePointRepository {
+	return &ChargePointRepository{
+		chargePoints: make(map[string]domain.ChargePoint),
+	}
+}
+
+// Save creates or fully overwrites a charge point
+func (r *ChargePointRepository) Save(ctx context.Context, cp *domain.ChargePoint) error {
+	now := time.Now()
+	if cp.CreatedAt.IsZero() {
+		cp.CreatedAt = now
+	}
+	cp.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chargePoints[cp.ID] = cloneChargePoint(*cp)
+	return nil
+}
+
+// FindByID returns a charge point, or nil when it does not exist
+func (r *ChargePointRepository) FindByID(ctx context.Context, id string) (*domain.ChargePoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cp, ok := r.chargePoints[id]
+	if !ok {
+		return nil, nil
+	}
+	cp = cloneChargePoint(cp)
+	return &cp, nil
+}
+
+// FindAll returns the charge points, filtered by "status" when set
+func (r *ChargePointRepository) FindAll(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	connectors, _ := filter[ports.ConnectorFilterKey].(domain.ConnectorFilter)
+
+	var cps []domain.ChargePoint
+	for _, cp := range r.chargePoints {
+		if status, ok := filter["status"]; ok && fmt.Sprint(status) != string(cp.Status) {
+			continue
+		}
+		if !connectors.Matches(&cp) {
+			continue
+		}
+		cps = append(cps, cloneChargePoint(cp))
+	}
+	sort.Slice(cps, func(i, j int) bool { return cps[i].ID < cps[j].ID })
+	return cps, nil
+}
+
+// UpdateStatus sets the status of a charge point
+func (r *ChargePointRepository) UpdateStatus(ctx context.Context, id string, status domain.ChargePointStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cp, ok := r.chargePoints[id]; ok {
+		cp.Status = status
+		r.chargePoints[id] = cp
+	}
+	return nil
+}
+
+// UpdateConnectorStatus sets the status of one connector, creating the
+// connector the first time a charge point reports it
+func (r *ChargePointRepository) UpdateConnectorStatus(ctx context.Context, chargePointID string, connectorID int, status domain.ChargePointStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cp, ok := r.chargePoints[chargePointID]
+	if !ok {
+		return fmt.Errorf("charge point not found: %s", chargePointID)
+	}
+	for i := range cp.Connectors {
+		if cp.Connectors[i].ConnectorID == connectorID {
+			cp.Connectors[i].Status = status
+			r.chargePoints[chargePointID] = cp
+			return nil
+		}
+	}
+	cp.Connectors = append(cp.Connectors, domain.Connector{
+		ID:            uuid.New().String(),
+		ChargePointID: chargePointID,
+		ConnectorID:   connectorID,
+		Type:          "Type2",
+		Status:        status,
+	})
+	r.chargePoints[chargePointID] = cp
+	return nil
+}
+
+// CompareAndSetConnectorStatus sets the connector's status to to if it is from
+func (r *ChargePointRepository) CompareAndSetConnectorStatus(ctx context.Context, chargePointID string, connectorID int, from, to domain.ChargePointStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cp, ok := r.chargePoints[chargePointID]
+	if !ok {
+		return false, fmt.Errorf("charge point not found: %s", chargePointID)
+	}
+	for i := range cp.Connectors {
+		if cp.Connectors[i].ConnectorID != connectorID {
+			continue
+		}
+		if cp.Connectors[i].Status != from {
+			return false, nil
+		}
+		cp.Connectors[i].Status = to
+		r.chargePoints[chargePointID] = cp
+		return true, nil
+	}
+
+	if len(cp.Connectors) > 0 || cp.Status != from {
+		return false, nil
+	}
+	cp.Connectors = append(cp.Connectors, domain.Connector{
+		ID:            uuid.New().String(),
+		ChargePointID: chargePointID,
+		ConnectorID:   connectorID,
+		Type:          "Type2",
+		Status:        to,
+	})
+	r.chargePoints[chargePointID] = cp
+	return true, nil
+}
+
+// FindNearby returns the charge points within radius km, nearest first
+func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type nearby struct {
+		cp       domain.ChargePoint
+		distance float64
+	}
+	var found []nearby
+	for _, cp := range r.chargePoints {
+		if cp.Location == nil || !connectors.Matches(&cp) {
+			continue
+		}
+		distance := distanceKM(lat, lon, cp.Location.Latitude, cp.Location.Longitude)
+		if distance <= radius {
+			found = append(found, nearby{cloneChargePoint(cp), distance})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].distance < found[j].distance })
+
+	cps := make([]domain.ChargePoint, 0, len(found))
+	for _, n := range paginate(found, nearbyLimit, 0) {
+		cps = append(cps, n.cp)
+	}
+	return cps, nil
+}
+
+// UpdateHeartbeatInterval stores the heartbeat interval negotiated at boot
+func (r *ChargePointRepository) UpdateHeartbeatInterval(ctx context.Context, id string, interval int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cp, ok := r.chargePoints[id]; ok {
+		cp.HeartbeatInterval = interval
+		r.chargePoints[id] = cp
+	}
+	return nil
+}
+
+// UpdateBootInfo stores the identity reported at boot and returns the
+// firmware version it replaced
+func (r *ChargePointRepository) UpdateBootInfo(ctx context.Context, id string, info domain.BootInfo) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cp, ok := r.chargePoints[id]
+	if !ok {
+		return "", domain.ErrDeviceNotFound
+	}
+	previous := cp.FirmwareVersion
+	for _, field := range []struct {
+		stored   *string
+		reported string
+	}{
+		{&cp.Vendor, info.Vendor},
+		{&cp.Model, info.Model},
+		{&cp.SerialNumber, info.SerialNumber},
+		{&cp.FirmwareVersion, info.FirmwareVersion},
+	} {
+		if field.reported != "" {
+			*field.stored = field.reported
+		}
+	}
+	r.chargePoints[id] = cp
+	return previous, nil
+}
+
+// cloneChargePoint copies the connectors and location so callers never share
+// them with the stored charge point
+func cloneChargePoint(cp domain.ChargePoint) domain.ChargePoint {
+	cp.Connectors = append([]domain.Connector(nil), cp.Connectors...)
+	if cp.Location != nil {
+		loc := *cp.Location
+		cp.Location = &loc
+	}
+	return cp
+}
+
+// distanceKM is the great-circle (haversine) distance between two points
+func distanceKM(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKM = 6371.0
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*
+			math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKM * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ChargingProfileReportRepository implements reported charging profile persistence in memory
+type ChargingProfileReportRepository struct {
+	mu      sync.RWMutex
+	reports map[string]domain.ChargingProfileReport
+}
+
+// NewChargingProfileReportRepository creates an empty charging profile report repository
+func NewChargingProfileReportRepository() ports.ChargingProfileReportRepository {
+	return &ChargingProfileReportRepository{
+		reports: make(map[string]domain.ChargingProfileReport),
+	}
+}
+
+// Save replaces the reported charging profiles of a charge point
+func (r *ChargingProfileReportRepository) Save(ctx context.Context, report *domain.ChargingProfileReport) error {
+	stored := *report
+	stored.Profiles = append([]domain.ReportedChargingProfile(nil), report.Profiles...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports[report.ChargePointID] = stored
+	return nil
+}
+
+// GetByChargePointID returns the reported charging profiles of a charge point, or nil
+func (r *ChargingProfileReportRepository) GetByChargePointID(ctx context.Context, chargePointID string) (*domain.ChargingProfileReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report, ok := r.reports[chargePointID]
+	if !ok {
+		return nil, nil
+	}
+	report.Profiles = append([]domain.ReportedChargingProfile(nil), report.Profiles...)
+	return &report, nil
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ConnectionEventRepository implements connection history persistence in memory
+type ConnectionEventRepository struct {
+	mu     sync.RWMutex
+	events map[string][]domain.ConnectionEvent // charge point ID → events, oldest first
+}
+
+// NewConnectionEventRepository creates an empty connection event repository
+func NewConnectionEventRepository() ports.ConnectionEventRepository {
+	return &ConnectionEventRepository{
+		events: make(map[string][]domain.ConnectionEvent),
+	}
+}
+
+// Save appends an event to its charge point's history
+func (r *ConnectionEventRepository) Save(ctx context.Context, event *domain.ConnectionEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := append(r.events[event.ChargePointID], *event)
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Timestamp.Equal(events[j].Timestamp) {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		}
+		// A reconnect's disconnect comes first
+		return events[i].Type == domain.ConnectionEventDisconnected && events[j].Type == domain.ConnectionEventConnected
+	})
+	r.events[event.ChargePointID] = events
+	return nil
+}
+
+// FindByChargePointID returns a charge point's events in [from, to), oldest first
+func (r *ConnectionEventRepository) FindByChargePointID(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.ConnectionEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []domain.ConnectionEvent
+	for _, event := range r.events[chargePointID] {
+		if !event.Timestamp.Before(from) && event.Timestamp.Before(to) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// FindLastBefore returns a charge point's last event before t, or nil
+func (r *ConnectionEventRepository) FindLastBefore(ctx context.Context, chargePointID string, t time.Time) (*domain.ConnectionEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := r.events[chargePointID]
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Timestamp.Before(t) {
+			event := events[i]
+			return &event, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// EventRepository implements the domain event log in memory
+type EventRepository struct {
+	mu     sync.RWMutex
+	events []domain.Event // In append order, which is Seq order
+}
+
+// NewEventRepository creates an empty event log
+func NewEventRepository() ports.EventRepository {
+	return &EventRepository{}
+}
+
+// Append adds an event to the log
+func (r *EventRepository) Append(ctx context.Context, event *domain.Event) error {
+	stored := *event
+	stored.Payload = append([]byte(nil), event.Payload...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.events {
+		if existing.Seq == event.Seq {
+			return domain.ErrConflict
+		}
+	}
+	r.events = append(r.events, stored)
+	return nil
+}
+
+// FindByAggregateID returns the aggregate's events with Seq > sinceSeq, oldest first
+func (r *EventRepository) FindByAggregateID(ctx context.Context, aggregateID string, sinceSeq int64) ([]domain.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []domain.Event
+	for _, event := range r.events {
+		if event.AggregateID == aggregateID && event.Seq > sinceSeq {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// LastSeq returns the highest stored Seq, 0 when the log is empty
+func (r *EventRepository) LastSeq(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var last int64
+	for _, event := range r.events {
+		if event.Seq > last {
+			last = event.Seq
+		}
+	}
+	return last, nil
+}
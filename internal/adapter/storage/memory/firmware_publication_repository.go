@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// FirmwarePublicationRepository implements PublishFirmware request persistence in memory
+type FirmwarePublicationRepository struct {
+	mu           sync.RWMutex
+	publications map[string]domain.FirmwarePublication
+}
+
+// NewFirmwarePublicationRepository creates an empty firmware publication repository
+func NewFirmwarePublicationRepository() ports.FirmwarePublicationRepository {
+	return &FirmwarePublicationRepository{
+		publications: make(map[string]domain.FirmwarePublication),
+	}
+}
+
+// Save stores a new firmware publication
+func (r *FirmwarePublicationRepository) Save(ctx context.Context, pub *domain.FirmwarePublication) error {
+	now := time.Now()
+	if pub.CreatedAt.IsZero() {
+		pub.CreatedAt = now
+	}
+	pub.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.publications[pub.ID] = *pub
+	return nil
+}
+
+// Update stores the status of a firmware publication
+func (r *FirmwarePublicationRepository) Update(ctx context.Context, pub *domain.FirmwarePublication) error {
+	return r.Save(ctx, pub)
+}
+
+// FindByRequestID returns the publication with an OCPP requestId, or nil
+func (r *FirmwarePublicationRepository) FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.FirmwarePublication, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, pub := range r.publications {
+		if pub.ChargePointID == chargePointID && pub.RequestID == requestID {
+			return &pub, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindByChargePointID returns a charge point's firmware publications, newest first
+func (r *FirmwarePublicationRepository) FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.FirmwarePublication, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pubs []domain.FirmwarePublication
+	for _, pub := range r.publications {
+		if pub.ChargePointID == chargePointID {
+			pubs = append(pubs, pub)
+		}
+	}
+	sort.Slice(pubs, func(i, j int) bool { return pubs[i].CreatedAt.After(pubs[j].CreatedAt) })
+	return paginate(pubs, limit, 0), nil
+}
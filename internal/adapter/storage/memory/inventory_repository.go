@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// DeviceInventoryRepository implements device inventory persistence in memory
+type DeviceInventoryRepository struct {
+	mu          sync.RWMutex
+	inventories map[string]domain.DeviceInventory
+}
+
+// NewDeviceInventoryRepository creates an empty device inventory repository
+func NewDeviceInventoryRepository() ports.DeviceInventoryRepository {
+	return &DeviceInventoryRepository{
+		inventories: make(map[string]domain.DeviceInventory),
+	}
+}
+
+// Save replaces the inventory of a charge point
+func (r *DeviceInventoryRepository) Save(ctx context.Context, inventory *domain.DeviceInventory) error {
+	stored := *inventory
+	stored.Variables = append([]domain.InventoryVariable(nil), inventory.Variables...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inventories[inventory.ChargePointID] = stored
+	return nil
+}
+
+// GetByChargePointID returns the inventory of a charge point, or nil
+func (r *DeviceInventoryRepository) GetByChargePointID(ctx context.Context, chargePointID string) (*domain.DeviceInventory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	inventory, ok := r.inventories[chargePointID]
+	if !ok {
+		return nil, nil
+	}
+	inventory.Variables = append([]domain.InventoryVariable(nil), inventory.Variables...)
+	return &inventory, nil
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// errCertificateNotFound matches the SQL backend, whose callers expect an
+// error rather than a nil certificate
+var errCertificateNotFound = errors.New("certificate not found")
+
+// ISO15118Repository implements contract certificate persistence in memory
+type ISO15118Repository struct {
+	mu    sync.RWMutex
+	certs map[string]domain.ISO15118Certificate
+}
+
+// NewISO15118Repository creates an empty contract certificate repository
+func NewISO15118Repository() ports.ISO15118Repository {
+	return &ISO15118Repository{
+		certs: make(map[string]domain.ISO15118Certificate),
+	}
+}
+
+// StoreCertificate stores a new certificate
+func (r *ISO15118Repository) StoreCertificate(ctx context.Context, cert *domain.ISO15118Certificate) error {
+	now := time.Now()
+	if cert.CreatedAt.IsZero() {
+		cert.CreatedAt = now
+	}
+	cert.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certs[cert.ID] = *cert
+	return nil
+}
+
+// UpdateCertificate overwrites a certificate
+func (r *ISO15118Repository) UpdateCertificate(ctx context.Context, cert *domain.ISO15118Certificate) error {
+	return r.StoreCertificate(ctx, cert)
+}
+
+// GetCertificateByEMAID returns the certificate of an EMAID
+func (r *ISO15118Repository) GetCertificateByEMAID(ctx context.Context, emaid string) (*domain.ISO15118Certificate, error) {
+	certs := r.find(func(c *domain.ISO15118Certificate) bool { return c.EMAID == emaid })
+	if len(certs) == 0 {
+		return nil, errCertificateNotFound
+	}
+	return certs[0], nil
+}
+
+// GetCertificateByContractID returns the certificate of a contract
+func (r *ISO15118Repository) GetCertificateByContractID(ctx context.Context, contractID string) (*domain.ISO15118Certificate, error) {
+	certs := r.find(func(c *domain.ISO15118Certificate) bool { return c.ContractID == contractID })
+	if len(certs) == 0 {
+		return nil, errCertificateNotFound
+	}
+	return certs[0], nil
+}
+
+// GetCertificateByVIN returns a vehicle's certificates, newest first
+func (r *ISO15118Repository) GetCertificateByVIN(ctx context.Context, vin string) ([]*domain.ISO15118Certificate, error) {
+	return r.find(func(c *domain.ISO15118Certificate) bool { return c.VehicleVIN == vin }), nil
+}
+
+// GetExpiringCertificates returns the unrevoked certificates expiring within
+// daysUntilExpiry, soonest first
+func (r *ISO15118Repository) GetExpiringCertificates(ctx context.Context, daysUntilExpiry int) ([]*domain.ISO15118Certificate, error) {
+	expiryDate := time.Now().AddDate(0, 0, daysUntilExpiry)
+	certs := r.find(func(c *domain.ISO15118Certificate) bool {
+		return !c.Revoked && !c.ValidTo.After(expiryDate)
+	})
+	sort.SliceStable(certs, func(i, j int) bool { return certs[i].ValidTo.Before(certs[j].ValidTo) })
+	return certs, nil
+}
+
+// GetV2GCapableCertificates returns the valid V2G-capable certificates
+func (r *ISO15118Repository) GetV2GCapableCertificates(ctx context.Context) ([]*domain.ISO15118Certificate, error) {
+	now := time.Now()
+	return r.find(func(c *domain.ISO15118Certificate) bool {
+		return c.V2GCapable && !c.Revoked && c.ValidTo.After(now)
+	}), nil
+}
+
+// find returns copies of the matching certificates, newest first
+func (r *ISO15118Repository) find(match func(c *domain.ISO15118Certificate) bool) []*domain.ISO15118Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var certs []*domain.ISO15118Certificate
+	for _, c := range r.certs {
+		if match(&c) {
+			cert := c
+			certs = append(certs, &cert)
+		}
+	}
+	sort.Slice(certs, func(i, j int) bool { return certs[i].CreatedAt.After(certs[j].CreatedAt) })
+	return certs
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// LogRequestRepository implements GetLog request persistence in memory
+type LogRequestRepository struct {
+	mu       sync.RWMutex
+	requests map[string]domain.LogRequest
+}
+
+// NewLogRequestRepository creates an empty log request repository
+func NewLogRequestRepository() ports.LogRequestRepository {
+	return &LogRequestRepository{
+		requests: make(map[string]domain.LogRequest),
+	}
+}
+
+// Save stores a new log request
+func (r *LogRequestRepository) Save(ctx context.Context, req *domain.LogRequest) error {
+	now := time.Now()
+	if req.CreatedAt.IsZero() {
+		req.CreatedAt = now
+	}
+	req.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests[req.ID] = *req
+	return nil
+}
+
+// Update stores the status of a log request
+func (r *LogRequestRepository) Update(ctx context.Context, req *domain.LogRequest) error {
+	return r.Save(ctx, req)
+}
+
+// FindByRequestID returns the request with an OCPP requestId, or nil
+func (r *LogRequestRepository) FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.LogRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, req := range r.requests {
+		if req.ChargePointID == chargePointID && req.RequestID == requestID {
+			return &req, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindByChargePointID returns a charge point's log requests, newest first
+func (r *LogRequestRepository) FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.LogRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var reqs []domain.LogRequest
+	for _, req := range r.requests {
+		if req.ChargePointID == chargePointID {
+			reqs = append(reqs, req)
+		}
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].CreatedAt.After(reqs[j].CreatedAt) })
+	return paginate(reqs, limit, 0), nil
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// MeterValueRepository implements meter sample persistence in memory
+type MeterValueRepository struct {
+	mu      sync.RWMutex
+	samples map[string][]domain.MeterSample // transaction ID → samples, oldest first
+}
+
+// NewMeterValueRepository creates an empty meter value repository
+func NewMeterValueRepository() ports.MeterValueRepository {
+	return &MeterValueRepository{
+		samples: make(map[string][]domain.MeterSample),
+	}
+}
+
+// Save appends samples to their transactions' series
+func (r *MeterValueRepository) Save(ctx context.Context, samples []domain.MeterSample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	touched := make(map[string]bool)
+	for _, sample := range samples {
+		r.samples[sample.TransactionID] = append(r.samples[sample.TransactionID], sample)
+		touched[sample.TransactionID] = true
+	}
+	for txID := range touched {
+		series := r.samples[txID]
+		sort.SliceStable(series, func(i, j int) bool { return series[i].Timestamp.Before(series[j].Timestamp) })
+	}
+	return nil
+}
+
+// FindByTransactionID returns the samples of a transaction, oldest first
+func (r *MeterValueRepository) FindByTransactionID(ctx context.Context, transactionID string) ([]domain.MeterSample, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]domain.MeterSample(nil), r.samples[transactionID]...), nil
+}
+
+// ReplaceByTransactionID replaces the samples of a transaction
+func (r *MeterValueRepository) ReplaceByTransactionID(ctx context.Context, transactionID string, samples []domain.MeterSample) error {
+	series := append([]domain.MeterSample(nil), samples...)
+	sort.SliceStable(series, func(i, j int) bool { return series[i].Timestamp.Before(series[j].Timestamp) })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(series) == 0 {
+		delete(r.samples, transactionID)
+		return nil
+	}
+	r.samples[transactionID] = series
+	return nil
+}
+
+// DeleteBefore removes every sample taken before t
+func (r *MeterValueRepository) DeleteBefore(ctx context.Context, t time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for txID, series := range r.samples {
+		kept := series[:0]
+		for _, sample := range series {
+			if sample.Timestamp.Before(t) {
+				deleted++
+				continue
+			}
+			kept = append(kept, sample)
+		}
+		if len(kept) == 0 {
+			delete(r.samples, txID)
+		} else {
+			r.samples[txID] = kept
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// NotificationPreferenceRepository implements notification preference persistence in memory
+type NotificationPreferenceRepository struct {
+	mu    sync.RWMutex
+	prefs map[string]domain.NotificationPreferences
+}
+
+// NewNotificationPreferenceRepository creates an empty notification preference repository
+func NewNotificationPreferenceRepository() ports.NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{
+		prefs: make(map[string]domain.NotificationPreferences),
+	}
+}
+
+// Save stores a user's preferences, replacing any earlier ones
+func (r *NotificationPreferenceRepository) Save(ctx context.Context, prefs *domain.NotificationPreferences) error {
+	prefs.UpdatedAt = time.Now()
+
+	stored := *prefs
+	stored.DisabledEvents = append([]string(nil), prefs.DisabledEvents...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefs[prefs.UserID] = stored
+	return nil
+}
+
+// FindByUserID returns nil when the user never set any preferences
+func (r *NotificationPreferenceRepository) FindByUserID(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs, ok := r.prefs[userID]
+	if !ok {
+		return nil, nil
+	}
+	prefs.DisabledEvents = append([]string(nil), prefs.DisabledEvents...)
+	return &prefs, nil
+}
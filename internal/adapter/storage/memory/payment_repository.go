@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// PaymentRepository implements payment and refund persistence in memory
+type PaymentRepository struct {
+	mu       sync.RWMutex
+	payments map[string]domain.Payment
+	refunds  map[string]domain.Refund
+	events   map[string]time.Time // claimed webhook events, by provider/event ID
+}
+
+// NewPaymentRepository creates an empty payment repository
+func NewPaymentRepository() ports.PaymentRepository {
+	return &PaymentRepository{
+		payments: make(map[string]domain.Payment),
+		refunds:  make(map[string]domain.Refund),
+		events:   make(map[string]time.Time),
+	}
+}
+
+// SavePayment creates or fully overwrites a payment
+func (r *PaymentRepository) SavePayment(ctx context.Context, payment *domain.Payment) error {
+	now := time.Now()
+	if payment.CreatedAt.IsZero() {
+		payment.CreatedAt = now
+	}
+	payment.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payments[payment.ID] = *payment
+	return nil
+}
+
+// GetPayment returns a payment, or nil when it does not exist
+func (r *PaymentRepository) GetPayment(ctx context.Context, id string) (*domain.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, nil
+	}
+	return &payment, nil
+}
+
+// GetPaymentByProviderID returns the payment with a provider reference, or nil
+func (r *PaymentRepository) GetPaymentByProviderID(ctx context.Context, providerID string) (*domain.Payment, error) {
+	payments := r.findPayments(func(p *domain.Payment) bool { return p.ProviderID == providerID })
+	if len(payments) == 0 {
+		return nil, nil
+	}
+	return &payments[0], nil
+}
+
+// GetPaymentsByUser returns a user's payments, newest first
+func (r *PaymentRepository) GetPaymentsByUser(ctx context.Context, userID string, limit, offset int) ([]domain.Payment, error) {
+	payments := r.findPayments(func(p *domain.Payment) bool { return p.UserID == userID })
+	return paginate(payments, limit, offset), nil
+}
+
+// GetPaymentsByTransaction returns the payments of a charging transaction
+func (r *PaymentRepository) GetPaymentsByTransaction(ctx context.Context, transactionID string) ([]domain.Payment, error) {
+	return r.findPayments(func(p *domain.Payment) bool { return p.TransactionID == transactionID }), nil
+}
+
+// SaveRefund creates or fully overwrites a refund
+func (r *PaymentRepository) SaveRefund(ctx context.Context, refund *domain.Refund) error {
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refunds[refund.ID] = *refund
+	return nil
+}
+
+// GetRefundsByPayment returns the refunds of a payment, oldest first
+func (r *PaymentRepository) GetRefundsByPayment(ctx context.Context, paymentID string) ([]domain.Refund, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var refunds []domain.Refund
+	for _, refund := range r.refunds {
+		if refund.PaymentID == paymentID {
+			refunds = append(refunds, refund)
+		}
+	}
+	sort.Slice(refunds, func(i, j int) bool { return refunds[i].CreatedAt.Before(refunds[j].CreatedAt) })
+	return refunds, nil
+}
+
+// ClaimWebhookEvent records a webhook event, returning false when it was
+// already claimed
+func (r *PaymentRepository) ClaimWebhookEvent(ctx context.Context, provider, eventID string) (bool, error) {
+	key := provider + "/" + eventID
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.events[key]; ok {
+		return false, nil
+	}
+	r.events[key] = time.Now()
+	return true, nil
+}
+
+// ReleaseWebhookEvent forgets a claimed webhook event
+func (r *PaymentRepository) ReleaseWebhookEvent(ctx context.Context, provider, eventID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.events, provider+"/"+eventID)
+	return nil
+}
+
+// findPayments returns the matching payments, newest first
+func (r *PaymentRepository) findPayments(match func(p *domain.Payment) bool) []domain.Payment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var payments []domain.Payment
+	for _, p := range r.payments {
+		if match(&p) {
+			payments = append(payments, p)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].CreatedAt.After(payments[j].CreatedAt) })
+	return payments
+}
+
+// CardRepository implements payment card persistence in memory
+type CardRepository struct {
+	mu    sync.RWMutex
+	cards map[string]domain.PaymentCard
+}
+
+// NewCardRepository creates an empty card repository
+func NewCardRepository() ports.CardRepository {
+	return &CardRepository{
+		cards: make(map[string]domain.PaymentCard),
+	}
+}
+
+// Save creates or fully overwrites a card
+func (r *CardRepository) Save(ctx context.Context, card *domain.PaymentCard) error {
+	if card.CreatedAt.IsZero() {
+		card.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cards[card.ID] = *card
+	return nil
+}
+
+// GetByID returns a card, or nil when it does not exist
+func (r *CardRepository) GetByID(ctx context.Context, id string) (*domain.PaymentCard, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	card, ok := r.cards[id]
+	if !ok {
+		return nil, nil
+	}
+	return &card, nil
+}
+
+// GetByUserID returns a user's cards, the default one first
+func (r *CardRepository) GetByUserID(ctx context.Context, userID string) ([]domain.PaymentCard, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var cards []domain.PaymentCard
+	for _, card := range r.cards {
+		if card.UserID == userID {
+			cards = append(cards, card)
+		}
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].IsDefault != cards[j].IsDefault {
+			return cards[i].IsDefault
+		}
+		return cards[i].CreatedAt.Before(cards[j].CreatedAt)
+	})
+	return cards, nil
+}
+
+// Delete removes a card
+func (r *CardRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cards, id)
+	return nil
+}
+
+// SetDefault makes cardID the user's only default card
+func (r *CardRepository) SetDefault(ctx context.Context, userID, cardID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, card := range r.cards {
+		if card.UserID != userID {
+			continue
+		}
+		card.IsDefault = id == cardID
+		r.cards[id] = card
+	}
+	return nil
+}
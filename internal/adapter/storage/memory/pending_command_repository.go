@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// PendingCommandRepository implements pending OCPP command persistence in memory
+type PendingCommandRepository struct {
+	mu       sync.RWMutex
+	commands map[string]domain.PendingCommand
+}
+
+// NewPendingCommandRepository creates an empty pending command repository
+func NewPendingCommandRepository() ports.PendingCommandRepository {
+	return &PendingCommandRepository{
+		commands: make(map[string]domain.PendingCommand),
+	}
+}
+
+// Save stores a command
+func (r *PendingCommandRepository) Save(ctx context.Context, cmd *domain.PendingCommand) error {
+	if cmd.CreatedAt.IsZero() {
+		cmd.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[cmd.MessageID] = *cmd
+	return nil
+}
+
+// Delete removes a command
+func (r *PendingCommandRepository) Delete(ctx context.Context, messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.commands, messageID)
+	return nil
+}
+
+// FindUnexpired returns the commands that expire after now, oldest first
+func (r *PendingCommandRepository) FindUnexpired(ctx context.Context, now time.Time) ([]domain.PendingCommand, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var commands []domain.PendingCommand
+	for _, cmd := range r.commands {
+		if cmd.ExpiresAt.After(now) {
+			commands = append(commands, cmd)
+		}
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].CreatedAt.Before(commands[j].CreatedAt) })
+	return commands, nil
+}
+
+// DeleteExpired removes the commands that expired at or before now
+func (r *PendingCommandRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, cmd := range r.commands {
+		if !cmd.ExpiresAt.After(now) {
+			delete(r.commands, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ReconciliationRepository implements reconciliation persistence in memory
+type ReconciliationRepository struct {
+	mu   sync.RWMutex
+	recs map[string]domain.Reconciliation // transactionID → record
+}
+
+// NewReconciliationRepository creates an empty reconciliation repository
+func NewReconciliationRepository() ports.ReconciliationRepository {
+	return &ReconciliationRepository{
+		recs: make(map[string]domain.Reconciliation),
+	}
+}
+
+// Save stores a reconciliation record
+func (r *ReconciliationRepository) Save(ctx context.Context, rec *domain.Reconciliation) error {
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recs[rec.TransactionID] = *rec
+	return nil
+}
+
+// FindByTransactionID returns the reconciliation of a transaction, or nil
+func (r *ReconciliationRepository) FindByTransactionID(ctx context.Context, transactionID string) (*domain.Reconciliation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, ok := r.recs[transactionID]
+	if !ok {
+		return nil, nil
+	}
+	return &rec, nil
+}
@@ -0,0 +1,51 @@
+// Package memory keeps every repository in process memory. It needs no
+// external service, which suits local development and tests; nothing
+// survives a restart.
+package memory
+
+import (
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// NewRepositories returns an in-memory implementation of every repository
+func NewRepositories() *ports.Repositories {
+	return &ports.Repositories{
+		ChargePoints:         NewChargePointRepository(),
+		Transactions:         NewTransactionRepository(),
+		Users:                NewUserRepository(),
+		Payments:             NewPaymentRepository(),
+		Cards:                NewCardRepository(),
+		Wallets:              NewWalletRepository(),
+		Reservations:         NewReservationRepository(),
+		MaintenanceWindows:   NewMaintenanceWindowRepository(),
+		Inventory:            NewDeviceInventoryRepository(),
+		MeterValues:          NewMeterValueRepository(),
+		ChargingProfiles:     NewChargingProfileReportRepository(),
+		LogRequests:          NewLogRequestRepository(),
+		FirmwarePublications: NewFirmwarePublicationRepository(),
+		ConnectionEvents:     NewConnectionEventRepository(),
+		StationCertificates:  NewStationCertificateRepository(),
+		Reconciliations:      NewReconciliationRepository(),
+		Alerts:               NewAlertRepository(),
+		Events:               NewEventRepository(),
+		VoiceCommands:        NewVoiceCommandRepository(),
+		V2G:                  NewV2GRepository(),
+		ISO15118:             NewISO15118Repository(),
+		Webhooks:             NewWebhookRepository(),
+		Audit:                NewAuditRepository(),
+		PendingCommands:      NewPendingCommandRepository(),
+		NotificationPrefs:    NewNotificationPreferenceRepository(),
+	}
+}
+
+// paginate returns items[offset:offset+limit]; a limit <= 0 means no limit
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,11 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, NewRepositories())
+}
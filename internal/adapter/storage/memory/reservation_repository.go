@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ReservationRepository implements reservation persistence in memory
+type ReservationRepository struct {
+	mu           sync.RWMutex
+	reservations map[string]domain.Reservation
+}
+
+// NewReservationRepository creates an empty reservation repository
+func NewReservationRepository() ports.ReservationRepository {
+	return &ReservationRepository{
+		reservations: make(map[string]domain.Reservation),
+	}
+}
+
+// Save creates or fully overwrites a reservation
+func (r *ReservationRepository) Save(ctx context.Context, reservation *domain.Reservation) error {
+	now := time.Now()
+	if reservation.CreatedAt.IsZero() {
+		reservation.CreatedAt = now
+	}
+	reservation.UpdatedAt = now
+
+	stored := *reservation
+	stored.User, stored.ChargePoint = nil, nil // Associations are not stored
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reservations[reservation.ID] = stored
+	return nil
+}
+
+// GetByID returns a reservation, or nil when it does not exist
+func (r *ReservationRepository) GetByID(ctx context.Context, id string) (*domain.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reservation, ok := r.reservations[id]
+	if !ok {
+		return nil, nil
+	}
+	return &reservation, nil
+}
+
+// GetByUserID returns a user's reservations, newest first, optionally by status
+func (r *ReservationRepository) GetByUserID(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error) {
+	reservations := r.find(func(res *domain.Reservation) bool {
+		return res.UserID == userID && (status == "" || string(res.Status) == status)
+	})
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.After(reservations[j].StartTime)
+	})
+	return paginate(reservations, limit, offset), nil
+}
+
+// GetByChargePointID returns the reservations of a charge point starting on
+// the day of date
+func (r *ReservationRepository) GetByChargePointID(ctx context.Context, chargePointID string, date time.Time) ([]domain.Reservation, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+	return r.find(func(res *domain.Reservation) bool {
+		return res.ChargePointID == chargePointID &&
+			!res.StartTime.Before(startOfDay) && res.StartTime.Before(endOfDay)
+	}), nil
+}
+
+// GetByTimeRange returns the reservations holding a connector at any time in
+// [startTime, endTime)
+func (r *ReservationRepository) GetByTimeRange(ctx context.Context, chargePointID string, connectorID int, startTime, endTime time.Time) ([]domain.Reservation, error) {
+	return r.find(func(res *domain.Reservation) bool {
+		return res.ChargePointID == chargePointID && res.ConnectorID == connectorID &&
+			holdsConnector(res) &&
+			res.StartTime.Before(endTime) && res.EndTime.After(startTime)
+	}), nil
+}
+
+// GetActiveByUserID returns a user's pending, confirmed and active reservations
+func (r *ReservationRepository) GetActiveByUserID(ctx context.Context, userID string) ([]domain.Reservation, error) {
+	return r.find(func(res *domain.Reservation) bool {
+		return res.UserID == userID && holdsConnector(res)
+	}), nil
+}
+
+// GetExpired returns the confirmed reservations whose user did not arrive
+// within gracePeriod of the start time
+func (r *ReservationRepository) GetExpired(ctx context.Context, gracePeriod time.Duration) ([]domain.Reservation, error) {
+	return r.find(func(res *domain.Reservation) bool { return res.IsExpired(gracePeriod) }), nil
+}
+
+// UpdateStatus sets the status of a reservation
+func (r *ReservationRepository) UpdateStatus(ctx context.Context, id string, status domain.ReservationStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if reservation, ok := r.reservations[id]; ok {
+		reservation.Status = status
+		reservation.UpdatedAt = time.Now()
+		r.reservations[id] = reservation
+	}
+	return nil
+}
+
+// Delete removes a reservation
+func (r *ReservationRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reservations, id)
+	return nil
+}
+
+// CountByUserAndStatus counts a user's reservations in any of statuses
+func (r *ReservationRepository) CountByUserAndStatus(ctx context.Context, userID string, statuses []domain.ReservationStatus) (int, error) {
+	return len(r.find(func(res *domain.Reservation) bool {
+		if res.UserID != userID {
+			return false
+		}
+		for _, status := range statuses {
+			if res.Status == status {
+				return true
+			}
+		}
+		return false
+	})), nil
+}
+
+// GetByOCPPReservationID returns the reservation held on a charge point, or nil
+func (r *ReservationRepository) GetByOCPPReservationID(ctx context.Context, chargePointID string, reservationID int) (*domain.Reservation, error) {
+	found := r.find(func(res *domain.Reservation) bool {
+		return res.ChargePointID == chargePointID && res.OCPPReservationID == reservationID
+	})
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return &found[0], nil
+}
+
+// find returns the matching reservations ordered by start time
+func (r *ReservationRepository) find(match func(res *domain.Reservation) bool) []domain.Reservation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var reservations []domain.Reservation
+	for _, res := range r.reservations {
+		if match(&res) {
+			reservations = append(reservations, res)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.Before(reservations[j].StartTime)
+	})
+	return reservations
+}
+
+// holdsConnector reports whether a reservation still blocks its connector
+func holdsConnector(res *domain.Reservation) bool {
+	return res.IsPending() || res.IsActive()
+}
+
+// MaintenanceWindowRepository implements maintenance window persistence in memory
+type MaintenanceWindowRepository struct {
+	mu      sync.RWMutex
+	windows map[string]domain.MaintenanceWindow
+}
+
+// NewMaintenanceWindowRepository creates an empty maintenance window repository
+func NewMaintenanceWindowRepository() ports.MaintenanceWindowRepository {
+	return &MaintenanceWindowRepository{
+		windows: make(map[string]domain.MaintenanceWindow),
+	}
+}
+
+// Save creates or fully overwrites a maintenance window
+func (r *MaintenanceWindowRepository) Save(ctx context.Context, window *domain.MaintenanceWindow) error {
+	if window.CreatedAt.IsZero() {
+		window.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.windows[window.ID] = *window
+	return nil
+}
+
+// FindOverlapping returns the windows of a charge point that overlap [from, to)
+func (r *MaintenanceWindowRepository) FindOverlapping(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var windows []domain.MaintenanceWindow
+	for _, w := range r.windows {
+		if w.ChargePointID == chargePointID && w.StartTime.Before(to) && w.EndTime.After(from) {
+			windows = append(windows, w)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].StartTime.Before(windows[j].StartTime) })
+	return windows, nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// StationCertificateRepository implements station certificate persistence in memory
+type StationCertificateRepository struct {
+	mu    sync.RWMutex
+	certs []domain.StationCertificate // In issue order
+}
+
+// NewStationCertificateRepository creates an empty station certificate repository
+func NewStationCertificateRepository() ports.StationCertificateRepository {
+	return &StationCertificateRepository{}
+}
+
+// Save stores an issued certificate
+func (r *StationCertificateRepository) Save(ctx context.Context, cert *domain.StationCertificate) error {
+	if cert.CreatedAt.IsZero() {
+		cert.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certs = append(r.certs, *cert)
+	return nil
+}
+
+// GetLatest returns the most recently issued certificate of a type, or nil
+func (r *StationCertificateRepository) GetLatest(ctx context.Context, chargePointID string, certType domain.StationCertificateType) (*domain.StationCertificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *domain.StationCertificate
+	for i := range r.certs {
+		cert := r.certs[i]
+		if cert.ChargePointID != chargePointID || cert.CertificateType != certType {
+			continue
+		}
+		if latest == nil || !cert.CreatedAt.Before(latest.CreatedAt) {
+			latest = &cert
+		}
+	}
+	return latest, nil
+}
+
+// FindExpiringBefore returns the latest certificate per charge point and type
+// when it expires before the given time, soonest first
+func (r *StationCertificateRepository) FindExpiringBefore(ctx context.Context, before time.Time) ([]domain.StationCertificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type key struct {
+		chargePointID string
+		certType      domain.StationCertificateType
+	}
+	latest := make(map[key]domain.StationCertificate)
+	for _, cert := range r.certs {
+		k := key{cert.ChargePointID, cert.CertificateType}
+		if current, ok := latest[k]; !ok || !cert.CreatedAt.Before(current.CreatedAt) {
+			latest[k] = cert
+		}
+	}
+
+	var expiring []domain.StationCertificate
+	for _, cert := range latest {
+		if cert.NotAfter.Before(before) {
+			expiring = append(expiring, cert)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool { return expiring[i].NotAfter.Before(expiring[j].NotAfter) })
+	return expiring, nil
+}
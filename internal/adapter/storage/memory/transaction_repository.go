@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// TransactionRepository implements transaction persistence in memory
+type TransactionRepository struct {
+	mu           sync.RWMutex
+	transactions map[string]domain.Transaction
+}
+
+// NewTransactionRepository creates an empty transaction repository
+func NewTransactionRepository() ports.TransactionRepository {
+	return &TransactionRepository{
+		transactions: make(map[string]domain.Transaction),
+	}
+}
+
+// Save creates or fully overwrites a transaction
+func (r *TransactionRepository) Save(ctx context.Context, tx *domain.Transaction) error {
+	now := time.Now()
+	if tx.CreatedAt.IsZero() {
+		tx.CreatedAt = now
+	}
+	tx.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transactions[tx.ID] = *tx
+	return nil
+}
+
+// Update overwrites a transaction
+func (r *TransactionRepository) Update(ctx context.Context, tx *domain.Transaction) error {
+	return r.Save(ctx, tx)
+}
+
+// FindByID returns a transaction, or nil when it does not exist
+func (r *TransactionRepository) FindByID(ctx context.Context, id string) (*domain.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tx, ok := r.transactions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &tx, nil
+}
+
+// FindActiveByUserID returns the user's started transaction, or nil
+func (r *TransactionRepository) FindActiveByUserID(ctx context.Context, userID string) (*domain.Transaction, error) {
+	active := r.find(func(tx *domain.Transaction) bool {
+		return tx.UserID == userID && tx.Status == domain.TransactionStatusStarted
+	})
+	if len(active) == 0 {
+		return nil, nil
+	}
+	return &active[0], nil
+}
+
+// FindHistoryByUserID returns a user's transactions, newest first
+func (r *TransactionRepository) FindHistoryByUserID(ctx context.Context, userID string) ([]domain.Transaction, error) {
+	return r.find(func(tx *domain.Transaction) bool { return tx.UserID == userID }), nil
+}
+
+// FindByDate returns the transactions created on the day of date
+func (r *TransactionRepository) FindByDate(ctx context.Context, date time.Time) ([]domain.Transaction, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+	return r.find(func(tx *domain.Transaction) bool {
+		return !tx.CreatedAt.Before(startOfDay) && tx.CreatedAt.Before(endOfDay)
+	}), nil
+}
+
+// FindPage returns up to limit transactions matching filter after the cursor
+func (r *TransactionRepository) FindPage(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+	txs := r.find(func(tx *domain.Transaction) bool {
+		if !matchesTransactionFilter(tx, filter) {
+			return false
+		}
+		if after == nil {
+			return true
+		}
+		// (created_at, id) < (after.CreatedAt, after.ID)
+		return tx.CreatedAt.Before(after.CreatedAt) ||
+			(tx.CreatedAt.Equal(after.CreatedAt) && tx.ID < after.ID)
+	})
+	return paginate(txs, limit, 0), nil
+}
+
+// Count returns the number of transactions matching filter
+func (r *TransactionRepository) Count(ctx context.Context, filter ports.TransactionFilter) (int, error) {
+	return len(r.find(func(tx *domain.Transaction) bool {
+		return matchesTransactionFilter(tx, filter)
+	})), nil
+}
+
+// CompareAndSetPaymentStatus sets the payment status under the lock
+func (r *TransactionRepository) CompareAndSetPaymentStatus(ctx context.Context, id string, from, to domain.TransactionPaymentStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx, ok := r.transactions[id]
+	if !ok || tx.PaymentStatus != from {
+		return false, nil
+	}
+	tx.PaymentStatus = to
+	tx.UpdatedAt = time.Now()
+	r.transactions[id] = tx
+	return true, nil
+}
+
+// FindByPaymentStatus returns the transactions in status last updated before
+// the given time, oldest first
+func (r *TransactionRepository) FindByPaymentStatus(ctx context.Context, status domain.TransactionPaymentStatus, updatedBefore time.Time) ([]domain.Transaction, error) {
+	txs := r.find(func(tx *domain.Transaction) bool {
+		return tx.PaymentStatus == status && tx.UpdatedAt.Before(updatedBefore)
+	})
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].UpdatedAt.Before(txs[j].UpdatedAt) })
+	return txs, nil
+}
+
+// FindByOCPPTransactionID returns the transaction a station gave
+// ocppTransactionID, or nil
+func (r *TransactionRepository) FindByOCPPTransactionID(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+	txs := r.find(func(tx *domain.Transaction) bool {
+		return tx.ChargePointID == chargePointID && tx.OCPPTransactionID == ocppTransactionID
+	})
+	if len(txs) == 0 {
+		return nil, nil
+	}
+	return &txs[0], nil
+}
+
+// find returns the matching transactions, newest first (created_at, then id)
+func (r *TransactionRepository) find(match func(tx *domain.Transaction) bool) []domain.Transaction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var txs []domain.Transaction
+	for _, tx := range r.transactions {
+		if match(&tx) {
+			txs = append(txs, tx)
+		}
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if !txs[i].CreatedAt.Equal(txs[j].CreatedAt) {
+			return txs[i].CreatedAt.After(txs[j].CreatedAt)
+		}
+		return txs[i].ID > txs[j].ID
+	})
+	return txs
+}
+
+func matchesTransactionFilter(tx *domain.Transaction, filter ports.TransactionFilter) bool {
+	if filter.Status != "" && string(tx.Status) != filter.Status {
+		return false
+	}
+	if filter.UserID != "" && tx.UserID != filter.UserID {
+		return false
+	}
+	if filter.ChargePointID != "" && tx.ChargePointID != filter.ChargePointID {
+		return false
+	}
+	if !filter.StartDate.IsZero() && tx.CreatedAt.Before(filter.StartDate) {
+		return false
+	}
+	if !filter.EndDate.IsZero() && !tx.CreatedAt.Before(filter.EndDate) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// UserRepository implements user persistence in memory
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[string]domain.User
+}
+
+// NewUserRepository creates an empty user repository
+func NewUserRepository() ports.UserRepository {
+	return &UserRepository{
+		users: make(map[string]domain.User),
+	}
+}
+
+// Save creates or fully overwrites a user
+func (r *UserRepository) Save(ctx context.Context, user *domain.User) error {
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID] = *user
+	return nil
+}
+
+// FindByID returns a user, or nil when it does not exist
+func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.findOne(func(u *domain.User) bool { return u.ID == id }), nil
+}
+
+// FindByEmail returns the user with an email, or nil
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.findOne(func(u *domain.User) bool { return u.Email == email }), nil
+}
+
+// FindByDocument returns the user with a document (CPF/CNPJ), or nil
+func (r *UserRepository) FindByDocument(ctx context.Context, document string) (*domain.User, error) {
+	return r.findOne(func(u *domain.User) bool { return u.Document == document }), nil
+}
+
+func (r *UserRepository) findOne(match func(u *domain.User) bool) *domain.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if match(&u) {
+			return &u
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// V2GRepository implements V2G session and preference persistence in memory
+type V2GRepository struct {
+	mu          sync.RWMutex
+	sessions    map[string]domain.V2GSession
+	preferences map[string]domain.V2GPreferences // userID → preferences
+}
+
+// NewV2GRepository creates an empty V2G repository
+func NewV2GRepository() ports.V2GRepository {
+	return &V2GRepository{
+		sessions:    make(map[string]domain.V2GSession),
+		preferences: make(map[string]domain.V2GPreferences),
+	}
+}
+
+// CreateSession stores a new V2G session
+func (r *V2GRepository) CreateSession(ctx context.Context, session *domain.V2GSession) error {
+	now := time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	session.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// UpdateSession overwrites a V2G session
+func (r *V2GRepository) UpdateSession(ctx context.Context, session *domain.V2GSession) error {
+	return r.CreateSession(ctx, session)
+}
+
+// GetSession returns a V2G session, or nil when it does not exist
+func (r *V2GRepository) GetSession(ctx context.Context, sessionID string) (*domain.V2GSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// GetSessionsByChargePoint returns a charge point's sessions, newest first
+func (r *V2GRepository) GetSessionsByChargePoint(ctx context.Context, chargePointID string, limit int) ([]domain.V2GSession, error) {
+	sessions := r.findSessions(func(s *domain.V2GSession) bool { return s.ChargePointID == chargePointID })
+	return paginate(sessions, limit, 0), nil
+}
+
+// GetSessionsByUser returns a user's sessions, newest first
+func (r *V2GRepository) GetSessionsByUser(ctx context.Context, userID string, limit int) ([]domain.V2GSession, error) {
+	sessions := r.findSessions(func(s *domain.V2GSession) bool { return s.UserID == userID })
+	return paginate(sessions, limit, 0), nil
+}
+
+// SavePreferences creates or replaces a user's V2G preferences
+func (r *V2GRepository) SavePreferences(ctx context.Context, prefs *domain.V2GPreferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := r.preferences[prefs.UserID]; ok {
+		prefs.ID = existing.ID
+		prefs.CreatedAt = existing.CreatedAt
+	} else {
+		if prefs.ID == "" {
+			prefs.ID = uuid.New().String()
+		}
+		prefs.CreatedAt = now
+	}
+	prefs.UpdatedAt = now
+	r.preferences[prefs.UserID] = *prefs
+	return nil
+}
+
+// GetPreferences returns a user's V2G preferences, or the defaults when the
+// user never saved any
+func (r *V2GRepository) GetPreferences(ctx context.Context, userID string) (*domain.V2GPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs, ok := r.preferences[userID]
+	if !ok {
+		return &domain.V2GPreferences{
+			UserID:          userID,
+			AutoDischarge:   false,
+			MinGridPrice:    0.80,
+			MaxDischargeKWh: 50.0,
+			PreserveSOC:     20,
+			NotifyOnStart:   true,
+			NotifyOnEnd:     true,
+		}, nil
+	}
+	return &prefs, nil
+}
+
+// GetUserStats aggregates a user's sessions created in [startDate, endDate]
+func (r *V2GRepository) GetUserStats(ctx context.Context, userID string, startDate, endDate time.Time) (*domain.V2GStats, error) {
+	sessions := r.findSessions(func(s *domain.V2GSession) bool { return s.UserID == userID })
+	return v2gStats(sessions, userID, "user", startDate, endDate), nil
+}
+
+// GetChargePointStats aggregates a charge point's sessions created in [startDate, endDate]
+func (r *V2GRepository) GetChargePointStats(ctx context.Context, chargePointID string, startDate, endDate time.Time) (*domain.V2GStats, error) {
+	sessions := r.findSessions(func(s *domain.V2GSession) bool { return s.ChargePointID == chargePointID })
+	return v2gStats(sessions, chargePointID, "charge_point", startDate, endDate), nil
+}
+
+// findSessions returns the matching sessions, newest first
+func (r *V2GRepository) findSessions(match func(s *domain.V2GSession) bool) []domain.V2GSession {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessions []domain.V2GSession
+	for _, s := range r.sessions {
+		if match(&s) {
+			sessions = append(sessions, s)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.After(sessions[j].CreatedAt) })
+	return sessions
+}
+
+// v2gStats aggregates sessions the way the SQL backend does: discharged
+// energy and compensation from discharging sessions, duration up to now for
+// running ones and participation during the 17h-21h peak
+func v2gStats(sessions []domain.V2GSession, entityID, entityType string, startDate, endDate time.Time) *domain.V2GStats {
+	stats := &domain.V2GStats{
+		EntityID:   entityID,
+		EntityType: entityType,
+		StartDate:  startDate,
+		EndDate:    endDate,
+	}
+
+	now := time.Now()
+	var totalDuration time.Duration
+	var peak int
+	for _, s := range sessions {
+		if s.CreatedAt.Before(startDate) || s.CreatedAt.After(endDate) {
+			continue
+		}
+		stats.TotalSessions++
+		if s.Direction == domain.V2GDirectionDischarging {
+			energy := s.EnergyTransferred
+			if energy < 0 {
+				energy = -energy
+			}
+			stats.TotalEnergyDischargedKWh += energy
+			stats.TotalCompensation += s.UserCompensation
+		}
+		end := now
+		if s.EndTime != nil {
+			end = *s.EndTime
+		}
+		totalDuration += end.Sub(s.StartTime)
+		if hour := s.StartTime.Hour(); hour >= 17 && hour <= 21 {
+			peak++
+		}
+	}
+	if stats.TotalSessions > 0 {
+		stats.AverageSessionDuration = (totalDuration / time.Duration(stats.TotalSessions)).Truncate(time.Second)
+		stats.PeakHoursParticipation = float64(peak) / float64(stats.TotalSessions) * 100
+	}
+	return stats
+}
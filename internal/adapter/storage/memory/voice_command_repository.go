@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// VoiceCommandRepository implements voice command history persistence in memory
+type VoiceCommandRepository struct {
+	mu       sync.RWMutex
+	commands []domain.VoiceCommand
+}
+
+// NewVoiceCommandRepository creates an empty voice command repository
+func NewVoiceCommandRepository() ports.VoiceCommandRepository {
+	return &VoiceCommandRepository{}
+}
+
+// Save stores a processed voice command
+func (r *VoiceCommandRepository) Save(ctx context.Context, cmd *domain.VoiceCommand) error {
+	if cmd.CreatedAt.IsZero() {
+		cmd.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, *cmd)
+	return nil
+}
+
+// FindByUserID returns a user's voice commands, newest first
+func (r *VoiceCommandRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) ([]domain.VoiceCommand, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var cmds []domain.VoiceCommand
+	for _, cmd := range r.commands {
+		if cmd.UserID == userID {
+			cmds = append(cmds, cmd)
+		}
+	}
+	sort.SliceStable(cmds, func(i, j int) bool { return cmds[i].CreatedAt.After(cmds[j].CreatedAt) })
+	return paginate(cmds, limit, offset), nil
+}
+
+// AnonymizeByUserID blanks the transcript and result of a user's voice commands
+func (r *VoiceCommandRepository) AnonymizeByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.commands {
+		if r.commands[i].UserID == userID {
+			r.commands[i].Transcript = ""
+			r.commands[i].Result = ""
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// WalletRepository implements wallet persistence in memory
+type WalletRepository struct {
+	mu           sync.RWMutex
+	wallets      map[string]domain.Wallet
+	transactions []domain.WalletTransaction
+}
+
+// NewWalletRepository creates an empty wallet repository
+func NewWalletRepository() ports.WalletRepository {
+	return &WalletRepository{
+		wallets: make(map[string]domain.Wallet),
+	}
+}
+
+// Save creates or fully overwrites a wallet
+func (r *WalletRepository) Save(ctx context.Context, wallet *domain.Wallet) error {
+	wallet.UpdatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wallets[wallet.ID] = *wallet
+	return nil
+}
+
+// GetByID returns a wallet, or nil when it does not exist
+func (r *WalletRepository) GetByID(ctx context.Context, id string) (*domain.Wallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallet, ok := r.wallets[id]
+	if !ok {
+		return nil, nil
+	}
+	return &wallet, nil
+}
+
+// GetByUserID returns a user's wallet, or nil
+func (r *WalletRepository) GetByUserID(ctx context.Context, userID string) (*domain.Wallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, wallet := range r.wallets {
+		if wallet.UserID == userID {
+			return &wallet, nil
+		}
+	}
+	return nil, nil
+}
+
+// AdjustBalance applies delta under the lock, so concurrent credits and
+// debits never lose updates or overdraw the wallet
+func (r *WalletRepository) AdjustBalance(ctx context.Context, walletID string, delta float64) (*domain.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.adjustBalance(walletID, delta)
+}
+
+// Credit adds and records the credit under the lock, once per reference
+func (r *WalletRepository) Credit(ctx context.Context, entry *domain.WalletTransaction) (*domain.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry.ReferenceID != "" {
+		for _, tx := range r.transactions {
+			if tx.Type == entry.Type && tx.ReferenceID == entry.ReferenceID {
+				return nil, domain.ErrAlreadyCredited
+			}
+		}
+	}
+	wallet, err := r.adjustBalance(entry.WalletID, entry.Amount)
+	if err != nil {
+		return nil, err
+	}
+	entry.Balance = wallet.Balance
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	r.transactions = append(r.transactions, *entry)
+	return wallet, nil
+}
+
+// adjustBalance applies delta; the caller holds the lock
+func (r *WalletRepository) adjustBalance(walletID string, delta float64) (*domain.Wallet, error) {
+	wallet, ok := r.wallets[walletID]
+	if !ok {
+		return nil, fmt.Errorf("wallet not found: %s", walletID)
+	}
+	if wallet.Balance+delta < 0 {
+		return nil, domain.ErrInsufficientBalance
+	}
+	wallet.Balance += delta
+	wallet.Version++
+	wallet.UpdatedAt = time.Now()
+	r.wallets[walletID] = wallet
+	return &wallet, nil
+}
+
+// SaveTransaction records a wallet transaction
+func (r *WalletRepository) SaveTransaction(ctx context.Context, tx *domain.WalletTransaction) error {
+	if tx.CreatedAt.IsZero() {
+		tx.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transactions = append(r.transactions, *tx)
+	return nil
+}
+
+// GetTransactions returns a wallet's transactions, newest first
+func (r *WalletRepository) GetTransactions(ctx context.Context, walletID string, limit, offset int) ([]domain.WalletTransaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var txs []domain.WalletTransaction
+	for _, tx := range r.transactions {
+		if tx.WalletID == walletID {
+			txs = append(txs, tx)
+		}
+	}
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].CreatedAt.After(txs[j].CreatedAt) })
+	return paginate(txs, limit, offset), nil
+}
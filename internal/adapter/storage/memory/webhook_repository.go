@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// WebhookRepository implements webhook persistence in memory
+type WebhookRepository struct {
+	mu         sync.RWMutex
+	endpoints  map[string]domain.WebhookEndpoint
+	deliveries map[string]domain.WebhookDelivery
+}
+
+// NewWebhookRepository creates an empty webhook repository
+func NewWebhookRepository() ports.WebhookRepository {
+	return &WebhookRepository{
+		endpoints:  make(map[string]domain.WebhookEndpoint),
+		deliveries: make(map[string]domain.WebhookDelivery),
+	}
+}
+
+// Save creates or updates an endpoint
+func (r *WebhookRepository) Save(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	now := time.Now()
+	if endpoint.CreatedAt.IsZero() {
+		endpoint.CreatedAt = now
+	}
+	endpoint.UpdatedAt = now
+
+	stored := *endpoint
+	stored.EventTypes = append([]string(nil), endpoint.EventTypes...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints[endpoint.ID] = stored
+	return nil
+}
+
+// FindByID returns an endpoint, or nil
+func (r *WebhookRepository) FindByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	endpoint, ok := r.endpoints[id]
+	if !ok {
+		return nil, nil
+	}
+	return &endpoint, nil
+}
+
+// FindByAccountID returns an account's endpoints, oldest first
+func (r *WebhookRepository) FindByAccountID(ctx context.Context, accountID string) ([]domain.WebhookEndpoint, error) {
+	return r.find(func(e *domain.WebhookEndpoint) bool { return e.AccountID == accountID }), nil
+}
+
+// FindByEventType returns the active endpoints subscribed to an event type
+func (r *WebhookRepository) FindByEventType(ctx context.Context, eventType string) ([]domain.WebhookEndpoint, error) {
+	return r.find(func(e *domain.WebhookEndpoint) bool { return e.Subscribes(eventType) }), nil
+}
+
+func (r *WebhookRepository) find(match func(e *domain.WebhookEndpoint) bool) []domain.WebhookEndpoint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var endpoints []domain.WebhookEndpoint
+	for _, endpoint := range r.endpoints {
+		if match(&endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].CreatedAt.Before(endpoints[j].CreatedAt) })
+	return endpoints
+}
+
+// Delete removes an endpoint and its delivery log
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.endpoints, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.EndpointID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// SaveDelivery creates or updates a delivery
+func (r *WebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+// FindDeliveries returns an endpoint's deliveries, newest first
+func (r *WebhookRepository) FindDeliveries(ctx context.Context, endpointID string, limit, offset int) ([]domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.EndpointID == endpointID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	return paginate(deliveries, limit, offset), nil
+}
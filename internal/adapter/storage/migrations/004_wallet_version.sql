@@ -0,0 +1,8 @@
+-- Migration: Wallet row version
+-- Created: 2026-10-16
+-- Description: Adds a row version to wallets and forbids negative balances
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallet_balance_non_negative;
+ALTER TABLE wallets ADD CONSTRAINT chk_wallet_balance_non_negative CHECK (balance >= 0);
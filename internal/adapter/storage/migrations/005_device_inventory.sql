@@ -0,0 +1,10 @@
+-- Migration: Device inventory
+-- Created: 2026-10-16
+-- Description: Stores the component/variable model reported via NotifyReport
+
+CREATE TABLE IF NOT EXISTS device_inventories (
+    charge_point_id VARCHAR(100) PRIMARY KEY,
+    request_id INTEGER NOT NULL DEFAULT 0,
+    variables JSONB NOT NULL DEFAULT '[]',
+    reported_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Migration: User language
+-- Created: 2026-10-16
+-- Description: Preferred language for notifications (BCP 47 tag, defaults to pt-BR)
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(10) NOT NULL DEFAULT 'pt-BR';
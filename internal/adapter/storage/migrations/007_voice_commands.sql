@@ -0,0 +1,15 @@
+-- Migration: Voice command history
+-- Created: 2026-10-16
+-- Description: Processed voice commands per user (transcript and result are stored redacted)
+
+CREATE TABLE IF NOT EXISTS voice_commands (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transcript TEXT NOT NULL DEFAULT '',
+    intent VARCHAR(50) NOT NULL DEFAULT '',
+    confidence DOUBLE PRECISION NOT NULL DEFAULT 0,
+    result TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_voice_commands_user_created ON voice_commands(user_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- Migration: Transaction event sequence
+-- Created: 2026-10-16
+-- Description: Highest OCPP 2.0.1 TransactionEvent seqNo applied to each transaction
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS last_seq_no INTEGER NOT NULL DEFAULT 0;
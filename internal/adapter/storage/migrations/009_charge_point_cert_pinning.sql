@@ -0,0 +1,6 @@
+-- Migration: Charge point client certificate pinning
+-- Created: 2026-10-16
+-- Description: Expected mTLS client certificate identity per charge point (opt-in)
+
+ALTER TABLE charge_points ADD COLUMN IF NOT EXISTS cert_common_name VARCHAR(255);
+ALTER TABLE charge_points ADD COLUMN IF NOT EXISTS cert_serial_number VARCHAR(100);
@@ -0,0 +1,5 @@
+-- Migration: Transaction listing index
+-- Created: 2026-10-16
+-- Description: Supports admin transaction listing ordered and paged by (created_at, id)
+
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at_id ON transactions(created_at DESC, id DESC);
@@ -0,0 +1,10 @@
+-- Migration: Transaction tax breakdown
+-- Created: 2026-10-16
+-- Description: Stores the ICMS/PIS/COFINS breakdown of the amount charged for each session
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_state VARCHAR(2);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_net DECIMAL(12,2) DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_icms DECIMAL(12,2) DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_pis DECIMAL(12,2) DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_cofins DECIMAL(12,2) DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_gross DECIMAL(12,2) DEFAULT 0;
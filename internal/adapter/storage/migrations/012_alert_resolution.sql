@@ -0,0 +1,8 @@
+-- Migration: Alert resolution
+-- Created: 2026-10-16
+-- Description: Lets alerts be resolved automatically when the reported condition clears
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS resolved BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_open_source ON alerts(type, source_id) WHERE resolved = FALSE;
@@ -0,0 +1,19 @@
+-- Migration: Log requests
+-- Created: 2026-10-16
+-- Description: Tracks GetLog requests and the upload status reported by LogStatusNotification
+
+CREATE TABLE IF NOT EXISTS log_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    charge_point_id VARCHAR(100) NOT NULL REFERENCES charge_points(id) ON DELETE CASCADE,
+    request_id INTEGER NOT NULL,
+    log_type VARCHAR(20) NOT NULL,
+    remote_location TEXT NOT NULL,
+    filename VARCHAR(255),
+    status VARCHAR(30) NOT NULL,
+    file_url TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_log_requests_cp_request ON log_requests(charge_point_id, request_id);
+CREATE INDEX IF NOT EXISTS idx_log_requests_cp_created ON log_requests(charge_point_id, created_at DESC);
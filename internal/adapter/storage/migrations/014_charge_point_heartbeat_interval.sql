@@ -0,0 +1,5 @@
+-- Migration: Charge point heartbeat interval
+-- Created: 2026-10-16
+-- Description: Stores the heartbeat interval negotiated at BootNotification for offline detection
+
+ALTER TABLE charge_points ADD COLUMN IF NOT EXISTS heartbeat_interval INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+-- Migration: Station certificates
+-- Created: 2026-10-16
+-- Description: Certificates issued to charge points through SignCertificate, with their expiry
+
+CREATE TABLE IF NOT EXISTS station_certificates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    charge_point_id VARCHAR(100) NOT NULL REFERENCES charge_points(id) ON DELETE CASCADE,
+    certificate_type VARCHAR(40) NOT NULL,
+    serial_number VARCHAR(64) NOT NULL,
+    common_name VARCHAR(255) NOT NULL,
+    certificate_chain TEXT NOT NULL,
+    not_before TIMESTAMP WITH TIME ZONE NOT NULL,
+    not_after TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_station_certificates_serial ON station_certificates(serial_number);
+CREATE INDEX IF NOT EXISTS idx_station_certificates_cp_type ON station_certificates(charge_point_id, certificate_type, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_station_certificates_not_after ON station_certificates(not_after);
@@ -0,0 +1,20 @@
+-- Migration: Transaction reconciliation
+-- Created: 2026-10-16
+-- Description: Station-reported session energy and the reconciliation of completed transactions against it
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS station_energy INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    charge_point_id VARCHAR(100) NOT NULL,
+    computed_energy_wh INTEGER NOT NULL,
+    station_energy_wh INTEGER NOT NULL,
+    difference_wh INTEGER NOT NULL,
+    tolerance_wh INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reconciliations_transaction ON reconciliations(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_reconciliations_status ON reconciliations(status, created_at DESC);
@@ -0,0 +1,7 @@
+-- Migration: Multi-currency payments
+-- Created: 2026-10-16
+-- Description: Exchange rate and settled amount of payments paid from a wallet held in another currency
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS fx_rate DECIMAL(18,8);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS settled_amount DECIMAL(12,4);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS settled_currency VARCHAR(3);
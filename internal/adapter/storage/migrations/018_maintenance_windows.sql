@@ -0,0 +1,18 @@
+-- Migration: Maintenance windows
+-- Created: 2026-10-16
+-- Description: Planned maintenance that blocks connectors from being reserved
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    charge_point_id VARCHAR(100) NOT NULL,
+    connector_id INTEGER NOT NULL DEFAULT 0, -- 0 = whole station
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_maintenance_charge_point FOREIGN KEY (charge_point_id) REFERENCES charge_points(id) ON DELETE CASCADE,
+    CONSTRAINT chk_maintenance_window CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_charge_point_time ON maintenance_windows(charge_point_id, start_time, end_time);
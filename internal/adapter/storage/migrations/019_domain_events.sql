@@ -0,0 +1,13 @@
+-- Migration: Domain events
+-- Created: 2026-10-16
+-- Description: Event store for the PostgreSQL storage backend
+
+CREATE TABLE IF NOT EXISTS domain_events (
+    seq BIGINT PRIMARY KEY,
+    aggregate_id VARCHAR(100) NOT NULL,
+    subject VARCHAR(100) NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_events_aggregate ON domain_events(aggregate_id, seq);
@@ -0,0 +1,20 @@
+-- Migration: Admin audit log
+-- Created: 2026-10-16
+-- Description: Who changed what through the admin API, with the values before and after
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id VARCHAR(100),
+    actor_role VARCHAR(20),
+    source_ip VARCHAR(45),
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created ON audit_entries(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_actor ON audit_entries(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_entity ON audit_entries(entity_type, entity_id, created_at DESC);
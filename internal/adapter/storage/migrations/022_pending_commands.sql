@@ -0,0 +1,14 @@
+-- Migration: Pending OCPP commands
+-- Created: 2026-10-16
+-- Description: Commands sent to charge points and not answered yet, so answers arriving after a restart can be matched
+
+CREATE TABLE IF NOT EXISTS pending_commands (
+    message_id VARCHAR(36) PRIMARY KEY,
+    charge_point_id VARCHAR(50) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_commands_expires ON pending_commands(expires_at);
@@ -0,0 +1,14 @@
+-- Migration: Notification preferences
+-- Created: 2026-10-16
+-- Description: Channels each user opted into, where to reach them and the notification types they turned off
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id VARCHAR(36) PRIMARY KEY,
+    email BOOLEAN NOT NULL DEFAULT TRUE,
+    push BOOLEAN NOT NULL DEFAULT TRUE,
+    sms BOOLEAN NOT NULL DEFAULT FALSE,
+    phone_number VARCHAR(20),
+    push_token TEXT,
+    disabled_events JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
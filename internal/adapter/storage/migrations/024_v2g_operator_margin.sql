@@ -0,0 +1,7 @@
+-- Migration: Per-user V2G operator margin
+-- Created: 2026-10-16
+-- Description: Operator margin negotiated for a user's plan or contract, overriding the configured default
+
+ALTER TABLE v2g_preferences
+    ADD COLUMN IF NOT EXISTS operator_margin DECIMAL(5,4)
+    CHECK (operator_margin IS NULL OR (operator_margin >= 0 AND operator_margin < 1));
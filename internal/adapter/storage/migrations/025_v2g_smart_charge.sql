@@ -0,0 +1,8 @@
+-- Migration: V2G smart charging preferences
+-- Created: 2026-10-16
+-- Description: Opt-in to charging in the cheapest hours before a daily departure time
+
+ALTER TABLE v2g_preferences
+    ADD COLUMN IF NOT EXISTS smart_charge BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS departure_time VARCHAR(5),
+    ADD COLUMN IF NOT EXISTS departure_soc INTEGER CHECK (departure_soc IS NULL OR (departure_soc >= 0 AND departure_soc <= 100));
@@ -0,0 +1,10 @@
+-- Migration: Payment webhook idempotency
+-- Created: 2026-10-16
+-- Description: Provider webhook events already processed, so retried deliveries credit wallets once
+
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, event_id)
+);
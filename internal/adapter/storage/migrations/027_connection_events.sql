@@ -0,0 +1,14 @@
+-- Migration: Connection events
+-- Created: 2026-10-16
+-- Description: When each charge point connected to and disconnected from the CSMS, for connection history and uptime
+
+CREATE TABLE IF NOT EXISTS connection_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    charge_point_id VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    remote_addr VARCHAR(100),
+    reason VARCHAR(50),
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_connection_events_charge_point_timestamp ON connection_events(charge_point_id, timestamp);
@@ -0,0 +1,10 @@
+-- Migration: Charging profile reports
+-- Created: 2026-10-16
+-- Description: Stores the charging profiles each charge point reported via ReportChargingProfiles
+
+CREATE TABLE IF NOT EXISTS charging_profile_reports (
+    charge_point_id VARCHAR(100) PRIMARY KEY,
+    request_id INTEGER NOT NULL DEFAULT 0,
+    profiles JSONB NOT NULL DEFAULT '[]',
+    reported_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Migration: Meter value retention
+-- Created: 2026-10-16
+-- Description: Index for reading and downsampling a transaction's meter values in order
+
+CREATE INDEX IF NOT EXISTS idx_meter_values_transaction_timestamp ON meter_values(transaction_id, timestamp);
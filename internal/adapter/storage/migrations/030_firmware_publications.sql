@@ -0,0 +1,18 @@
+-- Migration: Firmware publications
+-- Created: 2026-10-16
+-- Description: Tracks PublishFirmware requests and the status reported by PublishFirmwareStatusNotification
+
+CREATE TABLE IF NOT EXISTS firmware_publications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    charge_point_id VARCHAR(100) NOT NULL REFERENCES charge_points(id) ON DELETE CASCADE,
+    request_id INTEGER NOT NULL,
+    location TEXT NOT NULL,
+    checksum VARCHAR(32) NOT NULL,
+    status VARCHAR(30) NOT NULL,
+    published_uris JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_firmware_publications_cp_request ON firmware_publications(charge_point_id, request_id);
+CREATE INDEX IF NOT EXISTS idx_firmware_publications_cp_created ON firmware_publications(charge_point_id, created_at DESC);
@@ -0,0 +1,8 @@
+-- Migration: Transaction payment status
+-- Created: 2026-10-16
+-- Description: Records whether a completed session was paid for and by which payment
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payment_status VARCHAR(20);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payment_id VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_payment_status ON transactions(payment_status) WHERE payment_status IS NOT NULL;
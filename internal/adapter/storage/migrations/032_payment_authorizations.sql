@@ -0,0 +1,7 @@
+-- Migration: Payment authorizations
+-- Created: 2026-10-16
+-- Description: Records the amount held on a card when a session starts, captured when it stops
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS authorized_amount DECIMAL(12,4);
+
+CREATE INDEX IF NOT EXISTS idx_payments_authorized ON payments(created_at) WHERE status = 'authorized';
@@ -0,0 +1,5 @@
+-- Migration: Meter value location
+-- Created: 2026-10-16
+-- Description: Where a sampled value was measured (Body, Cable, EV, Inlet, Outlet), so SoC and outlet readings can be told apart
+
+ALTER TABLE meter_values ADD COLUMN IF NOT EXISTS location VARCHAR(10);
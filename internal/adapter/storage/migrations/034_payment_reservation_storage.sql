@@ -0,0 +1,52 @@
+-- Migration: Payment and reservation storage
+-- Created: 2026-10-16
+-- Description: Columns and tables the PostgreSQL payment, card and reservation repositories store
+
+-- Wallet top-ups and reservation fees have no charging transaction
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS fk_payment_transaction;
+ALTER TABLE payments ALTER COLUMN transaction_id TYPE VARCHAR(100) USING transaction_id::text;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS failure_reason VARCHAR(255);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_payments_transaction ON payments(transaction_id);
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    payment_id UUID NOT NULL,
+    provider_id VARCHAR(100),
+    amount DECIMAL(12,4) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reason VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT fk_refund_payment FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_payment ON refunds(payment_id);
+
+CREATE TABLE IF NOT EXISTS payment_cards (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    provider VARCHAR(50),
+    provider_id VARCHAR(100), -- Card token from the provider
+    brand VARCHAR(20),
+    last4 VARCHAR(4),
+    exp_month INTEGER,
+    exp_year INTEGER,
+    holder_name VARCHAR(255),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_payment_card_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_cards_user ON payment_cards(user_id);
+
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS duration INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS actual_arrival TIMESTAMP WITH TIME ZONE;
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS transaction_id VARCHAR(100);
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS fee DECIMAL(10,4) NOT NULL DEFAULT 0;
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS fee_paid BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_reservations_ocpp ON reservations(charge_point_id, reservation_id);
@@ -0,0 +1,7 @@
+-- Migration: OCPP transaction ID
+-- Created: 2026-10-16
+-- Description: The transactionId a station gave each session, so its events can be matched to it after a restart
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS ocpp_transaction_id VARCHAR(36);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_ocpp_id ON transactions(charge_point_id, ocpp_transaction_id) WHERE ocpp_transaction_id <> '';
@@ -0,0 +1,5 @@
+-- Migration: Transaction EVSE
+-- Created: 2026-10-16
+-- Description: The EVSE each OCPP 2.0.1 session runs on, which its connector ID does not identify
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS evse_id INTEGER NOT NULL DEFAULT 0;
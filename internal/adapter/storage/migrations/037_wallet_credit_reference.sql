@@ -0,0 +1,8 @@
+-- Migration: Wallet credit idempotency
+-- Created: 2026-10-16
+-- Description: One credit per reference, so a payment retried after a failure tops a wallet up once.
+-- Debits may share a reference (a reservation's fee and its no-show penalty).
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_tx_credit_reference
+    ON wallet_transactions(reference_id, type)
+    WHERE type = 'credit' AND reference_id IS NOT NULL AND reference_id <> '';
@@ -0,0 +1,114 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+// alertNode is the stored form of ports.Alert, which has no JSON tags
+type alertNode struct {
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	Severity     string     `json:"severity"`
+	Title        string     `json:"title"`
+	Message      string     `json:"message"`
+	Source       string     `json:"source"`
+	SourceID     string     `json:"source_id"`
+	Acknowledged bool       `json:"acknowledged"`
+	Resolved     bool       `json:"resolved"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type AlertRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewAlertRepository(db *DB, log *zap.Logger) ports.AlertRepository {
+	return &AlertRepository{db: db, log: log}
+}
+
+func (r *AlertRepository) Save(ctx context.Context, alert *ports.Alert) error {
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	node := alertNode(*alert)
+	m, err := ToMap(&node)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "alerts", map[string]interface{}{"id": alert.ID}, m)
+}
+
+func (r *AlertRepository) GetByID(ctx context.Context, id string) (*ports.Alert, error) {
+	m, err := r.db.QueryFirst(ctx, "alerts", " AND n.id = $id", map[string]interface{}{"id": id})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	var node alertNode
+	if err := FromMap(m, &node); err != nil {
+		return nil, err
+	}
+	alert := ports.Alert(node)
+	return &alert, nil
+}
+
+// GetAll returns the alerts with the given acknowledgement, newest first
+func (r *AlertRepository) GetAll(ctx context.Context, acknowledged bool, limit, offset int) ([]ports.Alert, error) {
+	alerts, err := r.find(ctx, " AND n.acknowledged = $ack", map[string]interface{}{"ack": acknowledged})
+	if err != nil {
+		return nil, err
+	}
+	return paginate(alerts, limit, offset), nil
+}
+
+func (r *AlertRepository) Acknowledge(ctx context.Context, id string) error {
+	return r.db.UpdateFields(ctx, "alerts", id, map[string]interface{}{
+		"acknowledged":    true,
+		"acknowledged_at": time.Now().Format(time.RFC3339),
+	})
+}
+
+func (r *AlertRepository) Delete(ctx context.Context, id string) error {
+	return r.db.Tombstone(ctx, "alerts", map[string]interface{}{"id": id})
+}
+
+func (r *AlertRepository) CountUnacknowledged(ctx context.Context) (int, error) {
+	alerts, err := r.find(ctx, " AND n.acknowledged = $ack", map[string]interface{}{"ack": false})
+	return len(alerts), err
+}
+
+func (r *AlertRepository) FindOpen(ctx context.Context, alertType, sourceID string) ([]ports.Alert, error) {
+	return r.find(ctx, " AND n.type = $type AND n.source_id = $sid AND n.resolved = $resolved",
+		map[string]interface{}{"type": alertType, "sid": sourceID, "resolved": false})
+}
+
+func (r *AlertRepository) Resolve(ctx context.Context, id string, resolvedAt time.Time) error {
+	return r.db.UpdateFields(ctx, "alerts", id, map[string]interface{}{
+		"resolved":    true,
+		"resolved_at": resolvedAt.Format(time.RFC3339),
+	})
+}
+
+// find returns the matching alerts, newest first
+func (r *AlertRepository) find(ctx context.Context, where string, params map[string]interface{}) ([]ports.Alert, error) {
+	rows, err := r.db.QueryByLabel(ctx, "alerts", where, params)
+	if err != nil {
+		return nil, err
+	}
+	nodes := fromRows[alertNode](rows)
+	alerts := make([]ports.Alert, len(nodes))
+	for i, node := range nodes {
+		alerts[i] = ports.Alert(node)
+	}
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].CreatedAt.After(alerts[j].CreatedAt) })
+	return alerts, nil
+}
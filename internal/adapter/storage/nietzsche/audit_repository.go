@@ -0,0 +1,65 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type AuditRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewAuditRepository(db *DB, log *zap.Logger) ports.AuditRepository {
+	return &AuditRepository{db: db, log: log}
+}
+
+func (r *AuditRepository) Save(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	m, err := ToMap(entry)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Insert(ctx, "audit_entries", m)
+	return err
+}
+
+func (r *AuditRepository) Find(ctx context.Context, filter ports.AuditFilter, limit, offset int) ([]domain.AuditEntry, int, error) {
+	where := ""
+	params := map[string]interface{}{}
+	for field, value := range map[string]string{
+		"actor_id":    filter.ActorID,
+		"action":      filter.Action,
+		"entity_type": filter.EntityType,
+		"entity_id":   filter.EntityID,
+	} {
+		if value != "" {
+			where += " AND n." + field + " = $" + field
+			params[field] = value
+		}
+	}
+
+	rows, err := r.db.QueryByLabel(ctx, "audit_entries", where, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	var entries []domain.AuditEntry
+	for _, entry := range fromRows[domain.AuditEntry](rows) {
+		if (filter.From.IsZero() || !entry.CreatedAt.Before(filter.From)) &&
+			(filter.To.IsZero() || entry.CreatedAt.Before(filter.To)) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	return paginate(entries, limit, offset), len(entries), nil
+}
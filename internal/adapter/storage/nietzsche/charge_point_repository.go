@@ -5,19 +5,23 @@ package nietzsche
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 	"go.uber.org/zap"
 )
 
 type ChargePointRepository struct {
-	db  *DB
-	log *zap.Logger
+	db      *DB
+	log     *zap.Logger
+	updates updateLock // Serializes compare-and-set
 }
 
-func NewChargePointRepository(db *DB, log *zap.Logger) ports.ChargePointRepository {
-	return &ChargePointRepository{db: db, log: log}
+// NewChargePointRepository creates the repository. locker may be nil.
+func NewChargePointRepository(db *DB, locker ports.Locker, log *zap.Logger) ports.ChargePointRepository {
+	return &ChargePointRepository{db: db, log: log, updates: updateLock{locker: locker, log: log}}
 }
 
 func (r *ChargePointRepository) Save(ctx context.Context, cp *domain.ChargePoint) error {
@@ -38,26 +42,8 @@ func (r *ChargePointRepository) FindByID(ctx context.Context, id string) (*domai
 	if err := FromMap(m, cp); err != nil {
 		return nil, err
 	}
-	// Load connectors
-	connRows, err := r.db.QueryByLabel(ctx, "connectors", " AND n.charge_point_id = $cpid", map[string]interface{}{"cpid": id})
-	if err == nil {
-		for _, cr := range connRows {
-			var c domain.Connector
-			if err := FromMap(cr, &c); err == nil {
-				cp.Connectors = append(cp.Connectors, c)
-			}
-		}
-	}
-	// Load location
-	if cp.LocationID != "" {
-		locM, err := r.db.QueryFirst(ctx, "locations", " AND n.id = $lid", map[string]interface{}{"lid": cp.LocationID})
-		if err == nil && locM != nil {
-			loc := &domain.Location{}
-			if err := FromMap(locM, loc); err == nil {
-				cp.Location = loc
-			}
-		}
-	}
+	r.loadConnectors(ctx, cp)
+	r.loadLocation(ctx, cp)
 	return cp, nil
 }
 
@@ -66,11 +52,16 @@ func (r *ChargePointRepository) FindAll(ctx context.Context, filter map[string]i
 	if err != nil {
 		return nil, err
 	}
+	connectors, _ := filter[ports.ConnectorFilterKey].(domain.ConnectorFilter)
+
 	var result []domain.ChargePoint
 	for _, m := range rows {
 		// Apply filters
 		match := true
 		for k, v := range filter {
+			if k == ports.ConnectorFilterKey {
+				continue // matched once the connectors are loaded
+			}
 			if mv, ok := m[k]; !ok || mv != v {
 				match = false
 				break
@@ -81,6 +72,11 @@ func (r *ChargePointRepository) FindAll(ctx context.Context, filter map[string]i
 		}
 		var cp domain.ChargePoint
 		if err := FromMap(m, &cp); err == nil {
+			r.loadConnectors(ctx, &cp)
+			if !connectors.Matches(&cp) {
+				continue
+			}
+			r.loadLocation(ctx, &cp)
 			result = append(result, cp)
 		}
 	}
@@ -93,7 +89,93 @@ func (r *ChargePointRepository) UpdateStatus(ctx context.Context, id string, sta
 	})
 }
 
-func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error) {
+// UpdateConnectorStatus sets the status of one connector, creating the
+// connector node the first time a charge point reports it
+func (r *ChargePointRepository) UpdateConnectorStatus(ctx context.Context, chargePointID string, connectorID int, status domain.ChargePointStatus) error {
+	_, _, err := r.db.Merge(ctx, "connectors",
+		map[string]interface{}{"charge_point_id": chargePointID, "connector_id": connectorID},
+		map[string]interface{}{
+			"id":              uuid.New().String(),
+			"charge_point_id": chargePointID,
+			"connector_id":    connectorID,
+			"type":            "Type2",
+			"status":          string(status),
+		},
+		map[string]interface{}{"status": string(status)},
+	)
+	return err
+}
+
+// CompareAndSetConnectorStatus sets the connector's status to to if it is from
+func (r *ChargePointRepository) CompareAndSetConnectorStatus(ctx context.Context, chargePointID string, connectorID int, from, to domain.ChargePointStatus) (bool, error) {
+	unlock, err := r.updates.lock(ctx, fmt.Sprintf("connector-status:%s:%d", chargePointID, connectorID))
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	cp, err := r.FindByID(ctx, chargePointID)
+	if err != nil {
+		return false, err
+	}
+	if cp == nil {
+		return false, domain.ErrDeviceNotFound
+	}
+
+	current := cp.Status
+	found := false
+	for _, c := range cp.Connectors {
+		if c.ConnectorID == connectorID {
+			current, found = c.Status, true
+		}
+	}
+	if current != from || (!found && len(cp.Connectors) > 0) {
+		return false, nil
+	}
+	return true, r.UpdateConnectorStatus(ctx, chargePointID, connectorID, to)
+}
+
+func (r *ChargePointRepository) UpdateHeartbeatInterval(ctx context.Context, id string, interval int) error {
+	return r.db.UpdateFields(ctx, "charge_points", id, map[string]interface{}{
+		"heartbeat_interval": interval,
+	})
+}
+
+// UpdateBootInfo reads and replaces the firmware version under a lock, like
+// CompareAndSetConnectorStatus
+func (r *ChargePointRepository) UpdateBootInfo(ctx context.Context, id string, info domain.BootInfo) (string, error) {
+	unlock, err := r.updates.lock(ctx, "boot-info:"+id)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	cp, err := r.FindByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if cp == nil {
+		return "", domain.ErrDeviceNotFound
+	}
+
+	fields := make(map[string]interface{})
+	for field, value := range map[string]string{
+		"vendor":           info.Vendor,
+		"model":            info.Model,
+		"serial_number":    info.SerialNumber,
+		"firmware_version": info.FirmwareVersion,
+	} {
+		if value != "" {
+			fields[field] = value
+		}
+	}
+	if len(fields) == 0 {
+		return cp.FirmwareVersion, nil
+	}
+	return cp.FirmwareVersion, r.db.UpdateFields(ctx, "charge_points", id, fields)
+}
+
+func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
 	// Load all locations, compute Haversine distance, filter by radius
 	locRows, err := r.db.QueryByLabel(ctx, "locations", "", nil)
 	if err != nil {
@@ -125,8 +207,42 @@ func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius
 		}
 		var cp domain.ChargePoint
 		if err := FromMap(m, &cp); err == nil {
+			r.loadConnectors(ctx, &cp)
+			if !connectors.Matches(&cp) {
+				continue
+			}
+			r.loadLocation(ctx, &cp)
 			result = append(result, cp)
 		}
 	}
 	return result, nil
 }
+
+// loadConnectors attaches the connector nodes of a charge point
+func (r *ChargePointRepository) loadConnectors(ctx context.Context, cp *domain.ChargePoint) {
+	connRows, err := r.db.QueryByLabel(ctx, "connectors", " AND n.charge_point_id = $cpid", map[string]interface{}{"cpid": cp.ID})
+	if err != nil {
+		r.log.Warn("Failed to load connectors", zap.String("charge_point_id", cp.ID), zap.Error(err))
+		return
+	}
+	for _, cr := range connRows {
+		var c domain.Connector
+		if err := FromMap(cr, &c); err == nil {
+			cp.Connectors = append(cp.Connectors, c)
+		}
+	}
+}
+
+// loadLocation attaches the location node of a charge point
+func (r *ChargePointRepository) loadLocation(ctx context.Context, cp *domain.ChargePoint) {
+	if cp.LocationID == "" {
+		return
+	}
+	locM, err := r.db.QueryFirst(ctx, "locations", " AND n.id = $lid", map[string]interface{}{"lid": cp.LocationID})
+	if err == nil && locM != nil {
+		loc := &domain.Location{}
+		if err := FromMap(locM, loc); err == nil {
+			cp.Location = loc
+		}
+	}
+}
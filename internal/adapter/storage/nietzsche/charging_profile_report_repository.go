@@ -0,0 +1,44 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type ChargingProfileReportRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewChargingProfileReportRepository(db *DB, log *zap.Logger) ports.ChargingProfileReportRepository {
+	return &ChargingProfileReportRepository{db: db, log: log}
+}
+
+func (r *ChargingProfileReportRepository) Save(ctx context.Context, report *domain.ChargingProfileReport) error {
+	m, err := ToMap(report)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "charging_profile_reports",
+		map[string]interface{}{"charge_point_id": report.ChargePointID}, m)
+}
+
+func (r *ChargingProfileReportRepository) GetByChargePointID(ctx context.Context, chargePointID string) (*domain.ChargingProfileReport, error) {
+	m, err := r.db.QueryFirst(ctx, "charging_profile_reports",
+		" AND n.charge_point_id = $cpid",
+		map[string]interface{}{"cpid": chargePointID})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	report := &domain.ChargingProfileReport{}
+	if err := FromMap(m, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
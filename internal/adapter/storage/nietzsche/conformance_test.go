@@ -0,0 +1,28 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"os"
+	"testing"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/storagetest"
+	"go.uber.org/zap"
+)
+
+// TestConformance runs against the NietzscheDB given in SIGEC_TEST_NIETZSCHE_ADDR
+func TestConformance(t *testing.T) {
+	addr := os.Getenv("SIGEC_TEST_NIETZSCHE_ADDR")
+	if addr == "" {
+		t.Skip("SIGEC_TEST_NIETZSCHE_ADDR not set")
+	}
+	db, err := NewConnection(addr, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	repos := NewRepositories(db, nil, zap.NewNop())
+	defer repos.Close()
+
+	storagetest.Run(t, repos)
+}
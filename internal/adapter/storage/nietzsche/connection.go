@@ -60,7 +60,9 @@ func (db *DB) QueryByLabel(ctx context.Context, label string, extraWhere string,
 	}
 	rows := make([]map[string]interface{}, 0, len(result.Nodes))
 	for _, n := range result.Nodes {
-		rows = append(rows, n.Content)
+		if !GetBool(n.Content, deletedField) {
+			rows = append(rows, n.Content)
+		}
 	}
 	return rows, nil
 }
@@ -76,7 +78,7 @@ func (db *DB) QueryFirst(ctx context.Context, label string, extraWhere string, p
 	if err != nil {
 		return nil, err
 	}
-	if len(result.Nodes) == 0 {
+	if len(result.Nodes) == 0 || GetBool(result.Nodes[0].Content, deletedField) {
 		return nil, nil
 	}
 	return result.Nodes[0].Content, nil
@@ -139,6 +141,38 @@ func (db *DB) UpdateFields(ctx context.Context, label string, id string, fields
 	return err
 }
 
+// Upsert creates the node matching matchKeys with content, or overwrites
+// the content of the existing one.
+func (db *DB) Upsert(ctx context.Context, label string, matchKeys, content map[string]interface{}) error {
+	onMatch := map[string]interface{}{deletedField: false} // Saving again undeletes
+	for k, v := range content {
+		onMatch[k] = v
+	}
+	_, _, err := db.Merge(ctx, label, withLabel(label, matchKeys), content, onMatch)
+	return err
+}
+
+// deletedField marks tombstoned nodes, which the query helpers skip
+const deletedField = "deleted"
+
+// Tombstone deletes the node matching matchKeys by marking it, since nodes
+// are only removable by their NietzscheDB node ID.
+func (db *DB) Tombstone(ctx context.Context, label string, matchKeys map[string]interface{}) error {
+	_, _, err := db.Merge(ctx, label, withLabel(label, matchKeys),
+		map[string]interface{}{deletedField: true},
+		map[string]interface{}{deletedField: true})
+	return err
+}
+
+// withLabel returns matchKeys restricted to nodes of label
+func withLabel(label string, matchKeys map[string]interface{}) map[string]interface{} {
+	keys := map[string]interface{}{"node_label": label}
+	for k, v := range matchKeys {
+		keys[k] = v
+	}
+	return keys
+}
+
 // DeleteNode removes a node by its NietzscheDB node ID.
 func (db *DB) DeleteNode(ctx context.Context, nodeID string) error {
 	return db.Client.DeleteNode(ctx, nodeID, db.Collection)
@@ -259,3 +293,27 @@ func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
 	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
 	return R * c
 }
+
+// fromRows decodes content maps, skipping those that do not decode
+func fromRows[T any](rows []map[string]interface{}) []T {
+	items := make([]T, 0, len(rows))
+	for _, m := range rows {
+		var item T
+		if err := FromMap(m, &item); err == nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// paginate returns items[offset:offset+limit]; a limit <= 0 means no limit
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type ConnectionEventRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewConnectionEventRepository(db *DB, log *zap.Logger) ports.ConnectionEventRepository {
+	return &ConnectionEventRepository{db: db, log: log}
+}
+
+func (r *ConnectionEventRepository) Save(ctx context.Context, event *domain.ConnectionEvent) error {
+	m, err := ToMap(event)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Insert(ctx, "connection_events", m)
+	return err
+}
+
+func (r *ConnectionEventRepository) FindByChargePointID(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.ConnectionEvent, error) {
+	all, err := r.history(ctx, chargePointID)
+	if err != nil {
+		return nil, err
+	}
+	var events []domain.ConnectionEvent
+	for _, event := range all {
+		if !event.Timestamp.Before(from) && event.Timestamp.Before(to) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *ConnectionEventRepository) FindLastBefore(ctx context.Context, chargePointID string, t time.Time) (*domain.ConnectionEvent, error) {
+	events, err := r.history(ctx, chargePointID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Timestamp.Before(t) {
+			return &events[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// history returns a charge point's events oldest first; at the same instant
+// a reconnect's disconnect comes first
+func (r *ConnectionEventRepository) history(ctx context.Context, chargePointID string) ([]domain.ConnectionEvent, error) {
+	rows, err := r.db.QueryByLabel(ctx, "connection_events",
+		" AND n.charge_point_id = $cpid",
+		map[string]interface{}{"cpid": chargePointID})
+	if err != nil {
+		return nil, err
+	}
+	events := fromRows[domain.ConnectionEvent](rows)
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Timestamp.Equal(events[j].Timestamp) {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		}
+		return events[i].Type == domain.ConnectionEventDisconnected && events[j].Type == domain.ConnectionEventConnected
+	})
+	return events, nil
+}
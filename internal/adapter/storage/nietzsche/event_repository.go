@@ -0,0 +1,128 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+// eventSeqLabel labels the single node holding the highest stored sequence,
+// so the sequence is loaded without scanning the log
+const eventSeqLabel = "domain_event_seq"
+
+type EventRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewEventRepository(db *DB, log *zap.Logger) ports.EventRepository {
+	return &EventRepository{db: db, log: log}
+}
+
+func (r *EventRepository) Append(ctx context.Context, event *domain.Event) error {
+	m, err := ToMap(event)
+	if err != nil {
+		return err
+	}
+	// A MERGE on the sequence claims it atomically, so two servers never
+	// store the same one
+	_, created, err := r.db.Merge(ctx, "domain_events", withLabel("domain_events", map[string]interface{}{
+		"seq": event.Seq,
+	}), m, nil)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return domain.ErrConflict
+	}
+	r.recordSeq(ctx, event.Seq)
+	return nil
+}
+
+// eventSeqKeys matches the sequence counter node
+func eventSeqKeys() map[string]interface{} {
+	return withLabel(eventSeqLabel, map[string]interface{}{"name": "last"})
+}
+
+// recordSeq stores seq as the highest sequence. A failure only costs a
+// longer LastSeq, which steps past a stale counter.
+func (r *EventRepository) recordSeq(ctx context.Context, seq int64) {
+	fields := map[string]interface{}{"seq": seq}
+	if _, _, err := r.db.Merge(ctx, eventSeqLabel, eventSeqKeys(), fields, map[string]interface{}{"seq": seq}); err != nil {
+		r.log.Warn("Failed to record event sequence", zap.Int64("seq", seq), zap.Error(err))
+	}
+}
+
+func (r *EventRepository) FindByAggregateID(ctx context.Context, aggregateID string, sinceSeq int64) ([]domain.Event, error) {
+	rows, err := r.db.QueryByLabel(ctx, "domain_events",
+		" AND n.aggregate_id = $aid AND n.seq > $since",
+		map[string]interface{}{"aid": aggregateID, "since": sinceSeq})
+	if err != nil {
+		return nil, err
+	}
+	var events []domain.Event
+	for _, m := range rows {
+		var event domain.Event
+		if err := FromMap(m, &event); err == nil {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Seq < events[j].Seq
+	})
+	return events, nil
+}
+
+// LastSeq reads the sequence counter and steps past any events stored after
+// it was last written, e.g. by a server that stopped in between. Logs written
+// before the counter existed are scanned once to create it.
+func (r *EventRepository) LastSeq(ctx context.Context) (int64, error) {
+	counter, err := r.db.QueryFirst(ctx, eventSeqLabel, " AND n.name = $name", map[string]interface{}{"name": "last"})
+	if err != nil {
+		return 0, err
+	}
+	if counter == nil {
+		last, err := r.scanLastSeq(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if last > 0 {
+			r.recordSeq(ctx, last)
+		}
+		return last, nil
+	}
+
+	last := int64(GetFloat64(counter, "seq"))
+	for {
+		next, err := r.db.QueryFirst(ctx, "domain_events", " AND n.seq = $seq", map[string]interface{}{"seq": last + 1})
+		if err != nil {
+			return 0, err
+		}
+		if next == nil {
+			return last, nil
+		}
+		last++
+	}
+}
+
+// scanLastSeq finds the highest sequence by reading the whole log
+func (r *EventRepository) scanLastSeq(ctx context.Context) (int64, error) {
+	rows, err := r.db.QueryByLabel(ctx, "domain_events", "", nil)
+	if err != nil {
+		return 0, err
+	}
+	var last int64
+	for _, m := range rows {
+		var event domain.Event
+		if err := FromMap(m, &event); err == nil && event.Seq > last {
+			last = event.Seq
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type FirmwarePublicationRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewFirmwarePublicationRepository(db *DB, log *zap.Logger) ports.FirmwarePublicationRepository {
+	return &FirmwarePublicationRepository{db: db, log: log}
+}
+
+func (r *FirmwarePublicationRepository) Save(ctx context.Context, pub *domain.FirmwarePublication) error {
+	now := time.Now()
+	if pub.CreatedAt.IsZero() {
+		pub.CreatedAt = now
+	}
+	pub.UpdatedAt = now
+
+	m, err := ToMap(pub)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Insert(ctx, "firmware_publications", m)
+	return err
+}
+
+func (r *FirmwarePublicationRepository) Update(ctx context.Context, pub *domain.FirmwarePublication) error {
+	m, err := ToMap(pub)
+	if err != nil {
+		return err
+	}
+	delete(m, "id")
+	delete(m, "node_label")
+	delete(m, "created_at")
+	return r.db.UpdateFields(ctx, "firmware_publications", pub.ID, m)
+}
+
+func (r *FirmwarePublicationRepository) FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.FirmwarePublication, error) {
+	m, err := r.db.QueryFirst(ctx, "firmware_publications",
+		" AND n.charge_point_id = $cpid AND n.request_id = $rid",
+		map[string]interface{}{"cpid": chargePointID, "rid": requestID})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	pub := &domain.FirmwarePublication{}
+	if err := FromMap(m, pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+func (r *FirmwarePublicationRepository) FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.FirmwarePublication, error) {
+	rows, err := r.db.QueryByLabel(ctx, "firmware_publications",
+		" AND n.charge_point_id = $cpid",
+		map[string]interface{}{"cpid": chargePointID})
+	if err != nil {
+		return nil, err
+	}
+	pubs := fromRows[domain.FirmwarePublication](rows)
+	sort.Slice(pubs, func(i, j int) bool {
+		return pubs[i].CreatedAt.After(pubs[j].CreatedAt)
+	})
+	return paginate(pubs, limit, 0), nil
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type DeviceInventoryRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewDeviceInventoryRepository(db *DB, log *zap.Logger) ports.DeviceInventoryRepository {
+	return &DeviceInventoryRepository{db: db, log: log}
+}
+
+func (r *DeviceInventoryRepository) Save(ctx context.Context, inventory *domain.DeviceInventory) error {
+	onCreate, err := ToMap(inventory)
+	if err != nil {
+		return err
+	}
+	onMatch, err := ToMap(inventory)
+	if err != nil {
+		return err
+	}
+	// A new report fully replaces the previous inventory
+	_, _, err = r.db.Merge(ctx, "device_inventories",
+		map[string]interface{}{"charge_point_id": inventory.ChargePointID, "node_label": "device_inventories"},
+		onCreate, onMatch,
+	)
+	return err
+}
+
+func (r *DeviceInventoryRepository) GetByChargePointID(ctx context.Context, chargePointID string) (*domain.DeviceInventory, error) {
+	m, err := r.db.QueryFirst(ctx, "device_inventories", " AND n.charge_point_id = $cpid", map[string]interface{}{"cpid": chargePointID})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	inventory := &domain.DeviceInventory{}
+	if err := FromMap(m, inventory); err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+const (
+	// updateLockTTL bounds how long a server that died mid-update blocks others
+	updateLockTTL = 10 * time.Second
+	// updateLockPoll is how often a held lock is retried
+	updateLockPoll = 20 * time.Millisecond
+)
+
+// updateLock serializes read-modify-write updates, since NietzscheDB has no
+// conditional update. With a shared locker it holds across every server
+// sharing it; without one, within this server process only.
+type updateLock struct {
+	locker ports.Locker // optional
+	mu     sync.Mutex
+	log    *zap.Logger
+}
+
+// lock waits for key and returns the function releasing it
+func (l *updateLock) lock(ctx context.Context, key string) (func(), error) {
+	if l.locker == nil {
+		l.mu.Lock()
+		return l.mu.Unlock, nil
+	}
+
+	for {
+		held, err := l.locker.TryLock(ctx, key, updateLockTTL)
+		if err == nil {
+			return func() {
+				if err := held.Release(context.Background()); err != nil {
+					l.log.Warn("Failed to release lock", zap.String("key", key), zap.Error(err))
+				}
+			}, nil
+		}
+		if !errors.Is(err, ports.ErrLockHeld) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(updateLockPoll):
+		}
+	}
+}
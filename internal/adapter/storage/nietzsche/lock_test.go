@@ -0,0 +1,77 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
+	"go.uber.org/zap"
+)
+
+func TestUpdateLock_SharedAcrossServers(t *testing.T) {
+	// Arrange - two servers share one locker
+	locker := cache.NewLocalLocker()
+	first := &updateLock{locker: locker, log: zap.NewNop()}
+	second := &updateLock{locker: locker, log: zap.NewNop()}
+
+	unlock, err := first.lock(context.Background(), "connector-status:CP-001:1")
+	if err != nil {
+		t.Fatalf("failed to lock: %v", err)
+	}
+
+	// Act - the second server waits while the first holds the connector
+	acquired := make(chan func())
+	go func() {
+		unlockSecond, err := second.lock(context.Background(), "connector-status:CP-001:1")
+		if err != nil {
+			t.Errorf("failed to lock: %v", err)
+			close(acquired)
+			return
+		}
+		acquired <- unlockSecond
+	}()
+
+	// Assert
+	select {
+	case <-acquired:
+		t.Fatal("expected the second server to wait for the lock")
+	case <-time.After(5 * updateLockPoll):
+	}
+
+	unlock()
+	select {
+	case unlockSecond := <-acquired:
+		if unlockSecond != nil {
+			unlockSecond()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the second server to get the lock once released")
+	}
+}
+
+func TestUpdateLock_GivesUpWithContext(t *testing.T) {
+	// Arrange
+	locker := cache.NewLocalLocker()
+	updates := &updateLock{locker: locker, log: zap.NewNop()}
+	unlock, err := updates.lock(context.Background(), "boot-info:CP-001")
+	if err != nil {
+		t.Fatalf("failed to lock: %v", err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*updateLockPoll)
+	defer cancel()
+
+	// Act
+	_, err = updates.lock(ctx, "boot-info:CP-001")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to end the wait, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type LogRequestRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewLogRequestRepository(db *DB, log *zap.Logger) ports.LogRequestRepository {
+	return &LogRequestRepository{db: db, log: log}
+}
+
+func (r *LogRequestRepository) Save(ctx context.Context, req *domain.LogRequest) error {
+	m, err := ToMap(req)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Insert(ctx, "log_requests", m)
+	return err
+}
+
+func (r *LogRequestRepository) Update(ctx context.Context, req *domain.LogRequest) error {
+	m, err := ToMap(req)
+	if err != nil {
+		return err
+	}
+	delete(m, "id")
+	delete(m, "node_label")
+	delete(m, "created_at")
+	return r.db.UpdateFields(ctx, "log_requests", req.ID, m)
+}
+
+func (r *LogRequestRepository) FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.LogRequest, error) {
+	m, err := r.db.QueryFirst(ctx, "log_requests",
+		" AND n.charge_point_id = $cpid AND n.request_id = $rid",
+		map[string]interface{}{"cpid": chargePointID, "rid": requestID})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	req := &domain.LogRequest{}
+	if err := FromMap(m, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (r *LogRequestRepository) FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.LogRequest, error) {
+	rows, err := r.db.QueryByLabel(ctx, "log_requests",
+		" AND n.charge_point_id = $cpid",
+		map[string]interface{}{"cpid": chargePointID})
+	if err != nil {
+		return nil, err
+	}
+	var reqs []domain.LogRequest
+	for _, m := range rows {
+		var req domain.LogRequest
+		if err := FromMap(m, &req); err == nil {
+			reqs = append(reqs, req)
+		}
+	}
+	sort.Slice(reqs, func(i, j int) bool {
+		return reqs[i].CreatedAt.After(reqs[j].CreatedAt)
+	})
+
+	if limit > 0 && limit < len(reqs) {
+		reqs = reqs[:limit]
+	}
+	return reqs, nil
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+// meterSeries is the node holding every sample of one transaction, so a
+// session's series is read and replaced as a whole
+type meterSeries struct {
+	TransactionID string               `json:"transaction_id"`
+	Samples       []domain.MeterSample `json:"samples"`
+}
+
+type MeterValueRepository struct {
+	db  *DB
+	log *zap.Logger
+
+	// Appending reads and rewrites a series, so writers are serialized
+	// here. They are atomic within one server process only.
+	seriesMu sync.Mutex
+}
+
+func NewMeterValueRepository(db *DB, log *zap.Logger) ports.MeterValueRepository {
+	return &MeterValueRepository{db: db, log: log}
+}
+
+func (r *MeterValueRepository) Save(ctx context.Context, samples []domain.MeterSample) error {
+	byTx := make(map[string][]domain.MeterSample)
+	for _, sample := range samples {
+		byTx[sample.TransactionID] = append(byTx[sample.TransactionID], sample)
+	}
+
+	r.seriesMu.Lock()
+	defer r.seriesMu.Unlock()
+	for txID, added := range byTx {
+		series, err := r.find(ctx, txID)
+		if err != nil {
+			return err
+		}
+		if err := r.write(ctx, txID, append(series, added...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MeterValueRepository) FindByTransactionID(ctx context.Context, transactionID string) ([]domain.MeterSample, error) {
+	return r.find(ctx, transactionID)
+}
+
+func (r *MeterValueRepository) ReplaceByTransactionID(ctx context.Context, transactionID string, samples []domain.MeterSample) error {
+	r.seriesMu.Lock()
+	defer r.seriesMu.Unlock()
+	return r.write(ctx, transactionID, append([]domain.MeterSample(nil), samples...))
+}
+
+func (r *MeterValueRepository) DeleteBefore(ctx context.Context, t time.Time) (int, error) {
+	r.seriesMu.Lock()
+	defer r.seriesMu.Unlock()
+
+	rows, err := r.db.QueryByLabel(ctx, "meter_series", "", nil)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, series := range fromRows[meterSeries](rows) {
+		kept := series.Samples[:0]
+		for _, sample := range series.Samples {
+			if sample.Timestamp.Before(t) {
+				continue
+			}
+			kept = append(kept, sample)
+		}
+		if len(kept) == len(series.Samples) {
+			continue
+		}
+		if err := r.write(ctx, series.TransactionID, kept); err != nil {
+			return deleted, err
+		}
+		deleted += len(series.Samples) - len(kept)
+	}
+	return deleted, nil
+}
+
+func (r *MeterValueRepository) find(ctx context.Context, transactionID string) ([]domain.MeterSample, error) {
+	m, err := r.db.QueryFirst(ctx, "meter_series",
+		" AND n.transaction_id = $tid",
+		map[string]interface{}{"tid": transactionID})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	var series meterSeries
+	if err := FromMap(m, &series); err != nil {
+		return nil, err
+	}
+	return series.Samples, nil
+}
+
+// write stores samples, oldest first, as the series of a transaction. An
+// empty series deletes it.
+func (r *MeterValueRepository) write(ctx context.Context, transactionID string, samples []domain.MeterSample) error {
+	keys := map[string]interface{}{"transaction_id": transactionID}
+	if len(samples) == 0 {
+		return r.db.Tombstone(ctx, "meter_series", keys)
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Timestamp.Before(samples[j].Timestamp) })
+	m, err := ToMap(&meterSeries{TransactionID: transactionID, Samples: samples})
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "meter_series", keys, m)
+}
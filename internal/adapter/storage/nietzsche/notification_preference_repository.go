@@ -0,0 +1,45 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type NotificationPreferenceRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewNotificationPreferenceRepository(db *DB, log *zap.Logger) ports.NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db, log: log}
+}
+
+func (r *NotificationPreferenceRepository) Save(ctx context.Context, prefs *domain.NotificationPreferences) error {
+	prefs.UpdatedAt = time.Now()
+	m, err := ToMap(prefs)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "notification_preferences", map[string]interface{}{"user_id": prefs.UserID}, m)
+}
+
+func (r *NotificationPreferenceRepository) FindByUserID(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	m, err := r.db.QueryFirst(ctx, "notification_preferences",
+		" AND n.user_id = $uid",
+		map[string]interface{}{"uid": userID})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	prefs := &domain.NotificationPreferences{}
+	if err := FromMap(m, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type PaymentRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewPaymentRepository(db *DB, log *zap.Logger) ports.PaymentRepository {
+	return &PaymentRepository{db: db, log: log}
+}
+
+func (r *PaymentRepository) SavePayment(ctx context.Context, payment *domain.Payment) error {
+	now := time.Now()
+	if payment.CreatedAt.IsZero() {
+		payment.CreatedAt = now
+	}
+	payment.UpdatedAt = now
+
+	m, err := ToMap(payment)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "payments", map[string]interface{}{"id": payment.ID}, m)
+}
+
+func (r *PaymentRepository) GetPayment(ctx context.Context, id string) (*domain.Payment, error) {
+	return r.first(ctx, " AND n.id = $id", map[string]interface{}{"id": id})
+}
+
+func (r *PaymentRepository) GetPaymentByProviderID(ctx context.Context, providerID string) (*domain.Payment, error) {
+	return r.first(ctx, " AND n.provider_id = $pid", map[string]interface{}{"pid": providerID})
+}
+
+func (r *PaymentRepository) first(ctx context.Context, where string, params map[string]interface{}) (*domain.Payment, error) {
+	m, err := r.db.QueryFirst(ctx, "payments", where, params)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	payment := &domain.Payment{}
+	if err := FromMap(m, payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetPaymentsByUser returns a user's payments, newest first
+func (r *PaymentRepository) GetPaymentsByUser(ctx context.Context, userID string, limit, offset int) ([]domain.Payment, error) {
+	payments, err := r.find(ctx, " AND n.user_id = $uid", map[string]interface{}{"uid": userID})
+	if err != nil {
+		return nil, err
+	}
+	return paginate(payments, limit, offset), nil
+}
+
+// GetPaymentsByTransaction returns the payments of a charging transaction, newest first
+func (r *PaymentRepository) GetPaymentsByTransaction(ctx context.Context, transactionID string) ([]domain.Payment, error) {
+	return r.find(ctx, " AND n.transaction_id = $tid", map[string]interface{}{"tid": transactionID})
+}
+
+func (r *PaymentRepository) find(ctx context.Context, where string, params map[string]interface{}) ([]domain.Payment, error) {
+	rows, err := r.db.QueryByLabel(ctx, "payments", where, params)
+	if err != nil {
+		return nil, err
+	}
+	payments := fromRows[domain.Payment](rows)
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].CreatedAt.After(payments[j].CreatedAt)
+	})
+	return payments, nil
+}
+
+func (r *PaymentRepository) SaveRefund(ctx context.Context, refund *domain.Refund) error {
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = time.Now()
+	}
+	m, err := ToMap(refund)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "refunds", map[string]interface{}{"id": refund.ID}, m)
+}
+
+// GetRefundsByPayment returns the refunds of a payment, oldest first
+func (r *PaymentRepository) GetRefundsByPayment(ctx context.Context, paymentID string) ([]domain.Refund, error) {
+	rows, err := r.db.QueryByLabel(ctx, "refunds",
+		" AND n.payment_id = $pid",
+		map[string]interface{}{"pid": paymentID})
+	if err != nil {
+		return nil, err
+	}
+	refunds := fromRows[domain.Refund](rows)
+	sort.Slice(refunds, func(i, j int) bool {
+		return refunds[i].CreatedAt.Before(refunds[j].CreatedAt)
+	})
+	return refunds, nil
+}
+
+// ClaimWebhookEvent claims the event with a MERGE, which NietzscheDB applies
+// atomically, so of concurrent deliveries to any server only one creates it
+func (r *PaymentRepository) ClaimWebhookEvent(ctx context.Context, provider, eventID string) (bool, error) {
+	_, created, err := r.db.Merge(ctx, "payment_webhook_events", webhookEventKeys(provider, eventID),
+		map[string]interface{}{
+			"provider":     provider,
+			"event_id":     eventID,
+			"processed_at": time.Now().Format(time.RFC3339),
+		}, nil)
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// ReleaseWebhookEvent deletes the claim, so the next MERGE creates it again
+func (r *PaymentRepository) ReleaseWebhookEvent(ctx context.Context, provider, eventID string) error {
+	nodeID, created, err := r.db.Merge(ctx, "payment_webhook_events", webhookEventKeys(provider, eventID), nil, nil)
+	if err != nil {
+		return err
+	}
+	if created {
+		r.log.Debug("Released webhook event was not claimed",
+			zap.String("provider", provider),
+			zap.String("event_id", eventID),
+		)
+	}
+	return r.db.DeleteNode(ctx, nodeID)
+}
+
+func webhookEventKeys(provider, eventID string) map[string]interface{} {
+	return withLabel("payment_webhook_events", map[string]interface{}{"provider": provider, "event_id": eventID})
+}
+
+type CardRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewCardRepository(db *DB, log *zap.Logger) ports.CardRepository {
+	return &CardRepository{db: db, log: log}
+}
+
+func (r *CardRepository) Save(ctx context.Context, card *domain.PaymentCard) error {
+	if card.CreatedAt.IsZero() {
+		card.CreatedAt = time.Now()
+	}
+	m, err := ToMap(card)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "payment_cards", map[string]interface{}{"id": card.ID}, m)
+}
+
+func (r *CardRepository) GetByID(ctx context.Context, id string) (*domain.PaymentCard, error) {
+	m, err := r.db.QueryFirst(ctx, "payment_cards", " AND n.id = $id", map[string]interface{}{"id": id})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	card := &domain.PaymentCard{}
+	if err := FromMap(m, card); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// GetByUserID returns a user's cards, the default one first
+func (r *CardRepository) GetByUserID(ctx context.Context, userID string) ([]domain.PaymentCard, error) {
+	rows, err := r.db.QueryByLabel(ctx, "payment_cards",
+		" AND n.user_id = $uid",
+		map[string]interface{}{"uid": userID})
+	if err != nil {
+		return nil, err
+	}
+	cards := fromRows[domain.PaymentCard](rows)
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].IsDefault != cards[j].IsDefault {
+			return cards[i].IsDefault
+		}
+		return cards[i].CreatedAt.Before(cards[j].CreatedAt)
+	})
+	return cards, nil
+}
+
+func (r *CardRepository) Delete(ctx context.Context, id string) error {
+	return r.db.Tombstone(ctx, "payment_cards", map[string]interface{}{"id": id})
+}
+
+// SetDefault makes cardID the user's only default card
+func (r *CardRepository) SetDefault(ctx context.Context, userID, cardID string) error {
+	cards, err := r.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, card := range cards {
+		if isDefault := card.ID == cardID; card.IsDefault != isDefault {
+			if err := r.db.UpdateFields(ctx, "payment_cards", card.ID, map[string]interface{}{
+				"is_default": isDefault,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type PendingCommandRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewPendingCommandRepository(db *DB, log *zap.Logger) ports.PendingCommandRepository {
+	return &PendingCommandRepository{db: db, log: log}
+}
+
+func (r *PendingCommandRepository) Save(ctx context.Context, cmd *domain.PendingCommand) error {
+	if cmd.CreatedAt.IsZero() {
+		cmd.CreatedAt = time.Now()
+	}
+	m, err := ToMap(cmd)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "pending_commands", map[string]interface{}{"message_id": cmd.MessageID}, m)
+}
+
+func (r *PendingCommandRepository) Delete(ctx context.Context, messageID string) error {
+	return r.db.Tombstone(ctx, "pending_commands", map[string]interface{}{"message_id": messageID})
+}
+
+func (r *PendingCommandRepository) FindUnexpired(ctx context.Context, now time.Time) ([]domain.PendingCommand, error) {
+	commands, err := r.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var unexpired []domain.PendingCommand
+	for _, cmd := range commands {
+		if cmd.ExpiresAt.After(now) {
+			unexpired = append(unexpired, cmd)
+		}
+	}
+	sort.Slice(unexpired, func(i, j int) bool { return unexpired[i].CreatedAt.Before(unexpired[j].CreatedAt) })
+	return unexpired, nil
+}
+
+func (r *PendingCommandRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	commands, err := r.all(ctx)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		if !cmd.ExpiresAt.After(now) {
+			if err := r.Delete(ctx, cmd.MessageID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *PendingCommandRepository) all(ctx context.Context) ([]domain.PendingCommand, error) {
+	rows, err := r.db.QueryByLabel(ctx, "pending_commands", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return fromRows[domain.PendingCommand](rows), nil
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type ReconciliationRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewReconciliationRepository(db *DB, log *zap.Logger) ports.ReconciliationRepository {
+	return &ReconciliationRepository{db: db, log: log}
+}
+
+func (r *ReconciliationRepository) Save(ctx context.Context, rec *domain.Reconciliation) error {
+	m, err := ToMap(rec)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Insert(ctx, "reconciliations", m)
+	return err
+}
+
+func (r *ReconciliationRepository) FindByTransactionID(ctx context.Context, transactionID string) (*domain.Reconciliation, error) {
+	rows, err := r.db.QueryByLabel(ctx, "reconciliations",
+		" AND n.transaction_id = $txid",
+		map[string]interface{}{"txid": transactionID})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	rec := &domain.Reconciliation{}
+	if err := FromMap(rows[0], rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
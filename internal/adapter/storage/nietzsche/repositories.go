@@ -0,0 +1,49 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+// NewRepositories builds every repository this backend implements on db.
+// locker, which may be nil, makes compare-and-set and balance changes atomic
+// across servers.
+// Closing the set closes db.
+func NewRepositories(db *DB, locker ports.Locker, log *zap.Logger) *ports.Repositories {
+	return &ports.Repositories{
+		ChargePoints:         NewChargePointRepository(db, locker, log),
+		Transactions:         NewTransactionRepository(db, log),
+		Users:                NewUserRepository(db, log),
+		Payments:             NewPaymentRepository(db, log),
+		Cards:                NewCardRepository(db, log),
+		Wallets:              NewWalletRepository(db, locker, log),
+		Reservations:         NewReservationRepository(db, log),
+		MaintenanceWindows:   NewMaintenanceWindowRepository(db, log),
+		Inventory:            NewDeviceInventoryRepository(db, log),
+		MeterValues:          NewMeterValueRepository(db, log),
+		ChargingProfiles:     NewChargingProfileReportRepository(db, log),
+		LogRequests:          NewLogRequestRepository(db, log),
+		FirmwarePublications: NewFirmwarePublicationRepository(db, log),
+		ConnectionEvents:     NewConnectionEventRepository(db, log),
+		StationCertificates:  NewStationCertificateRepository(db, log),
+		Reconciliations:      NewReconciliationRepository(db, log),
+		Alerts:               NewAlertRepository(db, log),
+		Events:               NewEventRepository(db, log),
+		VoiceCommands:        NewVoiceCommandRepository(db, log),
+		V2G:                  NewV2GRepository(db, log),
+		ISO15118:             NewISO15118Repository(db, log),
+		Webhooks:             NewWebhookRepository(db, log),
+		Audit:                NewAuditRepository(db, log),
+		PendingCommands:      NewPendingCommandRepository(db, log),
+		NotificationPrefs:    NewNotificationPreferenceRepository(db, log),
+		Closer:               db.Close,
+		Ping: func(ctx context.Context) error {
+			return db.Client.HealthCheck(ctx)
+		},
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type ReservationRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewReservationRepository(db *DB, log *zap.Logger) ports.ReservationRepository {
+	return &ReservationRepository{db: db, log: log}
+}
+
+func (r *ReservationRepository) Save(ctx context.Context, reservation *domain.Reservation) error {
+	now := time.Now()
+	if reservation.CreatedAt.IsZero() {
+		reservation.CreatedAt = now
+	}
+	reservation.UpdatedAt = now
+
+	stored := *reservation
+	stored.User, stored.ChargePoint = nil, nil // Associations are not stored
+	m, err := ToMap(&stored)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "reservations", map[string]interface{}{"id": reservation.ID}, m)
+}
+
+func (r *ReservationRepository) GetByID(ctx context.Context, id string) (*domain.Reservation, error) {
+	m, err := r.db.QueryFirst(ctx, "reservations", " AND n.id = $id", map[string]interface{}{"id": id})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	reservation := &domain.Reservation{}
+	if err := FromMap(m, reservation); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// GetByUserID returns a user's reservations, newest first, optionally by status
+func (r *ReservationRepository) GetByUserID(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error) {
+	where := " AND n.user_id = $uid"
+	params := map[string]interface{}{"uid": userID}
+	if status != "" {
+		where += " AND n.status = $st"
+		params["st"] = status
+	}
+	reservations, err := r.find(ctx, where, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.After(reservations[j].StartTime)
+	})
+	return paginate(reservations, limit, offset), nil
+}
+
+// GetByChargePointID returns the reservations of a charge point starting on
+// the day of date
+func (r *ReservationRepository) GetByChargePointID(ctx context.Context, chargePointID string, date time.Time) ([]domain.Reservation, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+	return r.find(ctx, " AND n.charge_point_id = $cpid",
+		map[string]interface{}{"cpid": chargePointID},
+		func(res *domain.Reservation) bool {
+			return !res.StartTime.Before(startOfDay) && res.StartTime.Before(endOfDay)
+		})
+}
+
+// GetByTimeRange returns the reservations holding a connector at any time in
+// [startTime, endTime)
+func (r *ReservationRepository) GetByTimeRange(ctx context.Context, chargePointID string, connectorID int, startTime, endTime time.Time) ([]domain.Reservation, error) {
+	return r.find(ctx, " AND n.charge_point_id = $cpid AND n.connector_id = $conn",
+		map[string]interface{}{"cpid": chargePointID, "conn": connectorID},
+		func(res *domain.Reservation) bool {
+			return holdsConnector(res) && res.StartTime.Before(endTime) && res.EndTime.After(startTime)
+		})
+}
+
+func (r *ReservationRepository) GetActiveByUserID(ctx context.Context, userID string) ([]domain.Reservation, error) {
+	return r.find(ctx, " AND n.user_id = $uid", map[string]interface{}{"uid": userID}, holdsConnector)
+}
+
+func (r *ReservationRepository) GetExpired(ctx context.Context, gracePeriod time.Duration) ([]domain.Reservation, error) {
+	return r.find(ctx, " AND n.status = $st",
+		map[string]interface{}{"st": string(domain.ReservationStatusConfirmed)},
+		func(res *domain.Reservation) bool { return res.IsExpired(gracePeriod) })
+}
+
+func (r *ReservationRepository) UpdateStatus(ctx context.Context, id string, status domain.ReservationStatus) error {
+	return r.db.UpdateFields(ctx, "reservations", id, map[string]interface{}{"status": string(status)})
+}
+
+func (r *ReservationRepository) Delete(ctx context.Context, id string) error {
+	return r.db.Tombstone(ctx, "reservations", map[string]interface{}{"id": id})
+}
+
+func (r *ReservationRepository) CountByUserAndStatus(ctx context.Context, userID string, statuses []domain.ReservationStatus) (int, error) {
+	reservations, err := r.find(ctx, " AND n.user_id = $uid",
+		map[string]interface{}{"uid": userID},
+		func(res *domain.Reservation) bool {
+			for _, status := range statuses {
+				if res.Status == status {
+					return true
+				}
+			}
+			return false
+		})
+	return len(reservations), err
+}
+
+func (r *ReservationRepository) GetByOCPPReservationID(ctx context.Context, chargePointID string, reservationID int) (*domain.Reservation, error) {
+	reservations, err := r.find(ctx, " AND n.charge_point_id = $cpid AND n.ocpp_reservation_id = $rid",
+		map[string]interface{}{"cpid": chargePointID, "rid": reservationID}, nil)
+	if err != nil || len(reservations) == 0 {
+		return nil, err
+	}
+	return &reservations[len(reservations)-1], nil // The latest start
+}
+
+// find returns the reservations matching where and match, ordered by start
+// time. A nil match keeps every row.
+func (r *ReservationRepository) find(ctx context.Context, where string, params map[string]interface{}, match func(res *domain.Reservation) bool) ([]domain.Reservation, error) {
+	rows, err := r.db.QueryByLabel(ctx, "reservations", where, params)
+	if err != nil {
+		return nil, err
+	}
+	var reservations []domain.Reservation
+	for _, res := range fromRows[domain.Reservation](rows) {
+		if match == nil || match(&res) {
+			reservations = append(reservations, res)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.Before(reservations[j].StartTime)
+	})
+	return reservations, nil
+}
+
+// holdsConnector reports whether a reservation still blocks its connector
+func holdsConnector(res *domain.Reservation) bool {
+	return res.IsPending() || res.IsActive()
+}
+
+type MaintenanceWindowRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewMaintenanceWindowRepository(db *DB, log *zap.Logger) ports.MaintenanceWindowRepository {
+	return &MaintenanceWindowRepository{db: db, log: log}
+}
+
+func (r *MaintenanceWindowRepository) Save(ctx context.Context, window *domain.MaintenanceWindow) error {
+	if window.CreatedAt.IsZero() {
+		window.CreatedAt = time.Now()
+	}
+	m, err := ToMap(window)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "maintenance_windows", map[string]interface{}{"id": window.ID}, m)
+}
+
+// FindOverlapping returns the windows of a charge point that overlap [from, to)
+func (r *MaintenanceWindowRepository) FindOverlapping(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+	rows, err := r.db.QueryByLabel(ctx, "maintenance_windows",
+		" AND n.charge_point_id = $cpid",
+		map[string]interface{}{"cpid": chargePointID})
+	if err != nil {
+		return nil, err
+	}
+	var windows []domain.MaintenanceWindow
+	for _, w := range fromRows[domain.MaintenanceWindow](rows) {
+		if w.StartTime.Before(to) && w.EndTime.After(from) {
+			windows = append(windows, w)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].StartTime.Before(windows[j].StartTime) })
+	return windows, nil
+}
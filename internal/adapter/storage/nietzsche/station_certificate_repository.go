@@ -0,0 +1,83 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type StationCertificateRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewStationCertificateRepository(db *DB, log *zap.Logger) ports.StationCertificateRepository {
+	return &StationCertificateRepository{db: db, log: log}
+}
+
+func (r *StationCertificateRepository) Save(ctx context.Context, cert *domain.StationCertificate) error {
+	m, err := ToMap(cert)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Insert(ctx, "station_certificates", m)
+	return err
+}
+
+func (r *StationCertificateRepository) GetLatest(ctx context.Context, chargePointID string, certType domain.StationCertificateType) (*domain.StationCertificate, error) {
+	rows, err := r.db.QueryByLabel(ctx, "station_certificates",
+		" AND n.charge_point_id = $cpid AND n.certificate_type = $type",
+		map[string]interface{}{"cpid": chargePointID, "type": string(certType)})
+	if err != nil {
+		return nil, err
+	}
+	var latest *domain.StationCertificate
+	for _, m := range rows {
+		cert := &domain.StationCertificate{}
+		if err := FromMap(m, cert); err != nil {
+			continue
+		}
+		if latest == nil || cert.CreatedAt.After(latest.CreatedAt) {
+			latest = cert
+		}
+	}
+	return latest, nil
+}
+
+func (r *StationCertificateRepository) FindExpiringBefore(ctx context.Context, before time.Time) ([]domain.StationCertificate, error) {
+	rows, err := r.db.QueryByLabel(ctx, "station_certificates", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the latest certificate per charge point and type
+	latest := make(map[string]domain.StationCertificate)
+	for _, m := range rows {
+		var cert domain.StationCertificate
+		if err := FromMap(m, &cert); err != nil {
+			continue
+		}
+		key := cert.ChargePointID + "/" + string(cert.CertificateType)
+		if cur, ok := latest[key]; !ok || cert.CreatedAt.After(cur.CreatedAt) {
+			latest[key] = cert
+		}
+	}
+
+	var certs []domain.StationCertificate
+	for _, cert := range latest {
+		if cert.NotAfter.Before(before) {
+			certs = append(certs, cert)
+		}
+	}
+	sort.Slice(certs, func(i, j int) bool {
+		return certs[i].NotAfter.Before(certs[j].NotAfter)
+	})
+	return certs, nil
+}
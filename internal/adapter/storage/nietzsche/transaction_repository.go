@@ -6,6 +6,7 @@ package nietzsche
 import (
 	"context"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
@@ -16,6 +17,10 @@ import (
 type TransactionRepository struct {
 	db  *DB
 	log *zap.Logger
+
+	// NietzscheDB has no conditional update, so compare-and-set is
+	// serialized here. It is atomic within one server process only.
+	casMu sync.Mutex
 }
 
 func NewTransactionRepository(db *DB, log *zap.Logger) ports.TransactionRepository {
@@ -98,6 +103,74 @@ func (r *TransactionRepository) FindByDate(ctx context.Context, date time.Time)
 	return txs, nil
 }
 
+func (r *TransactionRepository) FindPage(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+	txs, err := r.findFiltered(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if !txs[i].CreatedAt.Equal(txs[j].CreatedAt) {
+			return txs[i].CreatedAt.After(txs[j].CreatedAt)
+		}
+		return txs[i].ID > txs[j].ID
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(txs), func(i int) bool {
+			return txs[i].CreatedAt.Before(after.CreatedAt) ||
+				(txs[i].CreatedAt.Equal(after.CreatedAt) && txs[i].ID < after.ID)
+		})
+	}
+	end := start + limit
+	if end > len(txs) {
+		end = len(txs)
+	}
+	return txs[start:end], nil
+}
+
+func (r *TransactionRepository) Count(ctx context.Context, filter ports.TransactionFilter) (int, error) {
+	txs, err := r.findFiltered(ctx, filter)
+	return len(txs), err
+}
+
+func (r *TransactionRepository) findFiltered(ctx context.Context, filter ports.TransactionFilter) ([]domain.Transaction, error) {
+	cypher := ""
+	params := map[string]interface{}{}
+	if filter.Status != "" {
+		cypher += " AND n.status = $st"
+		params["st"] = filter.Status
+	}
+	if filter.UserID != "" {
+		cypher += " AND n.user_id = $uid"
+		params["uid"] = filter.UserID
+	}
+	if filter.ChargePointID != "" {
+		cypher += " AND n.charge_point_id = $cp"
+		params["cp"] = filter.ChargePointID
+	}
+
+	rows, err := r.db.QueryByLabel(ctx, "transactions", cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	var txs []domain.Transaction
+	for _, m := range rows {
+		createdAt := GetTime(m, "created_at")
+		if !filter.StartDate.IsZero() && createdAt.Before(filter.StartDate) {
+			continue
+		}
+		if !filter.EndDate.IsZero() && !createdAt.Before(filter.EndDate) {
+			continue
+		}
+		var tx domain.Transaction
+		if err := FromMap(m, &tx); err == nil {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
 func (r *TransactionRepository) Update(ctx context.Context, tx *domain.Transaction) error {
 	m, err := ToMap(tx)
 	if err != nil {
@@ -108,3 +181,50 @@ func (r *TransactionRepository) Update(ctx context.Context, tx *domain.Transacti
 	delete(m, "created_at")
 	return r.db.UpdateFields(ctx, "transactions", tx.ID, m)
 }
+
+func (r *TransactionRepository) CompareAndSetPaymentStatus(ctx context.Context, id string, from, to domain.TransactionPaymentStatus) (bool, error) {
+	r.casMu.Lock()
+	defer r.casMu.Unlock()
+
+	tx, err := r.FindByID(ctx, id)
+	if err != nil || tx == nil || tx.PaymentStatus != from {
+		return false, err
+	}
+	if err := r.db.UpdateFields(ctx, "transactions", id, map[string]interface{}{
+		"payment_status": string(to),
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *TransactionRepository) FindByPaymentStatus(ctx context.Context, status domain.TransactionPaymentStatus, updatedBefore time.Time) ([]domain.Transaction, error) {
+	rows, err := r.db.QueryByLabel(ctx, "transactions",
+		" AND n.payment_status = $ps",
+		map[string]interface{}{"ps": string(status)})
+	if err != nil {
+		return nil, err
+	}
+	var txs []domain.Transaction
+	for _, tx := range fromRows[domain.Transaction](rows) {
+		if tx.UpdatedAt.Before(updatedBefore) {
+			txs = append(txs, tx)
+		}
+	}
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].UpdatedAt.Before(txs[j].UpdatedAt) })
+	return txs, nil
+}
+
+func (r *TransactionRepository) FindByOCPPTransactionID(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+	m, err := r.db.QueryFirst(ctx, "transactions",
+		" AND n.charge_point_id = $cpid AND n.ocpp_transaction_id = $otid",
+		map[string]interface{}{"cpid": chargePointID, "otid": ocppTransactionID})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	tx := &domain.Transaction{}
+	if err := FromMap(m, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
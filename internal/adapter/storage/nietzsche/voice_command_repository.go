@@ -0,0 +1,81 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type VoiceCommandRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewVoiceCommandRepository(db *DB, log *zap.Logger) ports.VoiceCommandRepository {
+	return &VoiceCommandRepository{db: db, log: log}
+}
+
+func (r *VoiceCommandRepository) Save(ctx context.Context, cmd *domain.VoiceCommand) error {
+	m, err := ToMap(cmd)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Insert(ctx, "voice_commands", m)
+	return err
+}
+
+func (r *VoiceCommandRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) ([]domain.VoiceCommand, error) {
+	rows, err := r.db.QueryByLabel(ctx, "voice_commands",
+		" AND n.user_id = $uid",
+		map[string]interface{}{"uid": userID})
+	if err != nil {
+		return nil, err
+	}
+	var cmds []domain.VoiceCommand
+	for _, m := range rows {
+		var cmd domain.VoiceCommand
+		if err := FromMap(m, &cmd); err == nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].CreatedAt.After(cmds[j].CreatedAt)
+	})
+
+	if offset >= len(cmds) {
+		return []domain.VoiceCommand{}, nil
+	}
+	cmds = cmds[offset:]
+	if limit > 0 && limit < len(cmds) {
+		cmds = cmds[:limit]
+	}
+	return cmds, nil
+}
+
+func (r *VoiceCommandRepository) AnonymizeByUserID(ctx context.Context, userID string) error {
+	rows, err := r.db.QueryByLabel(ctx, "voice_commands",
+		" AND n.user_id = $uid",
+		map[string]interface{}{"uid": userID})
+	if err != nil {
+		return err
+	}
+	for _, m := range rows {
+		id, _ := m["id"].(string)
+		if id == "" {
+			continue
+		}
+		if err := r.db.UpdateFields(ctx, "voice_commands", id, map[string]interface{}{
+			"transcript": "",
+			"result":     "",
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+type WalletRepository struct {
+	db      *DB
+	log     *zap.Logger
+	updates updateLock // Serializes balance changes per wallet
+}
+
+// NewWalletRepository creates the repository. locker may be nil.
+func NewWalletRepository(db *DB, locker ports.Locker, log *zap.Logger) ports.WalletRepository {
+	return &WalletRepository{db: db, log: log, updates: updateLock{locker: locker, log: log}}
+}
+
+func (r *WalletRepository) Save(ctx context.Context, wallet *domain.Wallet) error {
+	wallet.UpdatedAt = time.Now()
+	m, err := ToMap(wallet)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "wallets", map[string]interface{}{"id": wallet.ID}, m)
+}
+
+func (r *WalletRepository) GetByID(ctx context.Context, id string) (*domain.Wallet, error) {
+	return r.first(ctx, " AND n.id = $id", map[string]interface{}{"id": id})
+}
+
+func (r *WalletRepository) GetByUserID(ctx context.Context, userID string) (*domain.Wallet, error) {
+	return r.first(ctx, " AND n.user_id = $uid", map[string]interface{}{"uid": userID})
+}
+
+func (r *WalletRepository) first(ctx context.Context, where string, params map[string]interface{}) (*domain.Wallet, error) {
+	m, err := r.db.QueryFirst(ctx, "wallets", where, params)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	wallet := &domain.Wallet{}
+	if err := FromMap(m, wallet); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// AdjustBalance reads and replaces the balance under the wallet's lock, and
+// bumps its version
+func (r *WalletRepository) AdjustBalance(ctx context.Context, walletID string, delta float64) (*domain.Wallet, error) {
+	unlock, err := r.updates.lock(ctx, "wallet:"+walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	wallet, err := r.GetByID(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, fmt.Errorf("wallet not found: %s", walletID)
+	}
+	if wallet.Balance+delta < 0 {
+		return nil, domain.ErrInsufficientBalance
+	}
+	wallet.Balance += delta
+	wallet.Version++
+	wallet.UpdatedAt = time.Now()
+
+	if err := r.db.UpdateFields(ctx, "wallets", walletID, map[string]interface{}{
+		"balance": wallet.Balance,
+		"version": wallet.Version,
+	}); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// Credit claims the entry's reference with a MERGE before touching the
+// balance. NietzscheDB applies a MERGE atomically, so each reference is
+// credited once across servers.
+func (r *WalletRepository) Credit(ctx context.Context, entry *domain.WalletTransaction) (*domain.Wallet, error) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	m, err := ToMap(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeID string
+	if entry.ReferenceID != "" {
+		var created bool
+		nodeID, created, err = r.db.Merge(ctx, "wallet_transactions", withLabel("wallet_transactions", map[string]interface{}{
+			"type":         entry.Type,
+			"reference_id": entry.ReferenceID,
+		}), m, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			return nil, domain.ErrAlreadyCredited
+		}
+	}
+
+	wallet, err := r.AdjustBalance(ctx, entry.WalletID, entry.Amount)
+	if err != nil {
+		if nodeID != "" {
+			if delErr := r.db.DeleteNode(ctx, nodeID); delErr != nil {
+				r.log.Error("Failed to release wallet credit reference",
+					zap.String("reference_id", entry.ReferenceID),
+					zap.Error(delErr),
+				)
+			}
+		}
+		return nil, err
+	}
+	entry.Balance = wallet.Balance
+
+	if nodeID == "" {
+		m["balance"] = wallet.Balance
+		if _, err := r.db.Insert(ctx, "wallet_transactions", m); err != nil {
+			return nil, err
+		}
+		return wallet, nil
+	}
+	if err := r.db.UpdateFields(ctx, "wallet_transactions", entry.ID, map[string]interface{}{
+		"balance": wallet.Balance,
+	}); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+func (r *WalletRepository) SaveTransaction(ctx context.Context, tx *domain.WalletTransaction) error {
+	if tx.CreatedAt.IsZero() {
+		tx.CreatedAt = time.Now()
+	}
+	m, err := ToMap(tx)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Insert(ctx, "wallet_transactions", m)
+	return err
+}
+
+// GetTransactions returns a wallet's transactions, newest first
+func (r *WalletRepository) GetTransactions(ctx context.Context, walletID string, limit, offset int) ([]domain.WalletTransaction, error) {
+	rows, err := r.db.QueryByLabel(ctx, "wallet_transactions",
+		" AND n.wallet_id = $wid",
+		map[string]interface{}{"wid": walletID})
+	if err != nil {
+		return nil, err
+	}
+	txs := fromRows[domain.WalletTransaction](rows)
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].CreatedAt.After(txs[j].CreatedAt)
+	})
+	return paginate(txs, limit, offset), nil
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2025-2026 Jose R F Junior <web2ajax@gmail.com>
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nietzsche
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"go.uber.org/zap"
+)
+
+// secretField stores WebhookEndpoint.Secret, which JSON leaves out
+const secretField = "secret"
+
+type WebhookRepository struct {
+	db  *DB
+	log *zap.Logger
+}
+
+func NewWebhookRepository(db *DB, log *zap.Logger) ports.WebhookRepository {
+	return &WebhookRepository{db: db, log: log}
+}
+
+func (r *WebhookRepository) Save(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	now := time.Now()
+	if endpoint.CreatedAt.IsZero() {
+		endpoint.CreatedAt = now
+	}
+	endpoint.UpdatedAt = now
+
+	m, err := ToMap(endpoint)
+	if err != nil {
+		return err
+	}
+	m[secretField] = endpoint.Secret
+	return r.db.Upsert(ctx, "webhook_endpoints", map[string]interface{}{"id": endpoint.ID}, m)
+}
+
+func (r *WebhookRepository) FindByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	m, err := r.db.QueryFirst(ctx, "webhook_endpoints", " AND n.id = $id", map[string]interface{}{"id": id})
+	if err != nil || m == nil {
+		return nil, err
+	}
+	endpoint, err := endpointFromMap(m)
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *WebhookRepository) FindByAccountID(ctx context.Context, accountID string) ([]domain.WebhookEndpoint, error) {
+	return r.find(ctx, " AND n.account_id = $aid", map[string]interface{}{"aid": accountID}, nil)
+}
+
+func (r *WebhookRepository) FindByEventType(ctx context.Context, eventType string) ([]domain.WebhookEndpoint, error) {
+	return r.find(ctx, " AND n.active = $active", map[string]interface{}{"active": true},
+		func(e *domain.WebhookEndpoint) bool { return e.Subscribes(eventType) })
+}
+
+// find returns the matching endpoints, oldest first. A nil match keeps every row.
+func (r *WebhookRepository) find(ctx context.Context, where string, params map[string]interface{}, match func(e *domain.WebhookEndpoint) bool) ([]domain.WebhookEndpoint, error) {
+	rows, err := r.db.QueryByLabel(ctx, "webhook_endpoints", where, params)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []domain.WebhookEndpoint
+	for _, m := range rows {
+		endpoint, err := endpointFromMap(m)
+		if err != nil {
+			continue
+		}
+		if match == nil || match(&endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].CreatedAt.Before(endpoints[j].CreatedAt) })
+	return endpoints, nil
+}
+
+// Delete removes an endpoint and its delivery log
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	deliveries, err := r.FindDeliveries(ctx, id, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		if err := r.db.Tombstone(ctx, "webhook_deliveries", map[string]interface{}{"id": delivery.ID}); err != nil {
+			return err
+		}
+	}
+	return r.db.Tombstone(ctx, "webhook_endpoints", map[string]interface{}{"id": id})
+}
+
+func (r *WebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+	m, err := ToMap(delivery)
+	if err != nil {
+		return err
+	}
+	return r.db.Upsert(ctx, "webhook_deliveries", map[string]interface{}{"id": delivery.ID}, m)
+}
+
+func (r *WebhookRepository) FindDeliveries(ctx context.Context, endpointID string, limit, offset int) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryByLabel(ctx, "webhook_deliveries",
+		" AND n.endpoint_id = $eid",
+		map[string]interface{}{"eid": endpointID})
+	if err != nil {
+		return nil, err
+	}
+	deliveries := fromRows[domain.WebhookDelivery](rows)
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	return paginate(deliveries, limit, offset), nil
+}
+
+func endpointFromMap(m map[string]interface{}) (domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	if err := FromMap(m, &endpoint); err != nil {
+		return endpoint, err
+	}
+	endpoint.Secret = GetString(m, secretField)
+	return endpoint, nil
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// alertsTable holds ports.Alert, which carries no table name of its own
+const alertsTable = "alerts"
+
+// AlertRepository implements alert persistence
+type AlertRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewAlertRepository creates a new alert repository
+func NewAlertRepository(db *gorm.DB, log *zap.Logger) ports.AlertRepository {
+	return &AlertRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *AlertRepository) alerts(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table(alertsTable)
+}
+
+// Save creates or fully overwrites an alert
+func (r *AlertRepository) Save(ctx context.Context, alert *ports.Alert) error {
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+
+	result := r.alerts(ctx).Save(alert)
+	if result.Error != nil {
+		r.log.Error("Failed to save alert",
+			zap.String("alertID", alert.ID),
+			zap.String("type", alert.Type),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// GetByID retrieves an alert, or nil
+func (r *AlertRepository) GetByID(ctx context.Context, id string) (*ports.Alert, error) {
+	var alert ports.Alert
+	if err := r.alerts(ctx).Where("id = ?", id).First(&alert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// GetAll retrieves the alerts with the given acknowledgement, newest first
+func (r *AlertRepository) GetAll(ctx context.Context, acknowledged bool, limit, offset int) ([]ports.Alert, error) {
+	query := r.alerts(ctx).Where("acknowledged = ?", acknowledged).Order("created_at DESC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var alerts []ports.Alert
+	if err := query.Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Acknowledge marks an alert as seen by an operator
+func (r *AlertRepository) Acknowledge(ctx context.Context, id string) error {
+	return r.alerts(ctx).Where("id = ?", id).Updates(map[string]interface{}{
+		"acknowledged":    true,
+		"acknowledged_at": time.Now(),
+	}).Error
+}
+
+// Delete removes an alert
+func (r *AlertRepository) Delete(ctx context.Context, id string) error {
+	return r.alerts(ctx).Where("id = ?", id).Delete(&ports.Alert{}).Error
+}
+
+// CountUnacknowledged counts the alerts no operator has seen
+func (r *AlertRepository) CountUnacknowledged(ctx context.Context) (int, error) {
+	var count int64
+	err := r.alerts(ctx).Where("acknowledged = ?", false).Count(&count).Error
+	return int(count), err
+}
+
+// FindOpen retrieves the unresolved alerts of alertType raised by sourceID, newest first
+func (r *AlertRepository) FindOpen(ctx context.Context, alertType, sourceID string) ([]ports.Alert, error) {
+	var alerts []ports.Alert
+	err := r.alerts(ctx).
+		Where("type = ? AND source_id = ? AND NOT resolved", alertType, sourceID).
+		Order("created_at DESC").
+		Find(&alerts).Error
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Resolve marks an alert as resolved because its condition cleared
+func (r *AlertRepository) Resolve(ctx context.Context, id string, resolvedAt time.Time) error {
+	return r.alerts(ctx).Where("id = ?", id).Updates(map[string]interface{}{
+		"resolved":    true,
+		"resolved_at": resolvedAt,
+	}).Error
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// AuditRepository implements the audit log
+type AuditRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *gorm.DB, log *zap.Logger) ports.AuditRepository {
+	return &AuditRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save appends an entry
+func (r *AuditRepository) Save(ctx context.Context, entry *domain.AuditEntry) error {
+	result := r.db.WithContext(ctx).Create(entry)
+	if result.Error != nil {
+		r.log.Error("Failed to save audit entry",
+			zap.String("action", entry.Action),
+			zap.String("entityID", entry.EntityID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// Find retrieves the entries matching filter, newest first, and the total
+func (r *AuditRepository) Find(ctx context.Context, filter ports.AuditFilter, limit, offset int) ([]domain.AuditEntry, int, error) {
+	query := r.db.WithContext(ctx).Model(&domain.AuditEntry{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []domain.AuditEntry
+	page := query.Order("created_at desc").Offset(offset)
+	if limit > 0 {
+		page = page.Limit(limit)
+	}
+	if err := page.Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, int(total), nil
+}
@@ -3,9 +3,12 @@ package postgres
 import (
 	"context"
 	"errors"
+	"strings"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
@@ -51,6 +54,10 @@ func (r *ChargePointRepository) FindAll(ctx context.Context, filter map[string]i
 	if status, ok := filter["status"]; ok {
 		query = query.Where("status = ?", status)
 	}
+	if connectors, ok := filter[ports.ConnectorFilterKey].(domain.ConnectorFilter); ok && !connectors.IsZero() {
+		cond, args := connectorFilterSQL("charge_points", connectors)
+		query = query.Where(cond, args...)
+	}
 	// Add other filters as needed
 
 	result := query.Find(&cps)
@@ -65,9 +72,117 @@ func (r *ChargePointRepository) UpdateStatus(ctx context.Context, id string, sta
 	return result.Error
 }
 
-func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error) {
+// UpdateConnectorStatus sets the status of one connector, creating the
+// connector row the first time a charge point reports it
+func (r *ChargePointRepository) UpdateConnectorStatus(ctx context.Context, chargePointID string, connectorID int, status domain.ChargePointStatus) error {
+	connector := domain.Connector{
+		ID:            uuid.New().String(),
+		ChargePointID: chargePointID,
+		ConnectorID:   connectorID,
+		Type:          "Type2",
+		Status:        status,
+	}
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "charge_point_id"}, {Name: "connector_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"status": status}),
+	}).Create(&connector)
+	if result.Error != nil {
+		r.log.Error("Failed to update connector status",
+			zap.String("charge_point_id", chargePointID),
+			zap.Int("connector_id", connectorID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// CompareAndSetConnectorStatus sets the connector's status to to if it is
+// from. The conditional UPDATE is atomic; a device without connector rows
+// gets its first row inserted, and the unique (charge_point_id, connector_id)
+// index lets only one concurrent insert win.
+func (r *ChargePointRepository) CompareAndSetConnectorStatus(ctx context.Context, chargePointID string, connectorID int, from, to domain.ChargePointStatus) (bool, error) {
+	db := r.db.WithContext(ctx)
+
+	result := db.Model(&domain.Connector{}).
+		Where("charge_point_id = ? AND connector_id = ? AND status = ?", chargePointID, connectorID, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	var connectors int64
+	if err := db.Model(&domain.Connector{}).Where("charge_point_id = ?", chargePointID).Count(&connectors).Error; err != nil {
+		return false, err
+	}
+	if connectors > 0 {
+		return false, nil
+	}
+
+	var cp domain.ChargePoint
+	if err := db.Select("status").Where("id = ?", chargePointID).First(&cp).Error; err != nil {
+		return false, err
+	}
+	if cp.Status != from {
+		return false, nil
+	}
+
+	result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.Connector{
+		ID:            uuid.New().String(),
+		ChargePointID: chargePointID,
+		ConnectorID:   connectorID,
+		Type:          "Type2",
+		Status:        to,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *ChargePointRepository) UpdateHeartbeatInterval(ctx context.Context, id string, interval int) error {
+	result := r.db.WithContext(ctx).Model(&domain.ChargePoint{}).Where("id = ?", id).Update("heartbeat_interval", interval)
+	return result.Error
+}
+
+// UpdateBootInfo locks the row in the subquery, so a concurrent boot waits
+// and then reads the version this one wrote as its previous version
+func (r *ChargePointRepository) UpdateBootInfo(ctx context.Context, id string, info domain.BootInfo) (string, error) {
+	var previous []string
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE charge_points cp SET
+			vendor = COALESCE(NULLIF(?, ''), cp.vendor),
+			model = COALESCE(NULLIF(?, ''), cp.model),
+			serial_number = COALESCE(NULLIF(?, ''), cp.serial_number),
+			firmware_version = COALESCE(NULLIF(?, ''), cp.firmware_version),
+			updated_at = NOW()
+		FROM (SELECT id, firmware_version FROM charge_points WHERE id = ? FOR UPDATE) old
+		WHERE cp.id = old.id
+		RETURNING COALESCE(old.firmware_version, '')`,
+		info.Vendor, info.Model, info.SerialNumber, info.FirmwareVersion, id,
+	).Scan(&previous).Error
+	if err != nil {
+		return "", err
+	}
+	if len(previous) == 0 {
+		return "", domain.ErrDeviceNotFound
+	}
+	return previous[0], nil
+}
+
+func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
 	var cps []domain.ChargePoint
 
+	var connectorSQL string
+	var connectorArgs []interface{}
+	if !connectors.IsZero() {
+		connectorSQL, connectorArgs = connectorFilterSQL("cp", connectors)
+		connectorSQL = "AND " + connectorSQL
+	}
+
 	// Haversine formula in SQL for calculating distance between two points
 	// Returns distance in kilometers
 	// Earth radius: 6371 km
@@ -88,6 +203,7 @@ func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius
 				POWER(SIN(RADIANS(l.longitude - ?) / 2), 2)
 			))
 		  ) <= ?
+		  ` + connectorSQL + `
 		ORDER BY (
 			6371 * 2 * ASIN(SQRT(
 				POWER(SIN(RADIANS(l.latitude - ?) / 2), 2) +
@@ -100,11 +216,10 @@ func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius
 
 	// Execute raw SQL with Haversine formula
 	// Parameters per formula instance: lat (for Δlat), lat (for cos(φ₁)), lon (for Δlon)
-	result := r.db.WithContext(ctx).Raw(
-		haversineSQL,
-		lat, lat, lon, radius, // WHERE clause: sin²(Δlat/2), cos(input_lat), sin²(Δlon/2), max_distance
-		lat, lat, lon,         // ORDER BY clause: same formula for sorting
-	).Scan(&cps)
+	args := []interface{}{lat, lat, lon, radius} // WHERE clause: sin²(Δlat/2), cos(input_lat), sin²(Δlon/2), max_distance
+	args = append(args, connectorArgs...)
+	args = append(args, lat, lat, lon) // ORDER BY clause: same formula for sorting
+	result := r.db.WithContext(ctx).Raw(haversineSQL, args...).Scan(&cps)
 
 	if result.Error != nil {
 		r.log.Error("Failed to find nearby charge points",
@@ -158,3 +273,30 @@ func (r *ChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius
 
 	return cps, nil
 }
+
+// connectorFilterSQL returns the condition selecting rows of the charge point
+// table (or alias) with a connector matching f, as domain.ConnectorFilter.Matches
+func connectorFilterSQL(table string, f domain.ConnectorFilter) (string, []interface{}) {
+	conds := []string{"c.charge_point_id = " + table + ".id"}
+	var args []interface{}
+	if f.Standard != "" {
+		conds = append(conds, "UPPER(c.type) = UPPER(?)")
+		args = append(args, f.Standard)
+	}
+	if f.MinPowerKW > 0 {
+		conds = append(conds, "c.max_power_kw >= ?")
+		args = append(args, f.MinPowerKW)
+	}
+	if f.AvailableNow {
+		conds = append(conds, "c.status = ?")
+		args = append(args, domain.ChargePointStatusAvailable)
+	}
+	cond := "EXISTS (SELECT 1 FROM connectors c WHERE " + strings.Join(conds, " AND ") + ")"
+
+	// Devices without connector data only match on their own availability
+	if f.Standard == "" && f.MinPowerKW == 0 {
+		cond = "(" + cond + " OR (NOT EXISTS (SELECT 1 FROM connectors c WHERE c.charge_point_id = " + table + ".id) AND " + table + ".status = ?))"
+		args = append(args, domain.ChargePointStatusAvailable)
+	}
+	return cond, args
+}
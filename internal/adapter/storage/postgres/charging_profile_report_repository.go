@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ChargingProfileReportRepository implements reported charging profile persistence
+type ChargingProfileReportRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewChargingProfileReportRepository creates a new charging profile report repository
+func NewChargingProfileReportRepository(db *gorm.DB, log *zap.Logger) ports.ChargingProfileReportRepository {
+	return &ChargingProfileReportRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save upserts the reported charging profiles of a charge point
+func (r *ChargingProfileReportRepository) Save(ctx context.Context, report *domain.ChargingProfileReport) error {
+	result := r.db.WithContext(ctx).Save(report)
+	if result.Error != nil {
+		r.log.Error("Failed to save charging profile report",
+			zap.String("chargePointID", report.ChargePointID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// GetByChargePointID retrieves the reported charging profiles of a charge point
+func (r *ChargingProfileReportRepository) GetByChargePointID(ctx context.Context, chargePointID string) (*domain.ChargingProfileReport, error) {
+	var report domain.ChargingProfileReport
+	err := r.db.WithContext(ctx).First(&report, "charge_point_id = ?", chargePointID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &report, nil
+}
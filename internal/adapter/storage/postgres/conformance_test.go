@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/storagetest"
+)
+
+// TestConformance runs against a migrated database given in SIGEC_TEST_POSTGRES_URL
+func TestConformance(t *testing.T) {
+	url := os.Getenv("SIGEC_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("SIGEC_TEST_POSTGRES_URL not set")
+	}
+	db, err := NewConnection(url, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	repos := NewRepositories(db, zap.NewNop())
+	defer repos.Close()
+
+	storagetest.Run(t, repos)
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ConnectionEventRepository implements connection history persistence
+type ConnectionEventRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewConnectionEventRepository creates a new connection event repository
+func NewConnectionEventRepository(db *gorm.DB, log *zap.Logger) ports.ConnectionEventRepository {
+	return &ConnectionEventRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores a connection event
+func (r *ConnectionEventRepository) Save(ctx context.Context, event *domain.ConnectionEvent) error {
+	result := r.db.WithContext(ctx).Create(event)
+	if result.Error != nil {
+		r.log.Error("Failed to save connection event",
+			zap.String("chargePointID", event.ChargePointID),
+			zap.String("type", string(event.Type)),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// FindByChargePointID retrieves a charge point's events in [from, to), oldest first
+func (r *ConnectionEventRepository) FindByChargePointID(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.ConnectionEvent, error) {
+	var events []domain.ConnectionEvent
+	err := r.db.WithContext(ctx).
+		Where("charge_point_id = ? AND timestamp >= ? AND timestamp < ?", chargePointID, from, to).
+		Order("timestamp asc, type desc"). // A reconnect's disconnect comes first
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// FindLastBefore retrieves a charge point's last event before t
+func (r *ConnectionEventRepository) FindLastBefore(ctx context.Context, chargePointID string, t time.Time) (*domain.ConnectionEvent, error) {
+	var event domain.ConnectionEvent
+	err := r.db.WithContext(ctx).
+		Where("charge_point_id = ? AND timestamp < ?", chargePointID, t).
+		Order("timestamp desc, type asc").
+		First(&event).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event, nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// eventRow maps domain.Event onto the domain_events table
+type eventRow struct {
+	Seq         int64 `gorm:"primaryKey"`
+	AggregateID string
+	Subject     string
+	Payload     string `gorm:"type:jsonb"`
+	CreatedAt   time.Time
+}
+
+func (eventRow) TableName() string {
+	return "domain_events"
+}
+
+// EventRepository implements the event store
+type EventRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewEventRepository creates a new event repository
+func NewEventRepository(db *gorm.DB, log *zap.Logger) ports.EventRepository {
+	return &EventRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Append stores an event; the primary key rejects a reused Seq
+func (r *EventRepository) Append(ctx context.Context, event *domain.Event) error {
+	row := eventRow{
+		Seq:         event.Seq,
+		AggregateID: event.AggregateID,
+		Subject:     event.Subject,
+		Payload:     string(event.Payload),
+		CreatedAt:   event.CreatedAt,
+	}
+	if len(event.Payload) == 0 {
+		row.Payload = "null"
+	}
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if result.Error != nil {
+		r.log.Error("Failed to append event",
+			zap.Int64("seq", event.Seq),
+			zap.String("subject", event.Subject),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// FindByAggregateID retrieves the events of an aggregate after sinceSeq, in order
+func (r *EventRepository) FindByAggregateID(ctx context.Context, aggregateID string, sinceSeq int64) ([]domain.Event, error) {
+	var rows []eventRow
+	err := r.db.WithContext(ctx).
+		Where("aggregate_id = ? AND seq > ?", aggregateID, sinceSeq).
+		Order("seq ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, domain.Event{
+			Seq:         row.Seq,
+			AggregateID: row.AggregateID,
+			Subject:     row.Subject,
+			Payload:     []byte(row.Payload),
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return events, nil
+}
+
+// LastSeq returns the highest sequence number stored, or 0 when empty
+func (r *EventRepository) LastSeq(ctx context.Context) (int64, error) {
+	var last int64
+	err := r.db.WithContext(ctx).Model(&eventRow{}).Select("COALESCE(MAX(seq), 0)").Scan(&last).Error
+	return last, err
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// FirmwarePublicationRepository implements PublishFirmware request persistence
+type FirmwarePublicationRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewFirmwarePublicationRepository creates a new firmware publication repository
+func NewFirmwarePublicationRepository(db *gorm.DB, log *zap.Logger) ports.FirmwarePublicationRepository {
+	return &FirmwarePublicationRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores a new firmware publication
+func (r *FirmwarePublicationRepository) Save(ctx context.Context, pub *domain.FirmwarePublication) error {
+	result := r.db.WithContext(ctx).Create(pub)
+	if result.Error != nil {
+		r.log.Error("Failed to save firmware publication",
+			zap.String("chargePointID", pub.ChargePointID),
+			zap.Int("requestID", pub.RequestID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// Update stores the status of a firmware publication
+func (r *FirmwarePublicationRepository) Update(ctx context.Context, pub *domain.FirmwarePublication) error {
+	return r.db.WithContext(ctx).Save(pub).Error
+}
+
+// FindByRequestID retrieves a firmware publication by the OCPP requestId
+func (r *FirmwarePublicationRepository) FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.FirmwarePublication, error) {
+	var pub domain.FirmwarePublication
+	err := r.db.WithContext(ctx).
+		First(&pub, "charge_point_id = ? AND request_id = ?", chargePointID, requestID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pub, nil
+}
+
+// FindByChargePointID retrieves a charge point's firmware publications, newest first
+func (r *FirmwarePublicationRepository) FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.FirmwarePublication, error) {
+	var pubs []domain.FirmwarePublication
+	query := r.db.WithContext(ctx).Where("charge_point_id = ?", chargePointID).Order("created_at desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&pubs).Error; err != nil {
+		return nil, err
+	}
+	return pubs, nil
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// DeviceInventoryRepository implements device inventory persistence
+type DeviceInventoryRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewDeviceInventoryRepository creates a new device inventory repository
+func NewDeviceInventoryRepository(db *gorm.DB, log *zap.Logger) ports.DeviceInventoryRepository {
+	return &DeviceInventoryRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save upserts the inventory of a charge point
+func (r *DeviceInventoryRepository) Save(ctx context.Context, inventory *domain.DeviceInventory) error {
+	result := r.db.WithContext(ctx).Save(inventory)
+	if result.Error != nil {
+		r.log.Error("Failed to save device inventory",
+			zap.String("chargePointID", inventory.ChargePointID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// GetByChargePointID retrieves the inventory of a charge point
+func (r *DeviceInventoryRepository) GetByChargePointID(ctx context.Context, chargePointID string) (*domain.DeviceInventory, error) {
+	var inventory domain.DeviceInventory
+	err := r.db.WithContext(ctx).First(&inventory, "charge_point_id = ?", chargePointID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inventory, nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// LogRequestRepository implements GetLog request persistence
+type LogRequestRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewLogRequestRepository creates a new log request repository
+func NewLogRequestRepository(db *gorm.DB, log *zap.Logger) ports.LogRequestRepository {
+	return &LogRequestRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores a new log request
+func (r *LogRequestRepository) Save(ctx context.Context, req *domain.LogRequest) error {
+	result := r.db.WithContext(ctx).Create(req)
+	if result.Error != nil {
+		r.log.Error("Failed to save log request",
+			zap.String("chargePointID", req.ChargePointID),
+			zap.Int("requestID", req.RequestID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// Update stores the status of a log request
+func (r *LogRequestRepository) Update(ctx context.Context, req *domain.LogRequest) error {
+	return r.db.WithContext(ctx).Save(req).Error
+}
+
+// FindByRequestID retrieves a log request by the OCPP requestId
+func (r *LogRequestRepository) FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.LogRequest, error) {
+	var req domain.LogRequest
+	err := r.db.WithContext(ctx).
+		First(&req, "charge_point_id = ? AND request_id = ?", chargePointID, requestID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &req, nil
+}
+
+// FindByChargePointID retrieves a charge point's log requests, newest first
+func (r *LogRequestRepository) FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.LogRequest, error) {
+	var reqs []domain.LogRequest
+	query := r.db.WithContext(ctx).Where("charge_point_id = ?", chargePointID).Order("created_at desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&reqs).Error; err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// meterValueBatchSize is how many samples are inserted per statement
+const meterValueBatchSize = 500
+
+// MeterValueRepository implements meter sample persistence
+type MeterValueRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewMeterValueRepository creates a new meter value repository
+func NewMeterValueRepository(db *gorm.DB, log *zap.Logger) ports.MeterValueRepository {
+	return &MeterValueRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores meter samples
+func (r *MeterValueRepository) Save(ctx context.Context, samples []domain.MeterSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	result := r.db.WithContext(ctx).CreateInBatches(samples, meterValueBatchSize)
+	if result.Error != nil {
+		r.log.Error("Failed to save meter values",
+			zap.String("transactionID", samples[0].TransactionID),
+			zap.Int("count", len(samples)),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// FindByTransactionID retrieves the samples of a transaction, oldest first
+func (r *MeterValueRepository) FindByTransactionID(ctx context.Context, transactionID string) ([]domain.MeterSample, error) {
+	var samples []domain.MeterSample
+	err := r.db.WithContext(ctx).
+		Where("transaction_id = ?", transactionID).
+		Order("timestamp asc").
+		Find(&samples).Error
+	if err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// ReplaceByTransactionID replaces the samples of a transaction in one
+// database transaction, so readers never see a partial series
+func (r *MeterValueRepository) ReplaceByTransactionID(ctx context.Context, transactionID string, samples []domain.MeterSample) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transaction_id = ?", transactionID).Delete(&domain.MeterSample{}).Error; err != nil {
+			return err
+		}
+		if len(samples) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(samples, meterValueBatchSize).Error
+	})
+}
+
+// DeleteBefore removes every sample taken before t
+func (r *MeterValueRepository) DeleteBefore(ctx context.Context, t time.Time) (int, error) {
+	result := r.db.WithContext(ctx).Where("timestamp < ?", t).Delete(&domain.MeterSample{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
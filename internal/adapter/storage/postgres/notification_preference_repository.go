@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// NotificationPreferenceRepository implements notification preference persistence
+type NotificationPreferenceRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewNotificationPreferenceRepository creates a new notification preference repository
+func NewNotificationPreferenceRepository(db *gorm.DB, log *zap.Logger) ports.NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores a user's preferences, replacing any earlier ones
+func (r *NotificationPreferenceRepository) Save(ctx context.Context, prefs *domain.NotificationPreferences) error {
+	result := r.db.WithContext(ctx).Save(prefs)
+	if result.Error != nil {
+		r.log.Error("Failed to save notification preferences",
+			zap.String("userID", prefs.UserID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// FindByUserID returns nil when the user never set any preferences
+func (r *NotificationPreferenceRepository) FindByUserID(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	var prefs domain.NotificationPreferences
+	err := r.db.WithContext(ctx).First(&prefs, "user_id = ?", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &prefs, nil
+}
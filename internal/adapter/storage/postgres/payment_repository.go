@@ -0,0 +1,218 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// paymentWebhookEventRow maps a claimed provider event onto payment_webhook_events
+type paymentWebhookEventRow struct {
+	Provider    string `gorm:"primaryKey"`
+	EventID     string `gorm:"primaryKey"`
+	ProcessedAt time.Time
+}
+
+func (paymentWebhookEventRow) TableName() string {
+	return "payment_webhook_events"
+}
+
+// PaymentRepository implements payment and refund persistence
+type PaymentRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewPaymentRepository creates a new payment repository
+func NewPaymentRepository(db *gorm.DB, log *zap.Logger) ports.PaymentRepository {
+	return &PaymentRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// SavePayment creates or fully overwrites a payment
+func (r *PaymentRepository) SavePayment(ctx context.Context, payment *domain.Payment) error {
+	now := time.Now()
+	if payment.CreatedAt.IsZero() {
+		payment.CreatedAt = now
+	}
+	payment.UpdatedAt = now
+
+	result := r.db.WithContext(ctx).Save(payment)
+	if result.Error != nil {
+		r.log.Error("Failed to save payment",
+			zap.String("paymentID", payment.ID),
+			zap.String("userID", payment.UserID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// GetPayment retrieves a payment, or nil
+func (r *PaymentRepository) GetPayment(ctx context.Context, id string) (*domain.Payment, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetPaymentByProviderID retrieves the payment with a provider reference, or nil
+func (r *PaymentRepository) GetPaymentByProviderID(ctx context.Context, providerID string) (*domain.Payment, error) {
+	return r.first(ctx, "provider_id = ?", providerID)
+}
+
+func (r *PaymentRepository) first(ctx context.Context, query string, args ...interface{}) (*domain.Payment, error) {
+	var payment domain.Payment
+	err := r.db.WithContext(ctx).Where(query, args...).Order("created_at DESC").First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetPaymentsByUser retrieves a user's payments, newest first
+func (r *PaymentRepository) GetPaymentsByUser(ctx context.Context, userID string, limit, offset int) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// GetPaymentsByTransaction retrieves the payments of a charging transaction, newest first
+func (r *PaymentRepository) GetPaymentsByTransaction(ctx context.Context, transactionID string) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.WithContext(ctx).
+		Where("transaction_id = ?", transactionID).
+		Order("created_at DESC").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// SaveRefund creates or fully overwrites a refund
+func (r *PaymentRepository) SaveRefund(ctx context.Context, refund *domain.Refund) error {
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = time.Now()
+	}
+
+	result := r.db.WithContext(ctx).Save(refund)
+	if result.Error != nil {
+		r.log.Error("Failed to save refund",
+			zap.String("refundID", refund.ID),
+			zap.String("paymentID", refund.PaymentID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// GetRefundsByPayment retrieves the refunds of a payment, oldest first
+func (r *PaymentRepository) GetRefundsByPayment(ctx context.Context, paymentID string) ([]domain.Refund, error) {
+	var refunds []domain.Refund
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("created_at").
+		Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// ClaimWebhookEvent inserts the event unless it is there, so of concurrent
+// deliveries exactly one claims it
+func (r *PaymentRepository) ClaimWebhookEvent(ctx context.Context, provider, eventID string) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&paymentWebhookEventRow{
+		Provider:    provider,
+		EventID:     eventID,
+		ProcessedAt: time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseWebhookEvent forgets a claimed event
+func (r *PaymentRepository) ReleaseWebhookEvent(ctx context.Context, provider, eventID string) error {
+	return r.db.WithContext(ctx).
+		Delete(&paymentWebhookEventRow{}, "provider = ? AND event_id = ?", provider, eventID).Error
+}
+
+// CardRepository implements payment card persistence
+type CardRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewCardRepository creates a new card repository
+func NewCardRepository(db *gorm.DB, log *zap.Logger) ports.CardRepository {
+	return &CardRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save creates or fully overwrites a card
+func (r *CardRepository) Save(ctx context.Context, card *domain.PaymentCard) error {
+	if card.CreatedAt.IsZero() {
+		card.CreatedAt = time.Now()
+	}
+	return r.db.WithContext(ctx).Save(card).Error
+}
+
+// GetByID retrieves a card, or nil
+func (r *CardRepository) GetByID(ctx context.Context, id string) (*domain.PaymentCard, error) {
+	var card domain.PaymentCard
+	if err := r.db.WithContext(ctx).First(&card, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+// GetByUserID retrieves a user's cards, the default one first
+func (r *CardRepository) GetByUserID(ctx context.Context, userID string) ([]domain.PaymentCard, error) {
+	var cards []domain.PaymentCard
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_default DESC, created_at").
+		Find(&cards).Error
+	if err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// Delete removes a card
+func (r *CardRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&domain.PaymentCard{}, "id = ?", id).Error
+}
+
+// SetDefault makes cardID the user's only default card in one statement
+func (r *CardRepository) SetDefault(ctx context.Context, userID, cardID string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.PaymentCard{}).
+		Where("user_id = ?", userID).
+		Update("is_default", gorm.Expr("id = ?", cardID)).Error
+}
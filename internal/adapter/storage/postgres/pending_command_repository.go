@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// PendingCommandRepository implements pending OCPP command persistence
+type PendingCommandRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewPendingCommandRepository creates a new pending command repository
+func NewPendingCommandRepository(db *gorm.DB, log *zap.Logger) ports.PendingCommandRepository {
+	return &PendingCommandRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores a command
+func (r *PendingCommandRepository) Save(ctx context.Context, cmd *domain.PendingCommand) error {
+	result := r.db.WithContext(ctx).Save(cmd)
+	if result.Error != nil {
+		r.log.Error("Failed to save pending command",
+			zap.String("messageID", cmd.MessageID),
+			zap.String("chargePointID", cmd.ChargePointID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// Delete removes a command
+func (r *PendingCommandRepository) Delete(ctx context.Context, messageID string) error {
+	return r.db.WithContext(ctx).Delete(&domain.PendingCommand{}, "message_id = ?", messageID).Error
+}
+
+// FindUnexpired retrieves the commands that expire after now, oldest first
+func (r *PendingCommandRepository) FindUnexpired(ctx context.Context, now time.Time) ([]domain.PendingCommand, error) {
+	var commands []domain.PendingCommand
+	err := r.db.WithContext(ctx).
+		Where("expires_at > ?", now).
+		Order("created_at").
+		Find(&commands).Error
+	if err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// DeleteExpired removes the commands that expired at or before now
+func (r *PendingCommandRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return r.db.WithContext(ctx).Delete(&domain.PendingCommand{}, "expires_at <= ?", now).Error
+}
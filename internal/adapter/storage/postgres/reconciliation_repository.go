@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ReconciliationRepository implements reconciliation persistence
+type ReconciliationRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewReconciliationRepository creates a new reconciliation repository
+func NewReconciliationRepository(db *gorm.DB, log *zap.Logger) ports.ReconciliationRepository {
+	return &ReconciliationRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores a reconciliation record
+func (r *ReconciliationRepository) Save(ctx context.Context, rec *domain.Reconciliation) error {
+	result := r.db.WithContext(ctx).Create(rec)
+	if result.Error != nil {
+		r.log.Error("Failed to save reconciliation",
+			zap.String("transactionID", rec.TransactionID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// FindByTransactionID retrieves the reconciliation of a transaction
+func (r *ReconciliationRepository) FindByTransactionID(ctx context.Context, transactionID string) (*domain.Reconciliation, error) {
+	var rec domain.Reconciliation
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).First(&rec).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
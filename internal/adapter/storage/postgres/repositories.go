@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// NewRepositories builds every repository this backend implements on db.
+// Closing the set closes db.
+func NewRepositories(db *gorm.DB, log *zap.Logger) *ports.Repositories {
+	return &ports.Repositories{
+		ChargePoints:         NewChargePointRepository(db, log),
+		Transactions:         NewTransactionRepository(db, log),
+		Users:                NewUserRepository(db, log),
+		Payments:             NewPaymentRepository(db, log),
+		Cards:                NewCardRepository(db, log),
+		Wallets:              NewWalletRepository(db, log),
+		Reservations:         NewReservationRepository(db, log),
+		MaintenanceWindows:   NewMaintenanceWindowRepository(db, log),
+		Inventory:            NewDeviceInventoryRepository(db, log),
+		MeterValues:          NewMeterValueRepository(db, log),
+		ChargingProfiles:     NewChargingProfileReportRepository(db, log),
+		LogRequests:          NewLogRequestRepository(db, log),
+		FirmwarePublications: NewFirmwarePublicationRepository(db, log),
+		ConnectionEvents:     NewConnectionEventRepository(db, log),
+		StationCertificates:  NewStationCertificateRepository(db, log),
+		Reconciliations:      NewReconciliationRepository(db, log),
+		Alerts:               NewAlertRepository(db, log),
+		Events:               NewEventRepository(db, log),
+		VoiceCommands:        NewVoiceCommandRepository(db, log),
+		V2G:                  NewV2GRepository(db, log),
+		ISO15118:             NewISO15118Repository(db, log),
+		Webhooks:             NewWebhookRepository(db, log),
+		Audit:                NewAuditRepository(db, log),
+		PendingCommands:      NewPendingCommandRepository(db, log),
+		NotificationPrefs:    NewNotificationPreferenceRepository(db, log),
+		Closer: func() error {
+			return Close(db)
+		},
+		Ping: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// connectorHoldingStatuses are the statuses of reservations that still block
+// their connector
+var connectorHoldingStatuses = []domain.ReservationStatus{
+	domain.ReservationStatusPending,
+	domain.ReservationStatusConfirmed,
+	domain.ReservationStatusActive,
+}
+
+// ReservationRepository implements reservation persistence
+type ReservationRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewReservationRepository creates a new reservation repository
+func NewReservationRepository(db *gorm.DB, log *zap.Logger) ports.ReservationRepository {
+	return &ReservationRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save creates or fully overwrites a reservation. Associations are not stored.
+func (r *ReservationRepository) Save(ctx context.Context, reservation *domain.Reservation) error {
+	now := time.Now()
+	if reservation.CreatedAt.IsZero() {
+		reservation.CreatedAt = now
+	}
+	reservation.UpdatedAt = now
+
+	result := r.db.WithContext(ctx).Omit(clause.Associations).Save(reservation)
+	if result.Error != nil {
+		r.log.Error("Failed to save reservation",
+			zap.String("reservationID", reservation.ID),
+			zap.String("chargePointID", reservation.ChargePointID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// GetByID retrieves a reservation, or nil
+func (r *ReservationRepository) GetByID(ctx context.Context, id string) (*domain.Reservation, error) {
+	var reservation domain.Reservation
+	if err := r.db.WithContext(ctx).First(&reservation, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// GetByUserID retrieves a user's reservations, newest first, optionally by status
+func (r *ReservationRepository) GetByUserID(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	query = query.Order("start_time DESC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var reservations []domain.Reservation
+	if err := query.Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// GetByChargePointID retrieves the reservations of a charge point starting on
+// the day of date
+func (r *ReservationRepository) GetByChargePointID(ctx context.Context, chargePointID string, date time.Time) ([]domain.Reservation, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return r.find(ctx, "charge_point_id = ? AND start_time >= ? AND start_time < ?",
+		chargePointID, startOfDay, startOfDay.Add(24*time.Hour))
+}
+
+// GetByTimeRange retrieves the reservations holding a connector at any time
+// in [startTime, endTime)
+func (r *ReservationRepository) GetByTimeRange(ctx context.Context, chargePointID string, connectorID int, startTime, endTime time.Time) ([]domain.Reservation, error) {
+	return r.find(ctx, "charge_point_id = ? AND connector_id = ? AND status IN ? AND start_time < ? AND end_time > ?",
+		chargePointID, connectorID, connectorHoldingStatuses, endTime, startTime)
+}
+
+// GetActiveByUserID retrieves a user's pending, confirmed and active reservations
+func (r *ReservationRepository) GetActiveByUserID(ctx context.Context, userID string) ([]domain.Reservation, error) {
+	return r.find(ctx, "user_id = ? AND status IN ?", userID, connectorHoldingStatuses)
+}
+
+// GetExpired retrieves the confirmed reservations whose user did not arrive
+// within gracePeriod of the start time
+func (r *ReservationRepository) GetExpired(ctx context.Context, gracePeriod time.Duration) ([]domain.Reservation, error) {
+	return r.find(ctx, "status = ? AND start_time < ?",
+		domain.ReservationStatusConfirmed, time.Now().Add(-gracePeriod))
+}
+
+// UpdateStatus sets the status of a reservation
+func (r *ReservationRepository) UpdateStatus(ctx context.Context, id string, status domain.ReservationStatus) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Reservation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()}).Error
+}
+
+// Delete removes a reservation
+func (r *ReservationRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&domain.Reservation{}, "id = ?", id).Error
+}
+
+// CountByUserAndStatus counts a user's reservations in any of statuses
+func (r *ReservationRepository) CountByUserAndStatus(ctx context.Context, userID string, statuses []domain.ReservationStatus) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Reservation{}).
+		Where("user_id = ? AND status IN ?", userID, statuses).
+		Count(&count).Error
+	return int(count), err
+}
+
+// GetByOCPPReservationID retrieves the reservation held on a charge point, or nil
+func (r *ReservationRepository) GetByOCPPReservationID(ctx context.Context, chargePointID string, reservationID int) (*domain.Reservation, error) {
+	var reservation domain.Reservation
+	err := r.db.WithContext(ctx).
+		Where("charge_point_id = ? AND reservation_id = ?", chargePointID, reservationID).
+		Order("start_time DESC").
+		First(&reservation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// find retrieves the matching reservations ordered by start time
+func (r *ReservationRepository) find(ctx context.Context, query string, args ...interface{}) ([]domain.Reservation, error) {
+	var reservations []domain.Reservation
+	err := r.db.WithContext(ctx).Where(query, args...).Order("start_time").Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// MaintenanceWindowRepository implements maintenance window persistence
+type MaintenanceWindowRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewMaintenanceWindowRepository creates a new maintenance window repository
+func NewMaintenanceWindowRepository(db *gorm.DB, log *zap.Logger) ports.MaintenanceWindowRepository {
+	return &MaintenanceWindowRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save creates or fully overwrites a maintenance window
+func (r *MaintenanceWindowRepository) Save(ctx context.Context, window *domain.MaintenanceWindow) error {
+	if window.CreatedAt.IsZero() {
+		window.CreatedAt = time.Now()
+	}
+	return r.db.WithContext(ctx).Save(window).Error
+}
+
+// FindOverlapping retrieves the windows of a charge point that overlap [from, to)
+func (r *MaintenanceWindowRepository) FindOverlapping(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+	var windows []domain.MaintenanceWindow
+	err := r.db.WithContext(ctx).
+		Where("charge_point_id = ? AND start_time < ? AND end_time > ?", chargePointID, to, from).
+		Order("start_time").
+		Find(&windows).Error
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// StationCertificateRepository implements station certificate persistence
+type StationCertificateRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewStationCertificateRepository creates a new station certificate repository
+func NewStationCertificateRepository(db *gorm.DB, log *zap.Logger) ports.StationCertificateRepository {
+	return &StationCertificateRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores an issued certificate
+func (r *StationCertificateRepository) Save(ctx context.Context, cert *domain.StationCertificate) error {
+	result := r.db.WithContext(ctx).Create(cert)
+	if result.Error != nil {
+		r.log.Error("Failed to save station certificate",
+			zap.String("chargePointID", cert.ChargePointID),
+			zap.String("serialNumber", cert.SerialNumber),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// GetLatest retrieves the most recently issued certificate of a type
+func (r *StationCertificateRepository) GetLatest(ctx context.Context, chargePointID string, certType domain.StationCertificateType) (*domain.StationCertificate, error) {
+	var cert domain.StationCertificate
+	err := r.db.WithContext(ctx).
+		Where("charge_point_id = ? AND certificate_type = ?", chargePointID, certType).
+		Order("created_at desc").
+		First(&cert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// FindExpiringBefore retrieves current certificates that expire before the
+// given time. Certificates already replaced by a newer one are ignored.
+func (r *StationCertificateRepository) FindExpiringBefore(ctx context.Context, before time.Time) ([]domain.StationCertificate, error) {
+	var certs []domain.StationCertificate
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT DISTINCT ON (charge_point_id, certificate_type) *
+			FROM station_certificates
+			ORDER BY charge_point_id, certificate_type, created_at DESC
+		) latest
+		WHERE not_after < ?
+		ORDER BY not_after`, before).
+		Scan(&certs).Error
+	if err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
@@ -63,6 +63,81 @@ func (r *TransactionRepository) Update(ctx context.Context, tx *domain.Transacti
 	return r.db.WithContext(ctx).Save(tx).Error
 }
 
+func (r *TransactionRepository) FindPage(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+	var txs []domain.Transaction
+	q := applyTransactionFilter(r.db.WithContext(ctx).Model(&domain.Transaction{}), filter)
+	if after != nil {
+		// Row comparison keeps the page boundary stable when created_at ties
+		q = q.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	err := q.Order("created_at desc, id desc").Limit(limit).Find(&txs).Error
+	return txs, err
+}
+
+func (r *TransactionRepository) Count(ctx context.Context, filter ports.TransactionFilter) (int, error) {
+	var count int64
+	err := applyTransactionFilter(r.db.WithContext(ctx).Model(&domain.Transaction{}), filter).Count(&count).Error
+	return int(count), err
+}
+
+// CompareAndSetPaymentStatus updates the payment status with a conditional
+// UPDATE, so only one concurrent caller matches from. Rows billed before
+// payment statuses existed hold NULL, which matches an empty from.
+func (r *TransactionRepository) CompareAndSetPaymentStatus(ctx context.Context, id string, from, to domain.TransactionPaymentStatus) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Transaction{}).
+		Where("id = ? AND COALESCE(payment_status, '') = ?", id, from).
+		Updates(map[string]interface{}{"payment_status": to, "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TransactionRepository) FindByPaymentStatus(ctx context.Context, status domain.TransactionPaymentStatus, updatedBefore time.Time) ([]domain.Transaction, error) {
+	var txs []domain.Transaction
+	err := r.db.WithContext(ctx).
+		Where("payment_status = ? AND updated_at < ?", status, updatedBefore).
+		Order("updated_at").
+		Find(&txs).Error
+	return txs, err
+}
+
+// FindByOCPPTransactionID retrieves the transaction a station gave
+// ocppTransactionID
+func (r *TransactionRepository) FindByOCPPTransactionID(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+	var tx domain.Transaction
+	err := r.db.WithContext(ctx).
+		Where("charge_point_id = ? AND ocpp_transaction_id = ?", chargePointID, ocppTransactionID).
+		First(&tx).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func applyTransactionFilter(q *gorm.DB, filter ports.TransactionFilter) *gorm.DB {
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != "" {
+		q = q.Where("user_id = ?", filter.UserID)
+	}
+	if filter.ChargePointID != "" {
+		q = q.Where("charge_point_id = ?", filter.ChargePointID)
+	}
+	if !filter.StartDate.IsZero() {
+		q = q.Where("created_at >= ?", filter.StartDate)
+	}
+	if !filter.EndDate.IsZero() {
+		q = q.Where("created_at < ?", filter.EndDate)
+	}
+	return q
+}
+
 func (r *TransactionRepository) FindByDate(ctx context.Context, date time.Time) ([]domain.Transaction, error) {
 	var txs []domain.Transaction
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// VoiceCommandRepository implements voice command history persistence
+type VoiceCommandRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewVoiceCommandRepository creates a new voice command repository
+func NewVoiceCommandRepository(db *gorm.DB, log *zap.Logger) ports.VoiceCommandRepository {
+	return &VoiceCommandRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save stores a processed voice command
+func (r *VoiceCommandRepository) Save(ctx context.Context, cmd *domain.VoiceCommand) error {
+	result := r.db.WithContext(ctx).Create(cmd)
+	if result.Error != nil {
+		r.log.Error("Failed to save voice command",
+			zap.String("userID", cmd.UserID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// FindByUserID retrieves a user's voice commands, newest first
+func (r *VoiceCommandRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) ([]domain.VoiceCommand, error) {
+	var cmds []domain.VoiceCommand
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&cmds).Error
+	return cmds, err
+}
+
+// AnonymizeByUserID blanks the transcript and result of a user's voice commands
+func (r *VoiceCommandRepository) AnonymizeByUserID(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.VoiceCommand{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"transcript": "", "result": ""}).Error
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// WalletRepository implements wallet persistence
+type WalletRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewWalletRepository creates a new wallet repository
+func NewWalletRepository(db *gorm.DB, log *zap.Logger) ports.WalletRepository {
+	return &WalletRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save creates or fully overwrites a wallet
+func (r *WalletRepository) Save(ctx context.Context, wallet *domain.Wallet) error {
+	return r.db.WithContext(ctx).Save(wallet).Error
+}
+
+// GetByID retrieves a wallet by ID
+func (r *WalletRepository) GetByID(ctx context.Context, id string) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	err := r.db.WithContext(ctx).First(&wallet, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// GetByUserID retrieves a user's wallet
+func (r *WalletRepository) GetByUserID(ctx context.Context, userID string) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	err := r.db.WithContext(ctx).First(&wallet, "user_id = ?", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// AdjustBalance applies delta in a single conditional UPDATE so concurrent
+// credits and debits never lose updates or overdraw the wallet
+func (r *WalletRepository) AdjustBalance(ctx context.Context, walletID string, delta float64) (*domain.Wallet, error) {
+	return r.adjustBalance(r.db.WithContext(ctx), walletID, delta)
+}
+
+// Credit adds the credit and records it in one database transaction. The
+// unique index on credited references makes a second credit for the same
+// reference fail and roll its balance change back.
+func (r *WalletRepository) Credit(ctx context.Context, entry *domain.WalletTransaction) (*domain.Wallet, error) {
+	var wallet *domain.Wallet
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		wallet, err = r.adjustBalance(tx, entry.WalletID, entry.Amount)
+		if err != nil {
+			return err
+		}
+		entry.Balance = wallet.Balance
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrAlreadyCredited
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// adjustBalance applies delta on db, which may be a transaction
+func (r *WalletRepository) adjustBalance(db *gorm.DB, walletID string, delta float64) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	result := db.
+		Model(&wallet).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND balance + ? >= 0", walletID, delta).
+		Updates(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", delta),
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		r.log.Error("Failed to adjust wallet balance",
+			zap.String("walletID", walletID),
+			zap.Error(result.Error),
+		)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var existing int64
+		if err := db.Model(&domain.Wallet{}).Where("id = ?", walletID).Count(&existing).Error; err != nil {
+			return nil, err
+		}
+		if existing == 0 {
+			return nil, fmt.Errorf("wallet not found: %s", walletID)
+		}
+		return nil, domain.ErrInsufficientBalance
+	}
+	return &wallet, nil
+}
+
+// SaveTransaction records a wallet transaction
+func (r *WalletRepository) SaveTransaction(ctx context.Context, tx *domain.WalletTransaction) error {
+	return r.db.WithContext(ctx).Create(tx).Error
+}
+
+// GetTransactions retrieves wallet transactions, newest first
+func (r *WalletRepository) GetTransactions(ctx context.Context, walletID string, limit, offset int) ([]domain.WalletTransaction, error) {
+	var txs []domain.WalletTransaction
+	err := r.db.WithContext(ctx).
+		Where("wallet_id = ?", walletID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&txs).Error
+	return txs, err
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// WebhookRepository implements webhook persistence
+type WebhookRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB, log *zap.Logger) ports.WebhookRepository {
+	return &WebhookRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Save creates or updates an endpoint
+func (r *WebhookRepository) Save(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	result := r.db.WithContext(ctx).Save(endpoint)
+	if result.Error != nil {
+		r.log.Error("Failed to save webhook endpoint",
+			zap.String("endpointID", endpoint.ID),
+			zap.String("accountID", endpoint.AccountID),
+			zap.Error(result.Error),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+// FindByID retrieves an endpoint
+func (r *WebhookRepository) FindByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	if err := r.db.WithContext(ctx).First(&endpoint, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// FindByAccountID retrieves an account's endpoints, oldest first
+func (r *WebhookRepository) FindByAccountID(ctx context.Context, accountID string) ([]domain.WebhookEndpoint, error) {
+	var endpoints []domain.WebhookEndpoint
+	err := r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at").
+		Find(&endpoints).Error
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// FindByEventType retrieves the active endpoints subscribed to an event type
+func (r *WebhookRepository) FindByEventType(ctx context.Context, eventType string) ([]domain.WebhookEndpoint, error) {
+	contains, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []domain.WebhookEndpoint
+	err = r.db.WithContext(ctx).
+		Where("active AND event_types @> ?::jsonb", string(contains)).
+		Order("created_at").
+		Find(&endpoints).Error
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// Delete removes an endpoint; its deliveries go with it
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&domain.WebhookEndpoint{}, "id = ?", id).Error
+}
+
+// SaveDelivery creates or updates a delivery
+func (r *WebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Save(delivery).Error
+}
+
+// FindDeliveries retrieves an endpoint's deliveries, newest first
+func (r *WebhookRepository) FindDeliveries(ctx context.Context, endpointID string, limit, offset int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	query := r.db.WithContext(ctx).
+		Where("endpoint_id = ?", endpointID).
+		Order("created_at desc").
+		Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
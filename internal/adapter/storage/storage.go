@@ -0,0 +1,71 @@
+// Package storage opens the storage backend selected in configuration.
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/nietzsche"
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/postgres"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Backend names a storage implementation
+type Backend string
+
+const (
+	BackendNietzsche Backend = "nietzsche"
+	BackendPostgres  Backend = "postgres"
+	BackendMemory    Backend = "memory" // Data is lost on restart; for development and tests
+)
+
+// Config selects the backend and where it lives
+type Config struct {
+	Backend     Backend
+	URL         string // PostgreSQL DSN
+	GRPCAddress string // NietzscheDB address
+
+	// Locker serializes compare-and-set and wallet balance changes across
+	// servers on NietzscheDB, which has no conditional update. Optional;
+	// without it such updates are atomic within one server only.
+	Locker ports.Locker
+}
+
+// Open connects to the configured backend, defaulting to NietzscheDB. The
+// caller owns the result and must Close it.
+func Open(cfg Config, log *zap.Logger) (*ports.Repositories, error) {
+	repos, err := open(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	if missing := repos.Missing(); len(missing) > 0 {
+		repos.Close()
+		return nil, fmt.Errorf("storage backend %q lacks repositories: %s", cfg.Backend, strings.Join(missing, ", "))
+	}
+	return repos, nil
+}
+
+func open(cfg Config, log *zap.Logger) (*ports.Repositories, error) {
+	switch cfg.Backend {
+	case BackendNietzsche, "":
+		db, err := nietzsche.NewConnection(cfg.GRPCAddress, log)
+		if err != nil {
+			return nil, err
+		}
+		return nietzsche.NewRepositories(db, cfg.Locker, log), nil
+	case BackendPostgres:
+		db, err := postgres.NewConnection(cfg.URL, log)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.NewRepositories(db, log), nil
+	case BackendMemory:
+		log.Warn("Using in-memory storage; data will be lost on restart")
+		return memory.NewRepositories(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
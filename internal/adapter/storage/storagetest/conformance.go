@@ -0,0 +1,1194 @@
+// Package storagetest is the conformance suite every storage backend runs, so
+// services behave the same whichever backend is configured.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Run checks repos against the contracts documented in ports. Repositories a
+// backend leaves nil are skipped. Every record gets a random ID so the suite
+// can run against a shared database.
+func Run(t *testing.T, repos *ports.Repositories) {
+	t.Helper()
+
+	suites := []struct {
+		name      string
+		supported bool
+		run       func(t *testing.T, repos *ports.Repositories)
+	}{
+		{"ChargePoints", repos.ChargePoints != nil, testChargePoints},
+		{"Transactions", repos.Transactions != nil, testTransactions},
+		{"Users", repos.Users != nil, testUsers},
+		{"Payments", repos.Payments != nil, testPayments},
+		{"Cards", repos.Cards != nil, testCards},
+		{"Wallets", repos.Wallets != nil, testWallets},
+		{"Reservations", repos.Reservations != nil, testReservations},
+		{"MaintenanceWindows", repos.MaintenanceWindows != nil, testMaintenanceWindows},
+		{"Inventory", repos.Inventory != nil, testInventory},
+		{"ChargingProfiles", repos.ChargingProfiles != nil, testChargingProfiles},
+		{"MeterValues", repos.MeterValues != nil, testMeterValues},
+		{"LogRequests", repos.LogRequests != nil, testLogRequests},
+		{"FirmwarePublications", repos.FirmwarePublications != nil, testFirmwarePublications},
+		{"ConnectionEvents", repos.ConnectionEvents != nil, testConnectionEvents},
+		{"StationCertificates", repos.StationCertificates != nil, testStationCertificates},
+		{"Reconciliations", repos.Reconciliations != nil, testReconciliations},
+		{"Alerts", repos.Alerts != nil, testAlerts},
+		{"Events", repos.Events != nil, testEvents},
+		{"VoiceCommands", repos.VoiceCommands != nil, testVoiceCommands},
+		{"V2G", repos.V2G != nil, testV2G},
+		{"ISO15118", repos.ISO15118 != nil, testISO15118},
+		{"Webhooks", repos.Webhooks != nil, testWebhooks},
+		{"Audit", repos.Audit != nil, testAudit},
+		{"PendingCommands", repos.PendingCommands != nil, testPendingCommands},
+		{"NotificationPrefs", repos.NotificationPrefs != nil, testNotificationPrefs},
+	}
+
+	for _, suite := range suites {
+		t.Run(suite.name, func(t *testing.T) {
+			if !suite.supported {
+				t.Skip("not provided by this backend")
+			}
+			suite.run(t, repos)
+		})
+	}
+}
+
+func newID() string {
+	return uuid.New().String()
+}
+
+// now is rounded so times survive databases with microsecond precision
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// seedChargePoint stores a charge point when the backend has the repository,
+// as SQL backends reference it from other tables
+func seedChargePoint(t *testing.T, repos *ports.Repositories) string {
+	t.Helper()
+	id := newID()
+	if repos.ChargePoints != nil {
+		must(t, repos.ChargePoints.Save(context.Background(), &domain.ChargePoint{
+			ID: id, Vendor: "Conformance", Model: "CP", Status: domain.ChargePointStatusAvailable,
+		}))
+	}
+	return id
+}
+
+// seedUser stores a user when the backend has the repository
+func seedUser(t *testing.T, repos *ports.Repositories) string {
+	t.Helper()
+	id := newID()
+	if repos.Users != nil {
+		must(t, repos.Users.Save(context.Background(), &domain.User{
+			ID: id, Name: "Conformance", Email: id + "@example.com", Document: id,
+			Password: "hash", Role: domain.UserRoleUser, Status: "Active",
+		}))
+	}
+	return id
+}
+
+func testChargePoints(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.ChargePoints
+
+	missing, err := repo.FindByID(ctx, newID())
+	must(t, err)
+	if missing != nil {
+		t.Fatalf("expected nil for an unknown charge point, got %+v", missing)
+	}
+
+	id := seedChargePoint(t, repos)
+	must(t, repo.UpdateStatus(ctx, id, domain.ChargePointStatusFaulted))
+	must(t, repo.UpdateConnectorStatus(ctx, id, 1, domain.ChargePointStatusOccupied))
+	must(t, repo.UpdateConnectorStatus(ctx, id, 1, domain.ChargePointStatusAvailable))
+	must(t, repo.UpdateHeartbeatInterval(ctx, id, 900))
+	previous, err := repo.UpdateBootInfo(ctx, id, domain.BootInfo{Model: "CP-2", FirmwareVersion: "2.1.0"})
+	must(t, err)
+	if previous != "" {
+		t.Errorf("expected no previous firmware version, got %q", previous)
+	}
+	previous, err = repo.UpdateBootInfo(ctx, id, domain.BootInfo{Vendor: "Conformance"})
+	must(t, err)
+	if previous != "2.1.0" {
+		t.Errorf("expected the previous firmware version 2.1.0, got %q", previous)
+	}
+	if _, err := repo.UpdateBootInfo(ctx, newID(), domain.BootInfo{FirmwareVersion: "1.0"}); !errors.Is(err, domain.ErrDeviceNotFound) {
+		t.Errorf("expected %v for an unknown charge point, got %v", domain.ErrDeviceNotFound, err)
+	}
+
+	cp, err := repo.FindByID(ctx, id)
+	must(t, err)
+	if cp == nil || cp.Status != domain.ChargePointStatusFaulted || cp.HeartbeatInterval != 900 {
+		t.Fatalf("expected the updated charge point, got %+v", cp)
+	}
+	if cp.Vendor != "Conformance" || cp.Model != "CP-2" || cp.FirmwareVersion != "2.1.0" {
+		t.Errorf("expected Conformance CP-2 on firmware 2.1.0, got %s %s on %q", cp.Vendor, cp.Model, cp.FirmwareVersion)
+	}
+	if len(cp.Connectors) != 1 || cp.Connectors[0].Status != domain.ChargePointStatusAvailable {
+		t.Errorf("expected one available connector, got %+v", cp.Connectors)
+	}
+
+	faulted, err := repo.FindAll(ctx, map[string]interface{}{"status": domain.ChargePointStatusFaulted})
+	must(t, err)
+	found := false
+	for _, c := range faulted {
+		found = found || c.ID == id
+		if c.Status != domain.ChargePointStatusFaulted {
+			t.Errorf("expected only faulted charge points, got %s", c.Status)
+		}
+	}
+	if !found {
+		t.Error("expected FindAll to filter by status")
+	}
+
+	// The seeded connector is a Type2 of unknown power
+	for _, tt := range []struct {
+		filter domain.ConnectorFilter
+		want   bool
+	}{
+		{domain.ConnectorFilter{Standard: "type2", AvailableNow: true}, true},
+		{domain.ConnectorFilter{Standard: "CCS2"}, false},
+		{domain.ConnectorFilter{MinPowerKW: 1}, false},
+	} {
+		matching, err := repo.FindAll(ctx, map[string]interface{}{ports.ConnectorFilterKey: tt.filter})
+		must(t, err)
+		found := false
+		for _, c := range matching {
+			found = found || c.ID == id
+		}
+		if found != tt.want {
+			t.Errorf("expected FindAll with %+v to match the charge point: %v, got %v", tt.filter, tt.want, found)
+		}
+	}
+
+	swapped, err := repo.CompareAndSetConnectorStatus(ctx, id, 1, domain.ChargePointStatusAvailable, domain.ChargePointStatusReserved)
+	must(t, err)
+	if !swapped {
+		t.Error("expected the available connector to be reserved")
+	}
+	swapped, err = repo.CompareAndSetConnectorStatus(ctx, id, 1, domain.ChargePointStatusAvailable, domain.ChargePointStatusReserved)
+	must(t, err)
+	if swapped {
+		t.Error("expected a reserved connector not to be reserved again")
+	}
+}
+
+func testTransactions(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Transactions
+	cpID := seedChargePoint(t, repos)
+	userID := seedUser(t, repos)
+
+	missing, err := repo.FindByID(ctx, newID())
+	must(t, err)
+	if missing != nil {
+		t.Fatalf("expected nil for an unknown transaction, got %+v", missing)
+	}
+
+	// Five finished transactions one minute apart, the newest one running
+	base := now().Add(-time.Hour)
+	ids := make([]string, 5)
+	for i := range ids {
+		ids[i] = newID()
+		status := domain.TransactionStatusCompleted
+		if i == len(ids)-1 {
+			status = domain.TransactionStatusStarted
+		}
+		created := base.Add(time.Duration(i) * time.Minute)
+		must(t, repo.Save(ctx, &domain.Transaction{
+			ID: ids[i], ChargePointID: cpID, ConnectorID: 1, UserID: userID, IdTag: "TAG",
+			StartTime: created, Status: status, CreatedAt: created,
+		}))
+	}
+
+	active, err := repo.FindActiveByUserID(ctx, userID)
+	must(t, err)
+	if active == nil || active.ID != ids[4] {
+		t.Fatalf("expected the running transaction, got %+v", active)
+	}
+	active.Status = domain.TransactionStatusCompleted
+	must(t, repo.Update(ctx, active))
+	if active, err = repo.FindActiveByUserID(ctx, userID); err != nil || active != nil {
+		t.Fatalf("expected no running transaction after update, got %+v, %v", active, err)
+	}
+
+	history, err := repo.FindHistoryByUserID(ctx, userID)
+	must(t, err)
+	if len(history) != 5 || history[0].ID != ids[4] {
+		t.Errorf("expected 5 transactions newest first, got %d", len(history))
+	}
+
+	// Station transaction IDs are looked up per station
+	history[1].OCPPTransactionID = "ocpp-tx-1"
+	must(t, repo.Update(ctx, &history[1]))
+	byOCPP, err := repo.FindByOCPPTransactionID(ctx, cpID, "ocpp-tx-1")
+	must(t, err)
+	if byOCPP == nil || byOCPP.ID != history[1].ID {
+		t.Errorf("expected the transaction the station named, got %+v", byOCPP)
+	}
+	if byOCPP, err = repo.FindByOCPPTransactionID(ctx, "other-station", "ocpp-tx-1"); err != nil || byOCPP != nil {
+		t.Errorf("expected nil for another station, got %+v, %v", byOCPP, err)
+	}
+
+	filter := ports.TransactionFilter{UserID: userID}
+	count, err := repo.Count(ctx, filter)
+	must(t, err)
+	if count != 5 {
+		t.Errorf("expected a count of 5, got %d", count)
+	}
+
+	// Pages of two walk the whole history in order
+	var walked []string
+	var after *ports.TransactionCursor
+	for {
+		page, err := repo.FindPage(ctx, filter, after, 2)
+		must(t, err)
+		for _, tx := range page {
+			walked = append(walked, tx.ID)
+		}
+		if len(page) < 2 {
+			break
+		}
+		last := page[len(page)-1]
+		after = &ports.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	if len(walked) != 5 {
+		t.Fatalf("expected paging to visit 5 transactions, got %v", walked)
+	}
+	for i, id := range walked {
+		if id != ids[4-i] {
+			t.Errorf("expected page order newest first, got %v", walked)
+			break
+		}
+	}
+
+	// Dates filter created_at in [StartDate, EndDate)
+	filter.StartDate = base.Add(time.Minute)
+	filter.EndDate = base.Add(3 * time.Minute)
+	count, err = repo.Count(ctx, filter)
+	must(t, err)
+	if count != 2 {
+		t.Errorf("expected 2 transactions in the date range, got %d", count)
+	}
+}
+
+func testUsers(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Users
+	id := seedUser(t, repos)
+
+	for name, find := range map[string]func() (*domain.User, error){
+		"id":       func() (*domain.User, error) { return repo.FindByID(ctx, id) },
+		"email":    func() (*domain.User, error) { return repo.FindByEmail(ctx, id+"@example.com") },
+		"document": func() (*domain.User, error) { return repo.FindByDocument(ctx, id) },
+	} {
+		user, err := find()
+		must(t, err)
+		if user == nil || user.ID != id {
+			t.Errorf("expected to find the user by %s, got %+v", name, user)
+		}
+	}
+
+	missing, err := repo.FindByEmail(ctx, newID()+"@example.com")
+	must(t, err)
+	if missing != nil {
+		t.Errorf("expected nil for an unknown email, got %+v", missing)
+	}
+}
+
+func testPayments(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Payments
+	userID := seedUser(t, repos)
+	txID := newID()
+
+	for i := 0; i < 3; i++ {
+		must(t, repo.SavePayment(ctx, &domain.Payment{
+			ID: newID(), UserID: userID, TransactionID: txID, ProviderID: "pi_" + newID(),
+			Status: domain.PaymentStatusCompleted, Amount: float64(10 + i), Currency: "BRL",
+			CreatedAt: now().Add(time.Duration(i) * time.Second),
+		}))
+	}
+	paymentID, providerID := newID(), "pi_"+newID()
+	must(t, repo.SavePayment(ctx, &domain.Payment{
+		ID: paymentID, UserID: userID, ProviderID: providerID, Status: domain.PaymentStatusPending,
+		Amount: 50, Currency: "BRL", CreatedAt: now().Add(time.Minute),
+	}))
+
+	payment, err := repo.GetPaymentByProviderID(ctx, providerID)
+	must(t, err)
+	if payment == nil || payment.ID != paymentID {
+		t.Fatalf("expected the payment by provider ID, got %+v", payment)
+	}
+	if missing, err := repo.GetPayment(ctx, newID()); err != nil || missing != nil {
+		t.Errorf("expected nil for an unknown payment, got %+v, %v", missing, err)
+	}
+
+	page, err := repo.GetPaymentsByUser(ctx, userID, 2, 0)
+	must(t, err)
+	if len(page) != 2 || page[0].ID != paymentID {
+		t.Errorf("expected a page of 2 newest first, got %+v", page)
+	}
+	byTx, err := repo.GetPaymentsByTransaction(ctx, txID)
+	must(t, err)
+	if len(byTx) != 3 {
+		t.Errorf("expected 3 payments of the transaction, got %d", len(byTx))
+	}
+
+	must(t, repo.SaveRefund(ctx, &domain.Refund{
+		ID: newID(), PaymentID: paymentID, Amount: 20, Status: domain.PaymentStatusCompleted,
+	}))
+	refunds, err := repo.GetRefundsByPayment(ctx, paymentID)
+	must(t, err)
+	if len(refunds) != 1 || refunds[0].Amount != 20 {
+		t.Errorf("expected the refund, got %+v", refunds)
+	}
+
+	eventID := newID()
+	claimed, err := repo.ClaimWebhookEvent(ctx, "stripe", eventID)
+	must(t, err)
+	again, err := repo.ClaimWebhookEvent(ctx, "stripe", eventID)
+	must(t, err)
+	if !claimed || again {
+		t.Errorf("expected a webhook event to be claimed once, got %v then %v", claimed, again)
+	}
+	if other, err := repo.ClaimWebhookEvent(ctx, "pagseguro", eventID); err != nil || !other {
+		t.Errorf("expected the same event ID of another provider to be claimable, got %v, %v", other, err)
+	}
+	must(t, repo.ReleaseWebhookEvent(ctx, "stripe", eventID))
+	if retried, err := repo.ClaimWebhookEvent(ctx, "stripe", eventID); err != nil || !retried {
+		t.Errorf("expected a released webhook event to be claimable, got %v, %v", retried, err)
+	}
+}
+
+func testCards(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Cards
+	userID := seedUser(t, repos)
+
+	first, second := newID(), newID()
+	must(t, repo.Save(ctx, &domain.PaymentCard{ID: first, UserID: userID, Last4: "4242", IsDefault: true}))
+	must(t, repo.Save(ctx, &domain.PaymentCard{ID: second, UserID: userID, Last4: "1881"}))
+	must(t, repo.SetDefault(ctx, userID, second))
+
+	cards, err := repo.GetByUserID(ctx, userID)
+	must(t, err)
+	defaults := 0
+	for _, card := range cards {
+		if card.IsDefault {
+			defaults++
+			if card.ID != second {
+				t.Errorf("expected %s to be the default card, got %s", second, card.ID)
+			}
+		}
+	}
+	if len(cards) != 2 || defaults != 1 {
+		t.Errorf("expected 2 cards with one default, got %+v", cards)
+	}
+
+	must(t, repo.Delete(ctx, first))
+	if card, err := repo.GetByID(ctx, first); err != nil || card != nil {
+		t.Errorf("expected the deleted card to be gone, got %+v, %v", card, err)
+	}
+}
+
+func testWallets(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Wallets
+	userID := seedUser(t, repos)
+	walletID := newID()
+
+	must(t, repo.Save(ctx, &domain.Wallet{ID: walletID, UserID: userID, Balance: 10, Currency: "BRL"}))
+
+	wallet, err := repo.AdjustBalance(ctx, walletID, 15)
+	must(t, err)
+	if wallet.Balance != 25 || wallet.Version != 1 {
+		t.Errorf("expected balance 25 at version 1, got %.2f at %d", wallet.Balance, wallet.Version)
+	}
+	if _, err := repo.AdjustBalance(ctx, walletID, -30); !errors.Is(err, domain.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance, got %v", err)
+	}
+	if wallet, err = repo.GetByUserID(ctx, userID); err != nil || wallet == nil || wallet.Balance != 25 {
+		t.Errorf("expected an overdraft to leave the balance at 25, got %+v, %v", wallet, err)
+	}
+
+	credit := func() (*domain.Wallet, error) {
+		return repo.Credit(ctx, &domain.WalletTransaction{
+			ID: newID(), WalletID: walletID, UserID: userID, Type: "credit", Amount: 5,
+			ReferenceID: "pay-" + walletID, CreatedAt: now(),
+		})
+	}
+	if wallet, err = credit(); err != nil || wallet.Balance != 30 {
+		t.Errorf("expected a credit to bring the balance to 30, got %+v, %v", wallet, err)
+	}
+	if _, err := credit(); !errors.Is(err, domain.ErrAlreadyCredited) {
+		t.Errorf("expected ErrAlreadyCredited for a second credit of one reference, got %v", err)
+	}
+	if wallet, err = repo.GetByID(ctx, walletID); err != nil || wallet == nil || wallet.Balance != 30 {
+		t.Errorf("expected a repeated credit to leave the balance at 30, got %+v, %v", wallet, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		must(t, repo.SaveTransaction(ctx, &domain.WalletTransaction{
+			ID: newID(), WalletID: walletID, UserID: userID, Type: "credit", Amount: 5,
+			CreatedAt: now().Add(time.Duration(i) * time.Second),
+		}))
+	}
+	history, err := repo.GetTransactions(ctx, walletID, 2, 1)
+	must(t, err)
+	if len(history) != 2 || history[0].CreatedAt.Before(history[1].CreatedAt) {
+		t.Errorf("expected a page of 2 newest first, got %+v", history)
+	}
+}
+
+func testReservations(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Reservations
+	cpID := seedChargePoint(t, repos)
+	userID := seedUser(t, repos)
+
+	start := now().Add(24 * time.Hour)
+	held := &domain.Reservation{
+		ID: newID(), UserID: userID, ChargePointID: cpID, ConnectorID: 1,
+		Status: domain.ReservationStatusConfirmed, StartTime: start, EndTime: start.Add(time.Hour),
+		OCPPReservationID: 7,
+	}
+	cancelled := &domain.Reservation{
+		ID: newID(), UserID: userID, ChargePointID: cpID, ConnectorID: 1,
+		Status: domain.ReservationStatusCancelled, StartTime: start, EndTime: start.Add(time.Hour),
+	}
+	must(t, repo.Save(ctx, held))
+	must(t, repo.Save(ctx, cancelled))
+
+	overlapping, err := repo.GetByTimeRange(ctx, cpID, 1, start.Add(30*time.Minute), start.Add(2*time.Hour))
+	must(t, err)
+	if len(overlapping) != 1 || overlapping[0].ID != held.ID {
+		t.Errorf("expected only the confirmed reservation to hold the connector, got %+v", overlapping)
+	}
+	adjacent, err := repo.GetByTimeRange(ctx, cpID, 1, start.Add(time.Hour), start.Add(2*time.Hour))
+	must(t, err)
+	if len(adjacent) != 0 {
+		t.Errorf("expected back-to-back slots not to overlap, got %+v", adjacent)
+	}
+
+	count, err := repo.CountByUserAndStatus(ctx, userID, []domain.ReservationStatus{
+		domain.ReservationStatusPending, domain.ReservationStatusConfirmed,
+	})
+	must(t, err)
+	if count != 1 {
+		t.Errorf("expected 1 pending or confirmed reservation, got %d", count)
+	}
+
+	byOCPP, err := repo.GetByOCPPReservationID(ctx, cpID, 7)
+	must(t, err)
+	if byOCPP == nil || byOCPP.ID != held.ID {
+		t.Errorf("expected the reservation by OCPP ID, got %+v", byOCPP)
+	}
+
+	must(t, repo.UpdateStatus(ctx, held.ID, domain.ReservationStatusCompleted))
+	active, err := repo.GetActiveByUserID(ctx, userID)
+	must(t, err)
+	if len(active) != 0 {
+		t.Errorf("expected no active reservations, got %+v", active)
+	}
+
+	must(t, repo.Delete(ctx, cancelled.ID))
+	if res, err := repo.GetByID(ctx, cancelled.ID); err != nil || res != nil {
+		t.Errorf("expected the deleted reservation to be gone, got %+v, %v", res, err)
+	}
+}
+
+func testMaintenanceWindows(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.MaintenanceWindows
+	cpID := seedChargePoint(t, repos)
+
+	start := now().Add(time.Hour)
+	must(t, repo.Save(ctx, &domain.MaintenanceWindow{
+		ID: newID(), ChargePointID: cpID, StartTime: start, EndTime: start.Add(2 * time.Hour), Reason: "cable swap",
+	}))
+
+	windows, err := repo.FindOverlapping(ctx, cpID, start.Add(time.Hour), start.Add(3*time.Hour))
+	must(t, err)
+	if len(windows) != 1 {
+		t.Errorf("expected the overlapping window, got %+v", windows)
+	}
+	windows, err = repo.FindOverlapping(ctx, cpID, start.Add(2*time.Hour), start.Add(3*time.Hour))
+	must(t, err)
+	if len(windows) != 0 {
+		t.Errorf("expected a window ending at from not to overlap, got %+v", windows)
+	}
+}
+
+func testInventory(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Inventory
+	cpID := seedChargePoint(t, repos)
+
+	if inv, err := repo.GetByChargePointID(ctx, cpID); err != nil || inv != nil {
+		t.Fatalf("expected nil before the first report, got %+v, %v", inv, err)
+	}
+	must(t, repo.Save(ctx, &domain.DeviceInventory{ChargePointID: cpID, RequestID: 1, ReportedAt: now()}))
+	must(t, repo.Save(ctx, &domain.DeviceInventory{ChargePointID: cpID, RequestID: 2, ReportedAt: now()}))
+
+	inv, err := repo.GetByChargePointID(ctx, cpID)
+	must(t, err)
+	if inv == nil || inv.RequestID != 2 {
+		t.Errorf("expected the latest report to replace the inventory, got %+v", inv)
+	}
+}
+
+func testChargingProfiles(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.ChargingProfiles
+	cpID := seedChargePoint(t, repos)
+
+	if report, err := repo.GetByChargePointID(ctx, cpID); err != nil || report != nil {
+		t.Fatalf("expected nil before the first report, got %+v, %v", report, err)
+	}
+	must(t, repo.Save(ctx, &domain.ChargingProfileReport{
+		ChargePointID: cpID, RequestID: 1, ReportedAt: now(),
+		Profiles: []domain.ReportedChargingProfile{{ID: 1, Purpose: "TxDefaultProfile"}, {ID: 2, Purpose: "TxProfile"}},
+	}))
+	must(t, repo.Save(ctx, &domain.ChargingProfileReport{
+		ChargePointID: cpID, RequestID: 2, ReportedAt: now(),
+		Profiles: []domain.ReportedChargingProfile{{ID: 3, EvseID: 1, Purpose: "TxProfile"}},
+	}))
+
+	report, err := repo.GetByChargePointID(ctx, cpID)
+	must(t, err)
+	if report == nil || report.RequestID != 2 || len(report.Profiles) != 1 || report.Profiles[0].ID != 3 {
+		t.Errorf("expected the latest report to replace the profiles, got %+v", report)
+	}
+}
+
+func testMeterValues(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.MeterValues
+	cpID := seedChargePoint(t, repos)
+	txID := newID()
+	start := now().Add(-2 * time.Hour)
+	must(t, repos.Transactions.Save(ctx, &domain.Transaction{
+		ID: txID, ChargePointID: cpID, ConnectorID: 1, UserID: seedUser(t, repos), IdTag: "TAG",
+		StartTime: start, Status: domain.TransactionStatusCompleted, CreatedAt: start,
+	}))
+	sample := func(minutes int, wh float64) domain.MeterSample {
+		return domain.MeterSample{
+			ID: newID(), TransactionID: txID, ChargePointID: cpID, ConnectorID: 1,
+			Timestamp: start.Add(time.Duration(minutes) * time.Minute),
+			Measurand: domain.MeasurandEnergyActiveImportRegister, Value: wh, Unit: "Wh",
+		}
+	}
+
+	// Saved out of order, read back oldest first
+	must(t, repo.Save(ctx, []domain.MeterSample{sample(10, 1500), sample(0, 1000), sample(20, 2000)}))
+	samples, err := repo.FindByTransactionID(ctx, txID)
+	must(t, err)
+	if len(samples) != 3 || samples[0].Value != 1000 || samples[2].Value != 2000 {
+		t.Fatalf("expected 3 samples oldest first, got %+v", samples)
+	}
+
+	must(t, repo.ReplaceByTransactionID(ctx, txID, []domain.MeterSample{sample(0, 1000), sample(20, 2000)}))
+	if samples, err = repo.FindByTransactionID(ctx, txID); err != nil || len(samples) != 2 {
+		t.Fatalf("expected the replaced series, got %+v, %v", samples, err)
+	}
+
+	deleted, err := repo.DeleteBefore(ctx, start.Add(time.Minute))
+	must(t, err)
+	if deleted < 1 {
+		t.Errorf("expected the oldest sample to be deleted, got %d", deleted)
+	}
+	if samples, err = repo.FindByTransactionID(ctx, txID); err != nil || len(samples) != 1 || samples[0].Value != 2000 {
+		t.Errorf("expected only the newest sample to remain, got %+v, %v", samples, err)
+	}
+}
+
+func testLogRequests(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.LogRequests
+	cpID := seedChargePoint(t, repos)
+
+	for i := 1; i <= 3; i++ {
+		must(t, repo.Save(ctx, &domain.LogRequest{
+			ID: newID(), ChargePointID: cpID, RequestID: i, LogType: "DiagnosticsLog",
+			Status: domain.LogUploadStatusAccepted, CreatedAt: now().Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	req, err := repo.FindByRequestID(ctx, cpID, 2)
+	must(t, err)
+	if req == nil || req.RequestID != 2 {
+		t.Fatalf("expected request 2, got %+v", req)
+	}
+	req.Status = domain.LogUploadStatusUploaded
+	must(t, repo.Update(ctx, req))
+	if req, err = repo.FindByRequestID(ctx, cpID, 2); err != nil || req.Status != domain.LogUploadStatusUploaded {
+		t.Errorf("expected the updated status, got %+v, %v", req, err)
+	}
+	if missing, err := repo.FindByRequestID(ctx, cpID, 99); err != nil || missing != nil {
+		t.Errorf("expected nil for an unknown request, got %+v, %v", missing, err)
+	}
+
+	latest, err := repo.FindByChargePointID(ctx, cpID, 2)
+	must(t, err)
+	if len(latest) != 2 || latest[0].RequestID != 3 {
+		t.Errorf("expected the 2 newest requests, got %+v", latest)
+	}
+}
+
+func testFirmwarePublications(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.FirmwarePublications
+	cpID := seedChargePoint(t, repos)
+
+	for i := 1; i <= 3; i++ {
+		must(t, repo.Save(ctx, &domain.FirmwarePublication{
+			ID: newID(), ChargePointID: cpID, RequestID: i, Location: "https://fw.example.com/v2.bin",
+			Checksum: "9e107d9d372bb6826bd81d3542a419d6", Status: domain.FirmwarePublishStatusAccepted,
+			CreatedAt: now().Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	pub, err := repo.FindByRequestID(ctx, cpID, 2)
+	must(t, err)
+	if pub == nil || pub.RequestID != 2 {
+		t.Fatalf("expected publication 2, got %+v", pub)
+	}
+	pub.Status = domain.FirmwarePublishStatusPublished
+	pub.PublishedURIs = []string{"http://10.0.0.2/fw.bin", "ftp://10.0.0.2/fw.bin"}
+	must(t, repo.Update(ctx, pub))
+	if pub, err = repo.FindByRequestID(ctx, cpID, 2); err != nil || pub.Status != domain.FirmwarePublishStatusPublished || len(pub.PublishedURIs) != 2 {
+		t.Errorf("expected the published status and URIs, got %+v, %v", pub, err)
+	}
+	if missing, err := repo.FindByRequestID(ctx, cpID, 99); err != nil || missing != nil {
+		t.Errorf("expected nil for an unknown publication, got %+v, %v", missing, err)
+	}
+
+	latest, err := repo.FindByChargePointID(ctx, cpID, 2)
+	must(t, err)
+	if len(latest) != 2 || latest[0].RequestID != 3 {
+		t.Errorf("expected the 2 newest publications, got %+v", latest)
+	}
+}
+
+func testConnectionEvents(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.ConnectionEvents
+	cpID := newID()
+	start := now().Add(-time.Hour)
+
+	// Saved out of order, as events of two connections may be
+	for _, event := range []domain.ConnectionEvent{
+		{Type: domain.ConnectionEventDisconnected, Reason: domain.DisconnectReasonPingTimeout, Timestamp: start.Add(20 * time.Minute)},
+		{Type: domain.ConnectionEventConnected, RemoteAddr: "10.0.0.7:4411", Timestamp: start},
+		{Type: domain.ConnectionEventConnected, RemoteAddr: "10.0.0.7:4412", Timestamp: start.Add(30 * time.Minute)},
+	} {
+		event.ID = newID()
+		event.ChargePointID = cpID
+		must(t, repo.Save(ctx, &event))
+	}
+	must(t, repo.Save(ctx, &domain.ConnectionEvent{
+		ID: newID(), ChargePointID: newID(), Type: domain.ConnectionEventConnected, Timestamp: start.Add(10 * time.Minute),
+	}))
+
+	events, err := repo.FindByChargePointID(ctx, cpID, start, start.Add(30*time.Minute))
+	must(t, err)
+	if len(events) != 2 || events[0].Type != domain.ConnectionEventConnected || events[1].Reason != domain.DisconnectReasonPingTimeout {
+		t.Fatalf("expected the connect and the disconnect, oldest first, got %+v", events)
+	}
+	if events[0].RemoteAddr != "10.0.0.7:4411" {
+		t.Errorf("expected the remote address to be kept, got %q", events[0].RemoteAddr)
+	}
+
+	last, err := repo.FindLastBefore(ctx, cpID, start.Add(25*time.Minute))
+	must(t, err)
+	if last == nil || last.Type != domain.ConnectionEventDisconnected {
+		t.Errorf("expected the disconnect, got %+v", last)
+	}
+	if last, err := repo.FindLastBefore(ctx, cpID, start); err != nil || last != nil {
+		t.Errorf("expected nil before the first event, got %+v, %v", last, err)
+	}
+}
+
+func testStationCertificates(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.StationCertificates
+	cpID := seedChargePoint(t, repos)
+	certType := domain.StationCertificateTypeChargingStation
+
+	old := &domain.StationCertificate{
+		ID: newID(), ChargePointID: cpID, CertificateType: certType, SerialNumber: newID(),
+		NotAfter: now().Add(24 * time.Hour), CreatedAt: now().Add(-time.Hour),
+	}
+	renewed := &domain.StationCertificate{
+		ID: newID(), ChargePointID: cpID, CertificateType: certType, SerialNumber: newID(),
+		NotAfter: now().Add(365 * 24 * time.Hour), CreatedAt: now(),
+	}
+	must(t, repo.Save(ctx, old))
+	must(t, repo.Save(ctx, renewed))
+
+	latest, err := repo.GetLatest(ctx, cpID, certType)
+	must(t, err)
+	if latest == nil || latest.ID != renewed.ID {
+		t.Fatalf("expected the renewed certificate, got %+v", latest)
+	}
+
+	expiring, err := repo.FindExpiringBefore(ctx, now().Add(48*time.Hour))
+	must(t, err)
+	for _, cert := range expiring {
+		if cert.ChargePointID == cpID {
+			t.Errorf("expected a replaced certificate not to be reported, got %s", cert.ID)
+		}
+	}
+}
+
+func testReconciliations(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Reconciliations
+	txID := newID()
+
+	if rec, err := repo.FindByTransactionID(ctx, txID); err != nil || rec != nil {
+		t.Fatalf("expected nil before reconciling, got %+v, %v", rec, err)
+	}
+	must(t, repo.Save(ctx, &domain.Reconciliation{
+		ID: newID(), TransactionID: txID, ComputedEnergyWh: 1000, StationEnergyWh: 1010,
+		DifferenceWh: 10, Status: domain.ReconciliationStatusMatched,
+	}))
+	rec, err := repo.FindByTransactionID(ctx, txID)
+	must(t, err)
+	if rec == nil || rec.DifferenceWh != 10 {
+		t.Errorf("expected the stored reconciliation, got %+v", rec)
+	}
+}
+
+func testAlerts(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Alerts
+	sourceID := newID()
+
+	before, err := repo.CountUnacknowledged(ctx)
+	must(t, err)
+
+	id := newID()
+	must(t, repo.Save(ctx, &ports.Alert{
+		ID: id, Type: "connector_fault", Severity: "high", Title: "Fault", SourceID: sourceID,
+	}))
+	open, err := repo.FindOpen(ctx, "connector_fault", sourceID)
+	must(t, err)
+	if len(open) != 1 || open[0].ID != id {
+		t.Fatalf("expected the open alert, got %+v", open)
+	}
+	if count, err := repo.CountUnacknowledged(ctx); err != nil || count != before+1 {
+		t.Errorf("expected %d unacknowledged alerts, got %d, %v", before+1, count, err)
+	}
+
+	must(t, repo.Resolve(ctx, id, now()))
+	must(t, repo.Acknowledge(ctx, id))
+	if open, err = repo.FindOpen(ctx, "connector_fault", sourceID); err != nil || len(open) != 0 {
+		t.Errorf("expected no open alert once resolved, got %+v, %v", open, err)
+	}
+	alert, err := repo.GetByID(ctx, id)
+	must(t, err)
+	if alert == nil || !alert.Acknowledged || !alert.Resolved || alert.ResolvedAt == nil {
+		t.Errorf("expected an acknowledged, resolved alert, got %+v", alert)
+	}
+
+	must(t, repo.Delete(ctx, id))
+	if alert, err = repo.GetByID(ctx, id); err != nil || alert != nil {
+		t.Errorf("expected the deleted alert to be gone, got %+v, %v", alert, err)
+	}
+}
+
+func testEvents(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Events
+	aggregateID := newID()
+
+	last, err := repo.LastSeq(ctx)
+	must(t, err)
+	for i := int64(1); i <= 3; i++ {
+		must(t, repo.Append(ctx, &domain.Event{
+			Seq: last + i, AggregateID: aggregateID, Subject: "transaction.started",
+			Payload: []byte(`{"id":"` + aggregateID + `"}`), CreatedAt: now(),
+		}))
+	}
+
+	taken := repo.Append(ctx, &domain.Event{
+		Seq: last + 3, AggregateID: aggregateID, Subject: "transaction.started", CreatedAt: now(),
+	})
+	if !errors.Is(taken, domain.ErrConflict) {
+		t.Errorf("expected ErrConflict appending a taken seq, got %v", taken)
+	}
+
+	if seq, err := repo.LastSeq(ctx); err != nil || seq != last+3 {
+		t.Errorf("expected last seq %d, got %d, %v", last+3, seq, err)
+	}
+	events, err := repo.FindByAggregateID(ctx, aggregateID, last+1)
+	must(t, err)
+	if len(events) != 2 || events[0].Seq != last+2 || events[1].Seq != last+3 {
+		t.Errorf("expected the 2 events after seq %d in order, got %+v", last+1, events)
+	}
+}
+
+func testVoiceCommands(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.VoiceCommands
+	userID := seedUser(t, repos)
+
+	for i := 0; i < 3; i++ {
+		must(t, repo.Save(ctx, &domain.VoiceCommand{
+			ID: newID(), UserID: userID, Transcript: "start charging", Intent: "start_charging",
+			Result: "ok", CreatedAt: now().Add(time.Duration(i) * time.Second),
+		}))
+	}
+	must(t, repo.AnonymizeByUserID(ctx, userID))
+
+	cmds, err := repo.FindByUserID(ctx, userID, 10, 0)
+	must(t, err)
+	if len(cmds) != 3 {
+		t.Fatalf("expected 3 commands, got %d", len(cmds))
+	}
+	for _, cmd := range cmds {
+		if cmd.Transcript != "" || cmd.Result != "" || cmd.Intent != "start_charging" {
+			t.Errorf("expected the transcript cleared and the intent kept, got %+v", cmd)
+		}
+	}
+	if cmds[0].CreatedAt.Before(cmds[2].CreatedAt) {
+		t.Error("expected newest first")
+	}
+}
+
+func testV2G(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.V2G
+	cpID := seedChargePoint(t, repos)
+	userID := seedUser(t, repos)
+
+	prefs, err := repo.GetPreferences(ctx, userID)
+	must(t, err)
+	if prefs == nil || prefs.UserID != userID {
+		t.Fatalf("expected default preferences, got %+v", prefs)
+	}
+	prefs.AutoDischarge = true
+	prefs.PreserveSOC = 40
+	must(t, repo.SavePreferences(ctx, prefs))
+	if prefs, err = repo.GetPreferences(ctx, userID); err != nil || !prefs.AutoDischarge || prefs.PreserveSOC != 40 {
+		t.Errorf("expected the saved preferences, got %+v, %v", prefs, err)
+	}
+
+	start := now().Add(-2 * time.Hour)
+	end := start.Add(time.Hour)
+	session := &domain.V2GSession{
+		ID: newID(), ChargePointID: cpID, ConnectorID: 1, UserID: userID,
+		Direction: domain.V2GDirectionDischarging, Status: domain.V2GStatusActive,
+		StartTime: start, CreatedAt: start,
+	}
+	must(t, repo.CreateSession(ctx, session))
+	session.EnergyTransferred = 12
+	session.UserCompensation = 9.6
+	session.EndTime = &end
+	must(t, repo.UpdateSession(ctx, session))
+
+	stored, err := repo.GetSession(ctx, session.ID)
+	must(t, err)
+	if stored == nil || stored.EnergyTransferred != 12 {
+		t.Fatalf("expected the updated session, got %+v", stored)
+	}
+	if missing, err := repo.GetSession(ctx, newID()); err != nil || missing != nil {
+		t.Errorf("expected nil for an unknown session, got %+v, %v", missing, err)
+	}
+
+	byCP, err := repo.GetSessionsByChargePoint(ctx, cpID, 10)
+	must(t, err)
+	byUser, err := repo.GetSessionsByUser(ctx, userID, 10)
+	must(t, err)
+	if len(byCP) != 1 || len(byUser) != 1 {
+		t.Errorf("expected the session by charge point and user, got %d and %d", len(byCP), len(byUser))
+	}
+
+	stats, err := repo.GetUserStats(ctx, userID, start.Add(-time.Hour), now().Add(time.Hour))
+	must(t, err)
+	if stats.TotalSessions != 1 || stats.TotalEnergyDischargedKWh != 12 || stats.TotalCompensation != 9.6 {
+		t.Errorf("expected the session in the user stats, got %+v", stats)
+	}
+	if stats, err = repo.GetChargePointStats(ctx, cpID, start.Add(-time.Hour), now().Add(time.Hour)); err != nil || stats.TotalSessions != 1 {
+		t.Errorf("expected the session in the charge point stats, got %+v, %v", stats, err)
+	}
+}
+
+func testISO15118(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.ISO15118
+	vin := newID()[:17]
+
+	valid := &domain.ISO15118Certificate{
+		ID: newID(), EMAID: "BR-EVI-" + newID(), ContractID: newID(), VehicleVIN: vin, V2GCapable: true,
+		ValidFrom: now().Add(-time.Hour), ValidTo: now().Add(365 * 24 * time.Hour),
+	}
+	expiring := &domain.ISO15118Certificate{
+		ID: newID(), EMAID: "BR-EVI-" + newID(), ContractID: newID(), VehicleVIN: vin,
+		ValidFrom: now().Add(-time.Hour), ValidTo: now().Add(24 * time.Hour),
+	}
+	must(t, repo.StoreCertificate(ctx, valid))
+	must(t, repo.StoreCertificate(ctx, expiring))
+
+	cert, err := repo.GetCertificateByEMAID(ctx, valid.EMAID)
+	must(t, err)
+	if cert == nil || cert.ID != valid.ID {
+		t.Fatalf("expected the certificate by EMAID, got %+v", cert)
+	}
+	if cert, err = repo.GetCertificateByContractID(ctx, expiring.ContractID); err != nil || cert.ID != expiring.ID {
+		t.Errorf("expected the certificate by contract ID, got %+v, %v", cert, err)
+	}
+	byVIN, err := repo.GetCertificateByVIN(ctx, vin)
+	must(t, err)
+	if len(byVIN) != 2 {
+		t.Errorf("expected both certificates of the vehicle, got %d", len(byVIN))
+	}
+
+	soon, err := repo.GetExpiringCertificates(ctx, 7)
+	must(t, err)
+	if !containsCertificate(soon, expiring.ID) || containsCertificate(soon, valid.ID) {
+		t.Errorf("expected only the certificate expiring tomorrow, got %d certificates", len(soon))
+	}
+
+	valid.Revoked = true
+	must(t, repo.UpdateCertificate(ctx, valid))
+	capable, err := repo.GetV2GCapableCertificates(ctx)
+	must(t, err)
+	if containsCertificate(capable, valid.ID) {
+		t.Error("expected a revoked certificate not to be V2G capable")
+	}
+}
+
+func containsCertificate(certs []*domain.ISO15118Certificate, id string) bool {
+	for _, cert := range certs {
+		if cert.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func testWebhooks(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Webhooks
+	accountID := newID()
+	eventType := "test." + newID()
+
+	subscribed := &domain.WebhookEndpoint{
+		ID: newID(), AccountID: accountID, URL: "https://fleet.example/hooks", Secret: "s3cret",
+		EventTypes: []string{eventType, "device.fault"}, Active: true,
+	}
+	paused := &domain.WebhookEndpoint{
+		ID: newID(), AccountID: accountID, URL: "https://fleet.example/paused", Secret: "s3cret",
+		EventTypes: []string{eventType}, Active: false,
+	}
+	must(t, repo.Save(ctx, subscribed))
+	must(t, repo.Save(ctx, paused))
+
+	found, err := repo.FindByID(ctx, subscribed.ID)
+	must(t, err)
+	if found == nil || found.Secret != "s3cret" || len(found.EventTypes) != 2 {
+		t.Fatalf("expected the endpoint with its secret and event types, got %+v", found)
+	}
+	if missing, err := repo.FindByID(ctx, newID()); err != nil || missing != nil {
+		t.Errorf("expected nil for an unknown endpoint, got %+v, %v", missing, err)
+	}
+
+	byAccount, err := repo.FindByAccountID(ctx, accountID)
+	must(t, err)
+	if len(byAccount) != 2 {
+		t.Errorf("expected both endpoints of the account, got %d", len(byAccount))
+	}
+	byEvent, err := repo.FindByEventType(ctx, eventType)
+	must(t, err)
+	if len(byEvent) != 1 || byEvent[0].ID != subscribed.ID {
+		t.Errorf("expected only the active subscriber, got %+v", byEvent)
+	}
+
+	first := &domain.WebhookDelivery{
+		ID: newID(), EndpointID: subscribed.ID, EventType: eventType, Payload: `{"n":1}`,
+		Status: domain.WebhookDeliveryPending, CreatedAt: now().Add(-time.Minute),
+	}
+	second := &domain.WebhookDelivery{
+		ID: newID(), EndpointID: subscribed.ID, EventType: eventType, Payload: `{"n":2}`,
+		Status: domain.WebhookDeliveryPending, CreatedAt: now(),
+	}
+	must(t, repo.SaveDelivery(ctx, first))
+	must(t, repo.SaveDelivery(ctx, second))
+	first.Status, first.Attempts, first.ResponseStatus = domain.WebhookDeliveryFailed, 4, 500
+	must(t, repo.SaveDelivery(ctx, first))
+
+	deliveries, err := repo.FindDeliveries(ctx, subscribed.ID, 10, 0)
+	must(t, err)
+	if len(deliveries) != 2 || deliveries[0].ID != second.ID {
+		t.Fatalf("expected two deliveries newest first, got %+v", deliveries)
+	}
+	if deliveries[1].Status != domain.WebhookDeliveryFailed || deliveries[1].Attempts != 4 {
+		t.Errorf("expected the updated delivery, got %+v", deliveries[1])
+	}
+
+	must(t, repo.Delete(ctx, subscribed.ID))
+	if found, err := repo.FindByID(ctx, subscribed.ID); err != nil || found != nil {
+		t.Errorf("expected the endpoint to be deleted, got %+v, %v", found, err)
+	}
+	if deliveries, err := repo.FindDeliveries(ctx, subscribed.ID, 10, 0); err != nil || len(deliveries) != 0 {
+		t.Errorf("expected its deliveries to go with it, got %d, %v", len(deliveries), err)
+	}
+}
+
+func testAudit(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.Audit
+	actorID := newID()
+	userID := newID()
+
+	older := &domain.AuditEntry{
+		ID: newID(), ActorID: actorID, ActorRole: "admin", SourceIP: "203.0.113.7",
+		Action: domain.AuditUserStatusUpdated, EntityType: "user", EntityID: userID,
+		Before: map[string]interface{}{"status": "Active"}, After: map[string]interface{}{"status": "Blocked"},
+		CreatedAt: now().Add(-time.Hour),
+	}
+	newer := &domain.AuditEntry{
+		ID: newID(), ActorID: actorID, ActorRole: "admin", SourceIP: "203.0.113.7",
+		Action: domain.AuditUserRoleUpdated, EntityType: "user", EntityID: userID,
+		Before: map[string]interface{}{"role": "user"}, After: map[string]interface{}{"role": "operator"},
+		CreatedAt: now(),
+	}
+	must(t, repo.Save(ctx, older))
+	must(t, repo.Save(ctx, newer))
+
+	entries, total, err := repo.Find(ctx, ports.AuditFilter{EntityID: userID}, 10, 0)
+	must(t, err)
+	if total != 2 || len(entries) != 2 || entries[0].ID != newer.ID {
+		t.Fatalf("expected both entries newest first, got %d of %d", len(entries), total)
+	}
+	if entries[0].Before["role"] != "user" || entries[0].After["role"] != "operator" || entries[0].SourceIP != "203.0.113.7" {
+		t.Errorf("expected the entry to keep its values, got %+v", entries[0])
+	}
+
+	byAction, total, err := repo.Find(ctx, ports.AuditFilter{ActorID: actorID, Action: domain.AuditUserStatusUpdated}, 10, 0)
+	must(t, err)
+	if total != 1 || len(byAction) != 1 || byAction[0].ID != older.ID {
+		t.Errorf("expected only the status change, got %d", total)
+	}
+
+	recent, total, err := repo.Find(ctx, ports.AuditFilter{EntityID: userID, From: now().Add(-time.Minute)}, 10, 0)
+	must(t, err)
+	if total != 1 || len(recent) != 1 || recent[0].ID != newer.ID {
+		t.Errorf("expected only the entry after From, got %d", total)
+	}
+
+	page, total, err := repo.Find(ctx, ports.AuditFilter{EntityID: userID}, 1, 1)
+	must(t, err)
+	if total != 2 || len(page) != 1 || page[0].ID != older.ID {
+		t.Errorf("expected the second page to hold the older entry, got %d of %d", len(page), total)
+	}
+}
+
+func testPendingCommands(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.PendingCommands
+	chargePointID := newID()
+
+	live := &domain.PendingCommand{
+		MessageID: newID(), ChargePointID: chargePointID, Action: "Reset",
+		Payload: `{"type":"Immediate"}`, ExpiresAt: now().Add(time.Minute), CreatedAt: now(),
+	}
+	expired := &domain.PendingCommand{
+		MessageID: newID(), ChargePointID: chargePointID, Action: "TriggerMessage",
+		Payload: `{"requestedMessage":"Heartbeat"}`, ExpiresAt: now().Add(-time.Minute), CreatedAt: now().Add(-2 * time.Minute),
+	}
+	answered := &domain.PendingCommand{
+		MessageID: newID(), ChargePointID: chargePointID, Action: "GetVariables",
+		Payload: `{}`, ExpiresAt: now().Add(time.Minute), CreatedAt: now(),
+	}
+	must(t, repo.Save(ctx, live))
+	must(t, repo.Save(ctx, expired))
+	must(t, repo.Save(ctx, answered))
+	must(t, repo.Delete(ctx, answered.MessageID))
+
+	found := pendingCommandsOf(t, repo, chargePointID, now().Add(-time.Hour))
+	if len(found) != 2 {
+		t.Fatalf("expected the live and expired commands, got %d", len(found))
+	}
+	found = pendingCommandsOf(t, repo, chargePointID, now())
+	if len(found) != 1 || found[0].MessageID != live.MessageID || found[0].Action != "Reset" {
+		t.Fatalf("expected only the live command, got %+v", found)
+	}
+	if found[0].Payload != live.Payload && found[0].Payload != `{"type": "Immediate"}` {
+		t.Errorf("expected the payload to be kept, got %s", found[0].Payload)
+	}
+
+	must(t, repo.DeleteExpired(ctx, now()))
+	if found := pendingCommandsOf(t, repo, chargePointID, now().Add(-time.Hour)); len(found) != 1 {
+		t.Errorf("expected the expired command to be deleted, got %d commands", len(found))
+	}
+}
+
+// pendingCommandsOf returns the commands of one charge point that expire after at
+func pendingCommandsOf(t *testing.T, repo ports.PendingCommandRepository, chargePointID string, at time.Time) []domain.PendingCommand {
+	t.Helper()
+	all, err := repo.FindUnexpired(context.Background(), at)
+	must(t, err)
+	var commands []domain.PendingCommand
+	for _, cmd := range all {
+		if cmd.ChargePointID == chargePointID {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+func testNotificationPrefs(t *testing.T, repos *ports.Repositories) {
+	ctx := context.Background()
+	repo := repos.NotificationPrefs
+	userID := newID()
+
+	if found, err := repo.FindByUserID(ctx, userID); err != nil || found != nil {
+		t.Fatalf("expected no preferences before any were saved, got %+v, %v", found, err)
+	}
+
+	prefs := &domain.NotificationPreferences{
+		UserID: userID, Email: false, Push: true, SMS: true,
+		PhoneNumber: "+5511999990000", PushToken: "fcm-token",
+		DisabledEvents: []string{domain.NotificationChargingStarted},
+	}
+	must(t, repo.Save(ctx, prefs))
+
+	found, err := repo.FindByUserID(ctx, userID)
+	must(t, err)
+	if found == nil || found.Email || !found.SMS || found.PhoneNumber != "+5511999990000" {
+		t.Fatalf("expected the saved preferences, got %+v", found)
+	}
+	if len(found.DisabledEvents) != 1 || found.DisabledEvents[0] != domain.NotificationChargingStarted {
+		t.Errorf("expected charging.started disabled, got %v", found.DisabledEvents)
+	}
+
+	found.Email = true
+	found.DisabledEvents = nil
+	must(t, repo.Save(ctx, found))
+	updated, err := repo.FindByUserID(ctx, userID)
+	must(t, err)
+	if !updated.Email || len(updated.DisabledEvents) != 0 {
+		t.Errorf("expected the update to replace the preferences, got %+v", updated)
+	}
+}
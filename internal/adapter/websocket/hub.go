@@ -2,10 +2,29 @@ package websocket
 
 import (
 	"sync"
+	"time"
 
 	"github.com/gofiber/websocket/v2"
+
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
+)
+
+const (
+	// defaultSendBuffer is how many messages a client may have queued before
+	// the hub gives up on it
+	defaultSendBuffer = 256
+	// writeWait bounds a single write to the socket
+	writeWait = 10 * time.Second
 )
 
+// hubConn is the part of the websocket connection the hub uses
+type hubConn interface {
+	ReadMessage() (int, []byte, error)
+	WriteMessage(messageType int, data []byte) error
+	SetWriteDeadline(t time.Time) error
+	Close() error
+}
+
 type Hub struct {
 	// Registered clients.
 	clients map[*Client]bool
@@ -19,13 +38,16 @@ type Hub struct {
 	// Unregister requests from clients.
 	unregister chan *Client
 
+	// Capacity of each client's send buffer.
+	sendBuffer int
+
 	mu sync.RWMutex
 }
 
 type Client struct {
 	hub *Hub
 	// The websocket connection.
-	conn *websocket.Conn
+	conn hubConn
 	// Buffered channel of outbound messages.
 	send chan []byte
 	// User ID
@@ -33,11 +55,16 @@ type Client struct {
 }
 
 func NewHub() *Hub {
+	return newHub(defaultSendBuffer)
+}
+
+func newHub(sendBuffer int) *Hub {
 	return &Hub{
 		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
+		sendBuffer: sendBuffer,
 	}
 }
 
@@ -56,28 +83,47 @@ func (h *Hub) Run() {
 			}
 			h.mu.Unlock()
 		case message := <-h.broadcast:
-			h.mu.RLock()
+			h.mu.Lock()
 			for client := range h.clients {
 				select {
 				case client.send <- message:
 				default:
-					close(client.send)
-					delete(h.clients, client)
+					// The client is not keeping up; drop it rather than
+					// stall every other client behind it
+					h.drop(client)
 				}
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
 		}
 	}
 }
 
+// drop disconnects a client whose send buffer is full. Callers hold h.mu.
+func (h *Hub) drop(client *Client) {
+	delete(h.clients, client)
+	close(client.send)
+	client.conn.Close()
+	telemetry.RecordWebSocketClientDropped()
+}
+
+// Broadcast queues a message for every connected client
+func (h *Hub) Broadcast(message []byte) {
+	h.broadcast <- message
+}
+
 func (h *Hub) AddClient(conn *websocket.Conn, userID string) {
-	client := &Client{hub: h, conn: conn, send: make(chan []byte, 256), userID: userID}
+	h.addClient(conn, userID)
+}
+
+func (h *Hub) addClient(conn hubConn, userID string) *Client {
+	client := &Client{hub: h, conn: conn, send: make(chan []byte, h.sendBuffer), userID: userID}
 	client.hub.register <- client
 
 	// Allow collection of memory referenced by the caller by doing all work in
 	// new goroutines.
 	go client.writePump()
 	go client.readPump()
+	return client
 }
 
 func (c *Client) readPump() {
@@ -94,34 +140,19 @@ func (c *Client) readPump() {
 	}
 }
 
+// writePump is the only goroutine writing to the socket, so a slow peer
+// only ever blocks its own client
 func (c *Client) writePump() {
 	defer func() {
 		c.conn.Close()
 	}()
-	for {
-		select {
-		case message, ok := <-c.send:
-			if !ok {
-				// The hub closed the channel.
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			// Add queued chat messages to the current websocket message.
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write(<-c.send)
-			}
-
-			if err := w.Close(); err != nil {
-				return
-			}
+	for message := range c.send {
+		c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			return
 		}
 	}
+	// The hub closed the channel.
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 }
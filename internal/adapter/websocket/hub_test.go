@@ -0,0 +1,128 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
+)
+
+// hubFakeConn records text frames. A stalled conn blocks every write until
+// it is closed, like a peer that stopped reading.
+type hubFakeConn struct {
+	stalled bool
+	closed  chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	written []string
+}
+
+func newHubFakeConn(stalled bool) *hubFakeConn {
+	return &hubFakeConn{stalled: stalled, closed: make(chan struct{})}
+}
+
+func (c *hubFakeConn) ReadMessage() (int, []byte, error) {
+	<-c.closed
+	return 0, nil, errors.New("connection closed")
+}
+
+func (c *hubFakeConn) WriteMessage(messageType int, data []byte) error {
+	if c.stalled {
+		<-c.closed
+		return errors.New("connection closed")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.written = append(c.written, string(data))
+	return nil
+}
+
+func (c *hubFakeConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *hubFakeConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *hubFakeConn) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.written)
+}
+
+func (c *hubFakeConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHub_DropsStalledClientWithoutBlockingOthers(t *testing.T) {
+	// Arrange
+	hub := newHub(4)
+	go hub.Run()
+
+	fast := newHubFakeConn(false)
+	stalled := newHubFakeConn(true)
+	hub.addClient(fast, "user-fast")
+	hub.addClient(stalled, "user-stalled")
+
+	dropsBefore := testutil.ToFloat64(telemetry.WebSocketClientsDropped)
+	const total = 20
+
+	// Act
+	for i := 0; i < total; i++ {
+		sent := make(chan struct{})
+		go func(i int) {
+			hub.Broadcast([]byte(fmt.Sprintf("msg-%d", i)))
+			close(sent)
+		}(i)
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatalf("broadcast %d blocked behind the stalled client", i)
+		}
+		waitUntil(t, func() bool { return fast.count() == i+1 })
+	}
+
+	// Assert
+	waitUntil(t, stalled.isClosed)
+	if fast.isClosed() {
+		t.Error("expected fast client to stay connected")
+	}
+	if got := fast.count(); got != total {
+		t.Errorf("expected fast client to receive %d messages, got %d", total, got)
+	}
+	if got := testutil.ToFloat64(telemetry.WebSocketClientsDropped) - dropsBefore; got != 1 {
+		t.Errorf("expected 1 dropped client, got %v", got)
+	}
+
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	if len(hub.clients) != 1 {
+		t.Errorf("expected 1 registered client, got %d", len(hub.clients))
+	}
+}
+
+// waitUntil polls cond until it holds or the test times out
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for condition")
+}
@@ -2,62 +2,225 @@ package websocket
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
+	"math"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"github.com/seu-repo/sigec-ve/internal/adapter/ai/gemini"
+	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/service/voice"
 	"go.uber.org/zap"
 )
 
+// connectionStateMessages are shown to the user when the assistant's
+// connection to Gemini changes
+var connectionStateMessages = map[gemini.ConnectionState]string{
+	gemini.StateReconnecting: "Reconectando ao assistente...",
+	gemini.StateConnected:    "Assistente conectado.",
+	gemini.StateFailed:       "O assistente de voz está indisponível no momento. Tente novamente mais tarde.",
+}
+
+// defaultVADThreshold is the normalized RMS energy above which inbound audio
+// counts as speech while the assistant is answering
+const defaultVADThreshold = 0.02
+
+// voiceAssistant is the part of the voice service used by the stream
+type voiceAssistant interface {
+	ProcessVoiceCommand(ctx context.Context, userID string, audioChunk []byte) (*domain.VoiceResponse, error)
+	Interrupt(ctx context.Context) error
+	ConnectionStates() (<-chan gemini.ConnectionState, func())
+}
+
+// voiceConn is the client side of the stream
+type voiceConn interface {
+	ReadMessage() (int, []byte, error)
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
 type VoiceStreamHandler struct {
-	assistant *voice.VoiceAssistant
-	logger    *zap.Logger
+	assistant    voiceAssistant
+	logger       *zap.Logger
+	vadThreshold float64
 }
 
 func NewVoiceStreamHandler(assistant *voice.VoiceAssistant, logger *zap.Logger) *VoiceStreamHandler {
 	return &VoiceStreamHandler{
-		assistant: assistant,
-		logger:    logger,
+		assistant:    assistant,
+		logger:       logger,
+		vadThreshold: defaultVADThreshold,
+	}
+}
+
+// voiceTurn is one assistant response in progress
+type voiceTurn struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (t *voiceTurn) active() bool {
+	if t == nil {
+		return false
+	}
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
 	}
 }
 
 // HandleVoiceStream gerencia o streaming bidirecional de voz
 func (h *VoiceStreamHandler) HandleVoiceStream(c *websocket.Conn) {
 	userID := c.Locals("user_id").(string)
+	h.serve(c, userID)
+}
 
-	ctx := context.Background()
+// serve processa o áudio do cliente turno a turno. Se o usuário voltar a
+// falar enquanto o assistente responde (barge-in), a resposta em andamento é
+// cancelada e o novo áudio inicia outro turno.
+func (h *VoiceStreamHandler) serve(conn voiceConn, userID string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	for {
-		// Recebe áudio do cliente (navegador)
-		messageType, audioData, err := c.ReadMessage()
-		if err != nil {
-			h.logger.Error("Erro ao ler mensagem WebSocket", zap.Error(err))
-			break
+	// Writes come from the read loop, the running turn and the state forwarder
+	var writeMu sync.Mutex
+	writeLocked := func(msg map[string]interface{}) error {
+		data, _ := json.Marshal(msg)
+		return conn.WriteMessage(websocket.TextMessage, data)
+	}
+	write := func(msg map[string]interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return writeLocked(msg)
+	}
+
+	states, unsubscribe := h.assistant.ConnectionStates()
+	defer unsubscribe()
+	go func() {
+		for state := range states {
+			text, ok := connectionStateMessages[state]
+			if !ok {
+				continue
+			}
+			if err := write(map[string]interface{}{
+				"type":    "connection_state",
+				"state":   state,
+				"message": text,
+			}); err != nil {
+				return
+			}
 		}
+	}()
+
+	startTurn := func(audioData []byte) *voiceTurn {
+		turnCtx, cancelTurn := context.WithCancel(ctx)
+		turn := &voiceTurn{cancel: cancelTurn, done: make(chan struct{})}
+
+		go func() {
+			defer close(turn.done)
 
-		if messageType == websocket.BinaryMessage {
 			// Processa áudio com Gemini
-			response, err := h.assistant.ProcessVoiceCommand(ctx, userID, audioData)
+			response, err := h.assistant.ProcessVoiceCommand(turnCtx, userID, audioData)
+			if turnCtx.Err() != nil {
+				// Interrompido pelo usuário
+				return
+			}
 			if err != nil {
 				h.logger.Error("Erro ao processar comando de voz", zap.Error(err))
-				continue
+				if errors.Is(err, gemini.ErrReconnectFailed) {
+					// Encerra a sessão de forma limpa após esgotar as tentativas
+					write(map[string]interface{}{
+						"type":  "error",
+						"error": connectionStateMessages[gemini.StateFailed],
+					})
+					conn.Close()
+				}
+				return
 			}
 
-			// Envia resposta de volta para o cliente
-			responseJSON, _ := json.Marshal(map[string]interface{}{
+			// Envia resposta de volta para o cliente, a menos que o turno
+			// tenha sido interrompido nesse meio tempo
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			if turnCtx.Err() != nil {
+				return
+			}
+			if err := writeLocked(map[string]interface{}{
 				"text":   response.Text,
 				"audio":  response.Audio, // Base64
 				"intent": response.Intent,
 				"result": response.ActionResult,
-			})
+			}); err != nil {
+				h.logger.Error("Erro ao enviar resposta", zap.Error(err))
+			}
+		}()
+
+		return turn
+	}
+
+	var turn *voiceTurn
+	defer func() {
+		if turn != nil {
+			turn.cancel()
+			<-turn.done
+		}
+	}()
 
-			if err := c.WriteMessage(websocket.TextMessage, responseJSON); err != nil {
+	for {
+		// Recebe áudio do cliente (navegador)
+		messageType, audioData, err := conn.ReadMessage()
+		if err != nil {
+			h.logger.Error("Erro ao ler mensagem WebSocket", zap.Error(err))
+			break
+		}
+		if messageType != websocket.BinaryMessage {
+			continue
+		}
+
+		if turn.active() {
+			// Ruído de fundo e eco não interrompem o assistente
+			if audioEnergy(audioData) < h.vadThreshold {
+				continue
+			}
+
+			// Barge-in: nothing from the cancelled turn is written after this
+			writeMu.Lock()
+			turn.cancel()
+			writeMu.Unlock()
+			<-turn.done
+
+			if err := h.assistant.Interrupt(ctx); err != nil {
+				h.logger.Warn("Erro ao interromper resposta do assistente", zap.Error(err))
+			}
+			if err := write(map[string]interface{}{"type": "interrupted"}); err != nil {
 				h.logger.Error("Erro ao enviar resposta", zap.Error(err))
 				break
 			}
 		}
+
+		turn = startTurn(audioData)
+	}
+}
+
+// audioEnergy returns the RMS energy of 16-bit little-endian PCM, normalized
+// to [0, 1]
+func audioEnergy(pcm []byte) float64 {
+	samples := len(pcm) / 2
+	if samples == 0 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i < samples; i++ {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+		sum += s * s
 	}
+	return math.Sqrt(sum/float64(samples)) / 32768
 }
 
 // SetupVoiceRoutes configura rotas de WebSocket para voz
@@ -0,0 +1,228 @@
+package websocket
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/websocket/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/ai/gemini"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// fakeAssistant answers the first turn only once its context is cancelled,
+// simulating a response that is still streaming when the user barges in.
+// Later turns answer immediately.
+type fakeAssistant struct {
+	mu         sync.Mutex
+	turns      int
+	interrupts int
+	started    chan struct{}
+}
+
+func (a *fakeAssistant) ProcessVoiceCommand(ctx context.Context, userID string, audioChunk []byte) (*domain.VoiceResponse, error) {
+	a.mu.Lock()
+	a.turns++
+	turn := a.turns
+	a.mu.Unlock()
+
+	if turn == 1 {
+		close(a.started)
+		<-ctx.Done()
+		// The response arrives anyway; the handler must drop it
+		return &domain.VoiceResponse{Text: "turn-1"}, nil
+	}
+	return &domain.VoiceResponse{Text: "turn-2"}, nil
+}
+
+func (a *fakeAssistant) Interrupt(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.interrupts++
+	return nil
+}
+
+func (a *fakeAssistant) ConnectionStates() (<-chan gemini.ConnectionState, func()) {
+	return make(chan gemini.ConnectionState), func() {}
+}
+
+// fakeConn feeds inbound frames from a channel and records outbound messages
+type fakeConn struct {
+	inbound chan []byte
+	closed  chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	written []map[string]interface{}
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{inbound: make(chan []byte), closed: make(chan struct{})}
+}
+
+func (c *fakeConn) ReadMessage() (int, []byte, error) {
+	select {
+	case data, ok := <-c.inbound:
+		if !ok {
+			return 0, nil, errors.New("client disconnected")
+		}
+		return websocket.BinaryMessage, data, nil
+	case <-c.closed:
+		return 0, nil, errors.New("connection closed")
+	}
+}
+
+func (c *fakeConn) WriteMessage(messageType int, data []byte) error {
+	var msg map[string]interface{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.written = append(c.written, msg)
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeConn) messages() []map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]map[string]interface{}(nil), c.written...)
+}
+
+// waitFor polls the recorded messages until cond holds
+func (c *fakeConn) waitFor(t *testing.T, cond func([]map[string]interface{}) bool) []map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if msgs := c.messages(); cond(msgs) {
+			return msgs
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out, got messages %v", c.messages())
+	return nil
+}
+
+// pcmTone returns 16-bit PCM with every sample at the given amplitude
+func pcmTone(amplitude int16, samples int) []byte {
+	pcm := make([]byte, 2*samples)
+	for i := 0; i < samples; i++ {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(amplitude))
+	}
+	return pcm
+}
+
+func newTestHandler(assistant voiceAssistant) *VoiceStreamHandler {
+	return &VoiceStreamHandler{
+		assistant:    assistant,
+		logger:       zap.NewNop(),
+		vadThreshold: defaultVADThreshold,
+	}
+}
+
+func TestVoiceStream_BargeInCutsResponse(t *testing.T) {
+	// Arrange
+	assistant := &fakeAssistant{started: make(chan struct{})}
+	conn := newFakeConn()
+	h := newTestHandler(assistant)
+
+	done := make(chan struct{})
+	go func() {
+		h.serve(conn, "user-1")
+		close(done)
+	}()
+
+	conn.inbound <- pcmTone(8000, 160)
+	<-assistant.started
+
+	// Act: the user starts talking while the first response is in progress
+	conn.inbound <- pcmTone(8000, 160)
+
+	msgs := conn.waitFor(t, func(msgs []map[string]interface{}) bool {
+		return len(msgs) > 0 && msgs[len(msgs)-1]["text"] == "turn-2"
+	})
+	close(conn.inbound)
+	<-done
+
+	// Assert
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %v", msgs)
+	}
+	if msgs[0]["type"] != "interrupted" {
+		t.Errorf("expected interrupted notice first, got %v", msgs[0])
+	}
+	for _, msg := range conn.messages() {
+		if msg["text"] == "turn-1" {
+			t.Errorf("expected interrupted response to be dropped, got %v", msg)
+		}
+	}
+	if assistant.interrupts != 1 {
+		t.Errorf("expected 1 interrupt sent to the assistant, got %d", assistant.interrupts)
+	}
+}
+
+func TestVoiceStream_QuietAudioDoesNotInterrupt(t *testing.T) {
+	// Arrange
+	assistant := &fakeAssistant{started: make(chan struct{})}
+	conn := newFakeConn()
+	h := newTestHandler(assistant)
+
+	done := make(chan struct{})
+	go func() {
+		h.serve(conn, "user-1")
+		close(done)
+	}()
+
+	conn.inbound <- pcmTone(8000, 160)
+	<-assistant.started
+
+	// Act: background noise below the VAD threshold
+	conn.inbound <- pcmTone(100, 160)
+	conn.inbound <- make([]byte, 320)
+	close(conn.inbound)
+	<-done
+
+	// Assert
+	if msgs := conn.messages(); len(msgs) != 0 {
+		t.Errorf("expected no messages, got %v", msgs)
+	}
+	if assistant.interrupts != 0 {
+		t.Errorf("expected no interrupts, got %d", assistant.interrupts)
+	}
+	if assistant.turns != 1 {
+		t.Errorf("expected quiet audio to be ignored while answering, got %d turns", assistant.turns)
+	}
+}
+
+func TestAudioEnergy(t *testing.T) {
+	tests := []struct {
+		name     string
+		pcm      []byte
+		min, max float64
+	}{
+		{"empty", nil, 0, 0},
+		{"silence", make([]byte, 320), 0, 0},
+		{"quiet", pcmTone(100, 160), 0.001, 0.01},
+		{"speech", pcmTone(8000, 160), 0.2, 0.3},
+		{"full scale", pcmTone(-32768, 160), 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := audioEnergy(tt.pcm); got < tt.min || got > tt.max {
+				t.Errorf("expected energy in [%v, %v], got %v", tt.min, tt.max, got)
+			}
+		})
+	}
+}
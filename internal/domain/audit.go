@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// AuditEntry records one administrative change: who made it, to which
+// entity, and the values before and after
+type AuditEntry struct {
+	ID         string                 `json:"id" gorm:"primaryKey"`
+	ActorID    string                 `json:"actor_id" gorm:"index"`
+	ActorRole  string                 `json:"actor_role,omitempty"`
+	SourceIP   string                 `json:"source_ip,omitempty"`
+	Action     string                 `json:"action" gorm:"index"` // e.g. user.role_updated
+	EntityType string                 `json:"entity_type"`         // user, station, alert, payment
+	EntityID   string                 `json:"entity_id"`
+	Before     map[string]interface{} `json:"before,omitempty" gorm:"serializer:json;type:jsonb"`
+	After      map[string]interface{} `json:"after,omitempty" gorm:"serializer:json;type:jsonb"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// Audited actions
+const (
+	AuditUserStatusUpdated    = "user.status_updated"
+	AuditUserRoleUpdated      = "user.role_updated"
+	AuditUserAnonymized       = "user.anonymized"
+	AuditStationStatusUpdated = "station.status_updated"
+	AuditAlertAcknowledged    = "alert.acknowledged"
+	AuditPaymentRefunded      = "payment.refunded"
+)
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,7 @@ const (
 	ChargePointStatusFaulted     ChargePointStatus = "Faulted"
 	ChargePointStatusUnavailable ChargePointStatus = "Unavailable"
 	ChargePointStatusCharging    ChargePointStatus = "Charging"
+	ChargePointStatusReserved    ChargePointStatus = "Reserved"
 )
 
 type ChargePoint struct {
@@ -27,6 +29,18 @@ type ChargePoint struct {
 	LastHeartbeat   time.Time         `json:"last_heartbeat" gorm:"column:last_heartbeat"`
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
+
+	// Heartbeat interval in seconds negotiated at the last BootNotification
+	HeartbeatInterval int `json:"heartbeat_interval,omitempty" gorm:"column:heartbeat_interval"`
+
+	// Client certificate pinning (mTLS), opt-in per device. When set, the
+	// station must present a certificate with this subject CN and/or serial.
+	CertCommonName   string `json:"cert_common_name,omitempty" gorm:"column:cert_common_name"`
+	CertSerialNumber string `json:"cert_serial_number,omitempty" gorm:"column:cert_serial_number"` // Hex, colons optional
+
+	// Derived fields, not persisted
+	AvailableConnectors int     `json:"available_connectors" gorm:"-"`
+	DistanceKM          float64 `json:"distance_km,omitempty" gorm:"-"` // Set by nearby searches
 }
 
 type Connector struct {
@@ -38,6 +52,162 @@ type Connector struct {
 	MaxPowerKW    float64           `json:"max_power_kw"`
 }
 
+// BootInfo is the identity a charge point reports in its BootNotification.
+// Empty fields were not reported.
+type BootInfo struct {
+	Vendor          string
+	Model           string
+	SerialNumber    string
+	FirmwareVersion string
+}
+
+// TimeZone returns the time zone the station keeps local time in: that of its
+// location, or the server's when it has none or the name is unknown
+func (cp *ChargePoint) TimeZone() *time.Location {
+	if cp.Location == nil || cp.Location.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(cp.Location.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// ChargePointSetting returns the value configured for chargePointID in a
+// per-station setting. The configuration loader lowercases map keys, so IDs
+// are matched case-insensitively; an exact match wins.
+func ChargePointSetting[V any](settings map[string]V, chargePointID string) (V, bool) {
+	if value, ok := settings[chargePointID]; ok {
+		return value, true
+	}
+	for id, value := range settings {
+		if strings.EqualFold(id, chargePointID) {
+			return value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// RequiresClientCertificate reports whether the device pinned a client certificate
+func (cp *ChargePoint) RequiresClientCertificate() bool {
+	return cp.CertCommonName != "" || cp.CertSerialNumber != ""
+}
+
+// HasAvailableConnector reports whether a new session can start on the device.
+// Devices without connector data fall back to the device-level status.
+func (cp *ChargePoint) HasAvailableConnector() bool {
+	if len(cp.Connectors) == 0 {
+		return cp.Status == ChargePointStatusAvailable
+	}
+	for _, c := range cp.Connectors {
+		if c.Status == ChargePointStatusAvailable {
+			return true
+		}
+	}
+	return false
+}
+
+// ConnectorStatus returns the status of one of the device's connectors, and
+// false when it has no such connector. Devices without connector data have
+// a single connector with the device-level status.
+func (cp *ChargePoint) ConnectorStatus(connectorID int) (ChargePointStatus, bool) {
+	if len(cp.Connectors) == 0 {
+		return cp.Status, connectorID == 1
+	}
+	for _, c := range cp.Connectors {
+		if c.ConnectorID == connectorID {
+			return c.Status, true
+		}
+	}
+	return "", false
+}
+
+// ConnectorRollup derives the device-level status from its connectors:
+// Available if any connector is free, Occupied if the rest are in use,
+// Faulted if every connector is faulted and Unavailable otherwise
+func (cp *ChargePoint) ConnectorRollup() ChargePointStatus {
+	if len(cp.Connectors) == 0 {
+		return cp.Status
+	}
+
+	busy, faulted := 0, 0
+	for _, c := range cp.Connectors {
+		switch c.Status {
+		case ChargePointStatusAvailable:
+			return ChargePointStatusAvailable
+		case ChargePointStatusOccupied, ChargePointStatusCharging, ChargePointStatusReserved:
+			busy++
+		case ChargePointStatusFaulted:
+			faulted++
+		}
+	}
+
+	switch {
+	case busy > 0:
+		return ChargePointStatusOccupied
+	case faulted == len(cp.Connectors):
+		return ChargePointStatusFaulted
+	default:
+		return ChargePointStatusUnavailable
+	}
+}
+
+// ApplyConnectorRollup refreshes Status and AvailableConnectors from the connectors
+func (cp *ChargePoint) ApplyConnectorRollup() {
+	cp.Status = cp.ConnectorRollup()
+	cp.AvailableConnectors = 0
+	for _, c := range cp.Connectors {
+		if c.Status == ChargePointStatusAvailable {
+			cp.AvailableConnectors++
+		}
+	}
+}
+
+// ConnectorFilter selects charge points with a connector meeting every set
+// criterion, e.g. a free CCS2 connector of at least 150 kW. The zero value
+// matches every charge point.
+type ConnectorFilter struct {
+	Standard     string  // Connector type, e.g. CCS2; case-insensitive
+	MinPowerKW   float64 // Minimum connector power
+	AvailableNow bool    // Connector is free to start a session
+}
+
+// IsZero reports whether the filter matches every charge point
+func (f ConnectorFilter) IsZero() bool {
+	return f == ConnectorFilter{}
+}
+
+// Matches reports whether one of the charge point's connectors meets the
+// filter. Devices without connector data only match an availability filter,
+// against their device-level status.
+func (f ConnectorFilter) Matches(cp *ChargePoint) bool {
+	if f.IsZero() {
+		return true
+	}
+	if len(cp.Connectors) == 0 {
+		return f.Standard == "" && f.MinPowerKW == 0 && cp.HasAvailableConnector()
+	}
+	for _, c := range cp.Connectors {
+		if f.MatchesConnector(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesConnector reports whether a connector meets the filter
+func (f ConnectorFilter) MatchesConnector(c Connector) bool {
+	if f.Standard != "" && !strings.EqualFold(c.Type, f.Standard) {
+		return false
+	}
+	if c.MaxPowerKW < f.MinPowerKW {
+		return false
+	}
+	return !f.AvailableNow || c.Status == ChargePointStatusAvailable
+}
+
 type Location struct {
 	ID        string  `json:"id" gorm:"primaryKey"`
 	Name      string  `json:"name"`
@@ -47,4 +217,16 @@ type Location struct {
 	City      string  `json:"city"`
 	State     string  `json:"state"`
 	Country   string  `json:"country"`
+	Timezone  string  `json:"timezone,omitempty"` // IANA name, e.g. America/Manaus; empty for the server's
+}
+
+// ConnectorFault is a fault reported by a charge point for one connector.
+// ConnectorID 0 refers to the charge point as a whole.
+type ConnectorFault struct {
+	ChargePointID   string    `json:"charge_point_id"`
+	ConnectorID     int       `json:"connector_id"`
+	ErrorCode       string    `json:"error_code,omitempty"`        // OCPP ChargePointErrorCode, e.g. "GroundFailure"
+	VendorErrorCode string    `json:"vendor_error_code,omitempty"` // Vendor-specific code, free form
+	Info            string    `json:"info,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
 }
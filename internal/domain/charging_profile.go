@@ -0,0 +1,32 @@
+package domain
+
+// ChargingProfile is a charging profile to set on a charge point, with the
+// fields OCPP 2.0.1 gives one. The OCPP adapter maps it to the station's
+// message.
+type ChargingProfile struct {
+	ID                     int                `json:"id"`
+	StackLevel             int                `json:"stack_level"`
+	ChargingProfilePurpose string             `json:"purpose"`                   // ChargingStationMaxProfile, TxDefaultProfile, TxProfile
+	ChargingProfileKind    string             `json:"kind"`                      // Absolute, Recurring, Relative
+	RecurrencyKind         string             `json:"recurrency_kind,omitempty"` // Daily or Weekly, for Recurring profiles
+	ValidFrom              *string            `json:"valid_from,omitempty"`
+	ValidTo                *string            `json:"valid_to,omitempty"`
+	ChargingSchedule       []ChargingSchedule `json:"charging_schedule"`
+}
+
+// ChargingSchedule is one schedule of a ChargingProfile
+type ChargingSchedule struct {
+	ID                     int                      `json:"id"`
+	StartSchedule          *string                  `json:"start_schedule,omitempty"`
+	Duration               *int                     `json:"duration,omitempty"` // Seconds
+	ChargingRateUnit       string                   `json:"charging_rate_unit"` // W or A
+	MinChargingRate        *float64                 `json:"min_charging_rate,omitempty"`
+	ChargingSchedulePeriod []ChargingSchedulePeriod `json:"periods"`
+}
+
+// ChargingSchedulePeriod is one period of a ChargingSchedule
+type ChargingSchedulePeriod struct {
+	StartPeriod  int     `json:"start_period"` // Seconds from the start of the schedule
+	Limit        float64 `json:"limit"`
+	NumberPhases *int    `json:"number_phases,omitempty"`
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// ChargingProfileReport is the set of charging profiles a charge point
+// reported via ReportChargingProfiles in answer to GetChargingProfiles
+type ChargingProfileReport struct {
+	ChargePointID string                    `json:"charge_point_id" gorm:"primaryKey"`
+	RequestID     int                       `json:"request_id"`
+	Profiles      []ReportedChargingProfile `json:"profiles" gorm:"serializer:json;type:jsonb"`
+	ReportedAt    time.Time                 `json:"reported_at"`
+}
+
+// ReportedChargingProfile is a charging profile installed on a charge point
+type ReportedChargingProfile struct {
+	ID                  int                        `json:"id"`
+	EvseID              int                        `json:"evse_id"`               // 0 for the whole station
+	ChargingLimitSource string                     `json:"charging_limit_source"` // CSO, EMS, SO or Other
+	StackLevel          int                        `json:"stack_level"`
+	Purpose             string                     `json:"purpose"`
+	Kind                string                     `json:"kind"`
+	RecurrencyKind      string                     `json:"recurrency_kind,omitempty"`
+	ValidFrom           *time.Time                 `json:"valid_from,omitempty"`
+	ValidTo             *time.Time                 `json:"valid_to,omitempty"`
+	Schedules           []ReportedChargingSchedule `json:"schedules"`
+}
+
+// ReportedChargingSchedule is one schedule of a reported charging profile
+type ReportedChargingSchedule struct {
+	ID               int                      `json:"id"`
+	StartSchedule    *time.Time               `json:"start_schedule,omitempty"`
+	Duration         *int                     `json:"duration,omitempty"` // Seconds
+	ChargingRateUnit string                   `json:"charging_rate_unit"` // W or A
+	MinChargingRate  *float64                 `json:"min_charging_rate,omitempty"`
+	Periods          []ReportedSchedulePeriod `json:"periods"`
+}
+
+// ReportedSchedulePeriod is one period of a reported charging schedule
+type ReportedSchedulePeriod struct {
+	StartPeriod  int     `json:"start_period"` // Seconds from the start of the schedule
+	Limit        float64 `json:"limit"`
+	NumberPhases *int    `json:"number_phases,omitempty"`
+}
+
+// ChargingLimit is the power limit an EVSE is held to right now by its
+// charging profiles, and the profile that sets it
+type ChargingLimit struct {
+	EvseID     int     `json:"evse_id"` // 0 for the whole station
+	LimitW     float64 `json:"limit_w"`
+	RatedW     float64 `json:"rated_w,omitempty"` // 0 when the rating is unknown
+	Throttled  bool    `json:"throttled"`         // Below the rating, or limited with the rating unknown
+	ProfileID  int     `json:"profile_id"`
+	Purpose    string  `json:"purpose"`
+	StackLevel int     `json:"stack_level"`
+	Source     string  `json:"source"` // Who set the profile: CSO, EMS, SO or Other
+}
@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+// ConnectionEventType says whether a charge point's websocket opened or closed
+type ConnectionEventType string
+
+const (
+	ConnectionEventConnected    ConnectionEventType = "connected"
+	ConnectionEventDisconnected ConnectionEventType = "disconnected"
+)
+
+// Why a connection ended
+const (
+	DisconnectReasonClosed      = "closed"       // The station sent a close frame
+	DisconnectReasonPingTimeout = "ping_timeout" // The station stopped answering pings
+	DisconnectReasonReplaced    = "replaced"     // The station opened a new connection
+	DisconnectReasonError       = "error"        // The connection broke
+)
+
+// ConnectionEvent records a charge point connecting to or disconnecting from
+// the CSMS
+type ConnectionEvent struct {
+	ID            string              `json:"id" gorm:"primaryKey"`
+	ChargePointID string              `json:"charge_point_id" gorm:"index"`
+	Type          ConnectionEventType `json:"type"`
+	RemoteAddr    string              `json:"remote_addr,omitempty"`
+	Reason        string              `json:"reason,omitempty"` // Set on disconnects
+	Timestamp     time.Time           `json:"timestamp"`
+}
+
+// DefaultUptimeWindow is how far back uptime is measured when no period is given
+const DefaultUptimeWindow = 7 * 24 * time.Hour
+
+// UptimePercent returns the share of [from, to) a charge point was
+// connected. last is its last event before from and events are its events
+// in the window, oldest first. Without a last event the window starts at
+// the first event, so a new station is not counted as down before it was
+// installed. Two connects in a row mean a disconnect was lost, e.g. to a
+// restart, and count as connected.
+func UptimePercent(last *ConnectionEvent, events []ConnectionEvent, from, to time.Time) float64 {
+	if last == nil && len(events) > 0 && events[0].Timestamp.After(from) {
+		from = events[0].Timestamp
+	}
+	window := to.Sub(from)
+	if window <= 0 {
+		return 0
+	}
+
+	connected := last != nil && last.Type == ConnectionEventConnected
+	since := from
+	var up time.Duration
+	for _, event := range events {
+		at := event.Timestamp
+		if at.Before(from) {
+			at = from
+		}
+		if at.After(to) {
+			break
+		}
+		if connected {
+			up += at.Sub(since)
+		}
+		connected = event.Type == ConnectionEventConnected
+		since = at
+	}
+	if connected {
+		up += to.Sub(since)
+	}
+	return float64(up) / float64(window) * 100
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConnectorQR is the connector a walk-up user scanned. The QR code printed on
+// each connector encodes "stationID:connectorID".
+type ConnectorQR struct {
+	StationID   string
+	ConnectorID int
+}
+
+// ParseConnectorQR reads a decoded connector QR code. The station ID is
+// everything before the last colon, so station IDs may contain colons.
+func ParseConnectorQR(code string) (ConnectorQR, error) {
+	code = strings.TrimSpace(code)
+	sep := strings.LastIndex(code, ":")
+	if sep <= 0 {
+		return ConnectorQR{}, fmt.Errorf("%w: QR code %q is not stationID:connectorID", ErrValidation, code)
+	}
+
+	connectorID, err := strconv.Atoi(code[sep+1:])
+	if err != nil || connectorID < 1 {
+		return ConnectorQR{}, fmt.Errorf("%w: QR code %q has no valid connector ID", ErrValidation, code)
+	}
+	return ConnectorQR{StationID: code[:sep], ConnectorID: connectorID}, nil
+}
+
+func (q ConnectorQR) String() string {
+	return q.StationID + ":" + strconv.Itoa(q.ConnectorID)
+}
@@ -0,0 +1,61 @@
+package domain
+
+import "errors"
+
+// Generic error kinds. Services wrap them with fmt.Errorf("...: %w", ErrX)
+// when no more specific sentinel exists, so the HTTP layer can still map
+// the error to the right status.
+var (
+	ErrNotFound     = errors.New("resource not found")
+	ErrConflict     = errors.New("resource conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrValidation   = errors.New("invalid request")
+)
+
+// ErrDeviceNotFound is returned when a charge point does not exist
+var ErrDeviceNotFound = errors.New("device not found")
+
+// ErrConnectorUnavailable is returned when a session cannot take a connector
+// because it is not Available
+var ErrConnectorUnavailable = errors.New("connector not available")
+
+// ErrUnknownConnector is returned when a connector QR code does not name a
+// connector of a known charge point
+var ErrUnknownConnector = errors.New("unknown connector")
+
+// ErrChargePointNotConnected is returned when a command needs a charge point
+// that has no open OCPP connection
+var ErrChargePointNotConnected = errors.New("charge point not connected")
+
+// ErrCommandRejected is returned when a charge point answers a command with
+// a status other than the one asked for, e.g. Rejected
+var ErrCommandRejected = errors.New("command rejected by charge point")
+
+// ErrTransactionNotFound is returned when a transaction does not exist
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrInventoryNotFound is returned when a charge point has not reported its device model yet
+var ErrInventoryNotFound = errors.New("inventory not reported yet")
+
+// ErrChargingProfilesNotFound is returned when a charge point has not reported its charging profiles yet
+var ErrChargingProfilesNotFound = errors.New("charging profiles not reported yet")
+
+// ErrOutsideGeofence is returned when a user's location is too far from the
+// station to act on their behalf
+var ErrOutsideGeofence = errors.New("outside the station geofence")
+
+// ErrFirmwareOperationInProgress is returned when a firmware update or
+// publication would overlap one the charge point is still running
+var ErrFirmwareOperationInProgress = errors.New("firmware operation already in progress")
+
+// ErrInvalidCredentials is returned when a login does not match any user
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ErrInvalidToken is returned when an access or refresh token cannot be used
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrEmailAlreadyRegistered is returned when registering an email that is already in use
+var ErrEmailAlreadyRegistered = errors.New("email already registered")
+
+// ErrDocumentAlreadyRegistered is returned when registering a CPF that is already in use
+var ErrDocumentAlreadyRegistered = errors.New("cpf already registered")
@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is a domain event appended to the event log before it is published
+// to the message queue. Seq is assigned on append and grows monotonically.
+type Event struct {
+	Seq         int64           `json:"seq"`
+	AggregateID string          `json:"aggregate_id"` // Transaction, V2G session or payment the event belongs to
+	Subject     string          `json:"subject"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
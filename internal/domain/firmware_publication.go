@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// FirmwarePublishStatus is the state of a firmware publication. After the
+// local controller accepts PublishFirmware it follows
+// PublishFirmwareStatusNotification (OCPP 2.0.1 PublishFirmwareStatusEnumType).
+type FirmwarePublishStatus string
+
+const (
+	FirmwarePublishStatusAccepted          FirmwarePublishStatus = "Accepted"
+	FirmwarePublishStatusIdle              FirmwarePublishStatus = "Idle"
+	FirmwarePublishStatusDownloadScheduled FirmwarePublishStatus = "DownloadScheduled"
+	FirmwarePublishStatusDownloading       FirmwarePublishStatus = "Downloading"
+	FirmwarePublishStatusDownloadPaused    FirmwarePublishStatus = "DownloadPaused"
+	FirmwarePublishStatusDownloaded        FirmwarePublishStatus = "Downloaded"
+	FirmwarePublishStatusChecksumVerified  FirmwarePublishStatus = "ChecksumVerified"
+	FirmwarePublishStatusPublished         FirmwarePublishStatus = "Published"
+	FirmwarePublishStatusDownloadFailed    FirmwarePublishStatus = "DownloadFailed"
+	FirmwarePublishStatusInvalidChecksum   FirmwarePublishStatus = "InvalidChecksum"
+	FirmwarePublishStatusPublishFailed     FirmwarePublishStatus = "PublishFailed"
+)
+
+// publishStage orders the publish statuses; a publication never moves to an
+// earlier stage. Pausing and resuming a download stay in the same stage.
+var publishStage = map[FirmwarePublishStatus]int{
+	FirmwarePublishStatusAccepted:          0,
+	FirmwarePublishStatusDownloadScheduled: 1,
+	FirmwarePublishStatusDownloading:       2,
+	FirmwarePublishStatusDownloadPaused:    2,
+	FirmwarePublishStatusDownloaded:        3,
+	FirmwarePublishStatusChecksumVerified:  4,
+	FirmwarePublishStatusPublished:         5,
+	FirmwarePublishStatusDownloadFailed:    5,
+	FirmwarePublishStatusInvalidChecksum:   5,
+	FirmwarePublishStatusPublishFailed:     5,
+	FirmwarePublishStatusIdle:              5,
+}
+
+// Finished reports whether no more status notifications are expected.
+// Idle means the controller is not publishing anything, e.g. after a reboot.
+func (s FirmwarePublishStatus) Finished() bool {
+	return publishStage[s] == publishStage[FirmwarePublishStatusPublished]
+}
+
+// FirmwarePublication tracks a PublishFirmware request sent to a local
+// controller that fetches firmware once and serves it to the stations behind it
+type FirmwarePublication struct {
+	ID            string                `json:"id" gorm:"primaryKey"`
+	ChargePointID string                `json:"charge_point_id" gorm:"index"` // The local controller
+	RequestID     int                   `json:"request_id"`                   // OCPP requestId, unique per charge point
+	Location      string                `json:"location"`                     // Where the controller downloads the firmware
+	Checksum      string                `json:"checksum"`                     // MD5 of the firmware file
+	Status        FirmwarePublishStatus `json:"status"`
+	PublishedURIs []string              `json:"published_uris,omitempty" gorm:"serializer:json;type:jsonb"` // Where peers fetch it; set once Published
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+// MarkStatus records a PublishFirmwareStatusNotification. Notifications that
+// would move the publication back, e.g. a late Downloading after Published,
+// are ignored; it reports whether the status was applied.
+func (p *FirmwarePublication) MarkStatus(status FirmwarePublishStatus, uris []string, at time.Time) bool {
+	stage, known := publishStage[status]
+	if !known || p.Status.Finished() || stage < publishStage[p.Status] {
+		return false
+	}
+	p.Status = status
+	p.UpdatedAt = at
+	if status == FirmwarePublishStatusPublished {
+		p.PublishedURIs = uris
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultHeartbeatInterval is the heartbeat interval in seconds returned in
+// BootNotification when no policy applies
+const DefaultHeartbeatInterval = 300
+
+// heartbeatMissTolerance is how many heartbeat intervals may pass without a
+// heartbeat before the device is considered offline
+const heartbeatMissTolerance = 2
+
+// HeartbeatPolicy picks the heartbeat interval a charge point is told to use.
+// A per-device interval wins over a per-vendor one, which wins over Default.
+// Vendor names and charge point IDs are matched case-insensitively.
+type HeartbeatPolicy struct {
+	Default       int            // Seconds; DefaultHeartbeatInterval when zero
+	ByVendor      map[string]int // Vendor name -> seconds
+	ByChargePoint map[string]int // Charge point ID -> seconds
+}
+
+// IntervalFor returns the heartbeat interval in seconds for a charge point
+func (p HeartbeatPolicy) IntervalFor(chargePointID, vendor string) int {
+	if interval, _ := ChargePointSetting(p.ByChargePoint, chargePointID); interval > 0 {
+		return interval
+	}
+	for name, interval := range p.ByVendor {
+		if interval > 0 && strings.EqualFold(name, vendor) {
+			return interval
+		}
+	}
+	if p.Default > 0 {
+		return p.Default
+	}
+	return DefaultHeartbeatInterval
+}
+
+// HeartbeatPeriod returns the negotiated heartbeat interval, falling back to
+// DefaultHeartbeatInterval for devices that never booted with a policy
+func (cp *ChargePoint) HeartbeatPeriod() time.Duration {
+	interval := cp.HeartbeatInterval
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	return time.Duration(interval) * time.Second
+}
+
+// IsOnline reports whether the device was seen recently enough for its
+// heartbeat interval, tolerating one missed heartbeat
+func (cp *ChargePoint) IsOnline(now time.Time) bool {
+	return now.Sub(cp.LastHeartbeat) < heartbeatMissTolerance*cp.HeartbeatPeriod()
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "fmt"
+
+// IdTokenType is how an idToken identifies the driver (OCPP 2.0.1 IdTokenEnumType)
+type IdTokenType string
+
+const (
+	IdTokenTypeCentral         IdTokenType = "Central" // Generated by the CSMS, e.g. for app starts
+	IdTokenTypeEMAID           IdTokenType = "eMAID"   // e-Mobility Account ID, ISO 15118 contracts
+	IdTokenTypeISO14443        IdTokenType = "ISO14443"
+	IdTokenTypeISO15693        IdTokenType = "ISO15693"
+	IdTokenTypeKeyCode         IdTokenType = "KeyCode" // Entered on the station's keypad
+	IdTokenTypeLocal           IdTokenType = "Local"
+	IdTokenTypeMacAddress      IdTokenType = "MacAddress"
+	IdTokenTypeNoAuthorization IdTokenType = "NoAuthorization"
+)
+
+// DefaultIdTokenType is used when a caller does not say, as RFID cards are
+// the most common token
+const DefaultIdTokenType = IdTokenTypeISO14443
+
+// ParseIdTokenType validates an idToken type; empty means DefaultIdTokenType
+func ParseIdTokenType(s string) (IdTokenType, error) {
+	switch t := IdTokenType(s); t {
+	case "":
+		return DefaultIdTokenType, nil
+	case IdTokenTypeCentral, IdTokenTypeEMAID, IdTokenTypeISO14443, IdTokenTypeISO15693,
+		IdTokenTypeKeyCode, IdTokenTypeLocal, IdTokenTypeMacAddress, IdTokenTypeNoAuthorization:
+		return t, nil
+	default:
+		return "", fmt.Errorf("%w: unknown idToken type %q", ErrValidation, s)
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// DeviceInventory is the component/variable model reported by a charge point
+type DeviceInventory struct {
+	ChargePointID string              `json:"charge_point_id" gorm:"primaryKey"`
+	RequestID     int                 `json:"request_id"`
+	Variables     []InventoryVariable `json:"variables" gorm:"serializer:json;type:jsonb"`
+	ReportedAt    time.Time           `json:"reported_at"`
+}
+
+// InventoryVariable is a single variable of a charge point component
+type InventoryVariable struct {
+	Component          string               `json:"component"`
+	ComponentInstance  string               `json:"component_instance,omitempty"`
+	EvseID             *int                 `json:"evse_id,omitempty"`
+	ConnectorID        *int                 `json:"connector_id,omitempty"`
+	Variable           string               `json:"variable"`
+	VariableInstance   string               `json:"variable_instance,omitempty"`
+	Attributes         []InventoryAttribute `json:"attributes"`
+	Unit               string               `json:"unit,omitempty"`
+	DataType           string               `json:"data_type,omitempty"`
+	MinLimit           *float64             `json:"min_limit,omitempty"`
+	MaxLimit           *float64             `json:"max_limit,omitempty"`
+	SupportsMonitoring bool                 `json:"supports_monitoring"`
+}
+
+// InventoryAttribute is one attribute (Actual, Target, MinSet, MaxSet) of a variable
+type InventoryAttribute struct {
+	Type       string `json:"type"`
+	Value      string `json:"value,omitempty"`
+	Mutability string `json:"mutability,omitempty"`
+}
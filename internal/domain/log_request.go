@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// LogUploadStatus is the state of a diagnostics log upload. After the charge
+// point accepts GetLog it follows LogStatusNotification (OCPP 2.0.1 UploadLogStatusEnumType).
+type LogUploadStatus string
+
+const (
+	LogUploadStatusAccepted      LogUploadStatus = "Accepted"
+	LogUploadStatusUploading     LogUploadStatus = "Uploading"
+	LogUploadStatusUploaded      LogUploadStatus = "Uploaded"
+	LogUploadStatusUploadFailure LogUploadStatus = "UploadFailure"
+)
+
+// LogRequest tracks a GetLog request sent to a charge point
+type LogRequest struct {
+	ID             string          `json:"id" gorm:"primaryKey"`
+	ChargePointID  string          `json:"charge_point_id" gorm:"index"`
+	RequestID      int             `json:"request_id"` // OCPP requestId, unique per charge point
+	LogType        string          `json:"log_type"`   // DiagnosticsLog or SecurityLog
+	RemoteLocation string          `json:"remote_location"`
+	Filename       string          `json:"filename,omitempty"` // Name of the uploaded file, reported by the charge point
+	Status         LogUploadStatus `json:"status"`
+	FileURL        string          `json:"file_url,omitempty"` // Where the file landed; set once Uploaded
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// MarkStatus records a LogStatusNotification. Reaching Uploaded stores the
+// location of the file.
+func (r *LogRequest) MarkStatus(status LogUploadStatus, at time.Time) {
+	r.Status = status
+	r.UpdatedAt = at
+	if status == LogUploadStatusUploaded {
+		r.FileURL = r.RemoteLocation
+		if r.Filename != "" {
+			r.FileURL = strings.TrimSuffix(r.RemoteLocation, "/") + "/" + r.Filename
+		}
+	}
+}
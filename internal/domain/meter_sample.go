@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// MeasurandEnergyActiveImportRegister is the cumulative energy register and
+// the measurand a sampled value without one refers to
+const MeasurandEnergyActiveImportRegister = "Energy.Active.Import.Register"
+
+// Sampling contexts that mark the meter readings a session is billed from
+const (
+	MeterContextTransactionBegin = "Transaction.Begin"
+	MeterContextTransactionEnd   = "Transaction.End"
+)
+
+// MeterSample is one sampled value a charge point reported during a transaction
+type MeterSample struct {
+	ID            string    `json:"id" gorm:"primaryKey"`
+	TransactionID string    `json:"transaction_id" gorm:"index"`
+	ChargePointID string    `json:"charge_point_id"`
+	ConnectorID   int       `json:"connector_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	Measurand     string    `json:"measurand"`
+	Value         float64   `json:"value"`
+	Unit          string    `json:"unit,omitempty"`
+	Phase         string    `json:"phase,omitempty"`
+	Location      string    `json:"location,omitempty"`
+	Context       string    `json:"context,omitempty"`
+}
+
+// TableName keeps the table name of the initial schema
+func (MeterSample) TableName() string {
+	return "meter_values"
+}
+
+// DownsampleMeterSamples keeps one sample per interval of each measurand,
+// phase and location: the last one of the interval. The first and last sample of every
+// series and the Transaction.Begin and Transaction.End readings are always
+// kept, so the energy between the first and last register reading, and with
+// it the billed total, does not change. The result is oldest first.
+func DownsampleMeterSamples(samples []MeterSample, interval time.Duration) []MeterSample {
+	if interval <= 0 || len(samples) == 0 {
+		return samples
+	}
+
+	type seriesKey struct{ measurand, phase, location string }
+	series := make(map[seriesKey][]MeterSample)
+	var keys []seriesKey
+	for _, sample := range samples {
+		key := seriesKey{sample.Measurand, sample.Phase, sample.Location}
+		if _, ok := series[key]; !ok {
+			keys = append(keys, key)
+		}
+		series[key] = append(series[key], sample)
+	}
+
+	var kept []MeterSample
+	for _, key := range keys {
+		points := series[key]
+		sort.SliceStable(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+
+		start := points[0].Timestamp
+		bucket := func(s MeterSample) int64 { return int64(s.Timestamp.Sub(start) / interval) }
+		for i, point := range points {
+			first, last := i == 0, i == len(points)-1
+			billed := point.Context == MeterContextTransactionBegin || point.Context == MeterContextTransactionEnd
+			lastOfBucket := !last && bucket(points[i+1]) != bucket(point)
+			if first || last || billed || lastOfBucket {
+				kept = append(kept, point)
+			}
+		}
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Timestamp.Before(kept[j].Timestamp) })
+	return kept
+}
@@ -0,0 +1,119 @@
+package domain
+
+import "time"
+
+// NotificationChannel is a way of reaching a user
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelPush  NotificationChannel = "push"
+	NotificationChannelSMS   NotificationChannel = "sms"
+)
+
+// Notification event types published on notifications.events
+const (
+	NotificationChargingStarted     = "charging.started"
+	NotificationChargingCompleted   = "charging.completed"
+	NotificationPaymentFailed       = "payment.failed"
+	NotificationLowBalance          = "balance.low"
+	NotificationBalanceExhausted    = "balance.exhausted" // A prepaid session was stopped
+	NotificationSessionLimit        = "session.limit"     // A session is about to reach, or reached, its maximum duration
+	NotificationReservationReminder = "reservation.reminder"
+)
+
+// notificationTypes are the event types users are notified about
+var notificationTypes = map[string]bool{
+	NotificationChargingStarted:     true,
+	NotificationChargingCompleted:   true,
+	NotificationPaymentFailed:       true,
+	NotificationLowBalance:          true,
+	NotificationBalanceExhausted:    true,
+	NotificationSessionLimit:        true,
+	NotificationReservationReminder: true,
+}
+
+// NotificationTypes lists the event types users are notified about
+func NotificationTypes() []string {
+	return []string{
+		NotificationChargingStarted,
+		NotificationChargingCompleted,
+		NotificationPaymentFailed,
+		NotificationLowBalance,
+		NotificationBalanceExhausted,
+		NotificationSessionLimit,
+		NotificationReservationReminder,
+	}
+}
+
+// IsNotificationType reports whether users are notified about eventType
+func IsNotificationType(eventType string) bool {
+	return notificationTypes[eventType]
+}
+
+// NotificationEvent asks for a user to be told about something
+type NotificationEvent struct {
+	Type   string            `json:"type"`
+	UserID string            `json:"user_id"`
+	Title  string            `json:"title"`
+	Body   string            `json:"body"`
+	Data   map[string]string `json:"data,omitempty"`
+}
+
+// NotificationPreferences are the channels a user opted into and where
+// to reach them on each
+type NotificationPreferences struct {
+	UserID         string    `json:"user_id" gorm:"primaryKey"`
+	Email          bool      `json:"email"`
+	Push           bool      `json:"push"`
+	SMS            bool      `json:"sms"`
+	PhoneNumber    string    `json:"phone_number,omitempty"`                            // E.164, for SMS
+	PushToken      string    `json:"push_token,omitempty"`                              // FCM registration token
+	DisabledEvents []string  `json:"disabled_events" gorm:"serializer:json;type:jsonb"` // Types turned off on every channel
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DefaultNotificationPreferences applies to users who never set any:
+// email and push on, SMS off
+func DefaultNotificationPreferences(userID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID: userID,
+		Email:  true,
+		Push:   true,
+	}
+}
+
+// Allows reports whether the user wants to hear about eventType
+func (p *NotificationPreferences) Allows(eventType string) bool {
+	for _, t := range p.DisabledEvents {
+		if t == eventType {
+			return false
+		}
+	}
+	return true
+}
+
+// ChannelsFor lists the channels eventType goes out on: none when the
+// user turned it off, otherwise every opted-in channel
+func (p *NotificationPreferences) ChannelsFor(eventType string) []NotificationChannel {
+	if !p.Allows(eventType) {
+		return nil
+	}
+	return p.Channels()
+}
+
+// Channels lists the opted-in channels. Push and SMS need a token or a
+// phone number to be usable.
+func (p *NotificationPreferences) Channels() []NotificationChannel {
+	var channels []NotificationChannel
+	if p.Email {
+		channels = append(channels, NotificationChannelEmail)
+	}
+	if p.Push && p.PushToken != "" {
+		channels = append(channels, NotificationChannelPush)
+	}
+	if p.SMS && p.PhoneNumber != "" {
+		channels = append(channels, NotificationChannelSMS)
+	}
+	return channels
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
@@ -9,6 +10,7 @@ type PaymentStatus string
 
 const (
 	PaymentStatusPending   PaymentStatus = "pending"
+	PaymentStatusAuthorized PaymentStatus = "authorized" // Held on the card, not captured yet
 	PaymentStatusProcessing PaymentStatus = "processing"
 	PaymentStatusCompleted PaymentStatus = "completed"
 	PaymentStatusFailed    PaymentStatus = "failed"
@@ -45,10 +47,14 @@ type Payment struct {
 	Method          PaymentMethod   `json:"method"`
 	Status          PaymentStatus   `json:"status"`
 	Amount          float64         `json:"amount"`
+	AuthorizedAmount float64        `json:"authorized_amount,omitempty"` // Held on the card; Amount is what was captured
 	Currency        string          `json:"currency"`
+	FXRate          float64         `json:"fx_rate,omitempty"`          // Units of SettledCurrency per unit of Currency
+	SettledAmount   float64         `json:"settled_amount,omitempty"`   // Amount moved in the wallet, when its currency differs
+	SettledCurrency string          `json:"settled_currency,omitempty"` // Currency of the wallet the payment settled in
 	Description     string          `json:"description,omitempty"`
 	FailureReason   string          `json:"failure_reason,omitempty"`
-	Metadata        JSONMap         `json:"metadata,omitempty" gorm:"type:jsonb"`
+	Metadata        JSONMap         `json:"metadata,omitempty" gorm:"serializer:json;type:jsonb"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
@@ -75,9 +81,28 @@ type Wallet struct {
 	UserID    string    `json:"user_id" gorm:"uniqueIndex"`
 	Balance   float64   `json:"balance"`
 	Currency  string    `json:"currency"`
+	Version   int64     `json:"version" gorm:"not null;default:0"` // Incremented on every balance change
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ErrPaymentHoldDeclined is returned when a session cannot start because
+// the hold on the user's card was declined
+var ErrPaymentHoldDeclined = errors.New("payment hold declined")
+
+// ErrCaptureExceedsHold is returned when more is captured than was authorized
+var ErrCaptureExceedsHold = errors.New("capture exceeds authorized amount")
+
+// ErrInsufficientBalance is returned when a debit would make the balance negative
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// ErrAlreadyCredited is returned when a wallet was already credited for a
+// reference, such as a top-up payment
+var ErrAlreadyCredited = errors.New("already credited")
+
+// ErrCurrencyNotHeld is returned when a wallet operation names a currency
+// other than the one the wallet holds
+var ErrCurrencyNotHeld = errors.New("currency not held by wallet")
+
 // WalletTransaction represents a wallet transaction
 type WalletTransaction struct {
 	ID          string    `json:"id" gorm:"primaryKey"`
@@ -85,7 +110,7 @@ type WalletTransaction struct {
 	UserID      string    `json:"user_id" gorm:"index"`
 	Type        string    `json:"type"` // credit, debit
 	Amount      float64   `json:"amount"`
-	Balance     float64   `json:"balance"` // Balance after transaction
+	Balance     float64   `json:"balance" gorm:"column:balance_after"` // Balance after transaction
 	Description string    `json:"description"`
 	ReferenceID string    `json:"reference_id,omitempty"` // Payment or Transaction ID
 	CreatedAt   time.Time `json:"created_at"`
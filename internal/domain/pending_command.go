@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// PendingCommand is an OCPP Call sent to a charge point that has not been
+// answered yet. It is kept in storage so a CallResult arriving after a
+// CSMS restart can still be matched to what was asked.
+type PendingCommand struct {
+	MessageID     string    `json:"message_id" gorm:"primaryKey"`
+	ChargePointID string    `json:"charge_point_id" gorm:"index"`
+	Action        string    `json:"action"`
+	Payload       string    `json:"payload"` // The Call payload as JSON
+	ExpiresAt     time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at"`
+}
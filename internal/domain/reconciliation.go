@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// ReconciliationStatus is the outcome of comparing a transaction with the
+// station's own total
+type ReconciliationStatus string
+
+const (
+	ReconciliationStatusMatched     ReconciliationStatus = "Matched"
+	ReconciliationStatusDiscrepancy ReconciliationStatus = "Discrepancy"
+	// ReconciliationStatusUnavailable means the station reported no total
+	ReconciliationStatusUnavailable ReconciliationStatus = "Unavailable"
+)
+
+// Reconciliation records the check of one completed transaction
+type Reconciliation struct {
+	ID               string               `json:"id" gorm:"primaryKey"`
+	TransactionID    string               `json:"transaction_id" gorm:"uniqueIndex"`
+	ChargePointID    string               `json:"charge_point_id" gorm:"index"`
+	ComputedEnergyWh int                  `json:"computed_energy_wh"` // What we billed
+	StationEnergyWh  int                  `json:"station_energy_wh"`  // What the station metered
+	DifferenceWh     int                  `json:"difference_wh"`      // Computed minus station
+	ToleranceWh      int                  `json:"tolerance_wh"`       // Allowed difference for this transaction
+	Status           ReconciliationStatus `json:"status"`
+	CreatedAt        time.Time            `json:"created_at"`
+}
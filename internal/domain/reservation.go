@@ -1,9 +1,14 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrReservationExpired is returned when a reservation is confirmed after its
+// grace period has passed
+var ErrReservationExpired = errors.New("reservation expired")
+
 // ReservationStatus represents the status of a reservation
 type ReservationStatus string
 
@@ -29,6 +34,7 @@ type Reservation struct {
 	Duration        int               `json:"duration"` // Duration in minutes
 	ActualArrival   *time.Time        `json:"actual_arrival,omitempty"`
 	TransactionID   string            `json:"transaction_id,omitempty"` // Linked transaction when active
+	OCPPReservationID int             `json:"ocpp_reservation_id,omitempty" gorm:"column:reservation_id"` // ID used with ReserveNow
 	Fee             float64           `json:"fee"`                      // Reservation fee
 	FeePaid         bool              `json:"fee_paid"`
 	Notes           string            `json:"notes,omitempty"`
@@ -64,11 +70,26 @@ type ReservationConfig struct {
 	// NoShowPenalty is the penalty for not showing up
 	NoShowPenalty float64 `json:"no_show_penalty"`
 
+	// Currency is the currency of the fee and the penalty, converted into
+	// each user's wallet currency when charged
+	Currency string `json:"currency"`
+
 	// MaxActiveReservations is the max concurrent reservations per user
 	MaxActiveReservations int `json:"max_active_reservations"`
 
+	// SlotGranularityMinutes is the length of the slots offered for booking
+	SlotGranularityMinutes int `json:"slot_granularity_minutes"`
+
+	// ExpectedSessionMinutes is how long a running session is assumed to
+	// take when its end cannot be projected
+	ExpectedSessionMinutes int `json:"expected_session_minutes"`
+
 	// RequirePaymentUpfront requires payment when making reservation
 	RequirePaymentUpfront bool `json:"require_payment_upfront"`
+
+	// ArrivalRadiusMeters is how close to the station a user must be for
+	// their location to activate the reservation
+	ArrivalRadiusMeters float64 `json:"arrival_radius_meters"`
 }
 
 // DefaultReservationConfig returns sensible defaults
@@ -81,8 +102,12 @@ func DefaultReservationConfig() *ReservationConfig {
 		CancellationDeadlineMinutes: 60,  // 1 hour before
 		ReservationFee:              5.0, // R$ 5.00
 		NoShowPenalty:               20.0, // R$ 20.00
+		Currency:                    "BRL",
 		MaxActiveReservations:       2,
+		SlotGranularityMinutes:      30,
+		ExpectedSessionMinutes:      60,
 		RequirePaymentUpfront:       false,
+		ArrivalRadiusMeters:         200,
 	}
 }
 
@@ -116,6 +141,18 @@ type TimeSlot struct {
 	Available bool      `json:"available"`
 }
 
+// MaintenanceWindow blocks a connector, or the whole station when ConnectorID
+// is 0, from being reserved
+type MaintenanceWindow struct {
+	ID            string    `json:"id"`
+	ChargePointID string    `json:"charge_point_id"`
+	ConnectorID   int       `json:"connector_id"` // 0 = all connectors
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Reason        string    `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 // ReservationSummary provides a summary of reservations
 type ReservationSummary struct {
 	TotalReservations     int     `json:"total_reservations"`
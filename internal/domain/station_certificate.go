@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// StationCertificateType is the kind of certificate a charge point asks the
+// CSMS to sign (OCPP 2.0.1 CertificateSigningUseEnumType)
+type StationCertificateType string
+
+const (
+	// StationCertificateTypeChargingStation is the TLS client certificate used
+	// for security profile 3
+	StationCertificateTypeChargingStation StationCertificateType = "ChargingStationCertificate"
+	// StationCertificateTypeV2G is the SECC certificate used for ISO 15118
+	StationCertificateTypeV2G StationCertificateType = "V2GCertificate"
+)
+
+// StationCertificate is a certificate the CSMS issued to a charge point
+type StationCertificate struct {
+	ID               string                 `json:"id" gorm:"primaryKey"`
+	ChargePointID    string                 `json:"charge_point_id" gorm:"index"`
+	CertificateType  StationCertificateType `json:"certificate_type"`
+	SerialNumber     string                 `json:"serial_number"` // Hex, as big.Int.Text(16)
+	CommonName       string                 `json:"common_name"`
+	CertificateChain string                 `json:"certificate_chain"` // PEM, leaf first
+	NotBefore        time.Time              `json:"not_before"`
+	NotAfter         time.Time              `json:"not_after"`
+	CreatedAt        time.Time              `json:"created_at"`
+}
+
+// ExpiresWithin reports whether the certificate is expired or will expire
+// within window of now
+func (c *StationCertificate) ExpiresWithin(now time.Time, window time.Duration) bool {
+	return !c.NotAfter.After(now.Add(window))
+}
@@ -0,0 +1,29 @@
+package domain
+
+// StationSelectionStrategy decides which charge point a session starts on
+// when the user does not name one
+type StationSelectionStrategy string
+
+const (
+	StationSelectionFirstAvailable StationSelectionStrategy = "first_available"
+	StationSelectionNearest        StationSelectionStrategy = "nearest"       // Needs the user's location
+	StationSelectionCheapest       StationSelectionStrategy = "cheapest"      // Lowest price per kWh right now
+	StationSelectionLeastBusy      StationSelectionStrategy = "least_busy"    // Smallest share of connectors in use
+	StationSelectionHighestPower   StationSelectionStrategy = "highest_power" // Most powerful free connector
+)
+
+// Valid reports whether s is a known strategy
+func (s StationSelectionStrategy) Valid() bool {
+	switch s {
+	case StationSelectionFirstAvailable, StationSelectionNearest, StationSelectionCheapest,
+		StationSelectionLeastBusy, StationSelectionHighestPower:
+		return true
+	}
+	return false
+}
+
+// StationSelection is how a request wants its charge point picked
+type StationSelection struct {
+	Strategy StationSelectionStrategy // Empty for the configured default
+	Location *Location                // Where the user is, if known
+}
@@ -1,9 +1,17 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
+// ErrNoActiveSession is returned when a user has no charging session in progress
+var ErrNoActiveSession = errors.New("no active charging session found")
+
+// ErrInvoiceNotFound is returned when an invoice does not exist or belongs to another user
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
 type TransactionStatus string
 
 const (
@@ -13,20 +21,155 @@ const (
 	TransactionStatusCompleted TransactionStatus = "Completed"
 )
 
+// TransactionPaymentStatus says whether a completed session was paid for.
+// It is empty until the session is billed, unless a hold was placed on the
+// user's card when it started.
+type TransactionPaymentStatus string
+
+const (
+	TransactionPaymentAuthorized TransactionPaymentStatus = "Authorized" // A hold awaits capture; PaymentID names it
+	TransactionPaymentPaid       TransactionPaymentStatus = "Paid"
+	TransactionPaymentUnpaid     TransactionPaymentStatus = "Unpaid"   // The charge was declined; the user owes it
+	TransactionPaymentFailed     TransactionPaymentStatus = "Failed"   // Payments were unavailable; billing may be retried
+	TransactionPaymentSettling   TransactionPaymentStatus = "Settling" // Claimed by the settlement charging it
+)
+
+// ErrInvalidTransition is returned when a transaction status change is not
+// allowed by the session state machine
+var ErrInvalidTransition = errors.New("invalid transaction status transition")
+
+// TransitionError describes a rejected status change. It matches
+// ErrInvalidTransition with errors.Is.
+type TransitionError struct {
+	From TransactionStatus
+	To   TransactionStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s: %s -> %s", ErrInvalidTransition, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// transactionTransitions lists the statuses each status may move to.
+// Started is the initial status; Completed is final.
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+	TransactionStatusStarted: {TransactionStatusStopped, TransactionStatusFaulted},
+	TransactionStatusFaulted: {TransactionStatusStopped, TransactionStatusCompleted},
+	TransactionStatusStopped: {TransactionStatusCompleted},
+}
+
+// CanTransitionTo reports whether a transaction in status s may move to next
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	for _, allowed := range transactionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo moves the transaction to next, or returns a *TransitionError
+// and leaves the status unchanged if the state machine forbids it
+func (t *Transaction) TransitionTo(next TransactionStatus) error {
+	if !t.Status.CanTransitionTo(next) {
+		return &TransitionError{From: t.Status, To: next}
+	}
+	t.Status = next
+	return nil
+}
+
+// Reopen moves a Stopped or Faulted transaction back to Started when its
+// station reports it is still charging. It is the one way back past the
+// state machine; a Completed transaction has been billed and stays closed.
+func (t *Transaction) Reopen() error {
+	if t.Status != TransactionStatusStopped && t.Status != TransactionStatusFaulted {
+		return &TransitionError{From: t.Status, To: TransactionStatusStarted}
+	}
+	t.Status = TransactionStatusStarted
+	return nil
+}
+
 type Transaction struct {
-	ID            string            `json:"id" gorm:"primaryKey"`
-	ChargePointID string            `json:"charge_point_id" gorm:"index"`
-	ConnectorID   int               `json:"connector_id"`
-	UserID        string            `json:"user_id" gorm:"index"`
-	IdTag         string            `json:"id_tag"` // RFID or other auth token
-	StartTime     time.Time         `json:"start_time"`
-	EndTime       *time.Time        `json:"end_time,omitempty"`
-	MeterStart    int               `json:"meter_start"`  // Wh
-	MeterStop     int               `json:"meter_stop"`   // Wh
-	TotalEnergy   int               `json:"total_energy"` // Wh
-	Status        TransactionStatus `json:"status"`
-	Cost          float64           `json:"cost"`
-	Currency      string            `json:"currency"`
-	CreatedAt     time.Time         `json:"created_at"`
-	UpdatedAt     time.Time         `json:"updated_at"`
+	ID            string                   `json:"id" gorm:"primaryKey"`
+	ChargePointID string                   `json:"charge_point_id" gorm:"index"`
+	ConnectorID   int                      `json:"connector_id"`
+	EvseID        int                      `json:"evse_id,omitempty"` // The station's EVSE (OCPP 2.0.1); 0 if not known
+	UserID        string                   `json:"user_id" gorm:"index"`
+	IdTag         string                   `json:"id_tag"` // RFID or other auth token
+	StartTime     time.Time                `json:"start_time"`
+	EndTime       *time.Time               `json:"end_time,omitempty"`
+	MeterStart    int                      `json:"meter_start"`              // Wh
+	MeterStop     int                      `json:"meter_stop"`               // Wh
+	TotalEnergy   int                      `json:"total_energy"`             // Wh
+	StationEnergy int                      `json:"station_energy,omitempty"` // Wh, session total reported by the station; 0 if not reported
+	Status        TransactionStatus        `json:"status"`
+	Cost          float64                  `json:"cost"`
+	Currency      string                   `json:"currency"`
+	Taxes         TaxBreakdown             `json:"taxes" gorm:"embedded;embeddedPrefix:tax_"`
+	PaymentStatus TransactionPaymentStatus `json:"payment_status,omitempty"`
+	PaymentID     string                   `json:"payment_id,omitempty"` // The card hold while Authorized, the payment once Paid
+	LastSeqNo     int                      `json:"last_seq_no"`          // Highest OCPP TransactionEvent seqNo applied
+	// The transactionId the station gave the session (OCPP 2.0.1), unique per station
+	OCPPTransactionID string    `json:"ocpp_transaction_id,omitempty" gorm:"column:ocpp_transaction_id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ActiveSession is an in-progress transaction with its latest power draw
+type ActiveSession struct {
+	Transaction    Transaction `json:"transaction"`
+	PowerKW        float64     `json:"power_kw"`
+	PowerSampledAt *time.Time  `json:"power_sampled_at,omitempty"` // Nil until the first power reading
+}
+
+// TaxBreakdown splits the amount charged for a session into the net price and
+// the Brazilian taxes included in it. Gross is what the customer pays.
+type TaxBreakdown struct {
+	State  string  `json:"state,omitempty"` // UF whose ICMS rate applied; empty when the fallback rate was used
+	Net    float64 `json:"net"`
+	ICMS   float64 `json:"icms"`
+	PIS    float64 `json:"pis"`
+	COFINS float64 `json:"cofins"`
+	Gross  float64 `json:"gross"`
+}
+
+// TotalTax returns the sum of the tax components
+func (b TaxBreakdown) TotalTax() float64 {
+	return b.ICMS + b.PIS + b.COFINS
+}
+
+// ChargeTarget is the optional goal of a session used for cost projection.
+// Either EnergyKWh or TargetSOC (with CurrentSOC and BatteryCapacityKWh) is set.
+type ChargeTarget struct {
+	EnergyKWh          float64 `json:"energy_kwh,omitempty"`           // Total energy to deliver in the session
+	TargetSOC          float64 `json:"target_soc,omitempty"`           // Desired state of charge (%)
+	CurrentSOC         float64 `json:"current_soc,omitempty"`          // Current state of charge (%)
+	BatteryCapacityKWh float64 `json:"battery_capacity_kwh,omitempty"` // Usable battery capacity
+}
+
+// SessionCost is what an active session has cost so far
+type SessionCost struct {
+	TransactionID string  `json:"transaction_id"`
+	EnergyKWh     float64 `json:"energy_kwh"`
+	Cost          float64 `json:"cost"`
+	RatePerKWh    float64 `json:"rate_per_kwh"`
+	Currency      string  `json:"currency"`
+	Estimated     bool    `json:"estimated"` // No meter reading yet; energy assumed from elapsed time
+}
+
+// SessionCostProjection estimates how an active session will end
+type SessionCostProjection struct {
+	TransactionID       string     `json:"transaction_id"`
+	CurrentEnergyKWh    float64    `json:"current_energy_kwh"`
+	CurrentCost         float64    `json:"current_cost"`
+	PowerKW             float64    `json:"power_kw"`
+	RatePerKWh          float64    `json:"rate_per_kwh"`
+	ProjectedEnergyKWh  float64    `json:"projected_energy_kwh"`
+	ProjectedCost       float64    `json:"projected_cost"`
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"`
+	Currency            string     `json:"currency"`
+	LowConfidence       bool       `json:"low_confidence"`
 }
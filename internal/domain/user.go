@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,41 @@ const (
 	UserRoleUser     UserRole = "user"
 )
 
+// UserStatusAnonymized marks an account whose personal data was erased on
+// request (LGPD/GDPR). It can no longer authenticate.
+const UserStatusAnonymized = "Anonymized"
+
+// User account statuses set by administrators
+const (
+	UserStatusActive   = "Active"
+	UserStatusInactive = "Inactive"
+	UserStatusBlocked  = "Blocked" // Cannot authorize at stations
+)
+
+// Supported user languages (BCP 47 tags)
+const (
+	LanguagePortugueseBR = "pt-BR"
+	LanguageEnglish      = "en"
+
+	DefaultLanguage = LanguagePortugueseBR
+)
+
+// NormalizeLanguage returns the supported tag for language, matching case
+// and separator loosely ("pt_br", "EN") and regional English ("en-US").
+// An empty language is DefaultLanguage. It reports false for any other.
+func NormalizeLanguage(language string) (string, bool) {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+	switch {
+	case tag == "":
+		return DefaultLanguage, true
+	case tag == "pt-br":
+		return LanguagePortugueseBR, true
+	case tag == "en" || strings.HasPrefix(tag, "en-"):
+		return LanguageEnglish, true
+	}
+	return "", false
+}
+
 type User struct {
 	ID        string    `json:"id" gorm:"primaryKey"`
 	Name      string    `json:"name"`
@@ -20,6 +56,12 @@ type User struct {
 	Document  string    `json:"document" gorm:"column:document;uniqueIndex"` // CPF/CNPJ
 	Role      UserRole  `json:"role"`
 	Status    string    `json:"status"`
+	Language  string    `json:"language" gorm:"default:pt-BR"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// IsAnonymized reports whether the account's personal data was erased
+func (u *User) IsAnonymized() bool {
+	return u.Status == UserStatusAnonymized
+}
@@ -70,6 +70,10 @@ type V2GPreferences struct {
 	PreserveSOC     int       `json:"preserve_soc"`     // Minimum battery SOC to maintain (%)
 	NotifyOnStart   bool      `json:"notify_on_start"`  // Notify when V2G session starts
 	NotifyOnEnd     bool      `json:"notify_on_end"`    // Notify when V2G session ends
+	OperatorMargin  *float64  `json:"operator_margin,omitempty"` // Negotiated margin for premium plans or contracts; nil uses the default
+	SmartCharge     bool      `json:"smart_charge"`             // Charge a plugged-in idle EV in the cheapest hours before departure
+	DepartureTime   string    `json:"departure_time,omitempty"` // Daily departure, local "15:04"
+	DepartureSOC    int       `json:"departure_soc,omitempty"`  // Battery SOC wanted at departure (%)
 	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type VoiceResponse struct {
 	Text         string  `json:"text"`
 	Audio        []byte  `json:"audio,omitempty"` // PCM audio bytes
@@ -14,3 +16,15 @@ type Intent struct {
 	Entities   map[string]string `json:"entities,omitempty"`
 	Slots      map[string]interface{} `json:"slots,omitempty"`
 }
+
+// VoiceCommand is a processed voice command kept in the user's history.
+// Transcript and Result are stored with sensitive tokens redacted.
+type VoiceCommand struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	UserID     string    `json:"user_id" gorm:"index"`
+	Transcript string    `json:"transcript"`
+	Intent     string    `json:"intent"`
+	Confidence float64   `json:"confidence"`
+	Result     string    `json:"result"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
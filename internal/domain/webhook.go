@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrWebhookNotFound is returned when a webhook endpoint does not exist or
+// belongs to another account
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// WebhookEndpoint is a partner URL our events are delivered to
+type WebhookEndpoint struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	AccountID  string    `json:"account_id" gorm:"index"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`                                             // Signs every delivery; shown once, on registration
+	EventTypes []string  `json:"event_types" gorm:"serializer:json;type:jsonb"` // Message queue subjects, e.g. transaction.completed
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether events of eventType are delivered to the endpoint
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	if !e.Active {
+		return false
+	}
+	for _, t := range e.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is where a delivery stands
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Retries exhausted or the endpoint refused it for good
+)
+
+// WebhookDelivery records one event sent to one endpoint
+type WebhookDelivery struct {
+	ID             string                `json:"id" gorm:"primaryKey"`
+	EndpointID     string                `json:"endpoint_id" gorm:"index"`
+	EventType      string                `json:"event_type"`
+	Payload        string                `json:"payload"` // The signed JSON body
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus int                   `json:"response_status,omitempty"` // HTTP status of the last attempt
+	Error          string                `json:"error,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
 )
 
 // State represents the circuit breaker state
@@ -365,8 +367,17 @@ func (m *Manager) Get(name string, settings Settings) *CircuitBreaker {
 	}
 
 	settings.Name = name
+	onStateChange := settings.OnStateChange
+	settings.OnStateChange = func(name string, from State, to State) {
+		telemetry.RecordCircuitBreakerTransition(name, from.String(), to.String(), int(to))
+		if onStateChange != nil {
+			onStateChange(name, from, to)
+		}
+	}
+
 	cb = New(settings, m.log)
 	m.breakers[name] = cb
+	telemetry.RecordCircuitBreakerState(name, int(StateClosed))
 
 	return cb
 }
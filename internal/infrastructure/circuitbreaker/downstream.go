@@ -0,0 +1,32 @@
+package circuitbreaker
+
+import "time"
+
+// Downstream dependency names used to scope circuit breakers
+const (
+	DownstreamPayment  = "payment"
+	DownstreamDatabase = "db"
+	DownstreamOCPP     = "ocpp"
+)
+
+// DownstreamSettings returns the circuit breaker settings for a downstream
+// dependency. Unknown names get DefaultSettings.
+func DownstreamSettings(name string) Settings {
+	settings := DefaultSettings()
+
+	switch name {
+	case DownstreamPayment:
+		// External providers recover slowly; trip early and back off longer
+		settings.FailureThreshold = 3
+		settings.Timeout = 60 * time.Second
+	case DownstreamDatabase:
+		// Tolerate short bursts of errors before rejecting traffic
+		settings.FailureThreshold = 10
+		settings.Timeout = 15 * time.Second
+	case DownstreamOCPP:
+		settings.FailureThreshold = 5
+		settings.Timeout = 30 * time.Second
+	}
+
+	return settings
+}
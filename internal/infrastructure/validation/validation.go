@@ -0,0 +1,187 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// Errors lists the fields of a request body that failed validation, keyed
+// by their JSON name.
+type Errors struct {
+	Fields map[string]string
+}
+
+func (e *Errors) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+" "+e.Fields[name])
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Unwrap lets callers match validation failures with domain.ErrValidation
+func (e *Errors) Unwrap() error {
+	return domain.ErrValidation
+}
+
+// ParseBody decodes the request body into out and validates it. A body
+// that cannot be decoded is a 400; one that decodes but breaks a
+// validate tag returns *Errors.
+func ParseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	return Struct(out)
+}
+
+// Struct checks the `validate` tags on the exported fields of v, which
+// must be a struct or a pointer to one. Supported rules: required,
+// omitempty, oneof=a b c, min=N, max=N (value for numbers, length for
+// strings and slices) and email.
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := map[string]string{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		if msg := checkField(rv.Field(i), strings.Split(tag, ",")); msg != "" {
+			fields[fieldName(sf)] = msg
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &Errors{Fields: fields}
+}
+
+// fieldName is the JSON key clients see for sf
+func fieldName(sf reflect.StructField) string {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+// checkField applies rules to fv and returns the first failure, or ""
+func checkField(fv reflect.Value, rules []string) string {
+	if isZero(fv) {
+		for _, rule := range rules {
+			if rule == "required" {
+				return "is required"
+			}
+		}
+		// Absent optional fields are not checked any further
+		return ""
+	}
+
+	for fv.Kind() == reflect.Ptr {
+		fv = fv.Elem()
+	}
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "oneof":
+			options := strings.Fields(param)
+			value := fmt.Sprint(fv.Interface())
+			found := false
+			for _, opt := range options {
+				if opt == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return "must be one of: " + strings.Join(options, ", ")
+			}
+		case "min":
+			if n, ok := measure(fv); ok && n < parseLimit(param) {
+				return "must be at least " + param + unit(fv)
+			}
+		case "max":
+			if n, ok := measure(fv); ok && n > parseLimit(param) {
+				return "must be at most " + param + unit(fv)
+			}
+		case "email":
+			if _, err := mail.ParseAddress(fv.String()); err != nil {
+				return "must be a valid email address"
+			}
+		}
+	}
+	return ""
+}
+
+// isZero reports whether fv is unset: a nil pointer, an empty string or
+// slice, a zero number or a zero time.
+func isZero(fv reflect.Value) bool {
+	if t, ok := fv.Interface().(time.Time); ok {
+		return t.IsZero()
+	}
+	return fv.IsZero()
+}
+
+// measure returns what min and max compare against
+func measure(fv reflect.Value) (float64, bool) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), true
+	case reflect.String:
+		return float64(len([]rune(fv.String()))), true
+	case reflect.Slice, reflect.Map:
+		return float64(fv.Len()), true
+	}
+	return 0, false
+}
+
+func unit(fv reflect.Value) string {
+	switch fv.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map:
+		return " items"
+	}
+	return ""
+}
+
+func parseLimit(param string) float64 {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: bad limit %q", param))
+	}
+	return n
+}
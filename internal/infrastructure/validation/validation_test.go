@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+type sampleRequest struct {
+	IdToken   string     `json:"id_token" validate:"required"`
+	TokenType string     `json:"id_token_type,omitempty" validate:"omitempty,oneof=ISO14443 eMAID"`
+	Duration  int        `json:"duration" validate:"required,min=30,max=180"`
+	VendorID  string     `json:"vendor_id" validate:"max=5"`
+	Email     string     `json:"email" validate:"omitempty,email"`
+	EvseID    *int       `json:"evse_id,omitempty" validate:"omitempty,min=1"`
+	StartTime time.Time  `json:"start_time" validate:"required"`
+	Notes     string     `json:"notes"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+func validSample() sampleRequest {
+	return sampleRequest{
+		IdToken:   "TOKEN-1",
+		Duration:  60,
+		StartTime: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestStruct(t *testing.T) {
+	zero := 0
+
+	tests := []struct {
+		name     string
+		mutate   func(r *sampleRequest)
+		expected map[string]string
+	}{
+		{"valid", func(r *sampleRequest) {}, nil},
+		{"missing required", func(r *sampleRequest) {
+			r.IdToken = ""
+			r.StartTime = time.Time{}
+		}, map[string]string{"id_token": "is required", "start_time": "is required"}},
+		{"not one of", func(r *sampleRequest) { r.TokenType = "Bogus" }, map[string]string{"id_token_type": "must be one of: ISO14443, eMAID"}},
+		{"below min", func(r *sampleRequest) { r.Duration = 10 }, map[string]string{"duration": "must be at least 30"}},
+		{"above max", func(r *sampleRequest) { r.Duration = 240 }, map[string]string{"duration": "must be at most 180"}},
+		{"string too long", func(r *sampleRequest) { r.VendorID = "vendor" }, map[string]string{"vendor_id": "must be at most 5 characters"}},
+		{"bad email", func(r *sampleRequest) { r.Email = "not-an-email" }, map[string]string{"email": "must be a valid email address"}},
+		{"pointer checked when set", func(r *sampleRequest) { r.EvseID = &zero }, map[string]string{"evse_id": "must be at least 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := validSample()
+			tt.mutate(&req)
+
+			// Act
+			err := Struct(&req)
+
+			// Assert
+			if tt.expected == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var fieldErrs *Errors
+			if !errors.As(err, &fieldErrs) {
+				t.Fatalf("expected *Errors, got %v", err)
+			}
+			if len(fieldErrs.Fields) != len(tt.expected) {
+				t.Errorf("expected fields %v, got %v", tt.expected, fieldErrs.Fields)
+			}
+			for field, msg := range tt.expected {
+				if fieldErrs.Fields[field] != msg {
+					t.Errorf("expected %s %q, got %q", field, msg, fieldErrs.Fields[field])
+				}
+			}
+			if !errors.Is(err, domain.ErrValidation) {
+				t.Error("expected error to wrap domain.ErrValidation")
+			}
+		})
+	}
+}
+
+func TestParseBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantFields []string
+	}{
+		{"valid", `{"id_token":"T","duration":60,"start_time":"2026-10-16T09:00:00Z"}`, fiber.StatusOK, nil},
+		{"missing fields", `{"duration":60}`, fiber.StatusUnprocessableEntity, []string{"id_token", "start_time"}},
+		{"malformed", `{"id_token":`, fiber.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var parseErr error
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				var req sampleRequest
+				parseErr = ParseBody(c, &req)
+				if parseErr == nil {
+					return c.SendStatus(fiber.StatusOK)
+				}
+				var fieldErrs *Errors
+				if errors.As(parseErr, &fieldErrs) {
+					return c.SendStatus(fiber.StatusUnprocessableEntity)
+				}
+				return parseErr
+			})
+
+			httpReq := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			// Act
+			resp, err := app.Test(httpReq)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			// Assert
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantFields != nil {
+				fieldErrs := parseErr.(*Errors)
+				for _, field := range tt.wantFields {
+					if _, ok := fieldErrs.Fields[field]; !ok {
+						t.Errorf("expected error for %s, got %v", field, fieldErrs.Fields)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package mocks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// MockOCPPCommandService is a mock implementation of OCPPCommandService
+type MockOCPPCommandService struct {
+	RemoteStartTransactionFunc     func(ctx context.Context, chargePointID, idToken string, tokenType domain.IdTokenType, evseID *int) error
+	RemoteStopTransactionFunc      func(ctx context.Context, chargePointID, transactionID string) error
+	ResetFunc                      func(ctx context.Context, chargePointID string, resetType string, evseID *int) (string, error)
+	TriggerMessageFunc             func(ctx context.Context, chargePointID, requestedMessage string, evseID *int) error
+	SetChargingProfileFunc         func(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) error
+	PreviewChargingProfileFunc     func(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) (*ports.ChargingProfilePreview, error)
+	ClearChargingProfileFunc       func(ctx context.Context, chargePointID string, profileID *int, evseID *int) error
+	UpdateFirmwareFunc             func(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime string, installDateTime *time.Time, retries, retryInterval *int) error
+	UpdateFirmwareSignedFunc       func(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime, signingCert, signature string, retries, retryInterval *int) error
+	PublishFirmwareFunc            func(ctx context.Context, chargePointID, location, checksum string, retries, retryInterval *int) error
+	UnlockConnectorFunc            func(ctx context.Context, chargePointID string, evseID, connectorID int) error
+	ChangeAvailabilityFunc         func(ctx context.Context, chargePointID string, operationalStatus string, evseID *int) error
+	GetVariablesFunc               func(ctx context.Context, chargePointID string, variables []ports.GetVariableRequest) ([]ports.GetVariableResponse, error)
+	SetVariablesFunc               func(ctx context.Context, chargePointID string, variables []ports.SetVariableRequest) ([]ports.SetVariableResult, error)
+	SetVariableMonitoringFunc      func(ctx context.Context, chargePointID string, monitors []ports.VariableMonitor) ([]ports.VariableMonitorResult, error)
+	SetMonitoringBaseFunc          func(ctx context.Context, chargePointID, monitoringBase string) (string, error)
+	GetLogFunc                     func(ctx context.Context, chargePointID, logType, uploadURL string) error
+	ReserveNowFunc                 func(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error
+	CancelReservationFunc          func(ctx context.Context, chargePointID string, reservationID int) error
+	ClearCacheFunc                 func(ctx context.Context, chargePointID string) (string, error)
+	DataTransferFunc               func(ctx context.Context, chargePointID, vendorID, messageID string, data json.RawMessage) (*ports.DataTransferResult, error)
+	InstallCertificateFunc         func(ctx context.Context, chargePointID, certificateType, certificate string) (string, error)
+	DeleteCertificateFunc          func(ctx context.Context, chargePointID string, hashData ports.CertificateHashData) (string, error)
+	GetInstalledCertificateIdsFunc func(ctx context.Context, chargePointID string, certificateTypes []string) ([]ports.InstalledCertificate, error)
+	SetV2GChargingProfileFunc      func(ctx context.Context, chargePointID string, evseID int, dischargePowerKW float64, durationSeconds int) error
+	ClearV2GChargingProfileFunc    func(ctx context.Context, chargePointID string, evseID int) error
+	GetV2GCapabilityFunc           func(ctx context.Context, chargePointID string) (*domain.V2GCapability, error)
+	BulkRemoteStopFunc             func(ctx context.Context, targets []ports.RemoteStopTarget) []ports.BulkCommandResult
+	IsConnectedFunc                func(chargePointID string) bool
+	GetConnectedClientsFunc        func() []string
+}
+
+func (m *MockOCPPCommandService) RemoteStartTransaction(ctx context.Context, chargePointID, idToken string, tokenType domain.IdTokenType, evseID *int) error {
+	if m.RemoteStartTransactionFunc != nil {
+		return m.RemoteStartTransactionFunc(ctx, chargePointID, idToken, tokenType, evseID)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) RemoteStopTransaction(ctx context.Context, chargePointID, transactionID string) error {
+	if m.RemoteStopTransactionFunc != nil {
+		return m.RemoteStopTransactionFunc(ctx, chargePointID, transactionID)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) Reset(ctx context.Context, chargePointID string, resetType string, evseID *int) (string, error) {
+	if m.ResetFunc != nil {
+		return m.ResetFunc(ctx, chargePointID, resetType, evseID)
+	}
+	return "Accepted", nil
+}
+
+func (m *MockOCPPCommandService) TriggerMessage(ctx context.Context, chargePointID, requestedMessage string, evseID *int) error {
+	if m.TriggerMessageFunc != nil {
+		return m.TriggerMessageFunc(ctx, chargePointID, requestedMessage, evseID)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) SetChargingProfile(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) error {
+	if m.SetChargingProfileFunc != nil {
+		return m.SetChargingProfileFunc(ctx, chargePointID, evseID, profile)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) PreviewChargingProfile(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) (*ports.ChargingProfilePreview, error) {
+	if m.PreviewChargingProfileFunc != nil {
+		return m.PreviewChargingProfileFunc(ctx, chargePointID, evseID, profile)
+	}
+	return &ports.ChargingProfilePreview{Valid: true}, nil
+}
+
+func (m *MockOCPPCommandService) ClearChargingProfile(ctx context.Context, chargePointID string, profileID *int, evseID *int) error {
+	if m.ClearChargingProfileFunc != nil {
+		return m.ClearChargingProfileFunc(ctx, chargePointID, profileID, evseID)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) UpdateFirmware(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime string, installDateTime *time.Time, retries, retryInterval *int) error {
+	if m.UpdateFirmwareFunc != nil {
+		return m.UpdateFirmwareFunc(ctx, chargePointID, firmwareURL, retrieveDateTime, installDateTime, retries, retryInterval)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) UpdateFirmwareSigned(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime, signingCert, signature string, retries, retryInterval *int) error {
+	if m.UpdateFirmwareSignedFunc != nil {
+		return m.UpdateFirmwareSignedFunc(ctx, chargePointID, firmwareURL, retrieveDateTime, signingCert, signature, retries, retryInterval)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) PublishFirmware(ctx context.Context, chargePointID, location, checksum string, retries, retryInterval *int) error {
+	if m.PublishFirmwareFunc != nil {
+		return m.PublishFirmwareFunc(ctx, chargePointID, location, checksum, retries, retryInterval)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) UnlockConnector(ctx context.Context, chargePointID string, evseID, connectorID int) error {
+	if m.UnlockConnectorFunc != nil {
+		return m.UnlockConnectorFunc(ctx, chargePointID, evseID, connectorID)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) ChangeAvailability(ctx context.Context, chargePointID string, operationalStatus string, evseID *int) error {
+	if m.ChangeAvailabilityFunc != nil {
+		return m.ChangeAvailabilityFunc(ctx, chargePointID, operationalStatus, evseID)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) GetVariables(ctx context.Context, chargePointID string, variables []ports.GetVariableRequest) ([]ports.GetVariableResponse, error) {
+	if m.GetVariablesFunc != nil {
+		return m.GetVariablesFunc(ctx, chargePointID, variables)
+	}
+	return nil, nil
+}
+
+func (m *MockOCPPCommandService) SetVariables(ctx context.Context, chargePointID string, variables []ports.SetVariableRequest) ([]ports.SetVariableResult, error) {
+	if m.SetVariablesFunc != nil {
+		return m.SetVariablesFunc(ctx, chargePointID, variables)
+	}
+	return nil, nil
+}
+
+func (m *MockOCPPCommandService) GetLog(ctx context.Context, chargePointID, logType, uploadURL string) error {
+	if m.GetLogFunc != nil {
+		return m.GetLogFunc(ctx, chargePointID, logType, uploadURL)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) ReserveNow(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error {
+	if m.ReserveNowFunc != nil {
+		return m.ReserveNowFunc(ctx, chargePointID, reservationID, connectorID, idToken, expiry)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) SetVariableMonitoring(ctx context.Context, chargePointID string, monitors []ports.VariableMonitor) ([]ports.VariableMonitorResult, error) {
+	if m.SetVariableMonitoringFunc != nil {
+		return m.SetVariableMonitoringFunc(ctx, chargePointID, monitors)
+	}
+	return nil, nil
+}
+
+func (m *MockOCPPCommandService) SetMonitoringBase(ctx context.Context, chargePointID, monitoringBase string) (string, error) {
+	if m.SetMonitoringBaseFunc != nil {
+		return m.SetMonitoringBaseFunc(ctx, chargePointID, monitoringBase)
+	}
+	return "Accepted", nil
+}
+
+func (m *MockOCPPCommandService) CancelReservation(ctx context.Context, chargePointID string, reservationID int) error {
+	if m.CancelReservationFunc != nil {
+		return m.CancelReservationFunc(ctx, chargePointID, reservationID)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) DataTransfer(ctx context.Context, chargePointID, vendorID, messageID string, data json.RawMessage) (*ports.DataTransferResult, error) {
+	if m.DataTransferFunc != nil {
+		return m.DataTransferFunc(ctx, chargePointID, vendorID, messageID, data)
+	}
+	return &ports.DataTransferResult{Status: "Accepted"}, nil
+}
+
+func (m *MockOCPPCommandService) ClearCache(ctx context.Context, chargePointID string) (string, error) {
+	if m.ClearCacheFunc != nil {
+		return m.ClearCacheFunc(ctx, chargePointID)
+	}
+	return "Accepted", nil
+}
+
+func (m *MockOCPPCommandService) InstallCertificate(ctx context.Context, chargePointID, certificateType, certificate string) (string, error) {
+	if m.InstallCertificateFunc != nil {
+		return m.InstallCertificateFunc(ctx, chargePointID, certificateType, certificate)
+	}
+	return "Accepted", nil
+}
+
+func (m *MockOCPPCommandService) DeleteCertificate(ctx context.Context, chargePointID string, hashData ports.CertificateHashData) (string, error) {
+	if m.DeleteCertificateFunc != nil {
+		return m.DeleteCertificateFunc(ctx, chargePointID, hashData)
+	}
+	return "Accepted", nil
+}
+
+func (m *MockOCPPCommandService) GetInstalledCertificateIds(ctx context.Context, chargePointID string, certificateTypes []string) ([]ports.InstalledCertificate, error) {
+	if m.GetInstalledCertificateIdsFunc != nil {
+		return m.GetInstalledCertificateIdsFunc(ctx, chargePointID, certificateTypes)
+	}
+	return nil, nil
+}
+
+func (m *MockOCPPCommandService) SetV2GChargingProfile(ctx context.Context, chargePointID string, evseID int, dischargePowerKW float64, durationSeconds int) error {
+	if m.SetV2GChargingProfileFunc != nil {
+		return m.SetV2GChargingProfileFunc(ctx, chargePointID, evseID, dischargePowerKW, durationSeconds)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) ClearV2GChargingProfile(ctx context.Context, chargePointID string, evseID int) error {
+	if m.ClearV2GChargingProfileFunc != nil {
+		return m.ClearV2GChargingProfileFunc(ctx, chargePointID, evseID)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) GetV2GCapability(ctx context.Context, chargePointID string) (*domain.V2GCapability, error) {
+	if m.GetV2GCapabilityFunc != nil {
+		return m.GetV2GCapabilityFunc(ctx, chargePointID)
+	}
+	return nil, nil
+}
+
+func (m *MockOCPPCommandService) BulkRemoteStop(ctx context.Context, targets []ports.RemoteStopTarget) []ports.BulkCommandResult {
+	if m.BulkRemoteStopFunc != nil {
+		return m.BulkRemoteStopFunc(ctx, targets)
+	}
+	return nil
+}
+
+func (m *MockOCPPCommandService) IsConnected(chargePointID string) bool {
+	if m.IsConnectedFunc != nil {
+		return m.IsConnectedFunc(chargePointID)
+	}
+	return false
+}
+
+func (m *MockOCPPCommandService) GetConnectedClients() []string {
+	if m.GetConnectedClientsFunc != nil {
+		return m.GetConnectedClientsFunc()
+	}
+	return nil
+}
@@ -2,11 +2,12 @@ package mocks
 
 // MockMessageQueue is a mock implementation of MessageQueue interface
 type MockMessageQueue struct {
-	PublishedMessages map[string][][]byte
-	Subscribers       map[string][]func([]byte) error
-	PublishFunc       func(topic string, data []byte) error
-	SubscribeFunc     func(topic string, handler func([]byte) error) error
-	CloseFunc         func() error
+	PublishedMessages  map[string][][]byte
+	Subscribers        map[string][]func([]byte) error
+	PublishFunc        func(topic string, data []byte) error
+	SubscribeFunc      func(topic string, handler func([]byte) error) error
+	QueueSubscribeFunc func(topic, group string, handler func([]byte) error) error
+	CloseFunc          func() error
 }
 
 func NewMockMessageQueue() *MockMessageQueue {
@@ -32,6 +33,14 @@ func (m *MockMessageQueue) Subscribe(topic string, handler func([]byte) error) e
 	return nil
 }
 
+func (m *MockMessageQueue) QueueSubscribe(topic, group string, handler func([]byte) error) error {
+	if m.QueueSubscribeFunc != nil {
+		return m.QueueSubscribeFunc(topic, group, handler)
+	}
+	m.Subscribers[topic] = append(m.Subscribers[topic], handler)
+	return nil
+}
+
 func (m *MockMessageQueue) Close() error {
 	if m.CloseFunc != nil {
 		return m.CloseFunc()
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // MockUserRepository is a mock implementation of UserRepository
@@ -49,7 +50,12 @@ type MockChargePointRepository struct {
 	FindByIDFunc     func(ctx context.Context, id string) (*domain.ChargePoint, error)
 	FindAllFunc      func(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error)
 	UpdateStatusFunc func(ctx context.Context, id string, status domain.ChargePointStatus) error
-	FindNearbyFunc   func(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error)
+	FindNearbyFunc   func(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error)
+
+	UpdateConnectorStatusFunc        func(ctx context.Context, chargePointID string, connectorID int, status domain.ChargePointStatus) error
+	CompareAndSetConnectorStatusFunc func(ctx context.Context, chargePointID string, connectorID int, from, to domain.ChargePointStatus) (bool, error)
+	UpdateHeartbeatIntervalFunc      func(ctx context.Context, id string, interval int) error
+	UpdateBootInfoFunc               func(ctx context.Context, id string, info domain.BootInfo) (string, error)
 }
 
 func (m *MockChargePointRepository) Save(ctx context.Context, cp *domain.ChargePoint) error {
@@ -80,21 +86,54 @@ func (m *MockChargePointRepository) UpdateStatus(ctx context.Context, id string,
 	return nil
 }
 
-func (m *MockChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error) {
+func (m *MockChargePointRepository) UpdateConnectorStatus(ctx context.Context, chargePointID string, connectorID int, status domain.ChargePointStatus) error {
+	if m.UpdateConnectorStatusFunc != nil {
+		return m.UpdateConnectorStatusFunc(ctx, chargePointID, connectorID, status)
+	}
+	return nil
+}
+
+func (m *MockChargePointRepository) CompareAndSetConnectorStatus(ctx context.Context, chargePointID string, connectorID int, from, to domain.ChargePointStatus) (bool, error) {
+	if m.CompareAndSetConnectorStatusFunc != nil {
+		return m.CompareAndSetConnectorStatusFunc(ctx, chargePointID, connectorID, from, to)
+	}
+	return true, nil
+}
+
+func (m *MockChargePointRepository) UpdateHeartbeatInterval(ctx context.Context, id string, interval int) error {
+	if m.UpdateHeartbeatIntervalFunc != nil {
+		return m.UpdateHeartbeatIntervalFunc(ctx, id, interval)
+	}
+	return nil
+}
+
+func (m *MockChargePointRepository) UpdateBootInfo(ctx context.Context, id string, info domain.BootInfo) (string, error) {
+	if m.UpdateBootInfoFunc != nil {
+		return m.UpdateBootInfoFunc(ctx, id, info)
+	}
+	return "", nil
+}
+
+func (m *MockChargePointRepository) FindNearby(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
 	if m.FindNearbyFunc != nil {
-		return m.FindNearbyFunc(ctx, lat, lon, radius)
+		return m.FindNearbyFunc(ctx, lat, lon, radius, connectors)
 	}
 	return []domain.ChargePoint{}, nil
 }
 
 // MockTransactionRepository is a mock implementation of TransactionRepository
 type MockTransactionRepository struct {
-	SaveFunc                func(ctx context.Context, tx *domain.Transaction) error
-	FindByIDFunc            func(ctx context.Context, id string) (*domain.Transaction, error)
-	FindActiveByUserIDFunc  func(ctx context.Context, userID string) (*domain.Transaction, error)
-	FindHistoryByUserIDFunc func(ctx context.Context, userID string) ([]domain.Transaction, error)
-	FindByDateFunc          func(ctx context.Context, date time.Time) ([]domain.Transaction, error)
-	UpdateFunc              func(ctx context.Context, tx *domain.Transaction) error
+	SaveFunc                       func(ctx context.Context, tx *domain.Transaction) error
+	FindByIDFunc                   func(ctx context.Context, id string) (*domain.Transaction, error)
+	FindActiveByUserIDFunc         func(ctx context.Context, userID string) (*domain.Transaction, error)
+	FindHistoryByUserIDFunc        func(ctx context.Context, userID string) ([]domain.Transaction, error)
+	FindByDateFunc                 func(ctx context.Context, date time.Time) ([]domain.Transaction, error)
+	UpdateFunc                     func(ctx context.Context, tx *domain.Transaction) error
+	FindPageFunc                   func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error)
+	CountFunc                      func(ctx context.Context, filter ports.TransactionFilter) (int, error)
+	CompareAndSetPaymentStatusFunc func(ctx context.Context, id string, from, to domain.TransactionPaymentStatus) (bool, error)
+	FindByPaymentStatusFunc        func(ctx context.Context, status domain.TransactionPaymentStatus, updatedBefore time.Time) ([]domain.Transaction, error)
+	FindByOCPPTransactionIDFunc    func(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error)
 }
 
 func (m *MockTransactionRepository) Save(ctx context.Context, tx *domain.Transaction) error {
@@ -132,9 +171,330 @@ func (m *MockTransactionRepository) FindByDate(ctx context.Context, date time.Ti
 	return []domain.Transaction{}, nil
 }
 
+func (m *MockTransactionRepository) FindPage(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+	if m.FindPageFunc != nil {
+		return m.FindPageFunc(ctx, filter, after, limit)
+	}
+	return []domain.Transaction{}, nil
+}
+
+func (m *MockTransactionRepository) Count(ctx context.Context, filter ports.TransactionFilter) (int, error) {
+	if m.CountFunc != nil {
+		return m.CountFunc(ctx, filter)
+	}
+	return 0, nil
+}
+
 func (m *MockTransactionRepository) Update(ctx context.Context, tx *domain.Transaction) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(ctx, tx)
 	}
 	return nil
 }
+
+func (m *MockTransactionRepository) CompareAndSetPaymentStatus(ctx context.Context, id string, from, to domain.TransactionPaymentStatus) (bool, error) {
+	if m.CompareAndSetPaymentStatusFunc != nil {
+		return m.CompareAndSetPaymentStatusFunc(ctx, id, from, to)
+	}
+	return true, nil
+}
+
+func (m *MockTransactionRepository) FindByPaymentStatus(ctx context.Context, status domain.TransactionPaymentStatus, updatedBefore time.Time) ([]domain.Transaction, error) {
+	if m.FindByPaymentStatusFunc != nil {
+		return m.FindByPaymentStatusFunc(ctx, status, updatedBefore)
+	}
+	return []domain.Transaction{}, nil
+}
+
+func (m *MockTransactionRepository) FindByOCPPTransactionID(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+	if m.FindByOCPPTransactionIDFunc != nil {
+		return m.FindByOCPPTransactionIDFunc(ctx, chargePointID, ocppTransactionID)
+	}
+	return nil, nil
+}
+
+// MockVoiceCommandRepository is a mock implementation of ports.VoiceCommandRepository
+type MockVoiceCommandRepository struct {
+	SaveFunc         func(ctx context.Context, cmd *domain.VoiceCommand) error
+	FindByUserIDFunc func(ctx context.Context, userID string, limit, offset int) ([]domain.VoiceCommand, error)
+
+	AnonymizeByUserIDFunc func(ctx context.Context, userID string) error
+}
+
+func (m *MockVoiceCommandRepository) Save(ctx context.Context, cmd *domain.VoiceCommand) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, cmd)
+	}
+	return nil
+}
+
+func (m *MockVoiceCommandRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) ([]domain.VoiceCommand, error) {
+	if m.FindByUserIDFunc != nil {
+		return m.FindByUserIDFunc(ctx, userID, limit, offset)
+	}
+	return []domain.VoiceCommand{}, nil
+}
+
+func (m *MockVoiceCommandRepository) AnonymizeByUserID(ctx context.Context, userID string) error {
+	if m.AnonymizeByUserIDFunc != nil {
+		return m.AnonymizeByUserIDFunc(ctx, userID)
+	}
+	return nil
+}
+
+// MockEventRepository is a mock implementation of ports.EventRepository
+type MockEventRepository struct {
+	AppendFunc            func(ctx context.Context, event *domain.Event) error
+	FindByAggregateIDFunc func(ctx context.Context, aggregateID string, sinceSeq int64) ([]domain.Event, error)
+	LastSeqFunc           func(ctx context.Context) (int64, error)
+}
+
+func (m *MockEventRepository) Append(ctx context.Context, event *domain.Event) error {
+	if m.AppendFunc != nil {
+		return m.AppendFunc(ctx, event)
+	}
+	return nil
+}
+
+func (m *MockEventRepository) FindByAggregateID(ctx context.Context, aggregateID string, sinceSeq int64) ([]domain.Event, error) {
+	if m.FindByAggregateIDFunc != nil {
+		return m.FindByAggregateIDFunc(ctx, aggregateID, sinceSeq)
+	}
+	return []domain.Event{}, nil
+}
+
+func (m *MockEventRepository) LastSeq(ctx context.Context) (int64, error) {
+	if m.LastSeqFunc != nil {
+		return m.LastSeqFunc(ctx)
+	}
+	return 0, nil
+}
+
+// MockReservationRepository is a mock implementation of ReservationRepository
+type MockReservationRepository struct {
+	SaveFunc                 func(ctx context.Context, reservation *domain.Reservation) error
+	GetByIDFunc              func(ctx context.Context, id string) (*domain.Reservation, error)
+	GetByUserIDFunc          func(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error)
+	GetByChargePointIDFunc   func(ctx context.Context, chargePointID string, date time.Time) ([]domain.Reservation, error)
+	GetByTimeRangeFunc       func(ctx context.Context, chargePointID string, connectorID int, startTime, endTime time.Time) ([]domain.Reservation, error)
+	GetActiveByUserIDFunc    func(ctx context.Context, userID string) ([]domain.Reservation, error)
+	GetExpiredFunc           func(ctx context.Context, gracePeriod time.Duration) ([]domain.Reservation, error)
+	UpdateStatusFunc         func(ctx context.Context, id string, status domain.ReservationStatus) error
+	DeleteFunc               func(ctx context.Context, id string) error
+	CountByUserAndStatusFunc func(ctx context.Context, userID string, statuses []domain.ReservationStatus) (int, error)
+
+	GetByOCPPReservationIDFunc func(ctx context.Context, chargePointID string, reservationID int) (*domain.Reservation, error)
+}
+
+func (m *MockReservationRepository) Save(ctx context.Context, reservation *domain.Reservation) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, reservation)
+	}
+	return nil
+}
+
+func (m *MockReservationRepository) GetByID(ctx context.Context, id string) (*domain.Reservation, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockReservationRepository) GetByUserID(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error) {
+	if m.GetByUserIDFunc != nil {
+		return m.GetByUserIDFunc(ctx, userID, status, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockReservationRepository) GetByChargePointID(ctx context.Context, chargePointID string, date time.Time) ([]domain.Reservation, error) {
+	if m.GetByChargePointIDFunc != nil {
+		return m.GetByChargePointIDFunc(ctx, chargePointID, date)
+	}
+	return nil, nil
+}
+
+func (m *MockReservationRepository) GetByTimeRange(ctx context.Context, chargePointID string, connectorID int, startTime, endTime time.Time) ([]domain.Reservation, error) {
+	if m.GetByTimeRangeFunc != nil {
+		return m.GetByTimeRangeFunc(ctx, chargePointID, connectorID, startTime, endTime)
+	}
+	return nil, nil
+}
+
+func (m *MockReservationRepository) GetActiveByUserID(ctx context.Context, userID string) ([]domain.Reservation, error) {
+	if m.GetActiveByUserIDFunc != nil {
+		return m.GetActiveByUserIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockReservationRepository) GetExpired(ctx context.Context, gracePeriod time.Duration) ([]domain.Reservation, error) {
+	if m.GetExpiredFunc != nil {
+		return m.GetExpiredFunc(ctx, gracePeriod)
+	}
+	return nil, nil
+}
+
+func (m *MockReservationRepository) UpdateStatus(ctx context.Context, id string, status domain.ReservationStatus) error {
+	if m.UpdateStatusFunc != nil {
+		return m.UpdateStatusFunc(ctx, id, status)
+	}
+	return nil
+}
+
+func (m *MockReservationRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockReservationRepository) GetByOCPPReservationID(ctx context.Context, chargePointID string, reservationID int) (*domain.Reservation, error) {
+	if m.GetByOCPPReservationIDFunc != nil {
+		return m.GetByOCPPReservationIDFunc(ctx, chargePointID, reservationID)
+	}
+	return nil, nil
+}
+
+// MockMaintenanceWindowRepository is a mock implementation of MaintenanceWindowRepository
+type MockMaintenanceWindowRepository struct {
+	SaveFunc            func(ctx context.Context, window *domain.MaintenanceWindow) error
+	FindOverlappingFunc func(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.MaintenanceWindow, error)
+}
+
+func (m *MockMaintenanceWindowRepository) Save(ctx context.Context, window *domain.MaintenanceWindow) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, window)
+	}
+	return nil
+}
+
+func (m *MockMaintenanceWindowRepository) FindOverlapping(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+	if m.FindOverlappingFunc != nil {
+		return m.FindOverlappingFunc(ctx, chargePointID, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockReservationRepository) CountByUserAndStatus(ctx context.Context, userID string, statuses []domain.ReservationStatus) (int, error) {
+	if m.CountByUserAndStatusFunc != nil {
+		return m.CountByUserAndStatusFunc(ctx, userID, statuses)
+	}
+	return 0, nil
+}
+
+// MockAlertRepository is a mock implementation of AlertRepository
+type MockAlertRepository struct {
+	SaveFunc                func(ctx context.Context, alert *ports.Alert) error
+	GetByIDFunc             func(ctx context.Context, id string) (*ports.Alert, error)
+	GetAllFunc              func(ctx context.Context, acknowledged bool, limit, offset int) ([]ports.Alert, error)
+	AcknowledgeFunc         func(ctx context.Context, id string) error
+	DeleteFunc              func(ctx context.Context, id string) error
+	CountUnacknowledgedFunc func(ctx context.Context) (int, error)
+	FindOpenFunc            func(ctx context.Context, alertType, sourceID string) ([]ports.Alert, error)
+	ResolveFunc             func(ctx context.Context, id string, resolvedAt time.Time) error
+}
+
+func (m *MockAlertRepository) Save(ctx context.Context, alert *ports.Alert) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, alert)
+	}
+	return nil
+}
+
+func (m *MockAlertRepository) GetByID(ctx context.Context, id string) (*ports.Alert, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockAlertRepository) GetAll(ctx context.Context, acknowledged bool, limit, offset int) ([]ports.Alert, error) {
+	if m.GetAllFunc != nil {
+		return m.GetAllFunc(ctx, acknowledged, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockAlertRepository) Acknowledge(ctx context.Context, id string) error {
+	if m.AcknowledgeFunc != nil {
+		return m.AcknowledgeFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockAlertRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockAlertRepository) CountUnacknowledged(ctx context.Context) (int, error) {
+	if m.CountUnacknowledgedFunc != nil {
+		return m.CountUnacknowledgedFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *MockAlertRepository) FindOpen(ctx context.Context, alertType, sourceID string) ([]ports.Alert, error) {
+	if m.FindOpenFunc != nil {
+		return m.FindOpenFunc(ctx, alertType, sourceID)
+	}
+	return nil, nil
+}
+
+func (m *MockAlertRepository) Resolve(ctx context.Context, id string, resolvedAt time.Time) error {
+	if m.ResolveFunc != nil {
+		return m.ResolveFunc(ctx, id, resolvedAt)
+	}
+	return nil
+}
+
+// MockStationCertificateRepository is a mock implementation of StationCertificateRepository
+type MockStationCertificateRepository struct {
+	SaveFunc               func(ctx context.Context, cert *domain.StationCertificate) error
+	GetLatestFunc          func(ctx context.Context, chargePointID string, certType domain.StationCertificateType) (*domain.StationCertificate, error)
+	FindExpiringBeforeFunc func(ctx context.Context, before time.Time) ([]domain.StationCertificate, error)
+}
+
+func (m *MockStationCertificateRepository) Save(ctx context.Context, cert *domain.StationCertificate) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, cert)
+	}
+	return nil
+}
+
+func (m *MockStationCertificateRepository) GetLatest(ctx context.Context, chargePointID string, certType domain.StationCertificateType) (*domain.StationCertificate, error) {
+	if m.GetLatestFunc != nil {
+		return m.GetLatestFunc(ctx, chargePointID, certType)
+	}
+	return nil, nil
+}
+
+func (m *MockStationCertificateRepository) FindExpiringBefore(ctx context.Context, before time.Time) ([]domain.StationCertificate, error) {
+	if m.FindExpiringBeforeFunc != nil {
+		return m.FindExpiringBeforeFunc(ctx, before)
+	}
+	return nil, nil
+}
+
+// MockReconciliationRepository is a mock implementation of ReconciliationRepository
+type MockReconciliationRepository struct {
+	SaveFunc                func(ctx context.Context, rec *domain.Reconciliation) error
+	FindByTransactionIDFunc func(ctx context.Context, transactionID string) (*domain.Reconciliation, error)
+}
+
+func (m *MockReconciliationRepository) Save(ctx context.Context, rec *domain.Reconciliation) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, rec)
+	}
+	return nil
+}
+
+func (m *MockReconciliationRepository) FindByTransactionID(ctx context.Context, transactionID string) (*domain.Reconciliation, error) {
+	if m.FindByTransactionIDFunc != nil {
+		return m.FindByTransactionIDFunc(ctx, transactionID)
+	}
+	return nil, nil
+}
@@ -2,8 +2,10 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // MockDeviceService is a mock implementation of DeviceService interface
@@ -11,8 +13,14 @@ type MockDeviceService struct {
 	GetDeviceFunc            func(ctx context.Context, id string) (*domain.ChargePoint, error)
 	ListDevicesFunc          func(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error)
 	UpdateStatusFunc         func(ctx context.Context, id string, status domain.ChargePointStatus) error
-	GetNearbyFunc            func(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error)
+	GetNearbyFunc            func(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error)
 	ListAvailableDevicesFunc func(ctx context.Context) ([]domain.ChargePoint, error)
+
+	UpdateConnectorStatusFunc func(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error
+	ReserveConnectorFunc      func(ctx context.Context, id string, connectorID int) error
+	ReleaseConnectorFunc      func(ctx context.Context, id string, connectorID int) error
+	SetHeartbeatIntervalFunc  func(ctx context.Context, id string, interval int) error
+	RecordBootInfoFunc        func(ctx context.Context, id string, info domain.BootInfo) error
 }
 
 func (m *MockDeviceService) GetDevice(ctx context.Context, id string) (*domain.ChargePoint, error) {
@@ -36,9 +44,44 @@ func (m *MockDeviceService) UpdateStatus(ctx context.Context, id string, status
 	return nil
 }
 
-func (m *MockDeviceService) GetNearby(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error) {
+func (m *MockDeviceService) UpdateConnectorStatus(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error {
+	if m.UpdateConnectorStatusFunc != nil {
+		return m.UpdateConnectorStatusFunc(ctx, id, connectorID, status)
+	}
+	return nil
+}
+
+func (m *MockDeviceService) ReserveConnector(ctx context.Context, id string, connectorID int) error {
+	if m.ReserveConnectorFunc != nil {
+		return m.ReserveConnectorFunc(ctx, id, connectorID)
+	}
+	return nil
+}
+
+func (m *MockDeviceService) ReleaseConnector(ctx context.Context, id string, connectorID int) error {
+	if m.ReleaseConnectorFunc != nil {
+		return m.ReleaseConnectorFunc(ctx, id, connectorID)
+	}
+	return nil
+}
+
+func (m *MockDeviceService) SetHeartbeatInterval(ctx context.Context, id string, interval int) error {
+	if m.SetHeartbeatIntervalFunc != nil {
+		return m.SetHeartbeatIntervalFunc(ctx, id, interval)
+	}
+	return nil
+}
+
+func (m *MockDeviceService) RecordBootInfo(ctx context.Context, id string, info domain.BootInfo) error {
+	if m.RecordBootInfoFunc != nil {
+		return m.RecordBootInfoFunc(ctx, id, info)
+	}
+	return nil
+}
+
+func (m *MockDeviceService) GetNearby(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
 	if m.GetNearbyFunc != nil {
-		return m.GetNearbyFunc(ctx, lat, lon, radius)
+		return m.GetNearbyFunc(ctx, lat, lon, radius, connectors)
 	}
 	return []domain.ChargePoint{}, nil
 }
@@ -57,9 +100,19 @@ type MockTransactionService struct {
 	GetTransactionFunc        func(ctx context.Context, id string) (*domain.Transaction, error)
 	GetActiveTransactionFunc  func(ctx context.Context, userID string) (*domain.Transaction, error)
 	GetTransactionHistoryFunc func(ctx context.Context, userID string) ([]domain.Transaction, error)
-	StartChargingFunc         func(ctx context.Context, userID string, stationID string) (*domain.Transaction, error)
+	StartChargingFunc         func(ctx context.Context, userID string, stationID string, selection domain.StationSelection) (*domain.Transaction, error)
 	StopActiveChargingFunc    func(ctx context.Context, userID string) error
-	GetCurrentSessionCostFunc func(ctx context.Context, userID string) (float64, error)
+	GetCurrentSessionCostFunc func(ctx context.Context, userID string) (*domain.SessionCost, error)
+
+	GetProjectedSessionCostFunc func(ctx context.Context, userID string, target domain.ChargeTarget) (*domain.SessionCostProjection, error)
+	RecordTransactionEventFunc  func(ctx context.Context, transactionID string, seqNo int, meterWh int) error
+	RecordStationEnergyFunc     func(ctx context.Context, transactionID string, energyWh int) error
+	RecordPowerFunc             func(ctx context.Context, transactionID string, powerKW float64, sampledAt time.Time) error
+	ListActiveSessionsFunc      func(ctx context.Context) ([]domain.ActiveSession, error)
+	ResumeTransactionFunc       func(ctx context.Context, transactionID string) (*domain.Transaction, error)
+	LinkOCPPTransactionFunc     func(ctx context.Context, transactionID, ocppTransactionID string, evseID int) error
+	FindByOCPPTransactionFunc   func(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error)
+	StartedTransactionsFunc     func(ctx context.Context, chargePointID string) ([]domain.Transaction, error)
 }
 
 func (m *MockTransactionService) StartTransaction(ctx context.Context, deviceID string, connectorID int, userID string, idTag string) (*domain.Transaction, error) {
@@ -76,6 +129,34 @@ func (m *MockTransactionService) StopTransaction(ctx context.Context, transactio
 	return nil, nil
 }
 
+func (m *MockTransactionService) ResumeTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+	if m.ResumeTransactionFunc != nil {
+		return m.ResumeTransactionFunc(ctx, transactionID)
+	}
+	return nil, nil
+}
+
+func (m *MockTransactionService) LinkOCPPTransaction(ctx context.Context, transactionID, ocppTransactionID string, evseID int) error {
+	if m.LinkOCPPTransactionFunc != nil {
+		return m.LinkOCPPTransactionFunc(ctx, transactionID, ocppTransactionID, evseID)
+	}
+	return nil
+}
+
+func (m *MockTransactionService) FindByOCPPTransaction(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+	if m.FindByOCPPTransactionFunc != nil {
+		return m.FindByOCPPTransactionFunc(ctx, chargePointID, ocppTransactionID)
+	}
+	return nil, nil
+}
+
+func (m *MockTransactionService) StartedTransactions(ctx context.Context, chargePointID string) ([]domain.Transaction, error) {
+	if m.StartedTransactionsFunc != nil {
+		return m.StartedTransactionsFunc(ctx, chargePointID)
+	}
+	return nil, nil
+}
+
 func (m *MockTransactionService) GetTransaction(ctx context.Context, id string) (*domain.Transaction, error) {
 	if m.GetTransactionFunc != nil {
 		return m.GetTransactionFunc(ctx, id)
@@ -97,9 +178,37 @@ func (m *MockTransactionService) GetTransactionHistory(ctx context.Context, user
 	return []domain.Transaction{}, nil
 }
 
-func (m *MockTransactionService) StartCharging(ctx context.Context, userID string, stationID string) (*domain.Transaction, error) {
+func (m *MockTransactionService) RecordTransactionEvent(ctx context.Context, transactionID string, seqNo int, meterWh int) error {
+	if m.RecordTransactionEventFunc != nil {
+		return m.RecordTransactionEventFunc(ctx, transactionID, seqNo, meterWh)
+	}
+	return nil
+}
+
+func (m *MockTransactionService) RecordStationEnergy(ctx context.Context, transactionID string, energyWh int) error {
+	if m.RecordStationEnergyFunc != nil {
+		return m.RecordStationEnergyFunc(ctx, transactionID, energyWh)
+	}
+	return nil
+}
+
+func (m *MockTransactionService) RecordPower(ctx context.Context, transactionID string, powerKW float64, sampledAt time.Time) error {
+	if m.RecordPowerFunc != nil {
+		return m.RecordPowerFunc(ctx, transactionID, powerKW, sampledAt)
+	}
+	return nil
+}
+
+func (m *MockTransactionService) ListActiveSessions(ctx context.Context) ([]domain.ActiveSession, error) {
+	if m.ListActiveSessionsFunc != nil {
+		return m.ListActiveSessionsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockTransactionService) StartCharging(ctx context.Context, userID string, stationID string, selection domain.StationSelection) (*domain.Transaction, error) {
 	if m.StartChargingFunc != nil {
-		return m.StartChargingFunc(ctx, userID, stationID)
+		return m.StartChargingFunc(ctx, userID, stationID, selection)
 	}
 	return nil, nil
 }
@@ -111,11 +220,18 @@ func (m *MockTransactionService) StopActiveCharging(ctx context.Context, userID
 	return nil
 }
 
-func (m *MockTransactionService) GetCurrentSessionCost(ctx context.Context, userID string) (float64, error) {
+func (m *MockTransactionService) GetCurrentSessionCost(ctx context.Context, userID string) (*domain.SessionCost, error) {
 	if m.GetCurrentSessionCostFunc != nil {
 		return m.GetCurrentSessionCostFunc(ctx, userID)
 	}
-	return 0, nil
+	return &domain.SessionCost{}, nil
+}
+
+func (m *MockTransactionService) GetProjectedSessionCost(ctx context.Context, userID string, target domain.ChargeTarget) (*domain.SessionCostProjection, error) {
+	if m.GetProjectedSessionCostFunc != nil {
+		return m.GetProjectedSessionCostFunc(ctx, userID, target)
+	}
+	return nil, nil
 }
 
 // MockEmailService is a mock implementation of EmailService interface
@@ -143,17 +259,8 @@ type SentEmail struct {
 	Data        map[string]interface{}
 }
 
-// Invoice for mock testing
-type Invoice struct {
-	ID            string
-	TransactionID string
-	Amount        float64
-	Currency      string
-	EnergyKWh     float64
-	Duration      string
-	StationName   string
-	Date          string
-}
+// Invoice for mock testing; the mock takes the same invoice the port does
+type Invoice = ports.Invoice
 
 func (m *MockEmailService) Send(ctx context.Context, to, subject, body string) error {
 	m.SentEmails = append(m.SentEmails, SentEmail{To: to, Subject: subject, Body: body})
@@ -41,6 +41,33 @@ var (
 		Buckets: []float64{60, 300, 600, 1800, 3600, 7200, 14400}, // 1min, 5min, 10min, 30min, 1h, 2h, 4h
 	})
 
+	// ==================== Payment Metrics ====================
+
+	// PaymentsTotal tracks payments by provider, method and outcome
+	PaymentsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_payments_total",
+		Help: "Total payments processed",
+	}, []string{"provider", "method", "status"}) // status: completed, failed
+
+	// PaymentRefundsTotal tracks refunds by provider and outcome
+	PaymentRefundsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_payment_refunds_total",
+		Help: "Total payment refunds processed",
+	}, []string{"provider", "status"}) // status: completed, failed
+
+	// PaymentWebhooksTotal tracks payment provider webhooks by outcome
+	PaymentWebhooksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_payment_webhooks_total",
+		Help: "Total payment provider webhooks received",
+	}, []string{"provider", "status"}) // status: processed, rejected, failed
+
+	// PaymentProviderLatency tracks the latency of calls to payment providers
+	PaymentProviderLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sigec_payment_provider_latency_seconds",
+		Help:    "Payment provider call latency in seconds",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0},
+	}, []string{"provider", "operation"})
+
 	// ==================== Voice Metrics ====================
 
 	// VoiceCommandsTotal tracks voice commands by intent and status
@@ -70,6 +97,24 @@ var (
 		Help: "Number of active OCPP WebSocket connections",
 	})
 
+	// OCPPCompressionNegotiated tracks whether connections negotiated permessage-deflate
+	OCPPCompressionNegotiated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_ocpp_compression_negotiated_total",
+		Help: "Total OCPP WebSocket connections by permessage-deflate negotiation result",
+	}, []string{"negotiated"})
+
+	// OCPPCompressionBytesSaved tracks bytes permessage-deflate kept off the wire
+	OCPPCompressionBytesSaved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_ocpp_compression_bytes_saved_total",
+		Help: "Total OCPP payload bytes saved by WebSocket compression",
+	}, []string{"direction"})
+
+	// OCPPConnectionsRejected tracks WebSocket connections refused by connection limits
+	OCPPConnectionsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_ocpp_connections_rejected_total",
+		Help: "Total OCPP WebSocket connections rejected by connection limits",
+	}, []string{"reason"})
+
 	// ==================== Device Metrics ====================
 
 	// DevicesTotal tracks total devices by status
@@ -117,6 +162,36 @@ var (
 		Name: "sigec_mq_messages_total",
 		Help: "Total message queue messages",
 	}, []string{"topic", "status"}) // status: published, consumed, failed
+
+	// CircuitBreakerState tracks the current state of each circuit breaker
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sigec_circuit_breaker_state",
+		Help: "Circuit breaker state (0 = closed, 1 = half-open, 2 = open)",
+	}, []string{"name"})
+
+	// CircuitBreakerTransitionsTotal tracks circuit breaker state transitions
+	CircuitBreakerTransitionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_circuit_breaker_transitions_total",
+		Help: "Total circuit breaker state transitions",
+	}, []string{"name", "from", "to"})
+
+	// WebSocketClientsDropped counts hub clients dropped for not keeping up
+	WebSocketClientsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sigec_websocket_clients_dropped_total",
+		Help: "Total WebSocket hub clients dropped because their send buffer was full",
+	})
+
+	// NotificationsSentTotal tracks notifications sent per channel
+	NotificationsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_notifications_sent_total",
+		Help: "Total notifications sent",
+	}, []string{"channel", "status"}) // status: sent, failed
+
+	// NotificationsDroppedTotal tracks notification events that were not sent
+	NotificationsDroppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sigec_notifications_dropped_total",
+		Help: "Total notification events dropped before dispatch",
+	}, []string{"reason"}) // malformed, unknown_type, unknown_user
 )
 
 // RecordTransactionStarted increments metrics when a transaction starts
@@ -134,6 +209,34 @@ func RecordTransactionCompleted(energyKWh float64, cost float64, currency string
 	ChargingDuration.Observe(durationSeconds)
 }
 
+// RecordPayment records a processed payment
+func RecordPayment(provider, method string, success bool) {
+	status := "completed"
+	if !success {
+		status = "failed"
+	}
+	PaymentsTotal.WithLabelValues(provider, method, status).Inc()
+}
+
+// RecordPaymentRefund records a processed refund
+func RecordPaymentRefund(provider string, success bool) {
+	status := "completed"
+	if !success {
+		status = "failed"
+	}
+	PaymentRefundsTotal.WithLabelValues(provider, status).Inc()
+}
+
+// RecordPaymentWebhook records a payment provider webhook
+func RecordPaymentWebhook(provider, status string) {
+	PaymentWebhooksTotal.WithLabelValues(provider, status).Inc()
+}
+
+// RecordPaymentProviderCall records the latency of a call to a payment provider
+func RecordPaymentProviderCall(provider, operation string, durationSeconds float64) {
+	PaymentProviderLatency.WithLabelValues(provider, operation).Observe(durationSeconds)
+}
+
 // RecordVoiceCommand records a voice command metric
 func RecordVoiceCommand(intent string, success bool, latencySeconds float64) {
 	status := "success"
@@ -153,6 +256,28 @@ func RecordOCPPMessage(action string, inbound bool) {
 	OCPPMessagesTotal.WithLabelValues(action, direction).Inc()
 }
 
+// RecordOCPPCompressionNegotiated records whether a connection uses compression
+func RecordOCPPCompressionNegotiated(negotiated bool) {
+	OCPPCompressionNegotiated.WithLabelValues(fmt.Sprintf("%t", negotiated)).Inc()
+}
+
+// RecordOCPPCompressionSaved records payload bytes saved by compression
+func RecordOCPPCompressionSaved(bytes int64, inbound bool) {
+	if bytes <= 0 {
+		return
+	}
+	direction := "outbound"
+	if inbound {
+		direction = "inbound"
+	}
+	OCPPCompressionBytesSaved.WithLabelValues(direction).Add(float64(bytes))
+}
+
+// RecordOCPPConnectionRejected records a connection refused by a connection limit
+func RecordOCPPConnectionRejected(reason string) {
+	OCPPConnectionsRejected.WithLabelValues(reason).Inc()
+}
+
 // RecordHTTPRequest records an HTTP request metric
 func RecordHTTPRequest(method, path string, status int, durationSeconds float64) {
 	statusStr := fmt.Sprintf("%d", status)
@@ -169,3 +294,33 @@ func RecordCacheAccess(hit bool) {
 	CacheHitsTotal.WithLabelValues(result).Inc()
 }
 
+
+// RecordCircuitBreakerState sets the current state gauge of a circuit breaker
+func RecordCircuitBreakerState(name string, state int) {
+	CircuitBreakerState.WithLabelValues(name).Set(float64(state))
+}
+
+// RecordCircuitBreakerTransition records a circuit breaker state change
+func RecordCircuitBreakerTransition(name, from, to string, state int) {
+	CircuitBreakerTransitionsTotal.WithLabelValues(name, from, to).Inc()
+	RecordCircuitBreakerState(name, state)
+}
+
+// RecordWebSocketClientDropped records a hub client dropped for backpressure
+func RecordWebSocketClientDropped() {
+	WebSocketClientsDropped.Inc()
+}
+
+// RecordNotificationSent records a notification sent on one channel
+func RecordNotificationSent(channel string, success bool) {
+	status := "sent"
+	if !success {
+		status = "failed"
+	}
+	NotificationsSentTotal.WithLabelValues(channel, status).Inc()
+}
+
+// RecordNotificationDropped records a notification event that was not sent
+func RecordNotificationDropped(reason string) {
+	NotificationsDroppedTotal.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,25 @@
+package ports
+
+import "context"
+
+// AuditActor is who performs an audited change and where the request came from
+type AuditActor struct {
+	UserID   string
+	Role     string
+	SourceIP string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context carrying the actor of the changes made
+// with it
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFrom returns the actor set with WithAuditActor, or the zero
+// actor for changes made by the system itself
+func AuditActorFrom(ctx context.Context) AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor
+}
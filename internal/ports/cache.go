@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -12,3 +13,45 @@ type Cache interface {
 	Ping() error // Added for health check in main.go
 	Close() error
 }
+
+// RateLimitStore counts requests per key in fixed windows. Implementations
+// backed by a shared store (Redis) enforce limits across instances.
+type RateLimitStore interface {
+	// Increment records one hit for key and returns the number of hits in the
+	// current window and the time left until the window resets
+	Increment(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error)
+}
+
+// NonceStore remembers request nonces for replay protection. Implementations
+// backed by a shared store (Redis) catch replays sent to another instance.
+type NonceStore interface {
+	// Claim records nonce for ttl and reports whether it was unused
+	Claim(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+	// Release forgets a claimed nonce, so a request that failed can be retried
+	Release(ctx context.Context, nonce string) error
+}
+
+// Lock errors
+var (
+	// ErrLockHeld is returned when another holder owns the lock
+	ErrLockHeld = errors.New("lock is held by another holder")
+	// ErrLockLost is returned when a lock expired and was taken over
+	ErrLockLost = errors.New("lock is no longer held")
+)
+
+// Locker hands out expiring locks. Implementations backed by a shared store
+// (Redis) exclude holders on every instance.
+type Locker interface {
+	// TryLock acquires key for ttl without waiting. It returns ErrLockHeld
+	// when the key is already locked.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is a lock acquired from a Locker
+type Lock interface {
+	// Refresh extends the lock to ttl from now. It returns ErrLockLost when
+	// the lock expired in the meantime.
+	Refresh(ctx context.Context, ttl time.Duration) error
+	// Release frees the lock, leaving it alone if it is now held by someone else
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,19 @@
+package ports
+
+import "context"
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context whose payment provider requests carry
+// key, so the provider runs a request repeated with the same key only once.
+// Callers derive key from what is being paid for, e.g. the session ID, so a
+// charge retried after an ambiguous timeout cannot take the money twice.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFrom returns the key set with WithIdempotencyKey, or ""
+func IdempotencyKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
@@ -0,0 +1,38 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// PushSender delivers push notifications to a device
+type PushSender interface {
+	SendPush(ctx context.Context, deviceToken, title, body string, data map[string]string) error
+}
+
+// SMSSender delivers text messages
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, message string) error
+}
+
+// NotificationPreferenceService lets users choose how they are notified
+type NotificationPreferenceService interface {
+	// GetPreferences returns the user's preferences, or the defaults when
+	// they never set any
+	GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error)
+
+	// UpdatePreferences changes the fields set in req
+	UpdatePreferences(ctx context.Context, userID string, req *NotificationPreferencesRequest) (*domain.NotificationPreferences, error)
+}
+
+// NotificationPreferencesRequest changes a user's notification preferences.
+// Nil fields are left as they are.
+type NotificationPreferencesRequest struct {
+	Email          *bool    `json:"email,omitempty"`
+	Push           *bool    `json:"push,omitempty"`
+	SMS            *bool    `json:"sms,omitempty"`
+	PhoneNumber    *string  `json:"phone_number,omitempty" validate:"omitempty,max=20"`
+	PushToken      *string  `json:"push_token,omitempty" validate:"omitempty,max=4096"`
+	DisabledEvents []string `json:"disabled_events,omitempty"` // Replaces the list; an empty list turns every type back on
+}
@@ -2,17 +2,39 @@ package ports
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
 )
 
+// ConnectorFilterKey is the FindAll and ListDevices filter key of a
+// domain.ConnectorFilter
+const ConnectorFilterKey = "connector"
+
 type ChargePointRepository interface {
 	Save(ctx context.Context, cp *domain.ChargePoint) error
 	FindByID(ctx context.Context, id string) (*domain.ChargePoint, error)
+	// FindAll filters on "status" and on a domain.ConnectorFilter under
+	// ConnectorFilterKey
 	FindAll(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error)
 	UpdateStatus(ctx context.Context, id string, status domain.ChargePointStatus) error
-	FindNearby(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error)
+	UpdateConnectorStatus(ctx context.Context, chargePointID string, connectorID int, status domain.ChargePointStatus) error
+	// CompareAndSetConnectorStatus atomically sets the connector's status to
+	// to if it is from, and reports whether it did. On a device without
+	// connector data the connector has the device-level status.
+	CompareAndSetConnectorStatus(ctx context.Context, chargePointID string, connectorID int, from, to domain.ChargePointStatus) (bool, error)
+	// FindNearby returns charge points within radius km matching connectors,
+	// closest first
+	FindNearby(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error)
+	// UpdateHeartbeatInterval stores the heartbeat interval (seconds) negotiated at boot
+	UpdateHeartbeatInterval(ctx context.Context, id string, interval int) error
+	// UpdateBootInfo stores the identity reported at boot, keeping the stored
+	// value of fields the charge point left empty, and returns the firmware
+	// version it replaced. Reading and replacing it is one atomic step, so
+	// two boots racing each other never both see the old version. Unknown
+	// charge points give domain.ErrDeviceNotFound.
+	UpdateBootInfo(ctx context.Context, id string, info domain.BootInfo) (previousFirmware string, err error)
 }
 
 type TransactionRepository interface {
@@ -22,6 +44,30 @@ type TransactionRepository interface {
 	FindHistoryByUserID(ctx context.Context, userID string) ([]domain.Transaction, error)
 	FindByDate(ctx context.Context, date time.Time) ([]domain.Transaction, error)
 	Update(ctx context.Context, tx *domain.Transaction) error
+
+	// FindPage returns up to limit transactions matching filter, newest first
+	// (created_at, then id). With after set, the page starts right after that
+	// transaction. Dates filter created_at in [StartDate, EndDate) when set.
+	FindPage(ctx context.Context, filter TransactionFilter, after *TransactionCursor, limit int) ([]domain.Transaction, error)
+	// Count returns the number of transactions matching filter
+	Count(ctx context.Context, filter TransactionFilter) (int, error)
+
+	// CompareAndSetPaymentStatus sets the payment status of a transaction to
+	// to if it is from, and reports whether it did. Of concurrent callers
+	// exactly one wins.
+	CompareAndSetPaymentStatus(ctx context.Context, id string, from, to domain.TransactionPaymentStatus) (bool, error)
+	// FindByPaymentStatus returns the transactions in status last updated
+	// before the given time, oldest first
+	FindByPaymentStatus(ctx context.Context, status domain.TransactionPaymentStatus, updatedBefore time.Time) ([]domain.Transaction, error)
+	// FindByOCPPTransactionID returns the transaction a station gave
+	// ocppTransactionID, or nil
+	FindByOCPPTransactionID(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error)
+}
+
+// TransactionCursor is the position of a transaction in FindPage order
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        string
 }
 
 type UserRepository interface {
@@ -40,6 +86,13 @@ type PaymentRepository interface {
 	GetPaymentsByTransaction(ctx context.Context, transactionID string) ([]domain.Payment, error)
 	SaveRefund(ctx context.Context, refund *domain.Refund) error
 	GetRefundsByPayment(ctx context.Context, paymentID string) ([]domain.Refund, error)
+	// ClaimWebhookEvent records that a provider's webhook event is being
+	// processed. It returns false when the event was already claimed, so
+	// retried deliveries are processed once.
+	ClaimWebhookEvent(ctx context.Context, provider, eventID string) (bool, error)
+	// ReleaseWebhookEvent forgets a claimed event whose processing failed,
+	// so the provider's retry is processed
+	ReleaseWebhookEvent(ctx context.Context, provider, eventID string) error
 }
 
 // CardRepository handles payment card persistence
@@ -56,6 +109,15 @@ type WalletRepository interface {
 	Save(ctx context.Context, wallet *domain.Wallet) error
 	GetByID(ctx context.Context, id string) (*domain.Wallet, error)
 	GetByUserID(ctx context.Context, userID string) (*domain.Wallet, error)
+	// AdjustBalance atomically adds delta to the wallet balance and bumps its
+	// version, returning the updated wallet. It returns
+	// domain.ErrInsufficientBalance instead of letting the balance go negative.
+	AdjustBalance(ctx context.Context, walletID string, delta float64) (*domain.Wallet, error)
+	// Credit atomically adds entry.Amount to the entry's wallet and records
+	// entry with the resulting balance. It returns domain.ErrAlreadyCredited,
+	// leaving the balance alone, when the wallet was already credited for
+	// entry.ReferenceID.
+	Credit(ctx context.Context, entry *domain.WalletTransaction) (*domain.Wallet, error)
 	SaveTransaction(ctx context.Context, tx *domain.WalletTransaction) error
 	GetTransactions(ctx context.Context, walletID string, limit, offset int) ([]domain.WalletTransaction, error)
 }
@@ -72,6 +134,88 @@ type ReservationRepository interface {
 	UpdateStatus(ctx context.Context, id string, status domain.ReservationStatus) error
 	Delete(ctx context.Context, id string) error
 	CountByUserAndStatus(ctx context.Context, userID string, statuses []domain.ReservationStatus) (int, error)
+	// GetByOCPPReservationID finds the reservation held on a charge point with ReserveNow
+	GetByOCPPReservationID(ctx context.Context, chargePointID string, reservationID int) (*domain.Reservation, error)
+}
+
+// MaintenanceWindowRepository handles planned maintenance persistence
+type MaintenanceWindowRepository interface {
+	Save(ctx context.Context, window *domain.MaintenanceWindow) error
+	// FindOverlapping returns the windows of a charge point that overlap [from, to)
+	FindOverlapping(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.MaintenanceWindow, error)
+}
+
+// DeviceInventoryRepository handles charge point device model persistence
+type DeviceInventoryRepository interface {
+	// Save replaces the stored inventory of inventory.ChargePointID
+	Save(ctx context.Context, inventory *domain.DeviceInventory) error
+	GetByChargePointID(ctx context.Context, chargePointID string) (*domain.DeviceInventory, error)
+}
+
+// MeterValueRepository handles meter sample persistence
+type MeterValueRepository interface {
+	Save(ctx context.Context, samples []domain.MeterSample) error
+	// FindByTransactionID returns the samples of a transaction, oldest first
+	FindByTransactionID(ctx context.Context, transactionID string) ([]domain.MeterSample, error)
+	// ReplaceByTransactionID replaces the samples of a transaction, e.g.
+	// with a downsampled series
+	ReplaceByTransactionID(ctx context.Context, transactionID string, samples []domain.MeterSample) error
+	// DeleteBefore removes every sample taken before t and returns how many
+	DeleteBefore(ctx context.Context, t time.Time) (int, error)
+}
+
+// ChargingProfileReportRepository handles reported charging profile persistence
+type ChargingProfileReportRepository interface {
+	// Save replaces the stored report of report.ChargePointID
+	Save(ctx context.Context, report *domain.ChargingProfileReport) error
+	GetByChargePointID(ctx context.Context, chargePointID string) (*domain.ChargingProfileReport, error)
+}
+
+// FirmwarePublicationRepository handles PublishFirmware request tracking
+type FirmwarePublicationRepository interface {
+	Save(ctx context.Context, pub *domain.FirmwarePublication) error
+	Update(ctx context.Context, pub *domain.FirmwarePublication) error
+	// FindByRequestID returns nil when the charge point has no such request
+	FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.FirmwarePublication, error)
+	// FindByChargePointID returns the newest publications first
+	FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.FirmwarePublication, error)
+}
+
+// LogRequestRepository handles GetLog request tracking
+type LogRequestRepository interface {
+	Save(ctx context.Context, req *domain.LogRequest) error
+	Update(ctx context.Context, req *domain.LogRequest) error
+	// FindByRequestID returns the request a charge point reports on, or nil
+	FindByRequestID(ctx context.Context, chargePointID string, requestID int) (*domain.LogRequest, error)
+	// FindByChargePointID returns a charge point's requests, newest first
+	FindByChargePointID(ctx context.Context, chargePointID string, limit int) ([]domain.LogRequest, error)
+}
+
+// ConnectionEventRepository stores when charge points connect and disconnect
+type ConnectionEventRepository interface {
+	Save(ctx context.Context, event *domain.ConnectionEvent) error
+	// FindByChargePointID returns a charge point's events in [from, to), oldest
+	// first; at the same instant a disconnect comes before a connect
+	FindByChargePointID(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.ConnectionEvent, error)
+	// FindLastBefore returns a charge point's last event before t, or nil
+	FindLastBefore(ctx context.Context, chargePointID string, t time.Time) (*domain.ConnectionEvent, error)
+}
+
+// StationCertificateRepository handles certificates issued to charge points
+type StationCertificateRepository interface {
+	Save(ctx context.Context, cert *domain.StationCertificate) error
+	// GetLatest returns the most recently issued certificate of certType, or nil
+	GetLatest(ctx context.Context, chargePointID string, certType domain.StationCertificateType) (*domain.StationCertificate, error)
+	// FindExpiringBefore returns the latest certificate per charge point and
+	// type when it expires before the given time
+	FindExpiringBefore(ctx context.Context, before time.Time) ([]domain.StationCertificate, error)
+}
+
+// ReconciliationRepository handles transaction reconciliation records
+type ReconciliationRepository interface {
+	Save(ctx context.Context, rec *domain.Reconciliation) error
+	// FindByTransactionID returns the reconciliation of a transaction, or nil
+	FindByTransactionID(ctx context.Context, transactionID string) (*domain.Reconciliation, error)
 }
 
 // AlertRepository handles alert persistence
@@ -82,6 +226,10 @@ type AlertRepository interface {
 	Acknowledge(ctx context.Context, id string) error
 	Delete(ctx context.Context, id string) error
 	CountUnacknowledged(ctx context.Context) (int, error)
+	// FindOpen returns the unresolved alerts of alertType raised by sourceID
+	FindOpen(ctx context.Context, alertType, sourceID string) ([]Alert, error)
+	// Resolve marks an alert as resolved because its condition cleared
+	Resolve(ctx context.Context, id string, resolvedAt time.Time) error
 }
 
 // Alert entity for repository
@@ -94,5 +242,144 @@ type Alert struct {
 	Source       string
 	SourceID     string
 	Acknowledged bool
+	Resolved     bool
+	ResolvedAt   *time.Time
 	CreatedAt    time.Time
 }
+
+// EventRepository is the append-only domain event log
+type EventRepository interface {
+	// Append stores event. It returns domain.ErrConflict when another
+	// writer already stored an event with event.Seq.
+	Append(ctx context.Context, event *domain.Event) error
+	// FindByAggregateID returns the aggregate's events with Seq > sinceSeq, oldest first
+	FindByAggregateID(ctx context.Context, aggregateID string, sinceSeq int64) ([]domain.Event, error)
+	// LastSeq returns the highest stored Seq, 0 when the log is empty
+	LastSeq(ctx context.Context) (int64, error)
+}
+
+// VoiceCommandRepository handles voice command history persistence
+type VoiceCommandRepository interface {
+	Save(ctx context.Context, cmd *domain.VoiceCommand) error
+	// FindByUserID returns a user's commands, newest first
+	FindByUserID(ctx context.Context, userID string, limit, offset int) ([]domain.VoiceCommand, error)
+	// AnonymizeByUserID blanks the transcript and result of a user's commands,
+	// keeping intent and timing for usage statistics
+	AnonymizeByUserID(ctx context.Context, userID string) error
+}
+
+// WebhookRepository handles webhook endpoints and their delivery log
+type WebhookRepository interface {
+	Save(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	// FindByID returns an endpoint, or nil
+	FindByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error)
+	// FindByAccountID returns an account's endpoints, oldest first
+	FindByAccountID(ctx context.Context, accountID string) ([]domain.WebhookEndpoint, error)
+	// FindByEventType returns the active endpoints subscribed to eventType
+	FindByEventType(ctx context.Context, eventType string) ([]domain.WebhookEndpoint, error)
+	Delete(ctx context.Context, id string) error
+	// SaveDelivery creates or updates a delivery
+	SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// FindDeliveries returns an endpoint's deliveries, newest first
+	FindDeliveries(ctx context.Context, endpointID string, limit, offset int) ([]domain.WebhookDelivery, error)
+}
+
+// PendingCommandRepository keeps the OCPP commands awaiting an answer
+type PendingCommandRepository interface {
+	Save(ctx context.Context, cmd *domain.PendingCommand) error
+	Delete(ctx context.Context, messageID string) error
+	// FindUnexpired returns the commands that expire after now, oldest first
+	FindUnexpired(ctx context.Context, now time.Time) ([]domain.PendingCommand, error)
+	// DeleteExpired removes the commands that expired at or before now
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// NotificationPreferenceRepository holds the channels users opted into
+type NotificationPreferenceRepository interface {
+	Save(ctx context.Context, prefs *domain.NotificationPreferences) error
+	// FindByUserID returns nil when the user never set any preferences
+	FindByUserID(ctx context.Context, userID string) (*domain.NotificationPreferences, error)
+}
+
+// AuditFilter narrows an audit log query; zero fields match everything
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       time.Time // Inclusive
+	To         time.Time // Exclusive
+}
+
+// AuditRepository handles the admin audit log. Entries are never changed
+// once saved.
+type AuditRepository interface {
+	Save(ctx context.Context, entry *domain.AuditEntry) error
+	// Find returns the entries matching filter, newest first, and how many
+	// match in total
+	Find(ctx context.Context, filter AuditFilter, limit, offset int) ([]domain.AuditEntry, int, error)
+}
+
+// Repositories is the set of repositories a storage backend provides.
+// Every backend implements all of them; see Missing.
+type Repositories struct {
+	ChargePoints         ChargePointRepository
+	Transactions         TransactionRepository
+	Users                UserRepository
+	Payments             PaymentRepository
+	Cards                CardRepository
+	Wallets              WalletRepository
+	Reservations         ReservationRepository
+	MaintenanceWindows   MaintenanceWindowRepository
+	Inventory            DeviceInventoryRepository
+	MeterValues          MeterValueRepository
+	ChargingProfiles     ChargingProfileReportRepository
+	LogRequests          LogRequestRepository
+	FirmwarePublications FirmwarePublicationRepository
+	ConnectionEvents     ConnectionEventRepository
+	StationCertificates  StationCertificateRepository
+	Reconciliations      ReconciliationRepository
+	Alerts               AlertRepository
+	Events               EventRepository
+	VoiceCommands        VoiceCommandRepository
+	V2G                  V2GRepository
+	ISO15118             ISO15118Repository
+	Webhooks             WebhookRepository
+	Audit                AuditRepository
+	PendingCommands      PendingCommandRepository
+	NotificationPrefs    NotificationPreferenceRepository
+
+	// Closer releases the backend's connection, if it holds one
+	Closer func() error
+	// Ping reports whether the backend is reachable, for readiness checks
+	Ping func(ctx context.Context) error
+}
+
+// Close releases the backend's connection
+func (r *Repositories) Close() error {
+	if r.Closer == nil {
+		return nil
+	}
+	return r.Closer()
+}
+
+// Healthy reports whether the backend is reachable
+func (r *Repositories) Healthy(ctx context.Context) error {
+	if r.Ping == nil {
+		return nil
+	}
+	return r.Ping(ctx)
+}
+
+// Missing names the repositories left nil, so a backend that lacks one is
+// refused at startup instead of failing on first use
+func (r *Repositories) Missing() []string {
+	var missing []string
+	v := reflect.ValueOf(r).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); f.Kind() == reflect.Interface && f.IsNil() {
+			missing = append(missing, v.Type().Field(i).Name)
+		}
+	}
+	return missing
+}
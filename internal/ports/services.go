@@ -2,6 +2,8 @@ package ports
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"time"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
@@ -18,21 +20,93 @@ type DeviceService interface {
 	GetDevice(ctx context.Context, id string) (*domain.ChargePoint, error)
 	ListDevices(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error)
 	UpdateStatus(ctx context.Context, id string, status domain.ChargePointStatus) error
-	GetNearby(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error)
+	UpdateConnectorStatus(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error
+	// ReserveConnector atomically moves an Available connector to Reserved.
+	// It returns domain.ErrConnectorUnavailable when the connector is not
+	// Available, e.g. because another session just took it.
+	ReserveConnector(ctx context.Context, id string, connectorID int) error
+	// ReleaseConnector moves a connector held by ReserveConnector back to
+	// Available. A connector that has moved on since is left alone.
+	ReleaseConnector(ctx context.Context, id string, connectorID int) error
+	// SetHeartbeatInterval records the heartbeat interval (seconds) a device was told to use
+	SetHeartbeatInterval(ctx context.Context, id string, interval int) error
+	// RecordBootInfo stores the vendor, model, serial number and firmware
+	// version a device reported at boot and publishes device.firmware_changed
+	// when the firmware version differs from the stored one
+	RecordBootInfo(ctx context.Context, id string, info domain.BootInfo) error
+	// GetNearby returns devices within radius km with a connector matching
+	// connectors, closest first
+	GetNearby(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error)
 	// Voice assistant methods
 	ListAvailableDevices(ctx context.Context) ([]domain.ChargePoint, error)
 }
 
+// FaultService raises alerts for faults reported by charge points
+type FaultService interface {
+	// ReportFault raises an alert for a faulted connector and publishes device.fault
+	ReportFault(ctx context.Context, fault domain.ConnectorFault) (*Alert, error)
+	// ResolveFaults resolves the open fault alerts of a connector that recovered
+	ResolveFaults(ctx context.Context, chargePointID string, connectorID int) error
+}
+
 type TransactionService interface {
 	StartTransaction(ctx context.Context, deviceID string, connectorID int, userID string, idTag string) (*domain.Transaction, error)
 	StopTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error)
 	GetTransaction(ctx context.Context, id string) (*domain.Transaction, error)
 	GetActiveTransaction(ctx context.Context, userID string) (*domain.Transaction, error)
 	GetTransactionHistory(ctx context.Context, userID string) ([]domain.Transaction, error)
+	RecordTransactionEvent(ctx context.Context, transactionID string, seqNo int, meterWh int) error
+	// RecordStationEnergy stores the session energy the station itself reported, for reconciliation
+	RecordStationEnergy(ctx context.Context, transactionID string, energyWh int) error
 	// Voice assistant methods
-	StartCharging(ctx context.Context, userID string, stationID string) (*domain.Transaction, error)
+	StartCharging(ctx context.Context, userID string, stationID string, selection domain.StationSelection) (*domain.Transaction, error)
 	StopActiveCharging(ctx context.Context, userID string) error
-	GetCurrentSessionCost(ctx context.Context, userID string) (float64, error)
+	GetCurrentSessionCost(ctx context.Context, userID string) (*domain.SessionCost, error)
+	GetProjectedSessionCost(ctx context.Context, userID string, target domain.ChargeTarget) (*domain.SessionCostProjection, error)
+	// Live sessions, served from memory
+	RecordPower(ctx context.Context, transactionID string, powerKW float64, sampledAt time.Time) error
+	ListActiveSessions(ctx context.Context) ([]domain.ActiveSession, error)
+}
+
+// TransactionResumer is implemented by transaction services that can reopen
+// a session its station reports as still ongoing
+type TransactionResumer interface {
+	ResumeTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error)
+}
+
+// StationTransactionLister is implemented by transaction services that can
+// list a station's started transactions from storage, so they are known
+// even after a restart
+type StationTransactionLister interface {
+	StartedTransactions(ctx context.Context, chargePointID string) ([]domain.Transaction, error)
+}
+
+// OCPPTransactionLinker is implemented by transaction services that keep
+// the transactionId a station gave a session, so its later events are
+// matched to the session even after a restart
+type OCPPTransactionLinker interface {
+	// LinkOCPPTransaction stores the station's transactionId on a session,
+	// with the EVSE it runs on
+	LinkOCPPTransaction(ctx context.Context, transactionID, ocppTransactionID string, evseID int) error
+	// FindByOCPPTransaction returns the session a station gave
+	// ocppTransactionID, or nil
+	FindByOCPPTransaction(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error)
+}
+
+// ActiveSessionIndex is implemented by transaction services that index their
+// started transactions in memory, so lookups by station or user need no scan
+type ActiveSessionIndex interface {
+	ActiveSessionsByChargePoint(ctx context.Context, chargePointID string) ([]domain.ActiveSession, error)
+	ActiveSessionsByUser(ctx context.Context, userID string) ([]domain.ActiveSession, error)
+}
+
+// QRChargeStarter is implemented by transaction services that start a
+// session for a walk-up user who scanned a connector's QR code
+type QRChargeStarter interface {
+	// StartFromQR starts a session on the scanned connector and asks its
+	// station to start charging. A code naming no known connector gives
+	// domain.ErrUnknownConnector.
+	StartFromQR(ctx context.Context, userID, code string) (*domain.Transaction, error)
 }
 
 // BillingService handles billing and payment calculations
@@ -94,16 +168,75 @@ type EmailService interface {
 	SendLowBalance(ctx context.Context, user *domain.User, balance float64) error
 }
 
-// Invoice represents an invoice for email sending
+// Invoice represents an invoice for email sending and PDF rendering
 type Invoice struct {
 	ID            string
 	TransactionID string
-	Amount        float64
+	Amount        float64 // Total, taxes included
 	Currency      string
 	EnergyKWh     float64
 	Duration      string
 	StationName   string
 	Date          string
+
+	// Line items; when EnergyCost is zero it is derived from Amount
+	UnitPrice  float64 // Price per kWh
+	EnergyCost float64
+	IdleFee    float64
+	Taxes      float64 // Sum of the components below when they are set
+
+	// Tax components (Brazilian invoices)
+	ICMS   float64
+	PIS    float64
+	COFINS float64
+
+	// Tax identifiers (CNPJ/CPF for Brazilian invoices). Empty values are
+	// rendered as blank fields to be filled in.
+	IssuerName    string
+	IssuerTaxID   string
+	CustomerName  string
+	CustomerTaxID string
+}
+
+// InvoiceService looks up invoices and renders them as PDF documents
+type InvoiceService interface {
+	// GetInvoice returns the invoice for one of the user's transactions. The
+	// ID may be the invoice ID ("INV-<transaction id>") or the transaction ID.
+	GetInvoice(ctx context.Context, userID, invoiceID string) (*Invoice, error)
+
+	// RenderPDF renders an invoice as a PDF document
+	RenderPDF(ctx context.Context, invoice *Invoice) ([]byte, error)
+}
+
+// WebhookService manages the endpoints partners receive our events at
+type WebhookService interface {
+	// Register creates an endpoint for an account. A signing secret is
+	// generated when the request carries none.
+	Register(ctx context.Context, accountID string, req *WebhookRequest) (*domain.WebhookEndpoint, error)
+
+	// List returns the account's endpoints
+	List(ctx context.Context, accountID string) ([]domain.WebhookEndpoint, error)
+
+	// Get returns one of the account's endpoints, or domain.ErrWebhookNotFound
+	Get(ctx context.Context, accountID, id string) (*domain.WebhookEndpoint, error)
+
+	// Update changes the fields set in req
+	Update(ctx context.Context, accountID, id string, req *WebhookRequest) (*domain.WebhookEndpoint, error)
+
+	// Delete removes an endpoint and its delivery log
+	Delete(ctx context.Context, accountID, id string) error
+
+	// Deliveries returns the delivery log of an endpoint, newest first
+	Deliveries(ctx context.Context, accountID, id string, limit, offset int) ([]domain.WebhookDelivery, error)
+}
+
+// WebhookRequest registers or updates a webhook endpoint. On update, zero
+// fields are left unchanged.
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret,omitempty"`
+	Active     *bool    `json:"active,omitempty"`
 }
 
 // PaymentService handles payment processing
@@ -117,6 +250,15 @@ type PaymentService interface {
 	// ProcessChargingPayment processes payment for a charging transaction
 	ProcessChargingPayment(ctx context.Context, userID string, transactionID string, amount float64) (*domain.Payment, error)
 
+	// Authorize places a hold on the user's card at session start
+	Authorize(ctx context.Context, userID string, transactionID string, amount float64) (*domain.Payment, error)
+
+	// Capture charges amount of an authorized payment and releases the rest
+	Capture(ctx context.Context, paymentID string, amount float64) (*domain.Payment, error)
+
+	// Void releases an authorized payment without charging it
+	Void(ctx context.Context, paymentID string) (*domain.Payment, error)
+
 	// GetPayment retrieves a payment by ID
 	GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error)
 
@@ -177,17 +319,43 @@ type WalletService interface {
 	// GetWallet retrieves or creates a user's wallet
 	GetWallet(ctx context.Context, userID string) (*domain.Wallet, error)
 
-	// AddFunds adds funds to the wallet
-	AddFunds(ctx context.Context, userID string, amount float64, paymentID string) error
+	// AddFunds adds funds to the wallet. An empty currency means the wallet's
+	// own; an empty wallet takes the currency of its first top-up, and other
+	// amounts are converted into the wallet's currency. It returns
+	// domain.ErrAlreadyCredited when paymentID was already credited.
+	AddFunds(ctx context.Context, userID string, amount float64, currency string, paymentID string) error
 
-	// DeductFunds deducts funds from the wallet
-	DeductFunds(ctx context.Context, userID string, amount float64, description string, referenceID string) error
+	// DeductFunds deducts funds from the wallet, converting amount from
+	// currency into the wallet's. It returns domain.ErrCurrencyNotHeld when
+	// no exchange rate is known.
+	DeductFunds(ctx context.Context, userID string, amount float64, currency string, description string, referenceID string) error
 
 	// GetTransactions retrieves wallet transaction history
 	GetTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.WalletTransaction, error)
 
-	// HasSufficientBalance checks if wallet has enough balance
-	HasSufficientBalance(ctx context.Context, userID string, amount float64) (bool, error)
+	// HasSufficientBalance checks if wallet has enough balance for amount
+	// in currency
+	HasSufficientBalance(ctx context.Context, userID string, amount float64, currency string) (bool, error)
+}
+
+// ChargingProfilePreview is the outcome of a SetChargingProfile dry run
+type ChargingProfilePreview struct {
+	Valid     bool                      `json:"valid"`
+	Errors    []string                  `json:"errors,omitempty"`
+	Composite []CompositeSchedulePeriod `json:"composite_schedule,omitempty"` // Effective limit on the EVSE if applied
+}
+
+// CompositeSchedulePeriod is one step of a composite schedule
+type CompositeSchedulePeriod struct {
+	StartPeriod int     `json:"start_period"` // Seconds from now
+	LimitW      float64 `json:"limit_w"`
+	Unlimited   bool    `json:"unlimited,omitempty"` // No profile applies and the station rating is unknown
+}
+
+// FXRateSource provides exchange rates between currencies
+type FXRateSource interface {
+	// Rate returns how many units of currency to one unit of from buys
+	Rate(ctx context.Context, from, to string) (float64, error)
 }
 
 // ReservationService handles charging station reservations
@@ -213,6 +381,10 @@ type ReservationService interface {
 	// ActivateReservation marks user as arrived and starts charging
 	ActivateReservation(ctx context.Context, id string, transactionID string) error
 
+	// ActivateReservationWithLocation activates a confirmed reservation and
+	// starts charging if the user at lat/lon is within the station geofence
+	ActivateReservationWithLocation(ctx context.Context, id string, lat, lon float64) error
+
 	// CompleteReservation marks reservation as completed
 	CompleteReservation(ctx context.Context, id string) error
 
@@ -229,6 +401,16 @@ type ReservationService interface {
 	GetReservationSummary(ctx context.Context, chargePointID string, startDate, endDate time.Time) (*domain.ReservationSummary, error)
 }
 
+// ReservationSync keeps reservations in line with what charge points report
+type ReservationSync interface {
+	// ApplyStationUpdate reconciles a ReservationStatusUpdate (Expired, Removed,
+	// Used) and returns the reservation, or nil if it is unknown
+	ApplyStationUpdate(ctx context.Context, chargePointID string, reservationID int, status string) (*domain.Reservation, error)
+	// LinkTransaction attaches a transaction started with idToken to the used
+	// reservation on that charge point still waiting for one
+	LinkTransaction(ctx context.Context, chargePointID, idToken, transactionID string) error
+}
+
 // ReservationRequest represents a reservation creation request
 type ReservationRequest struct {
 	UserID        string
@@ -251,6 +433,13 @@ type AdminService interface {
 	GetUserDetails(ctx context.Context, userID string) (*UserDetails, error)
 	UpdateUserStatus(ctx context.Context, userID string, status string) error
 	UpdateUserRole(ctx context.Context, userID string, role domain.UserRole) error
+	// ExportUserData assembles every record linked to a user (LGPD/GDPR data
+	// subject export). The profile is loaded up front; the returned export
+	// streams the remaining sections as JSON page by page.
+	ExportUserData(ctx context.Context, userID string) (io.WriterTo, error)
+	// AnonymizeUser erases a user's personal data (LGPD/GDPR) and keeps the
+	// financial records, which only reference the user by ID
+	AnonymizeUser(ctx context.Context, userID string) error
 
 	// Station management
 	GetStations(ctx context.Context, filter StationFilter, limit, offset int) ([]domain.ChargePoint, int, error)
@@ -258,8 +447,9 @@ type AdminService interface {
 	UpdateStationStatus(ctx context.Context, stationID string, status domain.ChargePointStatus) error
 
 	// Transaction management
-	GetTransactions(ctx context.Context, filter TransactionFilter, limit, offset int) ([]domain.Transaction, int, error)
+	GetTransactions(ctx context.Context, filter TransactionFilter, limit int, cursor string) (*TransactionPage, error)
 	GetTransactionDetails(ctx context.Context, txID string) (*TransactionDetails, error)
+	GetActiveTransactions(ctx context.Context) (*ActiveTransactions, error)
 
 	// Alerts and notifications
 	GetAlerts(ctx context.Context, limit, offset int) ([]Alert, error)
@@ -267,6 +457,9 @@ type AdminService interface {
 
 	// Reports
 	GenerateReport(ctx context.Context, reportType string, startDate, endDate time.Time) ([]byte, error)
+
+	// Audit log
+	GetAuditLog(ctx context.Context, filter AuditFilter, limit, offset int) ([]domain.AuditEntry, int, error)
 }
 
 // DashboardStats represents dashboard statistics
@@ -335,6 +528,21 @@ type TransactionFilter struct {
 	EndDate       time.Time
 }
 
+// TransactionPage is one page of a transaction listing. NextCursor is empty
+// on the last page.
+type TransactionPage struct {
+	Transactions []domain.Transaction `json:"transactions"`
+	Total        int                  `json:"total"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+}
+
+// ActiveTransactions lists the sessions in progress and their combined load
+type ActiveTransactions struct {
+	Sessions     []domain.ActiveSession `json:"sessions"`
+	Count        int                    `json:"count"`
+	TotalPowerKW float64                `json:"total_power_kw"`
+}
+
 // UserDetails provides detailed user information
 type UserDetails struct {
 	User              *domain.User          `json:"user"`
@@ -357,6 +565,7 @@ type StationDetails struct {
 	LastHeartbeat      *time.Time            `json:"last_heartbeat,omitempty"`
 	ActiveTransaction  *domain.Transaction   `json:"active_transaction,omitempty"`
 	RecentTransactions []domain.Transaction  `json:"recent_transactions,omitempty"`
+	ChargingLimits     []domain.ChargingLimit `json:"charging_limits,omitempty"` // EVSEs a charging profile limits right now
 }
 
 // TransactionDetails provides detailed transaction information
@@ -371,8 +580,11 @@ type TransactionDetails struct {
 // MeterValue represents a meter reading
 type MeterValue struct {
 	Timestamp time.Time `json:"timestamp"`
+	Measurand string    `json:"measurand"`
 	Value     float64   `json:"value"`
 	Unit      string    `json:"unit"`
+	Phase     string    `json:"phase,omitempty"`
+	Location  string    `json:"location,omitempty"`
 	Context   string    `json:"context"`
 }
 
@@ -459,20 +671,26 @@ type V2GRepository interface {
 
 // OCPPCommandService provides OCPP commands from CSMS to charge points
 type OCPPCommandService interface {
-	// RemoteStartTransaction requests charge point to start a transaction
-	RemoteStartTransaction(ctx context.Context, chargePointID, idToken string, evseID *int) error
+	// RemoteStartTransaction requests charge point to start a transaction.
+	// An empty tokenType means domain.DefaultIdTokenType.
+	RemoteStartTransaction(ctx context.Context, chargePointID, idToken string, tokenType domain.IdTokenType, evseID *int) error
 
 	// RemoteStopTransaction requests charge point to stop a transaction
 	RemoteStopTransaction(ctx context.Context, chargePointID, transactionID string) error
 
-	// Reset requests charge point to reset
-	Reset(ctx context.Context, chargePointID string, resetType string, evseID *int) error
+	// Reset requests charge point to reset and returns its status (Accepted,
+	// Rejected, Scheduled)
+	Reset(ctx context.Context, chargePointID string, resetType string, evseID *int) (string, error)
 
 	// TriggerMessage requests charge point to send a specific message
 	TriggerMessage(ctx context.Context, chargePointID, requestedMessage string, evseID *int) error
 
 	// SetChargingProfile sets a charging profile on an EVSE
-	SetChargingProfile(ctx context.Context, chargePointID string, evseID int, profile interface{}) error
+	SetChargingProfile(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) error
+
+	// PreviewChargingProfile validates a charging profile against the profiles
+	// active on the charge point without sending it (dry run)
+	PreviewChargingProfile(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) (*ChargingProfilePreview, error)
 
 	// ClearChargingProfile clears charging profile(s) from charge point
 	ClearChargingProfile(ctx context.Context, chargePointID string, profileID *int, evseID *int) error
@@ -483,31 +701,141 @@ type OCPPCommandService interface {
 	// UpdateFirmwareSigned requests signed firmware update
 	UpdateFirmwareSigned(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime, signingCert, signature string, retries, retryInterval *int) error
 
+	// PublishFirmware asks a local controller to download firmware from
+	// location and serve it to its peers; checksum is the file's MD5
+	PublishFirmware(ctx context.Context, chargePointID, location, checksum string, retries, retryInterval *int) error
+
 	// UnlockConnector requests to unlock a connector
 	UnlockConnector(ctx context.Context, chargePointID string, evseID, connectorID int) error
 
+	// ClearCache clears the charge point's Authorization Cache and returns
+	// its status (Accepted, Rejected)
+	ClearCache(ctx context.Context, chargePointID string) (string, error)
+
 	// ChangeAvailability changes charge point/EVSE availability
 	ChangeAvailability(ctx context.Context, chargePointID string, operationalStatus string, evseID *int) error
 
 	// GetVariables retrieves variable values from charge point
 	GetVariables(ctx context.Context, chargePointID string, variables []GetVariableRequest) ([]GetVariableResponse, error)
 
-	// SetVariables sets variable values on charge point
-	SetVariables(ctx context.Context, chargePointID string, variables []SetVariableRequest) error
+	// SetVariables sets variable values on charge point and returns the
+	// station's verdict on each
+	SetVariables(ctx context.Context, chargePointID string, variables []SetVariableRequest) ([]SetVariableResult, error)
+
+	// SetVariableMonitoring configures monitors on charge point variables and
+	// returns the station's verdict on each, in request order. Events of the
+	// accepted monitors are raised as alerts.
+	SetVariableMonitoring(ctx context.Context, chargePointID string, monitors []VariableMonitor) ([]VariableMonitorResult, error)
+
+	// SetMonitoringBase activates a predefined set of monitors (All,
+	// FactoryDefault, HardWiredOnly) and returns the charge point's status
+	// (Accepted, Rejected, NotSupported, EmptyResultSet)
+	SetMonitoringBase(ctx context.Context, chargePointID, monitoringBase string) (string, error)
 
 	// GetLog requests diagnostic logs from charge point
 	GetLog(ctx context.Context, chargePointID, logType, uploadURL string) error
 
+	// ReserveNow reserves a connector on the charge point for idToken until
+	// expiry. It fails unless the charge point accepts the reservation.
+	ReserveNow(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error
+
+	// CancelReservation cancels a reservation made with ReserveNow
+	CancelReservation(ctx context.Context, chargePointID string, reservationID int) error
+
+	// DataTransfer sends a vendor-specific message and returns the charge
+	// point's status (Accepted, Rejected, UnknownMessageId, UnknownVendorId)
+	DataTransfer(ctx context.Context, chargePointID, vendorID, messageID string, data json.RawMessage) (*DataTransferResult, error)
+
+	// InstallCertificate adds a root certificate to the station trust store
+	// and returns the charge point's status (Accepted, Rejected, Failed)
+	InstallCertificate(ctx context.Context, chargePointID, certificateType, certificate string) (string, error)
+
+	// DeleteCertificate removes an installed certificate and returns the
+	// charge point's status (Accepted, Failed, NotFound)
+	DeleteCertificate(ctx context.Context, chargePointID string, hashData CertificateHashData) (string, error)
+
+	// GetInstalledCertificateIds lists installed certificates, optionally
+	// restricted to certificateTypes
+	GetInstalledCertificateIds(ctx context.Context, chargePointID string, certificateTypes []string) ([]InstalledCertificate, error)
+
 	// V2G specific commands
 	SetV2GChargingProfile(ctx context.Context, chargePointID string, evseID int, dischargePowerKW float64, durationSeconds int) error
 	ClearV2GChargingProfile(ctx context.Context, chargePointID string, evseID int) error
 	GetV2GCapability(ctx context.Context, chargePointID string) (*domain.V2GCapability, error)
 
+	// BulkRemoteStop stops transactions on several charge points concurrently
+	// and reports the outcome for each target in request order
+	BulkRemoteStop(ctx context.Context, targets []RemoteStopTarget) []BulkCommandResult
+
 	// Connection status
 	IsConnected(chargePointID string) bool
 	GetConnectedClients() []string
 }
 
+// RemoteStopTarget identifies a transaction to stop in a bulk request.
+// An empty TransactionID stops the transaction currently active on the charge point.
+type RemoteStopTarget struct {
+	ChargePointID string
+	TransactionID string
+}
+
+// Bulk command result statuses
+const (
+	BulkStatusAccepted            = "Accepted"
+	BulkStatusFailed              = "Failed"
+	BulkStatusNotConnected        = "NotConnected"
+	BulkStatusNoActiveTransaction = "NoActiveTransaction"
+)
+
+// BulkCommandResult is the per-charge-point outcome of a bulk command
+type BulkCommandResult struct {
+	ChargePointID string `json:"device_id"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ConnectionTester checks that a charge point answers, by triggering a
+// message and waiting for it to arrive
+type ConnectionTester interface {
+	// TestConnection triggers requestedMessage (Heartbeat or
+	// BootNotification) and waits for it. A station that is not connected
+	// gives domain.ErrChargePointNotConnected; one that refuses or stays
+	// silent gives an unsuccessful result.
+	TestConnection(ctx context.Context, chargePointID, requestedMessage string) (*ConnectionTestResult, error)
+}
+
+// ConnectionTestResult is the outcome of a connection test
+type ConnectionTestResult struct {
+	ChargePointID    string `json:"device_id"`
+	RequestedMessage string `json:"requested_message"`
+	Success          bool   `json:"success"`
+	TriggerStatus    string `json:"trigger_status,omitempty"` // The station's answer to TriggerMessage
+	LatencyMs        int64  `json:"latency_ms"`               // From TriggerMessage sent to the triggered message received
+	Error            string `json:"error,omitempty"`
+}
+
+// DataTransferResult for OCPP DataTransfer response
+type DataTransferResult struct {
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// CertificateHashData identifies a certificate installed on a charge point
+type CertificateHashData struct {
+	HashAlgorithm  string `json:"hash_algorithm"`
+	IssuerNameHash string `json:"issuer_name_hash"`
+	IssuerKeyHash  string `json:"issuer_key_hash"`
+	SerialNumber   string `json:"serial_number"`
+}
+
+// InstalledCertificate is an entry of a charge point's trust store
+type InstalledCertificate struct {
+	CertificateType     string                `json:"certificate_type"`
+	CertificateHashData CertificateHashData   `json:"certificate_hash_data"`
+	ChildCertificates   []CertificateHashData `json:"child_certificates,omitempty"`
+}
+
 // GetVariableRequest for OCPP GetVariables
 type GetVariableRequest struct {
 	ComponentName string
@@ -519,6 +847,7 @@ type GetVariableRequest struct {
 type GetVariableResponse struct {
 	ComponentName string
 	VariableName  string
+	Instance      string
 	Value         string
 	Status        string
 }
@@ -527,9 +856,41 @@ type GetVariableResponse struct {
 type SetVariableRequest struct {
 	ComponentName string
 	VariableName  string
+	Instance      string
 	Value         string
 }
 
+// SetVariableResult for OCPP SetVariables response
+type SetVariableResult struct {
+	ComponentName string
+	VariableName  string
+	Instance      string
+	Status        string // Accepted, Rejected, RebootRequired, UnknownComponent, UnknownVariable, NotSupportedAttributeType
+	StatusInfo    string // Reason code given by the station, if any
+}
+
+// VariableMonitor for OCPP SetVariableMonitoring
+type VariableMonitor struct {
+	ID            *int // Set to replace an existing monitor
+	ComponentName string
+	VariableName  string
+	Instance      string
+	Type          string // UpperThreshold, LowerThreshold, Delta, Periodic, PeriodicClockAligned
+	Value         float64
+	Severity      int  // 0 (Danger) .. 9 (Debug)
+	Transaction   bool // Only monitor during transactions
+}
+
+// VariableMonitorResult for OCPP SetVariableMonitoring response
+type VariableMonitorResult struct {
+	ID            *int // The monitor's id on the station when accepted
+	ComponentName string
+	VariableName  string
+	Instance      string
+	Status        string // Accepted, UnknownComponent, UnknownVariable, UnsupportedMonitorType, Rejected, Duplicate
+	StatusInfo    string // Reason code given by the station, if any
+}
+
 // --- Firmware Service ---
 
 // FirmwareService handles firmware update operations
@@ -601,6 +962,75 @@ type ISO15118Service interface {
 	GetCertificateStatus(ctx context.Context, emaid string) (*ISO15118CertificateStatus, error)
 }
 
+// CustomerDataEraser clears what a charge point caches about a customer
+// (OCPP 2.0.1 CustomerInformation), e.g. after an account was anonymized
+type CustomerDataEraser interface {
+	ClearCustomerData(ctx context.Context, chargePointID, idToken string) error
+}
+
+// AuthorizationCacheClearer clears a charge point's Authorization Cache
+// (OCPP 2.0.1 ClearCache), e.g. after a user was blocked
+type AuthorizationCacheClearer interface {
+	ClearAuthorizationCache(ctx context.Context, chargePointID string) error
+}
+
+// ChargingLimiter caps the power of the session on an EVSE (an OCPP
+// TxProfile), e.g. to share a station's feeder between its connectors
+type ChargingLimiter interface {
+	SetChargingLimit(ctx context.Context, chargePointID string, evseID int, limitW float64) error
+}
+
+// V2GCompensationCalculator prices what a user is paid for a V2G session
+type V2GCompensationCalculator interface {
+	CalculateCompensation(ctx context.Context, session *domain.V2GSession) (*domain.V2GCompensation, error)
+}
+
+// ChargingWindowScheduler lets the session on an EVSE charge only between
+// start and end, e.g. in the cheapest hours before the driver's departure
+type ChargingWindowScheduler interface {
+	ScheduleChargingWindow(ctx context.Context, chargePointID string, evseID int, start, end time.Time, limitW float64) error
+}
+
+// SessionStopper asks the charge point running a session to end it (an OCPP
+// RequestStopTransaction), e.g. when a prepaid balance runs out. The
+// transaction is closed when the station reports it ended.
+type SessionStopper interface {
+	StopSession(ctx context.Context, chargePointID, transactionID string) error
+}
+
+// SessionStarter asks a charge point to start a session on one of its EVSEs
+// for a driver (an OCPP RequestStartTransaction), e.g. after they scanned
+// its QR code. The station reports the session when it starts.
+type SessionStarter interface {
+	StartSession(ctx context.Context, chargePointID string, evseID int, userID string) error
+}
+
+// ChargingLimitSource works out the power limit each EVSE of a charge point
+// is held to right now, from the charging profiles set on or reported by it
+type ChargingLimitSource interface {
+	// CurrentChargingLimits returns the EVSEs a charging profile limits now;
+	// EVSEs no profile applies to are left out
+	CurrentChargingLimits(ctx context.Context, chargePointID string) ([]domain.ChargingLimit, error)
+}
+
+// StationPriceSource quotes what charging at a charge point costs right now,
+// e.g. from the grid price of the region it is connected in
+type StationPriceSource interface {
+	PricePerKWh(ctx context.Context, chargePointID string) (float64, error)
+}
+
+// StationCertificateService signs and tracks the certificates charge points
+// use for OCPP security profile 3 and ISO 15118
+type StationCertificateService interface {
+	// SignCertificate signs a PEM encoded CSR sent by a charge point and
+	// returns the stored certificate
+	SignCertificate(ctx context.Context, chargePointID string, csrPEM string, certType domain.StationCertificateType) (*domain.StationCertificate, error)
+
+	// CheckExpiring raises alerts for certificates close to expiry and
+	// returns how many were found
+	CheckExpiring(ctx context.Context) (int, error)
+}
+
 // ISO15118CertificateStatus represents the status of an ISO 15118 certificate
 type ISO15118CertificateStatus struct {
 	EMAID            string     `json:"emaid"`
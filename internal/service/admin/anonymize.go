@@ -0,0 +1,271 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// anonymizedName replaces the name of an anonymized account
+const anonymizedName = "Anonymized user"
+
+// stationDataLookback is how far back anonymization looks for stations that
+// may still cache a user's idToken
+const stationDataLookback = 90 * 24 * time.Hour
+
+// SetCardRepository lets anonymization remove stored payment cards
+func (s *Service) SetCardRepository(cardRepo ports.CardRepository) {
+	s.cardRepo = cardRepo
+}
+
+// SetCertificateRevocation lets anonymization revoke the ISO 15118 contract
+// certificates of the vehicles a user charged with
+func (s *Service) SetCertificateRevocation(certRepo ports.ISO15118Repository, iso ports.ISO15118Service) {
+	s.certRepo = certRepo
+	s.iso15118 = iso
+}
+
+// SetCustomerDataEraser lets anonymization clear the data charge points
+// cache about the user (OCPP CustomerInformation)
+func (s *Service) SetCustomerDataEraser(eraser ports.CustomerDataEraser) {
+	s.eraser = eraser
+}
+
+// SetMessageQueue enables user.anonymized events
+func (s *Service) SetMessageQueue(mq queue.MessageQueue) {
+	s.mq = mq
+}
+
+// AnonymizeUser erases a user's personal data (LGPD/GDPR) while keeping
+// transactions, payments and wallet history for financial compliance; those
+// only reference the user by ID. Any running session is stopped first.
+//
+// Every step is safe to repeat and the user record is rewritten last, so a
+// failed run leaves the account usable and can simply be retried.
+func (s *Service) AnonymizeUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user %s: %w", userID, domain.ErrNotFound)
+	}
+	if user.IsAnonymized() {
+		return nil
+	}
+
+	if err := s.stopActiveSession(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.revokeContractCertificates(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.clearStationData(ctx, userID); err != nil {
+		return err
+	}
+
+	if s.cardRepo != nil {
+		cards, err := s.cardRepo.GetByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get payment cards: %w", err)
+		}
+		for _, card := range cards {
+			if err := s.cardRepo.Delete(ctx, card.ID); err != nil {
+				return fmt.Errorf("failed to delete payment card: %w", err)
+			}
+		}
+	}
+
+	if err := s.anonymizeReservations(ctx, userID); err != nil {
+		return err
+	}
+
+	if s.voiceRepo != nil {
+		if err := s.voiceRepo.AnonymizeByUserID(ctx, userID); err != nil {
+			return fmt.Errorf("failed to anonymize voice history: %w", err)
+		}
+	}
+
+	// The email stays unique; the empty password hash never matches a login
+	now := time.Now()
+	user.Name = anonymizedName
+	user.Email = fmt.Sprintf("anonymized+%s@invalid", user.ID)
+	user.Document = ""
+	user.Password = ""
+	user.Status = domain.UserStatusAnonymized
+	user.UpdatedAt = now
+	if err := s.userRepo.Save(ctx, user); err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	s.log.Info("User anonymized", zap.String("user_id", userID))
+	s.publishAnonymized(userID, now)
+
+	return nil
+}
+
+// stopActiveSession stops the user's running charging session, if any
+func (s *Service) stopActiveSession(ctx context.Context, userID string) error {
+	tx, err := s.txRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find active session: %w", err)
+	}
+	if tx == nil {
+		return nil
+	}
+	if s.txService == nil {
+		return fmt.Errorf("user has an active session: %w", ErrActiveSessionsUnavailable)
+	}
+	if _, err := s.txService.StopTransaction(ctx, tx.ID); err != nil {
+		return fmt.Errorf("failed to stop active session: %w", err)
+	}
+	return nil
+}
+
+// clearStationData asks every station the user charged at recently to forget
+// the idTokens used there. Stations that are offline or refuse are only
+// logged: their authorization cache expires by itself and the account can
+// no longer be authorized once anonymized.
+func (s *Service) clearStationData(ctx context.Context, userID string) error {
+	if s.eraser == nil {
+		return nil
+	}
+
+	type stationToken struct{ chargePointID, idToken string }
+	seen := make(map[stationToken]bool)
+	var tokens []stationToken
+
+	filter := ports.TransactionFilter{UserID: userID, StartDate: time.Now().Add(-stationDataLookback)}
+	var after *ports.TransactionCursor
+	for {
+		txs, err := s.txRepo.FindPage(ctx, filter, after, exportPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to find recent transactions: %w", err)
+		}
+		for _, tx := range txs {
+			idToken := tx.IdTag
+			if idToken == "" {
+				idToken = userID
+			}
+			key := stationToken{tx.ChargePointID, idToken}
+			if tx.ChargePointID == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			tokens = append(tokens, key)
+		}
+		if len(txs) < exportPageSize {
+			break
+		}
+		last := txs[len(txs)-1]
+		after = &ports.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	for _, t := range tokens {
+		if err := s.eraser.ClearCustomerData(ctx, t.chargePointID, t.idToken); err != nil {
+			s.log.Warn("Failed to clear customer data on station",
+				zap.String("user_id", userID),
+				zap.String("station_id", t.chargePointID),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// revokeContractCertificates revokes the contract certificates of every
+// vehicle seen in the user's V2G sessions
+func (s *Service) revokeContractCertificates(ctx context.Context, userID string) error {
+	if s.certRepo == nil || s.iso15118 == nil || s.v2gRepo == nil {
+		return nil
+	}
+
+	sessions, err := s.v2gRepo.GetSessionsByUser(ctx, userID, exportV2GLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get V2G sessions: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, session := range sessions {
+		if session.VehicleID == "" || seen[session.VehicleID] {
+			continue
+		}
+		seen[session.VehicleID] = true
+
+		certs, err := s.certRepo.GetCertificateByVIN(ctx, session.VehicleID)
+		if err != nil {
+			return fmt.Errorf("failed to get contract certificates: %w", err)
+		}
+		for _, cert := range certs {
+			if cert.Revoked {
+				continue
+			}
+			if err := s.iso15118.RevokeCertificate(ctx, cert.EMAID, "account anonymized"); err != nil {
+				return fmt.Errorf("failed to revoke contract certificate: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// anonymizeReservations clears the free-text notes of every reservation and
+// cancels the ones still waiting, so they no longer hold a connector
+func (s *Service) anonymizeReservations(ctx context.Context, userID string) error {
+	if s.reservationRepo == nil {
+		return nil
+	}
+
+	// Collect first so the updates cannot shift the pages being read
+	var reservations []domain.Reservation
+	for offset := 0; ; offset += exportPageSize {
+		batch, err := s.reservationRepo.GetByUserID(ctx, userID, "", exportPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to get reservations: %w", err)
+		}
+		reservations = append(reservations, batch...)
+		if len(batch) < exportPageSize {
+			break
+		}
+	}
+
+	now := time.Now()
+	for i := range reservations {
+		r := &reservations[i]
+		if r.Notes == "" && !r.CanBeCancelled() {
+			continue
+		}
+		r.Notes = ""
+		if r.CanBeCancelled() {
+			r.Status = domain.ReservationStatusCancelled
+			r.CancellationReason = "account anonymized"
+		}
+		r.UpdatedAt = now
+		if err := s.reservationRepo.Save(ctx, r); err != nil {
+			return fmt.Errorf("failed to anonymize reservation: %w", err)
+		}
+	}
+	return nil
+}
+
+// publishAnonymized emits user.anonymized so other services drop cached data
+func (s *Service) publishAnonymized(userID string, at time.Time) {
+	if s.mq == nil {
+		return
+	}
+
+	event := map[string]interface{}{
+		"user_id":   userID,
+		"timestamp": at.UTC().Format(time.RFC3339),
+	}
+	if data, err := json.Marshal(event); err == nil {
+		if err := s.mq.Publish("user.anonymized", data); err != nil {
+			s.log.Warn("Failed to publish user anonymized event", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+type memoryCards struct {
+	ports.CardRepository
+	cards []domain.PaymentCard
+}
+
+func (r *memoryCards) GetByUserID(ctx context.Context, userID string) ([]domain.PaymentCard, error) {
+	return r.cards, nil
+}
+
+func (r *memoryCards) Delete(ctx context.Context, id string) error {
+	for i, card := range r.cards {
+		if card.ID == id {
+			r.cards = append(r.cards[:i], r.cards[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type contractCertificates struct {
+	ports.ISO15118Repository
+	ports.ISO15118Service
+	revoked []string
+}
+
+func (c *contractCertificates) GetCertificateByVIN(ctx context.Context, vin string) ([]*domain.ISO15118Certificate, error) {
+	return []*domain.ISO15118Certificate{
+		{EMAID: "EMAID-" + vin},
+		{EMAID: "EMAID-OLD-" + vin, Revoked: true},
+	}, nil
+}
+
+func (c *contractCertificates) RevokeCertificate(ctx context.Context, emaid, reason string) error {
+	c.revoked = append(c.revoked, emaid)
+	return nil
+}
+
+type stationEraser struct {
+	cleared []string
+	err     error
+}
+
+func (e *stationEraser) ClearCustomerData(ctx context.Context, chargePointID, idToken string) error {
+	e.cleared = append(e.cleared, chargePointID+"/"+idToken)
+	return e.err
+}
+
+// anonymizationFixture is a user with a running session, a card, a pending
+// reservation with notes, a V2G vehicle and two finished transactions
+type anonymizationFixture struct {
+	user         *domain.User
+	active       *domain.Transaction
+	stopped      []string
+	cards        *memoryCards
+	reservations []domain.Reservation
+	voiceCleared bool
+	certs        *contractCertificates
+	mq           *mocks.MockMessageQueue
+	svc          *Service
+}
+
+func newAnonymizationFixture() *anonymizationFixture {
+	f := &anonymizationFixture{
+		user: &domain.User{
+			ID: "user-1", Name: "Maria Silva", Email: "maria@example.com",
+			Document: "12345678900", Password: "hash", Status: "Active",
+		},
+		active: &domain.Transaction{ID: "tx-live", UserID: "user-1", Status: domain.TransactionStatusStarted},
+		cards: &memoryCards{cards: []domain.PaymentCard{
+			{ID: "card-1", UserID: "user-1", HolderName: "MARIA SILVA", Last4: "4242"},
+		}},
+		reservations: []domain.Reservation{
+			{ID: "res-1", UserID: "user-1", Status: domain.ReservationStatusPending, Notes: "gate code 1234"},
+			{ID: "res-2", UserID: "user-1", Status: domain.ReservationStatusCompleted},
+		},
+		certs: &contractCertificates{},
+		mq:    mocks.NewMockMessageQueue(),
+	}
+
+	users := &mocks.MockUserRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.User, error) {
+			if id != f.user.ID {
+				return nil, nil
+			}
+			clone := *f.user
+			return &clone, nil
+		},
+		SaveFunc: func(ctx context.Context, user *domain.User) error {
+			*f.user = *user
+			return nil
+		},
+	}
+	history := []domain.Transaction{
+		{ID: "tx-1", UserID: "user-1", Cost: 30, MeterStart: 0, MeterStop: 20000},
+		{ID: "tx-2", UserID: "user-1", Cost: 12.5, MeterStart: 1000, MeterStop: 9000},
+	}
+	txs := &mocks.MockTransactionRepository{
+		FindActiveByUserIDFunc: func(ctx context.Context, userID string) (*domain.Transaction, error) {
+			return f.active, nil
+		},
+		FindHistoryByUserIDFunc: func(ctx context.Context, userID string) ([]domain.Transaction, error) {
+			return history, nil
+		},
+		FindPageFunc: func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+			if after != nil {
+				return nil, nil
+			}
+			return []domain.Transaction{
+				{ID: "tx-3", ChargePointID: "CP-1", IdTag: "RFID-1"},
+				{ID: "tx-4", ChargePointID: "CP-1", IdTag: "RFID-1"},
+				{ID: "tx-5", ChargePointID: "CP-2"},
+			}, nil
+		},
+	}
+	reservations := &mocks.MockReservationRepository{
+		GetByUserIDFunc: func(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error) {
+			return page(f.reservations, limit, offset), nil
+		},
+		SaveFunc: func(ctx context.Context, r *domain.Reservation) error {
+			for i := range f.reservations {
+				if f.reservations[i].ID == r.ID {
+					f.reservations[i] = *r
+				}
+			}
+			return nil
+		},
+	}
+
+	f.svc = NewService(users, nil, txs, nil, reservations, nil, zap.NewNop())
+	f.svc.SetTransactionService(&mocks.MockTransactionService{
+		StopTransactionFunc: func(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+			f.stopped = append(f.stopped, transactionID)
+			f.active = nil
+			return &domain.Transaction{ID: transactionID}, nil
+		},
+	})
+	f.svc.SetCardRepository(f.cards)
+	f.svc.SetV2GRepository(&exportV2G{sessions: []domain.V2GSession{
+		{ID: "v2g-1", VehicleID: "VIN1"}, {ID: "v2g-2", VehicleID: "VIN1"},
+	}})
+	f.svc.SetCertificateRevocation(f.certs, f.certs)
+	f.svc.SetVoiceCommandRepository(&mocks.MockVoiceCommandRepository{
+		AnonymizeByUserIDFunc: func(ctx context.Context, userID string) error {
+			f.voiceCleared = userID == "user-1"
+			return nil
+		},
+	})
+	f.svc.SetMessageQueue(f.mq)
+	return f
+}
+
+func TestAnonymizeUser_ClearsPIIAndKeepsTotals(t *testing.T) {
+	// Arrange
+	f := newAnonymizationFixture()
+	ctx := context.Background()
+	before, err := f.svc.GetUserDetails(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Act
+	err = f.svc.AnonymizeUser(ctx, "user-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(f.stopped) != 1 || f.stopped[0] != "tx-live" {
+		t.Errorf("expected the live session to be stopped, got %v", f.stopped)
+	}
+
+	u := f.user
+	if !u.IsAnonymized() {
+		t.Errorf("expected status %s, got %s", domain.UserStatusAnonymized, u.Status)
+	}
+	if strings.Contains(u.Name, "Maria") || strings.Contains(u.Email, "maria") || u.Document != "" || u.Password != "" {
+		t.Errorf("expected PII to be cleared, got %+v", u)
+	}
+	if len(f.cards.cards) != 0 {
+		t.Errorf("expected payment cards to be removed, got %v", f.cards.cards)
+	}
+	if f.reservations[0].Notes != "" || f.reservations[0].Status != domain.ReservationStatusCancelled {
+		t.Errorf("expected the pending reservation cancelled without notes, got %+v", f.reservations[0])
+	}
+	if f.reservations[1].Status != domain.ReservationStatusCompleted {
+		t.Errorf("expected the completed reservation to be kept, got %s", f.reservations[1].Status)
+	}
+	if !f.voiceCleared {
+		t.Error("expected the voice history to be anonymized")
+	}
+	if len(f.certs.revoked) != 1 || f.certs.revoked[0] != "EMAID-VIN1" {
+		t.Errorf("expected the vehicle's valid contract certificate revoked once, got %v", f.certs.revoked)
+	}
+
+	after, err := f.svc.GetUserDetails(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if after.TotalTransactions != before.TotalTransactions ||
+		after.TotalSpent != before.TotalSpent ||
+		after.TotalEnergyKWh != before.TotalEnergyKWh {
+		t.Errorf("expected totals %d/%.2f/%.2f to remain, got %d/%.2f/%.2f",
+			before.TotalTransactions, before.TotalSpent, before.TotalEnergyKWh,
+			after.TotalTransactions, after.TotalSpent, after.TotalEnergyKWh)
+	}
+
+	published := f.mq.PublishedMessages["user.anonymized"]
+	if len(published) != 1 {
+		t.Fatalf("expected one user.anonymized event, got %d", len(published))
+	}
+	var event map[string]interface{}
+	json.Unmarshal(published[0], &event)
+	if event["user_id"] != "user-1" {
+		t.Errorf("expected the event for user-1, got %v", event)
+	}
+}
+
+func TestAnonymizeUser_IsIdempotent(t *testing.T) {
+	// Arrange
+	f := newAnonymizationFixture()
+	ctx := context.Background()
+	if err := f.svc.AnonymizeUser(ctx, "user-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	updatedAt := f.user.UpdatedAt
+	time.Sleep(time.Millisecond)
+
+	// Act
+	err := f.svc.AnonymizeUser(ctx, "user-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !f.user.UpdatedAt.Equal(updatedAt) {
+		t.Error("expected an anonymized user to be left untouched")
+	}
+	if n := len(f.mq.PublishedMessages["user.anonymized"]); n != 1 {
+		t.Errorf("expected a single event, got %d", n)
+	}
+}
+
+func TestAnonymizeUser_ActiveSessionWithoutTransactionService(t *testing.T) {
+	// Arrange
+	f := newAnonymizationFixture()
+	f.svc.txService = nil
+
+	// Act
+	err := f.svc.AnonymizeUser(context.Background(), "user-1")
+
+	// Assert
+	if !errors.Is(err, ErrActiveSessionsUnavailable) {
+		t.Fatalf("expected ErrActiveSessionsUnavailable, got %v", err)
+	}
+	if f.user.IsAnonymized() || len(f.cards.cards) != 1 {
+		t.Error("expected nothing to be anonymized while a session is running")
+	}
+}
+
+func TestAnonymizeUser_ClearsStationData(t *testing.T) {
+	// Arrange
+	f := newAnonymizationFixture()
+	eraser := &stationEraser{err: errors.New("station offline")}
+	f.svc.SetCustomerDataEraser(eraser)
+
+	// Act
+	err := f.svc.AnonymizeUser(context.Background(), "user-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected an offline station not to block anonymization, got %v", err)
+	}
+	want := []string{"CP-1/RFID-1", "CP-2/user-1"}
+	if strings.Join(eraser.cleared, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v to be cleared, got %v", want, eraser.cleared)
+	}
+	if !f.user.IsAnonymized() {
+		t.Error("expected the user to be anonymized")
+	}
+}
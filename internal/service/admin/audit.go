@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Audit log page sizes
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+// SetAuditRepository enables GetAuditLog
+func (s *Service) SetAuditRepository(auditRepo ports.AuditRepository) {
+	s.auditRepo = auditRepo
+}
+
+// GetAuditLog returns the audit entries matching filter, newest first, and
+// how many match in total
+func (s *Service) GetAuditLog(ctx context.Context, filter ports.AuditFilter, limit, offset int) ([]domain.AuditEntry, int, error) {
+	if s.auditRepo == nil {
+		return nil, 0, fmt.Errorf("audit repository not configured")
+	}
+	if limit <= 0 {
+		limit = defaultAuditPageSize
+	}
+	if limit > maxAuditPageSize {
+		limit = maxAuditPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.auditRepo.Find(ctx, filter, limit, offset)
+}
+
+// auditLog writes audit entries for the decorators below
+type auditLog struct {
+	repo ports.AuditRepository
+	log  *zap.Logger
+}
+
+// record saves an entry for a change that has been applied. The actor comes
+// from ctx (see ports.WithAuditActor). A failed write is logged rather than
+// returned: the change has happened and reporting it as failed would invite
+// a retry.
+func (a *auditLog) record(ctx context.Context, action, entityType, entityID string, before, after map[string]interface{}) {
+	actor := ports.AuditActorFrom(ctx)
+	entry := &domain.AuditEntry{
+		ID:         uuid.New().String(),
+		ActorID:    actor.UserID,
+		ActorRole:  actor.Role,
+		SourceIP:   actor.SourceIP,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     before,
+		After:      after,
+		CreatedAt:  time.Now(),
+	}
+	if err := a.repo.Save(ctx, entry); err != nil {
+		a.log.Error("Failed to record audit entry",
+			zap.String("action", action),
+			zap.String("entity_id", entityID),
+			zap.String("actor_id", actor.UserID),
+			zap.Error(err),
+		)
+	}
+}
+
+// AuditedService records every admin mutation in the audit log, with the
+// values before and after. Reads go straight to the wrapped service.
+type AuditedService struct {
+	ports.AdminService
+	audit      auditLog
+	userRepo   ports.UserRepository
+	deviceRepo ports.ChargePointRepository
+	alertRepo  ports.AlertRepository // optional; alerts are audited without a before value when nil
+}
+
+// NewAuditedService wraps next so its mutations are audited. The
+// repositories are read for the values before each change.
+func NewAuditedService(
+	next ports.AdminService,
+	auditRepo ports.AuditRepository,
+	userRepo ports.UserRepository,
+	deviceRepo ports.ChargePointRepository,
+	alertRepo ports.AlertRepository,
+	log *zap.Logger,
+) *AuditedService {
+	return &AuditedService{
+		AdminService: next,
+		audit:        auditLog{repo: auditRepo, log: log},
+		userRepo:     userRepo,
+		deviceRepo:   deviceRepo,
+		alertRepo:    alertRepo,
+	}
+}
+
+// UpdateUserStatus updates a user's status and records the old and new status
+func (s *AuditedService) UpdateUserStatus(ctx context.Context, userID string, status string) error {
+	before := s.userSnapshot(ctx, userID, func(u *domain.User) interface{} { return u.Status })
+	if err := s.AdminService.UpdateUserStatus(ctx, userID, status); err != nil {
+		return err
+	}
+	s.audit.record(ctx, domain.AuditUserStatusUpdated, "user", userID,
+		snapshot("status", before), map[string]interface{}{"status": status})
+	return nil
+}
+
+// UpdateUserRole updates a user's role and records the old and new role
+func (s *AuditedService) UpdateUserRole(ctx context.Context, userID string, role domain.UserRole) error {
+	before := s.userSnapshot(ctx, userID, func(u *domain.User) interface{} { return string(u.Role) })
+	if err := s.AdminService.UpdateUserRole(ctx, userID, role); err != nil {
+		return err
+	}
+	s.audit.record(ctx, domain.AuditUserRoleUpdated, "user", userID,
+		snapshot("role", before), map[string]interface{}{"role": string(role)})
+	return nil
+}
+
+// AnonymizeUser anonymizes a user and records that it happened. The
+// personal data itself is never copied into the audit log.
+func (s *AuditedService) AnonymizeUser(ctx context.Context, userID string) error {
+	if err := s.AdminService.AnonymizeUser(ctx, userID); err != nil {
+		return err
+	}
+	s.audit.record(ctx, domain.AuditUserAnonymized, "user", userID,
+		nil, map[string]interface{}{"anonymized": true})
+	return nil
+}
+
+// UpdateStationStatus updates a station's status and records the old and new status
+func (s *AuditedService) UpdateStationStatus(ctx context.Context, stationID string, status domain.ChargePointStatus) error {
+	var before interface{}
+	if cp, err := s.deviceRepo.FindByID(ctx, stationID); err == nil && cp != nil {
+		before = string(cp.Status)
+	}
+	if err := s.AdminService.UpdateStationStatus(ctx, stationID, status); err != nil {
+		return err
+	}
+	s.audit.record(ctx, domain.AuditStationStatusUpdated, "station", stationID,
+		snapshot("status", before), map[string]interface{}{"status": string(status)})
+	return nil
+}
+
+// AcknowledgeAlert acknowledges an alert and records it
+func (s *AuditedService) AcknowledgeAlert(ctx context.Context, alertID string) error {
+	var before interface{}
+	if s.alertRepo != nil {
+		if alert, err := s.alertRepo.GetByID(ctx, alertID); err == nil && alert != nil {
+			before = alert.Acknowledged
+		}
+	}
+	if err := s.AdminService.AcknowledgeAlert(ctx, alertID); err != nil {
+		return err
+	}
+	s.audit.record(ctx, domain.AuditAlertAcknowledged, "alert", alertID,
+		snapshot("acknowledged", before), map[string]interface{}{"acknowledged": true})
+	return nil
+}
+
+// userSnapshot reads one field of a user before a change, or nil when the
+// user cannot be read
+func (s *AuditedService) userSnapshot(ctx context.Context, userID string, field func(u *domain.User) interface{}) interface{} {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil
+	}
+	return field(user)
+}
+
+// snapshot is {key: value}, or nil when the value is unknown
+func snapshot(key string, value interface{}) map[string]interface{} {
+	if value == nil {
+		return nil
+	}
+	return map[string]interface{}{key: value}
+}
+
+// AuditedPaymentService records refunds in the audit log. Everything else
+// goes straight to the wrapped service.
+type AuditedPaymentService struct {
+	ports.PaymentService
+	audit auditLog
+}
+
+// NewAuditedPaymentService wraps next so its refunds are audited
+func NewAuditedPaymentService(next ports.PaymentService, auditRepo ports.AuditRepository, log *zap.Logger) *AuditedPaymentService {
+	return &AuditedPaymentService{
+		PaymentService: next,
+		audit:          auditLog{repo: auditRepo, log: log},
+	}
+}
+
+// RefundPayment refunds a payment and records the payment status before and
+// the refund made
+func (s *AuditedPaymentService) RefundPayment(ctx context.Context, paymentID string, amount float64, reason string) (*domain.Refund, error) {
+	var before map[string]interface{}
+	if payment, err := s.PaymentService.GetPayment(ctx, paymentID); err == nil && payment != nil {
+		before = map[string]interface{}{"status": string(payment.Status), "amount": payment.Amount}
+	}
+
+	refund, err := s.PaymentService.RefundPayment(ctx, paymentID, amount, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	after := map[string]interface{}{
+		"refund_id":     refund.ID,
+		"refund_amount": refund.Amount,
+		"refund_status": string(refund.Status),
+		"reason":        reason,
+	}
+	s.audit.record(ctx, domain.AuditPaymentRefunded, "payment", paymentID, before, after)
+	return refund, nil
+}
@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+func newAuditedFixture(t *testing.T) (*AuditedService, ports.AuditRepository) {
+	t.Helper()
+	users := memory.NewUserRepository()
+	devices := memory.NewChargePointRepository()
+	audit := memory.NewAuditRepository()
+	if err := users.Save(context.Background(), &domain.User{ID: "user-1", Email: "ana@example.com", Role: domain.UserRoleUser, Status: "Active"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc := NewService(users, devices, nil, nil, nil, nil, zap.NewNop())
+	svc.SetAuditRepository(audit)
+	return NewAuditedService(svc, audit, users, devices, nil, zap.NewNop()), audit
+}
+
+func TestAuditedService_UpdateUserRoleRecordsOldAndNewRole(t *testing.T) {
+	// Arrange
+	svc, _ := newAuditedFixture(t)
+	ctx := ports.WithAuditActor(context.Background(), ports.AuditActor{
+		UserID:   "admin-1",
+		Role:     "admin",
+		SourceIP: "203.0.113.7",
+	})
+
+	// Act
+	err := svc.UpdateUserRole(ctx, "user-1", domain.UserRoleOperator)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, total, err := svc.GetAuditLog(context.Background(), ports.AuditFilter{EntityID: "user-1"}, 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", total)
+	}
+	entry := entries[0]
+	if entry.Action != domain.AuditUserRoleUpdated || entry.EntityType != "user" {
+		t.Errorf("expected a user.role_updated entry, got %s on %s", entry.Action, entry.EntityType)
+	}
+	if entry.Before["role"] != "user" || entry.After["role"] != "operator" {
+		t.Errorf("expected role user -> operator, got %v -> %v", entry.Before, entry.After)
+	}
+	if entry.ActorID != "admin-1" || entry.ActorRole != "admin" || entry.SourceIP != "203.0.113.7" {
+		t.Errorf("expected the actor admin-1 (admin) from 203.0.113.7, got %s (%s) from %s",
+			entry.ActorID, entry.ActorRole, entry.SourceIP)
+	}
+	if entry.CreatedAt.IsZero() {
+		t.Error("expected a timestamp")
+	}
+}
+
+func TestAuditedService_FailedChangeIsNotRecorded(t *testing.T) {
+	// Arrange
+	svc, audit := newAuditedFixture(t)
+	ctx := context.Background()
+
+	// Act
+	err := svc.UpdateUserRole(ctx, "missing", domain.UserRoleAdmin)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error for an unknown user")
+	}
+	if _, total, _ := audit.Find(ctx, ports.AuditFilter{}, 0, 0); total != 0 {
+		t.Errorf("expected no audit entry, got %d", total)
+	}
+}
+
+func TestAuditedService_FiltersByAction(t *testing.T) {
+	// Arrange
+	svc, _ := newAuditedFixture(t)
+	ctx := context.Background()
+	if err := svc.UpdateUserStatus(ctx, "user-1", "Blocked"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.UpdateUserRole(ctx, "user-1", domain.UserRoleOperator); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Act
+	entries, total, err := svc.GetAuditLog(ctx, ports.AuditFilter{Action: domain.AuditUserStatusUpdated}, 10, 0)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || entries[0].Before["status"] != "Active" || entries[0].After["status"] != "Blocked" {
+		t.Errorf("expected only the status change Active -> Blocked, got %d entries: %+v", total, entries)
+	}
+}
+
+func TestGetAuditLog_NotConfigured(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	_, _, err := svc.GetAuditLog(context.Background(), ports.AuditFilter{}, 10, 0)
+
+	if err == nil {
+		t.Error("expected an error without an audit repository")
+	}
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// authorizationCacheLookback is how far back blocking a user looks for
+// stations that may have cached their idToken as Accepted
+const authorizationCacheLookback = 30 * 24 * time.Hour
+
+// SetAuthorizationCacheClearer lets blocking a user clear the Authorization
+// Cache of the stations they recently charged at
+func (s *Service) SetAuthorizationCacheClearer(clearer ports.AuthorizationCacheClearer) {
+	s.authCache = clearer
+}
+
+// clearAuthorizationCaches sends ClearCache to every station the user
+// charged at within authorizationCacheLookback. The user is already blocked
+// in the CSMS, so failures are logged rather than returned; stations that
+// are offline fall back to the CSMS once the cache entry expires.
+func (s *Service) clearAuthorizationCaches(ctx context.Context, userID string) {
+	if s.authCache == nil {
+		return
+	}
+
+	stations, err := s.recentStations(ctx, userID, time.Now().Add(-authorizationCacheLookback))
+	if err != nil {
+		s.log.Warn("Failed to find stations to clear authorization caches on",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, stationID := range stations {
+		if err := s.authCache.ClearAuthorizationCache(ctx, stationID); err != nil {
+			s.log.Warn("Failed to clear authorization cache on station",
+				zap.String("user_id", userID),
+				zap.String("station_id", stationID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// recentStations returns the stations the user started a transaction at
+// since from, in order of first appearance
+func (s *Service) recentStations(ctx context.Context, userID string, from time.Time) ([]string, error) {
+	seen := make(map[string]bool)
+	var stations []string
+
+	filter := ports.TransactionFilter{UserID: userID, StartDate: from}
+	var after *ports.TransactionCursor
+	for {
+		txs, err := s.txRepo.FindPage(ctx, filter, after, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range txs {
+			if tx.ChargePointID == "" || seen[tx.ChargePointID] {
+				continue
+			}
+			seen[tx.ChargePointID] = true
+			stations = append(stations, tx.ChargePointID)
+		}
+		if len(txs) < exportPageSize {
+			return stations, nil
+		}
+		last := txs[len(txs)-1]
+		after = &ports.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
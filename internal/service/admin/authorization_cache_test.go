@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+type cacheClearer struct {
+	cleared []string
+	err     error
+}
+
+func (c *cacheClearer) ClearAuthorizationCache(ctx context.Context, chargePointID string) error {
+	c.cleared = append(c.cleared, chargePointID)
+	return c.err
+}
+
+// newBlockingFixture returns a service for a user with the given status who
+// recently charged at CP-1 (twice) and CP-2
+func newBlockingFixture(status string) (*Service, *cacheClearer, *ports.TransactionFilter) {
+	user := &domain.User{ID: "user-1", Status: status}
+	users := &mocks.MockUserRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.User, error) {
+			clone := *user
+			return &clone, nil
+		},
+		SaveFunc: func(ctx context.Context, u *domain.User) error {
+			*user = *u
+			return nil
+		},
+	}
+
+	var filter ports.TransactionFilter
+	txs := &mocks.MockTransactionRepository{
+		FindPageFunc: func(ctx context.Context, f ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+			filter = f
+			if after != nil {
+				return nil, nil
+			}
+			return []domain.Transaction{
+				{ID: "tx-1", ChargePointID: "CP-1"},
+				{ID: "tx-2", ChargePointID: "CP-2"},
+				{ID: "tx-3", ChargePointID: "CP-1"},
+			}, nil
+		},
+	}
+
+	clearer := &cacheClearer{}
+	svc := NewService(users, nil, txs, nil, nil, nil, zap.NewNop())
+	svc.SetAuthorizationCacheClearer(clearer)
+	return svc, clearer, &filter
+}
+
+func TestUpdateUserStatus_BlockingClearsRecentStationCaches(t *testing.T) {
+	// Arrange
+	svc, clearer, filter := newBlockingFixture(domain.UserStatusActive)
+
+	// Act
+	err := svc.UpdateUserStatus(context.Background(), "user-1", domain.UserStatusBlocked)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(clearer.cleared) != 2 || clearer.cleared[0] != "CP-1" || clearer.cleared[1] != "CP-2" {
+		t.Errorf("expected ClearCache on CP-1 and CP-2 once each, got %v", clearer.cleared)
+	}
+	if filter.UserID != "user-1" {
+		t.Errorf("expected transactions of user-1, got %q", filter.UserID)
+	}
+	if age := time.Since(filter.StartDate); age < authorizationCacheLookback-time.Minute || age > authorizationCacheLookback+time.Minute {
+		t.Errorf("expected transactions from the last %v, got from %v", authorizationCacheLookback, filter.StartDate)
+	}
+}
+
+func TestUpdateUserStatus_ClearsCachesOnlyWhenBlocking(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		status string
+	}{
+		{"deactivating", domain.UserStatusActive, domain.UserStatusInactive},
+		{"unblocking", domain.UserStatusBlocked, domain.UserStatusActive},
+		{"already blocked", domain.UserStatusBlocked, domain.UserStatusBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			svc, clearer, _ := newBlockingFixture(tt.from)
+
+			// Act
+			err := svc.UpdateUserStatus(context.Background(), "user-1", tt.status)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(clearer.cleared) != 0 {
+				t.Errorf("expected no ClearCache, got %v", clearer.cleared)
+			}
+		})
+	}
+}
+
+func TestUpdateUserStatus_BlockingSucceedsWhenStationsFail(t *testing.T) {
+	// Arrange
+	svc, clearer, _ := newBlockingFixture(domain.UserStatusActive)
+	clearer.err = errors.New("charge point not connected")
+
+	// Act
+	err := svc.UpdateUserStatus(context.Background(), "user-1", domain.UserStatusBlocked)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected the block to succeed, got %v", err)
+	}
+	if len(clearer.cleared) != 2 {
+		t.Errorf("expected every station to be tried, got %v", clearer.cleared)
+	}
+}
@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// chargingLimitTTL is how long a station's charging limits are reused;
+// operators refreshing the details page should not recompute them each time
+const chargingLimitTTL = 30 * time.Second
+
+// chargingLimitCache holds recently worked out charging limits per station
+type chargingLimitCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedChargingLimits
+}
+
+type cachedChargingLimits struct {
+	limits    []domain.ChargingLimit
+	expiresAt time.Time
+}
+
+// SetChargingLimitSource makes station details show the power limit each
+// EVSE is held to and the charging profile behind it
+func (s *Service) SetChargingLimitSource(source ports.ChargingLimitSource) {
+	s.limitSource = source
+	s.limitCache = &chargingLimitCache{entries: make(map[string]cachedChargingLimits)}
+}
+
+// chargingLimits returns a station's current charging limits, cached for
+// chargingLimitTTL. Failures are logged and leave the limits out.
+func (s *Service) chargingLimits(ctx context.Context, stationID string, now time.Time) []domain.ChargingLimit {
+	s.limitCache.mu.Lock()
+	cached, ok := s.limitCache.entries[stationID]
+	s.limitCache.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.limits
+	}
+
+	limits, err := s.limitSource.CurrentChargingLimits(ctx, stationID)
+	if err != nil {
+		s.log.Warn("Failed to get charging limits", zap.String("station_id", stationID), zap.Error(err))
+		return nil
+	}
+
+	s.limitCache.mu.Lock()
+	for id, entry := range s.limitCache.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.limitCache.entries, id)
+		}
+	}
+	s.limitCache.entries[stationID] = cachedChargingLimits{limits: limits, expiresAt: now.Add(chargingLimitTTL)}
+	s.limitCache.mu.Unlock()
+	return limits
+}
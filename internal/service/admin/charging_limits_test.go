@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// countingLimits serves limits and counts how often it was asked
+type countingLimits struct {
+	limits []domain.ChargingLimit
+	err    error
+	calls  int
+}
+
+func (c *countingLimits) CurrentChargingLimits(ctx context.Context, chargePointID string) ([]domain.ChargingLimit, error) {
+	c.calls++
+	return c.limits, c.err
+}
+
+func newLimitTestService(source *countingLimits) *Service {
+	devices := &mocks.MockChargePointRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, LastHeartbeat: time.Now()}, nil
+		},
+	}
+	svc := NewService(nil, devices, memoryTransactions(nil), nil, nil, nil, zap.NewNop())
+	svc.SetChargingLimitSource(source)
+	return svc
+}
+
+func TestGetStationDetails_ShowsChargingLimit(t *testing.T) {
+	// Arrange: a TxProfile throttles EVSE 1 to 7.4 kW
+	source := &countingLimits{limits: []domain.ChargingLimit{{
+		EvseID: 1, LimitW: 7400, RatedW: 22000, Throttled: true,
+		ProfileID: 7, Purpose: "TxProfile", StackLevel: 1, Source: "CSO",
+	}}}
+	svc := newLimitTestService(source)
+
+	// Act
+	first, err := svc.GetStationDetails(context.Background(), "CP001")
+	second, _ := svc.GetStationDetails(context.Background(), "CP001")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(first.ChargingLimits) != 1 || first.ChargingLimits[0] != source.limits[0] {
+		t.Errorf("expected the TxProfile limit, got %+v", first.ChargingLimits)
+	}
+	if len(second.ChargingLimits) != 1 {
+		t.Errorf("expected the cached limit, got %+v", second.ChargingLimits)
+	}
+	if source.calls != 1 {
+		t.Errorf("expected the limits to be worked out once, got %d", source.calls)
+	}
+}
+
+func TestGetStationDetails_ChargingLimitCacheExpires(t *testing.T) {
+	// Arrange
+	source := &countingLimits{}
+	svc := newLimitTestService(source)
+	now := time.Now()
+	svc.chargingLimits(context.Background(), "CP001", now)
+
+	// Act
+	svc.chargingLimits(context.Background(), "CP001", now.Add(chargingLimitTTL))
+
+	// Assert
+	if source.calls != 2 {
+		t.Errorf("expected the limits to be worked out again, got %d calls", source.calls)
+	}
+}
+
+func TestGetStationDetails_ChargingLimitFailure(t *testing.T) {
+	// Arrange
+	svc := newLimitTestService(&countingLimits{err: errors.New("report store down")})
+
+	// Act
+	details, err := svc.GetStationDetails(context.Background(), "CP001")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected the details without limits, got %v", err)
+	}
+	if details.ChargingLimits != nil {
+		t.Errorf("expected no limits, got %+v", details.ChargingLimits)
+	}
+}
@@ -0,0 +1,226 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// exportPageSize is how many records of a section are loaded at a time
+const exportPageSize = 500
+
+// exportV2GLimit caps the exported V2G sessions: V2GRepository cannot page
+const exportV2GLimit = 10000
+
+// SetWalletRepository adds the wallet and its history to user data exports
+func (s *Service) SetWalletRepository(walletRepo ports.WalletRepository) {
+	s.walletRepo = walletRepo
+}
+
+// SetV2GRepository adds V2G sessions to user data exports
+func (s *Service) SetV2GRepository(v2gRepo ports.V2GRepository) {
+	s.v2gRepo = v2gRepo
+}
+
+// SetVoiceCommandRepository adds the voice command history to user data exports
+func (s *Service) SetVoiceCommandRepository(voiceRepo ports.VoiceCommandRepository) {
+	s.voiceRepo = voiceRepo
+}
+
+// ExportUserData returns the data subject export of a user. Sections whose
+// repository is not configured are left out and logged.
+func (s *Service) ExportUserData(ctx context.Context, userID string) (io.WriterTo, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user %s: %w", userID, domain.ErrNotFound)
+	}
+
+	return &userDataExport{service: s, ctx: ctx, user: user}, nil
+}
+
+// userDataExport writes one user's records as a single JSON object
+type userDataExport struct {
+	service *Service
+	ctx     context.Context
+	user    *domain.User
+}
+
+// WriteTo streams the export. On error the JSON written so far is truncated.
+func (e *userDataExport) WriteTo(w io.Writer) (int64, error) {
+	s, ctx, userID := e.service, e.ctx, e.user.ID
+	out := &jsonStream{w: w}
+
+	out.raw("{")
+	out.field("exported_at", time.Now().UTC())
+	out.field("user", e.user)
+
+	var after *ports.TransactionCursor
+	writeArray(out, "transactions", func(int) ([]domain.Transaction, error) {
+		txs, err := s.txRepo.FindPage(ctx, ports.TransactionFilter{UserID: userID}, after, exportPageSize)
+		if len(txs) > 0 {
+			last := txs[len(txs)-1]
+			after = &ports.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+		return txs, err
+	})
+
+	if s.paymentRepo != nil {
+		writeArray(out, "payments", func(offset int) ([]domain.Payment, error) {
+			return s.paymentRepo.GetPaymentsByUser(ctx, userID, exportPageSize, offset)
+		})
+	} else {
+		e.skipped("payments")
+	}
+
+	if s.walletRepo != nil {
+		e.writeWallet(out)
+	} else {
+		e.skipped("wallet")
+	}
+
+	if s.reservationRepo != nil {
+		writeArray(out, "reservations", func(offset int) ([]domain.Reservation, error) {
+			return s.reservationRepo.GetByUserID(ctx, userID, "", exportPageSize, offset)
+		})
+	} else {
+		e.skipped("reservations")
+	}
+
+	if s.v2gRepo != nil {
+		writeArray(out, "v2g_sessions", func(offset int) ([]domain.V2GSession, error) {
+			if offset > 0 {
+				return nil, nil
+			}
+			return s.v2gRepo.GetSessionsByUser(ctx, userID, exportV2GLimit)
+		})
+	} else {
+		e.skipped("v2g_sessions")
+	}
+
+	if s.voiceRepo != nil {
+		writeArray(out, "voice_commands", func(offset int) ([]domain.VoiceCommand, error) {
+			return s.voiceRepo.FindByUserID(ctx, userID, exportPageSize, offset)
+		})
+	} else {
+		e.skipped("voice_commands")
+	}
+
+	out.raw("}")
+
+	if out.err != nil {
+		s.log.Error("User data export failed", zap.String("user_id", userID), zap.Error(out.err))
+	} else {
+		s.log.Info("User data exported", zap.String("user_id", userID), zap.Int64("bytes", out.n))
+	}
+	return out.n, out.err
+}
+
+// writeWallet writes the wallet and its transaction history
+func (e *userDataExport) writeWallet(out *jsonStream) {
+	wallet, err := e.service.walletRepo.GetByUserID(e.ctx, e.user.ID)
+	if err != nil {
+		out.fail(fmt.Errorf("failed to export wallet: %w", err))
+		return
+	}
+	out.field("wallet", wallet)
+	if wallet == nil {
+		return
+	}
+	writeArray(out, "wallet_transactions", func(offset int) ([]domain.WalletTransaction, error) {
+		return e.service.walletRepo.GetTransactions(e.ctx, wallet.ID, exportPageSize, offset)
+	})
+}
+
+func (e *userDataExport) skipped(section string) {
+	e.service.log.Warn("User data export without section, repository not configured",
+		zap.String("user_id", e.user.ID),
+		zap.String("section", section),
+	)
+}
+
+// jsonStream writes a JSON object field by field and keeps the first error
+type jsonStream struct {
+	w      io.Writer
+	n      int64
+	err    error
+	fields int
+}
+
+func (j *jsonStream) fail(err error) {
+	if j.err == nil {
+		j.err = err
+	}
+}
+
+func (j *jsonStream) raw(s string) {
+	if j.err != nil {
+		return
+	}
+	n, err := io.WriteString(j.w, s)
+	j.n += int64(n)
+	j.fail(err)
+}
+
+func (j *jsonStream) value(v interface{}) {
+	if j.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		j.fail(err)
+		return
+	}
+	n, err := j.w.Write(data)
+	j.n += int64(n)
+	j.fail(err)
+}
+
+func (j *jsonStream) key(name string) {
+	if j.fields > 0 {
+		j.raw(",")
+	}
+	j.fields++
+	j.value(name)
+	j.raw(":")
+}
+
+func (j *jsonStream) field(name string, v interface{}) {
+	j.key(name)
+	j.value(v)
+}
+
+// writeArray writes the field name as an array of the pages returned by next,
+// stopping after the first short page
+func writeArray[T any](j *jsonStream, name string, next func(offset int) ([]T, error)) {
+	j.key(name)
+	j.raw("[")
+	count := 0
+	for offset := 0; j.err == nil; offset += exportPageSize {
+		items, err := next(offset)
+		if err != nil {
+			j.fail(fmt.Errorf("failed to export %s: %w", name, err))
+			return
+		}
+		for i := range items {
+			if count > 0 {
+				j.raw(",")
+			}
+			j.value(&items[i])
+			count++
+		}
+		if len(items) < exportPageSize {
+			break
+		}
+	}
+	j.raw("]")
+}
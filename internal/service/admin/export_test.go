@@ -0,0 +1,182 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// page returns items[offset:offset+limit]
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
+
+type exportPayments struct {
+	ports.PaymentRepository
+	payments []domain.Payment
+}
+
+func (r *exportPayments) GetPaymentsByUser(ctx context.Context, userID string, limit, offset int) ([]domain.Payment, error) {
+	return page(r.payments, limit, offset), nil
+}
+
+type exportWallets struct {
+	ports.WalletRepository
+	wallet  *domain.Wallet
+	history []domain.WalletTransaction
+}
+
+func (r *exportWallets) GetByUserID(ctx context.Context, userID string) (*domain.Wallet, error) {
+	return r.wallet, nil
+}
+
+func (r *exportWallets) GetTransactions(ctx context.Context, walletID string, limit, offset int) ([]domain.WalletTransaction, error) {
+	return page(r.history, limit, offset), nil
+}
+
+type exportV2G struct {
+	ports.V2GRepository
+	sessions []domain.V2GSession
+}
+
+func (r *exportV2G) GetSessionsByUser(ctx context.Context, userID string, limit int) ([]domain.V2GSession, error) {
+	return page(r.sessions, limit, 0), nil
+}
+
+// exportTransactions gives every other sample transaction to user-1
+func exportTransactions() []domain.Transaction {
+	txs := sampleTransactions()
+	for i := range txs {
+		txs[i].UserID = fmt.Sprintf("user-%d", 1+i%2)
+	}
+	return txs
+}
+
+func exportService(t *testing.T) *Service {
+	t.Helper()
+
+	// More voice commands than fit in one page
+	voice := make([]domain.VoiceCommand, exportPageSize+3)
+	for i := range voice {
+		voice[i] = domain.VoiceCommand{ID: fmt.Sprintf("voice-%d", i), UserID: "user-1"}
+	}
+
+	users := &mocks.MockUserRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.User, error) {
+			if id != "user-1" {
+				return nil, nil
+			}
+			return &domain.User{ID: id, Name: "Maria", Password: "hash"}, nil
+		},
+	}
+	reservations := &mocks.MockReservationRepository{
+		GetByUserIDFunc: func(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error) {
+			return page([]domain.Reservation{{ID: "res-1", UserID: userID}}, limit, offset), nil
+		},
+	}
+
+	svc := NewService(users, nil, memoryTransactions(exportTransactions()),
+		&exportPayments{payments: []domain.Payment{{ID: "pay-1", UserID: "user-1"}}},
+		reservations, nil, zap.NewNop())
+	svc.SetWalletRepository(&exportWallets{
+		wallet:  &domain.Wallet{ID: "wallet-1", UserID: "user-1"},
+		history: []domain.WalletTransaction{{ID: "wtx-1"}, {ID: "wtx-2"}},
+	})
+	svc.SetV2GRepository(&exportV2G{sessions: []domain.V2GSession{{ID: "v2g-1"}}})
+	svc.SetVoiceCommandRepository(&mocks.MockVoiceCommandRepository{
+		FindByUserIDFunc: func(ctx context.Context, userID string, limit, offset int) ([]domain.VoiceCommand, error) {
+			return page(voice, limit, offset), nil
+		},
+	})
+	return svc
+}
+
+func TestExportUserData_PopulatesEverySection(t *testing.T) {
+	// Arrange
+	svc := exportService(t)
+	var userTxs int
+	for _, tx := range exportTransactions() {
+		if tx.UserID == "user-1" {
+			userTxs++
+		}
+	}
+
+	// Act
+	export, err := svc.ExportUserData(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var buf bytes.Buffer
+	n, err := export.WriteTo(&buf)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected %d bytes reported, got %d", buf.Len(), n)
+	}
+
+	var bundle map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &bundle); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+	var user map[string]interface{}
+	json.Unmarshal(bundle["user"], &user)
+	if user["id"] != "user-1" || user["password"] != nil {
+		t.Errorf("expected the profile without credentials, got %v", user)
+	}
+	var wallet domain.Wallet
+	json.Unmarshal(bundle["wallet"], &wallet)
+	if wallet.ID != "wallet-1" {
+		t.Errorf("expected wallet-1, got %q", wallet.ID)
+	}
+
+	sections := map[string]int{
+		"transactions":        userTxs,
+		"payments":            1,
+		"wallet_transactions": 2,
+		"reservations":        1,
+		"v2g_sessions":        1,
+		"voice_commands":      exportPageSize + 3,
+	}
+	for section, want := range sections {
+		var items []json.RawMessage
+		if err := json.Unmarshal(bundle[section], &items); err != nil {
+			t.Errorf("expected %s to be an array, got %s", section, bundle[section])
+			continue
+		}
+		if len(items) != want {
+			t.Errorf("expected %d %s, got %d", want, section, len(items))
+		}
+	}
+}
+
+func TestExportUserData_UnknownUser(t *testing.T) {
+	// Arrange
+	svc := exportService(t)
+
+	// Act
+	_, err := svc.ExportUserData(context.Background(), "nobody")
+
+	// Assert
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
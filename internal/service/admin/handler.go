@@ -1,22 +1,39 @@
 package admin
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/circuitbreaker"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // Handler handles admin HTTP requests
 type Handler struct {
-	service ports.AdminService
+	service  ports.AdminService
+	breakers *circuitbreaker.Manager
+	tester   ports.ConnectionTester // optional, see SetConnectionTester
 }
 
-// NewHandler creates a new admin handler
-func NewHandler(service ports.AdminService) *Handler {
-	return &Handler{service: service}
+// NewHandler creates a new admin handler. breakers may be nil when no
+// circuit breakers are configured.
+func NewHandler(service ports.AdminService, breakers *circuitbreaker.Manager) *Handler {
+	return &Handler{
+		service:  service,
+		breakers: breakers,
+	}
+}
+
+// SetConnectionTester enables the station connection test
+func (h *Handler) SetConnectionTester(tester ports.ConnectionTester) {
+	h.tester = tester
 }
 
 // RegisterRoutes registers admin routes
@@ -31,6 +48,8 @@ func (h *Handler) RegisterRoutes(app *fiber.App, authMiddleware, adminMiddleware
 	// Users
 	admin.Get("/users", h.GetUsers)
 	admin.Get("/users/:id", h.GetUserDetails)
+	admin.Get("/users/:id/export", h.ExportUserData)
+	admin.Post("/users/:id/anonymize", h.AnonymizeUser)
 	admin.Patch("/users/:id/status", h.UpdateUserStatus)
 	admin.Patch("/users/:id/role", h.UpdateUserRole)
 
@@ -38,9 +57,11 @@ func (h *Handler) RegisterRoutes(app *fiber.App, authMiddleware, adminMiddleware
 	admin.Get("/stations", h.GetStations)
 	admin.Get("/stations/:id", h.GetStationDetails)
 	admin.Patch("/stations/:id/status", h.UpdateStationStatus)
+	admin.Post("/devices/:id/test", h.TestConnection)
 
 	// Transactions
 	admin.Get("/transactions", h.GetTransactions)
+	admin.Get("/transactions/active", h.GetActiveTransactions)
 	admin.Get("/transactions/:id", h.GetTransactionDetails)
 
 	// Alerts
@@ -49,6 +70,12 @@ func (h *Handler) RegisterRoutes(app *fiber.App, authMiddleware, adminMiddleware
 
 	// Reports
 	admin.Get("/reports/:type", h.GenerateReport)
+
+	// Audit
+	admin.Get("/audit", h.GetAuditLog)
+
+	// System
+	admin.Get("/circuit-breakers", h.GetCircuitBreakers)
 }
 
 // GetDashboard handles GET /api/v1/admin/dashboard
@@ -130,6 +157,59 @@ func (h *Handler) GetUserDetails(c *fiber.Ctx) error {
 	return c.JSON(details)
 }
 
+// ExportUserData handles GET /api/v1/admin/users/:id/export
+// The export is streamed, so a failure halfway truncates the JSON body.
+func (h *Handler) ExportUserData(c *fiber.Ctx) error {
+	userID := c.Params("id")
+
+	export, err := h.service.ExportUserData(c.Context(), userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set("Content-Type", "application/json")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=user-%s-export.json", userID))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The service logs failures; the status line has already been sent
+		export.WriteTo(w)
+		w.Flush()
+	})
+
+	return nil
+}
+
+// AnonymizeUser handles POST /api/v1/admin/users/:id/anonymize
+func (h *Handler) AnonymizeUser(c *fiber.Ctx) error {
+	userID := c.Params("id")
+
+	if err := h.service.AnonymizeUser(auditContext(c), userID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, ErrActiveSessionsUnavailable):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User anonymized",
+	})
+}
+
 // UpdateUserStatus handles PATCH /api/v1/admin/users/:id/status
 func (h *Handler) UpdateUserStatus(c *fiber.Ctx) error {
 	userID := c.Params("id")
@@ -138,13 +218,11 @@ func (h *Handler) UpdateUserStatus(c *fiber.Ctx) error {
 		Status string `json:"status" validate:"required,oneof=Active Inactive Blocked"`
 	}
 
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := validation.ParseBody(c, &body); err != nil {
+		return err
 	}
 
-	if err := h.service.UpdateUserStatus(c.Context(), userID, body.Status); err != nil {
+	if err := h.service.UpdateUserStatus(auditContext(c), userID, body.Status); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -163,13 +241,11 @@ func (h *Handler) UpdateUserRole(c *fiber.Ctx) error {
 		Role string `json:"role" validate:"required,oneof=admin operator user"`
 	}
 
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := validation.ParseBody(c, &body); err != nil {
+		return err
 	}
 
-	if err := h.service.UpdateUserRole(c.Context(), userID, domain.UserRole(body.Role)); err != nil {
+	if err := h.service.UpdateUserRole(auditContext(c), userID, domain.UserRole(body.Role)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -227,13 +303,11 @@ func (h *Handler) UpdateStationStatus(c *fiber.Ctx) error {
 		Status string `json:"status" validate:"required"`
 	}
 
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := validation.ParseBody(c, &body); err != nil {
+		return err
 	}
 
-	if err := h.service.UpdateStationStatus(c.Context(), stationID, domain.ChargePointStatus(body.Status)); err != nil {
+	if err := h.service.UpdateStationStatus(auditContext(c), stationID, domain.ChargePointStatus(body.Status)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -244,7 +318,49 @@ func (h *Handler) UpdateStationStatus(c *fiber.Ctx) error {
 	})
 }
 
+// TestConnection handles POST /api/v1/admin/devices/:id/test
+// The optional body {"message": "BootNotification"} picks the triggered
+// message; Heartbeat by default.
+func (h *Handler) TestConnection(c *fiber.Ctx) error {
+	if h.tester == nil {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error": "Connection test not available",
+		})
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	result, err := h.tester.TestConnection(c.Context(), c.Params("id"), body.Message)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrChargePointNotConnected):
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Device is not connected",
+			})
+		case errors.Is(err, domain.ErrValidation):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(result)
+}
+
 // GetTransactions handles GET /api/v1/admin/transactions
+// Pass next_cursor from the previous response as ?cursor= to get the next page.
 func (h *Handler) GetTransactions(c *fiber.Ctx) error {
 	filter := ports.TransactionFilter{
 		Status:        c.Query("status"),
@@ -265,23 +381,44 @@ func (h *Handler) GetTransactions(c *fiber.Ctx) error {
 	}
 
 	limit := c.QueryInt("limit", 20)
-	offset := c.QueryInt("offset", 0)
 
-	transactions, total, err := h.service.GetTransactions(c.Context(), filter, limit, offset)
+	page, err := h.service.GetTransactions(c.Context(), filter, limit, c.Query("cursor"))
 	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid cursor",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"transactions": transactions,
-		"total":        total,
+		"transactions": page.Transactions,
+		"total":        page.Total,
 		"limit":        limit,
-		"offset":       offset,
+		"next_cursor":  page.NextCursor,
 	})
 }
 
+// GetActiveTransactions handles GET /api/v1/admin/transactions/active
+func (h *Handler) GetActiveTransactions(c *fiber.Ctx) error {
+	active, err := h.service.GetActiveTransactions(c.Context())
+	if err != nil {
+		if errors.Is(err, ErrActiveSessionsUnavailable) {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(active)
+}
+
 // GetTransactionDetails handles GET /api/v1/admin/transactions/:id
 func (h *Handler) GetTransactionDetails(c *fiber.Ctx) error {
 	txID := c.Params("id")
@@ -319,7 +456,7 @@ func (h *Handler) GetAlerts(c *fiber.Ctx) error {
 func (h *Handler) AcknowledgeAlert(c *fiber.Ctx) error {
 	alertID := c.Params("id")
 
-	if err := h.service.AcknowledgeAlert(c.Context(), alertID); err != nil {
+	if err := h.service.AcknowledgeAlert(auditContext(c), alertID); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -330,6 +467,45 @@ func (h *Handler) AcknowledgeAlert(c *fiber.Ctx) error {
 	})
 }
 
+// GetAuditLog handles GET /api/v1/admin/audit
+// Filters: actor_id, action, entity_type, entity_id, and from/to as RFC 3339
+// timestamps or YYYY-MM-DD dates (to is exclusive).
+func (h *Handler) GetAuditLog(c *fiber.Ctx) error {
+	filter := ports.AuditFilter{
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+	}
+	var err error
+	if filter.From, err = parseAuditTime(c.Query("from")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid from: " + err.Error(),
+		})
+	}
+	if filter.To, err = parseAuditTime(c.Query("to")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid to: " + err.Error(),
+		})
+	}
+	limit := c.QueryInt("limit", defaultAuditPageSize)
+	offset := c.QueryInt("offset", 0)
+
+	entries, total, err := h.service.GetAuditLog(c.Context(), filter, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"entries": entries,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
 // GenerateReport handles GET /api/v1/admin/reports/:type
 func (h *Handler) GenerateReport(c *fiber.Ctx) error {
 	reportType := c.Params("type")
@@ -358,6 +534,19 @@ func (h *Handler) GenerateReport(c *fiber.Ctx) error {
 	return c.Send(report)
 }
 
+// GetCircuitBreakers handles GET /api/v1/admin/circuit-breakers
+func (h *Handler) GetCircuitBreakers(c *fiber.Ctx) error {
+	if h.breakers == nil {
+		return c.JSON(fiber.Map{
+			"breakers": map[string]circuitbreaker.BreakerStatus{},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"breakers": h.breakers.Status(),
+	})
+}
+
 // AdminMiddleware checks if user is admin
 func AdminMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -371,6 +560,29 @@ func AdminMiddleware() fiber.Handler {
 	}
 }
 
+// auditContext carries the authenticated admin and the client IP to the
+// service, for the audit log
+func auditContext(c *fiber.Ctx) context.Context {
+	actor := ports.AuditActor{SourceIP: c.IP()}
+	actor.UserID, _ = c.Locals("user_id").(string)
+	if role, ok := c.Locals("user_role").(domain.UserRole); ok {
+		actor.Role = string(role)
+	}
+	return ports.WithAuditActor(c.Context(), actor)
+}
+
+// parseAuditTime accepts an RFC 3339 timestamp or a YYYY-MM-DD date; empty
+// is the zero time
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
 // parseDateRange parses start and end dates from query parameters
 func parseDateRange(c *fiber.Ctx) (time.Time, time.Time) {
 	now := time.Now()
@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetMeterValueRepository makes transaction details include every sampled
+// value of the session: energy, power, current, voltage, SoC and so on
+func (s *Service) SetMeterValueRepository(repo ports.MeterValueRepository) {
+	s.meterValues = repo
+}
+
+// transactionMeterValues returns a transaction's sampled values, oldest first
+func (s *Service) transactionMeterValues(ctx context.Context, txID string) ([]ports.MeterValue, error) {
+	samples, err := s.meterValues.FindByTransactionID(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find meter values: %w", err)
+	}
+
+	meterValues := make([]ports.MeterValue, 0, len(samples))
+	for _, sample := range samples {
+		meterValues = append(meterValues, ports.MeterValue{
+			Timestamp: sample.Timestamp,
+			Measurand: sample.Measurand,
+			Value:     sample.Value,
+			Unit:      sample.Unit,
+			Phase:     sample.Phase,
+			Location:  sample.Location,
+			Context:   sample.Context,
+		})
+	}
+	return meterValues, nil
+}
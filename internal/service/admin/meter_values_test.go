@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+func TestGetTransactionDetails_MeterValues(t *testing.T) {
+	// Arrange: power and SoC sampled alongside the energy register
+	ctx := context.Background()
+	txRepo := memory.NewTransactionRepository()
+	txRepo.Save(ctx, &domain.Transaction{ID: "tx-1", Status: domain.TransactionStatusStarted})
+	meterRepo := memory.NewMeterValueRepository()
+	at := time.Date(2026, 10, 16, 12, 5, 0, 0, time.UTC)
+	meterRepo.Save(ctx, []domain.MeterSample{
+		{ID: "s1", TransactionID: "tx-1", Timestamp: at, Measurand: "Energy.Active.Import.Register", Value: 5400, Unit: "Wh"},
+		{ID: "s2", TransactionID: "tx-1", Timestamp: at, Measurand: "Current.Import", Value: 10.7, Unit: "A", Phase: "L1"},
+		{ID: "s3", TransactionID: "tx-1", Timestamp: at, Measurand: "SoC", Value: 64, Unit: "Percent", Location: "EV"},
+	})
+	svc := NewService(nil, nil, txRepo, nil, nil, nil, zap.NewNop())
+	svc.SetMeterValueRepository(meterRepo)
+
+	// Act
+	details, err := svc.GetTransactionDetails(ctx, "tx-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(details.MeterValues) != 3 {
+		t.Fatalf("expected 3 meter values, got %+v", details.MeterValues)
+	}
+	current, soc := details.MeterValues[1], details.MeterValues[2]
+	if current.Measurand != "Current.Import" || current.Phase != "L1" || current.Value != 10.7 {
+		t.Errorf("expected 10.7 A on L1, got %+v", current)
+	}
+	if soc.Measurand != "SoC" || soc.Location != "EV" || soc.Value != 64 {
+		t.Errorf("expected an SoC of 64 from the EV, got %+v", soc)
+	}
+}
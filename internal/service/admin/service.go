@@ -3,13 +3,18 @@ package admin
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
@@ -22,6 +27,22 @@ type Service struct {
 	paymentRepo     ports.PaymentRepository
 	reservationRepo ports.ReservationRepository
 	alertRepo       ports.AlertRepository
+	txService       ports.TransactionService
+	walletRepo      ports.WalletRepository          // optional, see SetWalletRepository
+	v2gRepo         ports.V2GRepository             // optional, see SetV2GRepository
+	v2gComp         ports.V2GCompensationCalculator // optional, see SetV2GCompensationCalculator
+	voiceRepo       ports.VoiceCommandRepository    // optional, see SetVoiceCommandRepository
+	cardRepo        ports.CardRepository            // optional, see SetCardRepository
+	certRepo        ports.ISO15118Repository        // optional, see SetCertificateRevocation
+	iso15118        ports.ISO15118Service           // optional, see SetCertificateRevocation
+	eraser          ports.CustomerDataEraser        // optional, see SetCustomerDataEraser
+	auditRepo       ports.AuditRepository           // optional, see SetAuditRepository
+	authCache       ports.AuthorizationCacheClearer // optional, see SetAuthorizationCacheClearer
+	connEvents      ports.ConnectionEventRepository // optional, see SetConnectionEventRepository
+	meterValues     ports.MeterValueRepository      // optional, see SetMeterValueRepository
+	limitSource     ports.ChargingLimitSource       // optional, see SetChargingLimitSource
+	limitCache      *chargingLimitCache             // see SetChargingLimitSource
+	mq              queue.MessageQueue              // optional, see SetMessageQueue
 	log             *zap.Logger
 }
 
@@ -67,9 +88,8 @@ func (s *Service) GetDashboardStats(ctx context.Context) (*ports.DashboardStats,
 		}
 	}
 
-	// Get today's transactions from the database
-	today := time.Now().Truncate(24 * time.Hour)
-	todayTxs, err := s.txRepo.FindByDate(ctx, today)
+	// Get today's transactions, today being each station's local day
+	todayTxs, err := s.stationToday(ctx, time.Now())
 	if err != nil {
 		s.log.Warn("Failed to fetch today's transactions", zap.Error(err))
 	} else {
@@ -113,15 +133,9 @@ func (s *Service) GetRevenueStats(ctx context.Context, startDate, endDate time.T
 
 	var totalTxCount int
 
-	// Iterate each day in the range and aggregate revenue
-	for d := startDate.Truncate(24 * time.Hour); !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		dayTxs, err := s.txRepo.FindByDate(ctx, d)
-		if err != nil {
-			s.log.Warn("Failed to fetch transactions for date", zap.Time("date", d), zap.Error(err))
-			continue
-		}
-
-		dayKey := d.Format("2006-01-02")
+	// Iterate each station-local day in the range and aggregate revenue
+	for _, day := range s.stationDays(ctx, startDate, endDate) {
+		dayTxs := day.txs
 		var dayRevenue float64
 		for _, tx := range dayTxs {
 			dayRevenue += tx.Cost
@@ -130,7 +144,7 @@ func (s *Service) GetRevenueStats(ctx context.Context, startDate, endDate time.T
 				stats.RevenueByMethod[tx.Currency] += tx.Cost
 			}
 		}
-		stats.RevenueByDay[dayKey] = dayRevenue
+		stats.RevenueByDay[day.date] += dayRevenue
 		stats.TotalRevenue += dayRevenue
 		totalTxCount += len(dayTxs)
 	}
@@ -154,23 +168,17 @@ func (s *Service) GetUsageStats(ctx context.Context, startDate, endDate time.Tim
 	stationMap := make(map[string]*ports.StationUsage) // stationID -> aggregated usage
 	var totalDurationMin float64
 
-	// Iterate each day in the range
-	for d := startDate.Truncate(24 * time.Hour); !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		dayTxs, err := s.txRepo.FindByDate(ctx, d)
-		if err != nil {
-			s.log.Warn("Failed to fetch transactions for date", zap.Time("date", d), zap.Error(err))
-			continue
-		}
-
-		dayKey := d.Format("2006-01-02")
+	// Iterate each station-local day in the range
+	for _, day := range s.stationDays(ctx, startDate, endDate) {
+		dayTxs := day.txs
 		var dayEnergy float64
 
 		for _, tx := range dayTxs {
 			energyKWh := float64(tx.MeterStop-tx.MeterStart) / 1000.0
 			dayEnergy += energyKWh
 
-			// Track peak hour from transaction start times
-			hourCounts[tx.StartTime.Hour()]++
+			// Track peak hour from transaction start times, in station time
+			hourCounts[tx.StartTime.In(day.loc).Hour()]++
 
 			// Calculate session duration
 			if tx.EndTime != nil {
@@ -188,8 +196,8 @@ func (s *Service) GetUsageStats(ctx context.Context, startDate, endDate time.Tim
 			su.Revenue += tx.Cost
 		}
 
-		stats.SessionsByDay[dayKey] = len(dayTxs)
-		stats.EnergyByDay[dayKey] = dayEnergy
+		stats.SessionsByDay[day.date] += len(dayTxs)
+		stats.EnergyByDay[day.date] += dayEnergy
 		stats.TotalSessions += len(dayTxs)
 		stats.TotalEnergyKWh += dayEnergy
 	}
@@ -292,6 +300,7 @@ func (s *Service) UpdateUserStatus(ctx context.Context, userID string, status st
 		return fmt.Errorf("user not found")
 	}
 
+	wasBlocked := user.Status == domain.UserStatusBlocked
 	user.Status = status
 	user.UpdatedAt = time.Now()
 
@@ -304,6 +313,11 @@ func (s *Service) UpdateUserStatus(ctx context.Context, userID string, status st
 		zap.String("status", status),
 	)
 
+	// Stations may still accept the user from their Authorization Cache
+	if status == domain.UserStatusBlocked && !wasBlocked {
+		s.clearAuthorizationCaches(ctx, userID)
+	}
+
 	return nil
 }
 
@@ -392,8 +406,22 @@ func (s *Service) GetStationDetails(ctx context.Context, stationID string) (*por
 		}
 	}
 
-	// Uptime: if station was seen within last 5 min, consider it up
-	if time.Since(station.LastHeartbeat) < 5*time.Minute {
+	if s.limitSource != nil {
+		details.ChargingLimits = s.chargingLimits(ctx, stationID, time.Now())
+	}
+
+	if s.connEvents != nil {
+		uptime, err := s.stationUptime(ctx, stationID, time.Now())
+		if err == nil {
+			details.Uptime = uptime
+			return details, nil
+		}
+		s.log.Warn("Failed to compute station uptime", zap.String("station_id", stationID), zap.Error(err))
+	}
+
+	// Without a connection history: if station was seen within its
+	// heartbeat interval, consider it up
+	if station.IsOnline(time.Now()) {
 		details.Uptime = 100.0
 	} else if station.Status == domain.ChargePointStatusAvailable || station.Status == domain.ChargePointStatusOccupied {
 		details.Uptime = 95.0
@@ -416,66 +444,94 @@ func (s *Service) UpdateStationStatus(ctx context.Context, stationID string, sta
 	return nil
 }
 
-// GetTransactions returns paginated transactions
-func (s *Service) GetTransactions(ctx context.Context, filter ports.TransactionFilter, limit, offset int) ([]domain.Transaction, int, error) {
-	var transactions []domain.Transaction
+// Transaction listing page sizes
+const (
+	defaultTransactionPageSize = 20
+	maxTransactionPageSize     = 100
+)
 
-	if filter.UserID != "" {
-		txs, err := s.txRepo.FindHistoryByUserID(ctx, filter.UserID)
-		if err != nil {
-			return nil, 0, err
-		}
-		transactions = txs
-	} else if !filter.StartDate.IsZero() {
-		// Iterate each day in date range
-		end := filter.EndDate
-		if end.IsZero() {
-			end = time.Now()
-		}
-		for d := filter.StartDate.Truncate(24 * time.Hour); !d.After(end); d = d.AddDate(0, 0, 1) {
-			dayTxs, err := s.txRepo.FindByDate(ctx, d)
-			if err != nil {
-				continue
-			}
-			transactions = append(transactions, dayTxs...)
-		}
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = fmt.Errorf("%w: invalid cursor", domain.ErrValidation)
+
+// GetTransactions returns one page of transactions matching filter, newest
+// first. Filtering and paging run in the repository so only the requested
+// page is loaded. Dates are whole days: EndDate includes that entire day.
+func (s *Service) GetTransactions(ctx context.Context, filter ports.TransactionFilter, limit int, cursor string) (*ports.TransactionPage, error) {
+	if limit <= 0 {
+		limit = defaultTransactionPageSize
+	}
+	if limit > maxTransactionPageSize {
+		limit = maxTransactionPageSize
 	}
 
-	// Filter by status if specified
-	if filter.Status != "" {
-		filtered := make([]domain.Transaction, 0, len(transactions))
-		for _, tx := range transactions {
-			if string(tx.Status) == filter.Status {
-				filtered = append(filtered, tx)
-			}
+	var after *ports.TransactionCursor
+	if cursor != "" {
+		decoded, err := decodeTransactionCursor(cursor)
+		if err != nil {
+			return nil, err
 		}
-		transactions = filtered
+		after = decoded
 	}
 
-	// Filter by charge point if specified
-	if filter.ChargePointID != "" {
-		filtered := make([]domain.Transaction, 0, len(transactions))
-		for _, tx := range transactions {
-			if tx.ChargePointID == filter.ChargePointID {
-				filtered = append(filtered, tx)
-			}
-		}
-		transactions = filtered
+	filter = dayBounds(filter)
+
+	total, err := s.txRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %w", err)
 	}
 
-	total := len(transactions)
+	// Fetch one extra row to learn whether another page follows
+	txs, err := s.txRepo.FindPage(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
 
-	// Apply pagination
-	if offset >= len(transactions) {
-		return []domain.Transaction{}, total, nil
+	page := &ports.TransactionPage{Total: total}
+	if len(txs) > limit {
+		txs = txs[:limit]
+		last := txs[limit-1]
+		page.NextCursor = encodeTransactionCursor(ports.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID})
 	}
+	if txs == nil {
+		txs = []domain.Transaction{}
+	}
+	page.Transactions = txs
 
-	end := offset + limit
-	if end > len(transactions) {
-		end = len(transactions)
+	return page, nil
+}
+
+// dayBounds turns the inclusive day range of the admin filter into the
+// half-open [StartDate, EndDate) range used by the repository
+func dayBounds(filter ports.TransactionFilter) ports.TransactionFilter {
+	if !filter.StartDate.IsZero() {
+		filter.StartDate = filter.StartDate.Truncate(24 * time.Hour)
+	}
+	if !filter.EndDate.IsZero() {
+		filter.EndDate = filter.EndDate.Truncate(24 * time.Hour).AddDate(0, 0, 1)
 	}
+	return filter
+}
 
-	return transactions[offset:end], total, nil
+// encodeTransactionCursor makes an opaque cursor from a page position
+func encodeTransactionCursor(c ports.TransactionCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTransactionCursor(cursor string) (*ports.TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &ports.TransactionCursor{CreatedAt: t, ID: id}, nil
 }
 
 // GetTransactionDetails returns detailed transaction information
@@ -504,9 +560,54 @@ func (s *Service) GetTransactionDetails(ctx context.Context, txID string) (*port
 		details.Station = station
 	}
 
+	if s.meterValues != nil {
+		meterValues, err := s.transactionMeterValues(ctx, txID)
+		if err != nil {
+			s.log.Warn("Failed to get meter values", zap.String("tx_id", txID), zap.Error(err))
+		} else {
+			details.MeterValues = meterValues
+		}
+	}
+
 	return details, nil
 }
 
+// ErrActiveSessionsUnavailable is returned when no transaction service was set
+var ErrActiveSessionsUnavailable = errors.New("active session tracking not configured")
+
+// SetTransactionService enables the live sessions view
+func (s *Service) SetTransactionService(txService ports.TransactionService) {
+	s.txService = txService
+}
+
+// GetActiveTransactions returns every started transaction with its latest
+// power draw and the total load. Sessions come from the transaction
+// service's in-memory set, not from scanning the repository.
+func (s *Service) GetActiveTransactions(ctx context.Context) (*ports.ActiveTransactions, error) {
+	if s.txService == nil {
+		return nil, ErrActiveSessionsUnavailable
+	}
+
+	sessions, err := s.txService.ListActiveSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active sessions: %w", err)
+	}
+	if sessions == nil {
+		sessions = []domain.ActiveSession{}
+	}
+
+	active := &ports.ActiveTransactions{
+		Sessions: sessions,
+		Count:    len(sessions),
+	}
+	for _, session := range sessions {
+		active.TotalPowerKW += session.PowerKW
+	}
+	active.TotalPowerKW = math.Round(active.TotalPowerKW*1000) / 1000
+
+	return active, nil
+}
+
 // GetAlerts returns paginated alerts
 func (s *Service) GetAlerts(ctx context.Context, limit, offset int) ([]ports.Alert, error) {
 	if s.alertRepo == nil {
@@ -559,46 +660,48 @@ func (s *Service) GenerateReport(ctx context.Context, reportType string, startDa
 	switch reportType {
 	case "revenue":
 		w.Write([]string{"Date", "Transactions", "Revenue", "Energy_kWh"})
-		for d := startDate.Truncate(24 * time.Hour); !d.After(endDate); d = d.AddDate(0, 0, 1) {
-			dayTxs, err := s.txRepo.FindByDate(ctx, d)
-			if err != nil {
-				continue
-			}
-			var revenue, energy float64
-			for _, tx := range dayTxs {
-				revenue += tx.Cost
-				energy += float64(tx.MeterStop-tx.MeterStart) / 1000.0
+		days := make(map[string]*reportDay)
+		for _, day := range s.stationDays(ctx, startDate, endDate) {
+			row := reportRow(days, day.date)
+			for _, tx := range day.txs {
+				row.transactions++
+				row.revenue += tx.Cost
+				row.energy += float64(tx.MeterStop-tx.MeterStart) / 1000.0
 			}
+		}
+		for _, date := range sortedDates(days) {
+			row := days[date]
 			w.Write([]string{
-				d.Format("2006-01-02"),
-				strconv.Itoa(len(dayTxs)),
-				strconv.FormatFloat(revenue, 'f', 2, 64),
-				strconv.FormatFloat(energy, 'f', 2, 64),
+				date,
+				strconv.Itoa(row.transactions),
+				strconv.FormatFloat(row.revenue, 'f', 2, 64),
+				strconv.FormatFloat(row.energy, 'f', 2, 64),
 			})
 		}
 
 	case "usage":
 		w.Write([]string{"Date", "Sessions", "Energy_kWh", "Avg_Duration_min"})
-		for d := startDate.Truncate(24 * time.Hour); !d.After(endDate); d = d.AddDate(0, 0, 1) {
-			dayTxs, err := s.txRepo.FindByDate(ctx, d)
-			if err != nil {
-				continue
-			}
-			var energy, totalDur float64
-			for _, tx := range dayTxs {
-				energy += float64(tx.MeterStop-tx.MeterStart) / 1000.0
+		days := make(map[string]*reportDay)
+		for _, day := range s.stationDays(ctx, startDate, endDate) {
+			row := reportRow(days, day.date)
+			for _, tx := range day.txs {
+				row.transactions++
+				row.energy += float64(tx.MeterStop-tx.MeterStart) / 1000.0
 				if tx.EndTime != nil {
-					totalDur += tx.EndTime.Sub(tx.StartTime).Minutes()
+					row.durationMin += tx.EndTime.Sub(tx.StartTime).Minutes()
 				}
 			}
+		}
+		for _, date := range sortedDates(days) {
+			row := days[date]
 			avgDur := 0.0
-			if len(dayTxs) > 0 {
-				avgDur = totalDur / float64(len(dayTxs))
+			if row.transactions > 0 {
+				avgDur = row.durationMin / float64(row.transactions)
 			}
 			w.Write([]string{
-				d.Format("2006-01-02"),
-				strconv.Itoa(len(dayTxs)),
-				strconv.FormatFloat(energy, 'f', 2, 64),
+				date,
+				strconv.Itoa(row.transactions),
+				strconv.FormatFloat(row.energy, 'f', 2, 64),
 				strconv.FormatFloat(avgDur, 'f', 1, 64),
 			})
 		}
@@ -617,6 +720,11 @@ func (s *Service) GenerateReport(ctx context.Context, reportType string, startDa
 			w.Write([]string{st.ID, st.Vendor, st.Model, string(st.Status), addr})
 		}
 
+	case "v2g":
+		if err := s.writeV2GReport(ctx, w, startDate, endDate); err != nil {
+			return nil, err
+		}
+
 	default:
 		return nil, fmt.Errorf("unknown report type: %s", reportType)
 	}
@@ -0,0 +1,254 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// memoryTransactions serves FindByDate, FindPage and Count from txs
+func memoryTransactions(txs []domain.Transaction) *mocks.MockTransactionRepository {
+	matches := func(tx domain.Transaction, filter ports.TransactionFilter) bool {
+		return (filter.Status == "" || string(tx.Status) == filter.Status) &&
+			(filter.UserID == "" || tx.UserID == filter.UserID) &&
+			(filter.ChargePointID == "" || tx.ChargePointID == filter.ChargePointID) &&
+			(filter.StartDate.IsZero() || !tx.CreatedAt.Before(filter.StartDate)) &&
+			(filter.EndDate.IsZero() || tx.CreatedAt.Before(filter.EndDate))
+	}
+
+	return &mocks.MockTransactionRepository{
+		FindByDateFunc: func(ctx context.Context, date time.Time) ([]domain.Transaction, error) {
+			start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+			var out []domain.Transaction
+			for _, tx := range txs {
+				if !tx.CreatedAt.Before(start) && tx.CreatedAt.Before(start.Add(24*time.Hour)) {
+					out = append(out, tx)
+				}
+			}
+			return out, nil
+		},
+		FindPageFunc: func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+			var out []domain.Transaction
+			for _, tx := range txs {
+				if matches(tx, filter) {
+					out = append(out, tx)
+				}
+			}
+			sort.Slice(out, func(i, j int) bool {
+				if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+					return out[i].CreatedAt.After(out[j].CreatedAt)
+				}
+				return out[i].ID > out[j].ID
+			})
+			if after != nil {
+				for len(out) > 0 && !(out[0].CreatedAt.Before(after.CreatedAt) ||
+					(out[0].CreatedAt.Equal(after.CreatedAt) && out[0].ID < after.ID)) {
+					out = out[1:]
+				}
+			}
+			if len(out) > limit {
+				out = out[:limit]
+			}
+			return out, nil
+		},
+		CountFunc: func(ctx context.Context, filter ports.TransactionFilter) (int, error) {
+			count := 0
+			for _, tx := range txs {
+				if matches(tx, filter) {
+					count++
+				}
+			}
+			return count, nil
+		},
+	}
+}
+
+// legacyTransactions is the previous in-memory listing: load every day of
+// the range, then filter by status and charge point
+func legacyTransactions(ctx context.Context, repo ports.TransactionRepository, filter ports.TransactionFilter) []domain.Transaction {
+	var txs []domain.Transaction
+	for d := filter.StartDate.Truncate(24 * time.Hour); !d.After(filter.EndDate); d = d.AddDate(0, 0, 1) {
+		dayTxs, _ := repo.FindByDate(ctx, d)
+		for _, tx := range dayTxs {
+			if (filter.Status == "" || string(tx.Status) == filter.Status) &&
+				(filter.ChargePointID == "" || tx.ChargePointID == filter.ChargePointID) {
+				txs = append(txs, tx)
+			}
+		}
+	}
+	return txs
+}
+
+func sampleTransactions() []domain.Transaction {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	statuses := []domain.TransactionStatus{domain.TransactionStatusCompleted, domain.TransactionStatusStopped, domain.TransactionStatusStarted}
+	var txs []domain.Transaction
+	for i := 0; i < 90; i++ {
+		txs = append(txs, domain.Transaction{
+			ID:            fmt.Sprintf("tx-%03d", i),
+			ChargePointID: fmt.Sprintf("CP%d", i%2),
+			Status:        statuses[i%3],
+			// Several transactions per day, some sharing a timestamp
+			CreatedAt: base.Add(time.Duration(i/2) * 11 * time.Hour),
+		})
+	}
+	return txs
+}
+
+func TestGetTransactions_PagesMatchLegacyListing(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := memoryTransactions(sampleTransactions())
+	svc := NewService(nil, nil, repo, nil, nil, nil, zap.NewNop())
+	filter := ports.TransactionFilter{
+		Status:        string(domain.TransactionStatusCompleted),
+		ChargePointID: "CP0",
+		StartDate:     time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+	}
+	expected := legacyTransactions(ctx, repo, filter)
+
+	// Act - walk every page
+	var paged []domain.Transaction
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > len(expected) {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := svc.GetTransactions(ctx, filter, 3, cursor)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if page.Total != len(expected) {
+			t.Errorf("expected total %d, got %d", len(expected), page.Total)
+		}
+		paged = append(paged, page.Transactions...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	// Assert
+	if len(expected) == 0 {
+		t.Fatal("expected the sample filter to match transactions")
+	}
+	if len(paged) != len(expected) {
+		t.Fatalf("expected %d transactions, got %d", len(expected), len(paged))
+	}
+	seen := make(map[string]bool)
+	for i, tx := range paged {
+		if seen[tx.ID] {
+			t.Errorf("transaction %s returned twice", tx.ID)
+		}
+		seen[tx.ID] = true
+		if i > 0 && paged[i-1].CreatedAt.Before(tx.CreatedAt) {
+			t.Errorf("expected newest first, %s came before %s", paged[i-1].ID, tx.ID)
+		}
+	}
+	for _, tx := range expected {
+		if !seen[tx.ID] {
+			t.Errorf("expected %s in paged results", tx.ID)
+		}
+	}
+}
+
+func TestGetTransactions_InvalidCursor(t *testing.T) {
+	svc := NewService(nil, nil, memoryTransactions(nil), nil, nil, nil, zap.NewNop())
+
+	_, err := svc.GetTransactions(context.Background(), ports.TransactionFilter{}, 10, "not-a-cursor")
+
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestGetStationDetails_UsesNegotiatedHeartbeatInterval(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name           string
+		interval       int
+		expectedUptime float64
+	}{
+		{"default interval", 0, 0},
+		{"cellular interval", 1800, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange: last heartbeat 20 minutes ago
+			station := &domain.ChargePoint{
+				ID:                "CP001",
+				Status:            domain.ChargePointStatusUnavailable,
+				LastHeartbeat:     now.Add(-20 * time.Minute),
+				HeartbeatInterval: tt.interval,
+			}
+			devices := &mocks.MockChargePointRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+					return station, nil
+				},
+			}
+			svc := NewService(nil, devices, memoryTransactions(nil), nil, nil, nil, zap.NewNop())
+
+			// Act
+			details, err := svc.GetStationDetails(context.Background(), "CP001")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if details.Uptime != tt.expectedUptime {
+				t.Errorf("expected uptime %.0f, got %.0f", tt.expectedUptime, details.Uptime)
+			}
+		})
+	}
+}
+
+func TestGetActiveTransactions_SumsPower(t *testing.T) {
+	// Arrange
+	sessions := []domain.ActiveSession{
+		{Transaction: domain.Transaction{ID: "tx-1", ChargePointID: "CP1"}, PowerKW: 7.4},
+		{Transaction: domain.Transaction{ID: "tx-2", ChargePointID: "CP2"}, PowerKW: 22.1},
+		{Transaction: domain.Transaction{ID: "tx-3", ChargePointID: "CP3"}}, // No reading yet
+		{Transaction: domain.Transaction{ID: "tx-4", ChargePointID: "CP3"}, PowerKW: 150.05},
+	}
+	svc := NewService(nil, nil, nil, nil, nil, nil, zap.NewNop())
+	svc.SetTransactionService(&mocks.MockTransactionService{
+		ListActiveSessionsFunc: func(ctx context.Context) ([]domain.ActiveSession, error) {
+			return sessions, nil
+		},
+	})
+
+	// Act
+	active, err := svc.GetActiveTransactions(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if active.Count != 4 {
+		t.Errorf("expected 4 sessions, got %d", active.Count)
+	}
+	if active.TotalPowerKW != 179.55 {
+		t.Errorf("expected total 179.55 kW, got %v", active.TotalPowerKW)
+	}
+}
+
+func TestGetActiveTransactions_NotConfigured(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	_, err := svc.GetActiveTransactions(context.Background())
+
+	if !errors.Is(err, ErrActiveSessionsUnavailable) {
+		t.Errorf("expected ErrActiveSessionsUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// stationDay holds the transactions of the stations in one time zone on
+// one of their local days
+type stationDay struct {
+	date string // 2006-01-02
+	loc  *time.Location
+	txs  []domain.Transaction
+}
+
+// stationZones groups the stations by time zone. Stations without one,
+// and transactions of unknown stations, use the server's.
+type stationZones struct {
+	byStation map[string]*time.Location
+	zones     []*time.Location // Sorted by name, the server's included
+}
+
+// stationTimeZones returns the time zone of every station
+func (s *Service) stationTimeZones(ctx context.Context) stationZones {
+	zones := stationZones{byStation: make(map[string]*time.Location)}
+	if s.deviceRepo != nil {
+		stations, err := s.deviceRepo.FindAll(ctx, nil)
+		if err != nil {
+			s.log.Warn("Failed to fetch station time zones", zap.Error(err))
+		}
+		for i := range stations {
+			zones.byStation[stations[i].ID] = stations[i].TimeZone()
+		}
+	}
+
+	byName := map[string]*time.Location{time.Local.String(): time.Local}
+	for _, loc := range zones.byStation {
+		byName[loc.String()] = loc
+	}
+	for _, loc := range byName {
+		zones.zones = append(zones.zones, loc)
+	}
+	sort.Slice(zones.zones, func(i, j int) bool {
+		return zones.zones[i].String() < zones.zones[j].String()
+	})
+	return zones
+}
+
+func (z stationZones) of(chargePointID string) *time.Location {
+	if loc, ok := z.byStation[chargePointID]; ok {
+		return loc
+	}
+	return time.Local
+}
+
+// loadStationDay returns the transactions of the stations in d's location on
+// d's date
+func (s *Service) loadStationDay(ctx context.Context, zones stationZones, d time.Time) (stationDay, error) {
+	dayTxs, err := s.txRepo.FindByDate(ctx, d)
+	if err != nil {
+		return stationDay{}, err
+	}
+
+	day := stationDay{date: d.Format("2006-01-02"), loc: d.Location()}
+	for _, tx := range dayTxs {
+		if zones.of(tx.ChargePointID).String() == d.Location().String() {
+			day.txs = append(day.txs, tx)
+		}
+	}
+	return day, nil
+}
+
+// stationDays returns the transactions of every calendar day from startDate
+// to endDate, each day taken in the time zone of the station a transaction
+// ran on, so a session just after the station's midnight counts on its new
+// day whatever the server's clock says. Days come grouped by time zone.
+func (s *Service) stationDays(ctx context.Context, startDate, endDate time.Time) []stationDay {
+	zones := s.stationTimeZones(ctx)
+	startYear, startMonth, startDay := startDate.Date()
+	endYear, endMonth, endDay := endDate.Date()
+
+	var days []stationDay
+	for _, loc := range zones.zones {
+		last := time.Date(endYear, endMonth, endDay, 0, 0, 0, 0, loc)
+		for d := time.Date(startYear, startMonth, startDay, 0, 0, 0, 0, loc); !d.After(last); d = d.AddDate(0, 0, 1) {
+			day, err := s.loadStationDay(ctx, zones, d)
+			if err != nil {
+				s.log.Warn("Failed to fetch transactions for date", zap.Time("date", d), zap.Error(err))
+				continue
+			}
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// stationToday returns the transactions of every station's current local day
+func (s *Service) stationToday(ctx context.Context, now time.Time) ([]domain.Transaction, error) {
+	zones := s.stationTimeZones(ctx)
+
+	var txs []domain.Transaction
+	for _, loc := range zones.zones {
+		day, err := s.loadStationDay(ctx, zones, now.In(loc))
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, day.txs...)
+	}
+	return txs, nil
+}
+
+// reportDay totals the transactions of one date of a CSV report across
+// time zones
+type reportDay struct {
+	transactions int
+	revenue      float64
+	energy       float64
+	durationMin  float64
+}
+
+// reportRow returns the totals of date, adding them to days if missing
+func reportRow(days map[string]*reportDay, date string) *reportDay {
+	row, ok := days[date]
+	if !ok {
+		row = &reportDay{}
+		days[date] = row
+	}
+	return row
+}
+
+// sortedDates returns the dates of days in order
+func sortedDates(days map[string]*reportDay) []string {
+	dates := make([]string, 0, len(days))
+	for date := range days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
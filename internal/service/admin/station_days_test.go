@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// timeZoneStations serves CP-MAO in Manaus (UTC-4) and CP-UTC on UTC
+func timeZoneStations() *mocks.MockChargePointRepository {
+	return &mocks.MockChargePointRepository{
+		FindAllFunc: func(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error) {
+			return []domain.ChargePoint{
+				{ID: "CP-MAO", Location: &domain.Location{Timezone: "America/Manaus"}},
+				{ID: "CP-UTC", Location: &domain.Location{Timezone: "UTC"}},
+			}, nil
+		},
+	}
+}
+
+// sessionAt returns a 1 kWh session for 10.00 started at the given UTC time
+func sessionAt(id, chargePointID string, at time.Time) domain.Transaction {
+	end := at.Add(30 * time.Minute)
+	return domain.Transaction{
+		ID: id, ChargePointID: chargePointID, Status: domain.TransactionStatusCompleted,
+		StartTime: at, EndTime: &end, CreatedAt: at,
+		MeterStart: 0, MeterStop: 1000, Cost: 10,
+	}
+}
+
+// aroundMidnight has sessions either side of midnight in Manaus, which is
+// 04:00 UTC
+func aroundMidnight() []domain.Transaction {
+	return []domain.Transaction{
+		sessionAt("tx-1", "CP-MAO", time.Date(2026, 3, 2, 2, 30, 0, 0, time.UTC)), // 22:30 on 1 March in Manaus
+		sessionAt("tx-2", "CP-MAO", time.Date(2026, 3, 2, 2, 45, 0, 0, time.UTC)), // 22:45 on 1 March in Manaus
+		sessionAt("tx-3", "CP-MAO", time.Date(2026, 3, 2, 4, 15, 0, 0, time.UTC)), // 00:15 on 2 March in Manaus
+		sessionAt("tx-4", "CP-UTC", time.Date(2026, 3, 2, 2, 30, 0, 0, time.UTC)),
+	}
+}
+
+func TestGetUsageStats_BucketsByStationLocalDay(t *testing.T) {
+	// Arrange
+	svc := NewService(nil, timeZoneStations(), memoryTransactions(aroundMidnight()), nil, nil, nil, zap.NewNop())
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	stats, err := svc.GetUsageStats(context.Background(), start, end)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := map[string]int{"2026-03-01": 2, "2026-03-02": 2}
+	for day, sessions := range expected {
+		if stats.SessionsByDay[day] != sessions {
+			t.Errorf("expected %d sessions on %s, got %d", sessions, day, stats.SessionsByDay[day])
+		}
+	}
+	if stats.TotalSessions != 4 {
+		t.Errorf("expected 4 sessions, got %d", stats.TotalSessions)
+	}
+	if stats.PeakHour != 22 {
+		t.Errorf("expected peak hour 22 in station time, got %d", stats.PeakHour)
+	}
+}
+
+func TestGetRevenueStats_BucketsByStationLocalDay(t *testing.T) {
+	// Arrange
+	svc := NewService(nil, timeZoneStations(), memoryTransactions(aroundMidnight()), nil, nil, nil, zap.NewNop())
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	// Act: only 2 March
+	stats, err := svc.GetRevenueStats(context.Background(), day, day)
+
+	// Assert: the Manaus sessions before its midnight belong to 1 March
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats.RevenueByDay["2026-03-02"] != 20 || stats.TotalRevenue != 20 {
+		t.Errorf("expected 20.00 on 2026-03-02, got %v (total %.2f)", stats.RevenueByDay, stats.TotalRevenue)
+	}
+}
+
+func TestGenerateReport_RevenueRowsPerStationLocalDay(t *testing.T) {
+	// Arrange
+	svc := NewService(nil, timeZoneStations(), memoryTransactions(aroundMidnight()), nil, nil, nil, zap.NewNop())
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	report, err := svc.GenerateReport(context.Background(), "revenue", start, end)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := "Date,Transactions,Revenue,Energy_kWh\n2026-03-01,2,20.00,2.00\n2026-03-02,2,20.00,2.00\n"
+	if string(report) != expected {
+		t.Errorf("expected report\n%s\ngot\n%s", expected, strings.TrimSpace(string(report)))
+	}
+}
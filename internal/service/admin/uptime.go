@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetConnectionEventRepository makes station uptime come from the
+// connection history instead of the last heartbeat
+func (s *Service) SetConnectionEventRepository(repo ports.ConnectionEventRepository) {
+	s.connEvents = repo
+}
+
+// stationUptime returns the share of the last domain.DefaultUptimeWindow a
+// station was connected
+func (s *Service) stationUptime(ctx context.Context, stationID string, now time.Time) (float64, error) {
+	from := now.Add(-domain.DefaultUptimeWindow)
+	last, err := s.connEvents.FindLastBefore(ctx, stationID, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find connection events: %w", err)
+	}
+	events, err := s.connEvents.FindByChargePointID(ctx, stationID, from, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find connection events: %w", err)
+	}
+	return domain.UptimePercent(last, events, from, now), nil
+}
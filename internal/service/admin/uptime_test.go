@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+func TestGetStationDetails_UptimeFromConnectionHistory(t *testing.T) {
+	now := time.Now()
+	window := domain.DefaultUptimeWindow
+	start := now.Add(-window)
+	connect := func(at time.Time) domain.ConnectionEvent {
+		return domain.ConnectionEvent{Type: domain.ConnectionEventConnected, Timestamp: at}
+	}
+	disconnect := func(at time.Time, reason string) domain.ConnectionEvent {
+		return domain.ConnectionEvent{Type: domain.ConnectionEventDisconnected, Reason: reason, Timestamp: at}
+	}
+
+	tests := []struct {
+		name           string
+		events         []domain.ConnectionEvent
+		expectedUptime float64
+	}{
+		{"no history", nil, 0},
+		{"connected before the window", []domain.ConnectionEvent{connect(start.Add(-time.Hour))}, 100},
+		{"disconnected before the window", []domain.ConnectionEvent{
+			connect(start.Add(-2 * time.Hour)),
+			disconnect(start.Add(-time.Hour), domain.DisconnectReasonError),
+		}, 0},
+		{"down for a quarter of the window", []domain.ConnectionEvent{
+			connect(start.Add(-time.Hour)),
+			disconnect(start.Add(window/4), domain.DisconnectReasonPingTimeout),
+			connect(start.Add(window / 2)),
+		}, 75},
+		{"flapping", []domain.ConnectionEvent{
+			connect(start.Add(-time.Hour)),
+			disconnect(start.Add(window/10), domain.DisconnectReasonClosed),
+			connect(start.Add(window * 2 / 10)),
+			disconnect(start.Add(window*4/10), domain.DisconnectReasonPingTimeout),
+			connect(start.Add(window * 5 / 10)),
+			disconnect(start.Add(window*9/10), domain.DisconnectReasonError),
+		}, 70},
+		{"installed during the window", []domain.ConnectionEvent{
+			connect(start.Add(window / 2)),
+			disconnect(start.Add(window*3/4), domain.DisconnectReasonError),
+		}, 50},
+		{"reconnect replacing a connection", []domain.ConnectionEvent{
+			connect(start.Add(-time.Hour)),
+			disconnect(start.Add(window/2), domain.DisconnectReasonReplaced),
+			connect(start.Add(window / 2)),
+		}, 100},
+		{"lost disconnect", []domain.ConnectionEvent{
+			connect(start.Add(-time.Hour)),
+			connect(start.Add(window / 2)),
+		}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			station := &domain.ChargePoint{ID: "CP001", Status: domain.ChargePointStatusAvailable, LastHeartbeat: now}
+			devices := &mocks.MockChargePointRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+					return station, nil
+				},
+			}
+			history := memory.NewConnectionEventRepository()
+			for i := range tt.events {
+				tt.events[i].ChargePointID = "CP001"
+				if err := history.Save(context.Background(), &tt.events[i]); err != nil {
+					t.Fatalf("failed to save event: %v", err)
+				}
+			}
+			svc := NewService(nil, devices, memoryTransactions(nil), nil, nil, nil, zap.NewNop())
+			svc.SetConnectionEventRepository(history)
+
+			// Act
+			details, err := svc.GetStationDetails(context.Background(), "CP001")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(details.Uptime-tt.expectedUptime) > 0.01 {
+				t.Errorf("expected uptime %.2f, got %.2f", tt.expectedUptime, details.Uptime)
+			}
+		})
+	}
+}
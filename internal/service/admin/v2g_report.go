@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetV2GCompensationCalculator enables the v2g report, which prices each
+// discharge session the way the user is compensated for it
+func (s *Service) SetV2GCompensationCalculator(calc ports.V2GCompensationCalculator) {
+	s.v2gComp = calc
+}
+
+// writeV2GReport writes one row per discharge session started between
+// startDate and endDate, at any station, oldest first
+func (s *Service) writeV2GReport(ctx context.Context, w *csv.Writer, startDate, endDate time.Time) error {
+	if s.v2gRepo == nil || s.v2gComp == nil {
+		return errors.New("v2g report not configured")
+	}
+
+	stations, err := s.deviceRepo.FindAll(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get stations: %w", err)
+	}
+
+	var sessions []domain.V2GSession
+	for _, st := range stations {
+		stationSessions, err := s.v2gRepo.GetSessionsByChargePoint(ctx, st.ID, exportV2GLimit)
+		if err != nil {
+			return fmt.Errorf("failed to get V2G sessions of %s: %w", st.ID, err)
+		}
+		for _, session := range stationSessions {
+			if session.Direction != domain.V2GDirectionDischarging ||
+				session.StartTime.Before(startDate) || session.StartTime.After(endDate) {
+				continue
+			}
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+
+	w.Write([]string{"Date", "SessionID", "ChargePointID", "UserID", "Energy_Discharged_kWh", "Avg_Grid_Price", "Gross_Compensation", "Net_Compensation", "Currency"})
+	for i := range sessions {
+		session := &sessions[i]
+		comp, err := s.v2gComp.CalculateCompensation(ctx, session)
+		if err != nil {
+			return fmt.Errorf("failed to calculate compensation of %s: %w", session.ID, err)
+		}
+		w.Write([]string{
+			session.StartTime.Format("2006-01-02"),
+			session.ID,
+			session.ChargePointID,
+			session.UserID,
+			strconv.FormatFloat(comp.EnergyDischargedKWh, 'f', 3, 64),
+			strconv.FormatFloat(comp.AverageGridPrice, 'f', 4, 64),
+			strconv.FormatFloat(comp.GrossAmount, 'f', 2, 64),
+			strconv.FormatFloat(comp.NetAmount, 'f', 2, 64),
+			comp.Currency,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/internal/service/v2g"
+)
+
+type reportV2G struct {
+	ports.V2GRepository
+	sessions []domain.V2GSession
+}
+
+func (r *reportV2G) GetSessionsByChargePoint(ctx context.Context, chargePointID string, limit int) ([]domain.V2GSession, error) {
+	var out []domain.V2GSession
+	for _, session := range r.sessions {
+		if session.ChargePointID == chargePointID {
+			out = append(out, session)
+		}
+	}
+	return out, nil
+}
+
+func v2gReportSessions(day time.Time) []domain.V2GSession {
+	return []domain.V2GSession{
+		{ID: "v2g-2", ChargePointID: "CP-2", UserID: "user-2", Direction: domain.V2GDirectionDischarging,
+			EnergyTransferred: -4.5, GridPriceAtStart: 1.10, CurrentGridPrice: 1.30, StartTime: day.Add(14 * time.Hour)},
+		{ID: "v2g-1", ChargePointID: "CP-1", UserID: "user-1", Direction: domain.V2GDirectionDischarging,
+			EnergyTransferred: -12.25, GridPriceAtStart: 0.95, CurrentGridPrice: 1.05, StartTime: day.Add(9 * time.Hour)},
+		{ID: "v2g-charging", ChargePointID: "CP-1", UserID: "user-1", Direction: domain.V2GDirectionCharging,
+			EnergyTransferred: 8, StartTime: day.Add(10 * time.Hour)},
+		{ID: "v2g-old", ChargePointID: "CP-1", UserID: "user-1", Direction: domain.V2GDirectionDischarging,
+			EnergyTransferred: -3, GridPriceAtStart: 1, CurrentGridPrice: 1, StartTime: day.AddDate(0, 0, -7)},
+	}
+}
+
+func TestGenerateReport_V2GCompensationMatchesCalculateCompensation(t *testing.T) {
+	// Arrange
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	sessions := v2gReportSessions(day)
+	stations := &mocks.MockChargePointRepository{
+		FindAllFunc: func(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error) {
+			return []domain.ChargePoint{{ID: "CP-1"}, {ID: "CP-2"}}, nil
+		},
+	}
+	v2gService := v2g.NewService(nil, nil, nil, nil, nil, nil, zap.NewNop(), nil)
+	svc := NewService(nil, stations, nil, nil, nil, nil, zap.NewNop())
+	svc.SetV2GRepository(&reportV2G{sessions: sessions})
+	svc.SetV2GCompensationCalculator(v2gService)
+
+	// Act
+	report, err := svc.GenerateReport(context.Background(), "v2g", day, day.Add(24*time.Hour-time.Second))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(report)).ReadAll()
+	if err != nil {
+		t.Fatalf("expected valid CSV, got %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected a header and the 2 discharge sessions of the day, got %d rows", len(rows))
+	}
+	for i, want := range []domain.V2GSession{sessions[1], sessions[0]} {
+		row := rows[i+1]
+		if row[1] != want.ID || row[2] != want.ChargePointID || row[3] != want.UserID {
+			t.Fatalf("expected session %s first-to-last by start, got %v", want.ID, row)
+		}
+		comp, _ := v2gService.CalculateCompensation(context.Background(), &want)
+		if row[6] != strconv.FormatFloat(comp.GrossAmount, 'f', 2, 64) {
+			t.Errorf("expected gross %.2f for %s, got %s", comp.GrossAmount, want.ID, row[6])
+		}
+		if row[7] != strconv.FormatFloat(comp.NetAmount, 'f', 2, 64) {
+			t.Errorf("expected net %.2f for %s, got %s", comp.NetAmount, want.ID, row[7])
+		}
+	}
+}
+
+func TestGenerateReport_V2GRequiresCompensationCalculator(t *testing.T) {
+	// Arrange
+	svc := NewService(nil, &mocks.MockChargePointRepository{}, nil, nil, nil, nil, zap.NewNop())
+	svc.SetV2GRepository(&reportV2G{})
+
+	// Act
+	_, err := svc.GenerateReport(context.Background(), "v2g", time.Now().AddDate(0, 0, -1), time.Now())
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error without a compensation calculator")
+	}
+}
@@ -34,16 +34,16 @@ func (s *Service) Login(ctx context.Context, cpf, password string) (string, stri
 	user, err := s.userRepo.FindByDocument(ctx, cpf)
 	if err != nil {
 		s.log.Error("Login: error finding user by CPF", zap.String("cpf", cpf), zap.Error(err))
-		return "", "", errors.New("invalid credentials")
+		return "", "", domain.ErrInvalidCredentials
 	}
 	if user == nil {
 		s.log.Warn("Login: user not found by CPF", zap.String("cpf", cpf))
-		return "", "", errors.New("invalid credentials")
+		return "", "", domain.ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		s.log.Warn("Login: password mismatch", zap.String("cpf", cpf))
-		return "", "", errors.New("invalid credentials")
+		return "", "", domain.ErrInvalidCredentials
 	}
 
 	return s.generateTokens(user)
@@ -56,7 +56,7 @@ func (s *Service) Register(ctx context.Context, user *domain.User) error {
 		return err
 	}
 	if existing != nil {
-		return errors.New("email already registered")
+		return domain.ErrEmailAlreadyRegistered
 	}
 
 	// Check if CPF already exists
@@ -66,7 +66,7 @@ func (s *Service) Register(ctx context.Context, user *domain.User) error {
 			return err
 		}
 		if existingCPF != nil {
-			return errors.New("cpf already registered")
+			return domain.ErrDocumentAlreadyRegistered
 		}
 	}
 
@@ -114,6 +114,9 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (string
 	if err != nil || user == nil {
 		return "", errors.New("user not found")
 	}
+	if user.IsAnonymized() {
+		return "", domain.ErrInvalidToken
+	}
 
 	// Generate new access token
 	accessTokenStr, err := s.generateAccessToken(user)
@@ -140,7 +143,15 @@ func (s *Service) ValidateToken(ctx context.Context, tokenStr string) (*domain.U
 	}
 
 	// Could cache user lookup here
-	return s.userRepo.FindByID(ctx, userID)
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Tokens issued before an account was anonymized are no longer honoured
+	if user != nil && user.IsAnonymized() {
+		return nil, domain.ErrInvalidToken
+	}
+	return user, nil
 }
 
 func (s *Service) generateTokens(user *domain.User) (string, string, error) {
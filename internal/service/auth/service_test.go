@@ -274,6 +274,36 @@ func TestValidateToken_ExpiredToken(t *testing.T) {
 	}
 }
 
+func TestTokens_RejectedForAnonymizedUser(t *testing.T) {
+	ctx := context.Background()
+	jwtSecret := "test-secret-key"
+
+	mockRepo := &mocks.MockUserRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.User, error) {
+			return &domain.User{ID: id, Status: domain.UserStatusAnonymized}, nil
+		},
+	}
+	service := NewService(mockRepo, mocks.NewMockCache(), jwtSecret, newTestLogger())
+
+	access, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "user-123",
+		"exp":  time.Now().Add(15 * time.Minute).Unix(),
+		"type": "access",
+	}).SignedString([]byte(jwtSecret))
+	refresh, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "user-123",
+		"exp":  time.Now().Add(7 * 24 * time.Hour).Unix(),
+		"type": "refresh",
+	}).SignedString([]byte(jwtSecret))
+
+	if _, err := service.ValidateToken(ctx, access); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken for the access token, got %v", err)
+	}
+	if _, err := service.RefreshToken(ctx, refresh); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken for the refresh token, got %v", err)
+	}
+}
+
 func TestRefreshToken_Success(t *testing.T) {
 	ctx := context.Background()
 	jwtSecret := "test-secret-key"
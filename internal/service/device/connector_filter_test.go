@@ -0,0 +1,137 @@
+package device
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// filterStations are three stations around -23.55, -46.63
+func filterStations() []domain.ChargePoint {
+	at := func(lat float64) *domain.Location {
+		return &domain.Location{Latitude: lat, Longitude: -46.63}
+	}
+	return []domain.ChargePoint{
+		{ID: "ccs2-fast", Location: at(-23.551), Connectors: []domain.Connector{
+			{ConnectorID: 1, Type: "CCS2", MaxPowerKW: 180, Status: domain.ChargePointStatusAvailable},
+			{ConnectorID: 2, Type: "CHAdeMO", MaxPowerKW: 50, Status: domain.ChargePointStatusAvailable},
+		}},
+		{ID: "ccs2-busy", Location: at(-23.552), Connectors: []domain.Connector{
+			{ConnectorID: 1, Type: "CCS2", MaxPowerKW: 350, Status: domain.ChargePointStatusCharging},
+			{ConnectorID: 2, Type: "Type2", MaxPowerKW: 22, Status: domain.ChargePointStatusAvailable},
+		}},
+		{ID: "ac", Location: at(-23.553), Connectors: []domain.Connector{
+			{ConnectorID: 1, Type: "Type2", MaxPowerKW: 22, Status: domain.ChargePointStatusAvailable},
+		}},
+	}
+}
+
+func chargePointIDs(devices []domain.ChargePoint) []string {
+	ids := make([]string, len(devices))
+	for i, d := range devices {
+		ids[i] = d.ID
+	}
+	return ids
+}
+
+// connectorFilterTests list the matching stations closest first
+var connectorFilterTests = []struct {
+	name   string
+	filter domain.ConnectorFilter
+	want   []string
+}{
+	{"no filter", domain.ConnectorFilter{}, []string{"ccs2-fast", "ccs2-busy", "ac"}},
+	{"connector standard", domain.ConnectorFilter{Standard: "ccs2"}, []string{"ccs2-fast", "ccs2-busy"}},
+	{"minimum power", domain.ConnectorFilter{MinPowerKW: 150}, []string{"ccs2-fast", "ccs2-busy"}},
+	{"minimum power of the standard", domain.ConnectorFilter{Standard: "Type2", MinPowerKW: 50}, []string{}},
+	{"available now", domain.ConnectorFilter{Standard: "CCS2", MinPowerKW: 150, AvailableNow: true}, []string{"ccs2-fast"}},
+}
+
+func TestListDevices_FiltersByConnector(t *testing.T) {
+	for _, tt := range connectorFilterTests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			repo := memory.NewChargePointRepository()
+			for _, cp := range filterStations() {
+				if err := repo.Save(ctx, &cp); err != nil {
+					t.Fatalf("failed to seed %s: %v", cp.ID, err)
+				}
+			}
+			service := NewService(repo, mocks.NewMockCache(), mocks.NewMockMessageQueue(), newTestLogger())
+
+			// Act
+			devices, err := service.ListDevices(ctx, map[string]interface{}{ports.ConnectorFilterKey: tt.filter})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			got := chargePointIDs(devices)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(got)
+			sort.Strings(want)
+			if len(got) != len(want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("expected %v, got %v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetNearby_FiltersByConnector(t *testing.T) {
+	for _, tt := range connectorFilterTests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			repo := memory.NewChargePointRepository()
+			for _, cp := range filterStations() {
+				if err := repo.Save(ctx, &cp); err != nil {
+					t.Fatalf("failed to seed %s: %v", cp.ID, err)
+				}
+			}
+			service := NewService(repo, mocks.NewMockCache(), mocks.NewMockMessageQueue(), newTestLogger()).(*Service)
+
+			// Act: the cold query is pushed into the repository, the warm
+			// one is served from the geo index
+			cold, err := service.GetNearby(ctx, -23.55, -46.63, 5, tt.filter)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			deadline := time.Now().Add(2 * time.Second)
+			for !service.geo.Loaded() {
+				if time.Now().After(deadline) {
+					t.Fatal("geo index was not loaded")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			warm, err := service.GetNearby(ctx, -23.55, -46.63, 5, tt.filter)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			for name, result := range map[string][]domain.ChargePoint{"cold": cold, "warm": warm} {
+				got := chargePointIDs(result)
+				if len(got) != len(tt.want) {
+					t.Fatalf("%s: expected %v, got %v", name, tt.want, got)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("%s: expected %v, got %v", name, tt.want, got)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// AlertTypeConnectorFault marks alerts raised from Faulted status notifications
+const AlertTypeConnectorFault = "connector_fault"
+
+// faultSeverities maps OCPP ChargePointErrorCode values onto the alert
+// severities used by the admin dashboard. Unknown codes are "error".
+var faultSeverities = map[string]string{
+	// Electrical hazards; the connector must not be used
+	"GroundFailure":      "critical",
+	"OverCurrentFailure": "critical",
+	"OverVoltage":        "critical",
+	"HighTemperature":    "critical",
+	"PowerSwitchFailure": "critical",
+
+	"ConnectorLockFailure": "error",
+	"EVCommunicationError": "error",
+	"InternalError":        "error",
+	"PowerMeterFailure":    "error",
+	"ResetFailure":         "error",
+	"UnderVoltage":         "error",
+
+	// Charging can usually continue
+	"ReaderFailure":     "warning",
+	"WeakSignal":        "warning",
+	"LocalListConflict": "warning",
+	"OtherError":        "warning",
+}
+
+// FaultSeverity returns the alert severity for an OCPP error code
+func FaultSeverity(errorCode string) string {
+	if severity, ok := faultSeverities[errorCode]; ok {
+		return severity
+	}
+	return "error"
+}
+
+// FaultService turns connector faults into alerts and resolves them when
+// the connector recovers
+type FaultService struct {
+	alerts ports.AlertRepository
+	mq     queue.MessageQueue
+	log    *zap.Logger
+}
+
+// NewFaultService creates a new fault service. Without an alert repository
+// faults are only published.
+func NewFaultService(alerts ports.AlertRepository, mq queue.MessageQueue, log *zap.Logger) *FaultService {
+	return &FaultService{
+		alerts: alerts,
+		mq:     mq,
+		log:    log,
+	}
+}
+
+// Ensure FaultService implements ports.FaultService
+var _ ports.FaultService = (*FaultService)(nil)
+
+// ReportFault raises an alert for a faulted connector and publishes device.fault
+func (s *FaultService) ReportFault(ctx context.Context, fault domain.ConnectorFault) (*ports.Alert, error) {
+	if fault.Timestamp.IsZero() {
+		fault.Timestamp = time.Now()
+	}
+
+	code := fault.ErrorCode
+	if code == "" {
+		code = "Unknown"
+	}
+	message := fmt.Sprintf("Charge point %s reported connector %d as Faulted (error code: %s)",
+		fault.ChargePointID, fault.ConnectorID, code)
+	if fault.VendorErrorCode != "" {
+		message += fmt.Sprintf(", vendor error code: %s", fault.VendorErrorCode)
+	}
+	if fault.Info != "" {
+		message += ": " + fault.Info
+	}
+
+	alert := &ports.Alert{
+		ID:        uuid.New().String(),
+		Type:      AlertTypeConnectorFault,
+		Severity:  FaultSeverity(fault.ErrorCode),
+		Title:     fmt.Sprintf("Connector %d faulted: %s", fault.ConnectorID, code),
+		Message:   message,
+		Source:    "connector",
+		SourceID:  connectorSourceID(fault.ChargePointID, fault.ConnectorID),
+		CreatedAt: fault.Timestamp,
+	}
+
+	if s.alerts != nil {
+		if err := s.alerts.Save(ctx, alert); err != nil {
+			return nil, fmt.Errorf("failed to save fault alert: %w", err)
+		}
+	}
+
+	s.publish("device.fault", map[string]interface{}{
+		"alert_id":          alert.ID,
+		"device_id":         fault.ChargePointID,
+		"connector_id":      fault.ConnectorID,
+		"error_code":        fault.ErrorCode,
+		"vendor_error_code": fault.VendorErrorCode,
+		"severity":          alert.Severity,
+		"timestamp":         fault.Timestamp.UTC().Format(time.RFC3339),
+	})
+
+	s.log.Warn("Connector fault reported",
+		zap.String("device_id", fault.ChargePointID),
+		zap.Int("connector_id", fault.ConnectorID),
+		zap.String("error_code", fault.ErrorCode),
+		zap.String("severity", alert.Severity),
+	)
+
+	return alert, nil
+}
+
+// ResolveFaults resolves the open fault alerts of a connector that recovered
+func (s *FaultService) ResolveFaults(ctx context.Context, chargePointID string, connectorID int) error {
+	if s.alerts == nil {
+		return nil
+	}
+
+	open, err := s.alerts.FindOpen(ctx, AlertTypeConnectorFault, connectorSourceID(chargePointID, connectorID))
+	if err != nil {
+		return fmt.Errorf("failed to find open fault alerts: %w", err)
+	}
+
+	now := time.Now()
+	for _, alert := range open {
+		if err := s.alerts.Resolve(ctx, alert.ID, now); err != nil {
+			return fmt.Errorf("failed to resolve alert %s: %w", alert.ID, err)
+		}
+
+		s.publish("device.fault.resolved", map[string]interface{}{
+			"alert_id":     alert.ID,
+			"device_id":    chargePointID,
+			"connector_id": connectorID,
+			"timestamp":    now.UTC().Format(time.RFC3339),
+		})
+
+		s.log.Info("Connector fault resolved",
+			zap.String("device_id", chargePointID),
+			zap.Int("connector_id", connectorID),
+			zap.String("alert_id", alert.ID),
+		)
+	}
+
+	return nil
+}
+
+func (s *FaultService) publish(subject string, event map[string]interface{}) {
+	if s.mq == nil {
+		return
+	}
+	if data, err := json.Marshal(event); err == nil {
+		if err := s.mq.Publish(subject, data); err != nil {
+			s.log.Warn("Failed to publish fault event", zap.String("subject", subject), zap.Error(err))
+		}
+	}
+}
+
+// connectorSourceID identifies a connector as the source of an alert
+func connectorSourceID(chargePointID string, connectorID int) string {
+	return fmt.Sprintf("%s/%d", chargePointID, connectorID)
+}
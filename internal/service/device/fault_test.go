@@ -0,0 +1,130 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// newAlertStore returns an alert repository mock backed by a map
+func newAlertStore() (*mocks.MockAlertRepository, map[string]*ports.Alert) {
+	alerts := make(map[string]*ports.Alert)
+	repo := &mocks.MockAlertRepository{
+		SaveFunc: func(ctx context.Context, alert *ports.Alert) error {
+			stored := *alert
+			alerts[alert.ID] = &stored
+			return nil
+		},
+		FindOpenFunc: func(ctx context.Context, alertType, sourceID string) ([]ports.Alert, error) {
+			var open []ports.Alert
+			for _, a := range alerts {
+				if a.Type == alertType && a.SourceID == sourceID && !a.Resolved {
+					open = append(open, *a)
+				}
+			}
+			return open, nil
+		},
+		ResolveFunc: func(ctx context.Context, id string, resolvedAt time.Time) error {
+			alerts[id].Resolved = true
+			alerts[id].ResolvedAt = &resolvedAt
+			return nil
+		},
+	}
+	return repo, alerts
+}
+
+func TestReportFault_HighSeverityAlert(t *testing.T) {
+	// Arrange
+	repo, alerts := newAlertStore()
+	mq := mocks.NewMockMessageQueue()
+	service := NewFaultService(repo, mq, zap.NewNop())
+
+	// Act
+	alert, err := service.ReportFault(context.Background(), domain.ConnectorFault{
+		ChargePointID:   "CP-001",
+		ConnectorID:     2,
+		ErrorCode:       "GroundFailure",
+		VendorErrorCode: "E-417",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if alert.Severity != "critical" {
+		t.Errorf("expected critical severity, got %s", alert.Severity)
+	}
+	if _, ok := alerts[alert.ID]; !ok {
+		t.Error("expected the alert to be saved")
+	}
+	if alert.SourceID != "CP-001/2" {
+		t.Errorf("expected source CP-001/2, got %s", alert.SourceID)
+	}
+
+	published := mq.PublishedMessages["device.fault"]
+	if len(published) != 1 {
+		t.Fatalf("expected 1 device.fault event, got %d", len(published))
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(published[0], &event); err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	if event["error_code"] != "GroundFailure" || event["vendor_error_code"] != "E-417" {
+		t.Errorf("expected the error codes in the event, got %v", event)
+	}
+}
+
+func TestResolveFaults_ResolvesOpenAlerts(t *testing.T) {
+	// Arrange
+	repo, alerts := newAlertStore()
+	mq := mocks.NewMockMessageQueue()
+	service := NewFaultService(repo, mq, zap.NewNop())
+	ctx := context.Background()
+
+	faulted, _ := service.ReportFault(ctx, domain.ConnectorFault{ChargePointID: "CP-001", ConnectorID: 1, ErrorCode: "OverVoltage"})
+	other, _ := service.ReportFault(ctx, domain.ConnectorFault{ChargePointID: "CP-001", ConnectorID: 2, ErrorCode: "WeakSignal"})
+
+	// Act
+	err := service.ResolveFaults(ctx, "CP-001", 1)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !alerts[faulted.ID].Resolved || alerts[faulted.ID].ResolvedAt == nil {
+		t.Error("expected the connector 1 alert to be resolved")
+	}
+	if alerts[other.ID].Resolved {
+		t.Error("expected the connector 2 alert to stay open")
+	}
+	if len(mq.PublishedMessages["device.fault.resolved"]) != 1 {
+		t.Errorf("expected 1 device.fault.resolved event, got %d", len(mq.PublishedMessages["device.fault.resolved"]))
+	}
+}
+
+func TestFaultSeverity(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"GroundFailure", "critical"},
+		{"HighTemperature", "critical"},
+		{"ConnectorLockFailure", "error"},
+		{"WeakSignal", "warning"},
+		{"", "error"},
+		{"VendorSpecific", "error"},
+	}
+
+	for _, tt := range tests {
+		if got := FaultSeverity(tt.code); got != tt.expected {
+			t.Errorf("expected %q to map to %s, got %s", tt.code, tt.expected, got)
+		}
+	}
+}
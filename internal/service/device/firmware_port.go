@@ -0,0 +1,73 @@
+package device
+
+import (
+	"context"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Ensure FirmwarePort implements ports.FirmwareService
+var _ ports.FirmwareService = (*FirmwarePort)(nil)
+
+// FirmwarePort exposes a FirmwareService through the ports.FirmwareService
+// port used by the HTTP handlers and the OCPP server
+type FirmwarePort struct {
+	service *FirmwareService
+}
+
+// NewFirmwarePort creates the firmware port over service
+func NewFirmwarePort(service *FirmwareService) *FirmwarePort {
+	return &FirmwarePort{service: service}
+}
+
+func (p *FirmwarePort) UpdateFirmware(ctx context.Context, req *ports.FirmwareUpdateRequest) (*ports.FirmwareUpdateStatus, error) {
+	update, err := p.service.UpdateFirmware(ctx, &UpdateFirmwareRequest{
+		ChargePointID:      req.ChargePointID,
+		FirmwareURL:        req.FirmwareURL,
+		Version:            req.Version,
+		RetrieveDateTime:   req.RetrieveDateTime,
+		InstallDateTime:    req.InstallDateTime,
+		Retries:            req.Retries,
+		RetryInterval:      req.RetryInterval,
+		SigningCertificate: req.SigningCertificate,
+		Signature:          req.Signature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.status(update), nil
+}
+
+// GetFirmwareStatus returns nil when the charge point has no update
+func (p *FirmwarePort) GetFirmwareStatus(ctx context.Context, chargePointID string) (*ports.FirmwareUpdateStatus, error) {
+	update, err := p.service.GetFirmwareStatus(ctx, chargePointID)
+	if err != nil || update == nil {
+		return nil, err
+	}
+	return p.status(update), nil
+}
+
+func (p *FirmwarePort) CancelFirmwareUpdate(ctx context.Context, chargePointID string) error {
+	return p.service.CancelFirmwareUpdate(ctx, chargePointID)
+}
+
+func (p *FirmwarePort) HandleStatusNotification(chargePointID, status string, requestID *int) error {
+	return p.service.HandleFirmwareStatusNotification(chargePointID, status, requestID)
+}
+
+// status copies an update under the service lock, since status
+// notifications change it in place
+func (p *FirmwarePort) status(update *FirmwareUpdate) *ports.FirmwareUpdateStatus {
+	p.service.mu.RLock()
+	defer p.service.mu.RUnlock()
+	return &ports.FirmwareUpdateStatus{
+		ID:            update.ID,
+		ChargePointID: update.ChargePointID,
+		Version:       update.Version,
+		Status:        string(update.Status),
+		Progress:      update.Progress,
+		ErrorMessage:  update.ErrorMessage,
+		CreatedAt:     update.CreatedAt,
+		UpdatedAt:     update.UpdatedAt,
+	}
+}
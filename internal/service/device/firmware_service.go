@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -219,14 +220,14 @@ func (s *FirmwareService) CancelFirmwareUpdate(ctx context.Context, chargePointI
 	update, exists := s.updates[chargePointID]
 	if !exists {
 		s.mu.Unlock()
-		return fmt.Errorf("no firmware update found for %s", chargePointID)
+		return fmt.Errorf("no firmware update found for %s: %w", chargePointID, domain.ErrNotFound)
 	}
 
 	// Can only cancel if not yet installing
 	if update.Status == FirmwareStatusInstalling ||
 		update.Status == FirmwareStatusInstallRebooting {
 		s.mu.Unlock()
-		return fmt.Errorf("cannot cancel firmware update in status %s", update.Status)
+		return fmt.Errorf("cannot cancel firmware update in status %s: %w", update.Status, domain.ErrConflict)
 	}
 
 	update.Status = FirmwareStatusIdle
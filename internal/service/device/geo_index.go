@@ -0,0 +1,275 @@
+package device
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+const (
+	// geoMaxPrecision is the finest geohash precision indexed (~1.2 x 0.6 km cells)
+	geoMaxPrecision = 6
+
+	// geoMaxQueryCells bounds how many cells a query visits; coarser
+	// precisions are used for larger radii
+	geoMaxQueryCells = 64
+
+	earthRadiusKM = 6371.0
+	kmPerDegree   = math.Pi * earthRadiusKM / 180.0
+)
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// geoEntry is an indexed charge point with its coordinates
+type geoEntry struct {
+	lat, lon float64
+	hashes   [geoMaxPrecision + 1]string
+	device   domain.ChargePoint
+}
+
+// GeoIndex is an in-memory geohash index of charge points. Every device is
+// bucketed at each precision from 1 to geoMaxPrecision so a query can pick
+// the precision that covers its radius with a handful of cells.
+type GeoIndex struct {
+	mu      sync.RWMutex
+	entries map[string]*geoEntry
+	cells   [geoMaxPrecision + 1]map[string]map[string]struct{}
+	loaded  bool
+}
+
+// NewGeoIndex creates an empty, cold index
+func NewGeoIndex() *GeoIndex {
+	idx := &GeoIndex{entries: make(map[string]*geoEntry)}
+	for p := 1; p <= geoMaxPrecision; p++ {
+		idx.cells[p] = make(map[string]map[string]struct{})
+	}
+	return idx
+}
+
+// Load replaces the index contents and marks it warm
+func (idx *GeoIndex) Load(devices []domain.ChargePoint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.entries = make(map[string]*geoEntry, len(devices))
+	for p := 1; p <= geoMaxPrecision; p++ {
+		idx.cells[p] = make(map[string]map[string]struct{})
+	}
+	for _, cp := range devices {
+		idx.upsertLocked(cp)
+	}
+	idx.loaded = true
+}
+
+// Loaded reports whether the index has been populated
+func (idx *GeoIndex) Loaded() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.loaded
+}
+
+// Upsert adds or refreshes a charge point. Devices without a location are removed.
+func (idx *GeoIndex) Upsert(cp domain.ChargePoint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.upsertLocked(cp)
+}
+
+// Remove drops a charge point from the index
+func (idx *GeoIndex) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(id)
+}
+
+func (idx *GeoIndex) upsertLocked(cp domain.ChargePoint) {
+	idx.removeLocked(cp.ID)
+	if cp.Location == nil {
+		return
+	}
+
+	cp.Connectors = append([]domain.Connector(nil), cp.Connectors...)
+	location := *cp.Location
+	cp.Location = &location
+
+	e := &geoEntry{lat: location.Latitude, lon: location.Longitude, device: cp}
+	for p := 1; p <= geoMaxPrecision; p++ {
+		i, j := geoCell(e.lat, e.lon, p)
+		e.hashes[p] = geohashFromCell(i, j, p)
+		bucket := idx.cells[p][e.hashes[p]]
+		if bucket == nil {
+			bucket = make(map[string]struct{})
+			idx.cells[p][e.hashes[p]] = bucket
+		}
+		bucket[cp.ID] = struct{}{}
+	}
+	idx.entries[cp.ID] = e
+}
+
+func (idx *GeoIndex) removeLocked(id string) {
+	e, ok := idx.entries[id]
+	if !ok {
+		return
+	}
+	for p := 1; p <= geoMaxPrecision; p++ {
+		bucket := idx.cells[p][e.hashes[p]]
+		delete(bucket, id)
+		if len(bucket) == 0 {
+			delete(idx.cells[p], e.hashes[p])
+		}
+	}
+	delete(idx.entries, id)
+}
+
+// Nearby returns the charge points within radiusKM of (lat, lon), closest
+// first, with DistanceKM set
+func (idx *GeoIndex) Nearby(lat, lon, radiusKM float64) []domain.ChargePoint {
+	if radiusKM <= 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var result []domain.ChargePoint
+	idx.visitCells(lat, lon, radiusKM, func(bucket map[string]struct{}) {
+		for id := range bucket {
+			e := idx.entries[id]
+			dist := haversineKM(lat, lon, e.lat, e.lon)
+			if dist > radiusKM {
+				continue
+			}
+			cp := e.device
+			cp.Connectors = append([]domain.Connector(nil), e.device.Connectors...)
+			cp.DistanceKM = dist
+			result = append(result, cp)
+		}
+	})
+
+	sortByDistance(result)
+	return result
+}
+
+// visitCells calls fn for every non-empty cell intersecting the bounding box
+// of the search circle, at the finest precision that stays within
+// geoMaxQueryCells cells
+func (idx *GeoIndex) visitCells(lat, lon, radiusKM float64, fn func(map[string]struct{})) {
+	dLat := radiusKM / kmPerDegree
+	minLat := math.Max(lat-dLat, -90)
+	maxLat := math.Min(lat+dLat, 90)
+
+	// Near the poles (or for huge radii) every longitude is in range
+	fullLon := minLat <= -90 || maxLat >= 90
+	dLon := 180.0
+	if !fullLon {
+		dLon = dLat / math.Cos(lat*math.Pi/180)
+		fullLon = dLon >= 180
+	}
+
+	for p := geoMaxPrecision; p >= 1; p-- {
+		latBits, lonBits := geoBits(p)
+		lonCells := 1 << lonBits
+
+		iMin, _ := geoCell(minLat, 0, p)
+		iMax, _ := geoCell(maxLat, 0, p)
+		rows := iMax - iMin + 1
+
+		var jMin, cols int
+		if fullLon {
+			jMin, cols = 0, lonCells
+		} else {
+			_, jMin = geoCell(0, lon-dLon, p)
+			_, jMax := geoCell(0, lon+dLon, p)
+			cols = (jMax-jMin+lonCells)%lonCells + 1
+		}
+
+		if rows*cols > geoMaxQueryCells && p > 1 {
+			continue
+		}
+
+		for i := iMin; i <= iMax && i < 1<<latBits; i++ {
+			for c := 0; c < cols; c++ {
+				j := (jMin + c) % lonCells
+				if bucket := idx.cells[p][geohashFromCell(i, j, p)]; len(bucket) > 0 {
+					fn(bucket)
+				}
+			}
+		}
+		return
+	}
+}
+
+// geoBits returns how many latitude and longitude bits a geohash of
+// precision p carries (longitude takes the extra bit)
+func geoBits(p int) (latBits, lonBits int) {
+	total := 5 * p
+	return total / 2, (total + 1) / 2
+}
+
+// geoCell returns the latitude row and longitude column of the cell
+// containing (lat, lon) at precision p
+func geoCell(lat, lon float64, p int) (int, int) {
+	latBits, lonBits := geoBits(p)
+	latCells, lonCells := 1<<latBits, 1<<lonBits
+
+	i := int((lat + 90) / 180 * float64(latCells))
+	if i >= latCells {
+		i = latCells - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	j := int(lon / 360 * float64(lonCells))
+	if j >= lonCells {
+		j = lonCells - 1
+	}
+	return i, j
+}
+
+// geohashFromCell encodes a cell as a standard geohash string by
+// interleaving the column (longitude) and row (latitude) bits
+func geohashFromCell(i, j, p int) string {
+	latBits, lonBits := geoBits(p)
+	buf := make([]byte, p)
+
+	latPos, lonPos := latBits-1, lonBits-1
+	for c := 0; c < p; c++ {
+		var v int
+		for b := 0; b < 5; b++ {
+			bit := 0
+			if (c*5+b)%2 == 0 {
+				bit = (j >> lonPos) & 1
+				lonPos--
+			} else {
+				bit = (i >> latPos) & 1
+				latPos--
+			}
+			v = v<<1 | bit
+		}
+		buf[c] = geohashBase32[v]
+	}
+	return string(buf)
+}
+
+// haversineKM returns the great-circle distance between two points in km
+func haversineKM(lat1, lon1, lat2, lon2 float64) float64 {
+	const toRad = math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKM * math.Asin(math.Sqrt(a))
+}
+
+func sortByDistance(devices []domain.ChargePoint) {
+	sort.SliceStable(devices, func(a, b int) bool {
+		return devices[a].DistanceKM < devices[b].DistanceKM
+	})
+}
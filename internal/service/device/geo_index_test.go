@@ -0,0 +1,187 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// randomDevices spreads n charge points around (lat, lon) within spreadDeg degrees
+func randomDevices(rng *rand.Rand, n int, lat, lon, spreadDeg float64) []domain.ChargePoint {
+	devices := make([]domain.ChargePoint, n)
+	for i := range devices {
+		devices[i] = domain.ChargePoint{
+			ID:     fmt.Sprintf("cp-%d", i),
+			Status: domain.ChargePointStatusAvailable,
+			Location: &domain.Location{
+				Latitude:  lat + (rng.Float64()*2-1)*spreadDeg,
+				Longitude: lon + (rng.Float64()*2-1)*spreadDeg,
+			},
+		}
+	}
+	return devices
+}
+
+// linearNearby is the reference implementation: scan everything, sort by distance
+func linearNearby(devices []domain.ChargePoint, lat, lon, radiusKM float64) []domain.ChargePoint {
+	var result []domain.ChargePoint
+	for _, cp := range devices {
+		dist := haversineKM(lat, lon, cp.Location.Latitude, cp.Location.Longitude)
+		if dist <= radiusKM {
+			cp.DistanceKM = dist
+			result = append(result, cp)
+		}
+	}
+	sortByDistance(result)
+	return result
+}
+
+func TestGeoIndex_MatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	regions := []struct {
+		name      string
+		lat, lon  float64
+		spreadDeg float64
+	}{
+		{"sao paulo", -23.55, -46.63, 0.5},
+		{"brazil", -15, -50, 15},
+		{"antimeridian", 0, 179.9, 0.5},
+		{"near pole", 89.5, 0, 0.4},
+	}
+
+	for _, region := range regions {
+		t.Run(region.name, func(t *testing.T) {
+			devices := randomDevices(rng, 2000, region.lat, region.lon, region.spreadDeg)
+			idx := NewGeoIndex()
+			idx.Load(devices)
+
+			for q := 0; q < 200; q++ {
+				lat := region.lat + (rng.Float64()*2-1)*region.spreadDeg
+				lon := region.lon + (rng.Float64()*2-1)*region.spreadDeg
+				radius := []float64{0.5, 2, 10, 50, 500}[q%5]
+
+				got := idx.Nearby(lat, lon, radius)
+				want := linearNearby(devices, lat, lon, radius)
+
+				if len(got) != len(want) {
+					t.Fatalf("query (%f, %f, %gkm): expected %d results, got %d", lat, lon, radius, len(want), len(got))
+				}
+				for i := range want {
+					if got[i].DistanceKM != want[i].DistanceKM {
+						t.Fatalf("query (%f, %f, %gkm): result %d expected distance %f, got %f",
+							lat, lon, radius, i, want[i].DistanceKM, got[i].DistanceKM)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGeoIndex_UpsertAndRemove(t *testing.T) {
+	// Arrange
+	idx := NewGeoIndex()
+	cp := domain.ChargePoint{
+		ID:       "cp-1",
+		Status:   domain.ChargePointStatusAvailable,
+		Location: &domain.Location{Latitude: -23.55, Longitude: -46.63},
+	}
+	idx.Load([]domain.ChargePoint{cp})
+
+	// Act: the station is refreshed with a new status
+	cp.Status = domain.ChargePointStatusOccupied
+	idx.Upsert(cp)
+	got := idx.Nearby(-23.55, -46.63, 1)
+
+	// Assert
+	if len(got) != 1 || got[0].Status != domain.ChargePointStatusOccupied {
+		t.Fatalf("expected refreshed device, got %+v", got)
+	}
+
+	idx.Remove("cp-1")
+	if got := idx.Nearby(-23.55, -46.63, 1); len(got) != 0 {
+		t.Errorf("expected no devices after remove, got %d", len(got))
+	}
+}
+
+func TestGetNearby_UsesIndexOnceWarm(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	devices := []domain.ChargePoint{
+		{ID: "far", Location: &domain.Location{Latitude: -23.60, Longitude: -46.63}},
+		{ID: "near", Location: &domain.Location{Latitude: -23.551, Longitude: -46.63}},
+		{ID: "outside", Location: &domain.Location{Latitude: -22.90, Longitude: -43.20}},
+	}
+
+	repoCalls := 0
+	mockRepo := &mocks.MockChargePointRepository{
+		FindAllFunc: func(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error) {
+			return devices, nil
+		},
+		FindNearbyFunc: func(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
+			repoCalls++
+			return []domain.ChargePoint{devices[0], devices[1]}, nil
+		},
+	}
+
+	service := NewService(mockRepo, mocks.NewMockCache(), mocks.NewMockMessageQueue(), newTestLogger()).(*Service)
+
+	// Act: the cold query falls back to the repository and warms the index
+	cold, err := service.GetNearby(ctx, -23.55, -46.63, 10, domain.ConnectorFilter{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !service.geo.Loaded() {
+		if time.Now().After(deadline) {
+			t.Fatal("geo index was not loaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	warm, err := service.GetNearby(ctx, -23.55, -46.63, 10, domain.ConnectorFilter{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if repoCalls != 1 {
+		t.Errorf("expected 1 repository query, got %d", repoCalls)
+	}
+	for name, result := range map[string][]domain.ChargePoint{"cold": cold, "warm": warm} {
+		if len(result) != 2 {
+			t.Fatalf("%s: expected 2 devices, got %d", name, len(result))
+		}
+		if result[0].ID != "near" || result[1].ID != "far" {
+			t.Errorf("%s: expected [near far], got [%s %s]", name, result[0].ID, result[1].ID)
+		}
+		if result[0].DistanceKM <= 0 || result[0].DistanceKM > result[1].DistanceKM {
+			t.Errorf("%s: expected increasing distances, got %f and %f", name, result[0].DistanceKM, result[1].DistanceKM)
+		}
+	}
+}
+
+func BenchmarkNearby(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	devices := randomDevices(rng, 50000, -15, -50, 15)
+	idx := NewGeoIndex()
+	idx.Load(devices)
+
+	b.Run("geohash", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			idx.Nearby(-23.55, -46.63, 10)
+		}
+	})
+
+	b.Run("linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			linearNearby(devices, -23.55, -46.63, 10)
+		}
+	})
+}
@@ -3,7 +3,9 @@ package device
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -16,6 +18,13 @@ import (
 const (
 	cacheKeyPrefix = "device:"
 	cacheTTL       = 30 * time.Second
+
+	// nearbyLimit matches the row limit of the repository nearby query
+	nearbyLimit = 50
+
+	// DefaultGeoRefreshInterval is how often the geohash index is reloaded,
+	// picking up stations added or moved by other instances
+	DefaultGeoRefreshInterval = 5 * time.Minute
 )
 
 type Service struct {
@@ -23,6 +32,9 @@ type Service struct {
 	cache ports.Cache
 	mq    queue.MessageQueue
 	log   *zap.Logger
+
+	geo        *GeoIndex
+	geoLoading atomic.Bool
 }
 
 func NewService(repo ports.ChargePointRepository, cache ports.Cache, mq queue.MessageQueue, log *zap.Logger) ports.DeviceService {
@@ -31,6 +43,7 @@ func NewService(repo ports.ChargePointRepository, cache ports.Cache, mq queue.Me
 		cache: cache,
 		mq:    mq,
 		log:   log,
+		geo:   NewGeoIndex(),
 	}
 }
 
@@ -50,6 +63,9 @@ func (s *Service) GetDevice(ctx context.Context, id string) (*domain.ChargePoint
 	if err != nil {
 		return nil, err
 	}
+	if cp != nil {
+		cp.ApplyConnectorRollup()
+	}
 
 	// Set cache
 	if cp != nil {
@@ -64,7 +80,12 @@ func (s *Service) GetDevice(ctx context.Context, id string) (*domain.ChargePoint
 }
 
 func (s *Service) ListDevices(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error) {
-	return s.repo.FindAll(ctx, filter)
+	devices, err := s.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	applyConnectorRollup(devices)
+	return devices, nil
 }
 
 func (s *Service) UpdateStatus(ctx context.Context, id string, status domain.ChargePointStatus) error {
@@ -78,6 +99,12 @@ func (s *Service) UpdateStatus(ctx context.Context, id string, status domain.Cha
 		s.log.Warn("Failed to invalidate cache", zap.String("id", id), zap.Error(err))
 	}
 
+	if s.geo.Loaded() {
+		if cp, err := s.repo.FindByID(ctx, id); err == nil && cp != nil {
+			s.geo.Upsert(*cp)
+		}
+	}
+
 	// Publish event (if message queue available)
 	if s.mq != nil {
 		event := map[string]interface{}{
@@ -95,11 +122,225 @@ func (s *Service) UpdateStatus(ctx context.Context, id string, status domain.Cha
 	return nil
 }
 
-func (s *Service) GetNearby(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error) {
-	return s.repo.FindNearby(ctx, lat, lon, radius)
+// UpdateConnectorStatus records the status of a single connector and keeps
+// the device-level status in line with the connector rollup
+func (s *Service) UpdateConnectorStatus(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error {
+	if err := s.repo.UpdateConnectorStatus(ctx, id, connectorID, status); err != nil {
+		return fmt.Errorf("failed to update connector status: %w", err)
+	}
+
+	return s.connectorChanged(ctx, id, connectorID, status)
+}
+
+// ReserveConnector moves an Available connector to Reserved with a
+// compare-and-set, so of two sessions racing for it only one wins
+func (s *Service) ReserveConnector(ctx context.Context, id string, connectorID int) error {
+	swapped, err := s.repo.CompareAndSetConnectorStatus(ctx, id, connectorID, domain.ChargePointStatusAvailable, domain.ChargePointStatusReserved)
+	if err != nil {
+		return fmt.Errorf("failed to reserve connector: %w", err)
+	}
+	if !swapped {
+		return fmt.Errorf("%w: %s connector %d", domain.ErrConnectorUnavailable, id, connectorID)
+	}
+	return s.connectorChanged(ctx, id, connectorID, domain.ChargePointStatusReserved)
+}
+
+// ReleaseConnector moves a Reserved connector back to Available
+func (s *Service) ReleaseConnector(ctx context.Context, id string, connectorID int) error {
+	swapped, err := s.repo.CompareAndSetConnectorStatus(ctx, id, connectorID, domain.ChargePointStatusReserved, domain.ChargePointStatusAvailable)
+	if err != nil {
+		return fmt.Errorf("failed to release connector: %w", err)
+	}
+	if !swapped {
+		return nil
+	}
+	return s.connectorChanged(ctx, id, connectorID, domain.ChargePointStatusAvailable)
+}
+
+// connectorChanged publishes a connector status change and brings the
+// device status and caches in line with it
+func (s *Service) connectorChanged(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error {
+	cp, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to load device: %w", err)
+	}
+	if cp == nil {
+		return fmt.Errorf("device %s not found", id)
+	}
+
+	if s.mq != nil {
+		event := map[string]interface{}{
+			"device_id":    id,
+			"connector_id": connectorID,
+			"status":       status,
+			"timestamp":    time.Now().UTC().Format(time.RFC3339),
+		}
+		if data, err := json.Marshal(event); err == nil {
+			if err := s.mq.Publish("connector.status.changed", data); err != nil {
+				s.log.Warn("Failed to publish connector status event", zap.Error(err))
+			}
+		}
+	}
+
+	rollup := cp.ConnectorRollup()
+	if rollup == cp.Status {
+		// Device status is unchanged but the cached connectors are stale
+		if err := s.cache.Delete(ctx, cacheKeyPrefix+id); err != nil {
+			s.log.Warn("Failed to invalidate cache", zap.String("id", id), zap.Error(err))
+		}
+		if s.geo.Loaded() {
+			s.geo.Upsert(*cp)
+		}
+		return nil
+	}
+
+	return s.UpdateStatus(ctx, id, rollup)
+}
+
+// SetHeartbeatInterval stores the heartbeat interval negotiated at boot so
+// offline detection can use it
+func (s *Service) SetHeartbeatInterval(ctx context.Context, id string, interval int) error {
+	if err := s.repo.UpdateHeartbeatInterval(ctx, id, interval); err != nil {
+		return fmt.Errorf("failed to update heartbeat interval: %w", err)
+	}
+
+	if err := s.cache.Delete(ctx, cacheKeyPrefix+id); err != nil {
+		s.log.Warn("Failed to invalidate cache", zap.String("id", id), zap.Error(err))
+	}
+	return nil
+}
+
+// RecordBootInfo stores the identity a device reported at boot. A firmware
+// version other than the stored one, e.g. after an update or a swap done on
+// site, is published as device.firmware_changed.
+func (s *Service) RecordBootInfo(ctx context.Context, id string, info domain.BootInfo) error {
+	// The repository hands back the version it replaced, so a duplicated
+	// boot never publishes the same change twice
+	previous, err := s.repo.UpdateBootInfo(ctx, id, info)
+	if errors.Is(err, domain.ErrDeviceNotFound) {
+		return nil // Unregistered devices are not created on boot
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update boot info: %w", err)
+	}
+	if err := s.cache.Delete(ctx, cacheKeyPrefix+id); err != nil {
+		s.log.Warn("Failed to invalidate cache", zap.String("id", id), zap.Error(err))
+	}
+
+	if info.FirmwareVersion == "" || previous == "" || info.FirmwareVersion == previous {
+		return nil // Not reported, first seen or unchanged
+	}
+	s.log.Info("Device firmware version changed",
+		zap.String("id", id),
+		zap.String("previous", previous),
+		zap.String("firmwareVersion", info.FirmwareVersion),
+	)
+	if s.mq != nil {
+		event := map[string]interface{}{
+			"device_id":                 id,
+			"previous_firmware_version": previous,
+			"firmware_version":          info.FirmwareVersion,
+			"timestamp":                 time.Now().UTC().Format(time.RFC3339),
+		}
+		if data, err := json.Marshal(event); err == nil {
+			if err := s.mq.Publish("device.firmware_changed", data); err != nil {
+				s.log.Warn("Failed to publish firmware change event", zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// GetNearby returns devices within radius km with a connector matching
+// connectors, closest first, with their distance and connector-derived
+// status. Queries are served from the geohash index; until it is loaded the
+// repository's haversine query is used.
+func (s *Service) GetNearby(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
+	if s.geo.Loaded() {
+		devices := s.geo.Nearby(lat, lon, radius)
+		if !connectors.IsZero() {
+			matching := devices[:0]
+			for i := range devices {
+				if connectors.Matches(&devices[i]) {
+					matching = append(matching, devices[i])
+				}
+			}
+			devices = matching
+		}
+		if len(devices) > nearbyLimit {
+			devices = devices[:nearbyLimit]
+		}
+		applyConnectorRollup(devices)
+		return devices, nil
+	}
+
+	s.warmGeoIndex()
+
+	devices, err := s.repo.FindNearby(ctx, lat, lon, radius, connectors)
+	if err != nil {
+		return nil, err
+	}
+	for i := range devices {
+		if loc := devices[i].Location; loc != nil {
+			devices[i].DistanceKM = haversineKM(lat, lon, loc.Latitude, loc.Longitude)
+		}
+	}
+	sortByDistance(devices)
+	applyConnectorRollup(devices)
+	return devices, nil
+}
+
+// warmGeoIndex loads the geohash index in the background. A failed load is
+// retried by the next cold query.
+func (s *Service) warmGeoIndex() {
+	if !s.geoLoading.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer s.geoLoading.Store(false)
+		s.loadGeoIndex(context.Background())
+	}()
+}
+
+// loadGeoIndex replaces the geohash index with every stored device
+func (s *Service) loadGeoIndex(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	devices, err := s.repo.FindAll(ctx, map[string]interface{}{})
+	if err != nil {
+		s.log.Warn("Failed to load geo index", zap.Error(err))
+		return
+	}
+	s.geo.Load(devices)
+	s.log.Info("Geo index loaded", zap.Int("devices", len(devices)))
+}
+
+// StartGeoIndexRefresh loads the geohash index now and reloads it every
+// interval until ctx is done. The index only sees the updates made through
+// this instance, so the reload picks up those made elsewhere.
+func (s *Service) StartGeoIndexRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultGeoRefreshInterval
+	}
+	go func() {
+		s.loadGeoIndex(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.loadGeoIndex(ctx)
+			}
+		}
+	}()
 }
 
-// ListAvailableDevices returns all devices with Available status (used by VoiceAssistant)
+// ListAvailableDevices returns all devices with at least one free connector (used by VoiceAssistant)
 func (s *Service) ListAvailableDevices(ctx context.Context) ([]domain.ChargePoint, error) {
 	filter := map[string]interface{}{
 		"status": domain.ChargePointStatusAvailable,
@@ -111,5 +352,22 @@ func (s *Service) ListAvailableDevices(ctx context.Context) ([]domain.ChargePoin
 		return nil, fmt.Errorf("failed to list available devices: %w", err)
 	}
 
-	return devices, nil
+	// The stored device status can lag behind connector updates
+	available := make([]domain.ChargePoint, 0, len(devices))
+	for _, cp := range devices {
+		if !cp.HasAvailableConnector() {
+			continue
+		}
+		cp.ApplyConnectorRollup()
+		available = append(available, cp)
+	}
+
+	return available, nil
+}
+
+// applyConnectorRollup refreshes the derived status of every device
+func applyConnectorRollup(devices []domain.ChargePoint) {
+	for i := range devices {
+		devices[i].ApplyConnectorRollup()
+	}
 }
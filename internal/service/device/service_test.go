@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -313,7 +314,7 @@ func TestGetNearby_Success(t *testing.T) {
 	var receivedLat, receivedLon, receivedRadius float64
 
 	mockRepo := &mocks.MockChargePointRepository{
-		FindNearbyFunc: func(ctx context.Context, lat, lon, radius float64) ([]domain.ChargePoint, error) {
+		FindNearbyFunc: func(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
 			receivedLat = lat
 			receivedLon = lon
 			receivedRadius = radius
@@ -327,7 +328,7 @@ func TestGetNearby_Success(t *testing.T) {
 	service := NewService(mockRepo, mockCache, mockQueue, newTestLogger())
 
 	// Act
-	devices, err := service.GetNearby(ctx, -23.55, -46.63, 5.0)
+	devices, err := service.GetNearby(ctx, -23.55, -46.63, 5.0, domain.ConnectorFilter{})
 
 	// Assert
 	if err != nil {
@@ -408,3 +409,263 @@ func TestListAvailableDevices_RepositoryError(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestConnectorRollup_MixedStatuses(t *testing.T) {
+	tests := []struct {
+		name       string
+		connectors []domain.ChargePointStatus
+		expected   domain.ChargePointStatus
+		available  int
+	}{
+		{"one charging one available", []domain.ChargePointStatus{domain.ChargePointStatusOccupied, domain.ChargePointStatusAvailable}, domain.ChargePointStatusAvailable, 1},
+		{"all busy", []domain.ChargePointStatus{domain.ChargePointStatusOccupied, domain.ChargePointStatusReserved}, domain.ChargePointStatusOccupied, 0},
+		{"one busy one faulted", []domain.ChargePointStatus{domain.ChargePointStatusFaulted, domain.ChargePointStatusOccupied}, domain.ChargePointStatusOccupied, 0},
+		{"all faulted", []domain.ChargePointStatus{domain.ChargePointStatusFaulted, domain.ChargePointStatusFaulted}, domain.ChargePointStatusFaulted, 0},
+		{"faulted and unavailable", []domain.ChargePointStatus{domain.ChargePointStatusFaulted, domain.ChargePointStatusUnavailable}, domain.ChargePointStatusUnavailable, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &domain.ChargePoint{ID: "device-1", Status: domain.ChargePointStatusAvailable}
+			for i, status := range tt.connectors {
+				cp.Connectors = append(cp.Connectors, domain.Connector{ConnectorID: i + 1, Status: status})
+			}
+
+			cp.ApplyConnectorRollup()
+
+			if cp.Status != tt.expected {
+				t.Errorf("expected status '%s', got '%s'", tt.expected, cp.Status)
+			}
+			if cp.AvailableConnectors != tt.available {
+				t.Errorf("expected %d available connectors, got %d", tt.available, cp.AvailableConnectors)
+			}
+		})
+	}
+}
+
+func TestUpdateConnectorStatus_UpdatesDeviceRollup(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	device := &domain.ChargePoint{
+		ID:     "device-123",
+		Status: domain.ChargePointStatusAvailable,
+		Connectors: []domain.Connector{
+			{ConnectorID: 1, Status: domain.ChargePointStatusOccupied},
+			{ConnectorID: 2, Status: domain.ChargePointStatusAvailable},
+		},
+	}
+
+	var deviceStatus domain.ChargePointStatus
+	mockRepo := &mocks.MockChargePointRepository{
+		UpdateConnectorStatusFunc: func(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error {
+			for i := range device.Connectors {
+				if device.Connectors[i].ConnectorID == connectorID {
+					device.Connectors[i].Status = status
+				}
+			}
+			return nil
+		},
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			cp := *device
+			cp.Connectors = append([]domain.Connector(nil), device.Connectors...)
+			return &cp, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, id string, status domain.ChargePointStatus) error {
+			deviceStatus = status
+			device.Status = status
+			return nil
+		},
+	}
+
+	mockCache := mocks.NewMockCache()
+	mockQueue := mocks.NewMockMessageQueue()
+
+	service := NewService(mockRepo, mockCache, mockQueue, newTestLogger())
+
+	// Act: connector 1 finishing keeps the device available
+	if err := service.UpdateConnectorStatus(ctx, "device-123", 1, domain.ChargePointStatusAvailable); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deviceStatus != "" {
+		t.Errorf("expected device status untouched, got '%s'", deviceStatus)
+	}
+
+	// Act: both connectors in use
+	service.UpdateConnectorStatus(ctx, "device-123", 1, domain.ChargePointStatusOccupied)
+	err := service.UpdateConnectorStatus(ctx, "device-123", 2, domain.ChargePointStatusOccupied)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deviceStatus != domain.ChargePointStatusOccupied {
+		t.Errorf("expected device status 'Occupied', got '%s'", deviceStatus)
+	}
+}
+
+func TestListAvailableDevices_SkipsDevicesWithoutFreeConnector(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+
+	mockRepo := &mocks.MockChargePointRepository{
+		FindAllFunc: func(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error) {
+			return []domain.ChargePoint{
+				{ID: "mixed", Status: domain.ChargePointStatusAvailable, Connectors: []domain.Connector{
+					{ConnectorID: 1, Status: domain.ChargePointStatusOccupied},
+					{ConnectorID: 2, Status: domain.ChargePointStatusAvailable},
+				}},
+				{ID: "stale", Status: domain.ChargePointStatusAvailable, Connectors: []domain.Connector{
+					{ConnectorID: 1, Status: domain.ChargePointStatusOccupied},
+					{ConnectorID: 2, Status: domain.ChargePointStatusFaulted},
+				}},
+				{ID: "no-connectors", Status: domain.ChargePointStatusAvailable},
+			}, nil
+		},
+	}
+
+	mockCache := mocks.NewMockCache()
+	mockQueue := mocks.NewMockMessageQueue()
+
+	service := NewService(mockRepo, mockCache, mockQueue, newTestLogger())
+
+	// Act
+	devices, err := service.ListAvailableDevices(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(devices))
+	}
+	if devices[0].ID != "mixed" || devices[0].AvailableConnectors != 1 {
+		t.Errorf("expected 'mixed' with 1 available connector, got '%s' with %d", devices[0].ID, devices[0].AvailableConnectors)
+	}
+	if devices[1].ID != "no-connectors" {
+		t.Errorf("expected 'no-connectors', got '%s'", devices[1].ID)
+	}
+}
+
+func TestStartGeoIndexRefresh_PicksUpNewStations(t *testing.T) {
+	// Arrange - another instance adds a station after the first load
+	var mu sync.Mutex
+	stored := []domain.ChargePoint{{ID: "device-1", Location: &domain.Location{Latitude: -23.55, Longitude: -46.63}}}
+	mockRepo := &mocks.MockChargePointRepository{
+		FindAllFunc: func(ctx context.Context, filter map[string]interface{}) ([]domain.ChargePoint, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]domain.ChargePoint(nil), stored...), nil
+		},
+	}
+	service := NewService(mockRepo, mocks.NewMockCache(), mocks.NewMockMessageQueue(), newTestLogger()).(*Service)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	service.StartGeoIndexRefresh(ctx, 10*time.Millisecond)
+	mu.Lock()
+	stored = append(stored, domain.ChargePoint{ID: "device-2", Location: &domain.Location{Latitude: -23.551, Longitude: -46.631}})
+	mu.Unlock()
+
+	// Assert
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		devices, err := service.GetNearby(ctx, -23.55, -46.63, 5.0, domain.ConnectorFilter{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if service.geo.Loaded() && len(devices) == 2 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the reload to add device-2, got %d devices", len(devices))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestGetNearby_DerivesStatusFromConnectors(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+
+	mockRepo := &mocks.MockChargePointRepository{
+		FindNearbyFunc: func(ctx context.Context, lat, lon, radius float64, connectors domain.ConnectorFilter) ([]domain.ChargePoint, error) {
+			return []domain.ChargePoint{
+				{ID: "device-1", Status: domain.ChargePointStatusOccupied, Connectors: []domain.Connector{
+					{ConnectorID: 1, Status: domain.ChargePointStatusOccupied},
+					{ConnectorID: 2, Status: domain.ChargePointStatusAvailable},
+				}},
+			}, nil
+		},
+	}
+
+	mockCache := mocks.NewMockCache()
+	mockQueue := mocks.NewMockMessageQueue()
+
+	service := NewService(mockRepo, mockCache, mockQueue, newTestLogger())
+
+	// Act
+	devices, err := service.GetNearby(ctx, -23.55, -46.63, 5.0, domain.ConnectorFilter{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if devices[0].Status != domain.ChargePointStatusAvailable {
+		t.Errorf("expected status 'Available', got '%s'", devices[0].Status)
+	}
+	if devices[0].AvailableConnectors != 1 {
+		t.Errorf("expected 1 available connector, got %d", devices[0].AvailableConnectors)
+	}
+}
+
+func TestRecordBootInfo_UpdatesFirmwareVersionAcrossBoots(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	device := &domain.ChargePoint{ID: "device-123", Vendor: "ABB", Model: "Terra 184"}
+
+	mockRepo := &mocks.MockChargePointRepository{
+		UpdateBootInfoFunc: func(ctx context.Context, id string, info domain.BootInfo) (string, error) {
+			previous := device.FirmwareVersion
+			device.FirmwareVersion = info.FirmwareVersion
+			return previous, nil
+		},
+	}
+
+	mockCache := mocks.NewMockCache()
+	mockQueue := mocks.NewMockMessageQueue()
+
+	service := NewService(mockRepo, mockCache, mockQueue, newTestLogger())
+	boot := domain.BootInfo{Vendor: "ABB", Model: "Terra 184", FirmwareVersion: "1.0.0"}
+
+	// Act: the first boot only records the version
+	if err := service.RecordBootInfo(ctx, "device-123", boot); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(mockQueue.GetPublishedMessages("device.firmware_changed")) != 0 {
+		t.Error("expected no firmware change on the first boot")
+	}
+
+	// Act: rebooted after a firmware update
+	boot.FirmwareVersion = "1.1.0"
+	err := service.RecordBootInfo(ctx, "device-123", boot)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if device.FirmwareVersion != "1.1.0" {
+		t.Errorf("expected firmware version '1.1.0', got '%s'", device.FirmwareVersion)
+	}
+	messages := mockQueue.GetPublishedMessages("device.firmware_changed")
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 firmware change event, got %d", len(messages))
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(messages[0], &event); err != nil {
+		t.Fatalf("expected a JSON event, got %v", err)
+	}
+	if event["previous_firmware_version"] != "1.0.0" || event["firmware_version"] != "1.1.0" {
+		t.Errorf("expected 1.0.0 -> 1.1.0, got %v", event)
+	}
+}
@@ -0,0 +1,146 @@
+package email
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// numberFormat holds how a language writes numbers
+type numberFormat struct {
+	thousands string
+	decimal   string
+	symbolGap string // Between the currency symbol and the amount
+}
+
+var numberFormats = map[string]numberFormat{
+	domain.LanguageEnglish:      {thousands: ",", decimal: ".", symbolGap: ""},
+	domain.LanguagePortugueseBR: {thousands: ".", decimal: ",", symbolGap: " "},
+}
+
+// currencySymbols holds the symbols of the currencies users are billed in,
+// per language. Other currencies are written with their ISO code.
+var currencySymbols = map[string]map[string]string{
+	domain.LanguageEnglish: {
+		"BRL": "R$",
+		"USD": "$",
+		"EUR": "€",
+	},
+	domain.LanguagePortugueseBR: {
+		"BRL": "R$",
+		"USD": "US$",
+		"EUR": "€",
+	},
+}
+
+// formatFuncs returns the template functions formatting values for a
+// language:
+//
+//	{{money .Cost .Currency}} → "R$ 1.234,56" in pt-BR, "R$1,234.56" in English
+//	{{number .EnergyKWh}}     → "25,50" in pt-BR, "25.50" in English
+func formatFuncs(lang string) template.FuncMap {
+	return template.FuncMap{
+		"money": func(amount interface{}, currency string) string {
+			v, ok := toFloat(amount)
+			if !ok {
+				return fmt.Sprint(amount)
+			}
+			return formatMoney(lang, v, currency)
+		},
+		"number": func(value interface{}) string {
+			v, ok := toFloat(value)
+			if !ok {
+				return fmt.Sprint(value)
+			}
+			return formatNumber(lang, v, 2)
+		},
+	}
+}
+
+// formatMoney writes an amount with the currency symbol and separators of
+// a language. Currencies without a symbol are written as "CHF 5.25".
+func formatMoney(lang string, amount float64, currency string) string {
+	if currency == "" {
+		currency = "BRL"
+	}
+	currency = strings.ToUpper(currency)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	number := formatNumber(lang, amount, 2)
+
+	symbol, ok := currencySymbols[lang][currency]
+	if !ok {
+		if _, known := currencySymbols[lang]; !known {
+			symbol, ok = currencySymbols[domain.LanguageEnglish][currency]
+		}
+	}
+	if !ok {
+		return sign + currency + " " + number
+	}
+	return sign + symbol + formatOf(lang).symbolGap + number
+}
+
+// formatNumber writes a value with the given decimals and the separators of
+// a language
+func formatNumber(lang string, value float64, decimals int) string {
+	format := formatOf(lang)
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	text := strconv.FormatFloat(value, 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(text, ".")
+
+	var grouped strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(format.thousands)
+		}
+		grouped.WriteRune(d)
+	}
+	if frac != "" {
+		return sign + grouped.String() + format.decimal + frac
+	}
+	return sign + grouped.String()
+}
+
+// formatOf returns the number format of a language, English for languages
+// without their own
+func formatOf(lang string) numberFormat {
+	if format, ok := numberFormats[lang]; ok {
+		return format
+	}
+	return numberFormats[domain.LanguageEnglish]
+}
+
+// toFloat reads a numeric template value. Strings are accepted for callers
+// of SendTemplate that format amounts themselves.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, false
+		}
+		return f, true
+	}
+	return 0, false
+}
@@ -0,0 +1,107 @@
+package email
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		lang     string
+		amount   float64
+		currency string
+		want     string
+	}{
+		{domain.LanguagePortugueseBR, 5.25, "BRL", "R$ 5,25"},
+		{domain.LanguagePortugueseBR, 1234567.891, "BRL", "R$ 1.234.567,89"},
+		{domain.LanguagePortugueseBR, -12.5, "brl", "-R$ 12,50"},
+		{domain.LanguagePortugueseBR, 10, "USD", "US$ 10,00"},
+		{domain.LanguagePortugueseBR, 3, "", "R$ 3,00"},
+		{domain.LanguageEnglish, 5.25, "BRL", "R$5.25"},
+		{domain.LanguageEnglish, 1234.5, "USD", "$1,234.50"},
+		{domain.LanguageEnglish, 0.999, "EUR", "€1.00"},
+		{domain.LanguageEnglish, 5.25, "CHF", "CHF 5.25"},
+		{"de-DE", 1234.5, "EUR", "€1,234.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang+" "+tt.want, func(t *testing.T) {
+			// Act
+			got := formatMoney(tt.lang, tt.amount, tt.currency)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestService_SendChargingCompleted_FormatsForLocale(t *testing.T) {
+	tests := []struct {
+		language   string
+		wantCost   string
+		wantEnergy string
+	}{
+		{domain.LanguagePortugueseBR, "R$ 1.234,56", "25,50 kWh"},
+		{"en-US", "R$1,234.56", "25.50 kWh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			// Arrange
+			mockProvider := &MockProvider{}
+			service := newTestService(mockProvider)
+			service.loadTemplates()
+			user := &domain.User{ID: "user-123", Name: "Maria", Email: "maria@example.com", Language: tt.language}
+			endTime := time.Now()
+			tx := &domain.Transaction{
+				ID:         "tx-123",
+				StartTime:  endTime.Add(-90 * time.Minute),
+				EndTime:    &endTime,
+				MeterStart: 1000,
+				MeterStop:  26500,
+			}
+
+			// Act
+			err := service.SendChargingCompleted(context.Background(), user, tx, 1234.56)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			body := mockProvider.SentEmails[0].Body
+			if !strings.Contains(body, tt.wantCost) {
+				t.Errorf("expected body to contain %q", tt.wantCost)
+			}
+			if !strings.Contains(body, tt.wantEnergy) {
+				t.Errorf("expected body to contain %q", tt.wantEnergy)
+			}
+			if strings.Contains(body, "BRL") {
+				t.Error("expected the currency symbol instead of its code")
+			}
+		})
+	}
+}
+
+func TestRenderTemplate_KeepsPreformattedAmounts(t *testing.T) {
+	// Arrange: SendTemplate callers may still pass amounts as text
+	service := newTestService(&MockProvider{})
+	service.loadTemplates()
+	data := map[string]interface{}{"UserName": "Maria", "Balance": "not a number", "Currency": "BRL"}
+
+	// Act
+	body, err := service.renderTemplate(localizedKey(domain.LanguagePortugueseBR, "low_balance"), data)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(body, "not a number") {
+		t.Error("expected a value that is not a number to be rendered as is")
+	}
+}
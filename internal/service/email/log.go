@@ -0,0 +1,42 @@
+package email
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// LogProvider implements the Provider interface by logging messages instead
+// of sending them. It is meant for development and tests.
+type LogProvider struct {
+	log *zap.Logger
+}
+
+// NewLogProvider creates a new log-only provider
+func NewLogProvider(log *zap.Logger) *LogProvider {
+	return &LogProvider{log: log}
+}
+
+// Send logs the email and reports success
+func (p *LogProvider) Send(ctx context.Context, to, subject, body string, isHTML bool) error {
+	p.log.Info("Email not sent (log transport)",
+		zap.String("to", to),
+		zap.String("subject", subject),
+		zap.Bool("html", isHTML),
+		zap.Int("bodyLength", len(body)),
+	)
+	return nil
+}
+
+// SendWithAttachment logs the email and its attachment and reports success
+func (p *LogProvider) SendWithAttachment(ctx context.Context, to, subject, body string, isHTML bool, attachmentName string, attachmentData []byte) error {
+	p.log.Info("Email not sent (log transport)",
+		zap.String("to", to),
+		zap.String("subject", subject),
+		zap.Bool("html", isHTML),
+		zap.Int("bodyLength", len(body)),
+		zap.String("attachment", attachmentName),
+		zap.Int("attachmentSize", len(attachmentData)),
+	)
+	return nil
+}
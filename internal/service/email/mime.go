@@ -0,0 +1,86 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename string
+	Data     []byte
+}
+
+// contentType returns the MIME type for the attachment's file extension
+func (a *Attachment) contentType() string {
+	if ct := mime.TypeByExtension(filepath.Ext(a.Filename)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// buildMIMEMessage builds an RFC 5322 message. Without an attachment the body
+// is a single part; with one it becomes multipart/mixed.
+func buildMIMEMessage(from, to, subject, body string, isHTML bool, attachment *Attachment) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	bodyType := "text/plain; charset=UTF-8"
+	if isHTML {
+		bodyType = "text/html; charset=UTF-8"
+	}
+
+	if attachment == nil {
+		fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", bodyType)
+		msg.WriteString(body)
+		return msg.Bytes()
+	}
+
+	boundary := newBoundary()
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&msg, "--%s\r\n", boundary)
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", bodyType)
+	msg.WriteString(body)
+	msg.WriteString("\r\n")
+
+	fmt.Fprintf(&msg, "--%s\r\n", boundary)
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n", attachment.contentType())
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n")
+	fmt.Fprintf(&msg, "Content-Disposition: attachment; filename=%q\r\n\r\n", attachment.Filename)
+	writeBase64Lines(&msg, attachment.Data)
+	fmt.Fprintf(&msg, "--%s--\r\n", boundary)
+
+	return msg.Bytes()
+}
+
+// writeBase64Lines writes data as base64 wrapped at 76 characters per line
+func writeBase64Lines(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	if encoded != "" {
+		buf.WriteString(encoded)
+		buf.WriteString("\r\n")
+	}
+}
+
+func newBoundary() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strings.Repeat("sigecve", 4)
+	}
+	return "sigecve-" + hex.EncodeToString(b)
+}
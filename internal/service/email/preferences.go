@@ -0,0 +1,43 @@
+package email
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetPreferenceRepository makes notification emails honor the user's
+// preferences. Account and security emails (welcome, password reset,
+// invoices) are always sent.
+func (s *Service) SetPreferenceRepository(repo ports.NotificationPreferenceRepository) {
+	s.prefs = repo
+}
+
+// suppressed reports whether the user turned off email, or eventType,
+// in their notification preferences. A failed lookup sends the email.
+func (s *Service) suppressed(ctx context.Context, user *domain.User, eventType string) bool {
+	if s.prefs == nil || user == nil {
+		return false
+	}
+
+	prefs, err := s.prefs.FindByUserID(ctx, user.ID)
+	if err != nil {
+		s.log.Warn("Failed to load notification preferences, sending anyway",
+			zap.String("user_id", user.ID),
+			zap.Error(err),
+		)
+		return false
+	}
+	if prefs == nil || (prefs.Email && prefs.Allows(eventType)) {
+		return false
+	}
+
+	s.log.Debug("Email suppressed by notification preferences",
+		zap.String("user_id", user.ID),
+		zap.String("type", eventType),
+	)
+	return true
+}
@@ -0,0 +1,88 @@
+package email
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+func newPreferenceTestService(t *testing.T, prefs *domain.NotificationPreferences) (*Service, *MockProvider) {
+	t.Helper()
+	provider := &MockProvider{}
+	service := newTestService(provider)
+	service.loadTemplates()
+
+	repo := memory.NewNotificationPreferenceRepository()
+	if prefs != nil {
+		if err := repo.Save(context.Background(), prefs); err != nil {
+			t.Fatalf("failed to save preferences: %v", err)
+		}
+	}
+	service.SetPreferenceRepository(repo)
+	return service, provider
+}
+
+func TestService_NotificationEmailsHonorPreferences(t *testing.T) {
+	user := &domain.User{ID: "user-123", Name: "John Doe", Email: "john@example.com"}
+	tx := &domain.Transaction{ID: "tx-123", StartTime: time.Now()}
+
+	tests := []struct {
+		name          string
+		prefs         *domain.NotificationPreferences
+		expectedSent  int
+		expectedCause string
+	}{
+		{"no stored preferences", nil, 1, "defaults send"},
+		{"event disabled", &domain.NotificationPreferences{
+			UserID: user.ID, Email: true, DisabledEvents: []string{domain.NotificationChargingStarted},
+		}, 0, "charging.started turned off"},
+		{"other event disabled", &domain.NotificationPreferences{
+			UserID: user.ID, Email: true, DisabledEvents: []string{domain.NotificationLowBalance},
+		}, 1, "only balance.low turned off"},
+		{"email turned off", &domain.NotificationPreferences{UserID: user.ID, Email: false, Push: true}, 0, "email channel off"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, provider := newPreferenceTestService(t, tt.prefs)
+
+			// Act
+			err := service.SendChargingStarted(context.Background(), user, tx, nil)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(provider.SentEmails) != tt.expectedSent {
+				t.Errorf("expected %d emails (%s), got %d", tt.expectedSent, tt.expectedCause, len(provider.SentEmails))
+			}
+		})
+	}
+}
+
+func TestService_SecurityEmailsIgnorePreferences(t *testing.T) {
+	// Arrange - a user who turned email off entirely
+	user := &domain.User{ID: "user-123", Name: "John Doe", Email: "john@example.com"}
+	service, provider := newPreferenceTestService(t, &domain.NotificationPreferences{
+		UserID: user.ID, Email: false, DisabledEvents: domain.NotificationTypes(),
+	})
+
+	// Act
+	errReset := service.SendPasswordReset(context.Background(), user, "abc123xyz")
+	errBalance := service.SendLowBalance(context.Background(), user, 3.5)
+
+	// Assert
+	if errReset != nil || errBalance != nil {
+		t.Fatalf("expected no errors, got %v, %v", errReset, errBalance)
+	}
+	if len(provider.SentEmails) != 1 {
+		t.Fatalf("expected only the password reset email, got %d", len(provider.SentEmails))
+	}
+	if provider.SentEmails[0].To != user.Email {
+		t.Errorf("expected the reset email to go to %s, got %s", user.Email, provider.SentEmails[0].To)
+	}
+}
@@ -0,0 +1,113 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 500 * time.Millisecond
+)
+
+// TransientError marks a send failure that may succeed when retried, such as
+// a rate limit or a 5xx response from the provider
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string { return e.Err.Error() }
+
+func (e *TransientError) Unwrap() error { return e.Err }
+
+// IsTransient reports whether a send error is worth retrying. Network errors
+// and SMTP 4xx replies are transient; everything else is treated as permanent.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var transient *TransientError
+	if errors.As(err, &transient) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+
+	return false
+}
+
+// deliver sends through the provider, retrying transient failures with
+// exponential backoff
+func (s *Service) deliver(ctx context.Context, to, subject, body string, isHTML bool) error {
+	return s.retry(ctx, to, func() error {
+		return s.provider.Send(ctx, to, subject, body, isHTML)
+	})
+}
+
+// deliverWithAttachment is deliver for an HTML message with an attachment.
+// Providers that cannot attach files get the message alone.
+func (s *Service) deliverWithAttachment(ctx context.Context, to, subject, htmlBody string, attachment *Attachment) error {
+	ap, ok := s.provider.(AttachmentProvider)
+	if !ok {
+		s.log.Warn("Email provider does not support attachments, sending without",
+			zap.String("to", to),
+			zap.String("attachment", attachment.Filename),
+		)
+		return s.deliver(ctx, to, subject, htmlBody, true)
+	}
+
+	return s.retry(ctx, to, func() error {
+		return ap.SendWithAttachment(ctx, to, subject, htmlBody, true, attachment.Filename, attachment.Data)
+	})
+}
+
+// retry calls send until it succeeds, fails permanently or runs out of attempts
+func (s *Service) retry(ctx context.Context, to string, send func() error) error {
+	maxRetries := s.config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+	delay := s.config.RetryBaseDelay
+	if delay <= 0 {
+		delay = defaultRetryBaseDelay
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = send()
+		if err == nil || !IsTransient(err) || attempt >= maxRetries {
+			break
+		}
+
+		s.log.Warn("Transient email failure, retrying",
+			zap.String("to", to),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", delay),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	return err
+}
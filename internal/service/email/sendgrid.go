@@ -2,7 +2,9 @@ package email
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"net/http"
 
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
@@ -45,7 +47,11 @@ func (p *SendGridProvider) Send(ctx context.Context, to, subject, body string, i
 
 	// SendGrid returns 2xx for success
 	if response.StatusCode >= 300 {
-		return fmt.Errorf("sendgrid returned status %d: %s", response.StatusCode, response.Body)
+		err := fmt.Errorf("sendgrid returned status %d: %s", response.StatusCode, response.Body)
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+			return &TransientError{Err: err}
+		}
+		return err
 	}
 
 	return nil
@@ -70,9 +76,10 @@ func (p *SendGridProvider) SendWithAttachment(ctx context.Context, to, subject,
 		message.AddContent(mail.NewContent("text/plain", body))
 	}
 
-	// Add attachment
+	// Add attachment (SendGrid expects base64 content)
 	attachment := mail.NewAttachment()
-	attachment.SetContent(string(attachmentData))
+	attachment.SetContent(base64.StdEncoding.EncodeToString(attachmentData))
+	attachment.SetType((&Attachment{Filename: attachmentName}).contentType())
 	attachment.SetFilename(attachmentName)
 	attachment.SetDisposition("attachment")
 	message.AddAttachment(attachment)
@@ -83,7 +90,11 @@ func (p *SendGridProvider) SendWithAttachment(ctx context.Context, to, subject,
 	}
 
 	if response.StatusCode >= 300 {
-		return fmt.Errorf("sendgrid returned status %d: %s", response.StatusCode, response.Body)
+		err := fmt.Errorf("sendgrid returned status %d: %s", response.StatusCode, response.Body)
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+			return &TransientError{Err: err}
+		}
+		return err
 	}
 
 	return nil
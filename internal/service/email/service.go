@@ -3,13 +3,18 @@ package email
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/internal/service/invoice"
+	"github.com/seu-repo/sigec-ve/pkg/config"
 )
 
 // Provider defines the interface for email providers
@@ -17,9 +22,18 @@ type Provider interface {
 	Send(ctx context.Context, to, subject, body string, isHTML bool) error
 }
 
+// AttachmentProvider is implemented by providers that can send a file along
+// with the message
+type AttachmentProvider interface {
+	SendWithAttachment(ctx context.Context, to, subject, body string, isHTML bool, attachmentName string, attachmentData []byte) error
+}
+
+// ErrTemplateNotFound is returned by SendTemplate for unknown template names
+var ErrTemplateNotFound = errors.New("template not found")
+
 // Config holds email service configuration
 type Config struct {
-	// Provider type: "sendgrid" or "smtp"
+	// Provider type: "sendgrid", "ses", "smtp" or "log" (alias "noop") for development
 	Provider string
 
 	// From email address
@@ -36,6 +50,16 @@ type Config struct {
 	SMTPPassword string
 	SMTPUseTLS   bool
 
+	// AWS SES configuration
+	SESRegion          string
+	SESAccessKeyID     string
+	SESSecretAccessKey string
+	SESEndpoint        string // Optional, defaults to the regional endpoint
+
+	// Retry configuration for transient send failures
+	MaxRetries     int           // Defaults to 3
+	RetryBaseDelay time.Duration // Doubled after each attempt, defaults to 500ms
+
 	// Template configuration
 	TemplateDir string
 	BaseURL     string // Base URL for links in emails
@@ -54,11 +78,40 @@ func DefaultConfig() *Config {
 	}
 }
 
+// ConfigFromSettings builds the service configuration from the application
+// config. Without a base URL, links point at the development web app.
+func ConfigFromSettings(settings config.EmailConfig) *Config {
+	baseURL := settings.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultConfig().BaseURL
+	}
+
+	return &Config{
+		Provider:           settings.Provider,
+		FromEmail:          settings.From,
+		FromName:           settings.FromName,
+		SendGridAPIKey:     settings.APIKey,
+		SMTPHost:           settings.SMTP.Host,
+		SMTPPort:           settings.SMTP.Port,
+		SMTPUsername:       settings.SMTP.Username,
+		SMTPPassword:       settings.SMTP.Password,
+		SMTPUseTLS:         settings.SMTP.UseTLS,
+		SESRegion:          settings.SES.Region,
+		SESAccessKeyID:     settings.SES.AccessKeyID,
+		SESSecretAccessKey: settings.SES.SecretAccessKey,
+		SESEndpoint:        settings.SES.Endpoint,
+		MaxRetries:         settings.MaxRetries,
+		RetryBaseDelay:     settings.RetryBaseDelay,
+		BaseURL:            baseURL,
+	}
+}
+
 // Service implements the EmailService interface
 type Service struct {
 	config    *Config
 	provider  Provider
 	templates map[string]*template.Template
+	prefs     ports.NotificationPreferenceRepository // optional, see SetPreferenceRepository
 	log       *zap.Logger
 }
 
@@ -91,6 +144,20 @@ func NewService(config *Config, log *zap.Logger) (*Service, error) {
 			config.FromName,
 			config.SMTPUseTLS,
 		)
+	case "ses":
+		if config.SESRegion == "" || config.SESAccessKeyID == "" || config.SESSecretAccessKey == "" {
+			return nil, fmt.Errorf("SES region and credentials are required")
+		}
+		s.provider = NewSESProvider(
+			config.SESRegion,
+			config.SESAccessKeyID,
+			config.SESSecretAccessKey,
+			config.SESEndpoint,
+			config.FromEmail,
+			config.FromName,
+		)
+	case "log", "noop":
+		s.provider = NewLogProvider(log)
 	default:
 		return nil, fmt.Errorf("unknown email provider: %s", config.Provider)
 	}
@@ -101,14 +168,99 @@ func NewService(config *Config, log *zap.Logger) (*Service, error) {
 	return s, nil
 }
 
-// loadTemplates loads all email templates
+// loadTemplates loads all email templates. English templates are stored under
+// their bare name and localized ones under "<lang>/<name>". Each set formats
+// amounts the way its language writes them, see formatFuncs. Templates fail
+// to render when the data map lacks a key they reference instead of printing
+// "<no value>".
 func (s *Service) loadTemplates() {
-	s.templates["welcome"] = template.Must(template.New("welcome").Parse(welcomeTemplate))
-	s.templates["charging_started"] = template.Must(template.New("charging_started").Parse(chargingStartedTemplate))
-	s.templates["charging_completed"] = template.Must(template.New("charging_completed").Parse(chargingCompletedTemplate))
-	s.templates["password_reset"] = template.Must(template.New("password_reset").Parse(passwordResetTemplate))
-	s.templates["invoice"] = template.Must(template.New("invoice").Parse(invoiceTemplate))
-	s.templates["low_balance"] = template.Must(template.New("low_balance").Parse(lowBalanceTemplate))
+	templates := map[string]string{
+		"welcome":            welcomeTemplate,
+		"charging_started":   chargingStartedTemplate,
+		"charging_completed": chargingCompletedTemplate,
+		"password_reset":     passwordResetTemplate,
+		"invoice":            invoiceTemplate,
+		"low_balance":        lowBalanceTemplate,
+	}
+	for name, text := range templates {
+		s.templates[name] = template.Must(template.New(name).Funcs(formatFuncs(domain.LanguageEnglish)).Option("missingkey=error").Parse(text))
+	}
+
+	localized := map[string]map[string]string{
+		domain.LanguagePortugueseBR: {
+			"welcome":            welcomeTemplatePtBR,
+			"charging_started":   chargingStartedTemplatePtBR,
+			"charging_completed": chargingCompletedTemplatePtBR,
+			"password_reset":     passwordResetTemplatePtBR,
+			"invoice":            invoiceTemplatePtBR,
+			"low_balance":        lowBalanceTemplatePtBR,
+		},
+	}
+	for lang, set := range localized {
+		for name, text := range set {
+			key := localizedKey(lang, name)
+			s.templates[key] = template.Must(template.New(key).Funcs(formatFuncs(lang)).Option("missingkey=error").Parse(text))
+		}
+	}
+}
+
+// subjects holds the subject lines used by the Send* helpers, per language
+var subjects = map[string]map[string]string{
+	domain.LanguageEnglish: {
+		"welcome":            "Welcome to SIGEC-VE!",
+		"charging_started":   "Charging Session Started",
+		"charging_completed": "Charging Session Completed",
+		"password_reset":     "Reset Your Password",
+		"invoice":            "Invoice #%s",
+		"low_balance":        "Low Balance Warning",
+		"default":            "Notification from SIGEC-VE",
+	},
+	domain.LanguagePortugueseBR: {
+		"welcome":            "Bem-vindo(a) ao SIGEC-VE!",
+		"charging_started":   "Sessão de Recarga Iniciada",
+		"charging_completed": "Sessão de Recarga Concluída",
+		"password_reset":     "Redefinição de Senha",
+		"invoice":            "Fatura nº %s",
+		"low_balance":        "Aviso de Saldo Baixo",
+		"default":            "Notificação do SIGEC-VE",
+	},
+}
+
+func localizedKey(lang, name string) string {
+	return lang + "/" + name
+}
+
+// normalizeLanguage maps a user's language preference onto a supported tag.
+// Empty values select the platform default (pt-BR); unknown ones are kept so
+// lookups fall back to English.
+func normalizeLanguage(lang string) string {
+	lang = strings.TrimSpace(lang)
+	switch {
+	case lang == "":
+		return domain.DefaultLanguage
+	case strings.EqualFold(lang, "pt") || strings.EqualFold(lang, domain.LanguagePortugueseBR) || strings.EqualFold(lang, "pt_BR"):
+		return domain.LanguagePortugueseBR
+	case strings.EqualFold(lang, domain.LanguageEnglish) || strings.HasPrefix(strings.ToLower(lang), "en-"):
+		return domain.LanguageEnglish
+	}
+	return lang
+}
+
+// languageOf returns the normalized language of a user
+func languageOf(user *domain.User) string {
+	return normalizeLanguage(user.Language)
+}
+
+// subject returns the localized subject for a template, falling back to English
+func subject(lang, name string, args ...interface{}) string {
+	text, ok := subjects[lang][name]
+	if !ok {
+		text = subjects[domain.LanguageEnglish][name]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
 }
 
 // Send sends a generic email
@@ -118,7 +270,7 @@ func (s *Service) Send(ctx context.Context, to, subject, body string) error {
 		zap.String("subject", subject),
 	)
 
-	if err := s.provider.Send(ctx, to, subject, body, false); err != nil {
+	if err := s.deliver(ctx, to, subject, body, false); err != nil {
 		s.log.Error("Failed to send email",
 			zap.String("to", to),
 			zap.Error(err),
@@ -136,7 +288,7 @@ func (s *Service) SendHTML(ctx context.Context, to, subject, htmlBody string) er
 		zap.String("subject", subject),
 	)
 
-	if err := s.provider.Send(ctx, to, subject, htmlBody, true); err != nil {
+	if err := s.deliver(ctx, to, subject, htmlBody, true); err != nil {
 		s.log.Error("Failed to send HTML email",
 			zap.String("to", to),
 			zap.Error(err),
@@ -147,36 +299,92 @@ func (s *Service) SendHTML(ctx context.Context, to, subject, htmlBody string) er
 	return nil
 }
 
-// SendTemplate sends an email using a template
+// SendHTMLWithAttachment sends an HTML email with an attachment. Providers
+// without attachment support send the message alone.
+func (s *Service) SendHTMLWithAttachment(ctx context.Context, to, subject, htmlBody string, attachment *Attachment) error {
+	s.log.Info("Sending HTML email with attachment",
+		zap.String("to", to),
+		zap.String("subject", subject),
+		zap.String("attachment", attachment.Filename),
+	)
+
+	if err := s.deliverWithAttachment(ctx, to, subject, htmlBody, attachment); err != nil {
+		s.log.Error("Failed to send HTML email with attachment",
+			zap.String("to", to),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to send HTML email: %w", err)
+	}
+
+	return nil
+}
+
+// SendTemplate sends an email using a template. The template set is picked
+// from data["Language"] (default pt-BR), falling back to English when no
+// localized version exists.
 func (s *Service) SendTemplate(ctx context.Context, to, templateName string, data map[string]interface{}) error {
-	tmpl, ok := s.templates[templateName]
+	return s.sendTemplate(ctx, to, templateName, data, nil)
+}
+
+// sendTemplate renders a template and sends it, with an optional attachment
+func (s *Service) sendTemplate(ctx context.Context, to, templateName string, data map[string]interface{}, attachment *Attachment) error {
+	lang, _ := data["Language"].(string)
+	lang = normalizeLanguage(lang)
+
+	body, err := s.renderTemplate(s.resolveTemplate(templateName, lang), data)
+	if err != nil {
+		return err
+	}
+
+	subj, ok := data["Subject"].(string)
 	if !ok {
-		return fmt.Errorf("template not found: %s", templateName)
+		subj = subject(lang, "default")
 	}
 
-	// Add base URL to data
-	if data == nil {
-		data = make(map[string]interface{})
+	if attachment != nil {
+		return s.SendHTMLWithAttachment(ctx, to, subj, body, attachment)
 	}
-	data["BaseURL"] = s.config.BaseURL
+	return s.SendHTML(ctx, to, subj, body)
+}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+// resolveTemplate returns the key of the localized template if one is
+// loaded, otherwise the English template name
+func (s *Service) resolveTemplate(templateName, lang string) string {
+	if lang != domain.LanguageEnglish {
+		if key := localizedKey(lang, templateName); s.templates[key] != nil {
+			return key
+		}
 	}
+	return templateName
+}
 
-	subject, ok := data["Subject"].(string)
+// renderTemplate executes a named template with data plus BaseURL. The
+// caller's map is not modified.
+func (s *Service) renderTemplate(templateName string, data map[string]interface{}) (string, error) {
+	tmpl, ok := s.templates[templateName]
 	if !ok {
-		subject = "Notification from SIGEC-VE"
+		return "", fmt.Errorf("%w: %s", ErrTemplateNotFound, templateName)
 	}
 
-	return s.SendHTML(ctx, to, subject, buf.String())
+	values := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		values[k] = v
+	}
+	values["BaseURL"] = s.config.BaseURL
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
+	}
+	return buf.String(), nil
 }
 
 // SendWelcome sends a welcome email to a new user
 func (s *Service) SendWelcome(ctx context.Context, user *domain.User) error {
+	lang := languageOf(user)
 	data := map[string]interface{}{
-		"Subject":  "Welcome to SIGEC-VE!",
+		"Subject":  subject(lang, "welcome"),
+		"Language": lang,
 		"UserName": user.Name,
 		"Email":    user.Email,
 	}
@@ -186,13 +394,19 @@ func (s *Service) SendWelcome(ctx context.Context, user *domain.User) error {
 
 // SendChargingStarted sends a notification when charging starts
 func (s *Service) SendChargingStarted(ctx context.Context, user *domain.User, tx *domain.Transaction, station *domain.ChargePoint) error {
+	if s.suppressed(ctx, user, domain.NotificationChargingStarted) {
+		return nil
+	}
+
 	stationName := ""
 	if station != nil {
 		stationName = fmt.Sprintf("%s %s", station.Vendor, station.Model)
 	}
 
+	lang := languageOf(user)
 	data := map[string]interface{}{
-		"Subject":       "Charging Session Started",
+		"Subject":       subject(lang, "charging_started"),
+		"Language":      lang,
 		"UserName":      user.Name,
 		"TransactionID": tx.ID,
 		"StationName":   stationName,
@@ -204,6 +418,10 @@ func (s *Service) SendChargingStarted(ctx context.Context, user *domain.User, tx
 
 // SendChargingCompleted sends a notification when charging completes
 func (s *Service) SendChargingCompleted(ctx context.Context, user *domain.User, tx *domain.Transaction, cost float64) error {
+	if s.suppressed(ctx, user, domain.NotificationChargingCompleted) {
+		return nil
+	}
+
 	duration := ""
 	if tx.EndTime != nil {
 		dur := tx.EndTime.Sub(tx.StartTime)
@@ -216,25 +434,30 @@ func (s *Service) SendChargingCompleted(ctx context.Context, user *domain.User,
 		}
 	}
 
+	lang := languageOf(user)
 	data := map[string]interface{}{
-		"Subject":       "Charging Session Completed",
+		"Subject":       subject(lang, "charging_completed"),
+		"Language":      lang,
 		"UserName":      user.Name,
 		"TransactionID": tx.ID,
-		"EnergyKWh":     fmt.Sprintf("%.2f", tx.MeterStop-tx.MeterStart),
+		"EnergyKWh":     float64(tx.MeterStop-tx.MeterStart) / 1000.0, // Wh to kWh
 		"Duration":      duration,
-		"Cost":          fmt.Sprintf("%.2f", cost),
+		"Cost":          cost,
 		"Currency":      "BRL",
 	}
 
 	return s.SendTemplate(ctx, user.Email, "charging_completed", data)
 }
 
-// SendPasswordReset sends a password reset email
+// SendPasswordReset sends a password reset email. Security emails ignore
+// notification preferences.
 func (s *Service) SendPasswordReset(ctx context.Context, user *domain.User, resetToken string) error {
 	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.config.BaseURL, resetToken)
 
+	lang := languageOf(user)
 	data := map[string]interface{}{
-		"Subject":  "Reset Your Password",
+		"Subject":  subject(lang, "password_reset"),
+		"Language": lang,
 		"UserName": user.Name,
 		"ResetURL": resetURL,
 	}
@@ -242,30 +465,60 @@ func (s *Service) SendPasswordReset(ctx context.Context, user *domain.User, rese
 	return s.SendTemplate(ctx, user.Email, "password_reset", data)
 }
 
-// SendInvoice sends an invoice email
-func (s *Service) SendInvoice(ctx context.Context, user *domain.User, invoice *ports.Invoice) error {
+// SendInvoice sends an invoice email with the invoice attached as a PDF
+func (s *Service) SendInvoice(ctx context.Context, user *domain.User, inv *ports.Invoice) error {
+	lang := languageOf(user)
+	var taxLines []map[string]interface{}
+	if inv.ICMS != 0 || inv.PIS != 0 || inv.COFINS != 0 {
+		taxLines = []map[string]interface{}{
+			{"Name": "ICMS", "Amount": inv.ICMS},
+			{"Name": "PIS", "Amount": inv.PIS},
+			{"Name": "COFINS", "Amount": inv.COFINS},
+		}
+	}
 	data := map[string]interface{}{
-		"Subject":       fmt.Sprintf("Invoice #%s", invoice.ID),
+		"Subject":       subject(lang, "invoice", inv.ID),
+		"Language":      lang,
 		"UserName":      user.Name,
-		"InvoiceID":     invoice.ID,
-		"TransactionID": invoice.TransactionID,
-		"Amount":        fmt.Sprintf("%.2f", invoice.Amount),
-		"Currency":      invoice.Currency,
-		"EnergyKWh":     fmt.Sprintf("%.2f", invoice.EnergyKWh),
-		"Duration":      invoice.Duration,
-		"StationName":   invoice.StationName,
-		"Date":          invoice.Date,
+		"InvoiceID":     inv.ID,
+		"TransactionID": inv.TransactionID,
+		"Amount":        inv.Amount,
+		"Currency":      inv.Currency,
+		"EnergyKWh":     inv.EnergyKWh,
+		"Duration":      inv.Duration,
+		"StationName":   inv.StationName,
+		"Date":          inv.Date,
+		"TaxLines":      taxLines,
 	}
 
-	return s.SendTemplate(ctx, user.Email, "invoice", data)
+	document := *inv
+	if document.CustomerName == "" {
+		document.CustomerName = user.Name
+	}
+	if document.CustomerTaxID == "" {
+		document.CustomerTaxID = user.Document
+	}
+	pdf, err := invoice.BuildPDF(&document)
+	if err != nil {
+		return fmt.Errorf("failed to build invoice PDF: %w", err)
+	}
+
+	attachment := &Attachment{Filename: fmt.Sprintf("invoice-%s.pdf", inv.ID), Data: pdf}
+	return s.sendTemplate(ctx, user.Email, "invoice", data, attachment)
 }
 
 // SendLowBalance sends a low balance warning
 func (s *Service) SendLowBalance(ctx context.Context, user *domain.User, balance float64) error {
+	if s.suppressed(ctx, user, domain.NotificationLowBalance) {
+		return nil
+	}
+
+	lang := languageOf(user)
 	data := map[string]interface{}{
-		"Subject":  "Low Balance Warning",
+		"Subject":  subject(lang, "low_balance"),
+		"Language": lang,
 		"UserName": user.Name,
-		"Balance":  fmt.Sprintf("%.2f", balance),
+		"Balance":  balance,
 		"Currency": "BRL",
 	}
 
@@ -2,8 +2,12 @@ package email
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
 	"strings"
 	"testing"
 	"time"
@@ -12,6 +16,7 @@ import (
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/pkg/config"
 )
 
 // MockProvider is a mock email provider for testing
@@ -45,6 +50,20 @@ func (m *MockProvider) Send(ctx context.Context, to, subject, body string, isHTM
 	return nil
 }
 
+// AttachmentMockProvider is a mock provider that also records attachments
+type AttachmentMockProvider struct {
+	MockProvider
+	Attachments []Attachment
+}
+
+func (m *AttachmentMockProvider) SendWithAttachment(ctx context.Context, to, subject, body string, isHTML bool, attachmentName string, attachmentData []byte) error {
+	if err := m.Send(ctx, to, subject, body, isHTML); err != nil {
+		return err
+	}
+	m.Attachments = append(m.Attachments, Attachment{Filename: attachmentName, Data: attachmentData})
+	return nil
+}
+
 func newTestLogger() *zap.Logger {
 	logger, _ := zap.NewDevelopment()
 	return logger
@@ -147,9 +166,10 @@ func TestService_SendWelcome_Success(t *testing.T) {
 	service.loadTemplates()
 
 	user := &domain.User{
-		ID:    "user-123",
-		Name:  "John Doe",
-		Email: "john@example.com",
+		ID:       "user-123",
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Language: domain.LanguageEnglish,
 	}
 
 	// Act
@@ -174,6 +194,111 @@ func TestService_SendWelcome_Success(t *testing.T) {
 	}
 }
 
+func TestService_SendWelcome_PortugueseUser(t *testing.T) {
+	// Arrange
+	mockProvider := &MockProvider{}
+	service := newTestService(mockProvider)
+	service.loadTemplates()
+
+	user := &domain.User{
+		ID:       "user-123",
+		Name:     "João Silva",
+		Email:    "joao@example.com",
+		Language: domain.LanguagePortugueseBR,
+	}
+
+	// Act
+	err := service.SendWelcome(context.Background(), user)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(mockProvider.SentEmails) != 1 {
+		t.Fatalf("expected 1 email sent, got %d", len(mockProvider.SentEmails))
+	}
+	email := mockProvider.SentEmails[0]
+	if email.Subject != "Bem-vindo(a) ao SIGEC-VE!" {
+		t.Errorf("expected pt-BR subject, got '%s'", email.Subject)
+	}
+	if !strings.Contains(email.Body, "Bem-vindo(a), João Silva!") {
+		t.Error("expected body to contain the pt-BR welcome message")
+	}
+	if strings.Contains(email.Body, "Thank you for joining") {
+		t.Error("expected body not to contain the English welcome message")
+	}
+}
+
+func TestService_SendWelcome_DefaultsToPortuguese(t *testing.T) {
+	// Arrange
+	mockProvider := &MockProvider{}
+	service := newTestService(mockProvider)
+	service.loadTemplates()
+
+	user := &domain.User{ID: "user-123", Name: "John Doe", Email: "john@example.com"}
+
+	// Act
+	err := service.SendWelcome(context.Background(), user)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(mockProvider.SentEmails[0].Body, "Bem-vindo(a)") {
+		t.Error("expected users without a language to get the pt-BR template")
+	}
+}
+
+func TestService_SendTemplate_FallsBackToEnglish(t *testing.T) {
+	// Arrange
+	mockProvider := &MockProvider{}
+	service := newTestService(mockProvider)
+	service.loadTemplates()
+	delete(service.templates, localizedKey(domain.LanguagePortugueseBR, "welcome"))
+
+	user := &domain.User{
+		ID:       "user-123",
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Language: domain.LanguagePortugueseBR,
+	}
+
+	// Act
+	err := service.SendWelcome(context.Background(), user)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(mockProvider.SentEmails[0].Body, "Welcome, John Doe!") {
+		t.Error("expected the English template when no pt-BR version is loaded")
+	}
+}
+
+func TestService_SendTemplate_UnsupportedLanguageUsesEnglish(t *testing.T) {
+	// Arrange
+	mockProvider := &MockProvider{}
+	service := newTestService(mockProvider)
+	service.loadTemplates()
+
+	user := &domain.User{ID: "user-123", Name: "John Doe", Email: "john@example.com", Language: "de-DE"}
+
+	// Act
+	err := service.SendLowBalance(context.Background(), user, 15.00)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	email := mockProvider.SentEmails[0]
+	if email.Subject != "Low Balance Warning" {
+		t.Errorf("expected English subject, got '%s'", email.Subject)
+	}
+	if !strings.Contains(email.Body, "Your Balance is Running Low") {
+		t.Error("expected the English template for an unsupported language")
+	}
+}
+
 func TestService_SendChargingStarted_Success(t *testing.T) {
 	// Arrange
 	mockProvider := &MockProvider{}
@@ -230,8 +355,8 @@ func TestService_SendChargingCompleted_Success(t *testing.T) {
 		ID:         "tx-123",
 		StartTime:  endTime.Add(-90 * time.Minute),
 		EndTime:    &endTime,
-		MeterStart: 1000,
-		MeterStop:  1025.5,
+		MeterStart: 1000,  // Wh
+		MeterStop:  26500, // Wh
 	}
 
 	// Act
@@ -245,10 +370,10 @@ func TestService_SendChargingCompleted_Success(t *testing.T) {
 		t.Fatalf("expected 1 email sent, got %d", len(mockProvider.SentEmails))
 	}
 	email := mockProvider.SentEmails[0]
-	if !strings.Contains(email.Body, "25.50") {
+	if !strings.Contains(email.Body, "25,50") {
 		t.Error("expected body to contain energy delivered")
 	}
-	if !strings.Contains(email.Body, "45.50") {
+	if !strings.Contains(email.Body, "R$ 45,50") {
 		t.Error("expected body to contain cost")
 	}
 }
@@ -318,11 +443,106 @@ func TestService_SendInvoice_Success(t *testing.T) {
 	if !strings.Contains(email.Body, "inv-123") {
 		t.Error("expected body to contain invoice ID")
 	}
-	if !strings.Contains(email.Body, "45.50") {
+	if !strings.Contains(email.Body, "R$ 45,50") {
 		t.Error("expected body to contain amount")
 	}
 }
 
+func TestService_SendInvoice_TaxBreakdown(t *testing.T) {
+	// Arrange
+	mockProvider := &MockProvider{}
+	service := newTestService(mockProvider)
+	service.loadTemplates()
+
+	user := &domain.User{ID: "user-123", Name: "John Doe", Email: "john@example.com"}
+	invoice := &ports.Invoice{
+		ID:            "inv-123",
+		TransactionID: "tx-123",
+		Amount:        100,
+		Currency:      "BRL",
+		Taxes:         25.58,
+		ICMS:          18,
+		PIS:           1.35,
+		COFINS:        6.23,
+	}
+
+	// Act
+	err := service.SendInvoice(context.Background(), user, invoice)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	body := mockProvider.SentEmails[0].Body
+	for _, want := range []string{"ICMS", "R$ 18,00", "PIS", "R$ 1,35", "COFINS", "R$ 6,23"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected body to contain %q", want)
+		}
+	}
+}
+
+func TestService_SendInvoice_AttachesPDF(t *testing.T) {
+	// Arrange
+	provider := &AttachmentMockProvider{}
+	service := newTestService(&provider.MockProvider)
+	service.provider = provider
+	service.loadTemplates()
+
+	user := &domain.User{ID: "user-123", Name: "John Doe", Email: "john@example.com", Document: "123.456.789-00"}
+	invoice := &ports.Invoice{
+		ID:            "inv-123",
+		TransactionID: "tx-123",
+		Amount:        45.50,
+		Currency:      "BRL",
+		EnergyKWh:     25.5,
+		Date:          "2024-01-15",
+	}
+
+	// Act
+	err := service.SendInvoice(context.Background(), user, invoice)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(provider.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(provider.Attachments))
+	}
+	pdf := provider.Attachments[0]
+	if pdf.Filename != "invoice-inv-123.pdf" {
+		t.Errorf("expected filename 'invoice-inv-123.pdf', got '%s'", pdf.Filename)
+	}
+	if !strings.HasPrefix(string(pdf.Data), "%PDF-") {
+		t.Error("expected attachment to be a PDF")
+	}
+	if !strings.Contains(string(pdf.Data), "R$ 45,50") {
+		t.Error("expected PDF to contain the invoice total")
+	}
+	if !strings.Contains(string(pdf.Data), "123.456.789-00") {
+		t.Error("expected PDF to carry the customer's CPF")
+	}
+}
+
+func TestBuildMIMEMessage_WithAttachment(t *testing.T) {
+	// Act
+	msg := string(buildMIMEMessage("SIGEC-VE <noreply@sigec-ve.com>", "john@example.com", "Fatura nº 1",
+		"<p>Hi</p>", true, &Attachment{Filename: "invoice.pdf", Data: []byte("%PDF-1.4 test")}))
+
+	// Assert
+	if !strings.Contains(msg, "Subject: =?UTF-8?q?Fatura_n=C2=BA_1?=") {
+		t.Errorf("expected encoded subject, got:\n%s", msg)
+	}
+	if !strings.Contains(msg, "Content-Type: multipart/mixed; boundary=") {
+		t.Error("expected multipart message")
+	}
+	if !strings.Contains(msg, "Content-Type: application/pdf") {
+		t.Error("expected PDF content type on the attachment")
+	}
+	if !strings.Contains(msg, "JVBERi0xLjQgdGVzdA==") {
+		t.Error("expected base64 encoded attachment data")
+	}
+}
+
 func TestService_SendLowBalance_Success(t *testing.T) {
 	// Arrange
 	mockProvider := &MockProvider{}
@@ -330,9 +550,10 @@ func TestService_SendLowBalance_Success(t *testing.T) {
 	service.loadTemplates()
 
 	user := &domain.User{
-		ID:    "user-123",
-		Name:  "John Doe",
-		Email: "john@example.com",
+		ID:       "user-123",
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Language: domain.LanguageEnglish,
 	}
 
 	// Act
@@ -455,3 +676,298 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("expected SMTP port 1025, got %d", config.SMTPPort)
 	}
 }
+
+// FlakyProvider fails with the given errors before succeeding
+type FlakyProvider struct {
+	Errors   []error
+	Attempts int
+}
+
+func (f *FlakyProvider) Send(ctx context.Context, to, subject, body string, isHTML bool) error {
+	f.Attempts++
+	if f.Attempts <= len(f.Errors) {
+		return f.Errors[f.Attempts-1]
+	}
+	return nil
+}
+
+func TestService_SendTemplate_MissingKey(t *testing.T) {
+	// Arrange
+	mockProvider := &MockProvider{}
+	service := newTestService(mockProvider)
+	service.loadTemplates()
+
+	// Act: charging_started also needs TransactionID, StationName and StartTime
+	err := service.SendTemplate(context.Background(), "john@example.com", "charging_started", map[string]interface{}{
+		"UserName": "John Doe",
+	})
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "TransactionID") {
+		t.Errorf("expected error to name the missing key, got '%s'", err.Error())
+	}
+	if len(mockProvider.SentEmails) != 0 {
+		t.Errorf("expected no email sent, got %d", len(mockProvider.SentEmails))
+	}
+}
+
+func TestService_SendTemplate_UnknownTemplate(t *testing.T) {
+	// Arrange
+	service := newTestService(&MockProvider{})
+	service.loadTemplates()
+
+	// Act
+	err := service.SendTemplate(context.Background(), "john@example.com", "does_not_exist", nil)
+
+	// Assert
+	if !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestService_RenderTemplate_ByName(t *testing.T) {
+	// Arrange
+	service := newTestService(&MockProvider{})
+	service.loadTemplates()
+	data := map[string]interface{}{
+		"UserName": "<b>Maria</b>",
+		"Balance":  "12.30",
+		"Currency": "BRL",
+	}
+
+	// Act
+	body, err := service.renderTemplate("low_balance", data)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(body, "12.30") || !strings.Contains(body, "http://localhost:3000") {
+		t.Error("expected body to contain balance and base URL")
+	}
+	if strings.Contains(body, "<b>Maria</b>") {
+		t.Error("expected user-provided values to be HTML escaped")
+	}
+	if _, ok := data["BaseURL"]; ok {
+		t.Error("expected caller data to be left untouched")
+	}
+}
+
+func TestNewService_TransportSelection(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		check  func(Provider) bool
+	}{
+		{"smtp", &Config{Provider: "smtp", SMTPHost: "localhost", SMTPPort: 1025}, func(p Provider) bool { _, ok := p.(*SMTPProvider); return ok }},
+		{"sendgrid", &Config{Provider: "sendgrid", SendGridAPIKey: "key"}, func(p Provider) bool { _, ok := p.(*SendGridProvider); return ok }},
+		{"ses", &Config{Provider: "ses", SESRegion: "sa-east-1", SESAccessKeyID: "AKID", SESSecretAccessKey: "secret"}, func(p Provider) bool { _, ok := p.(*SESProvider); return ok }},
+		{"log", &Config{Provider: "log"}, func(p Provider) bool { _, ok := p.(*LogProvider); return ok }},
+		{"noop", &Config{Provider: "noop"}, func(p Provider) bool { _, ok := p.(*LogProvider); return ok }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := NewService(tt.config, newTestLogger())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !tt.check(service.provider) {
+				t.Errorf("unexpected provider %T", service.provider)
+			}
+		})
+	}
+}
+
+func TestNewService_SESMissingCredentials(t *testing.T) {
+	// Act
+	_, err := NewService(&Config{Provider: "ses", SESRegion: "sa-east-1"}, newTestLogger())
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "credentials are required") {
+		t.Errorf("expected 'credentials are required' error, got '%s'", err.Error())
+	}
+}
+
+func TestConfigFromSettings(t *testing.T) {
+	// Arrange
+	settings := config.EmailConfig{
+		Provider:       "ses",
+		From:           "noreply@sigec-ve.com",
+		FromName:       "SIGEC-VE",
+		SES:            config.SESConfig{Region: "sa-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		MaxRetries:     5,
+		RetryBaseDelay: time.Second,
+		BaseURL:        "https://app.sigec-ve.com",
+	}
+
+	// Act
+	cfg := ConfigFromSettings(settings)
+
+	// Assert
+	if cfg.Provider != "ses" || cfg.SESRegion != "sa-east-1" || cfg.SESAccessKeyID != "AKID" {
+		t.Errorf("expected SES settings to be mapped, got %+v", cfg)
+	}
+	if cfg.MaxRetries != 5 || cfg.RetryBaseDelay != time.Second {
+		t.Errorf("expected retry settings to be mapped, got %d and %v", cfg.MaxRetries, cfg.RetryBaseDelay)
+	}
+	if cfg.BaseURL != "https://app.sigec-ve.com" {
+		t.Errorf("expected base URL, got '%s'", cfg.BaseURL)
+	}
+}
+
+func TestConfigFromSettings_DefaultsBaseURL(t *testing.T) {
+	// Act
+	cfg := ConfigFromSettings(config.EmailConfig{Provider: "log"})
+
+	// Assert
+	if cfg.BaseURL != DefaultConfig().BaseURL {
+		t.Errorf("expected the default base URL, got '%s'", cfg.BaseURL)
+	}
+}
+
+func TestService_Send_RetriesTransientFailures(t *testing.T) {
+	// Arrange
+	provider := &FlakyProvider{Errors: []error{
+		&TransientError{Err: errors.New("status 503")},
+		&textproto.Error{Code: 421, Msg: "try again later"},
+	}}
+	service := newTestService(&MockProvider{})
+	service.provider = provider
+	service.config.RetryBaseDelay = time.Millisecond
+
+	// Act
+	err := service.Send(context.Background(), "john@example.com", "Subject", "Body")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if provider.Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", provider.Attempts)
+	}
+}
+
+func TestService_Send_DoesNotRetryPermanentFailures(t *testing.T) {
+	// Arrange
+	provider := &FlakyProvider{Errors: []error{&textproto.Error{Code: 550, Msg: "mailbox unavailable"}}}
+	service := newTestService(&MockProvider{})
+	service.provider = provider
+	service.config.RetryBaseDelay = time.Millisecond
+
+	// Act
+	err := service.Send(context.Background(), "john@example.com", "Subject", "Body")
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if provider.Attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", provider.Attempts)
+	}
+}
+
+func TestService_Send_GivesUpAfterMaxRetries(t *testing.T) {
+	// Arrange
+	transient := &TransientError{Err: errors.New("status 503")}
+	provider := &FlakyProvider{Errors: []error{transient, transient, transient, transient}}
+	service := newTestService(&MockProvider{})
+	service.provider = provider
+	service.config.MaxRetries = 2
+	service.config.RetryBaseDelay = time.Millisecond
+
+	// Act
+	err := service.Send(context.Background(), "john@example.com", "Subject", "Body")
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if provider.Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", provider.Attempts)
+	}
+}
+
+func TestSESProvider_Send(t *testing.T) {
+	// Arrange
+	var gotAuth, gotDate string
+	var gotBody sesSendRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != sesSendPath {
+			t.Errorf("expected path %s, got %s", sesSendPath, r.URL.Path)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		gotDate = r.Header.Get("X-Amz-Date")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := NewSESProvider("sa-east-1", "AKID", "secret", server.URL, "noreply@sigec-ve.com", "SIGEC-VE")
+	provider.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	// Act
+	err := provider.Send(context.Background(), "john@example.com", "Hello", "<p>Hi</p>", true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if gotDate != "20261016T120000Z" {
+		t.Errorf("expected X-Amz-Date 20261016T120000Z, got '%s'", gotDate)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKID/20261016/sa-east-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=") {
+		t.Errorf("unexpected Authorization header '%s'", gotAuth)
+	}
+	if len(gotBody.Destination.ToAddresses) != 1 || gotBody.Destination.ToAddresses[0] != "john@example.com" {
+		t.Errorf("unexpected destination %+v", gotBody.Destination)
+	}
+	if gotBody.Content.Simple.Body.Html == nil || gotBody.Content.Simple.Body.Html.Data != "<p>Hi</p>" {
+		t.Error("expected HTML body")
+	}
+}
+
+func TestSESProvider_ThrottlingIsTransient(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := NewSESProvider("sa-east-1", "AKID", "secret", server.URL, "noreply@sigec-ve.com", "")
+
+	// Act
+	err := provider.Send(context.Background(), "john@example.com", "Hello", "Hi", false)
+
+	// Assert
+	if !IsTransient(err) {
+		t.Errorf("expected transient error, got %v", err)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("invalid recipient"), false},
+		{"marked transient", &TransientError{Err: errors.New("status 502")}, true},
+		{"smtp 4xx", &textproto.Error{Code: 451, Msg: "local error"}, true},
+		{"smtp 5xx", &textproto.Error{Code: 554, Msg: "rejected"}, false},
+		{"context canceled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
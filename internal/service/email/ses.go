@@ -0,0 +1,193 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"time"
+)
+
+const sesSendPath = "/v2/email/outbound-emails"
+
+// SESProvider implements the Provider interface using the AWS SES v2 HTTP API.
+// Requests are signed with AWS Signature Version 4.
+type SESProvider struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	endpoint        string
+	fromEmail       string
+	fromName        string
+	client          *http.Client
+	now             func() time.Time
+}
+
+// NewSESProvider creates a new SES provider. An empty endpoint selects the
+// regional AWS endpoint.
+func NewSESProvider(region, accessKeyID, secretAccessKey, endpoint, fromEmail, fromName string) *SESProvider {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", region)
+	}
+	return &SESProvider{
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		endpoint:        endpoint,
+		fromEmail:       fromEmail,
+		fromName:        fromName,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		now:             time.Now,
+	}
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesSendRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple *sesSimpleContent `json:"Simple,omitempty"`
+		Raw    *sesRawContent    `json:"Raw,omitempty"`
+	} `json:"Content"`
+}
+
+type sesSimpleContent struct {
+	Subject sesContent `json:"Subject"`
+	Body    struct {
+		Text *sesContent `json:"Text,omitempty"`
+		Html *sesContent `json:"Html,omitempty"`
+	} `json:"Body"`
+}
+
+// sesRawContent carries a complete MIME message; encoding/json base64-encodes it
+type sesRawContent struct {
+	Data []byte `json:"Data"`
+}
+
+// Send sends an email using SES
+func (p *SESProvider) Send(ctx context.Context, to, subject, body string, isHTML bool) error {
+	req := p.newRequest(to)
+	simple := &sesSimpleContent{Subject: sesContent{Data: subject, Charset: "UTF-8"}}
+	if isHTML {
+		simple.Body.Html = &sesContent{Data: body, Charset: "UTF-8"}
+	} else {
+		simple.Body.Text = &sesContent{Data: body, Charset: "UTF-8"}
+	}
+	req.Content.Simple = simple
+
+	return p.post(ctx, req)
+}
+
+// SendWithAttachment sends an email with a single attachment as raw MIME content
+func (p *SESProvider) SendWithAttachment(ctx context.Context, to, subject, body string, isHTML bool, attachmentName string, attachmentData []byte) error {
+	req := p.newRequest(to)
+	attachment := &Attachment{Filename: attachmentName, Data: attachmentData}
+	req.Content.Raw = &sesRawContent{
+		Data: buildMIMEMessage(req.FromEmailAddress, to, subject, body, isHTML, attachment),
+	}
+
+	return p.post(ctx, req)
+}
+
+func (p *SESProvider) newRequest(to string) *sesSendRequest {
+	req := &sesSendRequest{}
+	req.FromEmailAddress = (&mail.Address{Name: p.fromName, Address: p.fromEmail}).String()
+	req.Destination.ToAddresses = []string{to}
+	return req
+}
+
+// post signs and sends a SendEmail request
+func (p *SESProvider) post(ctx context.Context, req *sesSendRequest) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("ses marshal error: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+sesSendPath, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("ses request error: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	p.sign(httpReq, payload, p.now())
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("ses error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	err = fmt.Errorf("ses returned status %d: %s", resp.StatusCode, respBody)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return &TransientError{Err: err}
+	}
+	return err
+}
+
+// sign adds the X-Amz-Date and Authorization headers (SigV4, service "ses")
+func (p *SESProvider) sign(req *http.Request, payload []byte, t time.Time) {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	dateStamp := amzDate[:8]
+	host := req.URL.Host
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	const signedHeaders = "content-type;host;x-amz-date"
+	canonicalRequest := req.Method + "\n" +
+		canonicalPath(req.URL) + "\n" +
+		req.URL.RawQuery + "\n" +
+		"content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + host + "\n" +
+		"x-amz-date:" + amzDate + "\n" +
+		"\n" +
+		signedHeaders + "\n" +
+		sha256Hex(payload)
+
+	scope := dateStamp + "/" + p.region + "/ses/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+p.secretAccessKey), dateStamp)
+	key = hmacSHA256(key, p.region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func canonicalPath(u *url.URL) string {
+	if path := u.EscapedPath(); path != "" {
+		return path
+	}
+	return "/"
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
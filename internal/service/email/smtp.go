@@ -5,7 +5,6 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/smtp"
-	"strings"
 )
 
 // SMTPProvider implements the Provider interface using SMTP
@@ -35,35 +34,24 @@ func NewSMTPProvider(host string, port int, username, password, fromEmail, fromN
 
 // Send sends an email using SMTP
 func (p *SMTPProvider) Send(ctx context.Context, to, subject, body string, isHTML bool) error {
-	// Build email headers
-	headers := make(map[string]string)
-	headers["From"] = p.formatFrom()
-	headers["To"] = to
-	headers["Subject"] = subject
-	headers["MIME-Version"] = "1.0"
-
-	if isHTML {
-		headers["Content-Type"] = "text/html; charset=UTF-8"
-	} else {
-		headers["Content-Type"] = "text/plain; charset=UTF-8"
-	}
+	return p.send(to, buildMIMEMessage(p.formatFrom(), to, subject, body, isHTML, nil))
+}
 
-	// Build message
-	var message strings.Builder
-	for key, value := range headers {
-		message.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
-	}
-	message.WriteString("\r\n")
-	message.WriteString(body)
+// SendWithAttachment sends an email with a single attachment using SMTP
+func (p *SMTPProvider) SendWithAttachment(ctx context.Context, to, subject, body string, isHTML bool, attachmentName string, attachmentData []byte) error {
+	attachment := &Attachment{Filename: attachmentName, Data: attachmentData}
+	return p.send(to, buildMIMEMessage(p.formatFrom(), to, subject, body, isHTML, attachment))
+}
 
-	// Connect and send
+// send delivers a complete MIME message
+func (p *SMTPProvider) send(to string, message []byte) error {
 	addr := fmt.Sprintf("%s:%d", p.host, p.port)
 
 	if p.useTLS {
-		return p.sendTLS(addr, to, message.String())
+		return p.sendTLS(addr, to, string(message))
 	}
 
-	return p.sendPlain(addr, to, message.String())
+	return p.sendPlain(addr, to, string(message))
 }
 
 // sendPlain sends email without TLS (for Mailhog and local development)
@@ -261,7 +261,7 @@ const chargingCompletedTemplate = `
             </div>
             <div class="info-row">
                 <span class="info-label">Energy Delivered</span>
-                <span class="info-value">{{.EnergyKWh}} kWh</span>
+                <span class="info-value">{{number .EnergyKWh}} kWh</span>
             </div>
             <div class="info-row">
                 <span class="info-label">Duration</span>
@@ -271,7 +271,7 @@ const chargingCompletedTemplate = `
 
         <div class="total-box">
             <p style="margin: 0 0 5px 0; opacity: 0.9;">Total Cost</p>
-            <div class="total-amount">{{.Currency}} {{.Cost}}</div>
+            <div class="total-amount">{{money .Cost .Currency}}</div>
         </div>
 
         <p>Thank you for using SIGEC-VE!</p>
@@ -388,7 +388,7 @@ const invoiceTemplate = `
             </div>
             <div class="info-row">
                 <span class="info-label">Energy Delivered</span>
-                <span class="info-value">{{.EnergyKWh}} kWh</span>
+                <span class="info-value">{{number .EnergyKWh}} kWh</span>
             </div>
             <div class="info-row">
                 <span class="info-label">Duration</span>
@@ -397,9 +397,15 @@ const invoiceTemplate = `
         </div>
 
         <div class="total-box">
+            {{range .TaxLines}}
+            <div class="total-row">
+                <span>{{.Name}}</span>
+                <span>{{money .Amount $.Currency}}</span>
+            </div>
+            {{end}}
             <div class="total-row">
                 <span>Total Amount</span>
-                <span class="total-amount">{{.Currency}} {{.Amount}}</span>
+                <span class="total-amount">{{money .Amount .Currency}}</span>
             </div>
         </div>
 
@@ -444,10 +450,10 @@ const lowBalanceTemplate = `
 
         <div class="warning-box">
             <p style="margin: 0 0 10px 0; color: #92400e;">Current Balance</p>
-            <div class="balance">{{.Currency}} {{.Balance}}</div>
+            <div class="balance">{{money .Balance .Currency}}</div>
         </div>
 
-        <p>We recommend maintaining a minimum balance of R$ 50.00 to ensure uninterrupted charging sessions.</p>
+        <p>We recommend maintaining a minimum balance of R$50.00 to ensure uninterrupted charging sessions.</p>
 
         <p style="text-align: center;">
             <a href="{{.BaseURL}}/wallet/add-funds" class="button">Add Funds</a>
@@ -0,0 +1,358 @@
+package email
+
+// Brazilian Portuguese (pt-BR) versions of the email templates. They take the
+// same data keys as their English counterparts in templates.go.
+
+const welcomeTemplatePtBR = `
+<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #2563eb, #1d4ed8); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { background: #ffffff; padding: 30px; border: 1px solid #e5e7eb; border-top: none; }
+        .footer { background: #f9fafb; padding: 20px; text-align: center; font-size: 12px; color: #6b7280; border: 1px solid #e5e7eb; border-top: none; border-radius: 0 0 10px 10px; }
+        .button { display: inline-block; background: #2563eb; color: white; padding: 12px 30px; text-decoration: none; border-radius: 6px; margin: 20px 0; }
+        .features { margin: 20px 0; }
+        .feature { padding: 10px 0; border-bottom: 1px solid #e5e7eb; }
+        .feature:last-child { border-bottom: none; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>SIGEC-VE</h1>
+        <p style="margin: 5px 0 0 0; opacity: 0.9;">Plataforma de Recarga de Veículos Elétricos</p>
+    </div>
+    <div class="content">
+        <h2>Bem-vindo(a), {{.UserName}}!</h2>
+        <p>Obrigado por se cadastrar no SIGEC-VE, sua plataforma inteligente de recarga de veículos elétricos.</p>
+
+        <div class="features">
+            <h3>O que você pode fazer:</h3>
+            <div class="feature">Encontrar estações de recarga próximas</div>
+            <div class="feature">Iniciar e encerrar sessões de recarga</div>
+            <div class="feature">Acompanhar seu histórico de recargas</div>
+            <div class="feature">Usar comandos de voz sem tirar as mãos do volante</div>
+            <div class="feature">Monitorar custos e consumo de energia</div>
+        </div>
+
+        <p style="text-align: center;">
+            <a href="{{.BaseURL}}/dashboard" class="button">Começar</a>
+        </p>
+
+        <p>Se tiver qualquer dúvida, nossa equipe de suporte está à disposição.</p>
+    </div>
+    <div class="footer">
+        <p>&copy; 2024 SIGEC-VE. Todos os direitos reservados.</p>
+        <p>Esta é uma mensagem automática. Por favor, não responda a este e-mail.</p>
+    </div>
+</body>
+</html>
+`
+
+const chargingStartedTemplatePtBR = `
+<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #2563eb, #1d4ed8); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { background: #ffffff; padding: 30px; border: 1px solid #e5e7eb; border-top: none; }
+        .footer { background: #f9fafb; padding: 20px; text-align: center; font-size: 12px; color: #6b7280; border: 1px solid #e5e7eb; border-top: none; border-radius: 0 0 10px 10px; }
+        .info-box { background: #d1fae5; border: 1px solid #10b981; padding: 20px; border-radius: 8px; margin: 20px 0; }
+        .info-row { display: flex; justify-content: space-between; padding: 8px 0; border-bottom: 1px solid #a7f3d0; }
+        .info-row:last-child { border-bottom: none; }
+        .info-label { color: #047857; }
+        .info-value { font-weight: 600; color: #065f46; }
+        .button { display: inline-block; background: #2563eb; color: white; padding: 12px 30px; text-decoration: none; border-radius: 6px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>SIGEC-VE</h1>
+        <p style="margin: 5px 0 0 0; opacity: 0.9;">Plataforma de Recarga de Veículos Elétricos</p>
+    </div>
+    <div class="content">
+        <h2>Sessão de Recarga Iniciada</h2>
+        <p>Olá, {{.UserName}},</p>
+        <p>Sua sessão de recarga foi iniciada com sucesso.</p>
+
+        <div class="info-box">
+            <div class="info-row">
+                <span class="info-label">ID da Transação</span>
+                <span class="info-value">{{.TransactionID}}</span>
+            </div>
+            <div class="info-row">
+                <span class="info-label">Estação</span>
+                <span class="info-value">{{.StationName}}</span>
+            </div>
+            <div class="info-row">
+                <span class="info-label">Início</span>
+                <span class="info-value">{{.StartTime}}</span>
+            </div>
+        </div>
+
+        <p>Você pode acompanhar sua sessão de recarga em tempo real pelo aplicativo.</p>
+
+        <p style="text-align: center;">
+            <a href="{{.BaseURL}}/transactions/{{.TransactionID}}" class="button">Ver Sessão</a>
+        </p>
+    </div>
+    <div class="footer">
+        <p>&copy; 2024 SIGEC-VE. Todos os direitos reservados.</p>
+        <p>Esta é uma mensagem automática. Por favor, não responda a este e-mail.</p>
+    </div>
+</body>
+</html>
+`
+
+const chargingCompletedTemplatePtBR = `
+<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #2563eb, #1d4ed8); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { background: #ffffff; padding: 30px; border: 1px solid #e5e7eb; border-top: none; }
+        .footer { background: #f9fafb; padding: 20px; text-align: center; font-size: 12px; color: #6b7280; border: 1px solid #e5e7eb; border-top: none; border-radius: 0 0 10px 10px; }
+        .info-box { background: #f3f4f6; padding: 20px; border-radius: 8px; margin: 20px 0; }
+        .info-row { display: flex; justify-content: space-between; padding: 8px 0; border-bottom: 1px solid #e5e7eb; }
+        .info-row:last-child { border-bottom: none; }
+        .info-label { color: #6b7280; }
+        .info-value { font-weight: 600; }
+        .total-box { background: #2563eb; color: white; padding: 20px; border-radius: 8px; margin: 20px 0; text-align: center; }
+        .total-amount { font-size: 32px; font-weight: bold; }
+        .button { display: inline-block; background: #2563eb; color: white; padding: 12px 30px; text-decoration: none; border-radius: 6px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>SIGEC-VE</h1>
+        <p style="margin: 5px 0 0 0; opacity: 0.9;">Plataforma de Recarga de Veículos Elétricos</p>
+    </div>
+    <div class="content">
+        <h2>Sessão de Recarga Concluída</h2>
+        <p>Olá, {{.UserName}},</p>
+        <p>Sua sessão de recarga foi concluída com sucesso.</p>
+
+        <div class="info-box">
+            <div class="info-row">
+                <span class="info-label">ID da Transação</span>
+                <span class="info-value">{{.TransactionID}}</span>
+            </div>
+            <div class="info-row">
+                <span class="info-label">Energia Fornecida</span>
+                <span class="info-value">{{number .EnergyKWh}} kWh</span>
+            </div>
+            <div class="info-row">
+                <span class="info-label">Duração</span>
+                <span class="info-value">{{.Duration}}</span>
+            </div>
+        </div>
+
+        <div class="total-box">
+            <p style="margin: 0 0 5px 0; opacity: 0.9;">Custo Total</p>
+            <div class="total-amount">{{money .Cost .Currency}}</div>
+        </div>
+
+        <p>Obrigado por usar o SIGEC-VE!</p>
+
+        <p style="text-align: center;">
+            <a href="{{.BaseURL}}/transactions/{{.TransactionID}}" class="button">Ver Detalhes</a>
+        </p>
+    </div>
+    <div class="footer">
+        <p>&copy; 2024 SIGEC-VE. Todos os direitos reservados.</p>
+        <p>Esta é uma mensagem automática. Por favor, não responda a este e-mail.</p>
+    </div>
+</body>
+</html>
+`
+
+const passwordResetTemplatePtBR = `
+<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #2563eb, #1d4ed8); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { background: #ffffff; padding: 30px; border: 1px solid #e5e7eb; border-top: none; }
+        .footer { background: #f9fafb; padding: 20px; text-align: center; font-size: 12px; color: #6b7280; border: 1px solid #e5e7eb; border-top: none; border-radius: 0 0 10px 10px; }
+        .button { display: inline-block; background: #2563eb; color: white; padding: 12px 30px; text-decoration: none; border-radius: 6px; margin: 20px 0; }
+        .warning { background: #fef3c7; border: 1px solid #f59e0b; padding: 15px; border-radius: 8px; margin: 20px 0; color: #92400e; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>SIGEC-VE</h1>
+        <p style="margin: 5px 0 0 0; opacity: 0.9;">Plataforma de Recarga de Veículos Elétricos</p>
+    </div>
+    <div class="content">
+        <h2>Redefinição de Senha</h2>
+        <p>Olá, {{.UserName}},</p>
+        <p>Recebemos uma solicitação para redefinir sua senha. Clique no botão abaixo para criar uma nova senha:</p>
+
+        <p style="text-align: center;">
+            <a href="{{.ResetURL}}" class="button">Redefinir Senha</a>
+        </p>
+
+        <div class="warning">
+            <strong>Aviso de Segurança:</strong> Este link expira em 1 hora. Se você não solicitou a redefinição de senha, ignore este e-mail ou entre em contato com o suporte caso esteja preocupado com a segurança da sua conta.
+        </div>
+
+        <p style="font-size: 12px; color: #6b7280;">
+            Se o botão não funcionar, copie e cole este link no seu navegador:<br>
+            <a href="{{.ResetURL}}" style="color: #2563eb; word-break: break-all;">{{.ResetURL}}</a>
+        </p>
+    </div>
+    <div class="footer">
+        <p>&copy; 2024 SIGEC-VE. Todos os direitos reservados.</p>
+        <p>Esta é uma mensagem automática. Por favor, não responda a este e-mail.</p>
+    </div>
+</body>
+</html>
+`
+
+const invoiceTemplatePtBR = `
+<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #2563eb, #1d4ed8); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { background: #ffffff; padding: 30px; border: 1px solid #e5e7eb; border-top: none; }
+        .footer { background: #f9fafb; padding: 20px; text-align: center; font-size: 12px; color: #6b7280; border: 1px solid #e5e7eb; border-top: none; border-radius: 0 0 10px 10px; }
+        .invoice-header { display: flex; justify-content: space-between; margin-bottom: 30px; padding-bottom: 20px; border-bottom: 2px solid #e5e7eb; }
+        .invoice-number { font-size: 24px; font-weight: bold; color: #2563eb; }
+        .invoice-date { color: #6b7280; }
+        .info-box { background: #f3f4f6; padding: 20px; border-radius: 8px; margin: 20px 0; }
+        .info-row { display: flex; justify-content: space-between; padding: 8px 0; border-bottom: 1px solid #e5e7eb; }
+        .info-row:last-child { border-bottom: none; }
+        .info-label { color: #6b7280; }
+        .info-value { font-weight: 600; }
+        .total-box { background: #1f2937; color: white; padding: 20px; border-radius: 8px; margin: 20px 0; }
+        .total-row { display: flex; justify-content: space-between; padding: 8px 0; }
+        .total-amount { font-size: 24px; font-weight: bold; }
+        .button { display: inline-block; background: #2563eb; color: white; padding: 12px 30px; text-decoration: none; border-radius: 6px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>SIGEC-VE</h1>
+        <p style="margin: 5px 0 0 0; opacity: 0.9;">Plataforma de Recarga de Veículos Elétricos</p>
+    </div>
+    <div class="content">
+        <div class="invoice-header">
+            <div>
+                <div class="invoice-number">Fatura nº {{.InvoiceID}}</div>
+                <div class="invoice-date">Data: {{.Date}}</div>
+            </div>
+        </div>
+
+        <p>Olá, {{.UserName}},</p>
+        <p>Segue a fatura da sua sessão de recarga recente:</p>
+
+        <div class="info-box">
+            <div class="info-row">
+                <span class="info-label">ID da Transação</span>
+                <span class="info-value">{{.TransactionID}}</span>
+            </div>
+            <div class="info-row">
+                <span class="info-label">Estação</span>
+                <span class="info-value">{{.StationName}}</span>
+            </div>
+            <div class="info-row">
+                <span class="info-label">Energia Fornecida</span>
+                <span class="info-value">{{number .EnergyKWh}} kWh</span>
+            </div>
+            <div class="info-row">
+                <span class="info-label">Duração</span>
+                <span class="info-value">{{.Duration}}</span>
+            </div>
+        </div>
+
+        <div class="total-box">
+            {{range .TaxLines}}
+            <div class="total-row">
+                <span>{{.Name}}</span>
+                <span>{{money .Amount $.Currency}}</span>
+            </div>
+            {{end}}
+            <div class="total-row">
+                <span>Valor Total</span>
+                <span class="total-amount">{{money .Amount .Currency}}</span>
+            </div>
+        </div>
+
+        <p style="text-align: center;">
+            <a href="{{.BaseURL}}/invoices/{{.InvoiceID}}" class="button">Baixar PDF</a>
+        </p>
+    </div>
+    <div class="footer">
+        <p>&copy; 2024 SIGEC-VE. Todos os direitos reservados.</p>
+        <p>Esta é uma mensagem automática. Por favor, não responda a este e-mail.</p>
+    </div>
+</body>
+</html>
+`
+
+const lowBalanceTemplatePtBR = `
+<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #f59e0b, #d97706); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { background: #ffffff; padding: 30px; border: 1px solid #e5e7eb; border-top: none; }
+        .footer { background: #f9fafb; padding: 20px; text-align: center; font-size: 12px; color: #6b7280; border: 1px solid #e5e7eb; border-top: none; border-radius: 0 0 10px 10px; }
+        .warning-box { background: #fef3c7; border: 2px solid #f59e0b; padding: 20px; border-radius: 8px; margin: 20px 0; text-align: center; }
+        .balance { font-size: 32px; font-weight: bold; color: #d97706; }
+        .button { display: inline-block; background: #2563eb; color: white; padding: 12px 30px; text-decoration: none; border-radius: 6px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>SIGEC-VE</h1>
+        <p style="margin: 5px 0 0 0; opacity: 0.9;">Aviso de Saldo Baixo</p>
+    </div>
+    <div class="content">
+        <h2>Seu Saldo Está Acabando</h2>
+        <p>Olá, {{.UserName}},</p>
+        <p>O saldo da sua conta está baixo. Adicione créditos para continuar usando nossos serviços de recarga sem interrupções.</p>
+
+        <div class="warning-box">
+            <p style="margin: 0 0 10px 0; color: #92400e;">Saldo Atual</p>
+            <div class="balance">{{money .Balance .Currency}}</div>
+        </div>
+
+        <p>Recomendamos manter um saldo mínimo de R$ 50,00 para garantir sessões de recarga sem interrupções.</p>
+
+        <p style="text-align: center;">
+            <a href="{{.BaseURL}}/wallet/add-funds" class="button">Adicionar Créditos</a>
+        </p>
+    </div>
+    <div class="footer">
+        <p>&copy; 2024 SIGEC-VE. Todos os direitos reservados.</p>
+        <p>Esta é uma mensagem automática. Por favor, não responda a este e-mail.</p>
+    </div>
+</body>
+</html>
+`
@@ -0,0 +1,237 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+const blankField = "____________________"
+
+// labels holds the captions printed on the invoice
+type labels struct {
+	title, date, issuer, customer, taxID, municipalReg          string
+	transaction, station, duration                              string
+	description, quantity, unitPrice, amount                    string
+	energy, idleFee, taxes, total                               string
+	fiscalTitle, fiscalNumber, fiscalVerification, fiscalAccess string
+	footer                                                      string
+}
+
+var labelsEN = labels{
+	title: "Invoice", date: "Date", issuer: "Issuer", customer: "Customer", taxID: "Tax ID",
+	transaction: "Transaction", station: "Station", duration: "Duration",
+	description: "Description", quantity: "Quantity", unitPrice: "Unit price", amount: "Amount",
+	energy: "Energy delivered", idleFee: "Idle fee", taxes: "Taxes", total: "Total",
+	footer: "SIGEC-VE - Electric Vehicle Charging Platform",
+}
+
+var labelsPtBR = labels{
+	title: "Fatura", date: "Data", issuer: "Emitente", customer: "Cliente", taxID: "CNPJ",
+	municipalReg: "Inscrição Municipal",
+	transaction:  "Transação", station: "Estação", duration: "Duração",
+	description: "Descrição", quantity: "Quantidade", unitPrice: "Preço unitário", amount: "Valor",
+	energy: "Energia fornecida", idleFee: "Taxa de ociosidade", taxes: "Impostos", total: "Total",
+	fiscalTitle:        "Dados fiscais (NFS-e)",
+	fiscalNumber:       "Número da NFS-e",
+	fiscalVerification: "Código de verificação",
+	fiscalAccess:       "Chave de acesso",
+	footer:             "SIGEC-VE - Plataforma de Recarga de Veículos Elétricos",
+}
+
+// isBrazilian reports whether an invoice follows the Brazilian layout, which
+// carries CNPJ/CPF fields and a block for the NFS-e (service invoice) data
+func isBrazilian(inv *ports.Invoice) bool {
+	return inv.Currency == "" || strings.EqualFold(inv.Currency, "BRL")
+}
+
+// BuildPDF renders an invoice as a single-page A4 PDF. It is shared by the
+// invoice email attachment and the download endpoint.
+func BuildPDF(inv *ports.Invoice) ([]byte, error) {
+	if inv == nil {
+		return nil, errors.New("invoice cannot be nil")
+	}
+
+	brazilian := isBrazilian(inv)
+	l := labelsEN
+	if brazilian {
+		l = labelsPtBR
+	}
+	money := func(v float64) string { return formatMoney(v, inv.Currency) }
+
+	p := &pdfPage{}
+	const left, right = 50.0, pageWidth - 50
+
+	// Header
+	p.text(left, 790, 22, true, "SIGEC-VE")
+	p.textRight(right, 792, 14, true, fmt.Sprintf("%s %s", l.title, inv.ID))
+	p.textRight(right, 774, 10, false, fmt.Sprintf("%s: %s", l.date, inv.Date))
+	p.line(left, 760, right, 760)
+
+	// Issuer and customer
+	y := 738.0
+	p.text(left, y, 10, true, l.issuer)
+	p.text(300, y, 10, true, l.customer)
+	y -= 15
+	p.text(left, y, 10, false, orBlank(inv.IssuerName))
+	p.text(300, y, 10, false, orBlank(inv.CustomerName))
+	y -= 15
+	customerTaxLabel := l.taxID
+	if brazilian {
+		customerTaxLabel = "CPF/CNPJ"
+	}
+	p.text(left, y, 10, false, fmt.Sprintf("%s: %s", l.taxID, orBlank(inv.IssuerTaxID)))
+	p.text(300, y, 10, false, fmt.Sprintf("%s: %s", customerTaxLabel, orBlank(inv.CustomerTaxID)))
+	if brazilian {
+		y -= 15
+		p.text(left, y, 10, false, fmt.Sprintf("%s: %s", l.municipalReg, blankField))
+	}
+
+	// Session details
+	y -= 30
+	p.text(left, y, 10, false, fmt.Sprintf("%s: %s", l.transaction, inv.TransactionID))
+	y -= 15
+	p.text(left, y, 10, false, fmt.Sprintf("%s: %s", l.station, orDash(inv.StationName)))
+	y -= 15
+	p.text(left, y, 10, false, fmt.Sprintf("%s: %s", l.duration, orDash(inv.Duration)))
+
+	// Line items
+	const colQty, colUnit, colAmount = 330.0, 440.0, right
+	y -= 35
+	p.text(left, y, 10, true, l.description)
+	p.textRight(colQty, y, 10, true, l.quantity)
+	p.textRight(colUnit, y, 10, true, l.unitPrice)
+	p.textRight(colAmount, y, 10, true, l.amount)
+	y -= 8
+	p.line(left, y, right, y)
+
+	energyCost, unitPrice := energyLine(inv)
+	y -= 16
+	p.text(left, y, 10, false, l.energy)
+	p.textRight(colQty, y, 10, false, fmt.Sprintf("%.2f kWh", inv.EnergyKWh))
+	p.textRight(colUnit, y, 10, false, money(unitPrice))
+	p.textRight(colAmount, y, 10, false, money(energyCost))
+
+	if inv.IdleFee > 0 {
+		y -= 16
+		p.text(left, y, 10, false, l.idleFee)
+		p.textRight(colAmount, y, 10, false, money(inv.IdleFee))
+	}
+
+	if components := taxComponents(inv); len(components) > 0 {
+		for _, c := range components {
+			y -= 16
+			p.text(left, y, 10, false, c.name)
+			p.textRight(colAmount, y, 10, false, money(c.amount))
+		}
+	} else {
+		y -= 16
+		p.text(left, y, 10, false, l.taxes)
+		p.textRight(colAmount, y, 10, false, money(inv.Taxes))
+	}
+
+	y -= 10
+	p.line(left, y, right, y)
+	y -= 20
+	p.text(colUnit-100, y, 12, true, l.total)
+	p.textRight(colAmount, y, 12, true, money(inv.Amount))
+
+	// Space for the NFS-e data, filled in once the municipal invoice is issued
+	if brazilian {
+		y -= 45
+		p.rect(left, y-62, right-left, 80)
+		p.text(left+10, y, 10, true, l.fiscalTitle)
+		p.text(left+10, y-20, 10, false, fmt.Sprintf("%s: %s", l.fiscalNumber, blankField))
+		p.text(300, y-20, 10, false, fmt.Sprintf("%s: %s", l.fiscalVerification, blankField))
+		p.text(left+10, y-40, 10, false, fmt.Sprintf("%s: %s%s", l.fiscalAccess, blankField, blankField))
+	}
+
+	p.line(left, 60, right, 60)
+	p.text(left, 45, 8, false, l.footer)
+
+	return p.bytes(), nil
+}
+
+// energyLine returns the energy amount and unit price. Invoices built from
+// a total only derive them from the amount net of fees and taxes.
+func energyLine(inv *ports.Invoice) (cost, unitPrice float64) {
+	cost = inv.EnergyCost
+	if cost == 0 {
+		cost = inv.Amount - inv.IdleFee - inv.Taxes
+	}
+	unitPrice = inv.UnitPrice
+	if unitPrice == 0 && inv.EnergyKWh > 0 {
+		unitPrice = cost / inv.EnergyKWh
+	}
+	return cost, unitPrice
+}
+
+// taxComponent is one tax line printed on the invoice
+type taxComponent struct {
+	name   string
+	amount float64
+}
+
+// taxComponents returns the ICMS/PIS/COFINS lines of an invoice, or nil when
+// only the total taxes are known
+func taxComponents(inv *ports.Invoice) []taxComponent {
+	if inv.ICMS == 0 && inv.PIS == 0 && inv.COFINS == 0 {
+		return nil
+	}
+	return []taxComponent{
+		{"ICMS", inv.ICMS},
+		{"PIS", inv.PIS},
+		{"COFINS", inv.COFINS},
+	}
+}
+
+// formatMoney formats an amount as "R$ 1.234,56" for BRL and
+// "USD 1,234.56" for other currencies
+func formatMoney(v float64, currency string) string {
+	if currency == "" {
+		currency = "BRL"
+	}
+
+	neg := v < 0
+	if neg {
+		v = -v
+	}
+	cents := int64(v*100 + 0.5)
+	whole, frac := cents/100, cents%100
+
+	thousands, decimal, prefix := ",", ".", strings.ToUpper(currency)
+	if strings.EqualFold(currency, "BRL") {
+		thousands, decimal, prefix = ".", ",", "R$"
+	}
+
+	digits := fmt.Sprintf("%d", whole)
+	var grouped strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteString(thousands)
+		}
+		grouped.WriteRune(d)
+	}
+
+	sign := ""
+	if neg {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s %s%s%02d", sign, prefix, grouped.String(), decimal, frac)
+}
+
+func orBlank(s string) string {
+	if s == "" {
+		return blankField
+	}
+	return s
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,185 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/internal/service/transaction"
+)
+
+func testInvoice() *ports.Invoice {
+	return &ports.Invoice{
+		ID:            "INV-tx-123",
+		TransactionID: "tx-123",
+		Amount:        1245.50,
+		Currency:      "BRL",
+		EnergyKWh:     25.5,
+		Duration:      "1h 30m",
+		StationName:   "ABB Terra 184",
+		Date:          "2024-01-15",
+		Taxes:         12.30,
+		IssuerName:    "SIGEC-VE",
+		IssuerTaxID:   "12.345.678/0001-90",
+		CustomerName:  "João Silva",
+	}
+}
+
+func TestBuildPDF_ContainsTotal(t *testing.T) {
+	// Act
+	pdf, err := BuildPDF(testInvoice())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) {
+		t.Error("expected PDF header")
+	}
+	if !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Error("expected PDF trailer")
+	}
+	if !bytes.Contains(pdf, []byte("(R$ 1.245,50)")) {
+		t.Error("expected PDF to contain the invoice total")
+	}
+	if !bytes.Contains(pdf, []byte("12.345.678/0001-90")) {
+		t.Error("expected PDF to contain the issuer CNPJ")
+	}
+	if !bytes.Contains(pdf, []byte("NFS-e")) {
+		t.Error("expected Brazilian invoice to reserve space for NFS-e data")
+	}
+	// "João" is written as WinAnsi with an octal escape for "ã"
+	if !bytes.Contains(pdf, []byte(`(Jo\343o Silva)`)) {
+		t.Error("expected customer name encoded as WinAnsi")
+	}
+}
+
+func TestBuildPDF_CrossReferenceOffsets(t *testing.T) {
+	// Arrange
+	pdf, err := BuildPDF(testInvoice())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Act
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf, -1)
+
+	// Assert
+	if len(offsets) != 6 {
+		t.Fatalf("expected 6 xref entries, got %d", len(offsets))
+	}
+	for i, m := range offsets {
+		off, _ := strconv.Atoi(string(m[1]))
+		want := strconv.Itoa(i+1) + " 0 obj"
+		if !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+}
+
+func TestBuildPDF_NonBrazilianInvoice(t *testing.T) {
+	// Arrange
+	inv := testInvoice()
+	inv.Currency = "USD"
+
+	// Act
+	pdf, err := BuildPDF(inv)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !bytes.Contains(pdf, []byte("(USD 1,245.50)")) {
+		t.Error("expected PDF to contain the total in USD format")
+	}
+	if bytes.Contains(pdf, []byte("NFS-e")) {
+		t.Error("expected no NFS-e block on a non-Brazilian invoice")
+	}
+}
+
+func TestBuildPDF_TaxComponents(t *testing.T) {
+	// Arrange
+	inv := testInvoice()
+	inv.Taxes, inv.ICMS, inv.PIS, inv.COFINS = 318.49, 224.19, 16.85, 77.45
+
+	// Act
+	pdf, err := BuildPDF(inv)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, want := range []string{"(ICMS)", "(R$ 224,19)", "(PIS)", "(R$ 16,85)", "(COFINS)", "(R$ 77,45)"} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("expected PDF to contain %s", want)
+		}
+	}
+}
+
+func TestBuildPDF_NilInvoice(t *testing.T) {
+	if _, err := BuildPDF(nil); err == nil {
+		t.Error("expected error for nil invoice")
+	}
+}
+
+func TestService_GetInvoice(t *testing.T) {
+	// Arrange
+	end := time.Date(2024, 1, 15, 11, 30, 0, 0, time.UTC)
+	tx := &domain.Transaction{
+		ID:            "tx-123",
+		UserID:        "user-1",
+		ChargePointID: "CP-001",
+		Status:        domain.TransactionStatusCompleted,
+		StartTime:     end.Add(-90 * time.Minute),
+		EndTime:       &end,
+		TotalEnergy:   25500,
+		Cost:          19.13,
+		Currency:      "BRL",
+	}
+	txRepo := &mocks.MockTransactionRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			if id != tx.ID {
+				return nil, nil
+			}
+			return tx, nil
+		},
+	}
+	userRepo := &mocks.MockUserRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.User, error) {
+			return &domain.User{ID: id, Name: "João Silva", Document: "123.456.789-00"}, nil
+		},
+	}
+	log := zap.NewNop()
+	billing := transaction.NewBillingService(txRepo, nil, nil, log)
+	service := NewService(txRepo, userRepo, billing, Issuer{Name: "SIGEC-VE", TaxID: "12.345.678/0001-90"}, log)
+
+	// Act
+	inv, err := service.GetInvoice(context.Background(), "user-1", "INV-tx-123")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if inv.ID != "INV-tx-123" || inv.Amount != 19.13 || inv.Duration != "1h 30m" {
+		t.Errorf("unexpected invoice %+v", inv)
+	}
+	if inv.IssuerTaxID != "12.345.678/0001-90" || inv.CustomerTaxID != "123.456.789-00" {
+		t.Errorf("expected tax identifiers to be filled, got issuer %q customer %q", inv.IssuerTaxID, inv.CustomerTaxID)
+	}
+
+	if _, err := service.GetInvoice(context.Background(), "user-2", "INV-tx-123"); !errors.Is(err, domain.ErrInvoiceNotFound) {
+		t.Errorf("expected ErrInvoiceNotFound for another user, got %v", err)
+	}
+	if _, err := service.GetInvoice(context.Background(), "user-1", "tx-missing"); !errors.Is(err, domain.ErrInvoiceNotFound) {
+		t.Errorf("expected ErrInvoiceNotFound for unknown transaction, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size in PDF points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+)
+
+// pdfPage is a single-page PDF document using the standard Helvetica fonts.
+// Text is written as WinAnsi, which covers the Latin-1 characters needed for
+// Portuguese; anything outside it is replaced with '?'.
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// text draws s with its baseline starting at (x, y)
+func (p *pdfPage) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, encodeText(s))
+}
+
+// textRight draws s so that it ends at x
+func (p *pdfPage) textRight(x, y, size float64, bold bool, s string) {
+	p.text(x-textWidth(s, size, bold), y, size, bold, s)
+}
+
+// line draws a straight line
+func (p *pdfPage) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// rect strokes a rectangle with its lower-left corner at (x, y)
+func (p *pdfPage) rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f %.2f %.2f re S\n", x, y, w, h)
+}
+
+// bytes assembles the document: catalog, page tree, page, two fonts and the
+// uncompressed content stream, followed by the cross-reference table
+func (p *pdfPage) bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+// encodeText converts s to WinAnsi and escapes it for a PDF string literal
+func encodeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// helveticaWidths holds glyph widths (per 1000 units) for the characters that
+// typically appear in right-aligned amounts; other glyphs use an average width
+var helveticaWidths = map[rune]float64{
+	'0': 556, '1': 556, '2': 556, '3': 556, '4': 556, '5': 556, '6': 556, '7': 556, '8': 556, '9': 556,
+	'.': 278, ',': 278, ' ': 278, '$': 556, '-': 333, '%': 889, '/': 278,
+	'R': 722, 'B': 667, 'L': 556, 'U': 722, 'S': 667, 'D': 722, 'E': 667,
+	'k': 500, 'W': 944, 'h': 556, 'm': 833,
+}
+
+// textWidth estimates the rendered width of s in points
+func textWidth(s string, size float64, bold bool) float64 {
+	var units float64
+	for _, r := range s {
+		w, ok := helveticaWidths[r]
+		if !ok {
+			w = 556
+		}
+		units += w
+	}
+	if bold {
+		units *= 1.05
+	}
+	return units * size / 1000
+}
@@ -0,0 +1,88 @@
+package invoice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/internal/service/transaction"
+)
+
+// Issuer identifies the company issuing the invoices
+type Issuer struct {
+	Name  string
+	TaxID string // CNPJ for Brazilian invoices
+}
+
+// Service builds invoices for completed transactions and renders them as PDF
+type Service struct {
+	txRepo   ports.TransactionRepository
+	userRepo ports.UserRepository
+	billing  *transaction.BillingService
+	issuer   Issuer
+	log      *zap.Logger
+}
+
+// NewService creates a new invoice service
+func NewService(
+	txRepo ports.TransactionRepository,
+	userRepo ports.UserRepository,
+	billing *transaction.BillingService,
+	issuer Issuer,
+	log *zap.Logger,
+) *Service {
+	return &Service{
+		txRepo:   txRepo,
+		userRepo: userRepo,
+		billing:  billing,
+		issuer:   issuer,
+		log:      log,
+	}
+}
+
+// Ensure Service implements ports.InvoiceService
+var _ ports.InvoiceService = (*Service)(nil)
+
+// GetInvoice returns the invoice of a finished transaction owned by userID
+func (s *Service) GetInvoice(ctx context.Context, userID, invoiceID string) (*ports.Invoice, error) {
+	txID := strings.TrimPrefix(invoiceID, transaction.InvoiceIDPrefix)
+
+	tx, err := s.txRepo.FindByID(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transaction: %w", err)
+	}
+	if tx == nil || tx.UserID != userID {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	if tx.Status != domain.TransactionStatusStopped && tx.Status != domain.TransactionStatusCompleted {
+		return nil, domain.ErrInvoiceNotFound
+	}
+
+	generated, err := s.billing.GenerateInvoice(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invoice: %w", err)
+	}
+
+	inv := generated.Document()
+	inv.IssuerName = s.issuer.Name
+	inv.IssuerTaxID = s.issuer.TaxID
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.log.Warn("Failed to load invoice customer", zap.String("user_id", userID), zap.Error(err))
+	} else if user != nil {
+		inv.CustomerName = user.Name
+		inv.CustomerTaxID = user.Document
+	}
+
+	return inv, nil
+}
+
+// RenderPDF renders an invoice as a PDF document
+func (s *Service) RenderPDF(ctx context.Context, invoice *ports.Invoice) ([]byte, error) {
+	return BuildPDF(invoice)
+}
@@ -0,0 +1,225 @@
+// Package notification fans notification events out to the channels each
+// user opted into: email, push and SMS.
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Subject is the message queue subject notification events are published on
+const Subject = "notifications.events"
+
+// Service sends notification events to users
+type Service struct {
+	users ports.UserRepository
+	prefs ports.NotificationPreferenceRepository // optional, see SetPreferenceRepository
+	email ports.EmailService                     // optional, see SetEmailService
+	push  ports.PushSender                       // optional, see SetPushSender
+	sms   ports.SMSSender                        // optional, see SetSMSSender
+	log   *zap.Logger
+}
+
+// NewService creates a notification service. Channels are enabled with
+// their setters; an event is never sent on a channel that is not set.
+func NewService(users ports.UserRepository, log *zap.Logger) *Service {
+	return &Service{users: users, log: log}
+}
+
+// SetPreferenceRepository enables per-user channel preferences. Without
+// it every user gets domain.DefaultNotificationPreferences.
+func (s *Service) SetPreferenceRepository(repo ports.NotificationPreferenceRepository) {
+	s.prefs = repo
+}
+
+// SetEmailService enables the email channel
+func (s *Service) SetEmailService(email ports.EmailService) {
+	s.email = email
+}
+
+// SetPushSender enables the push channel
+func (s *Service) SetPushSender(push ports.PushSender) {
+	s.push = push
+}
+
+// SetSMSSender enables the SMS channel
+func (s *Service) SetSMSSender(sms ports.SMSSender) {
+	s.sms = sms
+}
+
+// Start dispatches every event published on Subject
+func (s *Service) Start(mq queue.MessageQueue) error {
+	if err := mq.Subscribe(Subject, s.handle); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", Subject, err)
+	}
+	return nil
+}
+
+// handle decodes a queued event. Events that can never be sent are
+// dropped rather than returned as errors, so they are not redelivered.
+func (s *Service) handle(data []byte) error {
+	var event domain.NotificationEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		telemetry.RecordNotificationDropped("malformed")
+		s.log.Warn("Dropping malformed notification event", zap.Error(err))
+		return nil
+	}
+	return s.Dispatch(context.Background(), &event)
+}
+
+// Dispatch sends event on every channel its user opted into, unless they
+// turned its type off. Unknown event types and users are dropped. A failed
+// channel does not stop the others; their errors are joined.
+func (s *Service) Dispatch(ctx context.Context, event *domain.NotificationEvent) error {
+	if !domain.IsNotificationType(event.Type) {
+		telemetry.RecordNotificationDropped("unknown_type")
+		s.log.Debug("Dropping notification of unknown type", zap.String("type", event.Type))
+		return nil
+	}
+
+	user, err := s.users.FindByID(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to find user %s: %w", event.UserID, err)
+	}
+	if user == nil || user.IsAnonymized() {
+		telemetry.RecordNotificationDropped("unknown_user")
+		s.log.Debug("Dropping notification for unknown user", zap.String("user_id", event.UserID))
+		return nil
+	}
+
+	prefs, err := s.preferences(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, channel := range prefs.ChannelsFor(event.Type) {
+		sent, err := s.send(ctx, channel, user, prefs, event)
+		if !sent {
+			continue
+		}
+		telemetry.RecordNotificationSent(string(channel), err == nil)
+		if err != nil {
+			s.log.Error("Failed to send notification",
+				zap.String("channel", string(channel)),
+				zap.String("type", event.Type),
+				zap.String("user_id", user.ID),
+				zap.Error(err),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// phoneNumberPattern matches E.164 numbers, e.g. +5511999990000
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// GetPreferences returns the user's preferences, or the defaults when they
+// never set any
+func (s *Service) GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	return s.preferences(ctx, userID)
+}
+
+// UpdatePreferences changes the fields set in req
+func (s *Service) UpdatePreferences(ctx context.Context, userID string, req *ports.NotificationPreferencesRequest) (*domain.NotificationPreferences, error) {
+	if s.prefs == nil {
+		return nil, fmt.Errorf("notification preferences are not stored by this backend")
+	}
+
+	prefs, err := s.preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Email != nil {
+		prefs.Email = *req.Email
+	}
+	if req.Push != nil {
+		prefs.Push = *req.Push
+	}
+	if req.SMS != nil {
+		prefs.SMS = *req.SMS
+	}
+	if req.PhoneNumber != nil {
+		prefs.PhoneNumber = *req.PhoneNumber
+	}
+	if req.PushToken != nil {
+		prefs.PushToken = *req.PushToken
+	}
+	if req.DisabledEvents != nil {
+		prefs.DisabledEvents = req.DisabledEvents
+	}
+
+	if err := validatePreferences(prefs); err != nil {
+		return nil, err
+	}
+	if err := s.prefs.Save(ctx, prefs); err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// validatePreferences checks preferences before they are saved
+func validatePreferences(prefs *domain.NotificationPreferences) error {
+	if prefs.PhoneNumber != "" && !phoneNumberPattern.MatchString(prefs.PhoneNumber) {
+		return fmt.Errorf("%w: phone_number must be in E.164 format, e.g. +5511999990000", domain.ErrValidation)
+	}
+	if prefs.SMS && prefs.PhoneNumber == "" {
+		return fmt.Errorf("%w: phone_number is required to receive SMS", domain.ErrValidation)
+	}
+	for _, eventType := range prefs.DisabledEvents {
+		if !domain.IsNotificationType(eventType) {
+			return fmt.Errorf("%w: unknown notification type %q", domain.ErrValidation, eventType)
+		}
+	}
+	return nil
+}
+
+// preferences returns the user's stored preferences or the defaults
+func (s *Service) preferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	if s.prefs == nil {
+		return domain.DefaultNotificationPreferences(userID), nil
+	}
+	prefs, err := s.prefs.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+	if prefs == nil {
+		return domain.DefaultNotificationPreferences(userID), nil
+	}
+	return prefs, nil
+}
+
+// send delivers event on one channel. sent is false when the channel is
+// not configured or the user has no address on it.
+func (s *Service) send(ctx context.Context, channel domain.NotificationChannel, user *domain.User, prefs *domain.NotificationPreferences, event *domain.NotificationEvent) (sent bool, err error) {
+	switch channel {
+	case domain.NotificationChannelEmail:
+		if s.email == nil || user.Email == "" {
+			return false, nil
+		}
+		return true, s.email.Send(ctx, user.Email, event.Title, event.Body)
+	case domain.NotificationChannelPush:
+		if s.push == nil {
+			return false, nil
+		}
+		return true, s.push.SendPush(ctx, prefs.PushToken, event.Title, event.Body, event.Data)
+	case domain.NotificationChannelSMS:
+		if s.sms == nil {
+			return false, nil
+		}
+		return true, s.sms.SendSMS(ctx, prefs.PhoneNumber, event.Title+": "+event.Body)
+	}
+	return false, nil
+}
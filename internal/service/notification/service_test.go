@@ -0,0 +1,294 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+type sentPush struct {
+	token, title string
+}
+
+type fakePush struct {
+	sent []sentPush
+	err  error
+}
+
+func (f *fakePush) SendPush(ctx context.Context, deviceToken, title, body string, data map[string]string) error {
+	f.sent = append(f.sent, sentPush{deviceToken, title})
+	return f.err
+}
+
+type fakeSMS struct {
+	to []string
+}
+
+func (f *fakeSMS) SendSMS(ctx context.Context, to, message string) error {
+	f.to = append(f.to, to)
+	return nil
+}
+
+// preferences are the stored preferences of a test, by user
+type preferences map[string]*domain.NotificationPreferences
+
+type testChannels struct {
+	email *mocks.MockEmailService
+	push  *fakePush
+	sms   *fakeSMS
+}
+
+func newTestService(t *testing.T, stored preferences) (*Service, *testChannels) {
+	t.Helper()
+	prefs := memory.NewNotificationPreferenceRepository()
+	for _, p := range stored {
+		if err := prefs.Save(context.Background(), p); err != nil {
+			t.Fatalf("failed to save preferences: %v", err)
+		}
+	}
+
+	users := &mocks.MockUserRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.User, error) {
+			if id == "missing" {
+				return nil, nil
+			}
+			return &domain.User{ID: id, Email: id + "@example.com"}, nil
+		},
+	}
+	channels := &testChannels{
+		email: &mocks.MockEmailService{},
+		push:  &fakePush{},
+		sms:   &fakeSMS{},
+	}
+
+	svc := NewService(users, zap.NewNop())
+	svc.SetPreferenceRepository(prefs)
+	svc.SetEmailService(channels.email)
+	svc.SetPushSender(channels.push)
+	svc.SetSMSSender(channels.sms)
+	return svc, channels
+}
+
+func chargingCompleted(userID string) *domain.NotificationEvent {
+	return &domain.NotificationEvent{
+		Type:   domain.NotificationChargingCompleted,
+		UserID: userID,
+		Title:  "Charging completed",
+		Body:   "12.4 kWh delivered",
+	}
+}
+
+func TestDispatch_RoutesByPreferences(t *testing.T) {
+	tests := []struct {
+		name          string
+		prefs         *domain.NotificationPreferences
+		expectedEmail int
+		expectedPush  int
+		expectedSMS   int
+	}{
+		{"defaults without stored preferences", nil, 1, 0, 0},
+		{"all channels", &domain.NotificationPreferences{Email: true, Push: true, SMS: true, PushToken: "fcm-1", PhoneNumber: "+5511999990000"}, 1, 1, 1},
+		{"push only", &domain.NotificationPreferences{Push: true, PushToken: "fcm-1"}, 0, 1, 0},
+		{"sms without a phone number", &domain.NotificationPreferences{SMS: true}, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			prefs := preferences{}
+			if tt.prefs != nil {
+				tt.prefs.UserID = "user-1"
+				prefs["user-1"] = tt.prefs
+			}
+			svc, channels := newTestService(t, prefs)
+
+			// Act
+			err := svc.Dispatch(context.Background(), chargingCompleted("user-1"))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(channels.email.SentEmails) != tt.expectedEmail {
+				t.Errorf("expected %d emails, got %d", tt.expectedEmail, len(channels.email.SentEmails))
+			}
+			if len(channels.push.sent) != tt.expectedPush {
+				t.Errorf("expected %d pushes, got %d", tt.expectedPush, len(channels.push.sent))
+			}
+			if len(channels.sms.to) != tt.expectedSMS {
+				t.Errorf("expected %d SMS, got %d", tt.expectedSMS, len(channels.sms.to))
+			}
+		})
+	}
+}
+
+func TestDispatch_UserOptedOutOfEmail(t *testing.T) {
+	// Arrange
+	svc, channels := newTestService(t, preferences{
+		"user-1": {UserID: "user-1", Email: false, Push: true, PushToken: "fcm-1"},
+	})
+
+	// Act
+	err := svc.Dispatch(context.Background(), chargingCompleted("user-1"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(channels.email.SentEmails) != 0 {
+		t.Errorf("expected no email, got %d", len(channels.email.SentEmails))
+	}
+	if len(channels.push.sent) != 1 || channels.push.sent[0].token != "fcm-1" {
+		t.Errorf("expected one push to fcm-1, got %v", channels.push.sent)
+	}
+}
+
+func TestDispatch_DropsUnknownTypesAndUsers(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *domain.NotificationEvent
+	}{
+		{"unknown type", &domain.NotificationEvent{Type: "marketing.blast", UserID: "user-1"}},
+		{"unknown user", chargingCompleted("missing")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			svc, channels := newTestService(t, preferences{})
+
+			// Act
+			err := svc.Dispatch(context.Background(), tt.event)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected the event to be dropped without error, got %v", err)
+			}
+			if len(channels.email.SentEmails) != 0 {
+				t.Errorf("expected nothing sent, got %d emails", len(channels.email.SentEmails))
+			}
+		})
+	}
+}
+
+func TestDispatch_FailedChannelDoesNotStopOthers(t *testing.T) {
+	// Arrange
+	svc, channels := newTestService(t, preferences{
+		"user-1": {UserID: "user-1", Email: true, Push: true, PushToken: "fcm-1"},
+	})
+	channels.push.err = errors.New("fcm unavailable")
+
+	// Act
+	err := svc.Dispatch(context.Background(), chargingCompleted("user-1"))
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected the push failure to be reported")
+	}
+	if len(channels.email.SentEmails) != 1 {
+		t.Errorf("expected the email to be sent anyway, got %d", len(channels.email.SentEmails))
+	}
+}
+
+func TestStart_ConsumesQueuedEvents(t *testing.T) {
+	// Arrange
+	svc, channels := newTestService(t, preferences{})
+	mq := mocks.NewMockMessageQueue()
+	if err := svc.Start(mq); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	handler := mq.Subscribers[Subject][0]
+
+	// Act
+	errMalformed := handler([]byte(`{not json`))
+	errValid := handler([]byte(`{"type":"balance.low","user_id":"user-1","title":"Low balance","body":"R$ 5,00 left"}`))
+
+	// Assert
+	if errMalformed != nil {
+		t.Errorf("expected a malformed event to be dropped, got %v", errMalformed)
+	}
+	if errValid != nil {
+		t.Fatalf("expected no error, got %v", errValid)
+	}
+	if len(channels.email.SentEmails) != 1 || channels.email.SentEmails[0].To != "user-1@example.com" {
+		t.Errorf("expected one email to user-1@example.com, got %v", channels.email.SentEmails)
+	}
+}
+
+func TestDispatch_SkipsDisabledEventTypes(t *testing.T) {
+	// Arrange
+	svc, channels := newTestService(t, preferences{
+		"user-1": {
+			UserID: "user-1", Email: true, Push: true, PushToken: "fcm-1",
+			DisabledEvents: []string{domain.NotificationChargingCompleted},
+		},
+	})
+
+	// Act
+	errDisabled := svc.Dispatch(context.Background(), chargingCompleted("user-1"))
+	errEnabled := svc.Dispatch(context.Background(), &domain.NotificationEvent{
+		Type: domain.NotificationPaymentFailed, UserID: "user-1", Title: "Payment failed",
+	})
+
+	// Assert
+	if errDisabled != nil || errEnabled != nil {
+		t.Fatalf("expected no errors, got %v, %v", errDisabled, errEnabled)
+	}
+	if len(channels.email.SentEmails) != 1 || channels.email.SentEmails[0].Subject != "Payment failed" {
+		t.Errorf("expected only the payment email, got %v", channels.email.SentEmails)
+	}
+	if len(channels.push.sent) != 1 {
+		t.Errorf("expected only the payment push, got %d", len(channels.push.sent))
+	}
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	off := false
+	on := true
+	phone := "+5511999990000"
+	badPhone := "11 99999-0000"
+
+	tests := []struct {
+		name    string
+		req     ports.NotificationPreferencesRequest
+		wantErr bool
+	}{
+		{"turn email off", ports.NotificationPreferencesRequest{Email: &off}, false},
+		{"sms with phone", ports.NotificationPreferencesRequest{SMS: &on, PhoneNumber: &phone}, false},
+		{"sms without phone", ports.NotificationPreferencesRequest{SMS: &on}, true},
+		{"phone not E.164", ports.NotificationPreferencesRequest{PhoneNumber: &badPhone}, true},
+		{"unknown event type", ports.NotificationPreferencesRequest{DisabledEvents: []string{"marketing.blast"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			svc, _ := newTestService(t, preferences{})
+
+			// Act
+			prefs, err := svc.UpdatePreferences(context.Background(), "user-1", &tt.req)
+
+			// Assert
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrValidation) {
+					t.Fatalf("expected a validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			stored, _ := svc.GetPreferences(context.Background(), "user-1")
+			if stored.Email != prefs.Email || stored.SMS != prefs.SMS || !stored.Push {
+				t.Errorf("expected the update to be stored on top of the defaults, got %+v", stored)
+			}
+		})
+	}
+}
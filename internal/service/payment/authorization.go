@@ -0,0 +1,229 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
+)
+
+// Authorize places a hold for amount on the user's default card for a
+// charging session. Nothing is charged until the hold is captured; the
+// payment is saved as authorized, or as failed when the card was declined.
+// A user without a card at the provider gets ErrNoPaymentCard.
+func (s *Service) Authorize(ctx context.Context, userID string, transactionID string, amount float64) (payment *domain.Payment, err error) {
+	ctx, span := tracer.Start(ctx, "payment.Authorize", trace.WithAttributes(
+		attribute.String("payment.user_id", userID),
+		attribute.Float64("payment.amount", amount),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("authorization amount must be positive")
+	}
+
+	provider, err := s.getProvider(s.config.DefaultProvider)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("payment.provider", provider.Name()))
+
+	card, err := s.defaultCard(ctx, userID, s.config.DefaultProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	payment = &domain.Payment{
+		ID:               uuid.New().String(),
+		UserID:           userID,
+		TransactionID:    transactionID,
+		Provider:         s.config.DefaultProvider,
+		Method:           domain.PaymentMethodCreditCard,
+		Status:           domain.PaymentStatusProcessing,
+		AuthorizedAmount: amount,
+		Currency:         s.config.DefaultCurrency,
+		Description:      "Charging session authorization",
+		Metadata:         domain.JSONMap{"card_id": card.ID},
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := s.repo.SavePayment(ctx, payment); err != nil {
+		return nil, fmt.Errorf("failed to save payment: %w", err)
+	}
+
+	metadata := map[string]string{
+		"user_id":        userID,
+		"payment_id":     payment.ID,
+		"transaction_id": transactionID,
+	}
+
+	start := time.Now()
+	providerID, err := provider.AuthorizePayment(ctx, amount, payment.Currency, card.ProviderID, metadata)
+	observeProvider(provider, "authorize_payment", start)
+	if err != nil {
+		payment.Status = domain.PaymentStatusFailed
+		payment.FailureReason = err.Error()
+		payment.UpdatedAt = time.Now()
+		s.repo.SavePayment(ctx, payment)
+
+		s.log.Warn("Payment authorization failed",
+			zap.String("payment_id", payment.ID),
+			zap.String("transaction_id", transactionID),
+			zap.Error(err),
+		)
+		return payment, fmt.Errorf("payment authorization failed: %w", err)
+	}
+
+	payment.ProviderID = providerID
+	payment.Status = domain.PaymentStatusAuthorized
+	payment.UpdatedAt = time.Now()
+	if err := s.repo.SavePayment(ctx, payment); err != nil {
+		s.log.Error("Failed to update payment record",
+			zap.String("payment_id", payment.ID),
+			zap.Error(err),
+		)
+	}
+
+	s.log.Info("Payment authorized",
+		zap.String("payment_id", payment.ID),
+		zap.String("transaction_id", transactionID),
+		zap.Float64("amount", amount),
+	)
+	return payment, nil
+}
+
+// Capture charges amount of an authorized payment and releases the rest of
+// the hold. A zero amount voids the hold instead. Capturing more than was
+// authorized fails with domain.ErrCaptureExceedsHold.
+func (s *Service) Capture(ctx context.Context, paymentID string, amount float64) (payment *domain.Payment, err error) {
+	ctx, span := tracer.Start(ctx, "payment.Capture", trace.WithAttributes(
+		attribute.String("payment.id", paymentID),
+		attribute.Float64("payment.amount", amount),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if amount <= 0 {
+		return s.Void(ctx, paymentID)
+	}
+
+	payment, provider, err := s.authorized(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if amount > payment.AuthorizedAmount {
+		return nil, fmt.Errorf("%w: %.2f of %.2f", domain.ErrCaptureExceedsHold, amount, payment.AuthorizedAmount)
+	}
+
+	start := time.Now()
+	err = provider.CapturePayment(ctx, payment.ProviderID, amount)
+	observeProvider(provider, "capture_payment", start)
+	telemetry.RecordPayment(provider.Name(), string(payment.Method), err == nil)
+	if err != nil {
+		s.log.Error("Payment capture failed",
+			zap.String("payment_id", payment.ID),
+			zap.Error(err),
+		)
+		return payment, fmt.Errorf("payment capture failed: %w", err)
+	}
+
+	now := time.Now()
+	payment.Amount = amount
+	payment.Status = domain.PaymentStatusCompleted
+	payment.CompletedAt = &now
+	payment.UpdatedAt = now
+	if err := s.repo.SavePayment(ctx, payment); err != nil {
+		s.log.Error("Failed to update payment record",
+			zap.String("payment_id", payment.ID),
+			zap.Error(err),
+		)
+	}
+
+	s.log.Info("Payment captured",
+		zap.String("payment_id", payment.ID),
+		zap.Float64("amount", amount),
+		zap.Float64("released", payment.AuthorizedAmount-amount),
+	)
+	return payment, nil
+}
+
+// Void releases an authorized payment without charging it
+func (s *Service) Void(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	payment, provider, err := s.authorized(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	err = provider.VoidPayment(ctx, payment.ProviderID)
+	observeProvider(provider, "void_payment", start)
+	if err != nil {
+		s.log.Error("Payment void failed",
+			zap.String("payment_id", payment.ID),
+			zap.Error(err),
+		)
+		return payment, fmt.Errorf("payment void failed: %w", err)
+	}
+
+	payment.Status = domain.PaymentStatusCancelled
+	payment.UpdatedAt = time.Now()
+	if err := s.repo.SavePayment(ctx, payment); err != nil {
+		s.log.Error("Failed to update payment record",
+			zap.String("payment_id", payment.ID),
+			zap.Error(err),
+		)
+	}
+
+	s.log.Info("Payment voided",
+		zap.String("payment_id", payment.ID),
+		zap.Float64("released", payment.AuthorizedAmount),
+	)
+	return payment, nil
+}
+
+// defaultCard returns the card a user stored at provider, preferring their
+// default one
+func (s *Service) defaultCard(ctx context.Context, userID string, provider domain.PaymentProvider) (*domain.PaymentCard, error) {
+	if s.cards == nil {
+		return nil, ErrNoPaymentCard
+	}
+	cards, err := s.cards.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payment cards: %w", err)
+	}
+	// Default card first
+	for i := range cards {
+		if cards[i].Provider == provider && cards[i].ProviderID != "" {
+			return &cards[i], nil
+		}
+	}
+	return nil, ErrNoPaymentCard
+}
+
+// authorized loads a payment that holds funds awaiting capture, and its
+// provider
+func (s *Service) authorized(ctx context.Context, paymentID string) (*domain.Payment, Provider, error) {
+	payment, err := s.repo.GetPayment(ctx, paymentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("payment not found: %w", err)
+	}
+	if payment == nil {
+		return nil, nil, fmt.Errorf("payment not found: %s", paymentID)
+	}
+	if payment.Status != domain.PaymentStatusAuthorized {
+		return nil, nil, fmt.Errorf("payment %s is %s, not authorized", paymentID, payment.Status)
+	}
+
+	provider, err := s.getProvider(payment.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payment, provider, nil
+}
@@ -0,0 +1,208 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// holdingProvider authorizes holds and records what was captured or voided
+type holdingProvider struct {
+	Provider
+	authorizeErr error
+	held         float64
+	heldOn       string // Payment method of the hold
+	captured     []float64
+	voided       []string
+}
+
+func (h *holdingProvider) Name() string { return "fake" }
+
+func (h *holdingProvider) AuthorizePayment(ctx context.Context, amount float64, currency string, paymentMethodID string, metadata map[string]string) (string, error) {
+	if h.authorizeErr != nil {
+		return "", h.authorizeErr
+	}
+	h.held = amount
+	h.heldOn = paymentMethodID
+	return "fake_hold_1", nil
+}
+
+func (h *holdingProvider) CapturePayment(ctx context.Context, paymentID string, amount float64) error {
+	h.captured = append(h.captured, amount)
+	return nil
+}
+
+func (h *holdingProvider) VoidPayment(ctx context.Context, paymentID string) error {
+	h.voided = append(h.voided, paymentID)
+	return nil
+}
+
+func newHoldingService(t *testing.T, provider *holdingProvider) (*Service, *mockPaymentRepository) {
+	t.Helper()
+
+	repo := newMockPaymentRepository()
+	svc, err := NewService(&Config{DefaultProvider: "fake", DefaultCurrency: "BRL"}, repo, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create payment service: %v", err)
+	}
+	svc.providers["fake"] = provider
+
+	cards := memory.NewCardRepository()
+	cards.Save(context.Background(), &domain.PaymentCard{ID: "card-1", UserID: "user-1", Provider: "fake", ProviderID: "pm_old"})
+	cards.Save(context.Background(), &domain.PaymentCard{ID: "card-2", UserID: "user-1", Provider: "fake", ProviderID: "pm_default", IsDefault: true})
+	svc.SetCardRepository(cards)
+	return svc, repo
+}
+
+func TestAuthorize_CaptureLessThanHold(t *testing.T) {
+	// Arrange
+	provider := &holdingProvider{}
+	svc, repo := newHoldingService(t, provider)
+	ctx := context.Background()
+
+	// Act
+	hold, authErr := svc.Authorize(ctx, "user-1", "tx-1", 90)
+	payment, captureErr := svc.Capture(ctx, hold.ID, 23.45)
+
+	// Assert
+	if authErr != nil || captureErr != nil {
+		t.Fatalf("expected no errors, got %v, %v", authErr, captureErr)
+	}
+	if provider.held != 90 {
+		t.Errorf("expected 90 to be held, got %v", provider.held)
+	}
+	if len(provider.captured) != 1 || provider.captured[0] != 23.45 {
+		t.Errorf("expected 23.45 to be captured, got %v", provider.captured)
+	}
+	stored, _ := repo.GetPayment(ctx, hold.ID)
+	if stored.Status != domain.PaymentStatusCompleted || stored.Amount != 23.45 || stored.AuthorizedAmount != 90 {
+		t.Errorf("expected a completed payment of 23.45 out of 90, got %s %.2f of %.2f",
+			stored.Status, stored.Amount, stored.AuthorizedAmount)
+	}
+	if payment.ProviderID != "fake_hold_1" || payment.TransactionID != "tx-1" {
+		t.Errorf("expected the hold to be captured for tx-1, got %+v", payment)
+	}
+	if len(provider.voided) != 0 {
+		t.Errorf("expected no void, got %v", provider.voided)
+	}
+}
+
+func TestAuthorize_Void(t *testing.T) {
+	tests := []struct {
+		name    string
+		release func(svc *Service, paymentID string) (*domain.Payment, error)
+	}{
+		{"void", func(svc *Service, paymentID string) (*domain.Payment, error) {
+			return svc.Void(context.Background(), paymentID)
+		}},
+		{"capture nothing", func(svc *Service, paymentID string) (*domain.Payment, error) {
+			return svc.Capture(context.Background(), paymentID, 0)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			provider := &holdingProvider{}
+			svc, _ := newHoldingService(t, provider)
+			hold, err := svc.Authorize(context.Background(), "user-1", "tx-1", 90)
+			if err != nil {
+				t.Fatalf("expected the hold to be placed, got %v", err)
+			}
+
+			// Act
+			payment, err := tt.release(svc, hold.ID)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if payment.Status != domain.PaymentStatusCancelled || payment.Amount != 0 {
+				t.Errorf("expected a cancelled payment of nothing, got %s %.2f", payment.Status, payment.Amount)
+			}
+			if len(provider.voided) != 1 || provider.voided[0] != "fake_hold_1" {
+				t.Errorf("expected the hold to be voided, got %v", provider.voided)
+			}
+			if len(provider.captured) != 0 {
+				t.Errorf("expected nothing captured, got %v", provider.captured)
+			}
+			if _, err := svc.Capture(context.Background(), hold.ID, 10); err == nil {
+				t.Error("expected a voided hold not to be captured")
+			}
+		})
+	}
+}
+
+func TestCapture_MoreThanHold(t *testing.T) {
+	// Arrange
+	provider := &holdingProvider{}
+	svc, _ := newHoldingService(t, provider)
+	hold, _ := svc.Authorize(context.Background(), "user-1", "tx-1", 90)
+
+	// Act
+	_, err := svc.Capture(context.Background(), hold.ID, 90.01)
+
+	// Assert
+	if !errors.Is(err, domain.ErrCaptureExceedsHold) {
+		t.Errorf("expected ErrCaptureExceedsHold, got %v", err)
+	}
+	if len(provider.captured) != 0 {
+		t.Errorf("expected nothing captured, got %v", provider.captured)
+	}
+}
+
+func TestAuthorize_Declined(t *testing.T) {
+	// Arrange
+	svc, repo := newHoldingService(t, &holdingProvider{authorizeErr: errors.New("card declined")})
+
+	// Act
+	payment, err := svc.Authorize(context.Background(), "user-1", "tx-1", 90)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected the authorization to fail")
+	}
+	stored, _ := repo.GetPayment(context.Background(), payment.ID)
+	if stored.Status != domain.PaymentStatusFailed || stored.FailureReason == "" {
+		t.Errorf("expected a failed payment with its reason, got %s %q", stored.Status, stored.FailureReason)
+	}
+}
+
+func TestAuthorize_HoldsOnDefaultCard(t *testing.T) {
+	// Arrange
+	provider := &holdingProvider{}
+	svc, _ := newHoldingService(t, provider)
+
+	// Act
+	_, err := svc.Authorize(context.Background(), "user-1", "tx-1", 90)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if provider.heldOn != "pm_default" {
+		t.Errorf("expected the hold on pm_default, got %q", provider.heldOn)
+	}
+}
+
+func TestAuthorize_NoCard(t *testing.T) {
+	// Arrange
+	provider := &holdingProvider{}
+	svc, repo := newHoldingService(t, provider)
+
+	// Act
+	_, err := svc.Authorize(context.Background(), "user-2", "tx-1", 90)
+
+	// Assert
+	if !errors.Is(err, ErrNoPaymentCard) {
+		t.Errorf("expected ErrNoPaymentCard, got %v", err)
+	}
+	if repo.saved != 0 {
+		t.Errorf("expected no payment saved, got %d", repo.saved)
+	}
+}
@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ErrNoFXRate is returned when no exchange rate is known for a currency pair
+var ErrNoFXRate = errors.New("no exchange rate for currency pair")
+
+// StaticFXRates is an FXRateSource backed by fixed rates, typically from
+// configuration. Each rate is the value of one unit of the currency in the
+// base currency.
+type StaticFXRates struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewStaticFXRates creates a rate source quoting rates against base, e.g.
+// base BRL with {"USD": 5.0} means one USD buys five BRL
+func NewStaticFXRates(base string, rates map[string]float64) *StaticFXRates {
+	normalized := make(map[string]float64, len(rates)+1)
+	for currency, rate := range rates {
+		normalized[strings.ToUpper(currency)] = rate
+	}
+	normalized[strings.ToUpper(base)] = 1
+	return &StaticFXRates{base: strings.ToUpper(base), rates: normalized}
+}
+
+// Ensure StaticFXRates implements ports.FXRateSource
+var _ ports.FXRateSource = (*StaticFXRates)(nil)
+
+// Rate returns how many units of to one unit of from buys
+func (r *StaticFXRates) Rate(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+	fromRate, ok := r.rates[from]
+	if !ok || fromRate <= 0 {
+		return 0, fmt.Errorf("%w: %s/%s", ErrNoFXRate, from, to)
+	}
+	toRate, ok := r.rates[to]
+	if !ok || toRate <= 0 {
+		return 0, fmt.Errorf("%w: %s/%s", ErrNoFXRate, from, to)
+	}
+	return fromRate / toRate, nil
+}
+
+// convert returns amount of from in currency to, rounded to cents, and the
+// rate applied
+func convert(ctx context.Context, rates ports.FXRateSource, amount float64, from, to string) (float64, float64, error) {
+	if strings.EqualFold(from, to) {
+		return amount, 1, nil
+	}
+	if rates == nil {
+		return 0, 0, fmt.Errorf("%w: %s/%s", ErrNoFXRate, from, to)
+	}
+	rate, err := rates.Rate(ctx, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+	return math.Round(amount*rate*100) / 100, rate, nil
+}
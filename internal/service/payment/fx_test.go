@@ -0,0 +1,173 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+func TestStaticFXRates_Rate(t *testing.T) {
+	rates := NewStaticFXRates("BRL", map[string]float64{"USD": 5.0, "eur": 5.5})
+
+	tests := []struct {
+		from, to string
+		want     float64
+		wantErr  bool
+	}{
+		{from: "USD", to: "BRL", want: 5.0},
+		{from: "BRL", to: "USD", want: 0.2},
+		{from: "EUR", to: "USD", want: 1.1},
+		{from: "BRL", to: "BRL", want: 1},
+		{from: "BRL", to: "GBP", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"/"+tt.to, func(t *testing.T) {
+			rate, err := rates.Rate(context.Background(), tt.from, tt.to)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoFXRate) {
+					t.Errorf("expected ErrNoFXRate, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if diff := rate - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("expected rate %v, got %v", tt.want, rate)
+			}
+		})
+	}
+}
+
+func TestProcessChargingPayment_CrossCurrencyAppliesFXRate(t *testing.T) {
+	// Arrange: a USD wallet paying for a charge billed in BRL
+	walletRepo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 20, Currency: "USD"})
+	paymentRepo := newMockPaymentRepository()
+	svc, _ := NewService(&Config{
+		DefaultCurrency: "BRL",
+		FXRates:         map[string]float64{"USD": 5.0},
+	}, paymentRepo, NewWalletService(walletRepo, zap.NewNop()), zap.NewNop())
+
+	// Act
+	payment, err := svc.ProcessChargingPayment(context.Background(), "user-1", "tx-1", 37.5)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if payment.Method != domain.PaymentMethodWallet {
+		t.Fatalf("expected a wallet payment, got %s", payment.Method)
+	}
+	if payment.Amount != 37.5 || payment.Currency != "BRL" {
+		t.Errorf("expected 37.50 BRL billed, got %.2f %s", payment.Amount, payment.Currency)
+	}
+	if payment.FXRate != 0.2 || payment.SettledAmount != 7.5 || payment.SettledCurrency != "USD" {
+		t.Errorf("expected 7.50 USD settled at 0.2, got %.2f %s at %v", payment.SettledAmount, payment.SettledCurrency, payment.FXRate)
+	}
+	wallet, _ := walletRepo.GetByID(context.Background(), "wallet-1")
+	if wallet.Balance != 12.5 {
+		t.Errorf("expected 12.50 USD left, got %.2f", wallet.Balance)
+	}
+	if paymentRepo.payments[payment.ID] == nil {
+		t.Error("expected the payment to be stored")
+	}
+}
+
+func TestProcessChargingPayment_WithoutFXRateSkipsWallet(t *testing.T) {
+	// Arrange: no rate for EUR, so the wallet cannot settle a BRL charge
+	walletRepo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 100, Currency: "EUR"})
+	svc, _ := NewService(&Config{
+		DefaultCurrency: "BRL",
+		FXRates:         map[string]float64{"USD": 5.0},
+	}, newMockPaymentRepository(), NewWalletService(walletRepo, zap.NewNop()), zap.NewNop())
+
+	// Act: with no card provider configured the fallback fails
+	_, err := svc.ProcessChargingPayment(context.Background(), "user-1", "tx-1", 10)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected the card fallback to fail without a provider")
+	}
+	wallet, _ := walletRepo.GetByID(context.Background(), "wallet-1")
+	if wallet.Balance != 100 {
+		t.Errorf("expected the EUR wallet untouched, got %.2f", wallet.Balance)
+	}
+}
+
+func TestWalletService_DeductFundsRejectsCurrencyNotHeld(t *testing.T) {
+	// Arrange
+	repo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 50, Currency: "BRL"})
+	svc := NewWalletService(repo, zap.NewNop())
+
+	// Act
+	err := svc.DeductFunds(context.Background(), "user-1", 10, "USD", "charging", "tx-1")
+
+	// Assert
+	if !errors.Is(err, domain.ErrCurrencyNotHeld) {
+		t.Fatalf("expected ErrCurrencyNotHeld, got %v", err)
+	}
+	wallet, _ := repo.GetByID(context.Background(), "wallet-1")
+	if wallet.Balance != 50 {
+		t.Errorf("expected balance 50, got %.2f", wallet.Balance)
+	}
+}
+
+func TestWalletService_AddFundsAdoptsCurrencyOfEmptyWallet(t *testing.T) {
+	// Arrange
+	repo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Currency: "BRL"})
+	svc := NewWalletService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	// Act
+	if err := svc.AddFunds(ctx, "user-1", 25, "USD", "pay-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err := svc.AddFunds(ctx, "user-1", 10, "EUR", "pay-2")
+
+	// Assert
+	wallet, _ := repo.GetByID(ctx, "wallet-1")
+	if wallet.Currency != "USD" || wallet.Balance != 25 {
+		t.Errorf("expected 25 USD, got %.2f %s", wallet.Balance, wallet.Currency)
+	}
+	if !errors.Is(err, domain.ErrCurrencyNotHeld) {
+		t.Errorf("expected a EUR top-up into a funded USD wallet to be rejected, got %v", err)
+	}
+}
+
+func TestWalletService_ConvertsAmountsInAnotherCurrency(t *testing.T) {
+	// Arrange
+	repo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 50, Currency: "USD"})
+	svc := NewWalletService(repo, zap.NewNop())
+	svc.SetFXRateSource(NewStaticFXRates("BRL", map[string]float64{"USD": 5.0}))
+	ctx := context.Background()
+
+	// Act
+	enough, err := svc.HasSufficientBalance(ctx, "user-1", 250, "BRL")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := svc.DeductFunds(ctx, "user-1", 25, "BRL", "Reservation fee", "res-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err = svc.AddFunds(ctx, "user-1", 10, "BRL", "res-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !enough {
+		t.Error("expected 250 BRL to be covered by 50 USD")
+	}
+	wallet, _ := repo.GetByID(ctx, "wallet-1")
+	if wallet.Currency != "USD" || wallet.Balance != 47 {
+		t.Errorf("expected 47 USD, got %.2f %s", wallet.Balance, wallet.Currency)
+	}
+	if len(repo.transactions) != 2 || repo.transactions[0].Amount != 5 || repo.transactions[1].Amount != 2 {
+		t.Errorf("expected the wallet transactions in USD, got %+v", repo.transactions)
+	}
+}
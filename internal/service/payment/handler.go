@@ -0,0 +1,110 @@
+package payment
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// webhookSignatureHeaders maps each provider to the header carrying its webhook signature
+var webhookSignatureHeaders = map[string]string{
+	"stripe":    "Stripe-Signature",
+	"pagseguro": "X-Authenticity-Token",
+}
+
+// Handler handles payment HTTP requests
+type Handler struct {
+	service ports.PaymentService
+	log     *zap.Logger
+}
+
+// NewHandler creates a new payment handler
+func NewHandler(service ports.PaymentService, log *zap.Logger) *Handler {
+	return &Handler{service: service, log: log}
+}
+
+// RegisterRoutes registers payment routes. Middleware, such as replay
+// protection, runs before the webhook handler.
+func (h *Handler) RegisterRoutes(app *fiber.App, middleware ...fiber.Handler) {
+	// Webhooks are authenticated by the provider signature, not by JWT
+	app.Post("/api/v1/payments/webhook/:provider", append(middleware, h.HandleWebhook)...)
+}
+
+// SignedReplaySource returns, for replay protection, the time a provider
+// sent a webhook at and its signature as the nonce. Stripe signs its t=
+// timestamp along with the body, so a replay cannot pass for a new delivery
+// without the provider's secret. PagSeguro signs only the body and keeps
+// retrying a delivery for days with its original created_at, so its
+// webhooks are not held to the window: the time of receipt is used and the
+// event ID claimed by HandleWebhook dedupes them.
+func SignedReplaySource(c *fiber.Ctx) (timestamp, nonce string) {
+	switch strings.ToLower(c.Params("provider")) {
+	case "stripe":
+		for _, part := range strings.Split(c.Get(webhookSignatureHeaders["stripe"]), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch {
+			case key == "t":
+				timestamp = value
+			case key == "v1" && nonce == "":
+				nonce = value
+			}
+		}
+	case "pagseguro":
+		timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+		nonce = c.Get(webhookSignatureHeaders["pagseguro"])
+	}
+	return timestamp, nonce
+}
+
+// WebhookReplayScope keeps each provider's nonces apart
+func WebhookReplayScope(c *fiber.Ctx) string {
+	return "payment:" + strings.ToLower(c.Params("provider"))
+}
+
+// HandleWebhook handles POST /api/v1/payments/webhook/:provider
+func (h *Handler) HandleWebhook(c *fiber.Ctx) error {
+	provider := strings.ToLower(c.Params("provider"))
+
+	header, ok := webhookSignatureHeaders[provider]
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Unknown payment provider",
+		})
+	}
+
+	// The signature is computed over the exact bytes the provider sent. Copy
+	// them out of the fasthttp buffer (which is reused after the handler
+	// returns) and never run them through BodyParser.
+	payload := append([]byte(nil), c.Body()...)
+	signature := c.Get(header)
+
+	if err := h.service.HandleWebhook(c.Context(), provider, payload, signature); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidWebhookSignature):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid webhook signature",
+			})
+		case errors.Is(err, ErrUnknownProvider):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Unknown payment provider",
+			})
+		}
+
+		// Anything else is on our side; a non-2xx makes the provider retry
+		h.log.Error("Failed to process payment webhook",
+			zap.String("provider", provider),
+			zap.Error(err),
+		)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process webhook",
+		})
+	}
+
+	return c.JSON(fiber.Map{"received": true})
+}
@@ -0,0 +1,547 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stripe/stripe-go/v76/webhook"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+const testWebhookSecret = "whsec_test_secret"
+
+// mockPaymentRepository is an in-memory PaymentRepository for webhook tests.
+// It hands out copies, so a payment changed but not saved stays as stored.
+type mockPaymentRepository struct {
+	mu       sync.Mutex
+	payments map[string]*domain.Payment
+	events   map[string]bool
+	saved    int
+	// saveFailures fails that many SavePayment calls
+	saveFailures int
+}
+
+func newMockPaymentRepository(payments ...*domain.Payment) *mockPaymentRepository {
+	m := &mockPaymentRepository{payments: make(map[string]*domain.Payment), events: make(map[string]bool)}
+	for _, p := range payments {
+		m.payments[p.ID] = p
+	}
+	return m
+}
+
+func (m *mockPaymentRepository) SavePayment(ctx context.Context, payment *domain.Payment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.saveFailures > 0 {
+		m.saveFailures--
+		return errors.New("database unavailable")
+	}
+	stored := *payment
+	m.payments[payment.ID] = &stored
+	m.saved++
+	return nil
+}
+
+func (m *mockPaymentRepository) GetPayment(ctx context.Context, id string) (*domain.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.payments[id]; ok {
+		payment := *p
+		return &payment, nil
+	}
+	return nil, errors.New("payment not found")
+}
+
+func (m *mockPaymentRepository) GetPaymentByProviderID(ctx context.Context, providerID string) (*domain.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.payments {
+		if p.ProviderID == providerID {
+			payment := *p
+			return &payment, nil
+		}
+	}
+	return nil, errors.New("payment not found")
+}
+
+func (m *mockPaymentRepository) GetPaymentsByUser(ctx context.Context, userID string, limit, offset int) ([]domain.Payment, error) {
+	return nil, nil
+}
+
+func (m *mockPaymentRepository) GetPaymentsByTransaction(ctx context.Context, transactionID string) ([]domain.Payment, error) {
+	return nil, nil
+}
+
+func (m *mockPaymentRepository) SaveRefund(ctx context.Context, refund *domain.Refund) error {
+	return nil
+}
+
+func (m *mockPaymentRepository) GetRefundsByPayment(ctx context.Context, paymentID string) ([]domain.Refund, error) {
+	return nil, nil
+}
+
+func (m *mockPaymentRepository) ClaimWebhookEvent(ctx context.Context, provider, eventID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.events[provider+"/"+eventID] {
+		return false, nil
+	}
+	m.events[provider+"/"+eventID] = true
+	return true, nil
+}
+
+func (m *mockPaymentRepository) ReleaseWebhookEvent(ctx context.Context, provider, eventID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.events, provider+"/"+eventID)
+	return nil
+}
+
+// topUpWallet counts the funds added to an empty wallet, failing the first
+// failures credits
+type topUpWallet struct {
+	ports.WalletService
+	mu       sync.Mutex
+	credits  []float64
+	failures int
+}
+
+func (w *topUpWallet) GetWallet(ctx context.Context, userID string) (*domain.Wallet, error) {
+	return &domain.Wallet{UserID: userID, Currency: "BRL"}, nil
+}
+
+func (w *topUpWallet) AddFunds(ctx context.Context, userID string, amount float64, currency string, paymentID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("wallet store unavailable")
+	}
+	w.credits = append(w.credits, amount)
+	return nil
+}
+
+func newWebhookTestApp(t *testing.T, repo *mockPaymentRepository) *fiber.App {
+	t.Helper()
+
+	svc, err := NewService(&Config{
+		DefaultProvider:     domain.PaymentProviderStripe,
+		DefaultCurrency:     "BRL",
+		StripeSecretKey:     "sk_test_dummy",
+		StripeWebhookSecret: testWebhookSecret,
+	}, repo, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create payment service: %v", err)
+	}
+
+	app := fiber.New()
+	NewHandler(svc, zap.NewNop()).RegisterRoutes(app)
+	return app
+}
+
+func postStripeWebhook(t *testing.T, app *fiber.App, payload []byte, signature string) int {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/api/v1/payments/webhook/stripe", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Stripe-Signature", signature)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp.StatusCode
+}
+
+// Deliberately not canonical JSON (spacing, key order) so any re-encoding
+// of the body would break the signature.
+var succeededPayload = []byte(`{"id": "evt_1", "type": "payment_intent.succeeded",
+  "api_version": "2020-08-27",
+  "data": {"object": {"id": "pi_123", "object": "payment_intent", "amount": 5000}}}`)
+
+func TestHandleWebhook_ValidStripeSignature(t *testing.T) {
+	// Arrange
+	repo := newMockPaymentRepository(&domain.Payment{
+		ID:         "pay-1",
+		UserID:     "user-1",
+		ProviderID: "pi_123",
+		Provider:   domain.PaymentProviderStripe,
+		Status:     domain.PaymentStatusPending,
+		Amount:     50,
+	})
+	app := newWebhookTestApp(t, repo)
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: succeededPayload,
+		Secret:  testWebhookSecret,
+	})
+
+	// Act
+	status := postStripeWebhook(t, app, signed.Payload, signed.Header)
+
+	// Assert
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status 200, got %d", status)
+	}
+	payment, _ := repo.GetPayment(context.Background(), "pay-1")
+	if payment.Status != domain.PaymentStatusCompleted {
+		t.Errorf("expected status %s, got %s", domain.PaymentStatusCompleted, payment.Status)
+	}
+	if payment.CompletedAt == nil {
+		t.Error("expected CompletedAt to be set")
+	}
+}
+
+func TestHandleWebhook_ReplayedEventCreditsWalletOnce(t *testing.T) {
+	// Arrange
+	repo := newMockPaymentRepository(&domain.Payment{
+		ID:         "pay-1",
+		UserID:     "user-1",
+		ProviderID: "pi_123",
+		Provider:   domain.PaymentProviderStripe,
+		Status:     domain.PaymentStatusPending,
+		Amount:     50,
+	})
+	wallet := &topUpWallet{}
+	svc, err := NewService(&Config{
+		DefaultProvider:     domain.PaymentProviderStripe,
+		DefaultCurrency:     "BRL",
+		StripeSecretKey:     "sk_test_dummy",
+		StripeWebhookSecret: testWebhookSecret,
+	}, repo, wallet, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create payment service: %v", err)
+	}
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: succeededPayload,
+		Secret:  testWebhookSecret,
+	})
+
+	// Act
+	for i := 0; i < 2; i++ {
+		if err := svc.HandleWebhook(context.Background(), "stripe", signed.Payload, signed.Header); err != nil {
+			t.Fatalf("delivery %d: expected no error, got %v", i+1, err)
+		}
+	}
+
+	// Assert
+	if len(wallet.credits) != 1 || wallet.credits[0] != 50 {
+		t.Errorf("expected a single 50 top-up, got %v", wallet.credits)
+	}
+	if repo.saved != 1 {
+		t.Errorf("expected the replay to leave the payment alone, got %d saves", repo.saved)
+	}
+}
+
+func TestHandleWebhook_FailedCreditIsRetried(t *testing.T) {
+	// Arrange
+	repo := newMockPaymentRepository(&domain.Payment{
+		ID:         "pay-1",
+		UserID:     "user-1",
+		ProviderID: "pi_123",
+		Provider:   domain.PaymentProviderStripe,
+		Status:     domain.PaymentStatusPending,
+		Amount:     50,
+	})
+	wallet := &topUpWallet{failures: 1}
+	svc, err := NewService(&Config{
+		DefaultProvider:     domain.PaymentProviderStripe,
+		DefaultCurrency:     "BRL",
+		StripeSecretKey:     "sk_test_dummy",
+		StripeWebhookSecret: testWebhookSecret,
+	}, repo, wallet, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create payment service: %v", err)
+	}
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: succeededPayload,
+		Secret:  testWebhookSecret,
+	})
+
+	// Act
+	first := svc.HandleWebhook(context.Background(), "stripe", signed.Payload, signed.Header)
+	pending, _ := repo.GetPayment(context.Background(), "pay-1")
+	pendingStatus := pending.Status
+	retry := svc.HandleWebhook(context.Background(), "stripe", signed.Payload, signed.Header)
+
+	// Assert
+	if first == nil {
+		t.Fatal("expected the failed credit to fail the delivery")
+	}
+	if pendingStatus != domain.PaymentStatusPending {
+		t.Errorf("expected the payment left pending, got %s", pendingStatus)
+	}
+	if retry != nil {
+		t.Fatalf("expected the retry to succeed, got %v", retry)
+	}
+	if len(wallet.credits) != 1 || wallet.credits[0] != 50 {
+		t.Errorf("expected a single 50 top-up, got %v", wallet.credits)
+	}
+	payment, _ := repo.GetPayment(context.Background(), "pay-1")
+	if payment.Status != domain.PaymentStatusCompleted {
+		t.Errorf("expected status %s, got %s", domain.PaymentStatusCompleted, payment.Status)
+	}
+}
+
+func TestHandleWebhook_RetryAfterFailedSaveCreditsWalletOnce(t *testing.T) {
+	// Arrange
+	repo := newMockPaymentRepository(&domain.Payment{
+		ID:         "pay-1",
+		UserID:     "user-1",
+		ProviderID: "pi_123",
+		Provider:   domain.PaymentProviderStripe,
+		Status:     domain.PaymentStatusPending,
+		Amount:     50,
+		Currency:   "BRL",
+	})
+	repo.saveFailures = 1
+	walletRepo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Currency: "BRL"})
+	svc, err := NewService(&Config{
+		DefaultProvider:     domain.PaymentProviderStripe,
+		DefaultCurrency:     "BRL",
+		StripeSecretKey:     "sk_test_dummy",
+		StripeWebhookSecret: testWebhookSecret,
+	}, repo, NewWalletService(walletRepo, zap.NewNop()), zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create payment service: %v", err)
+	}
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: succeededPayload,
+		Secret:  testWebhookSecret,
+	})
+
+	// Act
+	first := svc.HandleWebhook(context.Background(), "stripe", signed.Payload, signed.Header)
+	retry := svc.HandleWebhook(context.Background(), "stripe", signed.Payload, signed.Header)
+
+	// Assert
+	if first == nil {
+		t.Fatal("expected the failed save to fail the delivery")
+	}
+	if retry != nil {
+		t.Fatalf("expected the retry to succeed, got %v", retry)
+	}
+	if len(walletRepo.transactions) != 1 {
+		t.Errorf("expected the wallet credited once, got %d credits", len(walletRepo.transactions))
+	}
+	wallet, _ := walletRepo.GetByID(context.Background(), "wallet-1")
+	if wallet.Balance != 50 {
+		t.Errorf("expected balance 50, got %.2f", wallet.Balance)
+	}
+	payment, _ := repo.GetPayment(context.Background(), "pay-1")
+	if payment.Status != domain.PaymentStatusCompleted {
+		t.Errorf("expected status %s, got %s", domain.PaymentStatusCompleted, payment.Status)
+	}
+}
+
+func TestHandleWebhook_ReplayedDeliveryRejected(t *testing.T) {
+	// Arrange
+	repo := newMockPaymentRepository(&domain.Payment{
+		ID:         "pay-1",
+		ProviderID: "pi_123",
+		Provider:   domain.PaymentProviderStripe,
+		Status:     domain.PaymentStatusPending,
+	})
+	svc, err := NewService(&Config{
+		DefaultProvider:     domain.PaymentProviderStripe,
+		StripeSecretKey:     "sk_test_dummy",
+		StripeWebhookSecret: testWebhookSecret,
+	}, repo, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create payment service: %v", err)
+	}
+	cfg := middleware.DefaultReplayProtectionConfig()
+	cfg.Source = SignedReplaySource
+	cfg.Scope = WebhookReplayScope
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+	NewHandler(svc, zap.NewNop()).RegisterRoutes(app, middleware.ReplayProtection(cache.NewLocalNonceStore(), cfg))
+
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: succeededPayload,
+		Secret:  testWebhookSecret,
+	})
+	resent := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload:   succeededPayload,
+		Secret:    testWebhookSecret,
+		Timestamp: signed.Timestamp.Add(time.Second),
+	})
+
+	// Act
+	first := postStripeWebhook(t, app, signed.Payload, signed.Header)
+	replayed := postStripeWebhook(t, app, signed.Payload, signed.Header)
+	retried := postStripeWebhook(t, app, resent.Payload, resent.Header)
+	unsigned := postStripeWebhook(t, app, signed.Payload, "")
+
+	// Assert
+	if first != fiber.StatusOK {
+		t.Fatalf("expected status 200, got %d", first)
+	}
+	if replayed != fiber.StatusConflict {
+		t.Errorf("expected the replay to get 409, got %d", replayed)
+	}
+	if retried != fiber.StatusOK {
+		t.Errorf("expected a newly signed delivery to get 200, got %d", retried)
+	}
+	if unsigned != fiber.StatusBadRequest {
+		t.Errorf("expected a delivery without signature to get 400, got %d", unsigned)
+	}
+}
+
+func TestSignedReplaySource_PagSeguroRetriesAreNotStale(t *testing.T) {
+	// Arrange - PagSeguro retries with the created_at of the first attempt
+	cfg := middleware.DefaultReplayProtectionConfig()
+	cfg.Source = SignedReplaySource
+	cfg.Scope = WebhookReplayScope
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(zap.NewNop())})
+	app.Post("/api/v1/payments/webhook/:provider", middleware.ReplayProtection(cache.NewLocalNonceStore(), cfg), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	payload := `{"id":"CHAR_1","created_at":"` + time.Now().Add(-time.Hour).Format(time.RFC3339) + `"}`
+	post := func() int {
+		req := httptest.NewRequest("POST", "/api/v1/payments/webhook/pagseguro", bytes.NewReader([]byte(payload)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Authenticity-Token", "sig-1")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Act
+	first := post()
+	replayed := post()
+
+	// Assert
+	if first != fiber.StatusOK {
+		t.Errorf("expected an hour-old delivery to get 200, got %d", first)
+	}
+	if replayed != fiber.StatusConflict {
+		t.Errorf("expected the replay to get 409, got %d", replayed)
+	}
+}
+
+func TestHandleWebhook_InvalidStripeSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature func() string
+	}{
+		{
+			name: "wrong secret",
+			signature: func() string {
+				return webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+					Payload: succeededPayload,
+					Secret:  "whsec_other",
+				}).Header
+			},
+		},
+		{
+			name: "expired timestamp",
+			signature: func() string {
+				return webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+					Payload:   succeededPayload,
+					Secret:    testWebhookSecret,
+					Timestamp: time.Now().Add(-time.Hour),
+				}).Header
+			},
+		},
+		{
+			name:      "missing header",
+			signature: func() string { return "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := newMockPaymentRepository(&domain.Payment{
+				ID:         "pay-1",
+				ProviderID: "pi_123",
+				Status:     domain.PaymentStatusPending,
+			})
+			app := newWebhookTestApp(t, repo)
+
+			// Act
+			status := postStripeWebhook(t, app, succeededPayload, tt.signature())
+
+			// Assert
+			if status != fiber.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", status)
+			}
+			if repo.saved != 0 {
+				t.Errorf("expected no payment updates, got %d", repo.saved)
+			}
+		})
+	}
+}
+
+func TestHandleWebhook_TamperedBodyRejected(t *testing.T) {
+	// Arrange
+	repo := newMockPaymentRepository()
+	app := newWebhookTestApp(t, repo)
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: succeededPayload,
+		Secret:  testWebhookSecret,
+	})
+	tampered := bytes.Replace(signed.Payload, []byte("5000"), []byte("9000"), 1)
+
+	// Act
+	status := postStripeWebhook(t, app, tampered, signed.Header)
+
+	// Assert
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", status)
+	}
+}
+
+func TestHandleWebhook_UnknownEventAcknowledged(t *testing.T) {
+	// Arrange
+	repo := newMockPaymentRepository(&domain.Payment{
+		ID:     "pay-1",
+		Status: domain.PaymentStatusPending,
+	})
+	app := newWebhookTestApp(t, repo)
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: []byte(`{"id":"evt_2","type":"customer.created","data":{"object":{"id":"cus_1"}}}`),
+		Secret:  testWebhookSecret,
+	})
+
+	// Act
+	status := postStripeWebhook(t, app, signed.Payload, signed.Header)
+
+	// Assert
+	if status != fiber.StatusOK {
+		t.Errorf("expected status 200, got %d", status)
+	}
+	if repo.saved != 0 {
+		t.Errorf("expected no payment updates, got %d", repo.saved)
+	}
+}
+
+func TestHandleWebhook_UnknownProvider(t *testing.T) {
+	// Arrange
+	app := newWebhookTestApp(t, newMockPaymentRepository())
+	req := httptest.NewRequest("POST", "/api/v1/payments/webhook/paypal", bytes.NewReader([]byte(`{}`)))
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("expected status 404, got %d", resp.StatusCode)
+	}
+}
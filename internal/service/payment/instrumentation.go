@@ -0,0 +1,45 @@
+package payment
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
+)
+
+// tracer resolves through the global provider, so spans are exported once
+// telemetry.InitTracer has run and are no-ops before that
+var tracer = otel.Tracer("github.com/seu-repo/sigec-ve/internal/service/payment")
+
+// endSpan records err on span, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// observeProvider records the latency of a provider call begun at start
+func observeProvider(provider Provider, operation string, start time.Time) {
+	telemetry.RecordPaymentProviderCall(provider.Name(), operation, time.Since(start).Seconds())
+}
+
+// recordWebhook records the outcome of a webhook. Unknown provider names
+// come from the URL, so they share one label instead of growing the series.
+func recordWebhook(provider string, err error) {
+	status := "processed"
+	switch {
+	case errors.Is(err, ErrUnknownProvider):
+		provider, status = "unknown", "rejected"
+	case errors.Is(err, ErrInvalidWebhookSignature):
+		status = "rejected"
+	case err != nil:
+		status = "failed"
+	}
+	telemetry.RecordPaymentWebhook(provider, status)
+}
@@ -0,0 +1,182 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// fakeProvider charges and refunds without calling out, failing with err
+type fakeProvider struct {
+	Provider
+	err error
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) ProcessPayment(ctx context.Context, amount float64, currency string, paymentMethodID string, metadata map[string]string) (string, error) {
+	return "fake_pay_1", f.err
+}
+
+func (f *fakeProvider) RefundPayment(ctx context.Context, paymentID string, amount float64) (string, error) {
+	return "fake_refund_1", f.err
+}
+
+func newFakeProviderService(t *testing.T, provider *fakeProvider, repo *mockPaymentRepository) *Service {
+	t.Helper()
+
+	svc, err := NewService(&Config{DefaultProvider: "fake", DefaultCurrency: "BRL"}, repo, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create payment service: %v", err)
+	}
+	svc.providers["fake"] = provider
+	return svc
+}
+
+// scrape returns the value of the sample of name with exactly labels from
+// the default registry, as served on /metrics
+func scrape(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if len(metric.GetLabel()) != len(labels) {
+				continue
+			}
+			matched := true
+			for _, label := range metric.GetLabel() {
+				matched = matched && labels[label.GetName()] == label.GetValue()
+			}
+			if !matched {
+				continue
+			}
+			if metric.GetCounter() != nil {
+				return metric.GetCounter().GetValue()
+			}
+			return float64(metric.GetHistogram().GetSampleCount())
+		}
+	}
+	return 0
+}
+
+func TestProcessPayment_RecordsMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status string
+	}{
+		{"completed", nil, "completed"},
+		{"failed", errors.New("card declined"), "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			svc := newFakeProviderService(t, &fakeProvider{err: tt.err}, newMockPaymentRepository())
+			payments := map[string]string{"provider": "fake", "method": "credit_card", "status": tt.status}
+			latency := map[string]string{"provider": "fake", "operation": "process_payment"}
+			paymentsBefore := scrape(t, "sigec_payments_total", payments)
+			callsBefore := scrape(t, "sigec_payment_provider_latency_seconds", latency)
+
+			// Act
+			svc.ProcessPayment(context.Background(), &ports.PaymentRequest{
+				UserID: "user-1",
+				Amount: 25,
+				Method: domain.PaymentMethodCreditCard,
+			})
+
+			// Assert
+			if got := scrape(t, "sigec_payments_total", payments) - paymentsBefore; got != 1 {
+				t.Errorf("expected one %s payment counted, got %v", tt.status, got)
+			}
+			if got := scrape(t, "sigec_payment_provider_latency_seconds", latency) - callsBefore; got != 1 {
+				t.Errorf("expected one provider call observed, got %v", got)
+			}
+		})
+	}
+}
+
+func TestRefundPayment_RecordsMetrics(t *testing.T) {
+	// Arrange
+	repo := newMockPaymentRepository(&domain.Payment{
+		ID:         "pay-1",
+		Provider:   "fake",
+		ProviderID: "fake_pay_1",
+		Status:     domain.PaymentStatusCompleted,
+		Amount:     25,
+	})
+	svc := newFakeProviderService(t, &fakeProvider{}, repo)
+	refunds := map[string]string{"provider": "fake", "status": "completed"}
+	before := scrape(t, "sigec_payment_refunds_total", refunds)
+
+	// Act
+	_, err := svc.RefundPayment(context.Background(), "pay-1", 0, "requested_by_customer")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := scrape(t, "sigec_payment_refunds_total", refunds) - before; got != 1 {
+		t.Errorf("expected one completed refund counted, got %v", got)
+	}
+}
+
+func TestHandleWebhook_RecordsUnknownProviderUnderOneLabel(t *testing.T) {
+	// Arrange
+	svc := newFakeProviderService(t, &fakeProvider{}, newMockPaymentRepository())
+	rejected := map[string]string{"provider": "unknown", "status": "rejected"}
+	before := scrape(t, "sigec_payment_webhooks_total", rejected)
+
+	// Act
+	err := svc.HandleWebhook(context.Background(), "paypal", nil, "")
+
+	// Assert
+	if !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("expected ErrUnknownProvider, got %v", err)
+	}
+	if got := scrape(t, "sigec_payment_webhooks_total", rejected) - before; got != 1 {
+		t.Errorf("expected one rejected webhook counted, got %v", got)
+	}
+}
+
+func TestProcessPayment_RecordsFailedSpan(t *testing.T) {
+	// Arrange
+	// tracer delegates to the first global provider set, so this is not
+	// restored; no other test in the package depends on tracing
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	svc := newFakeProviderService(t, &fakeProvider{err: errors.New("card declined")}, newMockPaymentRepository())
+
+	// Act
+	svc.ProcessPayment(context.Background(), &ports.PaymentRequest{UserID: "user-1", Amount: 25, Method: domain.PaymentMethodCreditCard})
+
+	// Assert
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(spans))
+	}
+	if spans[0].Name() != "payment.ProcessPayment" {
+		t.Errorf("expected span payment.ProcessPayment, got %s", spans[0].Name())
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("expected the span to be marked as an error, got %v", spans[0].Status().Code)
+	}
+}
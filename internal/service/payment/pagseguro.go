@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 const (
@@ -66,7 +67,7 @@ func (p *PagSeguroProvider) CreatePaymentIntent(ctx context.Context, amount floa
 				"reference_id": "charging",
 				"name":         "EV Charging",
 				"quantity":     1,
-				"unit_amount":  int(amount * 100),
+				"unit_amount":  toCents(amount),
 			},
 		},
 		"notification_urls": []string{
@@ -115,7 +116,7 @@ func (p *PagSeguroProvider) ProcessPayment(ctx context.Context, amount float64,
 		"reference_id": metadata["payment_id"],
 		"description":  "EV Charging Payment",
 		"amount": map[string]interface{}{
-			"value":    int(amount * 100),
+			"value":    toCents(amount),
 			"currency": "BRL",
 		},
 		"payment_method": map[string]interface{}{
@@ -147,6 +148,85 @@ func (p *PagSeguroProvider) ProcessPayment(ctx context.Context, amount float64,
 	return result.ID, nil
 }
 
+// AuthorizePayment pre-authorizes a card charge with PagSeguro, holding
+// the amount until it is captured or cancelled
+func (p *PagSeguroProvider) AuthorizePayment(ctx context.Context, amount float64, currency string, paymentMethodID string, metadata map[string]string) (string, error) {
+	reqBody := map[string]interface{}{
+		"reference_id": metadata["payment_id"],
+		"description":  "EV Charging Authorization",
+		"amount": map[string]interface{}{
+			"value":    toCents(amount),
+			"currency": "BRL",
+		},
+		"payment_method": map[string]interface{}{
+			"type":    "CREDIT_CARD",
+			"capture": false,
+			"card": map[string]interface{}{
+				"id": paymentMethodID,
+			},
+		},
+	}
+
+	resp, err := p.doRequest(ctx, "POST", "/charges", reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if result.Status != "AUTHORIZED" {
+		return "", fmt.Errorf("payment not authorized: %s", result.Status)
+	}
+
+	return result.ID, nil
+}
+
+// CapturePayment captures part or all of a pre-authorized PagSeguro charge
+func (p *PagSeguroProvider) CapturePayment(ctx context.Context, paymentID string, amount float64) error {
+	reqBody := map[string]interface{}{
+		"amount": map[string]interface{}{
+			"value": toCents(amount),
+		},
+	}
+
+	resp, err := p.doRequest(ctx, "POST", fmt.Sprintf("/charges/%s/capture", paymentID), reqBody)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Status string `json:"status"`
+	}
+
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if result.Status != "PAID" {
+		return fmt.Errorf("payment not captured: %s", result.Status)
+	}
+
+	return nil
+}
+
+// VoidPayment cancels a pre-authorized PagSeguro charge
+func (p *PagSeguroProvider) VoidPayment(ctx context.Context, paymentID string) error {
+	charge, err := p.GetPayment(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.RefundPayment(ctx, paymentID, charge.Amount)
+	return err
+}
+
 // CreatePixPayment creates a PIX payment
 func (p *PagSeguroProvider) CreatePixPayment(ctx context.Context, amount float64, description string, expiresIn time.Duration) (*domain.PixPayment, string, error) {
 	expirationDate := time.Now().Add(expiresIn).Format(time.RFC3339)
@@ -155,7 +235,7 @@ func (p *PagSeguroProvider) CreatePixPayment(ctx context.Context, amount float64
 		"reference_id": fmt.Sprintf("pix_%d", time.Now().UnixNano()),
 		"description":  description,
 		"amount": map[string]interface{}{
-			"value":    int(amount * 100),
+			"value":    toCents(amount),
 			"currency": "BRL",
 		},
 		"payment_method": map[string]interface{}{
@@ -219,7 +299,7 @@ func (p *PagSeguroProvider) CreateBoletoPayment(ctx context.Context, amount floa
 		"reference_id": fmt.Sprintf("boleto_%d", time.Now().UnixNano()),
 		"description":  "EV Charging - SIGEC-VE",
 		"amount": map[string]interface{}{
-			"value":    int(amount * 100),
+			"value":    toCents(amount),
 			"currency": "BRL",
 		},
 		"payment_method": map[string]interface{}{
@@ -295,7 +375,7 @@ func (p *PagSeguroProvider) CreateBoletoPayment(ctx context.Context, amount floa
 func (p *PagSeguroProvider) RefundPayment(ctx context.Context, paymentID string, amount float64) (string, error) {
 	reqBody := map[string]interface{}{
 		"amount": map[string]interface{}{
-			"value": int(amount * 100),
+			"value": toCents(amount),
 		},
 	}
 
@@ -390,6 +470,9 @@ func (p *PagSeguroProvider) ParseWebhook(payload []byte) (*WebhookEvent, error)
 
 	if len(event.Charges) > 0 {
 		charge := event.Charges[0]
+		// A notification is resent until acknowledged; each status change
+		// of the charge is a new event
+		webhookEvent.ID = event.ID + ":" + charge.ID + ":" + charge.Status
 		webhookEvent.PaymentID = charge.ID
 		webhookEvent.Status = p.mapStatus(charge.Status)
 		webhookEvent.Amount = float64(charge.Amount.Value) / 100
@@ -432,6 +515,9 @@ func (p *PagSeguroProvider) doRequest(ctx context.Context, method, path string,
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+p.token)
 	req.Header.Set("x-api-version", "4.0")
+	if isWriteMethod(method) && ports.IdempotencyKeyFrom(ctx) != "" {
+		req.Header.Set("x-idempotency-key", idempotencyKeyOf(req))
+	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
@@ -0,0 +1,216 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/circuitbreaker"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ErrProviderUnavailable is returned without calling the payment provider
+// while its circuit breaker is open
+var ErrProviderUnavailable = errors.New("payment provider unavailable")
+
+// RetryPolicy controls how failed payment provider requests are retried
+type RetryPolicy struct {
+	MaxRetries   int           // Retries after the first attempt
+	InitialDelay time.Duration // Backoff before the first retry, doubled on each retry
+	MaxDelay     time.Duration // Upper bound for backoff and Retry-After waits
+}
+
+// DefaultRetryPolicy returns the retry policy used for payment providers
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:   3,
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+	}
+}
+
+// resilientTransport retries failed provider requests and guards them with a
+// circuit breaker. Write requests get the caller's idempotency key, see
+// ports.WithIdempotencyKey, or a random one when they have none. The same key
+// is sent on every attempt so the provider can deduplicate a request whose
+// first response was lost.
+type resilientTransport struct {
+	base    http.RoundTripper
+	policy  RetryPolicy
+	breaker *circuitbreaker.CircuitBreaker
+	log     *zap.Logger
+}
+
+// providerBreaker returns the circuit breaker of the named provider. It is
+// taken from breakers when given, so its state is listed with the other
+// downstream breakers.
+func providerBreaker(breakers *circuitbreaker.Manager, provider string, log *zap.Logger) *circuitbreaker.CircuitBreaker {
+	settings := circuitbreaker.DownstreamSettings(circuitbreaker.DownstreamPayment)
+	settings.Name = circuitbreaker.DownstreamPayment + "." + provider
+	settings.OnStateChange = func(name string, from, to circuitbreaker.State) {
+		log.Warn("Payment provider circuit breaker state changed",
+			zap.String("name", name),
+			zap.String("from", from.String()),
+			zap.String("to", to.String()),
+		)
+	}
+
+	if breakers == nil {
+		return circuitbreaker.New(settings, log)
+	}
+	return breakers.Get(settings.Name, settings)
+}
+
+// newResilientClient returns an HTTP client guarded by breaker
+func newResilientClient(breaker *circuitbreaker.CircuitBreaker, policy RetryPolicy, log *zap.Logger) *http.Client {
+	return &http.Client{
+		Timeout: 80 * time.Second, // Covers all attempts; Stripe requests may take up to 80s
+		Transport: &resilientTransport{
+			base:    http.DefaultTransport,
+			policy:  policy,
+			breaker: breaker,
+			log:     log,
+		},
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The caller's key replaces the random one the Stripe client sets
+	header := req.Header.Clone()
+	if isWriteMethod(req.Method) && (header.Get("Idempotency-Key") == "" || ports.IdempotencyKeyFrom(req.Context()) != "") {
+		header.Set("Idempotency-Key", idempotencyKeyOf(req))
+	}
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req.Clone(ctx)
+		attemptReq.Header = header
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+			attemptReq.ContentLength = int64(len(body))
+		}
+
+		result, err := t.breaker.ExecuteCtx(ctx, func(ctx context.Context) (interface{}, error) {
+			resp, err := t.base.RoundTrip(attemptReq)
+			if err != nil {
+				return nil, err
+			}
+			// Provider outages count as failures; client errors do not
+			if resp.StatusCode >= 500 {
+				return resp, fmt.Errorf("server error: %d", resp.StatusCode)
+			}
+			return resp, nil
+		})
+		if circuitbreaker.IsCircuitOpen(err) || circuitbreaker.IsTooManyRequests(err) {
+			return nil, fmt.Errorf("%w (%s): %w", ErrProviderUnavailable, t.breaker.Name(), err)
+		}
+
+		resp, _ := result.(*http.Response)
+		if attempt >= t.policy.MaxRetries || !shouldRetry(ctx, resp, err) {
+			if resp != nil {
+				return resp, nil
+			}
+			return nil, err
+		}
+
+		delay := t.retryDelay(attempt, resp)
+		t.log.Warn("Retrying payment provider request",
+			zap.String("breaker", t.breaker.Name()),
+			zap.String("path", req.URL.Path),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// shouldRetry reports whether a failed attempt may be retried. Stripe's
+// Stripe-Should-Retry header overrides the status code when present.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if resp == nil {
+		return err != nil // Network error
+	}
+
+	switch resp.Header.Get("Stripe-Should-Retry") {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusConflict ||
+		resp.StatusCode >= 500
+}
+
+// retryDelay returns how long to wait before the next attempt, honoring the
+// provider's Retry-After header
+func (t *resilientTransport) retryDelay(attempt int, resp *http.Response) time.Duration {
+	delay := t.policy.InitialDelay << attempt
+
+	if resp != nil {
+		if after := resp.Header.Get("Retry-After"); after != "" {
+			if seconds, err := strconv.Atoi(after); err == nil {
+				delay = time.Duration(seconds) * time.Second
+			} else if at, err := http.ParseTime(after); err == nil {
+				delay = time.Until(at)
+			}
+		}
+	}
+
+	if delay > t.policy.MaxDelay {
+		delay = t.policy.MaxDelay
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return delay
+}
+
+// isWriteMethod reports whether method changes state on the provider
+// idempotencyKeyOf returns the key of the caller's payment, set with
+// ports.WithIdempotencyKey, scoped to the endpoint; other requests get a
+// random one
+func idempotencyKeyOf(req *http.Request) string {
+	if key := ports.IdempotencyKeyFrom(req.Context()); key != "" {
+		return key + ":" + req.URL.Path
+	}
+	return uuid.NewString()
+}
+
+func isWriteMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut ||
+		method == http.MethodPatch || method == http.MethodDelete
+}
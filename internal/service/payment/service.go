@@ -2,16 +2,34 @@ package payment
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/circuitbreaker"
+	"github.com/seu-repo/sigec-ve/internal/observability/telemetry"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
+var (
+	// ErrUnknownProvider is returned when a webhook targets a provider that is not configured
+	ErrUnknownProvider = errors.New("unknown payment provider")
+
+	// ErrInvalidWebhookSignature is returned when a webhook payload fails signature verification
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+	// ErrNoPaymentCard is returned when a hold is placed for a user with no
+	// card stored at the provider
+	ErrNoPaymentCard = errors.New("no payment card on file")
+)
+
 // Provider defines the interface for payment providers
 type Provider interface {
 	// CreatePaymentIntent creates a payment intent
@@ -26,6 +44,16 @@ type Provider interface {
 	// CreateBoletoPayment creates a Boleto payment
 	CreateBoletoPayment(ctx context.Context, amount float64, customerInfo map[string]string, expiresAt time.Time) (*domain.BoletoPayment, string, error)
 
+	// AuthorizePayment places a hold for amount on a card without charging it
+	AuthorizePayment(ctx context.Context, amount float64, currency string, paymentMethodID string, metadata map[string]string) (string, error)
+
+	// CapturePayment charges amount, at most the authorized amount, of a
+	// hold and releases the rest
+	CapturePayment(ctx context.Context, paymentID string, amount float64) error
+
+	// VoidPayment releases a hold without charging it
+	VoidPayment(ctx context.Context, paymentID string) error
+
 	// RefundPayment refunds a payment
 	RefundPayment(ctx context.Context, paymentID string, amount float64) (string, error)
 
@@ -52,6 +80,7 @@ type ProviderPayment struct {
 
 // WebhookEvent represents a webhook event from provider
 type WebhookEvent struct {
+	ID        string // Provider's event ID, the same on every delivery of the event
 	Type      string // payment.completed, payment.failed, etc
 	PaymentID string
 	Status    domain.PaymentStatus
@@ -64,6 +93,10 @@ type Config struct {
 	DefaultProvider domain.PaymentProvider
 	DefaultCurrency string
 
+	// FXRates values other currencies in DefaultCurrency (e.g. "USD": 5.0),
+	// used to settle charges from wallets held in those currencies
+	FXRates map[string]float64
+
 	// Stripe config
 	StripeSecretKey      string
 	StripeWebhookSecret  string
@@ -73,6 +106,10 @@ type Config struct {
 	PagSeguroEmail   string
 	PagSeguroToken   string
 	PagSeguroSandbox bool
+
+	// Breakers, when set, holds the providers' circuit breakers so their
+	// state is reported with the other downstream breakers
+	Breakers *circuitbreaker.Manager
 }
 
 // Service implements PaymentService interface
@@ -81,6 +118,8 @@ type Service struct {
 	providers map[domain.PaymentProvider]Provider
 	repo      ports.PaymentRepository
 	walletSvc ports.WalletService
+	cards     ports.CardRepository
+	fx        ports.FXRateSource
 	log       *zap.Logger
 }
 
@@ -96,7 +135,7 @@ func NewService(config *Config, repo ports.PaymentRepository, walletSvc ports.Wa
 
 	// Initialize Stripe provider if configured
 	if config.StripeSecretKey != "" {
-		stripeProvider := NewStripeProvider(config.StripeSecretKey, config.StripeWebhookSecret)
+		stripeProvider := NewStripeProvider(config.StripeSecretKey, config.StripeWebhookSecret, config.Breakers, log)
 		s.providers[domain.PaymentProviderStripe] = stripeProvider
 		log.Info("Stripe payment provider initialized")
 	}
@@ -112,9 +151,24 @@ func NewService(config *Config, repo ports.PaymentRepository, walletSvc ports.Wa
 		log.Warn("No payment providers configured")
 	}
 
+	if len(config.FXRates) > 0 {
+		s.fx = NewStaticFXRates(config.DefaultCurrency, config.FXRates)
+	}
+
 	return s, nil
 }
 
+// SetFXRateSource replaces the exchange rate source used for wallets held in
+// another currency than the one charges are billed in
+func (s *Service) SetFXRateSource(fx ports.FXRateSource) {
+	s.fx = fx
+}
+
+// SetCardRepository sets where the cards that holds are placed on are stored
+func (s *Service) SetCardRepository(cards ports.CardRepository) {
+	s.cards = cards
+}
+
 // getProvider returns the appropriate provider
 func (s *Service) getProvider(provider domain.PaymentProvider) (Provider, error) {
 	if provider == "" {
@@ -142,7 +196,9 @@ func (s *Service) CreatePaymentIntent(ctx context.Context, userID string, amount
 		"user_id": userID,
 	}
 
+	start := time.Now()
 	intent, err := provider.CreatePaymentIntent(ctx, amount, currency, metadata)
+	observeProvider(provider, "create_payment_intent", start)
 	if err != nil {
 		s.log.Error("Failed to create payment intent",
 			zap.String("user_id", userID),
@@ -156,20 +212,33 @@ func (s *Service) CreatePaymentIntent(ctx context.Context, userID string, amount
 }
 
 // ProcessPayment processes a payment
-func (s *Service) ProcessPayment(ctx context.Context, req *ports.PaymentRequest) (*domain.Payment, error) {
+func (s *Service) ProcessPayment(ctx context.Context, req *ports.PaymentRequest) (payment *domain.Payment, err error) {
+	ctx, span := tracer.Start(ctx, "payment.ProcessPayment", trace.WithAttributes(
+		attribute.String("payment.user_id", req.UserID),
+		attribute.String("payment.method", string(req.Method)),
+		attribute.Float64("payment.amount", req.Amount),
+	))
+	defer func() { endSpan(span, err) }()
+
 	provider, err := s.getProvider(s.config.DefaultProvider)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.String("payment.provider", provider.Name()))
 
 	currency := req.Currency
 	if currency == "" {
 		currency = s.config.DefaultCurrency
 	}
 
-	// Create payment record
-	payment := &domain.Payment{
-		ID:            uuid.New().String(),
+	// Create payment record. A payment repeated with the same idempotency
+	// key keeps its ID, so the provider sees the same request again.
+	paymentID := uuid.New().String()
+	if key := ports.IdempotencyKeyFrom(ctx); key != "" {
+		paymentID = uuid.NewSHA1(uuid.NameSpaceOID, []byte(key)).String()
+	}
+	payment = &domain.Payment{
+		ID:            paymentID,
 		UserID:        req.UserID,
 		TransactionID: req.TransactionID,
 		Provider:      s.config.DefaultProvider,
@@ -194,7 +263,12 @@ func (s *Service) ProcessPayment(ctx context.Context, req *ports.PaymentRequest)
 		"transaction_id": req.TransactionID,
 	}
 
+	span.SetAttributes(attribute.String("payment.id", payment.ID))
+
+	start := time.Now()
 	providerID, err := provider.ProcessPayment(ctx, req.Amount, currency, req.CardID, metadata)
+	observeProvider(provider, "process_payment", start)
+	telemetry.RecordPayment(provider.Name(), string(req.Method), err == nil)
 	if err != nil {
 		payment.Status = domain.PaymentStatusFailed
 		payment.FailureReason = err.Error()
@@ -231,44 +305,21 @@ func (s *Service) ProcessPayment(ctx context.Context, req *ports.PaymentRequest)
 	return payment, nil
 }
 
-// ProcessChargingPayment processes payment for a charging transaction
+// ProcessChargingPayment processes payment for a charging transaction.
+// amount is in the default currency; a wallet held in another currency is
+// debited the converted amount and the rate is recorded on the payment.
 func (s *Service) ProcessChargingPayment(ctx context.Context, userID string, transactionID string, amount float64) (*domain.Payment, error) {
 	// First try to use wallet balance
 	if s.walletSvc != nil {
-		hasFunds, err := s.walletSvc.HasSufficientBalance(ctx, userID, amount)
-		if err == nil && hasFunds {
-			// Deduct from wallet
-			err = s.walletSvc.DeductFunds(ctx, userID, amount, "Charging session payment", transactionID)
-			if err == nil {
-				// Create payment record for wallet payment
-				payment := &domain.Payment{
-					ID:            uuid.New().String(),
-					UserID:        userID,
-					TransactionID: transactionID,
-					Provider:      "wallet",
-					Method:        domain.PaymentMethodWallet,
-					Status:        domain.PaymentStatusCompleted,
-					Amount:        amount,
-					Currency:      s.config.DefaultCurrency,
-					Description:   "Charging session payment from wallet",
-					CreatedAt:     time.Now(),
-					UpdatedAt:     time.Now(),
-				}
-				now := time.Now()
-				payment.CompletedAt = &now
-
-				if err := s.repo.SavePayment(ctx, payment); err != nil {
-					s.log.Error("Failed to save wallet payment record", zap.Error(err))
-				}
-
-				s.log.Info("Charging payment processed from wallet",
-					zap.String("user_id", userID),
-					zap.String("transaction_id", transactionID),
-					zap.Float64("amount", amount),
-				)
-				return payment, nil
-			}
+		payment, err := s.payFromWallet(ctx, userID, transactionID, amount)
+		if err == nil {
+			return payment, nil
 		}
+		s.log.Info("Wallet payment not possible, charging card",
+			zap.String("user_id", userID),
+			zap.String("transaction_id", transactionID),
+			zap.Error(err),
+		)
 	}
 
 	// Fall back to card payment
@@ -282,6 +333,63 @@ func (s *Service) ProcessChargingPayment(ctx context.Context, userID string, tra
 	})
 }
 
+// payFromWallet settles a charge of amount (default currency) from the
+// user's wallet, converting it into the wallet's currency if needed
+func (s *Service) payFromWallet(ctx context.Context, userID string, transactionID string, amount float64) (*domain.Payment, error) {
+	wallet, err := s.walletSvc.GetWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settled, rate, err := convert(ctx, s.fx, amount, s.config.DefaultCurrency, wallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Balance < settled {
+		return nil, fmt.Errorf("%w: need %.2f %s", domain.ErrInsufficientBalance, settled, wallet.Currency)
+	}
+
+	if err := s.walletSvc.DeductFunds(ctx, userID, settled, wallet.Currency, "Charging session payment", transactionID); err != nil {
+		return nil, err
+	}
+	telemetry.RecordPayment("wallet", string(domain.PaymentMethodWallet), true)
+
+	// Create payment record for wallet payment
+	now := time.Now()
+	payment := &domain.Payment{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		TransactionID: transactionID,
+		Provider:      "wallet",
+		Method:        domain.PaymentMethodWallet,
+		Status:        domain.PaymentStatusCompleted,
+		Amount:        amount,
+		Currency:      s.config.DefaultCurrency,
+		Description:   "Charging session payment from wallet",
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		CompletedAt:   &now,
+	}
+	if wallet.Currency != s.config.DefaultCurrency {
+		payment.FXRate = rate
+		payment.SettledAmount = settled
+		payment.SettledCurrency = wallet.Currency
+	}
+
+	if err := s.repo.SavePayment(ctx, payment); err != nil {
+		s.log.Error("Failed to save wallet payment record", zap.Error(err))
+	}
+
+	s.log.Info("Charging payment processed from wallet",
+		zap.String("user_id", userID),
+		zap.String("transaction_id", transactionID),
+		zap.Float64("amount", amount),
+		zap.Float64("settled", settled),
+		zap.String("wallet_currency", wallet.Currency),
+	)
+	return payment, nil
+}
+
 // GetPayment retrieves a payment by ID
 func (s *Service) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
 	return s.repo.GetPayment(ctx, paymentID)
@@ -293,7 +401,13 @@ func (s *Service) GetPaymentHistory(ctx context.Context, userID string, limit, o
 }
 
 // RefundPayment refunds a payment
-func (s *Service) RefundPayment(ctx context.Context, paymentID string, amount float64, reason string) (*domain.Refund, error) {
+func (s *Service) RefundPayment(ctx context.Context, paymentID string, amount float64, reason string) (refund *domain.Refund, err error) {
+	ctx, span := tracer.Start(ctx, "payment.RefundPayment", trace.WithAttributes(
+		attribute.String("payment.id", paymentID),
+		attribute.Float64("refund.amount", amount),
+	))
+	defer func() { endSpan(span, err) }()
+
 	payment, err := s.repo.GetPayment(ctx, paymentID)
 	if err != nil {
 		return nil, fmt.Errorf("payment not found: %w", err)
@@ -315,9 +429,13 @@ func (s *Service) RefundPayment(ctx context.Context, paymentID string, amount fl
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.String("payment.provider", provider.Name()))
 
 	// Process refund with provider
+	start := time.Now()
 	refundID, err := provider.RefundPayment(ctx, payment.ProviderID, amount)
+	observeProvider(provider, "refund_payment", start)
+	telemetry.RecordPaymentRefund(provider.Name(), err == nil)
 	if err != nil {
 		s.log.Error("Refund failed",
 			zap.String("payment_id", paymentID),
@@ -327,7 +445,7 @@ func (s *Service) RefundPayment(ctx context.Context, paymentID string, amount fl
 	}
 
 	// Create refund record
-	refund := &domain.Refund{
+	refund = &domain.Refund{
 		ID:         uuid.New().String(),
 		PaymentID:  paymentID,
 		ProviderID: refundID,
@@ -384,7 +502,9 @@ func (s *Service) CreatePixPayment(ctx context.Context, userID string, amount fl
 	}
 
 	// Create PIX with provider
+	start := time.Now()
 	pixPayment, providerID, err := provider.CreatePixPayment(ctx, amount, "SIGEC-VE Recarga", 30*time.Minute)
+	observeProvider(provider, "create_pix_payment", start)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create PIX payment: %w", err)
 	}
@@ -428,7 +548,9 @@ func (s *Service) CreateBoletoPayment(ctx context.Context, userID string, amount
 		"user_id": userID,
 	}
 
+	start := time.Now()
 	boletoPayment, providerID, err := provider.CreateBoletoPayment(ctx, amount, customerInfo, expiresAt)
+	observeProvider(provider, "create_boleto_payment", start)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create Boleto: %w", err)
 	}
@@ -447,7 +569,15 @@ func (s *Service) CreateBoletoPayment(ctx context.Context, userID string, amount
 }
 
 // HandleWebhook handles payment provider webhooks
-func (s *Service) HandleWebhook(ctx context.Context, providerName string, payload []byte, signature string) error {
+func (s *Service) HandleWebhook(ctx context.Context, providerName string, payload []byte, signature string) (err error) {
+	ctx, span := tracer.Start(ctx, "payment.HandleWebhook", trace.WithAttributes(
+		attribute.String("payment.provider", providerName),
+	))
+	defer func() {
+		recordWebhook(providerName, err)
+		endSpan(span, err)
+	}()
+
 	var provider Provider
 	var providerType domain.PaymentProvider
 
@@ -457,13 +587,12 @@ func (s *Service) HandleWebhook(ctx context.Context, providerName string, payloa
 	case "pagseguro":
 		providerType = domain.PaymentProviderPagSeguro
 	default:
-		return fmt.Errorf("unknown provider: %s", providerName)
+		return fmt.Errorf("%w: %s", ErrUnknownProvider, providerName)
 	}
 
-	var err error
 	provider, err = s.getProvider(providerType)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %s", ErrUnknownProvider, providerName)
 	}
 
 	// Validate signature
@@ -472,7 +601,7 @@ func (s *Service) HandleWebhook(ctx context.Context, providerName string, payloa
 			zap.String("provider", providerName),
 			zap.Error(err),
 		)
-		return fmt.Errorf("invalid webhook signature: %w", err)
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookSignature, err)
 	}
 
 	// Parse event
@@ -486,30 +615,73 @@ func (s *Service) HandleWebhook(ctx context.Context, providerName string, payloa
 		zap.String("type", event.Type),
 		zap.String("payment_id", event.PaymentID),
 	)
+	span.SetAttributes(
+		attribute.String("webhook.type", event.Type),
+		attribute.String("payment.provider_id", event.PaymentID),
+	)
+
+	// Events we don't handle carry no payment reference; acknowledge them
+	// so the provider stops retrying.
+	if event.PaymentID == "" {
+		return nil
+	}
+
+	// Providers retry deliveries; process each event once
+	if event.ID != "" {
+		var claimed bool
+		claimed, err = s.repo.ClaimWebhookEvent(ctx, providerName, event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to claim webhook event: %w", err)
+		}
+		if !claimed {
+			s.log.Info("Webhook event already processed",
+				zap.String("provider", providerName),
+				zap.String("event_id", event.ID),
+			)
+			return nil
+		}
+		defer func() {
+			if err == nil {
+				return
+			}
+			if releaseErr := s.repo.ReleaseWebhookEvent(ctx, providerName, event.ID); releaseErr != nil {
+				s.log.Error("Failed to release webhook event", zap.String("event_id", event.ID), zap.Error(releaseErr))
+			}
+		}()
+	}
+
+	return s.applyWebhookEvent(ctx, event)
+}
 
+// applyWebhookEvent updates the payment an event refers to
+func (s *Service) applyWebhookEvent(ctx context.Context, event *WebhookEvent) error {
 	// Find payment by provider ID
 	payment, err := s.repo.GetPaymentByProviderID(ctx, event.PaymentID)
-	if err != nil {
+	if err != nil || payment == nil {
 		s.log.Warn("Payment not found for webhook",
 			zap.String("provider_id", event.PaymentID),
 		)
 		return nil // Don't error, might be a test event
 	}
 
+	completing := event.Status == domain.PaymentStatusCompleted && payment.Status != domain.PaymentStatusCompleted
+
+	// Add funds to wallet if this is a wallet top-up. The payment is left
+	// as it was when that fails, so the provider's retry credits it. The
+	// wallet is credited once per payment, so a retry after the save below
+	// failed, or another event for the same payment, does not credit it again.
+	if completing && s.walletSvc != nil && payment.TransactionID == "" {
+		if err := s.topUpWallet(ctx, payment); err != nil {
+			return err
+		}
+	}
+
 	// Update payment status
 	payment.Status = event.Status
 	payment.UpdatedAt = time.Now()
-
-	if event.Status == domain.PaymentStatusCompleted {
+	if completing {
 		now := time.Now()
 		payment.CompletedAt = &now
-
-		// Add funds to wallet if this is a wallet top-up
-		if s.walletSvc != nil && payment.TransactionID == "" {
-			if err := s.walletSvc.AddFunds(ctx, payment.UserID, payment.Amount, payment.ID); err != nil {
-				s.log.Error("Failed to add funds to wallet", zap.Error(err))
-			}
-		}
 	}
 
 	if err := s.repo.SavePayment(ctx, payment); err != nil {
@@ -519,3 +691,47 @@ func (s *Service) HandleWebhook(ctx context.Context, providerName string, payloa
 
 	return nil
 }
+
+// topUpWallet credits a completed top-up to the user's wallet. A top-up in
+// another currency than a funded wallet's is converted at the current rate.
+func (s *Service) topUpWallet(ctx context.Context, payment *domain.Payment) error {
+	amount, currency := payment.Amount, payment.Currency
+
+	wallet, err := s.walletSvc.GetWallet(ctx, payment.UserID)
+	if err != nil {
+		s.log.Error("Failed to get wallet for top-up", zap.Error(err))
+		return fmt.Errorf("failed to get wallet for top-up: %w", err)
+	}
+	if wallet.Balance != 0 && currency != "" && currency != wallet.Currency {
+		settled, rate, err := convert(ctx, s.fx, amount, currency, wallet.Currency)
+		if err != nil {
+			s.log.Error("Failed to convert wallet top-up",
+				zap.String("payment_id", payment.ID),
+				zap.String("currency", currency),
+				zap.String("wallet_currency", wallet.Currency),
+				zap.Error(err),
+			)
+			return fmt.Errorf("failed to convert wallet top-up: %w", err)
+		}
+		amount, currency = settled, wallet.Currency
+		payment.FXRate = rate
+		payment.SettledAmount = settled
+		payment.SettledCurrency = wallet.Currency
+	}
+
+	if err := s.walletSvc.AddFunds(ctx, payment.UserID, amount, currency, payment.ID); err != nil {
+		if errors.Is(err, domain.ErrAlreadyCredited) {
+			s.log.Info("Wallet top-up already credited", zap.String("payment_id", payment.ID))
+			return nil
+		}
+		s.log.Error("Failed to add funds to wallet", zap.Error(err))
+		return fmt.Errorf("failed to add funds to wallet: %w", err)
+	}
+	return nil
+}
+
+// toCents converts an amount to the minor currency unit, rounding to the
+// nearest cent
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
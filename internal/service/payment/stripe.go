@@ -10,22 +10,40 @@ import (
 	"github.com/stripe/stripe-go/v76/paymentintent"
 	"github.com/stripe/stripe-go/v76/refund"
 	"github.com/stripe/stripe-go/v76/webhook"
+	"go.uber.org/zap"
 
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/circuitbreaker"
 )
 
-// StripeProvider implements the Provider interface for Stripe
+// StripeProvider implements the Provider interface for Stripe. API calls are
+// retried and guarded by a circuit breaker (see resilientTransport).
 type StripeProvider struct {
 	secretKey     string
 	webhookSecret string
+	intents       paymentintent.Client
+	refunds       refund.Client
 }
 
-// NewStripeProvider creates a new Stripe provider
-func NewStripeProvider(secretKey, webhookSecret string) *StripeProvider {
-	stripe.Key = secretKey
+// NewStripeProvider creates a new Stripe provider. Its circuit breaker is
+// registered with breakers, which may be nil.
+func NewStripeProvider(secretKey, webhookSecret string, breakers *circuitbreaker.Manager, log *zap.Logger) *StripeProvider {
+	return newStripeProvider(secretKey, webhookSecret, stripe.APIURL, DefaultRetryPolicy(), providerBreaker(breakers, "stripe", log), log)
+}
+
+// newStripeProvider creates a Stripe provider calling the API at apiURL
+func newStripeProvider(secretKey, webhookSecret, apiURL string, policy RetryPolicy, breaker *circuitbreaker.CircuitBreaker, log *zap.Logger) *StripeProvider {
+	backend := stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		HTTPClient:        newResilientClient(breaker, policy, log),
+		URL:               stripe.String(apiURL),
+		MaxNetworkRetries: stripe.Int64(0), // Retries happen in resilientTransport
+	})
+
 	return &StripeProvider{
 		secretKey:     secretKey,
 		webhookSecret: webhookSecret,
+		intents:       paymentintent.Client{B: backend, Key: secretKey},
+		refunds:       refund.Client{B: backend, Key: secretKey},
 	}
 }
 
@@ -37,7 +55,7 @@ func (p *StripeProvider) Name() string {
 // CreatePaymentIntent creates a Stripe payment intent
 func (p *StripeProvider) CreatePaymentIntent(ctx context.Context, amount float64, currency string, metadata map[string]string) (*domain.PaymentIntent, error) {
 	// Stripe expects amount in cents
-	amountCents := int64(amount * 100)
+	amountCents := toCents(amount)
 
 	params := &stripe.PaymentIntentParams{
 		Amount:   stripe.Int64(amountCents),
@@ -55,7 +73,8 @@ func (p *StripeProvider) CreatePaymentIntent(ctx context.Context, amount float64
 		}
 	}
 
-	pi, err := paymentintent.New(params)
+	params.Context = ctx
+	pi, err := p.intents.New(params)
 	if err != nil {
 		return nil, fmt.Errorf("stripe error: %w", err)
 	}
@@ -71,7 +90,7 @@ func (p *StripeProvider) CreatePaymentIntent(ctx context.Context, amount float64
 
 // ProcessPayment processes a payment with Stripe
 func (p *StripeProvider) ProcessPayment(ctx context.Context, amount float64, currency string, paymentMethodID string, metadata map[string]string) (string, error) {
-	amountCents := int64(amount * 100)
+	amountCents := toCents(amount)
 
 	params := &stripe.PaymentIntentParams{
 		Amount:        stripe.Int64(amountCents),
@@ -91,7 +110,8 @@ func (p *StripeProvider) ProcessPayment(ctx context.Context, amount float64, cur
 		}
 	}
 
-	pi, err := paymentintent.New(params)
+	params.Context = ctx
+	pi, err := p.intents.New(params)
 	if err != nil {
 		return "", fmt.Errorf("stripe payment error: %w", err)
 	}
@@ -103,6 +123,77 @@ func (p *StripeProvider) ProcessPayment(ctx context.Context, amount float64, cur
 	return pi.ID, nil
 }
 
+// AuthorizePayment confirms a payment intent with manual capture, holding
+// the amount on the card until it is captured or cancelled
+func (p *StripeProvider) AuthorizePayment(ctx context.Context, amount float64, currency string, paymentMethodID string, metadata map[string]string) (string, error) {
+	amountCents := toCents(amount)
+
+	params := &stripe.PaymentIntentParams{
+		Amount:        stripe.Int64(amountCents),
+		Currency:      stripe.String(currency),
+		PaymentMethod: stripe.String(paymentMethodID),
+		CaptureMethod: stripe.String(string(stripe.PaymentIntentCaptureMethodManual)),
+		Confirm:       stripe.Bool(true),
+		AutomaticPaymentMethods: &stripe.PaymentIntentAutomaticPaymentMethodsParams{
+			Enabled:        stripe.Bool(true),
+			AllowRedirects: stripe.String("never"),
+		},
+	}
+
+	if metadata != nil {
+		params.Metadata = make(map[string]string)
+		for k, v := range metadata {
+			params.Metadata[k] = v
+		}
+	}
+
+	params.Context = ctx
+	pi, err := p.intents.New(params)
+	if err != nil {
+		return "", fmt.Errorf("stripe authorization error: %w", err)
+	}
+
+	if pi.Status != stripe.PaymentIntentStatusRequiresCapture {
+		return "", fmt.Errorf("payment not authorized: %s", pi.Status)
+	}
+
+	return pi.ID, nil
+}
+
+// CapturePayment captures part or all of an authorized payment intent;
+// Stripe releases the rest of the hold
+func (p *StripeProvider) CapturePayment(ctx context.Context, paymentID string, amount float64) error {
+	params := &stripe.PaymentIntentCaptureParams{
+		AmountToCapture: stripe.Int64(toCents(amount)),
+	}
+
+	params.Context = ctx
+	pi, err := p.intents.Capture(paymentID, params)
+	if err != nil {
+		return fmt.Errorf("stripe capture error: %w", err)
+	}
+
+	if pi.Status != stripe.PaymentIntentStatusSucceeded {
+		return fmt.Errorf("payment not captured: %s", pi.Status)
+	}
+
+	return nil
+}
+
+// VoidPayment cancels an authorized payment intent, releasing the hold
+func (p *StripeProvider) VoidPayment(ctx context.Context, paymentID string) error {
+	params := &stripe.PaymentIntentCancelParams{
+		CancellationReason: stripe.String(string(stripe.PaymentIntentCancellationReasonAbandoned)),
+	}
+
+	params.Context = ctx
+	if _, err := p.intents.Cancel(paymentID, params); err != nil {
+		return fmt.Errorf("stripe cancel error: %w", err)
+	}
+
+	return nil
+}
+
 // CreatePixPayment creates a PIX payment via Stripe (Brazil)
 func (p *StripeProvider) CreatePixPayment(ctx context.Context, amount float64, description string, expiresIn time.Duration) (*domain.PixPayment, string, error) {
 	// Stripe doesn't natively support PIX, this would need Stripe Brazil or a local provider
@@ -123,10 +214,11 @@ func (p *StripeProvider) RefundPayment(ctx context.Context, paymentID string, am
 	}
 
 	if amount > 0 {
-		params.Amount = stripe.Int64(int64(amount * 100))
+		params.Amount = stripe.Int64(toCents(amount))
 	}
 
-	r, err := refund.New(params)
+	params.Context = ctx
+	r, err := p.refunds.New(params)
 	if err != nil {
 		return "", fmt.Errorf("stripe refund error: %w", err)
 	}
@@ -136,7 +228,7 @@ func (p *StripeProvider) RefundPayment(ctx context.Context, paymentID string, am
 
 // GetPayment retrieves payment details from Stripe
 func (p *StripeProvider) GetPayment(ctx context.Context, paymentID string) (*ProviderPayment, error) {
-	pi, err := paymentintent.Get(paymentID, nil)
+	pi, err := p.intents.Get(paymentID, &stripe.PaymentIntentParams{Params: stripe.Params{Context: ctx}})
 	if err != nil {
 		return nil, fmt.Errorf("stripe get payment error: %w", err)
 	}
@@ -163,7 +255,11 @@ func (p *StripeProvider) GetPayment(ctx context.Context, paymentID string) (*Pro
 
 // ValidateWebhook validates Stripe webhook signature
 func (p *StripeProvider) ValidateWebhook(payload []byte, signature string) error {
-	_, err := webhook.ConstructEvent(payload, signature, p.webhookSecret)
+	// The account's API version may differ from the library's pinned version;
+	// only the signature and timestamp tolerance matter here.
+	_, err := webhook.ConstructEventWithOptions(payload, signature, p.webhookSecret, webhook.ConstructEventOptions{
+		IgnoreAPIVersionMismatch: true,
+	})
 	return err
 }
 
@@ -175,6 +271,7 @@ func (p *StripeProvider) ParseWebhook(payload []byte) (*WebhookEvent, error) {
 	}
 
 	webhookEvent := &WebhookEvent{
+		ID:       event.ID,
 		Type:     string(event.Type),
 		Metadata: make(map[string]string),
 	}
@@ -0,0 +1,208 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/circuitbreaker"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+const fakePaymentIntent = `{"id":"pi_123","object":"payment_intent","amount":1000,"currency":"brl","client_secret":"pi_123_secret","status":"requires_payment_method"}`
+
+// fakeStripe answers each request with the next scripted status, then 200
+type fakeStripe struct {
+	mu              sync.Mutex
+	statuses        []int
+	calls           int
+	idempotencyKeys []string
+}
+
+func (f *fakeStripe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.calls++
+	f.idempotencyKeys = append(f.idempotencyKeys, r.Header.Get("Idempotency-Key"))
+	status := http.StatusOK
+	if len(f.statuses) > 0 {
+		status, f.statuses = f.statuses[0], f.statuses[1:]
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case status == http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":{"type":"invalid_request_error","code":"rate_limit","message":"Too many requests"}}`))
+	case status >= 400:
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":{"type":"api_error","message":"Something went wrong"}}`))
+	default:
+		w.Write([]byte(fakePaymentIntent))
+	}
+}
+
+func (f *fakeStripe) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func newTestStripeProvider(t *testing.T, fake *fakeStripe) *StripeProvider {
+	t.Helper()
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	policy := RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: 20 * time.Millisecond}
+	return newStripeProvider("sk_test_123", "whsec_123", srv.URL, policy, providerBreaker(nil, "stripe", zap.NewNop()), zap.NewNop())
+}
+
+func TestStripeProvider_RetriesTransientErrors(t *testing.T) {
+	// Arrange
+	fake := &fakeStripe{statuses: []int{http.StatusTooManyRequests, http.StatusInternalServerError}}
+	provider := newTestStripeProvider(t, fake)
+
+	// Act
+	intent, err := provider.CreatePaymentIntent(context.Background(), 10, "brl", nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if intent.ID != "pi_123" {
+		t.Errorf("expected intent pi_123, got %s", intent.ID)
+	}
+	if fake.callCount() != 3 {
+		t.Errorf("expected 3 attempts, got %d", fake.callCount())
+	}
+	key := fake.idempotencyKeys[0]
+	if key == "" {
+		t.Fatal("expected an Idempotency-Key on the request")
+	}
+	for i, k := range fake.idempotencyKeys {
+		if k != key {
+			t.Errorf("expected attempt %d to reuse Idempotency-Key %s, got %s", i+1, key, k)
+		}
+	}
+}
+
+func TestStripeProvider_UsesCallerIdempotencyKey(t *testing.T) {
+	// Arrange - a charge repeated by a later sweep sends the same key
+	fake := &fakeStripe{}
+	provider := newTestStripeProvider(t, fake)
+	ctx := ports.WithIdempotencyKey(context.Background(), "charge-tx-1")
+
+	// Act
+	provider.CreatePaymentIntent(ctx, 10, "brl", nil)
+	provider.CreatePaymentIntent(ctx, 10, "brl", nil)
+
+	// Assert
+	if len(fake.idempotencyKeys) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(fake.idempotencyKeys))
+	}
+	for i, k := range fake.idempotencyKeys {
+		if k != "charge-tx-1:/v1/payment_intents" {
+			t.Errorf("expected request %d keyed by the charge, got %s", i+1, k)
+		}
+	}
+}
+
+func TestStripeProvider_DoesNotRetryClientErrors(t *testing.T) {
+	// Arrange
+	fake := &fakeStripe{statuses: []int{http.StatusBadRequest}}
+	provider := newTestStripeProvider(t, fake)
+
+	// Act
+	_, err := provider.CreatePaymentIntent(context.Background(), 10, "brl", nil)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error for a rejected request")
+	}
+	if fake.callCount() != 1 {
+		t.Errorf("expected 1 attempt, got %d", fake.callCount())
+	}
+}
+
+func TestStripeProvider_CircuitOpensDuringOutage(t *testing.T) {
+	// Arrange
+	outage := make([]int, 10)
+	for i := range outage {
+		outage[i] = http.StatusServiceUnavailable
+	}
+	fake := &fakeStripe{statuses: outage}
+	provider := newTestStripeProvider(t, fake)
+
+	// Act
+	_, firstErr := provider.RefundPayment(context.Background(), "pi_123", 0)
+	callsAfterFirst := fake.callCount()
+	_, secondErr := provider.RefundPayment(context.Background(), "pi_123", 0)
+
+	// Assert
+	if !errors.Is(firstErr, ErrProviderUnavailable) {
+		t.Errorf("expected ErrProviderUnavailable once the breaker trips, got %v", firstErr)
+	}
+	if !errors.Is(secondErr, ErrProviderUnavailable) {
+		t.Errorf("expected ErrProviderUnavailable while the breaker is open, got %v", secondErr)
+	}
+	if fake.callCount() != callsAfterFirst {
+		t.Errorf("expected no calls while the breaker is open, got %d more", fake.callCount()-callsAfterFirst)
+	}
+}
+
+func TestNewService_RegistersProviderBreaker(t *testing.T) {
+	// Arrange
+	breakers := circuitbreaker.NewManager(zap.NewNop())
+
+	// Act
+	_, err := NewService(&Config{
+		DefaultProvider: domain.PaymentProviderStripe,
+		StripeSecretKey: "sk_test_dummy",
+		Breakers:        breakers,
+	}, newMockPaymentRepository(), nil, zap.NewNop())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := breakers.Status()["payment.stripe"]; !ok {
+		t.Errorf("expected the payment.stripe breaker to be listed, got %v", breakers.Status())
+	}
+}
+
+func TestRetryDelay_HonorsRetryAfter(t *testing.T) {
+	transport := &resilientTransport{policy: RetryPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}}
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		expected   time.Duration
+	}{
+		{"exponential backoff", "", 2, 400 * time.Millisecond},
+		{"retry after seconds", "3", 0, 3 * time.Second},
+		{"capped at max delay", "120", 0, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			if got := transport.retryDelay(tt.attempt, resp); got != tt.expected {
+				t.Errorf("expected delay %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package payment
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 // WalletService implements ports.WalletService
 type WalletService struct {
 	repo ports.WalletRepository
+	fx   ports.FXRateSource // optional, see SetFXRateSource
 	log  *zap.Logger
 }
 
@@ -26,6 +28,12 @@ func NewWalletService(repo ports.WalletRepository, log *zap.Logger) *WalletServi
 	}
 }
 
+// SetFXRateSource sets the exchange rates used to convert amounts in another
+// currency than the wallet's. Without it such amounts are rejected.
+func (s *WalletService) SetFXRateSource(fx ports.FXRateSource) {
+	s.fx = fx
+}
+
 // GetWallet retrieves or creates a user's wallet
 func (s *WalletService) GetWallet(ctx context.Context, userID string) (*domain.Wallet, error) {
 	wallet, err := s.repo.GetByUserID(ctx, userID)
@@ -56,78 +64,70 @@ func (s *WalletService) GetWallet(ctx context.Context, userID string) (*domain.W
 	return wallet, nil
 }
 
-// AddFunds adds funds to the wallet
-func (s *WalletService) AddFunds(ctx context.Context, userID string, amount float64, paymentID string) error {
+// AddFunds adds funds to the wallet. An empty wallet takes the currency of
+// its first top-up, so users can hold their balance in USD or EUR; other
+// amounts are converted into the wallet's currency. A wallet is credited
+// once per paymentID: a repeated credit returns domain.ErrAlreadyCredited.
+func (s *WalletService) AddFunds(ctx context.Context, userID string, amount float64, currency string, paymentID string) error {
 	if amount <= 0 {
 		return fmt.Errorf("amount must be positive")
 	}
 
+	if err := s.adoptCurrency(ctx, userID, currency); err != nil {
+		return err
+	}
+
 	wallet, err := s.GetWallet(ctx, userID)
 	if err != nil {
 		return err
 	}
-
-	// Update balance
-	newBalance := wallet.Balance + amount
-	wallet.Balance = newBalance
-	wallet.UpdatedAt = time.Now()
-
-	if err := s.repo.Save(ctx, wallet); err != nil {
-		return fmt.Errorf("failed to update wallet balance: %w", err)
+	amount, err = s.toWalletCurrency(ctx, wallet, amount, currency)
+	if err != nil {
+		return err
 	}
 
-	// Record transaction
+	// Credit and record the transaction together
 	tx := &domain.WalletTransaction{
 		ID:          uuid.New().String(),
 		WalletID:    wallet.ID,
 		UserID:      userID,
 		Type:        "credit",
 		Amount:      amount,
-		Balance:     newBalance,
 		Description: "Funds added to wallet",
 		ReferenceID: paymentID,
 		CreatedAt:   time.Now(),
 	}
 
-	if err := s.repo.SaveTransaction(ctx, tx); err != nil {
-		s.log.Error("Failed to save wallet transaction",
-			zap.String("wallet_id", wallet.ID),
-			zap.Error(err),
-		)
+	updated, err := s.repo.Credit(ctx, tx)
+	if err != nil {
+		if errors.Is(err, domain.ErrAlreadyCredited) {
+			return fmt.Errorf("%w: %s", err, paymentID)
+		}
+		return fmt.Errorf("failed to update wallet balance: %w", err)
 	}
 
 	s.log.Info("Funds added to wallet",
 		zap.String("user_id", userID),
 		zap.Float64("amount", amount),
-		zap.Float64("new_balance", newBalance),
+		zap.Float64("new_balance", updated.Balance),
 	)
 
 	return nil
 }
 
-// DeductFunds deducts funds from the wallet
-func (s *WalletService) DeductFunds(ctx context.Context, userID string, amount float64, description string, referenceID string) error {
+// DeductFunds deducts funds from the wallet. Amounts in another currency
+// than the wallet's are converted into it.
+func (s *WalletService) DeductFunds(ctx context.Context, userID string, amount float64, currency string, description string, referenceID string) error {
 	if amount <= 0 {
 		return fmt.Errorf("amount must be positive")
 	}
 
-	wallet, err := s.GetWallet(ctx, userID)
+	wallet, debited, err := s.updateBalance(ctx, userID, -amount, currency)
 	if err != nil {
 		return err
 	}
-
-	if wallet.Balance < amount {
-		return fmt.Errorf("insufficient balance: have %.2f, need %.2f", wallet.Balance, amount)
-	}
-
-	// Update balance
-	newBalance := wallet.Balance - amount
-	wallet.Balance = newBalance
-	wallet.UpdatedAt = time.Now()
-
-	if err := s.repo.Save(ctx, wallet); err != nil {
-		return fmt.Errorf("failed to update wallet balance: %w", err)
-	}
+	amount = -debited
+	newBalance := wallet.Balance
 
 	// Record transaction
 	tx := &domain.WalletTransaction{
@@ -158,6 +158,68 @@ func (s *WalletService) DeductFunds(ctx context.Context, userID string, amount f
 	return nil
 }
 
+// adoptCurrency switches an empty wallet to currency
+func (s *WalletService) adoptCurrency(ctx context.Context, userID string, currency string) error {
+	wallet, err := s.GetWallet(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if currency == "" || wallet.Currency == currency || wallet.Balance != 0 {
+		return nil
+	}
+
+	s.log.Info("Wallet currency changed",
+		zap.String("wallet_id", wallet.ID),
+		zap.String("from", wallet.Currency),
+		zap.String("to", currency),
+	)
+	wallet.Currency = currency
+	wallet.UpdatedAt = time.Now()
+	if err := s.repo.Save(ctx, wallet); err != nil {
+		return fmt.Errorf("failed to update wallet currency: %w", err)
+	}
+	return nil
+}
+
+// updateBalance applies delta, in currency, to the user's wallet and returns
+// the delta applied in the wallet's currency. The repository performs the
+// change atomically, so concurrent debits can never drive the balance
+// negative and concurrent credits are never lost.
+func (s *WalletService) updateBalance(ctx context.Context, userID string, delta float64, currency string) (*domain.Wallet, float64, error) {
+	wallet, err := s.GetWallet(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	delta, err = s.toWalletCurrency(ctx, wallet, delta, currency)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	updated, err := s.repo.AdjustBalance(ctx, wallet.ID, delta)
+	if err != nil {
+		if errors.Is(err, domain.ErrInsufficientBalance) {
+			return nil, 0, fmt.Errorf("%w: need %.2f %s", err, -delta, wallet.Currency)
+		}
+		return nil, 0, fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+
+	return updated, delta, nil
+}
+
+// toWalletCurrency converts amount from currency into the wallet's. An empty
+// currency is the wallet's own. It returns domain.ErrCurrencyNotHeld when no
+// exchange rate is known.
+func (s *WalletService) toWalletCurrency(ctx context.Context, wallet *domain.Wallet, amount float64, currency string) (float64, error) {
+	if currency == "" {
+		return amount, nil
+	}
+	converted, _, err := convert(ctx, s.fx, amount, currency, wallet.Currency)
+	if err != nil {
+		return 0, fmt.Errorf("%w: wallet holds %s, not %s: %v", domain.ErrCurrencyNotHeld, wallet.Currency, currency, err)
+	}
+	return converted, nil
+}
+
 // GetTransactions retrieves wallet transaction history
 func (s *WalletService) GetTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.WalletTransaction, error) {
 	wallet, err := s.GetWallet(ctx, userID)
@@ -168,12 +230,17 @@ func (s *WalletService) GetTransactions(ctx context.Context, userID string, limi
 	return s.repo.GetTransactions(ctx, wallet.ID, limit, offset)
 }
 
-// HasSufficientBalance checks if wallet has enough balance
-func (s *WalletService) HasSufficientBalance(ctx context.Context, userID string, amount float64) (bool, error) {
+// HasSufficientBalance checks if wallet has enough balance for amount in
+// currency
+func (s *WalletService) HasSufficientBalance(ctx context.Context, userID string, amount float64, currency string) (bool, error) {
 	wallet, err := s.GetWallet(ctx, userID)
 	if err != nil {
 		return false, err
 	}
+	amount, err = s.toWalletCurrency(ctx, wallet, amount, currency)
+	if err != nil {
+		return false, err
+	}
 
 	return wallet.Balance >= amount, nil
 }
@@ -0,0 +1,233 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// mockWalletRepository is an in-memory WalletRepository whose AdjustBalance
+// is atomic like the database adapter's conditional UPDATE
+type mockWalletRepository struct {
+	mu           sync.Mutex
+	wallets      map[string]domain.Wallet
+	transactions []domain.WalletTransaction
+}
+
+func newMockWalletRepository(wallets ...domain.Wallet) *mockWalletRepository {
+	m := &mockWalletRepository{wallets: make(map[string]domain.Wallet)}
+	for _, w := range wallets {
+		m.wallets[w.ID] = w
+	}
+	return m
+}
+
+func (m *mockWalletRepository) Save(ctx context.Context, wallet *domain.Wallet) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.wallets[wallet.ID] = *wallet
+	return nil
+}
+
+func (m *mockWalletRepository) GetByID(ctx context.Context, id string) (*domain.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if w, ok := m.wallets[id]; ok {
+		return &w, nil
+	}
+	return nil, nil
+}
+
+func (m *mockWalletRepository) GetByUserID(ctx context.Context, userID string) (*domain.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.wallets {
+		if w.UserID == userID {
+			return &w, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockWalletRepository) AdjustBalance(ctx context.Context, walletID string, delta float64) (*domain.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.wallets[walletID]
+	if !ok {
+		return nil, errors.New("wallet not found")
+	}
+	if w.Balance+delta < 0 {
+		return nil, domain.ErrInsufficientBalance
+	}
+	w.Balance += delta
+	w.Version++
+	w.UpdatedAt = time.Now()
+	m.wallets[walletID] = w
+	return &w, nil
+}
+
+func (m *mockWalletRepository) Credit(ctx context.Context, entry *domain.WalletTransaction) (*domain.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tx := range m.transactions {
+		if entry.ReferenceID != "" && tx.Type == entry.Type && tx.ReferenceID == entry.ReferenceID {
+			return nil, domain.ErrAlreadyCredited
+		}
+	}
+	w, ok := m.wallets[entry.WalletID]
+	if !ok {
+		return nil, errors.New("wallet not found")
+	}
+	w.Balance += entry.Amount
+	w.Version++
+	w.UpdatedAt = time.Now()
+	m.wallets[entry.WalletID] = w
+	entry.Balance = w.Balance
+	m.transactions = append(m.transactions, *entry)
+	return &w, nil
+}
+
+func (m *mockWalletRepository) SaveTransaction(ctx context.Context, tx *domain.WalletTransaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transactions = append(m.transactions, *tx)
+	return nil
+}
+
+func (m *mockWalletRepository) GetTransactions(ctx context.Context, walletID string, limit, offset int) ([]domain.WalletTransaction, error) {
+	return nil, nil
+}
+
+func TestWalletService_DeductFundsInsufficientBalance(t *testing.T) {
+	// Arrange
+	repo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 10})
+	svc := NewWalletService(repo, zap.NewNop())
+
+	// Act
+	err := svc.DeductFunds(context.Background(), "user-1", 10.01, "", "charging", "tx-1")
+
+	// Assert
+	if !errors.Is(err, domain.ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+	wallet, _ := repo.GetByID(context.Background(), "wallet-1")
+	if wallet.Balance != 10 {
+		t.Errorf("expected balance 10, got %f", wallet.Balance)
+	}
+}
+
+func TestWalletService_AddFundsCreditsPaymentOnce(t *testing.T) {
+	// Arrange
+	repo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 10, Currency: "BRL"})
+	svc := NewWalletService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	// Act
+	if err := svc.AddFunds(ctx, "user-1", 50, "BRL", "pay-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err := svc.AddFunds(ctx, "user-1", 50, "BRL", "pay-1")
+
+	// Assert
+	if !errors.Is(err, domain.ErrAlreadyCredited) {
+		t.Fatalf("expected ErrAlreadyCredited, got %v", err)
+	}
+	wallet, _ := repo.GetByID(ctx, "wallet-1")
+	if wallet.Balance != 60 {
+		t.Errorf("expected balance 60, got %.2f", wallet.Balance)
+	}
+	if len(repo.transactions) != 1 || repo.transactions[0].Balance != 60 {
+		t.Errorf("expected one credit recorded at balance 60, got %+v", repo.transactions)
+	}
+}
+
+func TestWalletService_ConcurrentMixedOperations(t *testing.T) {
+	// Arrange
+	const (
+		workers      = 8
+		opsPerWorker = 50
+		amount       = 5.0
+		initial      = 100.0
+	)
+	repo := newMockWalletRepository(domain.Wallet{ID: "wallet-1", UserID: "user-1", Balance: initial})
+	svc := NewWalletService(repo, zap.NewNop())
+	ctx := context.Background()
+
+	var (
+		mu         sync.Mutex
+		credits    int
+		debits     int
+		rejected   int
+		unexpected []error
+		wg         sync.WaitGroup
+	)
+
+	// Act: half the workers only deduct so the wallet repeatedly hits zero
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < opsPerWorker; i++ {
+				if w%2 == 0 {
+					err := svc.AddFunds(ctx, "user-1", amount, "", fmt.Sprintf("pay-%d-%d", w, i))
+					mu.Lock()
+					if err != nil {
+						unexpected = append(unexpected, err)
+					} else {
+						credits++
+					}
+					mu.Unlock()
+					continue
+				}
+
+				err := svc.DeductFunds(ctx, "user-1", amount*2, "", "charging", "tx")
+				mu.Lock()
+				switch {
+				case err == nil:
+					debits++
+				case errors.Is(err, domain.ErrInsufficientBalance):
+					rejected++
+				default:
+					unexpected = append(unexpected, err)
+				}
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Assert
+	if len(unexpected) > 0 {
+		t.Fatalf("expected no unexpected errors, got %d (first: %v)", len(unexpected), unexpected[0])
+	}
+
+	expected := initial + float64(credits)*amount - float64(debits)*amount*2
+	wallet, _ := repo.GetByID(ctx, "wallet-1")
+	if wallet.Balance != expected {
+		t.Errorf("expected balance %.2f, got %.2f", expected, wallet.Balance)
+	}
+	if wallet.Balance < 0 {
+		t.Errorf("expected non-negative balance, got %.2f", wallet.Balance)
+	}
+	if wallet.Version != int64(credits+debits) {
+		t.Errorf("expected version %d, got %d", credits+debits, wallet.Version)
+	}
+	if len(repo.transactions) != credits+debits {
+		t.Errorf("expected %d wallet transactions, got %d", credits+debits, len(repo.transactions))
+	}
+	for _, tx := range repo.transactions {
+		if tx.Balance < 0 {
+			t.Errorf("expected non-negative balance after tx, got %.2f", tx.Balance)
+		}
+	}
+	if rejected == 0 {
+		t.Error("expected some debits to be rejected for insufficient balance")
+	}
+}
@@ -0,0 +1,75 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// earthRadiusMeters is the mean Earth radius used for geofence distances
+const earthRadiusMeters = 6371000.0
+
+// ActivateReservationWithLocation activates a confirmed reservation when the
+// user reports a location within ArrivalRadiusMeters of the station, and
+// remote-starts the reserved connector for them. The transaction is linked
+// to the reservation once the station reports it started.
+func (s *Service) ActivateReservationWithLocation(ctx context.Context, id string, lat, lon float64) error {
+	reservation, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get reservation: %w", err)
+	}
+	if reservation == nil {
+		return fmt.Errorf("reservation %s: %w", id, domain.ErrNotFound)
+	}
+	if reservation.Status != domain.ReservationStatusConfirmed {
+		return fmt.Errorf("can only activate confirmed reservations: %w", domain.ErrInvalidTransition)
+	}
+
+	station, err := s.deviceRepo.FindByID(ctx, reservation.ChargePointID)
+	if err != nil {
+		return fmt.Errorf("failed to find station: %w", err)
+	}
+	if station == nil || station.Location == nil {
+		return fmt.Errorf("station %s has no known location: %w", reservation.ChargePointID, domain.ErrConflict)
+	}
+
+	radius := s.config.ArrivalRadiusMeters
+	if radius <= 0 {
+		radius = domain.DefaultReservationConfig().ArrivalRadiusMeters
+	}
+	distance := haversineMeters(lat, lon, station.Location.Latitude, station.Location.Longitude)
+	if distance > radius {
+		s.log.Info("Arrival outside station geofence",
+			zap.String("reservation_id", id),
+			zap.Float64("distance_m", distance),
+			zap.Float64("radius_m", radius),
+		)
+		return fmt.Errorf("%w: %.0f m from the station, must be within %.0f m",
+			domain.ErrOutsideGeofence, distance, radius)
+	}
+
+	// Start while the connector is still held for this user, so nobody can
+	// take it in between
+	if s.ocpp != nil {
+		evseID := reservation.ConnectorID
+		if err := s.ocpp.RemoteStartTransaction(ctx, reservation.ChargePointID, reservation.UserID, domain.IdTokenTypeCentral, &evseID); err != nil {
+			return fmt.Errorf("failed to start charging: %w", err)
+		}
+	}
+
+	return s.ActivateReservation(ctx, id, "")
+}
+
+// haversineMeters returns the great-circle distance between two points in m
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const toRad = math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,109 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+func TestActivateReservationWithLocation_Geofence(t *testing.T) {
+	// The station sits in Praça da Sé; 0.001° of latitude is about 111 m
+	const stationLat, stationLon = -23.5505, -46.6333
+
+	tests := []struct {
+		name        string
+		lat, lon    float64
+		wantStarted bool
+	}{
+		{"at the station", stationLat, stationLon, true},
+		{"in the parking lot", stationLat + 0.0005, stationLon - 0.0005, true},
+		{"just inside the radius", stationLat + 0.0017, stationLon, true},
+		{"just outside the radius", stationLat + 0.0019, stationLon, false},
+		{"across town", -23.5874, -46.6576, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reservation := pendingReservation()
+			reservation.Status = domain.ReservationStatusConfirmed
+			stations := &mocks.MockChargePointRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+					return &domain.ChargePoint{ID: id, Location: &domain.Location{Latitude: stationLat, Longitude: stationLon}}, nil
+				},
+			}
+			var started []string
+			ocpp := &mocks.MockOCPPCommandService{
+				RemoteStartTransactionFunc: func(ctx context.Context, chargePointID, idToken string, tokenType domain.IdTokenType, evseID *int) error {
+					if evseID == nil || *evseID != 2 {
+						t.Errorf("expected a start on the reserved connector 2, got %v", evseID)
+					}
+					started = append(started, chargePointID+"/"+idToken)
+					return nil
+				},
+			}
+			svc := NewService(reservationStore(reservation), stations, nil, nil, zap.NewNop())
+			svc.SetOCPPService(ocpp)
+
+			// Act
+			err := svc.ActivateReservationWithLocation(context.Background(), "res-1", tt.lat, tt.lon)
+
+			// Assert
+			if tt.wantStarted {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if len(started) != 1 || started[0] != "CP001/user-1" {
+					t.Errorf("expected a remote start for user-1 on CP001, got %v", started)
+				}
+				if reservation.Status != domain.ReservationStatusActive || reservation.ActualArrival == nil {
+					t.Errorf("expected an active reservation with an arrival time, got %s", reservation.Status)
+				}
+				return
+			}
+			if !errors.Is(err, domain.ErrOutsideGeofence) {
+				t.Fatalf("expected %v, got %v", domain.ErrOutsideGeofence, err)
+			}
+			if len(started) != 0 {
+				t.Errorf("expected no remote start, got %v", started)
+			}
+			if reservation.Status != domain.ReservationStatusConfirmed {
+				t.Errorf("expected the reservation to stay confirmed, got %s", reservation.Status)
+			}
+		})
+	}
+}
+
+func TestActivateReservationWithLocation_StartRejected(t *testing.T) {
+	// Arrange
+	reservation := pendingReservation()
+	reservation.Status = domain.ReservationStatusConfirmed
+	stations := &mocks.MockChargePointRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Location: &domain.Location{Latitude: -23.5505, Longitude: -46.6333}}, nil
+		},
+	}
+	ocpp := &mocks.MockOCPPCommandService{
+		RemoteStartTransactionFunc: func(ctx context.Context, chargePointID, idToken string, tokenType domain.IdTokenType, evseID *int) error {
+			return errors.New("remote start rejected")
+		},
+	}
+	svc := NewService(reservationStore(reservation), stations, nil, nil, zap.NewNop())
+	svc.SetOCPPService(ocpp)
+
+	// Act
+	err := svc.ActivateReservationWithLocation(context.Background(), "res-1", -23.5505, -46.6333)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error when the station rejects the start")
+	}
+	if reservation.Status != domain.ReservationStatusConfirmed {
+		t.Errorf("expected the reservation to stay confirmed, got %s", reservation.Status)
+	}
+}
@@ -0,0 +1,94 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// StartTransactionSync completes a reservation when the transaction linked
+// to it stops
+func (s *Service) StartTransactionSync(mq queue.MessageQueue) error {
+	handler := func(data []byte) error {
+		var event struct {
+			TransactionID string `json:"transaction_id"`
+			UserID        string `json:"user_id"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil || event.TransactionID == "" || event.UserID == "" {
+			return nil
+		}
+		if err := s.CompleteForTransaction(context.Background(), event.UserID, event.TransactionID); err != nil {
+			s.log.Warn("Failed to complete reservation for transaction",
+				zap.String("transaction_id", event.TransactionID),
+				zap.Error(err),
+			)
+		}
+		return nil
+	}
+
+	if err := mq.Subscribe("transaction.completed", handler); err != nil {
+		return fmt.Errorf("failed to subscribe to transaction.completed: %w", err)
+	}
+	return nil
+}
+
+// CompleteForTransaction completes the active reservation of userID linked
+// to transactionID. Transactions without a reservation are ignored.
+func (s *Service) CompleteForTransaction(ctx context.Context, userID, transactionID string) error {
+	reservations, err := s.repo.GetByUserID(ctx, userID, string(domain.ReservationStatusActive), s.config.MaxActiveReservations, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get reservations: %w", err)
+	}
+
+	for _, reservation := range reservations {
+		if reservation.TransactionID == transactionID {
+			return s.CompleteReservation(ctx, reservation.ID)
+		}
+	}
+	return nil
+}
+
+// publishSessionEvent emits subject with the reservation's linked
+// transaction and, when the transaction service is set, the energy and cost
+// it has recorded
+func (s *Service) publishSessionEvent(ctx context.Context, subject string, reservation *domain.Reservation) {
+	if s.mq == nil {
+		return
+	}
+
+	event := map[string]interface{}{
+		"reservation_id": reservation.ID,
+		"user_id":        reservation.UserID,
+		"station_id":     reservation.ChargePointID,
+		"connector_id":   reservation.ConnectorID,
+		"status":         reservation.Status,
+		"transaction_id": reservation.TransactionID,
+		"timestamp":      time.Now().UTC().Format(time.RFC3339),
+	}
+	if s.txService != nil && reservation.TransactionID != "" {
+		tx, err := s.txService.GetTransaction(ctx, reservation.TransactionID)
+		if err != nil {
+			s.log.Warn("Failed to get reservation transaction",
+				zap.String("reservation_id", reservation.ID),
+				zap.String("transaction_id", reservation.TransactionID),
+				zap.Error(err),
+			)
+		} else if tx != nil {
+			event["energy_kwh"] = float64(tx.TotalEnergy) / 1000
+			event["cost"] = tx.Cost
+			event["currency"] = tx.Currency
+		}
+	}
+
+	if data, err := json.Marshal(event); err == nil {
+		if err := s.mq.Publish(subject, data); err != nil {
+			s.log.Warn("Failed to publish reservation event", zap.String("subject", subject), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// linkedReservationService serves an active reservation of user-1 linked to
+// tx-1, which delivered 12.5 kWh for 9.38
+func linkedReservationService(reservation *domain.Reservation, mq *mocks.MockMessageQueue) *Service {
+	repo := reservationStore(reservation)
+	repo.GetByUserIDFunc = func(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error) {
+		if userID != reservation.UserID || status != string(reservation.Status) {
+			return nil, nil
+		}
+		return []domain.Reservation{*reservation}, nil
+	}
+	svc := NewService(repo, nil, nil, nil, zap.NewNop())
+	svc.SetMessageQueue(mq)
+	svc.SetTransactionService(&mocks.MockTransactionService{
+		GetTransactionFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: id, TotalEnergy: 12500, Cost: 9.38, Currency: "BRL"}, nil
+		},
+	})
+	return svc
+}
+
+func activeReservation() *domain.Reservation {
+	reservation := pendingReservation()
+	reservation.Status = domain.ReservationStatusActive
+	reservation.TransactionID = "tx-1"
+	return reservation
+}
+
+func transactionCompleted(t *testing.T, mq *mocks.MockMessageQueue, transactionID string) {
+	t.Helper()
+	data, _ := json.Marshal(map[string]interface{}{
+		"transaction_id": transactionID,
+		"device_id":      "CP001",
+		"user_id":        "user-1",
+	})
+	for _, handler := range mq.Subscribers["transaction.completed"] {
+		handler(data)
+	}
+}
+
+func TestTransactionSync_CompletesLinkedReservation(t *testing.T) {
+	// Arrange
+	reservation := activeReservation()
+	mq := mocks.NewMockMessageQueue()
+	svc := linkedReservationService(reservation, mq)
+	if err := svc.StartTransactionSync(mq); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Act
+	transactionCompleted(t, mq, "tx-1")
+
+	// Assert
+	if reservation.Status != domain.ReservationStatusCompleted {
+		t.Fatalf("expected status %s, got %s", domain.ReservationStatusCompleted, reservation.Status)
+	}
+	published := mq.GetPublishedMessages("reservation.completed")
+	if len(published) != 1 {
+		t.Fatalf("expected one reservation.completed event, got %d", len(published))
+	}
+	var event map[string]interface{}
+	json.Unmarshal(published[0], &event)
+	if event["reservation_id"] != "res-1" || event["transaction_id"] != "tx-1" {
+		t.Errorf("expected event for res-1 and tx-1, got %v", event)
+	}
+	if event["energy_kwh"] != 12.5 || event["cost"] != 9.38 {
+		t.Errorf("expected 12.5 kWh for 9.38, got %v kWh for %v", event["energy_kwh"], event["cost"])
+	}
+}
+
+func TestTransactionSync_IgnoresOtherTransactions(t *testing.T) {
+	// Arrange
+	reservation := activeReservation()
+	mq := mocks.NewMockMessageQueue()
+	svc := linkedReservationService(reservation, mq)
+	svc.StartTransactionSync(mq)
+
+	// Act
+	transactionCompleted(t, mq, "tx-other")
+
+	// Assert
+	if reservation.Status != domain.ReservationStatusActive {
+		t.Errorf("expected status %s, got %s", domain.ReservationStatusActive, reservation.Status)
+	}
+	if published := mq.GetPublishedMessages("reservation.completed"); len(published) != 0 {
+		t.Errorf("expected no reservation.completed event, got %d", len(published))
+	}
+}
+
+func TestActivateReservation_PublishesActivated(t *testing.T) {
+	// Arrange
+	reservation := pendingReservation()
+	reservation.Status = domain.ReservationStatusConfirmed
+	mq := mocks.NewMockMessageQueue()
+	svc := linkedReservationService(reservation, mq)
+
+	// Act
+	err := svc.ActivateReservation(context.Background(), "res-1", "tx-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	published := mq.GetPublishedMessages("reservation.activated")
+	if len(published) != 1 {
+		t.Fatalf("expected one reservation.activated event, got %d", len(published))
+	}
+	var event map[string]interface{}
+	json.Unmarshal(published[0], &event)
+	if event["transaction_id"] != "tx-1" || event["status"] != string(domain.ReservationStatusActive) {
+		t.Errorf("expected an active event for tx-1, got %v", event)
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/seu-repo/sigec-ve/internal/adapter/http/fiber/middleware"
+	"github.com/seu-repo/sigec-ve/internal/infrastructure/validation"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
@@ -27,6 +29,7 @@ func (h *Handler) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler) {
 	reservations.Get("/:id", h.GetReservation)
 	reservations.Delete("/:id", h.CancelReservation)
 	reservations.Post("/:id/confirm", h.ConfirmReservation)
+	reservations.Post("/:id/arrive", h.ArriveReservation)
 
 	// Station availability
 	app.Get("/api/v1/stations/:id/availability", h.GetStationAvailability)
@@ -47,10 +50,8 @@ func (h *Handler) CreateReservation(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
 	var req CreateReservationRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	reservation, err := h.service.CreateReservation(c.Context(), &ports.ReservationRequest{
@@ -159,6 +160,44 @@ func (h *Handler) ConfirmReservation(c *fiber.Ctx) error {
 	})
 }
 
+// ArriveRequest is the location the app reports when the user arrives
+type ArriveRequest struct {
+	Latitude  float64 `json:"latitude" validate:"gte=-90,lte=90"`
+	Longitude float64 `json:"longitude" validate:"gte=-180,lte=180"`
+}
+
+// ArriveReservation handles POST /api/v1/reservations/:id/arrive
+func (h *Handler) ArriveReservation(c *fiber.Ctx) error {
+	id := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req ArriveRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	reservation, err := h.service.GetReservation(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	if reservation == nil {
+		return middleware.NewAPIError(fiber.StatusNotFound, middleware.CodeNotFound, "Reservation not found")
+	}
+	if reservation.UserID != userID {
+		return middleware.NewAPIError(fiber.StatusForbidden, middleware.CodeForbidden, "Access denied")
+	}
+
+	// Unknown failures, e.g. the repository or the station, reach the
+	// client as a generic internal error
+	if err := h.service.ActivateReservationWithLocation(c.Context(), id, req.Latitude, req.Longitude); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Reservation activated, charging is starting",
+	})
+}
+
 // GetStationAvailability handles GET /api/v1/stations/:id/availability
 func (h *Handler) GetStationAvailability(c *fiber.Ctx) error {
 	stationID := c.Params("id")
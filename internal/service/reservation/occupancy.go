@@ -0,0 +1,145 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// busyWindow is a period during which a connector cannot be reserved
+type busyWindow struct {
+	connectorID int // 0 = every connector of the station
+	start       time.Time
+	end         time.Time
+}
+
+// blocks reports whether the window occupies connectorID during [start, end).
+// A connectorID of 0 stands for the station as a whole.
+func (w busyWindow) blocks(connectorID int, start, end time.Time) bool {
+	if w.connectorID != 0 && connectorID != 0 && w.connectorID != connectorID {
+		return false
+	}
+	return start.Before(w.end) && end.After(w.start)
+}
+
+// SetTransactionService makes availability account for sessions that are
+// still running on the station
+func (s *Service) SetTransactionService(txService ports.TransactionService) {
+	s.txService = txService
+}
+
+// SetMaintenanceRepository makes availability account for planned maintenance
+func (s *Service) SetMaintenanceRepository(maintenance ports.MaintenanceWindowRepository) {
+	s.maintenance = maintenance
+}
+
+// slotDuration returns the configured booking granularity
+func (s *Service) slotDuration() time.Duration {
+	if s.config.SlotGranularityMinutes <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(s.config.SlotGranularityMinutes) * time.Minute
+}
+
+// occupancy returns the busy windows of a charge point overlapping [from, to):
+// the given reservations, running sessions and maintenance
+func (s *Service) occupancy(ctx context.Context, chargePointID string, reservations []domain.Reservation, from, to time.Time) ([]busyWindow, error) {
+	windows := make([]busyWindow, 0, len(reservations))
+	for _, r := range reservations {
+		if r.Status != domain.ReservationStatusPending &&
+			r.Status != domain.ReservationStatusConfirmed &&
+			r.Status != domain.ReservationStatusActive {
+			continue
+		}
+		windows = append(windows, busyWindow{connectorID: r.ConnectorID, start: r.StartTime, end: r.EndTime})
+	}
+
+	sessions, err := s.sessionWindows(ctx, chargePointID)
+	if err != nil {
+		return nil, err
+	}
+	windows = append(windows, sessions...)
+
+	if s.maintenance != nil {
+		planned, err := s.maintenance.FindOverlapping(ctx, chargePointID, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get maintenance windows: %w", err)
+		}
+		for _, m := range planned {
+			windows = append(windows, busyWindow{connectorID: m.ConnectorID, start: m.StartTime, end: m.EndTime})
+		}
+	}
+
+	return windows, nil
+}
+
+// sessionWindows returns the connectors held by running sessions, from their
+// start until their estimated end
+func (s *Service) sessionWindows(ctx context.Context, chargePointID string) ([]busyWindow, error) {
+	if s.txService == nil {
+		return nil, nil
+	}
+
+	sessions, err := s.txService.ListActiveSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active sessions: %w", err)
+	}
+
+	var windows []busyWindow
+	for i := range sessions {
+		tx := &sessions[i].Transaction
+		if tx.ChargePointID != chargePointID {
+			continue
+		}
+		windows = append(windows, busyWindow{
+			connectorID: tx.ConnectorID,
+			start:       tx.StartTime,
+			end:         s.estimatedSessionEnd(ctx, tx),
+		})
+	}
+	return windows, nil
+}
+
+// estimatedSessionEnd uses the session projection when it yields a completion
+// time and otherwise assumes the configured session length. A session that
+// overran its estimate still holds the connector for at least one more slot.
+func (s *Service) estimatedSessionEnd(ctx context.Context, tx *domain.Transaction) time.Time {
+	end := tx.StartTime.Add(time.Duration(s.config.ExpectedSessionMinutes) * time.Minute)
+
+	projection, err := s.txService.GetProjectedSessionCost(ctx, tx.UserID, domain.ChargeTarget{})
+	if err != nil {
+		s.log.Debug("No projection for active session",
+			zap.String("transaction_id", tx.ID),
+			zap.Error(err),
+		)
+	} else if projection != nil && projection.TransactionID == tx.ID && projection.EstimatedCompletion != nil {
+		end = *projection.EstimatedCompletion
+	}
+
+	if now := time.Now(); !end.After(now) {
+		end = now.Add(s.slotDuration())
+	}
+	return end
+}
+
+// connectorIDs lists the connectors of a charge point. Without connector data
+// the station is treated as a single resource, represented by 0.
+func (s *Service) connectorIDs(ctx context.Context, chargePointID string) []int {
+	if s.deviceRepo == nil {
+		return []int{0}
+	}
+	station, err := s.deviceRepo.FindByID(ctx, chargePointID)
+	if err != nil || station == nil || len(station.Connectors) == 0 {
+		return []int{0}
+	}
+	ids := make([]int, 0, len(station.Connectors))
+	for _, c := range station.Connectors {
+		ids = append(ids, c.ConnectorID)
+	}
+	return ids
+}
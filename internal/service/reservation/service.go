@@ -2,25 +2,38 @@ package reservation
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // Service implements ReservationService
 type Service struct {
-	repo          ports.ReservationRepository
-	deviceRepo    ports.ChargePointRepository
-	walletSvc     ports.WalletService
-	config        *domain.ReservationConfig
-	log           *zap.Logger
+	repo        ports.ReservationRepository
+	deviceRepo  ports.ChargePointRepository
+	walletSvc   ports.WalletService
+	ocpp        ports.OCPPCommandService          // optional, see SetOCPPService
+	txService   ports.TransactionService          // optional, see SetTransactionService
+	maintenance ports.MaintenanceWindowRepository // optional, see SetMaintenanceRepository
+	mq          queue.MessageQueue                // optional, see SetMessageQueue
+	config      *domain.ReservationConfig
+	locker      ports.Locker // Serializes confirmations of a reservation, see SetLocker
+	log         *zap.Logger
 }
 
+// confirmLockTTL bounds how long a crashed instance can block confirming a
+// reservation; confirming takes a ReserveNow round trip and a wallet debit
+const confirmLockTTL = time.Minute
+
 // NewService creates a new reservation service
 func NewService(
 	repo ports.ReservationRepository,
@@ -38,10 +51,47 @@ func NewService(
 		deviceRepo: deviceRepo,
 		walletSvc:  walletSvc,
 		config:     config,
+		locker:     cache.NewLocalLocker(),
 		log:        log,
 	}
 }
 
+// SetLocker shares the confirmation lock of each reservation between
+// instances, so a retry sent to another instance never captures the fee
+// twice. Without it confirmations are only serialized per instance.
+func (s *Service) SetLocker(locker ports.Locker) {
+	s.locker = locker
+}
+
+// SetOCPPService makes confirmed reservations hold the connector on the
+// charge point through ReserveNow
+func (s *Service) SetOCPPService(ocpp ports.OCPPCommandService) {
+	s.ocpp = ocpp
+}
+
+// ocppReservationID derives the integer reservation ID used on the wire from
+// the reservation UUID
+func ocppReservationID(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() & 0x7fffffff)
+}
+
+// releaseOnStation cancels the reservation on the charge point. Failures are
+// logged only: the reservation also expires on the station by itself.
+func (s *Service) releaseOnStation(ctx context.Context, reservation *domain.Reservation) {
+	if s.ocpp == nil || reservation.OCPPReservationID == 0 {
+		return
+	}
+	if err := s.ocpp.CancelReservation(ctx, reservation.ChargePointID, reservation.OCPPReservationID); err != nil {
+		s.log.Warn("Failed to cancel reservation on station",
+			zap.String("reservation_id", reservation.ID),
+			zap.String("station_id", reservation.ChargePointID),
+			zap.Error(err),
+		)
+	}
+}
+
 // CreateReservation creates a new reservation
 func (s *Service) CreateReservation(ctx context.Context, req *ports.ReservationRequest) (*domain.Reservation, error) {
 	// Validate request
@@ -101,7 +151,7 @@ func (s *Service) CreateReservation(ctx context.Context, req *ports.ReservationR
 	// Process payment if required
 	if s.config.RequirePaymentUpfront && s.config.ReservationFee > 0 {
 		if s.walletSvc != nil {
-			hasFunds, err := s.walletSvc.HasSufficientBalance(ctx, req.UserID, s.config.ReservationFee)
+			hasFunds, err := s.walletSvc.HasSufficientBalance(ctx, req.UserID, s.config.ReservationFee, s.config.Currency)
 			if err != nil {
 				return nil, fmt.Errorf("failed to check balance: %w", err)
 			}
@@ -109,7 +159,7 @@ func (s *Service) CreateReservation(ctx context.Context, req *ports.ReservationR
 				return nil, fmt.Errorf("insufficient balance for reservation fee")
 			}
 
-			err = s.walletSvc.DeductFunds(ctx, req.UserID, s.config.ReservationFee, "Reservation fee", reservation.ID)
+			err = s.walletSvc.DeductFunds(ctx, req.UserID, s.config.ReservationFee, s.config.Currency, "Reservation fee", reservation.ID)
 			if err != nil {
 				return nil, fmt.Errorf("failed to process reservation fee: %w", err)
 			}
@@ -121,7 +171,7 @@ func (s *Service) CreateReservation(ctx context.Context, req *ports.ReservationR
 	if err := s.repo.Save(ctx, reservation); err != nil {
 		// Refund if payment was made
 		if reservation.FeePaid && s.walletSvc != nil {
-			s.walletSvc.AddFunds(ctx, req.UserID, s.config.ReservationFee, "")
+			s.walletSvc.AddFunds(ctx, req.UserID, s.config.ReservationFee, s.config.Currency, reservation.ID)
 		}
 		return nil, fmt.Errorf("failed to save reservation: %w", err)
 	}
@@ -218,9 +268,12 @@ func (s *Service) CancelReservation(ctx context.Context, id string, userID strin
 		return fmt.Errorf("failed to update reservation: %w", err)
 	}
 
+	// Free the connector for walk-up users
+	s.releaseOnStation(ctx, reservation)
+
 	// Process refund if eligible and fee was paid
 	if refundEligible && reservation.FeePaid && s.walletSvc != nil {
-		if err := s.walletSvc.AddFunds(ctx, reservation.UserID, reservation.Fee, ""); err != nil {
+		if err := s.walletSvc.AddFunds(ctx, reservation.UserID, reservation.Fee, s.config.Currency, reservation.ID); err != nil {
 			s.log.Error("Failed to refund reservation fee",
 				zap.String("reservation_id", id),
 				zap.Error(err),
@@ -237,8 +290,28 @@ func (s *Service) CancelReservation(ctx context.Context, id string, userID strin
 	return nil
 }
 
-// ConfirmReservation confirms a pending reservation
+// ConfirmReservation confirms a pending reservation and captures its fee.
+// Confirming again is a no-op, so retries never charge the fee twice. A
+// reservation confirmed after its start time is already running and becomes
+// active at once; after the grace period it expires instead. A reservation
+// being confirmed by another request gets domain.ErrConflict.
 func (s *Service) ConfirmReservation(ctx context.Context, id string) error {
+	lock, err := s.locker.TryLock(ctx, "reservation:"+id, confirmLockTTL)
+	if errors.Is(err, ports.ErrLockHeld) {
+		return fmt.Errorf("%w: reservation is already being confirmed", domain.ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock reservation: %w", err)
+	}
+	defer func() {
+		// Release even if the caller's context is already done
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := lock.Release(releaseCtx); err != nil {
+			s.log.Warn("Failed to release reservation lock", zap.String("reservation_id", id), zap.Error(err))
+		}
+	}()
+
 	reservation, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get reservation: %w", err)
@@ -247,22 +320,93 @@ func (s *Service) ConfirmReservation(ctx context.Context, id string) error {
 		return fmt.Errorf("reservation not found")
 	}
 
-	if reservation.Status != domain.ReservationStatusPending {
+	switch reservation.Status {
+	case domain.ReservationStatusPending:
+	case domain.ReservationStatusConfirmed, domain.ReservationStatusActive:
+		s.log.Debug("Reservation already confirmed", zap.String("reservation_id", id))
+		return nil
+	default:
 		return fmt.Errorf("can only confirm pending reservations")
 	}
 
+	now := time.Now()
+	grace := time.Duration(s.config.GracePeriodMinutes) * time.Minute
+	if !now.Before(reservation.StartTime.Add(grace)) || !now.Before(reservation.EndTime) {
+		reservation.Status = domain.ReservationStatusExpired
+		reservation.UpdatedAt = now
+		if err := s.repo.Save(ctx, reservation); err != nil {
+			return fmt.Errorf("failed to update reservation: %w", err)
+		}
+		s.log.Info("Late confirmation expired reservation", zap.String("reservation_id", id))
+		return domain.ErrReservationExpired
+	}
+	started := !now.Before(reservation.StartTime)
+
+	// Hold the connector on the charge point until the grace period after
+	// the start time has passed. A reservation that already started is
+	// picked up by the user's next session instead (see LinkTransaction).
+	if s.ocpp != nil && !started {
+		reservationID := ocppReservationID(reservation.ID)
+		expiry := reservation.StartTime.Add(grace)
+		if err := s.ocpp.ReserveNow(ctx, reservation.ChargePointID, reservationID, reservation.ConnectorID, reservation.UserID, expiry); err != nil {
+			return fmt.Errorf("station did not accept reservation: %w", err)
+		}
+		reservation.OCPPReservationID = reservationID
+	}
+
+	captured, err := s.captureFee(ctx, reservation)
+	if err != nil {
+		s.releaseOnStation(ctx, reservation)
+		return err
+	}
+
 	reservation.Status = domain.ReservationStatusConfirmed
-	reservation.UpdatedAt = time.Now()
+	if started {
+		reservation.Status = domain.ReservationStatusActive
+	}
+	reservation.UpdatedAt = now
 
 	if err := s.repo.Save(ctx, reservation); err != nil {
+		s.releaseOnStation(ctx, reservation)
+		if captured {
+			s.refundFee(ctx, reservation)
+		}
 		return fmt.Errorf("failed to update reservation: %w", err)
 	}
 
-	s.log.Info("Reservation confirmed", zap.String("reservation_id", id))
+	s.log.Info("Reservation confirmed",
+		zap.String("reservation_id", id),
+		zap.String("status", string(reservation.Status)),
+		zap.Bool("fee_captured", captured),
+	)
 
 	return nil
 }
 
+// captureFee charges the reservation fee unless it was already paid. It
+// reports whether it charged anything.
+func (s *Service) captureFee(ctx context.Context, reservation *domain.Reservation) (bool, error) {
+	if reservation.FeePaid || reservation.Fee <= 0 || s.walletSvc == nil {
+		return false, nil
+	}
+	if err := s.walletSvc.DeductFunds(ctx, reservation.UserID, reservation.Fee, s.config.Currency, "Reservation fee", reservation.ID); err != nil {
+		return false, fmt.Errorf("failed to process reservation fee: %w", err)
+	}
+	reservation.FeePaid = true
+	return true, nil
+}
+
+// refundFee returns a fee captured for a confirmation that could not be stored
+func (s *Service) refundFee(ctx context.Context, reservation *domain.Reservation) {
+	if err := s.walletSvc.AddFunds(ctx, reservation.UserID, reservation.Fee, s.config.Currency, reservation.ID); err != nil {
+		s.log.Error("Failed to refund reservation fee",
+			zap.String("reservation_id", reservation.ID),
+			zap.Error(err),
+		)
+	}
+	reservation.FeePaid = false
+}
+
 // ActivateReservation marks user as arrived and starts charging
 func (s *Service) ActivateReservation(ctx context.Context, id string, transactionID string) error {
 	reservation, err := s.repo.GetByID(ctx, id)
@@ -287,6 +431,10 @@ func (s *Service) ActivateReservation(ctx context.Context, id string, transactio
 		return fmt.Errorf("failed to update reservation: %w", err)
 	}
 
+	// The user is charging, so the hold on the connector is no longer needed
+	s.releaseOnStation(ctx, reservation)
+	s.publishSessionEvent(ctx, "reservation.activated", reservation)
+
 	s.log.Info("Reservation activated",
 		zap.String("reservation_id", id),
 		zap.String("transaction_id", transactionID),
@@ -311,13 +459,15 @@ func (s *Service) CompleteReservation(ctx context.Context, id string) error {
 	if err := s.repo.Save(ctx, reservation); err != nil {
 		return fmt.Errorf("failed to update reservation: %w", err)
 	}
+	s.publishSessionEvent(ctx, "reservation.completed", reservation)
 
 	s.log.Info("Reservation completed", zap.String("reservation_id", id))
 
 	return nil
 }
 
-// CheckAvailability checks if a time slot is available
+// CheckAvailability reports whether a connector is free for the whole period:
+// no overlapping reservation, running session or maintenance window
 func (s *Service) CheckAvailability(ctx context.Context, chargePointID string, connectorID int, startTime, endTime time.Time) (bool, error) {
 	// Get existing reservations that overlap
 	existing, err := s.repo.GetByTimeRange(ctx, chargePointID, connectorID, startTime, endTime)
@@ -325,11 +475,12 @@ func (s *Service) CheckAvailability(ctx context.Context, chargePointID string, c
 		return false, fmt.Errorf("failed to check existing reservations: %w", err)
 	}
 
-	// Filter out cancelled/completed
-	for _, r := range existing {
-		if r.Status == domain.ReservationStatusPending ||
-			r.Status == domain.ReservationStatusConfirmed ||
-			r.Status == domain.ReservationStatusActive {
+	windows, err := s.occupancy(ctx, chargePointID, existing, startTime, endTime)
+	if err != nil {
+		return false, err
+	}
+	for _, w := range windows {
+		if w.blocks(connectorID, startTime, endTime) {
 			return false, nil
 		}
 	}
@@ -337,7 +488,9 @@ func (s *Service) CheckAvailability(ctx context.Context, chargePointID string, c
 	return true, nil
 }
 
-// GetAvailableSlots returns available time slots for a station
+// GetAvailableSlots returns the bookable slots of a station for a day (6 AM to
+// 10 PM at the configured granularity). A slot is available while at least one
+// connector is free of reservations, running sessions and maintenance.
 func (s *Service) GetAvailableSlots(ctx context.Context, chargePointID string, date time.Time) ([]domain.TimeSlot, error) {
 	// Get all reservations for the day
 	reservations, err := s.repo.GetByChargePointID(ctx, chargePointID, date)
@@ -345,40 +498,33 @@ func (s *Service) GetAvailableSlots(ctx context.Context, chargePointID string, d
 		return nil, fmt.Errorf("failed to get reservations: %w", err)
 	}
 
-	// Build slots for the day (6 AM to 10 PM, 30-minute slots)
-	slots := make([]domain.TimeSlot, 0)
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 6, 0, 0, 0, date.Location())
 	endOfDay := time.Date(date.Year(), date.Month(), date.Day(), 22, 0, 0, 0, date.Location())
 
-	slotDuration := 30 * time.Minute
+	windows, err := s.occupancy(ctx, chargePointID, reservations, startOfDay, endOfDay)
+	if err != nil {
+		return nil, err
+	}
+	connectors := s.connectorIDs(ctx, chargePointID)
+
+	slotDuration := s.slotDuration()
+	now := time.Now()
+	slots := make([]domain.TimeSlot, 0)
 
 	for current := startOfDay; current.Before(endOfDay); current = current.Add(slotDuration) {
 		slotEnd := current.Add(slotDuration)
 
-		// Check if slot is available
-		available := true
-		for _, r := range reservations {
-			if r.Status != domain.ReservationStatusPending &&
-				r.Status != domain.ReservationStatusConfirmed &&
-				r.Status != domain.ReservationStatusActive {
-				continue
-			}
-
-			// Check for overlap
-			if (current.Before(r.EndTime) || current.Equal(r.EndTime)) &&
-				(slotEnd.After(r.StartTime) || slotEnd.Equal(r.StartTime)) {
-				if current.Before(r.EndTime) && slotEnd.After(r.StartTime) {
-					available = false
+		// Don't show past slots
+		available := false
+		if !current.Before(now) {
+			for _, connectorID := range connectors {
+				if connectorFree(windows, connectorID, current, slotEnd) {
+					available = true
 					break
 				}
 			}
 		}
 
-		// Don't show past slots
-		if current.Before(time.Now()) {
-			available = false
-		}
-
 		slots = append(slots, domain.TimeSlot{
 			StartTime: current,
 			EndTime:   slotEnd,
@@ -389,6 +535,16 @@ func (s *Service) GetAvailableSlots(ctx context.Context, chargePointID string, d
 	return slots, nil
 }
 
+// connectorFree reports whether no busy window occupies the connector during [start, end)
+func connectorFree(windows []busyWindow, connectorID int, start, end time.Time) bool {
+	for _, w := range windows {
+		if w.blocks(connectorID, start, end) {
+			return false
+		}
+	}
+	return true
+}
+
 // ProcessExpiredReservations processes reservations that have expired
 func (s *Service) ProcessExpiredReservations(ctx context.Context) error {
 	gracePeriod := time.Duration(s.config.GracePeriodMinutes) * time.Minute
@@ -412,7 +568,7 @@ func (s *Service) ProcessExpiredReservations(ctx context.Context) error {
 
 		// Apply no-show penalty
 		if s.config.NoShowPenalty > 0 && s.walletSvc != nil {
-			if err := s.walletSvc.DeductFunds(ctx, r.UserID, s.config.NoShowPenalty, "No-show penalty", r.ID); err != nil {
+			if err := s.walletSvc.DeductFunds(ctx, r.UserID, s.config.NoShowPenalty, s.config.Currency, "No-show penalty", r.ID); err != nil {
 				s.log.Error("Failed to apply no-show penalty",
 					zap.String("reservation_id", r.ID),
 					zap.Error(err),
@@ -0,0 +1,479 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// reservationStore keeps one reservation in memory
+func reservationStore(reservation *domain.Reservation) *mocks.MockReservationRepository {
+	return &mocks.MockReservationRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*domain.Reservation, error) {
+			if id != reservation.ID {
+				return nil, nil
+			}
+			clone := *reservation
+			return &clone, nil
+		},
+		SaveFunc: func(ctx context.Context, r *domain.Reservation) error {
+			*reservation = *r
+			return nil
+		},
+	}
+}
+
+func pendingReservation() *domain.Reservation {
+	return &domain.Reservation{
+		ID:            "res-1",
+		UserID:        "user-1",
+		ChargePointID: "CP001",
+		ConnectorID:   2,
+		Status:        domain.ReservationStatusPending,
+		StartTime:     time.Now().Add(time.Hour),
+		EndTime:       time.Now().Add(2 * time.Hour),
+	}
+}
+
+type reserveNowCall struct {
+	chargePointID string
+	reservationID int
+	connectorID   int
+	idToken       string
+	expiry        time.Time
+}
+
+func TestConfirmReservation_SendsReserveNow(t *testing.T) {
+	// Arrange
+	reservation := pendingReservation()
+	var calls []reserveNowCall
+	ocpp := &mocks.MockOCPPCommandService{
+		ReserveNowFunc: func(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error {
+			calls = append(calls, reserveNowCall{chargePointID, reservationID, connectorID, idToken, expiry})
+			return nil
+		},
+	}
+	svc := NewService(reservationStore(reservation), nil, nil, nil, zap.NewNop())
+	svc.SetOCPPService(ocpp)
+
+	// Act
+	err := svc.ConfirmReservation(context.Background(), "res-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected one ReserveNow, got %d", len(calls))
+	}
+	call := calls[0]
+	if call.chargePointID != "CP001" || call.connectorID != 2 || call.idToken != "user-1" {
+		t.Errorf("expected ReserveNow for CP001 connector 2 and user-1, got %+v", call)
+	}
+	grace := time.Duration(domain.DefaultReservationConfig().GracePeriodMinutes) * time.Minute
+	if !call.expiry.Equal(reservation.StartTime.Add(grace)) {
+		t.Errorf("expected expiry at start time plus grace period, got %v", call.expiry)
+	}
+	if reservation.Status != domain.ReservationStatusConfirmed {
+		t.Errorf("expected status confirmed, got %s", reservation.Status)
+	}
+	if reservation.OCPPReservationID != call.reservationID || call.reservationID <= 0 {
+		t.Errorf("expected OCPP reservation ID %d to be stored, got %d", call.reservationID, reservation.OCPPReservationID)
+	}
+}
+
+func TestConfirmReservation_StationRejects(t *testing.T) {
+	// Arrange
+	reservation := pendingReservation()
+	ocpp := &mocks.MockOCPPCommandService{
+		ReserveNowFunc: func(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error {
+			return errors.New("reservation status: Occupied")
+		},
+	}
+	svc := NewService(reservationStore(reservation), nil, nil, nil, zap.NewNop())
+	svc.SetOCPPService(ocpp)
+
+	// Act
+	err := svc.ConfirmReservation(context.Background(), "res-1")
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error when the station rejects the reservation")
+	}
+	if reservation.Status != domain.ReservationStatusPending {
+		t.Errorf("expected reservation to stay pending, got %s", reservation.Status)
+	}
+}
+
+func TestCancelAndActivateReservation_ReleaseStation(t *testing.T) {
+	tests := []struct {
+		name string
+		act  func(svc *Service) error
+	}{
+		{"cancel", func(svc *Service) error {
+			return svc.CancelReservation(context.Background(), "res-1", "user-1", "plans changed")
+		}},
+		{"activate", func(svc *Service) error {
+			return svc.ActivateReservation(context.Background(), "res-1", "tx-1")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reservation := pendingReservation()
+			reservation.Status = domain.ReservationStatusConfirmed
+			reservation.OCPPReservationID = 4242
+			var cancelled []int
+			ocpp := &mocks.MockOCPPCommandService{
+				CancelReservationFunc: func(ctx context.Context, chargePointID string, reservationID int) error {
+					cancelled = append(cancelled, reservationID)
+					return nil
+				},
+			}
+			svc := NewService(reservationStore(reservation), nil, nil, nil, zap.NewNop())
+			svc.SetOCPPService(ocpp)
+
+			// Act
+			err := tt.act(svc)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(cancelled) != 1 || cancelled[0] != 4242 {
+				t.Errorf("expected CancelReservation for 4242, got %v", cancelled)
+			}
+		})
+	}
+}
+
+// feeWallet records reservation fee debits and refunds
+type feeWallet struct {
+	debits  []float64
+	credits []float64
+}
+
+func (w *feeWallet) GetWallet(ctx context.Context, userID string) (*domain.Wallet, error) {
+	return &domain.Wallet{UserID: userID, Currency: "BRL"}, nil
+}
+
+func (w *feeWallet) AddFunds(ctx context.Context, userID string, amount float64, currency string, paymentID string) error {
+	w.credits = append(w.credits, amount)
+	return nil
+}
+
+func (w *feeWallet) DeductFunds(ctx context.Context, userID string, amount float64, currency string, description string, referenceID string) error {
+	w.debits = append(w.debits, amount)
+	return nil
+}
+
+func (w *feeWallet) GetTransactions(ctx context.Context, userID string, limit, offset int) ([]domain.WalletTransaction, error) {
+	return nil, nil
+}
+
+func (w *feeWallet) HasSufficientBalance(ctx context.Context, userID string, amount float64, currency string) (bool, error) {
+	return true, nil
+}
+
+func TestConfirmReservation_DoubleConfirmCapturesFeeOnce(t *testing.T) {
+	// Arrange
+	reservation := pendingReservation()
+	reservation.Fee = 5
+	wallet := &feeWallet{}
+	reserveNowCalls := 0
+	ocpp := &mocks.MockOCPPCommandService{
+		ReserveNowFunc: func(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error {
+			reserveNowCalls++
+			return nil
+		},
+	}
+	svc := NewService(reservationStore(reservation), nil, wallet, nil, zap.NewNop())
+	svc.SetOCPPService(ocpp)
+
+	// Act
+	first := svc.ConfirmReservation(context.Background(), "res-1")
+	second := svc.ConfirmReservation(context.Background(), "res-1")
+
+	// Assert
+	if first != nil || second != nil {
+		t.Fatalf("expected both confirmations to succeed, got %v and %v", first, second)
+	}
+	if len(wallet.debits) != 1 || wallet.debits[0] != 5 {
+		t.Errorf("expected the fee to be captured once, got %v", wallet.debits)
+	}
+	if reserveNowCalls != 1 {
+		t.Errorf("expected one ReserveNow, got %d", reserveNowCalls)
+	}
+	if reservation.Status != domain.ReservationStatusConfirmed || !reservation.FeePaid {
+		t.Errorf("expected a confirmed reservation with the fee paid, got %s (paid=%v)", reservation.Status, reservation.FeePaid)
+	}
+}
+
+func TestConfirmReservation_LockedByAnotherInstance(t *testing.T) {
+	// Arrange - another instance is confirming the same reservation
+	reservation := pendingReservation()
+	reservation.Fee = 5
+	wallet := &feeWallet{}
+	locker := cache.NewLocalLocker()
+	held, err := locker.TryLock(context.Background(), "reservation:res-1", time.Minute)
+	if err != nil {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+	svc := NewService(reservationStore(reservation), nil, wallet, nil, zap.NewNop())
+	svc.SetLocker(locker)
+
+	// Act
+	busy := svc.ConfirmReservation(context.Background(), "res-1")
+	held.Release(context.Background())
+	retried := svc.ConfirmReservation(context.Background(), "res-1")
+
+	// Assert
+	if !errors.Is(busy, domain.ErrConflict) {
+		t.Errorf("expected a conflict while the lock is held, got %v", busy)
+	}
+	if retried != nil {
+		t.Fatalf("expected the retry to confirm, got %v", retried)
+	}
+	if len(wallet.debits) != 1 {
+		t.Errorf("expected the fee to be captured once, got %v", wallet.debits)
+	}
+}
+
+func TestConfirmReservation_UpfrontFeeNotCapturedAgain(t *testing.T) {
+	// Arrange
+	reservation := pendingReservation()
+	reservation.Fee = 5
+	reservation.FeePaid = true
+	wallet := &feeWallet{}
+	svc := NewService(reservationStore(reservation), nil, wallet, nil, zap.NewNop())
+
+	// Act
+	err := svc.ConfirmReservation(context.Background(), "res-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(wallet.debits) != 0 {
+		t.Errorf("expected no fee capture, got %v", wallet.debits)
+	}
+}
+
+func TestConfirmReservation_LateConfirm(t *testing.T) {
+	grace := time.Duration(domain.DefaultReservationConfig().GracePeriodMinutes) * time.Minute
+
+	tests := []struct {
+		name       string
+		startTime  time.Time
+		wantStatus domain.ReservationStatus
+		wantErr    error
+		wantFee    bool
+	}{
+		{
+			name:       "after start, within grace",
+			startTime:  time.Now().Add(-grace / 2),
+			wantStatus: domain.ReservationStatusActive,
+			wantFee:    true,
+		},
+		{
+			name:       "after grace period",
+			startTime:  time.Now().Add(-grace - time.Minute),
+			wantStatus: domain.ReservationStatusExpired,
+			wantErr:    domain.ErrReservationExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reservation := pendingReservation()
+			reservation.Fee = 5
+			reservation.StartTime = tt.startTime
+			reservation.EndTime = tt.startTime.Add(2 * time.Hour)
+			wallet := &feeWallet{}
+			ocpp := &mocks.MockOCPPCommandService{
+				ReserveNowFunc: func(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error {
+					t.Error("expected no ReserveNow for a reservation that already started")
+					return nil
+				},
+			}
+			svc := NewService(reservationStore(reservation), nil, wallet, nil, zap.NewNop())
+			svc.SetOCPPService(ocpp)
+
+			// Act
+			err := svc.ConfirmReservation(context.Background(), "res-1")
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if reservation.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, reservation.Status)
+			}
+			if captured := len(wallet.debits) == 1; captured != tt.wantFee {
+				t.Errorf("expected fee captured=%v, got debits %v", tt.wantFee, wallet.debits)
+			}
+		})
+	}
+}
+
+// activeSessionOn returns a transaction service with one running session on
+// CP001 and, when completion is set, a projection ending there
+func activeSessionOn(connectorID int, startTime time.Time, completion *time.Time) *mocks.MockTransactionService {
+	tx := domain.Transaction{
+		ID:            "tx-1",
+		ChargePointID: "CP001",
+		ConnectorID:   connectorID,
+		UserID:        "user-2",
+		StartTime:     startTime,
+	}
+	return &mocks.MockTransactionService{
+		ListActiveSessionsFunc: func(ctx context.Context) ([]domain.ActiveSession, error) {
+			return []domain.ActiveSession{
+				{Transaction: tx},
+				{Transaction: domain.Transaction{ID: "tx-2", ChargePointID: "CP999", ConnectorID: connectorID, StartTime: startTime}},
+			}, nil
+		},
+		GetProjectedSessionCostFunc: func(ctx context.Context, userID string, target domain.ChargeTarget) (*domain.SessionCostProjection, error) {
+			if userID != tx.UserID || completion == nil {
+				return nil, domain.ErrNoActiveSession
+			}
+			return &domain.SessionCostProjection{TransactionID: tx.ID, EstimatedCompletion: completion}, nil
+		},
+	}
+}
+
+func TestCheckAvailability_ActiveSession(t *testing.T) {
+	now := time.Now()
+	projected := now.Add(3 * time.Hour)
+
+	tests := []struct {
+		name        string
+		completion  *time.Time
+		connectorID int
+		start       time.Time
+		want        bool
+	}{
+		{name: "session blocks its connector until the expected end", connectorID: 2, start: now.Add(10 * time.Minute), want: false},
+		{name: "other connector is free", connectorID: 1, start: now.Add(10 * time.Minute), want: true},
+		{name: "connector free after the expected end", connectorID: 2, start: now.Add(2 * time.Hour), want: true},
+		{name: "projection extends the session", completion: &projected, connectorID: 2, start: now.Add(2 * time.Hour), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			svc := NewService(&mocks.MockReservationRepository{}, nil, nil, nil, zap.NewNop())
+			svc.SetTransactionService(activeSessionOn(2, now.Add(-10*time.Minute), tt.completion))
+
+			// Act
+			available, err := svc.CheckAvailability(context.Background(), "CP001", tt.connectorID, tt.start, tt.start.Add(30*time.Minute))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if available != tt.want {
+				t.Errorf("expected available=%v, got %v", tt.want, available)
+			}
+		})
+	}
+}
+
+func TestCheckAvailability_MaintenanceBlocksWholeStation(t *testing.T) {
+	// Arrange
+	start := time.Now().Add(24 * time.Hour)
+	svc := NewService(&mocks.MockReservationRepository{}, nil, nil, nil, zap.NewNop())
+	svc.SetMaintenanceRepository(&mocks.MockMaintenanceWindowRepository{
+		FindOverlappingFunc: func(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+			return []domain.MaintenanceWindow{{ChargePointID: chargePointID, StartTime: start, EndTime: start.Add(time.Hour)}}, nil
+		},
+	})
+
+	// Act
+	available, err := svc.CheckAvailability(context.Background(), "CP001", 3, start.Add(30*time.Minute), start.Add(90*time.Minute))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if available {
+		t.Error("expected maintenance to block the connector")
+	}
+}
+
+func TestGetAvailableSlots_ActiveSessionBlocksFreeSlot(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	at := func(hour int) time.Time {
+		return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, 0, 0, 0, tomorrow.Location())
+	}
+	completion := at(8).Add(30 * time.Minute)
+
+	tests := []struct {
+		name        string
+		connectors  []domain.Connector
+		wantBlocked []int // slot start hours
+	}{
+		{
+			name:        "single connector",
+			connectors:  []domain.Connector{{ConnectorID: 1}},
+			wantBlocked: []int{6, 7, 8, 12},
+		},
+		{
+			name:        "second connector stays free except during station maintenance",
+			connectors:  []domain.Connector{{ConnectorID: 1}, {ConnectorID: 2}},
+			wantBlocked: []int{12},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config := domain.DefaultReservationConfig()
+			config.SlotGranularityMinutes = 60
+			stations := &mocks.MockChargePointRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+					return &domain.ChargePoint{ID: id, Connectors: tt.connectors}, nil
+				},
+			}
+			svc := NewService(&mocks.MockReservationRepository{}, stations, nil, config, zap.NewNop())
+			svc.SetTransactionService(activeSessionOn(1, time.Now().Add(-time.Hour), &completion))
+			svc.SetMaintenanceRepository(&mocks.MockMaintenanceWindowRepository{
+				FindOverlappingFunc: func(ctx context.Context, chargePointID string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+					return []domain.MaintenanceWindow{{ChargePointID: chargePointID, StartTime: at(12), EndTime: at(13)}}, nil
+				},
+			})
+
+			// Act
+			slots, err := svc.GetAvailableSlots(context.Background(), "CP001", tomorrow)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(slots) != 16 {
+				t.Fatalf("expected 16 hourly slots, got %d", len(slots))
+			}
+			blocked := make(map[int]bool)
+			for _, hour := range tt.wantBlocked {
+				blocked[hour] = true
+			}
+			for _, slot := range slots {
+				if slot.EndTime.Sub(slot.StartTime) != time.Hour {
+					t.Fatalf("expected hourly slots, got %v", slot.EndTime.Sub(slot.StartTime))
+				}
+				if slot.Available == blocked[slot.StartTime.Hour()] {
+					t.Errorf("expected slot %02d:00 available=%v, got %v", slot.StartTime.Hour(), !blocked[slot.StartTime.Hour()], slot.Available)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// Reservation updates reported by charge points through ReservationStatusUpdate
+const (
+	StationUpdateExpired = "Expired"
+	StationUpdateRemoved = "Removed"
+	StationUpdateUsed    = "Used"
+)
+
+// stationUpdateStatus maps a station-reported update to our status
+var stationUpdateStatus = map[string]domain.ReservationStatus{
+	StationUpdateExpired: domain.ReservationStatusExpired,
+	StationUpdateRemoved: domain.ReservationStatusCancelled,
+	StationUpdateUsed:    domain.ReservationStatusActive,
+}
+
+var _ ports.ReservationSync = (*Service)(nil)
+
+// SetMessageQueue enables reservation.updated events
+func (s *Service) SetMessageQueue(mq queue.MessageQueue) {
+	s.mq = mq
+}
+
+// ApplyStationUpdate reconciles the reservation held on chargePointID with a
+// status the station reported. Only pending or confirmed reservations change:
+// an update that arrives after we cancelled or activated it is a late echo.
+func (s *Service) ApplyStationUpdate(ctx context.Context, chargePointID string, reservationID int, status string) (*domain.Reservation, error) {
+	newStatus, ok := stationUpdateStatus[status]
+	if !ok {
+		return nil, fmt.Errorf("unknown reservation update status %q", status)
+	}
+
+	reservation, err := s.repo.GetByOCPPReservationID(ctx, chargePointID, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	if reservation == nil {
+		return nil, nil
+	}
+	if !reservation.IsPending() {
+		s.log.Debug("Ignoring station update for settled reservation",
+			zap.String("reservation_id", reservation.ID),
+			zap.String("status", string(reservation.Status)),
+			zap.String("station_status", status),
+		)
+		return reservation, nil
+	}
+
+	now := time.Now()
+	reservation.Status = newStatus
+	reservation.UpdatedAt = now
+	switch status {
+	case StationUpdateRemoved:
+		reservation.CancellationReason = "Removed by charge point"
+	case StationUpdateUsed:
+		reservation.ActualArrival = &now
+	}
+
+	if err := s.repo.Save(ctx, reservation); err != nil {
+		return nil, fmt.Errorf("failed to update reservation: %w", err)
+	}
+	s.publishUpdated(reservation, "station")
+
+	s.log.Info("Reservation updated by station",
+		zap.String("reservation_id", reservation.ID),
+		zap.String("station_id", chargePointID),
+		zap.String("station_status", status),
+		zap.String("status", string(newStatus)),
+	)
+
+	return reservation, nil
+}
+
+// LinkTransaction links transactionID to the used reservation of idToken on
+// chargePointID that has no transaction yet. Reservations are held with the
+// user ID as idToken (see ConfirmReservation).
+func (s *Service) LinkTransaction(ctx context.Context, chargePointID, idToken, transactionID string) error {
+	reservations, err := s.repo.GetByUserID(ctx, idToken, string(domain.ReservationStatusActive), s.config.MaxActiveReservations, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get reservations: %w", err)
+	}
+
+	for i := range reservations {
+		reservation := &reservations[i]
+		if reservation.ChargePointID != chargePointID || reservation.TransactionID != "" {
+			continue
+		}
+
+		reservation.TransactionID = transactionID
+		reservation.UpdatedAt = time.Now()
+		if err := s.repo.Save(ctx, reservation); err != nil {
+			return fmt.Errorf("failed to update reservation: %w", err)
+		}
+		s.publishUpdated(reservation, "transaction")
+
+		s.log.Info("Reservation linked to transaction",
+			zap.String("reservation_id", reservation.ID),
+			zap.String("transaction_id", transactionID),
+		)
+		return nil
+	}
+
+	return nil
+}
+
+// publishUpdated emits reservation.updated; source tells what changed it
+func (s *Service) publishUpdated(reservation *domain.Reservation, source string) {
+	if s.mq == nil {
+		return
+	}
+
+	event := map[string]interface{}{
+		"reservation_id": reservation.ID,
+		"user_id":        reservation.UserID,
+		"station_id":     reservation.ChargePointID,
+		"status":         reservation.Status,
+		"transaction_id": reservation.TransactionID,
+		"source":         source,
+		"timestamp":      time.Now().UTC().Format(time.RFC3339),
+	}
+	if data, err := json.Marshal(event); err == nil {
+		if err := s.mq.Publish("reservation.updated", data); err != nil {
+			s.log.Warn("Failed to publish reservation updated event", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// heldReservation is a confirmed reservation held on CP001 as reservation 77
+func heldReservation() *domain.Reservation {
+	reservation := pendingReservation()
+	reservation.Status = domain.ReservationStatusConfirmed
+	reservation.OCPPReservationID = 77
+	return reservation
+}
+
+func stationStore(reservation *domain.Reservation) *mocks.MockReservationRepository {
+	repo := reservationStore(reservation)
+	repo.GetByOCPPReservationIDFunc = func(ctx context.Context, chargePointID string, reservationID int) (*domain.Reservation, error) {
+		if chargePointID != reservation.ChargePointID || reservationID != reservation.OCPPReservationID {
+			return nil, nil
+		}
+		clone := *reservation
+		return &clone, nil
+	}
+	return repo
+}
+
+func TestApplyStationUpdate_StatusMapping(t *testing.T) {
+	tests := []struct {
+		stationStatus  string
+		expectedStatus domain.ReservationStatus
+	}{
+		{StationUpdateExpired, domain.ReservationStatusExpired},
+		{StationUpdateRemoved, domain.ReservationStatusCancelled},
+		{StationUpdateUsed, domain.ReservationStatusActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stationStatus, func(t *testing.T) {
+			// Arrange
+			reservation := heldReservation()
+			mq := mocks.NewMockMessageQueue()
+			svc := NewService(stationStore(reservation), nil, nil, nil, zap.NewNop())
+			svc.SetMessageQueue(mq)
+
+			// Act
+			updated, err := svc.ApplyStationUpdate(context.Background(), "CP001", 77, tt.stationStatus)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if updated == nil || reservation.Status != tt.expectedStatus {
+				t.Fatalf("expected status %s, got %s", tt.expectedStatus, reservation.Status)
+			}
+
+			published := mq.GetPublishedMessages("reservation.updated")
+			if len(published) != 1 {
+				t.Fatalf("expected one reservation.updated event, got %d", len(published))
+			}
+			var event map[string]interface{}
+			json.Unmarshal(published[0], &event)
+			if event["reservation_id"] != "res-1" || event["status"] != string(tt.expectedStatus) {
+				t.Errorf("expected event for res-1 with status %s, got %v", tt.expectedStatus, event)
+			}
+		})
+	}
+}
+
+func TestApplyStationUpdate_RemovedRecordsReason(t *testing.T) {
+	reservation := heldReservation()
+	svc := NewService(stationStore(reservation), nil, nil, nil, zap.NewNop())
+
+	svc.ApplyStationUpdate(context.Background(), "CP001", 77, StationUpdateRemoved)
+
+	if reservation.CancellationReason == "" {
+		t.Error("expected a cancellation reason for a reservation removed on the station")
+	}
+}
+
+func TestApplyStationUpdate_IgnoresSettledReservation(t *testing.T) {
+	// Arrange: the user already cancelled, the station echoes Removed
+	reservation := heldReservation()
+	reservation.Status = domain.ReservationStatusCancelled
+	reservation.CancellationReason = "user request"
+	mq := mocks.NewMockMessageQueue()
+	svc := NewService(stationStore(reservation), nil, nil, nil, zap.NewNop())
+	svc.SetMessageQueue(mq)
+
+	// Act
+	_, err := svc.ApplyStationUpdate(context.Background(), "CP001", 77, StationUpdateRemoved)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reservation.CancellationReason != "user request" {
+		t.Errorf("expected the reservation to be left alone, got reason %q", reservation.CancellationReason)
+	}
+	if n := len(mq.GetPublishedMessages("reservation.updated")); n != 0 {
+		t.Errorf("expected no event, got %d", n)
+	}
+}
+
+func TestApplyStationUpdate_UnknownReservationAndStatus(t *testing.T) {
+	svc := NewService(stationStore(heldReservation()), nil, nil, nil, zap.NewNop())
+
+	updated, err := svc.ApplyStationUpdate(context.Background(), "CP001", 78, StationUpdateExpired)
+	if err != nil || updated != nil {
+		t.Errorf("expected nil for an unknown reservation, got %v, %v", updated, err)
+	}
+	if _, err := svc.ApplyStationUpdate(context.Background(), "CP001", 77, "Bogus"); err == nil {
+		t.Error("expected an error for an unknown status")
+	}
+}
+
+func TestLinkTransaction_UsedReservationWithoutTransaction(t *testing.T) {
+	// Arrange: the station reported the reservation used before any transaction
+	reservation := heldReservation()
+	repo := stationStore(reservation)
+	repo.GetByUserIDFunc = func(ctx context.Context, userID string, status string, limit, offset int) ([]domain.Reservation, error) {
+		if userID != reservation.UserID || status != string(reservation.Status) {
+			return nil, nil
+		}
+		return []domain.Reservation{*reservation}, nil
+	}
+	svc := NewService(repo, nil, nil, nil, zap.NewNop())
+	svc.ApplyStationUpdate(context.Background(), "CP001", 77, StationUpdateUsed)
+
+	// Act: a transaction on another station must not claim it
+	svc.LinkTransaction(context.Background(), "CP002", "user-1", "tx-other")
+	err := svc.LinkTransaction(context.Background(), "CP001", "user-1", "tx-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reservation.TransactionID != "tx-1" {
+		t.Errorf("expected reservation linked to tx-1, got %q", reservation.TransactionID)
+	}
+}
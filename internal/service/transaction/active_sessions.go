@@ -0,0 +1,240 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// activeLoadPageSize is the page size used to load started transactions
+// from the repository the first time the active set is read
+const activeLoadPageSize = 200
+
+// activeSessions keeps the started transactions in memory with their latest
+// power draw, so live views never scan the transaction table. Sessions that
+// started before this process are loaded from the repository on first use.
+// They are also indexed by charge point and by user for direct lookups.
+type activeSessions struct {
+	mu            sync.RWMutex
+	loaded        bool
+	sessions      map[string]*domain.ActiveSession // transaction ID → session
+	byChargePoint map[string]map[string]struct{}   // charge point ID → transaction IDs
+	byUser        map[string]map[string]struct{}   // user ID → transaction IDs
+}
+
+func newActiveSessions() *activeSessions {
+	return &activeSessions{
+		sessions:      make(map[string]*domain.ActiveSession),
+		byChargePoint: make(map[string]map[string]struct{}),
+		byUser:        make(map[string]map[string]struct{}),
+	}
+}
+
+// put adds a started transaction or refreshes its copy, keeping the power
+func (a *activeSessions) put(tx domain.Transaction) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.putLocked(tx)
+}
+
+func (a *activeSessions) putLocked(tx domain.Transaction) {
+	if session, ok := a.sessions[tx.ID]; ok {
+		a.unindex(session.Transaction)
+		session.Transaction = tx
+		a.index(tx)
+		return
+	}
+	a.sessions[tx.ID] = &domain.ActiveSession{Transaction: tx}
+	a.index(tx)
+}
+
+func (a *activeSessions) remove(transactionID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if session, ok := a.sessions[transactionID]; ok {
+		a.unindex(session.Transaction)
+		delete(a.sessions, transactionID)
+	}
+}
+
+// index and unindex keep the lookup maps in step with sessions; callers
+// hold the write lock
+func (a *activeSessions) index(tx domain.Transaction) {
+	addToIndex(a.byChargePoint, tx.ChargePointID, tx.ID)
+	addToIndex(a.byUser, tx.UserID, tx.ID)
+}
+
+func (a *activeSessions) unindex(tx domain.Transaction) {
+	removeFromIndex(a.byChargePoint, tx.ChargePointID, tx.ID)
+	removeFromIndex(a.byUser, tx.UserID, tx.ID)
+}
+
+func addToIndex(index map[string]map[string]struct{}, key, transactionID string) {
+	if key == "" {
+		return
+	}
+	ids, ok := index[key]
+	if !ok {
+		ids = make(map[string]struct{})
+		index[key] = ids
+	}
+	ids[transactionID] = struct{}{}
+}
+
+func removeFromIndex(index map[string]map[string]struct{}, key, transactionID string) {
+	ids, ok := index[key]
+	if !ok {
+		return
+	}
+	delete(ids, transactionID)
+	if len(ids) == 0 {
+		delete(index, key)
+	}
+}
+
+// setPower records a power reading; it reports false for unknown sessions
+func (a *activeSessions) setPower(transactionID string, powerKW float64, sampledAt time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	session, ok := a.sessions[transactionID]
+	if !ok {
+		return false
+	}
+	// Readings may be replayed out of order; keep the newest
+	if session.PowerSampledAt != nil && sampledAt.Before(*session.PowerSampledAt) {
+		return true
+	}
+	session.PowerKW = powerKW
+	session.PowerSampledAt = &sampledAt
+	return true
+}
+
+// load fills the set with the repository's started transactions once
+func (a *activeSessions) load(ctx context.Context, repo ports.TransactionRepository) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.loaded {
+		return nil
+	}
+
+	filter := ports.TransactionFilter{Status: string(domain.TransactionStatusStarted)}
+	var after *ports.TransactionCursor
+	for {
+		page, err := repo.FindPage(ctx, filter, after, activeLoadPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to load active transactions: %w", err)
+		}
+		for _, tx := range page {
+			if _, ok := a.sessions[tx.ID]; !ok {
+				a.putLocked(tx)
+			}
+		}
+		if len(page) < activeLoadPageSize {
+			break
+		}
+		last := page[len(page)-1]
+		after = &ports.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	a.loaded = true
+	return nil
+}
+
+// list returns copies of the sessions, oldest start first
+func (a *activeSessions) list() []domain.ActiveSession {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result := make([]domain.ActiveSession, 0, len(a.sessions))
+	for _, session := range a.sessions {
+		result = append(result, *session)
+	}
+	sortByStart(result)
+	return result
+}
+
+// forChargePoint returns copies of a charge point's sessions, oldest start first
+func (a *activeSessions) forChargePoint(chargePointID string) []domain.ActiveSession {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lookup(a.byChargePoint[chargePointID])
+}
+
+// forUser returns copies of a user's sessions, oldest start first
+func (a *activeSessions) forUser(userID string) []domain.ActiveSession {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lookup(a.byUser[userID])
+}
+
+func (a *activeSessions) lookup(ids map[string]struct{}) []domain.ActiveSession {
+	result := make([]domain.ActiveSession, 0, len(ids))
+	for id := range ids {
+		result = append(result, *a.sessions[id])
+	}
+	sortByStart(result)
+	return result
+}
+
+func sortByStart(sessions []domain.ActiveSession) {
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Transaction.StartTime.Before(sessions[j].Transaction.StartTime)
+	})
+}
+
+// RecordPower stores the latest power draw of a started transaction
+func (s *Service) RecordPower(ctx context.Context, transactionID string, powerKW float64, sampledAt time.Time) error {
+	if s.active.setPower(transactionID, powerKW, sampledAt) {
+		return nil
+	}
+
+	// Not tracked yet, e.g. started before a restart
+	tx, err := s.repo.FindByID(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if tx == nil || tx.Status != domain.TransactionStatusStarted {
+		return nil
+	}
+	s.active.put(*tx)
+	s.active.setPower(transactionID, powerKW, sampledAt)
+	return nil
+}
+
+// ListActiveSessions returns every started transaction with its latest power
+func (s *Service) ListActiveSessions(ctx context.Context) ([]domain.ActiveSession, error) {
+	if err := s.active.load(ctx, s.repo); err != nil {
+		return nil, err
+	}
+	return s.active.list(), nil
+}
+
+// LoadActiveSessions fills the active session index from the repository.
+// It runs on first use anyway; calling it at startup keeps that first
+// lookup from paying for the load.
+func (s *Service) LoadActiveSessions(ctx context.Context) error {
+	return s.active.load(ctx, s.repo)
+}
+
+// ActiveSessionsByChargePoint returns the started transactions on a charge
+// point without scanning the transaction table
+func (s *Service) ActiveSessionsByChargePoint(ctx context.Context, chargePointID string) ([]domain.ActiveSession, error) {
+	if err := s.active.load(ctx, s.repo); err != nil {
+		return nil, err
+	}
+	return s.active.forChargePoint(chargePointID), nil
+}
+
+// ActiveSessionsByUser returns a user's started transactions without
+// scanning the transaction table
+func (s *Service) ActiveSessionsByUser(ctx context.Context, userID string) ([]domain.ActiveSession, error) {
+	if err := s.active.load(ctx, s.repo); err != nil {
+		return nil, err
+	}
+	return s.active.forUser(userID), nil
+}
@@ -0,0 +1,220 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+func TestActiveSessions_TracksPowerUntilStopped(t *testing.T) {
+	// Arrange: one session started before this process, one started now
+	ctx := context.Background()
+	stored := map[string]*domain.Transaction{
+		"tx-old": {ID: "tx-old", ChargePointID: "CP-A", Status: domain.TransactionStatusStarted, StartTime: time.Now().Add(-time.Hour)},
+	}
+	pageCalls := 0
+
+	repo := &mocks.MockTransactionRepository{
+		SaveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			stored[tx.ID] = tx
+			return nil
+		},
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			return stored[id], nil
+		},
+		FindPageFunc: func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+			pageCalls++
+			if filter.Status != string(domain.TransactionStatusStarted) {
+				t.Errorf("expected only started transactions to be loaded, got filter %q", filter.Status)
+			}
+			return []domain.Transaction{*stored["tx-old"]}, nil
+		},
+	}
+	devices := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable}, nil
+		},
+	}
+	service := NewService(repo, devices, nil, newTestLogger())
+
+	tx, err := service.StartTransaction(ctx, "CP-B", 1, "user-1", "tag-1")
+	if err != nil {
+		t.Fatalf("failed to start transaction: %v", err)
+	}
+
+	// Act
+	sampledAt := time.Now()
+	service.RecordPower(ctx, "tx-old", 7.4, sampledAt)
+	service.RecordPower(ctx, tx.ID, 22, sampledAt)
+	service.RecordPower(ctx, tx.ID, 50, sampledAt.Add(-time.Minute)) // Late replay
+	sessions, err := service.ListActiveSessions(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Transaction.ID != "tx-old" || sessions[0].PowerKW != 7.4 {
+		t.Errorf("expected tx-old at 7.4 kW first, got %s at %.1f kW", sessions[0].Transaction.ID, sessions[0].PowerKW)
+	}
+	if sessions[1].Transaction.ID != tx.ID || sessions[1].PowerKW != 22 {
+		t.Errorf("expected %s at 22 kW, got %s at %.1f kW", tx.ID, sessions[1].Transaction.ID, sessions[1].PowerKW)
+	}
+
+	// Stopping removes the session without another repository scan
+	repo.UpdateFunc = func(ctx context.Context, tx *domain.Transaction) error { return nil }
+	if _, err := service.StopTransaction(ctx, tx.ID); err != nil {
+		t.Fatalf("failed to stop transaction: %v", err)
+	}
+	sessions, _ = service.ListActiveSessions(ctx)
+	if len(sessions) != 1 {
+		t.Errorf("expected 1 session after stop, got %d", len(sessions))
+	}
+	if pageCalls != 1 {
+		t.Errorf("expected the repository to be read once, got %d reads", pageCalls)
+	}
+}
+
+// availableDevices serves every charge point as Available
+func availableDevices() *mocks.MockDeviceService {
+	return &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable}, nil
+		},
+	}
+}
+
+func TestActiveSessions_RehydratesIndex(t *testing.T) {
+	// Arrange: sessions stored before this process started
+	ctx := context.Background()
+	repo := memory.NewTransactionRepository()
+	stored := []domain.Transaction{
+		{ID: "tx-1", ChargePointID: "CP-A", UserID: "user-1", Status: domain.TransactionStatusStarted, StartTime: time.Now().Add(-2 * time.Hour)},
+		{ID: "tx-2", ChargePointID: "CP-A", UserID: "user-2", Status: domain.TransactionStatusStarted, StartTime: time.Now().Add(-time.Hour)},
+		{ID: "tx-3", ChargePointID: "CP-B", UserID: "user-1", Status: domain.TransactionStatusCompleted, StartTime: time.Now().Add(-3 * time.Hour)},
+	}
+	for i := range stored {
+		stored[i].CreatedAt = stored[i].StartTime
+		if err := repo.Save(ctx, &stored[i]); err != nil {
+			t.Fatalf("failed to save transaction: %v", err)
+		}
+	}
+	service := NewService(repo, availableDevices(), nil, zap.NewNop()).(*Service)
+
+	// Act
+	err := service.LoadActiveSessions(ctx)
+	onA, _ := service.ActiveSessionsByChargePoint(ctx, "CP-A")
+	onB, _ := service.ActiveSessionsByChargePoint(ctx, "CP-B")
+	ofUser, _ := service.ActiveSessionsByUser(ctx, "user-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(onA) != 2 || onA[0].Transaction.ID != "tx-1" || onA[1].Transaction.ID != "tx-2" {
+		t.Errorf("expected tx-1 and tx-2 on CP-A, got %v", sessionIDs(onA))
+	}
+	if len(onB) != 0 {
+		t.Errorf("expected no active session on CP-B, got %v", sessionIDs(onB))
+	}
+	if len(ofUser) != 1 || ofUser[0].Transaction.ID != "tx-1" {
+		t.Errorf("expected tx-1 for user-1, got %v", sessionIDs(ofUser))
+	}
+}
+
+// Run with -race: starts, stops and lookups share the index
+func TestActiveSessions_ConcurrentStartStopLookup(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service := NewService(memory.NewTransactionRepository(), availableDevices(), nil, zap.NewNop()).(*Service)
+	if err := service.LoadActiveSessions(ctx); err != nil {
+		t.Fatalf("failed to load active sessions: %v", err)
+	}
+
+	const (
+		users        = 16
+		chargePoints = 4
+	)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	done := make(chan struct{})
+	errs := make(chan error, users)
+
+	// Act: every user starts and stops a session while readers look up
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			userID := fmt.Sprintf("user-%d", i)
+			chargePointID := fmt.Sprintf("CP-%d", i%chargePoints)
+			tx, err := service.StartTransaction(ctx, chargePointID, i/chargePoints+1, userID, "rfid")
+			if err != nil {
+				errs <- err
+				return
+			}
+			own, _ := service.ActiveSessionsByUser(ctx, userID)
+			if len(own) != 1 || own[0].Transaction.ID != tx.ID {
+				errs <- fmt.Errorf("expected %s for %s, got %v", tx.ID, userID, sessionIDs(own))
+			}
+			if _, err := service.StopTransaction(ctx, tx.ID); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	var readers sync.WaitGroup
+	for r := 0; r < chargePoints; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			<-start
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				service.ActiveSessionsByChargePoint(ctx, fmt.Sprintf("CP-%d", r))
+				service.ListActiveSessions(ctx)
+			}
+		}(r)
+	}
+	close(start)
+	wg.Wait()
+	close(done)
+	readers.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		t.Error(err)
+	}
+	for r := 0; r < chargePoints; r++ {
+		if left, _ := service.ActiveSessionsByChargePoint(ctx, fmt.Sprintf("CP-%d", r)); len(left) != 0 {
+			t.Errorf("expected CP-%d to have no sessions left, got %v", r, sessionIDs(left))
+		}
+	}
+	if len(service.active.byChargePoint) != 0 || len(service.active.byUser) != 0 {
+		t.Errorf("expected empty indexes, got %d charge points and %d users",
+			len(service.active.byChargePoint), len(service.active.byUser))
+	}
+}
+
+func sessionIDs(sessions []domain.ActiveSession) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.Transaction.ID)
+	}
+	return ids
+}
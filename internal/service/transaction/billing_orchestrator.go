@@ -0,0 +1,397 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// BillingSubject is the message queue subject stopped sessions are published
+// on for billing
+const BillingSubject = "billing.events"
+
+// BillingQueueGroup shares BillingSubject between server replicas, so each
+// stopped session is delivered to one of them
+const BillingQueueGroup = "billing-settlement"
+
+// AlertTypePaymentFailed marks alerts raised when a session could not be
+// charged
+const AlertTypePaymentFailed = "payment_failed"
+
+// BillingOrchestratorConfig holds settings for charging stopped sessions
+type BillingOrchestratorConfig struct {
+	MaxAttempts   int           // Payment attempts when payments are unavailable
+	RetryDelay    time.Duration // Wait before the second attempt, doubled after each one
+	SweepInterval time.Duration // How often sessions left Failed are charged again
+}
+
+// DefaultBillingOrchestratorConfig tries a payment 3 times, 2 then 4 seconds
+// apart, and charges sessions left Failed again every 15 minutes
+func DefaultBillingOrchestratorConfig() BillingOrchestratorConfig {
+	return BillingOrchestratorConfig{
+		MaxAttempts:   3,
+		RetryDelay:    2 * time.Second,
+		SweepInterval: 15 * time.Minute,
+	}
+}
+
+// BillingOrchestrator charges a session once it stops: it works out the
+// final cost, takes the payment from the wallet or else the card, records
+// whether the session was paid and emails the receipt. Sessions that could
+// not be charged raise an alert.
+type BillingOrchestrator struct {
+	txRepo   ports.TransactionRepository
+	billing  *BillingService
+	payments ports.PaymentService
+	users    ports.UserRepository
+	email    ports.EmailService    // optional, see SetEmailService
+	alerts   ports.AlertRepository // optional, see SetAlertRepository
+	config   BillingOrchestratorConfig
+	log      *zap.Logger
+}
+
+// NewBillingOrchestrator creates a billing orchestrator
+func NewBillingOrchestrator(
+	txRepo ports.TransactionRepository,
+	billing *BillingService,
+	payments ports.PaymentService,
+	users ports.UserRepository,
+	config BillingOrchestratorConfig,
+	log *zap.Logger,
+) *BillingOrchestrator {
+	defaults := DefaultBillingOrchestratorConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.RetryDelay < 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	if config.SweepInterval <= 0 {
+		config.SweepInterval = defaults.SweepInterval
+	}
+
+	return &BillingOrchestrator{
+		txRepo:   txRepo,
+		billing:  billing,
+		payments: payments,
+		users:    users,
+		config:   config,
+		log:      log,
+	}
+}
+
+// SetEmailService enables emailing receipts for paid sessions
+func (o *BillingOrchestrator) SetEmailService(email ports.EmailService) {
+	o.email = email
+}
+
+// SetAlertRepository enables alerts for sessions that could not be charged
+func (o *BillingOrchestrator) SetAlertRepository(alerts ports.AlertRepository) {
+	o.alerts = alerts
+}
+
+// Start settles every session published on BillingSubject. Replicas share
+// the subscription through BillingQueueGroup.
+func (o *BillingOrchestrator) Start(mq queue.MessageQueue) error {
+	if err := mq.QueueSubscribe(BillingSubject, BillingQueueGroup, o.handle); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", BillingSubject, err)
+	}
+	return nil
+}
+
+// StartRetrySweep charges again, every SweepInterval, the sessions left
+// Failed because payments were unavailable
+func (o *BillingOrchestrator) StartRetrySweep(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(o.config.SweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := o.RetryFailed(ctx); err != nil {
+					o.log.Error("Settlement retry sweep failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// RetryFailed settles the sessions that have been Failed for at least a
+// SweepInterval and returns how many were paid. Sessions left Settling by a
+// crash are not retried, since their charge may have gone through.
+func (o *BillingOrchestrator) RetryFailed(ctx context.Context) (int, error) {
+	failed, err := o.txRepo.FindByPaymentStatus(ctx, domain.TransactionPaymentFailed, time.Now().Add(-o.config.SweepInterval))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unsettled sessions: %w", err)
+	}
+
+	paid := 0
+	for _, tx := range failed {
+		settled, err := o.Settle(ctx, tx.ID)
+		if err != nil {
+			o.log.Warn("Settlement retry failed", zap.String("tx_id", tx.ID), zap.Error(err))
+			continue
+		}
+		if settled.PaymentStatus == domain.TransactionPaymentPaid {
+			paid++
+		}
+	}
+	return paid, nil
+}
+
+// handle decodes a stopped session event. Malformed events are dropped
+// rather than returned as errors, so they are not redelivered.
+func (o *BillingOrchestrator) handle(data []byte) error {
+	var event struct {
+		TransactionID string `json:"transaction_id"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil || event.TransactionID == "" {
+		o.log.Warn("Dropping malformed billing event", zap.ByteString("msg", data))
+		return nil
+	}
+
+	_, err := o.Settle(context.Background(), event.TransactionID)
+	return err
+}
+
+// Settle charges a stopped session and records the outcome in its payment
+// status. A session that started with a hold on the user's card is captured
+// for its cost, the rest of the hold released. The session is claimed by
+// moving it to Settling first, so of concurrent deliveries only one charges
+// it; a session already Paid, Unpaid or Settling is returned as is, and a
+// Failed one is charged again. Declined and unavailable payments are not
+// errors, they are reported in the returned transaction.
+func (o *BillingOrchestrator) Settle(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+	tx, err := o.txRepo.FindByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, domain.ErrTransactionNotFound
+	}
+	switch tx.PaymentStatus {
+	case domain.TransactionPaymentPaid, domain.TransactionPaymentUnpaid, domain.TransactionPaymentSettling:
+		return tx, nil
+	}
+
+	claimedFrom := tx.PaymentStatus
+	claimed, err := o.txRepo.CompareAndSetPaymentStatus(ctx, tx.ID, claimedFrom, domain.TransactionPaymentSettling)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim transaction: %w", err)
+	}
+	if !claimed {
+		// Another delivery got there first
+		return o.txRepo.FindByID(ctx, tx.ID)
+	}
+	tx.PaymentStatus = domain.TransactionPaymentSettling
+
+	if tx.Status != domain.TransactionStatusCompleted {
+		if _, err := o.billing.complete(ctx, tx); err != nil {
+			o.release(ctx, tx.ID, claimedFrom)
+			return nil, fmt.Errorf("failed to bill transaction: %w", err)
+		}
+	}
+
+	var payment *domain.Payment
+	switch {
+	case tx.PaymentID != "":
+		// A hold was placed when the session started
+		payment, err = o.captureHold(ctx, tx)
+	case tx.Cost > 0:
+		payment, err = o.charge(ctx, tx)
+	}
+	switch {
+	case err == nil:
+		tx.PaymentStatus = domain.TransactionPaymentPaid
+		tx.PaymentID = ""
+		if payment != nil {
+			tx.PaymentID = payment.ID
+		}
+	case isTransientPaymentError(err):
+		tx.PaymentStatus = domain.TransactionPaymentFailed
+	default:
+		tx.PaymentStatus = domain.TransactionPaymentUnpaid
+	}
+	tx.UpdatedAt = time.Now()
+	if updateErr := o.txRepo.Update(ctx, tx); updateErr != nil {
+		return nil, fmt.Errorf("failed to update payment status: %w", updateErr)
+	}
+
+	if err != nil {
+		o.log.Warn("Session could not be charged",
+			zap.String("tx_id", tx.ID),
+			zap.String("user_id", tx.UserID),
+			zap.Float64("amount", tx.Cost),
+			zap.String("payment_status", string(tx.PaymentStatus)),
+			zap.Error(err),
+		)
+		o.raiseAlert(ctx, tx, err)
+		return tx, nil
+	}
+
+	o.log.Info("Session charged",
+		zap.String("tx_id", tx.ID),
+		zap.String("user_id", tx.UserID),
+		zap.Float64("amount", tx.Cost),
+		zap.String("payment_id", tx.PaymentID),
+	)
+	if payment != nil {
+		o.sendReceipt(ctx, tx)
+	}
+	return tx, nil
+}
+
+// release hands back the claim on a session nothing was charged for, so the
+// next delivery settles it
+func (o *BillingOrchestrator) release(ctx context.Context, transactionID string, status domain.TransactionPaymentStatus) {
+	if _, err := o.txRepo.CompareAndSetPaymentStatus(ctx, transactionID, domain.TransactionPaymentSettling, status); err != nil {
+		o.log.Error("Failed to release settlement claim", zap.String("tx_id", transactionID), zap.Error(err))
+	}
+}
+
+// charge takes the session's cost from the user's wallet or card. Every
+// attempt, including those of later sweeps, sends the same idempotency key,
+// so a charge whose response timed out is not taken twice.
+func (o *BillingOrchestrator) charge(ctx context.Context, tx *domain.Transaction) (*domain.Payment, error) {
+	ctx = ports.WithIdempotencyKey(ctx, "charge-"+tx.ID)
+	return o.retry(ctx, tx, func() (*domain.Payment, error) {
+		return o.payments.ProcessChargingPayment(ctx, tx.UserID, tx.ID, tx.Cost)
+	})
+}
+
+// captureHold captures the session's cost from the hold placed when it
+// started, releasing the rest. A session that cost nothing voids the hold
+// and returns no payment; one that cost more than the hold is charged the
+// excess separately.
+func (o *BillingOrchestrator) captureHold(ctx context.Context, tx *domain.Transaction) (*domain.Payment, error) {
+	hold, err := o.payments.GetPayment(ctx, tx.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if hold == nil {
+		return nil, fmt.Errorf("payment hold %s not found", tx.PaymentID)
+	}
+
+	captured := math.Min(tx.Cost, hold.AuthorizedAmount)
+	captureCtx := ports.WithIdempotencyKey(ctx, "capture-"+tx.ID)
+	payment, err := o.retry(ctx, tx, func() (*domain.Payment, error) {
+		return o.payments.Capture(captureCtx, tx.PaymentID, captured)
+	})
+	if err != nil || captured <= 0 {
+		return nil, err
+	}
+
+	excess := math.Round((tx.Cost-captured)*100) / 100
+	if excess <= 0 {
+		return payment, nil
+	}
+	o.log.Warn("Session cost more than its hold, charging the excess",
+		zap.String("tx_id", tx.ID),
+		zap.Float64("hold", hold.AuthorizedAmount),
+		zap.Float64("excess", excess),
+	)
+	excessCtx := ports.WithIdempotencyKey(ctx, "charge-excess-"+tx.ID)
+	if _, err := o.retry(ctx, tx, func() (*domain.Payment, error) {
+		return o.payments.ProcessChargingPayment(excessCtx, tx.UserID, tx.ID, excess)
+	}); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// retry calls pay, trying again while payments are unavailable
+func (o *BillingOrchestrator) retry(ctx context.Context, tx *domain.Transaction, pay func() (*domain.Payment, error)) (*domain.Payment, error) {
+	delay := o.config.RetryDelay
+	for attempt := 1; ; attempt++ {
+		payment, err := pay()
+		if err == nil || !isTransientPaymentError(err) || attempt >= o.config.MaxAttempts {
+			return payment, err
+		}
+
+		o.log.Warn("Payment unavailable, retrying",
+			zap.String("tx_id", tx.ID),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isTransientPaymentError reports whether a payment may succeed when
+// retried: network failures and timeouts are, declines are not. Retries are
+// safe because payments carry an idempotency key, see charge.
+func isTransientPaymentError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// sendReceipt emails the invoice of a paid session
+func (o *BillingOrchestrator) sendReceipt(ctx context.Context, tx *domain.Transaction) {
+	if o.email == nil {
+		return
+	}
+
+	user, err := o.users.FindByID(ctx, tx.UserID)
+	if err != nil || user == nil {
+		o.log.Warn("Receipt not sent, user not found", zap.String("tx_id", tx.ID), zap.Error(err))
+		return
+	}
+	invoice, err := o.billing.GenerateInvoice(ctx, tx)
+	if err != nil {
+		o.log.Warn("Receipt not sent, invoice failed", zap.String("tx_id", tx.ID), zap.Error(err))
+		return
+	}
+	if err := o.email.SendInvoice(ctx, user, invoice.Document()); err != nil {
+		o.log.Warn("Failed to send receipt", zap.String("tx_id", tx.ID), zap.Error(err))
+	}
+}
+
+// raiseAlert records a session that could not be charged
+func (o *BillingOrchestrator) raiseAlert(ctx context.Context, tx *domain.Transaction, cause error) {
+	if o.alerts == nil {
+		return
+	}
+
+	severity := "warning"
+	title := fmt.Sprintf("Payment unavailable for session %s", tx.ID)
+	if tx.PaymentStatus == domain.TransactionPaymentUnpaid {
+		severity = "high"
+		title = fmt.Sprintf("Unpaid session %s", tx.ID)
+	}
+	alert := &ports.Alert{
+		ID:       uuid.New().String(),
+		Type:     AlertTypePaymentFailed,
+		Severity: severity,
+		Title:    title,
+		Message: fmt.Sprintf("User %s could not be charged %.2f %s: %v",
+			tx.UserID, tx.Cost, tx.Currency, cause),
+		Source:    "transaction",
+		SourceID:  tx.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := o.alerts.Save(ctx, alert); err != nil {
+		o.log.Error("Failed to save payment alert", zap.String("tx_id", tx.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,336 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// chargingPayments takes payments from the wallet while it holds enough,
+// then from the card, which fails with cardErrs in turn
+type chargingPayments struct {
+	ports.PaymentService
+	walletBalance float64
+	cardErrs      []error
+	calls         int
+	keys          []string // Idempotency keys of the charges
+	holds         map[string]*domain.Payment
+	captured      []float64
+	voided        []string
+}
+
+func (p *chargingPayments) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	if hold, ok := p.holds[paymentID]; ok {
+		return hold, nil
+	}
+	return nil, errors.New("payment not found")
+}
+
+func (p *chargingPayments) Capture(ctx context.Context, paymentID string, amount float64) (*domain.Payment, error) {
+	if amount <= 0 {
+		return p.Void(ctx, paymentID)
+	}
+	p.captured = append(p.captured, amount)
+	return &domain.Payment{ID: paymentID, Method: domain.PaymentMethodCreditCard, Status: domain.PaymentStatusCompleted, Amount: amount}, nil
+}
+
+func (p *chargingPayments) Void(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	p.voided = append(p.voided, paymentID)
+	return &domain.Payment{ID: paymentID, Status: domain.PaymentStatusCancelled}, nil
+}
+
+func (p *chargingPayments) ProcessChargingPayment(ctx context.Context, userID, transactionID string, amount float64) (*domain.Payment, error) {
+	p.calls++
+	p.keys = append(p.keys, ports.IdempotencyKeyFrom(ctx))
+	if p.walletBalance >= amount {
+		p.walletBalance -= amount
+		return &domain.Payment{ID: "pay-wallet", Method: domain.PaymentMethodWallet, Status: domain.PaymentStatusCompleted, Amount: amount}, nil
+	}
+	if len(p.cardErrs) > 0 {
+		err := p.cardErrs[0]
+		p.cardErrs = p.cardErrs[1:]
+		if err != nil {
+			return &domain.Payment{ID: "pay-failed", Status: domain.PaymentStatusFailed}, fmt.Errorf("payment processing failed: %w", err)
+		}
+	}
+	return &domain.Payment{ID: "pay-card", Method: domain.PaymentMethodCreditCard, Status: domain.PaymentStatusCompleted, Amount: amount}, nil
+}
+
+// receiptRecorder records the invoices it was asked to email
+type receiptRecorder struct {
+	ports.EmailService
+	sent []*ports.Invoice
+}
+
+func (r *receiptRecorder) SendInvoice(ctx context.Context, user *domain.User, invoice *ports.Invoice) error {
+	r.sent = append(r.sent, invoice)
+	return nil
+}
+
+func TestBillingOrchestrator_Settle(t *testing.T) {
+	unreachable := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	declined := errors.New("card declined")
+
+	// 10 kWh off-peak at 0.75 per kWh
+	tests := []struct {
+		name        string
+		wallet      float64
+		cardErrs    []error
+		wantStatus  domain.TransactionPaymentStatus
+		wantPayment string
+		wantCalls   int
+		wantReceipt bool
+		wantAlert   bool
+	}{
+		{"wallet pays", 50, nil, domain.TransactionPaymentPaid, "pay-wallet", 1, true, false},
+		{"card after short wallet", 5, nil, domain.TransactionPaymentPaid, "pay-card", 1, true, false},
+		{"card retried while unreachable", 0, []error{unreachable, nil}, domain.TransactionPaymentPaid, "pay-card", 2, true, false},
+		{"card declined", 0, []error{declined}, domain.TransactionPaymentUnpaid, "", 1, false, true},
+		{"payments unreachable", 0, []error{unreachable, unreachable, unreachable}, domain.TransactionPaymentFailed, "", 3, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			txRepo := memory.NewTransactionRepository()
+			users := memory.NewUserRepository()
+			alerts := memory.NewAlertRepository()
+			users.Save(ctx, &domain.User{ID: "user-1", Email: "driver@example.com"})
+			start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+			end := start.Add(90 * time.Minute)
+			txRepo.Save(ctx, &domain.Transaction{
+				ID: "tx-1", ChargePointID: "CP-001", UserID: "user-1", Status: domain.TransactionStatusStopped,
+				StartTime: start, EndTime: &end, MeterStart: 1000, MeterStop: 11000, TotalEnergy: 10000,
+			})
+			payments := &chargingPayments{walletBalance: tt.wallet, cardErrs: tt.cardErrs}
+			receipts := &receiptRecorder{}
+			billing := NewBillingService(txRepo, nil, nil, zap.NewNop())
+			billing.SetTaxConfig(nil)
+			orchestrator := NewBillingOrchestrator(txRepo, billing, payments, users,
+				BillingOrchestratorConfig{MaxAttempts: 3, RetryDelay: time.Millisecond}, zap.NewNop())
+			orchestrator.SetEmailService(receipts)
+			orchestrator.SetAlertRepository(alerts)
+
+			// Act
+			tx, err := orchestrator.Settle(ctx, "tx-1")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tx.Status != domain.TransactionStatusCompleted || tx.Cost != 7.5 {
+				t.Errorf("expected a Completed session costing 7.50, got %s costing %.2f", tx.Status, tx.Cost)
+			}
+			stored, _ := txRepo.FindByID(ctx, "tx-1")
+			if stored.PaymentStatus != tt.wantStatus || stored.PaymentID != tt.wantPayment {
+				t.Errorf("expected %s with payment %q, got %s with %q", tt.wantStatus, tt.wantPayment, stored.PaymentStatus, stored.PaymentID)
+			}
+			if payments.calls != tt.wantCalls {
+				t.Errorf("expected %d payment attempts, got %d", tt.wantCalls, payments.calls)
+			}
+			if got := len(receipts.sent) == 1; got != tt.wantReceipt {
+				t.Errorf("expected receipt %v, got %d sent", tt.wantReceipt, len(receipts.sent))
+			}
+			open, _ := alerts.FindOpen(ctx, AlertTypePaymentFailed, "tx-1")
+			if got := len(open) == 1; got != tt.wantAlert {
+				t.Errorf("expected alert %v, got %d", tt.wantAlert, len(open))
+			}
+		})
+	}
+}
+
+func TestBillingOrchestrator_SettleCapturesHold(t *testing.T) {
+	// 10 kWh off-peak at 0.75 per kWh is 7.50
+	tests := []struct {
+		name         string
+		energy       int
+		duration     time.Duration
+		hold         float64
+		wantCaptured []float64
+		wantVoided   bool
+		wantCharged  int
+		wantPayment  string
+	}{
+		{"captures less than the hold", 10000, 90 * time.Minute, 90, []float64{7.5}, false, 0, "hold-1"},
+		{"voids the hold of a session that cost nothing", 0, 0, 90, nil, true, 0, ""},
+		{"charges what the hold does not cover", 10000, 90 * time.Minute, 5, []float64{5}, false, 1, "hold-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			txRepo := memory.NewTransactionRepository()
+			start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+			end := start.Add(tt.duration)
+			txRepo.Save(ctx, &domain.Transaction{
+				ID: "tx-1", UserID: "user-1", Status: domain.TransactionStatusStopped,
+				StartTime: start, EndTime: &end, TotalEnergy: tt.energy,
+				PaymentStatus: domain.TransactionPaymentAuthorized, PaymentID: "hold-1",
+			})
+			payments := &chargingPayments{holds: map[string]*domain.Payment{
+				"hold-1": {ID: "hold-1", Status: domain.PaymentStatusAuthorized, AuthorizedAmount: tt.hold},
+			}}
+			billing := NewBillingService(txRepo, nil, nil, zap.NewNop())
+			billing.SetTaxConfig(nil)
+			orchestrator := NewBillingOrchestrator(txRepo, billing, payments, memory.NewUserRepository(),
+				BillingOrchestratorConfig{MaxAttempts: 3, RetryDelay: time.Millisecond}, zap.NewNop())
+
+			// Act
+			tx, err := orchestrator.Settle(ctx, "tx-1")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tx.PaymentStatus != domain.TransactionPaymentPaid || tx.PaymentID != tt.wantPayment {
+				t.Errorf("expected Paid with payment %q, got %s with %q", tt.wantPayment, tx.PaymentStatus, tx.PaymentID)
+			}
+			if len(payments.captured) != len(tt.wantCaptured) ||
+				(len(tt.wantCaptured) > 0 && payments.captured[0] != tt.wantCaptured[0]) {
+				t.Errorf("expected %v captured, got %v", tt.wantCaptured, payments.captured)
+			}
+			if got := len(payments.voided) == 1; got != tt.wantVoided {
+				t.Errorf("expected voided %v, got %v", tt.wantVoided, payments.voided)
+			}
+			if payments.calls != tt.wantCharged {
+				t.Errorf("expected %d separate charges, got %d", tt.wantCharged, payments.calls)
+			}
+		})
+	}
+}
+
+func TestBillingOrchestrator_RedeliveredEventChargesOnce(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	txRepo := memory.NewTransactionRepository()
+	end := time.Now()
+	txRepo.Save(ctx, &domain.Transaction{
+		ID: "tx-1", UserID: "user-1", Status: domain.TransactionStatusStopped,
+		StartTime: end.Add(-time.Hour), EndTime: &end, TotalEnergy: 10000,
+	})
+	payments := &chargingPayments{walletBalance: 100}
+	orchestrator := NewBillingOrchestrator(txRepo, NewBillingService(txRepo, nil, nil, zap.NewNop()), payments,
+		memory.NewUserRepository(), BillingOrchestratorConfig{}, zap.NewNop())
+	event := []byte(`{"transaction_id":"tx-1","total_energy":10000}`)
+
+	// Act
+	first := orchestrator.handle(event)
+	second := orchestrator.handle(event)
+
+	// Assert
+	if first != nil || second != nil {
+		t.Fatalf("expected both deliveries to succeed, got %v and %v", first, second)
+	}
+	if payments.calls != 1 {
+		t.Errorf("expected one payment, got %d", payments.calls)
+	}
+}
+
+func TestBillingOrchestrator_ClaimedSessionNotChargedAgain(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	txRepo := memory.NewTransactionRepository()
+	end := time.Now()
+	txRepo.Save(ctx, &domain.Transaction{
+		ID: "tx-1", UserID: "user-1", Status: domain.TransactionStatusCompleted, Cost: 10,
+		StartTime: end.Add(-time.Hour), EndTime: &end, PaymentStatus: domain.TransactionPaymentSettling,
+	})
+	payments := &chargingPayments{walletBalance: 100}
+	orchestrator := NewBillingOrchestrator(txRepo, NewBillingService(txRepo, nil, nil, zap.NewNop()), payments,
+		memory.NewUserRepository(), BillingOrchestratorConfig{}, zap.NewNop())
+
+	// Act
+	tx, err := orchestrator.Settle(ctx, "tx-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if payments.calls != 0 {
+		t.Errorf("expected a session claimed elsewhere not to be charged, got %d payments", payments.calls)
+	}
+	if tx.PaymentStatus != domain.TransactionPaymentSettling {
+		t.Errorf("expected payment status Settling, got %s", tx.PaymentStatus)
+	}
+}
+
+func TestBillingOrchestrator_RetriedChargeKeepsIdempotencyKey(t *testing.T) {
+	// Arrange - the card times out until the sweep charges the session again
+	ctx := context.Background()
+	txRepo := memory.NewTransactionRepository()
+	end := time.Now().Add(-time.Hour)
+	txRepo.Save(ctx, &domain.Transaction{
+		ID: "tx-1", UserID: "user-1", Status: domain.TransactionStatusCompleted, Cost: 10,
+		StartTime: end.Add(-time.Hour), EndTime: &end,
+	})
+	payments := &chargingPayments{cardErrs: []error{context.DeadlineExceeded, context.DeadlineExceeded}}
+	orchestrator := NewBillingOrchestrator(txRepo, NewBillingService(txRepo, nil, nil, zap.NewNop()), payments,
+		memory.NewUserRepository(), BillingOrchestratorConfig{MaxAttempts: 2, SweepInterval: time.Millisecond}, zap.NewNop())
+
+	// Act
+	first, _ := orchestrator.Settle(ctx, "tx-1")
+	time.Sleep(5 * time.Millisecond)
+	orchestrator.RetryFailed(ctx)
+
+	// Assert
+	if first.PaymentStatus != domain.TransactionPaymentFailed {
+		t.Fatalf("expected the timed out charge Failed, got %s", first.PaymentStatus)
+	}
+	if len(payments.keys) != 3 {
+		t.Fatalf("expected 3 charge attempts, got %d", len(payments.keys))
+	}
+	for i, key := range payments.keys {
+		if key != "charge-tx-1" {
+			t.Errorf("expected attempt %d keyed by the session, got %q", i+1, key)
+		}
+	}
+}
+
+func TestBillingOrchestrator_RetryFailed(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	txRepo := memory.NewTransactionRepository()
+	end := time.Now()
+	failed := func(id string) {
+		txRepo.Save(ctx, &domain.Transaction{
+			ID: id, UserID: "user-1", Status: domain.TransactionStatusCompleted, Cost: 10,
+			StartTime: end.Add(-time.Hour), EndTime: &end, PaymentStatus: domain.TransactionPaymentFailed,
+		})
+	}
+	failed("tx-stale")
+	time.Sleep(20 * time.Millisecond)
+	failed("tx-recent")
+	payments := &chargingPayments{walletBalance: 100}
+	orchestrator := NewBillingOrchestrator(txRepo, NewBillingService(txRepo, nil, nil, zap.NewNop()), payments,
+		memory.NewUserRepository(), BillingOrchestratorConfig{SweepInterval: 10 * time.Millisecond}, zap.NewNop())
+
+	// Act
+	paid, err := orchestrator.RetryFailed(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if paid != 1 {
+		t.Errorf("expected 1 session paid, got %d", paid)
+	}
+	stale, _ := txRepo.FindByID(ctx, "tx-stale")
+	if stale.PaymentStatus != domain.TransactionPaymentPaid {
+		t.Errorf("expected the stale session Paid, got %s", stale.PaymentStatus)
+	}
+	recent, _ := txRepo.FindByID(ctx, "tx-recent")
+	if recent.PaymentStatus != domain.TransactionPaymentFailed {
+		t.Errorf("expected the recent failure left for the next sweep, got %s", recent.PaymentStatus)
+	}
+}
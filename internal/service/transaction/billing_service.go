@@ -38,10 +38,12 @@ func DefaultPricingConfig() *PricingConfig {
 
 // BillingService handles billing and payment calculations
 type BillingService struct {
-	txRepo  ports.TransactionRepository
-	mq      queue.MessageQueue
-	pricing *PricingConfig
-	log     *zap.Logger
+	txRepo   ports.TransactionRepository
+	mq       queue.MessageQueue
+	pricing  *PricingConfig
+	taxes    *TaxConfig
+	stations ports.ChargePointRepository
+	log      *zap.Logger
 }
 
 // NewBillingService creates a new billing service
@@ -54,14 +56,31 @@ func NewBillingService(
 	if pricing == nil {
 		pricing = DefaultPricingConfig()
 	}
+	var taxes *TaxConfig
+	if pricing.Currency == "BRL" {
+		taxes = DefaultTaxConfig()
+	}
 	return &BillingService{
 		txRepo:  txRepo,
 		mq:      mq,
 		pricing: pricing,
+		taxes:   taxes,
 		log:     log,
 	}
 }
 
+// SetTaxConfig replaces the tax rates. A nil config disables the tax
+// breakdown and bills the whole amount as net.
+func (s *BillingService) SetTaxConfig(taxes *TaxConfig) {
+	s.taxes = taxes
+}
+
+// SetStationRepository sets the repository used to find the state of the
+// station where a session took place. Without it the fallback ICMS rate applies.
+func (s *BillingService) SetStationRepository(stations ports.ChargePointRepository) {
+	s.stations = stations
+}
+
 // CalculateCost calculates the total cost of a transaction
 func (s *BillingService) CalculateCost(ctx context.Context, tx *domain.Transaction) (float64, error) {
 	if tx == nil {
@@ -70,7 +89,7 @@ func (s *BillingService) CalculateCost(ctx context.Context, tx *domain.Transacti
 
 	// Calculate energy cost
 	energyKWh := float64(tx.TotalEnergy) / 1000.0 // Convert Wh to kWh
-	rate := s.getRate(tx.StartTime)
+	rate := s.getRate(ctx, tx)
 	energyCost := energyKWh * rate
 
 	// Calculate idle fee if applicable
@@ -90,13 +109,66 @@ func (s *BillingService) CalculateCost(ctx context.Context, tx *domain.Transacti
 	return totalCost, nil
 }
 
-// getRate returns the rate based on time of day
-func (s *BillingService) getRate(startTime time.Time) float64 {
+// CalculateCostBreakdown calculates the cost of a transaction and splits it
+// into the net amount and the taxes of the station's state
+func (s *BillingService) CalculateCostBreakdown(ctx context.Context, tx *domain.Transaction) (*domain.TaxBreakdown, error) {
+	cost, err := s.CalculateCost(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.taxes == nil {
+		gross := roundCents(cost)
+		return &domain.TaxBreakdown{Net: gross, Gross: gross}, nil
+	}
+
+	breakdown := s.taxes.Breakdown(cost, s.stationState(ctx, tx.ChargePointID))
+	return &breakdown, nil
+}
+
+// station loads the station a session ran on, or nil if it is unknown or
+// no station repository was set
+func (s *BillingService) station(ctx context.Context, chargePointID string) *domain.ChargePoint {
+	if s.stations == nil {
+		return nil
+	}
+	cp, err := s.stations.FindByID(ctx, chargePointID)
+	if err != nil {
+		s.log.Warn("Failed to load station for billing",
+			zap.String("charge_point_id", chargePointID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return cp
+}
+
+// stationState returns the UF of the station's location, or "" if unknown
+func (s *BillingService) stationState(ctx context.Context, chargePointID string) string {
+	cp := s.station(ctx, chargePointID)
+	if cp == nil || cp.Location == nil {
+		return ""
+	}
+	return cp.Location.State
+}
+
+// getRate returns the rate for the hour the session started at, in the
+// station's local time since peak hours follow the local grid
+func (s *BillingService) getRate(ctx context.Context, tx *domain.Transaction) float64 {
+	startTime := tx.StartTime
+	if cp := s.station(ctx, tx.ChargePointID); cp != nil {
+		startTime = startTime.In(cp.TimeZone())
+	}
+	return rateAt(s.pricing, startTime)
+}
+
+// rateAt returns the tariff for a session started at startTime
+func rateAt(pricing *PricingConfig, startTime time.Time) float64 {
 	hour := startTime.Hour()
-	if hour >= s.pricing.PeakHoursStart && hour < s.pricing.PeakHoursEnd {
-		return s.pricing.BaseRatePerKWh * s.pricing.PeakRateMultiplier
+	if hour >= pricing.PeakHoursStart && hour < pricing.PeakHoursEnd {
+		return pricing.BaseRatePerKWh * pricing.PeakRateMultiplier
 	}
-	return s.pricing.BaseRatePerKWh
+	return pricing.BaseRatePerKWh
 }
 
 // calculateIdleFee calculates the idle fee if the vehicle stayed connected after charging
@@ -124,25 +196,11 @@ func (s *BillingService) ProcessPayment(ctx context.Context, tx *domain.Transact
 		return errors.New("transaction cannot be nil")
 	}
 
-	if tx.Status != domain.TransactionStatusStopped && tx.Status != domain.TransactionStatusCompleted {
-		return errors.New("can only process payment for stopped or completed transactions")
-	}
-
-	// Calculate final cost
-	cost, err := s.CalculateCost(ctx, tx)
+	breakdown, err := s.complete(ctx, tx)
 	if err != nil {
-		return fmt.Errorf("failed to calculate cost: %w", err)
-	}
-
-	// Update transaction with cost
-	tx.Cost = cost
-	tx.Currency = s.pricing.Currency
-	tx.Status = domain.TransactionStatusCompleted
-	tx.UpdatedAt = time.Now()
-
-	if err := s.txRepo.Update(ctx, tx); err != nil {
-		return fmt.Errorf("failed to update transaction: %w", err)
+		return err
 	}
+	cost := breakdown.Gross
 
 	// Publish payment event for external processing (e.g., Stripe)
 	if s.mq != nil {
@@ -151,6 +209,8 @@ func (s *BillingService) ProcessPayment(ctx context.Context, tx *domain.Transact
 			"transaction_id": tx.ID,
 			"user_id":        tx.UserID,
 			"amount":         cost,
+			"net_amount":     breakdown.Net,
+			"tax_amount":     roundCents(breakdown.TotalTax()),
 			"currency":       s.pricing.Currency,
 			"energy_kwh":     float64(tx.TotalEnergy) / 1000.0,
 			"timestamp":      time.Now().UTC().Format(time.RFC3339),
@@ -172,9 +232,34 @@ func (s *BillingService) ProcessPayment(ctx context.Context, tx *domain.Transact
 	return nil
 }
 
+// complete stores the final cost of tx and moves it to Completed
+func (s *BillingService) complete(ctx context.Context, tx *domain.Transaction) (*domain.TaxBreakdown, error) {
+	if !tx.Status.CanTransitionTo(domain.TransactionStatusCompleted) {
+		return nil, &domain.TransitionError{From: tx.Status, To: domain.TransactionStatusCompleted}
+	}
+
+	// Calculate final cost
+	breakdown, err := s.CalculateCostBreakdown(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate cost: %w", err)
+	}
+
+	// Update transaction with cost
+	tx.Cost = breakdown.Gross
+	tx.Taxes = *breakdown
+	tx.Currency = s.pricing.Currency
+	tx.Status = domain.TransactionStatusCompleted
+	tx.UpdatedAt = time.Now()
+
+	if err := s.txRepo.Update(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+	return breakdown, nil
+}
+
 // GetPricePerKWh returns the current price per kWh
 func (s *BillingService) GetPricePerKWh(ctx context.Context) float64 {
-	return s.getRate(time.Now())
+	return rateAt(s.pricing, time.Now())
 }
 
 // GenerateInvoice generates an invoice for a transaction
@@ -184,7 +269,7 @@ func (s *BillingService) GenerateInvoice(ctx context.Context, tx *domain.Transac
 	}
 
 	energyKWh := float64(tx.TotalEnergy) / 1000.0
-	rate := s.getRate(tx.StartTime)
+	rate := s.getRate(ctx, tx)
 	idleFee := s.calculateIdleFee(tx)
 
 	var duration time.Duration
@@ -192,8 +277,21 @@ func (s *BillingService) GenerateInvoice(ctx context.Context, tx *domain.Transac
 		duration = tx.EndTime.Sub(tx.StartTime)
 	}
 
+	// Prices are tax-inclusive; with a breakdown the line items show the
+	// net share of each charge and the taxes are listed separately
+	energyCost := energyKWh * rate
+	taxes := tx.Taxes
+	if taxes.Gross > 0 && energyCost+idleFee > 0 {
+		energyShare := energyCost / (energyCost + idleFee)
+		energyCost = roundCents(taxes.Net * energyShare)
+		idleFee = roundCents(taxes.Net - energyCost)
+		if energyKWh > 0 {
+			rate = energyCost / energyKWh
+		}
+	}
+
 	invoice := &Invoice{
-		InvoiceID:       fmt.Sprintf("INV-%s", tx.ID[:8]),
+		InvoiceID:       InvoiceIDPrefix + tx.ID,
 		TransactionID:   tx.ID,
 		UserID:          tx.UserID,
 		ChargePointID:   tx.ChargePointID,
@@ -202,8 +300,9 @@ func (s *BillingService) GenerateInvoice(ctx context.Context, tx *domain.Transac
 		Duration:        duration,
 		EnergyKWh:       energyKWh,
 		RatePerKWh:      rate,
-		EnergyCost:      energyKWh * rate,
+		EnergyCost:      energyCost,
 		IdleFee:         idleFee,
+		Taxes:           taxes,
 		TotalAmount:     tx.Cost,
 		Currency:        tx.Currency,
 		GeneratedAt:     time.Now(),
@@ -212,20 +311,55 @@ func (s *BillingService) GenerateInvoice(ctx context.Context, tx *domain.Transac
 	return invoice, nil
 }
 
+// InvoiceIDPrefix prefixes the transaction ID to form the invoice ID
+const InvoiceIDPrefix = "INV-"
+
 // Invoice represents a billing invoice
 type Invoice struct {
-	InvoiceID       string        `json:"invoice_id"`
-	TransactionID   string        `json:"transaction_id"`
-	UserID          string        `json:"user_id"`
-	ChargePointID   string        `json:"charge_point_id"`
-	StartTime       time.Time     `json:"start_time"`
-	EndTime         *time.Time    `json:"end_time,omitempty"`
-	Duration        time.Duration `json:"duration"`
-	EnergyKWh       float64       `json:"energy_kwh"`
-	RatePerKWh      float64       `json:"rate_per_kwh"`
-	EnergyCost      float64       `json:"energy_cost"`
-	IdleFee         float64       `json:"idle_fee"`
-	TotalAmount     float64       `json:"total_amount"`
-	Currency        string        `json:"currency"`
-	GeneratedAt     time.Time     `json:"generated_at"`
+	InvoiceID     string              `json:"invoice_id"`
+	TransactionID string              `json:"transaction_id"`
+	UserID        string              `json:"user_id"`
+	ChargePointID string              `json:"charge_point_id"`
+	StartTime     time.Time           `json:"start_time"`
+	EndTime       *time.Time          `json:"end_time,omitempty"`
+	Duration      time.Duration       `json:"duration"`
+	EnergyKWh     float64             `json:"energy_kwh"`
+	RatePerKWh    float64             `json:"rate_per_kwh"`
+	EnergyCost    float64             `json:"energy_cost"`
+	IdleFee       float64             `json:"idle_fee"`
+	Taxes         domain.TaxBreakdown `json:"taxes"`
+	TotalAmount   float64             `json:"total_amount"`
+	Currency      string              `json:"currency"`
+	GeneratedAt   time.Time           `json:"generated_at"`
+}
+
+// Document converts the invoice to the form used for emails and PDF rendering
+func (i *Invoice) Document() *ports.Invoice {
+	return &ports.Invoice{
+		ID:            i.InvoiceID,
+		TransactionID: i.TransactionID,
+		Amount:        i.TotalAmount,
+		Currency:      i.Currency,
+		EnergyKWh:     i.EnergyKWh,
+		Duration:      formatDuration(i.Duration),
+		StationName:   i.ChargePointID,
+		Date:          i.GeneratedAt.Format("2006-01-02"),
+		UnitPrice:     i.RatePerKWh,
+		EnergyCost:    i.EnergyCost,
+		IdleFee:       i.IdleFee,
+		Taxes:         roundCents(i.Taxes.TotalTax()),
+		ICMS:          i.Taxes.ICMS,
+		PIS:           i.Taxes.PIS,
+		COFINS:        i.Taxes.COFINS,
+	}
+}
+
+// formatDuration formats a session duration as "1h 30m" or "45m"
+func formatDuration(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
 }
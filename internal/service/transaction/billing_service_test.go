@@ -0,0 +1,47 @@
+package transaction
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+func TestCalculateCost_PeakHoursInStationTimeZone(t *testing.T) {
+	// Arrange: 21:30 UTC is 18:30 in São Paulo, inside the 18-21 peak
+	if _, err := time.LoadLocation("America/Sao_Paulo"); err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	start := time.Date(2024, 1, 15, 21, 30, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	tx := &domain.Transaction{
+		ID:            "tx-789",
+		ChargePointID: "CP-SP",
+		StartTime:     start,
+		EndTime:       &end,
+		TotalEnergy:   7000,
+	}
+	billing := NewBillingService(&mocks.MockTransactionRepository{}, nil, nil, zap.NewNop())
+	billing.SetStationRepository(&mocks.MockChargePointRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Location: &domain.Location{Timezone: "America/Sao_Paulo"}}, nil
+		},
+	})
+
+	// Act
+	cost, err := billing.CalculateCost(context.Background(), tx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pricing := DefaultPricingConfig()
+	if want := 7 * pricing.BaseRatePerKWh * pricing.PeakRateMultiplier; math.Abs(cost-want) > 0.001 {
+		t.Errorf("expected the peak rate cost %.3f, got %.3f", want, cost)
+	}
+}
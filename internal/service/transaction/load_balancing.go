@@ -0,0 +1,247 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// stationSessionLimit bounds the started transactions read for one station
+const stationSessionLimit = 100
+
+// ConnectorDemand is what one charging connector asks for when a station's
+// power is shared
+type ConnectorDemand struct {
+	ConnectorID int
+	DemandW     float64 // Most the connector can draw
+	Priority    bool    // The session holds a reservation
+}
+
+// AllocatePower shares availableW between connectors by weighted max-min
+// fairness: each gets a share in proportion to its weight, priority
+// connectors weighing priorityWeight and the others 1. A connector never
+// gets more than its demand; what it leaves is shared among the rest.
+func AllocatePower(availableW float64, demands []ConnectorDemand, priorityWeight float64) map[int]float64 {
+	limits := make(map[int]float64, len(demands))
+	weight := func(d ConnectorDemand) float64 {
+		if d.Priority && priorityWeight > 0 {
+			return priorityWeight
+		}
+		return 1
+	}
+
+	remaining := math.Max(availableW, 0)
+	pending := make([]ConnectorDemand, 0, len(demands))
+	for _, d := range demands {
+		if d.DemandW > 0 {
+			pending = append(pending, d)
+		} else {
+			limits[d.ConnectorID] = 0
+		}
+	}
+
+	for len(pending) > 0 {
+		totalWeight := 0.0
+		for _, d := range pending {
+			totalWeight += weight(d)
+		}
+
+		// Connectors whose fair share covers their demand are served in full
+		var unsatisfied []ConnectorDemand
+		served := 0.0
+		for _, d := range pending {
+			if d.DemandW <= remaining*weight(d)/totalWeight {
+				limits[d.ConnectorID] = d.DemandW
+				served += d.DemandW
+			} else {
+				unsatisfied = append(unsatisfied, d)
+			}
+		}
+		if len(unsatisfied) == len(pending) {
+			for _, d := range pending {
+				limits[d.ConnectorID] = math.Floor(remaining * weight(d) / totalWeight)
+			}
+			break
+		}
+		remaining -= served
+		pending = unsatisfied
+	}
+
+	return limits
+}
+
+// SetChargingLimiter enables load balancing: BalanceStation pushes the
+// computed limits through limiter
+func (s *SmartChargingService) SetChargingLimiter(limiter ports.ChargingLimiter) {
+	s.limiter = limiter
+}
+
+// SetReservationRepository lets load balancing favour sessions that hold a
+// reservation
+func (s *SmartChargingService) SetReservationRepository(reservationRepo ports.ReservationRepository) {
+	s.reservationRepo = reservationRepo
+}
+
+// StartLoadBalancing rebalances a station whenever a session starts or ends on it
+func (s *SmartChargingService) StartLoadBalancing(mq queue.MessageQueue) error {
+	handler := func(data []byte) error {
+		var event struct {
+			DeviceID string `json:"device_id"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil || event.DeviceID == "" {
+			return nil
+		}
+		if _, err := s.BalanceStation(context.Background(), event.DeviceID); err != nil {
+			s.log.Warn("Failed to balance station", zap.String("device_id", event.DeviceID), zap.Error(err))
+		}
+		return nil
+	}
+
+	for _, subject := range []string{"transaction.started", "transaction.completed"} {
+		if err := mq.Subscribe(subject, handler); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+		}
+	}
+	return nil
+}
+
+// BalanceStation shares the power available to a station between the
+// connectors charging on it and pushes each connector's limit. Limits that
+// did not change are not sent again. It returns the limits in W by connector.
+func (s *SmartChargingService) BalanceStation(ctx context.Context, deviceID string) (map[int]float64, error) {
+	if !s.config.LoadBalancingEnabled || s.limiter == nil {
+		return nil, nil
+	}
+
+	s.balanceMu.Lock()
+	defer s.balanceMu.Unlock()
+
+	device, err := s.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	if device == nil {
+		return nil, errors.New("device not found")
+	}
+
+	demands, err := s.connectorDemands(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	limits := AllocatePower(s.stationPowerW(device), demands, s.config.ReservationWeight)
+
+	pushed := s.balancedLimits[deviceID]
+	if pushed == nil {
+		pushed = make(map[int]float64)
+		s.balancedLimits[deviceID] = pushed
+	}
+	for connectorID := range pushed {
+		if _, charging := limits[connectorID]; !charging {
+			delete(pushed, connectorID) // The station drops a TxProfile with its session
+		}
+	}
+
+	connectorIDs := make([]int, 0, len(limits))
+	for connectorID := range limits {
+		connectorIDs = append(connectorIDs, connectorID)
+	}
+	sort.Ints(connectorIDs)
+	for _, connectorID := range connectorIDs {
+		limit := limits[connectorID]
+		if prev, ok := pushed[connectorID]; ok && prev == limit {
+			continue
+		}
+		if err := s.limiter.SetChargingLimit(ctx, deviceID, connectorID, limit); err != nil {
+			s.log.Warn("Failed to push connector limit",
+				zap.String("device_id", deviceID),
+				zap.Int("connector_id", connectorID),
+				zap.Float64("limit_w", limit),
+				zap.Error(err),
+			)
+			continue
+		}
+		pushed[connectorID] = limit
+	}
+
+	s.log.Info("Station load balanced",
+		zap.String("device_id", deviceID),
+		zap.Int("sessions", len(demands)),
+		zap.Any("limits_w", limits),
+	)
+
+	return limits, nil
+}
+
+// stationPowerW returns the power a station's connectors share: the
+// configured feeder limit, or else the sum of the connector ratings
+func (s *SmartChargingService) stationPowerW(device *domain.ChargePoint) float64 {
+	if kw, _ := domain.ChargePointSetting(s.config.StationPowerKW, device.ID); kw > 0 {
+		return kw * 1000
+	}
+	total := 0.0
+	for _, conn := range device.Connectors {
+		total += conn.MaxPowerKW * 1000
+	}
+	return total
+}
+
+// connectorDemands returns one demand per session charging on the station
+func (s *SmartChargingService) connectorDemands(ctx context.Context, device *domain.ChargePoint) ([]ConnectorDemand, error) {
+	txs, err := s.txRepo.FindPage(ctx, ports.TransactionFilter{
+		Status:        string(domain.TransactionStatusStarted),
+		ChargePointID: device.ID,
+	}, nil, stationSessionLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station sessions: %w", err)
+	}
+
+	reserved := s.reservedTransactions(ctx, device.ID)
+
+	demands := make([]ConnectorDemand, 0, len(txs))
+	for _, tx := range txs {
+		demand := ConnectorDemand{
+			ConnectorID: tx.ConnectorID,
+			DemandW:     s.config.DefaultMaxPowerKW * 1000,
+			Priority:    reserved[tx.ID],
+		}
+		for _, conn := range device.Connectors {
+			if conn.ConnectorID == tx.ConnectorID && conn.MaxPowerKW > 0 {
+				demand.DemandW = conn.MaxPowerKW * 1000
+			}
+		}
+		demands = append(demands, demand)
+	}
+	return demands, nil
+}
+
+// reservedTransactions returns the IDs of the sessions started with an
+// active reservation on the station
+func (s *SmartChargingService) reservedTransactions(ctx context.Context, deviceID string) map[string]bool {
+	reserved := make(map[string]bool)
+	if s.reservationRepo == nil {
+		return reserved
+	}
+
+	reservations, err := s.reservationRepo.GetByChargePointID(ctx, deviceID, time.Now())
+	if err != nil {
+		// Everyone gets the same share rather than no limit at all
+		s.log.Warn("Failed to get station reservations", zap.String("device_id", deviceID), zap.Error(err))
+		return reserved
+	}
+	for _, r := range reservations {
+		if r.IsActive() && r.TransactionID != "" {
+			reserved[r.TransactionID] = true
+		}
+	}
+	return reserved
+}
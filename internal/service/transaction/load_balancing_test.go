@@ -0,0 +1,171 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// recordingLimiter keeps the last limit pushed to each connector
+type recordingLimiter struct {
+	limits map[int]float64
+	pushes int
+}
+
+func (l *recordingLimiter) SetChargingLimit(ctx context.Context, chargePointID string, evseID int, limitW float64) error {
+	l.limits[evseID] = limitW
+	l.pushes++
+	return nil
+}
+
+func TestAllocatePower(t *testing.T) {
+	tests := []struct {
+		name      string
+		available float64
+		demands   []ConnectorDemand
+		want      map[int]float64
+	}{
+		{
+			name:      "equal split",
+			available: 22000,
+			demands:   []ConnectorDemand{{ConnectorID: 1, DemandW: 22000}, {ConnectorID: 2, DemandW: 22000}},
+			want:      map[int]float64{1: 11000, 2: 11000},
+		},
+		{
+			name:      "reservation holder weighs double",
+			available: 30000,
+			demands:   []ConnectorDemand{{ConnectorID: 1, DemandW: 22000, Priority: true}, {ConnectorID: 2, DemandW: 22000}},
+			want:      map[int]float64{1: 20000, 2: 10000},
+		},
+		{
+			name:      "small demand leaves the rest to others",
+			available: 30000,
+			demands:   []ConnectorDemand{{ConnectorID: 1, DemandW: 7400}, {ConnectorID: 2, DemandW: 22000}, {ConnectorID: 3, DemandW: 22000}},
+			want:      map[int]float64{1: 7400, 2: 11300, 3: 11300},
+		},
+		{
+			name:      "enough for everyone",
+			available: 50000,
+			demands:   []ConnectorDemand{{ConnectorID: 1, DemandW: 22000}, {ConnectorID: 2, DemandW: 11000}},
+			want:      map[int]float64{1: 22000, 2: 11000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := AllocatePower(tt.available, tt.demands, 2)
+
+			// Assert
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			total := 0.0
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("connector %d: expected %.0f W, got %.0f W", id, want, got[id])
+				}
+				total += got[id]
+			}
+			if total > tt.available {
+				t.Errorf("expected at most %.0f W in total, got %.0f W", tt.available, total)
+			}
+		})
+	}
+}
+
+func TestBalanceStation_ConnectorLeavingRampsOthersUp(t *testing.T) {
+	// Arrange: three 22 kW connectors share a 33 kW feeder; the session on
+	// connector 1 holds a reservation
+	sessions := []domain.Transaction{
+		{ID: "tx-1", ChargePointID: "CP-001", ConnectorID: 1, Status: domain.TransactionStatusStarted},
+		{ID: "tx-2", ChargePointID: "CP-001", ConnectorID: 2, Status: domain.TransactionStatusStarted},
+		{ID: "tx-3", ChargePointID: "CP-001", ConnectorID: 3, Status: domain.TransactionStatusStarted},
+	}
+	devices := &mocks.MockChargePointRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Connectors: []domain.Connector{
+				{ConnectorID: 1, MaxPowerKW: 22},
+				{ConnectorID: 2, MaxPowerKW: 22},
+				{ConnectorID: 3, MaxPowerKW: 22},
+			}}, nil
+		},
+	}
+	txRepo := &mocks.MockTransactionRepository{
+		FindPageFunc: func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+			if filter.ChargePointID != "CP-001" || filter.Status != string(domain.TransactionStatusStarted) {
+				t.Errorf("expected started sessions of CP-001, got %+v", filter)
+			}
+			return sessions, nil
+		},
+	}
+	reservations := &mocks.MockReservationRepository{
+		GetByChargePointIDFunc: func(ctx context.Context, chargePointID string, date time.Time) ([]domain.Reservation, error) {
+			return []domain.Reservation{{ID: "res-1", Status: domain.ReservationStatusActive, TransactionID: "tx-1"}}, nil
+		},
+	}
+	config := DefaultSmartChargingConfig()
+	config.StationPowerKW = map[string]float64{"CP-001": 33}
+	svc := NewSmartChargingService(devices, txRepo, nil, config, zap.NewNop())
+	svc.SetReservationRepository(reservations)
+	limiter := &recordingLimiter{limits: make(map[int]float64)}
+	svc.SetChargingLimiter(limiter)
+
+	// Act
+	if _, err := svc.BalanceStation(context.Background(), "CP-001"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	before := map[int]float64{1: limiter.limits[1], 2: limiter.limits[2], 3: limiter.limits[3]}
+
+	sessions = sessions[:2] // Connector 3 leaves
+	limits, err := svc.BalanceStation(context.Background(), "CP-001")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if before[1] != 16500 || before[2] != 8250 || before[3] != 8250 {
+		t.Errorf("expected 16500/8250/8250 W with three sessions, got %v", before)
+	}
+	if limits[1] != 22000 || limits[2] != 11000 {
+		t.Errorf("expected 22000 and 11000 W once connector 3 left, got %v", limits)
+	}
+	if limiter.limits[1] != 22000 || limiter.limits[2] != 11000 {
+		t.Errorf("expected the new limits pushed, got %v", limiter.limits)
+	}
+	if limiter.pushes != 5 {
+		t.Errorf("expected 3 pushes then 2, got %d", limiter.pushes)
+	}
+}
+
+func TestBalanceStation_SkipsUnchangedLimits(t *testing.T) {
+	// Arrange
+	devices := &mocks.MockChargePointRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Connectors: []domain.Connector{{ConnectorID: 1, MaxPowerKW: 22}, {ConnectorID: 2, MaxPowerKW: 22}}}, nil
+		},
+	}
+	txRepo := &mocks.MockTransactionRepository{
+		FindPageFunc: func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+			return []domain.Transaction{{ID: "tx-1", ConnectorID: 1}, {ID: "tx-2", ConnectorID: 2}}, nil
+		},
+	}
+	svc := NewSmartChargingService(devices, txRepo, nil, nil, zap.NewNop())
+	limiter := &recordingLimiter{limits: make(map[int]float64)}
+	svc.SetChargingLimiter(limiter)
+
+	// Act
+	svc.BalanceStation(context.Background(), "CP-001")
+	svc.BalanceStation(context.Background(), "CP-001")
+
+	// Assert
+	if limiter.pushes != 2 {
+		t.Errorf("expected the second round to push nothing, got %d pushes", limiter.pushes)
+	}
+}
@@ -0,0 +1,170 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// meterRetentionPageSize is how many transactions are read per page
+const meterRetentionPageSize = 100
+
+// MeterRetentionConfig holds meter value retention settings
+type MeterRetentionConfig struct {
+	FullResolution     time.Duration // Sessions younger than this keep every sample
+	DownsampleInterval time.Duration // Older sessions keep one sample per interval
+	Retention          time.Duration // Samples older than this are deleted
+}
+
+// DefaultMeterRetentionConfig keeps every sample for 30 days, then one per
+// 15 minutes for a year
+func DefaultMeterRetentionConfig() MeterRetentionConfig {
+	return MeterRetentionConfig{
+		FullResolution:     30 * 24 * time.Hour,
+		DownsampleInterval: 15 * time.Minute,
+		Retention:          365 * 24 * time.Hour,
+	}
+}
+
+// MeterRetentionResult is what one retention run changed
+type MeterRetentionResult struct {
+	Downsampled int // Transactions whose series was downsampled
+	Deleted     int // Samples past the retention horizon
+}
+
+// MeterRetentionService downsamples the meter values of finished sessions
+// once they are no longer recent and deletes them past the retention
+// horizon. Billing reads the start and stop registers stored on the
+// transaction, which are never touched.
+type MeterRetentionService struct {
+	txRepo    ports.TransactionRepository
+	meterRepo ports.MeterValueRepository
+	config    MeterRetentionConfig
+	log       *zap.Logger
+
+	// Sessions created before this were downsampled by an earlier run
+	downsampledUntil time.Time
+}
+
+// NewMeterRetentionService creates a new meter value retention service
+func NewMeterRetentionService(
+	txRepo ports.TransactionRepository,
+	meterRepo ports.MeterValueRepository,
+	config MeterRetentionConfig,
+	log *zap.Logger,
+) *MeterRetentionService {
+	defaults := DefaultMeterRetentionConfig()
+	if config.FullResolution <= 0 {
+		config.FullResolution = defaults.FullResolution
+	}
+	if config.DownsampleInterval <= 0 {
+		config.DownsampleInterval = defaults.DownsampleInterval
+	}
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+
+	return &MeterRetentionService{
+		txRepo:    txRepo,
+		meterRepo: meterRepo,
+		config:    config,
+		log:       log,
+	}
+}
+
+// RunOnce downsamples the finished sessions that left the full resolution
+// window since the last run and deletes the samples past the horizon
+func (s *MeterRetentionService) RunOnce(ctx context.Context) (MeterRetentionResult, error) {
+	var result MeterRetentionResult
+	now := time.Now()
+	horizon := now.Add(-s.config.Retention)
+
+	deleted, err := s.meterRepo.DeleteBefore(ctx, horizon)
+	if err != nil {
+		return result, fmt.Errorf("failed to delete expired meter values: %w", err)
+	}
+	result.Deleted = deleted
+
+	from := horizon
+	if s.downsampledUntil.After(from) {
+		from = s.downsampledUntil
+	}
+	until := now.Add(-s.config.FullResolution)
+	if !until.After(from) {
+		return result, nil
+	}
+
+	for _, status := range []domain.TransactionStatus{domain.TransactionStatusStopped, domain.TransactionStatusCompleted} {
+		filter := ports.TransactionFilter{Status: string(status), StartDate: from, EndDate: until}
+		var after *ports.TransactionCursor
+		for {
+			page, err := s.txRepo.FindPage(ctx, filter, after, meterRetentionPageSize)
+			if err != nil {
+				return result, fmt.Errorf("failed to list transactions: %w", err)
+			}
+			for i := range page {
+				downsampled, err := s.downsample(ctx, page[i].ID)
+				if err != nil {
+					return result, err
+				}
+				if downsampled {
+					result.Downsampled++
+				}
+			}
+			if len(page) < meterRetentionPageSize {
+				break
+			}
+			last := page[len(page)-1]
+			after = &ports.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+	}
+	s.downsampledUntil = until
+
+	if result.Downsampled > 0 || result.Deleted > 0 {
+		s.log.Info("Meter value retention applied",
+			zap.Int("downsampled_transactions", result.Downsampled),
+			zap.Int("deleted_samples", result.Deleted),
+		)
+	}
+	return result, nil
+}
+
+// Start runs RunOnce every interval until ctx is done
+func (s *MeterRetentionService) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.RunOnce(ctx); err != nil {
+					s.log.Error("Meter value retention failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// downsample replaces the series of a transaction with its downsampled
+// form. It reports false when there was nothing to drop.
+func (s *MeterRetentionService) downsample(ctx context.Context, txID string) (bool, error) {
+	samples, err := s.meterRepo.FindByTransactionID(ctx, txID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get meter values of %s: %w", txID, err)
+	}
+	kept := domain.DownsampleMeterSamples(samples, s.config.DownsampleInterval)
+	if len(kept) == len(samples) {
+		return false, nil
+	}
+	if err := s.meterRepo.ReplaceByTransactionID(ctx, txID, kept); err != nil {
+		return false, fmt.Errorf("failed to downsample meter values of %s: %w", txID, err)
+	}
+	return true, nil
+}
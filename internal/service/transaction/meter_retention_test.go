@@ -0,0 +1,168 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// chargingSeries is a session sampled every step for duration: an energy
+// register drawing 11 kW, opened and closed by the billed readings, and the
+// active power alongside
+func chargingSeries(txID string, start time.Time, duration, step time.Duration) []domain.MeterSample {
+	var samples []domain.MeterSample
+	for at := time.Duration(0); at <= duration; at += step {
+		sampleContext := "Sample.Periodic"
+		switch at {
+		case 0:
+			sampleContext = domain.MeterContextTransactionBegin
+		case duration:
+			sampleContext = domain.MeterContextTransactionEnd
+		}
+		ts := start.Add(at)
+		samples = append(samples,
+			domain.MeterSample{
+				ID: fmt.Sprintf("%s-e-%d", txID, at), TransactionID: txID, Timestamp: ts, Context: sampleContext,
+				Measurand: domain.MeasurandEnergyActiveImportRegister, Value: 5000 + at.Hours()*11000, Unit: "Wh",
+			},
+			domain.MeterSample{
+				ID: fmt.Sprintf("%s-p-%d", txID, at), TransactionID: txID, Timestamp: ts, Context: sampleContext,
+				Measurand: "Power.Active.Import", Value: 11000 + float64(at/step%3), Unit: "W",
+			},
+		)
+	}
+	return samples
+}
+
+// registerEndpoints returns the first and last energy register readings
+func registerEndpoints(samples []domain.MeterSample) (first, last domain.MeterSample) {
+	var registers []domain.MeterSample
+	for _, s := range samples {
+		if s.Measurand == domain.MeasurandEnergyActiveImportRegister {
+			registers = append(registers, s)
+		}
+	}
+	return registers[0], registers[len(registers)-1]
+}
+
+func TestDownsampleMeterSamples_PreservesEnergyAndEndpoints(t *testing.T) {
+	start := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		duration time.Duration
+		step     time.Duration
+		interval time.Duration
+		wantMax  int // Samples kept per measurand at most
+	}{
+		{"minute samples to quarter hours", 2 * time.Hour, time.Minute, 15 * time.Minute, 10},
+		{"ten second samples to minutes", 20*time.Minute + 30*time.Second, 10 * time.Second, time.Minute, 23},
+		{"uneven interval", 3 * time.Hour, 2 * time.Minute, 7 * time.Minute, 28},
+		{"interval longer than the session", 30 * time.Minute, time.Minute, time.Hour, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			samples := chargingSeries("tx-1", start, tt.duration, tt.step)
+			wantFirst, wantLast := registerEndpoints(samples)
+
+			// Act
+			kept := domain.DownsampleMeterSamples(samples, tt.interval)
+
+			// Assert
+			if len(kept) > 2*tt.wantMax || len(kept) >= len(samples) {
+				t.Errorf("expected at most %d samples of %d, got %d", 2*tt.wantMax, len(samples), len(kept))
+			}
+			first, last := registerEndpoints(kept)
+			if first.ID != wantFirst.ID || last.ID != wantLast.ID {
+				t.Errorf("expected the endpoints %s and %s, got %s and %s", wantFirst.ID, wantLast.ID, first.ID, last.ID)
+			}
+			if got, want := last.Value-first.Value, wantLast.Value-wantFirst.Value; got != want {
+				t.Errorf("expected total energy %.0f Wh, got %.0f Wh", want, got)
+			}
+			contexts := map[string]int{}
+			for i, s := range kept {
+				contexts[s.Context]++
+				if i > 0 && s.Timestamp.Before(kept[i-1].Timestamp) {
+					t.Fatalf("expected samples oldest first, got %s after %s", s.Timestamp, kept[i-1].Timestamp)
+				}
+			}
+			if contexts[domain.MeterContextTransactionBegin] != 2 || contexts[domain.MeterContextTransactionEnd] != 2 {
+				t.Errorf("expected the begin and end readings of both measurands, got %v", contexts)
+			}
+		})
+	}
+}
+
+func TestMeterRetention_RunOnce(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Now()
+	txRepo := memory.NewTransactionRepository()
+	meterRepo := memory.NewMeterValueRepository()
+	sessions := map[string]struct {
+		age    time.Duration
+		status domain.TransactionStatus
+	}{
+		"tx-old":     {40 * 24 * time.Hour, domain.TransactionStatusCompleted},
+		"tx-recent":  {2 * 24 * time.Hour, domain.TransactionStatusCompleted},
+		"tx-stuck":   {40 * 24 * time.Hour, domain.TransactionStatusStarted},
+		"tx-expired": {400 * 24 * time.Hour, domain.TransactionStatusCompleted},
+	}
+	stored := map[string][]domain.MeterSample{}
+	for id, session := range sessions {
+		start := now.Add(-session.age)
+		if err := txRepo.Save(ctx, &domain.Transaction{ID: id, Status: session.status, StartTime: start, CreatedAt: start}); err != nil {
+			t.Fatalf("failed to save transaction: %v", err)
+		}
+		stored[id] = chargingSeries(id, start, 2*time.Hour, time.Minute)
+		if err := meterRepo.Save(ctx, stored[id]); err != nil {
+			t.Fatalf("failed to save samples: %v", err)
+		}
+	}
+	service := NewMeterRetentionService(txRepo, meterRepo, MeterRetentionConfig{
+		FullResolution:     30 * 24 * time.Hour,
+		DownsampleInterval: 15 * time.Minute,
+		Retention:          365 * 24 * time.Hour,
+	}, zap.NewNop())
+
+	// Act
+	result, err := service.RunOnce(ctx)
+	again, againErr := service.RunOnce(ctx)
+
+	// Assert
+	if err != nil || againErr != nil {
+		t.Fatalf("expected no error, got %v, %v", err, againErr)
+	}
+	if result.Downsampled != 1 || result.Deleted != len(stored["tx-expired"]) {
+		t.Errorf("expected 1 downsampled session and %d deleted samples, got %+v", len(stored["tx-expired"]), result)
+	}
+	if again != (MeterRetentionResult{}) {
+		t.Errorf("expected a second run to change nothing, got %+v", again)
+	}
+
+	old, _ := meterRepo.FindByTransactionID(ctx, "tx-old")
+	if len(old) >= len(stored["tx-old"]) {
+		t.Errorf("expected tx-old to be downsampled, got %d of %d samples", len(old), len(stored["tx-old"]))
+	}
+	first, last := registerEndpoints(old)
+	wantFirst, wantLast := registerEndpoints(stored["tx-old"])
+	if last.Value-first.Value != wantLast.Value-wantFirst.Value {
+		t.Errorf("expected total energy %.0f Wh, got %.0f Wh", wantLast.Value-wantFirst.Value, last.Value-first.Value)
+	}
+	for _, id := range []string{"tx-recent", "tx-stuck"} {
+		if samples, _ := meterRepo.FindByTransactionID(ctx, id); len(samples) != len(stored[id]) {
+			t.Errorf("expected %s to keep all %d samples, got %d", id, len(stored[id]), len(samples))
+		}
+	}
+	if expired, _ := meterRepo.FindByTransactionID(ctx, "tx-expired"); len(expired) != 0 {
+		t.Errorf("expected the samples of tx-expired to be deleted, got %d", len(expired))
+	}
+}
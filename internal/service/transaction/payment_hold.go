@@ -0,0 +1,117 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// PaymentHoldConfig sizes the hold placed on a card user's card when their
+// session starts
+type PaymentHoldConfig struct {
+	MaxEnergyKWh float64 // Most energy a session is expected to draw, priced at the peak rate
+}
+
+// DefaultPaymentHoldConfig holds enough for 80 kWh, a full charge of most
+// cars
+func DefaultPaymentHoldConfig() PaymentHoldConfig {
+	return PaymentHoldConfig{MaxEnergyKWh: 80}
+}
+
+// paymentHolds places holds on the cards of users paying by card
+type paymentHolds struct {
+	payments ports.PaymentService
+	wallets  ports.WalletRepository
+	users    ports.UserRepository
+	config   PaymentHoldConfig
+}
+
+// SetPaymentHolds enables placing a hold on the card of users whose wallet
+// cannot cover a session when it starts. The hold is sized to the most the
+// session is expected to cost and captured for the actual cost when the
+// session is settled; a declined hold refuses the session. Sessions a
+// station started for an ID token that is not a known user, e.g. an RFID
+// card, are not held.
+func (s *Service) SetPaymentHolds(payments ports.PaymentService, wallets ports.WalletRepository, users ports.UserRepository, config PaymentHoldConfig) {
+	if config.MaxEnergyKWh <= 0 {
+		config.MaxEnergyKWh = DefaultPaymentHoldConfig().MaxEnergyKWh
+	}
+	s.holds = &paymentHolds{payments: payments, wallets: wallets, users: users, config: config}
+}
+
+// holdAmount is what a session may cost at most: the configured energy at
+// the peak rate
+func (s *Service) holdAmount() float64 {
+	rate := s.pricing.BaseRatePerKWh
+	if s.pricing.PeakRateMultiplier > 1 {
+		rate *= s.pricing.PeakRateMultiplier
+	}
+	return math.Ceil(s.holds.config.MaxEnergyKWh*rate*100) / 100
+}
+
+// placeHold authorizes the most tx may cost on its user's card, unless their
+// wallet covers it or tx has no known user to hold. The hold is recorded on
+// tx.
+func (s *Service) placeHold(ctx context.Context, tx *domain.Transaction) error {
+	if s.holds == nil {
+		return nil
+	}
+	if s.holds.users != nil {
+		user, err := s.holds.users.FindByID(ctx, tx.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			s.log.Info("No user for the session's ID token, no payment hold placed",
+				zap.String("tx_id", tx.ID),
+				zap.String("id_tag", tx.IdTag),
+			)
+			return nil
+		}
+	}
+
+	amount := s.holdAmount()
+	if s.holds.wallets != nil {
+		wallet, err := s.holds.wallets.GetByUserID(ctx, tx.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get wallet: %w", err)
+		}
+		if wallet != nil && wallet.Balance >= amount {
+			return nil
+		}
+	}
+
+	payment, err := s.holds.payments.Authorize(ctx, tx.UserID, tx.ID, amount)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrPaymentHoldDeclined, err)
+	}
+	tx.PaymentStatus = domain.TransactionPaymentAuthorized
+	tx.PaymentID = payment.ID
+
+	s.log.Info("Payment hold placed",
+		zap.String("tx_id", tx.ID),
+		zap.String("user_id", tx.UserID),
+		zap.String("payment_id", payment.ID),
+		zap.Float64("amount", amount),
+	)
+	return nil
+}
+
+// releaseHold voids the hold of a session that did not start
+func (s *Service) releaseHold(ctx context.Context, tx *domain.Transaction) {
+	if s.holds == nil || tx.PaymentStatus != domain.TransactionPaymentAuthorized {
+		return
+	}
+	if _, err := s.holds.payments.Void(ctx, tx.PaymentID); err != nil {
+		s.log.Error("Failed to release payment hold",
+			zap.String("tx_id", tx.ID),
+			zap.String("payment_id", tx.PaymentID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,103 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// authorizingPayments places holds, declining them with err
+type authorizingPayments struct {
+	ports.PaymentService
+	err        error
+	authorized []float64
+	voided     []string
+}
+
+func (p *authorizingPayments) Authorize(ctx context.Context, userID, transactionID string, amount float64) (*domain.Payment, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.authorized = append(p.authorized, amount)
+	return &domain.Payment{ID: "hold-1", Status: domain.PaymentStatusAuthorized, AuthorizedAmount: amount}, nil
+}
+
+func (p *authorizingPayments) Void(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	p.voided = append(p.voided, paymentID)
+	return &domain.Payment{ID: paymentID, Status: domain.PaymentStatusCancelled}, nil
+}
+
+func TestStartTransaction_PaymentHold(t *testing.T) {
+	// 80 kWh at 0.75 per kWh and the 1.5 peak multiplier
+	tests := []struct {
+		name         string
+		userID       string
+		wallet       float64
+		declined     error
+		wantErr      error
+		wantHold     []float64
+		wantStatus   domain.TransactionPaymentStatus
+		wantReleased bool
+	}{
+		{"card user is held the most the session may cost", "user-1", 10, nil, nil, []float64{90}, domain.TransactionPaymentAuthorized, false},
+		{"wallet covering the session is not held", "user-1", 100, nil, nil, nil, "", false},
+		{"declined hold refuses the session", "user-1", 10, errors.New("card declined"), domain.ErrPaymentHoldDeclined, nil, "", true},
+		{"RFID card without a user is not held", "04A1B2C3", 0, nil, nil, nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			released := false
+			devices := &mocks.MockDeviceService{
+				GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+					return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable}, nil
+				},
+				ReleaseConnectorFunc: func(ctx context.Context, id string, connectorID int) error {
+					released = true
+					return nil
+				},
+			}
+			txRepo := &mocks.MockTransactionRepository{
+				FindActiveByUserIDFunc: func(ctx context.Context, userID string) (*domain.Transaction, error) {
+					return nil, nil
+				},
+			}
+			payments := &authorizingPayments{err: tt.declined}
+			wallets := &walletBalances{wallets: map[string]*domain.Wallet{"user-1": {UserID: "user-1", Balance: tt.wallet}}}
+			users := &mocks.MockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*domain.User, error) {
+					if id != "user-1" {
+						return nil, nil
+					}
+					return &domain.User{ID: id}, nil
+				},
+			}
+			service := NewService(txRepo, devices, mocks.NewMockMessageQueue(), newTestLogger()).(*Service)
+			service.SetPaymentHolds(payments, wallets, users, DefaultPaymentHoldConfig())
+
+			// Act
+			tx, err := service.StartTransaction(ctx, "CP-001", 1, tt.userID, "rfid")
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(payments.authorized) != len(tt.wantHold) ||
+				(len(tt.wantHold) > 0 && payments.authorized[0] != tt.wantHold[0]) {
+				t.Errorf("expected %v held, got %v", tt.wantHold, payments.authorized)
+			}
+			if tx != nil && (tx.PaymentStatus != tt.wantStatus || (tt.wantStatus != "" && tx.PaymentID != "hold-1")) {
+				t.Errorf("expected payment status %q, got %q with %q", tt.wantStatus, tx.PaymentStatus, tx.PaymentID)
+			}
+			if released != tt.wantReleased {
+				t.Errorf("expected connector released %v, got %v", tt.wantReleased, released)
+			}
+		})
+	}
+}
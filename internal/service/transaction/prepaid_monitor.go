@@ -0,0 +1,261 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/internal/service/notification"
+)
+
+// PrepaidMonitorConfig holds prepaid session monitor settings
+type PrepaidMonitorConfig struct {
+	Interval time.Duration // How often active sessions are checked
+	Buffer   float64       // Stop once the accrued cost is within this amount of the balance
+}
+
+// DefaultPrepaidMonitorConfig checks every 30 seconds and keeps 1.00 of the
+// balance free, about what a 22 kW session draws between two checks
+func DefaultPrepaidMonitorConfig() PrepaidMonitorConfig {
+	return PrepaidMonitorConfig{
+		Interval: 30 * time.Second,
+		Buffer:   1.00,
+	}
+}
+
+// prepaidSession is what the monitor remembers about an active session
+type prepaidSession struct {
+	prepaid       bool // The user's wallet held funds when the session was first seen
+	stopRequested bool
+}
+
+// PrepaidMonitor stops prepaid sessions before they cost more than their
+// user's wallet holds. A session is prepaid when its user's wallet held
+// funds the first time the monitor saw it; users paying by card have no
+// balance and are billed when the session ends. Sessions with a payment
+// hold are paid by card even when the wallet has funds, and are not
+// monitored.
+type PrepaidMonitor struct {
+	sessions ports.TransactionService
+	wallets  ports.WalletRepository
+	stopper  ports.SessionStopper
+	mq       queue.MessageQueue          // optional, users are not notified without it
+	stations ports.ChargePointRepository // optional, see SetStationRepository
+	pricing  *PricingConfig
+	config   PrepaidMonitorConfig
+	log      *zap.Logger
+
+	mu      sync.Mutex
+	tracked map[string]*prepaidSession // transaction ID → session
+}
+
+// NewPrepaidMonitor creates a prepaid session monitor
+func NewPrepaidMonitor(
+	sessions ports.TransactionService,
+	wallets ports.WalletRepository,
+	stopper ports.SessionStopper,
+	mq queue.MessageQueue,
+	config PrepaidMonitorConfig,
+	log *zap.Logger,
+) *PrepaidMonitor {
+	defaults := DefaultPrepaidMonitorConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Buffer < 0 {
+		config.Buffer = defaults.Buffer
+	}
+
+	return &PrepaidMonitor{
+		sessions: sessions,
+		wallets:  wallets,
+		stopper:  stopper,
+		mq:       mq,
+		pricing:  DefaultPricingConfig(),
+		config:   config,
+		log:      log,
+		tracked:  make(map[string]*prepaidSession),
+	}
+}
+
+// SetStationRepository prices peak hours in each station's local time
+// instead of the server's, as billing does
+func (m *PrepaidMonitor) SetStationRepository(stations ports.ChargePointRepository) {
+	m.stations = stations
+}
+
+// stationZone returns the time zone of a session's station, the server's
+// when it is unknown
+func (m *PrepaidMonitor) stationZone(ctx context.Context, chargePointID string) *time.Location {
+	if m.stations == nil {
+		return time.Local
+	}
+	cp, err := m.stations.FindByID(ctx, chargePointID)
+	if err != nil || cp == nil {
+		return time.Local
+	}
+	return cp.TimeZone()
+}
+
+// RunOnce checks every active session and returns how many were asked to
+// stop. A session whose stop fails is tried again on the next run.
+func (m *PrepaidMonitor) RunOnce(ctx context.Context) (int, error) {
+	active, err := m.sessions.ListActiveSessions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list active sessions: %w", err)
+	}
+	m.forgetEnded(active)
+
+	now := time.Now()
+	stopped := 0
+	for i := range active {
+		tx := &active[i].Transaction
+		ok, err := m.check(ctx, tx, now)
+		if err != nil {
+			m.log.Warn("Failed to check prepaid session",
+				zap.String("tx_id", tx.ID),
+				zap.String("user_id", tx.UserID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if ok {
+			stopped++
+		}
+	}
+	return stopped, nil
+}
+
+// Start runs RunOnce every interval until ctx is done
+func (m *PrepaidMonitor) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := m.RunOnce(ctx); err != nil {
+					m.log.Error("Prepaid session check failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// check stops tx if its accrued cost has come within the buffer of the
+// user's balance. It reports whether a stop was requested.
+func (m *PrepaidMonitor) check(ctx context.Context, tx *domain.Transaction, now time.Time) (bool, error) {
+	if tx.PaymentStatus == domain.TransactionPaymentAuthorized {
+		return false, nil
+	}
+
+	// Only metered energy counts; an estimate could cut a session off early
+	cost := currentSessionCost(tx, m.pricing, m.stationZone(ctx, tx.ChargePointID), now)
+	if cost.Estimated {
+		return false, nil
+	}
+
+	m.mu.Lock()
+	session, seen := m.tracked[tx.ID]
+	m.mu.Unlock()
+	if seen && (!session.prepaid || session.stopRequested) {
+		return false, nil
+	}
+
+	wallet, err := m.wallets.GetByUserID(ctx, tx.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	m.mu.Lock()
+	if !seen {
+		session = &prepaidSession{prepaid: wallet != nil && wallet.Balance > 0}
+		m.tracked[tx.ID] = session
+	}
+	prepaid := session.prepaid
+	m.mu.Unlock()
+	if !prepaid || wallet == nil {
+		return false, nil
+	}
+	if wallet.Currency != "" && wallet.Currency != cost.Currency {
+		m.log.Warn("Prepaid session not billed in the wallet currency, not monitored",
+			zap.String("tx_id", tx.ID),
+			zap.String("session_currency", cost.Currency),
+			zap.String("wallet_currency", wallet.Currency),
+		)
+		m.mu.Lock()
+		session.prepaid = false
+		m.mu.Unlock()
+		return false, nil
+	}
+
+	if cost.Cost+m.config.Buffer < wallet.Balance {
+		return false, nil
+	}
+
+	if err := m.stopper.StopSession(ctx, tx.ChargePointID, tx.ID); err != nil {
+		return false, fmt.Errorf("failed to stop session: %w", err)
+	}
+	m.mu.Lock()
+	session.stopRequested = true
+	m.mu.Unlock()
+
+	m.log.Info("Prepaid balance exhausted, session stop requested",
+		zap.String("tx_id", tx.ID),
+		zap.String("user_id", tx.UserID),
+		zap.Float64("cost", cost.Cost),
+		zap.Float64("balance", wallet.Balance),
+	)
+	m.notify(tx, cost, wallet)
+	return true, nil
+}
+
+// notify tells the user their session was stopped
+func (m *PrepaidMonitor) notify(tx *domain.Transaction, cost *domain.SessionCost, wallet *domain.Wallet) {
+	if m.mq == nil {
+		return
+	}
+
+	event := domain.NotificationEvent{
+		Type:   domain.NotificationBalanceExhausted,
+		UserID: tx.UserID,
+		Title:  "Charging stopped",
+		Body: fmt.Sprintf("Your wallet balance of %.2f %s is almost used up; the session has cost %.2f %s so far.",
+			wallet.Balance, cost.Currency, cost.Cost, cost.Currency),
+		Data: map[string]string{
+			"transaction_id":  tx.ID,
+			"charge_point_id": tx.ChargePointID,
+		},
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := m.mq.Publish(notification.Subject, data); err != nil {
+		m.log.Warn("Failed to publish balance exhausted notification", zap.Error(err))
+	}
+}
+
+// forgetEnded drops the sessions that are no longer active
+func (m *PrepaidMonitor) forgetEnded(active []domain.ActiveSession) {
+	ids := make(map[string]bool, len(active))
+	for _, session := range active {
+		ids[session.Transaction.ID] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id := range m.tracked {
+		if !ids[id] {
+			delete(m.tracked, id)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/internal/service/notification"
+)
+
+// walletBalances serves wallets by user ID; users without one have none
+type walletBalances struct {
+	ports.WalletRepository
+	wallets map[string]*domain.Wallet
+}
+
+func (w *walletBalances) GetByUserID(ctx context.Context, userID string) (*domain.Wallet, error) {
+	return w.wallets[userID], nil
+}
+
+// recordingStopper records the sessions it was asked to stop
+type recordingStopper struct {
+	err     error
+	stopped []string
+}
+
+func (r *recordingStopper) StopSession(ctx context.Context, chargePointID, transactionID string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.stopped = append(r.stopped, chargePointID+"/"+transactionID)
+	return nil
+}
+
+// meteredSession is an off-peak session of user-1 that has drawn energyWh
+func meteredSession(energyWh int) domain.ActiveSession {
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+	return domain.ActiveSession{Transaction: domain.Transaction{
+		ID:            "tx-1",
+		ChargePointID: "CP-001",
+		UserID:        "user-1",
+		StartTime:     start,
+		MeterStart:    1000,
+		MeterStop:     1000 + energyWh,
+		Status:        domain.TransactionStatusStarted,
+		Currency:      "BRL",
+	}}
+}
+
+// heldSession marks session as paid by a hold on the user's card
+func heldSession(session domain.ActiveSession) domain.ActiveSession {
+	session.Transaction.PaymentStatus = domain.TransactionPaymentAuthorized
+	session.Transaction.PaymentID = "hold-1"
+	return session
+}
+
+func activeSessionsOf(sessions ...domain.ActiveSession) *mocks.MockTransactionService {
+	return &mocks.MockTransactionService{
+		ListActiveSessionsFunc: func(ctx context.Context) ([]domain.ActiveSession, error) {
+			return sessions, nil
+		},
+	}
+}
+
+func TestPrepaidMonitor_StopsWhenCostReachesBalance(t *testing.T) {
+	funded := &domain.Wallet{UserID: "user-1", Balance: 20, Currency: "BRL"}
+
+	// 0.75 per kWh off-peak with a 1.00 buffer: the stop comes at 25.33 kWh
+	tests := []struct {
+		name     string
+		session  domain.ActiveSession
+		wallet   *domain.Wallet
+		wantStop bool
+	}{
+		{"well within balance", meteredSession(10000), funded, false},
+		{"just outside buffer", meteredSession(25000), funded, false},
+		{"within buffer", meteredSession(26000), funded, true},
+		{"past balance", meteredSession(30000), funded, true},
+		{"no wallet", meteredSession(30000), nil, false},
+		{"paid by a card hold", heldSession(meteredSession(30000)), funded, false},
+		{"empty wallet", meteredSession(30000), &domain.Wallet{UserID: "user-1", Currency: "BRL"}, false},
+		{"other currency", meteredSession(30000), &domain.Wallet{UserID: "user-1", Balance: 20, Currency: "USD"}, false},
+		{"no meter reading", domain.ActiveSession{Transaction: domain.Transaction{
+			ID: "tx-1", ChargePointID: "CP-001", UserID: "user-1", StartTime: time.Now().Add(-10 * time.Hour), Currency: "BRL",
+		}}, funded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			wallets := &walletBalances{wallets: map[string]*domain.Wallet{}}
+			if tt.wallet != nil {
+				wallets.wallets["user-1"] = tt.wallet
+			}
+			stopper := &recordingStopper{}
+			monitor := NewPrepaidMonitor(activeSessionsOf(tt.session), wallets, stopper, nil,
+				PrepaidMonitorConfig{Buffer: 1}, zap.NewNop())
+
+			// Act
+			stopped, err := monitor.RunOnce(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tt.wantStop && (stopped != 1 || len(stopper.stopped) != 1 || stopper.stopped[0] != "CP-001/tx-1") {
+				t.Errorf("expected CP-001/tx-1 to be stopped, got %d stops %v", stopped, stopper.stopped)
+			}
+			if !tt.wantStop && len(stopper.stopped) != 0 {
+				t.Errorf("expected no stop, got %v", stopper.stopped)
+			}
+		})
+	}
+}
+
+func TestPrepaidMonitor_StopsOnceAndNotifies(t *testing.T) {
+	// Arrange
+	session := meteredSession(10000)
+	sessions := &mocks.MockTransactionService{
+		ListActiveSessionsFunc: func(ctx context.Context) ([]domain.ActiveSession, error) {
+			return []domain.ActiveSession{session}, nil
+		},
+	}
+	wallets := &walletBalances{wallets: map[string]*domain.Wallet{
+		"user-1": {UserID: "user-1", Balance: 20, Currency: "BRL"},
+	}}
+	stopper := &recordingStopper{}
+	mq := mocks.NewMockMessageQueue()
+	monitor := NewPrepaidMonitor(sessions, wallets, stopper, mq, PrepaidMonitorConfig{Buffer: 1}, zap.NewNop())
+	ctx := context.Background()
+
+	// Act: the session keeps charging past the balance until the station ends it
+	monitor.RunOnce(ctx)
+	session = meteredSession(27000)
+	monitor.RunOnce(ctx)
+	session = meteredSession(28000)
+	monitor.RunOnce(ctx)
+
+	// Assert
+	if len(stopper.stopped) != 1 {
+		t.Fatalf("expected one stop request, got %v", stopper.stopped)
+	}
+	published := mq.PublishedMessages[notification.Subject]
+	if len(published) != 1 {
+		t.Fatalf("expected one notification, got %d", len(published))
+	}
+	var event domain.NotificationEvent
+	if err := json.Unmarshal(published[0], &event); err != nil {
+		t.Fatalf("expected a notification event, got %v", err)
+	}
+	if event.Type != domain.NotificationBalanceExhausted || event.UserID != "user-1" {
+		t.Errorf("expected %s for user-1, got %s for %s", domain.NotificationBalanceExhausted, event.Type, event.UserID)
+	}
+	if event.Data["transaction_id"] != "tx-1" {
+		t.Errorf("expected transaction tx-1, got %s", event.Data["transaction_id"])
+	}
+}
+
+func TestPrepaidMonitor_RetriesFailedStop(t *testing.T) {
+	// Arrange
+	wallets := &walletBalances{wallets: map[string]*domain.Wallet{
+		"user-1": {UserID: "user-1", Balance: 20, Currency: "BRL"},
+	}}
+	stopper := &recordingStopper{err: errors.New("charge point CP-001 not connected")}
+	mq := mocks.NewMockMessageQueue()
+	monitor := NewPrepaidMonitor(activeSessionsOf(meteredSession(30000)), wallets, stopper, mq,
+		PrepaidMonitorConfig{Buffer: 1}, zap.NewNop())
+	ctx := context.Background()
+
+	// Act
+	first, _ := monitor.RunOnce(ctx)
+	stopper.err = nil
+	second, _ := monitor.RunOnce(ctx)
+
+	// Assert
+	if first != 0 || second != 1 {
+		t.Errorf("expected the stop to succeed on the second run, got %d then %d", first, second)
+	}
+	if len(mq.PublishedMessages[notification.Subject]) != 1 {
+		t.Errorf("expected the user to be notified once, got %d", len(mq.PublishedMessages[notification.Subject]))
+	}
+}
+
+func TestPrepaidMonitor_ListError(t *testing.T) {
+	// Arrange
+	sessions := &mocks.MockTransactionService{
+		ListActiveSessionsFunc: func(ctx context.Context) ([]domain.ActiveSession, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	monitor := NewPrepaidMonitor(sessions, &walletBalances{}, &recordingStopper{}, nil, PrepaidMonitorConfig{}, zap.NewNop())
+
+	// Act
+	_, err := monitor.RunOnce(context.Background())
+
+	// Assert
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,211 @@
+package transaction
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+const (
+	// minProjectionWindow is the metered time needed before the measured
+	// power is trusted for a projection
+	minProjectionWindow = 5 * time.Minute
+
+	// assumedPowerKW is used when there is not enough meter history
+	assumedPowerKW = 7.0
+
+	// recentPowerSamples is how many of the latest energy register readings
+	// the current charging power is measured over
+	recentPowerSamples = 6
+)
+
+// SetMeterValueRepository makes projections charge at the power measured
+// over the latest stored meter readings instead of the session average,
+// which overestimates the rate once the car tapers off
+func (s *Service) SetMeterValueRepository(repo ports.MeterValueRepository) {
+	s.meterValues = repo
+}
+
+// GetProjectedSessionCost estimates the final cost and completion time of the
+// active session, charging towards target when one is given
+func (s *Service) GetProjectedSessionCost(ctx context.Context, userID string, target domain.ChargeTarget) (*domain.SessionCostProjection, error) {
+	tx, err := s.repo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, domain.ErrNoActiveSession
+	}
+
+	return projectSessionCost(tx, target, s.pricing, s.stationZone(ctx, tx.ChargePointID), s.meterSamples(ctx, tx.ID), time.Now()), nil
+}
+
+// meterSamples returns the stored samples of a session, none when they are
+// not kept or cannot be loaded
+func (s *Service) meterSamples(ctx context.Context, txID string) []domain.MeterSample {
+	if s.meterValues == nil {
+		return nil
+	}
+	samples, err := s.meterValues.FindByTransactionID(ctx, txID)
+	if err != nil {
+		s.log.Warn("Failed to load meter values for projection", zap.String("tx_id", txID), zap.Error(err))
+		return nil
+	}
+	return samples
+}
+
+// stationZone returns the time zone of a session's station, the server's
+// when it is unknown. Peak hours are priced in the station's local time, as
+// billing does.
+func (s *Service) stationZone(ctx context.Context, chargePointID string) *time.Location {
+	cp, err := s.deviceService.GetDevice(ctx, chargePointID)
+	if err != nil || cp == nil {
+		return time.Local
+	}
+	return cp.TimeZone()
+}
+
+// projectSessionCost extrapolates a session from its meter readings: the
+// latest register value on the transaction (MeterStop, stamped by UpdatedAt)
+// and the stored samples, oldest first. The power is measured over the last
+// samples, or is the average between start and the latest reading when there
+// are too few of them. Without usable readings the time-based estimate is returned as both the
+// current and the projected figures, flagged as low confidence. The tariff
+// is that of the start hour in zone, the station's time zone.
+func projectSessionCost(tx *domain.Transaction, target domain.ChargeTarget, pricing *PricingConfig, zone *time.Location, samples []domain.MeterSample, now time.Time) *domain.SessionCostProjection {
+	rate := rateAt(pricing, tx.StartTime.In(zone))
+	currency := tx.Currency
+	if currency == "" {
+		currency = pricing.Currency
+	}
+
+	p := &domain.SessionCostProjection{
+		TransactionID: tx.ID,
+		RatePerKWh:    rate,
+		Currency:      currency,
+	}
+
+	readingAt := tx.UpdatedAt
+	if readingAt.IsZero() || readingAt.After(now) {
+		readingAt = now
+	}
+	metered := readingAt.Sub(tx.StartTime)
+	energyKWh := float64(tx.MeterStop-tx.MeterStart) / 1000.0
+
+	if energyKWh <= 0 || metered < minProjectionWindow {
+		elapsed := now.Sub(tx.StartTime)
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		p.CurrentEnergyKWh = elapsed.Hours() * assumedPowerKW
+		p.CurrentCost = p.CurrentEnergyKWh * rate
+		p.ProjectedEnergyKWh = p.CurrentEnergyKWh
+		p.ProjectedCost = p.CurrentCost
+		p.LowConfidence = true
+		return p
+	}
+
+	p.PowerKW = energyKWh / metered.Hours()
+	if powerKW, ok := recentPowerKW(samples); ok {
+		p.PowerKW = powerKW
+	}
+	p.CurrentEnergyKWh = energyKWh
+	p.CurrentCost = energyKWh * rate
+	p.ProjectedEnergyKWh = energyKWh
+	p.ProjectedCost = p.CurrentCost
+
+	targetKWh := targetEnergyKWh(target, energyKWh)
+	if targetKWh <= 0 {
+		return p
+	}
+	if targetKWh <= energyKWh {
+		p.EstimatedCompletion = &now
+		return p
+	}
+
+	remaining := time.Duration((targetKWh - energyKWh) / p.PowerKW * float64(time.Hour))
+	completion := readingAt.Add(remaining)
+	p.ProjectedEnergyKWh = targetKWh
+	p.ProjectedCost = targetKWh * rate
+	p.EstimatedCompletion = &completion
+
+	return p
+}
+
+// recentPowerKW returns the average power over the last recentPowerSamples
+// readings of the energy register. It reports false with fewer readings, or
+// when they show no energy flowing to extrapolate from.
+func recentPowerKW(samples []domain.MeterSample) (float64, bool) {
+	var register []domain.MeterSample
+	for _, sample := range samples {
+		if sample.Measurand == domain.MeasurandEnergyActiveImportRegister && sample.Phase == "" {
+			register = append(register, sample)
+		}
+	}
+	if len(register) < recentPowerSamples {
+		return 0, false
+	}
+	register = register[len(register)-recentPowerSamples:]
+
+	first, last := register[0], register[len(register)-1]
+	hours := last.Timestamp.Sub(first.Timestamp).Hours()
+	energyKWh := (registerWh(last) - registerWh(first)) / 1000.0
+	if hours <= 0 || energyKWh <= 0 {
+		return 0, false
+	}
+	return energyKWh / hours, true
+}
+
+// registerWh returns an energy register reading in Wh, the default unit
+func registerWh(sample domain.MeterSample) float64 {
+	if strings.EqualFold(sample.Unit, "kWh") {
+		return sample.Value * 1000
+	}
+	return sample.Value
+}
+
+// targetEnergyKWh returns the total session energy implied by target, or 0
+// when no target was given. A SOC target adds the energy still needed on top
+// of what has already been delivered.
+func targetEnergyKWh(target domain.ChargeTarget, deliveredKWh float64) float64 {
+	if target.EnergyKWh > 0 {
+		return target.EnergyKWh
+	}
+	if target.BatteryCapacityKWh > 0 && target.TargetSOC > target.CurrentSOC {
+		return deliveredKWh + (target.TargetSOC-target.CurrentSOC)/100.0*target.BatteryCapacityKWh
+	}
+	return 0
+}
+
+// currentSessionCost prices the energy metered so far at the session's
+// tariff, that of its start hour in zone. Before the first meter reading the
+// energy is assumed from the elapsed time, and the cost is flagged as an
+// estimate.
+func currentSessionCost(tx *domain.Transaction, pricing *PricingConfig, zone *time.Location, now time.Time) *domain.SessionCost {
+	c := &domain.SessionCost{
+		TransactionID: tx.ID,
+		RatePerKWh:    rateAt(pricing, tx.StartTime.In(zone)),
+		Currency:      tx.Currency,
+	}
+	if c.Currency == "" {
+		c.Currency = pricing.Currency
+	}
+
+	if tx.MeterStop > 0 {
+		c.EnergyKWh = float64(tx.MeterStop-tx.MeterStart) / 1000.0
+	} else {
+		elapsed := now.Sub(tx.StartTime)
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		c.EnergyKWh = elapsed.Hours() * assumedPowerKW
+		c.Estimated = true
+	}
+	c.Cost = c.EnergyKWh * c.RatePerKWh
+	return c
+}
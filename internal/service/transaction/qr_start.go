@@ -0,0 +1,88 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// SetSessionStarter enables starting sessions from a scanned connector QR
+// code, by asking the station to start charging
+func (s *Service) SetSessionStarter(starter ports.SessionStarter) {
+	s.starter = starter
+}
+
+// StartFromQR starts a session for userID on the connector whose QR code
+// they scanned, and asks the station to start charging for them. The
+// station's report that the session started is matched to this session.
+// When the station cannot be asked to start, the session is abandoned.
+func (s *Service) StartFromQR(ctx context.Context, userID, code string) (*domain.Transaction, error) {
+	if s.starter == nil {
+		return nil, errors.New("remote start is not configured")
+	}
+
+	qr, err := domain.ParseConnectorQR(code)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := s.deviceService.GetDevice(ctx, qr.StationID)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownConnector, qr)
+	}
+	status, ok := device.ConnectorStatus(qr.ConnectorID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownConnector, qr)
+	}
+	if status != domain.ChargePointStatusAvailable {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrConnectorUnavailable, qr, status)
+	}
+
+	tx, err := s.StartTransaction(ctx, qr.StationID, qr.ConnectorID, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.starter.StartSession(ctx, qr.StationID, qr.ConnectorID, userID); err != nil {
+		s.abandon(ctx, tx)
+		return nil, fmt.Errorf("failed to start charging: %w", err)
+	}
+
+	s.log.Info("Session started from QR code",
+		zap.String("tx_id", tx.ID),
+		zap.String("user_id", userID),
+		zap.String("connector", qr.String()),
+	)
+	return tx, nil
+}
+
+// abandon closes a session its station never started, without billing it,
+// and frees its connector
+func (s *Service) abandon(ctx context.Context, tx *domain.Transaction) {
+	s.releaseHold(ctx, tx)
+	if err := tx.TransitionTo(domain.TransactionStatusFaulted); err != nil {
+		s.log.Warn("Failed to abandon transaction", zap.String("tx_id", tx.ID), zap.Error(err))
+		return
+	}
+	now := time.Now()
+	tx.EndTime = &now
+	tx.UpdatedAt = now
+	if err := s.repo.Update(ctx, tx); err != nil {
+		s.log.Error("Failed to abandon transaction", zap.String("tx_id", tx.ID), zap.Error(err))
+		return
+	}
+	s.active.remove(tx.ID)
+
+	if err := s.deviceService.UpdateConnectorStatus(ctx, tx.ChargePointID, tx.ConnectorID, domain.ChargePointStatusAvailable); err != nil {
+		s.log.Warn("Failed to update device status", zap.Error(err))
+	}
+}
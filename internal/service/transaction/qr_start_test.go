@@ -0,0 +1,159 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// recordingStarter records the sessions it was asked to start
+type recordingStarter struct {
+	err     error
+	started []domain.ConnectorQR
+}
+
+func (r *recordingStarter) StartSession(ctx context.Context, chargePointID string, evseID int, userID string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.started = append(r.started, domain.ConnectorQR{StationID: chargePointID, ConnectorID: evseID})
+	return nil
+}
+
+// scanStation is CP-001 with connector 1 busy and connector 2 free
+func scanStation(ctx context.Context, id string) (*domain.ChargePoint, error) {
+	if id != "CP-001" {
+		return nil, nil
+	}
+	return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable, Connectors: []domain.Connector{
+		{ConnectorID: 1, Status: domain.ChargePointStatusOccupied},
+		{ConnectorID: 2, Status: domain.ChargePointStatusAvailable},
+	}}, nil
+}
+
+func TestStartFromQR(t *testing.T) {
+	refused := errors.New("remote start Rejected by charge point")
+
+	tests := []struct {
+		name        string
+		qr          string
+		starterErr  error
+		wantErr     error
+		wantStarted bool
+	}{
+		{"starts on the scanned connector", "CP-001:2", nil, nil, true},
+		{"unknown station", "CP-999:1", nil, domain.ErrUnknownConnector, false},
+		{"unknown connector", "CP-001:7", nil, domain.ErrUnknownConnector, false},
+		{"malformed code", "CP-001", nil, domain.ErrValidation, false},
+		{"busy connector", "CP-001:1", nil, domain.ErrConnectorUnavailable, false},
+		{"station refuses", "CP-001:2", refused, refused, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			txRepo := memory.NewTransactionRepository()
+			devices := &mocks.MockDeviceService{GetDeviceFunc: scanStation}
+			starter := &recordingStarter{err: tt.starterErr}
+			service := NewService(txRepo, devices, mocks.NewMockMessageQueue(), newTestLogger()).(*Service)
+			service.SetSessionStarter(starter)
+
+			// Act
+			tx, err := service.StartFromQR(ctx, "user-1", tt.qr)
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got := len(starter.started) == 1; got != tt.wantStarted {
+				t.Fatalf("expected station asked to start %v, got %v", tt.wantStarted, starter.started)
+			}
+			active, _ := txRepo.FindActiveByUserID(ctx, "user-1")
+			if !tt.wantStarted {
+				if active != nil {
+					t.Errorf("expected no active session, got %s", active.Status)
+				}
+				return
+			}
+			if starter.started[0] != (domain.ConnectorQR{StationID: "CP-001", ConnectorID: 2}) {
+				t.Errorf("expected a start on CP-001:2, got %s", starter.started[0])
+			}
+			if tx == nil || active == nil || active.ID != tx.ID || tx.ConnectorID != 2 {
+				t.Errorf("expected the returned session to be active on connector 2, got %+v", tx)
+			}
+		})
+	}
+}
+
+func TestStartFromQR_StationRefusesFreesConnector(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	txRepo := memory.NewTransactionRepository()
+	var connectorStatus domain.ChargePointStatus
+	devices := &mocks.MockDeviceService{
+		GetDeviceFunc: scanStation,
+		UpdateConnectorStatusFunc: func(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error {
+			connectorStatus = status
+			return nil
+		},
+	}
+	service := NewService(txRepo, devices, mocks.NewMockMessageQueue(), newTestLogger()).(*Service)
+	service.SetSessionStarter(&recordingStarter{err: domain.ErrChargePointNotConnected})
+
+	// Act
+	_, err := service.StartFromQR(ctx, "user-1", "CP-001:2")
+
+	// Assert
+	if !errors.Is(err, domain.ErrChargePointNotConnected) {
+		t.Fatalf("expected ErrChargePointNotConnected, got %v", err)
+	}
+	history, _ := txRepo.FindHistoryByUserID(ctx, "user-1")
+	if len(history) != 1 || history[0].Status != domain.TransactionStatusFaulted || history[0].Cost != 0 {
+		t.Fatalf("expected one unbilled Faulted session, got %+v", history)
+	}
+	if connectorStatus != domain.ChargePointStatusAvailable {
+		t.Errorf("expected the connector to be Available again, got %s", connectorStatus)
+	}
+	if sessions, _ := service.ListActiveSessions(ctx); len(sessions) != 0 {
+		t.Errorf("expected no live session, got %d", len(sessions))
+	}
+}
+
+func TestStartTransaction_StationReportsScannedSession(t *testing.T) {
+	// Arrange - once the scan opened the session the connector is no longer
+	// Available; the station's Started report must still find it
+	ctx := context.Background()
+	txRepo := memory.NewTransactionRepository()
+	station := &domain.ChargePoint{ID: "CP-001", Status: domain.ChargePointStatusAvailable}
+	devices := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return station, nil
+		},
+		UpdateConnectorStatusFunc: func(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error {
+			station.Status = status
+			return nil
+		},
+	}
+	service := NewService(txRepo, devices, mocks.NewMockMessageQueue(), newTestLogger()).(*Service)
+	service.SetSessionStarter(&recordingStarter{})
+	scanned, err := service.StartFromQR(ctx, "user-1", "CP-001:1")
+	if err != nil {
+		t.Fatalf("expected the scan to start a session, got %v", err)
+	}
+
+	// Act
+	reported, err := service.StartTransaction(ctx, "CP-001", 1, "user-1", "user-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reported.ID != scanned.ID {
+		t.Errorf("expected the scanned session %s, got %s", scanned.ID, reported.ID)
+	}
+}
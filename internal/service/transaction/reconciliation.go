@@ -0,0 +1,216 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// AlertTypeBillingDiscrepancy marks alerts raised when our billed energy and
+// the station's meter disagree
+const AlertTypeBillingDiscrepancy = "billing_discrepancy"
+
+// reconciliationPageSize is how many transactions are read per page
+const reconciliationPageSize = 100
+
+// ReconciliationConfig holds reconciliation settings. A transaction matches
+// when the difference is within the larger of the two tolerances.
+type ReconciliationConfig struct {
+	TolerancePercent float64       // Allowed difference relative to the station total
+	ToleranceWh      int           // Allowed absolute difference, for short sessions
+	Lookback         time.Duration // How far back completed transactions are checked
+}
+
+// DefaultReconciliationConfig allows 1% or 100 Wh and looks back 7 days
+func DefaultReconciliationConfig() ReconciliationConfig {
+	return ReconciliationConfig{
+		TolerancePercent: 1,
+		ToleranceWh:      100,
+		Lookback:         7 * 24 * time.Hour,
+	}
+}
+
+// ReconciliationService compares the energy billed for finished transactions
+// with the session total reported by the station
+type ReconciliationService struct {
+	txRepo  ports.TransactionRepository
+	recRepo ports.ReconciliationRepository
+	alerts  ports.AlertRepository
+	config  ReconciliationConfig
+	log     *zap.Logger
+}
+
+// NewReconciliationService creates a new reconciliation service. Without an
+// alert repository discrepancies are only recorded and logged.
+func NewReconciliationService(
+	txRepo ports.TransactionRepository,
+	recRepo ports.ReconciliationRepository,
+	alerts ports.AlertRepository,
+	config ReconciliationConfig,
+	log *zap.Logger,
+) *ReconciliationService {
+	defaults := DefaultReconciliationConfig()
+	if config.TolerancePercent < 0 {
+		config.TolerancePercent = defaults.TolerancePercent
+	}
+	if config.ToleranceWh < 0 {
+		config.ToleranceWh = defaults.ToleranceWh
+	}
+	if config.Lookback <= 0 {
+		config.Lookback = defaults.Lookback
+	}
+
+	return &ReconciliationService{
+		txRepo:  txRepo,
+		recRepo: recRepo,
+		alerts:  alerts,
+		config:  config,
+		log:     log,
+	}
+}
+
+// RunOnce reconciles every finished transaction in the lookback window that
+// has no reconciliation yet and returns how many discrepancies were found
+func (s *ReconciliationService) RunOnce(ctx context.Context) (int, error) {
+	since := time.Now().Add(-s.config.Lookback)
+	discrepancies := 0
+
+	for _, status := range []domain.TransactionStatus{domain.TransactionStatusStopped, domain.TransactionStatusCompleted} {
+		filter := ports.TransactionFilter{Status: string(status), StartDate: since}
+		var after *ports.TransactionCursor
+		for {
+			page, err := s.txRepo.FindPage(ctx, filter, after, reconciliationPageSize)
+			if err != nil {
+				return discrepancies, fmt.Errorf("failed to list transactions: %w", err)
+			}
+			for i := range page {
+				rec, err := s.reconcile(ctx, &page[i])
+				if err != nil {
+					return discrepancies, err
+				}
+				if rec != nil && rec.Status == domain.ReconciliationStatusDiscrepancy {
+					discrepancies++
+				}
+			}
+			if len(page) < reconciliationPageSize {
+				break
+			}
+			last := page[len(page)-1]
+			after = &ports.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+	}
+
+	return discrepancies, nil
+}
+
+// Start runs RunOnce every interval until ctx is done
+func (s *ReconciliationService) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.RunOnce(ctx); err != nil {
+					s.log.Error("Transaction reconciliation failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// reconcile checks one transaction. It returns nil if the transaction was
+// already reconciled.
+func (s *ReconciliationService) reconcile(ctx context.Context, tx *domain.Transaction) (*domain.Reconciliation, error) {
+	existing, err := s.recRepo.FindByTransactionID(ctx, tx.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reconciliation: %w", err)
+	}
+	if existing != nil {
+		return nil, nil
+	}
+
+	rec := &domain.Reconciliation{
+		ID:               uuid.New().String(),
+		TransactionID:    tx.ID,
+		ChargePointID:    tx.ChargePointID,
+		ComputedEnergyWh: tx.TotalEnergy,
+		StationEnergyWh:  tx.StationEnergy,
+		DifferenceWh:     tx.TotalEnergy - tx.StationEnergy,
+		ToleranceWh:      s.toleranceWh(tx.StationEnergy),
+		CreatedAt:        time.Now(),
+	}
+	switch {
+	case tx.StationEnergy <= 0:
+		rec.Status = domain.ReconciliationStatusUnavailable
+	case abs(rec.DifferenceWh) > rec.ToleranceWh:
+		rec.Status = domain.ReconciliationStatusDiscrepancy
+	default:
+		rec.Status = domain.ReconciliationStatusMatched
+	}
+
+	if err := s.recRepo.Save(ctx, rec); err != nil {
+		return nil, fmt.Errorf("failed to save reconciliation: %w", err)
+	}
+
+	if rec.Status == domain.ReconciliationStatusDiscrepancy {
+		s.log.Warn("Billing discrepancy",
+			zap.String("txID", tx.ID),
+			zap.String("chargePointID", tx.ChargePointID),
+			zap.Int("computedWh", rec.ComputedEnergyWh),
+			zap.Int("stationWh", rec.StationEnergyWh),
+			zap.Int("toleranceWh", rec.ToleranceWh),
+		)
+		if err := s.raiseAlert(ctx, rec); err != nil {
+			return nil, err
+		}
+	}
+
+	return rec, nil
+}
+
+// toleranceWh is the allowed difference for a session of stationWh
+func (s *ReconciliationService) toleranceWh(stationWh int) int {
+	relative := int(math.Round(float64(stationWh) * s.config.TolerancePercent / 100))
+	if relative > s.config.ToleranceWh {
+		return relative
+	}
+	return s.config.ToleranceWh
+}
+
+func (s *ReconciliationService) raiseAlert(ctx context.Context, rec *domain.Reconciliation) error {
+	if s.alerts == nil {
+		return nil
+	}
+	alert := &ports.Alert{
+		ID:       uuid.New().String(),
+		Type:     AlertTypeBillingDiscrepancy,
+		Severity: "warning",
+		Title:    fmt.Sprintf("Energy mismatch on %s", rec.ChargePointID),
+		Message: fmt.Sprintf("Transaction %s billed %d Wh but the station metered %d Wh (tolerance %d Wh)",
+			rec.TransactionID, rec.ComputedEnergyWh, rec.StationEnergyWh, rec.ToleranceWh),
+		Source:    "transaction",
+		SourceID:  rec.TransactionID,
+		CreatedAt: rec.CreatedAt,
+	}
+	if err := s.alerts.Save(ctx, alert); err != nil {
+		return fmt.Errorf("failed to save discrepancy alert: %w", err)
+	}
+	return nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
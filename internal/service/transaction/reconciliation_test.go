@@ -0,0 +1,131 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+func TestReconciliation_RunOnce(t *testing.T) {
+	tests := []struct {
+		name            string
+		totalEnergy     int
+		stationEnergy   int
+		wantStatus      domain.ReconciliationStatus
+		wantToleranceWh int
+		wantAlert       bool
+	}{
+		{name: "matching totals", totalEnergy: 20000, stationEnergy: 20000, wantStatus: domain.ReconciliationStatusMatched, wantToleranceWh: 200},
+		{name: "within percent tolerance", totalEnergy: 20150, stationEnergy: 20000, wantStatus: domain.ReconciliationStatusMatched, wantToleranceWh: 200},
+		{name: "within absolute tolerance", totalEnergy: 1080, stationEnergy: 1000, wantStatus: domain.ReconciliationStatusMatched, wantToleranceWh: 100},
+		{name: "mismatching totals", totalEnergy: 21000, stationEnergy: 20000, wantStatus: domain.ReconciliationStatusDiscrepancy, wantToleranceWh: 200, wantAlert: true},
+		{name: "under-billed", totalEnergy: 18000, stationEnergy: 20000, wantStatus: domain.ReconciliationStatusDiscrepancy, wantToleranceWh: 200, wantAlert: true},
+		{name: "no station total", totalEnergy: 20000, stationEnergy: 0, wantStatus: domain.ReconciliationStatusUnavailable, wantToleranceWh: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			txRepo := &mocks.MockTransactionRepository{
+				FindPageFunc: func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+					if filter.Status != string(domain.TransactionStatusCompleted) {
+						return nil, nil
+					}
+					return []domain.Transaction{{
+						ID:            "tx-1",
+						ChargePointID: "CP-001",
+						Status:        domain.TransactionStatusCompleted,
+						TotalEnergy:   tt.totalEnergy,
+						StationEnergy: tt.stationEnergy,
+					}}, nil
+				},
+			}
+			var saved *domain.Reconciliation
+			recRepo := &mocks.MockReconciliationRepository{
+				SaveFunc: func(ctx context.Context, rec *domain.Reconciliation) error {
+					saved = rec
+					return nil
+				},
+			}
+			var raised []*ports.Alert
+			alerts := &mocks.MockAlertRepository{
+				SaveFunc: func(ctx context.Context, alert *ports.Alert) error {
+					raised = append(raised, alert)
+					return nil
+				},
+			}
+			service := NewReconciliationService(txRepo, recRepo, alerts, ReconciliationConfig{TolerancePercent: 1, ToleranceWh: 100}, zap.NewNop())
+
+			// Act
+			count, err := service.RunOnce(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if saved == nil {
+				t.Fatal("expected a reconciliation record")
+			}
+			if saved.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, saved.Status)
+			}
+			if saved.ToleranceWh != tt.wantToleranceWh {
+				t.Errorf("expected tolerance %d Wh, got %d", tt.wantToleranceWh, saved.ToleranceWh)
+			}
+			if saved.DifferenceWh != tt.totalEnergy-tt.stationEnergy {
+				t.Errorf("expected difference %d Wh, got %d", tt.totalEnergy-tt.stationEnergy, saved.DifferenceWh)
+			}
+			if tt.wantAlert {
+				if count != 1 || len(raised) != 1 {
+					t.Fatalf("expected 1 discrepancy and alert, got %d and %d", count, len(raised))
+				}
+				if raised[0].Type != AlertTypeBillingDiscrepancy || raised[0].SourceID != "tx-1" {
+					t.Errorf("expected a billing discrepancy alert for tx-1, got %s %s", raised[0].Type, raised[0].SourceID)
+				}
+			} else if count != 0 || len(raised) != 0 {
+				t.Errorf("expected no discrepancy or alert, got %d and %d", count, len(raised))
+			}
+		})
+	}
+}
+
+func TestReconciliation_SkipsReconciledTransactions(t *testing.T) {
+	// Arrange
+	txRepo := &mocks.MockTransactionRepository{
+		FindPageFunc: func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+			return []domain.Transaction{{ID: "tx-1", TotalEnergy: 30000, StationEnergy: 20000}}, nil
+		},
+	}
+	recRepo := &mocks.MockReconciliationRepository{
+		FindByTransactionIDFunc: func(ctx context.Context, transactionID string) (*domain.Reconciliation, error) {
+			return &domain.Reconciliation{TransactionID: transactionID, Status: domain.ReconciliationStatusDiscrepancy}, nil
+		},
+		SaveFunc: func(ctx context.Context, rec *domain.Reconciliation) error {
+			t.Error("expected no new reconciliation record")
+			return nil
+		},
+	}
+	alerts := &mocks.MockAlertRepository{
+		SaveFunc: func(ctx context.Context, alert *ports.Alert) error {
+			t.Error("expected no repeated alert")
+			return nil
+		},
+	}
+	service := NewReconciliationService(txRepo, recRepo, alerts, DefaultReconciliationConfig(), zap.NewNop())
+
+	// Act
+	count, err := service.RunOnce(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no new discrepancies, got %d", count)
+	}
+}
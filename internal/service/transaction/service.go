@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/google/uuid"
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
 	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
@@ -22,18 +23,30 @@ const (
 )
 
 type Service struct {
-	repo          ports.TransactionRepository
-	deviceService ports.DeviceService
-	mq            queue.MessageQueue
-	log           *zap.Logger
+	repo             ports.TransactionRepository
+	deviceService    ports.DeviceService
+	mq               queue.MessageQueue
+	pricing          *PricingConfig
+	active           *activeSessions
+	stationSelection domain.StationSelectionStrategy // see SetStationSelection
+	stationPrices    ports.StationPriceSource        // optional, see SetStationPriceSource
+	holds            *paymentHolds                   // optional, see SetPaymentHolds
+	starter          ports.SessionStarter            // optional, see SetSessionStarter
+	stopLocks        ports.Locker                    // one stop at a time per transaction, see SetLocker
+	meterValues      ports.MeterValueRepository      // optional, see SetMeterValueRepository
+	log              *zap.Logger
 }
 
 func NewService(repo ports.TransactionRepository, deviceService ports.DeviceService, mq queue.MessageQueue, log *zap.Logger) ports.TransactionService {
 	return &Service{
-		repo:          repo,
-		deviceService: deviceService,
-		mq:            mq,
-		log:           log,
+		repo:             repo,
+		deviceService:    deviceService,
+		mq:               mq,
+		pricing:          DefaultPricingConfig(),
+		active:           newActiveSessions(),
+		stopLocks:        cache.NewLocalLocker(),
+		stationSelection: domain.StationSelectionFirstAvailable,
+		log:              log,
 	}
 }
 
@@ -47,15 +60,27 @@ func (s *Service) StartTransaction(ctx context.Context, deviceID string, connect
 		return nil, errors.New("device not found")
 	}
 
+	// Check if user already has an active transaction. A retried start on
+	// the same connector gets the session it already started, as does the
+	// station reporting a session started remotely, whose connector is no
+	// longer Available.
+	existingTx, _ := s.repo.FindActiveByUserID(ctx, userID)
+	if existingTx != nil {
+		if existingTx.ChargePointID == deviceID && existingTx.ConnectorID == connectorID {
+			return existingTx, nil
+		}
+		return nil, errors.New("user already has an active charging session")
+	}
+
 	// Check if device is available
 	if device.Status != domain.ChargePointStatusAvailable {
 		return nil, fmt.Errorf("device is not available, current status: %s", device.Status)
 	}
 
-	// Check if user already has an active transaction
-	existingTx, _ := s.repo.FindActiveByUserID(ctx, userID)
-	if existingTx != nil {
-		return nil, errors.New("user already has an active charging session")
+	// The status check above may be stale; only the session that moves the
+	// connector from Available to Reserved goes ahead
+	if err := s.deviceService.ReserveConnector(ctx, deviceID, connectorID); err != nil {
+		return nil, err
 	}
 
 	// Create transaction
@@ -72,12 +97,24 @@ func (s *Service) StartTransaction(ctx context.Context, deviceID string, connect
 		UpdatedAt:     time.Now(),
 	}
 
+	if err := s.placeHold(ctx, tx); err != nil {
+		if releaseErr := s.deviceService.ReleaseConnector(ctx, deviceID, connectorID); releaseErr != nil {
+			s.log.Warn("Failed to release connector", zap.Error(releaseErr))
+		}
+		return nil, err
+	}
+
 	if err := s.repo.Save(ctx, tx); err != nil {
+		s.releaseHold(ctx, tx)
+		if releaseErr := s.deviceService.ReleaseConnector(ctx, deviceID, connectorID); releaseErr != nil {
+			s.log.Warn("Failed to release connector", zap.Error(releaseErr))
+		}
 		return nil, err
 	}
+	s.active.put(*tx)
 
-	// Update device status to Occupied
-	if err := s.deviceService.UpdateStatus(ctx, deviceID, domain.ChargePointStatusOccupied); err != nil {
+	// Update connector status to Occupied; the device status follows
+	if err := s.deviceService.UpdateConnectorStatus(ctx, deviceID, connectorID, domain.ChargePointStatusOccupied); err != nil {
 		s.log.Warn("Failed to update device status", zap.Error(err))
 	}
 
@@ -106,6 +143,12 @@ func (s *Service) StartTransaction(ctx context.Context, deviceID string, connect
 }
 
 func (s *Service) StopTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+	unlock, err := s.lockStop(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	tx, err := s.repo.FindByID(ctx, transactionID)
 	if err != nil {
 		return nil, err
@@ -114,13 +157,12 @@ func (s *Service) StopTransaction(ctx context.Context, transactionID string) (*d
 		return nil, errors.New("transaction not found")
 	}
 
-	if tx.Status != domain.TransactionStatusStarted {
-		return nil, fmt.Errorf("transaction is not active, current status: %s", tx.Status)
+	if err := tx.TransitionTo(domain.TransactionStatusStopped); err != nil {
+		return nil, err
 	}
 
 	now := time.Now()
 	tx.EndTime = &now
-	tx.Status = domain.TransactionStatusStopped
 	tx.UpdatedAt = now
 
 	// Calculate energy and cost
@@ -132,9 +174,10 @@ func (s *Service) StopTransaction(ctx context.Context, transactionID string) (*d
 	if err := s.repo.Update(ctx, tx); err != nil {
 		return nil, err
 	}
+	s.active.remove(tx.ID)
 
-	// Update device status to Available
-	if err := s.deviceService.UpdateStatus(ctx, tx.ChargePointID, domain.ChargePointStatusAvailable); err != nil {
+	// Update connector status to Available; the device status follows
+	if err := s.deviceService.UpdateConnectorStatus(ctx, tx.ChargePointID, tx.ConnectorID, domain.ChargePointStatusAvailable); err != nil {
 		s.log.Warn("Failed to update device status", zap.Error(err))
 	}
 
@@ -168,6 +211,51 @@ func (s *Service) StopTransaction(ctx context.Context, transactionID string) (*d
 	return tx, nil
 }
 
+// ResumeTransaction reopens a session we stopped while its station kept
+// charging, e.g. because the Ended event we acted on was not the station's.
+// Energy and cost are worked out again when the session really stops.
+func (s *Service) ResumeTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+	tx, err := s.repo.FindByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, domain.ErrTransactionNotFound
+	}
+
+	if err := tx.Reopen(); err != nil {
+		return nil, err
+	}
+	tx.EndTime = nil
+	tx.TotalEnergy = 0
+	tx.Cost = 0
+	tx.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, tx); err != nil {
+		return nil, err
+	}
+	s.active.put(*tx)
+
+	if err := s.deviceService.UpdateConnectorStatus(ctx, tx.ChargePointID, tx.ConnectorID, domain.ChargePointStatusOccupied); err != nil {
+		s.log.Warn("Failed to update device status", zap.Error(err))
+	}
+
+	s.log.Info("Transaction resumed",
+		zap.String("tx_id", tx.ID),
+		zap.String("device_id", tx.ChargePointID),
+	)
+
+	return tx, nil
+}
+
+// StartedTransactions returns the station's started transactions as stored
+func (s *Service) StartedTransactions(ctx context.Context, chargePointID string) ([]domain.Transaction, error) {
+	return s.repo.FindPage(ctx, ports.TransactionFilter{
+		Status:        string(domain.TransactionStatusStarted),
+		ChargePointID: chargePointID,
+	}, nil, stationSessionLimit)
+}
+
 func (s *Service) GetTransaction(ctx context.Context, id string) (*domain.Transaction, error) {
 	return s.repo.FindByID(ctx, id)
 }
@@ -180,51 +268,146 @@ func (s *Service) GetTransactionHistory(ctx context.Context, userID string) ([]d
 	return s.repo.FindHistoryByUserID(ctx, userID)
 }
 
-// StartCharging starts a charging session for the voice assistant
-// It finds an available connector on the specified station
-func (s *Service) StartCharging(ctx context.Context, userID string, stationID string) (*domain.Transaction, error) {
-	// If no station specified, find the nearest available one
-	if stationID == "" {
-		availableDevices, err := s.deviceService.ListAvailableDevices(ctx)
-		if err != nil || len(availableDevices) == 0 {
-			return nil, errors.New("no available charging stations found")
+// RecordTransactionEvent stores the seqNo of an applied OCPP TransactionEvent
+// and its energy register reading (meterWh, 0 when the event had none). The
+// first reading becomes the meter start. Events below the stored seqNo are
+// ignored so replays cannot move the meter backwards.
+func (s *Service) RecordTransactionEvent(ctx context.Context, transactionID string, seqNo int, meterWh int) error {
+	tx, err := s.repo.FindByID(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return errors.New("transaction not found")
+	}
+	if seqNo < tx.LastSeqNo {
+		return nil
+	}
+
+	tx.LastSeqNo = seqNo
+	if meterWh > 0 {
+		if tx.MeterStart == 0 && tx.MeterStop == 0 {
+			tx.MeterStart = meterWh
 		}
-		stationID = availableDevices[0].ID
+		tx.MeterStop = meterWh
+	}
+	tx.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, tx); err != nil {
+		return err
+	}
+	if tx.Status == domain.TransactionStatusStarted {
+		s.active.put(*tx)
+	}
+	return nil
+}
+
+// LinkOCPPTransaction stores the transactionId the station gave a session
+// and the EVSE it runs on
+func (s *Service) LinkOCPPTransaction(ctx context.Context, transactionID, ocppTransactionID string, evseID int) error {
+	tx, err := s.repo.FindByID(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return errors.New("transaction not found")
+	}
+	if tx.OCPPTransactionID == ocppTransactionID && tx.EvseID == evseID {
+		return nil
+	}
+
+	tx.OCPPTransactionID = ocppTransactionID
+	tx.EvseID = evseID
+	tx.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, tx); err != nil {
+		return err
+	}
+	if tx.Status == domain.TransactionStatusStarted {
+		s.active.put(*tx)
+	}
+	return nil
+}
+
+// FindByOCPPTransaction returns the session a station gave
+// ocppTransactionID, or nil
+func (s *Service) FindByOCPPTransaction(ctx context.Context, chargePointID, ocppTransactionID string) (*domain.Transaction, error) {
+	return s.repo.FindByOCPPTransactionID(ctx, chargePointID, ocppTransactionID)
+}
+
+// RecordStationEnergy stores the session total the station reported when the
+// transaction ended
+func (s *Service) RecordStationEnergy(ctx context.Context, transactionID string, energyWh int) error {
+	tx, err := s.repo.FindByID(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return errors.New("transaction not found")
+	}
+
+	tx.StationEnergy = energyWh
+	tx.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, tx)
+}
+
+// StartCharging starts a charging session for the voice assistant
+// It finds an available connector on the specified station, or picks a
+// station with the selection strategy when none is specified
+func (s *Service) StartCharging(ctx context.Context, userID string, stationID string, selection domain.StationSelection) (*domain.Transaction, error) {
+	if selection.Strategy != "" && !selection.Strategy.Valid() {
+		return nil, fmt.Errorf("%w: unknown station selection strategy %q", domain.ErrValidation, selection.Strategy)
+	}
+
+	// Use default connector 1 on a named station
+	if stationID != "" {
+		return s.StartTransaction(ctx, stationID, 1, userID, userID)
 	}
 
-	// Use default connector 1
-	return s.StartTransaction(ctx, stationID, 1, userID, userID)
+	availableDevices, err := s.deviceService.ListAvailableDevices(ctx)
+	if err != nil || len(availableDevices) == 0 {
+		return nil, errors.New("no available charging stations found")
+	}
+	candidate, ok := s.selectStation(ctx, availableDevices, selection)
+	if !ok {
+		return nil, errors.New("no available charging stations found")
+	}
+	return s.StartTransaction(ctx, candidate.device.ID, candidate.connectorID, userID, userID)
 }
 
-// StopActiveCharging stops the active charging session for a user
+// StopActiveCharging stops the active charging session for a user. It is
+// idempotent: a stop racing another one, or repeated shortly after the
+// session stopped, succeeds without billing the session again.
 func (s *Service) StopActiveCharging(ctx context.Context, userID string) error {
 	tx, err := s.repo.FindActiveByUserID(ctx, userID)
 	if err != nil {
 		return err
 	}
 	if tx == nil {
-		return errors.New("no active charging session found")
+		if s.stoppedRecently(ctx, userID, time.Now()) {
+			return nil
+		}
+		return domain.ErrNoActiveSession
 	}
 
 	_, err = s.StopTransaction(ctx, tx.ID)
+	var transitionErr *domain.TransitionError
+	if errors.As(err, &transitionErr) {
+		// A concurrent stop got there first
+		s.log.Debug("Session already stopped", zap.String("tx_id", tx.ID))
+		return nil
+	}
 	return err
 }
 
-// GetCurrentSessionCost returns the estimated cost of the active session
-func (s *Service) GetCurrentSessionCost(ctx context.Context, userID string) (float64, error) {
+// GetCurrentSessionCost returns what the active session has cost so far
+func (s *Service) GetCurrentSessionCost(ctx context.Context, userID string) (*domain.SessionCost, error) {
 	tx, err := s.repo.FindActiveByUserID(ctx, userID)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	if tx == nil {
-		return 0, errors.New("no active charging session found")
+		return nil, domain.ErrNoActiveSession
 	}
 
-	// Calculate estimated cost based on time elapsed
-	// In a real implementation, this would query the meter values
-	elapsed := time.Since(tx.StartTime)
-	estimatedKWh := elapsed.Hours() * 7.0 // Assume average 7kW charging rate
-	estimatedCost := estimatedKWh * defaultPricePerKWh
-
-	return estimatedCost, nil
+	return currentSessionCost(tx, s.pricing, s.stationZone(ctx, tx.ChargePointID), time.Now()), nil
 }
@@ -3,13 +3,20 @@ package transaction
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"sync"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/internal/service/device"
 )
 
 func newTestLogger() *zap.Logger {
@@ -313,6 +320,179 @@ func TestStopTransaction_AlreadyStopped(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
+	if !errors.Is(err, domain.ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition, got %v", err)
+	}
+}
+
+func TestStopTransaction_SharedLockBillsOnce(t *testing.T) {
+	// Arrange - two instances sharing the repository and the locker
+	var mu sync.Mutex
+	stored := domain.Transaction{ID: "tx-123", ChargePointID: "device-123", Status: domain.TransactionStatusStarted, MeterStop: 10000}
+	txRepo := &mocks.MockTransactionRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			copied := stored
+			return &copied, nil
+		},
+		UpdateFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			time.Sleep(10 * time.Millisecond) // widen the read-then-write window
+			mu.Lock()
+			defer mu.Unlock()
+			stored = *tx
+			return nil
+		},
+	}
+	locker := cache.NewLocalLocker()
+	queues := []*mocks.MockMessageQueue{mocks.NewMockMessageQueue(), mocks.NewMockMessageQueue()}
+	var wg sync.WaitGroup
+	for _, mq := range queues {
+		service := NewService(txRepo, &mocks.MockDeviceService{}, mq, zap.NewNop()).(*Service)
+		service.SetLocker(locker)
+		wg.Add(1)
+
+		// Act
+		go func() {
+			defer wg.Done()
+			service.StopTransaction(context.Background(), "tx-123")
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	billed := len(queues[0].GetPublishedMessages("billing.events")) + len(queues[1].GetPublishedMessages("billing.events"))
+	if billed != 1 {
+		t.Errorf("expected the session billed once, got %d", billed)
+	}
+}
+
+func TestResumeTransaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  domain.TransactionStatus
+		resumed bool
+	}{
+		{"stopped", domain.TransactionStatusStopped, true},
+		{"faulted", domain.TransactionStatusFaulted, true},
+		{"completed stays closed", domain.TransactionStatusCompleted, false},
+		{"already started", domain.TransactionStatusStarted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			end := time.Now()
+			tx := &domain.Transaction{ID: "tx-123", ChargePointID: "CP-001", Status: tt.status, EndTime: &end, TotalEnergy: 5000, Cost: 3.75}
+			var saved *domain.Transaction
+			mockTxRepo := &mocks.MockTransactionRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+					return tx, nil
+				},
+				UpdateFunc: func(ctx context.Context, tx *domain.Transaction) error {
+					saved = tx
+					return nil
+				},
+			}
+			var deviceStatus domain.ChargePointStatus
+			mockDeviceService := &mocks.MockDeviceService{
+				UpdateConnectorStatusFunc: func(ctx context.Context, id string, connectorID int, status domain.ChargePointStatus) error {
+					deviceStatus = status
+					return nil
+				},
+			}
+			service := NewService(mockTxRepo, mockDeviceService, mocks.NewMockMessageQueue(), newTestLogger())
+
+			// Act
+			_, err := service.(ports.TransactionResumer).ResumeTransaction(context.Background(), "tx-123")
+
+			// Assert
+			if !tt.resumed {
+				if !errors.Is(err, domain.ErrInvalidTransition) || saved != nil {
+					t.Errorf("expected ErrInvalidTransition and nothing saved, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if saved == nil || saved.Status != domain.TransactionStatusStarted || saved.EndTime != nil || saved.Cost != 0 {
+				t.Errorf("expected an open session, got %+v", saved)
+			}
+			if deviceStatus != domain.ChargePointStatusOccupied {
+				t.Errorf("expected the device to be occupied again, got %s", deviceStatus)
+			}
+		})
+	}
+}
+
+func TestTransactionStateMachine(t *testing.T) {
+	tests := []struct {
+		from  domain.TransactionStatus
+		to    domain.TransactionStatus
+		legal bool
+	}{
+		{domain.TransactionStatusStarted, domain.TransactionStatusStopped, true},
+		{domain.TransactionStatusStarted, domain.TransactionStatusFaulted, true},
+		{domain.TransactionStatusFaulted, domain.TransactionStatusStopped, true},
+		{domain.TransactionStatusFaulted, domain.TransactionStatusCompleted, true},
+		{domain.TransactionStatusStopped, domain.TransactionStatusCompleted, true},
+
+		{domain.TransactionStatusStarted, domain.TransactionStatusStarted, false},
+		{domain.TransactionStatusStarted, domain.TransactionStatusCompleted, false},
+		{domain.TransactionStatusStopped, domain.TransactionStatusStopped, false},
+		{domain.TransactionStatusStopped, domain.TransactionStatusStarted, false},
+		{domain.TransactionStatusStopped, domain.TransactionStatusFaulted, false},
+		{domain.TransactionStatusFaulted, domain.TransactionStatusStarted, false},
+		{domain.TransactionStatusFaulted, domain.TransactionStatusFaulted, false},
+		{domain.TransactionStatusCompleted, domain.TransactionStatusStarted, false},
+		{domain.TransactionStatusCompleted, domain.TransactionStatusStopped, false},
+		{domain.TransactionStatusCompleted, domain.TransactionStatusFaulted, false},
+		{domain.TransactionStatusCompleted, domain.TransactionStatusCompleted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			tx := &domain.Transaction{ID: "tx-1", Status: tt.from}
+
+			err := tx.TransitionTo(tt.to)
+
+			if tt.legal {
+				if err != nil {
+					t.Fatalf("expected transition to be allowed, got %v", err)
+				}
+				if tx.Status != tt.to {
+					t.Errorf("expected status %s, got %s", tt.to, tx.Status)
+				}
+				return
+			}
+
+			var transitionErr *domain.TransitionError
+			if !errors.As(err, &transitionErr) || !errors.Is(err, domain.ErrInvalidTransition) {
+				t.Fatalf("expected a TransitionError, got %v", err)
+			}
+			if transitionErr.From != tt.from || transitionErr.To != tt.to {
+				t.Errorf("expected error for %s -> %s, got %s -> %s", tt.from, tt.to, transitionErr.From, transitionErr.To)
+			}
+			if tx.Status != tt.from {
+				t.Errorf("expected status to stay %s, got %s", tt.from, tx.Status)
+			}
+		})
+	}
+}
+
+func TestProcessPayment_AlreadyCompleted(t *testing.T) {
+	// Arrange
+	billing := NewBillingService(&mocks.MockTransactionRepository{}, nil, nil, zap.NewNop())
+	tx := &domain.Transaction{ID: "tx-123", Status: domain.TransactionStatusCompleted}
+
+	// Act
+	err := billing.ProcessPayment(context.Background(), tx)
+
+	// Assert
+	if !errors.Is(err, domain.ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition when paying twice, got %v", err)
+	}
 }
 
 func TestGetTransaction_Success(t *testing.T) {
@@ -465,7 +645,7 @@ func TestStartCharging_Success(t *testing.T) {
 	service := NewService(mockTxRepo, mockDeviceService, mockQueue, newTestLogger())
 
 	// Act
-	tx, err := service.StartCharging(ctx, userID, stationID)
+	tx, err := service.StartCharging(ctx, userID, stationID, domain.StationSelection{})
 
 	// Assert
 	if err != nil {
@@ -512,7 +692,7 @@ func TestStartCharging_NoStationSpecified_FindsAvailable(t *testing.T) {
 	service := NewService(mockTxRepo, mockDeviceService, mockQueue, newTestLogger())
 
 	// Act
-	tx, err := service.StartCharging(ctx, userID, "") // Empty station ID
+	tx, err := service.StartCharging(ctx, userID, "", domain.StationSelection{}) // Empty station ID
 
 	// Assert
 	if err != nil {
@@ -594,6 +774,108 @@ func TestStopActiveCharging_NoActiveSession(t *testing.T) {
 	}
 }
 
+// Run with -race: a double tap must bill the session once
+func TestStopActiveCharging_ConcurrentStopsBillOnce(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockQueue := mocks.NewMockMessageQueue()
+	service := NewService(memory.NewTransactionRepository(), availableDevices(), mockQueue, zap.NewNop())
+	if _, err := service.StartTransaction(ctx, "CP-A", 1, "user-1", "rfid"); err != nil {
+		t.Fatalf("failed to start transaction: %v", err)
+	}
+
+	const stops = 2
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, stops)
+
+	// Act
+	for i := 0; i < stops; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- service.StopActiveCharging(ctx, "user-1")
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		if err != nil {
+			t.Errorf("expected every stop to succeed, got %v", err)
+		}
+	}
+	if billed := mockQueue.GetPublishedMessages("billing.events"); len(billed) != 1 {
+		t.Errorf("expected one billing event, got %d", len(billed))
+	}
+}
+
+func TestStopActiveCharging_AfterSessionStopped(t *testing.T) {
+	tests := []struct {
+		name    string
+		endedAt time.Duration
+		wantErr error
+	}{
+		{"repeated right after the stop", -10 * time.Second, nil},
+		{"long after the stop", -2 * stopRetryWindow, domain.ErrNoActiveSession},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			repo := memory.NewTransactionRepository()
+			endTime := time.Now().Add(tt.endedAt)
+			repo.Save(ctx, &domain.Transaction{
+				ID: "tx-1", UserID: "user-1", Status: domain.TransactionStatusStopped,
+				StartTime: endTime.Add(-time.Hour), EndTime: &endTime, CreatedAt: endTime.Add(-time.Hour),
+			})
+			service := NewService(repo, availableDevices(), nil, zap.NewNop())
+
+			// Act
+			err := service.StopActiveCharging(ctx, "user-1")
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestStopActiveCharging_LoadsOnlyNewestTransaction(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	endTime := time.Now().Add(-10 * time.Second)
+	var gotFilter ports.TransactionFilter
+	var gotLimit int
+	repo := &mocks.MockTransactionRepository{
+		FindPageFunc: func(ctx context.Context, filter ports.TransactionFilter, after *ports.TransactionCursor, limit int) ([]domain.Transaction, error) {
+			gotFilter, gotLimit = filter, limit
+			return []domain.Transaction{{ID: "tx-1", UserID: "user-1", Status: domain.TransactionStatusStopped, EndTime: &endTime}}, nil
+		},
+		FindHistoryByUserIDFunc: func(ctx context.Context, uid string) ([]domain.Transaction, error) {
+			t.Error("expected the full history not to be loaded")
+			return nil, nil
+		},
+	}
+	service := NewService(repo, availableDevices(), nil, zap.NewNop())
+
+	// Act
+	err := service.StopActiveCharging(ctx, "user-1")
+
+	// Assert
+	if err != nil {
+		t.Errorf("expected the repeated stop to succeed, got %v", err)
+	}
+	if gotFilter.UserID != "user-1" || gotLimit != 1 {
+		t.Errorf("expected one transaction of user-1, got %+v with limit %d", gotFilter, gotLimit)
+	}
+}
+
 func TestGetCurrentSessionCost_Success(t *testing.T) {
 	// Arrange
 	ctx := context.Background()
@@ -624,12 +906,113 @@ func TestGetCurrentSessionCost_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	// Expected: ~1 hour * 7kW * 0.75 R$/kWh = ~5.25
-	if cost < 5.0 || cost > 6.0 {
-		t.Errorf("expected cost around 5.25, got %f", cost)
+	// Expected: no meter data yet, so ~1 hour * 7kW at the session's rate
+	rate := rateAt(DefaultPricingConfig(), activeTx.StartTime)
+	if !cost.Estimated {
+		t.Error("expected a time-based estimate without meter data")
+	}
+	if cost.EnergyKWh < 6.9 || cost.EnergyKWh > 7.1 {
+		t.Errorf("expected around 7 kWh, got %f", cost.EnergyKWh)
+	}
+	if cost.Cost < 6.9*rate || cost.Cost > 7.1*rate {
+		t.Errorf("expected cost around %.2f, got %f", 7*rate, cost.Cost)
+	}
+}
+
+func TestCurrentSessionCost_EnergyVersusTime(t *testing.T) {
+	start := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC) // Off-peak: R$ 0.75/kWh
+	now := start.Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		meterStart    int
+		meterStop     int
+		wantEnergyKWh float64
+		wantCost      float64
+		wantEstimated bool
+	}{
+		{"fast charger metered", 1000, 51000, 50, 37.5, false},
+		{"slow session metered", 1000, 4000, 3, 2.25, false},
+		{"metered, nothing delivered yet", 1000, 1000, 0, 0, false},
+		{"no meter data falls back to time", 0, 0, 7, 5.25, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tx := &domain.Transaction{
+				ID:         "tx-1",
+				StartTime:  start,
+				MeterStart: tt.meterStart,
+				MeterStop:  tt.meterStop,
+			}
+
+			// Act
+			cost := currentSessionCost(tx, DefaultPricingConfig(), time.UTC, now)
+
+			// Assert
+			if math.Abs(cost.EnergyKWh-tt.wantEnergyKWh) > 1e-9 || math.Abs(cost.Cost-tt.wantCost) > 1e-9 {
+				t.Errorf("expected %.2f kWh for %.2f, got %.2f kWh for %.2f", tt.wantEnergyKWh, tt.wantCost, cost.EnergyKWh, cost.Cost)
+			}
+			if cost.Estimated != tt.wantEstimated {
+				t.Errorf("expected estimated %v, got %v", tt.wantEstimated, cost.Estimated)
+			}
+			if cost.Currency != "BRL" || cost.RatePerKWh != 0.75 {
+				t.Errorf("expected BRL at 0.75/kWh, got %s at %.2f", cost.Currency, cost.RatePerKWh)
+			}
+		})
 	}
 }
 
+func TestCurrentSessionCost_PeakTariff(t *testing.T) {
+	// Arrange: started at 19h, within peak hours
+	start := time.Date(2026, 3, 10, 19, 0, 0, 0, time.UTC)
+	tx := &domain.Transaction{ID: "tx-1", StartTime: start, MeterStart: 0, MeterStop: 10000}
+
+	// Act
+	cost := currentSessionCost(tx, DefaultPricingConfig(), time.UTC, start.Add(30*time.Minute))
+
+	// Assert
+	if math.Abs(cost.Cost-11.25) > 1e-9 {
+		t.Errorf("expected 10 kWh at the 1.125 peak rate = 11.25, got %f", cost.Cost)
+	}
+}
+
+func TestGetCurrentSessionCost_PeakHoursInStationTimeZone(t *testing.T) {
+	// Arrange: 21:30 UTC is 18:30 in São Paulo, inside the 18-21 peak
+	if _, err := time.LoadLocation("America/Sao_Paulo"); err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	tx := &domain.Transaction{
+		ID:            "tx-1",
+		ChargePointID: "CP-SP",
+		StartTime:     time.Date(2024, 1, 15, 21, 30, 0, 0, time.UTC),
+		MeterStop:     7000,
+	}
+	txRepo := &mocks.MockTransactionRepository{
+		FindActiveByUserIDFunc: func(ctx context.Context, userID string) (*domain.Transaction, error) {
+			return tx, nil
+		},
+	}
+	devices := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Location: &domain.Location{Timezone: "America/Sao_Paulo"}}, nil
+		},
+	}
+	service := NewService(txRepo, devices, nil, zap.NewNop())
+
+	// Act
+	cost, err := service.GetCurrentSessionCost(context.Background(), "user-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pricing := DefaultPricingConfig()
+	if want := pricing.BaseRatePerKWh * pricing.PeakRateMultiplier; cost.RatePerKWh != want {
+		t.Errorf("expected the peak rate %.4f, got %.4f", want, cost.RatePerKWh)
+	}
+}
 func TestGetCurrentSessionCost_NoActiveSession(t *testing.T) {
 	// Arrange
 	ctx := context.Background()
@@ -690,3 +1073,416 @@ func TestStartTransaction_RepositoryError(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestProjectSessionCost_EnergyTarget(t *testing.T) {
+	// Arrange: 10 kWh delivered in the first hour, off-peak start
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	reading := start.Add(time.Hour)
+	tx := &domain.Transaction{
+		ID:         "tx-1",
+		StartTime:  start,
+		MeterStart: 1000,
+		MeterStop:  11000,
+		UpdatedAt:  reading,
+		Currency:   "BRL",
+	}
+
+	// Act
+	p := projectSessionCost(tx, domain.ChargeTarget{EnergyKWh: 30}, DefaultPricingConfig(), time.UTC, nil, reading.Add(time.Minute))
+
+	// Assert
+	if p.LowConfidence {
+		t.Error("expected confident projection")
+	}
+	if p.PowerKW != 10 {
+		t.Errorf("expected 10 kW, got %f", p.PowerKW)
+	}
+	if p.CurrentCost != 7.5 {
+		t.Errorf("expected current cost 7.50, got %f", p.CurrentCost)
+	}
+	if p.ProjectedEnergyKWh != 30 {
+		t.Errorf("expected projected energy 30 kWh, got %f", p.ProjectedEnergyKWh)
+	}
+	if p.ProjectedCost != 22.5 {
+		t.Errorf("expected projected cost 22.50, got %f", p.ProjectedCost)
+	}
+	expected := reading.Add(2 * time.Hour)
+	if p.EstimatedCompletion == nil || !p.EstimatedCompletion.Equal(expected) {
+		t.Errorf("expected completion at %v, got %v", expected, p.EstimatedCompletion)
+	}
+}
+
+func TestProjectSessionCost_SOCTargetUsesPeakRate(t *testing.T) {
+	// Arrange: 11 kW average, started during peak hours
+	start := time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)
+	reading := start.Add(30 * time.Minute)
+	tx := &domain.Transaction{
+		StartTime:  start,
+		MeterStart: 0,
+		MeterStop:  5500,
+		UpdatedAt:  reading,
+	}
+	target := domain.ChargeTarget{TargetSOC: 80, CurrentSOC: 50, BatteryCapacityKWh: 60}
+
+	// Act
+	p := projectSessionCost(tx, target, DefaultPricingConfig(), time.UTC, nil, reading)
+
+	// Assert: 5.5 kWh delivered + 30% of 60 kWh still needed
+	if p.ProjectedEnergyKWh != 23.5 {
+		t.Errorf("expected projected energy 23.5 kWh, got %f", p.ProjectedEnergyKWh)
+	}
+	if p.RatePerKWh != 1.125 {
+		t.Errorf("expected peak rate 1.125, got %f", p.RatePerKWh)
+	}
+	if diff := p.ProjectedCost - 23.5*1.125; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected projected cost %f, got %f", 23.5*1.125, p.ProjectedCost)
+	}
+	if p.Currency != "BRL" {
+		t.Errorf("expected currency BRL from pricing, got %s", p.Currency)
+	}
+	if p.EstimatedCompletion == nil {
+		t.Fatal("expected completion time")
+	}
+	remaining := p.EstimatedCompletion.Sub(reading)
+	// 18 kWh at 11 kW ≈ 98 minutes
+	if remaining < 98*time.Minute || remaining > 99*time.Minute {
+		t.Errorf("expected about 98 minutes remaining, got %v", remaining)
+	}
+}
+
+func TestProjectSessionCost_NoTarget(t *testing.T) {
+	// Arrange
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	tx := &domain.Transaction{
+		StartTime: start,
+		MeterStop: 7000,
+		UpdatedAt: start.Add(time.Hour),
+	}
+
+	// Act
+	p := projectSessionCost(tx, domain.ChargeTarget{}, DefaultPricingConfig(), time.UTC, nil, start.Add(time.Hour))
+
+	// Assert
+	if p.ProjectedCost != p.CurrentCost {
+		t.Errorf("expected projected cost to equal current cost, got %f and %f", p.ProjectedCost, p.CurrentCost)
+	}
+	if p.EstimatedCompletion != nil {
+		t.Errorf("expected no completion time without a target, got %v", p.EstimatedCompletion)
+	}
+}
+
+func TestProjectSessionCost_TargetAlreadyReached(t *testing.T) {
+	// Arrange
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	tx := &domain.Transaction{
+		StartTime: start,
+		MeterStop: 20000,
+		UpdatedAt: now,
+	}
+
+	// Act
+	p := projectSessionCost(tx, domain.ChargeTarget{EnergyKWh: 15}, DefaultPricingConfig(), time.UTC, nil, now)
+
+	// Assert
+	if p.ProjectedEnergyKWh != 20 {
+		t.Errorf("expected projected energy to stay at 20 kWh, got %f", p.ProjectedEnergyKWh)
+	}
+	if p.EstimatedCompletion == nil || !p.EstimatedCompletion.Equal(now) {
+		t.Errorf("expected completion now, got %v", p.EstimatedCompletion)
+	}
+}
+
+func TestProjectSessionCost_PowerFromRecentSamples(t *testing.T) {
+	// 20 kWh in the first hour, tapering to 6 kW over the last 25 minutes
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	reading := start.Add(time.Hour)
+	var tapering []domain.MeterSample
+	for i := 0; i < 6; i++ {
+		tapering = append(tapering, domain.MeterSample{
+			Timestamp: start.Add(time.Duration(35+5*i) * time.Minute),
+			Measurand: domain.MeasurandEnergyActiveImportRegister,
+			Value:     17.5 + 0.5*float64(i),
+			Unit:      "kWh",
+		})
+	}
+
+	tests := []struct {
+		name       string
+		samples    []domain.MeterSample
+		wantPower  float64
+		completion time.Time
+	}{
+		{"enough samples", tapering, 6, reading.Add(time.Hour)},
+		{"too few samples", tapering[3:], 20, reading.Add(18 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tx := &domain.Transaction{ID: "tx-1", StartTime: start, MeterStop: 20000, UpdatedAt: reading}
+
+			// Act
+			p := projectSessionCost(tx, domain.ChargeTarget{EnergyKWh: 26}, DefaultPricingConfig(), time.UTC, tt.samples, reading)
+
+			// Assert
+			if math.Abs(p.PowerKW-tt.wantPower) > 1e-9 {
+				t.Errorf("expected %.1f kW, got %f", tt.wantPower, p.PowerKW)
+			}
+			if p.EstimatedCompletion == nil || p.EstimatedCompletion.Sub(tt.completion).Abs() > time.Second {
+				t.Errorf("expected completion at %v, got %v", tt.completion, p.EstimatedCompletion)
+			}
+		})
+	}
+}
+
+func TestProjectSessionCost_InsufficientMeterHistory(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   *domain.Transaction
+	}{
+		{"no readings", &domain.Transaction{MeterStart: 500, MeterStop: 0}},
+		{"reading too early", &domain.Transaction{MeterStop: 300}},
+	}
+
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.tx.StartTime = start
+			tt.tx.UpdatedAt = start.Add(2 * time.Minute)
+
+			// Act
+			p := projectSessionCost(tt.tx, domain.ChargeTarget{EnergyKWh: 50}, DefaultPricingConfig(), time.UTC, nil, now)
+
+			// Assert: fall back to 1 hour at the assumed 7 kW
+			if !p.LowConfidence {
+				t.Error("expected low-confidence projection")
+			}
+			if p.CurrentCost != 5.25 {
+				t.Errorf("expected current cost 5.25, got %f", p.CurrentCost)
+			}
+			if p.ProjectedCost != p.CurrentCost {
+				t.Errorf("expected projected cost to equal current cost, got %f", p.ProjectedCost)
+			}
+			if p.EstimatedCompletion != nil {
+				t.Errorf("expected no completion time, got %v", p.EstimatedCompletion)
+			}
+		})
+	}
+}
+
+func TestGetProjectedSessionCost_NoActiveSession(t *testing.T) {
+	// Arrange
+	mockTxRepo := &mocks.MockTransactionRepository{
+		FindActiveByUserIDFunc: func(ctx context.Context, uid string) (*domain.Transaction, error) {
+			return nil, nil
+		},
+	}
+	service := NewService(mockTxRepo, &mocks.MockDeviceService{}, mocks.NewMockMessageQueue(), newTestLogger())
+
+	// Act
+	_, err := service.GetProjectedSessionCost(context.Background(), "user-123", domain.ChargeTarget{})
+
+	// Assert
+	if !errors.Is(err, domain.ErrNoActiveSession) {
+		t.Errorf("expected ErrNoActiveSession, got %v", err)
+	}
+}
+
+func TestRecordTransactionEvent_IgnoresStaleSeqNo(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	existingTx := &domain.Transaction{
+		ID:     "tx-123",
+		Status: domain.TransactionStatusStarted,
+	}
+	updates := 0
+
+	mockTxRepo := &mocks.MockTransactionRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.Transaction, error) {
+			return existingTx, nil
+		},
+		UpdateFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			updates++
+			return nil
+		},
+	}
+
+	service := NewService(mockTxRepo, &mocks.MockDeviceService{}, nil, newTestLogger())
+
+	// Act
+	service.RecordTransactionEvent(ctx, "tx-123", 0, 1000)
+	service.RecordTransactionEvent(ctx, "tx-123", 2, 4000)
+	err := service.RecordTransactionEvent(ctx, "tx-123", 1, 2500)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updates != 2 {
+		t.Errorf("expected 2 updates, got %d", updates)
+	}
+	if existingTx.LastSeqNo != 2 {
+		t.Errorf("expected last seqNo 2, got %d", existingTx.LastSeqNo)
+	}
+	if existingTx.MeterStart != 1000 || existingTx.MeterStop != 4000 {
+		t.Errorf("expected meter 1000 → 4000, got %d → %d", existingTx.MeterStart, existingTx.MeterStop)
+	}
+}
+
+// noCache never hits, so every read goes to the repository
+func noCache() *mocks.MockCache {
+	return &mocks.MockCache{
+		GetFunc:    func(ctx context.Context, key string) (string, error) { return "", nil },
+		SetFunc:    func(ctx context.Context, key string, value interface{}, expiration time.Duration) error { return nil },
+		DeleteFunc: func(ctx context.Context, key string) error { return nil },
+	}
+}
+
+func TestStartTransaction_ConcurrentStartsOnOneConnector(t *testing.T) {
+	// Arrange - a real device service over the in-memory store
+	ctx := context.Background()
+	stations := memory.NewChargePointRepository()
+	if err := stations.Save(ctx, &domain.ChargePoint{
+		ID:         "CP-001",
+		Status:     domain.ChargePointStatusAvailable,
+		Connectors: []domain.Connector{{ID: "c-1", ChargePointID: "CP-001", ConnectorID: 1, Status: domain.ChargePointStatusAvailable}},
+	}); err != nil {
+		t.Fatalf("failed to save station: %v", err)
+	}
+	devices := device.NewService(stations, noCache(), nil, zap.NewNop())
+	service := NewService(memory.NewTransactionRepository(), devices, nil, zap.NewNop())
+
+	const users = 8
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	results := make(chan error, users)
+
+	// Act
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, err := service.StartTransaction(ctx, "CP-001", 1, fmt.Sprintf("user-%d", i), "rfid")
+			results <- err
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(results)
+
+	// Assert
+	winners := 0
+	for err := range results {
+		if err == nil {
+			winners++
+		}
+	}
+	if winners != 1 {
+		t.Fatalf("expected exactly one session to start, got %d", winners)
+	}
+	cp, _ := stations.FindByID(ctx, "CP-001")
+	if cp.Connectors[0].Status != domain.ChargePointStatusOccupied {
+		t.Errorf("expected the connector to be Occupied, got %s", cp.Connectors[0].Status)
+	}
+}
+
+func TestStartTransaction_ConnectorTaken(t *testing.T) {
+	// Arrange - the device looked available, but another session won the connector
+	saved := false
+	mockTxRepo := &mocks.MockTransactionRepository{
+		SaveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			saved = true
+			return nil
+		},
+	}
+	mockDeviceService := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable}, nil
+		},
+		ReserveConnectorFunc: func(ctx context.Context, id string, connectorID int) error {
+			return domain.ErrConnectorUnavailable
+		},
+	}
+	service := NewService(mockTxRepo, mockDeviceService, nil, zap.NewNop())
+
+	// Act
+	_, err := service.StartTransaction(context.Background(), "device-123", 1, "user-123", "rfid")
+
+	// Assert
+	if !errors.Is(err, domain.ErrConnectorUnavailable) {
+		t.Fatalf("expected ErrConnectorUnavailable, got %v", err)
+	}
+	if saved {
+		t.Error("expected no transaction to be saved")
+	}
+}
+
+func TestStartTransaction_ReleasesConnectorWhenSaveFails(t *testing.T) {
+	// Arrange
+	var released []int
+	mockTxRepo := &mocks.MockTransactionRepository{
+		SaveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			return errors.New("database error")
+		},
+	}
+	mockDeviceService := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable}, nil
+		},
+		ReleaseConnectorFunc: func(ctx context.Context, id string, connectorID int) error {
+			released = append(released, connectorID)
+			return nil
+		},
+	}
+	service := NewService(mockTxRepo, mockDeviceService, nil, zap.NewNop())
+
+	// Act
+	_, err := service.StartTransaction(context.Background(), "device-123", 2, "user-123", "rfid")
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected the save error")
+	}
+	if len(released) != 1 || released[0] != 2 {
+		t.Errorf("expected connector 2 to be released, got %v", released)
+	}
+}
+
+func TestStartTransaction_RetryReturnsExistingSession(t *testing.T) {
+	// Arrange
+	existing := &domain.Transaction{ID: "tx-1", ChargePointID: "device-123", ConnectorID: 1, UserID: "user-123", Status: domain.TransactionStatusStarted}
+	reserved := false
+	mockTxRepo := &mocks.MockTransactionRepository{
+		FindActiveByUserIDFunc: func(ctx context.Context, userID string) (*domain.Transaction, error) {
+			return existing, nil
+		},
+	}
+	mockDeviceService := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable}, nil
+		},
+		ReserveConnectorFunc: func(ctx context.Context, id string, connectorID int) error {
+			reserved = true
+			return nil
+		},
+	}
+	service := NewService(mockTxRepo, mockDeviceService, nil, zap.NewNop())
+
+	// Act
+	tx, err := service.StartTransaction(context.Background(), "device-123", 1, "user-123", "rfid")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tx.ID != "tx-1" {
+		t.Errorf("expected the existing session tx-1, got %s", tx.ID)
+	}
+	if reserved {
+		t.Error("expected the connector not to be reserved again")
+	}
+}
@@ -0,0 +1,323 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+	"github.com/seu-repo/sigec-ve/internal/service/notification"
+)
+
+// SessionLimitConfig holds maximum session duration settings
+type SessionLimitConfig struct {
+	Interval      time.Duration            // How often active sessions are checked
+	MaxDuration   time.Duration            // Sessions are stopped once they last this long; 0 disables the limit
+	ByChargePoint map[string]time.Duration // Charge point ID → its own limit, 0 exempts the station
+	WarnBefore    time.Duration            // The user is warned this long before the stop
+	StopGrace     time.Duration            // Wait for the station to end the session before billing it anyway
+}
+
+// DefaultSessionLimitConfig stops sessions after 12 hours, warning 15
+// minutes before, and bills them 10 minutes after the stop if the station
+// never reports the end
+func DefaultSessionLimitConfig() SessionLimitConfig {
+	return SessionLimitConfig{
+		Interval:    time.Minute,
+		MaxDuration: 12 * time.Hour,
+		WarnBefore:  15 * time.Minute,
+		StopGrace:   10 * time.Minute,
+	}
+}
+
+// limitedSession is what the monitor remembers about an active session
+type limitedSession struct {
+	warned          bool
+	stopRequestedAt time.Time // Zero until the stop was sent, or tried
+}
+
+// SessionLimitMonitor stops sessions that run past their station's maximum
+// duration, e.g. because the meter got stuck or the car finished charging
+// but stayed plugged in. The station is asked to end the session; when it
+// does not within the grace period, the session is billed for the energy
+// metered so far. V2G sessions are exempt, they run for as long as the
+// grid needs them.
+type SessionLimitMonitor struct {
+	sessions ports.TransactionService
+	stopper  ports.SessionStopper
+	mq       queue.MessageQueue          // optional, users are not notified without it
+	v2g      ports.V2GRepository         // optional, see SetV2GRepository
+	stations ports.ChargePointRepository // optional, see SetStationRepository
+	config   SessionLimitConfig
+	log      *zap.Logger
+
+	mu      sync.Mutex
+	tracked map[string]*limitedSession // transaction ID → session
+}
+
+// NewSessionLimitMonitor creates a maximum session duration monitor
+func NewSessionLimitMonitor(
+	sessions ports.TransactionService,
+	stopper ports.SessionStopper,
+	mq queue.MessageQueue,
+	config SessionLimitConfig,
+	log *zap.Logger,
+) *SessionLimitMonitor {
+	defaults := DefaultSessionLimitConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.MaxDuration < 0 {
+		config.MaxDuration = defaults.MaxDuration
+	}
+	if config.WarnBefore < 0 {
+		config.WarnBefore = defaults.WarnBefore
+	}
+	if config.StopGrace <= 0 {
+		config.StopGrace = defaults.StopGrace
+	}
+
+	return &SessionLimitMonitor{
+		sessions: sessions,
+		stopper:  stopper,
+		mq:       mq,
+		config:   config,
+		log:      log,
+		tracked:  make(map[string]*limitedSession),
+	}
+}
+
+// SetV2GRepository enables exempting the sessions of stations discharging
+// to the grid
+func (m *SessionLimitMonitor) SetV2GRepository(repo ports.V2GRepository) {
+	m.v2g = repo
+}
+
+// SetStationRepository makes warnings give the stop time in the station's
+// local time instead of the server's
+func (m *SessionLimitMonitor) SetStationRepository(stations ports.ChargePointRepository) {
+	m.stations = stations
+}
+
+// stationZone returns the time zone of a session's station, the server's
+// when it is unknown
+func (m *SessionLimitMonitor) stationZone(ctx context.Context, chargePointID string) *time.Location {
+	if m.stations == nil {
+		return time.Local
+	}
+	cp, err := m.stations.FindByID(ctx, chargePointID)
+	if err != nil || cp == nil {
+		return time.Local
+	}
+	return cp.TimeZone()
+}
+
+// RunOnce checks every active session and returns how many were asked to
+// stop. A session whose stop fails is billed once the grace period ends.
+func (m *SessionLimitMonitor) RunOnce(ctx context.Context) (int, error) {
+	active, err := m.sessions.ListActiveSessions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list active sessions: %w", err)
+	}
+	m.forgetEnded(active)
+
+	now := time.Now()
+	stopped := 0
+	for i := range active {
+		tx := &active[i].Transaction
+		if m.check(ctx, tx, now) {
+			stopped++
+		}
+	}
+	return stopped, nil
+}
+
+// Start runs RunOnce every interval until ctx is done
+func (m *SessionLimitMonitor) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := m.RunOnce(ctx); err != nil {
+					m.log.Error("Session duration check failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// limitFor returns the maximum duration of sessions at a charge point, 0
+// when they are not limited
+func (m *SessionLimitMonitor) limitFor(chargePointID string) time.Duration {
+	if limit, ok := domain.ChargePointSetting(m.config.ByChargePoint, chargePointID); ok {
+		return limit
+	}
+	return m.config.MaxDuration
+}
+
+// check warns about, stops or bills tx depending on how long it has run.
+// It reports whether a stop was requested.
+func (m *SessionLimitMonitor) check(ctx context.Context, tx *domain.Transaction, now time.Time) bool {
+	limit := m.limitFor(tx.ChargePointID)
+	if limit <= 0 {
+		return false
+	}
+	elapsed := now.Sub(tx.StartTime)
+	if elapsed < limit-m.config.WarnBefore {
+		return false
+	}
+	if m.isV2G(ctx, tx) {
+		return false
+	}
+
+	m.mu.Lock()
+	session, ok := m.tracked[tx.ID]
+	if !ok {
+		session = &limitedSession{}
+		m.tracked[tx.ID] = session
+	}
+	warned := session.warned
+	stopRequestedAt := session.stopRequestedAt
+	m.mu.Unlock()
+
+	if elapsed < limit {
+		if !warned {
+			m.notify(tx, "Charging ends soon", fmt.Sprintf(
+				"Your session will be stopped at %s, after the maximum of %s at this station.",
+				tx.StartTime.Add(limit).In(m.stationZone(ctx, tx.ChargePointID)).Format("15:04"), limit))
+			m.mu.Lock()
+			session.warned = true
+			m.mu.Unlock()
+		}
+		return false
+	}
+
+	if !stopRequestedAt.IsZero() {
+		if now.Sub(stopRequestedAt) >= m.config.StopGrace {
+			m.finalize(ctx, tx)
+		}
+		return false
+	}
+
+	m.mu.Lock()
+	session.stopRequestedAt = now
+	m.mu.Unlock()
+	if err := m.stopper.StopSession(ctx, tx.ChargePointID, tx.ID); err != nil {
+		// The station may be hung; the session is billed after the grace period
+		m.log.Warn("Failed to stop session past its maximum duration",
+			zap.String("tx_id", tx.ID),
+			zap.String("cp_id", tx.ChargePointID),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	m.log.Info("Maximum session duration reached, session stop requested",
+		zap.String("tx_id", tx.ID),
+		zap.String("cp_id", tx.ChargePointID),
+		zap.Duration("elapsed", elapsed),
+		zap.Duration("limit", limit),
+	)
+	m.notify(tx, "Charging stopped", fmt.Sprintf(
+		"Your session reached the maximum of %s at this station and was stopped.", limit))
+	return true
+}
+
+// finalize ends and bills a session the station did not end after it was
+// asked to
+func (m *SessionLimitMonitor) finalize(ctx context.Context, tx *domain.Transaction) {
+	if _, err := m.sessions.StopTransaction(ctx, tx.ID); err != nil {
+		m.log.Error("Failed to finalize session past its maximum duration",
+			zap.String("tx_id", tx.ID),
+			zap.String("cp_id", tx.ChargePointID),
+			zap.Error(err),
+		)
+		return
+	}
+	m.log.Warn("Station did not end session, finalized without it",
+		zap.String("tx_id", tx.ID),
+		zap.String("cp_id", tx.ChargePointID),
+	)
+
+	m.mu.Lock()
+	delete(m.tracked, tx.ID)
+	m.mu.Unlock()
+}
+
+// isV2G reports whether tx is a V2G session, linked to it or running on a
+// station that is discharging
+func (m *SessionLimitMonitor) isV2G(ctx context.Context, tx *domain.Transaction) bool {
+	if m.v2g == nil {
+		return false
+	}
+
+	sessions, err := m.v2g.GetSessionsByChargePoint(ctx, tx.ChargePointID, 10)
+	if err != nil {
+		// Not stopping a session is the safer mistake
+		m.log.Warn("Failed to load V2G sessions, session not limited",
+			zap.String("tx_id", tx.ID),
+			zap.Error(err),
+		)
+		return true
+	}
+	for _, session := range sessions {
+		if session.TransactionID == tx.ID {
+			return true
+		}
+		if session.Status == domain.V2GStatusActive && session.EndTime == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// notify tells the user about the session limit
+func (m *SessionLimitMonitor) notify(tx *domain.Transaction, title, body string) {
+	if m.mq == nil {
+		return
+	}
+
+	event := domain.NotificationEvent{
+		Type:   domain.NotificationSessionLimit,
+		UserID: tx.UserID,
+		Title:  title,
+		Body:   body,
+		Data: map[string]string{
+			"transaction_id":  tx.ID,
+			"charge_point_id": tx.ChargePointID,
+		},
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := m.mq.Publish(notification.Subject, data); err != nil {
+		m.log.Warn("Failed to publish session limit notification", zap.Error(err))
+	}
+}
+
+// forgetEnded drops the sessions that are no longer active
+func (m *SessionLimitMonitor) forgetEnded(active []domain.ActiveSession) {
+	ids := make(map[string]bool, len(active))
+	for _, session := range active {
+		ids[session.Transaction.ID] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id := range m.tracked {
+		if !ids[id] {
+			delete(m.tracked, id)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/service/notification"
+)
+
+// sessionRunningFor is a session of user-1 at CP-001 started d ago
+func sessionRunningFor(d time.Duration) domain.ActiveSession {
+	return domain.ActiveSession{Transaction: domain.Transaction{
+		ID:            "tx-1",
+		ChargePointID: "CP-001",
+		UserID:        "user-1",
+		StartTime:     time.Now().Add(-d),
+		Status:        domain.TransactionStatusStarted,
+	}}
+}
+
+// limitNotifications decodes the session limit notifications published on mq
+func limitNotifications(t *testing.T, mq *mocks.MockMessageQueue) []domain.NotificationEvent {
+	t.Helper()
+	var events []domain.NotificationEvent
+	for _, data := range mq.PublishedMessages[notification.Subject] {
+		var event domain.NotificationEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			t.Fatalf("expected a notification event, got %v", err)
+		}
+		if event.Type == domain.NotificationSessionLimit {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestSessionLimitMonitor_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		elapsed    time.Duration
+		byStation  map[string]time.Duration
+		wantStop   bool
+		wantNotice string
+	}{
+		{"well within limit", 2 * time.Hour, nil, false, ""},
+		{"about to reach limit", 11*time.Hour + 50*time.Minute, nil, false, "Charging ends soon"},
+		{"past limit", 13 * time.Hour, nil, true, "Charging stopped"},
+		{"past station limit", 5 * time.Hour, map[string]time.Duration{"CP-001": 4 * time.Hour}, true, "Charging stopped"},
+		{"station exempt", 13 * time.Hour, map[string]time.Duration{"CP-001": 0}, false, ""},
+		{"station limit from config", 5 * time.Hour, map[string]time.Duration{"cp-001": 4 * time.Hour}, true, "Charging stopped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			stopper := &recordingStopper{}
+			mq := mocks.NewMockMessageQueue()
+			monitor := NewSessionLimitMonitor(activeSessionsOf(sessionRunningFor(tt.elapsed)), stopper, mq, SessionLimitConfig{
+				MaxDuration:   12 * time.Hour,
+				ByChargePoint: tt.byStation,
+				WarnBefore:    15 * time.Minute,
+			}, zap.NewNop())
+
+			// Act
+			stopped, err := monitor.RunOnce(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tt.wantStop && (stopped != 1 || len(stopper.stopped) != 1 || stopper.stopped[0] != "CP-001/tx-1") {
+				t.Errorf("expected CP-001/tx-1 to be stopped, got %d stops %v", stopped, stopper.stopped)
+			}
+			if !tt.wantStop && len(stopper.stopped) != 0 {
+				t.Errorf("expected no stop, got %v", stopper.stopped)
+			}
+			events := limitNotifications(t, mq)
+			if tt.wantNotice == "" && len(events) != 0 {
+				t.Errorf("expected no notification, got %+v", events)
+			}
+			if tt.wantNotice != "" && (len(events) != 1 || events[0].Title != tt.wantNotice || events[0].UserID != "user-1") {
+				t.Errorf("expected %q for user-1, got %+v", tt.wantNotice, events)
+			}
+		})
+	}
+}
+
+func TestSessionLimitMonitor_BillsSessionStationDidNotEnd(t *testing.T) {
+	// Arrange: the station accepts the stop but never reports the end
+	var finalized []string
+	sessions := activeSessionsOf(sessionRunningFor(13 * time.Hour))
+	sessions.StopTransactionFunc = func(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+		finalized = append(finalized, transactionID)
+		return &domain.Transaction{ID: transactionID, Status: domain.TransactionStatusStopped}, nil
+	}
+	stopper := &recordingStopper{}
+	monitor := NewSessionLimitMonitor(sessions, stopper, nil, SessionLimitConfig{
+		MaxDuration: 12 * time.Hour,
+		StopGrace:   time.Millisecond,
+	}, zap.NewNop())
+	ctx := context.Background()
+
+	// Act
+	monitor.RunOnce(ctx)
+	time.Sleep(5 * time.Millisecond)
+	monitor.RunOnce(ctx)
+
+	// Assert
+	if len(stopper.stopped) != 1 {
+		t.Errorf("expected one stop request, got %v", stopper.stopped)
+	}
+	if len(finalized) != 1 || finalized[0] != "tx-1" {
+		t.Errorf("expected tx-1 to be billed, got %v", finalized)
+	}
+}
+
+func TestSessionLimitMonitor_BillsWhenStopFails(t *testing.T) {
+	// Arrange: the station is hung and refuses the stop
+	var finalized []string
+	sessions := activeSessionsOf(sessionRunningFor(13 * time.Hour))
+	sessions.StopTransactionFunc = func(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+		finalized = append(finalized, transactionID)
+		return &domain.Transaction{ID: transactionID}, nil
+	}
+	stopper := &recordingStopper{err: errors.New("charge point CP-001 not connected")}
+	monitor := NewSessionLimitMonitor(sessions, stopper, nil, SessionLimitConfig{
+		MaxDuration: 12 * time.Hour,
+		StopGrace:   20 * time.Millisecond,
+	}, zap.NewNop())
+	ctx := context.Background()
+
+	// Act
+	first, _ := monitor.RunOnce(ctx)
+	second, _ := monitor.RunOnce(ctx)
+	billedEarly := len(finalized)
+	time.Sleep(30 * time.Millisecond)
+	monitor.RunOnce(ctx)
+
+	// Assert
+	if first != 0 || second != 0 {
+		t.Errorf("expected no accepted stop, got %d then %d", first, second)
+	}
+	if billedEarly != 0 {
+		t.Errorf("expected billing to wait for the grace period, got %v", finalized)
+	}
+	if len(finalized) != 1 || finalized[0] != "tx-1" {
+		t.Errorf("expected tx-1 to be billed after the grace period, got %v", finalized)
+	}
+}
+
+func TestSessionLimitMonitor_ExemptsV2G(t *testing.T) {
+	tests := []struct {
+		name    string
+		session domain.V2GSession
+	}{
+		{"linked to the transaction", domain.V2GSession{
+			ID: "v2g-1", TransactionID: "tx-1", ChargePointID: "CP-001", Status: domain.V2GStatusCompleted,
+		}},
+		{"station discharging", domain.V2GSession{
+			ID: "v2g-1", ChargePointID: "CP-001", Status: domain.V2GStatusActive,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := memory.NewV2GRepository()
+			session := tt.session
+			session.StartTime = time.Now().Add(-13 * time.Hour)
+			repo.CreateSession(context.Background(), &session)
+			stopper := &recordingStopper{}
+			mq := mocks.NewMockMessageQueue()
+			monitor := NewSessionLimitMonitor(activeSessionsOf(sessionRunningFor(13*time.Hour)), stopper, mq,
+				SessionLimitConfig{MaxDuration: 12 * time.Hour}, zap.NewNop())
+			monitor.SetV2GRepository(repo)
+
+			// Act
+			stopped, err := monitor.RunOnce(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if stopped != 0 || len(stopper.stopped) != 0 {
+				t.Errorf("expected the V2G session to keep running, got %v", stopper.stopped)
+			}
+			if events := limitNotifications(t, mq); len(events) != 0 {
+				t.Errorf("expected no notification, got %+v", events)
+			}
+		})
+	}
+}
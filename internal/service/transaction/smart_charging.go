@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -51,6 +52,8 @@ type SmartChargingConfig struct {
 	LoadBalancingEnabled bool    // Enable load balancing between chargers
 	PeakShavingEnabled  bool    // Enable peak shaving during high demand
 	V2GEnabled          bool    // Enable Vehicle-to-Grid (future)
+	StationPowerKW      map[string]float64 // Device ID -> power its connectors share; defaults to the sum of their ratings
+	ReservationWeight   float64            // Share of a reservation holder relative to other sessions
 }
 
 // DefaultSmartChargingConfig returns the default smart charging configuration
@@ -62,6 +65,7 @@ func DefaultSmartChargingConfig() *SmartChargingConfig {
 		LoadBalancingEnabled: true,
 		PeakShavingEnabled:  true,
 		V2GEnabled:          false, // Not yet implemented
+		ReservationWeight:   2.0,
 	}
 }
 
@@ -72,6 +76,10 @@ type SmartChargingService struct {
 	mq             queue.MessageQueue
 	config         *SmartChargingConfig
 	activeProfiles map[string]*ChargingProfile // key: "deviceID:connectorID"
+	limiter         ports.ChargingLimiter
+	reservationRepo ports.ReservationRepository
+	balancedLimits  map[string]map[int]float64 // deviceID -> connectorID -> last limit pushed, in W
+	balanceMu       sync.Mutex
 	log            *zap.Logger
 }
 
@@ -92,6 +100,7 @@ func NewSmartChargingService(
 		mq:             mq,
 		config:         config,
 		activeProfiles: make(map[string]*ChargingProfile),
+		balancedLimits: make(map[string]map[int]float64),
 		log:            log,
 	}
 }
@@ -137,8 +146,8 @@ func (s *SmartChargingService) OptimizeCharging(
 		maxPowerKW = s.config.MinPowerKW
 	}
 
-	// Create charging schedule
-	now := time.Now()
+	// Create charging schedule; peak hours are the station's local hours
+	now := time.Now().In(device.TimeZone())
 	schedule := s.createOptimalSchedule(targetEnergyKWh, maxPowerKW, departureTime, now)
 
 	profile := &ChargingProfile{
@@ -0,0 +1,142 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+const earthRadiusKM = 6371.0
+
+// stationCandidate is an available charge point StartCharging may use
+type stationCandidate struct {
+	device      domain.ChargePoint
+	connectorID int     // Most powerful free connector, 1 if none are listed
+	powerKW     float64 // Rating of that connector
+	busy        float64 // Share of the connectors in use
+	distanceKM  float64 // From the user, +Inf when unknown
+	price       float64 // Per kWh, +Inf when unknown
+}
+
+// SetStationSelection sets how StartCharging picks a station when the
+// request names neither a station nor a strategy
+func (s *Service) SetStationSelection(strategy domain.StationSelectionStrategy) error {
+	if !strategy.Valid() {
+		return fmt.Errorf("%w: unknown station selection strategy %q", domain.ErrValidation, strategy)
+	}
+	s.stationSelection = strategy
+	return nil
+}
+
+// SetStationPriceSource enables the cheapest station selection strategy
+func (s *Service) SetStationPriceSource(prices ports.StationPriceSource) {
+	s.stationPrices = prices
+}
+
+// selectStation picks the candidate the strategy prefers among the
+// available devices. A strategy that lacks the data it ranks by falls back
+// to the first available station.
+func (s *Service) selectStation(ctx context.Context, devices []domain.ChargePoint, selection domain.StationSelection) (stationCandidate, bool) {
+	strategy := selection.Strategy
+	if strategy == "" {
+		strategy = s.stationSelection
+	}
+	switch {
+	case strategy == domain.StationSelectionNearest && selection.Location == nil:
+		s.log.Info("User location unknown, picking the first available station")
+		strategy = domain.StationSelectionFirstAvailable
+	case strategy == domain.StationSelectionCheapest && s.stationPrices == nil:
+		s.log.Warn("No station price source configured, picking the first available station")
+		strategy = domain.StationSelectionFirstAvailable
+	}
+
+	candidates := make([]stationCandidate, 0, len(devices))
+	for _, device := range devices {
+		c, ok := newStationCandidate(device)
+		if !ok {
+			continue
+		}
+		switch strategy {
+		case domain.StationSelectionNearest:
+			if device.Location != nil {
+				c.distanceKM = haversineKM(selection.Location.Latitude, selection.Location.Longitude,
+					device.Location.Latitude, device.Location.Longitude)
+			}
+		case domain.StationSelectionCheapest:
+			c.price = s.stationPrice(ctx, device.ID)
+		}
+		candidates = append(candidates, c)
+	}
+	if len(candidates) == 0 {
+		return stationCandidate{}, false
+	}
+
+	// Stable, so ties keep the order the devices were listed in
+	sort.SliceStable(candidates, func(a, b int) bool {
+		switch strategy {
+		case domain.StationSelectionNearest:
+			return candidates[a].distanceKM < candidates[b].distanceKM
+		case domain.StationSelectionCheapest:
+			return candidates[a].price < candidates[b].price
+		case domain.StationSelectionLeastBusy:
+			return candidates[a].busy < candidates[b].busy
+		case domain.StationSelectionHighestPower:
+			return candidates[a].powerKW > candidates[b].powerKW
+		}
+		return false
+	})
+	return candidates[0], true
+}
+
+// newStationCandidate reports false when every connector of device is taken
+func newStationCandidate(device domain.ChargePoint) (stationCandidate, bool) {
+	c := stationCandidate{device: device, connectorID: 1, distanceKM: math.Inf(1), price: math.Inf(1)}
+	if len(device.Connectors) == 0 {
+		return c, true // Only the device status is known
+	}
+
+	free, inUse := false, 0
+	for _, connector := range device.Connectors {
+		switch connector.Status {
+		case domain.ChargePointStatusAvailable:
+			if !free || connector.MaxPowerKW > c.powerKW {
+				c.connectorID, c.powerKW = connector.ConnectorID, connector.MaxPowerKW
+			}
+			free = true
+		case domain.ChargePointStatusOccupied, domain.ChargePointStatusCharging, domain.ChargePointStatusReserved:
+			inUse++
+		}
+	}
+	c.busy = float64(inUse) / float64(len(device.Connectors))
+	return c, free
+}
+
+// stationPrice returns the price per kWh at a charge point, +Inf when it
+// cannot be quoted so the station ranks last
+func (s *Service) stationPrice(ctx context.Context, chargePointID string) float64 {
+	price, err := s.stationPrices.PricePerKWh(ctx, chargePointID)
+	if err != nil {
+		s.log.Warn("Failed to get station price",
+			zap.String("chargePointID", chargePointID),
+			zap.Error(err),
+		)
+		return math.Inf(1)
+	}
+	return price
+}
+
+// haversineKM returns the great-circle distance between two points in km
+func haversineKM(lat1, lon1, lat2, lon2 float64) float64 {
+	const toRad = math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKM * math.Asin(math.Sqrt(a))
+}
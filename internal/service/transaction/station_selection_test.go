@@ -0,0 +1,191 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// fixedStationPrices quotes a price per charge point, failing for the others
+type fixedStationPrices map[string]float64
+
+func (p fixedStationPrices) PricePerKWh(ctx context.Context, chargePointID string) (float64, error) {
+	price, ok := p[chargePointID]
+	if !ok {
+		return 0, errors.New("no tariff")
+	}
+	return price, nil
+}
+
+// selectionCandidates is the set every strategy picks from, in the order
+// ListAvailableDevices returns it
+func selectionCandidates() []domain.ChargePoint {
+	at := func(lat, lon float64) *domain.Location {
+		return &domain.Location{Latitude: lat, Longitude: lon}
+	}
+	connector := func(id int, powerKW float64, status domain.ChargePointStatus) domain.Connector {
+		return domain.Connector{ConnectorID: id, MaxPowerKW: powerKW, Status: status}
+	}
+	return []domain.ChargePoint{
+		{ID: "full", Location: at(-23.5505, -46.6340), Connectors: []domain.Connector{
+			connector(1, 150, domain.ChargePointStatusOccupied),
+		}},
+		{ID: "centro", Location: at(-23.5505, -46.6333), Connectors: []domain.Connector{
+			connector(1, 50, domain.ChargePointStatusAvailable),
+			connector(2, 22, domain.ChargePointStatusOccupied),
+		}},
+		{ID: "paulista", Location: at(-23.5614, -46.6559), Connectors: []domain.Connector{
+			connector(1, 22, domain.ChargePointStatusAvailable),
+			connector(2, 150, domain.ChargePointStatusAvailable),
+			connector(3, 50, domain.ChargePointStatusOccupied),
+		}},
+		{ID: "aeroporto", Location: at(-23.4356, -46.4731), Connectors: []domain.Connector{
+			connector(1, 350, domain.ChargePointStatusCharging),
+			connector(2, 22, domain.ChargePointStatusAvailable),
+		}},
+		{ID: "shopping", Connectors: []domain.Connector{
+			connector(1, 7, domain.ChargePointStatusAvailable),
+		}},
+		{ID: "rodoviaria", Location: at(-23.5165, -46.6250), Connectors: []domain.Connector{
+			connector(1, 60, domain.ChargePointStatusAvailable),
+			connector(2, 60, domain.ChargePointStatusReserved),
+		}},
+	}
+}
+
+func TestSelectStation(t *testing.T) {
+	nearAirport := &domain.Location{Latitude: -23.44, Longitude: -46.48}
+	prices := fixedStationPrices{"full": 0.10, "centro": 1.10, "paulista": 0.95, "shopping": 0.90, "rodoviaria": 0.80}
+
+	tests := []struct {
+		name          string
+		defaultTo     domain.StationSelectionStrategy
+		selection     domain.StationSelection
+		prices        fixedStationPrices
+		wantStation   string
+		wantConnector int
+	}{
+		{"first available", "", domain.StationSelection{}, nil, "centro", 1},
+		{"nearest", "", domain.StationSelection{Strategy: domain.StationSelectionNearest, Location: nearAirport}, nil, "aeroporto", 2},
+		{"cheapest", "", domain.StationSelection{Strategy: domain.StationSelectionCheapest}, prices, "rodoviaria", 1},
+		{"least busy", "", domain.StationSelection{Strategy: domain.StationSelectionLeastBusy}, nil, "shopping", 1},
+		{"highest power", "", domain.StationSelection{Strategy: domain.StationSelectionHighestPower}, nil, "paulista", 2},
+		{"configured default", domain.StationSelectionLeastBusy, domain.StationSelection{}, nil, "shopping", 1},
+		{"request overrides default", domain.StationSelectionLeastBusy, domain.StationSelection{Strategy: domain.StationSelectionHighestPower}, nil, "paulista", 2},
+		{"nearest without location", "", domain.StationSelection{Strategy: domain.StationSelectionNearest}, nil, "centro", 1},
+		{"cheapest without prices", "", domain.StationSelection{Strategy: domain.StationSelectionCheapest}, nil, "centro", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := NewService(nil, nil, nil, zap.NewNop()).(*Service)
+			if tt.defaultTo != "" {
+				if err := service.SetStationSelection(tt.defaultTo); err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			}
+			if tt.prices != nil {
+				service.SetStationPriceSource(tt.prices)
+			}
+
+			// Act
+			got, ok := service.selectStation(context.Background(), selectionCandidates(), tt.selection)
+
+			// Assert
+			if !ok {
+				t.Fatal("expected a station")
+			}
+			if got.device.ID != tt.wantStation || got.connectorID != tt.wantConnector {
+				t.Errorf("expected %s connector %d, got %s connector %d", tt.wantStation, tt.wantConnector, got.device.ID, got.connectorID)
+			}
+		})
+	}
+}
+
+func TestSelectStation_NoFreeConnector(t *testing.T) {
+	// Arrange
+	service := NewService(nil, nil, nil, zap.NewNop()).(*Service)
+
+	// Act
+	_, ok := service.selectStation(context.Background(), selectionCandidates()[:1], domain.StationSelection{})
+
+	// Assert
+	if ok {
+		t.Error("expected no station when every connector is taken")
+	}
+}
+
+func TestStartCharging_UsesSelectedStationAndConnector(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	devices := selectionCandidates()
+	var reserved string
+	var reservedConnector int
+	txRepo := &mocks.MockTransactionRepository{
+		FindActiveByUserIDFunc: func(ctx context.Context, uid string) (*domain.Transaction, error) {
+			return nil, nil
+		},
+	}
+	deviceService := &mocks.MockDeviceService{
+		ListAvailableDevicesFunc: func(ctx context.Context) ([]domain.ChargePoint, error) {
+			return devices, nil
+		},
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusAvailable}, nil
+		},
+		ReserveConnectorFunc: func(ctx context.Context, id string, connectorID int) error {
+			reserved, reservedConnector = id, connectorID
+			return nil
+		},
+	}
+	service := NewService(txRepo, deviceService, mocks.NewMockMessageQueue(), zap.NewNop())
+
+	// Act
+	tx, err := service.StartCharging(ctx, "user-1", "", domain.StationSelection{Strategy: domain.StationSelectionHighestPower})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tx.ChargePointID != "paulista" || tx.ConnectorID != 2 {
+		t.Errorf("expected paulista connector 2, got %s connector %d", tx.ChargePointID, tx.ConnectorID)
+	}
+	if reserved != "paulista" || reservedConnector != 2 {
+		t.Errorf("expected paulista connector 2 to be reserved, got %s connector %d", reserved, reservedConnector)
+	}
+}
+
+func TestStartCharging_UnknownStrategy(t *testing.T) {
+	// Arrange
+	service := NewService(nil, &mocks.MockDeviceService{}, nil, zap.NewNop())
+
+	// Act
+	_, err := service.StartCharging(context.Background(), "user-1", "", domain.StationSelection{Strategy: "random"})
+
+	// Assert
+	if !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestSetStationSelection_RejectsUnknownStrategy(t *testing.T) {
+	// Arrange
+	service := NewService(nil, nil, nil, zap.NewNop()).(*Service)
+
+	// Act
+	err := service.SetStationSelection("random")
+
+	// Assert
+	if !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+	if service.stationSelection != domain.StationSelectionFirstAvailable {
+		t.Errorf("expected the default to stay %s, got %s", domain.StationSelectionFirstAvailable, service.stationSelection)
+	}
+}
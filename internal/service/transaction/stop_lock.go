@@ -0,0 +1,73 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// stopRetryWindow is how long after a session stopped a repeated stop
+// request still succeeds, e.g. a double tap in the app or a repeated voice
+// command
+const stopRetryWindow = time.Minute
+
+const (
+	// stopLockTTL bounds how long an instance that died mid-stop blocks
+	// stopping the transaction elsewhere
+	stopLockTTL = 30 * time.Second
+	// stopLockPoll is how often a held stop lock is retried
+	stopLockPoll = 20 * time.Millisecond
+)
+
+// SetLocker shares the stop lock of each transaction between instances, so
+// that only one of them bills a session stopped twice at once. Without it
+// stops are only serialized per instance.
+func (s *Service) SetLocker(locker ports.Locker) {
+	s.stopLocks = locker
+}
+
+// lockStop waits until no other stop of transactionID runs and returns the
+// function releasing the lock
+func (s *Service) lockStop(ctx context.Context, transactionID string) (func(), error) {
+	key := "transaction-stop:" + transactionID
+	for {
+		held, err := s.stopLocks.TryLock(ctx, key, stopLockTTL)
+		if err == nil {
+			return func() {
+				// Release even if the caller's context is already done
+				if err := held.Release(context.Background()); err != nil {
+					s.log.Warn("Failed to release stop lock", zap.String("tx_id", transactionID), zap.Error(err))
+				}
+			}, nil
+		}
+		if !errors.Is(err, ports.ErrLockHeld) {
+			return nil, fmt.Errorf("failed to lock transaction: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(stopLockPoll):
+		}
+	}
+}
+
+// stoppedRecently reports whether the user's latest session stopped within
+// stopRetryWindow of now. Only the newest transaction is loaded.
+func (s *Service) stoppedRecently(ctx context.Context, userID string, now time.Time) bool {
+	newest, err := s.repo.FindPage(ctx, ports.TransactionFilter{UserID: userID}, nil, 1)
+	if err != nil || len(newest) == 0 {
+		return false
+	}
+	latest := newest[0]
+	if latest.Status != domain.TransactionStatusStopped && latest.Status != domain.TransactionStatusCompleted {
+		return false
+	}
+	return latest.EndTime != nil && now.Sub(*latest.EndTime) < stopRetryWindow
+}
@@ -0,0 +1,79 @@
+package transaction
+
+import (
+	"math"
+	"strings"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// TaxConfig holds the tax rates applied to charging sessions, as fractions.
+// ICMS is a state tax and varies with the station's UF; PIS and COFINS are
+// federal.
+type TaxConfig struct {
+	ICMSByState  map[string]float64 // ICMS rate by UF (e.g., "SP")
+	FallbackICMS float64            // ICMS rate for stations without a known state
+	PIS          float64
+	COFINS       float64
+}
+
+// DefaultTaxConfig returns the modal ICMS rates for electricity of the states
+// where stations are deployed and the non-cumulative PIS/COFINS rates
+func DefaultTaxConfig() *TaxConfig {
+	return &TaxConfig{
+		ICMSByState: map[string]float64{
+			"SP": 0.18,
+			"RJ": 0.20,
+			"MG": 0.18,
+			"PR": 0.195,
+			"SC": 0.17,
+			"RS": 0.17,
+			"BA": 0.205,
+			"PE": 0.205,
+			"CE": 0.20,
+			"GO": 0.19,
+			"DF": 0.20,
+		},
+		FallbackICMS: 0.18,
+		PIS:          0.0165, // 1.65%
+		COFINS:       0.076,  // 7.6%
+	}
+}
+
+// icmsRate returns the ICMS rate for state and the normalized UF it was
+// found under, or the fallback rate and "" when the state is unknown
+func (c *TaxConfig) icmsRate(state string) (float64, string) {
+	uf := strings.ToUpper(strings.TrimSpace(state))
+	if rate, ok := c.ICMSByState[uf]; ok {
+		return rate, uf
+	}
+	return c.FallbackICMS, ""
+}
+
+// Breakdown splits a tax-inclusive amount charged at a station in state.
+// ICMS is calculated "por dentro" on the gross amount and PIS/COFINS on the
+// gross amount less ICMS (STF, RE 574.706). Taxes are rounded to cents and
+// the net amount takes the remainder, so the parts always add up to gross.
+func (c *TaxConfig) Breakdown(gross float64, state string) domain.TaxBreakdown {
+	gross = roundCents(gross)
+	icmsRate, uf := c.icmsRate(state)
+
+	icms := roundCents(gross * icmsRate)
+	base := gross - icms
+	pis := roundCents(base * c.PIS)
+	cofins := roundCents(base * c.COFINS)
+
+	return domain.TaxBreakdown{
+		State:  uf,
+		Net:    roundCents(gross - icms - pis - cofins),
+		ICMS:   icms,
+		PIS:    pis,
+		COFINS: cofins,
+		Gross:  gross,
+	}
+}
+
+// roundCents rounds an amount to two decimal places
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
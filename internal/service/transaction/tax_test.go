@@ -0,0 +1,156 @@
+package transaction
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+func assertSumsToGross(t *testing.T, b domain.TaxBreakdown) {
+	t.Helper()
+	if sum := b.Net + b.ICMS + b.PIS + b.COFINS; math.Abs(sum-b.Gross) > 0.001 {
+		t.Errorf("expected net + taxes to equal gross %.2f, got %.2f (%+v)", b.Gross, sum, b)
+	}
+}
+
+func TestTaxConfig_Breakdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		gross     float64
+		state     string
+		wantState string
+		wantICMS  float64
+		wantPIS   float64
+		wantCOFIN float64
+		wantNet   float64
+	}{
+		// ICMS 18% of 100; PIS/COFINS on 82
+		{"Sao Paulo", 100, "SP", "SP", 18.00, 1.35, 6.23, 74.42},
+		// ICMS 20% of 100; PIS/COFINS on 80
+		{"Rio de Janeiro", 100, "RJ", "RJ", 20.00, 1.32, 6.08, 72.60},
+		{"lower case state", 100, " sp ", "SP", 18.00, 1.35, 6.23, 74.42},
+		{"unknown state uses fallback", 100, "", "", 18.00, 1.35, 6.23, 74.42},
+		{"Parana", 19.13, "PR", "PR", 3.73, 0.25, 1.17, 13.98},
+	}
+
+	taxes := DefaultTaxConfig()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			b := taxes.Breakdown(tt.gross, tt.state)
+
+			// Assert
+			if b.State != tt.wantState {
+				t.Errorf("expected state %q, got %q", tt.wantState, b.State)
+			}
+			if b.ICMS != tt.wantICMS || b.PIS != tt.wantPIS || b.COFINS != tt.wantCOFIN {
+				t.Errorf("expected ICMS %.2f PIS %.2f COFINS %.2f, got %.2f %.2f %.2f",
+					tt.wantICMS, tt.wantPIS, tt.wantCOFIN, b.ICMS, b.PIS, b.COFINS)
+			}
+			if b.Net != tt.wantNet {
+				t.Errorf("expected net %.2f, got %.2f", tt.wantNet, b.Net)
+			}
+			assertSumsToGross(t, b)
+		})
+	}
+}
+
+func TestTaxConfig_BreakdownSumsToGross(t *testing.T) {
+	taxes := DefaultTaxConfig()
+	for cents := 1; cents <= 5000; cents += 7 {
+		for _, state := range []string{"SP", "PR", "BA", "XX"} {
+			assertSumsToGross(t, taxes.Breakdown(float64(cents)/100, state))
+		}
+	}
+}
+
+func TestProcessPayment_StoresTaxBreakdown(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	end := start.Add(3 * time.Hour)
+	tx := &domain.Transaction{
+		ID:            "tx-123",
+		ChargePointID: "CP-001",
+		Status:        domain.TransactionStatusStopped,
+		StartTime:     start,
+		EndTime:       &end,
+		TotalEnergy:   20000,
+	}
+	billing := NewBillingService(&mocks.MockTransactionRepository{}, nil, nil, zap.NewNop())
+	billing.SetStationRepository(&mocks.MockChargePointRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Location: &domain.Location{State: "RJ"}}, nil
+		},
+	})
+
+	// Act
+	err := billing.ProcessPayment(context.Background(), tx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tx.Taxes.State != "RJ" {
+		t.Errorf("expected RJ rates, got state %q", tx.Taxes.State)
+	}
+	if tx.Taxes.Gross != tx.Cost {
+		t.Errorf("expected gross %.2f to equal the cost, got %.2f", tx.Cost, tx.Taxes.Gross)
+	}
+	if want := roundCents(tx.Cost * 0.20); tx.Taxes.ICMS != want {
+		t.Errorf("expected ICMS %.2f, got %.2f", want, tx.Taxes.ICMS)
+	}
+	assertSumsToGross(t, tx.Taxes)
+
+	// Assert - invoice lines add up to the total
+	invoice, err := billing.GenerateInvoice(context.Background(), tx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	doc := invoice.Document()
+	if sum := doc.EnergyCost + doc.IdleFee + doc.Taxes; math.Abs(sum-doc.Amount) > 0.001 {
+		t.Errorf("expected invoice lines to add up to %.2f, got %.2f", doc.Amount, sum)
+	}
+	if doc.ICMS != tx.Taxes.ICMS || doc.PIS != tx.Taxes.PIS || doc.COFINS != tx.Taxes.COFINS {
+		t.Errorf("expected invoice to carry the tax components, got %+v", doc)
+	}
+}
+
+func TestProcessPayment_UnknownStationUsesFallbackRate(t *testing.T) {
+	// Arrange
+	end := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)
+	tx := &domain.Transaction{
+		ID:            "tx-456",
+		ChargePointID: "CP-404",
+		Status:        domain.TransactionStatusStopped,
+		StartTime:     end.Add(-time.Hour),
+		EndTime:       &end,
+		TotalEnergy:   7000,
+	}
+	billing := NewBillingService(&mocks.MockTransactionRepository{}, nil, nil, zap.NewNop())
+	billing.SetStationRepository(&mocks.MockChargePointRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return nil, nil
+		},
+	})
+
+	// Act
+	err := billing.ProcessPayment(context.Background(), tx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tx.Taxes.State != "" {
+		t.Errorf("expected fallback rate without a state, got state %q", tx.Taxes.State)
+	}
+	if want := roundCents(tx.Cost * DefaultTaxConfig().FallbackICMS); tx.Taxes.ICMS != want {
+		t.Errorf("expected fallback ICMS %.2f, got %.2f", want, tx.Taxes.ICMS)
+	}
+	assertSumsToGross(t, tx.Taxes)
+}
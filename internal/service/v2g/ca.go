@@ -0,0 +1,157 @@
+package v2g
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrInvalidCSR is returned when a certificate signing request cannot be
+// parsed or its signature does not verify
+var ErrInvalidCSR = errors.New("invalid certificate signing request")
+
+// CertificateAuthority signs certificates for charge points
+type CertificateAuthority struct {
+	cert  *x509.Certificate
+	chain []*x509.Certificate // cert followed by its issuers
+	key   crypto.Signer
+}
+
+// LoadCertificateAuthority reads a CA certificate chain and its private key.
+// The first certificate in certPath must belong to the key.
+func LoadCertificateAuthority(certPath, keyPath string) (*CertificateAuthority, error) {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CA key pair: %w", err)
+	}
+
+	chain := make([]*x509.Certificate, 0, len(pair.Certificate))
+	for _, der := range pair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+		}
+		chain = append(chain, cert)
+	}
+
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("CA private key cannot sign")
+	}
+	return NewCertificateAuthority(chain, signer)
+}
+
+// NewCertificateAuthority creates a CA from an already parsed chain and key
+func NewCertificateAuthority(chain []*x509.Certificate, key crypto.Signer) (*CertificateAuthority, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("empty CA certificate chain")
+	}
+	if !chain[0].IsCA {
+		return nil, fmt.Errorf("certificate %q is not a CA", chain[0].Subject.CommonName)
+	}
+	return &CertificateAuthority{cert: chain[0], chain: chain, key: key}, nil
+}
+
+// Certificate returns the CA certificate that signs issued certificates
+func (ca *CertificateAuthority) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// ParseCSR decodes a PEM encoded CSR and verifies its signature
+func ParseCSR(csrPEM string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("%w: no CERTIFICATE REQUEST block", ErrInvalidCSR)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSR, err)
+	}
+	return csr, nil
+}
+
+// Sign issues a certificate for the CSR valid from now for validity. extKeyUsage
+// selects client (station to CSMS) or server (SECC) authentication. The
+// returned PEM holds the leaf followed by the CA chain.
+func (ca *CertificateAuthority) Sign(csr *x509.CertificateRequest, validity time.Duration, extKeyUsage x509.ExtKeyUsage) (*x509.Certificate, string, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	notAfter := now.Add(validity)
+	if notAfter.After(ca.cert.NotAfter) {
+		// A certificate cannot outlive its issuer
+		notAfter = ca.cert.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      csr.Subject,
+		DNSNames:     csr.DNSNames,
+		IPAddresses:  csr.IPAddresses,
+		// Tolerate small clock skew on the station
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    notAfter,
+		KeyUsage:    keyUsageFor(csr.PublicKey),
+		ExtKeyUsage: []x509.ExtKeyUsage{extKeyUsage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse signed certificate: %w", err)
+	}
+
+	chainPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	for _, issuer := range ca.chain {
+		chainPEM = append(chainPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: issuer.Raw})...)
+	}
+	return cert, string(chainPEM), nil
+}
+
+// keyUsageFor returns the key usage TLS needs for the public key type
+func keyUsageFor(pub crypto.PublicKey) x509.KeyUsage {
+	if _, ok := pub.(*ecdsa.PublicKey); ok {
+		return x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement
+	}
+	return x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+}
+
+// parsePEMCertificates parses every CERTIFICATE block in pemData
+func parsePEMCertificates(pemData []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	for {
+		block, rest := pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+
+		if block.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+
+		pemData = rest
+	}
+
+	return certs, nil
+}
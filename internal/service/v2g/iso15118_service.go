@@ -3,7 +3,6 @@ package v2g
 import (
 	"context"
 	"crypto/x509"
-	"encoding/pem"
 	"fmt"
 	"sync"
 	"time"
@@ -365,26 +364,7 @@ func (s *ISO15118Service) GetCertificateStatus(ctx context.Context, emaid string
 
 // parseCertificateChain parses a PEM-encoded certificate chain
 func (s *ISO15118Service) parseCertificateChain(pemData []byte) ([]*x509.Certificate, error) {
-	var certs []*x509.Certificate
-
-	for {
-		block, rest := pem.Decode(pemData)
-		if block == nil {
-			break
-		}
-
-		if block.Type == "CERTIFICATE" {
-			cert, err := x509.ParseCertificate(block.Bytes)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse certificate: %w", err)
-			}
-			certs = append(certs, cert)
-		}
-
-		pemData = rest
-	}
-
-	return certs, nil
+	return parsePEMCertificates(pemData)
 }
 
 // extractVehicleIdentity extracts vehicle identity from a certificate
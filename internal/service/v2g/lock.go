@@ -0,0 +1,109 @@
+package v2g
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ErrChargePointBusy is returned when another instance is already starting
+// V2G on the charge point
+var ErrChargePointBusy = errors.New("V2G operation already in progress for charge point")
+
+// ErrDischargeActive is returned when the charge point already discharges
+var ErrDischargeActive = errors.New("charge point already has an active V2G session")
+
+// defaultLockTTL bounds how long a crashed instance can block a charge point
+const defaultLockTTL = 30 * time.Second
+
+// SetLocker makes V2G starts exclusive per charge point across instances.
+// Without a locker each instance only relies on its own session state.
+func (s *Service) SetLocker(locker ports.Locker) {
+	s.locker = locker
+}
+
+// withChargePointLock runs fn while holding the V2G lock of chargePointID.
+// The lock is refreshed while fn runs; if it is lost, fn's context is
+// cancelled so the operation does not continue unprotected.
+func (s *Service) withChargePointLock(ctx context.Context, chargePointID string, fn func(ctx context.Context) error) error {
+	if s.locker == nil {
+		return fn(ctx)
+	}
+
+	ttl := s.config.LockTTL
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+
+	lock, err := s.locker.TryLock(ctx, "v2g:"+chargePointID, ttl)
+	if errors.Is(err, ports.ErrLockHeld) {
+		return ErrChargePointBusy
+	}
+	if err != nil {
+		return err
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-lockCtx.Done():
+				return
+			case <-ticker.C:
+				if err := lock.Refresh(lockCtx, ttl); err != nil {
+					if lockCtx.Err() != nil {
+						return
+					}
+					s.log.Warn("Lost V2G lock", zap.String("chargePointID", chargePointID), zap.Error(err))
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	defer func() {
+		cancel()
+		<-renewed
+
+		// Release even if the caller's context is already done
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer releaseCancel()
+		if err := lock.Release(releaseCtx); err != nil {
+			s.log.Warn("Failed to release V2G lock", zap.String("chargePointID", chargePointID), zap.Error(err))
+		}
+	}()
+
+	return fn(lockCtx)
+}
+
+// hasActiveSession reports whether the charge point discharges, either on
+// this instance or, through the repository, on another one
+func (s *Service) hasActiveSession(ctx context.Context, chargePointID string) bool {
+	if existing, _ := s.GetActiveSession(ctx, chargePointID); existing != nil {
+		return true
+	}
+	if s.v2gRepo == nil {
+		return false
+	}
+
+	sessions, err := s.v2gRepo.GetSessionsByChargePoint(ctx, chargePointID, 10)
+	if err != nil {
+		return false
+	}
+	for _, session := range sessions {
+		if session.Status == domain.V2GStatusActive && session.EndTime == nil {
+			return true
+		}
+	}
+	return false
+}
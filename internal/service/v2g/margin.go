@@ -0,0 +1,72 @@
+package v2g
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// ValidateOperatorMargin checks margin is a fraction of the gross
+// compensation the operator keeps, in [0, 1)
+func ValidateOperatorMargin(margin float64) error {
+	if margin < 0 || margin >= 1 {
+		return fmt.Errorf("%w: operator margin must be in [0, 1), got %v", domain.ErrValidation, margin)
+	}
+	return nil
+}
+
+// SetOperatorMargin overrides the operator margin applied to a user's V2G
+// compensation, e.g. for a premium plan or a commercial contract. A nil
+// margin reverts the user to Config.OperatorMargin.
+func (s *Service) SetOperatorMargin(ctx context.Context, userID string, margin *float64) error {
+	if margin != nil {
+		if err := ValidateOperatorMargin(*margin); err != nil {
+			return err
+		}
+	}
+	if s.v2gRepo == nil {
+		return errors.New("v2g repository not configured")
+	}
+
+	prefs, err := s.v2gRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get V2G preferences: %w", err)
+	}
+	prefs.UserID = userID
+	prefs.OperatorMargin = margin
+	return s.v2gRepo.SavePreferences(ctx, prefs)
+}
+
+// operatorMargin returns the margin negotiated for userID, or fallback when
+// there is none. A margin that cannot be read or is out of range falls back
+// too, so compensation is never computed from a bad override.
+func operatorMargin(ctx context.Context, repo ports.V2GRepository, userID string, fallback float64, log *zap.Logger) float64 {
+	if repo == nil || userID == "" {
+		return fallback
+	}
+
+	prefs, err := repo.GetPreferences(ctx, userID)
+	if err != nil {
+		log.Warn("Failed to get V2G preferences, using default operator margin",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return fallback
+	}
+	if prefs == nil || prefs.OperatorMargin == nil {
+		return fallback
+	}
+	if err := ValidateOperatorMargin(*prefs.OperatorMargin); err != nil {
+		log.Warn("Ignoring invalid operator margin of user",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return fallback
+	}
+	return *prefs.OperatorMargin
+}
@@ -101,9 +101,10 @@ func (s *V2GPaymentService) CalculateAndRecordCompensation(ctx context.Context,
 	// Calculate average price
 	avgPrice := (session.GridPriceAtStart + session.CurrentGridPrice) / 2
 
-	// Calculate amounts
+	// Calculate amounts, with the user's operator margin or the default one
+	margin := operatorMargin(ctx, s.v2gRepo, session.UserID, s.config.OperatorMargin, s.log)
 	grossAmount := energyDischarged * avgPrice
-	netAmount := grossAmount * (1 - s.config.OperatorMargin)
+	netAmount := grossAmount * (1 - margin)
 
 	record := &V2GCompensationRecord{
 		ID:                  uuid.New().String(),
@@ -111,7 +112,7 @@ func (s *V2GPaymentService) CalculateAndRecordCompensation(ctx context.Context,
 		UserID:              session.UserID,
 		EnergyDischargedKWh: energyDischarged,
 		AverageGridPrice:    avgPrice,
-		OperatorMargin:      s.config.OperatorMargin,
+		OperatorMargin:      margin,
 		GrossAmount:         grossAmount,
 		NetAmount:           netAmount,
 		Currency:            s.config.PayoutCurrency,
@@ -162,6 +163,7 @@ func (s *V2GPaymentService) ProcessPayout(ctx context.Context, record *V2GCompen
 		ctx,
 		record.UserID,
 		record.NetAmount,
+		record.Currency,
 		fmt.Sprintf("v2g-compensation-%s", record.ID),
 	)
 	if err != nil {
@@ -38,7 +38,7 @@ func (m *MockWalletService) GetWallet(ctx context.Context, userID string) (*doma
 	}, nil
 }
 
-func (m *MockWalletService) AddFunds(ctx context.Context, userID string, amount float64, paymentID string) error {
+func (m *MockWalletService) AddFunds(ctx context.Context, userID string, amount float64, currency string, paymentID string) error {
 	m.wallets[userID] += amount
 	m.transactions = append(m.transactions, WalletTransaction{
 		UserID:      userID,
@@ -48,7 +48,7 @@ func (m *MockWalletService) AddFunds(ctx context.Context, userID string, amount
 	return nil
 }
 
-func (m *MockWalletService) DeductFunds(ctx context.Context, userID string, amount float64, description string, referenceID string) error {
+func (m *MockWalletService) DeductFunds(ctx context.Context, userID string, amount float64, currency string, description string, referenceID string) error {
 	m.wallets[userID] -= amount
 	m.transactions = append(m.transactions, WalletTransaction{
 		UserID:      userID,
@@ -62,7 +62,7 @@ func (m *MockWalletService) GetTransactions(ctx context.Context, userID string,
 	return nil, nil
 }
 
-func (m *MockWalletService) HasSufficientBalance(ctx context.Context, userID string, amount float64) (bool, error) {
+func (m *MockWalletService) HasSufficientBalance(ctx context.Context, userID string, amount float64, currency string) (bool, error) {
 	return m.wallets[userID] >= amount, nil
 }
 
@@ -22,11 +22,14 @@ type Service struct {
 	gridPriceService ports.GridPriceService
 	ocppServer      ports.OCPPCommandService
 	mq              ports.MessageQueue
+	locker          ports.Locker // optional, see SetLocker
+	chargeWindows   ports.ChargingWindowScheduler // optional, see SetChargingWindowScheduler
 	log             *zap.Logger
 
 	// In-memory tracking
 	activeSessions  map[string]*domain.V2GSession
 	capabilities    map[string]*domain.V2GCapability
+	windows         map[string]chargingWindow // transaction ID → smart charging window
 	mu              sync.RWMutex
 
 	config          *Config
@@ -39,6 +42,10 @@ type Config struct {
 	OperatorMargin          float64 // Operator margin on V2G compensation (0.10 = 10%)
 	MinGridPriceForV2G      float64 // Minimum grid price to consider V2G worthwhile (R$/kWh)
 	CompensationCurrency    string  // Currency for compensation (BRL)
+	LockTTL                 time.Duration // Expiry of the per charge point V2G lock, refreshed while held
+	DefaultDepartureSOC     int     // SOC smart charging aims for when the user set none (%)
+	DefaultChargePowerKW    float64 // Charging power assumed when the connector reports none
+	DefaultChargeEnergyKWh  float64 // Energy smart charging plans for when the vehicle reports no SOC
 }
 
 // DefaultConfig returns default V2G configuration
@@ -49,6 +56,10 @@ func DefaultConfig() *Config {
 		OperatorMargin:         0.10,  // 10%
 		MinGridPriceForV2G:     0.80,  // R$ 0.80/kWh
 		CompensationCurrency:   "BRL",
+		LockTTL:                defaultLockTTL,
+		DefaultDepartureSOC:    80,    // 80%
+		DefaultChargePowerKW:   11.0,  // 11 kW AC
+		DefaultChargeEnergyKWh: 30.0,  // 30 kWh
 	}
 }
 
@@ -77,6 +88,7 @@ func NewService(
 		log:              log,
 		activeSessions:   make(map[string]*domain.V2GSession),
 		capabilities:     make(map[string]*domain.V2GCapability),
+		windows:          make(map[string]chargingWindow),
 		config:           config,
 	}
 }
@@ -92,8 +104,22 @@ type DischargeRequest struct {
 	EndTime       *time.Time
 }
 
-// StartDischarge initiates a V2G discharge session
+// StartDischarge initiates a V2G discharge session. Only one instance at a
+// time may start discharge on a charge point.
 func (s *Service) StartDischarge(ctx context.Context, req *DischargeRequest) (*domain.V2GSession, error) {
+	var session *domain.V2GSession
+	err := s.withChargePointLock(ctx, req.ChargePointID, func(ctx context.Context) error {
+		if s.hasActiveSession(ctx, req.ChargePointID) {
+			return ErrDischargeActive
+		}
+		var err error
+		session, err = s.startDischarge(ctx, req)
+		return err
+	})
+	return session, err
+}
+
+func (s *Service) startDischarge(ctx context.Context, req *DischargeRequest) (*domain.V2GSession, error) {
 	// Validate charge point exists and is connected
 	device, err := s.deviceService.GetDevice(ctx, req.ChargePointID)
 	if err != nil {
@@ -175,9 +201,10 @@ func (s *Service) StartDischarge(ctx context.Context, req *DischargeRequest) (*d
 
 	session.Status = domain.V2GStatusActive
 
-	// Store session
+	// Store a private copy so later metric updates never touch the
+	// pointer returned to the caller
 	s.mu.Lock()
-	s.activeSessions[session.ID] = session
+	s.activeSessions[session.ID] = cloneSession(session)
 	s.mu.Unlock()
 
 	// Persist to database
@@ -274,7 +301,7 @@ func (s *Service) GetActiveSession(ctx context.Context, chargePointID string) (*
 
 	for _, session := range s.activeSessions {
 		if session.ChargePointID == chargePointID && session.Status == domain.V2GStatusActive {
-			return session, nil
+			return cloneSession(session), nil
 		}
 	}
 
@@ -285,6 +312,9 @@ func (s *Service) GetActiveSession(ctx context.Context, chargePointID string) (*
 func (s *Service) GetSession(ctx context.Context, sessionID string) (*domain.V2GSession, error) {
 	s.mu.RLock()
 	session, ok := s.activeSessions[sessionID]
+	if ok {
+		session = cloneSession(session)
+	}
 	s.mu.RUnlock()
 
 	if ok {
@@ -299,6 +329,9 @@ func (s *Service) GetSession(ctx context.Context, sessionID string) (*domain.V2G
 	return nil, fmt.Errorf("session %s not found", sessionID)
 }
 
+// Ensure Service implements ports.V2GCompensationCalculator
+var _ ports.V2GCompensationCalculator = (*Service)(nil)
+
 // CalculateCompensation calculates the compensation for a V2G session
 func (s *Service) CalculateCompensation(ctx context.Context, session *domain.V2GSession) (*domain.V2GCompensation, error) {
 	if session.EnergyTransferred >= 0 {
@@ -321,15 +354,16 @@ func (s *Service) CalculateCompensation(ctx context.Context, session *domain.V2G
 	// Calculate gross amount (what the energy is worth)
 	grossAmount := energyDischarged * avgPrice
 
-	// Apply operator margin
-	netAmount := grossAmount * (1 - s.config.OperatorMargin)
+	// Apply the user's operator margin, or the default one
+	margin := operatorMargin(ctx, s.v2gRepo, session.UserID, s.config.OperatorMargin, s.log)
+	netAmount := grossAmount * (1 - margin)
 
 	return &domain.V2GCompensation{
 		SessionID:           session.ID,
 		UserID:              session.UserID,
 		EnergyDischargedKWh: energyDischarged,
 		AverageGridPrice:    avgPrice,
-		OperatorMargin:      s.config.OperatorMargin,
+		OperatorMargin:      margin,
 		GrossAmount:         grossAmount,
 		NetAmount:           netAmount,
 		Currency:            s.config.CompensationCurrency,
@@ -368,10 +402,25 @@ func (s *Service) CheckV2GCapability(ctx context.Context, chargePointID string)
 	return ocppCap, nil
 }
 
-// SetUserPreferences sets V2G preferences for a user
+// SetUserPreferences sets V2G preferences for a user. The operator margin
+// is kept as is: it is negotiated, see SetOperatorMargin.
 func (s *Service) SetUserPreferences(ctx context.Context, userID string, prefs *domain.V2GPreferences) error {
+	if prefs.DepartureTime != "" {
+		if _, err := time.Parse(departureLayout, prefs.DepartureTime); err != nil {
+			return fmt.Errorf("%w: departure time must be HH:MM, got %q", domain.ErrValidation, prefs.DepartureTime)
+		}
+	}
+	if prefs.DepartureSOC < 0 || prefs.DepartureSOC > 100 {
+		return fmt.Errorf("%w: departure SOC must be between 0 and 100, got %d", domain.ErrValidation, prefs.DepartureSOC)
+	}
+
 	prefs.UserID = userID
 	if s.v2gRepo != nil {
+		current, err := s.v2gRepo.GetPreferences(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get V2G preferences: %w", err)
+		}
+		prefs.OperatorMargin = current.OperatorMargin
 		return s.v2gRepo.SavePreferences(ctx, prefs)
 	}
 	return nil
@@ -407,6 +456,11 @@ func (s *Service) OptimizeV2G(ctx context.Context, chargePointID string, userID
 		return nil // Auto-discharge not enabled
 	}
 
+	// Discharging would undo the smart charging window the EV is in
+	if s.inChargingWindow(chargePointID, time.Now()) {
+		return nil
+	}
+
 	// Get current grid price
 	currentPrice, err := s.gridPriceService.GetCurrentPrice(ctx)
 	if err != nil {
@@ -424,22 +478,28 @@ func (s *Service) OptimizeV2G(ctx context.Context, chargePointID string, userID
 		return nil
 	}
 
-	// Check if already in a session
-	existingSession, _ := s.GetActiveSession(ctx, chargePointID)
-	if existingSession != nil {
-		return nil // Already discharging
-	}
+	// Another instance may be optimizing the same charge point
+	err = s.withChargePointLock(ctx, chargePointID, func(ctx context.Context) error {
+		// Check if already in a session
+		if s.hasActiveSession(ctx, chargePointID) {
+			return ErrDischargeActive
+		}
 
-	// Start discharge
-	req := &DischargeRequest{
-		ChargePointID: chargePointID,
-		ConnectorID:   cap.ConnectorID,
-		UserID:        userID,
-		MaxEnergyKWh:  prefs.MaxDischargeKWh,
-		MinBatterySOC: prefs.PreserveSOC,
-	}
+		// Start discharge
+		req := &DischargeRequest{
+			ChargePointID: chargePointID,
+			ConnectorID:   cap.ConnectorID,
+			UserID:        userID,
+			MaxEnergyKWh:  prefs.MaxDischargeKWh,
+			MinBatterySOC: prefs.PreserveSOC,
+		}
 
-	_, err = s.StartDischarge(ctx, req)
+		_, err := s.startDischarge(ctx, req)
+		return err
+	})
+	if errors.Is(err, ErrChargePointBusy) || errors.Is(err, ErrDischargeActive) {
+		return nil // Already being handled or discharging
+	}
 	if err != nil {
 		return err
 	}
@@ -455,6 +515,17 @@ func (s *Service) OptimizeV2G(ctx context.Context, chargePointID string, userID
 
 // UpdateSessionMetrics updates energy and power metrics for an active session
 func (s *Service) UpdateSessionMetrics(ctx context.Context, sessionID string, powerKW, energyKWh float64, currentSOC int) error {
+	// Fetch the grid price before taking the lock so a slow price source
+	// does not block readers of active sessions
+	var gridPrice float64
+	hasGridPrice := false
+	if s.gridPriceService != nil {
+		if price, err := s.gridPriceService.GetCurrentPrice(ctx); err == nil {
+			gridPrice = price
+			hasGridPrice = true
+		}
+	}
+
 	s.mu.Lock()
 	session, ok := s.activeSessions[sessionID]
 	if !ok {
@@ -465,18 +536,17 @@ func (s *Service) UpdateSessionMetrics(ctx context.Context, sessionID string, po
 	session.ActualPowerKW = powerKW
 	session.EnergyTransferred = energyKWh // Will be negative for discharge
 	session.CurrentSOC = currentSOC
-
-	// Get current grid price
-	if s.gridPriceService != nil {
-		if price, err := s.gridPriceService.GetCurrentPrice(ctx); err == nil {
-			session.CurrentGridPrice = price
-		}
+	if hasGridPrice {
+		session.CurrentGridPrice = gridPrice
 	}
+
+	// Everything after the unlock works on a snapshot
+	snapshot := cloneSession(session)
 	s.mu.Unlock()
 
 	// Persist update
 	if s.v2gRepo != nil {
-		if err := s.v2gRepo.UpdateSession(ctx, session); err != nil {
+		if err := s.v2gRepo.UpdateSession(ctx, snapshot); err != nil {
 			s.log.Error("Failed to update V2G session", zap.Error(err))
 		}
 	}
@@ -492,11 +562,11 @@ func (s *Service) UpdateSessionMetrics(ctx context.Context, sessionID string, po
 	}
 
 	// Check if SOC reached minimum
-	if currentSOC <= session.MinBatterySOC {
+	if currentSOC <= snapshot.MinBatterySOC {
 		s.log.Info("V2G session stopping due to min SOC reached",
 			zap.String("sessionID", sessionID),
 			zap.Int("currentSOC", currentSOC),
-			zap.Int("minSOC", session.MinBatterySOC),
+			zap.Int("minSOC", snapshot.MinBatterySOC),
 		)
 		return s.StopDischarge(ctx, sessionID)
 	}
@@ -504,6 +574,18 @@ func (s *Service) UpdateSessionMetrics(ctx context.Context, sessionID string, po
 	return nil
 }
 
+// cloneSession returns a copy of a session that can be read or modified
+// without holding s.mu. Callers must hold at least a read lock while copying
+// a session owned by activeSessions.
+func cloneSession(session *domain.V2GSession) *domain.V2GSession {
+	clone := *session
+	if session.EndTime != nil {
+		endTime := *session.EndTime
+		clone.EndTime = &endTime
+	}
+	return &clone
+}
+
 // GetUserStats returns V2G statistics for a user
 func (s *Service) GetUserStats(ctx context.Context, userID string, startDate, endDate time.Time) (*domain.V2GStats, error) {
 	if s.v2gRepo != nil {
@@ -2,17 +2,25 @@ package v2g
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/seu-repo/sigec-ve/internal/adapter/cache"
 	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
 	"github.com/seu-repo/sigec-ve/internal/ports"
 )
 
 // MockV2GRepository is a mock implementation of V2GRepository
 type MockV2GRepository struct {
+	mu          sync.Mutex
 	sessions    map[string]*domain.V2GSession
 	preferences map[string]*domain.V2GPreferences
 }
@@ -25,16 +33,22 @@ func NewMockV2GRepository() *MockV2GRepository {
 }
 
 func (m *MockV2GRepository) CreateSession(ctx context.Context, session *domain.V2GSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.sessions[session.ID] = session
 	return nil
 }
 
 func (m *MockV2GRepository) UpdateSession(ctx context.Context, session *domain.V2GSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.sessions[session.ID] = session
 	return nil
 }
 
 func (m *MockV2GRepository) GetSession(ctx context.Context, sessionID string) (*domain.V2GSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if session, ok := m.sessions[sessionID]; ok {
 		return session, nil
 	}
@@ -42,6 +56,8 @@ func (m *MockV2GRepository) GetSession(ctx context.Context, sessionID string) (*
 }
 
 func (m *MockV2GRepository) GetSessionsByChargePoint(ctx context.Context, chargePointID string, limit int) ([]domain.V2GSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	var result []domain.V2GSession
 	for _, s := range m.sessions {
 		if s.ChargePointID == chargePointID {
@@ -55,6 +71,8 @@ func (m *MockV2GRepository) GetSessionsByChargePoint(ctx context.Context, charge
 }
 
 func (m *MockV2GRepository) GetSessionsByUser(ctx context.Context, userID string, limit int) ([]domain.V2GSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	var result []domain.V2GSession
 	for _, s := range m.sessions {
 		if s.UserID == userID {
@@ -157,21 +175,24 @@ func NewMockOCPPCommandService() *MockOCPPCommandService {
 	}
 }
 
-func (m *MockOCPPCommandService) RemoteStartTransaction(ctx context.Context, chargePointID, idToken string, evseID *int) error {
+func (m *MockOCPPCommandService) RemoteStartTransaction(ctx context.Context, chargePointID, idToken string, tokenType domain.IdTokenType, evseID *int) error {
 	return nil
 }
 func (m *MockOCPPCommandService) RemoteStopTransaction(ctx context.Context, chargePointID, transactionID string) error {
 	return nil
 }
-func (m *MockOCPPCommandService) Reset(ctx context.Context, chargePointID string, resetType string, evseID *int) error {
-	return nil
+func (m *MockOCPPCommandService) Reset(ctx context.Context, chargePointID string, resetType string, evseID *int) (string, error) {
+	return "Accepted", nil
 }
 func (m *MockOCPPCommandService) TriggerMessage(ctx context.Context, chargePointID, requestedMessage string, evseID *int) error {
 	return nil
 }
-func (m *MockOCPPCommandService) SetChargingProfile(ctx context.Context, chargePointID string, evseID int, profile interface{}) error {
+func (m *MockOCPPCommandService) SetChargingProfile(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) error {
 	return nil
 }
+func (m *MockOCPPCommandService) PreviewChargingProfile(ctx context.Context, chargePointID string, evseID int, profile domain.ChargingProfile) (*ports.ChargingProfilePreview, error) {
+	return &ports.ChargingProfilePreview{Valid: true}, nil
+}
 func (m *MockOCPPCommandService) ClearChargingProfile(ctx context.Context, chargePointID string, profileID *int, evseID *int) error {
 	return nil
 }
@@ -181,6 +202,9 @@ func (m *MockOCPPCommandService) UpdateFirmware(ctx context.Context, chargePoint
 func (m *MockOCPPCommandService) UpdateFirmwareSigned(ctx context.Context, chargePointID, firmwareURL, retrieveDateTime, signingCert, signature string, retries, retryInterval *int) error {
 	return nil
 }
+func (m *MockOCPPCommandService) PublishFirmware(ctx context.Context, chargePointID, location, checksum string, retries, retryInterval *int) error {
+	return nil
+}
 func (m *MockOCPPCommandService) UnlockConnector(ctx context.Context, chargePointID string, evseID, connectorID int) error {
 	return nil
 }
@@ -190,12 +214,39 @@ func (m *MockOCPPCommandService) ChangeAvailability(ctx context.Context, chargeP
 func (m *MockOCPPCommandService) GetVariables(ctx context.Context, chargePointID string, variables []ports.GetVariableRequest) ([]ports.GetVariableResponse, error) {
 	return nil, nil
 }
-func (m *MockOCPPCommandService) SetVariables(ctx context.Context, chargePointID string, variables []ports.SetVariableRequest) error {
-	return nil
+func (m *MockOCPPCommandService) SetVariables(ctx context.Context, chargePointID string, variables []ports.SetVariableRequest) ([]ports.SetVariableResult, error) {
+	return nil, nil
 }
 func (m *MockOCPPCommandService) GetLog(ctx context.Context, chargePointID, logType, uploadURL string) error {
 	return nil
 }
+func (m *MockOCPPCommandService) ReserveNow(ctx context.Context, chargePointID string, reservationID int, connectorID int, idToken string, expiry time.Time) error {
+	return nil
+}
+func (m *MockOCPPCommandService) CancelReservation(ctx context.Context, chargePointID string, reservationID int) error {
+	return nil
+}
+func (m *MockOCPPCommandService) SetVariableMonitoring(ctx context.Context, chargePointID string, monitors []ports.VariableMonitor) ([]ports.VariableMonitorResult, error) {
+	return nil, nil
+}
+func (m *MockOCPPCommandService) SetMonitoringBase(ctx context.Context, chargePointID, monitoringBase string) (string, error) {
+	return "Accepted", nil
+}
+func (m *MockOCPPCommandService) DataTransfer(ctx context.Context, chargePointID, vendorID, messageID string, data json.RawMessage) (*ports.DataTransferResult, error) {
+	return &ports.DataTransferResult{Status: "Accepted"}, nil
+}
+func (m *MockOCPPCommandService) ClearCache(ctx context.Context, chargePointID string) (string, error) {
+	return "Accepted", nil
+}
+func (m *MockOCPPCommandService) InstallCertificate(ctx context.Context, chargePointID, certificateType, certificate string) (string, error) {
+	return "Accepted", nil
+}
+func (m *MockOCPPCommandService) DeleteCertificate(ctx context.Context, chargePointID string, hashData ports.CertificateHashData) (string, error) {
+	return "Accepted", nil
+}
+func (m *MockOCPPCommandService) GetInstalledCertificateIds(ctx context.Context, chargePointID string, certificateTypes []string) ([]ports.InstalledCertificate, error) {
+	return nil, nil
+}
 func (m *MockOCPPCommandService) SetV2GChargingProfile(ctx context.Context, chargePointID string, evseID int, dischargePowerKW float64, durationSeconds int) error {
 	return nil
 }
@@ -211,6 +262,9 @@ func (m *MockOCPPCommandService) GetV2GCapability(ctx context.Context, chargePoi
 		ISO15118Support:       true,
 	}, nil
 }
+func (m *MockOCPPCommandService) BulkRemoteStop(ctx context.Context, targets []ports.RemoteStopTarget) []ports.BulkCommandResult {
+	return nil
+}
 func (m *MockOCPPCommandService) IsConnected(chargePointID string) bool {
 	return m.connected[chargePointID]
 }
@@ -374,3 +428,261 @@ func TestV2GService_CalculateCompensation(t *testing.T) {
 		t.Errorf("Expected currency BRL, got %s", compensation.Currency)
 	}
 }
+
+func TestV2GService_CalculateCompensationUsesUserMargin(t *testing.T) {
+	service, repo := createTestV2GService()
+	ctx := context.Background()
+
+	if err := service.SetOperatorMargin(ctx, "premium", ptrFloat(0.02)); err != nil {
+		t.Fatalf("SetOperatorMargin failed: %v", err)
+	}
+
+	discharge := func(userID string) *domain.V2GSession {
+		return &domain.V2GSession{
+			ID:                "session-" + userID,
+			UserID:            userID,
+			Direction:         domain.V2GDirectionDischarging,
+			EnergyTransferred: -20.0,
+			GridPriceAtStart:  0.80,
+			CurrentGridPrice:  0.90,
+		}
+	}
+
+	standard, err := service.CalculateCompensation(ctx, discharge("standard"))
+	if err != nil {
+		t.Fatalf("CalculateCompensation failed: %v", err)
+	}
+	premium, err := service.CalculateCompensation(ctx, discharge("premium"))
+	if err != nil {
+		t.Fatalf("CalculateCompensation failed: %v", err)
+	}
+
+	if standard.OperatorMargin != 0.10 {
+		t.Errorf("Expected the default margin 0.10, got %f", standard.OperatorMargin)
+	}
+	if premium.OperatorMargin != 0.02 {
+		t.Errorf("Expected the user's margin 0.02, got %f", premium.OperatorMargin)
+	}
+	if premium.NetAmount <= standard.NetAmount {
+		t.Errorf("Expected premium net %f above standard net %f", premium.NetAmount, standard.NetAmount)
+	}
+	if want := 20.0 * 0.85 * 0.98; math.Abs(premium.NetAmount-want) > 1e-9 {
+		t.Errorf("Expected premium net %f, got %f", want, premium.NetAmount)
+	}
+
+	// Users cannot change a negotiated margin through their own preferences
+	if err := service.SetUserPreferences(ctx, "premium", &domain.V2GPreferences{AutoDischarge: true}); err != nil {
+		t.Fatalf("SetUserPreferences failed: %v", err)
+	}
+	if margin := repo.preferences["premium"].OperatorMargin; margin == nil || *margin != 0.02 {
+		t.Errorf("Expected the margin 0.02 kept, got %v", margin)
+	}
+}
+
+func TestV2GService_SetOperatorMarginValidatesRange(t *testing.T) {
+	tests := []struct {
+		margin  float64
+		wantErr bool
+	}{
+		{0, false},
+		{0.5, false},
+		{0.9999, false},
+		{-0.01, true},
+		{1, true},
+		{1.5, true},
+	}
+
+	for _, tt := range tests {
+		service, repo := createTestV2GService()
+		err := service.SetOperatorMargin(context.Background(), "user123", ptrFloat(tt.margin))
+
+		if tt.wantErr {
+			if !errors.Is(err, domain.ErrValidation) {
+				t.Errorf("Margin %v: expected a validation error, got %v", tt.margin, err)
+			}
+			if repo.preferences["user123"] != nil {
+				t.Errorf("Margin %v: expected nothing saved", tt.margin)
+			}
+		} else if err != nil {
+			t.Errorf("Margin %v: expected no error, got %v", tt.margin, err)
+		}
+	}
+}
+
+func ptrFloat(v float64) *float64 {
+	return &v
+}
+
+func TestV2GService_UpdateSessionMetricsConcurrentReads(t *testing.T) {
+	service, _ := createTestV2GService()
+	ctx := context.Background()
+
+	service.activeSessions["session123"] = &domain.V2GSession{
+		ID:            "session123",
+		ChargePointID: "CP001",
+		UserID:        "user123",
+		Direction:     domain.V2GDirectionDischarging,
+		Status:        domain.V2GStatusActive,
+		MinBatterySOC: 10,
+		CurrentSOC:    80,
+		StartTime:     time.Now(),
+	}
+
+	const iterations = 200
+	var wg sync.WaitGroup
+
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				energy := -float64(worker*iterations+i) / 10
+				if err := service.UpdateSessionMetrics(ctx, "session123", 11.0, energy, 50); err != nil {
+					t.Errorf("UpdateSessionMetrics failed: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				session, err := service.GetActiveSession(ctx, "CP001")
+				if err != nil {
+					t.Errorf("GetActiveSession failed: %v", err)
+					return
+				}
+				if session == nil {
+					t.Error("Expected active session for CP001")
+					return
+				}
+				// Mutating the returned copy must not leak into the service
+				session.CurrentSOC = 0
+				_ = session.EnergyTransferred + session.ActualPowerKW
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	session, err := service.GetActiveSession(ctx, "CP001")
+	if err != nil {
+		t.Fatalf("GetActiveSession failed: %v", err)
+	}
+	if session == nil {
+		t.Fatal("Expected active session after concurrent updates")
+	}
+	if session.CurrentSOC != 50 {
+		t.Errorf("Expected SOC 50, got %d", session.CurrentSOC)
+	}
+	if session.ActualPowerKW != 11.0 {
+		t.Errorf("Expected power 11.0, got %f", session.ActualPowerKW)
+	}
+}
+
+// slowDischargeOCPP counts V2G profiles and takes a while to apply them
+type slowDischargeOCPP struct {
+	*MockOCPPCommandService
+	delay    time.Duration
+	profiles int32
+}
+
+func (m *slowDischargeOCPP) SetV2GChargingProfile(ctx context.Context, chargePointID string, evseID int, dischargePowerKW float64, durationSeconds int) error {
+	atomic.AddInt32(&m.profiles, 1)
+	time.Sleep(m.delay)
+	return nil
+}
+
+func (m *slowDischargeOCPP) GetV2GCapability(ctx context.Context, chargePointID string) (*domain.V2GCapability, error) {
+	return &domain.V2GCapability{
+		ChargePointID:       chargePointID,
+		ConnectorID:         1,
+		Supported:           true,
+		MaxDischargePowerKW: 11.0,
+		CurrentSOC:          80,
+		LastUpdated:         time.Now(),
+	}, nil
+}
+
+func TestV2GService_OptimizeV2GLockedAcrossInstances(t *testing.T) {
+	// Arrange: two replicas sharing the lock store and the session database
+	repo := NewMockV2GRepository()
+	repo.SavePreferences(context.Background(), &domain.V2GPreferences{
+		UserID:          "user123",
+		AutoDischarge:   true,
+		MinGridPrice:    0.80,
+		MaxDischargeKWh: 20,
+		PreserveSOC:     20,
+	})
+	devices := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{ID: id, Status: domain.ChargePointStatusOccupied}, nil
+		},
+	}
+	ocpp := &slowDischargeOCPP{MockOCPPCommandService: NewMockOCPPCommandService(), delay: 150 * time.Millisecond}
+	locker := cache.NewLocalLocker()
+
+	// The operation outlives the lock TTL, so it only stays exclusive if the
+	// lock is renewed
+	config := DefaultConfig()
+	config.LockTTL = 60 * time.Millisecond
+
+	replicas := make([]*Service, 2)
+	for i := range replicas {
+		replicas[i] = NewService(repo, devices, nil, NewMockGridPriceService(), ocpp, nil, zap.NewNop(), config)
+		replicas[i].SetLocker(locker)
+	}
+
+	// Act
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for _, replica := range replicas {
+		wg.Add(1)
+		go func(replica *Service) {
+			defer wg.Done()
+			if err := replica.OptimizeV2G(ctx, "CP001", "user123"); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}(replica)
+		time.Sleep(100 * time.Millisecond) // the second replica arrives after the first TTL
+	}
+	wg.Wait()
+
+	// A later run on the other replica sees the session in the database
+	if err := replicas[1].OptimizeV2G(ctx, "CP001", "user123"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Assert
+	if got := atomic.LoadInt32(&ocpp.profiles); got != 1 {
+		t.Errorf("expected discharge to be started once, got %d", got)
+	}
+
+	// The lock is released afterwards
+	lock, err := locker.TryLock(ctx, "v2g:CP001", time.Second)
+	if err != nil {
+		t.Fatalf("expected the lock to be free, got %v", err)
+	}
+	lock.Release(ctx)
+}
+
+func TestV2GService_StartDischargeRejectsWhileLocked(t *testing.T) {
+	// Arrange
+	service, _ := createTestV2GService()
+	locker := cache.NewLocalLocker()
+	service.SetLocker(locker)
+	ctx := context.Background()
+	held, _ := locker.TryLock(ctx, "v2g:CP001", time.Minute)
+	defer held.Release(ctx)
+
+	// Act
+	_, err := service.StartDischarge(ctx, &DischargeRequest{ChargePointID: "CP001", ConnectorID: 1, UserID: "user123"})
+
+	// Assert
+	if err != ErrChargePointBusy {
+		t.Errorf("expected ErrChargePointBusy, got %v", err)
+	}
+}
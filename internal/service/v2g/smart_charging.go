@@ -0,0 +1,293 @@
+package v2g
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// departureLayout is the format of V2GPreferences.DepartureTime
+const departureLayout = "15:04"
+
+// defaultSmartChargeInterval is how often sessions are planned when no
+// interval is configured
+const defaultSmartChargeInterval = 5 * time.Minute
+
+// chargingWindow is where a session was scheduled to charge
+type chargingWindow struct {
+	chargePointID string
+	start, end    time.Time
+}
+
+// SetChargingWindowScheduler enables smart charging: plugged-in idle EVs of
+// users who opted in charge in the cheapest hours before their departure
+func (s *Service) SetChargingWindowScheduler(scheduler ports.ChargingWindowScheduler) {
+	s.chargeWindows = scheduler
+}
+
+// ScheduleSmartCharging plans a charging window for every active session
+// whose EV is plugged in but idle and whose user opted in. Sessions are
+// planned once; it returns how many were planned by this run.
+func (s *Service) ScheduleSmartCharging(ctx context.Context) (int, error) {
+	if s.chargeWindows == nil {
+		return 0, errors.New("charging window scheduler not configured")
+	}
+
+	sessions, err := s.txService.ListActiveSessions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list active sessions: %w", err)
+	}
+
+	now := time.Now()
+	active := make(map[string]bool, len(sessions))
+	planned := 0
+	for i := range sessions {
+		tx := &sessions[i].Transaction
+		active[tx.ID] = true
+		if s.hasChargingWindow(tx.ID) {
+			continue
+		}
+
+		ok, err := s.scheduleSession(ctx, tx, now)
+		if err != nil {
+			s.log.Warn("Failed to schedule smart charging",
+				zap.String("transactionID", tx.ID),
+				zap.String("chargePointID", tx.ChargePointID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if ok {
+			planned++
+		}
+	}
+
+	// Forget the windows of sessions that ended
+	s.mu.Lock()
+	for txID := range s.windows {
+		if !active[txID] {
+			delete(s.windows, txID)
+		}
+	}
+	s.mu.Unlock()
+
+	return planned, nil
+}
+
+// StartSmartChargeScheduling runs ScheduleSmartCharging every interval until
+// ctx is done
+func (s *Service) StartSmartChargeScheduling(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSmartChargeInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.ScheduleSmartCharging(ctx); err != nil {
+					s.log.Error("Smart charge scheduling failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// scheduleSession plans the charging window of one session. It reports
+// false when the session does not qualify or no window fits.
+func (s *Service) scheduleSession(ctx context.Context, tx *domain.Transaction, now time.Time) (bool, error) {
+	prefs, err := s.GetUserPreferences(ctx, tx.UserID)
+	if err != nil {
+		return false, err
+	}
+	if !prefs.SmartCharge || prefs.DepartureTime == "" {
+		return false, nil
+	}
+
+	device, err := s.deviceService.GetDevice(ctx, tx.ChargePointID)
+	if err != nil {
+		return false, err
+	}
+	status, powerKW := device.Status, s.config.DefaultChargePowerKW
+	for _, c := range device.Connectors {
+		if c.ConnectorID == tx.ConnectorID {
+			status = c.Status
+			if c.MaxPowerKW > 0 {
+				powerKW = c.MaxPowerKW
+			}
+		}
+	}
+	// Charging already, or the EV left
+	if status != domain.ChargePointStatusOccupied {
+		return false, nil
+	}
+
+	// Departure times are the user's wall clock at the station
+	departure, err := nextDeparture(now, prefs.DepartureTime, device.TimeZone())
+	if err != nil {
+		return false, err
+	}
+
+	energyKWh, err := s.energyToDeparture(ctx, tx.ChargePointID, prefs)
+	if err != nil {
+		return false, err
+	}
+	if energyKWh <= 0 {
+		return false, nil
+	}
+	hours := int(math.Ceil(energyKWh / powerKW))
+
+	forecast, err := s.gridPriceService.GetPriceForecast(ctx, int(math.Ceil(departure.Sub(now).Hours()))+1)
+	if err != nil {
+		return false, fmt.Errorf("failed to get price forecast: %w", err)
+	}
+
+	// Hours priced for auto-discharge are left to V2G
+	var dischargeFrom float64
+	if prefs.AutoDischarge {
+		dischargeFrom = prefs.MinGridPrice
+	}
+	start, end, ok := cheapestWindow(forecast, now, departure, hours, dischargeFrom)
+	if !ok {
+		s.log.Info("No charging window fits before departure",
+			zap.String("transactionID", tx.ID),
+			zap.Int("hours", hours),
+			zap.Time("departure", departure),
+		)
+		return false, nil
+	}
+
+	err = s.withChargePointLock(ctx, tx.ChargePointID, func(ctx context.Context) error {
+		if s.hasActiveSession(ctx, tx.ChargePointID) {
+			return ErrDischargeActive
+		}
+		return s.chargeWindows.ScheduleChargingWindow(ctx, tx.ChargePointID, tx.ConnectorID, start, end, powerKW*1000)
+	})
+	if errors.Is(err, ErrChargePointBusy) || errors.Is(err, ErrDischargeActive) {
+		return false, nil // V2G owns the charge point right now
+	}
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.windows[tx.ID] = chargingWindow{chargePointID: tx.ChargePointID, start: start, end: end}
+	s.mu.Unlock()
+
+	s.log.Info("Smart charging scheduled",
+		zap.String("transactionID", tx.ID),
+		zap.String("chargePointID", tx.ChargePointID),
+		zap.Time("start", start),
+		zap.Time("end", end),
+		zap.Float64("powerKW", powerKW),
+	)
+	return true, nil
+}
+
+// energyToDeparture estimates the kWh needed to reach the departure SOC.
+// Vehicles that do not report their SOC get DefaultChargeEnergyKWh.
+func (s *Service) energyToDeparture(ctx context.Context, chargePointID string, prefs *domain.V2GPreferences) (float64, error) {
+	cap, err := s.CheckV2GCapability(ctx, chargePointID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check vehicle SOC: %w", err)
+	}
+	if cap.BatteryCapacityKWh <= 0 || cap.CurrentSOC <= 0 {
+		return s.config.DefaultChargeEnergyKWh, nil
+	}
+
+	target := prefs.DepartureSOC
+	if target == 0 {
+		target = s.config.DefaultDepartureSOC
+	}
+	return float64(target-cap.CurrentSOC) / 100 * cap.BatteryCapacityKWh, nil
+}
+
+func (s *Service) hasChargingWindow(txID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.windows[txID]
+	return ok
+}
+
+// inChargingWindow reports whether a session on chargePointID is scheduled
+// to charge at t
+func (s *Service) inChargingWindow(chargePointID string, t time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, w := range s.windows {
+		if w.chargePointID == chargePointID && !t.Before(w.start) && t.Before(w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextDeparture returns the first daily departure after now, reading the
+// departure time as local time in loc
+func nextDeparture(now time.Time, departure string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(departureLayout, departure)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: departure time must be HH:MM, got %q", domain.ErrValidation, departure)
+	}
+	now = now.In(loc)
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+// cheapestWindow returns the run of hours consecutive forecast hours with
+// the lowest total price that ends by departure. Hours priced at or above
+// dischargeFrom (when positive) are skipped, since auto-discharge sells
+// energy then. The window may start in the current hour; it then starts
+// at now.
+func cheapestWindow(forecast []domain.GridPricePoint, now, departure time.Time, hours int, dischargeFrom float64) (time.Time, time.Time, bool) {
+	if hours <= 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	best, bestCost := -1, math.Inf(1)
+	for i := 0; i+hours <= len(forecast); i++ {
+		if forecast[i].Timestamp.Add(time.Hour).Before(now) {
+			continue // already over
+		}
+		if forecast[i+hours-1].Timestamp.Add(time.Hour).After(departure) {
+			break
+		}
+
+		cost, usable := 0.0, true
+		for j := i; j < i+hours && usable; j++ {
+			p := forecast[j]
+			if j > i && !p.Timestamp.Equal(forecast[j-1].Timestamp.Add(time.Hour)) {
+				usable = false // gap in the forecast
+			}
+			if dischargeFrom > 0 && p.Price >= dischargeFrom {
+				usable = false
+			}
+			cost += p.Price
+		}
+		if usable && cost < bestCost {
+			best, bestCost = i, cost
+		}
+	}
+	if best < 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start := forecast[best].Timestamp
+	if start.Before(now) {
+		start = now
+	}
+	return start, forecast[best+hours-1].Timestamp.Add(time.Hour), true
+}
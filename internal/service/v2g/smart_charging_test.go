@@ -0,0 +1,375 @@
+package v2g
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+)
+
+// scheduledWindow is a window passed to fakeWindowScheduler
+type scheduledWindow struct {
+	chargePointID string
+	evseID        int
+	start, end    time.Time
+	limitW        float64
+}
+
+// fakeWindowScheduler records the charging windows sent to stations
+type fakeWindowScheduler struct {
+	windows []scheduledWindow
+}
+
+func (f *fakeWindowScheduler) ScheduleChargingWindow(ctx context.Context, chargePointID string, evseID int, start, end time.Time, limitW float64) error {
+	f.windows = append(f.windows, scheduledWindow{chargePointID, evseID, start, end, limitW})
+	return nil
+}
+
+// forecastGridPrice serves a fixed hourly forecast; the current price is the
+// first hour's
+type forecastGridPrice struct {
+	*MockGridPriceService
+	points []domain.GridPricePoint
+}
+
+func (g *forecastGridPrice) GetPriceForecast(ctx context.Context, hours int) ([]domain.GridPricePoint, error) {
+	return g.points, nil
+}
+
+// socOCPP reports a vehicle at soc and counts the discharges started
+type socOCPP struct {
+	*MockOCPPCommandService
+	soc        int
+	discharges atomic.Int32
+}
+
+func (m *socOCPP) GetV2GCapability(ctx context.Context, chargePointID string) (*domain.V2GCapability, error) {
+	cap, _ := m.MockOCPPCommandService.GetV2GCapability(ctx, chargePointID)
+	cap.CurrentSOC = m.soc
+	return cap, nil
+}
+
+func (m *socOCPP) SetV2GChargingProfile(ctx context.Context, chargePointID string, evseID int, dischargePowerKW float64, durationSeconds int) error {
+	m.discharges.Add(1)
+	return nil
+}
+
+// hourlyForecast prices consecutive hours starting with the current one
+func hourlyForecast(prices ...float64) []domain.GridPricePoint {
+	hour := time.Now().Truncate(time.Hour)
+	points := make([]domain.GridPricePoint, len(prices))
+	for i, price := range prices {
+		points[i] = domain.GridPricePoint{Timestamp: hour.Add(time.Duration(i) * time.Hour), Price: price}
+	}
+	return points
+}
+
+// newSmartChargeService returns a service where user-1 is plugged in on
+// connector 1 of CP001 (10 kW, in connectorStatus) with a vehicle that needs
+// the default 30 kWh, i.e. a 3 hour window, and leaves at the end of the
+// forecast
+func newSmartChargeService(t *testing.T, forecast []domain.GridPricePoint, prefs domain.V2GPreferences, connectorStatus domain.ChargePointStatus) (*Service, *fakeWindowScheduler, *MockV2GRepository, *socOCPP) {
+	t.Helper()
+
+	repo := NewMockV2GRepository()
+	prefs.UserID = "user-1"
+	prefs.DepartureTime = forecast[len(forecast)-1].Timestamp.Add(time.Hour).Format(departureLayout)
+	repo.preferences["user-1"] = &prefs
+
+	devices := &mocks.MockDeviceService{
+		GetDeviceFunc: func(ctx context.Context, id string) (*domain.ChargePoint, error) {
+			return &domain.ChargePoint{
+				ID:     id,
+				Status: domain.ChargePointStatusOccupied,
+				Connectors: []domain.Connector{
+					{ConnectorID: 1, Status: connectorStatus, MaxPowerKW: 10},
+				},
+			}, nil
+		},
+	}
+	transactions := &mocks.MockTransactionService{
+		ListActiveSessionsFunc: func(ctx context.Context) ([]domain.ActiveSession, error) {
+			return []domain.ActiveSession{{Transaction: domain.Transaction{
+				ID: "tx-1", ChargePointID: "CP001", ConnectorID: 1, UserID: "user-1",
+			}}}, nil
+		},
+	}
+	grid := &forecastGridPrice{MockGridPriceService: NewMockGridPriceService(), points: forecast}
+	grid.currentPrice = forecast[0].Price
+	ocpp := &socOCPP{MockOCPPCommandService: NewMockOCPPCommandService(), soc: 70}
+
+	service := NewService(repo, devices, transactions, grid, ocpp, nil, zap.NewNop(), nil)
+	scheduler := &fakeWindowScheduler{}
+	service.SetChargingWindowScheduler(scheduler)
+	return service, scheduler, repo, ocpp
+}
+
+func TestCheapestWindow(t *testing.T) {
+	now := time.Now()
+	hour := now.Truncate(time.Hour)
+	at := func(h int) time.Time { return hour.Add(time.Duration(h) * time.Hour) }
+
+	// The hour at(2) is missing
+	gapped := hourlyForecast(0.9, 0.2, 0.1, 0.2, 0.5, 0.9)
+	gapped = append(gapped[:2], gapped[3:]...)
+
+	tests := []struct {
+		name          string
+		forecast      []domain.GridPricePoint
+		departure     time.Time
+		hours         int
+		dischargeFrom float64
+		wantOK        bool
+		wantStart     time.Time
+		wantEnd       time.Time
+	}{
+		{
+			name:      "cheapest run",
+			forecast:  hourlyForecast(0.9, 0.8, 0.3, 0.2, 0.4, 0.9),
+			departure: at(6),
+			hours:     3,
+			wantOK:    true,
+			wantStart: at(2),
+			wantEnd:   at(5),
+		},
+		{
+			name:      "ends by departure",
+			forecast:  hourlyForecast(0.6, 0.6, 0.6, 0.9, 0.1, 0.1, 0.1),
+			departure: at(4),
+			hours:     3,
+			wantOK:    true,
+			wantStart: now,
+			wantEnd:   at(3),
+		},
+		{
+			name:          "skips discharge hours",
+			forecast:      hourlyForecast(0.1, 0.9, 0.1, 0.6, 0.6, 0.6),
+			departure:     at(6),
+			hours:         3,
+			dischargeFrom: 0.85,
+			wantOK:        true,
+			wantStart:     at(2),
+			wantEnd:       at(5),
+		},
+		{
+			name:      "gap in the forecast",
+			forecast:  gapped,
+			departure: at(6),
+			hours:     2,
+			wantOK:    true,
+			wantStart: at(3),
+			wantEnd:   at(5),
+		},
+		{
+			name:      "no window before departure",
+			forecast:  hourlyForecast(0.5, 0.5, 0.5, 0.5),
+			departure: at(2),
+			hours:     3,
+			wantOK:    false,
+		},
+		{
+			name:          "every hour priced for discharge",
+			forecast:      hourlyForecast(0.9, 0.9, 0.9),
+			departure:     at(3),
+			hours:         1,
+			dischargeFrom: 0.85,
+			wantOK:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			start, end, ok := cheapestWindow(tt.forecast, now, tt.departure, tt.hours, tt.dischargeFrom)
+
+			// Assert
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok %v, got %v", tt.wantOK, ok)
+			}
+			if !ok {
+				return
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("expected window %v-%v, got %v-%v", tt.wantStart, tt.wantEnd, start, end)
+			}
+		})
+	}
+}
+
+func TestNextDeparture(t *testing.T) {
+	now := time.Date(2024, 3, 10, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		departure string
+		want      time.Time
+	}{
+		{"19:00", time.Date(2024, 3, 10, 19, 0, 0, 0, time.UTC)},
+		{"07:30", time.Date(2024, 3, 11, 7, 30, 0, 0, time.UTC)},
+		{"18:30", time.Date(2024, 3, 11, 18, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.departure, func(t *testing.T) {
+			got, err := nextDeparture(now, tt.departure, time.UTC)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNextDeparture_StationTimeZone(t *testing.T) {
+	// Arrange: 23:30 UTC is still the evening before in São Paulo (UTC-3)
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	now := time.Date(2024, 3, 10, 23, 30, 0, 0, time.UTC)
+
+	// Act
+	got, err := nextDeparture(now, "07:30", saoPaulo)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := time.Date(2024, 3, 11, 7, 30, 0, 0, saoPaulo)
+	if !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestScheduleSmartCharging_PlansCheapestWindowOnce(t *testing.T) {
+	// Arrange
+	forecast := hourlyForecast(0.9, 0.9, 0.5, 0.3, 0.4, 0.9, 0.9, 0.9)
+	service, scheduler, _, _ := newSmartChargeService(t, forecast, domain.V2GPreferences{SmartCharge: true}, domain.ChargePointStatusOccupied)
+
+	// Act
+	planned, err := service.ScheduleSmartCharging(context.Background())
+	replanned, _ := service.ScheduleSmartCharging(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if planned != 1 || replanned != 0 {
+		t.Fatalf("expected the session planned once, got %d then %d", planned, replanned)
+	}
+	if len(scheduler.windows) != 1 {
+		t.Fatalf("expected one window sent to the station, got %d", len(scheduler.windows))
+	}
+	w := scheduler.windows[0]
+	if w.chargePointID != "CP001" || w.evseID != 1 {
+		t.Errorf("expected CP001 connector 1, got %s connector %d", w.chargePointID, w.evseID)
+	}
+	if !w.start.Equal(forecast[2].Timestamp) || !w.end.Equal(forecast[5].Timestamp) {
+		t.Errorf("expected window %v-%v, got %v-%v", forecast[2].Timestamp, forecast[5].Timestamp, w.start, w.end)
+	}
+	if w.limitW != 10000 {
+		t.Errorf("expected the connector's 10 kW as the limit, got %v W", w.limitW)
+	}
+}
+
+func TestScheduleSmartCharging_SkipsSessions(t *testing.T) {
+	forecast := hourlyForecast(0.9, 0.5, 0.3, 0.4, 0.9, 0.9)
+
+	tests := []struct {
+		name      string
+		prefs     domain.V2GPreferences
+		connector domain.ChargePointStatus
+		discharge bool
+	}{
+		{"not opted in", domain.V2GPreferences{}, domain.ChargePointStatusOccupied, false},
+		{"already charging", domain.V2GPreferences{SmartCharge: true}, domain.ChargePointStatusCharging, false},
+		{"discharging", domain.V2GPreferences{SmartCharge: true}, domain.ChargePointStatusOccupied, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, scheduler, repo, _ := newSmartChargeService(t, forecast, tt.prefs, tt.connector)
+			if tt.discharge {
+				repo.sessions["v2g-1"] = &domain.V2GSession{
+					ID: "v2g-1", ChargePointID: "CP001", UserID: "user-1",
+					Direction: domain.V2GDirectionDischarging, Status: domain.V2GStatusActive,
+				}
+			}
+
+			// Act
+			planned, err := service.ScheduleSmartCharging(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if planned != 0 || len(scheduler.windows) != 0 {
+				t.Errorf("expected nothing scheduled, got %d windows", len(scheduler.windows))
+			}
+		})
+	}
+}
+
+func TestScheduleSmartCharging_LeavesAutoDischargeHoursToV2G(t *testing.T) {
+	// Arrange
+	forecast := hourlyForecast(0.1, 0.9, 0.1, 0.6, 0.6, 0.6)
+	prefs := domain.V2GPreferences{SmartCharge: true, AutoDischarge: true, MinGridPrice: 0.85}
+	service, scheduler, _, _ := newSmartChargeService(t, forecast, prefs, domain.ChargePointStatusOccupied)
+
+	// Act
+	_, err := service.ScheduleSmartCharging(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(scheduler.windows) != 1 {
+		t.Fatalf("expected one window, got %d", len(scheduler.windows))
+	}
+	if w := scheduler.windows[0]; w.start.Before(forecast[2].Timestamp) {
+		t.Errorf("expected the window to start after the discharge hour, got %v", w.start)
+	}
+}
+
+func TestOptimizeV2G_DoesNotDischargeDuringChargingWindow(t *testing.T) {
+	tests := []struct {
+		name          string
+		smartCharge   bool
+		wantDischarge bool
+	}{
+		{"in charging window", true, false},
+		{"no charging window", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			// The cheapest window starts now; the user turns on
+			// auto-discharge once it is scheduled, and the current price
+			// is above their threshold
+			forecast := hourlyForecast(0.84, 0.84, 0.84, 2.0, 2.0)
+			prefs := domain.V2GPreferences{SmartCharge: tt.smartCharge, MinGridPrice: 0.80}
+			service, _, repo, ocpp := newSmartChargeService(t, forecast, prefs, domain.ChargePointStatusOccupied)
+			service.ScheduleSmartCharging(context.Background())
+			repo.preferences["user-1"].AutoDischarge = true
+
+			// Act
+			err := service.OptimizeV2G(context.Background(), "CP001", "user-1")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := ocpp.discharges.Load() == 1; got != tt.wantDischarge {
+				t.Errorf("expected discharge %v, got %v", tt.wantDischarge, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package v2g
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// AlertTypeCertificateExpiring marks alerts raised for station certificates
+// close to expiry
+const AlertTypeCertificateExpiring = "certificate_expiring"
+
+var (
+	// ErrCertificateAuthorityUnavailable is returned when no CA is configured
+	ErrCertificateAuthorityUnavailable = errors.New("certificate authority not configured")
+	// ErrCSRIdentityMismatch is returned when the CSR common name is not the
+	// charge point that sent it
+	ErrCSRIdentityMismatch = errors.New("CSR common name does not match charge point")
+)
+
+// StationCertificateConfig holds station certificate settings
+type StationCertificateConfig struct {
+	Validity      time.Duration // Lifetime of issued certificates
+	ExpiryWarning time.Duration // Raise an alert this long before expiry
+}
+
+// DefaultStationCertificateConfig returns a one year validity with a 30 day warning
+func DefaultStationCertificateConfig() StationCertificateConfig {
+	return StationCertificateConfig{
+		Validity:      365 * 24 * time.Hour,
+		ExpiryWarning: 30 * 24 * time.Hour,
+	}
+}
+
+// StationCertificateService signs charge point CSRs (SignCertificate) and
+// watches the issued certificates for expiry
+type StationCertificateService struct {
+	repo   ports.StationCertificateRepository
+	alerts ports.AlertRepository
+	ca     *CertificateAuthority
+	config StationCertificateConfig
+	log    *zap.Logger
+}
+
+// NewStationCertificateService creates a new station certificate service.
+// Without an alert repository expiring certificates are only logged.
+func NewStationCertificateService(
+	repo ports.StationCertificateRepository,
+	alerts ports.AlertRepository,
+	ca *CertificateAuthority,
+	config StationCertificateConfig,
+	log *zap.Logger,
+) *StationCertificateService {
+	defaults := DefaultStationCertificateConfig()
+	if config.Validity <= 0 {
+		config.Validity = defaults.Validity
+	}
+	if config.ExpiryWarning <= 0 {
+		config.ExpiryWarning = defaults.ExpiryWarning
+	}
+
+	return &StationCertificateService{
+		repo:   repo,
+		alerts: alerts,
+		ca:     ca,
+		config: config,
+		log:    log,
+	}
+}
+
+// Ensure StationCertificateService implements ports.StationCertificateService
+var _ ports.StationCertificateService = (*StationCertificateService)(nil)
+
+// SignCertificate signs a CSR from a charge point. The CSR common name must
+// be the charge point ID, so a station cannot obtain another's identity.
+func (s *StationCertificateService) SignCertificate(ctx context.Context, chargePointID string, csrPEM string, certType domain.StationCertificateType) (*domain.StationCertificate, error) {
+	if s.ca == nil {
+		return nil, ErrCertificateAuthorityUnavailable
+	}
+	if certType == "" {
+		certType = domain.StationCertificateTypeChargingStation
+	}
+
+	csr, err := ParseCSR(csrPEM)
+	if err != nil {
+		return nil, err
+	}
+	if csr.Subject.CommonName != chargePointID {
+		return nil, fmt.Errorf("%w: %q", ErrCSRIdentityMismatch, csr.Subject.CommonName)
+	}
+
+	// The station certificate authenticates the station as a TLS client; the
+	// V2G certificate is the SECC server certificate towards the EV
+	usage := x509.ExtKeyUsageClientAuth
+	if certType == domain.StationCertificateTypeV2G {
+		usage = x509.ExtKeyUsageServerAuth
+	}
+
+	cert, chainPEM, err := s.ca.Sign(csr, s.config.Validity, usage)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := &domain.StationCertificate{
+		ID:               uuid.New().String(),
+		ChargePointID:    chargePointID,
+		CertificateType:  certType,
+		SerialNumber:     cert.SerialNumber.Text(16),
+		CommonName:       cert.Subject.CommonName,
+		CertificateChain: chainPEM,
+		NotBefore:        cert.NotBefore,
+		NotAfter:         cert.NotAfter,
+		CreatedAt:        time.Now(),
+	}
+	if err := s.repo.Save(ctx, issued); err != nil {
+		return nil, fmt.Errorf("failed to save station certificate: %w", err)
+	}
+
+	s.resolveExpiryAlerts(ctx, issued)
+
+	s.log.Info("Station certificate issued",
+		zap.String("chargePointID", chargePointID),
+		zap.String("type", string(certType)),
+		zap.String("serial", issued.SerialNumber),
+		zap.Time("notAfter", issued.NotAfter),
+	)
+
+	return issued, nil
+}
+
+// CheckExpiring raises one alert per certificate that expires within the
+// warning window. Certificates that already have an open alert are skipped.
+func (s *StationCertificateService) CheckExpiring(ctx context.Context) (int, error) {
+	now := time.Now()
+	certs, err := s.repo.FindExpiringBefore(ctx, now.Add(s.config.ExpiryWarning))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expiring certificates: %w", err)
+	}
+
+	for i := range certs {
+		cert := &certs[i]
+		s.log.Warn("Station certificate expiring",
+			zap.String("chargePointID", cert.ChargePointID),
+			zap.String("type", string(cert.CertificateType)),
+			zap.Time("notAfter", cert.NotAfter),
+		)
+
+		if s.alerts == nil {
+			continue
+		}
+		sourceID := certificateSourceID(cert)
+		open, err := s.alerts.FindOpen(ctx, AlertTypeCertificateExpiring, sourceID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find open certificate alerts: %w", err)
+		}
+		if len(open) > 0 {
+			continue
+		}
+
+		severity := "warning"
+		if !cert.NotAfter.After(now) {
+			severity = "critical"
+		}
+		alert := &ports.Alert{
+			ID:       uuid.New().String(),
+			Type:     AlertTypeCertificateExpiring,
+			Severity: severity,
+			Title:    fmt.Sprintf("%s of %s expires soon", cert.CertificateType, cert.ChargePointID),
+			Message: fmt.Sprintf("Certificate %s of charge point %s expires at %s",
+				cert.SerialNumber, cert.ChargePointID, cert.NotAfter.UTC().Format(time.RFC3339)),
+			Source:    "charge_point",
+			SourceID:  sourceID,
+			CreatedAt: now,
+		}
+		if err := s.alerts.Save(ctx, alert); err != nil {
+			return 0, fmt.Errorf("failed to save certificate alert: %w", err)
+		}
+	}
+
+	return len(certs), nil
+}
+
+// StartExpiryChecks runs CheckExpiring every interval until ctx is done
+func (s *StationCertificateService) StartExpiryChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.CheckExpiring(ctx); err != nil {
+					s.log.Error("Certificate expiry check failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// resolveExpiryAlerts clears expiry alerts once a certificate was renewed
+func (s *StationCertificateService) resolveExpiryAlerts(ctx context.Context, cert *domain.StationCertificate) {
+	if s.alerts == nil {
+		return
+	}
+	open, err := s.alerts.FindOpen(ctx, AlertTypeCertificateExpiring, certificateSourceID(cert))
+	if err != nil {
+		s.log.Warn("Failed to find certificate alerts", zap.String("chargePointID", cert.ChargePointID), zap.Error(err))
+		return
+	}
+	for _, alert := range open {
+		if err := s.alerts.Resolve(ctx, alert.ID, cert.CreatedAt); err != nil {
+			s.log.Warn("Failed to resolve certificate alert", zap.String("alertID", alert.ID), zap.Error(err))
+		}
+	}
+}
+
+// certificateSourceID identifies a station certificate slot as the source of an alert
+func certificateSourceID(cert *domain.StationCertificate) string {
+	return fmt.Sprintf("%s/%s", cert.ChargePointID, cert.CertificateType)
+}
@@ -0,0 +1,247 @@
+package v2g
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/mocks"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+// newTestCA creates a self-signed ECDSA CA valid for two years
+func newTestCA(t *testing.T) *CertificateAuthority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CSMS CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(2 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	ca, err := NewCertificateAuthority([]*x509.Certificate{cert}, key)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	return ca
+}
+
+// newTestCSR creates a PEM encoded CSR with the given common name
+func newTestCSR(t *testing.T, commonName string) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate station key: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: commonName, Organization: []string{"SIGEC-VE"}},
+	}, key)
+	if err != nil {
+		t.Fatalf("failed to create CSR: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+}
+
+func TestSignCertificate_RoundTrip(t *testing.T) {
+	// Arrange
+	ca := newTestCA(t)
+	var saved *domain.StationCertificate
+	repo := &mocks.MockStationCertificateRepository{
+		SaveFunc: func(ctx context.Context, cert *domain.StationCertificate) error {
+			saved = cert
+			return nil
+		},
+	}
+	service := NewStationCertificateService(repo, nil, ca, StationCertificateConfig{Validity: 90 * 24 * time.Hour}, zap.NewNop())
+
+	// Act
+	issued, err := service.SignCertificate(context.Background(), "CP-001", newTestCSR(t, "CP-001"), domain.StationCertificateTypeChargingStation)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if saved != issued {
+		t.Error("expected the issued certificate to be stored")
+	}
+
+	certs, err := parsePEMCertificates([]byte(issued.CertificateChain))
+	if err != nil {
+		t.Fatalf("expected a parseable chain, got %v", err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("expected leaf and CA in the chain, got %d certificates", len(certs))
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate())
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		t.Errorf("expected the certificate to verify against the CA, got %v", err)
+	}
+	if certs[0].Subject.CommonName != "CP-001" {
+		t.Errorf("expected common name CP-001, got %s", certs[0].Subject.CommonName)
+	}
+	if issued.SerialNumber != certs[0].SerialNumber.Text(16) {
+		t.Errorf("expected serial %s, got %s", certs[0].SerialNumber.Text(16), issued.SerialNumber)
+	}
+	if !issued.NotAfter.Equal(certs[0].NotAfter) {
+		t.Errorf("expected expiry %v, got %v", certs[0].NotAfter, issued.NotAfter)
+	}
+	if issued.NotAfter.After(time.Now().Add(91 * 24 * time.Hour)) {
+		t.Errorf("expected the configured validity, got expiry %v", issued.NotAfter)
+	}
+}
+
+func TestSignCertificate_V2GUsesServerAuth(t *testing.T) {
+	// Arrange
+	ca := newTestCA(t)
+	service := NewStationCertificateService(&mocks.MockStationCertificateRepository{}, nil, ca, StationCertificateConfig{}, zap.NewNop())
+
+	// Act
+	issued, err := service.SignCertificate(context.Background(), "CP-001", newTestCSR(t, "CP-001"), domain.StationCertificateTypeV2G)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	certs, _ := parsePEMCertificates([]byte(issued.CertificateChain))
+	if len(certs[0].ExtKeyUsage) != 1 || certs[0].ExtKeyUsage[0] != x509.ExtKeyUsageServerAuth {
+		t.Errorf("expected server auth usage, got %v", certs[0].ExtKeyUsage)
+	}
+}
+
+func TestSignCertificate_Rejections(t *testing.T) {
+	tests := []struct {
+		name    string
+		ca      bool
+		csr     func(t *testing.T) string
+		wantErr error
+	}{
+		{
+			name:    "common name of another station",
+			ca:      true,
+			csr:     func(t *testing.T) string { return newTestCSR(t, "CP-OTHER") },
+			wantErr: ErrCSRIdentityMismatch,
+		},
+		{
+			name:    "not a CSR",
+			ca:      true,
+			csr:     func(t *testing.T) string { return "-----BEGIN GARBAGE-----" },
+			wantErr: ErrInvalidCSR,
+		},
+		{
+			name:    "no CA configured",
+			ca:      false,
+			csr:     func(t *testing.T) string { return newTestCSR(t, "CP-001") },
+			wantErr: ErrCertificateAuthorityUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var ca *CertificateAuthority
+			if tt.ca {
+				ca = newTestCA(t)
+			}
+			saved := false
+			repo := &mocks.MockStationCertificateRepository{
+				SaveFunc: func(ctx context.Context, cert *domain.StationCertificate) error {
+					saved = true
+					return nil
+				},
+			}
+			service := NewStationCertificateService(repo, nil, ca, StationCertificateConfig{}, zap.NewNop())
+
+			// Act
+			_, err := service.SignCertificate(context.Background(), "CP-001", tt.csr(t), domain.StationCertificateTypeChargingStation)
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if saved {
+				t.Error("expected nothing to be stored")
+			}
+		})
+	}
+}
+
+func TestCheckExpiring_RaisesOneAlertPerCertificate(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	var before time.Time
+	repo := &mocks.MockStationCertificateRepository{
+		FindExpiringBeforeFunc: func(ctx context.Context, b time.Time) ([]domain.StationCertificate, error) {
+			before = b
+			return []domain.StationCertificate{
+				{ChargePointID: "CP-001", CertificateType: domain.StationCertificateTypeChargingStation, NotAfter: now.Add(10 * 24 * time.Hour)},
+				{ChargePointID: "CP-002", CertificateType: domain.StationCertificateTypeChargingStation, NotAfter: now.Add(-time.Hour)},
+				{ChargePointID: "CP-003", CertificateType: domain.StationCertificateTypeV2G, NotAfter: now.Add(time.Hour)},
+			}, nil
+		},
+	}
+	var raised []*ports.Alert
+	alerts := &mocks.MockAlertRepository{
+		FindOpenFunc: func(ctx context.Context, alertType, sourceID string) ([]ports.Alert, error) {
+			// CP-003 was already alerted
+			if sourceID == "CP-003/V2GCertificate" {
+				return []ports.Alert{{ID: "existing"}}, nil
+			}
+			return nil, nil
+		},
+		SaveFunc: func(ctx context.Context, alert *ports.Alert) error {
+			raised = append(raised, alert)
+			return nil
+		},
+	}
+	service := NewStationCertificateService(repo, alerts, nil, StationCertificateConfig{ExpiryWarning: 30 * 24 * time.Hour}, zap.NewNop())
+
+	// Act
+	count, err := service.CheckExpiring(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 expiring certificates, got %d", count)
+	}
+	if before.Before(now.Add(29*24*time.Hour)) || before.After(time.Now().Add(30*24*time.Hour)) {
+		t.Errorf("expected a 30 day warning window, got %v", before)
+	}
+	if len(raised) != 2 {
+		t.Fatalf("expected 2 new alerts, got %d", len(raised))
+	}
+	if raised[0].SourceID != "CP-001/ChargingStationCertificate" || raised[0].Severity != "warning" {
+		t.Errorf("expected a warning for CP-001, got %s %s", raised[0].SourceID, raised[0].Severity)
+	}
+	if raised[1].SourceID != "CP-002/ChargingStationCertificate" || raised[1].Severity != "critical" {
+		t.Errorf("expected a critical alert for expired CP-002, got %s %s", raised[1].SourceID, raised[1].Severity)
+	}
+}
@@ -0,0 +1,46 @@
+package voice
+
+import "regexp"
+
+const redactedPlaceholder = "[REDACTED]"
+
+// redaction replaces matches of pattern; replacement may reference groups
+type redaction struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// redactions match values that must not be stored in the command history.
+// Order matters: specific token formats run before the generic ones.
+var redactions = []redaction{
+	// JWTs
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), redactedPlaceholder},
+	// Bearer tokens
+	{regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`), redactedPlaceholder},
+	// Provider API keys (Stripe, Google, SendGrid)
+	{regexp.MustCompile(`\b(?:sk|pk|rk)_(?:live|test)_[A-Za-z0-9]+`), redactedPlaceholder},
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`), redactedPlaceholder},
+	{regexp.MustCompile(`\bSG\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), redactedPlaceholder},
+	// Passwords, PINs and codes spoken after a keyword; the keyword is kept so
+	// the history still reads naturally
+	{
+		regexp.MustCompile(`(?i)\b(senha|password|pin|token|código|codigo|code)((?:\s*[:=]\s*|\s+)(?:(?:é|e|is)\s+)?)\S+`),
+		"${1}${2}" + redactedPlaceholder,
+	},
+	// Card numbers (13-19 digits, optionally grouped)
+	{regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), redactedPlaceholder},
+	// CPF
+	{regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`), redactedPlaceholder},
+	// Long opaque tokens (hex or base64-like). Hyphens are excluded so
+	// session UUIDs stay readable.
+	{regexp.MustCompile(`\b[A-Za-z0-9_]{32,}\b`), redactedPlaceholder},
+}
+
+// redactSensitive replaces credentials, card numbers and other personal
+// identifiers in text with a placeholder
+func redactSensitive(text string) string {
+	for _, r := range redactions {
+		text = r.pattern.ReplaceAllString(text, r.replacement)
+	}
+	return text
+}
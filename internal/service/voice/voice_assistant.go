@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/seu-repo/sigec-ve/internal/adapter/ai/gemini"
 	"github.com/seu-repo/sigec-ve/internal/domain"
 	"github.com/seu-repo/sigec-ve/internal/ports"
@@ -16,19 +19,27 @@ type VoiceAssistant struct {
 	gemini        *gemini.LiveClient
 	deviceService ports.DeviceService
 	txService     ports.TransactionService
+	history       ports.VoiceCommandRepository
 	logger        *zap.Logger
 }
 
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
 func NewVoiceAssistant(
 	gemini *gemini.LiveClient,
 	deviceSvc ports.DeviceService,
 	txSvc ports.TransactionService,
+	history ports.VoiceCommandRepository,
 	logger *zap.Logger,
 ) *VoiceAssistant {
 	return &VoiceAssistant{
 		gemini:        gemini,
 		deviceService: deviceSvc,
 		txService:     txSvc,
+		history:       history,
 		logger:        logger,
 	}
 }
@@ -50,6 +61,10 @@ func (va *VoiceAssistant) ProcessVoiceCommand(
 	if err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		// O usuário interrompeu o turno; nenhuma ação deve ser executada
+		return nil, err
+	}
 
 	// 3. Extrai texto e áudio da resposta
 	var responseText string
@@ -70,6 +85,9 @@ func (va *VoiceAssistant) ProcessVoiceCommand(
 	// 5. Executa ação baseada na intenção
 	actionResult := va.executeAction(ctx, userID, intent)
 
+	// 6. Registra o comando no histórico do usuário
+	va.recordCommand(ctx, userID, responseText, intent, actionResult)
+
 	return &domain.VoiceResponse{
 		Text:         responseText,
 		Audio:        responseAudio,
@@ -79,6 +97,69 @@ func (va *VoiceAssistant) ProcessVoiceCommand(
 	}, nil
 }
 
+// ConnectionStates subscribes to the Gemini connection state so callers can
+// tell the user when the assistant is reconnecting. Call the returned
+// function to unsubscribe.
+func (va *VoiceAssistant) ConnectionStates() (<-chan gemini.ConnectionState, func()) {
+	if va.gemini == nil {
+		ch := make(chan gemini.ConnectionState)
+		return ch, func() {}
+	}
+	return va.gemini.Subscribe()
+}
+
+// Interrupt stops the response Gemini is currently producing so the user can
+// talk over the assistant
+func (va *VoiceAssistant) Interrupt(ctx context.Context) error {
+	if va.gemini == nil {
+		return nil
+	}
+	return va.gemini.Interrupt(ctx)
+}
+
+// recordCommand stores a processed command in the user's history with
+// sensitive tokens redacted. Failures are logged and never fail the command.
+func (va *VoiceAssistant) recordCommand(ctx context.Context, userID, transcript string, intent *domain.Intent, result string) {
+	if va.history == nil {
+		return
+	}
+
+	cmd := &domain.VoiceCommand{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Transcript: redactSensitive(transcript),
+		Intent:     intent.Name,
+		Confidence: intent.Confidence,
+		Result:     redactSensitive(result),
+		CreatedAt:  time.Now(),
+	}
+	if err := va.history.Save(ctx, cmd); err != nil {
+		va.logger.Warn("Failed to store voice command", zap.String("user_id", userID), zap.Error(err))
+	}
+}
+
+// GetHistory returns a page of the user's voice commands, newest first
+func (va *VoiceAssistant) GetHistory(ctx context.Context, userID string, limit, offset int) ([]domain.VoiceCommand, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if va.history == nil {
+		return []domain.VoiceCommand{}, nil
+	}
+
+	cmds, err := va.history.FindByUserID(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load voice history: %w", err)
+	}
+	return cmds, nil
+}
+
 // parseIntent identifica a intenção do usuário
 func (va *VoiceAssistant) parseIntent(text string) *domain.Intent {
 	// Implementação de NLU básica
@@ -132,10 +213,12 @@ func (va *VoiceAssistant) executeAction(
 
 	case "start_charge":
 		stationID := ""
+		var selection domain.StationSelection
 		if intent.Entities != nil {
 			stationID = intent.Entities["station_id"]
+			selection = stationSelection(intent.Entities)
 		}
-		tx, err := va.txService.StartCharging(ctx, userID, stationID)
+		tx, err := va.txService.StartCharging(ctx, userID, stationID, selection)
 		if err != nil {
 			va.logger.Error("Failed to start charging", zap.Error(err), zap.String("user_id", userID))
 			return fmt.Sprintf("Não foi possível iniciar o carregamento: %s", err.Error())
@@ -156,7 +239,10 @@ func (va *VoiceAssistant) executeAction(
 			va.logger.Warn("Failed to get current session cost", zap.Error(err))
 			return "Você não possui uma sessão de carregamento ativa no momento."
 		}
-		return fmt.Sprintf("O custo estimado da sua sessão atual é R$ %.2f.", cost)
+		if cost.Estimated {
+			return fmt.Sprintf("O custo estimado da sua sessão atual é R$ %.2f.", cost.Cost)
+		}
+		return fmt.Sprintf("O custo da sua sessão atual é R$ %.2f (%.1f kWh).", cost.Cost, cost.EnergyKWh)
 
 	case "report_issue":
 		// Log the issue for later processing
@@ -171,6 +257,22 @@ func (va *VoiceAssistant) executeAction(
 	}
 }
 
+// stationSelection reads how the user wants a station picked ("strategy":
+// nearest, cheapest, ...) and where they are, when the client sent it.
+// Strategies the service does not know are left to its default.
+func stationSelection(entities map[string]string) domain.StationSelection {
+	var selection domain.StationSelection
+	if strategy := domain.StationSelectionStrategy(entities["strategy"]); strategy.Valid() {
+		selection.Strategy = strategy
+	}
+	lat, latErr := strconv.ParseFloat(entities["latitude"], 64)
+	lon, lonErr := strconv.ParseFloat(entities["longitude"], 64)
+	if latErr == nil && lonErr == nil {
+		selection.Location = &domain.Location{Latitude: lat, Longitude: lon}
+	}
+	return selection
+}
+
 func (va *VoiceAssistant) extractEntities(text string) map[string]string {
 	// Placeholder for entity extraction logic
 	return make(map[string]string)
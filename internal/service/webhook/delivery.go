@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/domain"
+)
+
+// envelope is the body partners receive
+type envelope struct {
+	ID        string          `json:"id"` // The delivery ID; the same on every retry
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Dispatch delivers an event to every endpoint subscribed to its type and
+// returns the deliveries once each has succeeded or given up
+func (s *Service) Dispatch(ctx context.Context, eventType string, data []byte) ([]domain.WebhookDelivery, error) {
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("%w: %s event is not JSON", domain.ErrValidation, eventType)
+	}
+
+	endpoints, err := s.repo.FindByEventType(ctx, eventType)
+	if err != nil {
+		s.log.Error("Failed to find webhook subscribers", zap.String("event_type", eventType), zap.Error(err))
+		return nil, fmt.Errorf("failed to find webhook subscribers: %w", err)
+	}
+
+	owner := eventOwner(data)
+	staff := make(map[string]bool)
+	deliveries := make([]domain.WebhookDelivery, 0, len(endpoints))
+	for i := range endpoints {
+		if !s.receives(ctx, &endpoints[i], owner, staff) {
+			continue
+		}
+		delivery, err := s.deliver(ctx, &endpoints[i], eventType, data)
+		if err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, nil
+}
+
+// eventOwner returns the user an event belongs to, or "" for station-wide
+// events such as device faults
+func eventOwner(data []byte) string {
+	var event struct {
+		UserID string `json:"user_id"`
+	}
+	json.Unmarshal(data, &event)
+	return event.UserID
+}
+
+// receives reports whether an endpoint's account may see an event of owner:
+// its own, or any when the account is an admin or operator. staff caches
+// the accounts already looked up.
+func (s *Service) receives(ctx context.Context, endpoint *domain.WebhookEndpoint, owner string, staff map[string]bool) bool {
+	if owner != "" && endpoint.AccountID == owner {
+		return true
+	}
+	isStaff, ok := staff[endpoint.AccountID]
+	if !ok {
+		user, err := s.users.FindByID(ctx, endpoint.AccountID)
+		if err != nil {
+			s.log.Warn("Failed to look up webhook account", zap.String("account_id", endpoint.AccountID), zap.Error(err))
+		}
+		isStaff = user != nil && (user.Role == domain.UserRoleAdmin || user.Role == domain.UserRoleOperator)
+		staff[endpoint.AccountID] = isStaff
+	}
+	return isStaff
+}
+
+// deliver posts one event to one endpoint, retrying failed attempts with
+// exponential backoff, and keeps the delivery log up to date
+func (s *Service) deliver(ctx context.Context, endpoint *domain.WebhookEndpoint, eventType string, data []byte) (*domain.WebhookDelivery, error) {
+	delivery := &domain.WebhookDelivery{
+		ID:         uuid.New().String(),
+		EndpointID: endpoint.ID,
+		EventType:  eventType,
+		Status:     domain.WebhookDeliveryPending,
+		CreatedAt:  time.Now(),
+	}
+	body, err := json.Marshal(envelope{
+		ID:        delivery.ID,
+		Type:      eventType,
+		CreatedAt: delivery.CreatedAt.UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook body: %w", err)
+	}
+	delivery.Payload = string(body)
+	// The partner still gets the event if the log is unavailable
+	if err := s.repo.SaveDelivery(ctx, delivery); err != nil {
+		s.log.Warn("Failed to save webhook delivery", zap.String("delivery_id", delivery.ID), zap.Error(err))
+	}
+
+	delay := s.config.RetryBaseDelay
+	for {
+		delivery.Attempts++
+		status, err := s.post(ctx, endpoint, body)
+		delivery.ResponseStatus = status
+		if err == nil {
+			now := time.Now()
+			delivery.Status = domain.WebhookDeliveryDelivered
+			delivery.Error = ""
+			delivery.DeliveredAt = &now
+			break
+		}
+		delivery.Error = err.Error()
+
+		if delivery.Attempts > s.config.MaxRetries {
+			delivery.Status = domain.WebhookDeliveryFailed
+			s.log.Warn("Webhook delivery failed, giving up",
+				zap.String("webhook_id", endpoint.ID),
+				zap.String("delivery_id", delivery.ID),
+				zap.String("event_type", eventType),
+				zap.Int("attempts", delivery.Attempts),
+				zap.Error(err),
+			)
+			break
+		}
+
+		// Recorded before waiting so the log shows where a slow delivery is
+		if err := s.repo.SaveDelivery(ctx, delivery); err != nil {
+			s.log.Warn("Failed to update webhook delivery", zap.String("delivery_id", delivery.ID), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			delivery.Status = domain.WebhookDeliveryFailed
+			delivery.Error = fmt.Sprintf("%v (last error: %v)", ctx.Err(), err)
+			s.saveResult(delivery)
+			return delivery, nil
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	s.saveResult(delivery)
+	return delivery, nil
+}
+
+// saveResult stores the final state of a delivery, even when the context
+// it was dispatched with is done
+func (s *Service) saveResult(delivery *domain.WebhookDelivery) {
+	if err := s.repo.SaveDelivery(context.Background(), delivery); err != nil {
+		s.log.Warn("Failed to update webhook delivery", zap.String("delivery_id", delivery.ID), zap.Error(err))
+	}
+}
+
+// post sends one signed attempt and returns the response status. Anything
+// but a 2xx is a failure.
+func (s *Service) post(ctx context.Context, endpoint *domain.WebhookEndpoint, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Lets the connection be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,234 @@
+// Package webhook delivers our events to partner endpoints, such as a fleet
+// operator's system, as signed JSON over HTTPS.
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/queue"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+const (
+	defaultMaxRetries     = 5
+	defaultRetryBaseDelay = 2 * time.Second
+	defaultTimeout        = 10 * time.Second
+)
+
+// DefaultEventTypes are the message queue subjects partners can subscribe to
+var DefaultEventTypes = []string{
+	"transaction.started",
+	"transaction.completed",
+	"device.fault",
+	"device.fault.resolved",
+	"reservation.activated",
+	"reservation.completed",
+	"device.firmware_changed",
+}
+
+// Config holds the webhook delivery configuration
+type Config struct {
+	EventTypes     []string      // Subjects partners can subscribe to; DefaultEventTypes when empty
+	MaxRetries     int           // Retries after the first attempt
+	RetryBaseDelay time.Duration // Doubles after every retry
+	Timeout        time.Duration // Per attempt
+	// Lets endpoints on loopback and private addresses be registered and
+	// delivered to, e.g. for a partner on the same network; off by default
+	AllowInternalHosts bool
+}
+
+// Service manages webhook endpoints and delivers events to them. An event
+// that belongs to a user (it names a user_id) is delivered to that user's
+// endpoints; admins' and operators' endpoints receive every event.
+type Service struct {
+	repo   ports.WebhookRepository
+	users  ports.UserRepository
+	client *http.Client
+	config Config
+	log    *zap.Logger
+}
+
+// NewService creates a new webhook service
+func NewService(repo ports.WebhookRepository, users ports.UserRepository, config Config, log *zap.Logger) *Service {
+	if len(config.EventTypes) == 0 {
+		config.EventTypes = DefaultEventTypes
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.RetryBaseDelay <= 0 {
+		config.RetryBaseDelay = defaultRetryBaseDelay
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	client := &http.Client{Timeout: config.Timeout}
+	if !config.AllowInternalHosts {
+		client.Transport = publicTransport(config.Timeout)
+	}
+	return &Service{
+		repo:   repo,
+		users:  users,
+		client: client,
+		config: config,
+		log:    log,
+	}
+}
+
+// SetHTTPClient replaces the client deliveries are sent with
+func (s *Service) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// Start delivers every event of a subscribable type published on mq
+func (s *Service) Start(mq queue.MessageQueue) error {
+	for _, eventType := range s.config.EventTypes {
+		eventType := eventType
+		err := mq.Subscribe(eventType, func(data []byte) error {
+			// Retries can take minutes; the queue is not held up meanwhile
+			go s.Dispatch(context.Background(), eventType, data)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+// Register creates an endpoint for an account
+func (s *Service) Register(ctx context.Context, accountID string, req *ports.WebhookRequest) (*domain.WebhookEndpoint, error) {
+	endpoint := &domain.WebhookEndpoint{
+		ID:         uuid.New().String(),
+		AccountID:  accountID,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Active:     req.Active == nil || *req.Active,
+	}
+	if err := s.validate(endpoint); err != nil {
+		return nil, err
+	}
+	if endpoint.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Secret = secret
+	}
+
+	if err := s.repo.Save(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+
+	s.log.Info("Webhook registered",
+		zap.String("webhook_id", endpoint.ID),
+		zap.String("account_id", accountID),
+		zap.Strings("event_types", endpoint.EventTypes),
+	)
+	return endpoint, nil
+}
+
+// List returns the account's endpoints
+func (s *Service) List(ctx context.Context, accountID string) ([]domain.WebhookEndpoint, error) {
+	return s.repo.FindByAccountID(ctx, accountID)
+}
+
+// Get returns one of the account's endpoints
+func (s *Service) Get(ctx context.Context, accountID, id string) (*domain.WebhookEndpoint, error) {
+	endpoint, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if endpoint == nil || endpoint.AccountID != accountID {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return endpoint, nil
+}
+
+// Update changes the fields set in req
+func (s *Service) Update(ctx context.Context, accountID, id string, req *ports.WebhookRequest) (*domain.WebhookEndpoint, error) {
+	endpoint, err := s.Get(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != "" {
+		endpoint.URL = req.URL
+	}
+	if req.EventTypes != nil {
+		endpoint.EventTypes = req.EventTypes
+	}
+	if req.Secret != "" {
+		endpoint.Secret = req.Secret
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+	if err := s.validate(endpoint); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+	return endpoint, nil
+}
+
+// Delete removes an endpoint and its delivery log
+func (s *Service) Delete(ctx context.Context, accountID, id string) error {
+	if _, err := s.Get(ctx, accountID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	s.log.Info("Webhook deleted", zap.String("webhook_id", id), zap.String("account_id", accountID))
+	return nil
+}
+
+// Deliveries returns the delivery log of an endpoint, newest first
+func (s *Service) Deliveries(ctx context.Context, accountID, id string, limit, offset int) ([]domain.WebhookDelivery, error) {
+	if _, err := s.Get(ctx, accountID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindDeliveries(ctx, id, limit, offset)
+}
+
+// validate checks an endpoint before it is saved
+func (s *Service) validate(endpoint *domain.WebhookEndpoint) error {
+	u, err := url.Parse(endpoint.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute https URL", domain.ErrValidation)
+	}
+	if !s.config.AllowInternalHosts && internalHost(u.Hostname()) {
+		return fmt.Errorf("%w: url must not point at a loopback or private address", domain.ErrValidation)
+	}
+	if len(endpoint.EventTypes) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", domain.ErrValidation)
+	}
+	for _, eventType := range endpoint.EventTypes {
+		if !s.supports(eventType) {
+			return fmt.Errorf("%w: unsupported event type %q", domain.ErrValidation, eventType)
+		}
+	}
+	return nil
+}
+
+func (s *Service) supports(eventType string) bool {
+	for _, t := range s.config.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/seu-repo/sigec-ve/internal/adapter/storage/memory"
+	"github.com/seu-repo/sigec-ve/internal/domain"
+	"github.com/seu-repo/sigec-ve/internal/ports"
+)
+
+func newTestService(t *testing.T, srv *httptest.Server) *Service {
+	t.Helper()
+	users := memory.NewUserRepository()
+	users.Save(context.Background(), &domain.User{ID: "fleet-1", Email: "fleet-1@example.com", Role: domain.UserRoleOperator})
+	svc := NewService(memory.NewWebhookRepository(), users, Config{
+		MaxRetries:         3,
+		RetryBaseDelay:     time.Millisecond,
+		AllowInternalHosts: true, // Test servers listen on loopback
+	}, zap.NewNop())
+	if srv != nil {
+		svc.SetHTTPClient(srv.Client())
+	}
+	return svc
+}
+
+func TestSign(t *testing.T) {
+	// Arrange
+	body := []byte(`{"type":"transaction.completed"}`)
+	timestamp := time.Unix(1760572800, 0)
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1760572800." + string(body)))
+	expected := "t=1760572800,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	// Act
+	header := Sign("whsec_test", timestamp, body)
+
+	// Assert
+	if header != expected {
+		t.Errorf("expected %s, got %s", expected, header)
+	}
+	if Sign("other", timestamp, body) == header {
+		t.Error("expected another secret to give another signature")
+	}
+	if Sign("whsec_test", timestamp, []byte(`{"type":"device.fault"}`)) == header {
+		t.Error("expected another body to give another signature")
+	}
+}
+
+func TestDispatch_SignsTheDeliveredBody(t *testing.T) {
+	// Arrange
+	var gotBody []byte
+	var gotSignature string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(SignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	svc := newTestService(t, srv)
+	ctx := context.Background()
+	endpoint, err := svc.Register(ctx, "fleet-1", &ports.WebhookRequest{
+		URL:        srv.URL + "/hooks",
+		EventTypes: []string{"transaction.completed"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Act
+	deliveries, err := svc.Dispatch(ctx, "transaction.completed", []byte(`{"transaction_id":"tx-1"}`))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Status != domain.WebhookDeliveryDelivered {
+		t.Fatalf("expected one delivered delivery, got %+v", deliveries)
+	}
+	if !strings.HasPrefix(endpoint.Secret, "whsec_") {
+		t.Errorf("expected a generated secret, got %q", endpoint.Secret)
+	}
+
+	parts := strings.SplitN(gotSignature, ",", 2)
+	if len(parts) != 2 {
+		t.Fatalf("expected t=...,v1=..., got %q", gotSignature)
+	}
+	mac := hmac.New(sha256.New, []byte(endpoint.Secret))
+	mac.Write([]byte(strings.TrimPrefix(parts[0], "t=") + "." + string(gotBody)))
+	if parts[1] != "v1="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("expected the signature to match the body, got %q", gotSignature)
+	}
+
+	var body struct {
+		ID   string          `json:"id"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(gotBody, &body); err != nil {
+		t.Fatalf("expected a JSON body, got %s", gotBody)
+	}
+	if body.ID != deliveries[0].ID || body.Type != "transaction.completed" || string(body.Data) != `{"transaction_id":"tx-1"}` {
+		t.Errorf("expected the event in an envelope, got %s", gotBody)
+	}
+}
+
+func TestDispatch_RetriesNon2xx(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		wantStatus   domain.WebhookDeliveryStatus
+		wantAttempts int
+		wantResponse int
+	}{
+		{"succeeds after two failures", 2, domain.WebhookDeliveryDelivered, 3, http.StatusOK},
+		{"gives up after the last retry", 10, domain.WebhookDeliveryFailed, 4, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var calls atomic.Int32
+			var deliveryIDs []string
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					ID string `json:"id"`
+				}
+				json.NewDecoder(r.Body).Decode(&body)
+				deliveryIDs = append(deliveryIDs, body.ID)
+				if calls.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			svc := newTestService(t, srv)
+			ctx := context.Background()
+			endpoint, _ := svc.Register(ctx, "fleet-1", &ports.WebhookRequest{
+				URL:        srv.URL,
+				EventTypes: []string{"device.fault"},
+			})
+
+			// Act
+			deliveries, err := svc.Dispatch(ctx, "device.fault", []byte(`{"device_id":"CP-001"}`))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			d := deliveries[0]
+			if d.Status != tt.wantStatus || d.Attempts != tt.wantAttempts || d.ResponseStatus != tt.wantResponse {
+				t.Errorf("expected %s after %d attempts with %d, got %s after %d with %d",
+					tt.wantStatus, tt.wantAttempts, tt.wantResponse, d.Status, d.Attempts, d.ResponseStatus)
+			}
+			for _, id := range deliveryIDs {
+				if id != d.ID {
+					t.Errorf("expected every retry to carry delivery ID %s, got %s", d.ID, id)
+				}
+			}
+
+			logged, _ := svc.Deliveries(ctx, "fleet-1", endpoint.ID, 10, 0)
+			if len(logged) != 1 || logged[0].Status != tt.wantStatus || logged[0].Attempts != tt.wantAttempts {
+				t.Errorf("expected the delivery log to hold the outcome, got %+v", logged)
+			}
+		})
+	}
+}
+
+func TestDispatch_SkipsUnsubscribedAndInactiveEndpoints(t *testing.T) {
+	// Arrange
+	var calls atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	svc := newTestService(t, srv)
+	ctx := context.Background()
+	inactive := false
+	svc.Register(ctx, "fleet-1", &ports.WebhookRequest{URL: srv.URL, EventTypes: []string{"device.fault"}})
+	svc.Register(ctx, "fleet-2", &ports.WebhookRequest{URL: srv.URL, EventTypes: []string{"transaction.completed"}, Active: &inactive})
+
+	// Act
+	deliveries, err := svc.Dispatch(ctx, "transaction.completed", []byte(`{}`))
+
+	// Assert
+	if err != nil || len(deliveries) != 0 || calls.Load() != 0 {
+		t.Errorf("expected no delivery, got %d deliveries, %d calls, %v", len(deliveries), calls.Load(), err)
+	}
+}
+
+func TestDispatch_OnlyToTheEventOwnerAndStaff(t *testing.T) {
+	// Arrange
+	var calls atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	svc := newTestService(t, srv)
+	ctx := context.Background()
+	hook := &ports.WebhookRequest{URL: srv.URL, EventTypes: []string{"transaction.completed", "device.fault"}}
+	owner, _ := svc.Register(ctx, "user-1", hook)
+	svc.Register(ctx, "user-2", hook)
+	operator, _ := svc.Register(ctx, "fleet-1", hook)
+
+	// Act
+	own, ownErr := svc.Dispatch(ctx, "transaction.completed", []byte(`{"transaction_id":"tx-1","user_id":"user-1"}`))
+	station, stationErr := svc.Dispatch(ctx, "device.fault", []byte(`{"device_id":"CP-001"}`))
+
+	// Assert
+	if ownErr != nil || stationErr != nil {
+		t.Fatalf("unexpected errors: %v, %v", ownErr, stationErr)
+	}
+	if len(own) != 2 || own[0].EndpointID == own[1].EndpointID {
+		t.Fatalf("expected the owner's and the operator's endpoints, got %+v", own)
+	}
+	for _, d := range own {
+		if d.EndpointID != owner.ID && d.EndpointID != operator.ID {
+			t.Errorf("expected no delivery to another user's endpoint, got one to %s", d.EndpointID)
+		}
+	}
+	if len(station) != 1 || station[0].EndpointID != operator.ID {
+		t.Errorf("expected station events to reach the operator only, got %+v", station)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestRegister_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  ports.WebhookRequest
+	}{
+		{"plain http", ports.WebhookRequest{URL: "http://fleet.example/hooks", EventTypes: []string{"device.fault"}}},
+		{"relative url", ports.WebhookRequest{URL: "/hooks", EventTypes: []string{"device.fault"}}},
+		{"no event types", ports.WebhookRequest{URL: "https://fleet.example/hooks"}},
+		{"unknown event type", ports.WebhookRequest{URL: "https://fleet.example/hooks", EventTypes: []string{"user.created"}}},
+		{"loopback", ports.WebhookRequest{URL: "https://127.0.0.1:8443/hooks", EventTypes: []string{"device.fault"}}},
+		{"localhost", ports.WebhookRequest{URL: "https://localhost/hooks", EventTypes: []string{"device.fault"}}},
+		{"private network", ports.WebhookRequest{URL: "https://10.0.0.5/hooks", EventTypes: []string{"device.fault"}}},
+		{"metadata service", ports.WebhookRequest{URL: "https://169.254.169.254/latest", EventTypes: []string{"device.fault"}}},
+	}
+
+	svc := NewService(memory.NewWebhookRepository(), memory.NewUserRepository(), Config{}, zap.NewNop())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Register(context.Background(), "fleet-1", &tt.req)
+			if !errors.Is(err, domain.ErrValidation) {
+				t.Errorf("expected ErrValidation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGet_OtherAccount(t *testing.T) {
+	// Arrange
+	svc := newTestService(t, nil)
+	ctx := context.Background()
+	endpoint, _ := svc.Register(ctx, "fleet-1", &ports.WebhookRequest{
+		URL:        "https://fleet.example/hooks",
+		EventTypes: []string{"device.fault"},
+	})
+
+	// Act
+	_, getErr := svc.Get(ctx, "fleet-2", endpoint.ID)
+	deleteErr := svc.Delete(ctx, "fleet-2", endpoint.ID)
+
+	// Assert
+	if !errors.Is(getErr, domain.ErrWebhookNotFound) || !errors.Is(deleteErr, domain.ErrWebhookNotFound) {
+		t.Errorf("expected ErrWebhookNotFound, got %v and %v", getErr, deleteErr)
+	}
+	if found, _ := svc.Get(ctx, "fleet-1", endpoint.ID); found == nil {
+		t.Error("expected the endpoint to survive")
+	}
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SignatureHeader carries the signature of every delivery:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Partners recompute
+// it with their secret and reject stale timestamps to stop replays.
+const SignatureHeader = "X-Sigec-Signature"
+
+// Sign returns the SignatureHeader value for body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, signature(secret, t, body))
+}
+
+func signature(secret, t string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newSecret generates a signing secret for an endpoint registered without one
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
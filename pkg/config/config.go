@@ -22,6 +22,8 @@ type Config struct {
 	Notification   NotificationConfig   `mapstructure:"notification"`
 	Analytics      AnalyticsConfig      `mapstructure:"analytics"`
 	FeatureFlags   FeatureFlagsConfig   `mapstructure:"feature_flags"`
+	SmartCharging  SmartChargingConfig  `mapstructure:"smart_charging"`
+	Webhooks       WebhooksConfig       `mapstructure:"webhooks"`
 	Cache          CacheConfig          `mapstructure:"cache"`
 	Jobs           JobsConfig           `mapstructure:"jobs"`
 	Limits         LimitsConfig         `mapstructure:"limits"`
@@ -49,21 +51,45 @@ type GRPCConfig struct {
 }
 
 type OCPPConfig struct {
-	Port                  int           `mapstructure:"port"`
-	Version               string        `mapstructure:"version"`
-	HeartbeatInterval     int           `mapstructure:"heartbeat_interval"`
-	WebsocketPingInterval time.Duration `mapstructure:"websocket_ping_interval"`
-	Security              OCPPSecurity  `mapstructure:"security"`
+	Port                   int                `mapstructure:"port"`
+	Version                string             `mapstructure:"version"`
+	HeartbeatInterval      int                `mapstructure:"heartbeat_interval"`
+	HeartbeatByVendor      map[string]int     `mapstructure:"heartbeat_by_vendor"`       // Vendor name -> seconds
+	HeartbeatByChargePoint map[string]int     `mapstructure:"heartbeat_by_charge_point"` // Charge point ID -> seconds
+	WebsocketPingInterval  time.Duration      `mapstructure:"websocket_ping_interval"`
+	WebsocketPongTimeout   time.Duration      `mapstructure:"websocket_pong_timeout"`
+	WebsocketCompression   bool               `mapstructure:"websocket_compression"`       // Offer permessage-deflate
+	CompressionLevel       int                `mapstructure:"websocket_compression_level"` // 1 (fastest) to 9 (smallest)
+	SchemaValidation       bool               `mapstructure:"schema_validation"`           // Reject calls that violate the OCPP 2.0.1 schemas
+	CostUpdateInterval     time.Duration      `mapstructure:"cost_update_interval"`
+	DrainTimeout           time.Duration      `mapstructure:"drain_timeout"`        // How long shutdown waits for in-flight OCPP messages
+	SiteCapacityKW         map[string]float64 `mapstructure:"site_capacity_kw"`     // Charge point ID -> grid connection limit
+	SiteCapacityPolicy     string             `mapstructure:"site_capacity_policy"` // scale (default) or reject
+	ChargeRatePolicy       string             `mapstructure:"charge_rate_policy"`   // clamp (default) or reject limits above an EVSE's maximum
+	MinChargeRateW         float64            `mapstructure:"min_charge_rate_w"`    // Minimum for EVSEs that report none
+	Security               OCPPSecurity       `mapstructure:"security"`
 }
 
 type OCPPSecurity struct {
-	Enabled    bool   `mapstructure:"enabled"`
-	TLSCert    string `mapstructure:"tls_cert"`
-	TLSKey     string `mapstructure:"tls_key"`
-	ClientAuth bool   `mapstructure:"client_auth"`
+	Enabled           bool          `mapstructure:"enabled"`
+	TLSCert           string        `mapstructure:"tls_cert"`
+	TLSKey            string        `mapstructure:"tls_key"`
+	ClientAuth        bool          `mapstructure:"client_auth"`
+	CACert            string        `mapstructure:"ca_cert"` // Signs station CSRs (SignCertificate)
+	CAKey             string        `mapstructure:"ca_key"`
+	CertValidity      time.Duration `mapstructure:"cert_validity"`
+	CertExpiryWarning time.Duration `mapstructure:"cert_expiry_warning"`
+
+	// Connection limits; 0 keeps the default, a negative value turns the limit off
+	MaxConnections       int           `mapstructure:"max_connections"`        // Across all IPs
+	MaxConnectionsPerIP  int           `mapstructure:"max_connections_per_ip"` // Open at once from one IP
+	ConnectionRate       int           `mapstructure:"connection_rate"`        // New connections per IP per window
+	ConnectionRateWindow time.Duration `mapstructure:"connection_rate_window"`
 }
 
 type DatabaseConfig struct {
+	Backend     string `mapstructure:"backend"`      // nietzsche (default), postgres or memory
+	URL         string `mapstructure:"url"`          // PostgreSQL DSN, used by the postgres backend
 	GRPCAddress string `mapstructure:"grpc_address"` // NietzscheDB gRPC endpoint (default: "136.111.0.47:50051")
 	Collection  string `mapstructure:"collection"`   // NietzscheDB collection name (default: "ev-ia")
 	LogQueries  bool   `mapstructure:"log_queries"`
@@ -161,11 +187,37 @@ type SecurityConfig struct {
 	TLSKeyPath  string `mapstructure:"tls_key_path"`
 	EnableMTLS  bool   `mapstructure:"enable_mtls"`
 	CACertPath  string `mapstructure:"ca_cert_path"`
+	// Replay protection of sensitive POSTs (payment webhook, voice commands)
+	ReplayWindow        time.Duration `mapstructure:"replay_window"`         // How far X-Timestamp may be from the server clock
+	WebhookReplayWindow time.Duration `mapstructure:"webhook_replay_window"` // The same for the timestamp payment providers sign
 }
 
 type PaymentConfig struct {
 	Stripe  StripeConfig  `mapstructure:"stripe"`
 	Pricing PricingConfig `mapstructure:"pricing"`
+	Invoice InvoiceConfig `mapstructure:"invoice"`
+	// FXRates values foreign wallet currencies in the billing currency
+	// (stripe.currency), e.g. USD: 5.0
+	FXRates    map[string]float64 `mapstructure:"fx_rates"`
+	Settlement SettlementConfig   `mapstructure:"settlement"`
+}
+
+// SettlementConfig controls charging sessions as soon as they stop
+type SettlementConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	MaxAttempts int           `mapstructure:"max_attempts"` // Payment attempts while the provider is unreachable
+	RetryDelay  time.Duration `mapstructure:"retry_delay"`  // Doubled after each attempt
+	// Sessions left Failed after those attempts are charged again this often
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+	// Card users are held the price of this much energy at the peak rate
+	// when a session starts; zero disables holds
+	HoldMaxEnergyKWh float64 `mapstructure:"hold_max_energy_kwh"`
+}
+
+// InvoiceConfig identifies the company printed as issuer on invoices
+type InvoiceConfig struct {
+	IssuerName  string `mapstructure:"issuer_name"`
+	IssuerTaxID string `mapstructure:"issuer_tax_id"` // CNPJ
 }
 
 type StripeConfig struct {
@@ -186,10 +238,30 @@ type NotificationConfig struct {
 }
 
 type EmailConfig struct {
-	Provider string `mapstructure:"provider"`
-	APIKey   string `mapstructure:"api_key"`
-	From     string `mapstructure:"from"`
-	FromName string `mapstructure:"from_name"`
+	Provider       string        `mapstructure:"provider"` // sendgrid, ses, smtp, log
+	APIKey         string        `mapstructure:"api_key"`
+	From           string        `mapstructure:"from"`
+	FromName       string        `mapstructure:"from_name"`
+	SMTP           SMTPConfig    `mapstructure:"smtp"`
+	SES            SESConfig     `mapstructure:"ses"`
+	MaxRetries     int           `mapstructure:"max_retries"`
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"`
+	BaseURL        string        `mapstructure:"base_url"` // Public web app URL that links in emails point to
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	UseTLS   bool   `mapstructure:"use_tls"`
+}
+
+type SESConfig struct {
+	Region          string `mapstructure:"region"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	Endpoint        string `mapstructure:"endpoint"`
 }
 
 type SMSConfig struct {
@@ -202,6 +274,8 @@ type SMSConfig struct {
 type PushConfig struct {
 	Provider        string `mapstructure:"provider"`
 	CredentialsPath string `mapstructure:"credentials_path"`
+	ServerKey       string `mapstructure:"server_key"`
+	ProjectID       string `mapstructure:"project_id"`
 }
 
 type AnalyticsConfig struct {
@@ -218,6 +292,22 @@ type FeatureFlagsConfig struct {
 	BlockchainAudit bool `mapstructure:"blockchain_audit"`
 }
 
+type SmartChargingConfig struct {
+	StationPowerKW    map[string]float64 `mapstructure:"station_power_kw"`   // Charge point ID -> power its connectors share
+	ReservationWeight float64            `mapstructure:"reservation_weight"` // Share of a reservation holder relative to other sessions
+	StationSelection  string             `mapstructure:"station_selection"`  // first_available, nearest, cheapest, least_busy or highest_power
+}
+
+type WebhooksConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	EventTypes     []string      `mapstructure:"event_types"` // Subjects partners can subscribe to
+	MaxRetries     int           `mapstructure:"max_retries"`
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	// Allows endpoints on loopback and private addresses; off by default
+	AllowInternalHosts bool `mapstructure:"allow_internal_hosts"`
+}
+
 type CacheConfig struct {
 	DeviceStatusTTL       time.Duration `mapstructure:"device_status_ttl"`
 	UserSessionTTL        time.Duration `mapstructure:"user_session_ttl"`
@@ -226,10 +316,60 @@ type CacheConfig struct {
 }
 
 type JobsConfig struct {
-	DailyReport          JobSchedule `mapstructure:"daily_report"`
-	AnalyticsAggregation JobSchedule `mapstructure:"analytics_aggregation"`
-	DeviceHealthCheck    JobSchedule `mapstructure:"device_health_check"`
-	InvoiceGeneration    JobSchedule `mapstructure:"invoice_generation"`
+	DailyReport          JobSchedule       `mapstructure:"daily_report"`
+	AnalyticsAggregation JobSchedule       `mapstructure:"analytics_aggregation"`
+	DeviceHealthCheck    JobSchedule       `mapstructure:"device_health_check"`
+	InvoiceGeneration    JobSchedule       `mapstructure:"invoice_generation"`
+	Reconciliation       ReconciliationJob `mapstructure:"reconciliation"`
+	PrepaidMonitor       PrepaidMonitorJob `mapstructure:"prepaid_monitor"`
+	MeterRetention       MeterRetentionJob `mapstructure:"meter_retention"`
+	SessionLimit         SessionLimitJob   `mapstructure:"session_limit"`
+	SmartCharging        SmartChargingJob  `mapstructure:"smart_charging"`
+}
+
+// ReconciliationJob compares billed energy with the station-reported totals.
+// A transaction matches when the difference is within the larger tolerance.
+type ReconciliationJob struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	Interval         time.Duration `mapstructure:"interval"`
+	Lookback         time.Duration `mapstructure:"lookback"`
+	TolerancePercent float64       `mapstructure:"tolerance_percent"`
+	ToleranceWh      int           `mapstructure:"tolerance_wh"`
+}
+
+// PrepaidMonitorJob stops prepaid sessions before their cost exceeds the
+// user's wallet balance
+type PrepaidMonitorJob struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	Buffer   float64       `mapstructure:"buffer"` // Balance kept free to avoid going negative
+}
+
+// MeterRetentionJob downsamples the meter values of older sessions and
+// deletes them past the retention horizon
+type MeterRetentionJob struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	Interval           time.Duration `mapstructure:"interval"`
+	FullResolution     time.Duration `mapstructure:"full_resolution"`     // Sessions younger than this keep every sample
+	DownsampleInterval time.Duration `mapstructure:"downsample_interval"` // Older sessions keep one sample per interval
+	Retention          time.Duration `mapstructure:"retention"`
+}
+
+// SessionLimitJob stops sessions that run past limits.max_transaction_duration,
+// or their station's own limit
+type SessionLimitJob struct {
+	Enabled       bool                     `mapstructure:"enabled"`
+	Interval      time.Duration            `mapstructure:"interval"`
+	ByChargePoint map[string]time.Duration `mapstructure:"by_charge_point"` // Charge point ID -> limit, 0 exempts it
+	WarnBefore    time.Duration            `mapstructure:"warn_before"`     // The user is notified this long before the stop
+	StopGrace     time.Duration            `mapstructure:"stop_grace"`      // Bill anyway if the station has not ended the session by then
+}
+
+// SmartChargingJob plans charging windows for the plugged-in EVs of users
+// who opted in to smart charging, in the cheapest hours before departure
+type SmartChargingJob struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
 }
 
 type JobSchedule struct {